@@ -2,11 +2,14 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -15,6 +18,12 @@ import (
 
 	"github.com/toeic-app/internal/backup"
 	"github.com/toeic-app/internal/config"
+	"github.com/toeic-app/internal/db/migrations"
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/readonly"
+	"github.com/toeic-app/internal/uploader"
+
+	_ "github.com/lib/pq"
 )
 
 const (
@@ -40,12 +49,30 @@ func main() {
 		handleList(args)
 	case "validate":
 		handleValidate(args)
+	case "verify-restore":
+		handleVerifyRestore(args)
 	case "cleanup":
 		handleCleanup(args)
+	case "prune":
+		handlePrune(args)
+	case "protect":
+		handleProtect(args)
 	case "status":
 		handleStatus(args)
 	case "monitor":
 		handleMonitor(args)
+	case "rotate-keys":
+		handleRotateKeys(args)
+	case "read-only":
+		handleReadOnly(args)
+	case "pre-migrate":
+		handlePreMigrate(args)
+	case "tools":
+		handleTools(args)
+	case "assets":
+		handleAssets(args)
+	case "export":
+		handleExport(args)
 	case "help", "-h", "--help":
 		showUsage()
 	case "version", "-v", "--version":
@@ -68,9 +95,19 @@ COMMANDS:
     restore     Restore database from backup
     list        List available backups
     validate    Validate backup file integrity
-    cleanup     Clean up old backups
+    verify-restore
+                Restore a backup into a scratch database and check it
+    cleanup     Remove the backups the retention policy does not keep
+    prune       Keep the latest backups and remove the rest
+    protect     Protect a backup from deletion, or lift its protection
     status      Show backup system status
     monitor     Start monitoring mode
+    rotate-keys Rewrap encrypted backups with the active encryption key
+    read-only   Switch the API's read-only mode (on, off, status)
+    pre-migrate Back up the database before migrations are applied
+    tools       Check pg_dump, psql and pg_restore against the server
+    assets      List or extract the media assets of a full backup
+    export      Export tables, or some of their rows and columns, as CSV or NDJSON
     help        Show this help message
     version     Show version information
 
@@ -78,46 +115,86 @@ Use '%s <command> --help' for more information about a command.
 
 EXAMPLES:
     %s create --description "Manual backup before upgrade"
+    %s create --mode incremental
+    %s create --format directory --jobs 8
+    %s create --database analytics
     %s restore --file backup_20250615_120000.sql
+    %s restore --file backup_20250615_120000.sql --dry-run
+    %s restore --file backup_20250615_120000.sql --tables users,words
     %s list --sort date --limit 10
     %s validate --file backup_20250615_120000.sql
+    %s verify-restore --file backup_20250615_120000.sql
+    %s cleanup --dry-run --verbose
     %s cleanup --older-than 30d
+    %s prune --keep-last 10 --keep-weekly 8 --dry-run
+    %s protect --file backup_20250615_120000.sql
     %s status --detailed
+    %s status --verify
     %s monitor --interval 5m
-
-`, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName)
+    %s read-only on --reason "Running migrations"
+    %s rotate-keys --dry-run=false
+    %s pre-migrate && migrate -path internal/db/migrations -database "$DATABASE_URL" up
+    %s tools
+    %s assets --file backup_20250615_120000.sql --extract ./media
+    %s export --tables words,questions --out ./export
+    %s export --tables user_progress --columns user_id,score --where "updated_at > now() - interval '7 days'" --format ndjson --out -
+
+`, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName, appName)
 }
 
 func handleCreate(args []string) {
 	fs := flag.NewFlagSet("create", flag.ExitOnError)
 	description := fs.String("description", "", "Backup description")
 	backupType := fs.String("type", "manual", "Backup type (manual, automatic, migration)")
+	mode := fs.String("mode", backup.ModeFull, "Backup mode (full, incremental, differential)")
 	compress := fs.Bool("compress", true, "Compress backup")
+	format := fs.String("format", "", "Dump format of full backups (plain, custom, directory); default BACKUP_DUMP_FORMAT")
+	jobs := fs.Int("jobs", 0, "Parallel pg_dump workers of directory backups; default BACKUP_PARALLEL_JOBS")
+	unthrottled := fs.Bool("unthrottled", false, "Ignore BACKUP_IO_MAX_RATE, BACKUP_IO_MAX_OPS and BACKUP_IO_SCHEDULE")
 	validate := fs.Bool("validate", true, "Validate backup after creation")
 	verbose := fs.Bool("verbose", false, "Verbose output")
+	database := fs.String("database", config.PrimaryDatabase, "Database: primary or one of BACKUP_DATABASES")
 
 	fs.Parse(args)
 
 	if *description == "" {
 		*description = fmt.Sprintf("Manual backup created at %s", time.Now().Format("2006-01-02 15:04:05"))
 	}
+	if !backup.ValidMode(*mode) {
+		fmt.Printf("❌ Invalid backup mode: %s (use full, incremental or differential)\n", *mode)
+		os.Exit(1)
+	}
+	if *format != "" && !backup.ValidFormat(*format) {
+		fmt.Printf("❌ Invalid dump format: %s (use plain, custom or directory)\n", *format)
+		os.Exit(1)
+	}
 
 	fmt.Printf("Creating backup: %s\n", *description)
 	// Load configuration
 	backupConfig := config.LoadBackupConfig()
 	backupConfig.CompressBackups = *compress
 	backupConfig.ValidateAfterBackup = *validate
+	if *format != "" {
+		backupConfig.DumpFormat = *format
+	}
+	if *jobs > 0 {
+		backupConfig.ParallelJobs = *jobs
+	}
 
 	dbConfig := config.DefaultConfig()
 
 	// Create backup manager
-	manager := backup.NewBackupManager(backupConfig, dbConfig)
+	manager := newDatabaseManager(backupConfig, dbConfig, *database)
+	setAssetSigner(manager, dbConfig)
 
 	// Create backup
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
 	defer cancel()
+	if *unthrottled {
+		ctx = backup.WithIOLimit(ctx, backup.IOLimit{})
+	}
 
-	result, err := manager.CreateBackup(ctx, *description, *backupType)
+	result, err := manager.CreateBackupWithMode(ctx, *description, *backupType, *mode)
 	if err != nil {
 		fmt.Printf("❌ Backup creation failed: %v\n", err)
 		os.Exit(1)
@@ -125,6 +202,11 @@ func handleCreate(args []string) {
 
 	fmt.Printf("✅ Backup created successfully!\n")
 	fmt.Printf("   Filename: %s\n", result.Metadata.Filename)
+	fmt.Printf("   Mode: %s\n", result.Metadata.BackupMode())
+	fmt.Printf("   Format: %s\n", result.Metadata.DumpFormat())
+	if result.Metadata.Parent != "" {
+		fmt.Printf("   Builds on: %s (%d tables changed)\n", result.Metadata.Parent, len(result.Metadata.Tables))
+	}
 	fmt.Printf("   Size: %s\n", formatBytes(result.Size))
 	fmt.Printf("   Duration: %v\n", result.Duration)
 
@@ -153,10 +235,22 @@ func handleRestore(args []string) {
 	fs := flag.NewFlagSet("restore", flag.ExitOnError)
 	filename := fs.String("file", "", "Backup file to restore from (required)")
 	confirm := fs.Bool("yes", false, "Skip confirmation prompt")
+	readOnly := fs.Bool("read-only", true, "Switch the API to read-only while restoring")
+	jobs := fs.Int("jobs", 0, "Parallel pg_restore workers of archive backups; default BACKUP_PARALLEL_JOBS")
+	unthrottled := fs.Bool("unthrottled", false, "Ignore BACKUP_IO_MAX_RATE, BACKUP_IO_MAX_OPS and BACKUP_IO_SCHEDULE")
+	dryRun := fs.Bool("dry-run", false, "Compare the backup with the live database and report the differences without restoring")
+	format := fs.String("format", "table", "Output format of --dry-run: table, json")
+	tables := fs.String("tables", "", "Comma-separated tables to restore, e.g. users,words; the rest of the database is kept")
 	_ = fs.Bool("verbose", false, "Verbose output")
+	database := fs.String("database", config.PrimaryDatabase, "Database: primary or one of BACKUP_DATABASES")
 
 	fs.Parse(args)
 
+	var options backup.RestoreOptions
+	if *tables != "" {
+		options.Tables = strings.Split(*tables, ",")
+	}
+
 	if *filename == "" {
 		fmt.Println("❌ Error: --file parameter is required")
 		fmt.Println("Use 'backup-admin list' to see available backups")
@@ -166,14 +260,41 @@ func handleRestore(args []string) {
 
 	// Load configuration
 	backupConfig := config.LoadBackupConfig()
+	if *jobs > 0 {
+		backupConfig.ParallelJobs = *jobs
+	}
 	dbConfig := config.DefaultConfig()
 
 	// Create backup manager
-	manager := backup.NewBackupManager(backupConfig, dbConfig)
+	manager := newDatabaseManager(backupConfig, dbConfig, *database)
+	if *database != config.PrimaryDatabase {
+		// The API only writes to the application database
+		*readOnly = false
+	}
+
+	if *dryRun {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+		defer cancel()
+		preview, err := manager.PreviewRestore(ctx, *filename)
+		if err != nil {
+			fmt.Printf("❌ Restore preview failed: %v\n", err)
+			os.Exit(1)
+		}
+		if *format == "json" {
+			outputJSON(preview)
+		} else {
+			printRestorePreview(preview)
+		}
+		return
+	}
 
 	// Confirmation prompt
 	if !*confirm {
-		fmt.Printf("\n⚠️  WARNING: This will replace the current database contents!\n")
+		if len(options.Tables) > 0 {
+			fmt.Printf("\n⚠️  WARNING: This will replace the rows of these tables: %s\n", strings.Join(options.Tables, ", "))
+		} else {
+			fmt.Printf("\n⚠️  WARNING: This will replace the current database contents!\n")
+		}
 		fmt.Printf("Database: %s@%s:%s/%s\n", dbConfig.DBUser, dbConfig.DBHost, dbConfig.DBPort, dbConfig.DBName)
 		fmt.Printf("Continue? (yes/no): ")
 
@@ -189,10 +310,29 @@ func handleRestore(args []string) {
 	// Perform restore
 	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Minute)
 	defer cancel()
+	if *unthrottled {
+		ctx = backup.WithIOLimit(ctx, backup.IOLimit{})
+	}
+
+	// Keep the API from writing while the database is replaced
+	release := func() {}
+	if *readOnly {
+		var err error
+		mode := newReadOnlyMode(dbConfig)
+		release, err = mode.Acquire(ctx, "restore of "+*filename, appName)
+		if err != nil {
+			fmt.Printf("❌ Failed to switch the API to read-only: %v\n", err)
+			fmt.Println("Use --read-only=false to restore anyway")
+			os.Exit(1)
+		}
+		fmt.Printf("🔒 API switched to read-only, waiting %v for every instance to notice\n", dbConfig.ReadOnlyRefreshInterval)
+		time.Sleep(dbConfig.ReadOnlyRefreshInterval)
+	}
 
 	fmt.Printf("🔄 Starting restore operation...\n")
 
-	result, err := manager.RestoreBackup(ctx, *filename)
+	result, err := manager.RestoreBackup(ctx, *filename, options)
+	release()
 	if err != nil {
 		fmt.Printf("❌ Restore failed: %v\n", err)
 		os.Exit(1)
@@ -200,6 +340,12 @@ func handleRestore(args []string) {
 
 	fmt.Printf("✅ Database restored successfully!\n")
 	fmt.Printf("   Duration: %v\n", result.Duration)
+	if len(result.Chain) > 1 {
+		fmt.Printf("   Replayed: %s\n", strings.Join(result.Chain, " -> "))
+	}
+	if len(result.Tables) > 0 {
+		fmt.Printf("   Restored tables: %s\n", strings.Join(result.Tables, ", "))
+	}
 
 	if result.TablesCount > 0 {
 		fmt.Printf("   Tables: %d\n", result.TablesCount)
@@ -223,16 +369,18 @@ func handleList(args []string) {
 	limit := fs.Int("limit", 20, "Maximum number of backups to show")
 	format := fs.String("format", "table", "Output format: table, json")
 	verbose := fs.Bool("verbose", false, "Show detailed information")
+	database := fs.String("database", config.PrimaryDatabase, "Database: primary or one of BACKUP_DATABASES")
 
 	fs.Parse(args)
 
 	// Load configuration
 	backupConfig := config.LoadBackupConfig()
+	manager := newDatabaseManager(backupConfig, config.DefaultConfig(), *database)
 
-	// Scan backup directory
-	backups, err := scanBackupDirectory(backupConfig.BackupDir)
+	// List backups in the configured storage
+	backups, err := listBackups(manager)
 	if err != nil {
-		fmt.Printf("❌ Error scanning backup directory: %v\n", err)
+		fmt.Printf("❌ Error listing backups: %v\n", err)
 		os.Exit(1)
 	}
 
@@ -261,6 +409,7 @@ func handleList(args []string) {
 	if *limit > 0 && len(backups) > *limit {
 		backups = backups[:*limit]
 	}
+	describeBackups(manager, backups)
 
 	// Output results
 	switch *format {
@@ -275,6 +424,7 @@ func handleValidate(args []string) {
 	fs := flag.NewFlagSet("validate", flag.ExitOnError)
 	filename := fs.String("file", "", "Backup file to validate (required)")
 	verbose := fs.Bool("verbose", false, "Verbose output")
+	database := fs.String("database", config.PrimaryDatabase, "Database: primary or one of BACKUP_DATABASES")
 
 	fs.Parse(args)
 
@@ -289,52 +439,289 @@ func handleValidate(args []string) {
 	dbConfig := config.DefaultConfig()
 
 	// Create backup manager
-	_ = backup.NewBackupManager(backupConfig, dbConfig)
+	manager := newDatabaseManager(backupConfig, dbConfig, *database)
 
-	// Perform validation (simplified)
-	backupPath := filepath.Join(backupConfig.BackupDir, *filename)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
 
-	// Check if file exists
-	fileInfo, err := os.Stat(backupPath)
+	// Encrypted and compressed backups are decrypted and decompressed first
+	report, err := manager.ValidateBackup(ctx, *filename)
 	if err != nil {
-		fmt.Printf("❌ Backup file not found: %v\n", err)
+		fmt.Printf("❌ Backup cannot be validated: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Printf("✅ File exists: %s (%s)\n", *filename, formatBytes(fileInfo.Size()))
-	fmt.Printf("✅ File is readable\n")
-	fmt.Printf("✅ File size is valid\n")
+	fmt.Printf("✅ File exists: %s (%s)\n", *filename, formatBytes(report.Size))
+	if report.ManifestMatch && report.ManifestSigned {
+		fmt.Printf("✅ Files match the signed manifest\n")
+	} else if report.ManifestMatch {
+		fmt.Printf("✅ Files match the manifest\n")
+	}
+	if report.Encrypted {
+		fmt.Printf("🔐 Encrypted with key: %s\n", report.KeyID)
+	}
+	if report.ReadableFile {
+		fmt.Printf("✅ File is readable\n")
+	}
+	if report.ValidStructure {
+		fmt.Printf("✅ SQL structure is valid\n")
+	}
+	if report.ChecksumMatch {
+		fmt.Printf("✅ Checksum matches\n")
+	}
 
 	if *verbose {
-		fmt.Printf("   Created: %s\n", fileInfo.ModTime().Format("2006-01-02 15:04:05"))
-		fmt.Printf("   Size: %s\n", formatBytes(fileInfo.Size()))
+		fmt.Printf("   Modified: %s\n", report.LastModified.Format("2006-01-02 15:04:05"))
+	}
+	for _, warning := range report.Warnings {
+		fmt.Printf("⚠️  %s\n", warning)
+	}
+	for _, issue := range report.Issues {
+		fmt.Printf("❌ %s\n", issue)
 	}
 
+	if !report.Valid {
+		fmt.Printf("❌ Backup validation failed\n")
+		os.Exit(1)
+	}
 	fmt.Printf("✅ Backup validation completed successfully\n")
 }
 
+// printRestorePreview prints what a restore would change
+func printRestorePreview(preview *backup.RestorePreview) {
+	if len(preview.Chain) > 1 {
+		fmt.Printf("   Would replay: %s\n", strings.Join(preview.Chain, " -> "))
+	}
+	if preview.BackupSchemaVersion != "" {
+		fmt.Printf("   Schema: live migration %s, backup migration %s\n", preview.LiveSchemaVersion, preview.BackupSchemaVersion)
+	}
+	for _, table := range preview.NewTables {
+		fmt.Printf("➕ %s would be created\n", table)
+	}
+	for _, table := range preview.MissingTables {
+		fmt.Printf("➖ %s is not in the backup and would be left as it is\n", table)
+	}
+	for _, table := range preview.ChangedTables {
+		fmt.Printf("✏️  %s: %d columns added, %d dropped, %d changed\n",
+			table.Table, len(table.AddedColumns), len(table.DroppedColumns), len(table.ChangedColumns))
+	}
+	for _, operation := range preview.Destructive {
+		fmt.Printf("⚠️  %-16s %s\n", operation.Operation, operation.Detail)
+	}
+	for _, warning := range preview.Warnings {
+		fmt.Printf("⚠️  %s\n", warning)
+	}
+	if preview.IsDestructive() {
+		fmt.Printf("Dry run: restoring %s would lose live data (%d destructive operations), nothing was restored\n", preview.Filename, len(preview.Destructive))
+	} else {
+		fmt.Printf("✅ Dry run: restoring %s loses no live data, nothing was restored\n", preview.Filename)
+	}
+}
+
+func handleVerifyRestore(args []string) {
+	fs := flag.NewFlagSet("verify-restore", flag.ExitOnError)
+	filename := fs.String("file", "", "Backup file to verify (required)")
+	scratchDB := fs.String("scratch-db", "", "Existing database to restore into, its contents are replaced (default BACKUP_VERIFY_SCRATCH_DB, else a temporary database)")
+	keep := fs.Bool("keep", false, "Keep the temporary database for inspection")
+	format := fs.String("format", "table", "Output format: table, json")
+	verbose := fs.Bool("verbose", false, "Show the row counts of every table")
+
+	fs.Parse(args)
+
+	if *filename == "" {
+		fmt.Println("❌ Error: --file parameter is required")
+		os.Exit(1)
+	}
+
+	backupConfig := config.LoadBackupConfig()
+	manager := backup.NewBackupManager(backupConfig, config.DefaultConfig())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Minute)
+	defer cancel()
+
+	if *format != "json" {
+		fmt.Printf("Verifying that %s can be restored...\n", *filename)
+	}
+	report, err := manager.VerifyRestore(ctx, *filename, backup.VerifyOptions{ScratchDB: *scratchDB, Keep: *keep})
+	if err != nil {
+		fmt.Printf("❌ Restore verification could not run: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *format == "json" {
+		outputJSON(report)
+	} else {
+		if len(report.Chain) > 1 {
+			fmt.Printf("   Restored: %s\n", strings.Join(report.Chain, " -> "))
+		}
+		if report.Database != "" {
+			fmt.Printf("   Database: %s\n", report.Database)
+		}
+		for _, check := range report.Checks {
+			status := "✅"
+			if !check.Passed {
+				status = "❌"
+			}
+			fmt.Printf("%s %-10s %s\n", status, check.Name, check.Detail)
+		}
+		for _, table := range report.Tables {
+			if *verbose || !table.Passed() {
+				fmt.Printf("   %-40s expected %d rows, restored %d\n", table.Table, table.ExpectedRows, table.RestoredRows)
+			}
+		}
+		for _, warning := range report.Warnings {
+			fmt.Printf("⚠️  %s\n", warning)
+		}
+		if report.Kept {
+			fmt.Printf("   Temporary database %s was kept, drop it when done\n", report.Database)
+		}
+		fmt.Printf("   Duration: %v\n", report.Duration.Round(time.Millisecond))
+	}
+
+	if !report.Passed {
+		if *format != "json" {
+			fmt.Printf("❌ Backup %s failed restore verification\n", *filename)
+		}
+		os.Exit(1)
+	}
+	if *format != "json" {
+		fmt.Printf("✅ Backup %s is restorable\n", *filename)
+	}
+}
+
+func handleRotateKeys(args []string) {
+	fs := flag.NewFlagSet("rotate-keys", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", true, "Only list the backups that would be rewrapped")
+
+	fs.Parse(args)
+
+	backupConfig := config.LoadBackupConfig()
+	manager := backup.NewBackupManager(backupConfig, config.DefaultConfig())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Minute)
+	defer cancel()
+
+	rotated, err := manager.RotateKeys(ctx, *dryRun)
+	for _, rotation := range rotated {
+		fmt.Printf("  - %s: %s -> %s\n", rotation.Filename, rotation.From, rotation.To)
+	}
+	if err != nil {
+		fmt.Printf("❌ Key rotation failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	switch {
+	case len(rotated) == 0:
+		fmt.Println("✅ Every encrypted backup uses the active key")
+	case *dryRun:
+		fmt.Printf("%d backups use an older key (dry run - use --dry-run=false to rewrap them)\n", len(rotated))
+	default:
+		fmt.Printf("✅ Rewrapped the data keys of %d backups\n", len(rotated))
+	}
+}
+
 func handleCleanup(args []string) {
+	// Load configuration
+	backupConfig := config.LoadBackupConfig()
+	manager := backup.NewBackupManager(backupConfig, config.DefaultConfig())
+	policy := manager.RetentionPolicy()
+
 	fs := flag.NewFlagSet("cleanup", flag.ExitOnError)
-	olderThan := fs.String("older-than", "30d", "Remove backups older than (e.g., 30d, 7d, 24h)")
+	daily := fs.Int("daily", policy.Daily, "Days to keep the newest backup of (BACKUP_KEEP_DAILY)")
+	weekly := fs.Int("weekly", policy.Weekly, "Weeks to keep the newest backup of (BACKUP_KEEP_WEEKLY)")
+	monthly := fs.Int("monthly", policy.Monthly, "Months to keep the newest backup of (BACKUP_KEEP_MONTHLY)")
+	olderThan := fs.String("older-than", "", "Remove every backup older than this instead (e.g., 30d, 7d, 24h)")
 	dryRun := fs.Bool("dry-run", false, "Show what would be deleted without actually deleting")
 	confirm := fs.Bool("yes", false, "Skip confirmation prompt")
+	verbose := fs.Bool("verbose", false, "List the backups kept and why")
+	database := fs.String("database", config.PrimaryDatabase, "Database: primary or one of BACKUP_DATABASES")
 
 	fs.Parse(args)
 
+	if *database != config.PrimaryDatabase {
+		// Unless given, keep the backups the policy of the database keeps
+		manager = newDatabaseManager(backupConfig, config.DefaultConfig(), *database)
+		set := make(map[string]bool)
+		fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+		databasePolicy := manager.RetentionPolicy()
+		if !set["daily"] {
+			*daily = databasePolicy.Daily
+		}
+		if !set["weekly"] {
+			*weekly = databasePolicy.Weekly
+		}
+		if !set["monthly"] {
+			*monthly = databasePolicy.Monthly
+		}
+	}
+
+	if *olderThan != "" {
+		cleanupOlderThan(manager, *olderThan, *dryRun, *confirm)
+		return
+	}
+
+	policy = backup.RetentionPolicy{Daily: *daily, Weekly: *weekly, Monthly: *monthly}
+	plan, err := manager.PlanRetention(context.Background(), policy)
+	if err != nil {
+		fmt.Printf("❌ Error planning retention: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Retention policy: keep %s\n", policy)
+	fmt.Printf("Keeping %d backups\n", len(plan.Keep))
+	if *verbose {
+		for _, kept := range plan.Keep {
+			fmt.Printf("  + %s (%s, %s)\n", kept.Name, kept.ModTime.Format("2006-01-02"), strings.Join(kept.Reasons, ", "))
+		}
+	}
+
+	if len(plan.Remove) == 0 {
+		fmt.Println("No backups to remove.")
+		return
+	}
+
+	fmt.Printf("Found %d backups the retention policy does not keep:\n", len(plan.Remove))
+
+	var totalSize int64
+	for _, backup := range plan.Remove {
+		fmt.Printf("  - %s (%s, %s)\n", backup.Name, formatBytes(backup.Size), backup.ModTime.Format("2006-01-02"))
+		totalSize += backup.Size
+	}
+
+	fmt.Printf("Total size to be freed: %s\n", formatBytes(totalSize))
+
+	if *dryRun {
+		fmt.Printf("(Dry run - no files were deleted)\n")
+		return
+	}
+
+	if !*confirm && !confirmCleanup() {
+		return
+	}
+
+	// Delete the backups, with their metadata and local copies
+	deleted := manager.ApplyRetention(context.Background(), plan)
+	if deleted < len(plan.Remove) {
+		fmt.Printf("❌ Failed to delete %d backups, see the log\n", len(plan.Remove)-deleted)
+	}
+
+	fmt.Printf("✅ Cleanup completed: %d backups deleted\n", deleted)
+}
+
+// cleanupOlderThan removes every backup older than a duration, regardless
+// of the retention policy
+func cleanupOlderThan(manager *backup.BackupManager, olderThan string, dryRun, confirm bool) {
 	// Parse duration
-	duration, err := parseDuration(*olderThan)
+	duration, err := parseDuration(olderThan)
 	if err != nil {
 		fmt.Printf("❌ Invalid duration format: %v\n", err)
 		os.Exit(1)
 	}
 
-	// Load configuration
-	backupConfig := config.LoadBackupConfig()
-
-	// Scan backup directory
-	backups, err := scanBackupDirectory(backupConfig.BackupDir)
+	// List backups in the configured storage
+	backups, err := listBackups(manager)
 	if err != nil {
-		fmt.Printf("❌ Error scanning backup directory: %v\n", err)
+		fmt.Printf("❌ Error listing backups: %v\n", err)
 		os.Exit(1)
 	}
 
@@ -349,11 +736,11 @@ func handleCleanup(args []string) {
 	}
 
 	if len(oldBackups) == 0 {
-		fmt.Printf("No backups older than %s found.\n", *olderThan)
+		fmt.Printf("No backups older than %s found.\n", olderThan)
 		return
 	}
 
-	fmt.Printf("Found %d backups older than %s:\n", len(oldBackups), *olderThan)
+	fmt.Printf("Found %d backups older than %s:\n", len(oldBackups), olderThan)
 
 	var totalSize int64
 	for _, backup := range oldBackups {
@@ -363,28 +750,19 @@ func handleCleanup(args []string) {
 
 	fmt.Printf("Total size to be freed: %s\n", formatBytes(totalSize))
 
-	if *dryRun {
+	if dryRun {
 		fmt.Printf("(Dry run - no files were deleted)\n")
 		return
 	}
 
-	// Confirmation
-	if !*confirm {
-		fmt.Printf("Delete these backups? (yes/no): ")
-		var response string
-		fmt.Scanln(&response)
-
-		if strings.ToLower(response) != "yes" && strings.ToLower(response) != "y" {
-			fmt.Println("Cleanup cancelled.")
-			return
-		}
+	if !confirm && !confirmCleanup() {
+		return
 	}
 
-	// Delete old backups
+	// Delete old backups, with their metadata and local copies
 	deleted := 0
 	for _, backup := range oldBackups {
-		backupPath := filepath.Join(backupConfig.BackupDir, backup.Name)
-		if err := os.Remove(backupPath); err != nil {
+		if err := manager.DeleteBackup(context.Background(), backup.Name); err != nil {
 			fmt.Printf("❌ Failed to delete %s: %v\n", backup.Name, err)
 		} else {
 			deleted++
@@ -394,10 +772,122 @@ func handleCleanup(args []string) {
 	fmt.Printf("✅ Cleanup completed: %d backups deleted\n", deleted)
 }
 
+// confirmCleanup asks whether the listed backups should be deleted
+func confirmCleanup() bool {
+	fmt.Printf("Delete these backups? (yes/no): ")
+	var response string
+	fmt.Scanln(&response)
+
+	if strings.ToLower(response) != "yes" && strings.ToLower(response) != "y" {
+		fmt.Println("Cleanup cancelled.")
+		return false
+	}
+	return true
+}
+
+func handlePrune(args []string) {
+	fs := flag.NewFlagSet("prune", flag.ExitOnError)
+	keepLast := fs.Int("keep-last", 0, "Newest backups to keep")
+	keepDaily := fs.Int("keep-daily", 0, "Days to keep the newest backup of")
+	keepWeekly := fs.Int("keep-weekly", 0, "Weeks to keep the newest backup of")
+	keepMonthly := fs.Int("keep-monthly", 0, "Months to keep the newest backup of")
+	dryRun := fs.Bool("dry-run", false, "Show what would be deleted without actually deleting")
+	confirm := fs.Bool("yes", false, "Skip confirmation prompt")
+	verbose := fs.Bool("verbose", false, "List the backups kept and why")
+	database := fs.String("database", config.PrimaryDatabase, "Database: primary or one of BACKUP_DATABASES")
+
+	fs.Parse(args)
+
+	backupConfig := config.LoadBackupConfig()
+	manager := newDatabaseManager(backupConfig, config.DefaultConfig(), *database)
+	if *database == config.PrimaryDatabase {
+		// Backups protected in the catalog are never pruned
+		defer setCatalog(manager).Close()
+	}
+
+	policy := backup.RetentionPolicy{Last: *keepLast, Daily: *keepDaily, Weekly: *keepWeekly, Monthly: *keepMonthly}
+	plan, err := manager.PlanPrune(context.Background(), policy)
+	if err != nil {
+		fmt.Printf("❌ Error planning prune: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Prune policy: keep %s\n", policy)
+	fmt.Printf("Keeping %d backups\n", len(plan.Keep))
+	for _, kept := range plan.Keep {
+		if *verbose || slices.Contains(kept.Reasons, backup.RetainFull) {
+			fmt.Printf("  + %s (%s, %s)\n", kept.Name, kept.ModTime.Format("2006-01-02"), strings.Join(kept.Reasons, ", "))
+		}
+	}
+
+	if len(plan.Remove) == 0 {
+		fmt.Println("No backups to remove.")
+		return
+	}
+
+	fmt.Printf("Found %d backups the prune policy does not keep:\n", len(plan.Remove))
+
+	var totalSize int64
+	for _, backup := range plan.Remove {
+		fmt.Printf("  - %s (%s, %s)\n", backup.Name, formatBytes(backup.Size), backup.ModTime.Format("2006-01-02"))
+		totalSize += backup.Size
+	}
+
+	fmt.Printf("Total size to be freed: %s\n", formatBytes(totalSize))
+
+	if *dryRun {
+		fmt.Printf("(Dry run - no files were deleted)\n")
+		return
+	}
+
+	if !*confirm && !confirmCleanup() {
+		return
+	}
+
+	deleted := manager.ApplyRetention(context.Background(), plan)
+	if deleted < len(plan.Remove) {
+		fmt.Printf("❌ Failed to delete %d backups, see the log\n", len(plan.Remove)-deleted)
+	}
+
+	fmt.Printf("✅ Prune completed: %d backups deleted\n", deleted)
+}
+
+func handleProtect(args []string) {
+	fs := flag.NewFlagSet("protect", flag.ExitOnError)
+	file := fs.String("file", "", "Backup file to protect")
+	remove := fs.Bool("remove", false, "Lift the protection instead")
+
+	fs.Parse(args)
+
+	if *file == "" {
+		fmt.Println("❌ Error: --file parameter is required")
+		os.Exit(1)
+	}
+
+	manager := backup.NewBackupManager(config.LoadBackupConfig(), config.DefaultConfig())
+	defer setCatalog(manager).Close()
+
+	if err := manager.ProtectBackup(context.Background(), *file, !*remove); err != nil {
+		if errors.Is(err, backup.ErrBackupNotFound) {
+			fmt.Printf("❌ %s is not in the backup catalog\n", *file)
+			fmt.Printf("Use '%s list' to see the backups\n", appName)
+		} else {
+			fmt.Printf("❌ Failed to change the protection of %s: %v\n", *file, err)
+		}
+		os.Exit(1)
+	}
+	if *remove {
+		fmt.Printf("✅ %s is no longer protected\n", *file)
+	} else {
+		fmt.Printf("✅ %s is protected from cleanup, pruning and deletion\n", *file)
+	}
+}
+
 func handleStatus(args []string) {
 	fs := flag.NewFlagSet("status", flag.ExitOnError)
 	detailed := fs.Bool("detailed", false, "Show detailed status")
 	format := fs.String("format", "table", "Output format: table, json")
+	verify := fs.Bool("verify", false, "Check every stored backup against its manifest")
 
 	fs.Parse(args)
 
@@ -407,6 +897,16 @@ func handleStatus(args []string) {
 	// Create simple monitor
 	monitor := backup.NewSimpleBackupMonitor(backupConfig)
 	monitor.UpdateMetrics()
+	if *verify {
+		monitor.SetVerifier(backup.NewBackupManager(backupConfig, config.DefaultConfig()))
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Hour)
+		_, err := monitor.VerifyBackups(ctx)
+		cancel()
+		if err != nil {
+			fmt.Printf("❌ Failed to verify backups: %v\n", err)
+			os.Exit(1)
+		}
+	}
 
 	status := monitor.GetStatusSummary()
 	health := monitor.CheckHealth()
@@ -417,6 +917,9 @@ func handleStatus(args []string) {
 			"status": status,
 			"health": health,
 		}
+		if verification := monitor.Verification(); verification != nil {
+			data["verification"] = verification
+		}
 		jsonData, _ := json.MarshalIndent(data, "", "  ")
 		fmt.Println(string(jsonData))
 	default:
@@ -445,11 +948,18 @@ func handleStatus(args []string) {
 		fmt.Printf("  Available Space: %s\n", formatBytes(status["available_space"].(int64)))
 		fmt.Printf("  Last Check: %s\n", status["last_check"].(time.Time).Format("2006-01-02 15:04:05"))
 
+		if verification := monitor.Verification(); verification != nil {
+			fmt.Printf("\nVerification:\n")
+			fmt.Printf("  Checked: %d\n", verification.Checked)
+			fmt.Printf("  Corrupted: %d\n", len(verification.Corrupted))
+			fmt.Printf("  Without Manifest: %d\n", len(verification.Unverified))
+		}
+
 		if *detailed {
 			fmt.Printf("\nConfiguration:\n")
 			fmt.Printf("  Backup Directory: %s\n", backupConfig.BackupDir)
 			fmt.Printf("  Auto Backup: %t\n", backupConfig.AutoBackupEnabled)
-			fmt.Printf("  Retention Days: %d\n", backupConfig.RetentionDays)
+			fmt.Printf("  Retention: %s\n", backup.NewRetentionPolicy(backupConfig))
 			fmt.Printf("  Compression: %t\n", backupConfig.CompressBackups)
 			fmt.Printf("  Encryption: %t\n", backupConfig.EncryptBackups)
 		}
@@ -486,69 +996,453 @@ func handleMonitor(args []string) {
 	}
 }
 
+func handleReadOnly(args []string) {
+	if len(args) == 0 {
+		fmt.Println("❌ Error: expected on, off or status")
+		os.Exit(1)
+	}
+	fs := flag.NewFlagSet("read-only", flag.ExitOnError)
+	reason := fs.String("reason", "database maintenance", "Reason shown while read-only")
+
+	fs.Parse(args[1:])
+
+	cfg := config.DefaultConfig()
+	mode := newReadOnlyMode(cfg)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var state readonly.State
+	var err error
+	switch args[0] {
+	case "on":
+		state, err = mode.Enable(ctx, *reason, appName)
+	case "off":
+		state, err = mode.Disable(ctx)
+	case "status":
+		state = mode.Status(ctx)
+	default:
+		fmt.Printf("❌ Unknown read-only command: %s (expected on, off or status)\n", args[0])
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Printf("❌ Failed to switch read-only mode: %v\n", err)
+		os.Exit(1)
+	}
+
+	if !state.Enabled {
+		fmt.Println("✅ API accepts writes")
+		return
+	}
+	fmt.Printf("🔒 API is read-only: %s\n", state.Reason)
+	if state.SetBy != "" {
+		fmt.Printf("   Set by: %s\n", state.SetBy)
+	}
+	if state.Since != nil {
+		fmt.Printf("   Since: %s\n", state.Since.Format("2006-01-02 15:04:05"))
+	}
+	if args[0] == "on" {
+		fmt.Printf("   Every instance refuses writes within %v\n", cfg.ReadOnlyRefreshInterval)
+	}
+}
+
+func handlePreMigrate(args []string) {
+	backupConfig := config.LoadBackupConfig()
+	fs := flag.NewFlagSet("pre-migrate", flag.ExitOnError)
+	target := fs.Uint("target", migrations.LatestVersion(), "Schema version the migrations go to")
+	skipBackup := fs.Bool("skip-backup", backupConfig.SkipMigrationBackup, "Migrate without a backup; default MIGRATION_SKIP_BACKUP")
+	timeout := fs.Duration("timeout", 60*time.Minute, "Time the backup may take")
+
+	fs.Parse(args)
+
+	if *skipBackup {
+		fmt.Println("⚠️  Migrating without a backup (--skip-backup)")
+		return
+	}
+
+	dbConfig := config.DefaultConfig()
+	manager := backup.NewBackupManager(backupConfig, dbConfig)
+	setAssetSigner(manager, dbConfig)
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	fmt.Printf("Backing up the database before migrating to schema version %d\n", *target)
+	result, err := manager.BackupBeforeMigration(ctx, *target)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		fmt.Println("Refusing to migrate. Use --skip-backup or MIGRATION_SKIP_BACKUP=true to migrate without a backup")
+		os.Exit(1)
+	}
+	if result == nil {
+		fmt.Println("✅ Nothing to back up, the schema is up to date or the database is empty")
+		return
+	}
+
+	fmt.Printf("✅ Backup created, safe to migrate\n")
+	fmt.Printf("   Filename: %s\n", result.Metadata.Filename)
+	fmt.Printf("   Schema version: %s\n", result.Metadata.SchemaVersion)
+	fmt.Printf("   Size: %s\n", formatBytes(result.Size))
+	fmt.Printf("   Duration: %v\n", result.Duration)
+	for _, warning := range result.Warnings {
+		fmt.Printf("   Warning: %s\n", warning)
+	}
+}
+
+func handleTools(args []string) {
+	fs := flag.NewFlagSet("tools", flag.ExitOnError)
+	format := fs.String("format", "table", "Output format: table, json")
+	timeout := fs.Duration("timeout", 5*time.Minute, "Time the checks may take, including pulling the image of BACKUP_TOOLS_IMAGE")
+
+	fs.Parse(args)
+
+	backupConfig := config.LoadBackupConfig()
+	manager := backup.NewBackupManager(backupConfig, config.DefaultConfig())
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	statuses, err := manager.CheckTools(ctx)
+	switch *format {
+	case "json":
+		output, _ := json.MarshalIndent(statuses, "", "  ")
+		fmt.Println(string(output))
+	default:
+		fmt.Printf("Database tools (%s mode)\n", manager.Tools().Mode())
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "TOOL\tVERSION\tPATH\tSTATUS")
+		for _, status := range statuses {
+			result := "✅"
+			if status.Error != "" {
+				result = "❌ " + status.Error
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", status.Tool, status.Version, status.Path, result)
+		}
+		w.Flush()
+	}
+	if err != nil {
+		os.Exit(1)
+	}
+}
+
+func handleAssets(args []string) {
+	fs := flag.NewFlagSet("assets", flag.ExitOnError)
+	file := fs.String("file", "", "Backup file")
+	extract := fs.String("extract", "", "Directory to extract the downloaded assets into")
+	format := fs.String("format", "table", "Output format: table, json")
+	timeout := fs.Duration("timeout", 30*time.Minute, "Time fetching the backup and its assets may take")
+
+	fs.Parse(args)
+
+	if *file == "" {
+		fmt.Println("❌ Backup file is required (--file)")
+		os.Exit(1)
+	}
+
+	manager := backup.NewBackupManager(config.LoadBackupConfig(), config.DefaultConfig())
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	var manifest *backup.AssetManifest
+	var err error
+	if *extract != "" {
+		if err := os.MkdirAll(*extract, 0755); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		manifest, err = manager.ExtractAssets(ctx, *file, *extract)
+	} else {
+		manifest, err = manager.Assets(ctx, *file)
+	}
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	switch *format {
+	case "json":
+		outputJSON(manifest)
+	default:
+		fmt.Printf("Media assets of %s: %d referenced, %d downloaded, %d failed\n",
+			manifest.Backup, len(manifest.Assets), manifest.Downloaded, manifest.Failed)
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ASSET\tCOLUMNS\tFILE\tSIZE\tSTATUS")
+		for _, asset := range manifest.Assets {
+			status, size := "", ""
+			if asset.File != "" {
+				status, size = "✅", formatBytes(asset.Size)
+			}
+			if asset.Error != "" {
+				status = "❌ " + asset.Error
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", asset.Ref, strings.Join(asset.Columns, ","), asset.File, size, status)
+		}
+		w.Flush()
+		if *extract != "" {
+			fmt.Printf("✅ Extracted %d assets into %s\n", manifest.Downloaded, *extract)
+		}
+	}
+}
+
+func handleExport(args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	tables := fs.String("tables", "", "Comma-separated tables to export (required unless --list)")
+	columns := fs.String("columns", "", "Comma-separated columns to export, of a single table; default all")
+	where := fs.String("where", "", "SQL condition the exported rows meet, e.g. \"created_at >= '2025-01-01'\"")
+	limit := fs.Int64("limit", 0, "Most rows exported per table; 0 exports all")
+	format := fs.String("format", backup.ExportCSV, "Output format: csv, ndjson")
+	out := fs.String("out", ".", "Directory the <table>.<format> files are written to, or - for standard output")
+	database := fs.String("database", config.PrimaryDatabase, "Database: primary or one of BACKUP_DATABASES")
+	list := fs.Bool("list", false, "List the tables that can be exported")
+	timeout := fs.Duration("timeout", 60*time.Minute, "Time the export may take")
+
+	fs.Parse(args)
+
+	if *format != backup.ExportCSV && *format != backup.ExportNDJSON {
+		fmt.Printf("❌ Invalid export format: %s (use csv or ndjson)\n", *format)
+		os.Exit(1)
+	}
+	names := splitList(*tables)
+	selected := splitList(*columns)
+	if !*list && len(names) == 0 {
+		fmt.Println("❌ Error: --tables parameter is required")
+		fmt.Printf("Use '%s export --list' to see the tables\n", appName)
+		os.Exit(1)
+	}
+	if len(selected) > 0 && len(names) > 1 {
+		fmt.Println("❌ Error: --columns selects the columns of a single table")
+		os.Exit(1)
+	}
+	if *out == "-" && len(names) > 1 {
+		fmt.Println("❌ Error: export one table at a time to standard output")
+		os.Exit(1)
+	}
+
+	dbConfig := config.DefaultConfig()
+	if *database != config.PrimaryDatabase {
+		backupConfig := config.LoadBackupConfig()
+		target, ok := backupConfig.Database(*database)
+		if !ok {
+			fmt.Printf("❌ %s: %v (use %s)\n", *database, backup.ErrUnknownDatabase, strings.Join(backup.DatabaseNames(backupConfig), ", "))
+			os.Exit(1)
+		}
+		dbConfig = target.Connection(dbConfig)
+	}
+	conn, err := sql.Open(dbConfig.DBDriver, dbConfig.DBSource)
+	if err != nil {
+		fmt.Printf("❌ Failed to open database: %v\n", err)
+		os.Exit(1)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	if *list {
+		available, err := backup.ExportableTables(ctx, conn)
+		if err != nil {
+			fmt.Printf("❌ Failed to list tables: %v\n", err)
+			os.Exit(1)
+		}
+		for _, table := range available {
+			fmt.Println(table)
+		}
+		return
+	}
+
+	// Progress goes to standard error while the rows go to standard output
+	status := os.Stdout
+	if *out == "-" {
+		status = os.Stderr
+	} else if err := os.MkdirAll(*out, 0755); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	for _, table := range names {
+		options := backup.TableExport{Table: table, Columns: selected, Where: *where, Limit: *limit}
+		start := time.Now()
+		var count int64
+		var path string
+		if *out == "-" {
+			count, err = backup.ExportTable(ctx, conn, options, *format, os.Stdout)
+		} else {
+			path = filepath.Join(*out, table+"."+*format)
+			count, err = exportTableFile(ctx, conn, options, *format, path)
+		}
+		if err != nil {
+			fmt.Fprintf(status, "❌ Export of %s failed: %v\n", table, err)
+			os.Exit(1)
+		}
+		if path != "" {
+			fmt.Fprintf(status, "✅ %s: %d rows to %s in %v\n", table, count, path, time.Since(start).Round(time.Millisecond))
+		} else {
+			fmt.Fprintf(status, "✅ %s: %d rows in %v\n", table, count, time.Since(start).Round(time.Millisecond))
+		}
+	}
+}
+
+// exportTableFile exports a table into the file at path, removing it when
+// the export fails
+func exportTableFile(ctx context.Context, conn *sql.DB, options backup.TableExport, format, path string) (int64, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	count, err := backup.ExportTable(ctx, conn, options, format, file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+	}
+	return count, err
+}
+
+// splitList returns the non-empty items of a comma-separated flag
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 // Helper types and functions
 
+// newDatabaseManager creates the manager of the database backed up under
+// name, exiting when it is not configured
+func newDatabaseManager(backupConfig config.BackupConfig, dbConfig config.Config, name string) *backup.BackupManager {
+	manager, err := backup.NewDatabaseManager(backupConfig, dbConfig, name)
+	if err != nil {
+		fmt.Printf("❌ %s: %v (use %s)\n", name, err, strings.Join(backup.DatabaseNames(backupConfig), ", "))
+		os.Exit(1)
+	}
+	return manager
+}
+
+// setCatalog makes manager use the backup catalog of the application
+// database and returns the connection to it
+func setCatalog(manager *backup.BackupManager) *sql.DB {
+	cfg := config.DefaultConfig()
+	conn, err := sql.Open(cfg.DBDriver, cfg.DBSource)
+	if err == nil {
+		err = conn.Ping()
+	}
+	if err != nil {
+		fmt.Printf("❌ Failed to open the backup catalog: %v\n", err)
+		os.Exit(1)
+	}
+	manager.SetCatalog(backup.NewCatalog(db.New(conn)))
+	return conn
+}
+
+// setAssetSigner lets manager download the private media assets of
+// Cloudinary when it is configured
+func setAssetSigner(manager *backup.BackupManager, cfg config.Config) {
+	if cfg.CloudinaryURL == "" {
+		return
+	}
+	signer, err := uploader.NewCloudinaryUploader(cfg)
+	if err != nil {
+		fmt.Printf("⚠️  Private media assets will not be downloaded: %v\n", err)
+		return
+	}
+	manager.SetAssetSigner(signer)
+}
+
+// newReadOnlyMode opens the read-only switch the API instances share
+func newReadOnlyMode(cfg config.Config) *readonly.Mode {
+	return readonly.NewMode(readonly.NewStore(cfg), func() bool { return cfg.ReadOnlyMode }, 0)
+}
+
 type BackupInfo struct {
 	Name    string
 	Size    int64
 	ModTime time.Time
+	// From the metadata of the backup; SchemaVersion is the migration
+	// applied when it was taken, MigrationTarget the version a migration
+	// backup was taken before migrating to
+	Type            string `json:",omitempty"`
+	SchemaVersion   string `json:",omitempty"`
+	MigrationTarget string `json:",omitempty"`
 }
 
-func scanBackupDirectory(dir string) ([]BackupInfo, error) {
-	var backups []BackupInfo
-
-	if _, err := os.Stat(dir); os.IsNotExist(err) {
-		return backups, nil
-	}
+// listBackups lists the backups in the configured storage, which is the
+// backup directory or a remote bucket
+func listBackups(manager *backup.BackupManager) ([]BackupInfo, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
 
-	files, err := os.ReadDir(dir)
+	files, err := manager.ListBackups(ctx)
 	if err != nil {
 		return nil, err
 	}
 
+	backups := make([]BackupInfo, 0, len(files))
 	for _, file := range files {
-		if file.IsDir() {
-			continue
-		}
+		backups = append(backups, BackupInfo{
+			Name:    file.Name,
+			Size:    file.Size,
+			ModTime: file.ModTime,
+		})
+	}
+	return backups, nil
+}
 
-		ext := filepath.Ext(file.Name())
-		if ext != ".sql" && ext != ".gz" && ext != ".enc" {
-			continue
-		}
+// describeBackups reads the type and schema version of the backups from
+// their metadata
+func describeBackups(manager *backup.BackupManager, backups []BackupInfo) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
 
-		fileInfo, err := file.Info()
-		if err == nil {
-			backups = append(backups, BackupInfo{
-				Name:    file.Name(),
-				Size:    fileInfo.Size(),
-				ModTime: fileInfo.ModTime(),
-			})
+	for i := range backups {
+		metadata, err := manager.Metadata(ctx, backups[i].Name)
+		if err != nil {
+			continue
 		}
+		backups[i].Type = metadata.Type
+		backups[i].SchemaVersion = metadata.SchemaVersion
+		backups[i].MigrationTarget = metadata.MigrationTarget
 	}
+}
 
-	return backups, nil
+// schemaColumn shows the schema version of a backup, and the version a
+// migration backup was taken before migrating to
+func schemaColumn(backup BackupInfo) string {
+	switch {
+	case backup.MigrationTarget != "":
+		return backup.SchemaVersion + " -> " + backup.MigrationTarget
+	case backup.SchemaVersion != "":
+		return backup.SchemaVersion
+	default:
+		return "-"
+	}
 }
 
 func outputTable(backups []BackupInfo, verbose bool) {
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
 
 	if verbose {
-		fmt.Fprintln(w, "NAME\tSIZE\tCREATED\tAGE")
+		fmt.Fprintln(w, "NAME\tTYPE\tSCHEMA\tSIZE\tCREATED\tAGE")
 	} else {
-		fmt.Fprintln(w, "NAME\tSIZE\tCREATED")
+		fmt.Fprintln(w, "NAME\tTYPE\tSCHEMA\tSIZE\tCREATED")
 	}
 
 	for _, backup := range backups {
 		if verbose {
 			age := time.Since(backup.ModTime)
-			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
 				backup.Name,
+				backup.Type,
+				schemaColumn(backup),
 				formatBytes(backup.Size),
 				backup.ModTime.Format("2006-01-02 15:04"),
 				formatDuration(age))
 		} else {
-			fmt.Fprintf(w, "%s\t%s\t%s\n",
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
 				backup.Name,
+				backup.Type,
+				schemaColumn(backup),
 				formatBytes(backup.Size),
 				backup.ModTime.Format("2006-01-02 15:04"))
 		}
@@ -557,8 +1451,8 @@ func outputTable(backups []BackupInfo, verbose bool) {
 	w.Flush()
 }
 
-func outputJSON(backups []BackupInfo) {
-	jsonData, err := json.MarshalIndent(backups, "", "  ")
+func outputJSON(value interface{}) {
+	jsonData, err := json.MarshalIndent(value, "", "  ")
 	if err != nil {
 		fmt.Printf("❌ Error formatting JSON: %v\n", err)
 		return
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/toeic-app/internal/config"
+	"github.com/toeic-app/internal/sanitize"
+)
+
+const appName = "content-sanitizer"
+
+// content-sanitizer re-applies the configured sanitization policies to
+// content already stored in the database. Run it after tightening
+// SANITIZE_RICH_TEXT_POLICY or SANITIZE_MARKDOWN_POLICY.
+func main() {
+	dryRun := flag.Bool("dry-run", true, "Report rows that would change without updating them")
+	tables := flag.String("tables", "", "Comma-separated tables to process (default: all)")
+	batchSize := flag.Int("batch-size", 500, "Rows read per query")
+	timeout := flag.Duration("timeout", 30*time.Minute, "Maximum run time")
+	flag.Usage = func() {
+		fmt.Printf("%s - Re-sanitize stored rich text content\n\nUSAGE:\n    %s [options]\n\nOPTIONS:\n", appName, appName)
+		flag.PrintDefaults()
+		fmt.Printf("\nEXAMPLES:\n    %s --dry-run\n    %s --dry-run=false --tables grammars,writing_prompts\n\n", appName, appName)
+	}
+	flag.Parse()
+
+	targets, err := selectTargets(*tables)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	cfg := config.DefaultConfig()
+	conn, err := sql.Open(cfg.DBDriver, cfg.DBSource)
+	if err != nil {
+		fmt.Printf("❌ Failed to open database: %v\n", err)
+		os.Exit(1)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	if err := conn.PingContext(ctx); err != nil {
+		fmt.Printf("❌ Failed to connect to database: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *dryRun {
+		fmt.Println("🔍 Dry run: no rows will be updated (use --dry-run=false to apply)")
+	}
+	fmt.Printf("Policies: rich_text=%s markdown=%s\n", cfg.SanitizeRichTextPolicy, cfg.SanitizeMarkdownPolicy)
+
+	sanitizer := sanitize.New(cfg)
+	reports, err := sanitizer.Resanitize(ctx, conn, targets, sanitize.ResanitizeOptions{
+		DryRun:    *dryRun,
+		BatchSize: *batchSize,
+	})
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TABLE\tSCANNED\tCHANGED\tFAILED")
+	for _, report := range reports {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\n", report.Table, report.Scanned, report.Changed, report.Failed)
+	}
+	w.Flush()
+
+	if err != nil {
+		fmt.Printf("❌ Re-sanitization failed: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println("✅ Re-sanitization completed")
+}
+
+// selectTargets filters the known targets by table name
+func selectTargets(tables string) ([]sanitize.Target, error) {
+	if strings.TrimSpace(tables) == "" {
+		return sanitize.Targets, nil
+	}
+
+	var selected []sanitize.Target
+	for _, name := range strings.Split(tables, ",") {
+		name = strings.TrimSpace(name)
+		found := false
+		for _, target := range sanitize.Targets {
+			if target.Table == name {
+				selected = append(selected, target)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown table: %s", name)
+		}
+	}
+	return selected, nil
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/toeic-app/internal/config"
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/pii"
+)
+
+const appName = "pii-admin"
+
+func main() {
+	if len(os.Args) < 2 {
+		showUsage()
+		os.Exit(1)
+	}
+
+	command := os.Args[1]
+	args := os.Args[2:]
+
+	switch command {
+	case "status":
+		handleStatus(args)
+	case pii.ModeBackfill, pii.ModeRotate, pii.ModeDecrypt:
+		handleMigrate(command, args)
+	case "help", "-h", "--help":
+		showUsage()
+	default:
+		fmt.Printf("Unknown command: %s\n\n", command)
+		showUsage()
+		os.Exit(1)
+	}
+}
+
+func showUsage() {
+	fmt.Printf(`%s - PII encryption administration tool
+
+USAGE:
+    %s <command> [options]
+
+COMMANDS:
+    status      Count plaintext and encrypted values per key
+    backfill    Encrypt PII that is still stored in plaintext
+    rotate      Re-wrap data keys with PII_ACTIVE_KEY_ID and refresh blind indexes
+    decrypt     Restore plaintext values (run before rolling back migration 000023)
+    help        Show this help message
+
+OPTIONS (backfill, rotate, decrypt):
+    --dry-run       Report rows that would change without updating them (default true)
+    --batch-size    Rows read per query (default 500)
+    --timeout       Maximum run time (default 30m)
+
+EXAMPLES:
+    %s status
+    %s backfill --dry-run=false
+    %s rotate --dry-run=false
+
+`, appName, appName, appName, appName, appName)
+}
+
+// openQuerier connects to the configured database
+func openQuerier(ctx context.Context, cfg config.Config) (*sql.DB, db.Querier) {
+	conn, err := sql.Open(cfg.DBDriver, cfg.DBSource)
+	if err != nil {
+		fmt.Printf("❌ Failed to open database: %v\n", err)
+		os.Exit(1)
+	}
+	if err := conn.PingContext(ctx); err != nil {
+		fmt.Printf("❌ Failed to connect to database: %v\n", err)
+		os.Exit(1)
+	}
+	return conn, db.New(conn)
+}
+
+func handleStatus(args []string) {
+	fs := flag.NewFlagSet("status", flag.ExitOnError)
+	batchSize := fs.Int("batch-size", 500, "Rows read per query")
+	fs.Parse(args)
+
+	cfg := config.DefaultConfig()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	conn, querier := openQuerier(ctx, cfg)
+	defer conn.Close()
+
+	statuses, err := pii.Status(ctx, querier, *batchSize)
+	if err != nil {
+		fmt.Printf("❌ Failed to read PII status: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Active key: %s\n", cfg.PIIActiveKeyID)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TABLE\tPLAINTEXT\tENCRYPTED BY KEY")
+	for _, status := range statuses {
+		keyIDs := make([]string, 0, len(status.ByKey))
+		for keyID := range status.ByKey {
+			keyIDs = append(keyIDs, keyID)
+		}
+		sort.Strings(keyIDs)
+
+		counts := make([]string, 0, len(keyIDs))
+		for _, keyID := range keyIDs {
+			counts = append(counts, fmt.Sprintf("%s=%d", keyID, status.ByKey[keyID]))
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\n", status.Table, status.Plaintext, strings.Join(counts, " "))
+	}
+	w.Flush()
+}
+
+func handleMigrate(mode string, args []string) {
+	fs := flag.NewFlagSet(mode, flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", true, "Report rows that would change without updating them")
+	batchSize := fs.Int("batch-size", 500, "Rows read per query")
+	timeout := fs.Duration("timeout", 30*time.Minute, "Maximum run time")
+	fs.Parse(args)
+
+	cfg := config.DefaultConfig()
+	protector, err := pii.NewProtector(cfg)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	if protector == nil {
+		fmt.Println("❌ PII_ENCRYPTION_KEYS is not configured")
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	conn, querier := openQuerier(ctx, cfg)
+	defer conn.Close()
+
+	if *dryRun {
+		fmt.Println("🔍 Dry run: no rows will be updated (use --dry-run=false to apply)")
+	}
+	fmt.Printf("Running %s with active key %s\n", mode, protector.ActiveKeyID())
+
+	reports, err := protector.Migrate(ctx, querier, pii.MigrateOptions{
+		Mode:      mode,
+		DryRun:    *dryRun,
+		BatchSize: *batchSize,
+	})
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TABLE\tSCANNED\tCHANGED\tFAILED")
+	for _, report := range reports {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\n", report.Table, report.Scanned, report.Changed, report.Failed)
+	}
+	w.Flush()
+
+	if err != nil {
+		fmt.Printf("❌ PII %s failed: %v\n", mode, err)
+		os.Exit(1)
+	}
+	fmt.Printf("✅ PII %s completed\n", mode)
+}
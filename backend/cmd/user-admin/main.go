@@ -0,0 +1,580 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/toeic-app/internal/account"
+	"github.com/toeic-app/internal/config"
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/pii"
+	"github.com/toeic-app/internal/rbac"
+	"github.com/toeic-app/internal/util"
+)
+
+const appName = "user-admin"
+
+// actorEnv names the default acting administrator
+const actorEnv = "USER_ADMIN_ACTOR"
+
+func main() {
+	if len(os.Args) < 2 {
+		showUsage()
+		os.Exit(1)
+	}
+
+	command := os.Args[1]
+	args := os.Args[2:]
+
+	switch command {
+	case "create-admin":
+		handleCreateAdmin(args)
+	case "reset-password":
+		handleResetPassword(args)
+	case "roles":
+		handleRoles(args)
+	case "lock":
+		handleLock(args)
+	case "unlock":
+		handleUnlock(args)
+	case "info":
+		handleInfo(args)
+	case "sessions":
+		handleSessions(args)
+	case "help", "-h", "--help":
+		showUsage()
+	default:
+		fmt.Printf("Unknown command: %s\n\n", command)
+		showUsage()
+		os.Exit(1)
+	}
+}
+
+func showUsage() {
+	fmt.Printf(`%s - User account administration tool
+
+USAGE:
+    %s <command> [options]
+
+COMMANDS:
+    create-admin      Create a user and give them the admin role
+    reset-password    Set a new password for a user
+    roles             List, assign or remove the roles of a user
+    lock              Block sign-in and token refresh of a user
+    unlock            Lift the lock and clear failed sign-ins of a user
+    info              Show a user with their roles and lock state
+    sessions          List the sign-in sessions of a user
+    help              Show this help message
+
+Every command runs as --actor (an email or user ID, default $%s) and needs
+the same permissions as the admin API:
+    create-admin      users.create and roles.assign
+    reset-password    users.update
+    roles             users.read to list, roles.assign to change
+    lock, unlock      users.update
+    info, sessions    users.read
+create-admin runs without an actor only while no user has the admin role.
+
+OPTIONS:
+    --user              Email or ID of the user to manage
+    --actor             Email or ID of the administrator running the command
+    --password-stdin    Read the password from stdin instead of generating one
+                        (create-admin, reset-password)
+    --username          Username of the new admin (create-admin)
+    --email             Email of the new admin (create-admin)
+    --role              Role of the new user (create-admin, default admin)
+    --assign, --remove  Role to assign or remove (roles)
+    --expires           Lifetime of an assigned role, e.g. 720h (roles)
+    --duration          Lock duration (lock, default 24h)
+    --reason            Lock reason (lock)
+    --all               Include ended and expired sessions (sessions)
+    --limit             Maximum sessions (sessions, default 20)
+
+EXAMPLES:
+    %s create-admin --username ops --email ops@example.com
+    %s reset-password --user jane@example.com --actor ops@example.com --password-stdin < password.txt
+    %s roles --user 42 --assign teacher --expires 720h --actor 1
+    %s unlock --user jane@example.com --actor ops@example.com
+    %s sessions --user jane@example.com --all --actor ops@example.com
+
+`, appName, appName, actorEnv, appName, appName, appName, appName, appName)
+}
+
+// tool holds the stores every command works with
+type tool struct {
+	conn     *sql.DB
+	store    db.Querier
+	rbac     *rbac.Service
+	accounts *account.Service
+}
+
+// openTool connects to the configured database. User reads and writes go
+// through the PII store so emails are encrypted like the API does.
+func openTool(ctx context.Context) *tool {
+	cfg := config.DefaultConfig()
+	conn, err := sql.Open(cfg.DBDriver, cfg.DBSource)
+	if err != nil {
+		fmt.Printf("❌ Failed to open database: %v\n", err)
+		os.Exit(1)
+	}
+	if err := conn.PingContext(ctx); err != nil {
+		fmt.Printf("❌ Failed to connect to database: %v\n", err)
+		os.Exit(1)
+	}
+	store, err := pii.WrapStore(cfg, db.New(conn))
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	return &tool{
+		conn:     conn,
+		store:    store,
+		rbac:     rbac.NewService(store),
+		accounts: account.NewService(store, cfg.AccountLockoutThreshold, cfg.AccountLockoutDuration, time.Duration(cfg.RefreshTokenDuration)*time.Second),
+	}
+}
+
+// findUser looks a user up by ID or email
+func (t *tool) findUser(ctx context.Context, ref string) (db.User, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return db.User{}, errors.New("no user given")
+	}
+	var user db.User
+	var err error
+	if id, convErr := strconv.ParseInt(ref, 10, 32); convErr == nil {
+		user, err = t.store.GetUser(ctx, int32(id))
+	} else {
+		user, err = t.store.GetUserByEmail(ctx, sql.NullString{String: ref, Valid: true})
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return db.User{}, fmt.Errorf("user %s not found", ref)
+	}
+	if err != nil {
+		return db.User{}, fmt.Errorf("failed to find user %s: %w", ref, err)
+	}
+	return user, nil
+}
+
+// authorize resolves the actor and checks they hold every permission
+func (t *tool) authorize(ctx context.Context, ref string, permissions ...string) (db.User, error) {
+	if ref == "" {
+		ref = os.Getenv(actorEnv)
+	}
+	if ref == "" {
+		return db.User{}, fmt.Errorf("no actor given; use --actor or set %s", actorEnv)
+	}
+	actor, err := t.findUser(ctx, ref)
+	if err != nil {
+		return db.User{}, fmt.Errorf("actor: %w", err)
+	}
+	for _, permission := range permissions {
+		check, err := t.rbac.CheckPermission(ctx, actor.ID, permission)
+		if err != nil {
+			return db.User{}, err
+		}
+		if !check.HasPermission {
+			return db.User{}, fmt.Errorf("%s (user %d) lacks permission %s", actor.Username, actor.ID, permission)
+		}
+	}
+	return actor, nil
+}
+
+func (t *tool) mustFindUser(ctx context.Context, ref string) db.User {
+	user, err := t.findUser(ctx, ref)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	return user
+}
+
+func (t *tool) mustAuthorize(ctx context.Context, ref string, permissions ...string) db.User {
+	actor, err := t.authorize(ctx, ref, permissions...)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	return actor
+}
+
+// readPassword reads a password from stdin, or generates one when
+// fromStdin is false. Generated passwords are printed once.
+func readPassword(fromStdin bool) string {
+	if !fromStdin {
+		password, err := generatePassword(20)
+		if err != nil {
+			fmt.Printf("❌ Failed to generate password: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("🔑 Generated password (shown once): %s\n", password)
+		return password
+	}
+
+	password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && password == "" {
+		fmt.Printf("❌ Failed to read password from stdin: %v\n", err)
+		os.Exit(1)
+	}
+	password = strings.TrimRight(password, "\r\n")
+	if !util.IsStrongPassword(password) {
+		fmt.Println("❌ Password must have at least 8 characters including uppercase, lowercase, numbers and special characters")
+		os.Exit(1)
+	}
+	return password
+}
+
+// generatePassword returns a random password meeting util.IsStrongPassword
+func generatePassword(length int) (string, error) {
+	classes := []string{"ABCDEFGHJKLMNPQRSTUVWXYZ", "abcdefghijkmnopqrstuvwxyz", "23456789", "!#%+-=?@^_"}
+	all := strings.Join(classes, "")
+	password := make([]byte, length)
+	for i := range password {
+		// The first characters cover every class
+		charset := all
+		if i < len(classes) {
+			charset = classes[i]
+		}
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(charset))))
+		if err != nil {
+			return "", err
+		}
+		password[i] = charset[n.Int64()]
+	}
+	// Shuffle so the class characters are not always first
+	for i := len(password) - 1; i > 0; i-- {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return "", err
+		}
+		j := n.Int64()
+		password[i], password[j] = password[j], password[i]
+	}
+	return string(password), nil
+}
+
+func handleCreateAdmin(args []string) {
+	fs := flag.NewFlagSet("create-admin", flag.ExitOnError)
+	username := fs.String("username", "", "Username of the new admin")
+	email := fs.String("email", "", "Email of the new admin")
+	role := fs.String("role", rbac.RoleAdmin, "Role of the new user")
+	passwordStdin := fs.Bool("password-stdin", false, "Read the password from stdin")
+	actorRef := fs.String("actor", "", "Email or ID of the administrator running the command")
+	fs.Parse(args)
+
+	if len(*username) < 3 || len(*username) > 50 {
+		fmt.Println("❌ --username must have 3 to 50 characters")
+		os.Exit(1)
+	}
+	if !util.IsValidEmail(*email) {
+		fmt.Println("❌ --email is not a valid email")
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	t := openTool(ctx)
+	defer t.conn.Close()
+
+	roleRecord, err := t.rbac.GetRoleByName(ctx, *role)
+	if err != nil {
+		fmt.Printf("❌ Role %s not found: %v\n", *role, err)
+		os.Exit(1)
+	}
+
+	// The first admin is created without an actor
+	var assignedBy int32
+	admins, err := t.rbac.GetUsersByRole(ctx, rbac.RoleAdmin)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	if len(admins) > 0 || *actorRef != "" || os.Getenv(actorEnv) != "" {
+		actor := t.mustAuthorize(ctx, *actorRef, rbac.PermUserCreate, rbac.PermRoleAssign)
+		assignedBy = actor.ID
+	} else {
+		fmt.Println("No admin exists yet: creating the first one without an actor")
+	}
+
+	if _, err := t.findUser(ctx, *email); err == nil {
+		fmt.Printf("❌ Email %s is already registered\n", *email)
+		os.Exit(1)
+	}
+
+	hash, err := util.HashPassword(readPassword(*passwordStdin))
+	if err != nil {
+		fmt.Printf("❌ Failed to hash password: %v\n", err)
+		os.Exit(1)
+	}
+	user, err := t.store.CreateUser(ctx, db.CreateUserParams{
+		Username:     *username,
+		Email:        sql.NullString{String: *email, Valid: true},
+		PasswordHash: hash,
+	})
+	if err != nil {
+		fmt.Printf("❌ Failed to create user: %v\n", err)
+		os.Exit(1)
+	}
+	if err := t.rbac.AssignRole(ctx, user.ID, roleRecord.ID, assignedBy, nil); err != nil {
+		fmt.Printf("❌ User %d was created but the role was not assigned: %v\n", user.ID, err)
+		os.Exit(1)
+	}
+	fmt.Printf("✅ Created user %d (%s) with role %s\n", user.ID, user.Username, roleRecord.Name)
+}
+
+func handleResetPassword(args []string) {
+	fs := flag.NewFlagSet("reset-password", flag.ExitOnError)
+	userRef := fs.String("user", "", "Email or ID of the user")
+	passwordStdin := fs.Bool("password-stdin", false, "Read the password from stdin")
+	actorRef := fs.String("actor", "", "Email or ID of the administrator running the command")
+	fs.Parse(args)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	t := openTool(ctx)
+	defer t.conn.Close()
+
+	t.mustAuthorize(ctx, *actorRef, rbac.PermUserUpdate)
+	user := t.mustFindUser(ctx, *userRef)
+
+	hash, err := util.HashPassword(readPassword(*passwordStdin))
+	if err != nil {
+		fmt.Printf("❌ Failed to hash password: %v\n", err)
+		os.Exit(1)
+	}
+	if _, err := t.store.UpdateUser(ctx, db.UpdateUserParams{
+		ID:           user.ID,
+		Username:     user.Username,
+		Email:        user.Email,
+		PasswordHash: hash,
+	}); err != nil {
+		fmt.Printf("❌ Failed to update password: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("✅ Password of user %d (%s) reset\n", user.ID, user.Username)
+	fmt.Println("Tokens already issued stay valid until they expire; lock the account to block token refresh.")
+}
+
+func handleRoles(args []string) {
+	fs := flag.NewFlagSet("roles", flag.ExitOnError)
+	userRef := fs.String("user", "", "Email or ID of the user")
+	assign := fs.String("assign", "", "Role to assign")
+	remove := fs.String("remove", "", "Role to remove")
+	expires := fs.Duration("expires", 0, "Lifetime of an assigned role (0 keeps it forever)")
+	actorRef := fs.String("actor", "", "Email or ID of the administrator running the command")
+	fs.Parse(args)
+
+	if *assign != "" && *remove != "" {
+		fmt.Println("❌ Use either --assign or --remove")
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	t := openTool(ctx)
+	defer t.conn.Close()
+
+	switch {
+	case *assign != "":
+		actor := t.mustAuthorize(ctx, *actorRef, rbac.PermRoleAssign)
+		user := t.mustFindUser(ctx, *userRef)
+		role, err := t.rbac.GetRoleByName(ctx, *assign)
+		if err != nil {
+			fmt.Printf("❌ Role %s not found: %v\n", *assign, err)
+			os.Exit(1)
+		}
+		var expiresAt *time.Time
+		if *expires > 0 {
+			at := time.Now().Add(*expires)
+			expiresAt = &at
+		}
+		if err := t.rbac.AssignRole(ctx, user.ID, role.ID, actor.ID, expiresAt); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ Assigned role %s to user %d (%s)\n", role.Name, user.ID, user.Username)
+	case *remove != "":
+		t.mustAuthorize(ctx, *actorRef, rbac.PermRoleAssign)
+		user := t.mustFindUser(ctx, *userRef)
+		role, err := t.rbac.GetRoleByName(ctx, *remove)
+		if err != nil {
+			fmt.Printf("❌ Role %s not found: %v\n", *remove, err)
+			os.Exit(1)
+		}
+		if err := t.rbac.RemoveRole(ctx, user.ID, role.ID); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ Removed role %s from user %d (%s)\n", role.Name, user.ID, user.Username)
+	default:
+		t.mustAuthorize(ctx, *actorRef, rbac.PermUserRead)
+		user := t.mustFindUser(ctx, *userRef)
+		t.printRoles(ctx, user)
+	}
+}
+
+func (t *tool) printRoles(ctx context.Context, user db.User) {
+	assignments, err := t.rbac.GetUserRoleAssignments(ctx, user.ID)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	if len(assignments) == 0 {
+		fmt.Println("No roles assigned")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ROLE\tASSIGNED AT\tASSIGNED BY\tEXPIRES AT")
+	for _, assignment := range assignments {
+		assignedBy, expiresAt := "-", "never"
+		if assignment.AssignedBy != nil {
+			assignedBy = strconv.Itoa(int(*assignment.AssignedBy))
+		}
+		if assignment.ExpiresAt != nil {
+			expiresAt = assignment.ExpiresAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", assignment.RoleName, assignment.AssignedAt.Format(time.RFC3339), assignedBy, expiresAt)
+	}
+	w.Flush()
+}
+
+func handleLock(args []string) {
+	fs := flag.NewFlagSet("lock", flag.ExitOnError)
+	userRef := fs.String("user", "", "Email or ID of the user")
+	duration := fs.Duration("duration", 24*time.Hour, "Lock duration")
+	reason := fs.String("reason", "", "Lock reason")
+	actorRef := fs.String("actor", "", "Email or ID of the administrator running the command")
+	fs.Parse(args)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	t := openTool(ctx)
+	defer t.conn.Close()
+
+	actor := t.mustAuthorize(ctx, *actorRef, rbac.PermUserUpdate)
+	user := t.mustFindUser(ctx, *userRef)
+	if user.ID == actor.ID {
+		fmt.Println("❌ You cannot lock your own account")
+		os.Exit(1)
+	}
+
+	status, err := t.accounts.Lock(ctx, user.ID, time.Now().Add(*duration), *reason)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("✅ User %d (%s) locked until %s\n", user.ID, user.Username, status.LockedUntil.Format(time.RFC3339))
+}
+
+func handleUnlock(args []string) {
+	fs := flag.NewFlagSet("unlock", flag.ExitOnError)
+	userRef := fs.String("user", "", "Email or ID of the user")
+	actorRef := fs.String("actor", "", "Email or ID of the administrator running the command")
+	fs.Parse(args)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	t := openTool(ctx)
+	defer t.conn.Close()
+
+	t.mustAuthorize(ctx, *actorRef, rbac.PermUserUpdate)
+	user := t.mustFindUser(ctx, *userRef)
+
+	cleared, err := t.accounts.Unlock(ctx, user.ID)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	if !cleared {
+		fmt.Printf("User %d (%s) was not locked\n", user.ID, user.Username)
+		return
+	}
+	fmt.Printf("✅ User %d (%s) unlocked\n", user.ID, user.Username)
+}
+
+func handleInfo(args []string) {
+	fs := flag.NewFlagSet("info", flag.ExitOnError)
+	userRef := fs.String("user", "", "Email or ID of the user")
+	actorRef := fs.String("actor", "", "Email or ID of the administrator running the command")
+	fs.Parse(args)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	t := openTool(ctx)
+	defer t.conn.Close()
+
+	t.mustAuthorize(ctx, *actorRef, rbac.PermUserRead)
+	user := t.mustFindUser(ctx, *userRef)
+	status, err := t.accounts.Status(ctx, user.ID)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("ID:               %d\n", user.ID)
+	fmt.Printf("Username:         %s\n", user.Username)
+	fmt.Printf("Email:            %s\n", user.Email.String)
+	fmt.Printf("Created at:       %s\n", user.CreatedAt.Format(time.RFC3339))
+	fmt.Printf("Failed sign-ins:  %d\n", status.FailedAttempts)
+	if status.Locked(time.Now()) {
+		fmt.Printf("Locked until:     %s (%s)\n", status.LockedUntil.Format(time.RFC3339), status.Reason)
+	} else {
+		fmt.Println("Locked:           no")
+	}
+	fmt.Println()
+	t.printRoles(ctx, user)
+}
+
+func handleSessions(args []string) {
+	fs := flag.NewFlagSet("sessions", flag.ExitOnError)
+	userRef := fs.String("user", "", "Email or ID of the user")
+	all := fs.Bool("all", false, "Include ended and expired sessions")
+	limit := fs.Int("limit", account.DefaultSessionLimit, "Maximum sessions")
+	actorRef := fs.String("actor", "", "Email or ID of the administrator running the command")
+	fs.Parse(args)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	t := openTool(ctx)
+	defer t.conn.Close()
+
+	t.mustAuthorize(ctx, *actorRef, rbac.PermUserRead)
+	user := t.mustFindUser(ctx, *userRef)
+	sessions, err := t.accounts.Sessions(ctx, user.ID, *all, *limit)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	if len(sessions) == 0 {
+		fmt.Printf("No sessions for user %d (%s)\n", user.ID, user.Username)
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSTATE\tIP\tSTARTED\tLAST SEEN\tEXPIRES\tUSER AGENT")
+	for _, session := range sessions {
+		state := "active"
+		switch {
+		case session.EndedAt != nil:
+			state = "signed out"
+		case !session.Active:
+			state = "expired"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", session.ID, state, session.IPAddress,
+			session.CreatedAt.Format(time.RFC3339), session.LastSeenAt.Format(time.RFC3339),
+			session.ExpiresAt.Format(time.RFC3339), session.UserAgent)
+	}
+	w.Flush()
+}
@@ -0,0 +1,248 @@
+// Package accesslog records when staff read the personal data of another
+// user, such as writings, speaking sessions and exam answers, so the user
+// can see who looked at their data and why.
+package accesslog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/logger"
+)
+
+// Purposes of a read, sent by clients in the X-Access-Purpose header
+const (
+	PurposeSupport     = "support"
+	PurposeGrading     = "grading"
+	PurposeModeration  = "moderation"
+	PurposeLegal       = "legal"
+	PurposeUnspecified = "unspecified"
+)
+
+// Kinds of personal data whose reads are recorded
+const (
+	ResourceWriting          = "writing"
+	ResourceSpeakingSession  = "speaking_session"
+	ResourceSpeakingTurn     = "speaking_turn"
+	ResourceExamAnswers      = "exam_answers"
+	ResourceExamAttempt      = "exam_attempt"
+	ResourceIntegrityEvents  = "integrity_events"
+	ResourceProctoringPhotos = "proctoring_photos"
+)
+
+// DefaultLimit is the number of entries listed by default
+const DefaultLimit = 20
+
+var ErrUnknownPurpose = errors.New("unknown access purpose")
+
+// Purposes lists the valid purpose codes
+func Purposes() []string {
+	return []string{PurposeSupport, PurposeGrading, PurposeModeration, PurposeLegal, PurposeUnspecified}
+}
+
+// ValidPurpose reports whether purpose is a known purpose code
+func ValidPurpose(purpose string) bool {
+	for _, known := range Purposes() {
+		if purpose == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Access is a read of one resource owned by SubjectID
+type Access struct {
+	SubjectID    int32
+	ResourceType string
+	ResourceID   int32
+}
+
+// Entry is a recorded read, as shown to the user whose data was read
+type Entry struct {
+	ID int64 `json:"id"`
+	// AccessorID is empty once the reading account has been deleted
+	AccessorID       *int32    `json:"accessor_id,omitempty"`
+	AccessorUsername string    `json:"accessor_username,omitempty"`
+	ResourceType     string    `json:"resource_type"`
+	ResourceID       int32     `json:"resource_id"`
+	Purpose          string    `json:"purpose"`
+	AccessedAt       time.Time `json:"accessed_at"`
+}
+
+func newEntry(row db.ListDataAccessLogsBySubjectRow) Entry {
+	entry := Entry{
+		ID:               row.ID,
+		AccessorUsername: row.AccessorUsername,
+		ResourceType:     row.ResourceType,
+		ResourceID:       row.ResourceID,
+		Purpose:          row.Purpose,
+		AccessedAt:       row.AccessedAt,
+	}
+	if row.AccessorUserID.Valid {
+		entry.AccessorID = &row.AccessorUserID.Int32
+	}
+	return entry
+}
+
+// Service records reads of personal data and removes them after the
+// retention period
+type Service struct {
+	store db.Querier
+	// retention is how long entries are kept; 0 keeps them forever
+	retention time.Duration
+	now       func() time.Time
+
+	mutex     sync.Mutex
+	isRunning bool
+	stopChan  chan struct{}
+	wg        sync.WaitGroup
+}
+
+// NewService creates an access log keeping entries for retention
+func NewService(store db.Querier, retention time.Duration) *Service {
+	return &Service{store: store, retention: retention, now: time.Now}
+}
+
+// Record logs reads by accessorID. Reads of the accessor's own data are
+// not recorded, so their purpose is not checked either.
+func (s *Service) Record(ctx context.Context, accessorID int32, purpose string, accesses ...Access) error {
+	arg := db.CreateDataAccessLogsParams{AccessorUserID: accessorID, Purpose: purpose}
+	for _, access := range accesses {
+		if access.SubjectID == accessorID {
+			continue
+		}
+		arg.SubjectUserIds = append(arg.SubjectUserIds, access.SubjectID)
+		arg.ResourceTypes = append(arg.ResourceTypes, access.ResourceType)
+		arg.ResourceIds = append(arg.ResourceIds, access.ResourceID)
+	}
+	if len(arg.SubjectUserIds) == 0 {
+		return nil
+	}
+	if !ValidPurpose(purpose) {
+		return ErrUnknownPurpose
+	}
+	if err := s.store.CreateDataAccessLogs(ctx, arg); err != nil {
+		return fmt.Errorf("failed to record data access: %w", err)
+	}
+	return nil
+}
+
+// List returns the latest reads of a user's data, newest first, and the
+// number of recorded reads
+func (s *Service) List(ctx context.Context, subjectID int32, limit, offset int32) ([]Entry, int64, error) {
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	rows, err := s.store.ListDataAccessLogsBySubject(ctx, db.ListDataAccessLogsBySubjectParams{
+		SubjectUserID: subjectID,
+		Limit:         limit,
+		Offset:        offset,
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list data access log: %w", err)
+	}
+	total, err := s.store.CountDataAccessLogsBySubject(ctx, subjectID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count data access log: %w", err)
+	}
+	entries := make([]Entry, 0, len(rows))
+	for _, row := range rows {
+		entries = append(entries, newEntry(row))
+	}
+	return entries, total, nil
+}
+
+// Prune removes entries older than the retention period
+func (s *Service) Prune(ctx context.Context) (int64, error) {
+	if s.retention <= 0 {
+		return 0, nil
+	}
+	removed, err := s.store.DeleteDataAccessLogsBefore(ctx, s.now().Add(-s.retention))
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune data access log: %w", err)
+	}
+	return removed, nil
+}
+
+// Start prunes expired entries right away and then every interval until
+// Stop is called
+func (s *Service) Start(interval time.Duration) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.isRunning {
+		return fmt.Errorf("data access log pruner is already running")
+	}
+	s.isRunning = true
+	s.stopChan = make(chan struct{})
+	s.wg.Add(1)
+	go s.run(interval)
+
+	logger.Info("Data access log pruner started with interval: %v", interval)
+	return nil
+}
+
+// Stop stops the periodic pruning
+func (s *Service) Stop() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.isRunning {
+		return fmt.Errorf("data access log pruner is not running")
+	}
+	close(s.stopChan)
+	s.wg.Wait()
+	s.isRunning = false
+
+	logger.Info("Data access log pruner stopped")
+	return nil
+}
+
+// IsRunning returns whether the pruner is running
+func (s *Service) IsRunning() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.isRunning
+}
+
+func (s *Service) run(interval time.Duration) {
+	defer s.wg.Done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-s.stopChan:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		s.prune(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *Service) prune(ctx context.Context) {
+	removed, err := s.Prune(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.Error("Failed to prune data access log: %v", err)
+		}
+		return
+	}
+	if removed > 0 {
+		logger.Info("Pruned %d data access log entries older than %v", removed, s.retention)
+	}
+}
@@ -0,0 +1,156 @@
+package accesslog
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+// fakeStore keeps access log entries in memory
+type fakeStore struct {
+	db.Querier
+	logs []db.DataAccessLog
+}
+
+func (f *fakeStore) CreateDataAccessLogs(_ context.Context, arg db.CreateDataAccessLogsParams) error {
+	for i := range arg.SubjectUserIds {
+		f.logs = append(f.logs, db.DataAccessLog{
+			ID:             int64(len(f.logs) + 1),
+			SubjectUserID:  arg.SubjectUserIds[i],
+			AccessorUserID: sql.NullInt32{Int32: arg.AccessorUserID, Valid: true},
+			ResourceType:   arg.ResourceTypes[i],
+			ResourceID:     arg.ResourceIds[i],
+			Purpose:        arg.Purpose,
+			AccessedAt:     time.Now(),
+		})
+	}
+	return nil
+}
+
+func (f *fakeStore) ListDataAccessLogsBySubject(_ context.Context, arg db.ListDataAccessLogsBySubjectParams) ([]db.ListDataAccessLogsBySubjectRow, error) {
+	var rows []db.ListDataAccessLogsBySubjectRow
+	for i := len(f.logs) - 1; i >= 0; i-- {
+		entry := f.logs[i]
+		if entry.SubjectUserID != arg.SubjectUserID {
+			continue
+		}
+		rows = append(rows, db.ListDataAccessLogsBySubjectRow{
+			ID:               entry.ID,
+			AccessorUserID:   entry.AccessorUserID,
+			AccessorUsername: "teacher",
+			ResourceType:     entry.ResourceType,
+			ResourceID:       entry.ResourceID,
+			Purpose:          entry.Purpose,
+			AccessedAt:       entry.AccessedAt,
+		})
+	}
+	if int(arg.Offset) >= len(rows) {
+		return nil, nil
+	}
+	rows = rows[arg.Offset:]
+	if len(rows) > int(arg.Limit) {
+		rows = rows[:arg.Limit]
+	}
+	return rows, nil
+}
+
+func (f *fakeStore) CountDataAccessLogsBySubject(_ context.Context, subjectID int32) (int64, error) {
+	var count int64
+	for _, entry := range f.logs {
+		if entry.SubjectUserID == subjectID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (f *fakeStore) DeleteDataAccessLogsBefore(_ context.Context, before time.Time) (int64, error) {
+	kept := f.logs[:0]
+	for _, entry := range f.logs {
+		if !entry.AccessedAt.Before(before) {
+			kept = append(kept, entry)
+		}
+	}
+	removed := int64(len(f.logs) - len(kept))
+	f.logs = kept
+	return removed, nil
+}
+
+func TestRecordSkipsOwnData(t *testing.T) {
+	store := &fakeStore{}
+	service := NewService(store, 0)
+
+	err := service.Record(context.Background(), 7, PurposeGrading,
+		Access{SubjectID: 7, ResourceType: ResourceWriting, ResourceID: 1},
+		Access{SubjectID: 3, ResourceType: ResourceWriting, ResourceID: 2},
+	)
+	require.NoError(t, err)
+	require.Len(t, store.logs, 1)
+	assert.Equal(t, int32(3), store.logs[0].SubjectUserID)
+	assert.Equal(t, int32(2), store.logs[0].ResourceID)
+	assert.Equal(t, PurposeGrading, store.logs[0].Purpose)
+}
+
+func TestRecordRejectsUnknownPurpose(t *testing.T) {
+	store := &fakeStore{}
+	service := NewService(store, 0)
+	other := Access{SubjectID: 3, ResourceType: ResourceSpeakingSession, ResourceID: 5}
+
+	assert.ErrorIs(t, service.Record(context.Background(), 7, "curiosity", other), ErrUnknownPurpose)
+	assert.Empty(t, store.logs)
+
+	// Reading one's own data needs no purpose
+	own := Access{SubjectID: 7, ResourceType: ResourceSpeakingSession, ResourceID: 6}
+	assert.NoError(t, service.Record(context.Background(), 7, "curiosity", own))
+}
+
+func TestListPagesNewestFirst(t *testing.T) {
+	store := &fakeStore{}
+	service := NewService(store, 0)
+	for id := int32(1); id <= 3; id++ {
+		require.NoError(t, service.Record(context.Background(), 9, PurposeSupport,
+			Access{SubjectID: 3, ResourceType: ResourceWriting, ResourceID: id}))
+	}
+	require.NoError(t, service.Record(context.Background(), 9, PurposeSupport,
+		Access{SubjectID: 4, ResourceType: ResourceWriting, ResourceID: 10}))
+
+	entries, total, err := service.List(context.Background(), 3, 2, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+	require.Len(t, entries, 2)
+	assert.Equal(t, int32(3), entries[0].ResourceID)
+	assert.Equal(t, int32(2), entries[1].ResourceID)
+	require.NotNil(t, entries[0].AccessorID)
+	assert.Equal(t, int32(9), *entries[0].AccessorID)
+
+	entries, _, err = service.List(context.Background(), 3, 2, 2)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, int32(1), entries[0].ResourceID)
+}
+
+func TestPruneRemovesExpiredEntries(t *testing.T) {
+	now := time.Now()
+	store := &fakeStore{logs: []db.DataAccessLog{
+		{ID: 1, SubjectUserID: 3, AccessedAt: now.Add(-400 * 24 * time.Hour)},
+		{ID: 2, SubjectUserID: 3, AccessedAt: now.Add(-time.Hour)},
+	}}
+
+	// Without a retention period nothing expires
+	removed, err := NewService(store, 0).Prune(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, removed)
+
+	service := NewService(store, 365*24*time.Hour)
+	service.now = func() time.Time { return now }
+	removed, err = service.Prune(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), removed)
+	require.Len(t, store.logs, 1)
+	assert.Equal(t, int64(2), store.logs[0].ID)
+}
@@ -0,0 +1,235 @@
+// Package account locks accounts after repeated failed sign-ins and keeps a
+// record of sign-in sessions, so operators can see where a user is signed in
+// and unlock them.
+package account
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+const (
+	// ReasonFailedLogins is the lock reason after too many failed sign-ins
+	ReasonFailedLogins = "too many failed sign-ins"
+	// DefaultSessionLimit is the number of sessions listed by default
+	DefaultSessionLimit = 20
+	// sessionHistory is how long sessions are kept after they ended or expired
+	sessionHistory = 30 * 24 * time.Hour
+
+	maxIPLength        = 64
+	maxUserAgentLength = 255
+	maxReasonLength    = 100
+)
+
+var ErrInvalidLock = errors.New("lock must end in the future")
+
+// Status is the lock state of an account
+type Status struct {
+	FailedAttempts int32      `json:"failed_attempts"`
+	LockedUntil    *time.Time `json:"locked_until,omitempty"`
+	Reason         string     `json:"reason,omitempty"`
+}
+
+// Locked reports whether sign-in is blocked at now
+func (s Status) Locked(now time.Time) bool {
+	return s.LockedUntil != nil && s.LockedUntil.After(now)
+}
+
+func newStatus(row db.AccountLockout) Status {
+	status := Status{FailedAttempts: row.FailedAttempts}
+	if row.LockedUntil.Valid {
+		status.LockedUntil = &row.LockedUntil.Time
+		status.Reason = row.Reason
+	}
+	return status
+}
+
+// Session is a sign-in of a user
+type Session struct {
+	ID         int64      `json:"id"`
+	IPAddress  string     `json:"ip_address"`
+	UserAgent  string     `json:"user_agent"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	EndedAt    *time.Time `json:"ended_at,omitempty"`
+	Active     bool       `json:"active"`
+}
+
+func newSession(row db.UserSession, now time.Time) Session {
+	session := Session{
+		ID:         row.ID,
+		IPAddress:  row.IpAddress,
+		UserAgent:  row.UserAgent,
+		CreatedAt:  row.CreatedAt,
+		LastSeenAt: row.LastSeenAt,
+		ExpiresAt:  row.ExpiresAt,
+		Active:     !row.EndedAt.Valid && row.ExpiresAt.After(now),
+	}
+	if row.EndedAt.Valid {
+		session.EndedAt = &row.EndedAt.Time
+	}
+	return session
+}
+
+// Service tracks failed sign-ins, locks and sessions
+type Service struct {
+	store db.Querier
+	// threshold is the number of failed sign-ins that locks an account; 0
+	// disables automatic locks
+	threshold    int
+	lockDuration time.Duration
+	// sessionTTL is how long a session lasts without a token refresh
+	sessionTTL time.Duration
+}
+
+// NewService creates an account service locking accounts for lockDuration
+// after threshold failed sign-ins
+func NewService(store db.Querier, threshold int, lockDuration, sessionTTL time.Duration) *Service {
+	return &Service{store: store, threshold: threshold, lockDuration: lockDuration, sessionTTL: sessionTTL}
+}
+
+// Status returns the lock state of an account
+func (s *Service) Status(ctx context.Context, userID int32) (Status, error) {
+	row, err := s.store.GetAccountLockout(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Status{}, nil
+	}
+	if err != nil {
+		return Status{}, fmt.Errorf("failed to get account lock: %w", err)
+	}
+	return newStatus(row), nil
+}
+
+// RecordFailure counts a failed sign-in and locks the account once the
+// threshold is reached
+func (s *Service) RecordFailure(ctx context.Context, userID int32) (Status, error) {
+	row, err := s.store.RecordFailedLogin(ctx, userID)
+	if err != nil {
+		return Status{}, fmt.Errorf("failed to record failed sign-in: %w", err)
+	}
+	if s.threshold > 0 && int(row.FailedAttempts) >= s.threshold {
+		return s.Lock(ctx, userID, time.Now().Add(s.lockDuration), ReasonFailedLogins)
+	}
+	return newStatus(row), nil
+}
+
+// RecordSuccess clears the failed sign-ins of an account
+func (s *Service) RecordSuccess(ctx context.Context, userID int32) error {
+	if err := s.store.ResetFailedLogins(ctx, userID); err != nil {
+		return fmt.Errorf("failed to reset failed sign-ins: %w", err)
+	}
+	return nil
+}
+
+// Lock blocks sign-in and token refresh until until
+func (s *Service) Lock(ctx context.Context, userID int32, until time.Time, reason string) (Status, error) {
+	if !until.After(time.Now()) {
+		return Status{}, ErrInvalidLock
+	}
+	row, err := s.store.LockAccount(ctx, db.LockAccountParams{
+		UserID:      userID,
+		LockedUntil: sql.NullTime{Time: until, Valid: true},
+		Reason:      truncate(reason, maxReasonLength),
+	})
+	if err != nil {
+		return Status{}, fmt.Errorf("failed to lock account: %w", err)
+	}
+	return newStatus(row), nil
+}
+
+// Unlock lifts the lock and clears the failed sign-ins of an account. It
+// reports whether there was anything to clear.
+func (s *Service) Unlock(ctx context.Context, userID int32) (bool, error) {
+	removed, err := s.store.UnlockAccount(ctx, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to unlock account: %w", err)
+	}
+	return removed > 0, nil
+}
+
+// StartSession records a sign-in and forgets sessions that ended long ago
+func (s *Service) StartSession(ctx context.Context, userID int32, ip, userAgent string) error {
+	now := time.Now()
+	_, err := s.store.CreateUserSession(ctx, db.CreateUserSessionParams{
+		UserID:    userID,
+		IpAddress: truncate(ip, maxIPLength),
+		UserAgent: truncate(userAgent, maxUserAgentLength),
+		ExpiresAt: now.Add(s.sessionTTL),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record session: %w", err)
+	}
+	if _, err := s.store.DeleteStaleUserSessions(ctx, db.DeleteStaleUserSessionsParams{
+		UserID:    userID,
+		ExpiresAt: now.Add(-sessionHistory),
+	}); err != nil {
+		return fmt.Errorf("failed to delete old sessions: %w", err)
+	}
+	return nil
+}
+
+// RefreshSession extends the open session of a client after a token
+// refresh. Clients without one, e.g. signed in before sessions were
+// recorded, get a new session.
+func (s *Service) RefreshSession(ctx context.Context, userID int32, ip, userAgent string) error {
+	touched, err := s.store.TouchUserSession(ctx, db.TouchUserSessionParams{
+		UserID:    userID,
+		UserAgent: truncate(userAgent, maxUserAgentLength),
+		IpAddress: truncate(ip, maxIPLength),
+		ExpiresAt: time.Now().Add(s.sessionTTL),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to refresh session: %w", err)
+	}
+	if touched == 0 {
+		return s.StartSession(ctx, userID, ip, userAgent)
+	}
+	return nil
+}
+
+// EndSession closes the open session of a client on sign-out
+func (s *Service) EndSession(ctx context.Context, userID int32, userAgent string) error {
+	if _, err := s.store.EndUserSession(ctx, db.EndUserSessionParams{
+		UserID:    userID,
+		UserAgent: truncate(userAgent, maxUserAgentLength),
+	}); err != nil {
+		return fmt.Errorf("failed to end session: %w", err)
+	}
+	return nil
+}
+
+// Sessions lists the latest sessions of a user, only open ones unless
+// includeClosed is set
+func (s *Service) Sessions(ctx context.Context, userID int32, includeClosed bool, limit int) ([]Session, error) {
+	if limit <= 0 {
+		limit = DefaultSessionLimit
+	}
+	rows, err := s.store.ListUserSessions(ctx, db.ListUserSessionsParams{
+		UserID:        userID,
+		IncludeClosed: includeClosed,
+		Limit:         int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	now := time.Now()
+	sessions := make([]Session, 0, len(rows))
+	for _, row := range rows {
+		sessions = append(sessions, newSession(row, now))
+	}
+	return sessions, nil
+}
+
+func truncate(value string, limit int) string {
+	if utf8.RuneCountInString(value) <= limit {
+		return value
+	}
+	return string([]rune(value)[:limit])
+}
@@ -0,0 +1,216 @@
+package account
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+// fakeStore keeps lockouts and sessions in memory
+type fakeStore struct {
+	db.Querier
+	lockouts map[int32]db.AccountLockout
+	sessions []db.UserSession
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{lockouts: make(map[int32]db.AccountLockout)}
+}
+
+func (f *fakeStore) GetAccountLockout(_ context.Context, userID int32) (db.AccountLockout, error) {
+	row, ok := f.lockouts[userID]
+	if !ok {
+		return db.AccountLockout{}, sql.ErrNoRows
+	}
+	return row, nil
+}
+
+func (f *fakeStore) RecordFailedLogin(_ context.Context, userID int32) (db.AccountLockout, error) {
+	row := f.lockouts[userID]
+	row.UserID = userID
+	row.FailedAttempts++
+	f.lockouts[userID] = row
+	return row, nil
+}
+
+func (f *fakeStore) LockAccount(_ context.Context, arg db.LockAccountParams) (db.AccountLockout, error) {
+	row := f.lockouts[arg.UserID]
+	row.UserID = arg.UserID
+	row.LockedUntil = arg.LockedUntil
+	row.Reason = arg.Reason
+	f.lockouts[arg.UserID] = row
+	return row, nil
+}
+
+func (f *fakeStore) UnlockAccount(_ context.Context, userID int32) (int64, error) {
+	if _, ok := f.lockouts[userID]; !ok {
+		return 0, nil
+	}
+	delete(f.lockouts, userID)
+	return 1, nil
+}
+
+func (f *fakeStore) ResetFailedLogins(_ context.Context, userID int32) error {
+	row, ok := f.lockouts[userID]
+	if !ok {
+		return nil
+	}
+	row.FailedAttempts = 0
+	if !row.LockedUntil.Valid || !row.LockedUntil.Time.After(time.Now()) {
+		row.LockedUntil = sql.NullTime{}
+		row.Reason = ""
+	}
+	f.lockouts[userID] = row
+	return nil
+}
+
+func (f *fakeStore) CreateUserSession(_ context.Context, arg db.CreateUserSessionParams) (db.UserSession, error) {
+	now := time.Now()
+	session := db.UserSession{
+		ID:         int64(len(f.sessions) + 1),
+		UserID:     arg.UserID,
+		IpAddress:  arg.IpAddress,
+		UserAgent:  arg.UserAgent,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  arg.ExpiresAt,
+	}
+	f.sessions = append(f.sessions, session)
+	return session, nil
+}
+
+func (f *fakeStore) open(userID int32, userAgent string) int {
+	for i := len(f.sessions) - 1; i >= 0; i-- {
+		s := f.sessions[i]
+		if s.UserID == userID && s.UserAgent == userAgent && !s.EndedAt.Valid && s.ExpiresAt.After(time.Now()) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (f *fakeStore) TouchUserSession(_ context.Context, arg db.TouchUserSessionParams) (int64, error) {
+	i := f.open(arg.UserID, arg.UserAgent)
+	if i < 0 {
+		return 0, nil
+	}
+	f.sessions[i].IpAddress = arg.IpAddress
+	f.sessions[i].ExpiresAt = arg.ExpiresAt
+	f.sessions[i].LastSeenAt = time.Now()
+	return 1, nil
+}
+
+func (f *fakeStore) EndUserSession(_ context.Context, arg db.EndUserSessionParams) (int64, error) {
+	i := f.open(arg.UserID, arg.UserAgent)
+	if i < 0 {
+		return 0, nil
+	}
+	f.sessions[i].EndedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	return 1, nil
+}
+
+func (f *fakeStore) ListUserSessions(_ context.Context, arg db.ListUserSessionsParams) ([]db.UserSession, error) {
+	var rows []db.UserSession
+	for i := len(f.sessions) - 1; i >= 0 && len(rows) < int(arg.Limit); i-- {
+		s := f.sessions[i]
+		if s.UserID == arg.UserID && (arg.IncludeClosed || (!s.EndedAt.Valid && s.ExpiresAt.After(time.Now()))) {
+			rows = append(rows, s)
+		}
+	}
+	return rows, nil
+}
+
+func (f *fakeStore) DeleteStaleUserSessions(context.Context, db.DeleteStaleUserSessionsParams) (int64, error) {
+	return 0, nil
+}
+
+func TestRecordFailureLocksAtThreshold(t *testing.T) {
+	store := newFakeStore()
+	service := NewService(store, 3, 15*time.Minute, time.Hour)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		status, err := service.RecordFailure(ctx, 7)
+		require.NoError(t, err)
+		assert.False(t, status.Locked(time.Now()))
+	}
+	status, err := service.RecordFailure(ctx, 7)
+	require.NoError(t, err)
+	assert.True(t, status.Locked(time.Now()))
+	assert.Equal(t, ReasonFailedLogins, status.Reason)
+	assert.WithinDuration(t, time.Now().Add(15*time.Minute), *status.LockedUntil, time.Second)
+
+	// A correct password does not lift an unexpired lock
+	require.NoError(t, service.RecordSuccess(ctx, 7))
+	status, err = service.Status(ctx, 7)
+	require.NoError(t, err)
+	assert.True(t, status.Locked(time.Now()))
+	assert.Zero(t, status.FailedAttempts)
+
+	cleared, err := service.Unlock(ctx, 7)
+	require.NoError(t, err)
+	assert.True(t, cleared)
+	status, err = service.Status(ctx, 7)
+	require.NoError(t, err)
+	assert.False(t, status.Locked(time.Now()))
+
+	cleared, err = service.Unlock(ctx, 7)
+	require.NoError(t, err)
+	assert.False(t, cleared)
+}
+
+func TestRecordFailureWithoutThreshold(t *testing.T) {
+	service := NewService(newFakeStore(), 0, time.Minute, time.Hour)
+	for i := 0; i < 20; i++ {
+		status, err := service.RecordFailure(context.Background(), 1)
+		require.NoError(t, err)
+		assert.False(t, status.Locked(time.Now()))
+	}
+}
+
+func TestLockRejectsPastTime(t *testing.T) {
+	service := NewService(newFakeStore(), 3, time.Minute, time.Hour)
+	_, err := service.Lock(context.Background(), 1, time.Now().Add(-time.Minute), "")
+	assert.ErrorIs(t, err, ErrInvalidLock)
+
+	status, err := service.Lock(context.Background(), 1, time.Now().Add(time.Hour), strings.Repeat("x", 150))
+	require.NoError(t, err)
+	assert.Len(t, status.Reason, maxReasonLength)
+}
+
+func TestSessions(t *testing.T) {
+	store := newFakeStore()
+	service := NewService(store, 3, time.Minute, time.Hour)
+	ctx := context.Background()
+
+	require.NoError(t, service.StartSession(ctx, 5, "10.0.0.1", "phone"))
+	require.NoError(t, service.StartSession(ctx, 5, "10.0.0.2", "browser"))
+
+	// Refreshing extends the open session of the client
+	require.NoError(t, service.RefreshSession(ctx, 5, "10.0.0.3", "phone"))
+	sessions, err := service.Sessions(ctx, 5, false, 0)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	assert.Equal(t, "10.0.0.3", sessions[1].IPAddress)
+
+	// Clients without an open session get a new one
+	require.NoError(t, service.RefreshSession(ctx, 5, "10.0.0.4", "tablet"))
+
+	require.NoError(t, service.EndSession(ctx, 5, "browser"))
+	sessions, err = service.Sessions(ctx, 5, false, 0)
+	require.NoError(t, err)
+	assert.Len(t, sessions, 2)
+
+	sessions, err = service.Sessions(ctx, 5, true, 0)
+	require.NoError(t, err)
+	require.Len(t, sessions, 3)
+	assert.Equal(t, "browser", sessions[1].UserAgent)
+	assert.False(t, sessions[1].Active)
+	assert.NotNil(t, sessions[1].EndedAt)
+}
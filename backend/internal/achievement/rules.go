@@ -0,0 +1,70 @@
+package achievement
+
+import (
+	"errors"
+
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+// Rule types of badges
+const (
+	RuleActivityCount = "activity_count" // Number of activities of a type
+	RuleStreakDays    = "streak_days"    // Current daily streak
+	RuleWordsMastered = "words_mastered" // Words at mastery level 8 or above
+	RuleTotalXP       = "total_xp"       // XP earned from all activities
+)
+
+var (
+	// ErrInvalidRule is returned for badge rules that cannot be evaluated
+	ErrInvalidRule = errors.New("invalid badge rule")
+	// ErrBadgeNotFound is returned for unknown badges
+	ErrBadgeNotFound = errors.New("badge not found")
+	// ErrDuplicateBadge is returned when a badge code is already used
+	ErrDuplicateBadge = errors.New("a badge with this code already exists")
+)
+
+// Stats are the figures of a user that badge rules are evaluated against
+type Stats struct {
+	ActivityCounts map[string]int64
+	Streak         int
+	MasteredWords  int64
+	TotalXP        int64
+}
+
+// ValidateRule checks that a rule can be evaluated
+func ValidateRule(ruleType, activityType string, threshold int32) error {
+	if threshold <= 0 {
+		return ErrInvalidRule
+	}
+	switch ruleType {
+	case RuleActivityCount:
+		if activityType == "" {
+			return ErrInvalidRule
+		}
+	case RuleStreakDays, RuleWordsMastered, RuleTotalXP:
+	default:
+		return ErrInvalidRule
+	}
+	return nil
+}
+
+// Progress returns the value the rule of a badge compares to its threshold
+func Progress(badge db.Badge, stats Stats) int64 {
+	switch badge.RuleType {
+	case RuleActivityCount:
+		return stats.ActivityCounts[badge.ActivityType.String]
+	case RuleStreakDays:
+		return int64(stats.Streak)
+	case RuleWordsMastered:
+		return stats.MasteredWords
+	case RuleTotalXP:
+		return stats.TotalXP
+	default:
+		return 0
+	}
+}
+
+// Earned reports whether the stats satisfy the rule of a badge
+func Earned(badge db.Badge, stats Stats) bool {
+	return Progress(badge, stats) >= int64(badge.Threshold)
+}
@@ -0,0 +1,40 @@
+package achievement
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+func TestValidateRule(t *testing.T) {
+	assert.NoError(t, ValidateRule(RuleActivityCount, "exam_completed", 1))
+	assert.NoError(t, ValidateRule(RuleStreakDays, "", 30))
+	assert.ErrorIs(t, ValidateRule(RuleActivityCount, "", 1), ErrInvalidRule)
+	assert.ErrorIs(t, ValidateRule(RuleTotalXP, "", 0), ErrInvalidRule)
+	assert.ErrorIs(t, ValidateRule("login_count", "", 5), ErrInvalidRule)
+}
+
+func TestEarned(t *testing.T) {
+	stats := Stats{
+		ActivityCounts: map[string]int64{"exam_completed": 1},
+		Streak:         29,
+		MasteredWords:  1000,
+		TotalXP:        250,
+	}
+
+	firstExam := db.Badge{RuleType: RuleActivityCount, ActivityType: sql.NullString{String: "exam_completed", Valid: true}, Threshold: 1}
+	assert.True(t, Earned(firstExam, stats))
+
+	placement := db.Badge{RuleType: RuleActivityCount, ActivityType: sql.NullString{String: "placement_completed", Valid: true}, Threshold: 1}
+	assert.False(t, Earned(placement, stats))
+
+	streak := db.Badge{RuleType: RuleStreakDays, Threshold: 30}
+	assert.False(t, Earned(streak, stats))
+	assert.Equal(t, int64(29), Progress(streak, stats))
+
+	assert.True(t, Earned(db.Badge{RuleType: RuleWordsMastered, Threshold: 1000}, stats))
+	assert.False(t, Earned(db.Badge{RuleType: RuleTotalXP, Threshold: 500}, stats))
+	assert.False(t, Earned(db.Badge{RuleType: "unknown", Threshold: 1}, stats))
+}
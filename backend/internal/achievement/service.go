@@ -0,0 +1,390 @@
+package achievement
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/logger"
+	"github.com/toeic-app/internal/social"
+)
+
+// EventBadgeUnlocked is the WebSocket event sent when a user earns a badge
+const EventBadgeUnlocked = "achievement.unlocked"
+
+const (
+	cursorName      = "achievements" // Position of the evaluator in the activity log
+	batchSize       = 500            // Activities read per evaluation run
+	maxStreakLookup = 400            // Activity days read to compute a streak
+)
+
+// Notifier delivers real-time events to connected users
+type Notifier interface {
+	SendToUser(userID string, messageType string, data interface{}) error
+}
+
+// BadgeStatus is a badge with the progress of a user towards it
+type BadgeStatus struct {
+	ID          int32      `json:"id"`
+	Code        string     `json:"code"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	IconURL     string     `json:"icon_url,omitempty"`
+	Threshold   int32      `json:"threshold"`
+	Progress    int64      `json:"progress"`
+	Earned      bool       `json:"earned"`
+	AwardedAt   *time.Time `json:"awarded_at,omitempty"`
+}
+
+// Unlocked is the payload of the celebration event
+type Unlocked struct {
+	ID          int32  `json:"id"`
+	Code        string `json:"code"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	IconURL     string `json:"icon_url,omitempty"`
+}
+
+// Service awards badges by evaluating their rules on the activity log
+type Service struct {
+	store    db.Querier
+	notifier Notifier
+
+	mutex     sync.Mutex
+	isRunning bool
+	stopChan  chan struct{}
+	wg        sync.WaitGroup
+}
+
+// NewService creates a new achievement service. notifier may be nil.
+func NewService(store db.Querier, notifier Notifier) *Service {
+	return &Service{store: store, notifier: notifier}
+}
+
+// Start evaluates new activities periodically until Stop is called
+func (s *Service) Start(interval time.Duration) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.isRunning {
+		return fmt.Errorf("achievement evaluator is already running")
+	}
+	s.isRunning = true
+	s.stopChan = make(chan struct{})
+	s.wg.Add(1)
+	go s.run(interval)
+
+	logger.Info("Achievement evaluator started with interval: %v", interval)
+	return nil
+}
+
+// Stop stops the periodic evaluation
+func (s *Service) Stop() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.isRunning {
+		return fmt.Errorf("achievement evaluator is not running")
+	}
+	close(s.stopChan)
+	s.wg.Wait()
+	s.isRunning = false
+
+	logger.Info("Achievement evaluator stopped")
+	return nil
+}
+
+// IsRunning returns whether the evaluator is running
+func (s *Service) IsRunning() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.isRunning
+}
+
+func (s *Service) run(interval time.Duration) {
+	defer s.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			// Drain the backlog before waiting for the next tick
+			for {
+				processed, err := s.ProcessEvents(context.Background())
+				if err != nil {
+					logger.Error("Failed to evaluate achievements: %v", err)
+				}
+				if err != nil || processed < batchSize {
+					break
+				}
+			}
+		case <-s.stopChan:
+			return
+		}
+	}
+}
+
+// ProcessEvents evaluates the badges of users with activities recorded since
+// the last run and returns the number of activities read
+func (s *Service) ProcessEvents(ctx context.Context) (int, error) {
+	lastID, err := s.store.GetEventCursor(ctx, cursorName)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("failed to get event cursor: %w", err)
+	}
+
+	activities, err := s.store.ListActivitiesAfter(ctx, db.ListActivitiesAfterParams{AfterID: lastID, MaxItems: batchSize})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list activities: %w", err)
+	}
+	if len(activities) == 0 {
+		return 0, nil
+	}
+
+	// Rules depend on totals, so each user is evaluated once per batch
+	latest := map[int32]int64{}
+	users := []int32{}
+	for _, activity := range activities {
+		if _, seen := latest[activity.UserID]; !seen {
+			users = append(users, activity.UserID)
+		}
+		latest[activity.UserID] = activity.ID
+	}
+	for _, userID := range users {
+		if _, err := s.Evaluate(ctx, userID, latest[userID]); err != nil {
+			return 0, err
+		}
+	}
+
+	last := activities[len(activities)-1].ID
+	if err := s.store.UpsertEventCursor(ctx, db.UpsertEventCursorParams{Name: cursorName, LastID: last}); err != nil {
+		return 0, fmt.Errorf("failed to save event cursor: %w", err)
+	}
+	return len(activities), nil
+}
+
+// Evaluate awards the badges a user qualifies for. activityID is the activity
+// that triggered the evaluation, 0 if none.
+func (s *Service) Evaluate(ctx context.Context, userID int32, activityID int64) ([]db.Badge, error) {
+	candidates, err := s.store.ListUnearnedBadges(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unearned badges: %w", err)
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	stats, err := s.Stats(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	awarded := []db.Badge{}
+	for _, badge := range candidates {
+		if !Earned(badge, stats) {
+			continue
+		}
+		inserted, err := s.store.AwardBadge(ctx, db.AwardBadgeParams{
+			UserID:     userID,
+			BadgeID:    badge.ID,
+			ActivityID: sql.NullInt64{Int64: activityID, Valid: activityID > 0},
+		})
+		if err != nil {
+			return awarded, fmt.Errorf("failed to award badge: %w", err)
+		}
+		if inserted == 0 {
+			continue
+		}
+
+		awarded = append(awarded, badge)
+		logger.InfoWithFields(logger.Fields{"user_id": userID, "badge": badge.Code}, "Badge awarded")
+		s.celebrate(userID, badge)
+	}
+	return awarded, nil
+}
+
+func (s *Service) celebrate(userID int32, badge db.Badge) {
+	if s.notifier == nil {
+		return
+	}
+	event := Unlocked{
+		ID:          badge.ID,
+		Code:        badge.Code,
+		Name:        badge.Name,
+		Description: badge.Description,
+		IconURL:     badge.IconUrl.String,
+	}
+	if err := s.notifier.SendToUser(strconv.Itoa(int(userID)), EventBadgeUnlocked, event); err != nil {
+		logger.Debug("Failed to send %s to user %d: %v", EventBadgeUnlocked, userID, err)
+	}
+}
+
+// Stats collects the figures badge rules are evaluated against
+func (s *Service) Stats(ctx context.Context, userID int32) (Stats, error) {
+	stats := Stats{ActivityCounts: map[string]int64{}}
+
+	counts, err := s.store.CountUserActivitiesByType(ctx, userID)
+	if err != nil {
+		return stats, fmt.Errorf("failed to count activities: %w", err)
+	}
+	for _, count := range counts {
+		stats.ActivityCounts[count.Type] = count.Count
+	}
+
+	days, err := s.store.ListUserActivityDays(ctx, db.ListUserActivityDaysParams{UserID: userID, Limit: maxStreakLookup})
+	if err != nil {
+		return stats, fmt.Errorf("failed to list activity days: %w", err)
+	}
+	stats.Streak = social.CurrentStreak(days, time.Now())
+
+	if stats.MasteredWords, err = s.store.CountMasteredWords(ctx, userID); err != nil {
+		return stats, fmt.Errorf("failed to count mastered words: %w", err)
+	}
+	if stats.TotalXP, err = s.store.GetUserTotalXP(ctx, userID); err != nil {
+		return stats, fmt.Errorf("failed to get total XP: %w", err)
+	}
+	return stats, nil
+}
+
+// Badges lists the active badges with the progress of a user, earned badges first
+func (s *Service) Badges(ctx context.Context, userID int32) ([]BadgeStatus, error) {
+	badges, err := s.store.ListBadges(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list badges: %w", err)
+	}
+	earned, err := s.store.ListUserBadges(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user badges: %w", err)
+	}
+	stats, err := s.Stats(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	awardedAt := map[int32]time.Time{}
+	for _, badge := range earned {
+		awardedAt[badge.ID] = badge.AwardedAt
+	}
+
+	earnedStatuses := []BadgeStatus{}
+	pendingStatuses := []BadgeStatus{}
+	for _, badge := range badges {
+		at, ok := awardedAt[badge.ID]
+		// Retired badges stay visible to users who earned them
+		if !badge.IsActive && !ok {
+			continue
+		}
+		status := BadgeStatus{
+			ID:          badge.ID,
+			Code:        badge.Code,
+			Name:        badge.Name,
+			Description: badge.Description,
+			IconURL:     badge.IconUrl.String,
+			Threshold:   badge.Threshold,
+			Progress:    Progress(badge, stats),
+			Earned:      ok,
+		}
+		if ok {
+			status.AwardedAt = &at
+			earnedStatuses = append(earnedStatuses, status)
+		} else {
+			pendingStatuses = append(pendingStatuses, status)
+		}
+	}
+	return append(earnedStatuses, pendingStatuses...), nil
+}
+
+// BadgeInput is the editable definition of a badge
+type BadgeInput struct {
+	Name         string
+	Description  string
+	IconURL      string
+	RuleType     string
+	ActivityType string
+	Threshold    int32
+	IsActive     bool
+}
+
+func (input BadgeInput) validate() error {
+	return ValidateRule(input.RuleType, input.ActivityType, input.Threshold)
+}
+
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+// ListBadges returns every badge definition, including inactive ones
+func (s *Service) ListBadges(ctx context.Context) ([]db.Badge, error) {
+	badges, err := s.store.ListBadges(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list badges: %w", err)
+	}
+	return badges, nil
+}
+
+// CreateBadge adds a badge definition. Users who already qualify receive it on their next activity.
+func (s *Service) CreateBadge(ctx context.Context, code string, input BadgeInput) (db.Badge, error) {
+	if err := input.validate(); err != nil {
+		return db.Badge{}, err
+	}
+	badge, err := s.store.CreateBadge(ctx, db.CreateBadgeParams{
+		Code:         code,
+		Name:         input.Name,
+		Description:  input.Description,
+		IconUrl:      nullString(input.IconURL),
+		RuleType:     input.RuleType,
+		ActivityType: nullString(input.ActivityType),
+		Threshold:    input.Threshold,
+		IsActive:     input.IsActive,
+	})
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return badge, ErrDuplicateBadge
+	}
+	if err != nil {
+		return badge, fmt.Errorf("failed to create badge: %w", err)
+	}
+	return badge, nil
+}
+
+// UpdateBadge replaces a badge definition. Badges already awarded are kept.
+func (s *Service) UpdateBadge(ctx context.Context, id int32, input BadgeInput) (db.Badge, error) {
+	if err := input.validate(); err != nil {
+		return db.Badge{}, err
+	}
+	badge, err := s.store.UpdateBadge(ctx, db.UpdateBadgeParams{
+		ID:           id,
+		Name:         input.Name,
+		Description:  input.Description,
+		IconUrl:      nullString(input.IconURL),
+		RuleType:     input.RuleType,
+		ActivityType: nullString(input.ActivityType),
+		Threshold:    input.Threshold,
+		IsActive:     input.IsActive,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return badge, ErrBadgeNotFound
+	}
+	if err != nil {
+		return badge, fmt.Errorf("failed to update badge: %w", err)
+	}
+	return badge, nil
+}
+
+// DeleteBadge removes a badge definition and the awards of that badge
+func (s *Service) DeleteBadge(ctx context.Context, id int32) error {
+	deleted, err := s.store.DeleteBadge(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete badge: %w", err)
+	}
+	if deleted == 0 {
+		return ErrBadgeNotFound
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+package ai
+
+import "context"
+
+// Provider scores writing and replies in speaking practice. ScoringService
+// is the production implementation backed by the OpenAI chat completions API.
+type Provider interface {
+	ScoreWriting(ctx context.Context, req AIScoreRequest) (*AIScoreResponse, error)
+	GenerateSpeakingResponse(ctx context.Context, req AISpeakingRequest) (*AISpeakingResponse, error)
+	IsHealthy() bool
+}
+
+var _ Provider = (*ScoringService)(nil)
@@ -0,0 +1,104 @@
+package ai
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// RubricCriterion is a criterion writing is scored on
+type RubricCriterion struct {
+	// Key names the criterion in the AI reply and in the feedback
+	Key         string `json:"key" example:"task_response"`
+	Name        string `json:"name" example:"Task completion"`
+	Description string `json:"description" example:"Every request or question of the email is answered"`
+	// Weight is the percentage of the score the criterion counts for
+	Weight int `json:"weight" example:"40"`
+}
+
+// Rubric is what a writing task is scored on: weighted criteria and
+// instructions for the assessor
+type Rubric struct {
+	Name         string            `json:"name" example:"Business email"`
+	Instructions string            `json:"instructions" example:"The response answers an email in a tone fit for business."`
+	Criteria     []RubricCriterion `json:"criteria"`
+}
+
+// CriterionScore is the score and feedback of writing on a criterion
+type CriterionScore struct {
+	Key      string `json:"key" example:"task_response"`
+	Name     string `json:"name" example:"Task completion"`
+	Weight   int    `json:"weight" example:"40"`
+	Score    int    `json:"score" example:"150"` // 0-200
+	Feedback string `json:"feedback" example:"Both questions of the email are answered"`
+}
+
+// DefaultRubric is the rubric of writing without a task specific one: the
+// general TOEIC writing criteria
+func DefaultRubric() Rubric {
+	return Rubric{
+		Name: "TOEIC writing",
+		Criteria: []RubricCriterion{
+			{Key: "grammar", Name: "Grammar", Description: "accuracy and variety of grammatical structures", Weight: 20},
+			{Key: "vocabulary", Name: "Vocabulary", Description: "range, accuracy, and appropriateness of word choice", Weight: 15},
+			{Key: "organization", Name: "Organization", Description: "logical structure and coherence", Weight: 15},
+			{Key: "development", Name: "Development", Description: "how well ideas are developed and supported", Weight: 15},
+			{Key: "task_response", Name: "Task Response", Description: "how well the writing addresses the task requirements", Weight: 20},
+			{Key: "language_use", Name: "Language Use", Description: "overall fluency and natural expression", Weight: 15},
+		},
+	}
+}
+
+// describeCriteria lists the criteria of the rubric for the assessor
+func (r Rubric) describeCriteria() string {
+	var b strings.Builder
+	for i, criterion := range r.Criteria {
+		fmt.Fprintf(&b, "%d. %s (key %q, %d%% of the score)", i+1, criterion.Name, criterion.Key, criterion.Weight)
+		if criterion.Description != "" {
+			b.WriteString(" - " + criterion.Description)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// score returns the scores of the criteria of the rubric found in the AI
+// reply and the weighted score, which is only computed when every
+// criterion was scored
+func (r Rubric) score(reply map[string]criterionReply) ([]CriterionScore, int, bool) {
+	scores := make([]CriterionScore, 0, len(r.Criteria))
+	weighted, complete := 0.0, true
+	for _, criterion := range r.Criteria {
+		scored, ok := reply[criterion.Key]
+		if !ok {
+			complete = false
+			continue
+		}
+		score := clampScore(scored.Score)
+		scores = append(scores, CriterionScore{
+			Key:      criterion.Key,
+			Name:     criterion.Name,
+			Weight:   criterion.Weight,
+			Score:    score,
+			Feedback: scored.Feedback,
+		})
+		weighted += float64(score*criterion.Weight) / 100
+	}
+	return scores, int(math.Round(weighted)), complete && len(scores) > 0
+}
+
+// criterionReply is the assessment of a criterion in the AI reply
+type criterionReply struct {
+	Score    int    `json:"score"`
+	Feedback string `json:"feedback"`
+}
+
+func clampScore(score int) int {
+	if score < 0 {
+		return 0
+	}
+	if score > 200 {
+		return 200
+	}
+	return score
+}
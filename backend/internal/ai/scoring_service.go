@@ -43,12 +43,14 @@ type ScoringCriteria struct {
 
 // AIScoreResponse represents the AI scoring response
 type AIScoreResponse struct {
-	Score       int             `json:"score"`       // 0-200 TOEIC writing score
-	Band        TOEICBand       `json:"band"`        // TOEIC band level
-	Feedback    ScoringCriteria `json:"feedback"`    // Detailed feedback
-	Suggestions []string        `json:"suggestions"` // Improvement suggestions
-	Confidence  float64         `json:"confidence"`  // AI confidence score (0-1)
-	ProcessedAt time.Time       `json:"processed_at"`
+	Score       int              `json:"score"`              // 0-200 TOEIC writing score
+	Band        TOEICBand        `json:"band"`               // TOEIC band level
+	Feedback    ScoringCriteria  `json:"feedback"`           // Detailed feedback
+	Rubric      string           `json:"rubric"`             // Name of the rubric scored on
+	Criteria    []CriterionScore `json:"criteria,omitempty"` // Scores on the rubric criteria
+	Suggestions []string         `json:"suggestions"`        // Improvement suggestions
+	Confidence  float64          `json:"confidence"`         // AI confidence score (0-1)
+	ProcessedAt time.Time        `json:"processed_at"`
 }
 
 // AIScoreRequest represents the request to score writing
@@ -56,6 +58,18 @@ type AIScoreRequest struct {
 	Text     string `json:"text"`
 	PromptID *int32 `json:"prompt_id,omitempty"`
 	UserID   int32  `json:"user_id"`
+	// Task is the prompt the text answers, if any
+	Task string `json:"task,omitempty"`
+	// Rubric is what the text is scored on; nil scores on DefaultRubric
+	Rubric *Rubric `json:"rubric,omitempty"`
+}
+
+// rubric returns the rubric the request is scored on
+func (req AIScoreRequest) rubric() Rubric {
+	if req.Rubric == nil || len(req.Rubric.Criteria) == 0 {
+		return DefaultRubric()
+	}
+	return *req.Rubric
 }
 
 // AISpeakingRequest represents the request to generate speaking response
@@ -141,7 +155,8 @@ func (s *ScoringService) ScoreWriting(ctx context.Context, req AIScoreRequest) (
 // ScoreWritingWithOpenAI scores writing using OpenAI ChatGPT API
 func (s *ScoringService) ScoreWritingWithOpenAI(ctx context.Context, req AIScoreRequest) (*AIScoreResponse, error) {
 	// Create the prompt for TOEIC writing assessment
-	prompt := s.createTOEICPrompt(req.Text)
+	rubric := req.rubric()
+	prompt := s.createTOEICPrompt(req.Text, req.Task, rubric)
 	// Prepare OpenAI request
 	openAIReq := OpenAIRequest{
 		Model: "gpt-3.5-turbo", // Using GPT-3.5-turbo for cost efficiency
@@ -206,7 +221,7 @@ func (s *ScoringService) ScoreWritingWithOpenAI(ctx context.Context, req AIScore
 
 	// Parse the AI assessment from the response
 	aiAssessment := openAIResp.Choices[0].Message.Content
-	response, err := s.parseAIAssessment(aiAssessment)
+	response, err := s.parseAIAssessment(aiAssessment, rubric)
 	if err != nil {
 		// Fallback to basic scoring if AI parsing fails
 		logger.Warn("Failed to parse AI assessment, falling back to basic scoring: %v", err)
@@ -424,19 +439,32 @@ func (s *ScoringService) GetStats() map[string]interface{} {
 }
 
 // createTOEICPrompt creates a detailed prompt for TOEIC writing assessment
-func (s *ScoringService) createTOEICPrompt(text string) string {
-	return fmt.Sprintf(`Please evaluate this TOEIC writing sample according to official TOEIC writing assessment criteria and provide a score from 0-200.
+// on the criteria of the rubric
+func (s *ScoringService) createTOEICPrompt(text, task string, rubric Rubric) string {
+	var taskContext strings.Builder
+	if task != "" {
+		fmt.Fprintf(&taskContext, "\nTASK:\n%s\n", task)
+	}
+	if rubric.Instructions != "" {
+		fmt.Fprintf(&taskContext, "\nASSESSMENT INSTRUCTIONS:\n%s\n", rubric.Instructions)
+	}
 
+	var criteriaFormat strings.Builder
+	for i, criterion := range rubric.Criteria {
+		if i > 0 {
+			criteriaFormat.WriteString(",\n")
+		}
+		fmt.Fprintf(&criteriaFormat, "    %q: {\"score\": [numeric score 0-200], \"feedback\": \"[detailed feedback on %s]\"}", criterion.Key, strings.ToLower(criterion.Name))
+	}
+
+	return fmt.Sprintf(`Please evaluate this TOEIC writing sample according to official TOEIC writing assessment criteria and provide a score from 0-200.
+%s
 WRITING SAMPLE:
 %s
 
-Please assess the writing based on these TOEIC criteria:
-1. Grammar - accuracy and variety of grammatical structures
-2. Vocabulary - range, accuracy, and appropriateness of word choice
-3. Organization - logical structure and coherence
-4. Development - how well ideas are developed and supported
-5. Task Response - how well the writing addresses the task requirements
-6. Language Use - overall fluency and natural expression
+Please assess the writing with the %s rubric, scoring each criterion from 0-200:
+%s
+The overall score is the average of the criterion scores weighted by their percentage.
 
 SCORING BANDS:
 - Band 1 (0-30): Novice Low
@@ -454,13 +482,10 @@ Respond in JSON format:
 {
   "score": [numeric score 0-200],
   "band": "[1-10]",
+  "criteria": {
+%s
+  },
   "feedback": {
-    "grammar": "[detailed feedback on grammar]",
-    "vocabulary": "[detailed feedback on vocabulary]",
-    "organization": "[detailed feedback on organization]",
-    "development": "[detailed feedback on development]",
-    "task_response": "[detailed feedback on task response]",
-    "language_use": "[detailed feedback on language use]",
     "overall": "[overall assessment summary]"
   },
   "suggestions": [
@@ -469,11 +494,13 @@ Respond in JSON format:
     "[specific improvement suggestion 3]"
   ],
   "confidence": [0.0-1.0]
-}`, text)
+}`, taskContext.String(), text, rubric.Name, rubric.describeCriteria(), criteriaFormat.String())
 }
 
-// parseAIAssessment parses the AI response and converts it to AIScoreResponse
-func (s *ScoringService) parseAIAssessment(assessment string) (*AIScoreResponse, error) {
+// parseAIAssessment parses the AI response and converts it to AIScoreResponse.
+// When every criterion of the rubric is scored the score is their weighted
+// average, else the score the AI gave.
+func (s *ScoringService) parseAIAssessment(assessment string, rubric Rubric) (*AIScoreResponse, error) {
 	// Try to extract JSON from the response
 	assessment = strings.TrimSpace(assessment)
 
@@ -489,11 +516,12 @@ func (s *ScoringService) parseAIAssessment(assessment string) (*AIScoreResponse,
 
 	// Parse the JSON response
 	var aiResp struct {
-		Score       int               `json:"score"`
-		Band        string            `json:"band"`
-		Feedback    map[string]string `json:"feedback"`
-		Suggestions []string          `json:"suggestions"`
-		Confidence  float64           `json:"confidence"`
+		Score       int                       `json:"score"`
+		Band        string                    `json:"band"`
+		Criteria    map[string]criterionReply `json:"criteria"`
+		Feedback    map[string]string         `json:"feedback"`
+		Suggestions []string                  `json:"suggestions"`
+		Confidence  float64                   `json:"confidence"`
 	}
 
 	if err := json.Unmarshal([]byte(jsonStr), &aiResp); err != nil {
@@ -504,11 +532,22 @@ func (s *ScoringService) parseAIAssessment(assessment string) (*AIScoreResponse,
 	band := s.validateBand(aiResp.Band)
 
 	// Validate score
-	score := aiResp.Score
-	if score < 0 {
-		score = 0
-	} else if score > 200 {
-		score = 200
+	score := clampScore(aiResp.Score)
+
+	criteria, weighted, complete := rubric.score(aiResp.Criteria)
+	if complete {
+		score = weighted
+		band = s.scoreToBand(score)
+	}
+
+	// Criteria feedback fills the feedback of the same name
+	if aiResp.Feedback == nil {
+		aiResp.Feedback = map[string]string{}
+	}
+	for _, criterion := range criteria {
+		if _, exists := aiResp.Feedback[criterion.Key]; !exists {
+			aiResp.Feedback[criterion.Key] = criterion.Feedback
+		}
 	}
 
 	// Convert feedback map to ScoringCriteria
@@ -534,6 +573,8 @@ func (s *ScoringService) parseAIAssessment(assessment string) (*AIScoreResponse,
 		Score:       score,
 		Band:        band,
 		Feedback:    feedback,
+		Rubric:      rubric.Name,
+		Criteria:    criteria,
 		Suggestions: aiResp.Suggestions,
 		Confidence:  confidence,
 	}, nil
@@ -566,6 +607,7 @@ func (s *ScoringService) fallbackScoring(req AIScoreRequest) (*AIScoreResponse,
 		Score:       score,
 		Band:        band,
 		Feedback:    feedback,
+		Rubric:      req.rubric().Name,
 		Suggestions: suggestions,
 		Confidence:  0.60, // Lower confidence for basic scoring
 		ProcessedAt: time.Now(),
@@ -0,0 +1,61 @@
+package ai
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateTOEICPromptListsRubricCriteria(t *testing.T) {
+	s := NewScoringService("key", "")
+	rubric := Rubric{
+		Name:         "Business email",
+		Instructions: "Answer every question of the email.",
+		Criteria: []RubricCriterion{
+			{Key: "task_response", Name: "Task completion", Description: "every request is answered", Weight: 70},
+			{Key: "tone", Name: "Tone", Weight: 30},
+		},
+	}
+
+	prompt := s.createTOEICPrompt("Dear team, ...", "Reply to the email", rubric)
+	assert.Contains(t, prompt, "TASK:\nReply to the email")
+	assert.Contains(t, prompt, "Answer every question of the email.")
+	assert.Contains(t, prompt, `1. Task completion (key "task_response", 70% of the score) - every request is answered`)
+	assert.Contains(t, prompt, `"tone": {"score": [numeric score 0-200], "feedback": "[detailed feedback on tone]"}`)
+	assert.NotContains(t, prompt, "language_use")
+
+	prompt = s.createTOEICPrompt("Dear team, ...", "", DefaultRubric())
+	assert.NotContains(t, prompt, "TASK:")
+	assert.Contains(t, prompt, `6. Language Use (key "language_use", 15% of the score)`)
+}
+
+func TestParseAIAssessmentWeighsCriteria(t *testing.T) {
+	s := NewScoringService("key", "")
+	rubric := Rubric{Name: "Business email", Criteria: []RubricCriterion{
+		{Key: "task_response", Name: "Task completion", Weight: 70},
+		{Key: "tone", Name: "Tone", Weight: 30},
+	}}
+
+	response, err := s.parseAIAssessment(`{"score": 90, "band": "4", "criteria": {
+		"task_response": {"score": 180, "feedback": "All answered"},
+		"tone": {"score": 250, "feedback": "Polite"}}}`, rubric)
+	require.NoError(t, err)
+	// 180*0.7 + 200*0.3, the tone score capped at 200
+	assert.Equal(t, 186, response.Score)
+	assert.Equal(t, BandLevel9, response.Band)
+	assert.Equal(t, "Business email", response.Rubric)
+	assert.Equal(t, []CriterionScore{
+		{Key: "task_response", Name: "Task completion", Weight: 70, Score: 180, Feedback: "All answered"},
+		{Key: "tone", Name: "Tone", Weight: 30, Score: 200, Feedback: "Polite"},
+	}, response.Criteria)
+	assert.Equal(t, "All answered", response.Feedback.TaskResponse)
+
+	// Without every criterion scored the score of the reply is kept
+	response, err = s.parseAIAssessment(`{"score": 90, "band": "4", "criteria": {
+		"tone": {"score": 120, "feedback": "Polite"}}}`, rubric)
+	require.NoError(t, err)
+	assert.Equal(t, 90, response.Score)
+	assert.Equal(t, BandLevel4, response.Band)
+	assert.Len(t, response.Criteria, 1)
+}
@@ -0,0 +1,195 @@
+// Package ailimit bounds the text of submissions sent to the AI provider, so
+// that one huge writing or conversation cannot burn through tokens. Each kind
+// of submission has a word and a character limit. Oversized text is rejected,
+// or in truncate mode cut down to the chunk that fits, at a sentence end when
+// one is close.
+package ailimit
+
+import (
+	"errors"
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/toeic-app/internal/config"
+)
+
+// ErrTooLong is returned for text over the limits of its kind in reject mode,
+// or with no chunk fitting them in truncate mode
+var ErrTooLong = errors.New("text exceeds the AI submission limit")
+
+// Kinds of submissions
+const (
+	KindWriting             = "writing"
+	KindSpeaking            = "speaking"             // A speaking practice message
+	KindConversationContext = "conversation_context" // The conversation a speaking message answers
+)
+
+// Modes of handling oversized text
+const (
+	ModeReject   = "reject"
+	ModeTruncate = "truncate"
+)
+
+// Limit bounds the text of a kind of submission, 0 disables a bound
+type Limit struct {
+	MaxWords int `json:"max_words"`
+	MaxChars int `json:"max_chars"`
+}
+
+// Limits are the limits of every kind of submission
+type Limits struct {
+	Mode  string           `json:"mode"`
+	Kinds map[string]Limit `json:"kinds"`
+}
+
+// NewLimits returns the limits of the configuration
+func NewLimits(cfg config.Config) Limits {
+	return Limits{
+		Mode: cfg.AIOversizeMode,
+		Kinds: map[string]Limit{
+			KindWriting:             {MaxWords: cfg.AIMaxWritingWords, MaxChars: cfg.AIMaxWritingChars},
+			KindSpeaking:            {MaxWords: cfg.AIMaxSpeakingWords, MaxChars: cfg.AIMaxSpeakingChars},
+			KindConversationContext: {MaxChars: cfg.AIMaxContextChars},
+		},
+	}
+}
+
+// Checked is a text within the limits of its kind
+type Checked struct {
+	Text  string
+	Words int
+	Chars int
+	// Truncated is set when Text is the chunk of a longer text that fits
+	Truncated bool
+}
+
+// Check returns text when it is within the limits of its kind. An oversized
+// text is rejected with ErrTooLong, or truncated in truncate mode: writing
+// and speaking messages keep their beginning, conversation context keeps its
+// most recent end.
+func (l Limits) Check(kind, text string) (Checked, error) {
+	limit := l.Kinds[kind]
+	words := wordSpans(text)
+	chars := utf8.RuneCountInString(text)
+	checked := Checked{Text: text, Words: len(words), Chars: chars}
+	if limit.fits(len(words), chars) {
+		return checked, nil
+	}
+
+	if l.Mode != ModeTruncate {
+		return checked, fmt.Errorf("%w: %s of %d words and %d characters, at most %s", ErrTooLong, kind, len(words), chars, limit)
+	}
+	if kind == KindConversationContext {
+		checked.Text = limit.tail(text, words, chars)
+	} else {
+		checked.Text = limit.head(text, words)
+	}
+	if checked.Text == "" {
+		return checked, fmt.Errorf("%w: no part of the %s fits %s", ErrTooLong, kind, limit)
+	}
+	checked.Words = len(wordSpans(checked.Text))
+	checked.Chars = utf8.RuneCountInString(checked.Text)
+	checked.Truncated = true
+	return checked, nil
+}
+
+func (l Limit) String() string {
+	switch {
+	case l.MaxWords > 0 && l.MaxChars > 0:
+		return fmt.Sprintf("%d words and %d characters", l.MaxWords, l.MaxChars)
+	case l.MaxWords > 0:
+		return fmt.Sprintf("%d words", l.MaxWords)
+	default:
+		return fmt.Sprintf("%d characters", l.MaxChars)
+	}
+}
+
+func (l Limit) fits(words, chars int) bool {
+	return (l.MaxWords == 0 || words <= l.MaxWords) && (l.MaxChars == 0 || chars <= l.MaxChars)
+}
+
+// span is a word of a text: its byte offsets and the rune offsets of its
+// start and end
+type span struct {
+	start, end         int
+	runeStart, runeEnd int
+}
+
+// wordSpans splits text at white space like strings.Fields, keeping where
+// each word is
+func wordSpans(text string) []span {
+	var spans []span
+	inWord := false
+	runes := 0
+	for i, r := range text {
+		if unicode.IsSpace(r) {
+			if inWord {
+				spans[len(spans)-1].end = i
+				spans[len(spans)-1].runeEnd = runes
+				inWord = false
+			}
+		} else if !inWord {
+			spans = append(spans, span{start: i, runeStart: runes})
+			inWord = true
+		}
+		runes++
+	}
+	if inWord {
+		spans[len(spans)-1].end = len(text)
+		spans[len(spans)-1].runeEnd = runes
+	}
+	return spans
+}
+
+func endsSentence(text string, word span) bool {
+	switch text[word.end-1] {
+	case '.', '!', '?':
+		return true
+	}
+	return false
+}
+
+// head returns the longest beginning of text within the limit, ending at the
+// last sentence end of its second half, if any
+func (l Limit) head(text string, words []span) string {
+	n := len(words)
+	if l.MaxWords > 0 {
+		n = min(n, l.MaxWords)
+	}
+	for n > 0 && l.MaxChars > 0 && words[n-1].runeEnd > l.MaxChars {
+		n--
+	}
+	if n == 0 {
+		return ""
+	}
+	for i := n - 1; i >= n/2; i-- {
+		if endsSentence(text, words[i]) {
+			n = i + 1
+			break
+		}
+	}
+	return text[words[0].start:words[n-1].end]
+}
+
+// tail returns the longest end of text within the limit, starting after the
+// first sentence end of its first half, if any
+func (l Limit) tail(text string, words []span, chars int) string {
+	first := 0
+	if l.MaxWords > 0 {
+		first = max(first, len(words)-l.MaxWords)
+	}
+	for first < len(words) && l.MaxChars > 0 && chars-words[first].runeStart > l.MaxChars {
+		first++
+	}
+	if first == len(words) {
+		return ""
+	}
+	for i := first; i < first+(len(words)-first)/2; i++ {
+		if endsSentence(text, words[i]) {
+			first = i + 1
+			break
+		}
+	}
+	return text[words[first].start:words[len(words)-1].end]
+}
@@ -0,0 +1,53 @@
+package ailimit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckRejectsOversizedText(t *testing.T) {
+	limits := Limits{Mode: ModeReject, Kinds: map[string]Limit{KindWriting: {MaxWords: 5, MaxChars: 40}}}
+
+	checked, err := limits.Check(KindWriting, "The meeting moved to Friday.")
+	require.NoError(t, err)
+	assert.Equal(t, Checked{Text: "The meeting moved to Friday.", Words: 5, Chars: 28}, checked)
+
+	_, err = limits.Check(KindWriting, "The meeting moved to Friday afternoon.")
+	assert.ErrorIs(t, err, ErrTooLong)
+	assert.ErrorContains(t, err, "writing of 6 words and 38 characters, at most 5 words and 40 characters")
+
+	// Kinds without limits take any text
+	_, err = limits.Check(KindSpeaking, strings.Repeat("word ", 1000))
+	assert.NoError(t, err)
+}
+
+func TestCheckTruncatesAtSentenceEnds(t *testing.T) {
+	limits := Limits{Mode: ModeTruncate, Kinds: map[string]Limit{
+		KindWriting:             {MaxWords: 8},
+		KindConversationContext: {MaxChars: 40},
+	}}
+
+	checked, err := limits.Check(KindWriting, "Thank you for the invitation. I will attend the meeting on Friday.")
+	require.NoError(t, err)
+	assert.True(t, checked.Truncated)
+	assert.Equal(t, "Thank you for the invitation.", checked.Text)
+	assert.Equal(t, 5, checked.Words)
+
+	// Without a sentence end in reach the text is cut at a word
+	checked, err = limits.Check(KindWriting, "one two three four five six seven eight nine ten")
+	require.NoError(t, err)
+	assert.Equal(t, "one two three four five six seven eight", checked.Text)
+
+	// Conversation context keeps the most recent part
+	checked, err = limits.Check(KindConversationContext, "Hello, how are you? I am fine. What did you do at the weekend?")
+	require.NoError(t, err)
+	assert.Equal(t, "What did you do at the weekend?", checked.Text)
+	assert.Equal(t, 31, checked.Chars)
+
+	limits.Kinds[KindSpeaking] = Limit{MaxChars: 3}
+	_, err = limits.Check(KindSpeaking, "Unbelievable")
+	assert.ErrorIs(t, err, ErrTooLong)
+}
@@ -4,8 +4,11 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math/rand"
+	"net"
 	"net/http"
 	"time"
 
@@ -17,6 +20,43 @@ type AnalyzeClient struct {
 	baseURL    string
 	httpClient *http.Client
 	timeout    time.Duration
+	options    ClientOptions
+	outcomes   *outcomeStats
+}
+
+// ClientOptions tunes connection pooling, retries and hedging of the client
+type ClientOptions struct {
+	Timeout         time.Duration // Upper bound for a whole call including retries
+	AttemptTimeout  time.Duration // Upper bound for a single HTTP attempt
+	MaxRetries      int           // Retries after the first attempt on network errors, 429 and 5xx
+	RetryBackoff    time.Duration // Base backoff, doubled per retry with full jitter
+	HedgeDelay      time.Duration // Delay before a hedged duplicate request is sent; zero disables hedging
+	MaxConnsPerHost int           // Size of the keep-alive connection pool
+}
+
+// DefaultClientOptions returns the options used when none are configured
+func DefaultClientOptions() ClientOptions {
+	return ClientOptions{
+		Timeout:         30 * time.Second,
+		AttemptTimeout:  10 * time.Second,
+		MaxRetries:      2,
+		RetryBackoff:    200 * time.Millisecond,
+		HedgeDelay:      0,
+		MaxConnsPerHost: 32,
+	}
+}
+
+// maxRetryBackoff caps the exponential backoff between retries
+const maxRetryBackoff = 5 * time.Second
+
+// statusError is returned when the analyze service answers with a non-200 status
+type statusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("analyze service returned status %d: %s", e.StatusCode, e.Body)
 }
 
 // Suggestion represents a word suggestion with level and definition
@@ -59,32 +99,159 @@ type AnalysisResult struct {
 	Result    *TextAnalysisResponse `json:"result,omitempty"`
 	Error     string                `json:"error,omitempty"`
 	Timestamp time.Time             `json:"timestamp"`
+	Degraded  bool                  `json:"degraded,omitempty"`
 }
 
 // NewAnalyzeClient creates a new analyze service client
 func NewAnalyzeClient(baseURL string, timeout time.Duration) *AnalyzeClient {
-	if timeout == 0 {
-		timeout = 30 * time.Second
+	options := DefaultClientOptions()
+	options.Timeout = timeout
+	return NewAnalyzeClientWithOptions(baseURL, options)
+}
+
+// NewAnalyzeClientWithOptions creates an analyze service client with a pooled
+// transport and the given retry and hedging behaviour
+func NewAnalyzeClientWithOptions(baseURL string, options ClientOptions) *AnalyzeClient {
+	defaults := DefaultClientOptions()
+	if options.Timeout <= 0 {
+		options.Timeout = defaults.Timeout
+	}
+	if options.AttemptTimeout <= 0 || options.AttemptTimeout > options.Timeout {
+		options.AttemptTimeout = options.Timeout
+	}
+	if options.MaxRetries < 0 {
+		options.MaxRetries = 0
+	}
+	if options.RetryBackoff <= 0 {
+		options.RetryBackoff = defaults.RetryBackoff
+	}
+	if options.MaxConnsPerHost <= 0 {
+		options.MaxConnsPerHost = defaults.MaxConnsPerHost
+	}
+
+	// Timeouts are enforced per attempt through the request context, so the
+	// client itself has none and connections are reused across attempts
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   5 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:   true,
+		MaxIdleConns:        options.MaxConnsPerHost,
+		MaxIdleConnsPerHost: options.MaxConnsPerHost,
+		MaxConnsPerHost:     options.MaxConnsPerHost,
+		IdleConnTimeout:     90 * time.Second,
+		TLSHandshakeTimeout: 5 * time.Second,
 	}
 
 	return &AnalyzeClient{
-		baseURL: baseURL,
-		httpClient: &http.Client{
-			Timeout: timeout,
-		},
-		timeout: timeout,
+		baseURL:    baseURL,
+		httpClient: &http.Client{Transport: transport},
+		timeout:    options.Timeout,
+		options:    options,
+		outcomes:   newOutcomeStats(),
 	}
 }
 
-// AnalyzeText performs synchronous text analysis
+// AnalyzeText performs synchronous text analysis, retrying transient failures
+// with jittered backoff and hedging slow attempts when configured
 func (c *AnalyzeClient) AnalyzeText(ctx context.Context, request TextAnalysisRequest) (*TextAnalysisResponse, error) {
 	requestBody, err := json.Marshal(request)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var lastErr error
+	for attempt := 0; attempt <= c.options.MaxRetries; attempt++ {
+		if attempt > 0 {
+			if err := sleepContext(ctx, c.retryDelay(attempt)); err != nil {
+				break
+			}
+			logger.Debug("Retrying analyze request (attempt %d): %v", attempt+1, lastErr)
+		}
+
+		response, hedged, err := c.hedgedAnalyze(ctx, requestBody)
+		if err == nil {
+			outcome := OutcomeSuccess
+			if hedged {
+				outcome = OutcomeHedged
+			} else if attempt > 0 {
+				outcome = OutcomeRetried
+			}
+			c.outcomes.record(outcome, time.Since(start))
+			return response, nil
+		}
+
+		lastErr = err
+		if ctx.Err() != nil || !isRetryable(err) {
+			break
+		}
+	}
+
+	c.outcomes.record(OutcomeFailure, time.Since(start))
+	return nil, lastErr
+}
+
+// hedgedAnalyze sends the request and, if no answer arrives within the hedge
+// delay, sends a duplicate and takes whichever succeeds first. Analysis is
+// idempotent so the losing request is simply cancelled.
+func (c *AnalyzeClient) hedgedAnalyze(ctx context.Context, body []byte) (*TextAnalysisResponse, bool, error) {
+	if c.options.HedgeDelay <= 0 {
+		response, err := c.analyzeOnce(ctx, body)
+		return response, false, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type attemptResult struct {
+		response *TextAnalysisResponse
+		hedged   bool
+		err      error
+	}
+	results := make(chan attemptResult, 2)
+	launch := func(hedged bool) {
+		go func() {
+			response, err := c.analyzeOnce(ctx, body)
+			results <- attemptResult{response, hedged, err}
+		}()
+	}
+
+	launch(false)
+	inFlight := 1
+	timer := time.NewTimer(c.options.HedgeDelay)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			launch(true)
+			inFlight++
+		case result := <-results:
+			inFlight--
+			if result.err == nil {
+				return result.response, result.hedged, nil
+			}
+			if inFlight == 0 {
+				// A fast failure is left to the retry loop rather than hedged
+				return nil, false, result.err
+			}
+		}
+	}
+}
+
+// analyzeOnce performs a single POST /analyze bounded by the attempt timeout
+func (c *AnalyzeClient) analyzeOnce(ctx context.Context, body []byte) (*TextAnalysisResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.options.AttemptTimeout)
+	defer cancel()
+
 	url := fmt.Sprintf("%s/analyze", c.baseURL)
-	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(requestBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -98,8 +265,8 @@ func (c *AnalyzeClient) AnalyzeText(ctx context.Context, request TextAnalysisReq
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("analyze service returned status %d: %s", resp.StatusCode, string(body))
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, &statusError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	var words []WordAnalysis
@@ -110,6 +277,43 @@ func (c *AnalyzeClient) AnalyzeText(ctx context.Context, request TextAnalysisReq
 	return &TextAnalysisResponse{Words: words}, nil
 }
 
+// retryDelay returns an exponential backoff with full jitter for the given retry
+func (c *AnalyzeClient) retryDelay(attempt int) time.Duration {
+	backoff := c.options.RetryBackoff << (attempt - 1)
+	if backoff <= 0 || backoff > maxRetryBackoff {
+		backoff = maxRetryBackoff
+	}
+	return time.Duration(rand.Int63n(int64(backoff))) + 1
+}
+
+// isRetryable reports whether a failed attempt may succeed when repeated
+func isRetryable(err error) bool {
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= 500
+	}
+	// Network errors and attempt timeouts are transient; malformed responses are not
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// sleepContext waits for the given duration unless the context ends first
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// OutcomeCounts returns how many calls ended with each outcome
+func (c *AnalyzeClient) OutcomeCounts() map[Outcome]int64 {
+	return c.outcomes.counts()
+}
+
 // AnalyzeTextAsync performs asynchronous text analysis using goroutines
 func (c *AnalyzeClient) AnalyzeTextAsync(ctx context.Context, userID int32, request TextAnalysisRequest, resultChan chan<- AnalysisResult) {
 	go func() {
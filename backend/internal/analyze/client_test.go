@@ -0,0 +1,124 @@
+package analyze
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testOptions() ClientOptions {
+	return ClientOptions{
+		Timeout:        5 * time.Second,
+		AttemptTimeout: time.Second,
+		MaxRetries:     2,
+		RetryBackoff:   time.Millisecond,
+	}
+}
+
+func TestAnalyzeTextRetriesTransientFailures(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`[{"word": "hello", "level": "A1", "count": 1}]`))
+	}))
+	defer server.Close()
+
+	client := NewAnalyzeClientWithOptions(server.URL, testOptions())
+	response, err := client.AnalyzeText(context.Background(), TextAnalysisRequest{Text: "hello"})
+	require.NoError(t, err)
+	require.Len(t, response.Words, 1)
+	assert.Equal(t, "A1", response.Words[0].Level)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	assert.Equal(t, int64(1), client.OutcomeCounts()[OutcomeRetried])
+}
+
+func TestAnalyzeTextDoesNotRetryClientErrors(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	client := NewAnalyzeClientWithOptions(server.URL, testOptions())
+	_, err := client.AnalyzeText(context.Background(), TextAnalysisRequest{Text: "hello"})
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	assert.Equal(t, int64(1), client.OutcomeCounts()[OutcomeFailure])
+}
+
+func TestAnalyzeTextGivesUpAfterMaxRetries(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client := NewAnalyzeClientWithOptions(server.URL, testOptions())
+	_, err := client.AnalyzeText(context.Background(), TextAnalysisRequest{Text: "hello"})
+	assert.Error(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestAnalyzeTextHedgesSlowRequests(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			// The first request stalls until the hedge wins and cancels it
+			io.Copy(io.Discard, r.Body)
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+			return
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	options := testOptions()
+	options.MaxRetries = 0
+	options.HedgeDelay = 20 * time.Millisecond
+	client := NewAnalyzeClientWithOptions(server.URL, options)
+
+	start := time.Now()
+	_, err := client.AnalyzeText(context.Background(), TextAnalysisRequest{Text: "hello"})
+	require.NoError(t, err)
+	assert.Less(t, time.Since(start), options.AttemptTimeout)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	assert.Equal(t, int64(1), client.OutcomeCounts()[OutcomeHedged])
+}
+
+func TestRetryDelayIsJitteredAndCapped(t *testing.T) {
+	client := NewAnalyzeClientWithOptions("http://localhost", ClientOptions{RetryBackoff: 100 * time.Millisecond})
+	for i := 0; i < 50; i++ {
+		delay := client.retryDelay(2)
+		assert.Greater(t, delay, time.Duration(0))
+		assert.LessOrEqual(t, delay, 200*time.Millisecond)
+	}
+	assert.LessOrEqual(t, client.retryDelay(40), maxRetryBackoff)
+}
+
+func TestFallbackAnalysis(t *testing.T) {
+	response := fallbackAnalysis("The cat sat. The cat's hat, 'the' end!")
+	words := make(map[string]int)
+	for _, word := range response.Words {
+		words[word.Word] = word.Count
+		assert.Equal(t, "unknown", word.Level)
+	}
+	assert.Equal(t, 3, words["the"])
+	assert.Equal(t, 1, words["cat"])
+	assert.Equal(t, 1, words["cat's"])
+	assert.Equal(t, "the", response.Words[0].Word)
+}
@@ -0,0 +1,69 @@
+package analyze
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Outcome labels how a request for text analysis was answered
+type Outcome string
+
+const (
+	OutcomeSuccess  Outcome = "success"  // First attempt succeeded
+	OutcomeRetried  Outcome = "retried"  // Succeeded after at least one retry
+	OutcomeHedged   Outcome = "hedged"   // The hedged duplicate answered first
+	OutcomeFailure  Outcome = "failure"  // All attempts failed
+	OutcomeDegraded Outcome = "degraded" // Answered locally because the service is down
+	OutcomeCached   Outcome = "cached"   // Answered from the result cache
+)
+
+var (
+	analyzeRequestsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "analyze_requests_total",
+			Help: "Total number of text analysis requests by outcome",
+		},
+		[]string{"outcome"},
+	)
+	analyzeRequestDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "analyze_request_duration_seconds",
+			Help:    "Duration of text analysis requests by outcome",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"outcome"},
+	)
+)
+
+// outcomeStats mirrors the outcome counters for the stats endpoint
+type outcomeStats struct {
+	mu     sync.Mutex
+	totals map[Outcome]int64
+}
+
+func newOutcomeStats() *outcomeStats {
+	return &outcomeStats{totals: make(map[Outcome]int64)}
+}
+
+func (o *outcomeStats) record(outcome Outcome, duration time.Duration) {
+	analyzeRequestsTotal.WithLabelValues(string(outcome)).Inc()
+	analyzeRequestDuration.WithLabelValues(string(outcome)).Observe(duration.Seconds())
+
+	o.mu.Lock()
+	o.totals[outcome]++
+	o.mu.Unlock()
+}
+
+func (o *outcomeStats) counts() map[Outcome]int64 {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	counts := make(map[Outcome]int64, len(o.totals))
+	for outcome, total := range o.totals {
+		counts[outcome] = total
+	}
+	return counts
+}
@@ -3,8 +3,11 @@ package analyze
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+	"unicode"
 
 	"github.com/toeic-app/internal/config"
 	"github.com/toeic-app/internal/logger"
@@ -22,6 +25,7 @@ type Service struct {
 	healthStatus    bool
 	lastHealthCheck time.Time
 	healthMutex     sync.RWMutex
+	degradedMode    bool
 }
 
 // ServiceConfig holds configuration for the analyze service
@@ -52,7 +56,14 @@ func NewService(cfg config.Config) *Service {
 		serviceConfig.Timeout = cfg.AnalyzeServiceTimeout
 	}
 
-	client := NewAnalyzeClient(serviceConfig.BaseURL, serviceConfig.Timeout)
+	client := NewAnalyzeClientWithOptions(serviceConfig.BaseURL, ClientOptions{
+		Timeout:         serviceConfig.Timeout,
+		AttemptTimeout:  cfg.AnalyzeAttemptTimeout,
+		MaxRetries:      cfg.AnalyzeMaxRetries,
+		RetryBackoff:    cfg.AnalyzeRetryBackoff,
+		HedgeDelay:      cfg.AnalyzeHedgeDelay,
+		MaxConnsPerHost: cfg.AnalyzeMaxConns,
+	})
 
 	service := &Service{
 		client:       client,
@@ -61,6 +72,7 @@ func NewService(cfg config.Config) *Service {
 		cacheTimeout: serviceConfig.CacheTimeout,
 		maxCacheSize: serviceConfig.MaxCacheSize,
 		healthStatus: false,
+		degradedMode: cfg.AnalyzeDegradedMode,
 	}
 
 	// Start health monitoring
@@ -75,24 +87,36 @@ func (s *Service) startHealthMonitoring(interval time.Duration) {
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
+	// Check right away so requests are not degraded until the first tick
+	s.checkHealth()
 	for range ticker.C {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		healthy := s.client.IsHealthy(ctx)
-		cancel()
-
-		s.healthMutex.Lock()
-		s.healthStatus = healthy
-		s.lastHealthCheck = time.Now()
-		s.healthMutex.Unlock()
-
-		if healthy {
-			logger.Debug("Analyze service health check passed")
-		} else {
-			logger.Warn("Analyze service health check failed")
-		}
+		s.checkHealth()
 	}
 }
 
+// checkHealth probes the analyze service and records the result
+func (s *Service) checkHealth() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	healthy := s.client.IsHealthy(ctx)
+	cancel()
+
+	s.setHealthStatus(healthy)
+
+	if healthy {
+		logger.Debug("Analyze service health check passed")
+	} else {
+		logger.Warn("Analyze service health check failed")
+	}
+}
+
+// setHealthStatus records the current health of the analyze service
+func (s *Service) setHealthStatus(healthy bool) {
+	s.healthMutex.Lock()
+	s.healthStatus = healthy
+	s.lastHealthCheck = time.Now()
+	s.healthMutex.Unlock()
+}
+
 // IsHealthy returns the current health status of the analyze service
 func (s *Service) IsHealthy() bool {
 	s.healthMutex.RLock()
@@ -107,6 +131,12 @@ func (s *Service) GetHealthStatus() (bool, time.Time) {
 	return s.healthStatus, s.lastHealthCheck
 }
 
+// DegradedModeEnabled reports whether requests are answered locally while the
+// analyze service is down instead of failing
+func (s *Service) DegradedModeEnabled() bool {
+	return s.degradedMode
+}
+
 // generateCacheKey generates a cache key for the request
 func (s *Service) generateCacheKey(userID int32, text string, minLevel string) string {
 	return fmt.Sprintf("%d:%s:%s", userID, text, minLevel)
@@ -191,15 +221,22 @@ func (s *Service) cleanupCache() {
 
 // AnalyzeTextSync performs synchronous text analysis with caching
 func (s *Service) AnalyzeTextSync(ctx context.Context, userID int32, text string, minSynonymLevel string) (*AnalysisResult, error) {
+	atomic.AddInt64(&s.requestCounter, 1)
+	start := time.Now()
+
 	// Check cache first
 	cacheKey := s.generateCacheKey(userID, text, minSynonymLevel)
 	if cached := s.getCachedResult(cacheKey); cached != nil {
 		logger.Debug("Returning cached analysis result for user %d", userID)
+		s.client.outcomes.record(OutcomeCached, time.Since(start))
 		return cached, nil
 	}
 
 	// Check if service is healthy
 	if !s.IsHealthy() {
+		if s.degradedMode {
+			return s.degradedResult(userID, text, start), nil
+		}
 		return nil, fmt.Errorf("analyze service is not healthy")
 	}
 
@@ -210,6 +247,13 @@ func (s *Service) AnalyzeTextSync(ctx context.Context, userID int32, text string
 
 	analysisResponse, err := s.client.AnalyzeText(ctx, request)
 	if err != nil {
+		if s.degradedMode && ctx.Err() == nil {
+			// Retries are exhausted, so treat the service as down until the
+			// next health check rather than making every request wait
+			logger.Warn("Analyze service request failed, switching to degraded mode: %v", err)
+			s.setHealthStatus(false)
+			return s.degradedResult(userID, text, start), nil
+		}
 		return nil, fmt.Errorf("failed to analyze text: %w", err)
 	}
 
@@ -227,6 +271,43 @@ func (s *Service) AnalyzeTextSync(ctx context.Context, userID int32, text string
 	return result, nil
 }
 
+// degradedResult answers a request locally with word counts only. Degraded
+// results carry no levels or suggestions and are never cached.
+func (s *Service) degradedResult(userID int32, text string, start time.Time) *AnalysisResult {
+	s.client.outcomes.record(OutcomeDegraded, time.Since(start))
+	return &AnalysisResult{
+		UserID:    userID,
+		Text:      text,
+		Result:    fallbackAnalysis(text),
+		Timestamp: time.Now(),
+		Degraded:  true,
+	}
+}
+
+// fallbackAnalysis counts the words of a text in order of first appearance
+func fallbackAnalysis(text string) *TextAnalysisResponse {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+
+	words := make([]WordAnalysis, 0, len(fields))
+	index := make(map[string]int, len(fields))
+	for _, field := range fields {
+		word := strings.Trim(field, "'")
+		if word == "" {
+			continue
+		}
+		if i, ok := index[word]; ok {
+			words[i].Count++
+			continue
+		}
+		index[word] = len(words)
+		words = append(words, WordAnalysis{Word: word, Level: "unknown", Count: 1})
+	}
+
+	return &TextAnalysisResponse{Words: words}
+}
+
 // AnalyzeTextAsync performs asynchronous text analysis
 func (s *Service) AnalyzeTextAsync(ctx context.Context, userID int32, text string, minSynonymLevel string, callback func(*AnalysisResult, error)) {
 	go func() {
@@ -282,7 +363,7 @@ func (s *Service) AnalyzeMultipleTexts(ctx context.Context, userID int32, texts
 	go func() {
 		defer close(resultChan)
 
-		if !s.IsHealthy() {
+		if !s.IsHealthy() && !s.degradedMode {
 			select {
 			case resultChan <- struct {
 				Results []AnalysisResult
@@ -406,9 +487,14 @@ func (s *Service) GetStats() map[string]interface{} {
 	stats := map[string]interface{}{
 		"healthy":           healthy,
 		"last_health_check": lastCheck.Format(time.RFC3339),
-		"request_count":     s.requestCounter,
+		"request_count":     atomic.LoadInt64(&s.requestCounter),
 		"service_url":       s.client.baseURL,
 		"timeout":           s.client.timeout.String(),
+		"attempt_timeout":   s.client.options.AttemptTimeout.String(),
+		"max_retries":       s.client.options.MaxRetries,
+		"hedge_delay":       s.client.options.HedgeDelay.String(),
+		"degraded_mode":     s.degradedMode,
+		"outcomes":          s.client.OutcomeCounts(),
 	}
 
 	// Merge cache stats
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/accesslog"
+	"github.com/toeic-app/internal/token"
+)
+
+// accessPurposeHeader carries the purpose code of a read of another user's
+// data, e.g. "grading"; reads without it are logged as "unspecified"
+const accessPurposeHeader = "X-Access-Purpose"
+
+// recordDataAccess logs reads of other users' personal data by the caller
+// before the data is returned. It writes an error response and returns
+// false when the purpose is unknown or the read could not be logged, so
+// unlogged data is never served.
+func (server *Server) recordDataAccess(ctx *gin.Context, accesses ...accesslog.Access) bool {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+	purpose := strings.ToLower(strings.TrimSpace(ctx.GetHeader(accessPurposeHeader)))
+	if purpose == "" {
+		purpose = accesslog.PurposeUnspecified
+	}
+
+	err := server.accessLog.Record(ctx, authPayload.ID, purpose, accesses...)
+	if errors.Is(err, accesslog.ErrUnknownPurpose) {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid access purpose",
+			fmt.Errorf("%s must be one of %s", accessPurposeHeader, strings.Join(accesslog.Purposes(), ", ")))
+		return false
+	}
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to record data access", err)
+		return false
+	}
+	return true
+}
+
+type listAccessLogQuery struct {
+	Limit  int32 `form:"limit,default=20" binding:"min=1,max=100"`
+	Offset int32 `form:"offset" binding:"min=0"`
+}
+
+// @Summary     List reads of my data
+// @Description Lists when other users, such as teachers and administrators, read your writings, speaking sessions and exam answers, with the purpose they gave. Entries are kept for DATA_ACCESS_LOG_RETENTION_DAYS.
+// @Tags        users
+// @Produce     json
+// @Param       limit query int false "Maximum entries" default(20)
+// @Param       offset query int false "Entries to skip" default(0)
+// @Success     200 {object} Response{data=[]accesslog.Entry} "Access log retrieved successfully"
+// @Failure     400 {object} Response "Invalid query parameters"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     500 {object} Response "Failed to retrieve access log"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/users/me/access-log [get]
+func (server *Server) listMyAccessLog(ctx *gin.Context) {
+	var query listAccessLogQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	entries, total, err := server.accessLog.List(ctx, authPayload.ID, query.Limit, query.Offset)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve access log", err)
+		return
+	}
+
+	PaginatedResponse(ctx, http.StatusOK, "Access log retrieved successfully", entries,
+		NewPagination(query.Limit, query.Offset, len(entries)).WithTotal(total))
+}
@@ -0,0 +1,228 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/account"
+	"github.com/toeic-app/internal/logger"
+)
+
+// ensureUnlocked writes a 423 response and returns false when the account
+// is locked. Failing to read the lock never blocks sign-in.
+func (server *Server) ensureUnlocked(ctx *gin.Context, userID int32) bool {
+	status, err := server.accounts.Status(ctx, userID)
+	if err != nil {
+		logger.Error("Failed to check lock of account %d: %v", userID, err)
+		return true
+	}
+	if !status.Locked(time.Now()) {
+		return true
+	}
+	ErrorResponse(ctx, http.StatusLocked, "Account is locked",
+		fmt.Errorf("account is locked until %s", status.LockedUntil.UTC().Format(time.RFC3339)))
+	return false
+}
+
+// recordFailedSignIn counts a wrong password against an account, except
+// while read-only
+func (server *Server) recordFailedSignIn(ctx *gin.Context, userID int32) {
+	if server.isReadOnly(ctx) {
+		return
+	}
+	status, err := server.accounts.RecordFailure(ctx, userID)
+	if err != nil {
+		logger.Error("Failed to record failed sign-in of account %d: %v", userID, err)
+		return
+	}
+	if status.Locked(time.Now()) && status.Reason == account.ReasonFailedLogins {
+		logger.Warn("Account %d locked until %s after repeated failed sign-ins", userID, status.LockedUntil.Format(time.RFC3339))
+	}
+}
+
+// recordSignIn clears failed sign-ins and starts a session for the client,
+// except while read-only
+func (server *Server) recordSignIn(ctx *gin.Context, userID int32) {
+	if server.isReadOnly(ctx) {
+		return
+	}
+	if err := server.accounts.RecordSuccess(ctx, userID); err != nil {
+		logger.Error("Failed to reset failed sign-ins of account %d: %v", userID, err)
+	}
+	if err := server.accounts.StartSession(ctx, userID, ctx.ClientIP(), ctx.Request.UserAgent()); err != nil {
+		logger.Error("Failed to record session of user %d: %v", userID, err)
+	}
+}
+
+type userAccountURI struct {
+	ID int32 `uri:"id" binding:"required,min=1"`
+}
+
+type userAccountQuery struct {
+	IncludeClosed bool `form:"include_closed"`
+	Limit         int  `form:"limit,default=20" binding:"min=1,max=100"`
+}
+
+// UserAccountResponse is the lock state and sessions of a user
+type UserAccountResponse struct {
+	UserID   int32             `json:"user_id"`
+	Username string            `json:"username"`
+	Lock     account.Status    `json:"lock"`
+	Locked   bool              `json:"locked"`
+	Sessions []account.Session `json:"sessions"`
+}
+
+type lockUserAccountRequest struct {
+	DurationMinutes int    `json:"duration_minutes" binding:"required,min=1" example:"1440"`
+	Reason          string `json:"reason" binding:"max=100" sanitize:"plain" example:"Suspicious activity"`
+}
+
+func (server *Server) userAccountResponse(ctx *gin.Context, userID int32, query userAccountQuery) (UserAccountResponse, error) {
+	user, err := server.store.GetUser(ctx, userID)
+	if err != nil {
+		return UserAccountResponse{}, err
+	}
+	status, err := server.accounts.Status(ctx, userID)
+	if err != nil {
+		return UserAccountResponse{}, err
+	}
+	sessions, err := server.accounts.Sessions(ctx, userID, query.IncludeClosed, query.Limit)
+	if err != nil {
+		return UserAccountResponse{}, err
+	}
+	return UserAccountResponse{
+		UserID:   user.ID,
+		Username: user.Username,
+		Lock:     status,
+		Locked:   status.Locked(time.Now()),
+		Sessions: sessions,
+	}, nil
+}
+
+// @Summary     Get the account state of a user
+// @Description Shows failed sign-ins, the lock of an account and its latest sessions (admin only). Sessions are sign-ins that last as long as their refresh token; only open ones are listed unless include_closed is set.
+// @Tags        admin
+// @Produce     json
+// @Param       id path int true "User ID"
+// @Param       include_closed query bool false "Include ended and expired sessions"
+// @Param       limit query int false "Maximum sessions" default(20)
+// @Success     200 {object} Response{data=UserAccountResponse} "User account retrieved successfully"
+// @Failure     403 {object} Response "Missing users.read permission"
+// @Failure     404 {object} Response "User not found"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/users/{id}/account [get]
+func (server *Server) getUserAccount(ctx *gin.Context) {
+	var uri userAccountURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+	var query userAccountQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	response, err := server.userAccountResponse(ctx, uri.ID, query)
+	if err != nil {
+		userAccountError(ctx, err, "Failed to get user account")
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "User account retrieved successfully", response)
+}
+
+// @Summary     Lock a user account
+// @Description Blocks sign-in and token refresh of a user for a while (admin only). Tokens already issued stay valid until they expire.
+// @Tags        admin
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "User ID"
+// @Param       lock body lockUserAccountRequest true "Lock"
+// @Success     200 {object} Response{data=UserAccountResponse} "User account locked successfully"
+// @Failure     403 {object} Response "Missing users.update permission"
+// @Failure     404 {object} Response "User not found"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/users/{id}/lock [post]
+func (server *Server) lockUserAccount(ctx *gin.Context) {
+	var uri userAccountURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+	var req lockUserAccountRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	server.sanitizer.Struct(&req)
+	if _, err := server.store.GetUser(ctx, uri.ID); err != nil {
+		userAccountError(ctx, err, "Failed to lock user account")
+		return
+	}
+
+	until := time.Now().Add(time.Duration(req.DurationMinutes) * time.Minute)
+	if _, err := server.accounts.Lock(ctx, uri.ID, until, req.Reason); err != nil {
+		userAccountError(ctx, err, "Failed to lock user account")
+		return
+	}
+	logger.Info("Account %d locked until %s by an administrator", uri.ID, until.Format(time.RFC3339))
+
+	response, err := server.userAccountResponse(ctx, uri.ID, userAccountQuery{Limit: account.DefaultSessionLimit})
+	if err != nil {
+		userAccountError(ctx, err, "Failed to get user account")
+		return
+	}
+	SuccessResponse(ctx, http.StatusOK, "User account locked successfully", response)
+}
+
+// @Summary     Unlock a user account
+// @Description Lifts the lock of a user and clears their failed sign-ins (admin only)
+// @Tags        admin
+// @Produce     json
+// @Param       id path int true "User ID"
+// @Success     200 {object} Response{data=UserAccountResponse} "User account unlocked successfully"
+// @Failure     403 {object} Response "Missing users.update permission"
+// @Failure     404 {object} Response "User not found"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/users/{id}/unlock [post]
+func (server *Server) unlockUserAccount(ctx *gin.Context) {
+	var uri userAccountURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+	if _, err := server.store.GetUser(ctx, uri.ID); err != nil {
+		userAccountError(ctx, err, "Failed to unlock user account")
+		return
+	}
+
+	if _, err := server.accounts.Unlock(ctx, uri.ID); err != nil {
+		userAccountError(ctx, err, "Failed to unlock user account")
+		return
+	}
+	logger.Info("Account %d unlocked by an administrator", uri.ID)
+
+	response, err := server.userAccountResponse(ctx, uri.ID, userAccountQuery{Limit: account.DefaultSessionLimit})
+	if err != nil {
+		userAccountError(ctx, err, "Failed to get user account")
+		return
+	}
+	SuccessResponse(ctx, http.StatusOK, "User account unlocked successfully", response)
+}
+
+// userAccountError writes the response of a failed account operation
+func userAccountError(ctx *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		ErrorResponse(ctx, http.StatusNotFound, "User not found", err)
+	case errors.Is(err, account.ErrInvalidLock):
+		ErrorResponse(ctx, http.StatusBadRequest, err.Error(), err)
+	default:
+		ErrorResponse(ctx, http.StatusInternalServerError, message, err)
+	}
+}
@@ -0,0 +1,216 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/achievement"
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/token"
+)
+
+// BadgeResponse is a badge definition as seen by admins
+type BadgeResponse struct {
+	ID           int32     `json:"id"`
+	Code         string    `json:"code"`
+	Name         string    `json:"name"`
+	Description  string    `json:"description"`
+	IconURL      string    `json:"icon_url,omitempty"`
+	RuleType     string    `json:"rule_type"`
+	ActivityType string    `json:"activity_type,omitempty"`
+	Threshold    int32     `json:"threshold"`
+	IsActive     bool      `json:"is_active"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// UpdateBadgeRequest replaces the definition of a badge
+type UpdateBadgeRequest struct {
+	Name         string `json:"name" binding:"required,max=100"`
+	Description  string `json:"description" binding:"max=1000"`
+	IconURL      string `json:"icon_url" binding:"omitempty,url,max=255"`
+	RuleType     string `json:"rule_type" binding:"required,oneof=activity_count streak_days words_mastered total_xp" example:"activity_count"`
+	ActivityType string `json:"activity_type" binding:"max=50" example:"exam_completed"` // Required for activity_count rules
+	Threshold    int32  `json:"threshold" binding:"required,min=1" example:"1"`
+	IsActive     bool   `json:"is_active"`
+}
+
+// CreateBadgeRequest defines a new badge
+type CreateBadgeRequest struct {
+	Code string `json:"code" binding:"required,max=50" example:"first_exam"`
+	UpdateBadgeRequest
+}
+
+func (req UpdateBadgeRequest) input() achievement.BadgeInput {
+	return achievement.BadgeInput{
+		Name:         req.Name,
+		Description:  req.Description,
+		IconURL:      req.IconURL,
+		RuleType:     req.RuleType,
+		ActivityType: req.ActivityType,
+		Threshold:    req.Threshold,
+		IsActive:     req.IsActive,
+	}
+}
+
+func toBadgeResponse(badge db.Badge) BadgeResponse {
+	return BadgeResponse{
+		ID:           badge.ID,
+		Code:         badge.Code,
+		Name:         badge.Name,
+		Description:  badge.Description,
+		IconURL:      badge.IconUrl.String,
+		RuleType:     badge.RuleType,
+		ActivityType: badge.ActivityType.String,
+		Threshold:    badge.Threshold,
+		IsActive:     badge.IsActive,
+		CreatedAt:    badge.CreatedAt,
+		UpdatedAt:    badge.UpdatedAt,
+	}
+}
+
+// achievementErrorStatus maps achievement errors to HTTP statuses
+func achievementErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, achievement.ErrInvalidRule):
+		return http.StatusBadRequest
+	case errors.Is(err, achievement.ErrBadgeNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, achievement.ErrDuplicateBadge):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// @Summary     List my badges
+// @Description Lists the badges earned by the current user followed by the active badges not earned yet, with the progress towards each
+// @Tags        achievements
+// @Produce     json
+// @Success     200 {object} Response{data=[]achievement.BadgeStatus} "Badges retrieved successfully"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     500 {object} Response "Failed to retrieve badges"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/users/me/badges [get]
+func (server *Server) listMyBadges(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	badges, err := server.achievements.Badges(ctx, authPayload.ID)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve badges", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Badges retrieved successfully", badges)
+}
+
+// @Summary     List badge definitions
+// @Description List every badge definition, including inactive ones (admin only)
+// @Tags        achievements
+// @Produce     json
+// @Success     200 {object} Response{data=[]BadgeResponse} "Badges retrieved successfully"
+// @Failure     403 {object} Response "Insufficient permissions"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/badges [get]
+func (server *Server) listBadges(ctx *gin.Context) {
+	badges, err := server.achievements.ListBadges(ctx)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve badges", err)
+		return
+	}
+
+	resp := make([]BadgeResponse, 0, len(badges))
+	for _, badge := range badges {
+		resp = append(resp, toBadgeResponse(badge))
+	}
+	SuccessResponse(ctx, http.StatusOK, "Badges retrieved successfully", resp)
+}
+
+// @Summary     Create badge
+// @Description Define a new badge (admin only). Users who already qualify receive it with their next activity.
+// @Tags        achievements
+// @Accept      json
+// @Produce     json
+// @Param       badge body CreateBadgeRequest true "Badge"
+// @Success     201 {object} Response{data=BadgeResponse} "Badge created successfully"
+// @Failure     400 {object} Response "Invalid request"
+// @Failure     403 {object} Response "Insufficient permissions"
+// @Failure     409 {object} Response "Badge code already exists"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/badges [post]
+func (server *Server) createBadge(ctx *gin.Context) {
+	var req CreateBadgeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request parameters", err)
+		return
+	}
+
+	badge, err := server.achievements.CreateBadge(ctx, req.Code, req.input())
+	if err != nil {
+		ErrorResponse(ctx, achievementErrorStatus(err), "Failed to create badge", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusCreated, "Badge created successfully", toBadgeResponse(badge))
+}
+
+// @Summary     Update badge
+// @Description Replace the definition of a badge (admin only). Badges already awarded are kept; set is_active=false to retire a badge.
+// @Tags        achievements
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Badge ID"
+// @Param       badge body UpdateBadgeRequest true "Badge"
+// @Success     200 {object} Response{data=BadgeResponse} "Badge updated successfully"
+// @Failure     400 {object} Response "Invalid request"
+// @Failure     404 {object} Response "Badge not found"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/badges/{id} [put]
+func (server *Server) updateBadge(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid badge ID", err)
+		return
+	}
+
+	var req UpdateBadgeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request parameters", err)
+		return
+	}
+
+	badge, err := server.achievements.UpdateBadge(ctx, int32(id), req.input())
+	if err != nil {
+		ErrorResponse(ctx, achievementErrorStatus(err), "Failed to update badge", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Badge updated successfully", toBadgeResponse(badge))
+}
+
+// @Summary     Delete badge
+// @Description Delete a badge and remove it from every user (admin only)
+// @Tags        achievements
+// @Produce     json
+// @Param       id path int true "Badge ID"
+// @Success     200 {object} Response "Badge deleted successfully"
+// @Failure     404 {object} Response "Badge not found"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/badges/{id} [delete]
+func (server *Server) deleteBadge(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid badge ID", err)
+		return
+	}
+
+	if err := server.achievements.DeleteBadge(ctx, int32(id)); err != nil {
+		ErrorResponse(ctx, achievementErrorStatus(err), "Failed to delete badge", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Badge deleted successfully", nil)
+}
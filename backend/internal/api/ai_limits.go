@@ -0,0 +1,35 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/ailimit"
+	"github.com/toeic-app/internal/middleware"
+)
+
+// aiRateLimit limits requests to the AI endpoints per user with a bucket of
+// their own, AI_RATE_LIMIT_PER_MINUTE and AI_RATE_LIMIT_PER_HOUR, on top of
+// the general rate limits. It does nothing while rate limiting is disabled.
+func (server *Server) aiRateLimit() gin.HandlerFunc {
+	if server.rateLimiter == nil {
+		return func(ctx *gin.Context) { ctx.Next() }
+	}
+	return server.rateLimiter.Bucket("ai", []middleware.RateWindow{
+		{Limit: server.config.AIRateLimitPerMinute, Window: time.Minute},
+		{Limit: server.config.AIRateLimitPerHour, Window: time.Hour},
+	})
+}
+
+// checkAIText checks a text against the AI limits of its kind, responding
+// with 413 when it is too long. It returns the text to send, which is
+// truncated in truncate mode, and false when the request was answered.
+func (server *Server) checkAIText(ctx *gin.Context, kind, text string) (ailimit.Checked, bool) {
+	checked, err := server.aiLimits.Check(kind, text)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusRequestEntityTooLarge, "Text too long for AI processing", err)
+		return checked, false
+	}
+	return checked, true
+}
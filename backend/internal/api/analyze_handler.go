@@ -31,10 +31,11 @@ type analyzeTextResponse struct {
 	Error     string                        `json:"error,omitempty"`
 	Timestamp string                        `json:"timestamp"`
 	Cached    bool                          `json:"cached,omitempty"`
+	Degraded  bool                          `json:"degraded,omitempty"`
 }
 
 // @Summary Analyze text
-// @Description Analyze English text to get word levels and synonym suggestions
+// @Description Analyze English text to get word levels and synonym suggestions. When the analyze service is down and degraded mode is enabled, the response is marked degraded and contains word counts only.
 // @Tags text-analysis
 // @Accept json
 // @Produce json
@@ -72,7 +73,7 @@ func (server *Server) analyzeText(ctx *gin.Context) {
 		return
 	}
 
-	if !server.analyzeService.IsHealthy() {
+	if !server.analyzeService.IsHealthy() && !server.analyzeService.DegradedModeEnabled() {
 		ErrorResponse(ctx, http.StatusServiceUnavailable, "Analyze service is not healthy", nil)
 		return
 	}
@@ -127,6 +128,12 @@ func (server *Server) analyzeText(ctx *gin.Context) {
 		Result:    analysisResult.Result,
 		Error:     analysisResult.Error,
 		Timestamp: analysisResult.Timestamp.Format(time.RFC3339),
+		Degraded:  analysisResult.Degraded,
+	}
+
+	if analysisResult.Degraded {
+		SuccessResponse(ctx, http.StatusOK, "Analyze service unavailable, returning basic word counts", response)
+		return
 	}
 
 	SuccessResponse(ctx, http.StatusOK, "Text analyzed successfully", response)
@@ -182,7 +189,7 @@ func (server *Server) analyzeMultipleTexts(ctx *gin.Context) {
 		return
 	}
 
-	if !server.analyzeService.IsHealthy() {
+	if !server.analyzeService.IsHealthy() && !server.analyzeService.DegradedModeEnabled() {
 		ErrorResponse(ctx, http.StatusServiceUnavailable, "Analyze service is not healthy", nil)
 		return
 	}
@@ -211,6 +218,7 @@ func (server *Server) analyzeMultipleTexts(ctx *gin.Context) {
 				Result:    analysisResult.Result,
 				Error:     analysisResult.Error,
 				Timestamp: analysisResult.Timestamp.Format(time.RFC3339),
+				Degraded:  analysisResult.Degraded,
 			}
 		}
 
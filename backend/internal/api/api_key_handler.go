@@ -0,0 +1,229 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/apikey"
+	"github.com/toeic-app/internal/token"
+)
+
+// createAPIKeyRequest issues a key to a third-party tool. Omitted limits use
+// the configured defaults.
+type createAPIKeyRequest struct {
+	Name              string `json:"name" binding:"required,max=100" example:"Flashcards app"`
+	ContactEmail      string `json:"contact_email" binding:"omitempty,max=255" example:"dev@example.com"`
+	DailyQuota        int32  `json:"daily_quota" binding:"min=0" example:"10000"`
+	RequestsPerMinute int32  `json:"requests_per_minute" binding:"min=0" example:"60"`
+}
+
+// updateAPIKeyLimitsRequest changes the quotas of a key
+type updateAPIKeyLimitsRequest struct {
+	DailyQuota        int32 `json:"daily_quota" binding:"required,min=1" example:"20000"`
+	RequestsPerMinute int32 `json:"requests_per_minute" binding:"required,min=1" example:"120"`
+}
+
+type apiKeyUsageRequest struct {
+	Days int `form:"days,default=30" binding:"min=1,max=365"`
+}
+
+// parseAPIKeyID reads the key ID path parameter
+func parseAPIKeyID(ctx *gin.Context) (int32, bool) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 32)
+	if err != nil || id <= 0 {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid API key ID", err)
+		return 0, false
+	}
+	return int32(id), true
+}
+
+// @Summary     Create public API key
+// @Description Issues an API key for the public word and grammar API (admin only). The key is only returned in this response.
+// @Tags        admin
+// @Accept      json
+// @Produce     json
+// @Param       key body createAPIKeyRequest true "Key owner and limits"
+// @Success     201 {object} Response{data=apikey.CreatedKey} "API key created successfully"
+// @Failure     400 {object} Response "Invalid request parameters"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     403 {object} Response "Forbidden"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/api-keys [post]
+func (server *Server) createAPIKey(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	var req createAPIKeyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request parameters", err)
+		return
+	}
+
+	key, err := server.apiKeys.Create(ctx, req.Name, req.ContactEmail, apikey.Limits{
+		DailyQuota:        req.DailyQuota,
+		RequestsPerMinute: req.RequestsPerMinute,
+	}, authPayload.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, apikey.ErrInvalidName), errors.Is(err, apikey.ErrInvalidEmail), errors.Is(err, apikey.ErrInvalidLimits):
+			ErrorResponse(ctx, http.StatusBadRequest, err.Error(), err)
+		default:
+			ErrorResponse(ctx, http.StatusInternalServerError, "Failed to create API key", err)
+		}
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusCreated, "API key created successfully", key)
+}
+
+// @Summary     List public API keys
+// @Description Lists issued API keys, including revoked ones (admin only)
+// @Tags        admin
+// @Produce     json
+// @Success     200 {object} Response{data=[]apikey.Key} "API keys retrieved successfully"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     403 {object} Response "Forbidden"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/api-keys [get]
+func (server *Server) listAPIKeys(ctx *gin.Context) {
+	keys, err := server.apiKeys.List(ctx)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to list API keys", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "API keys retrieved successfully", keys)
+}
+
+// @Summary     Update public API key limits
+// @Description Changes the daily quota and per-minute limit of a key (admin only)
+// @Tags        admin
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "API key ID"
+// @Param       limits body updateAPIKeyLimitsRequest true "New limits"
+// @Success     200 {object} Response{data=apikey.Key} "API key limits updated successfully"
+// @Failure     400 {object} Response "Invalid request parameters"
+// @Failure     404 {object} Response "API key not found"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/api-keys/{id} [patch]
+func (server *Server) updateAPIKeyLimits(ctx *gin.Context) {
+	id, ok := parseAPIKeyID(ctx)
+	if !ok {
+		return
+	}
+
+	var req updateAPIKeyLimitsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request parameters", err)
+		return
+	}
+
+	key, err := server.apiKeys.UpdateLimits(ctx, id, apikey.Limits{
+		DailyQuota:        req.DailyQuota,
+		RequestsPerMinute: req.RequestsPerMinute,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, apikey.ErrKeyNotFound):
+			ErrorResponse(ctx, http.StatusNotFound, "API key not found", err)
+		case errors.Is(err, apikey.ErrInvalidLimits):
+			ErrorResponse(ctx, http.StatusBadRequest, err.Error(), err)
+		default:
+			ErrorResponse(ctx, http.StatusInternalServerError, "Failed to update API key limits", err)
+		}
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "API key limits updated successfully", key)
+}
+
+// @Summary     Revoke public API key
+// @Description Revokes a key; requests using it are rejected within a minute on every instance (admin only)
+// @Tags        admin
+// @Produce     json
+// @Param       id path int true "API key ID"
+// @Success     200 {object} Response "API key revoked successfully"
+// @Failure     400 {object} Response "Invalid API key ID"
+// @Failure     404 {object} Response "API key not found or already revoked"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/api-keys/{id} [delete]
+func (server *Server) revokeAPIKey(ctx *gin.Context) {
+	id, ok := parseAPIKeyID(ctx)
+	if !ok {
+		return
+	}
+
+	if err := server.apiKeys.Revoke(ctx, id); err != nil {
+		if errors.Is(err, apikey.ErrKeyNotFound) {
+			ErrorResponse(ctx, http.StatusNotFound, "API key not found or already revoked", err)
+			return
+		}
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to revoke API key", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "API key revoked successfully", nil)
+}
+
+// @Summary     Public API key usage
+// @Description Daily requests, throttled requests and per-endpoint breakdown of a key (admin only)
+// @Tags        admin
+// @Produce     json
+// @Param       id path int true "API key ID"
+// @Param       days query int false "Days of history including today (1-365, default 30)"
+// @Success     200 {object} Response{data=apikey.Usage} "API key usage retrieved successfully"
+// @Failure     400 {object} Response "Invalid request parameters"
+// @Failure     404 {object} Response "API key not found"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/api-keys/{id}/usage [get]
+func (server *Server) getAPIKeyUsage(ctx *gin.Context) {
+	id, ok := parseAPIKeyID(ctx)
+	if !ok {
+		return
+	}
+
+	var req apiKeyUsageRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	usage, err := server.apiKeys.Usage(ctx, id, req.Days)
+	if err != nil {
+		if errors.Is(err, apikey.ErrKeyNotFound) {
+			ErrorResponse(ctx, http.StatusNotFound, "API key not found", err)
+			return
+		}
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to get API key usage", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "API key usage retrieved successfully", usage)
+}
+
+// @Summary     Public API usage dashboard
+// @Description Requests and throttled requests of every active key over a period, busiest first (admin only)
+// @Tags        admin
+// @Produce     json
+// @Param       days query int false "Days including today (1-365, default 30)"
+// @Success     200 {object} Response{data=[]apikey.KeyTotals} "API usage retrieved successfully"
+// @Failure     400 {object} Response "Invalid query parameters"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/api-keys/usage [get]
+func (server *Server) getAPIUsageDashboard(ctx *gin.Context) {
+	var req apiKeyUsageRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	totals, err := server.apiKeys.Totals(ctx, req.Days)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to get API usage", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "API usage retrieved successfully", totals)
+}
@@ -15,6 +15,7 @@ import (
 	db "github.com/toeic-app/internal/db/sqlc"
 	apperrors "github.com/toeic-app/internal/errors"
 	"github.com/toeic-app/internal/logger"
+	"github.com/toeic-app/internal/referral"
 	"github.com/toeic-app/internal/token"
 	"github.com/toeic-app/internal/util"
 )
@@ -33,7 +34,7 @@ func NewUserResponse(user db.User) UserResponse {
 	return UserResponse{
 		ID:        user.ID,
 		Username:  user.Username,
-		Email:     user.Email,
+		Email:     user.Email.String,
 		CreatedAt: user.CreatedAt.Format(time.RFC3339),
 	}
 }
@@ -73,6 +74,7 @@ type SecurityConfig struct {
 // @Success     200 {object} Response{data=loginUserResponse} "Login successful"
 // @Failure     400 {object} Response "Invalid request"
 // @Failure     401 {object} Response "Authentication failed"
+// @Failure     423 {object} Response "Account is locked"
 // @Failure     500 {object} Response "Server error"
 // @Router      /api/auth/login [post]
 func (server *Server) loginUser(ctx *gin.Context) {
@@ -90,9 +92,10 @@ func (server *Server) loginUser(ctx *gin.Context) {
 		return
 	}
 
-	user, err := server.store.GetUserByEmail(ctx, req.Email)
+	user, err := server.store.GetUserByEmail(ctx, sql.NullString{String: req.Email, Valid: true})
 	if err != nil {
 		if err == sql.ErrNoRows {
+			server.recordAuthFailure(ctx, req.Email)
 			// Use specific error code for authentication failures
 			appErr := apperrors.FromGinContext(ctx, apperrors.ErrCodeInvalidCredentials, "Invalid email or password")
 			appErr.WithMetadata("attempted_email", req.Email)
@@ -109,16 +112,24 @@ func (server *Server) loginUser(ctx *gin.Context) {
 		return
 	}
 
+	if !server.ensureUnlocked(ctx, user.ID) {
+		return
+	}
+
 	err = util.CheckPassword(req.Password, user.PasswordHash)
 	if err != nil {
+		server.recordAuthFailure(ctx, req.Email)
+		server.recordFailedSignIn(ctx, user.ID)
 		// Use specific error code for authentication failures
 		appErr := apperrors.FromGinContext(ctx, apperrors.ErrCodeInvalidCredentials, "Invalid email or password")
 		appErr.WithUserID(user.ID)
-		appErr.WithMetadata("user_email", user.Email)
+		appErr.WithMetadata("user_email", user.Email.String)
 		ctx.Error(appErr)
 		ErrorResponse(ctx, http.StatusUnauthorized, "Invalid email or password", appErr)
 		return
 	}
+	server.recordAuthSuccess(req.Email)
+	server.recordSignIn(ctx, user.ID)
 
 	accessToken, err := server.tokenMaker.CreateToken(
 		user.ID,
@@ -152,19 +163,7 @@ func (server *Server) loginUser(ctx *gin.Context) {
 	userResp := NewUserResponse(user)
 
 	// Generate client-side security configuration
-	securityConfig := SecurityConfig{
-		SecretKey:        server.generateClientSecurityKey(user.ID),
-		SecurityLevel:    2, // Standard security level
-		WasmEnabled:      true,
-		WebWorkerEnabled: true,
-		RequiredHeaders: []string{
-			"X-Security-Token",
-			"X-Client-Signature",
-			"X-Request-Timestamp",
-			"X-Origin-Validation",
-		},
-		MaxTimestampAge: 300, // 5 minutes
-	}
+	securityConfig := server.clientSecurityConfig(user.ID)
 
 	response := loginUserResponse{
 		User:           userResp,
@@ -179,9 +178,10 @@ func (server *Server) loginUser(ctx *gin.Context) {
 // registerUserRequest defines the structure for user registration requests.
 // It includes username, email, and password, all of which are required and validated.
 type registerUserRequest struct {
-	Username string `json:"username" binding:"required,min=3,max=50"`
-	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=8,strong_password"`
+	Username     string `json:"username" binding:"required,min=3,max=50"`
+	Email        string `json:"email" binding:"required,email"`
+	Password     string `json:"password" binding:"required,min=8,strong_password"`
+	ReferralCode string `json:"referral_code,omitempty" binding:"omitempty,max=16"` // Code of the user who referred the new user
 }
 
 // registerUserResponse defines the structure for user registration responses.
@@ -220,12 +220,25 @@ func (server *Server) registerUser(ctx *gin.Context) {
 		return
 	}
 
-	_, err := server.store.GetUserByEmail(ctx, req.Email)
+	_, err := server.store.GetUserByEmail(ctx, sql.NullString{String: req.Email, Valid: true})
 	if err == nil {
 		ErrorResponse(ctx, http.StatusBadRequest, "Email already registered", nil)
 		return
 	}
 
+	var referrerID int32
+	if req.ReferralCode != "" {
+		referrerID, err = server.referrals.Lookup(ctx, req.ReferralCode)
+		if errors.Is(err, referral.ErrInvalidCode) {
+			ErrorResponse(ctx, http.StatusBadRequest, "Invalid referral code", err)
+			return
+		}
+		if err != nil {
+			ErrorResponse(ctx, http.StatusInternalServerError, "Failed to check referral code", err)
+			return
+		}
+	}
+
 	hashedPassword, err := util.HashPassword(req.Password)
 	if err != nil {
 		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to process password", err)
@@ -234,7 +247,7 @@ func (server *Server) registerUser(ctx *gin.Context) {
 
 	arg := db.CreateUserParams{
 		Username:     req.Username,
-		Email:        req.Email,
+		Email:        sql.NullString{String: req.Email, Valid: true},
 		PasswordHash: hashedPassword,
 	}
 
@@ -244,6 +257,16 @@ func (server *Server) registerUser(ctx *gin.Context) {
 		return
 	}
 
+	// The account exists at this point, so a failed attribution does not fail the registration
+	if referrerID != 0 {
+		if _, err := server.referrals.Attribute(ctx, referrerID, req.ReferralCode, user, ctx.ClientIP()); err != nil {
+			logger.Error("Failed to attribute referral of user %d: %v", user.ID, err)
+		}
+	}
+	if err := server.accounts.StartSession(ctx, user.ID, ctx.ClientIP(), ctx.Request.UserAgent()); err != nil {
+		logger.Error("Failed to record session of user %d: %v", user.ID, err)
+	}
+
 	accessToken, err := server.tokenMaker.CreateToken(
 		user.ID,
 		user.Username,
@@ -266,19 +289,7 @@ func (server *Server) registerUser(ctx *gin.Context) {
 	userResp := NewUserResponse(user)
 
 	// Generate client-side security configuration
-	securityConfig := SecurityConfig{
-		SecretKey:        server.generateClientSecurityKey(user.ID),
-		SecurityLevel:    2, // Standard security level
-		WasmEnabled:      true,
-		WebWorkerEnabled: true,
-		RequiredHeaders: []string{
-			"X-Security-Token",
-			"X-Client-Signature",
-			"X-Request-Timestamp",
-			"X-Origin-Validation",
-		},
-		MaxTimestampAge: 300, // 5 minutes
-	}
+	securityConfig := server.clientSecurityConfig(user.ID)
 
 	response := registerUserResponse{
 		User:           userResp,
@@ -343,6 +354,14 @@ func (server *Server) logoutUser(ctx *gin.Context) {
 
 	accessToken := fields[1]
 
+	// End the session before the token stops verifying; sessions are kept
+	// while read-only
+	if payload, err := server.tokenMaker.VerifyToken(accessToken); err == nil && !server.isReadOnly(ctx) {
+		if err := server.accounts.EndSession(ctx, payload.ID, ctx.Request.UserAgent()); err != nil {
+			logger.Error("Failed to end session of user %d: %v", payload.ID, err)
+		}
+	}
+
 	// Blacklist the access token
 	err := server.tokenMaker.BlacklistToken(accessToken)
 	if err != nil {
@@ -380,6 +399,7 @@ func (server *Server) logoutUser(ctx *gin.Context) {
 // @Success     200 {object} Response{data=refreshTokenResponse} "Token refreshed successfully"
 // @Failure     400 {object} Response "Invalid request"
 // @Failure     401 {object} Response "Invalid refresh token"
+// @Failure     423 {object} Response "Account is locked"
 // @Failure     500 {object} Response "Server error"
 // @Router      /api/auth/refresh-token [post]
 func (server *Server) refreshToken(ctx *gin.Context) {
@@ -411,6 +431,15 @@ func (server *Server) refreshToken(ctx *gin.Context) {
 		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to find user", err)
 		return
 	}
+	if !server.ensureUnlocked(ctx, user.ID) {
+		return
+	}
+	// Nothing is written while read-only; the next refresh extends the session
+	if !server.isReadOnly(ctx) {
+		if err := server.accounts.RefreshSession(ctx, user.ID, ctx.ClientIP(), ctx.Request.UserAgent()); err != nil {
+			logger.Error("Failed to refresh session of user %d: %v", user.ID, err)
+		}
+	}
 
 	// Create new access token
 	accessToken, err := server.tokenMaker.CreateToken(
@@ -515,6 +544,7 @@ func (server *Server) authMiddleware() gin.HandlerFunc {
 		fields["user_id"] = payload.ID
 		fields["token_valid"] = true
 		logger.DebugWithFields(fields, "Auth successful")
+		server.applyPreferredLanguage(ctx, payload.ID)
 		ctx.Next()
 	}
 }
@@ -534,6 +564,24 @@ func (server *Server) GetAuthPayload(ctx *gin.Context) (*token.Payload, error) {
 	return authPayload, nil
 }
 
+// clientSecurityConfig returns the client-side security configuration sent
+// with a new session
+func (server *Server) clientSecurityConfig(userID int32) SecurityConfig {
+	return SecurityConfig{
+		SecretKey:        server.generateClientSecurityKey(userID),
+		SecurityLevel:    2, // Standard security level
+		WasmEnabled:      true,
+		WebWorkerEnabled: true,
+		RequiredHeaders: []string{
+			"X-Security-Token",
+			"X-Client-Signature",
+			"X-Request-Timestamp",
+			"X-Origin-Validation",
+		},
+		MaxTimestampAge: 300, // 5 minutes
+	}
+}
+
 // generateClientSecurityKey generates a unique security key for the client
 func (server *Server) generateClientSecurityKey(userID int32) string {
 	// Generate deterministic key based on server secret and user ID (no timestamp)
@@ -0,0 +1,547 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/authoring"
+	"github.com/toeic-app/internal/token"
+)
+
+// authoringGrammarRequest is the content of a grammar draft
+type authoringGrammarRequest struct {
+	Level      int32            `json:"level" binding:"required,min=1"`
+	Title      string           `json:"title" binding:"required" sanitize:"plain"`
+	Tag        []string         `json:"tag"`
+	GrammarKey string           `json:"grammar_key" binding:"required"`
+	Related    []int32          `json:"related"`
+	Contents   []GrammarContent `json:"contents,omitempty"`
+	Note       string           `json:"note" binding:"max=500" sanitize:"plain"`
+}
+
+func (req authoringGrammarRequest) content() *authoring.GrammarData {
+	return &authoring.GrammarData{
+		Level:      req.Level,
+		Title:      req.Title,
+		Tag:        req.Tag,
+		GrammarKey: req.GrammarKey,
+		Related:    req.Related,
+		Contents:   toRawMessageFromGrammarContents(req.Contents),
+	}
+}
+
+// authoringWritingPromptRequest is the content of a writing prompt draft
+type authoringWritingPromptRequest struct {
+	PromptText      string `json:"prompt_text" binding:"required" sanitize:"markdown"`
+	Topic           string `json:"topic" sanitize:"plain"`
+	DifficultyLevel string `json:"difficulty_level" binding:"omitempty,difficulty_level"`
+	Note            string `json:"note" binding:"max=500" sanitize:"plain"`
+}
+
+func (req authoringWritingPromptRequest) content() *authoring.WritingPromptData {
+	return &authoring.WritingPromptData{
+		PromptText:      req.PromptText,
+		Topic:           req.Topic,
+		DifficultyLevel: req.DifficultyLevel,
+	}
+}
+
+// revisionNoteRequest carries the optional note of a workflow step
+type revisionNoteRequest struct {
+	Note string `json:"note" binding:"max=500" sanitize:"plain"`
+}
+
+type approveRevisionRequest struct {
+	// PublishAt schedules publishing; empty or past publishes right away
+	PublishAt *time.Time `json:"publish_at"`
+	Note      string     `json:"note" binding:"max=500" sanitize:"plain"`
+}
+
+type listRevisionsRequest struct {
+	Status      string `form:"status" binding:"omitempty,oneof=draft review scheduled published discarded"`
+	ContentType string `form:"content_type" binding:"omitempty,oneof=grammar writing_prompt"`
+	Limit       int32  `form:"limit,default=20" binding:"min=1,max=100"`
+	Offset      int32  `form:"offset,default=0" binding:"min=0"`
+}
+
+type listContentHistoryRequest struct {
+	Limit  int32 `form:"limit,default=50" binding:"min=1,max=200"`
+	Offset int32 `form:"offset,default=0" binding:"min=0"`
+}
+
+// parseAuthoringID reads the ID path parameter of a revision or of content
+func parseAuthoringID(ctx *gin.Context, what string) (int32, bool) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 32)
+	if err != nil || id <= 0 {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid "+what+" ID", err)
+		return 0, false
+	}
+	return int32(id), true
+}
+
+// bindRevisionNote reads the optional note of a workflow step
+func (server *Server) bindRevisionNote(ctx *gin.Context) (string, bool) {
+	var req revisionNoteRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+			return "", false
+		}
+		server.sanitizer.Struct(&req)
+	}
+	return req.Note, true
+}
+
+// authoringError writes the response of a failed workflow operation
+func authoringError(ctx *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, authoring.ErrRevisionNotFound):
+		ErrorResponse(ctx, http.StatusNotFound, "Revision not found", err)
+	case errors.Is(err, authoring.ErrContentNotFound):
+		ErrorResponse(ctx, http.StatusNotFound, "Content not found", err)
+	case errors.Is(err, authoring.ErrInvalidTransition), errors.Is(err, authoring.ErrRevisionInProgress):
+		ErrorResponse(ctx, http.StatusConflict, err.Error(), err)
+	case errors.Is(err, authoring.ErrInvalidContent), errors.Is(err, authoring.ErrContentTypeMismatch),
+		errors.Is(err, authoring.ErrUnknownType):
+		ErrorResponse(ctx, http.StatusBadRequest, err.Error(), err)
+	default:
+		ErrorResponse(ctx, http.StatusInternalServerError, message, err)
+	}
+}
+
+// @Summary     Create a grammar draft
+// @Description Adds a grammar that stays hidden from learners until a reviewer publishes it. The draft is the first revision of the grammar.
+// @Tags        authoring
+// @Accept      json
+// @Produce     json
+// @Param       grammar body authoringGrammarRequest true "Grammar content"
+// @Success     201 {object} Response{data=authoring.Revision} "Grammar draft created successfully"
+// @Failure     400 {object} Response "Invalid request body"
+// @Failure     403 {object} Response "Missing content.create permission"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/authoring/grammars [post]
+func (server *Server) createGrammarDraft(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	var req authoringGrammarRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	server.sanitizer.Struct(&req)
+
+	revision, err := server.authoring.Create(ctx, req.content(), req.Note, authPayload.ID)
+	if err != nil {
+		authoringError(ctx, err, "Failed to create grammar draft")
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusCreated, "Grammar draft created successfully", revision)
+}
+
+// @Summary     Create a writing prompt draft
+// @Description Adds a writing prompt that stays hidden from learners until a reviewer publishes it
+// @Tags        authoring
+// @Accept      json
+// @Produce     json
+// @Param       prompt body authoringWritingPromptRequest true "Writing prompt content"
+// @Success     201 {object} Response{data=authoring.Revision} "Writing prompt draft created successfully"
+// @Failure     400 {object} Response "Invalid request body"
+// @Failure     403 {object} Response "Missing content.create permission"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/authoring/writing-prompts [post]
+func (server *Server) createWritingPromptDraft(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	var req authoringWritingPromptRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	server.sanitizer.Struct(&req)
+
+	revision, err := server.authoring.Create(ctx, req.content(), req.Note, authPayload.ID)
+	if err != nil {
+		authoringError(ctx, err, "Failed to create writing prompt draft")
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusCreated, "Writing prompt draft created successfully", revision)
+}
+
+// startRevision opens a draft revision of contentType from its current version
+func (server *Server) startRevision(ctx *gin.Context, contentType string) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+	id, ok := parseAuthoringID(ctx, "content")
+	if !ok {
+		return
+	}
+	note, ok := server.bindRevisionNote(ctx)
+	if !ok {
+		return
+	}
+
+	revision, err := server.authoring.StartRevision(ctx, contentType, id, nil, note, authPayload.ID)
+	if err != nil {
+		authoringError(ctx, err, "Failed to start revision")
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusCreated, "Revision started successfully", revision)
+}
+
+// @Summary     Start a grammar revision
+// @Description Opens a draft copy of the current grammar. Learners keep seeing the published version until the revision is published. A grammar has at most one revision in progress.
+// @Tags        authoring
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Grammar ID"
+// @Param       body body revisionNoteRequest false "Optional note"
+// @Success     201 {object} Response{data=authoring.Revision} "Revision started successfully"
+// @Failure     404 {object} Response "Content not found"
+// @Failure     409 {object} Response "A revision is already in progress"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/authoring/grammars/{id}/revisions [post]
+func (server *Server) startGrammarRevision(ctx *gin.Context) {
+	server.startRevision(ctx, authoring.TypeGrammar)
+}
+
+// @Summary     Start a writing prompt revision
+// @Description Opens a draft copy of the current writing prompt. Learners keep seeing the published version until the revision is published.
+// @Tags        authoring
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Writing prompt ID"
+// @Param       body body revisionNoteRequest false "Optional note"
+// @Success     201 {object} Response{data=authoring.Revision} "Revision started successfully"
+// @Failure     404 {object} Response "Content not found"
+// @Failure     409 {object} Response "A revision is already in progress"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/authoring/writing-prompts/{id}/revisions [post]
+func (server *Server) startWritingPromptRevision(ctx *gin.Context) {
+	server.startRevision(ctx, authoring.TypeWritingPrompt)
+}
+
+// listVersions lists every revision of a piece of content
+func (server *Server) listVersions(ctx *gin.Context, contentType string) {
+	id, ok := parseAuthoringID(ctx, "content")
+	if !ok {
+		return
+	}
+
+	revisions, err := server.authoring.Versions(ctx, contentType, id)
+	if err != nil {
+		authoringError(ctx, err, "Failed to list versions")
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Versions retrieved successfully", revisions)
+}
+
+// @Summary     List grammar versions
+// @Description Lists every revision of a grammar, newest first
+// @Tags        authoring
+// @Produce     json
+// @Param       id path int true "Grammar ID"
+// @Success     200 {object} Response{data=[]authoring.Revision} "Versions retrieved successfully"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/authoring/grammars/{id}/versions [get]
+func (server *Server) listGrammarVersions(ctx *gin.Context) {
+	server.listVersions(ctx, authoring.TypeGrammar)
+}
+
+// @Summary     List writing prompt versions
+// @Description Lists every revision of a writing prompt, newest first
+// @Tags        authoring
+// @Produce     json
+// @Param       id path int true "Writing prompt ID"
+// @Success     200 {object} Response{data=[]authoring.Revision} "Versions retrieved successfully"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/authoring/writing-prompts/{id}/versions [get]
+func (server *Server) listWritingPromptVersions(ctx *gin.Context) {
+	server.listVersions(ctx, authoring.TypeWritingPrompt)
+}
+
+// listContentHistory lists the workflow changes of a piece of content
+func (server *Server) listContentHistory(ctx *gin.Context, contentType string) {
+	id, ok := parseAuthoringID(ctx, "content")
+	if !ok {
+		return
+	}
+	var req listContentHistoryRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	history, err := server.authoring.History(ctx, contentType, id, req.Limit, req.Offset)
+	if err != nil {
+		authoringError(ctx, err, "Failed to list content history")
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Content history retrieved successfully", history)
+}
+
+// @Summary     Grammar change history
+// @Description Lists who created, edited, submitted, approved, rejected and published a grammar, newest first
+// @Tags        authoring
+// @Produce     json
+// @Param       id path int true "Grammar ID"
+// @Param       limit query int false "Page size (1-200, default 50)"
+// @Param       offset query int false "Offset (default 0)"
+// @Success     200 {object} Response{data=[]authoring.HistoryEntry} "Content history retrieved successfully"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/authoring/grammars/{id}/history [get]
+func (server *Server) listGrammarHistory(ctx *gin.Context) {
+	server.listContentHistory(ctx, authoring.TypeGrammar)
+}
+
+// @Summary     Writing prompt change history
+// @Description Lists the workflow changes of a writing prompt, newest first
+// @Tags        authoring
+// @Produce     json
+// @Param       id path int true "Writing prompt ID"
+// @Param       limit query int false "Page size (1-200, default 50)"
+// @Param       offset query int false "Offset (default 0)"
+// @Success     200 {object} Response{data=[]authoring.HistoryEntry} "Content history retrieved successfully"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/authoring/writing-prompts/{id}/history [get]
+func (server *Server) listWritingPromptHistory(ctx *gin.Context) {
+	server.listContentHistory(ctx, authoring.TypeWritingPrompt)
+}
+
+// @Summary     List revisions
+// @Description Lists revisions across content, most recently changed first. Filter by status=review to see the review queue.
+// @Tags        authoring
+// @Produce     json
+// @Param       status query string false "draft, review, scheduled, published or discarded"
+// @Param       content_type query string false "grammar or writing_prompt"
+// @Param       limit query int false "Page size (1-100, default 20)"
+// @Param       offset query int false "Offset (default 0)"
+// @Success     200 {object} Response{data=[]authoring.Revision} "Revisions retrieved successfully"
+// @Failure     400 {object} Response "Invalid query parameters"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/authoring/revisions [get]
+func (server *Server) listRevisions(ctx *gin.Context) {
+	var req listRevisionsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	revisions, err := server.authoring.List(ctx, authoring.Filter{
+		Status:      req.Status,
+		ContentType: req.ContentType,
+		Limit:       req.Limit,
+		Offset:      req.Offset,
+	})
+	if err != nil {
+		authoringError(ctx, err, "Failed to list revisions")
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Revisions retrieved successfully", revisions)
+}
+
+// @Summary     Get a revision
+// @Tags        authoring
+// @Produce     json
+// @Param       id path int true "Revision ID"
+// @Success     200 {object} Response{data=authoring.Revision} "Revision retrieved successfully"
+// @Failure     404 {object} Response "Revision not found"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/authoring/revisions/{id} [get]
+func (server *Server) getRevision(ctx *gin.Context) {
+	id, ok := parseAuthoringID(ctx, "revision")
+	if !ok {
+		return
+	}
+
+	revision, err := server.authoring.Get(ctx, id)
+	if err != nil {
+		authoringError(ctx, err, "Failed to get revision")
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Revision retrieved successfully", revision)
+}
+
+// @Summary     Edit a draft revision
+// @Description Replaces the content of a draft. The body is an authoringGrammarRequest or an authoringWritingPromptRequest, matching the content type of the revision.
+// @Tags        authoring
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Revision ID"
+// @Param       body body authoringGrammarRequest true "New content"
+// @Success     200 {object} Response{data=authoring.Revision} "Revision updated successfully"
+// @Failure     400 {object} Response "Invalid request body"
+// @Failure     404 {object} Response "Revision not found"
+// @Failure     409 {object} Response "Revision is not a draft"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/authoring/revisions/{id} [put]
+func (server *Server) editRevision(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+	id, ok := parseAuthoringID(ctx, "revision")
+	if !ok {
+		return
+	}
+
+	current, err := server.authoring.Get(ctx, id)
+	if err != nil {
+		authoringError(ctx, err, "Failed to get revision")
+		return
+	}
+
+	var content authoring.Content
+	var note string
+	switch current.ContentType {
+	case authoring.TypeGrammar:
+		var req authoringGrammarRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+			return
+		}
+		server.sanitizer.Struct(&req)
+		content, note = req.content(), req.Note
+	case authoring.TypeWritingPrompt:
+		var req authoringWritingPromptRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+			return
+		}
+		server.sanitizer.Struct(&req)
+		content, note = req.content(), req.Note
+	default:
+		authoringError(ctx, authoring.ErrUnknownType, "Failed to update revision")
+		return
+	}
+
+	revision, err := server.authoring.Edit(ctx, id, content, note, authPayload.ID)
+	if err != nil {
+		authoringError(ctx, err, "Failed to update revision")
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Revision updated successfully", revision)
+}
+
+// moveRevision runs a workflow step that only takes a note
+func (server *Server) moveRevision(ctx *gin.Context, step func(*gin.Context, int32, string, int32) (*authoring.Revision, error), message string) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+	id, ok := parseAuthoringID(ctx, "revision")
+	if !ok {
+		return
+	}
+	note, ok := server.bindRevisionNote(ctx)
+	if !ok {
+		return
+	}
+
+	revision, err := step(ctx, id, note, authPayload.ID)
+	if err != nil {
+		authoringError(ctx, err, "Failed to update revision")
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, message, revision)
+}
+
+// @Summary     Submit a revision for review
+// @Description Locks a draft and adds it to the review queue
+// @Tags        authoring
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Revision ID"
+// @Param       body body revisionNoteRequest false "Optional note"
+// @Success     200 {object} Response{data=authoring.Revision} "Revision submitted for review"
+// @Failure     404 {object} Response "Revision not found"
+// @Failure     409 {object} Response "Revision is not a draft"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/authoring/revisions/{id}/submit [post]
+func (server *Server) submitRevision(ctx *gin.Context) {
+	server.moveRevision(ctx, func(c *gin.Context, id int32, note string, actor int32) (*authoring.Revision, error) {
+		return server.authoring.Submit(c, id, note, actor)
+	}, "Revision submitted for review")
+}
+
+// @Summary     Approve a revision
+// @Description Publishes a revision in review, replacing the version learners see. With a publish_at in the future the revision is scheduled and published automatically at that time. Requires the content.publish permission.
+// @Tags        authoring
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Revision ID"
+// @Param       body body approveRevisionRequest false "Optional publish time and note"
+// @Success     200 {object} Response{data=authoring.Revision} "Revision approved successfully"
+// @Failure     403 {object} Response "Missing content.publish permission"
+// @Failure     404 {object} Response "Revision or content not found"
+// @Failure     409 {object} Response "Revision is not in review"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/authoring/revisions/{id}/approve [post]
+func (server *Server) approveRevision(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+	id, ok := parseAuthoringID(ctx, "revision")
+	if !ok {
+		return
+	}
+	var req approveRevisionRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+			return
+		}
+		server.sanitizer.Struct(&req)
+	}
+
+	var publishAt time.Time
+	if req.PublishAt != nil {
+		publishAt = *req.PublishAt
+	}
+	revision, err := server.authoring.Approve(ctx, id, publishAt, req.Note, authPayload.ID)
+	if err != nil {
+		authoringError(ctx, err, "Failed to approve revision")
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Revision approved successfully", revision)
+}
+
+// @Summary     Reject a revision
+// @Description Sends a revision in review, or a scheduled one, back to draft so the author can change it. Requires the content.publish permission.
+// @Tags        authoring
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Revision ID"
+// @Param       body body revisionNoteRequest false "Feedback for the author"
+// @Success     200 {object} Response{data=authoring.Revision} "Revision rejected"
+// @Failure     403 {object} Response "Missing content.publish permission"
+// @Failure     404 {object} Response "Revision not found"
+// @Failure     409 {object} Response "Revision is not in review or scheduled"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/authoring/revisions/{id}/reject [post]
+func (server *Server) rejectRevision(ctx *gin.Context) {
+	server.moveRevision(ctx, func(c *gin.Context, id int32, note string, actor int32) (*authoring.Revision, error) {
+		return server.authoring.Reject(c, id, note, actor)
+	}, "Revision rejected")
+}
+
+// @Summary     Discard a revision
+// @Description Abandons a revision that has not been published. The published version is unchanged.
+// @Tags        authoring
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Revision ID"
+// @Param       body body revisionNoteRequest false "Optional note"
+// @Success     200 {object} Response{data=authoring.Revision} "Revision discarded"
+// @Failure     404 {object} Response "Revision not found"
+// @Failure     409 {object} Response "Revision is already published or discarded"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/authoring/revisions/{id}/discard [post]
+func (server *Server) discardRevision(ctx *gin.Context) {
+	server.moveRevision(ctx, func(c *gin.Context, id int32, note string, actor int32) (*authoring.Revision, error) {
+		return server.authoring.Discard(c, id, note, actor)
+	}, "Revision discarded")
+}
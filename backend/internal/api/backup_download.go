@@ -0,0 +1,164 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/logger"
+)
+
+// backupChunkSize is how much of a backup is written before the response is
+// flushed to the client
+const backupChunkSize = 1 << 20
+
+// errRangeNotSatisfiable is returned for a Range header outside the file
+var errRangeNotSatisfiable = errors.New("requested range not satisfiable")
+
+// byteRange is an inclusive range of bytes of a file
+type byteRange struct {
+	start, end int64
+}
+
+func (r byteRange) length() int64 {
+	return r.end - r.start + 1
+}
+
+// parseByteRange parses the Range header of a request for a file of size
+// bytes. Only a single range is supported; for a missing header, a unit
+// other than bytes or several ranges it returns false and the whole file is
+// served, as RFC 9110 allows.
+func parseByteRange(header string, size int64) (byteRange, bool, error) {
+	spec, ok := strings.CutPrefix(header, "bytes=")
+	if !ok || strings.Contains(spec, ",") {
+		return byteRange{}, false, nil
+	}
+	first, last, ok := strings.Cut(strings.TrimSpace(spec), "-")
+	if !ok {
+		return byteRange{}, false, errRangeNotSatisfiable
+	}
+
+	if first == "" {
+		// A suffix range asks for the last bytes of the file
+		n, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || n <= 0 || size == 0 {
+			return byteRange{}, false, errRangeNotSatisfiable
+		}
+		return byteRange{start: max(size-n, 0), end: size - 1}, true, nil
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 || start >= size {
+		return byteRange{}, false, errRangeNotSatisfiable
+	}
+	end := size - 1
+	if last != "" {
+		end, err = strconv.ParseInt(last, 10, 64)
+		if err != nil || end < start {
+			return byteRange{}, false, errRangeNotSatisfiable
+		}
+		end = min(end, size-1)
+	}
+	return byteRange{start: start, end: end}, true, nil
+}
+
+// backupETag identifies a version of a backup file for If-Range
+func backupETag(info os.FileInfo) string {
+	return fmt.Sprintf(`"%x-%x"`, info.ModTime().UnixNano(), info.Size())
+}
+
+// rangeStillValid reports whether the If-Range header of a request, when
+// set, matches the backup, so that a resumed download does not mix bytes of
+// two different files
+func rangeStillValid(ifRange string, info os.FileInfo) bool {
+	if ifRange == "" {
+		return true
+	}
+	if strings.HasPrefix(ifRange, `"`) || strings.HasPrefix(ifRange, "W/") {
+		return ifRange == backupETag(info)
+	}
+	modified, err := http.ParseTime(ifRange)
+	return err == nil && !info.ModTime().Truncate(time.Second).After(modified)
+}
+
+// streamBackupFile sends a backup file in chunks, flushing after each one,
+// so that large backups are never held in memory. A Range request resumes
+// an interrupted download with 206 Partial Content. X-Backup-Size and
+// X-Backup-Offset report the full size of the backup and where the response
+// starts in it, so the client can show the progress of a resumed download.
+func streamBackupFile(ctx *gin.Context, path, filename string) {
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			ErrorResponse(ctx, http.StatusNotFound, "Backup file not found", nil)
+			return
+		}
+		ErrorResponse(ctx, http.StatusInternalServerError, "Error accessing backup file", err)
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Error accessing backup file", err)
+		return
+	}
+	size := info.Size()
+
+	header := ctx.Writer.Header()
+	header.Set("Accept-Ranges", "bytes")
+	header.Set("ETag", backupETag(info))
+	header.Set("Last-Modified", info.ModTime().UTC().Format(http.TimeFormat))
+	header.Set("X-Backup-Size", strconv.FormatInt(size, 10))
+
+	part := byteRange{start: 0, end: size - 1}
+	status := http.StatusOK
+	if rangeStillValid(ctx.GetHeader("If-Range"), info) {
+		requested, ok, err := parseByteRange(ctx.GetHeader("Range"), size)
+		if err != nil {
+			header.Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+			ErrorResponse(ctx, http.StatusRequestedRangeNotSatisfiable, "Requested range not satisfiable", err)
+			return
+		}
+		if ok {
+			part, status = requested, http.StatusPartialContent
+			header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", part.start, part.end, size))
+		}
+	}
+
+	if _, err := file.Seek(part.start, io.SeekStart); err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Error accessing backup file", err)
+		return
+	}
+
+	header.Set("Content-Type", "application/octet-stream")
+	header.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	header.Set("Content-Length", strconv.FormatInt(part.length(), 10))
+	header.Set("X-Backup-Offset", strconv.FormatInt(part.start, 10))
+	ctx.Status(status)
+
+	var sent int64
+	for sent < part.length() {
+		if ctx.Request.Context().Err() != nil {
+			break
+		}
+		n, err := io.CopyN(ctx.Writer, file, min(backupChunkSize, part.length()-sent))
+		sent += n
+		if err != nil {
+			break
+		}
+		ctx.Writer.Flush()
+	}
+
+	if sent < part.length() {
+		logger.Warn("Download of backup %s stopped after %d of %d bytes", filename, part.start+sent, size)
+		return
+	}
+	logger.Info("Sent backup %s: bytes %d-%d of %d", filename, part.start, part.end, size)
+}
@@ -2,23 +2,25 @@ package api
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/backup"
 	"github.com/toeic-app/internal/logger"
 	"github.com/toeic-app/internal/util"
 )
 
 // backupRequest holds the data needed to perform a backup
 type backupRequest struct {
-	Filename    string `json:"filename" form:"filename"`
+	Filename    string `json:"filename" form:"filename" binding:"omitempty,safe_filename"`
 	Description string `json:"description" form:"description"`
 }
 
@@ -33,11 +35,23 @@ type backupResponse struct {
 
 // backupListResponse is a single backup file info for listing
 type backupListItem struct {
-	Filename    string    `json:"filename"`
-	Description string    `json:"description"`
-	Size        int64     `json:"size"`
-	CreatedAt   time.Time `json:"created_at"`
-	DownloadURL string    `json:"download_url"`
+	Filename      string    `json:"filename"`
+	Description   string    `json:"description"`
+	Size          int64     `json:"size"`
+	CreatedAt     time.Time `json:"created_at"`
+	DownloadURL   string    `json:"download_url"`
+	Type          string    `json:"type,omitempty"`
+	Mode          string    `json:"mode,omitempty"`
+	Checksum      string    `json:"checksum,omitempty"`
+	SchemaVersion string    `json:"schema_version,omitempty"`
+	// MigrationTarget is the schema version a migration backup was taken
+	// before migrating to
+	MigrationTarget string `json:"migration_target,omitempty"`
+	DBVersion       string `json:"db_version,omitempty"`
+	AppVersion      string `json:"app_version,omitempty"`
+	Encrypted       bool   `json:"encrypted"`
+	KeyID           string `json:"key_id,omitempty"`
+	DurationMs      int64  `json:"duration_ms,omitempty"`
 }
 
 // ensureBackupDir creates the backup directory if it doesn't exist
@@ -85,17 +99,10 @@ func (server *Server) createBackupWithTransaction(filename, description string)
 	// Force UTF-8 client encoding to prevent encoding issues
 	env = append(env, "PGCLIENTENCODING=UTF8")
 
-	// Get pg_dump command with appropriate path
-	pgDumpCmd, cmdErr := util.GetPgDumpCommand()
-	if cmdErr != nil {
-		logger.Error("Failed to find pg_dump command: %v", cmdErr)
-		return nil, fmt.Errorf("pg_dump command not found: %w", cmdErr)
-	}
-	logger.Debug("Using pg_dump command: %s", pgDumpCmd)
-
-	// Create pg_dump command with UTF-8 encoding
-	cmd := exec.Command(
-		pgDumpCmd,
+	// Create pg_dump command with UTF-8 encoding, run as configured
+	cmd, cmdErr := server.backupManager.Tools().Command(
+		context.Background(),
+		util.ToolPgDump,
 		"--host="+dbHost,
 		"--port="+dbPort,
 		"--username="+dbUser,
@@ -108,6 +115,11 @@ func (server *Server) createBackupWithTransaction(filename, description string)
 		"--file="+backupPath,
 		dbName,
 	)
+	if cmdErr != nil {
+		logger.Error("Failed to find pg_dump command: %v", cmdErr)
+		return nil, fmt.Errorf("pg_dump command not found: %w", cmdErr)
+	}
+	logger.Debug("Using pg_dump command: %s", cmd.Path)
 
 	// Set environment variables
 	cmd.Env = env
@@ -216,69 +228,127 @@ func (server *Server) createBackup(ctx *gin.Context) {
 		return
 	}
 
+	if err := server.backupManager.TrackBackup(ctx, response.Filename, "manual", req.Description); err != nil {
+		logger.Warn("Failed to catalog backup %s: %v", response.Filename, err)
+	}
+
 	SuccessResponse(ctx, http.StatusOK, "Database backup created successfully", response)
 }
 
+// listBackupsQuery filters the backups of the catalog
+type listBackupsQuery struct {
+	Type string    `form:"type" binding:"omitempty,max=32" example:"automatic"`
+	From time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To   time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00" binding:"omitempty,gtfield=From"`
+	Q    string    `form:"q" binding:"omitempty,max=100"`
+}
+
 // @Summary     List database backups
-// @Description Lists all available database backups
+// @Description Lists the completed backups of the backup catalog, newest first, with their metadata. type, from, to and q narrow the list; q matches part of the filename or description, ignoring case. With format=csv or format=xlsx the list is downloaded as a file.
 // @Tags        admin
 // @Accept      json
-// @Produce     json
+// @Produce     json,text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param       type query string false "Backup type, e.g. manual, automatic or migration"
+// @Param       from query string false "Only backups made at or after this time (RFC 3339)"
+// @Param       to query string false "Only backups made before this time (RFC 3339)"
+// @Param       q query string false "Text to find in the filename or description"
+// @Param       format query string false "Download the list as a file" Enums(csv, xlsx)
 // @Success     200 {object} Response{data=[]backupListItem} "Backups retrieved successfully"
+// @Failure     400 {object} Response "Invalid query parameters"
 // @Failure     500 {object} Response "Failed to retrieve backups"
 // @Security    ApiKeyAuth
 // @Router      /api/v1/admin/backups [get]
 func (server *Server) listBackups(ctx *gin.Context) {
-	// Ensure the backup directory exists
-	backupDir := filepath.Join(".", "backups")
-	if err := ensureBackupDir(backupDir); err != nil {
-		logger.Error("Failed to create backup directory: %v", err)
-		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to access backup directory", err)
+	var query listBackupsQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+	format, ok := exportFormat(ctx)
+	if !ok {
 		return
 	}
 
-	// Read directory contents
-	files, err := os.ReadDir(backupDir)
+	entries, err := server.backupCatalog.Search(ctx, backup.CatalogQuery{
+		Status: backup.StatusCompleted,
+		Type:   query.Type,
+		From:   query.From,
+		To:     query.To,
+		Text:   strings.TrimSpace(query.Q),
+	})
 	if err != nil {
-		logger.Error("Failed to read backup directory: %v", err)
-		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to read backup directory", err)
+		logger.Error("Failed to read backup catalog: %v", err)
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to read backup catalog", err)
 		return
 	}
-
-	// Create response list
-	backups := make([]backupListItem, 0)
-	for _, file := range files {
-		// Skip directories and non-SQL files
-		if file.IsDir() || filepath.Ext(file.Name()) != ".sql" {
-			continue
-		}
-
-		fileInfo, err := file.Info()
-		if err != nil {
-			logger.Warn("Failed to get info for backup file %s: %v", file.Name(), err)
-			continue
-		}
-
+	backups := make([]backupListItem, 0, len(entries))
+	for _, entry := range entries {
 		backups = append(backups, backupListItem{
-			Filename:    file.Name(),
-			Description: "", // We would need to store this separately or in file metadata
-			Size:        fileInfo.Size(),
-			CreatedAt:   fileInfo.ModTime(),
-			DownloadURL: fmt.Sprintf("/api/v1/admin/backups/download/%s", file.Name()),
+			Filename:        entry.Filename,
+			Description:     entry.Description,
+			Size:            entry.Size,
+			CreatedAt:       entry.CreatedAt,
+			DownloadURL:     fmt.Sprintf("/api/v1/admin/backups/download/%s", entry.Filename),
+			Type:            entry.Type,
+			Mode:            entry.Mode,
+			Checksum:        entry.Checksum,
+			SchemaVersion:   entry.SchemaVersion,
+			MigrationTarget: entry.MigrationTarget,
+			DBVersion:       entry.DBVersion,
+			AppVersion:      entry.AppVersion,
+			Encrypted:       entry.Encrypted,
+			KeyID:           entry.KeyID,
+			DurationMs:      entry.Duration.Milliseconds(),
 		})
 	}
 
+	if format != "" {
+		header := []string{"filename", "description", "size", "created_at", "download_url", "type", "mode", "checksum",
+			"schema_version", "migration_target", "db_version", "app_version", "encrypted", "key_id"}
+		streamExport(ctx, format, "backups", header, func(write func([]string) error) error {
+			for _, backup := range backups {
+				if err := write([]string{
+					backup.Filename,
+					backup.Description,
+					strconv.FormatInt(backup.Size, 10),
+					backup.CreatedAt.UTC().Format(time.RFC3339),
+					backup.DownloadURL,
+					backup.Type,
+					backup.Mode,
+					backup.Checksum,
+					backup.SchemaVersion,
+					backup.MigrationTarget,
+					backup.DBVersion,
+					backup.AppVersion,
+					strconv.FormatBool(backup.Encrypted),
+					backup.KeyID,
+				}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		return
+	}
+
 	SuccessResponse(ctx, http.StatusOK, "Database backups retrieved successfully", backups)
 }
 
 // @Summary     Download database backup
-// @Description Downloads a specific database backup file
+// @Description Downloads a specific database backup file. The file is streamed in chunks; a Range header (bytes=start-end) resumes an interrupted download, guarded by If-Range with the returned ETag. X-Backup-Size holds the full size of the backup and X-Backup-Offset where the response starts in it.
 // @Tags        admin
 // @Produce     application/octet-stream
 // @Param       filename path string true "Backup filename"
+// @Param       Range header string false "Byte range to resume from, e.g. bytes=1048576-"
+// @Param       If-Range header string false "ETag or Last-Modified of the partially downloaded backup"
 // @Success     200 {file} binary "Backup file content"
+// @Success     206 {file} binary "Requested range of the backup file"
+// @Header      200,206 {string} X-Backup-Size "Full size of the backup in bytes"
+// @Header      200,206 {string} X-Backup-Offset "Offset of the first byte sent"
+// @Header      200,206 {string} ETag "Version of the backup for If-Range"
 // @Failure     400 {object} Response "Invalid filename"
 // @Failure     404 {object} Response "Backup file not found"
+// @Failure     416 {object} Response "Requested range not satisfiable"
 // @Failure     500 {object} Response "Failed to serve backup file"
 // @Security    ApiKeyAuth
 // @Router      /api/v1/admin/backups/download/{filename} [get]
@@ -301,25 +371,12 @@ func (server *Server) downloadBackup(ctx *gin.Context) {
 	}
 	logger.Debug("Validated filename: %s", validFilename)
 
-	// Path to backup file
-	backupPath := filepath.Join(".", "backups", validFilename)
+	// Path to backup file, downloaded from remote storage if needed
+	backupPath := server.localBackupPath(ctx, validFilename)
 	logger.Debug("Looking for backup at path: %s", backupPath)
 
-	// Check if file exists
-	fileInfo, err := os.Stat(backupPath)
-	if os.IsNotExist(err) {
-		logger.Warn("Backup file not found: %s", backupPath)
-		ErrorResponse(ctx, http.StatusNotFound, "Backup file not found", nil)
-		return
-	} else if err != nil {
-		logger.Error("Error checking backup file: %v", err)
-		ErrorResponse(ctx, http.StatusInternalServerError, "Error accessing backup file", err)
-		return
-	}
-
-	logger.Info("Serving backup file: %s (size: %d bytes)", validFilename, fileInfo.Size())
-	// Serve the file
-	ctx.FileAttachment(backupPath, filename)
+	// Stream the file, resuming from the requested range
+	streamBackupFile(ctx, backupPath, validFilename)
 }
 
 // @Summary     Delete database backup
@@ -331,6 +388,7 @@ func (server *Server) downloadBackup(ctx *gin.Context) {
 // @Success     200 {object} Response "Backup deleted successfully"
 // @Failure     400 {object} Response "Invalid filename"
 // @Failure     404 {object} Response "Backup file not found"
+// @Failure     409 {object} Response "Backup is protected from deletion"
 // @Failure     500 {object} Response "Failed to delete backup file"
 // @Security    ApiKeyAuth
 // @Router      /api/v1/admin/backups/{filename} [delete]
@@ -353,6 +411,34 @@ func (server *Server) deleteBackup(ctx *gin.Context) {
 	}
 	logger.Debug("Validated filename for deletion: %s", validFilename)
 
+	// Protected backups stay until their protection is lifted
+	if server.backupManager != nil {
+		if protected, err := server.backupManager.IsProtected(ctx, validFilename); err != nil {
+			logger.Error("Failed to check the protection of backup %s: %v", validFilename, err)
+			ErrorResponse(ctx, http.StatusInternalServerError, "Failed to delete backup file", err)
+			return
+		} else if protected {
+			ErrorResponse(ctx, http.StatusConflict, "Backup is protected from deletion", nil)
+			return
+		}
+	}
+
+	// Backups kept in remote storage are deleted there with their local copy
+	if server.remoteBackupStorage() {
+		if err := server.backupManager.DeleteBackup(ctx, validFilename); err != nil {
+			if errors.Is(err, backup.ErrBackupNotFound) {
+				ErrorResponse(ctx, http.StatusNotFound, "Backup file not found", nil)
+				return
+			}
+			logger.Error("Failed to delete remote backup: %v", err)
+			ErrorResponse(ctx, http.StatusInternalServerError, "Failed to delete backup file", err)
+			return
+		}
+		logger.Info("Remote backup successfully deleted: %s", validFilename)
+		SuccessResponse(ctx, http.StatusOK, "Backup deleted successfully", nil)
+		return
+	}
+
 	// Path to backup file
 	backupPath := filepath.Join(".", "backups", validFilename)
 	logger.Debug("Backup file to delete: %s", backupPath)
@@ -379,13 +465,15 @@ func (server *Server) deleteBackup(ctx *gin.Context) {
 		return
 	}
 
+	server.backupManager.ForgetBackup(ctx, validFilename)
+
 	logger.Info("Backup file successfully deleted: %s", validFilename)
 	SuccessResponse(ctx, http.StatusOK, "Backup deleted successfully", nil)
 }
 
 // restoreRequest holds the data needed to restore a database
 type restoreRequest struct {
-	Filename string `json:"filename" binding:"required"`
+	Filename string `json:"filename" binding:"required,safe_filename"`
 }
 
 // @Summary     Restore database from backup
@@ -420,8 +508,8 @@ func (server *Server) restoreBackup(ctx *gin.Context) {
 	}
 	logger.Debug("Validated filename for restore: %s", validFilename)
 
-	// Path to backup file
-	backupPath := filepath.Join(".", "backups", validFilename)
+	// Path to backup file, downloaded from remote storage if needed
+	backupPath := server.localBackupPath(ctx, validFilename)
 	logger.Debug("Looking for backup at path: %s", backupPath)
 
 	// Check if file exists and get size
@@ -438,6 +526,14 @@ func (server *Server) restoreBackup(ctx *gin.Context) {
 
 	logger.Info("Preparing to restore database from file: %s (size: %d bytes, modified: %s)",
 		validFilename, fileInfo.Size(), fileInfo.ModTime().Format(time.RFC3339))
+
+	// Refuse writes until the restore finished
+	releaseReadOnly, ok := server.enterReadOnly(ctx, "backup restore")
+	if !ok {
+		return
+	}
+	defer releaseReadOnly()
+
 	// Extract database configuration
 	dbUser := server.config.DBUser
 	dbPassword := server.config.DBPassword
@@ -452,18 +548,11 @@ func (server *Server) restoreBackup(ctx *gin.Context) {
 	// Force UTF-8 client encoding to prevent encoding issues
 	env = append(env, "PGCLIENTENCODING=UTF8")
 
-	// Get psql command with appropriate path
-	psqlCmd, cmdErr := util.GetPsqlCommand()
-	if cmdErr != nil {
-		logger.Error("Failed to find psql command: %v", cmdErr)
-		ErrorResponse(ctx, http.StatusInternalServerError, "psql command not found", cmdErr)
-		return
-	}
-	logger.Debug("Using psql command: %s", psqlCmd)
-
-	// Create psql command to restore from the backup with UTF-8 encoding
-	cmd := exec.Command(
-		psqlCmd,
+	// Create psql command to restore from the backup with UTF-8 encoding,
+	// run as configured
+	cmd, cmdErr := server.backupManager.Tools().Command(
+		context.Background(),
+		util.ToolPsql,
 		"--host="+dbHost,
 		"--port="+dbPort,
 		"--username="+dbUser,
@@ -471,6 +560,12 @@ func (server *Server) restoreBackup(ctx *gin.Context) {
 		"--set=client_encoding=UTF8",
 		"--file="+backupPath,
 	)
+	if cmdErr != nil {
+		logger.Error("Failed to find psql command: %v", cmdErr)
+		ErrorResponse(ctx, http.StatusInternalServerError, "psql command not found", cmdErr)
+		return
+	}
+	logger.Debug("Using psql command: %s", cmd.Path)
 
 	// Set environment variables
 	cmd.Env = env
@@ -540,89 +635,3 @@ func (server *Server) restoreBackup(ctx *gin.Context) {
 	SuccessResponse(ctx, http.StatusOK, "Database restored successfully", nil)
 }
 
-// @Summary     Upload database backup
-// @Description Uploads a backup file to the server
-// @Tags        admin
-// @Accept      multipart/form-data
-// @Produce     json
-// @Param       file formData file true "Backup SQL file"
-// @Param       description formData string false "Backup description"
-// @Success     200 {object} Response{data=backupResponse} "Backup uploaded successfully"
-// @Failure     400 {object} Response "Invalid file or description"
-// @Failure     500 {object} Response "Failed to save backup file"
-// @Security    ApiKeyAuth
-// @Router      /api/v1/admin/backups/upload [post]
-func (server *Server) uploadBackup(ctx *gin.Context) {
-	// Get file from form data
-	file, header, err := ctx.Request.FormFile("file")
-	if err != nil {
-		ErrorResponse(ctx, http.StatusBadRequest, "No backup file found in request", err)
-		return
-	}
-	defer file.Close()
-
-	// Validate file extension
-	if filepath.Ext(header.Filename) != ".sql" {
-		ErrorResponse(ctx, http.StatusBadRequest, "Only .sql files are allowed", nil)
-		return
-	}
-
-	// Get description from form data
-	description := ctx.PostForm("description")
-
-	// Ensure the backup directory exists
-	backupDir := filepath.Join(".", "backups")
-	if err := ensureBackupDir(backupDir); err != nil {
-		logger.Error("Failed to create backup directory: %v", err)
-		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to create backup directory", err)
-		return
-	}
-
-	// Create destination file
-	filename := header.Filename
-	destPath := filepath.Join(backupDir, filename)
-
-	// Check if file already exists and append timestamp if needed
-	if _, err := os.Stat(destPath); err == nil {
-		filename = fmt.Sprintf("%s_%s%s",
-			strings.TrimSuffix(header.Filename, filepath.Ext(header.Filename)),
-			time.Now().Format("20060102_150405"),
-			filepath.Ext(header.Filename))
-		destPath = filepath.Join(backupDir, filename)
-	}
-
-	dest, err := os.Create(destPath)
-	if err != nil {
-		logger.Error("Failed to create destination file: %v", err)
-		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to save backup file", err)
-		return
-	}
-	defer dest.Close()
-
-	// Copy file contents
-	_, err = io.Copy(dest, file)
-	if err != nil {
-		logger.Error("Failed to copy file contents: %v", err)
-		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to save backup file", err)
-		return
-	}
-
-	// Get file info
-	fileInfo, err := os.Stat(destPath)
-	if err != nil {
-		logger.Error("Failed to get backup file info: %v", err)
-		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to get backup file info", err)
-		return
-	}
-
-	// Create response
-	response := backupResponse{
-		Filename:    filename,
-		Description: description,
-		Size:        fileInfo.Size(),
-		CreatedAt:   time.Now(),
-		DownloadURL: fmt.Sprintf("/api/v1/admin/backups/download/%s", filename),
-	}
-
-	SuccessResponse(ctx, http.StatusOK, "Backup file uploaded successfully", response)
-}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/backup"
+	"github.com/toeic-app/internal/logger"
+	"github.com/toeic-app/internal/token"
+)
+
+// backupQuarantineDir holds uploaded backups until they are approved
+var backupQuarantineDir = filepath.Join(".", "backups", "quarantine")
+
+// @Summary     Upload database backup
+// @Description Uploads a plain (.sql) or gzipped (.sql.gz) SQL dump. The file type is verified from its content and must match the declared SHA-256. Verified files are quarantined and only become restorable after approval.
+// @Tags        admin
+// @Accept      multipart/form-data
+// @Produce     json
+// @Param       file formData file true "Backup SQL file (.sql or .sql.gz)"
+// @Param       sha256 formData string true "Hex SHA-256 checksum of the file"
+// @Param       description formData string false "Backup description"
+// @Success     202 {object} Response{data=backup.QuarantineEntry} "Backup uploaded to quarantine"
+// @Failure     400 {object} Response "Invalid file, checksum mismatch or not a SQL dump"
+// @Failure     413 {object} Response "Backup file too large"
+// @Failure     500 {object} Response "Failed to save backup file"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/backups/upload [post]
+func (server *Server) uploadBackup(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	file, header, err := ctx.Request.FormFile("file")
+	if err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "No backup file found in request", err)
+		return
+	}
+	defer file.Close()
+
+	if header.Size > server.config.MaxBackupUploadSize {
+		ErrorResponse(ctx, http.StatusRequestEntityTooLarge, "Backup file too large",
+			fmt.Errorf("maximum size is %d bytes", server.config.MaxBackupUploadSize))
+		return
+	}
+
+	entry, err := server.backupQuarantine.Save(file, backup.QuarantineEntry{
+		OriginalName: header.Filename,
+		Description:  ctx.PostForm("description"),
+		UploadedBy:   authPayload.ID,
+	}, ctx.PostForm("sha256"), server.config.MaxBackupUploadSize)
+	switch {
+	case errors.Is(err, backup.ErrUploadTooLarge):
+		ErrorResponse(ctx, http.StatusRequestEntityTooLarge, "Backup file too large", err)
+		return
+	case errors.Is(err, backup.ErrInvalidBackupUpload), errors.Is(err, backup.ErrChecksumMismatch):
+		logger.Warn("Rejected backup upload %q from user %d: %v", header.Filename, authPayload.ID, err)
+		ErrorResponse(ctx, http.StatusBadRequest, "Backup file rejected", err)
+		return
+	case err != nil:
+		logger.Error("Failed to quarantine backup upload: %v", err)
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to save backup file", err)
+		return
+	}
+
+	logger.Info("Backup %s uploaded by user %d and quarantined (sha256: %s)", entry.Filename, authPayload.ID, entry.SHA256)
+	SuccessResponse(ctx, http.StatusAccepted, "Backup uploaded to quarantine, approval required before restore", entry)
+}
+
+// @Summary     List quarantined backups
+// @Description Lists uploaded backups waiting for approval
+// @Tags        admin
+// @Produce     json
+// @Success     200 {object} Response{data=[]backup.QuarantineEntry} "Quarantined backups retrieved successfully"
+// @Failure     500 {object} Response "Failed to list quarantined backups"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/backups/quarantine [get]
+func (server *Server) listQuarantinedBackups(ctx *gin.Context) {
+	entries, err := server.backupQuarantine.List()
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to list quarantined backups", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Quarantined backups retrieved successfully", entries)
+}
+
+// @Summary     Approve quarantined backup
+// @Description Re-verifies the checksum of a quarantined upload and moves it to the backup directory, where it can be restored. Requires the system.restore permission and, unless disabled, a TOTP code in the X-TOTP-Code header.
+// @Tags        admin
+// @Produce     json
+// @Param       filename path string true "Quarantined backup filename"
+// @Param       X-TOTP-Code header string false "Current TOTP code"
+// @Success     200 {object} Response{data=backup.QuarantineEntry} "Backup approved"
+// @Failure     400 {object} Response "Invalid filename or checksum mismatch"
+// @Failure     403 {object} Response "Two-factor verification failed"
+// @Failure     404 {object} Response "Backup is not in quarantine"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/backups/quarantine/{filename}/approve [post]
+func (server *Server) approveQuarantinedBackup(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	if server.config.BackupApprovalRequireMFA && !server.requireTOTP(ctx) {
+		return
+	}
+
+	entry, err := server.backupQuarantine.Release(ctx.Param("filename"), filepath.Join(".", "backups"))
+	switch {
+	case errors.Is(err, backup.ErrNotQuarantined):
+		ErrorResponse(ctx, http.StatusNotFound, "Backup is not in quarantine", err)
+		return
+	case errors.Is(err, backup.ErrInvalidBackupUpload), errors.Is(err, backup.ErrChecksumMismatch):
+		ErrorResponse(ctx, http.StatusBadRequest, "Backup cannot be approved", err)
+		return
+	case err != nil:
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to approve backup", err)
+		return
+	}
+
+	if err := server.backupManager.TrackBackup(ctx, entry.Filename, "uploaded", ""); err != nil {
+		logger.Warn("Failed to catalog approved backup %s: %v", entry.Filename, err)
+	}
+
+	addSecurityEventDetail(ctx, "backup", entry.Filename)
+	logger.Info("Backup %s (uploaded by user %d) approved by user %d", entry.Filename, entry.UploadedBy, authPayload.ID)
+	SuccessResponse(ctx, http.StatusOK, "Backup approved", entry)
+}
+
+// @Summary     Reject quarantined backup
+// @Description Deletes an uploaded backup from quarantine
+// @Tags        admin
+// @Produce     json
+// @Param       filename path string true "Quarantined backup filename"
+// @Success     200 {object} Response "Backup removed from quarantine"
+// @Failure     404 {object} Response "Backup is not in quarantine"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/backups/quarantine/{filename} [delete]
+func (server *Server) rejectQuarantinedBackup(ctx *gin.Context) {
+	err := server.backupQuarantine.Remove(ctx.Param("filename"))
+	switch {
+	case errors.Is(err, backup.ErrNotQuarantined), errors.Is(err, backup.ErrInvalidBackupUpload):
+		ErrorResponse(ctx, http.StatusNotFound, "Backup is not in quarantine", err)
+		return
+	case err != nil:
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to remove backup from quarantine", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Backup removed from quarantine", nil)
+}
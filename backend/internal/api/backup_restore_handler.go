@@ -0,0 +1,83 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/backup"
+	"github.com/toeic-app/internal/logger"
+	"github.com/toeic-app/internal/token"
+)
+
+// restoreError maps restore tracker errors to responses
+func restoreError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, backup.ErrRestoreNotFound):
+		ErrorResponse(ctx, http.StatusNotFound, "Restore not found", err)
+	case errors.Is(err, backup.ErrRestoreFinished):
+		ErrorResponse(ctx, http.StatusConflict, "Restore already finished", err)
+	default:
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to get restore", err)
+	}
+}
+
+// @Summary     List restores
+// @Description Lists the running restore and the last 20 finished ones, newest first, with their phase, tables and rows restored and elapsed time. Restores are tracked by the instance running them, so ask the instance the restore was started on.
+// @Tags        admin
+// @Produce     json
+// @Success     200 {object} Response{data=[]backup.Restore} "Restores retrieved successfully"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     403 {object} Response "Permission denied"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/backups/restores [get]
+func (server *Server) listRestores(ctx *gin.Context) {
+	SuccessResponse(ctx, http.StatusOK, "Restores retrieved successfully", server.backupRestores.List())
+}
+
+// @Summary     Get a restore
+// @Description Polls a restore: its state (running, succeeded, failed, canceled), its phase (preparing, safety_backup, staging, restoring, rolling_back, validating, finished), the tables and rows restored out of those the backups copy, an estimate of the percentage done, the elapsed time and, once finished, its result or error. The same is pushed as restore.progress and restore.finished events to subscribers of the admin.restores WebSocket topic.
+// @Tags        admin
+// @Produce     json
+// @Param       id path string true "Restore ID"
+// @Success     200 {object} Response{data=backup.Restore} "Restore retrieved successfully"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     403 {object} Response "Permission denied"
+// @Failure     404 {object} Response "Restore not found"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/backups/restores/{id} [get]
+// @Router      /api/v1/admin/backups/restore/{id}/progress [get]
+func (server *Server) getRestore(ctx *gin.Context) {
+	restore, err := server.backupRestores.Get(ctx.Param("id"))
+	if err != nil {
+		restoreError(ctx, err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Restore retrieved successfully", restore)
+}
+
+// @Summary     Cancel a restore
+// @Description Stops a running restore. Before the database is changed the restore simply stops. A full restore stopped while restoring rolls the database back to the safety backup taken before it; a selective restore is rolled back with its transaction. Poll the restore until it is canceled.
+// @Tags        admin
+// @Produce     json
+// @Param       id path string true "Restore ID"
+// @Success     202 {object} Response{data=backup.Restore} "Restore cancellation requested"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     403 {object} Response "Permission denied"
+// @Failure     404 {object} Response "Restore not found"
+// @Failure     409 {object} Response "Restore already finished"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/backups/restores/{id}/cancel [post]
+func (server *Server) cancelRestore(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	restore, err := server.backupRestores.Cancel(ctx.Param("id"))
+	if err != nil {
+		restoreError(ctx, err)
+		return
+	}
+
+	logger.Info("Restore %s of %s cancelled by %s in the %s phase", restore.ID, restore.Filename, authPayload.Username, restore.Progress.Phase)
+	SuccessResponse(ctx, http.StatusAccepted, "Restore cancellation requested", restore)
+}
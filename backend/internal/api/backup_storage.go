@@ -0,0 +1,69 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/toeic-app/internal/backup"
+	"github.com/toeic-app/internal/config"
+	"github.com/toeic-app/internal/db/migrations"
+	"github.com/toeic-app/internal/logger"
+)
+
+// backupBeforePendingMigrations takes a migration backup when the database
+// is behind the migrations the server was built with, so the backup is
+// ready, and reused by backup-admin pre-migrate, when they are applied
+func (server *Server) backupBeforePendingMigrations() {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Minute)
+	defer cancel()
+
+	result, err := server.backupManager.BackupBeforeMigration(ctx, migrations.LatestVersion())
+	if err != nil {
+		logger.Error("Failed to back up before pending migrations: %v", err)
+		return
+	}
+	if result != nil {
+		logger.Info("Schema migrations to version %d are pending, backed up to %s",
+			migrations.LatestVersion(), result.Metadata.Filename)
+	}
+}
+
+// newBackupManager creates a backup manager for backupConfig that records
+// its backups in the backup catalog and backs up private media assets
+// through the media uploader
+func (server *Server) newBackupManager(backupConfig config.BackupConfig) *backup.BackupManager {
+	manager := backup.NewBackupManager(backupConfig, server.config)
+	manager.SetCatalog(server.backupCatalog)
+	manager.SetAssetSigner(server.uploader)
+	return manager
+}
+
+// remoteBackupStorage reports whether backups are kept in remote storage
+// such as S3 instead of the local backup directory
+func (server *Server) remoteBackupStorage() bool {
+	return server.backupManager != nil && server.backupManager.Storage().Type() != "local"
+}
+
+// localBackupPath returns the path of a backup in the local backup
+// directory. A backup only kept in remote storage is downloaded first.
+func (server *Server) localBackupPath(ctx context.Context, filename string) string {
+	backupPath := filepath.Join(".", "backups", filename)
+	if !server.remoteBackupStorage() {
+		return backupPath
+	}
+	if _, err := os.Stat(backupPath); err == nil {
+		return backupPath
+	}
+
+	path, err := server.backupManager.LocalCopy(ctx, filename)
+	if err != nil {
+		if !errors.Is(err, backup.ErrBackupNotFound) {
+			logger.Warn("Failed to download backup %s from remote storage: %v", filename, err)
+		}
+		return backupPath
+	}
+	return path
+}
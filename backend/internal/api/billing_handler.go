@@ -0,0 +1,393 @@
+package api
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/billing"
+	"github.com/toeic-app/internal/logger"
+	"github.com/toeic-app/internal/token"
+)
+
+// linkPurchaseRequest links a store purchase made in the mobile app to the current user
+type linkPurchaseRequest struct {
+	Provider   string `json:"provider" binding:"required,oneof=google_play app_store"`
+	ProductID  string `json:"product_id" binding:"required,max=255"`
+	ExternalID string `json:"external_id" binding:"required,max=255"` // Play purchase token or App Store original transaction ID
+}
+
+// grantSubscriptionRequest grants a manual subscription
+type grantSubscriptionRequest struct {
+	UserID int32  `json:"user_id" binding:"required,min=1"`
+	Plan   string `json:"plan" binding:"required"`
+	Days   int32  `json:"days" binding:"required,min=1,max=3650"`
+}
+
+// listBillingEventsRequest pages through received webhook events
+type listBillingEventsRequest struct {
+	Result string `form:"result" binding:"omitempty,oneof=received processed ignored unmatched failed"`
+	Limit  int32  `form:"limit,default=50" binding:"min=1,max=200"`
+	Offset int32  `form:"offset,default=0" binding:"min=0"`
+}
+
+// billingErrorStatus maps billing errors to HTTP statuses
+func billingErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, billing.ErrPlanNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, billing.ErrDuplicatePlan), errors.Is(err, billing.ErrPurchaseLinked):
+		return http.StatusConflict
+	case errors.Is(err, billing.ErrInvalidPlan), errors.Is(err, billing.ErrUnknownProduct),
+		errors.Is(err, billing.ErrUnsupportedProvider):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// newAppStoreVerifier loads the Apple root certificate, returning nil when App Store webhooks are disabled
+func newAppStoreVerifier(path string) *billing.AppStoreVerifier {
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		logger.Error("Failed to read App Store root certificate, App Store webhooks disabled: %v", err)
+		return nil
+	}
+	verifier, err := billing.NewAppStoreVerifier(data)
+	if err != nil {
+		logger.Error("App Store webhooks disabled: %v", err)
+		return nil
+	}
+	return verifier
+}
+
+// requireEntitlement responds 402 and returns false when the current user lacks an entitlement
+func (server *Server) requireEntitlement(ctx *gin.Context, userID int32, entitlement string) bool {
+	entitled, err := server.billing.HasEntitlement(ctx, userID, entitlement)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to check subscription", err)
+		return false
+	}
+	if !entitled {
+		ErrorResponse(ctx, http.StatusPaymentRequired, "A premium subscription is required", nil)
+		return false
+	}
+	return true
+}
+
+// @Summary     List plans
+// @Description Lists the active subscription plans with their entitlements and the store products that sell them
+// @Tags        subscriptions
+// @Produce     json
+// @Success     200 {object} Response{data=[]billing.Plan} "Plans retrieved successfully"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/subscriptions/plans [get]
+func (server *Server) listPlans(ctx *gin.Context) {
+	plans, err := server.billing.ListPlans(ctx, true)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve plans", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Plans retrieved successfully", plans)
+}
+
+// @Summary     Get my subscription
+// @Description Returns the entitlements of the current user, their subscriptions and today's AI scoring use (limit -1 when unlimited)
+// @Tags        subscriptions
+// @Produce     json
+// @Success     200 {object} Response{data=billing.Status} "Subscription retrieved successfully"
+// @Failure     401 {object} Response "Unauthorized"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/users/me/subscription [get]
+func (server *Server) getMySubscription(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	status, err := server.billing.UserStatus(ctx, authPayload.ID)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve subscription", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Subscription retrieved successfully", status)
+}
+
+// @Summary     Link store purchase
+// @Description Links a Google Play or App Store purchase made in the app to the current user. The subscription stays pending until the store notifies its state; notifications received before linking are applied immediately.
+// @Tags        subscriptions
+// @Accept      json
+// @Produce     json
+// @Param       purchase body linkPurchaseRequest true "Store purchase"
+// @Success     200 {object} Response{data=billing.Status} "Purchase linked successfully"
+// @Failure     400 {object} Response "Invalid request or unknown product"
+// @Failure     409 {object} Response "Purchase is linked to another user"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/users/me/subscriptions/link [post]
+func (server *Server) linkPurchase(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	var req linkPurchaseRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request parameters", err)
+		return
+	}
+
+	if _, err := server.billing.Link(ctx, authPayload.ID, req.Provider, req.ExternalID, req.ProductID); err != nil {
+		ErrorResponse(ctx, billingErrorStatus(err), "Failed to link purchase", err)
+		return
+	}
+	status, err := server.billing.UserStatus(ctx, authPayload.ID)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve subscription", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Purchase linked successfully", status)
+}
+
+// handleBillingEvent stores and applies a verified event. Failures return 500
+// so the provider delivers the event again.
+func (server *Server) handleBillingEvent(ctx *gin.Context, event *billing.Event, payload []byte) {
+	result, err := server.billing.HandleEvent(ctx, event, payload)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to process event", err)
+		return
+	}
+	SuccessResponse(ctx, http.StatusOK, "Event received", gin.H{"result": result})
+}
+
+// @Summary     Stripe webhook
+// @Description Receives Stripe events verified with the Stripe-Signature header. customer.subscription.* events update subscriptions; the user is read from the user_id metadata set at checkout.
+// @Tags        subscriptions
+// @Accept      json
+// @Produce     json
+// @Success     200 {object} Response "Event received"
+// @Failure     400 {object} Response "Invalid signature or payload"
+// @Failure     404 {object} Response "Stripe webhooks are not configured"
+// @Router      /api/v1/billing/webhooks/stripe [post]
+func (server *Server) stripeWebhook(ctx *gin.Context) {
+	if server.config.StripeWebhookSecret == "" {
+		ErrorResponse(ctx, http.StatusNotFound, "Stripe webhooks are not configured", nil)
+		return
+	}
+	payload, err := ctx.GetRawData()
+	if err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Failed to read payload", err)
+		return
+	}
+	if err := billing.VerifyStripeSignature(payload, ctx.GetHeader("Stripe-Signature"), server.config.StripeWebhookSecret, time.Now()); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid signature", err)
+		return
+	}
+	event, err := billing.ParseStripe(payload)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid payload", err)
+		return
+	}
+
+	server.handleBillingEvent(ctx, event, payload)
+}
+
+// @Summary     Google Play webhook
+// @Description Receives Real-time Developer Notifications from a Pub/Sub push subscription whose endpoint URL carries the configured token
+// @Tags        subscriptions
+// @Accept      json
+// @Produce     json
+// @Param       token query string true "Webhook token"
+// @Success     200 {object} Response "Event received"
+// @Failure     400 {object} Response "Invalid payload"
+// @Failure     401 {object} Response "Invalid token"
+// @Failure     404 {object} Response "Google Play webhooks are not configured"
+// @Router      /api/v1/billing/webhooks/google-play [post]
+func (server *Server) googlePlayWebhook(ctx *gin.Context) {
+	expected := server.config.GooglePlayWebhookToken
+	if expected == "" {
+		ErrorResponse(ctx, http.StatusNotFound, "Google Play webhooks are not configured", nil)
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(ctx.Query("token")), []byte(expected)) != 1 {
+		ErrorResponse(ctx, http.StatusUnauthorized, "Invalid token", nil)
+		return
+	}
+	payload, err := ctx.GetRawData()
+	if err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Failed to read payload", err)
+		return
+	}
+	event, err := billing.ParseGooglePlay(payload)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid payload", err)
+		return
+	}
+
+	server.handleBillingEvent(ctx, event, payload)
+}
+
+// @Summary     App Store webhook
+// @Description Receives App Store Server Notifications V2, verified against the configured Apple root certificate
+// @Tags        subscriptions
+// @Accept      json
+// @Produce     json
+// @Success     200 {object} Response "Event received"
+// @Failure     400 {object} Response "Invalid signature or payload"
+// @Failure     404 {object} Response "App Store webhooks are not configured"
+// @Router      /api/v1/billing/webhooks/app-store [post]
+func (server *Server) appStoreWebhook(ctx *gin.Context) {
+	if server.appStoreVerifier == nil {
+		ErrorResponse(ctx, http.StatusNotFound, "App Store webhooks are not configured", nil)
+		return
+	}
+	payload, err := ctx.GetRawData()
+	if err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Failed to read payload", err)
+		return
+	}
+	event, err := server.appStoreVerifier.Parse(payload)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid notification", err)
+		return
+	}
+
+	server.handleBillingEvent(ctx, event, payload)
+}
+
+// @Summary     List all plans
+// @Description Lists all plans, including inactive ones (admin only)
+// @Tags        admin
+// @Produce     json
+// @Success     200 {object} Response{data=[]billing.Plan} "Plans retrieved successfully"
+// @Failure     403 {object} Response "Insufficient permissions"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/billing/plans [get]
+func (server *Server) adminListPlans(ctx *gin.Context) {
+	plans, err := server.billing.ListPlans(ctx, false)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve plans", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Plans retrieved successfully", plans)
+}
+
+// @Summary     Create plan
+// @Description Creates a plan with its entitlements and store products (admin only)
+// @Tags        admin
+// @Accept      json
+// @Produce     json
+// @Param       plan body billing.PlanInput true "Plan"
+// @Success     201 {object} Response{data=billing.Plan} "Plan created successfully"
+// @Failure     400 {object} Response "Invalid plan"
+// @Failure     409 {object} Response "Plan code or store product already exists"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/billing/plans [post]
+func (server *Server) createPlan(ctx *gin.Context) {
+	var req billing.PlanInput
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request parameters", err)
+		return
+	}
+
+	plan, err := server.billing.CreatePlan(ctx, req)
+	if err != nil {
+		ErrorResponse(ctx, billingErrorStatus(err), "Failed to create plan", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusCreated, "Plan created successfully", plan)
+}
+
+// @Summary     Update plan
+// @Description Updates a plan and replaces its store products. The code cannot be changed. (admin only)
+// @Tags        admin
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Plan ID"
+// @Param       plan body billing.PlanInput true "Plan"
+// @Success     200 {object} Response{data=billing.Plan} "Plan updated successfully"
+// @Failure     400 {object} Response "Invalid plan"
+// @Failure     404 {object} Response "Plan not found"
+// @Failure     409 {object} Response "Store product already sold by another plan"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/billing/plans/{id} [put]
+func (server *Server) updatePlan(ctx *gin.Context) {
+	var uri struct {
+		ID int32 `uri:"id" binding:"required,min=1"`
+	}
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid plan ID", err)
+		return
+	}
+	var req billing.PlanInput
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request parameters", err)
+		return
+	}
+
+	plan, err := server.billing.UpdatePlan(ctx, uri.ID, req)
+	if err != nil {
+		ErrorResponse(ctx, billingErrorStatus(err), "Failed to update plan", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Plan updated successfully", plan)
+}
+
+// @Summary     Grant subscription
+// @Description Grants a user a manual subscription to a plan for a number of days (admin only)
+// @Tags        admin
+// @Accept      json
+// @Produce     json
+// @Param       grant body grantSubscriptionRequest true "Grant"
+// @Success     201 {object} Response "Subscription granted successfully"
+// @Failure     400 {object} Response "Invalid request parameters"
+// @Failure     404 {object} Response "Plan not found"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/billing/subscriptions [post]
+func (server *Server) grantSubscription(ctx *gin.Context) {
+	var req grantSubscriptionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request parameters", err)
+		return
+	}
+
+	sub, err := server.billing.Grant(ctx, req.UserID, req.Plan, req.Days)
+	if err != nil {
+		ErrorResponse(ctx, billingErrorStatus(err), "Failed to grant subscription", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusCreated, "Subscription granted successfully", sub)
+}
+
+// @Summary     List billing events
+// @Description Lists received webhook events, newest first, optionally filtered by processing result (admin only)
+// @Tags        admin
+// @Produce     json
+// @Param       result query string false "Processing result" Enums(received, processed, ignored, unmatched, failed)
+// @Param       limit query int false "Page size (max 200)" default(50)
+// @Param       offset query int false "Offset" default(0)
+// @Success     200 {object} Response "Billing events retrieved successfully"
+// @Failure     400 {object} Response "Invalid query parameters"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/billing/events [get]
+func (server *Server) listBillingEvents(ctx *gin.Context) {
+	var req listBillingEventsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	events, err := server.billing.ListEvents(ctx, req.Result, req.Limit, req.Offset)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve billing events", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Billing events retrieved successfully", events)
+}
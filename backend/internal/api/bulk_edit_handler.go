@@ -0,0 +1,246 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/bulkedit"
+	"github.com/toeic-app/internal/jobs"
+	"github.com/toeic-app/internal/token"
+)
+
+// maxBulkEditUploadSize bounds bulk edit CSV files; a full batch with long
+// explanations stays far below it
+const maxBulkEditUploadSize = 5 << 20
+
+type bulkEditQuestionsRequest struct {
+	Items  []bulkedit.QuestionPatch `json:"items" binding:"required"`
+	DryRun bool                     `json:"dry_run"`
+}
+
+type bulkEditContentsRequest struct {
+	Items  []bulkedit.ContentPatch `json:"items" binding:"required"`
+	DryRun bool                    `json:"dry_run"`
+}
+
+// bulkEditError writes the response of a batch that could not run
+func bulkEditError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, bulkedit.ErrEmptyBatch), errors.Is(err, bulkedit.ErrBatchTooLarge),
+		errors.Is(err, bulkedit.ErrMissingIDColumn), errors.Is(err, bulkedit.ErrInvalidFile):
+		ErrorResponse(ctx, http.StatusBadRequest, err.Error(), err)
+	default:
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to apply bulk edit", err)
+	}
+}
+
+// bulkEditResponse reports a batch: 200 when it was applied or was a clean
+// dry run, 422 when an item failed and nothing was kept
+func bulkEditResponse(ctx *gin.Context, result *bulkedit.Result) {
+	switch {
+	case result.Failed > 0:
+		SuccessResponse(ctx, http.StatusUnprocessableEntity, "Bulk edit rejected; no changes were applied", result)
+	case result.DryRun:
+		SuccessResponse(ctx, http.StatusOK, "Bulk edit checked; no changes were applied", result)
+	default:
+		SuccessResponse(ctx, http.StatusOK, "Bulk edit applied successfully", result)
+	}
+}
+
+// bulkEditQuestionsResult is an applied question batch, with the job
+// re-grading past answers to the questions whose correct answer changed
+type bulkEditQuestionsResult struct {
+	*bulkedit.Result
+	RegradeJob *jobs.Job `json:"regrade_job,omitempty"`
+}
+
+// bulkEditQuestionsResponse reports a question batch like bulkEditResponse,
+// queueing a re-grade when it changed correct answers
+func (server *Server) bulkEditQuestionsResponse(ctx *gin.Context, result *bulkedit.Result) {
+	if !result.Applied {
+		bulkEditResponse(ctx, result)
+		return
+	}
+
+	var questionIDs []int32
+	for _, item := range result.Items {
+		if slices.Contains(item.Changed, "answer_key") {
+			questionIDs = append(questionIDs, item.ID)
+		}
+	}
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+	SuccessResponse(ctx, http.StatusOK, "Bulk edit applied successfully", bulkEditQuestionsResult{
+		Result:     result,
+		RegradeJob: server.queueRegrade(ctx, authPayload.ID, questionIDs),
+	})
+}
+
+// bulkEditFile opens the uploaded CSV file of a bulk edit and reads its
+// dry_run field. It writes the error response and returns false on failure.
+func bulkEditFile(ctx *gin.Context) (io.ReadCloser, bool, bool) {
+	file, header, err := ctx.Request.FormFile("file")
+	if err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "No CSV file found in request", err)
+		return nil, false, false
+	}
+	if header.Size > maxBulkEditUploadSize {
+		file.Close()
+		ErrorResponse(ctx, http.StatusRequestEntityTooLarge, "CSV file too large",
+			fmt.Errorf("maximum size is %d bytes", maxBulkEditUploadSize))
+		return nil, false, false
+	}
+	dryRun := false
+	if value := ctx.PostForm("dry_run"); value != "" {
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			file.Close()
+			ErrorResponse(ctx, http.StatusBadRequest, "Invalid dry_run value", err)
+			return nil, false, false
+		}
+	}
+	return file, dryRun, true
+}
+
+// @Summary     Bulk edit questions
+// @Description Applies partial updates to many questions in one transaction: fix titles and explanations, retag (replace keywords, or add_keywords/remove_keywords), adjust difficulty (1-6, 0 clears it) or change answer options (options keyed A, B, C... with answer_key, or possible_answers and true_answer texts from older clients). Fields left out keep their value. When the correct option changes, the applied result carries a regrade_answers job re-grading past answers to those questions. Every item is checked and reported; if any item fails, nothing is applied and the others are reported as rolled_back. With dry_run the changes are reported but never applied. At most 1000 items.
+// @Tags        admin
+// @Accept      json
+// @Produce     json
+// @Param       request body bulkEditQuestionsRequest true "Question patches"
+// @Success     200 {object} Response{data=bulkEditQuestionsResult} "Bulk edit applied successfully"
+// @Failure     400 {object} Response "Invalid request body or batch size"
+// @Failure     403 {object} Response "Missing content.update permission"
+// @Failure     422 {object} Response{data=bulkedit.Result} "An item failed; no changes were applied"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/questions/bulk [patch]
+func (server *Server) bulkEditQuestions(ctx *gin.Context) {
+	var req bulkEditQuestionsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	server.sanitizer.Struct(&req)
+
+	result, err := server.bulkEdit.Questions(ctx, req.Items, req.DryRun)
+	if err != nil {
+		bulkEditError(ctx, err)
+		return
+	}
+	server.bulkEditQuestionsResponse(ctx, result)
+}
+
+// @Summary     Bulk edit questions from CSV
+// @Description Applies the question patches of a CSV file with a header row, like PATCH /admin/questions/bulk. Columns: question_id (required), title, explanation, keywords, add_keywords, remove_keywords (";" separated), difficulty, options (written "A=text;B=text"), answer_key, true_answer, possible_answers (";" separated). Empty cells leave the field unchanged. Item results carry the line of their row; when a row cannot be read nothing is applied and row_errors lists the bad rows.
+// @Tags        admin
+// @Accept      multipart/form-data
+// @Produce     json
+// @Param       file formData file true "CSV file with a header row"
+// @Param       dry_run formData bool false "Report the changes without applying them"
+// @Success     200 {object} Response{data=bulkEditQuestionsResult} "Bulk edit applied successfully"
+// @Failure     400 {object} Response "Invalid file"
+// @Failure     403 {object} Response "Missing content.update permission"
+// @Failure     413 {object} Response "CSV file too large"
+// @Failure     422 {object} Response{data=bulkedit.Result} "A row or item failed; no changes were applied"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/questions/bulk/csv [post]
+func (server *Server) bulkEditQuestionsCSV(ctx *gin.Context) {
+	file, dryRun, ok := bulkEditFile(ctx)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	patches, rowErrors, err := bulkedit.ParseQuestions(io.LimitReader(file, maxBulkEditUploadSize))
+	if err != nil {
+		bulkEditError(ctx, err)
+		return
+	}
+	if len(rowErrors) > 0 {
+		bulkEditResponse(ctx, bulkedit.Rejected(rowErrors, dryRun))
+		return
+	}
+	for i := range patches {
+		server.sanitizer.Struct(&patches[i])
+	}
+
+	result, err := server.bulkEdit.Questions(ctx, patches, dryRun)
+	if err != nil {
+		bulkEditError(ctx, err)
+		return
+	}
+	server.bulkEditQuestionsResponse(ctx, result)
+}
+
+// @Summary     Bulk edit contents
+// @Description Applies partial updates (type, description) to many contents in one transaction, with the same per-item results and all-or-nothing behaviour as PATCH /admin/questions/bulk
+// @Tags        admin
+// @Accept      json
+// @Produce     json
+// @Param       request body bulkEditContentsRequest true "Content patches"
+// @Success     200 {object} Response{data=bulkedit.Result} "Bulk edit applied successfully"
+// @Failure     400 {object} Response "Invalid request body or batch size"
+// @Failure     403 {object} Response "Missing content.update permission"
+// @Failure     422 {object} Response{data=bulkedit.Result} "An item failed; no changes were applied"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/contents/bulk [patch]
+func (server *Server) bulkEditContents(ctx *gin.Context) {
+	var req bulkEditContentsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	server.sanitizer.Struct(&req)
+
+	result, err := server.bulkEdit.Contents(ctx, req.Items, req.DryRun)
+	if err != nil {
+		bulkEditError(ctx, err)
+		return
+	}
+	bulkEditResponse(ctx, result)
+}
+
+// @Summary     Bulk edit contents from CSV
+// @Description Applies the content patches of a CSV file with a header row. Columns: content_id (required), type, description. Empty cells leave the field unchanged.
+// @Tags        admin
+// @Accept      multipart/form-data
+// @Produce     json
+// @Param       file formData file true "CSV file with a header row"
+// @Param       dry_run formData bool false "Report the changes without applying them"
+// @Success     200 {object} Response{data=bulkedit.Result} "Bulk edit applied successfully"
+// @Failure     400 {object} Response "Invalid file"
+// @Failure     403 {object} Response "Missing content.update permission"
+// @Failure     413 {object} Response "CSV file too large"
+// @Failure     422 {object} Response{data=bulkedit.Result} "A row or item failed; no changes were applied"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/contents/bulk/csv [post]
+func (server *Server) bulkEditContentsCSV(ctx *gin.Context) {
+	file, dryRun, ok := bulkEditFile(ctx)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	patches, rowErrors, err := bulkedit.ParseContents(io.LimitReader(file, maxBulkEditUploadSize))
+	if err != nil {
+		bulkEditError(ctx, err)
+		return
+	}
+	if len(rowErrors) > 0 {
+		bulkEditResponse(ctx, bulkedit.Rejected(rowErrors, dryRun))
+		return
+	}
+	for i := range patches {
+		server.sanitizer.Struct(&patches[i])
+	}
+
+	result, err := server.bulkEdit.Contents(ctx, patches, dryRun)
+	if err != nil {
+		bulkEditError(ctx, err)
+		return
+	}
+	bulkEditResponse(ctx, result)
+}
@@ -32,7 +32,7 @@ func (server *Server) getCacheStats(ctx *gin.Context) {
 	// Basic cache info
 	stats["enabled"] = server.config.CacheEnabled
 	stats["type"] = server.config.CacheType
-	stats["default_ttl"] = server.config.CacheDefaultTTL.String()
+	stats["default_ttl"] = server.configReloader.Current().CacheDefaultTTL.String()
 	stats["http_cache_enabled"] = server.config.HTTPCacheEnabled
 	stats["http_cache_ttl"] = server.config.HTTPCacheTTL.String()
 
@@ -321,7 +321,7 @@ func (server *Server) triggerCacheWarming(ctx *gin.Context) {
 // @Failure 401 {object} Response "Unauthorized"
 // @Failure 400 {object} Response "Bad Request"
 // @Failure 500 {object} Response "Internal Server Error"
-// @Router /api/v1/admin/cache/invalidate/tag/{tag} [delete]
+// @Router /api/v1/admin/cache/invalidate/tag/{tag} [post]
 func (server *Server) invalidateCacheByTag(ctx *gin.Context) {
 	if !server.config.CacheEnabled {
 		ErrorResponse(ctx, http.StatusBadRequest, "Cache is not enabled", nil)
@@ -363,7 +363,7 @@ func (server *Server) getCacheStatsData() map[string]interface{} {
 	// Basic cache info
 	stats["enabled"] = server.config.CacheEnabled
 	stats["type"] = server.config.CacheType
-	stats["default_ttl"] = server.config.CacheDefaultTTL.String()
+	stats["default_ttl"] = server.configReloader.Current().CacheDefaultTTL.String()
 	stats["http_cache_enabled"] = server.config.HTTPCacheEnabled
 	stats["http_cache_ttl"] = server.config.HTTPCacheTTL.String()
 
@@ -0,0 +1,187 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/calendar"
+	"github.com/toeic-app/internal/token"
+)
+
+// calendarFeedResponse is the subscription URL of a calendar feed and its settings
+type calendarFeedResponse struct {
+	URL            string     `json:"url"`
+	WebcalURL      string     `json:"webcal_url"`
+	StudyTime      string     `json:"study_time"`
+	Timezone       string     `json:"timezone"`
+	CreatedAt      time.Time  `json:"created_at"`
+	LastAccessedAt *time.Time `json:"last_accessed_at,omitempty"`
+}
+
+// configureCalendarFeedRequest sets when study sessions appear on the calendar
+type configureCalendarFeedRequest struct {
+	StudyTime string `json:"study_time" example:"19:00"`
+	Timezone  string `json:"timezone" example:"Asia/Ho_Chi_Minh"`
+}
+
+// externalURL returns the absolute URL of a path on the host the request
+// reached, honoring TLS termination at a proxy
+func externalURL(ctx *gin.Context, path string) string {
+	scheme := "http"
+	if ctx.Request.TLS != nil || ctx.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + ctx.Request.Host + path
+}
+
+// newCalendarFeedResponse builds the feed URLs from the host the request reached
+func newCalendarFeedResponse(ctx *gin.Context, feed *calendar.Feed) calendarFeedResponse {
+	path := "/api/v1/calendar/" + feed.Token + ".ics"
+
+	return calendarFeedResponse{
+		URL:            externalURL(ctx, path),
+		WebcalURL:      "webcal://" + ctx.Request.Host + path,
+		StudyTime:      feed.StudyTime,
+		Timezone:       feed.Timezone,
+		CreatedAt:      feed.CreatedAt,
+		LastAccessedAt: feed.LastAccessedAt,
+	}
+}
+
+// @Summary     Get my calendar feed
+// @Description Returns the signed ICS feed URL of the study plan that can be subscribed to from Google or Apple Calendar
+// @Tags        users
+// @Produce     json
+// @Success     200 {object} Response{data=calendarFeedResponse} "Calendar feed retrieved successfully"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     404 {object} Response "Calendar feed not enabled"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/users/me/calendar [get]
+func (server *Server) getMyCalendarFeed(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	feed, err := server.calendar.Get(ctx, authPayload.ID)
+	if err != nil {
+		if errors.Is(err, calendar.ErrFeedNotFound) {
+			ErrorResponse(ctx, http.StatusNotFound, "Calendar feed not enabled", err)
+			return
+		}
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to get calendar feed", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Calendar feed retrieved successfully", newCalendarFeedResponse(ctx, feed))
+}
+
+// @Summary     Enable or configure my calendar feed
+// @Description Enables the ICS feed of the study plan, or changes the daily study time and time zone of an enabled feed. The feed URL stays the same.
+// @Tags        users
+// @Accept      json
+// @Produce     json
+// @Param       settings body configureCalendarFeedRequest false "Study time (HH:MM, default 19:00) and IANA time zone (default UTC)"
+// @Success     200 {object} Response{data=calendarFeedResponse} "Calendar feed saved successfully"
+// @Failure     400 {object} Response "Invalid study time or time zone"
+// @Failure     401 {object} Response "Unauthorized"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/users/me/calendar [put]
+func (server *Server) configureMyCalendarFeed(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	var req configureCalendarFeedRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ErrorResponse(ctx, http.StatusBadRequest, "Invalid request parameters", err)
+			return
+		}
+	}
+
+	feed, err := server.calendar.Configure(ctx, authPayload.ID, req.StudyTime, req.Timezone)
+	if err != nil {
+		if errors.Is(err, calendar.ErrInvalidStudyTime) || errors.Is(err, calendar.ErrInvalidTimezone) {
+			ErrorResponse(ctx, http.StatusBadRequest, err.Error(), err)
+			return
+		}
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to save calendar feed", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Calendar feed saved successfully", newCalendarFeedResponse(ctx, feed))
+}
+
+// @Summary     Regenerate my calendar feed URL
+// @Description Issues a new feed URL. The previous URL stops working, so existing calendar subscriptions must be re-added.
+// @Tags        users
+// @Produce     json
+// @Success     200 {object} Response{data=calendarFeedResponse} "Calendar feed URL regenerated successfully"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     404 {object} Response "Calendar feed not enabled"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/users/me/calendar/regenerate [post]
+func (server *Server) regenerateMyCalendarFeed(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	feed, err := server.calendar.Rotate(ctx, authPayload.ID)
+	if err != nil {
+		if errors.Is(err, calendar.ErrFeedNotFound) {
+			ErrorResponse(ctx, http.StatusNotFound, "Calendar feed not enabled", err)
+			return
+		}
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to regenerate calendar feed", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Calendar feed URL regenerated successfully", newCalendarFeedResponse(ctx, feed))
+}
+
+// @Summary     Revoke my calendar feed
+// @Description Disables the ICS feed. The feed URL stops working; enabling the feed again issues a new URL.
+// @Tags        users
+// @Produce     json
+// @Success     200 {object} Response "Calendar feed revoked successfully"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     404 {object} Response "Calendar feed not enabled"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/users/me/calendar [delete]
+func (server *Server) revokeMyCalendarFeed(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	if err := server.calendar.Revoke(ctx, authPayload.ID); err != nil {
+		if errors.Is(err, calendar.ErrFeedNotFound) {
+			ErrorResponse(ctx, http.StatusNotFound, "Calendar feed not enabled", err)
+			return
+		}
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to revoke calendar feed", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Calendar feed revoked successfully", nil)
+}
+
+// @Summary     Study plan calendar feed
+// @Description Returns the study plan as an ICS calendar: recurring study sessions, scheduled mock tests, the exam day and countdown reminders. Authenticated by the signed token in the URL.
+// @Tags        calendar
+// @Produce     text/calendar
+// @Param       token path string true "Signed feed token, optionally with an .ics suffix"
+// @Success     200 {string} string "ICS calendar"
+// @Failure     404 {object} Response "Calendar feed not found"
+// @Router      /api/v1/calendar/{token} [get]
+func (server *Server) getCalendarFeedICS(ctx *gin.Context) {
+	feedToken := strings.TrimSuffix(ctx.Param("token"), ".ics")
+
+	ics, err := server.calendar.Render(ctx, feedToken)
+	if err != nil {
+		// Unknown, revoked and forged tokens look the same to the caller
+		if errors.Is(err, calendar.ErrInvalidToken) {
+			ErrorResponse(ctx, http.StatusNotFound, "Calendar feed not found", err)
+			return
+		}
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to render calendar feed", err)
+		return
+	}
+
+	ctx.Header("Cache-Control", "private, max-age=3600")
+	ctx.Data(http.StatusOK, "text/calendar; charset=utf-8", ics)
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/calibration"
+)
+
+type listQuestionCalibrationsQuery struct {
+	MinDrift int32 `form:"min_drift,default=1" binding:"min=0,max=5"`
+	Limit    int32 `form:"limit,default=20" binding:"min=1,max=100"`
+	Offset   int32 `form:"offset" binding:"min=0"`
+}
+
+// @Summary     Question calibration status
+// @Description Reports how many questions have a difficulty calibrated from answers and when it was last computed. Requires exams.update. (admin only)
+// @Tags        admin
+// @Produce     json
+// @Success     200 {object} Response{data=calibration.Summary} "Question calibration status retrieved successfully"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     403 {object} Response "Permission denied"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/question-calibration [get]
+func (server *Server) getQuestionCalibrationStatus(ctx *gin.Context) {
+	summary, err := server.calibration.Summary(ctx)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to get question calibration status", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Question calibration status retrieved successfully", summary)
+}
+
+// @Summary     List calibrated questions
+// @Description Lists calibrated questions whose calibrated difficulty is at least min_drift levels away from the authored difficulty, biggest difference first. Questions without an authored difficulty are always listed. Requires exams.update. (admin only)
+// @Tags        admin
+// @Produce     json
+// @Param       min_drift query int false "Smallest difference between calibrated and authored difficulty" default(1)
+// @Param       limit query int false "Maximum questions" default(20)
+// @Param       offset query int false "Questions to skip" default(0)
+// @Success     200 {object} Response{data=[]calibration.Question} "Calibrated questions retrieved successfully"
+// @Failure     400 {object} Response "Invalid query parameters"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     403 {object} Response "Permission denied"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/question-calibration/questions [get]
+func (server *Server) listQuestionCalibrations(ctx *gin.Context) {
+	var query listQuestionCalibrationsQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	questions, total, err := server.calibration.Questions(ctx, query.MinDrift, query.Limit, query.Offset)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to list calibrated questions", err)
+		return
+	}
+
+	PaginatedResponse(ctx, http.StatusOK, "Calibrated questions retrieved successfully", questions,
+		NewPagination(query.Limit, query.Offset, len(questions)).WithTotal(total))
+}
+
+// @Summary     Calibrate question difficulty
+// @Description Recomputes the difficulty of every question with enough answers now instead of waiting for the next scheduled run. Requires exams.update. (admin only)
+// @Tags        admin
+// @Produce     json
+// @Success     200 {object} Response{data=calibration.Stats} "Question difficulty calibrated successfully"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     403 {object} Response "Permission denied"
+// @Failure     409 {object} Response "A calibration is already running"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/question-calibration/run [post]
+func (server *Server) calibrateQuestionDifficulty(ctx *gin.Context) {
+	stats, err := server.calibration.Calibrate(ctx)
+	if err != nil {
+		if errors.Is(err, calibration.ErrRunInProgress) {
+			ErrorResponse(ctx, http.StatusConflict, err.Error(), err)
+			return
+		}
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to calibrate question difficulty", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Question difficulty calibrated successfully", stats)
+}
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	configPkg "github.com/toeic-app/internal/config"
+	"github.com/toeic-app/internal/logger"
+	"github.com/toeic-app/internal/security"
+)
+
+// CaptchaTokenHeader carries the token of a solved CAPTCHA challenge
+const CaptchaTokenHeader = "X-Captcha-Token"
+
+// CaptchaChallengeResponse tells clients which challenge to render
+type CaptchaChallengeResponse struct {
+	Required bool   `json:"required"`
+	Reason   string `json:"reason,omitempty"`
+	Provider string `json:"provider"`
+	SiteKey  string `json:"site_key"`
+}
+
+// newCaptchaProtection creates the bot detector and CAPTCHA verifier.
+// Both are nil when CAPTCHA is disabled (the default, and in tests).
+func newCaptchaProtection(config configPkg.Config) (*security.BotDetector, *security.CaptchaVerifier) {
+	if !config.CaptchaEnabled {
+		return nil, nil
+	}
+
+	verifier, err := security.NewCaptchaVerifier(config.CaptchaProvider, config.CaptchaSecretKey)
+	if err != nil {
+		logger.Error("Failed to initialize CAPTCHA, challenges disabled: %v", err)
+		return nil, nil
+	}
+
+	logger.Info("CAPTCHA challenges enabled on auth endpoints (provider: %s)", config.CaptchaProvider)
+	detector := security.NewBotDetector(config.CaptchaFailureThreshold, config.CaptchaVelocityThreshold, config.CaptchaWindow)
+	return detector, verifier
+}
+
+// authAccountFromBody reads the email of an auth request without consuming the body
+func authAccountFromBody(ctx *gin.Context) string {
+	if ctx.Request.Body == nil {
+		return ""
+	}
+
+	body, err := io.ReadAll(ctx.Request.Body)
+	ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return ""
+	}
+
+	var req struct {
+		Email string `json:"email"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return ""
+	}
+	return req.Email
+}
+
+// captchaMiddleware requires a solved CAPTCHA on auth requests that look automated
+func (server *Server) captchaMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if server.botDetector == nil {
+			ctx.Next()
+			return
+		}
+
+		ip := ctx.ClientIP()
+		account := authAccountFromBody(ctx)
+		server.botDetector.RecordRequest(ip)
+
+		required, reason := server.botDetector.RequiresChallenge(ip, account)
+		if !required {
+			ctx.Next()
+			return
+		}
+
+		ctx.Header("X-Captcha-Required", "true")
+		ctx.Header("X-Captcha-Provider", server.captchaVerifier.Provider())
+		ctx.Header("X-Captcha-Site-Key", server.config.CaptchaSiteKey)
+
+		err := server.captchaVerifier.Verify(ctx.Request.Context(), ctx.GetHeader(CaptchaTokenHeader), ip)
+		if err == nil {
+			ctx.Next()
+			return
+		}
+
+		logger.Warn("CAPTCHA challenge failed for %s on %s (reason: %s): %v", ip, ctx.Request.URL.Path, reason, err)
+		message := "CAPTCHA verification failed"
+		if errors.Is(err, security.ErrCaptchaMissing) {
+			message = "CAPTCHA verification required"
+		}
+		ErrorResponse(ctx, http.StatusForbidden, message, err)
+		ctx.Abort()
+	}
+}
+
+// recordAuthFailure counts a failed login towards the CAPTCHA threshold
+func (server *Server) recordAuthFailure(ctx *gin.Context, account string) {
+	addSecurityEventDetail(ctx, "account", account)
+	if server.botDetector != nil {
+		server.botDetector.RecordFailure(ctx.ClientIP(), account)
+	}
+}
+
+// recordAuthSuccess resets the failed login count of an account
+func (server *Server) recordAuthSuccess(account string) {
+	if server.botDetector != nil {
+		server.botDetector.RecordSuccess(account)
+	}
+}
+
+// @Summary     Get CAPTCHA challenge status
+// @Description Reports whether the next login or registration from this client must include a solved CAPTCHA in the X-Captcha-Token header
+// @Tags        auth
+// @Produce     json
+// @Param       email query string false "Account email"
+// @Success     200 {object} Response{data=CaptchaChallengeResponse} "CAPTCHA status retrieved successfully"
+// @Router      /api/auth/captcha [get]
+func (server *Server) getCaptchaStatus(ctx *gin.Context) {
+	response := CaptchaChallengeResponse{
+		Provider: server.config.CaptchaProvider,
+		SiteKey:  server.config.CaptchaSiteKey,
+	}
+	if server.botDetector != nil {
+		response.Required, response.Reason = server.botDetector.RequiresChallenge(ctx.ClientIP(), ctx.Query("email"))
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "CAPTCHA status retrieved successfully", response)
+}
@@ -0,0 +1,149 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/ailimit"
+	"github.com/toeic-app/internal/logger"
+	"github.com/toeic-app/internal/upgrade"
+)
+
+// clientConfigQuery describes the app asking for its configuration
+type clientConfigQuery struct {
+	AppVersion string `form:"app_version" binding:"omitempty,semver" example:"1.1.0"`
+}
+
+// clientConfigResponse tells the apps what the server offers right now, so
+// they adapt without hard-coding it
+type clientConfigResponse struct {
+	// Features are the feature flags evaluated for the user, with those
+	// listed in FEATURE_FLAGS on
+	Features    map[string]bool         `json:"features"`
+	App         clientAppConfig         `json:"app"`
+	AI          clientAIConfig          `json:"ai"`
+	Maintenance clientMaintenanceConfig `json:"maintenance"`
+	Quotas      clientQuotaConfig       `json:"quotas"`
+}
+
+// clientAppConfig describes the supported app versions
+type clientAppConfig struct {
+	LatestVersion string `json:"latest_version" example:"1.2.0"`
+	// MinVersion is the oldest version allowed to keep running
+	MinVersion string `json:"min_version,omitempty" example:"1.0.0"`
+	// UpdateRequired is set when app_version is older than MinVersion
+	UpdateRequired bool `json:"update_required"`
+}
+
+// clientAIConfig tells which AI features can be used and how much text
+// they take
+type clientAIConfig struct {
+	// Available is set when any AI feature is available
+	Available        bool `json:"available"`
+	WritingScoring   bool `json:"writing_scoring"`
+	SpeakingPractice bool `json:"speaking_practice"`
+	TextAnalysis     bool `json:"text_analysis"`
+	// Limits bound the text of each kind of AI submission
+	Limits ailimit.Limits `json:"limits"`
+}
+
+// clientMaintenanceConfig tells whether the API is under maintenance
+type clientMaintenanceConfig struct {
+	// ReadOnly is set while writes are refused, e.g. during a restore
+	ReadOnly bool       `json:"read_only"`
+	Reason   string     `json:"reason,omitempty" example:"Restoring the database"`
+	Since    *time.Time `json:"since,omitempty"`
+	// Draining is set while the instance refuses new work before a restart
+	Draining bool `json:"draining"`
+	// Notice is CLIENT_MAINTENANCE_NOTICE, e.g. of planned maintenance
+	Notice string `json:"notice,omitempty" example:"Maintenance on Sunday from 02:00 to 03:00 UTC"`
+}
+
+// clientQuotaConfig lists the limits of the user. Zero is unlimited.
+type clientQuotaConfig struct {
+	// UploadMaxBytes bounds the body of an upload
+	UploadMaxBytes      int64 `json:"upload_max_bytes" example:"52428800"`
+	UploadDailyBytes    int64 `json:"upload_daily_bytes" example:"209715200"`
+	UploadDailyFiles    int64 `json:"upload_daily_files" example:"100"`
+	AIRequestsPerMinute int   `json:"ai_requests_per_minute" example:"10"`
+	AIRequestsPerHour   int   `json:"ai_requests_per_hour" example:"100"`
+}
+
+// @Summary     Get client configuration
+// @Description Get what the app should adapt to: the feature flags of the user, the minimum supported app version, which AI features are available, maintenance notices and the quotas of the user. Pass app_version to learn whether the app must be updated. Settings come from the feature flags, the reloadable configuration (CLIENT_MIN_APP_VERSION, CLIENT_MAINTENANCE_NOTICE), read-only mode and the dependency graph.
+// @Tags        client
+// @Produce     json
+// @Param       app_version query string false "Version of the app, e.g. 1.1.0"
+// @Success     200 {object} Response{data=clientConfigResponse} "Client configuration retrieved successfully"
+// @Failure     400 {object} Response "Invalid query parameters"
+// @Failure     401 {object} Response "Unauthorized"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/client-config [get]
+func (server *Server) getClientConfig(ctx *gin.Context) {
+	var query clientConfigQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+	current := server.configReloader.Current()
+
+	features := make(map[string]bool)
+	if server.featureFlags != nil {
+		flags, err := server.featureFlags.ListFlags(ctx)
+		if err != nil {
+			// The apps fall back to their defaults for flags not listed
+			logger.Warn("Failed to list feature flags for client configuration: %v", err)
+		}
+		evalCtx := server.featureEvalContext(ctx)
+		for _, flag := range flags {
+			features[flag.Key] = flag.Evaluate(evalCtx)
+		}
+	}
+	for _, key := range current.EnabledFeatures() {
+		features[key] = true
+	}
+
+	app := clientAppConfig{
+		LatestVersion: server.upgradeService.GetCurrentVersion().Version,
+		MinVersion:    server.upgradeService.MinSupportedVersion(current.ClientMinAppVersion),
+	}
+	if query.AppVersion != "" {
+		app.UpdateRequired = !upgrade.Supported(query.AppVersion, app.MinVersion)
+	}
+
+	ai := clientAIConfig{
+		WritingScoring:   server.aiScoringService != nil && server.dependencies.Available("writing_scoring"),
+		SpeakingPractice: server.aiScoringService != nil && server.dependencies.Available("speaking_practice"),
+		TextAnalysis:     server.config.AnalyzeServiceEnabled && server.analyzeService != nil && server.dependencies.Available("text_analysis"),
+		Limits:           server.aiLimits,
+	}
+	ai.Available = ai.WritingScoring || ai.SpeakingPractice || ai.TextAnalysis
+
+	readOnly := server.readOnly.Status(ctx.Request.Context())
+	maintenance := clientMaintenanceConfig{
+		ReadOnly: readOnly.Enabled,
+		Reason:   readOnly.Reason,
+		Since:    readOnly.Since,
+		Draining: server.drain.Draining(),
+		Notice:   current.ClientMaintenanceNotice,
+	}
+
+	quotas := clientQuotaConfig{
+		UploadMaxBytes:   server.config.MaxUploadBodySize,
+		UploadDailyBytes: server.config.UploadDailyQuotaBytes,
+		UploadDailyFiles: server.config.UploadDailyQuotaFiles,
+	}
+	if server.rateLimiter != nil {
+		quotas.AIRequestsPerMinute = server.config.AIRateLimitPerMinute
+		quotas.AIRequestsPerHour = server.config.AIRateLimitPerHour
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Client configuration retrieved successfully", clientConfigResponse{
+		Features:    features,
+		App:         app,
+		AI:          ai,
+		Maintenance: maintenance,
+		Quotas:      quotas,
+	})
+}
@@ -11,7 +11,7 @@ import (
 // @Description Returns detailed concurrency metrics including active operations, worker pool status, and performance statistics
 // @Tags Performance
 // @Produce json
-// @Success 200 {object} object{concurrency_metrics=object,connection_pool_stats=object,request_handler_stats=object} "Concurrency metrics retrieved successfully"
+// @Success 200 {object} Response{data=object{concurrency_metrics=object,connection_pool_stats=object,request_handler_stats=object}} "Concurrency metrics retrieved successfully"
 // @Failure 500 {object} Response "Internal Server Error"
 // @Router /api/v1/performance/concurrency [get]
 func (server *Server) getConcurrencyMetrics(ctx *gin.Context) {
@@ -88,14 +88,14 @@ func (server *Server) getConcurrencyMetrics(ctx *gin.Context) {
 		}
 	}
 
-	ctx.JSON(http.StatusOK, response)
+	SuccessResponse(ctx, http.StatusOK, "Concurrency metrics retrieved successfully", response)
 }
 
 // @Summary Get concurrency health status
 // @Description Returns health status of concurrency management components
 // @Tags Performance
 // @Produce json
-// @Success 200 {object} object{overall_health=string,components=object} "Health status retrieved successfully"
+// @Success 200 {object} Response{data=object{overall_health=string,components=object}} "Health status retrieved successfully"
 // @Failure 500 {object} Response "Internal Server Error"
 // @Router /api/v1/performance/concurrency/health [get]
 func (server *Server) getConcurrencyHealth(ctx *gin.Context) {
@@ -202,7 +202,7 @@ func (server *Server) getConcurrencyHealth(ctx *gin.Context) {
 		response["overall_health"] = "degraded"
 	}
 
-	ctx.JSON(http.StatusOK, response)
+	SuccessResponse(ctx, http.StatusOK, "Health status retrieved successfully", response)
 }
 
 // @Summary Reset concurrency metrics
@@ -248,5 +248,5 @@ func (server *Server) resetConcurrencyMetrics(ctx *gin.Context) {
 		}
 	}
 	logger.Info("Concurrency metrics reset by admin")
-	ctx.JSON(http.StatusOK, gin.H{"message": "Concurrency metrics reset successfully"})
+	SuccessResponse(ctx, http.StatusOK, "Concurrency metrics reset successfully", nil)
 }
@@ -0,0 +1,147 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	configPkg "github.com/toeic-app/internal/config"
+	"github.com/toeic-app/internal/logger"
+	"github.com/toeic-app/internal/middleware"
+)
+
+// reloadableConfigResponse lists the settings that can change without a restart
+type reloadableConfigResponse struct {
+	RateLimitRequests       int    `json:"rate_limit_requests"`
+	RateLimitBurst          int    `json:"rate_limit_burst"`
+	AuthRateLimitRequests   int    `json:"auth_rate_limit_requests"`
+	AuthRateLimitBurst      int    `json:"auth_rate_limit_burst"`
+	CacheDefaultTTL         string `json:"cache_default_ttl"`
+	HTTPCacheTTL            string `json:"http_cache_ttl"`
+	FeatureFlags            string `json:"feature_flags"`
+	ClientMinAppVersion     string `json:"client_min_app_version"`
+	ClientMaintenanceNotice string `json:"client_maintenance_notice"`
+	RequestSigningMode      string `json:"request_signing_mode"`
+	ReadOnlyMode            bool   `json:"read_only_mode"`
+	CORSAllowedOrigins      string `json:"cors_allowed_origins"`
+	CORSMaxAge              string `json:"cors_max_age"`
+}
+
+func newReloadableConfigResponse(cfg configPkg.Config) reloadableConfigResponse {
+	return reloadableConfigResponse{
+		RateLimitRequests:       cfg.RateLimitRequests,
+		RateLimitBurst:          cfg.RateLimitBurst,
+		AuthRateLimitRequests:   cfg.AuthRateLimitRequests,
+		AuthRateLimitBurst:      cfg.AuthRateLimitBurst,
+		CacheDefaultTTL:         cfg.CacheDefaultTTL.String(),
+		HTTPCacheTTL:            cfg.HTTPCacheTTL.String(),
+		FeatureFlags:            cfg.FeatureFlags,
+		ClientMinAppVersion:     cfg.ClientMinAppVersion,
+		ClientMaintenanceNotice: cfg.ClientMaintenanceNotice,
+		RequestSigningMode:      cfg.RequestSigningMode,
+		ReadOnlyMode:            cfg.ReadOnlyMode,
+		CORSAllowedOrigins:      strings.Join(cfg.CORSOrigins(), ","),
+		CORSMaxAge:              cfg.CORSMaxAge.String(),
+	}
+}
+
+// applyReloadedConfig pushes reloaded settings into the running components
+func (server *Server) applyReloadedConfig(cfg configPkg.Config) {
+	if server.rateLimiter != nil {
+		server.rateLimiter.UpdateLimits(cfg)
+	}
+	if server.httpCache != nil {
+		server.httpCache.SetDefaultTTL(cfg.HTTPCacheTTL)
+	}
+	if server.requestSigner != nil {
+		server.requestSigner.UpdateKeys(cfg)
+	}
+	if server.corsPolicy != nil {
+		server.corsPolicy.Update(cfg)
+	}
+	logger.Info("Reloadable configuration applied (cache TTL %s, HTTP cache TTL %s, feature flags %q)",
+		cfg.CacheDefaultTTL, cfg.HTTPCacheTTL, cfg.FeatureFlags)
+}
+
+// @Summary Get reloadable configuration
+// @Description Get the live values of settings that can be changed without a restart
+// @Tags admin
+// @Produce json
+// @Success 200 {object} Response{data=reloadableConfigResponse} "Configuration retrieved successfully"
+// @Failure 401 {object} Response "Unauthorized"
+// @Failure 403 {object} Response "Forbidden"
+// @Security ApiKeyAuth
+// @Router /api/v1/admin/system/config [get]
+func (server *Server) getReloadableConfig(ctx *gin.Context) {
+	SuccessResponse(ctx, http.StatusOK, "Configuration retrieved successfully",
+		newReloadableConfigResponse(server.configReloader.Current()))
+}
+
+// @Summary Reload configuration
+// @Description Re-read the config file and environment and apply reloadable settings (rate limits, cache TTLs, feature flags, client configuration, CORS origins)
+// @Tags admin
+// @Produce json
+// @Success 200 {object} Response{data=reloadableConfigResponse} "Configuration reloaded successfully"
+// @Failure 401 {object} Response "Unauthorized"
+// @Failure 403 {object} Response "Forbidden"
+// @Failure 422 {object} Response "Invalid configuration, current configuration kept"
+// @Security ApiKeyAuth
+// @Router /api/v1/admin/system/config/reload [post]
+func (server *Server) reloadConfig(ctx *gin.Context) {
+	cfg, err := server.configReloader.Reload()
+	if err != nil {
+		logger.Warn("Config reload rejected: %v", err)
+		ErrorResponse(ctx, http.StatusUnprocessableEntity, "Invalid configuration, current configuration kept", err)
+		return
+	}
+
+	logger.Info("Configuration reloaded via admin endpoint")
+	SuccessResponse(ctx, http.StatusOK, "Configuration reloaded successfully", newReloadableConfigResponse(cfg))
+}
+
+// corsPolicyResponse is the CORS policy applied to requests
+type corsPolicyResponse struct {
+	Environment      string   `json:"environment" example:"production"`
+	Origins          []string `json:"origins" example:"https://toeic.app,flutter-app://toeic-app"`
+	Patterns         []string `json:"patterns" example:"https://*.toeic.app"`
+	AllowCredentials bool     `json:"allow_credentials" example:"true"`
+	AllowedMethods   []string `json:"allowed_methods"`
+	AllowedHeaders   []string `json:"allowed_headers"`
+	ExposedHeaders   []string `json:"exposed_headers"`
+	MaxAgeSeconds    int      `json:"max_age_seconds" example:"600"`
+	Origin           string   `json:"origin,omitempty" example:"https://admin.toeic.app"` // Origin checked with ?origin=
+	OriginAllowed    *bool    `json:"origin_allowed,omitempty" example:"true"`
+}
+
+// @Summary Get CORS policy
+// @Description Get the effective CORS policy of the app environment: exact origins, wildcard subdomain patterns, headers and preflight max age. Pass origin to check whether it is allowed.
+// @Tags admin
+// @Produce json
+// @Param origin query string false "Origin to check, e.g. https://admin.toeic.app"
+// @Success 200 {object} Response{data=corsPolicyResponse} "CORS policy retrieved successfully"
+// @Failure 401 {object} Response "Unauthorized"
+// @Failure 403 {object} Response "Forbidden"
+// @Security ApiKeyAuth
+// @Router /api/v1/admin/system/cors [get]
+func (server *Server) getCORSPolicy(ctx *gin.Context) {
+	rules := server.corsPolicy.Rules()
+	response := corsPolicyResponse{
+		Environment:      rules.Environment,
+		Origins:          rules.Origins,
+		Patterns:         rules.Patterns,
+		AllowCredentials: true,
+		AllowedMethods:   middleware.CORSAllowedMethods,
+		AllowedHeaders:   middleware.CORSAllowedHeaders,
+		ExposedHeaders:   middleware.CORSExposedHeaders,
+		MaxAgeSeconds:    int(rules.MaxAge.Seconds()),
+	}
+	if response.Patterns == nil {
+		response.Patterns = []string{}
+	}
+	if origin := ctx.Query("origin"); origin != "" {
+		allowed := server.corsPolicy.Allowed(origin)
+		response.Origin = origin
+		response.OriginAllowed = &allowed
+	}
+	SuccessResponse(ctx, http.StatusOK, "CORS policy retrieved successfully", response)
+}
@@ -31,7 +31,7 @@ func NewContentResponse(content db.Content) ContentResponse {
 type createContentRequest struct {
 	PartID      int32  `json:"part_id" binding:"required,min=1"`
 	Type        string `json:"type" binding:"required"`
-	Description string `json:"description" binding:"required"`
+	Description string `json:"description" binding:"required" sanitize:"rich_text"`
 }
 
 // @Summary     Create new content
@@ -51,6 +51,7 @@ func (server *Server) createContent(ctx *gin.Context) {
 		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
 		return
 	}
+	server.sanitizer.Struct(&req)
 
 	arg := db.CreateContentParams{
 		PartID:      req.PartID,
@@ -148,7 +149,7 @@ func (server *Server) listContentsByPart(ctx *gin.Context) {
 type updateContentRequest struct {
 	PartID      *int32  `json:"part_id,omitempty" binding:"omitempty,min=1"`
 	Type        *string `json:"type,omitempty"`
-	Description *string `json:"description,omitempty"`
+	Description *string `json:"description,omitempty" sanitize:"rich_text"`
 }
 
 // @Summary     Update content
@@ -176,6 +177,7 @@ func (server *Server) updateContent(ctx *gin.Context) {
 		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
 		return
 	}
+	server.sanitizer.Struct(&req)
 
 	// Get existing content to update only provided fields
 	existingContent, err := server.store.GetContent(ctx, int32(contentID))
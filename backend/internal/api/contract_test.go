@@ -0,0 +1,390 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swaggo/swag"
+	"github.com/toeic-app/docs"
+	"github.com/toeic-app/internal/config"
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+// swaggerSpec is the part of the generated swagger document the contract
+// tests read
+type swaggerSpec struct {
+	Paths       map[string]map[string]swaggerOperation `json:"paths"`
+	Definitions map[string]*swaggerSchema              `json:"definitions"`
+}
+
+type swaggerOperation struct {
+	Parameters []swaggerParameter             `json:"parameters"`
+	Security   []map[string][]string          `json:"security"`
+	Responses  map[string]swaggerResponseSpec `json:"responses"`
+}
+
+type swaggerParameter struct {
+	Name     string `json:"name"`
+	In       string `json:"in"`
+	Required bool   `json:"required"`
+}
+
+type swaggerResponseSpec struct {
+	Schema *swaggerSchema `json:"schema"`
+}
+
+type swaggerSchema struct {
+	Ref                  string                    `json:"$ref"`
+	Type                 string                    `json:"type"`
+	AllOf                []*swaggerSchema          `json:"allOf"`
+	Properties           map[string]*swaggerSchema `json:"properties"`
+	Required             []string                  `json:"required"`
+	Items                *swaggerSchema            `json:"items"`
+	AdditionalProperties json.RawMessage           `json:"additionalProperties"`
+}
+
+// additional is the schema of properties that are not listed, which is nil
+// when additionalProperties is a boolean
+func (s *swaggerSchema) additional() *swaggerSchema {
+	var schema swaggerSchema
+	if json.Unmarshal(s.AdditionalProperties, &schema) != nil {
+		return nil
+	}
+	return &schema
+}
+
+// documentedRoute is one operation of the swagger document
+type documentedRoute struct {
+	Method    string
+	Path      string
+	Operation swaggerOperation
+}
+
+func (r documentedRoute) String() string {
+	return r.Method + " " + r.Path
+}
+
+// secured reports whether the operation is documented as needing a token
+func (r documentedRoute) secured() bool {
+	return len(r.Operation.Security) > 0
+}
+
+// needsCredentials reports whether the operation is documented as needing a
+// token or a credential header such as X-API-Key
+func (r documentedRoute) needsCredentials() bool {
+	for _, parameter := range r.Operation.Parameters {
+		if parameter.In == "header" && parameter.Required {
+			return true
+		}
+	}
+	return r.secured()
+}
+
+// concretePath fills every path parameter with 1
+func (r documentedRoute) concretePath() string {
+	return swaggerParam.ReplaceAllString(r.Path, "1")
+}
+
+func loadSwagger(t *testing.T) (*swaggerSpec, []documentedRoute) {
+	t.Helper()
+	var spec swaggerSpec
+	require.NoError(t, json.Unmarshal([]byte(docs.SwaggerInfo.ReadDoc()), &spec))
+
+	var routes []documentedRoute
+	for path, operations := range spec.Paths {
+		for method, operation := range operations {
+			routes = append(routes, documentedRoute{Method: strings.ToUpper(method), Path: path, Operation: operation})
+		}
+	}
+	sort.Slice(routes, func(i, j int) bool { return routes[i].String() < routes[j].String() })
+	require.NotEmpty(t, routes)
+	return &spec, routes
+}
+
+// contractStore grants no permissions and users have no preferred language.
+// Calls of other queries panic, which the recovery middleware turns into a
+// 500.
+type contractStore struct {
+	db.Querier
+}
+
+func (contractStore) CheckUserPermission(context.Context, db.CheckUserPermissionParams) (bool, error) {
+	return false, nil
+}
+
+func (contractStore) CheckUserPermissionByResourceAction(context.Context, db.CheckUserPermissionByResourceActionParams) (bool, error) {
+	return false, nil
+}
+
+func (contractStore) GetUserRoles(context.Context, int32) ([]db.Role, error) {
+	return nil, nil
+}
+
+func (contractStore) GetUserPreferredLanguage(context.Context, sql.NullInt32) (sql.NullString, error) {
+	return sql.NullString{}, sql.ErrNoRows
+}
+
+// newContractServer registers every optional route group
+func newContractServer(t *testing.T) *testServer {
+	return newTestServer(t, contractStore{}, withConfig(func(cfg *config.Config) {
+		cfg.CacheEnabled = true
+		cfg.CacheType = "memory"
+		cfg.ConcurrencyEnabled = true
+		cfg.PublicAPIEnabled = true
+	}))
+}
+
+// probe sends an empty request to a documented route
+func probe(t *testing.T, ts *testServer, route documentedRoute, userID int32) (int, []byte) {
+	t.Helper()
+	var body interface{}
+	if route.Method == http.MethodPost || route.Method == http.MethodPut || route.Method == http.MethodPatch {
+		body = struct{}{}
+	}
+	recorder := ts.requestJSON(t, route.Method, route.concretePath(), body, userID)
+	return recorder.Code, recorder.Body.Bytes()
+}
+
+// TestContractSwaggerIsCurrent fails when annotations changed without
+// regenerating the swagger documents with `make swagger`
+func TestContractSwaggerIsCurrent(t *testing.T) {
+	if testing.Short() {
+		t.Skip("parsing every annotation takes a few seconds")
+	}
+	parser := swag.New(swag.SetParseDependency(1), swag.ParseUsingGoList(true),
+		swag.SetDebugger(log.New(io.Discard, "", 0)))
+	require.NoError(t, parser.ParseAPI("../..", "main.go", 100))
+	encoded, err := json.Marshal(parser.GetSwagger())
+	require.NoError(t, err)
+
+	var annotated, generated struct {
+		Paths       map[string]interface{} `json:"paths"`
+		Definitions map[string]interface{} `json:"definitions"`
+	}
+	require.NoError(t, json.Unmarshal(encoded, &annotated))
+	file, err := os.ReadFile("../../docs/swagger.json")
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(file, &generated))
+
+	for path, operations := range annotated.Paths {
+		assert.Equal(t, operations, generated.Paths[path], "%s differs from docs/swagger.json; run make swagger", path)
+	}
+	for path := range generated.Paths {
+		assert.Contains(t, annotated.Paths, path, "%s is no longer annotated; run make swagger", path)
+	}
+	for name, definition := range annotated.Definitions {
+		assert.Equal(t, definition, generated.Definitions[name], "%s differs from docs/swagger.json; run make swagger", name)
+	}
+}
+
+// registeredRoutes lists the routes of the router
+func registeredRoutes(ts *testServer) routeSet {
+	return newRouteSet(ts.router.Routes())
+}
+
+func TestContractDocumentedRoutesAreRegistered(t *testing.T) {
+	_, routes := loadSwagger(t)
+	registered := registeredRoutes(newContractServer(t))
+
+	for _, route := range routes {
+		assert.True(t, registered.has(route.Method, route.Path), "%s is documented but not registered", route)
+	}
+}
+
+func TestContractAuthRequirements(t *testing.T) {
+	spec, routes := loadSwagger(t)
+	ts := newContractServer(t)
+	registered := registeredRoutes(ts)
+
+	for _, route := range routes {
+		if !registered.has(route.Method, route.Path) {
+			continue
+		}
+		t.Run(route.String(), func(t *testing.T) {
+			status, body := probe(t, ts, route, 0)
+			if route.needsCredentials() {
+				assert.Equal(t, http.StatusUnauthorized, status, "secured route accepted a request without credentials")
+			} else {
+				assert.NotEqual(t, http.StatusUnauthorized, status, "route needs credentials but has no @Security annotation")
+			}
+			validateResponse(t, spec, route, status, body)
+		})
+	}
+}
+
+func TestContractAdminRoutesNeedPermission(t *testing.T) {
+	spec, routes := loadSwagger(t)
+	ts := newContractServer(t)
+	registered := registeredRoutes(ts)
+
+	for _, route := range routes {
+		if !strings.HasPrefix(route.Path, "/api/v1/admin/") || !registered.has(route.Method, route.Path) {
+			continue
+		}
+		t.Run(route.String(), func(t *testing.T) {
+			require.True(t, route.secured(), "admin route has no @Security annotation")
+			status, body := probe(t, ts, route, 1)
+			assert.Equal(t, http.StatusForbidden, status, "admin route accepted a user without permissions")
+			validateResponse(t, spec, route, status, body)
+		})
+	}
+}
+
+// undocumentedStatuses may be returned by any route: they come from
+// middleware rather than from handlers
+var undocumentedStatuses = map[int]bool{
+	http.StatusUnauthorized:        true,
+	http.StatusForbidden:           true,
+	http.StatusTooManyRequests:     true,
+	http.StatusInternalServerError: true,
+	http.StatusServiceUnavailable:  true,
+}
+
+// validateResponse checks a response against the schema documented for its
+// status. Statuses without a documented schema must still use the response
+// envelope.
+func validateResponse(t *testing.T, spec *swaggerSpec, route documentedRoute, status int, body []byte) {
+	t.Helper()
+	documented, ok := route.Operation.Responses[fmt.Sprint(status)]
+	if !ok {
+		assert.True(t, undocumentedStatuses[status], "status %d is not documented", status)
+	}
+	schema := documented.Schema
+	if schema == nil {
+		schema = &swaggerSchema{Ref: "#/definitions/api.Response"}
+	}
+
+	var value interface{}
+	require.NoError(t, json.Unmarshal(body, &value), "response is not JSON: %s", body)
+	for _, problem := range spec.validate(schema, value, "$") {
+		t.Errorf("response %d does not match the documented schema: %s", status, problem)
+	}
+}
+
+// validate lists where value departs from schema. Null matches any schema
+// because Go encodes nil pointers, slices and maps as null.
+func (spec *swaggerSpec) validate(schema *swaggerSchema, value interface{}, at string) []string {
+	if schema == nil || value == nil {
+		return nil
+	}
+	if schema.Ref != "" {
+		definition, ok := spec.Definitions[strings.TrimPrefix(schema.Ref, "#/definitions/")]
+		if !ok {
+			return []string{fmt.Sprintf("%s: unknown definition %s", at, schema.Ref)}
+		}
+		return spec.validate(definition, value, at)
+	}
+
+	var problems []string
+	for _, part := range schema.AllOf {
+		problems = append(problems, spec.validate(part, value, at)...)
+	}
+
+	mismatch := func() []string {
+		return append(problems, fmt.Sprintf("%s: expected %s, got %T", at, schema.Type, value))
+	}
+	switch schema.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return mismatch()
+		}
+		for _, name := range schema.Required {
+			if _, ok := object[name]; !ok {
+				problems = append(problems, fmt.Sprintf("%s: missing required %q", at, name))
+			}
+		}
+		for name, field := range object {
+			if property, ok := schema.Properties[name]; ok {
+				problems = append(problems, spec.validate(property, field, at+"."+name)...)
+			} else if additional := schema.additional(); additional != nil {
+				problems = append(problems, spec.validate(additional, field, at+"."+name)...)
+			}
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return mismatch()
+		}
+		for i, item := range items {
+			problems = append(problems, spec.validate(schema.Items, item, fmt.Sprintf("%s[%d]", at, i))...)
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			return mismatch()
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return mismatch()
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return mismatch()
+		}
+	case "integer":
+		if number, ok := value.(float64); !ok || number != float64(int64(number)) {
+			return mismatch()
+		}
+	}
+	return problems
+}
+
+func TestContractSwaggerSelfCheck(t *testing.T) {
+	ts := newContractServer(t)
+	recorder := ts.requestJSON(t, http.MethodGet, "/swagger/validate", nil, 0)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var report swaggerValidationResponse
+	decodeData(t, recorder, &report)
+	for _, check := range report.Checks {
+		assert.True(t, check.Passed, "%s: %s", check.Name, check.Detail)
+	}
+	assert.True(t, report.Valid)
+	assert.Len(t, report.Checks, 6)
+	for _, warning := range report.Warnings {
+		assert.NotContains(t, warning, "turned off", "every optional route group is registered")
+	}
+}
+
+func TestSwaggerSelfCheckFindsProblems(t *testing.T) {
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(docs.SwaggerInfo.ReadDoc()), &doc))
+	definitions := doc["definitions"].(map[string]interface{})
+	// A dangling reference, an error code the catalog lacks and an envelope
+	// field without example
+	definitions["api.Meta"].(map[string]interface{})["properties"].(map[string]interface{})["next"] = map[string]interface{}{"$ref": "#/definitions/api.Missing"}
+	errorCodes := definitions["errors.ErrorCode"].(map[string]interface{})
+	errorCodes["enum"] = append(errorCodes["enum"].([]interface{}), "TEAPOT")
+	definitions["api.Pagination"].(map[string]interface{})["properties"].(map[string]interface{})["cursor"] = map[string]interface{}{"type": "string"}
+	encoded, err := json.Marshal(doc)
+	require.NoError(t, err)
+
+	report := validateSwaggerDoc(string(encoded), gin.RoutesInfo{{Method: http.MethodGet, Path: "/api/v1/undocumented"}})
+	assert.False(t, report.Valid)
+	failed := make(map[string]string)
+	for _, check := range report.Checks {
+		if !check.Passed {
+			failed[check.Name] = check.Detail
+		}
+	}
+	assert.Equal(t, "#/definitions/api.Missing", failed[SwaggerCheckReferences])
+	assert.Equal(t, "errors.ErrorCode has unknown TEAPOT", failed[SwaggerCheckErrorCodes])
+	assert.Equal(t, "api.Pagination.cursor", failed[SwaggerCheckExamples])
+	assert.Contains(t, report.Warnings, "GET /api/v1/undocumented is not documented")
+
+	report = validateSwaggerDoc("{", nil)
+	assert.False(t, report.Valid)
+	assert.Equal(t, SwaggerCheckDocument, report.Checks[0].Name)
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/ai"
+	"github.com/toeic-app/internal/cache"
+	configPkg "github.com/toeic-app/internal/config"
+	"github.com/toeic-app/internal/dependencies"
+)
+
+// cloudinaryAPIURL answers without credentials, which is enough to tell
+// that Cloudinary is reachable
+const cloudinaryAPIURL = "https://api.cloudinary.com"
+
+// newDependencyGraph registers the configured dependencies and the features
+// that use them
+func newDependencyGraph(config configPkg.Config, dbConn *sql.DB, cacheInstance cache.Cache) *dependencies.Graph {
+	graph := dependencies.NewGraph(dependencies.Options{
+		Timeout:       config.DependencyProbeTimeout,
+		SlowThreshold: config.DependencySlowThreshold,
+	})
+	client := &http.Client{Timeout: config.DependencyProbeTimeout}
+
+	if dbConn != nil {
+		graph.AddDependency("database", dependencies.PingProbe(dbConn))
+	}
+	if config.CacheType == "redis" && cacheInstance != nil {
+		graph.AddDependency("redis", dependencies.CacheProbe(cacheInstance))
+	}
+	if config.OpenAIAPIKey != "" && config.OpenAIAPIURL != "" {
+		graph.AddDependency("ai_provider", dependencies.HTTPProbe(client, config.OpenAIAPIURL))
+	}
+	if config.AnalyzeServiceEnabled {
+		graph.AddDependency("analyze_service",
+			dependencies.HTTPProbe(client, strings.TrimRight(config.AnalyzeServiceURL, "/")+"/health"))
+	}
+	if config.CloudinaryURL != "" {
+		graph.AddDependency("cloudinary", dependencies.HTTPProbe(client, cloudinaryAPIURL))
+	}
+	graph.AddDependency("storage", dependencies.DirProbe(filepath.Join(".", "backups"), config.JobsDir))
+
+	graph.Depends("api", "database", true)
+	graph.Depends("api", "redis", false) // Requests fall back to the database
+	graph.Depends("writing_scoring", "database", true)
+	graph.Depends("writing_scoring", "ai_provider", true)
+	graph.Depends("speaking_practice", "database", true)
+	graph.Depends("speaking_practice", "ai_provider", true)
+	graph.Depends("speaking_practice", "cloudinary", true)
+	// Degraded mode answers analysis requests locally
+	graph.Depends("text_analysis", "analyze_service", !config.AnalyzeDegradedMode)
+	graph.Depends("media_uploads", "cloudinary", true)
+	graph.Depends("backups", "database", true)
+	graph.Depends("backups", "storage", true)
+	graph.Depends("background_jobs", "database", true)
+	graph.Depends("background_jobs", "storage", true)
+	return graph
+}
+
+// graphScorer reports the AI provider unhealthy while the dependency graph
+// has writing scoring down, so scoring fails at once instead of waiting for
+// the provider to time out
+type graphScorer struct {
+	ai.Provider
+	graph *dependencies.Graph
+}
+
+func (s graphScorer) IsHealthy() bool {
+	return s.Provider.IsHealthy() && s.graph.Available("writing_scoring")
+}
+
+// @Summary     Get the dependency graph
+// @Description Returns the health, score and probe latency of every dependency (database, Redis, AI provider, analyze service, Cloudinary, storage), the features using them and the edges between the two, for incident triage. A feature is down when a critical dependency is down and degraded when an optional one is. Requires the system.monitor permission. (admin only)
+// @Tags        monitoring
+// @Produce     json
+// @Success     200 {object} Response{data=dependencies.Report} "Dependency graph retrieved successfully"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     403 {object} Response "Permission denied"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/monitoring/dependencies [get]
+func (server *Server) getDependencyGraph(ctx *gin.Context) {
+	SuccessResponse(ctx, http.StatusOK, "Dependency graph retrieved successfully", server.dependencies.Report())
+}
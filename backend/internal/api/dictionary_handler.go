@@ -0,0 +1,145 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/dictionary"
+	"github.com/toeic-app/internal/logger"
+)
+
+// startDictionaryBackfillRequest limits how many words a backfill looks up
+type startDictionaryBackfillRequest struct {
+	Limit int `json:"limit" binding:"omitempty,min=1,max=10000"`
+}
+
+// @Summary     Start dictionary backfill
+// @Description Starts a background job that fills missing IPA, definitions and audio of words from the external dictionary, most frequent words first (admin only)
+// @Tags        admin
+// @Accept      json
+// @Produce     json
+// @Param       backfill body startDictionaryBackfillRequest false "Backfill options (limit defaults to 500)"
+// @Success     202 {object} Response "Dictionary backfill started"
+// @Failure     400 {object} Response "Invalid request parameters"
+// @Failure     409 {object} Response "A backfill is already running"
+// @Failure     503 {object} Response "Dictionary lookups are disabled"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/dictionary/backfill [post]
+func (server *Server) startDictionaryBackfill(ctx *gin.Context) {
+	if server.dictionary == nil {
+		ErrorResponse(ctx, http.StatusServiceUnavailable, "Dictionary lookups are disabled", nil)
+		return
+	}
+
+	var req startDictionaryBackfillRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ErrorResponse(ctx, http.StatusBadRequest, "Invalid request parameters", err)
+			return
+		}
+	}
+	if req.Limit == 0 {
+		req.Limit = 500
+	}
+
+	run, err := server.dictionary.StartBackfill(req.Limit)
+	if err != nil {
+		if errors.Is(err, dictionary.ErrBackfillRunning) {
+			ErrorResponse(ctx, http.StatusConflict, "A backfill is already running", err)
+			return
+		}
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to start dictionary backfill", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusAccepted, "Dictionary backfill started", run)
+}
+
+// @Summary     Get dictionary backfill status
+// @Description Returns the number of words missing dictionary data, lookup counts by status and the last backfill run (admin only)
+// @Tags        admin
+// @Produce     json
+// @Success     200 {object} Response{data=dictionary.Status} "Dictionary status retrieved successfully"
+// @Failure     503 {object} Response "Dictionary lookups are disabled"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/dictionary/backfill [get]
+func (server *Server) getDictionaryBackfill(ctx *gin.Context) {
+	if server.dictionary == nil {
+		ErrorResponse(ctx, http.StatusServiceUnavailable, "Dictionary lookups are disabled", nil)
+		return
+	}
+
+	status, err := server.dictionary.Status(ctx)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to get dictionary status", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Dictionary status retrieved successfully", status)
+}
+
+// @Summary     Look up a word in the dictionary
+// @Description Looks a single word up in the external dictionary right away and fills its empty fields (admin only)
+// @Tags        admin
+// @Produce     json
+// @Param       id path int true "Word ID"
+// @Success     200 {object} Response{data=WordResponse} "Word enriched successfully"
+// @Failure     404 {object} Response "Word not found"
+// @Failure     429 {object} Response "Dictionary rate limit exceeded"
+// @Failure     503 {object} Response "Dictionary lookups are disabled"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/dictionary/words/{id}/lookup [post]
+func (server *Server) lookupWordInDictionary(ctx *gin.Context) {
+	if server.dictionary == nil {
+		ErrorResponse(ctx, http.StatusServiceUnavailable, "Dictionary lookups are disabled", nil)
+		return
+	}
+
+	var req getWordRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid word ID", err)
+		return
+	}
+
+	word, err := server.store.GetWord(ctx, req.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ErrorResponse(ctx, http.StatusNotFound, "Word not found", err)
+			return
+		}
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to get word", err)
+		return
+	}
+
+	word, err = server.dictionary.EnrichWord(ctx, word)
+	if err != nil {
+		switch {
+		case errors.Is(err, dictionary.ErrNotFound):
+			ErrorResponse(ctx, http.StatusNotFound, "Word not found in dictionary", err)
+		case errors.Is(err, dictionary.ErrRateLimited):
+			ErrorResponse(ctx, http.StatusTooManyRequests, "Dictionary rate limit exceeded", err)
+		default:
+			ErrorResponse(ctx, http.StatusBadGateway, "Dictionary lookup failed", err)
+		}
+		return
+	}
+
+	// Clear cache for the enriched word
+	if server.config.CacheEnabled && server.serviceCache != nil {
+		cacheKey := server.serviceCache.GenerateKey("word", req.ID)
+		go func() {
+			bgCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			if err := server.serviceCache.Delete(bgCtx, cacheKey); err != nil {
+				logger.Warn("Failed to clear cache for enriched word %d: %v", req.ID, err)
+			}
+		}()
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Word enriched successfully", NewWordResponse(word))
+}
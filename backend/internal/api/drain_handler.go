@@ -0,0 +1,127 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/drain"
+	"github.com/toeic-app/internal/logger"
+	"github.com/toeic-app/internal/token"
+)
+
+// drainRetryAfter is the Retry-After, in seconds, sent with requests refused
+// while draining; by then the load balancer routes to another instance
+const drainRetryAfter = 30
+
+// untrackedRoutes are not counted as in flight: probes and drain progress
+// must keep answering, and WebSocket connections are closed by Shutdown
+var untrackedRoutes = map[string]bool{
+	"/health":                    true,
+	"/health/live":               true,
+	"/health/ready":              true,
+	"/api/v1/admin/system/drain": true,
+	"/api/v1/upgrade/ws":         true,
+}
+
+type startDrainRequest struct {
+	// Seconds in-flight requests and queued jobs get to finish; defaults to
+	// DRAIN_TIMEOUT
+	TimeoutSeconds int `json:"timeout_seconds" binding:"omitempty,min=1,max=3600" example:"120"`
+}
+
+// trackRequests counts the requests in flight for drain progress
+func (server *Server) trackRequests() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if untrackedRoutes[ctx.FullPath()] {
+			ctx.Next()
+			return
+		}
+		done := server.drain.Track()
+		defer done()
+		ctx.Next()
+	}
+}
+
+// refuseWhileDraining rejects new work once the instance is draining
+func (server *Server) refuseWhileDraining() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if server.drain.Draining() {
+			ctx.Header("Retry-After", strconv.Itoa(drainRetryAfter))
+			ErrorResponse(ctx, http.StatusServiceUnavailable, "Server is draining for maintenance, please retry shortly", nil)
+			ctx.Abort()
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// @Summary     Start draining the instance
+// @Description Prepares the instance for a deployment: new exam attempts, writing scoring, AI speaking responses and background jobs are refused with 503, and the readiness probe fails so the load balancer stops routing here. Requests in flight and queued background jobs get until the deadline to finish; poll GET /api/v1/admin/system/drain until safe_to_shutdown is set. Starting again while draining keeps the first deadline. Requires the system.manage permission. (admin only)
+// @Tags        system
+// @Accept      json
+// @Produce     json
+// @Param       drain body startDrainRequest false "Time work gets to finish"
+// @Success     202 {object} Response{data=drain.Status} "Draining started"
+// @Failure     400 {object} Response "Invalid request parameters"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     403 {object} Response "Permission denied"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/system/drain [post]
+func (server *Server) startDrain(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	var req startDrainRequest
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ErrorResponse(ctx, http.StatusBadRequest, "Invalid request parameters", err)
+			return
+		}
+	}
+	timeout := server.config.DrainTimeout
+	if req.TimeoutSeconds > 0 {
+		timeout = time.Duration(req.TimeoutSeconds) * time.Second
+	}
+
+	status := server.drain.Start(timeout, authPayload.Username)
+	logger.Warn("Draining started by %s, deadline %s", status.StartedBy, status.Deadline.Format(time.RFC3339))
+	SuccessResponse(ctx, http.StatusAccepted, "Draining started", status)
+}
+
+// @Summary     Get drain progress
+// @Description Returns the drain state (serving, draining or drained), the deadline, the requests in flight and the background jobs left. safe_to_shutdown is set once everything finished or the deadline passed; jobs still running then are cancelled by the shutdown. Requires the system.manage permission. (admin only)
+// @Tags        system
+// @Produce     json
+// @Success     200 {object} Response{data=drain.Status} "Drain status retrieved successfully"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     403 {object} Response "Permission denied"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/system/drain [get]
+func (server *Server) getDrainStatus(ctx *gin.Context) {
+	SuccessResponse(ctx, http.StatusOK, "Drain status retrieved successfully", server.drain.Status())
+}
+
+// @Summary     Stop draining
+// @Description Cancels a drain so the instance accepts exam attempts and AI work again, for a deployment that was called off. Requires the system.manage permission. (admin only)
+// @Tags        system
+// @Produce     json
+// @Success     200 {object} Response{data=drain.Status} "Draining stopped"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     403 {object} Response "Permission denied"
+// @Failure     409 {object} Response "Instance is not draining"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/system/drain [delete]
+func (server *Server) stopDrain(ctx *gin.Context) {
+	if err := server.drain.Resume(); err != nil {
+		if errors.Is(err, drain.ErrNotDraining) {
+			ErrorResponse(ctx, http.StatusConflict, "Instance is not draining", err)
+			return
+		}
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to stop draining", err)
+		return
+	}
+	logger.Info("Draining stopped, accepting new work again")
+	SuccessResponse(ctx, http.StatusOK, "Draining stopped", server.drain.Status())
+}
@@ -2,8 +2,12 @@ package api
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"net/http"
+	"slices"
 	"strconv"
 	"time"
 
@@ -11,12 +15,14 @@ import (
 	"github.com/toeic-app/internal/backup"
 	"github.com/toeic-app/internal/config"
 	"github.com/toeic-app/internal/logger"
+	"github.com/toeic-app/internal/scheduler"
+	"github.com/toeic-app/internal/token"
 )
 
 // Enhanced backup endpoints using the new backup manager
 
 // @Summary     Create enhanced database backup
-// @Description Creates a backup with advanced features like compression, encryption, and validation
+// @Description Creates a backup with advanced features like compression, encryption, and validation. The mode picks a full pg_dump snapshot (default), an incremental backup of the tables written since the last backup, or a differential backup of those written since the last full backup. Without a backup to build on, or after a schema migration, a full backup is created and a warning returned. With database one of the databases listed in BACKUP_DATABASES is backed up instead of the application database; its backups are kept apart and listed under its name in the backup status.
 // @Tags        admin
 // @Accept      json
 // @Produce     json
@@ -34,7 +40,10 @@ func (server *Server) createEnhancedBackup(ctx *gin.Context) {
 		return
 	}
 
-	logger.Info("Enhanced backup request: description=%s, type=%s", req.Description, req.Type)
+	if req.Mode == "" {
+		req.Mode = backup.ModeFull
+	}
+	logger.Info("Enhanced backup request: description=%s, type=%s, mode=%s", req.Description, req.Type, req.Mode)
 
 	// Load backup configuration
 	backupConfig := config.LoadBackupConfig()
@@ -45,14 +54,22 @@ func (server *Server) createEnhancedBackup(ctx *gin.Context) {
 	}
 
 	// Create backup manager
-	backupManager := backup.NewBackupManager(backupConfig, server.config)
+	backupManager := server.newBackupManager(backupConfig)
+	if req.Database != "" && req.Database != config.PrimaryDatabase {
+		manager, err := backup.NewDatabaseManager(backupConfig, server.config, req.Database)
+		if err != nil {
+			ErrorResponse(ctx, http.StatusBadRequest, "Unknown backup target", unknownBackupTarget(req.Database))
+			return
+		}
+		backupManager = manager
+	}
 
 	// Create context with timeout
 	backupCtx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
 	defer cancel()
 
 	// Create backup
-	result, err := backupManager.CreateBackup(backupCtx, req.Description, req.Type)
+	result, err := backupManager.CreateBackupWithMode(backupCtx, req.Description, req.Type, req.Mode)
 	if err != nil {
 		logger.Error("Enhanced backup creation failed: %v", err)
 		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to create enhanced backup", err)
@@ -71,6 +88,9 @@ func (server *Server) createEnhancedBackup(ctx *gin.Context) {
 		Checksum:    result.Metadata.Checksum,
 		Warnings:    result.Warnings,
 		DownloadURL: "/api/v1/admin/backups/download/" + result.Metadata.Filename,
+		Mode:        result.Metadata.BackupMode(),
+		Parent:      result.Metadata.Parent,
+		Tables:      result.Metadata.Tables,
 	}
 
 	logger.Info("Enhanced backup created successfully: %s", result.Metadata.Filename)
@@ -78,14 +98,16 @@ func (server *Server) createEnhancedBackup(ctx *gin.Context) {
 }
 
 // @Summary     Restore database from enhanced backup
-// @Description Restores database with advanced validation and safety measures
+// @Description Restores database with advanced validation and safety measures. An incremental or differential backup is replayed on top of the backups it builds on, starting with the full backup; the response lists the chain. With dry_run the backup is only read and compared with the live database: the preview lists the tables the restore creates, live tables the backup lacks, changed columns and the destructive operations (replaced rows, dropped columns, changed types, schema downgrade) to review before restoring for real. With tables only those tables are restored: the backup is restored into a scratch database and the rows of the tables are replaced in one transaction, referenced tables first, leaving the rest of the database as it is; the restore is rolled back when a foreign key between the restored tables and the others would break. Its progress is pushed on the admin.restores WebSocket topic and can be polled at /admin/backups/restores/{id}; with async the restore is answered at once with 202 to follow it there.
 // @Tags        admin
 // @Accept      json
 // @Produce     json
 // @Param       restore body enhancedRestoreRequest true "Enhanced restore details"
 // @Success     200 {object} Response{data=enhancedRestoreResponse} "Database restored successfully"
+// @Success     202 {object} Response{data=backup.Restore} "Restore started"
 // @Failure     400 {object} Response "Invalid request parameters"
 // @Failure     404 {object} Response "Backup file not found"
+// @Failure     409 {object} Response "Another restore is running"
 // @Failure     500 {object} Response "Failed to restore database"
 // @Security    ApiKeyAuth
 // @Router      /api/v1/admin/backups/enhanced/restore [post]
@@ -108,14 +130,74 @@ func (server *Server) restoreEnhancedBackup(ctx *gin.Context) {
 	}
 
 	// Create backup manager
-	backupManager := backup.NewBackupManager(backupConfig, server.config)
+	backupManager := server.newBackupManager(backupConfig)
 
-	// Create context with timeout
-	restoreCtx, cancel := context.WithTimeout(context.Background(), 60*time.Minute)
-	defer cancel()
+	if req.DryRun {
+		previewCtx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+		defer cancel()
+
+		preview, err := backupManager.PreviewRestore(previewCtx, req.Filename)
+		if err != nil {
+			logger.Error("Restore preview failed: %v", err)
+			ErrorResponse(ctx, http.StatusInternalServerError, "Failed to preview restore", err)
+			return
+		}
+		SuccessResponse(ctx, http.StatusOK, "Restore preview completed, nothing was restored", enhancedRestoreResponse{
+			Success:  true,
+			Duration: preview.Duration.String(),
+			Warnings: preview.Warnings,
+			Chain:    preview.Chain,
+			DryRun:   true,
+			Preview:  preview,
+		})
+		return
+	}
+
+	// Refuse writes until the restore finished
+	releaseReadOnly, ok := server.enterReadOnly(ctx, "backup restore")
+	if !ok {
+		return
+	}
+
+	// Followed on the restore topic and at /backups/restores/:id
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+	restore, restoreCtx, err := server.backupRestores.Start(context.Background(), req.Filename, req.Tables, authPayload.Username)
+	if err != nil {
+		releaseReadOnly()
+		if errors.Is(err, backup.ErrRestoreRunning) {
+			ErrorResponse(ctx, http.StatusConflict, "Another restore is running",
+				fmt.Errorf("restore %s is running", restore.ID))
+			return
+		}
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to start restore", err)
+		return
+	}
+	run := func() (*backup.RestoreResult, error) {
+		defer releaseReadOnly()
+		restoreCtx, cancel := context.WithTimeout(restoreCtx, 60*time.Minute)
+		defer cancel()
+		result, err := backupManager.RestoreBackup(restoreCtx, req.Filename, backup.RestoreOptions{Tables: req.Tables})
+		server.backupRestores.Finish(restore.ID, result, err)
+		return result, err
+	}
+
+	if req.Async {
+		go func() {
+			if _, err := run(); err != nil {
+				logger.Error("Restore %s of %s failed: %v", restore.ID, req.Filename, err)
+			}
+		}()
+		ctx.Header("Location", "/api/v1/admin/backups/restores/"+restore.ID)
+		SuccessResponse(ctx, http.StatusAccepted, "Restore started", restore)
+		return
+	}
 
 	// Restore backup
-	result, err := backupManager.RestoreBackup(restoreCtx, req.Filename)
+	result, err := run()
+	if errors.Is(err, backup.ErrUnknownTable) {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid tables to restore", err)
+		return
+	}
 	if err != nil {
 		logger.Error("Enhanced restore failed: %v", err)
 		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to restore from enhanced backup", err)
@@ -124,11 +206,14 @@ func (server *Server) restoreEnhancedBackup(ctx *gin.Context) {
 
 	// Prepare response
 	response := enhancedRestoreResponse{
+		RestoreID:    restore.ID,
 		Success:      result.Success,
 		Duration:     result.Duration.String(),
 		TablesCount:  result.TablesCount,
 		RecordsCount: result.RecordsCount,
 		Warnings:     result.Warnings,
+		Chain:        result.Chain,
+		Tables:       result.Tables,
 	}
 
 	logger.Info("Enhanced restore completed successfully from: %s", req.Filename)
@@ -136,7 +221,7 @@ func (server *Server) restoreEnhancedBackup(ctx *gin.Context) {
 }
 
 // @Summary     Get backup status and health
-// @Description Returns backup system status, recent activity, and health metrics
+// @Description Returns backup system status, recent activity, and health metrics. Totals and activity come from the backup catalog; cataloged backups missing from storage, and backups that failed the periodic check against their manifest (BACKUP_REVERIFY_INTERVAL), are reported as health issues. Each database listed in BACKUP_DATABASES is reported under databases with its own totals, next scheduled backup, retention and health; its issues count towards the overall health.
 // @Tags        admin
 // @Accept      json
 // @Produce     json
@@ -151,7 +236,7 @@ func (server *Server) getBackupStatus(ctx *gin.Context) {
 	backupConfig := config.LoadBackupConfig()
 
 	// Get backup statistics
-	stats, err := server.getBackupStatistics()
+	stats, err := server.getBackupStatistics(ctx)
 	if err != nil {
 		logger.Error("Failed to get backup statistics: %v", err)
 		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve backup status", err)
@@ -159,14 +244,27 @@ func (server *Server) getBackupStatus(ctx *gin.Context) {
 	}
 
 	// Check backup system health
-	health := server.checkBackupHealth(backupConfig)
+	health := server.checkBackupHealth(ctx, backupConfig)
 
 	// Get recent backup activity
-	recentActivity, err := server.getRecentBackupActivity(5) // Last 5 backups
+	recentActivity, err := server.getRecentBackupActivity(ctx, 5) // Last 5 backups
 	if err != nil {
 		logger.Warn("Failed to get recent backup activity: %v", err)
 	}
 
+	var databases []scheduler.DatabaseStatus
+	if server.enhancedBackupScheduler != nil {
+		databases = server.enhancedBackupScheduler.DatabaseStatuses(ctx)
+	}
+	for _, database := range databases {
+		for _, issue := range database.Issues {
+			health.Issues = append(health.Issues, fmt.Sprintf("Database %s: %s", database.Name, issue))
+		}
+		if database.Health == "critical" || (database.Health == "warning" && health.Overall == "healthy") {
+			health.Overall = database.Health
+		}
+	}
+
 	response := backupStatusResponse{
 		Enabled:        backupConfig.Enabled,
 		AutoBackup:     backupConfig.AutoBackupEnabled,
@@ -178,20 +276,21 @@ func (server *Server) getBackupStatus(ctx *gin.Context) {
 		Health:         health,
 		RecentActivity: recentActivity,
 		Configuration: backupConfigSummary{
-			RetentionDays:   backupConfig.RetentionDays,
+			Retention:       backup.NewRetentionPolicy(backupConfig),
 			MaxBackupCount:  backupConfig.MaxBackupCount,
 			CompressBackups: backupConfig.CompressBackups,
 			EncryptBackups:  backupConfig.EncryptBackups,
 			ValidateBackups: backupConfig.ValidateAfterBackup,
 			StorageType:     backupConfig.StorageType,
 		},
+		Databases: databases,
 	}
 
 	SuccessResponse(ctx, http.StatusOK, "Backup status retrieved successfully", response)
 }
 
 // @Summary     Validate backup file
-// @Description Validates the integrity and structure of a backup file
+// @Description Validates that a backup can be restored: its files are checked against the signed SHA-256 manifest written with it, encrypted backups are decrypted and compressed ones decompressed, the SQL is checked and its checksum compared with the one recorded at creation. Backups in remote storage are downloaded first.
 // @Tags        admin
 // @Accept      json
 // @Produce     json
@@ -201,7 +300,7 @@ func (server *Server) getBackupStatus(ctx *gin.Context) {
 // @Failure     404 {object} Response "Backup file not found"
 // @Failure     500 {object} Response "Validation failed"
 // @Security    ApiKeyAuth
-// @Router      /api/v1/admin/backups/{filename}/validate [post]
+// @Router      /api/v1/admin/backups/validate/{filename} [post]
 func (server *Server) validateBackupFile(ctx *gin.Context) {
 	filename := ctx.Param("filename")
 	if filename == "" {
@@ -213,10 +312,19 @@ func (server *Server) validateBackupFile(ctx *gin.Context) {
 
 	// Load backup configuration
 	backupConfig := config.LoadBackupConfig()
-	backupManager := backup.NewBackupManager(backupConfig, server.config)
+	backupManager := server.newBackupManager(backupConfig)
 
 	// Perform validation
-	validationResult := server.performBackupValidation(backupManager, filename)
+	validationResult, err := backupManager.ValidateBackup(ctx.Request.Context(), filename)
+	if errors.Is(err, backup.ErrBackupNotFound) {
+		ErrorResponse(ctx, http.StatusNotFound, "Backup file not found", err)
+		return
+	}
+	if err != nil {
+		logger.Error("Backup validation of %s failed: %v", filename, err)
+		ErrorResponse(ctx, http.StatusInternalServerError, "Validation failed", err)
+		return
+	}
 
 	response := backupValidationResponse{
 		Filename:       filename,
@@ -225,6 +333,10 @@ func (server *Server) validateBackupFile(ctx *gin.Context) {
 		ReadableFile:   validationResult.ReadableFile,
 		ValidStructure: validationResult.ValidStructure,
 		ChecksumMatch:  validationResult.ChecksumMatch,
+		ManifestMatch:  validationResult.ManifestMatch,
+		ManifestSigned: validationResult.ManifestSigned,
+		Encrypted:      validationResult.Encrypted,
+		KeyID:          validationResult.KeyID,
 		Size:           validationResult.Size,
 		LastModified:   validationResult.LastModified,
 		Issues:         validationResult.Issues,
@@ -237,11 +349,54 @@ func (server *Server) validateBackupFile(ctx *gin.Context) {
 	}
 }
 
+// @Summary     Get backup media assets
+// @Description Returns the asset manifest of a full backup: the Cloudinary audio and images the database referenced when it was taken, the columns referencing each and, when BACKUP_ASSETS_DOWNLOAD is set, the file each was downloaded to in the media archive kept alongside the backup with its size and SHA-256, or why it could not be downloaded.
+// @Tags        admin
+// @Produce     json
+// @Param       filename path string true "Backup filename"
+// @Success     200 {object} Response{data=backup.AssetManifest} "Backup media assets retrieved successfully"
+// @Failure     400 {object} Response "Filename is required"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     403 {object} Response "Permission denied"
+// @Failure     404 {object} Response "Backup or asset manifest not found"
+// @Failure     500 {object} Response "Failed to get backup media assets"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/backups/assets/{filename} [get]
+func (server *Server) getBackupAssets(ctx *gin.Context) {
+	filename := ctx.Param("filename")
+	if filename == "" {
+		ErrorResponse(ctx, http.StatusBadRequest, "Filename is required", nil)
+		return
+	}
+
+	backupManager := server.newBackupManager(config.LoadBackupConfig())
+	manifest, err := backupManager.Assets(ctx.Request.Context(), filename)
+	switch {
+	case errors.Is(err, backup.ErrBackupNotFound):
+		ErrorResponse(ctx, http.StatusNotFound, "Backup file not found", err)
+		return
+	case errors.Is(err, backup.ErrAssetsNotFound):
+		ErrorResponse(ctx, http.StatusNotFound, "Backup has no media assets", err)
+		return
+	case err != nil:
+		logger.Error("Failed to read the media assets of %s: %v", filename, err)
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to get backup media assets", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Backup media assets retrieved successfully", manifest)
+}
+
 // Helper types for enhanced backup API
 
 type enhancedBackupRequest struct {
 	Description string `json:"description" binding:"required"`
 	Type        string `json:"type" binding:"required"` // manual, automatic, migration, etc.
+	// Database is one of BACKUP_DATABASES; empty or primary backs up the
+	// application database
+	Database string `json:"database,omitempty" example:"analytics"`
+	// Mode is full (the default), incremental or differential
+	Mode string `json:"mode" binding:"omitempty,oneof=full incremental differential" example:"incremental"`
 }
 
 type enhancedBackupResponse struct {
@@ -255,18 +410,36 @@ type enhancedBackupResponse struct {
 	Checksum    string   `json:"checksum"`
 	Warnings    []string `json:"warnings,omitempty"`
 	DownloadURL string   `json:"download_url"`
+	Mode        string   `json:"mode"`
+	Parent      string   `json:"parent,omitempty"` // Backup an incremental or differential backup builds on
+	Tables      []string `json:"tables,omitempty"` // Tables an incremental or differential backup holds
 }
 
 type enhancedRestoreRequest struct {
-	Filename string `json:"filename" binding:"required"`
+	Filename string `json:"filename" binding:"required,safe_filename"`
+	// DryRun compares the backup with the live database and reports the
+	// differences without restoring it
+	DryRun bool `json:"dry_run" example:"true"`
+	// Tables restores only these tables, as name or schema.name, and
+	// leaves the rest of the database as it is
+	Tables []string `json:"tables" binding:"omitempty,max=100,dive,required,max=128" example:"users,words"`
+	// Async answers at once with the restore to follow instead of waiting
+	// for it to finish
+	Async bool `json:"async" example:"true"`
 }
 
 type enhancedRestoreResponse struct {
+	RestoreID    string   `json:"restore_id,omitempty"`
 	Success      bool     `json:"success"`
 	Duration     string   `json:"duration"`
 	TablesCount  int      `json:"tables_count"`
 	RecordsCount int64    `json:"records_count"`
 	Warnings     []string `json:"warnings,omitempty"`
+	Chain        []string `json:"chain,omitempty"`  // Backups replayed, full backup first
+	Tables       []string `json:"tables,omitempty"` // Tables restored by a selective restore
+	DryRun       bool     `json:"dry_run,omitempty"`
+	// Preview of a dry run: schema differences and destructive operations
+	Preview *backup.RestorePreview `json:"preview,omitempty"`
 }
 
 type backupStatusResponse struct {
@@ -280,6 +453,9 @@ type backupStatusResponse struct {
 	Health         backupHealthStatus   `json:"health"`
 	RecentActivity []backupActivityItem `json:"recent_activity"`
 	Configuration  backupConfigSummary  `json:"configuration"`
+	// Databases are the databases backed up besides the application
+	// database, whose status the fields above report
+	Databases []scheduler.DatabaseStatus `json:"databases,omitempty"`
 }
 
 type backupHealthStatus struct {
@@ -299,12 +475,12 @@ type backupActivityItem struct {
 }
 
 type backupConfigSummary struct {
-	RetentionDays   int    `json:"retention_days"`
-	MaxBackupCount  int    `json:"max_backup_count"`
-	CompressBackups bool   `json:"compress_backups"`
-	EncryptBackups  bool   `json:"encrypt_backups"`
-	ValidateBackups bool   `json:"validate_backups"`
-	StorageType     string `json:"storage_type"`
+	Retention       backup.RetentionPolicy `json:"retention"`
+	MaxBackupCount  int                    `json:"max_backup_count"`
+	CompressBackups bool                   `json:"compress_backups"`
+	EncryptBackups  bool                   `json:"encrypt_backups"`
+	ValidateBackups bool                   `json:"validate_backups"`
+	StorageType     string                 `json:"storage_type"`
 }
 
 type backupValidationResponse struct {
@@ -314,6 +490,10 @@ type backupValidationResponse struct {
 	ReadableFile   bool      `json:"readable_file"`
 	ValidStructure bool      `json:"valid_structure"`
 	ChecksumMatch  bool      `json:"checksum_match"`
+	ManifestMatch  bool      `json:"manifest_match"`  // The stored files match the manifest written with the backup
+	ManifestSigned bool      `json:"manifest_signed"` // The manifest signature was verified with BACKUP_MANIFEST_KEY
+	Encrypted      bool      `json:"encrypted"`
+	KeyID          string    `json:"key_id,omitempty"` // Master key wrapping the data key of an encrypted backup
 	Size           int64     `json:"size"`
 	LastModified   time.Time `json:"last_modified"`
 	Issues         []string  `json:"issues,omitempty"`
@@ -321,12 +501,20 @@ type backupValidationResponse struct {
 }
 
 type scheduleInfo struct {
-	ID          string `json:"id"`
-	Description string `json:"description"`
-	Schedule    string `json:"schedule"`
-	LastRun     string `json:"last_run"`
-	NextRun     string `json:"next_run"`
-	Enabled     bool   `json:"enabled"`
+	ID          string          `json:"id"`
+	Description string          `json:"description"`
+	Schedule    string          `json:"schedule"`
+	LastRun     string          `json:"last_run"`
+	NextRun     string          `json:"next_run"`
+	Enabled     bool            `json:"enabled"`
+	IOLimit     *backup.IOLimit `json:"io_limit,omitempty"` // Overrides the configured IO throttling
+	Database    string          `json:"database,omitempty"` // Empty is the application database
+}
+
+// unknownBackupTarget is the error of a database not configured to be
+// backed up, worded to be answered as a bad request
+func unknownBackupTarget(name string) error {
+	return fmt.Errorf("%q is not one of BACKUP_DATABASES", name)
 }
 
 // Helper method implementations would go here...
@@ -340,75 +528,142 @@ type backupStatistics struct {
 	OldestBackupTime *time.Time
 }
 
-type validationResult struct {
-	Valid          bool
-	FileExists     bool
-	ReadableFile   bool
-	ValidStructure bool
-	ChecksumMatch  bool
-	Size           int64
-	LastModified   time.Time
-	Issues         []string
-	Warnings       []string
-}
-
-// getBackupStatistics retrieves backup statistics
-func (server *Server) getBackupStatistics() (*backupStatistics, error) {
-	// Placeholder implementation - would scan backup directory and collect stats
+// getBackupStatistics retrieves backup statistics from the completed
+// backups of the catalog
+func (server *Server) getBackupStatistics(ctx context.Context) (*backupStatistics, error) {
 	stats := &backupStatistics{
 		TotalBackups: 0,
 		TotalSize:    0,
 	}
+	if server.backupCatalog == nil {
+		return stats, nil
+	}
 
-	// In a real implementation, this would scan the backup directory
-	// and collect actual statistics
-
+	// Entries are newest first
+	entries, err := server.backupCatalog.Entries(ctx, backup.StatusCompleted)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		stats.TotalBackups++
+		stats.TotalSize += entry.Size
+	}
+	if len(entries) > 0 {
+		last, oldest := entries[0].CreatedAt, entries[len(entries)-1].CreatedAt
+		stats.LastBackupTime, stats.OldestBackupTime = &last, &oldest
+	}
 	return stats, nil
 }
 
 // checkBackupHealth checks the health of the backup system
-func (server *Server) checkBackupHealth(_ config.BackupConfig) backupHealthStatus {
+func (server *Server) checkBackupHealth(ctx context.Context, _ config.BackupConfig) backupHealthStatus {
 	health := backupHealthStatus{
 		Overall:   "healthy",
 		LastCheck: time.Now(),
 		Issues:    []string{},
 	}
-	// Check if backup tools are available
-	// In a real implementation, this would check database connectivity
-	// For now, assume healthy
 
-	// Add more health checks as needed
+	// Backups that failed their last verification against their manifest,
+	// on the leader
+	if server.backupMonitor != nil {
+		if verification := server.backupMonitor.Verification(); verification != nil {
+			for _, name := range slices.Sorted(maps.Keys(verification.Corrupted)) {
+				health.Overall = "critical"
+				health.Issues = append(health.Issues, fmt.Sprintf("Backup %s failed verification: %s", name, verification.Corrupted[name]))
+			}
+		}
+	}
+	if server.backupCatalog == nil {
+		return health
+	}
 
+	// Backups the catalog lost track of
+	missing, err := server.backupCatalog.Entries(ctx, backup.StatusMissing)
+	if err != nil {
+		if health.Overall == "healthy" {
+			health.Overall = "warning"
+		}
+		health.Issues = append(health.Issues, fmt.Sprintf("Backup catalog unavailable: %v", err))
+		return health
+	}
+	if len(missing) > 0 {
+		if health.Overall == "healthy" {
+			health.Overall = "warning"
+		}
+		health.Issues = append(health.Issues, fmt.Sprintf("%d cataloged backups are missing from storage", len(missing)))
+	}
 	return health
 }
 
-// getRecentBackupActivity retrieves recent backup activity
-func (server *Server) getRecentBackupActivity(_ int) ([]backupActivityItem, error) {
-	// Placeholder implementation - would read from activity log
-	activity := []backupActivityItem{}
+// backupActivityStatuses maps catalog statuses to activity statuses
+var backupActivityStatuses = map[string]string{
+	backup.StatusCompleted: "success",
+	backup.StatusDeleted:   "success",
+	backup.StatusFailed:    "failure",
+	backup.StatusMissing:   "warning",
+}
 
-	// In a real implementation, this would read from an activity log
-	// or scan backup files for recent activity
+// getRecentBackupActivity retrieves the newest backups of the catalog
+func (server *Server) getRecentBackupActivity(ctx context.Context, limit int) ([]backupActivityItem, error) {
+	activity := []backupActivityItem{}
+	if server.backupCatalog == nil {
+		return activity, nil
+	}
 
+	entries, err := server.backupCatalog.Recent(ctx, limit)
+	if err != nil {
+		return activity, err
+	}
+	for _, entry := range entries {
+		item := backupActivityItem{
+			Timestamp:   entry.CreatedAt,
+			Action:      "backup",
+			Filename:    entry.Filename,
+			Status:      backupActivityStatuses[entry.Status],
+			Size:        entry.Size,
+			Description: entry.Description,
+		}
+		if entry.Duration > 0 {
+			item.Duration = entry.Duration.String()
+		}
+		if entry.Error != "" {
+			item.Description = entry.Error
+		}
+		activity = append(activity, item)
+	}
 	return activity, nil
 }
 
-// performBackupValidation validates a backup file
-func (server *Server) performBackupValidation(_ *backup.BackupManager, _ string) *validationResult {
-	result := &validationResult{
-		Valid:          true,
-		FileExists:     true,
-		ReadableFile:   true,
-		ValidStructure: true,
-		ChecksumMatch:  true,
-		Issues:         []string{},
-		Warnings:       []string{},
-	}
+// reconcileBackupsRequest reconciles the backup catalog with the storage
+type reconcileBackupsRequest struct {
+	DryRun bool `json:"dry_run"` // Only report the differences
+}
 
-	// In a real implementation, this would perform comprehensive validation
-	// For now, just return a successful validation
+// @Summary     Reconcile the backup catalog
+// @Description Compares the backup catalog with the backup storage. Backups found in storage but not cataloged (made by the backup-admin tool, copied in by hand, or made after the backup the database was restored from) are added from their metadata; cataloged backups gone from storage are marked missing. With dry_run the differences are only reported.
+// @Tags        admin
+// @Accept      json
+// @Produce     json
+// @Param       request body reconcileBackupsRequest false "Reconcile options"
+// @Success     200 {object} Response{data=backup.Reconciliation} "Backup catalog reconciled"
+// @Failure     400 {object} Response "Invalid request body"
+// @Failure     500 {object} Response "Failed to reconcile backup catalog"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/backups/reconcile [post]
+func (server *Server) reconcileBackups(ctx *gin.Context) {
+	var req reconcileBackupsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
 
-	return result
+	report, err := server.backupManager.ReconcileCatalog(ctx, req.DryRun)
+	if err != nil {
+		logger.Error("Failed to reconcile backup catalog: %v", err)
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to reconcile backup catalog", err)
+		return
+	}
+	SuccessResponse(ctx, http.StatusOK, "Backup catalog reconciled", report)
 }
 
 // @Summary     Get backup schedules
@@ -436,6 +691,8 @@ func (server *Server) getBackupSchedules(ctx *gin.Context) {
 			LastRun:     "N/A",                                                  // lastRun is private, would need a getter method
 			NextRun:     time.Now().Add(schedule.Interval).Format(time.RFC3339), // Estimate next run
 			Enabled:     schedule.Enabled,
+			IOLimit:     schedule.IOLimit,
+			Database:    schedule.Database,
 		})
 	}
 
@@ -443,7 +700,7 @@ func (server *Server) getBackupSchedules(ctx *gin.Context) {
 }
 
 // @Summary     Add backup schedule
-// @Description Add a new backup schedule
+// @Description Add a new backup schedule, of the application database or, with database, of one of the databases listed in BACKUP_DATABASES
 // @Tags        admin
 // @Accept      json
 // @Produce     json
@@ -465,7 +722,11 @@ func (server *Server) addBackupSchedule(ctx *gin.Context) {
 		return
 	}
 
-	err := server.enhancedBackupScheduler.AddSchedule(req.ID, req.Schedule, req.Description, req.BackupType)
+	err := server.enhancedBackupScheduler.AddSchedule(req.ID, req.Schedule, req.Description, req.BackupType, req.Database, req.IOLimit)
+	if errors.Is(err, backup.ErrUnknownDatabase) {
+		ErrorResponse(ctx, http.StatusBadRequest, "Unknown backup target", unknownBackupTarget(req.Database))
+		return
+	}
 	if err != nil {
 		logger.Error("Failed to add backup schedule: %v", err)
 		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to add schedule", err)
@@ -505,15 +766,22 @@ func (server *Server) removeBackupSchedule(ctx *gin.Context) {
 }
 
 // @Summary     Get backup history
-// @Description Get backup history and statistics
+// @Description Get backup history and statistics. With format=csv or format=xlsx the history is downloaded as a file.
 // @Tags        admin
-// @Produce     json
+// @Produce     json,text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
 // @Param       limit query int false "Limit number of results" default(50)
+// @Param       format query string false "Download the history as a file" Enums(csv, xlsx)
 // @Success     200 {object} Response{data=backupHistoryResponse} "Backup history retrieved successfully"
+// @Failure     400 {object} Response "Unsupported export format"
 // @Failure     500 {object} Response "Failed to get backup history"
 // @Security    ApiKeyAuth
 // @Router      /api/v1/admin/backups/history [get]
 func (server *Server) getBackupHistory(ctx *gin.Context) {
+	format, ok := exportFormat(ctx)
+	if !ok {
+		return
+	}
+
 	limit := 50
 	if limitStr := ctx.Query("limit"); limitStr != "" {
 		if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 {
@@ -527,6 +795,29 @@ func (server *Server) getBackupHistory(ctx *gin.Context) {
 	}
 	history := server.enhancedBackupScheduler.GetBackupHistory(limit)
 
+	if format != "" {
+		header := []string{"timestamp", "schedule", "type", "filename", "success", "duration_seconds", "size", "error", "database"}
+		streamExport(ctx, format, "backup-history", header, func(write func([]string) error) error {
+			for _, item := range history {
+				if err := write([]string{
+					item.Timestamp.UTC().Format(time.RFC3339),
+					item.ScheduleName,
+					item.BackupType,
+					item.Filename,
+					strconv.FormatBool(item.Success),
+					strconv.FormatFloat(item.Duration.Seconds(), 'f', 3, 64),
+					strconv.FormatInt(item.Size, 10),
+					item.Error,
+					item.Database,
+				}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		return
+	}
+
 	// Convert scheduler.BackupHistoryItem to backupHistoryItem
 	var convertedHistory []backupHistoryItem
 	for _, item := range history {
@@ -539,6 +830,7 @@ func (server *Server) getBackupHistory(ctx *gin.Context) {
 			Size:        item.Size,
 			Description: fmt.Sprintf("Schedule: %s, Type: %s", item.ScheduleName, item.BackupType),
 			Error:       item.Error,
+			Database:    item.Database,
 		})
 	}
 
@@ -564,6 +856,7 @@ type backupHistoryItem struct {
 	Size        int64     `json:"size"`
 	Description string    `json:"description"`
 	Error       string    `json:"error,omitempty"`
+	Database    string    `json:"database,omitempty"` // Empty is the application database
 }
 
 type addScheduleRequest struct {
@@ -571,42 +864,92 @@ type addScheduleRequest struct {
 	Schedule    string `json:"schedule" binding:"required"`
 	Description string `json:"description" binding:"required"`
 	BackupType  string `json:"backup_type"`
+	// IOLimit overrides the configured IO throttling for the backups of
+	// the schedule, e.g. unlimited for a night schedule
+	IOLimit *backup.IOLimit `json:"io_limit,omitempty"`
+	// Database is one of BACKUP_DATABASES; empty backs up the application
+	// database
+	Database string `json:"database,omitempty" example:"analytics"`
 }
 
+// cleanupRequest removes old backups. Without max_age the backups the
+// grandfather-father-son retention policy does not keep are removed; daily,
+// weekly and monthly override the configured policy.
 type cleanupRequest struct {
-	MaxAge string `json:"max_age"` // Duration string like "720h" for 30 days
+	MaxAge  string `json:"max_age" example:"720h"` // Remove every backup older than this instead
+	Daily   *int   `json:"daily,omitempty" binding:"omitempty,min=0" example:"7"`
+	Weekly  *int   `json:"weekly,omitempty" binding:"omitempty,min=0" example:"4"`
+	Monthly *int   `json:"monthly,omitempty" binding:"omitempty,min=0" example:"12"`
+	DryRun  bool   `json:"dry_run"` // Only report what the retention policy would remove
+}
+
+// cleanupResponse is the outcome of a retention cleanup
+type cleanupResponse struct {
+	backup.RetentionPlan
+	Deleted int  `json:"deleted"`
+	DryRun  bool `json:"dry_run"`
 }
 
 // @Summary     Manual cleanup of old backups
-// @Description Manually trigger cleanup of old backup files
+// @Description Removes the backups the grandfather-father-son retention policy does not keep: the newest backup of each of the last BACKUP_KEEP_DAILY days, BACKUP_KEEP_WEEKLY weeks and BACKUP_KEEP_MONTHLY months that have a backup is kept, along with the backups kept incremental and differential backups build on. daily, weekly and monthly override the policy, dry_run only reports the plan. With max_age every backup older than it is removed instead.
 // @Tags        admin
 // @Accept      json
 // @Produce     json
 // @Param       cleanup body cleanupRequest false "Cleanup parameters"
-// @Success     200 {object} Response "Cleanup completed successfully"
+// @Success     200 {object} Response{data=cleanupResponse} "Cleanup completed successfully"
+// @Failure     400 {object} Response "Invalid cleanup parameters"
 // @Failure     500 {object} Response "Failed to cleanup backups"
 // @Security    ApiKeyAuth
 // @Router      /api/v1/admin/backups/cleanup [post]
 func (server *Server) cleanupOldBackupsHandler(ctx *gin.Context) {
 	var req cleanupRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		// Use default values if no request body
-		req.MaxAge = "720h" // 30 days default
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
 	}
 
-	maxAge, err := time.ParseDuration(req.MaxAge)
-	if err != nil {
-		ErrorResponse(ctx, http.StatusBadRequest, "Invalid max_age duration format", err)
+	if req.MaxAge != "" {
+		maxAge, err := time.ParseDuration(req.MaxAge)
+		if err != nil {
+			ErrorResponse(ctx, http.StatusBadRequest, "Invalid max_age duration format", err)
+			return
+		}
+
+		if err := server.CleanupOldBackups(maxAge); err != nil {
+			logger.Error("Failed to cleanup old backups: %v", err)
+			ErrorResponse(ctx, http.StatusInternalServerError, "Failed to cleanup backups", err)
+			return
+		}
+		logger.Info("Manual backup cleanup completed successfully")
+		SuccessResponse(ctx, http.StatusOK, "Cleanup completed successfully", nil)
 		return
 	}
 
-	// Use the existing CleanupOldBackups method
-	err = server.CleanupOldBackups(maxAge)
+	policy := server.backupManager.RetentionPolicy()
+	for _, override := range []struct {
+		value  *int
+		target *int
+	}{{req.Daily, &policy.Daily}, {req.Weekly, &policy.Weekly}, {req.Monthly, &policy.Monthly}} {
+		if override.value != nil {
+			*override.target = *override.value
+		}
+	}
+
+	plan, err := server.backupManager.PlanRetention(ctx, policy)
+	if errors.Is(err, backup.ErrInvalidRetention) {
+		ErrorResponse(ctx, http.StatusBadRequest, err.Error(), err)
+		return
+	}
 	if err != nil {
-		logger.Error("Failed to cleanup old backups: %v", err)
+		logger.Error("Failed to plan backup retention: %v", err)
 		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to cleanup backups", err)
 		return
 	}
-	logger.Info("Manual backup cleanup completed successfully")
-	SuccessResponse(ctx, http.StatusOK, "Cleanup completed successfully", nil)
+
+	response := cleanupResponse{RetentionPlan: *plan, DryRun: req.DryRun}
+	if !req.DryRun {
+		response.Deleted = server.backupManager.ApplyRetention(ctx, plan)
+		logger.Info("Manual backup cleanup kept %d backups and removed %d (keeping %s)", len(plan.Keep), response.Deleted, policy)
+	}
+	SuccessResponse(ctx, http.StatusOK, "Cleanup completed successfully", response)
 }
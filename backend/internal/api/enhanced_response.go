@@ -8,6 +8,7 @@ import (
 	"github.com/toeic-app/internal/errors"
 	"github.com/toeic-app/internal/i18n"
 	"github.com/toeic-app/internal/logger"
+	"github.com/toeic-app/internal/validation"
 )
 
 // Enhanced response structure with better error handling
@@ -20,14 +21,18 @@ type EnhancedResponse struct {
 	Language  string                 `json:"language,omitempty"`
 	Timestamp string                 `json:"timestamp"`
 	TraceID   string                 `json:"trace_id,omitempty"`
+	Meta      *Meta                  `json:"meta,omitempty"`
 }
 
-// ErrorDetails provides detailed error information
+// ErrorDetails provides detailed error information. GET /api/v1/errors
+// lists the codes.
 type ErrorDetails struct {
-	Code     string                 `json:"code"`
-	Message  string                 `json:"message"`
-	Details  string                 `json:"details,omitempty"`
-	Fields   map[string]string      `json:"fields,omitempty"`
+	Code    errors.ErrorCode `json:"code" example:"VALIDATION_FAILED"`
+	Message string           `json:"message" example:"Invalid request parameters"`
+	Details string           `json:"details,omitempty" example:"Key: 'createUserRequest.Email' Error:Field validation for 'Email' failed on the 'email' tag"`
+	// Fields maps each invalid field to what is wrong with it
+	Fields map[string]string `json:"fields,omitempty" example:"email:must be a valid email address"`
+	// Metadata is only returned in debug mode
 	Metadata map[string]interface{} `json:"metadata,omitempty"`
 }
 
@@ -43,6 +48,7 @@ func EnhancedSuccessResponse(c *gin.Context, statusCode int, messageKey string,
 		Language:  string(lang),
 		Timestamp: time.Now().UTC().Format(time.RFC3339),
 		TraceID:   c.GetHeader("X-Trace-ID"),
+		Meta:      responseMeta(c),
 	}
 
 	logSuccessResponse(c, statusCode, translatedMessage, messageKey, data != nil)
@@ -59,7 +65,7 @@ func EnhancedErrorResponse(c *gin.Context, appErr *errors.AppError) {
 	statusCode := appErr.Code.GetHTTPStatus()
 
 	errorDetails := &ErrorDetails{
-		Code:    string(appErr.Code),
+		Code:    appErr.Code,
 		Message: appErr.Message,
 		Details: appErr.Details,
 	}
@@ -75,6 +81,7 @@ func EnhancedErrorResponse(c *gin.Context, appErr *errors.AppError) {
 		Language:  string(lang),
 		Timestamp: appErr.Timestamp.Format(time.RFC3339),
 		TraceID:   appErr.TraceID,
+		Meta:      responseMeta(c),
 	}
 
 	logErrorResponse(c, appErr, statusCode)
@@ -93,7 +100,7 @@ func EnhancedValidationErrorResponse(c *gin.Context, validationErr *errors.Valid
 	statusCode := validationErr.Code.GetHTTPStatus()
 
 	errorDetails := &ErrorDetails{
-		Code:    string(validationErr.Code),
+		Code:    validationErr.Code,
 		Message: validationErr.Message,
 		Details: validationErr.Details,
 		Fields:  validationErr.Fields,
@@ -110,6 +117,7 @@ func EnhancedValidationErrorResponse(c *gin.Context, validationErr *errors.Valid
 		Language:  string(lang),
 		Timestamp: validationErr.Timestamp.Format(time.RFC3339),
 		TraceID:   validationErr.TraceID,
+		Meta:      responseMeta(c),
 	}
 
 	logErrorResponse(c, validationErr.AppError, statusCode)
@@ -156,7 +164,7 @@ func logSuccessResponse(c *gin.Context, statusCode int, translatedMessage, messa
 		"translated_message": translatedMessage,
 		"client_ip":          c.ClientIP(),
 		"user_agent":         c.GetHeader("User-Agent"),
-		"request_id":         c.GetHeader("X-Request-ID"),
+		"request_id":         requestID(c),
 		"trace_id":           c.GetHeader("X-Trace-ID"),
 		"has_data":           hasData,
 	}
@@ -185,7 +193,7 @@ func logErrorResponse(c *gin.Context, appErr *errors.AppError, statusCode int) {
 		"path":          c.Request.URL.Path,
 		"client_ip":     c.ClientIP(),
 		"user_agent":    c.GetHeader("User-Agent"),
-		"request_id":    c.GetHeader("X-Request-ID"),
+		"request_id":    requestID(c),
 		"trace_id":      appErr.TraceID,
 		"timestamp":     appErr.Timestamp.Format(time.RFC3339),
 	}
@@ -245,6 +253,14 @@ func ErrorResponse(c *gin.Context, statusCode int, messageKey string, err error)
 		return
 	}
 
+	// Binding errors name each field that failed validation, in the
+	// language of the request
+	if fields := validation.FieldErrors(err, i18n.GetLanguageFromContext(c)); fields != nil {
+		appErr := errors.Wrap(err, getErrorCodeFromStatus(statusCode), messageKey).WithRequestPath(c.Request.URL.Path)
+		EnhancedValidationErrorResponse(c, &errors.ValidationError{AppError: appErr, Fields: fields})
+		return
+	}
+
 	// Handle database errors
 	if isDatabaseError(err) {
 		DatabaseErrorResponse(c, err, "unknown_operation")
@@ -298,8 +314,14 @@ func getErrorCodeFromStatus(statusCode int) errors.ErrorCode {
 		return errors.ErrCodeNotFound
 	case 409:
 		return errors.ErrCodeConflict
+	case 413:
+		return errors.ErrCodePayloadTooLarge
+	case 416:
+		return errors.ErrCodeRangeNotSatisfiable
 	case 422:
 		return errors.ErrCodeValidationFailed
+	case 423:
+		return errors.ErrCodeAccountLocked
 	case 429:
 		return errors.ErrCodeRateLimited
 	case 500:
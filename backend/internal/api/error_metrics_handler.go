@@ -9,7 +9,8 @@ import (
 	"github.com/toeic-app/internal/logger"
 )
 
-// ErrorMetricsHandler handles error metrics endpoints
+// ErrorMetricsHandler handles error metrics endpoints. Its routes are not
+// registered, so they are left out of the swagger document.
 type ErrorMetricsHandler struct {
 	metrics *errors.ErrorMetrics
 }
@@ -21,16 +22,7 @@ func NewErrorMetricsHandler(metrics *errors.ErrorMetrics) *ErrorMetricsHandler {
 	}
 }
 
-// @Summary     Get error metrics summary
-// @Description Get a comprehensive summary of error metrics including counts, rates, and top errors
-// @Tags        monitoring
-// @Accept      json
-// @Produce     json
-// @Param       top_errors query int false "Number of top errors to include" default(10)
-// @Success     200 {object} Response{data=errors.ErrorSummary} "Error metrics retrieved successfully"
-// @Failure     500 {object} Response "Server error"
-// @Router      /api/v1/admin/metrics/errors/summary [get]
-// @Security    ApiKeyAuth
+// GetErrorSummary returns a summary of error counts, rates and top errors
 func (h *ErrorMetricsHandler) GetErrorSummary(c *gin.Context) {
 	// Parse query parameter for top errors limit
 	topLimit := 10
@@ -53,15 +45,7 @@ func (h *ErrorMetricsHandler) GetErrorSummary(c *gin.Context) {
 	SuccessResponse(c, http.StatusOK, "Error metrics retrieved successfully", summary)
 }
 
-// @Summary     Get error counts by code
-// @Description Get error counts grouped by error code
-// @Tags        monitoring
-// @Accept      json
-// @Produce     json
-// @Success     200 {object} Response{data=map[string]int64} "Error counts retrieved successfully"
-// @Failure     500 {object} Response "Server error"
-// @Router      /api/v1/admin/metrics/errors/counts [get]
-// @Security    ApiKeyAuth
+// GetErrorCounts returns error counts by code
 func (h *ErrorMetricsHandler) GetErrorCounts(c *gin.Context) {
 	counts := h.metrics.GetErrorCounts()
 
@@ -80,15 +64,7 @@ func (h *ErrorMetricsHandler) GetErrorCounts(c *gin.Context) {
 	SuccessResponse(c, http.StatusOK, "Error counts retrieved successfully", result)
 }
 
-// @Summary     Get error counts by category
-// @Description Get error counts grouped by category (CLIENT, SERVER, DATABASE, etc.)
-// @Tags        monitoring
-// @Accept      json
-// @Produce     json
-// @Success     200 {object} Response{data=map[string]int64} "Category counts retrieved successfully"
-// @Failure     500 {object} Response "Server error"
-// @Router      /api/v1/admin/metrics/errors/categories [get]
-// @Security    ApiKeyAuth
+// GetCategoryCounts returns error counts by category
 func (h *ErrorMetricsHandler) GetCategoryCounts(c *gin.Context) {
 	counts := h.metrics.GetCategoryCounts()
 
@@ -107,15 +83,7 @@ func (h *ErrorMetricsHandler) GetCategoryCounts(c *gin.Context) {
 	SuccessResponse(c, http.StatusOK, "Category counts retrieved successfully", result)
 }
 
-// @Summary     Get error counts by severity
-// @Description Get error counts grouped by severity level (LOW, MEDIUM, HIGH, CRITICAL)
-// @Tags        monitoring
-// @Accept      json
-// @Produce     json
-// @Success     200 {object} Response{data=map[string]int64} "Severity counts retrieved successfully"
-// @Failure     500 {object} Response "Server error"
-// @Router      /api/v1/admin/metrics/errors/severity [get]
-// @Security    ApiKeyAuth
+// GetSeverityCounts returns error counts by severity
 func (h *ErrorMetricsHandler) GetSeverityCounts(c *gin.Context) {
 	counts := h.metrics.GetSeverityCounts()
 
@@ -134,15 +102,7 @@ func (h *ErrorMetricsHandler) GetSeverityCounts(c *gin.Context) {
 	SuccessResponse(c, http.StatusOK, "Severity counts retrieved successfully", result)
 }
 
-// @Summary     Get error counts by HTTP status
-// @Description Get error counts grouped by HTTP status code
-// @Tags        monitoring
-// @Accept      json
-// @Produce     json
-// @Success     200 {object} Response{data=map[string]int64} "HTTP status counts retrieved successfully"
-// @Failure     500 {object} Response "Server error"
-// @Router      /api/v1/admin/metrics/errors/status [get]
-// @Security    ApiKeyAuth
+// GetHTTPStatusCounts returns error counts by HTTP status
 func (h *ErrorMetricsHandler) GetHTTPStatusCounts(c *gin.Context) {
 	counts := h.metrics.GetHTTPStatusCounts()
 
@@ -161,15 +121,7 @@ func (h *ErrorMetricsHandler) GetHTTPStatusCounts(c *gin.Context) {
 	SuccessResponse(c, http.StatusOK, "HTTP status counts retrieved successfully", result)
 }
 
-// @Summary     Get error rate
-// @Description Get the current error rate per minute
-// @Tags        monitoring
-// @Accept      json
-// @Produce     json
-// @Success     200 {object} Response{data=object} "Error rate retrieved successfully"
-// @Failure     500 {object} Response "Server error"
-// @Router      /api/v1/admin/metrics/errors/rate [get]
-// @Security    ApiKeyAuth
+// GetErrorRate returns the error rate
 func (h *ErrorMetricsHandler) GetErrorRate(c *gin.Context) {
 	rate := h.metrics.GetErrorRate()
 	totalErrors := h.metrics.GetTotalErrors()
@@ -189,15 +141,7 @@ func (h *ErrorMetricsHandler) GetErrorRate(c *gin.Context) {
 	SuccessResponse(c, http.StatusOK, "Error rate retrieved successfully", result)
 }
 
-// @Summary     Reset error metrics
-// @Description Reset all error metrics counters to zero
-// @Tags        monitoring
-// @Accept      json
-// @Produce     json
-// @Success     200 {object} Response "Error metrics reset successfully"
-// @Failure     500 {object} Response "Server error"
-// @Router      /api/v1/admin/metrics/errors/reset [post]
-// @Security    ApiKeyAuth
+// ResetMetrics resets the error metrics
 func (h *ErrorMetricsHandler) ResetMetrics(c *gin.Context) {
 	oldTotal := h.metrics.GetTotalErrors()
 	h.metrics.Reset()
@@ -214,16 +158,7 @@ func (h *ErrorMetricsHandler) ResetMetrics(c *gin.Context) {
 	})
 }
 
-// @Summary     Get top errors
-// @Description Get the most frequently occurring errors
-// @Tags        monitoring
-// @Accept      json
-// @Produce     json
-// @Param       limit query int false "Number of top errors to return" default(10)
-// @Success     200 {object} Response{data=[]object} "Top errors retrieved successfully"
-// @Failure     500 {object} Response "Server error"
-// @Router      /api/v1/admin/metrics/errors/top [get]
-// @Security    ApiKeyAuth
+// GetTopErrors returns the most frequent errors
 func (h *ErrorMetricsHandler) GetTopErrors(c *gin.Context) {
 	// Parse limit parameter
 	limit := 10
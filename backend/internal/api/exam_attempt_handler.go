@@ -1,15 +1,23 @@
 package api
 
 import (
-	"database/sql"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/accesslog"
+	"github.com/toeic-app/internal/billing"
+	"github.com/toeic-app/internal/cache"
 	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/examservice"
+	"github.com/toeic-app/internal/integrity"
 	"github.com/toeic-app/internal/logger"
+	"github.com/toeic-app/internal/prediction"
+	"github.com/toeic-app/internal/social"
 	"github.com/toeic-app/internal/token"
 )
 
@@ -24,6 +32,15 @@ type ExamAttemptResponse struct {
 	Status    db.ExamStatusEnum `json:"status"`
 	CreatedAt time.Time         `json:"created_at"`
 	UpdatedAt time.Time         `json:"updated_at"`
+	// Partial attempts practice the single part part_id within
+	// time_limit_minutes and are left out of the leaderboard
+	Partial          bool   `json:"partial"`
+	PartID           *int32 `json:"part_id,omitempty"`
+	TimeLimitMinutes *int32 `json:"time_limit_minutes,omitempty"`
+	MaxScore         int32  `json:"max_score" example:"990"`
+	// Integrity is only shown to teachers and administrators reading
+	// another user's attempt
+	Integrity *integrity.Summary `json:"integrity,omitempty"`
 }
 
 // ExamAttemptStatsResponse provides statistics about user's exam attempts
@@ -56,6 +73,15 @@ func NewExamAttemptResponse(attempt db.ExamAttempt) ExamAttemptResponse {
 		Status:    attempt.Status,
 		CreatedAt: attempt.CreatedAt,
 		UpdatedAt: attempt.UpdatedAt,
+		Partial:   attempt.PartID.Valid,
+		MaxScore:  examservice.MaxScore(attempt),
+	}
+
+	if attempt.PartID.Valid {
+		response.PartID = &attempt.PartID.Int32
+	}
+	if attempt.TimeLimitMinutes.Valid {
+		response.TimeLimitMinutes = &attempt.TimeLimitMinutes.Int32
 	}
 
 	if attempt.EndTime.Valid {
@@ -70,15 +96,46 @@ func NewExamAttemptResponse(attempt db.ExamAttempt) ExamAttemptResponse {
 	return response
 }
 
+// newExamService creates the exam attempt service. Results are cached in
+// serviceCache, if the cache is enabled, changes clear the user's cached
+// progress and completed attempts refresh their exam's leaderboard and
+// their user's predicted score.
+func newExamService(store db.Querier, serviceCache *cache.ServiceCache, clearUserCache func(userID int64) error,
+	leaderboards *examservice.LeaderboardRefresher, predictions *prediction.Service) examservice.Service {
+	options := examservice.Options{
+		UserChanged: func(userID int32) {
+			if err := clearUserCache(int64(userID)); err != nil {
+				logger.Warn("Failed to clear cache of user %d: %v", userID, err)
+			}
+		},
+		AttemptCompleted: func(attempt db.ExamAttempt) {
+			leaderboards.Completed(attempt)
+			predictions.Completed(attempt)
+		},
+	}
+	if serviceCache != nil {
+		options.Cache = serviceCache
+	}
+	return examservice.NewService(store, options)
+}
+
 // createExamAttemptRequest defines the structure for creating a new exam attempt
 type createExamAttemptRequest struct {
 	ExamID int32 `json:"exam_id" binding:"required,min=1"`
 }
 
+// startPartPracticeRequest defines the structure for practicing a single
+// part of an exam
+type startPartPracticeRequest struct {
+	PartID int32 `json:"part_id" binding:"required,min=1" example:"5"`
+	// Minutes allowed; the part's share of the exam's time limit when omitted
+	TimeLimitMinutes int32 `json:"time_limit_minutes,omitempty" binding:"omitempty,min=1,max=600" example:"25"`
+}
+
 // updateExamAttemptRequest defines the structure for updating an exam attempt
 type updateExamAttemptRequest struct {
-	Status *string `json:"status,omitempty"`
-	Score  *string `json:"score,omitempty"`
+	Status *string `json:"status,omitempty" enums:"in_progress,completed,abandoned" example:"completed"`
+	Score  *string `json:"score,omitempty" binding:"omitempty,toeic_score" example:"785"`
 }
 
 // getExamAttemptRequest defines the structure for getting an exam attempt by ID
@@ -98,9 +155,11 @@ type listLeaderboardRequest struct {
 	Offset int32 `form:"offset" binding:"min=0"`
 }
 
-// completeExamAttemptRequest defines the structure for completing an exam attempt
+// completeExamAttemptRequest defines the structure for completing an exam
+// attempt. Without a score, and always for part practice, the attempt is
+// scored from its answers.
 type completeExamAttemptRequest struct {
-	Score string `json:"score" binding:"required"`
+	Score string `json:"score,omitempty" binding:"omitempty,toeic_score" example:"785"`
 }
 
 // @Summary     Start a new exam attempt
@@ -112,6 +171,7 @@ type completeExamAttemptRequest struct {
 // @Success     201 {object} Response{data=ExamAttemptResponse} "Exam attempt started successfully"
 // @Failure     400 {object} Response "Invalid request body"
 // @Failure     401 {object} Response "Unauthorized"
+// @Failure     402 {object} Response "Premium exam requires a subscription"
 // @Failure     404 {object} Response "Exam not found"
 // @Failure     409 {object} Response "User already has an active attempt for this exam"
 // @Failure     500 {object} Response "Failed to create exam attempt"
@@ -137,65 +197,105 @@ func (server *Server) createExamAttempt(ctx *gin.Context) {
 		return
 	}
 
-	// Check if exam exists
-	_, err := server.store.GetExam(ctx, req.ExamID)
+	// Premium exams need a subscription
+	exam, err := server.exams.Exam(ctx, req.ExamID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ErrorResponse(ctx, http.StatusNotFound, "exam_not_found", err)
-			return
-		}
-		ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_retrieve_exam", err)
+		examServiceError(ctx, err, "failed_to_retrieve_exam")
+		return
+	}
+	if exam.IsPremium && !server.requireEntitlement(ctx, authPayload.ID, billing.EntitlementPremiumExams) {
 		return
 	}
 
-	// Check if user already has an active attempt for this exam
-	activeAttempt, err := server.store.GetActiveExamAttempt(ctx, db.GetActiveExamAttemptParams{
-		UserID: authPayload.ID,
-		ExamID: req.ExamID,
-	})
-	if err == nil {
-		// User already has an active attempt - return conflict with attempt ID in message
-		message := fmt.Sprintf("User already has active attempt with ID: %d", activeAttempt.AttemptID)
+	attempt, err := server.exams.StartAttempt(ctx, authPayload.ID, req.ExamID)
+	if errors.Is(err, examservice.ErrActiveAttempt) {
+		// Return conflict with the active attempt's ID in the message
+		message := fmt.Sprintf("User already has active attempt with ID: %d", attempt.AttemptID)
 		ErrorResponseWithMessage(ctx, http.StatusConflict, message, nil)
 		return
-	} else if err != sql.ErrNoRows {
-		ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_check_active_attempt", err)
+	}
+	if err != nil {
+		examServiceError(ctx, err, "failed_to_create_exam_attempt")
 		return
 	}
 
-	// Create the exam attempt
-	arg := db.CreateExamAttemptParams{
-		UserID:    authPayload.ID,
-		ExamID:    req.ExamID,
-		StartTime: time.Now(),
-		Status:    db.ExamStatusEnumInProgress,
+	response := NewExamAttemptResponse(attempt)
+	SuccessResponse(ctx, http.StatusCreated, "exam_attempt_created_successfully", response)
+}
+
+// @Summary     Practice a single part of an exam
+// @Description Start an attempt scoped to one part of an exam, such as Part 5. It lasts time_limit_minutes, by default the part's share of the exam's time limit, only accepts answers to questions of the part, and is scored as the percentage of correct answers (max_score 100). Part practice is left out of the exam's leaderboard, attempt statistics and LMS grade passback.
+// @Tags        exam-attempts
+// @Accept      json
+// @Produce     json
+// @Param       practice body startPartPracticeRequest true "Part to practice"
+// @Success     201 {object} Response{data=ExamAttemptResponse} "Part practice started successfully"
+// @Failure     400 {object} Response "Invalid request body or the part has no questions"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     402 {object} Response "Premium exam requires a subscription"
+// @Failure     404 {object} Response "Part not found"
+// @Failure     409 {object} Response "User already practices this part"
+// @Failure     500 {object} Response "Failed to start part practice"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/exam-attempts/practice [post]
+func (server *Server) startPartPractice(ctx *gin.Context) {
+	var req startPartPracticeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "invalid_request_body", err)
+		return
 	}
 
-	attempt, err := server.store.CreateExamAttempt(ctx, arg)
+	// Get user from authorization
+	payload, exists := ctx.Get(AuthorizationPayloadKey)
+	if !exists {
+		ErrorResponse(ctx, http.StatusUnauthorized, "authorization_payload_not_found", nil)
+		return
+	}
+
+	authPayload, ok := payload.(*token.Payload)
+	if !ok {
+		ErrorResponse(ctx, http.StatusUnauthorized, "invalid_authorization_payload", nil)
+		return
+	}
+
+	// Premium exams need a subscription, for their parts too
+	part, err := server.exams.Part(ctx, req.PartID)
 	if err != nil {
-		ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_create_exam_attempt", err)
+		examServiceError(ctx, err, "failed_to_retrieve_part")
+		return
+	}
+	exam, err := server.exams.Exam(ctx, part.ExamID)
+	if err != nil {
+		examServiceError(ctx, err, "failed_to_retrieve_exam")
+		return
+	}
+	if exam.IsPremium && !server.requireEntitlement(ctx, authPayload.ID, billing.EntitlementPremiumExams) {
 		return
 	}
 
-	// Clear user cache for exam attempts
-	if server.serviceCache != nil {
-		go func() {
-			server.ClearUserCache(int64(authPayload.ID))
-		}()
+	attempt, err := server.exams.StartPartPractice(ctx, authPayload.ID, req.PartID, req.TimeLimitMinutes)
+	if errors.Is(err, examservice.ErrActiveAttempt) {
+		message := fmt.Sprintf("User already has active attempt with ID: %d", attempt.AttemptID)
+		ErrorResponseWithMessage(ctx, http.StatusConflict, message, nil)
+		return
+	}
+	if err != nil {
+		examServiceError(ctx, err, "failed_to_start_part_practice")
+		return
 	}
 
-	response := NewExamAttemptResponse(attempt)
-	SuccessResponse(ctx, http.StatusCreated, "exam_attempt_created_successfully", response)
+	SuccessResponse(ctx, http.StatusCreated, "part_practice_started_successfully", NewExamAttemptResponse(attempt))
 }
 
 // @Summary     Get an exam attempt by ID
-// @Description Retrieve a specific exam attempt by its ID (user can only access their own attempts)
+// @Description Retrieve a specific exam attempt by its ID. Users can only access their own attempts; users with exams.grade can read any attempt, which includes its integrity score and is recorded in the owner's access log.
 // @Tags        exam-attempts
 // @Accept      json
 // @Produce     json
 // @Param       id path int true "Exam Attempt ID"
+// @Param       X-Access-Purpose header string false "Why another user's attempt is read: support, grading, moderation, legal or unspecified"
 // @Success     200 {object} Response{data=ExamAttemptResponse} "Exam attempt retrieved successfully"
-// @Failure     400 {object} Response "Invalid attempt ID"
+// @Failure     400 {object} Response "Invalid attempt ID or access purpose"
 // @Failure     401 {object} Response "Unauthorized"
 // @Failure     403 {object} Response "Access denied - not your exam attempt"
 // @Failure     404 {object} Response "Exam attempt not found"
@@ -222,21 +322,22 @@ func (server *Server) getExamAttempt(ctx *gin.Context) {
 		return
 	}
 
-	// Get exam attempt and verify ownership
-	attempt, err := server.store.GetExamAttemptByUser(ctx, db.GetExamAttemptByUserParams{
-		AttemptID: req.AttemptID,
-		UserID:    authPayload.ID,
-	})
+	// Get exam attempt and verify it belongs to the user or the user grades exams
+	attempt, err := server.exams.Attempt(ctx, req.AttemptID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ErrorResponse(ctx, http.StatusNotFound, "exam_attempt_not_found", err)
+		examServiceError(ctx, err, "failed_to_retrieve_exam_attempt")
+		return
+	}
+	response := NewExamAttemptResponse(attempt)
+
+	if attempt.UserID != authPayload.ID {
+		summary, ok := server.staffIntegritySummary(ctx, authPayload.ID, attempt, accesslog.ResourceExamAttempt)
+		if !ok {
 			return
 		}
-		ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_retrieve_exam_attempt", err)
-		return
+		response.Integrity = summary
 	}
 
-	response := NewExamAttemptResponse(attempt)
 	SuccessResponse(ctx, http.StatusOK, "exam_attempt_retrieved_successfully", response)
 }
 
@@ -275,21 +376,7 @@ func (server *Server) listUserExamAttempts(ctx *gin.Context) {
 		return
 	}
 
-	// Check cache first
-	cacheKey := fmt.Sprintf("user:exam_attempts:%d:%d:%d", authPayload.ID, req.Limit, req.Offset)
-	if server.serviceCache != nil {
-		var cachedAttempts []ExamAttemptResponse
-		if err := server.serviceCache.Get(ctx, cacheKey, &cachedAttempts); err == nil {
-			SuccessResponse(ctx, http.StatusOK, "exam_attempts_retrieved_successfully", cachedAttempts)
-			return
-		}
-	}
-
-	attempts, err := server.store.ListExamAttemptsByUser(ctx, db.ListExamAttemptsByUserParams{
-		UserID: authPayload.ID,
-		Limit:  req.Limit,
-		Offset: req.Offset,
-	})
+	attempts, err := server.exams.ListAttempts(ctx, authPayload.ID, req.Limit, req.Offset)
 	if err != nil {
 		ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_retrieve_exam_attempts", err)
 		return
@@ -300,14 +387,7 @@ func (server *Server) listUserExamAttempts(ctx *gin.Context) {
 		response[i] = NewExamAttemptResponse(attempt)
 	}
 
-	// Cache the results
-	if server.serviceCache != nil {
-		go func() {
-			server.serviceCache.Set(ctx, cacheKey, response, 5*time.Minute)
-		}()
-	}
-
-	SuccessResponse(ctx, http.StatusOK, "exam_attempts_retrieved_successfully", response)
+	PaginatedResponse(ctx, http.StatusOK, "exam_attempts_retrieved_successfully", response, NewPagination(req.Limit, req.Offset, len(response)))
 }
 
 // @Summary     Update exam attempt status
@@ -351,77 +431,27 @@ func (server *Server) updateExamAttempt(ctx *gin.Context) {
 		return
 	}
 
-	// Verify ownership first
-	_, err := server.store.GetExamAttemptByUser(ctx, db.GetExamAttemptByUserParams{
-		AttemptID: req.AttemptID,
-		UserID:    authPayload.ID,
+	// A score also completes the attempt
+	updatedAttempt, err := server.exams.UpdateAttempt(ctx, authPayload.ID, req.AttemptID, examservice.AttemptUpdate{
+		Status: updateReq.Status,
+		Score:  updateReq.Score,
 	})
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ErrorResponse(ctx, http.StatusNotFound, "exam_attempt_not_found", err)
-			return
-		}
-		ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_retrieve_exam_attempt", err)
-		return
-	}
-
-	var updatedAttempt db.ExamAttempt
-
-	// Handle different update operations
-	if updateReq.Score != nil {
-		// Update score (this will also complete the attempt)
-		scoreValue := sql.NullString{String: *updateReq.Score, Valid: true}
-		updatedAttempt, err = server.store.UpdateExamAttemptScore(ctx, db.UpdateExamAttemptScoreParams{
-			AttemptID: req.AttemptID,
-			Score:     scoreValue,
-		})
-	} else if updateReq.Status != nil {
-		// Update status only
-		var statusEnum db.ExamStatusEnum
-		switch *updateReq.Status {
-		case "in_progress":
-			statusEnum = db.ExamStatusEnumInProgress
-		case "completed":
-			statusEnum = db.ExamStatusEnumCompleted
-		case "abandoned":
-			statusEnum = db.ExamStatusEnumAbandoned
-		default:
-			ErrorResponse(ctx, http.StatusBadRequest, "invalid_status_value", nil)
-			return
-		}
-
-		updatedAttempt, err = server.store.UpdateExamAttemptStatus(ctx, db.UpdateExamAttemptStatusParams{
-			AttemptID: req.AttemptID,
-			Status:    statusEnum,
-		})
-	} else {
-		ErrorResponse(ctx, http.StatusBadRequest, "no_update_fields_provided", nil)
+		examServiceError(ctx, err, "failed_to_update_exam_attempt")
 		return
 	}
 
-	if err != nil {
-		ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_update_exam_attempt", err)
-		return
-	}
-
-	// Clear user cache
-	if server.serviceCache != nil {
-		go func() {
-			server.ClearUserCache(int64(authPayload.ID))
-		}()
-	}
-
 	response := NewExamAttemptResponse(updatedAttempt)
 	SuccessResponse(ctx, http.StatusOK, "exam_attempt_updated_successfully", response)
 }
 
 // @Summary     Complete exam attempt
-// @Description Complete an exam attempt with final score
+// @Description Complete an exam attempt with final score. Without a score, and always for part practice, the attempt is scored from its answers.
 // @Tags        exam-attempts
 // @Accept      json
 // @Produce     json
 // @Param       id path int true "Exam Attempt ID"
-// @Param       request body completeExamAttemptRequest true "Complete exam attempt request"
+// @Param       request body completeExamAttemptRequest false "Complete exam attempt request"
 // @Success     200 {object} Response{data=ExamAttemptResponse} "Exam attempt completed successfully"
 // @Failure     400 {object} Response "Invalid request"
 // @Failure     401 {object} Response "Unauthorized"
@@ -436,8 +466,9 @@ func (server *Server) completeExamAttempt(ctx *gin.Context) {
 		return
 	}
 
+	// The body may be left out to score the attempt from its answers
 	var scoreReq completeExamAttemptRequest
-	if err := ctx.ShouldBindJSON(&scoreReq); err != nil {
+	if err := ctx.ShouldBindJSON(&scoreReq); err != nil && !errors.Is(err, io.EOF) {
 		ErrorResponse(ctx, http.StatusBadRequest, "invalid_request_body", err)
 		return
 	}
@@ -455,37 +486,18 @@ func (server *Server) completeExamAttempt(ctx *gin.Context) {
 		return
 	}
 
-	// Verify ownership
-	_, err := server.store.GetExamAttemptByUser(ctx, db.GetExamAttemptByUserParams{
-		AttemptID: req.AttemptID,
-		UserID:    authPayload.ID,
-	})
-	if err != nil {
-		if err == sql.ErrNoRows {
-			ErrorResponse(ctx, http.StatusNotFound, "exam_attempt_not_found", err)
-			return
-		}
-		ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_retrieve_exam_attempt", err)
-		return
-	}
-
-	// Complete the attempt
-	scoreValue := sql.NullString{String: scoreReq.Score, Valid: true}
-	updatedAttempt, err := server.store.CompleteExamAttempt(ctx, db.CompleteExamAttemptParams{
-		AttemptID: req.AttemptID,
-		Score:     scoreValue,
-	})
+	updatedAttempt, err := server.exams.CompleteAttempt(ctx, authPayload.ID, req.AttemptID, scoreReq.Score)
 	if err != nil {
-		ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_complete_exam_attempt", err)
+		examServiceError(ctx, err, "failed_to_complete_exam_attempt")
 		return
 	}
 
-	// Clear user cache
-	if server.serviceCache != nil {
-		go func() {
-			server.ClearUserCache(int64(authPayload.ID))
-		}()
+	activity := map[string]interface{}{"exam_id": updatedAttempt.ExamID, "attempt_id": updatedAttempt.AttemptID}
+	if score, err := strconv.ParseFloat(updatedAttempt.Score.String, 64); err == nil {
+		activity["score"] = score
 	}
+	server.recordActivity(ctx, authPayload.ID, social.ActivityExamCompleted, activity, social.XPExamCompleted)
+	server.submitLTIScore(updatedAttempt)
 
 	response := NewExamAttemptResponse(updatedAttempt)
 	SuccessResponse(ctx, http.StatusOK, "exam_attempt_completed_successfully", response)
@@ -524,34 +536,12 @@ func (server *Server) abandonExamAttempt(ctx *gin.Context) {
 		return
 	}
 
-	// Verify ownership
-	_, err := server.store.GetExamAttemptByUser(ctx, db.GetExamAttemptByUserParams{
-		AttemptID: req.AttemptID,
-		UserID:    authPayload.ID,
-	})
-	if err != nil {
-		if err == sql.ErrNoRows {
-			ErrorResponse(ctx, http.StatusNotFound, "exam_attempt_not_found", err)
-			return
-		}
-		ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_retrieve_exam_attempt", err)
-		return
-	}
-
-	// Abandon the attempt
-	updatedAttempt, err := server.store.AbandonExamAttempt(ctx, req.AttemptID)
+	updatedAttempt, err := server.exams.AbandonAttempt(ctx, authPayload.ID, req.AttemptID)
 	if err != nil {
-		ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_abandon_exam_attempt", err)
+		examServiceError(ctx, err, "failed_to_abandon_exam_attempt")
 		return
 	}
 
-	// Clear user cache
-	if server.serviceCache != nil {
-		go func() {
-			server.ClearUserCache(int64(authPayload.ID))
-		}()
-	}
-
 	response := NewExamAttemptResponse(updatedAttempt)
 	SuccessResponse(ctx, http.StatusOK, "exam_attempt_abandoned_successfully", response)
 }
@@ -580,17 +570,7 @@ func (server *Server) getExamAttemptStats(ctx *gin.Context) {
 		return
 	}
 
-	// Check cache first
-	cacheKey := fmt.Sprintf("user:exam_attempt_stats:%d", authPayload.ID)
-	if server.serviceCache != nil {
-		var cachedStats ExamAttemptStatsResponse
-		if err := server.serviceCache.Get(ctx, cacheKey, &cachedStats); err == nil {
-			SuccessResponse(ctx, http.StatusOK, "exam_attempt_stats_retrieved_successfully", cachedStats)
-			return
-		}
-	}
-
-	stats, err := server.store.GetExamAttemptStats(ctx, authPayload.ID)
+	stats, err := server.exams.Stats(ctx, authPayload.ID)
 	if err != nil {
 		ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_retrieve_exam_attempt_stats", err)
 		return
@@ -601,36 +581,16 @@ func (server *Server) getExamAttemptStats(ctx *gin.Context) {
 		CompletedAttempts:  stats.CompletedAttempts,
 		InProgressAttempts: stats.InProgressAttempts,
 		AbandonedAttempts:  stats.AbandonedAttempts,
-	}
-
-	// Handle nullable scores by checking for a sentinel value (e.g., -1 means no score)
-	if stats.AverageScore != -1 {
-		avgScore := fmt.Sprintf("%.2f", stats.AverageScore)
-		response.AverageScore = &avgScore
-	}
-
-	if stats.HighestScore != -1 {
-		highScore := fmt.Sprintf("%.2f", stats.HighestScore)
-		response.HighestScore = &highScore
-	}
-
-	if stats.LowestScore != -1 {
-		lowScore := fmt.Sprintf("%.2f", stats.LowestScore)
-		response.LowestScore = &lowScore
-	}
-
-	// Cache the results
-	if server.serviceCache != nil {
-		go func() {
-			server.serviceCache.Set(ctx, cacheKey, response, 10*time.Minute)
-		}()
+		AverageScore:       formatScore(stats.AverageScore),
+		HighestScore:       formatScore(stats.HighestScore),
+		LowestScore:        formatScore(stats.LowestScore),
 	}
 
 	SuccessResponse(ctx, http.StatusOK, "exam_attempt_stats_retrieved_successfully", response)
 }
 
 // @Summary     Get exam leaderboard
-// @Description Get the leaderboard for a specific exam
+// @Description Get the leaderboard for a specific exam. Pages are cached and refreshed as soon as an attempt of the exam is completed; subscribe to the exam.{id}.leaderboard WebSocket topic to receive them.
 // @Tags        exam-attempts
 // @Accept      json
 // @Produce     json
@@ -658,66 +618,18 @@ func (server *Server) getExamLeaderboard(ctx *gin.Context) {
 		return
 	}
 
-	// Check if exam exists
-	_, err = server.store.GetExam(ctx, int32(examID))
-	if err != nil {
-		if err == sql.ErrNoRows {
-			ErrorResponse(ctx, http.StatusNotFound, "exam_not_found", err)
-			return
-		}
-		ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_retrieve_exam", err)
-		return
-	}
-
-	// Check cache first
-	cacheKey := fmt.Sprintf("exam:leaderboard:%d:%d:%d", examID, req.Limit, req.Offset)
-	if server.serviceCache != nil {
-		var cachedLeaderboard []LeaderboardEntry
-		if err := server.serviceCache.Get(ctx, cacheKey, &cachedLeaderboard); err == nil {
-			SuccessResponse(ctx, http.StatusOK, "exam_leaderboard_retrieved_successfully", cachedLeaderboard)
-			return
-		}
-	}
-
-	leaderboard, err := server.store.GetExamLeaderboard(ctx, db.GetExamLeaderboardParams{
-		ExamID: int32(examID),
-		Limit:  req.Limit,
-		Offset: req.Offset,
-	})
+	leaderboard, err := server.exams.Leaderboard(ctx, int32(examID), req.Limit, req.Offset)
 	if err != nil {
-		ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_retrieve_exam_leaderboard", err)
+		examServiceError(ctx, err, "failed_to_retrieve_exam_leaderboard")
 		return
 	}
 
 	response := make([]LeaderboardEntry, len(leaderboard))
 	for i, entry := range leaderboard {
-		var endTime time.Time
-		if entry.EndTime.Valid {
-			endTime = entry.EndTime.Time
-		}
-
-		var score string
-		if entry.Score.Valid {
-			score = entry.Score.String
-		}
-
-		response[i] = LeaderboardEntry{
-			UserID:   entry.UserID,
-			Username: entry.Username,
-			Score:    score,
-			EndTime:  endTime,
-			Rank:     entry.Rank,
-		}
-	}
-
-	// Cache the results
-	if server.serviceCache != nil {
-		go func() {
-			server.serviceCache.Set(ctx, cacheKey, response, 30*time.Minute)
-		}()
+		response[i] = LeaderboardEntry(entry)
 	}
 
-	SuccessResponse(ctx, http.StatusOK, "exam_leaderboard_retrieved_successfully", response)
+	PaginatedResponse(ctx, http.StatusOK, "exam_leaderboard_retrieved_successfully", response, NewPagination(req.Limit, req.Offset, len(response)))
 }
 
 // @Summary     Delete an exam attempt
@@ -761,42 +673,59 @@ func (server *Server) deleteExamAttempt(ctx *gin.Context) {
 		return
 	}
 
-	if !isAdmin {
-		// Non-admin users can only delete their own incomplete attempts
-		attempt, err := server.store.GetExamAttemptByUser(ctx, db.GetExamAttemptByUserParams{
-			AttemptID: req.AttemptID,
-			UserID:    authPayload.ID,
-		})
-		if err != nil {
-			if err == sql.ErrNoRows {
-				ErrorResponse(ctx, http.StatusNotFound, "exam_attempt_not_found", err)
-				return
-			}
-			ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_retrieve_exam_attempt", err)
-			return
-		}
-
-		// Only allow deletion of in-progress attempts
-		if attempt.Status != db.ExamStatusEnumInProgress {
-			ErrorResponse(ctx, http.StatusForbidden, "cannot_delete_completed_attempt", nil)
-			return
-		}
+	// Non-admin users can only delete their own incomplete attempts
+	err = server.exams.DeleteAttempt(ctx, authPayload.ID, req.AttemptID, isAdmin)
+	if errors.Is(err, examservice.ErrAttemptClosed) {
+		ErrorResponse(ctx, http.StatusForbidden, "cannot_delete_completed_attempt", nil)
+		return
 	}
-
-	// Delete the exam attempt (this will cascade to user_answers)
-	err = server.store.DeleteExamAttempt(ctx, req.AttemptID)
 	if err != nil {
-		ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_delete_exam_attempt", err)
+		examServiceError(ctx, err, "failed_to_delete_exam_attempt")
 		return
 	}
 
-	// Clear user cache
-	if server.serviceCache != nil {
-		go func() {
-			server.ClearUserCache(int64(authPayload.ID))
-		}()
-	}
-
 	logger.Info("Exam attempt %d deleted by user %d", req.AttemptID, authPayload.ID)
 	SuccessResponse(ctx, http.StatusOK, "exam_attempt_deleted_successfully", nil)
 }
+
+// formatScore formats a score with two decimals, or returns nil
+func formatScore(score *float64) *string {
+	if score == nil {
+		return nil
+	}
+	formatted := fmt.Sprintf("%.2f", *score)
+	return &formatted
+}
+
+// examServiceError responds to an error of the exam attempt service,
+// with message when the error is unexpected
+func examServiceError(ctx *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, examservice.ErrExamNotFound):
+		ErrorResponse(ctx, http.StatusNotFound, "exam_not_found", err)
+	case errors.Is(err, examservice.ErrAttemptNotFound):
+		ErrorResponse(ctx, http.StatusNotFound, "exam_attempt_not_found", err)
+	case errors.Is(err, examservice.ErrAnswerNotFound):
+		ErrorResponse(ctx, http.StatusNotFound, "user_answer_not_found", err)
+	case errors.Is(err, examservice.ErrQuestionNotFound):
+		ErrorResponse(ctx, http.StatusNotFound, "question_not_found", err)
+	case errors.Is(err, examservice.ErrAnswerExists):
+		ErrorResponse(ctx, http.StatusConflict, "answer_already_exists", nil)
+	case errors.Is(err, examservice.ErrInvalidStatus):
+		ErrorResponse(ctx, http.StatusBadRequest, "invalid_status_value", nil)
+	case errors.Is(err, examservice.ErrInvalidOption):
+		ErrorResponse(ctx, http.StatusBadRequest, "invalid_option", err)
+	case errors.Is(err, examservice.ErrNoUpdate):
+		ErrorResponse(ctx, http.StatusBadRequest, "no_update_fields_provided", nil)
+	case errors.Is(err, examservice.ErrPartNotFound):
+		ErrorResponse(ctx, http.StatusNotFound, "part_not_found", err)
+	case errors.Is(err, examservice.ErrPartEmpty):
+		ErrorResponse(ctx, http.StatusBadRequest, "part_has_no_questions", err)
+	case errors.Is(err, examservice.ErrNotInPart):
+		ErrorResponse(ctx, http.StatusBadRequest, "question_not_in_part", err)
+	case errors.Is(err, examservice.ErrAnswerRevealed):
+		ErrorResponse(ctx, http.StatusForbidden, "answer_locked_after_transcript", err)
+	default:
+		ErrorResponse(ctx, http.StatusInternalServerError, message, err)
+	}
+}
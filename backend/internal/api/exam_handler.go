@@ -8,6 +8,7 @@ import (
 	"github.com/gin-gonic/gin"
 	db "github.com/toeic-app/internal/db/sqlc"
 	apperrors "github.com/toeic-app/internal/errors"
+	"github.com/toeic-app/internal/savedfilter"
 )
 
 // ExamResponse defines the structure for exam information returned to clients.
@@ -16,23 +17,30 @@ type ExamResponse struct {
 	Title            string `json:"title"`
 	TimeLimitMinutes int32  `json:"time_limit_minutes"`
 	IsUnlocked       bool   `json:"is_unlocked"`
+	IsPremium        bool   `json:"is_premium"` // Requires the premium_exams entitlement
+	// Learners can reveal the transcripts of listening questions after answering
+	TranscriptsEnabled bool `json:"transcripts_enabled"`
 }
 
 // NewExamResponse creates an ExamResponse from a db.Exam model
 func NewExamResponse(exam db.Exam) ExamResponse {
 	return ExamResponse{
-		ExamID:           exam.ExamID,
-		Title:            exam.Title,
-		TimeLimitMinutes: exam.TimeLimitMinutes,
-		IsUnlocked:       exam.IsUnlocked,
+		ExamID:             exam.ExamID,
+		Title:              exam.Title,
+		TimeLimitMinutes:   exam.TimeLimitMinutes,
+		IsUnlocked:         exam.IsUnlocked,
+		IsPremium:          exam.IsPremium,
+		TranscriptsEnabled: exam.TranscriptsEnabled,
 	}
 }
 
 // createExamRequest defines the structure for creating a new exam
 type createExamRequest struct {
-	Title            string `json:"title" binding:"required"`
-	TimeLimitMinutes int32  `json:"time_limit_minutes" binding:"required,min=1"`
-	IsUnlocked       bool   `json:"is_unlocked"`
+	Title              string `json:"title" binding:"required"`
+	TimeLimitMinutes   int32  `json:"time_limit_minutes" binding:"required,min=1"`
+	IsUnlocked         bool   `json:"is_unlocked"`
+	IsPremium          bool   `json:"is_premium"`
+	TranscriptsEnabled bool   `json:"transcripts_enabled"`
 }
 
 // @Summary     Create a new exam
@@ -71,9 +79,11 @@ func (server *Server) createExam(ctx *gin.Context) {
 	}
 
 	arg := db.CreateExamParams{
-		Title:            req.Title,
-		TimeLimitMinutes: req.TimeLimitMinutes,
-		IsUnlocked:       req.IsUnlocked,
+		Title:              req.Title,
+		TimeLimitMinutes:   req.TimeLimitMinutes,
+		IsUnlocked:         req.IsUnlocked,
+		IsPremium:          req.IsPremium,
+		TranscriptsEnabled: req.TranscriptsEnabled,
 	}
 
 	exam, err := server.store.CreateExam(ctx, arg)
@@ -140,17 +150,23 @@ func (server *Server) getExam(ctx *gin.Context) {
 type listExamsRequest struct {
 	Limit  int32 `form:"limit,default=10" binding:"min=1,max=100"`
 	Offset int32 `form:"offset,default=0" binding:"min=0"`
+	listFilterQuery
 }
 
 // @Summary     List exams
-// @Description Get a list of all exams with pagination
+// @Description Get a list of all exams with pagination, optionally filtered by a saved filter and the filter fields overriding it
 // @Tags        exams
 // @Accept      json
 // @Produce     json
 // @Param       limit query int false "Limit" default(10)
 // @Param       offset query int false "Offset" default(0)
+// @Param       filter_id query int false "Saved filter ID"
+// @Param       query query string false "Substring of the exam title"
+// @Param       premium query bool false "Premium exams only, or free ones only"
+// @Param       unlocked query bool false "Unlocked exams only, or locked ones only"
 // @Success     200 {object} Response{data=[]ExamResponse} "Exams retrieved successfully"
-// @Failure     400 {object} Response "Invalid query parameters"
+// @Failure     400 {object} Response "Invalid query parameters or filter"
+// @Failure     404 {object} Response "Saved filter not found"
 // @Failure     500 {object} Response "Failed to retrieve exams"
 // @Security    ApiKeyAuth
 // @Router      /api/v1/exams [get]
@@ -161,7 +177,17 @@ func (server *Server) listExams(ctx *gin.Context) {
 		return
 	}
 
-	exams, err := server.store.ListExams(ctx)
+	criteria, ok := server.listFilter(ctx, savedfilter.ResourceExams, req.listFilterQuery)
+	if !ok {
+		return
+	}
+	var exams []db.Exam
+	var err error
+	if criteria.IsZero() {
+		exams, err = server.store.ListExams(ctx)
+	} else {
+		exams, err = server.store.ListExamsFiltered(ctx, criteria.ExamParams())
+	}
 	if err != nil {
 		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve exams", err)
 		return
@@ -180,9 +206,11 @@ func (server *Server) listExams(ctx *gin.Context) {
 
 // updateExamRequest defines the structure for updating an existing exam
 type updateExamRequest struct {
-	Title            *string `json:"title,omitempty"`
-	TimeLimitMinutes *int32  `json:"time_limit_minutes,omitempty" binding:"omitempty,min=1"`
-	IsUnlocked       *bool   `json:"is_unlocked,omitempty"`
+	Title              *string `json:"title,omitempty"`
+	TimeLimitMinutes   *int32  `json:"time_limit_minutes,omitempty" binding:"omitempty,min=1"`
+	IsUnlocked         *bool   `json:"is_unlocked,omitempty"`
+	IsPremium          *bool   `json:"is_premium,omitempty"`
+	TranscriptsEnabled *bool   `json:"transcripts_enabled,omitempty"`
 }
 
 // @Summary     Update an exam
@@ -224,10 +252,12 @@ func (server *Server) updateExam(ctx *gin.Context) {
 
 	// Prepare update parameters
 	arg := db.UpdateExamParams{
-		ExamID:           int32(examID),
-		Title:            existingExam.Title,
-		TimeLimitMinutes: existingExam.TimeLimitMinutes,
-		IsUnlocked:       existingExam.IsUnlocked,
+		ExamID:             int32(examID),
+		Title:              existingExam.Title,
+		TimeLimitMinutes:   existingExam.TimeLimitMinutes,
+		IsUnlocked:         existingExam.IsUnlocked,
+		IsPremium:          existingExam.IsPremium,
+		TranscriptsEnabled: existingExam.TranscriptsEnabled,
 	}
 
 	// Update only provided fields
@@ -240,6 +270,12 @@ func (server *Server) updateExam(ctx *gin.Context) {
 	if req.IsUnlocked != nil {
 		arg.IsUnlocked = *req.IsUnlocked
 	}
+	if req.IsPremium != nil {
+		arg.IsPremium = *req.IsPremium
+	}
+	if req.TranscriptsEnabled != nil {
+		arg.TranscriptsEnabled = *req.TranscriptsEnabled
+	}
 
 	exam, err := server.store.UpdateExam(ctx, arg)
 	if err != nil {
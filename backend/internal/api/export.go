@@ -0,0 +1,57 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/export"
+	"github.com/toeic-app/internal/logger"
+)
+
+// exportBatchSize is how many rows are read from the database at a time
+// while streaming an export
+const exportBatchSize = 500
+
+// exportFormat reads the format query parameter of listing endpoints. An
+// empty format means the usual JSON response. It responds and returns false
+// when the format is not supported.
+func exportFormat(ctx *gin.Context) (export.Format, bool) {
+	format, err := export.ParseFormat(ctx.Query("format"))
+	if err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, err.Error(), err)
+		return "", false
+	}
+	return format, true
+}
+
+// streamExport sends the rows produced by fill as a file download. fill
+// calls write once per row, so rows go to the client as they are read
+// instead of being collected first. An error before anything was sent is
+// reported as JSON; after that the download is cut short and logged.
+func streamExport(ctx *gin.Context, format export.Format, name string, header []string, fill func(write func(row []string) error) error) {
+	export.SetHeaders(ctx.Writer.Header(), format, name)
+	ctx.Status(http.StatusOK)
+
+	w, err := export.NewWriter(format, ctx.Writer, name)
+	if err == nil {
+		err = w.Write(header)
+	}
+	if err == nil {
+		err = fill(w.Write)
+	}
+	if err == nil {
+		err = w.Close()
+	}
+	if err == nil {
+		return
+	}
+
+	if !ctx.Writer.Written() {
+		ctx.Writer.Header().Del("Content-Type")
+		ctx.Writer.Header().Del("Content-Disposition")
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to export "+name, err)
+		return
+	}
+	logger.Error("Export of %s failed after it started streaming: %v", name, err)
+	ctx.Abort()
+}
@@ -0,0 +1,291 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/featureflags"
+	"github.com/toeic-app/internal/logger"
+	"github.com/toeic-app/internal/token"
+)
+
+// FeatureFlagResponse represents a feature flag with its targeting rules
+type FeatureFlagResponse struct {
+	Key               string   `json:"key"`
+	Description       string   `json:"description"`
+	Enabled           bool     `json:"enabled"`
+	RolloutPercentage int32    `json:"rollout_percentage"`
+	AllowedRoles      []string `json:"allowed_roles"`
+	AllowedUserIDs    []int32  `json:"allowed_user_ids"`
+	AllowedOrgs       []string `json:"allowed_orgs"`
+	CreatedAt         string   `json:"created_at"`
+	UpdatedAt         string   `json:"updated_at"`
+}
+
+// CreateFeatureFlagRequest defines the body for creating a feature flag
+type CreateFeatureFlagRequest struct {
+	Key               string   `json:"key" binding:"required,min=2,max=100"`
+	Description       string   `json:"description" binding:"max=500"`
+	Enabled           bool     `json:"enabled"`
+	RolloutPercentage int32    `json:"rollout_percentage" binding:"min=0,max=100"`
+	AllowedRoles      []string `json:"allowed_roles"`
+	AllowedUserIDs    []int32  `json:"allowed_user_ids"`
+	AllowedOrgs       []string `json:"allowed_orgs"`
+}
+
+// UpdateFeatureFlagRequest defines the body for updating a feature flag
+type UpdateFeatureFlagRequest struct {
+	Description       string   `json:"description" binding:"max=500"`
+	Enabled           bool     `json:"enabled"`
+	RolloutPercentage int32    `json:"rollout_percentage" binding:"min=0,max=100"`
+	AllowedRoles      []string `json:"allowed_roles"`
+	AllowedUserIDs    []int32  `json:"allowed_user_ids"`
+	AllowedOrgs       []string `json:"allowed_orgs"`
+}
+
+func toFeatureFlagResponse(flag featureflags.Flag) FeatureFlagResponse {
+	return FeatureFlagResponse{
+		Key:               flag.Key,
+		Description:       flag.Description,
+		Enabled:           flag.Enabled,
+		RolloutPercentage: flag.RolloutPercentage,
+		AllowedRoles:      flag.AllowedRoles,
+		AllowedUserIDs:    flag.AllowedUserIDs,
+		AllowedOrgs:       flag.AllowedOrgs,
+		CreatedAt:         flag.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:         flag.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// featureEvalContext builds the evaluation context for the authenticated user
+func (server *Server) featureEvalContext(ctx *gin.Context) featureflags.EvalContext {
+	evalCtx := featureflags.EvalContext{}
+
+	payload, exists := ctx.Get(AuthorizationPayloadKey)
+	if !exists {
+		return evalCtx
+	}
+	authPayload, ok := payload.(*token.Payload)
+	if !ok {
+		return evalCtx
+	}
+	evalCtx.UserID = authPayload.ID
+
+	roles, err := server.rbacService.GetUserRoles(ctx, authPayload.ID)
+	if err != nil {
+		logger.Warn("Failed to load roles for feature flag evaluation (user %d): %v", authPayload.ID, err)
+		return evalCtx
+	}
+	for _, role := range roles {
+		evalCtx.Roles = append(evalCtx.Roles, role.Name)
+	}
+
+	return evalCtx
+}
+
+// isFeatureEnabled reports whether a feature is on for the current request.
+// Flags listed in the FEATURE_FLAGS setting are on for everyone.
+func (server *Server) isFeatureEnabled(ctx *gin.Context, key string) bool {
+	if server.configReloader.Current().IsFeatureEnabled(key) {
+		return true
+	}
+	if server.featureFlags == nil {
+		return false
+	}
+	return server.featureFlags.IsEnabled(ctx, key, server.featureEvalContext(ctx))
+}
+
+// requireFeature hides a route behind a feature flag
+func (server *Server) requireFeature(key string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !server.isFeatureEnabled(ctx, key) {
+			ErrorResponse(ctx, http.StatusNotFound, "Feature not available", nil)
+			ctx.Abort()
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// @Summary     Get enabled features
+// @Description Evaluate all feature flags for the current user
+// @Tags        feature-flags
+// @Produce     json
+// @Success     200 {object} Response{data=map[string]bool} "Feature flags evaluated successfully"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     500 {object} Response "Server error"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/feature-flags [get]
+func (server *Server) getMyFeatureFlags(ctx *gin.Context) {
+	flags, err := server.featureFlags.ListFlags(ctx)
+	if err != nil {
+		logger.Error("Failed to list feature flags: %v", err)
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve feature flags", err)
+		return
+	}
+
+	evalCtx := server.featureEvalContext(ctx)
+	current := server.configReloader.Current()
+	result := make(map[string]bool, len(flags))
+	for _, flag := range flags {
+		result[flag.Key] = current.IsFeatureEnabled(flag.Key) || flag.Evaluate(evalCtx)
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Feature flags evaluated successfully", result)
+}
+
+// @Summary     List feature flags
+// @Description Get all feature flags with their targeting rules (admin only)
+// @Tags        feature-flags
+// @Produce     json
+// @Success     200 {object} Response{data=[]FeatureFlagResponse} "Feature flags retrieved successfully"
+// @Failure     403 {object} Response "Insufficient permissions"
+// @Failure     500 {object} Response "Server error"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/feature-flags [get]
+func (server *Server) listFeatureFlags(ctx *gin.Context) {
+	flags, err := server.featureFlags.ListFlags(ctx)
+	if err != nil {
+		logger.Error("Failed to list feature flags: %v", err)
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve feature flags", err)
+		return
+	}
+
+	responses := make([]FeatureFlagResponse, len(flags))
+	for i, flag := range flags {
+		responses[i] = toFeatureFlagResponse(flag)
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Feature flags retrieved successfully", responses)
+}
+
+// @Summary     Get feature flag
+// @Description Get a feature flag by key (admin only)
+// @Tags        feature-flags
+// @Produce     json
+// @Param       key path string true "Feature flag key"
+// @Success     200 {object} Response{data=FeatureFlagResponse} "Feature flag retrieved successfully"
+// @Failure     403 {object} Response "Insufficient permissions"
+// @Failure     404 {object} Response "Feature flag not found"
+// @Failure     500 {object} Response "Server error"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/feature-flags/{key} [get]
+func (server *Server) getFeatureFlag(ctx *gin.Context) {
+	flag, err := server.featureFlags.GetFlag(ctx, ctx.Param("key"))
+	if err != nil {
+		if errors.Is(err, featureflags.ErrFlagNotFound) {
+			ErrorResponse(ctx, http.StatusNotFound, "Feature flag not found", err)
+			return
+		}
+		logger.Error("Failed to get feature flag: %v", err)
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve feature flag", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Feature flag retrieved successfully", toFeatureFlagResponse(*flag))
+}
+
+// @Summary     Create feature flag
+// @Description Create a new feature flag (admin only)
+// @Tags        feature-flags
+// @Accept      json
+// @Produce     json
+// @Param       flag body CreateFeatureFlagRequest true "Feature flag"
+// @Success     201 {object} Response{data=FeatureFlagResponse} "Feature flag created successfully"
+// @Failure     400 {object} Response "Invalid request"
+// @Failure     403 {object} Response "Insufficient permissions"
+// @Failure     500 {object} Response "Server error"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/feature-flags [post]
+func (server *Server) createFeatureFlag(ctx *gin.Context) {
+	var req CreateFeatureFlagRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request parameters", err)
+		return
+	}
+
+	flag, err := server.featureFlags.CreateFlag(ctx, req.Key, featureflags.FlagInput{
+		Description:       req.Description,
+		Enabled:           req.Enabled,
+		RolloutPercentage: req.RolloutPercentage,
+		AllowedRoles:      req.AllowedRoles,
+		AllowedUserIDs:    req.AllowedUserIDs,
+		AllowedOrgs:       req.AllowedOrgs,
+	})
+	if err != nil {
+		logger.Error("Failed to create feature flag: %v", err)
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to create feature flag", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusCreated, "Feature flag created successfully", toFeatureFlagResponse(*flag))
+}
+
+// @Summary     Update feature flag
+// @Description Replace the state and targeting rules of a feature flag (admin only). Set enabled=false to kill a feature instantly.
+// @Tags        feature-flags
+// @Accept      json
+// @Produce     json
+// @Param       key path string true "Feature flag key"
+// @Param       flag body UpdateFeatureFlagRequest true "Feature flag"
+// @Success     200 {object} Response{data=FeatureFlagResponse} "Feature flag updated successfully"
+// @Failure     400 {object} Response "Invalid request"
+// @Failure     403 {object} Response "Insufficient permissions"
+// @Failure     404 {object} Response "Feature flag not found"
+// @Failure     500 {object} Response "Server error"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/feature-flags/{key} [put]
+func (server *Server) updateFeatureFlag(ctx *gin.Context) {
+	var req UpdateFeatureFlagRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request parameters", err)
+		return
+	}
+
+	flag, err := server.featureFlags.UpdateFlag(ctx, ctx.Param("key"), featureflags.FlagInput{
+		Description:       req.Description,
+		Enabled:           req.Enabled,
+		RolloutPercentage: req.RolloutPercentage,
+		AllowedRoles:      req.AllowedRoles,
+		AllowedUserIDs:    req.AllowedUserIDs,
+		AllowedOrgs:       req.AllowedOrgs,
+	})
+	if err != nil {
+		if errors.Is(err, featureflags.ErrFlagNotFound) {
+			ErrorResponse(ctx, http.StatusNotFound, "Feature flag not found", err)
+			return
+		}
+		logger.Error("Failed to update feature flag: %v", err)
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to update feature flag", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Feature flag updated successfully", toFeatureFlagResponse(*flag))
+}
+
+// @Summary     Delete feature flag
+// @Description Delete a feature flag (admin only)
+// @Tags        feature-flags
+// @Produce     json
+// @Param       key path string true "Feature flag key"
+// @Success     200 {object} Response "Feature flag deleted successfully"
+// @Failure     403 {object} Response "Insufficient permissions"
+// @Failure     404 {object} Response "Feature flag not found"
+// @Failure     500 {object} Response "Server error"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/feature-flags/{key} [delete]
+func (server *Server) deleteFeatureFlag(ctx *gin.Context) {
+	if err := server.featureFlags.DeleteFlag(ctx, ctx.Param("key")); err != nil {
+		if errors.Is(err, featureflags.ErrFlagNotFound) {
+			ErrorResponse(ctx, http.StatusNotFound, "Feature flag not found", err)
+			return
+		}
+		logger.Error("Failed to delete feature flag: %v", err)
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to delete feature flag", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Feature flag deleted successfully", nil)
+}
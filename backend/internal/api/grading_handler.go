@@ -0,0 +1,219 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/grading"
+	"github.com/toeic-app/internal/token"
+)
+
+type listGradingQueueQuery struct {
+	OrganizationID int32 `form:"organization_id" binding:"min=0"`
+	Limit          int32 `form:"limit,default=20" binding:"min=1,max=100"`
+	Offset         int32 `form:"offset" binding:"min=0"`
+}
+
+type listSpeakingGradesQuery struct {
+	Limit  int32 `form:"limit,default=20" binding:"min=1,max=100"`
+	Offset int32 `form:"offset" binding:"min=0"`
+}
+
+// gradeSpeakingTurnRequest scores a claimed turn against the rubric
+type gradeSpeakingTurnRequest struct {
+	Scores  map[string]int `json:"scores" binding:"required"`
+	Comment string         `json:"comment" binding:"max=2000" example:"Clear answer; work on linking words."`
+}
+
+// @Summary     Speaking grading rubric
+// @Description Returns the criteria teachers score speaking turns against. Requires speaking.evaluate.
+// @Tags        speaking
+// @Produce     json
+// @Success     200 {object} Response{data=[]grading.Criterion} "Grading rubric retrieved successfully"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     403 {object} Response "Permission denied"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/speaking/grading/rubric [get]
+func (server *Server) getSpeakingGradingRubric(ctx *gin.Context) {
+	SuccessResponse(ctx, http.StatusOK, "Grading rubric retrieved successfully", grading.Rubric)
+}
+
+// @Summary     Speaking grading queue
+// @Description Lists recorded speaking turns of learners in my organizations that are not graded yet, oldest first. Turns another teacher has claimed are hidden until the claim is released or expires. Requires speaking.evaluate.
+// @Tags        speaking
+// @Produce     json
+// @Param       organization_id query int false "Only learners of this organization (LTI platform ID)"
+// @Param       limit query int false "Maximum turns" default(20)
+// @Param       offset query int false "Turns to skip" default(0)
+// @Success     200 {object} Response{data=[]grading.QueueItem} "Grading queue retrieved successfully"
+// @Failure     400 {object} Response "Invalid query parameters"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     403 {object} Response "Permission denied"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/speaking/grading/queue [get]
+func (server *Server) listSpeakingGradingQueue(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	var query listGradingQueueQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	items, total, err := server.grading.Queue(ctx, authPayload.ID, query.OrganizationID, query.Limit, query.Offset)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to list grading queue", err)
+		return
+	}
+
+	PaginatedResponse(ctx, http.StatusOK, "Grading queue retrieved successfully", items,
+		NewPagination(query.Limit, query.Offset, len(items)).WithTotal(total))
+}
+
+// @Summary     Claim a speaking turn
+// @Description Reserves a turn so no other teacher grades it. The claim expires after a configured time; claiming again extends it. Requires speaking.evaluate.
+// @Tags        speaking
+// @Produce     json
+// @Param       id path int true "Speaking turn ID"
+// @Success     200 {object} Response{data=grading.Claim} "Speaking turn claimed successfully"
+// @Failure     400 {object} Response "Invalid speaking turn ID"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     403 {object} Response "Permission denied"
+// @Failure     404 {object} Response "Speaking turn not found"
+// @Failure     409 {object} Response "Speaking turn is already claimed or graded"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/speaking/grading/turns/{id}/claim [post]
+func (server *Server) claimSpeakingTurn(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid speaking turn ID", err)
+		return
+	}
+
+	claim, err := server.grading.Claim(ctx, authPayload.ID, int32(id))
+	if err != nil {
+		switch {
+		case errors.Is(err, grading.ErrTurnNotFound):
+			ErrorResponse(ctx, http.StatusNotFound, "Speaking turn not found", err)
+		case errors.Is(err, grading.ErrAlreadyClaimed):
+			ErrorResponse(ctx, http.StatusConflict, "Speaking turn is already claimed or graded", err)
+		default:
+			ErrorResponse(ctx, http.StatusInternalServerError, "Failed to claim speaking turn", err)
+		}
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Speaking turn claimed successfully", claim)
+}
+
+// @Summary     Release a speaking turn
+// @Description Returns a turn I claimed to the queue without grading it. Requires speaking.evaluate.
+// @Tags        speaking
+// @Produce     json
+// @Param       id path int true "Speaking turn ID"
+// @Success     200 {object} Response "Speaking turn released successfully"
+// @Failure     400 {object} Response "Invalid speaking turn ID"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     403 {object} Response "Permission denied"
+// @Failure     409 {object} Response "Speaking turn is not claimed by you"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/speaking/grading/turns/{id}/claim [delete]
+func (server *Server) releaseSpeakingTurn(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid speaking turn ID", err)
+		return
+	}
+
+	if err := server.grading.Release(ctx, authPayload.ID, int32(id)); err != nil {
+		if errors.Is(err, grading.ErrNotClaimed) {
+			ErrorResponse(ctx, http.StatusConflict, "Speaking turn is not claimed by you", err)
+			return
+		}
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to release speaking turn", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Speaking turn released successfully", nil)
+}
+
+// @Summary     Grade a speaking turn
+// @Description Scores a turn I claimed against the rubric, with an optional comment, and notifies the learner. Every rubric criterion must be scored. Requires speaking.evaluate.
+// @Tags        speaking
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Speaking turn ID"
+// @Param       grade body gradeSpeakingTurnRequest true "Rubric scores and comment"
+// @Success     200 {object} Response{data=grading.Grade} "Speaking turn graded successfully"
+// @Failure     400 {object} Response "Invalid request parameters"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     403 {object} Response "Permission denied"
+// @Failure     409 {object} Response "Speaking turn is not claimed by you"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/speaking/grading/turns/{id}/grade [post]
+func (server *Server) gradeSpeakingTurn(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid speaking turn ID", err)
+		return
+	}
+
+	var req gradeSpeakingTurnRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request parameters", err)
+		return
+	}
+
+	grade, err := server.grading.Grade(ctx, authPayload.ID, int32(id), req.Scores, req.Comment)
+	if err != nil {
+		switch {
+		case errors.Is(err, grading.ErrInvalidScores):
+			ErrorResponse(ctx, http.StatusBadRequest, err.Error(), err)
+		case errors.Is(err, grading.ErrNotClaimed):
+			ErrorResponse(ctx, http.StatusConflict, "Speaking turn is not claimed by you", err)
+		default:
+			ErrorResponse(ctx, http.StatusInternalServerError, "Failed to grade speaking turn", err)
+		}
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Speaking turn graded successfully", grade)
+}
+
+// @Summary     List my speaking grades
+// @Description Lists teacher grades of my speaking turns, newest first
+// @Tags        speaking
+// @Produce     json
+// @Param       limit query int false "Maximum grades" default(20)
+// @Param       offset query int false "Grades to skip" default(0)
+// @Success     200 {object} Response{data=[]grading.Grade} "Speaking grades retrieved successfully"
+// @Failure     400 {object} Response "Invalid query parameters"
+// @Failure     401 {object} Response "Unauthorized"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/speaking/grades [get]
+func (server *Server) listMySpeakingGrades(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	var query listSpeakingGradesQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	grades, total, err := server.grading.Grades(ctx, authPayload.ID, query.Limit, query.Offset)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to list speaking grades", err)
+		return
+	}
+
+	PaginatedResponse(ctx, http.StatusOK, "Speaking grades retrieved successfully", grades,
+		NewPagination(query.Limit, query.Offset, len(grades)).WithTotal(total))
+}
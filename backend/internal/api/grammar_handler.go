@@ -10,29 +10,31 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/authoring"
 	db "github.com/toeic-app/internal/db/sqlc"
 	"github.com/toeic-app/internal/logger"
+	"github.com/toeic-app/internal/related"
 )
 
 // GrammarExample represents an example in grammar content
 // swagger:model
 type GrammarExample struct {
-	Example *string `json:"e,omitempty"`
+	Example *string `json:"e,omitempty" sanitize:"rich_text"`
 }
 
 // GrammarContentElement represents individual content element in grammar
 // swagger:model
 type GrammarContentElement struct {
-	Content  *string          `json:"c,omitempty"`
+	Content  *string          `json:"c,omitempty" sanitize:"rich_text"`
 	Examples []GrammarExample `json:"e,omitempty"`
-	Formulas []string         `json:"f,omitempty"`
+	Formulas []string         `json:"f,omitempty" sanitize:"plain"`
 }
 
 // GrammarContent represents content section in grammar
 // swagger:model
 type GrammarContent struct {
 	Content  []GrammarContentElement `json:"content,omitempty"`
-	SubTitle *string                 `json:"sub_title,omitempty"`
+	SubTitle *string                 `json:"sub_title,omitempty" sanitize:"plain"`
 }
 
 // GrammarResponse defines the structure for grammar information returned to clients.
@@ -45,6 +47,8 @@ type GrammarResponse struct {
 	GrammarKey string           `json:"grammar_key"`
 	Related    []int32          `json:"related"`
 	Contents   []GrammarContent `json:"contents,omitempty"`
+	// RelatedContent is only filled on the grammar detail endpoint
+	RelatedContent []related.Item `json:"related_content,omitempty"`
 }
 
 // NewGrammarResponse creates a GrammarResponse from db.Grammar model
@@ -73,7 +77,7 @@ func NewGrammarResponse(grammar db.Grammar) GrammarResponse {
 // swagger:model
 type createGrammarRequest struct {
 	Level      int32            `json:"level" binding:"required,min=1"`
-	Title      string           `json:"title" binding:"required"`
+	Title      string           `json:"title" binding:"required" sanitize:"plain"`
 	Tag        []string         `json:"tag" binding:"required"`
 	GrammarKey string           `json:"grammar_key" binding:"required"`
 	Related    []int32          `json:"related" binding:"required"`
@@ -97,6 +101,7 @@ func (server *Server) createGrammar(ctx *gin.Context) {
 		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
 		return
 	}
+	server.sanitizer.Struct(&req)
 
 	arg := db.CreateGrammarParams{
 		Level:      req.Level,
@@ -131,7 +136,6 @@ type getGrammarRequest struct {
 // @Failure     400 {object} Response "Invalid grammar ID"
 // @Failure     404 {object} Response "Grammar not found"
 // @Failure     500 {object} Response "Failed to retrieve grammar"
-// @Security    ApiKeyAuth
 // @Router      /api/v1/grammars/{id} [get]
 func (server *Server) getGrammar(ctx *gin.Context) {
 	var req getGrammarRequest
@@ -141,6 +145,10 @@ func (server *Server) getGrammar(ctx *gin.Context) {
 	}
 
 	grammar, err := server.store.GetGrammar(ctx, req.ID)
+	if err == nil && grammar.Status != authoring.StatusPublished {
+		// Drafts are only visible through the authoring endpoints
+		err = sql.ErrNoRows
+	}
 	if err != nil {
 		if err == sql.ErrNoRows {
 			ErrorResponse(ctx, http.StatusNotFound, "Grammar not found", err)
@@ -150,7 +158,9 @@ func (server *Server) getGrammar(ctx *gin.Context) {
 		return
 	}
 
-	SuccessResponse(ctx, http.StatusOK, "Grammar retrieved successfully", NewGrammarResponse(grammar))
+	response := NewGrammarResponse(grammar)
+	response.RelatedContent = server.relatedContent(ctx, related.TypeGrammar, grammar.ID)
+	SuccessResponse(ctx, http.StatusOK, "Grammar retrieved successfully", response)
 }
 
 // listGrammarsRequest defines the structure for listing grammars with pagination.
@@ -169,7 +179,6 @@ type listGrammarsRequest struct {
 // @Success     200 {object} Response{data=[]GrammarResponse} "Grammars retrieved successfully"
 // @Failure     400 {object} Response "Invalid query parameters"
 // @Failure     500 {object} Response "Failed to retrieve grammars"
-// @Security    ApiKeyAuth
 // @Router      /api/v1/grammars [get]
 func (server *Server) listGrammars(ctx *gin.Context) {
 	var req listGrammarsRequest
@@ -198,7 +207,7 @@ func (server *Server) listGrammars(ctx *gin.Context) {
 		grammarResponses = []GrammarResponse{}
 	}
 
-	SuccessResponse(ctx, http.StatusOK, "Grammars retrieved successfully", grammarResponses)
+	PaginatedResponse(ctx, http.StatusOK, "Grammars retrieved successfully", grammarResponses, NewPagination(req.Limit, req.Offset, len(grammarResponses)))
 }
 
 // updateGrammarRequest defines the structure for updating an existing grammar entry.
@@ -206,7 +215,7 @@ func (server *Server) listGrammars(ctx *gin.Context) {
 type updateGrammarRequest struct {
 	ID         int32            `json:"id" binding:"required,min=1"`
 	Level      int32            `json:"level" binding:"omitempty,min=1"`
-	Title      string           `json:"title" binding:"omitempty"`
+	Title      string           `json:"title" binding:"omitempty" sanitize:"plain"`
 	Tag        []string         `json:"tag" binding:"omitempty"`
 	GrammarKey string           `json:"grammar_key" binding:"omitempty"`
 	Related    []int32          `json:"related" binding:"omitempty"`
@@ -253,6 +262,7 @@ func (server *Server) updateGrammar(ctx *gin.Context) {
 		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
 		return
 	}
+	server.sanitizer.Struct(&req)
 
 	// Ensure the ID in the URI matches the ID in the request body if provided
 	if req.ID != 0 && req.ID != int32(uriID) {
@@ -354,7 +364,6 @@ func (server *Server) deleteGrammar(ctx *gin.Context) {
 // @Produce     json
 // @Success     200 {object} Response{data=GrammarResponse} "Random grammar retrieved successfully"
 // @Failure     500 {object} Response "Failed to retrieve random grammar"
-// @Security    ApiKeyAuth
 // @Router      /api/v1/grammars/random [get]
 func (server *Server) getRandomGrammar(ctx *gin.Context) {
 	grammar, err := server.store.GetRandomGrammar(ctx)
@@ -384,7 +393,6 @@ type listGrammarsByLevelRequest struct {
 // @Success     200 {object} Response{data=[]GrammarResponse} "Grammars retrieved successfully"
 // @Failure     400 {object} Response "Invalid query parameters"
 // @Failure     500 {object} Response "Failed to retrieve grammars by level"
-// @Security    ApiKeyAuth
 // @Router      /api/v1/grammars/level [get]
 func (server *Server) listGrammarsByLevel(ctx *gin.Context) {
 	var req listGrammarsByLevelRequest
@@ -414,7 +422,7 @@ func (server *Server) listGrammarsByLevel(ctx *gin.Context) {
 		grammarResponses = []GrammarResponse{}
 	}
 
-	SuccessResponse(ctx, http.StatusOK, "Grammars retrieved successfully for the level", grammarResponses)
+	PaginatedResponse(ctx, http.StatusOK, "Grammars retrieved successfully for the level", grammarResponses, NewPagination(req.Limit, req.Offset, len(grammarResponses)))
 }
 
 // listGrammarsByTagRequest defines the structure for listing grammars by tag with pagination.
@@ -435,7 +443,6 @@ type listGrammarsByTagRequest struct {
 // @Success     200 {object} Response{data=[]GrammarResponse} "Grammars retrieved successfully"
 // @Failure     400 {object} Response "Invalid query parameters"
 // @Failure     500 {object} Response "Failed to retrieve grammars by tag"
-// @Security    ApiKeyAuth
 // @Router      /api/v1/grammars/tag [get]
 func (server *Server) listGrammarsByTag(ctx *gin.Context) {
 	var req listGrammarsByTagRequest
@@ -465,7 +472,7 @@ func (server *Server) listGrammarsByTag(ctx *gin.Context) {
 		grammarResponses = []GrammarResponse{}
 	}
 
-	SuccessResponse(ctx, http.StatusOK, "Grammars retrieved successfully for the tag", grammarResponses)
+	PaginatedResponse(ctx, http.StatusOK, "Grammars retrieved successfully for the tag", grammarResponses, NewPagination(req.Limit, req.Offset, len(grammarResponses)))
 }
 
 // searchGrammarsRequest defines the structure for searching grammars with pagination.
@@ -486,7 +493,6 @@ type searchGrammarsRequest struct {
 // @Success     200 {object} Response{data=[]GrammarResponse} "Grammars retrieved successfully"
 // @Failure     400 {object} Response "Invalid query parameters"
 // @Failure     500 {object} Response "Failed to search grammars"
-// @Security    ApiKeyAuth
 // @Router      /api/v1/grammars/search [get]
 func (server *Server) searchGrammars(ctx *gin.Context) {
 	var req searchGrammarsRequest
@@ -538,7 +544,7 @@ func (server *Server) searchGrammars(ctx *gin.Context) {
 		}()
 	}
 
-	SuccessResponse(ctx, http.StatusOK, "Grammar search completed successfully", grammarResponses)
+	PaginatedResponse(ctx, http.StatusOK, "Grammar search completed successfully", grammarResponses, NewPagination(req.Limit, req.Offset, len(grammarResponses)))
 }
 
 // batchGetGrammarsRequest defines the structure for batch getting grammars by IDs.
@@ -555,7 +561,6 @@ type batchGetGrammarsRequest struct {
 // @Success 200 {object} Response{data=[]GrammarResponse} "Grammars retrieved successfully"
 // @Failure 400 {object} Response "Invalid request body"
 // @Failure 500 {object} Response "Failed to retrieve grammars"
-// @Security    ApiKeyAuth
 // @Router /api/v1/grammars/batch [post]
 func (server *Server) batchGetGrammars(ctx *gin.Context) {
 	var req batchGetGrammarsRequest
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/toeic-app/internal/logger"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// newHTTPServer builds the http.Server from configuration: timeouts, header
+// size limit, TLS (certificate files or Let's Encrypt autocert) and HTTP/2.
+func (server *Server) newHTTPServer(address string) (*http.Server, error) {
+	cfg := server.config
+
+	httpServer := &http.Server{
+		Addr:              address,
+		Handler:           server.router,
+		ReadTimeout:       cfg.ServerReadTimeout,
+		ReadHeaderTimeout: cfg.ServerReadHeaderTimeout,
+		WriteTimeout:      cfg.ServerWriteTimeout,
+		IdleTimeout:       cfg.ServerIdleTimeout,
+		MaxHeaderBytes:    cfg.ServerMaxHeaderBytes,
+	}
+
+	h2s := &http2.Server{
+		MaxConcurrentStreams: cfg.HTTP2MaxStreams,
+		IdleTimeout:          time.Duration(cfg.HTTP2IdleTimeout) * time.Second,
+		MaxReadFrameSize:     1048576, // 1MB
+	}
+
+	if !cfg.TLSEnabled {
+		if cfg.HTTP2Enabled {
+			// HTTP/2 without TLS (h2c - HTTP/2 Cleartext)
+			logger.Warn("HTTP/2 without TLS is enabled (h2c). This provides limited functionality and is not recommended for production.")
+			httpServer.Handler = h2c.NewHandler(server.router, h2s)
+		}
+		return httpServer, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12, // HTTP/2 requires TLS 1.2+
+	}
+	if cfg.TLSAutocertEnabled {
+		manager := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(splitDomains(cfg.TLSAutocertDomains)...),
+			Cache:      autocert.DirCache(cfg.TLSAutocertCacheDir),
+		}
+		tlsConfig.GetCertificate = manager.GetCertificate
+		logger.Info("Using automatic TLS certificates for: %s", cfg.TLSAutocertDomains)
+	}
+
+	if cfg.HTTP2Enabled {
+		tlsConfig.NextProtos = []string{"h2", "http/1.1"} // Enable HTTP/2 and fallback to HTTP/1.1
+		httpServer.TLSConfig = tlsConfig
+		if err := http2.ConfigureServer(httpServer, h2s); err != nil {
+			return nil, fmt.Errorf("failed to configure HTTP/2: %w", err)
+		}
+	} else {
+		tlsConfig.NextProtos = []string{"http/1.1"}
+		httpServer.TLSConfig = tlsConfig
+		// An empty map stops net/http from enabling HTTP/2 on its own
+		httpServer.TLSNextProto = make(map[string]func(*http.Server, *tls.Conn, http.Handler))
+	}
+
+	if cfg.TLSAutocertEnabled {
+		// Answer TLS-ALPN-01 challenges on the same listener
+		tlsConfig.NextProtos = append(tlsConfig.NextProtos, acme.ALPNProto)
+	}
+
+	return httpServer, nil
+}
+
+// listenAndServe runs the server over TLS or plain HTTP depending on configuration
+func (server *Server) listenAndServe(httpServer *http.Server) error {
+	cfg := server.config
+	protocol := "HTTP/1.1"
+	if cfg.HTTP2Enabled {
+		protocol = "HTTP/2"
+	}
+
+	if !cfg.TLSEnabled {
+		logger.Info("Starting HTTP server (%s) on %s", protocol, httpServer.Addr)
+		return httpServer.ListenAndServe()
+	}
+
+	logger.Info("Starting HTTPS server (%s) on %s", protocol, httpServer.Addr)
+	if cfg.TLSAutocertEnabled {
+		// Certificates come from TLSConfig.GetCertificate
+		return httpServer.ListenAndServeTLS("", "")
+	}
+
+	logger.Info("Using TLS certificate: %s", cfg.TLSCertFile)
+	logger.Info("Using TLS key: %s", cfg.TLSKeyFile)
+	return httpServer.ListenAndServeTLS(cfg.TLSCertFile, cfg.TLSKeyFile)
+}
+
+// splitDomains parses a comma-separated domain list
+func splitDomains(value string) []string {
+	var domains []string
+	for _, domain := range strings.Split(value, ",") {
+		if domain = strings.TrimSpace(domain); domain != "" {
+			domains = append(domains, domain)
+		}
+	}
+	return domains
+}
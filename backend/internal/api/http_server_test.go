@@ -0,0 +1,122 @@
+package api
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toeic-app/internal/config"
+)
+
+func newTestHTTPServer(t *testing.T, cfg config.Config) (*http.Server, string) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/fast", func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+	router.GET("/slow", func(c *gin.Context) {
+		time.Sleep(300 * time.Millisecond)
+		c.String(http.StatusOK, "late")
+	})
+
+	server := &Server{config: cfg, router: router}
+	httpServer, err := server.newHTTPServer("127.0.0.1:0")
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go httpServer.Serve(listener)
+	t.Cleanup(func() { httpServer.Close() })
+
+	return httpServer, "http://" + listener.Addr().String()
+}
+
+func testServerConfig() config.Config {
+	return config.Config{
+		ServerReadTimeout:       time.Second,
+		ServerReadHeaderTimeout: 100 * time.Millisecond,
+		ServerWriteTimeout:      100 * time.Millisecond,
+		ServerIdleTimeout:       time.Second,
+		ServerMaxHeaderBytes:    1024,
+	}
+}
+
+func TestHTTPServerAppliesConfig(t *testing.T) {
+	cfg := testServerConfig()
+	httpServer, _ := newTestHTTPServer(t, cfg)
+
+	assert.Equal(t, cfg.ServerReadTimeout, httpServer.ReadTimeout)
+	assert.Equal(t, cfg.ServerReadHeaderTimeout, httpServer.ReadHeaderTimeout)
+	assert.Equal(t, cfg.ServerWriteTimeout, httpServer.WriteTimeout)
+	assert.Equal(t, cfg.ServerIdleTimeout, httpServer.IdleTimeout)
+	assert.Equal(t, cfg.ServerMaxHeaderBytes, httpServer.MaxHeaderBytes)
+	assert.Nil(t, httpServer.TLSConfig)
+}
+
+func TestHTTPServerWriteTimeout(t *testing.T) {
+	_, baseURL := newTestHTTPServer(t, testServerConfig())
+
+	resp, err := http.Get(baseURL + "/fast")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// The handler outlives the write timeout, so the connection is dropped
+	_, err = http.Get(baseURL + "/slow")
+	assert.Error(t, err)
+}
+
+func TestHTTPServerReadHeaderTimeout(t *testing.T) {
+	_, baseURL := newTestHTTPServer(t, testServerConfig())
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(baseURL, "http://"))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// Send an incomplete request and never finish the headers
+	_, err = conn.Write([]byte("GET /fast HTTP/1.1\r\nHost: localhost\r\n"))
+	require.NoError(t, err)
+
+	start := time.Now()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, err = bufio.NewReader(conn).ReadString('\n')
+	assert.Error(t, err, "server should close a connection that stalls while sending headers")
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestHTTPServerMaxHeaderBytes(t *testing.T) {
+	_, baseURL := newTestHTTPServer(t, testServerConfig())
+
+	req, err := http.NewRequest(http.MethodGet, baseURL+"/fast", nil)
+	require.NoError(t, err)
+	req.Header.Set("X-Large", strings.Repeat("a", 8192))
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusRequestHeaderFieldsTooLarge, resp.StatusCode)
+}
+
+func TestHTTPServerTLSProtocols(t *testing.T) {
+	cfg := testServerConfig()
+	cfg.TLSEnabled = true
+
+	server := &Server{config: cfg, router: gin.New()}
+	httpServer, err := server.newHTTPServer("127.0.0.1:0")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"http/1.1"}, httpServer.TLSConfig.NextProtos)
+	assert.NotNil(t, httpServer.TLSNextProto, "HTTP/2 must stay off when disabled")
+
+	cfg.HTTP2Enabled = true
+	cfg.HTTP2MaxStreams = 100
+	server = &Server{config: cfg, router: gin.New()}
+	httpServer, err = server.newHTTPServer("127.0.0.1:0")
+	require.NoError(t, err)
+	assert.Contains(t, httpServer.TLSConfig.NextProtos, "h2")
+}
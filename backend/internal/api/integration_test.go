@@ -0,0 +1,1537 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toeic-app/internal/accesslog"
+	"github.com/toeic-app/internal/ai"
+	"github.com/toeic-app/internal/backup"
+	"github.com/toeic-app/internal/config"
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/drain"
+	"github.com/toeic-app/internal/grading"
+	"github.com/toeic-app/internal/pii"
+	"github.com/toeic-app/internal/playback"
+	"github.com/toeic-app/internal/portfolio"
+	"github.com/toeic-app/internal/proctoring"
+	"github.com/toeic-app/internal/rbac"
+	"github.com/toeic-app/internal/readonly"
+	"github.com/toeic-app/internal/util"
+)
+
+// integrationStore keeps the users, lockouts, sessions, writings, exam
+// attempts, words, portfolio share links, speaking grades, preferred
+// languages, backup catalog and data access log of handler integration
+// tests in memory
+type integrationStore struct {
+	db.Querier
+	mu              sync.Mutex
+	users           []db.User
+	lockouts        map[int32]db.AccountLockout
+	sessions        []db.UserSession
+	scorings        int
+	writings        []db.UserWriting
+	accessLogs      []db.ListDataAccessLogsBySubjectRow
+	subjects        []int32
+	attempts        []db.ExamAttempt
+	integrityEvents []db.CreateIntegrityEventsParams
+	answers         []db.ListUserAnswersByAttemptWithQuestionsRow
+	words           []db.ListWordsForLinkingRow
+	studySetWords   []db.ListStudySetWordsForUserRow
+	// questionAudio maps questions of exam 1 to their audio URL
+	questionAudio  map[int32]string
+	playbackTokens []db.AudioPlaybackToken
+	consents       []db.ExamProctoringConsent
+	photos         []db.ExamProctoringPhoto
+	shareLinks     []db.PortfolioShareLink
+	// speakingGrades are keyed by turn; turn 5 is a recording of user 1
+	speakingGrades map[int32]db.SpeakingGrade
+	// permissions are granted per user as "resource.action"
+	permissions map[int32][]string
+	// languages are the preferred languages of users
+	languages map[int32]string
+	// uploadUsage is the upload usage of users today
+	uploadUsage map[int32]db.UserUploadUsage
+	// flags are the feature flags
+	flags []db.FeatureFlag
+	// backups is the backup catalog
+	backups []db.Backup
+}
+
+func newIntegrationStore(t *testing.T, email, password string) *integrationStore {
+	hash, err := util.HashPassword(password)
+	require.NoError(t, err)
+	return &integrationStore{
+		users: []db.User{{
+			ID:           1,
+			Username:     "jane",
+			Email:        sql.NullString{String: email, Valid: true},
+			PasswordHash: hash,
+			CreatedAt:    time.Now(),
+		}},
+		lockouts: make(map[int32]db.AccountLockout),
+	}
+}
+
+func (s *integrationStore) GetUser(_ context.Context, id int32) (db.User, error) {
+	for _, user := range s.users {
+		if user.ID == id {
+			return user, nil
+		}
+	}
+	return db.User{}, sql.ErrNoRows
+}
+
+func (s *integrationStore) GetUserByEmail(_ context.Context, email sql.NullString) (db.User, error) {
+	for _, user := range s.users {
+		if user.Email.String == email.String {
+			return user, nil
+		}
+	}
+	return db.User{}, sql.ErrNoRows
+}
+
+func (s *integrationStore) ListUsersAfter(_ context.Context, arg db.ListUsersAfterParams) ([]db.User, error) {
+	var users []db.User
+	for _, user := range s.users {
+		if user.ID > arg.ID && len(users) < int(arg.Limit) {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+func (s *integrationStore) GetAccountLockout(_ context.Context, userID int32) (db.AccountLockout, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	row, ok := s.lockouts[userID]
+	if !ok {
+		return db.AccountLockout{}, sql.ErrNoRows
+	}
+	return row, nil
+}
+
+func (s *integrationStore) RecordFailedLogin(_ context.Context, userID int32) (db.AccountLockout, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	row := s.lockouts[userID]
+	row.UserID = userID
+	row.FailedAttempts++
+	s.lockouts[userID] = row
+	return row, nil
+}
+
+func (s *integrationStore) LockAccount(_ context.Context, arg db.LockAccountParams) (db.AccountLockout, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	row := s.lockouts[arg.UserID]
+	row.UserID, row.LockedUntil, row.Reason = arg.UserID, arg.LockedUntil, arg.Reason
+	s.lockouts[arg.UserID] = row
+	return row, nil
+}
+
+func (s *integrationStore) ResetFailedLogins(context.Context, int32) error {
+	return nil
+}
+
+func (s *integrationStore) CreateUserSession(_ context.Context, arg db.CreateUserSessionParams) (db.UserSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session := db.UserSession{ID: int64(len(s.sessions) + 1), UserID: arg.UserID, IpAddress: arg.IpAddress,
+		UserAgent: arg.UserAgent, CreatedAt: time.Now(), LastSeenAt: time.Now(), ExpiresAt: arg.ExpiresAt}
+	s.sessions = append(s.sessions, session)
+	return session, nil
+}
+
+func (s *integrationStore) DeleteStaleUserSessions(context.Context, db.DeleteStaleUserSessionsParams) (int64, error) {
+	return 0, nil
+}
+
+func (s *integrationStore) ListUserEntitlements(context.Context, int32) ([]string, error) {
+	return nil, nil
+}
+
+func (s *integrationStore) ConsumeEntitlementUsage(context.Context, db.ConsumeEntitlementUsageParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scorings++
+	return 1, nil
+}
+
+func (s *integrationStore) GetUserWriting(_ context.Context, id int32) (db.UserWriting, error) {
+	for _, writing := range s.writings {
+		if writing.ID == id {
+			return writing, nil
+		}
+	}
+	return db.UserWriting{}, sql.ErrNoRows
+}
+
+// Prompt 3 is a business email with a rubric of its own
+func (s *integrationStore) GetWritingPrompt(_ context.Context, id int32) (db.WritingPrompt, error) {
+	if id != 3 {
+		return db.WritingPrompt{}, sql.ErrNoRows
+	}
+	return db.WritingPrompt{ID: 3, PromptText: "Reply to your manager about the delayed shipment"}, nil
+}
+
+func (s *integrationStore) GetWritingRubricForPrompt(_ context.Context, promptID int32) (db.WritingRubric, error) {
+	if promptID != 3 {
+		return db.WritingRubric{}, sql.ErrNoRows
+	}
+	return db.WritingRubric{
+		ID:       1,
+		PromptID: sql.NullInt32{Int32: 3, Valid: true},
+		Name:     "Business email",
+		Criteria: json.RawMessage(`[{"key": "task_response", "name": "Task completion", "weight": 60},
+			{"key": "organization", "name": "Organization", "weight": 40}]`),
+	}, nil
+}
+
+func (s *integrationStore) CreateDataAccessLogs(_ context.Context, arg db.CreateDataAccessLogsParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, subject := range arg.SubjectUserIds {
+		s.subjects = append(s.subjects, subject)
+		s.accessLogs = append(s.accessLogs, db.ListDataAccessLogsBySubjectRow{
+			ID:             int64(len(s.accessLogs) + 1),
+			AccessorUserID: sql.NullInt32{Int32: arg.AccessorUserID, Valid: true},
+			ResourceType:   arg.ResourceTypes[i],
+			ResourceID:     arg.ResourceIds[i],
+			Purpose:        arg.Purpose,
+			AccessedAt:     time.Now(),
+		})
+	}
+	return nil
+}
+
+func (s *integrationStore) ListDataAccessLogsBySubject(_ context.Context, arg db.ListDataAccessLogsBySubjectParams) ([]db.ListDataAccessLogsBySubjectRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var rows []db.ListDataAccessLogsBySubjectRow
+	for i, subject := range s.subjects {
+		if subject == arg.SubjectUserID {
+			rows = append(rows, s.accessLogs[i])
+		}
+	}
+	return rows, nil
+}
+
+func (s *integrationStore) CountDataAccessLogsBySubject(ctx context.Context, subjectID int32) (int64, error) {
+	rows, err := s.ListDataAccessLogsBySubject(ctx, db.ListDataAccessLogsBySubjectParams{SubjectUserID: subjectID})
+	return int64(len(rows)), err
+}
+
+func (s *integrationStore) CheckUserPermission(_ context.Context, arg db.CheckUserPermissionParams) (bool, error) {
+	for _, permission := range s.permissions[arg.UserID] {
+		if permission == arg.Name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *integrationStore) CheckUserPermissionByResourceAction(ctx context.Context, arg db.CheckUserPermissionByResourceActionParams) (bool, error) {
+	return s.CheckUserPermission(ctx, db.CheckUserPermissionParams{UserID: arg.UserID, Name: arg.Resource + "." + arg.Action})
+}
+
+func (s *integrationStore) GetUserRoles(context.Context, int32) ([]db.Role, error) {
+	return nil, nil
+}
+
+func (s *integrationStore) ListFeatureFlags(context.Context) ([]db.FeatureFlag, error) {
+	return s.flags, nil
+}
+
+// UpsertBackup records a backup in the catalog
+func (s *integrationStore) UpsertBackup(_ context.Context, arg db.UpsertBackupParams) (db.Backup, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	backup := db.Backup{Filename: arg.Filename, Type: arg.Type, Status: arg.Status, Description: arg.Description, CreatedAt: time.Now()}
+	s.backups = append(s.backups, backup)
+	return backup, nil
+}
+
+// SearchBackups filters the backup catalog like the query does
+func (s *integrationStore) SearchBackups(_ context.Context, arg db.SearchBackupsParams) ([]db.Backup, error) {
+	var backups []db.Backup
+	for _, backup := range s.backups {
+		search := strings.ToLower(arg.Search)
+		if (arg.Status == "" || backup.Status == arg.Status) &&
+			(arg.Type == "" || backup.Type == arg.Type) &&
+			(!arg.CreatedFrom.Valid || !backup.CreatedAt.Before(arg.CreatedFrom.Time)) &&
+			(!arg.CreatedTo.Valid || backup.CreatedAt.Before(arg.CreatedTo.Time)) &&
+			(search == "" || strings.Contains(strings.ToLower(backup.Filename+" "+backup.Description), search)) {
+			backups = append(backups, backup)
+		}
+	}
+	return backups, nil
+}
+
+func (s *integrationStore) GetExamAttempt(_ context.Context, id int32) (db.ExamAttempt, error) {
+	for _, attempt := range s.attempts {
+		if attempt.AttemptID == id {
+			return attempt, nil
+		}
+	}
+	return db.ExamAttempt{}, sql.ErrNoRows
+}
+
+func (s *integrationStore) GetExamAttemptByUser(ctx context.Context, arg db.GetExamAttemptByUserParams) (db.ExamAttempt, error) {
+	attempt, err := s.GetExamAttempt(ctx, arg.AttemptID)
+	if err != nil || attempt.UserID != arg.UserID {
+		return db.ExamAttempt{}, sql.ErrNoRows
+	}
+	return attempt, nil
+}
+
+func (s *integrationStore) CreateIntegrityEvents(_ context.Context, arg db.CreateIntegrityEventsParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.integrityEvents = append(s.integrityEvents, arg)
+	return nil
+}
+
+func (s *integrationStore) SummarizeIntegrityEvents(_ context.Context, attemptID int32) ([]db.SummarizeIntegrityEventsRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var rows []db.SummarizeIntegrityEventsRow
+	for _, batch := range s.integrityEvents {
+		if batch.AttemptID != attemptID {
+			continue
+		}
+		for i, eventType := range batch.EventTypes {
+			rows = append(rows, db.SummarizeIntegrityEventsRow{
+				EventType:   eventType,
+				Events:      1,
+				DurationMs:  int64(batch.DurationsMs[i]),
+				PasteLength: int64(batch.PasteLengths[i]),
+			})
+		}
+	}
+	return rows, nil
+}
+
+func (s *integrationStore) ListIntegrityEvents(context.Context, db.ListIntegrityEventsParams) ([]db.ExamAttemptIntegrityEvent, error) {
+	return nil, nil
+}
+
+func (s *integrationStore) ListUserAnswersByAttemptWithQuestions(_ context.Context, attemptID int32) ([]db.ListUserAnswersByAttemptWithQuestionsRow, error) {
+	var rows []db.ListUserAnswersByAttemptWithQuestionsRow
+	for _, answer := range s.answers {
+		if answer.AttemptID == attemptID {
+			rows = append(rows, answer)
+		}
+	}
+	return rows, nil
+}
+
+func (s *integrationStore) ListWordsForLinking(context.Context) ([]db.ListWordsForLinkingRow, error) {
+	return s.words, nil
+}
+
+func (s *integrationStore) ListStudySetWordsForUser(_ context.Context, arg db.ListStudySetWordsForUserParams) ([]db.ListStudySetWordsForUserRow, error) {
+	// Every study set belongs to user 1
+	if arg.UserID != 1 {
+		return nil, nil
+	}
+	return s.studySetWords, nil
+}
+
+func (s *integrationStore) GetExamQuestionAudio(_ context.Context, arg db.GetExamQuestionAudioParams) (db.GetExamQuestionAudioRow, error) {
+	url, ok := s.questionAudio[arg.QuestionID]
+	if !ok || arg.ExamID != 1 {
+		return db.GetExamQuestionAudioRow{}, sql.ErrNoRows
+	}
+	return db.GetExamQuestionAudioRow{QuestionID: arg.QuestionID, MediaUrl: sql.NullString{String: url, Valid: url != ""}}, nil
+}
+
+func (s *integrationStore) IssuePlaybackToken(_ context.Context, arg db.IssuePlaybackTokenParams) (db.AudioPlaybackToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, token := range s.playbackTokens {
+		if token.AttemptID == arg.AttemptID && token.QuestionID == arg.QuestionID {
+			s.playbackTokens[i].TokenHash = arg.TokenHash
+			s.playbackTokens[i].ExpiresAt = arg.ExpiresAt
+			return s.playbackTokens[i], nil
+		}
+	}
+	token := db.AudioPlaybackToken{
+		ID:         int32(len(s.playbackTokens) + 1),
+		AttemptID:  arg.AttemptID,
+		QuestionID: arg.QuestionID,
+		TokenHash:  arg.TokenHash,
+		MaxPlays:   arg.MaxPlays,
+		ExpiresAt:  arg.ExpiresAt,
+	}
+	s.playbackTokens = append(s.playbackTokens, token)
+	return token, nil
+}
+
+func (s *integrationStore) GetPlaybackToken(_ context.Context, tokenHash string) (db.AudioPlaybackToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, token := range s.playbackTokens {
+		if token.TokenHash == tokenHash {
+			return token, nil
+		}
+	}
+	return db.AudioPlaybackToken{}, sql.ErrNoRows
+}
+
+func (s *integrationStore) ConsumePlaybackToken(_ context.Context, arg db.ConsumePlaybackTokenParams) (db.AudioPlaybackToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, token := range s.playbackTokens {
+		if token.ID == arg.ID && token.PlaysUsed < token.MaxPlays && token.ExpiresAt.After(arg.Now) {
+			s.playbackTokens[i].PlaysUsed++
+			return s.playbackTokens[i], nil
+		}
+	}
+	return db.AudioPlaybackToken{}, sql.ErrNoRows
+}
+
+func (s *integrationStore) RecordProctoringConsent(_ context.Context, arg db.RecordProctoringConsentParams) (db.ExamProctoringConsent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	consent := db.ExamProctoringConsent{ID: int32(len(s.consents) + 1), AttemptID: arg.AttemptID, UserID: arg.UserID,
+		PolicyVersion: arg.PolicyVersion, IpAddress: arg.IpAddress, UserAgent: arg.UserAgent, ConsentedAt: arg.ConsentedAt}
+	s.consents = append(s.consents, consent)
+	return consent, nil
+}
+
+func (s *integrationStore) GetProctoringConsent(_ context.Context, attemptID int32) (db.ExamProctoringConsent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, consent := range s.consents {
+		if consent.AttemptID == attemptID {
+			return consent, nil
+		}
+	}
+	return db.ExamProctoringConsent{}, sql.ErrNoRows
+}
+
+func (s *integrationStore) CountProctoringPhotos(ctx context.Context, attemptID sql.NullInt32) (int64, error) {
+	photos, err := s.ListProctoringPhotos(ctx, attemptID)
+	return int64(len(photos)), err
+}
+
+func (s *integrationStore) GetLatestProctoringPhoto(ctx context.Context, attemptID sql.NullInt32) (db.ExamProctoringPhoto, error) {
+	photos, _ := s.ListProctoringPhotos(ctx, attemptID)
+	if len(photos) == 0 {
+		return db.ExamProctoringPhoto{}, sql.ErrNoRows
+	}
+	return photos[len(photos)-1], nil
+}
+
+func (s *integrationStore) CreateProctoringPhoto(_ context.Context, arg db.CreateProctoringPhotoParams) (db.ExamProctoringPhoto, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	photo := db.ExamProctoringPhoto{ID: int64(len(s.photos) + 1), AttemptID: arg.AttemptID, UserID: arg.UserID,
+		PublicID: arg.PublicID, SizeBytes: arg.SizeBytes, CapturedAt: arg.CapturedAt, ReceivedAt: arg.ReceivedAt}
+	s.photos = append(s.photos, photo)
+	return photo, nil
+}
+
+func (s *integrationStore) ListProctoringPhotos(_ context.Context, attemptID sql.NullInt32) ([]db.ExamProctoringPhoto, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var photos []db.ExamProctoringPhoto
+	for _, photo := range s.photos {
+		if photo.AttemptID == attemptID {
+			photos = append(photos, photo)
+		}
+	}
+	return photos, nil
+}
+
+func (s *integrationStore) ListBestUserWritings(_ context.Context, arg db.ListBestUserWritingsParams) ([]db.ListBestUserWritingsRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var rows []db.ListBestUserWritingsRow
+	for _, writing := range s.writings {
+		if writing.UserID == arg.UserID && writing.AiScore.Valid {
+			rows = append(rows, db.ListBestUserWritingsRow{ID: writing.ID, SubmissionText: writing.SubmissionText,
+				AiScore: writing.AiScore, SubmittedAt: writing.SubmittedAt, EvaluatedAt: writing.EvaluatedAt})
+		}
+	}
+	return rows, nil
+}
+
+func (s *integrationStore) ListBestSpeakingSessions(context.Context, db.ListBestSpeakingSessionsParams) ([]db.ListBestSpeakingSessionsRow, error) {
+	return nil, nil
+}
+
+func (s *integrationStore) GetWritingScoreProgression(context.Context, db.GetWritingScoreProgressionParams) ([]db.GetWritingScoreProgressionRow, error) {
+	return nil, nil
+}
+
+func (s *integrationStore) GetSpeakingScoreProgression(context.Context, db.GetSpeakingScoreProgressionParams) ([]db.GetSpeakingScoreProgressionRow, error) {
+	return nil, nil
+}
+
+func (s *integrationStore) CountActivePortfolioShareLinks(_ context.Context, arg db.CountActivePortfolioShareLinksParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var count int64
+	for _, link := range s.shareLinks {
+		if link.UserID == arg.UserID && !link.RevokedAt.Valid && link.ExpiresAt.After(arg.Now) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (s *integrationStore) CreatePortfolioShareLink(_ context.Context, arg db.CreatePortfolioShareLinkParams) (db.PortfolioShareLink, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	link := db.PortfolioShareLink{ID: int32(len(s.shareLinks) + 1), UserID: arg.UserID, TokenHash: arg.TokenHash,
+		Label: arg.Label, ExpiresAt: arg.ExpiresAt, CreatedAt: time.Now()}
+	s.shareLinks = append(s.shareLinks, link)
+	return link, nil
+}
+
+func (s *integrationStore) RevokePortfolioShareLink(_ context.Context, arg db.RevokePortfolioShareLinkParams) (db.PortfolioShareLink, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, link := range s.shareLinks {
+		if link.ID == arg.ID && link.UserID == arg.UserID {
+			s.shareLinks[i].RevokedAt = sql.NullTime{Time: arg.RevokedAt, Valid: true}
+			return s.shareLinks[i], nil
+		}
+	}
+	return db.PortfolioShareLink{}, sql.ErrNoRows
+}
+
+func (s *integrationStore) ViewPortfolioShareLink(_ context.Context, arg db.ViewPortfolioShareLinkParams) (db.PortfolioShareLink, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, link := range s.shareLinks {
+		if link.TokenHash == arg.TokenHash && !link.RevokedAt.Valid && link.ExpiresAt.After(arg.ViewedAt) {
+			s.shareLinks[i].ViewCount++
+			return s.shareLinks[i], nil
+		}
+	}
+	return db.PortfolioShareLink{}, sql.ErrNoRows
+}
+
+func (s *integrationStore) GetUserPreferences(context.Context, int32) (db.UserPreference, error) {
+	return db.UserPreference{}, sql.ErrNoRows
+}
+
+func (s *integrationStore) GetUserPreferredLanguage(_ context.Context, userID sql.NullInt32) (sql.NullString, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	language, exists := s.languages[userID.Int32]
+	if !exists {
+		return sql.NullString{}, sql.ErrNoRows
+	}
+	return sql.NullString{String: language, Valid: language != ""}, nil
+}
+
+func (s *integrationStore) SetUserPreferredLanguage(_ context.Context, arg db.SetUserPreferredLanguageParams) (db.UserProfile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.languages == nil {
+		s.languages = make(map[int32]string)
+	}
+	s.languages[arg.UserID.Int32] = arg.PreferredLanguage.String
+	return db.UserProfile{UserID: arg.UserID, PreferredLanguage: arg.PreferredLanguage, UpdatedAt: time.Now()}, nil
+}
+
+func (s *integrationStore) GetUserUploadUsage(_ context.Context, arg db.GetUserUploadUsageParams) (db.UserUploadUsage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	usage, exists := s.uploadUsage[arg.UserID]
+	if !exists {
+		return db.UserUploadUsage{}, sql.ErrNoRows
+	}
+	return usage, nil
+}
+
+func (s *integrationStore) AddUserUploadUsage(_ context.Context, arg db.AddUserUploadUsageParams) (db.UserUploadUsage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.uploadUsage == nil {
+		s.uploadUsage = make(map[int32]db.UserUploadUsage)
+	}
+	usage := s.uploadUsage[arg.UserID]
+	usage.UserID, usage.UsageDate = arg.UserID, arg.UsageDate
+	usage.BytesUsed += arg.BytesUsed
+	usage.FilesCount++
+	s.uploadUsage[arg.UserID] = usage
+	return usage, nil
+}
+
+func (s *integrationStore) ReserveUserUploadUsage(_ context.Context, arg db.ReserveUserUploadUsageParams) (db.UserUploadUsage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.uploadUsage == nil {
+		s.uploadUsage = make(map[int32]db.UserUploadUsage)
+	}
+	usage, exists := s.uploadUsage[arg.UserID]
+	if exists && ((arg.MaxBytes > 0 && usage.BytesUsed+arg.BytesUsed > arg.MaxBytes) || (arg.MaxFiles > 0 && usage.FilesCount >= arg.MaxFiles)) {
+		return db.UserUploadUsage{}, sql.ErrNoRows
+	}
+	usage.UserID, usage.UsageDate = arg.UserID, arg.UsageDate
+	usage.BytesUsed += arg.BytesUsed
+	usage.FilesCount++
+	s.uploadUsage[arg.UserID] = usage
+	return usage, nil
+}
+
+func (s *integrationStore) ReleaseUserUploadUsage(_ context.Context, arg db.ReleaseUserUploadUsageParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if usage, exists := s.uploadUsage[arg.UserID]; exists {
+		usage.BytesUsed = max(usage.BytesUsed-arg.BytesUsed, 0)
+		usage.FilesCount = max(usage.FilesCount-1, 0)
+		s.uploadUsage[arg.UserID] = usage
+	}
+	return nil
+}
+
+func (s *integrationStore) GetGradableSpeakingTurn(_ context.Context, arg db.GetGradableSpeakingTurnParams) (db.GetGradableSpeakingTurnRow, error) {
+	if arg.TurnID != 5 || arg.GraderID == 1 {
+		return db.GetGradableSpeakingTurnRow{}, sql.ErrNoRows
+	}
+	return db.GetGradableSpeakingTurnRow{TurnID: 5, StudentID: 1}, nil
+}
+
+func (s *integrationStore) ClaimSpeakingTurn(_ context.Context, arg db.ClaimSpeakingTurnParams) (db.SpeakingGrade, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	grade, exists := s.speakingGrades[arg.TurnID]
+	if exists && (grade.Status != "claimed" || (grade.GraderID != arg.GraderID && grade.ClaimExpiresAt.After(arg.ClaimedAt))) {
+		return db.SpeakingGrade{}, sql.ErrNoRows
+	}
+	if s.speakingGrades == nil {
+		s.speakingGrades = make(map[int32]db.SpeakingGrade)
+	}
+	grade = db.SpeakingGrade{TurnID: arg.TurnID, StudentID: arg.StudentID, GraderID: arg.GraderID, Status: "claimed",
+		ClaimedAt: arg.ClaimedAt, ClaimExpiresAt: arg.ClaimExpiresAt}
+	s.speakingGrades[arg.TurnID] = grade
+	return grade, nil
+}
+
+func (s *integrationStore) GradeSpeakingTurn(_ context.Context, arg db.GradeSpeakingTurnParams) (db.SpeakingGrade, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	grade, exists := s.speakingGrades[arg.TurnID]
+	if !exists || grade.GraderID != arg.GraderID || grade.Status != "claimed" || !grade.ClaimExpiresAt.After(arg.GradedAt) {
+		return db.SpeakingGrade{}, sql.ErrNoRows
+	}
+	grade.Status, grade.Scores, grade.TotalScore, grade.Comment = "graded", arg.Scores, arg.TotalScore, arg.Comment
+	grade.GradedAt = sql.NullTime{Time: arg.GradedAt, Valid: true}
+	s.speakingGrades[arg.TurnID] = grade
+	return grade, nil
+}
+
+func (s *integrationStore) ListStudentSpeakingGrades(_ context.Context, arg db.ListStudentSpeakingGradesParams) ([]db.ListStudentSpeakingGradesRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var rows []db.ListStudentSpeakingGradesRow
+	for _, grade := range s.speakingGrades {
+		if grade.StudentID == arg.StudentID && grade.Status == "graded" {
+			rows = append(rows, db.ListStudentSpeakingGradesRow{TurnID: grade.TurnID, Scores: grade.Scores,
+				TotalScore: grade.TotalScore.Int32, Comment: grade.Comment, GradedAt: grade.GradedAt.Time})
+		}
+	}
+	return rows, nil
+}
+
+func (s *integrationStore) CountStudentSpeakingGrades(_ context.Context, studentID int32) (int64, error) {
+	rows, err := s.ListStudentSpeakingGrades(context.Background(), db.ListStudentSpeakingGradesParams{StudentID: studentID})
+	return int64(len(rows)), err
+}
+
+func TestIntegrationLoginLocksAccount(t *testing.T) {
+	store := newIntegrationStore(t, "jane@example.com", "Secret123!")
+	ts := newTestServer(t, store, withConfig(func(cfg *config.Config) {
+		cfg.AccountLockoutThreshold = 2
+		cfg.AccountLockoutDuration = time.Hour
+	}))
+
+	login := func(password string) int {
+		return ts.requestJSON(t, http.MethodPost, "/api/auth/login",
+			loginUserRequest{Email: "jane@example.com", Password: password}, 0).Code
+	}
+
+	assert.Equal(t, http.StatusOK, login("Secret123!"))
+	require.Len(t, store.sessions, 1)
+	assert.Equal(t, testUserAgent, store.sessions[0].UserAgent)
+
+	assert.Equal(t, http.StatusUnauthorized, login("wrong-password"))
+	assert.Equal(t, http.StatusUnauthorized, login("wrong-password"))
+	// The right password no longer helps
+	assert.Equal(t, http.StatusLocked, login("Secret123!"))
+}
+
+func TestIntegrationScoreWritingCallsOpenAI(t *testing.T) {
+	store := newIntegrationStore(t, "jane@example.com", "Secret123!")
+	ts := newTestServer(t, store)
+
+	recorder := ts.requestJSON(t, http.MethodPost, "/api/v1/writing/score",
+		scoreWritingRequest{Text: "I am writing to confirm the meeting on Monday."}, 1)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	var response scoreWritingResponse
+	decodeData(t, recorder, &response)
+	assert.Equal(t, 150, response.Score)
+	assert.Equal(t, []string{"Use more linking words"}, response.Suggestions)
+	assert.Equal(t, 1, store.scorings)
+
+	requests := ts.openAI.ChatRequests()
+	require.Len(t, requests, 1)
+	assert.Equal(t, "test-openai-key", requests[0].APIKey)
+	assert.Contains(t, requests[0].Messages[1].Content, "confirm the meeting")
+
+	// An unparsable reply falls back to local scoring
+	ts.openAI.Reply("no assessment today")
+	recorder = ts.requestJSON(t, http.MethodPost, "/api/v1/writing/score",
+		scoreWritingRequest{Text: "Short text."}, 1)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestIntegrationUploadImageUsesUploader(t *testing.T) {
+	ts := newTestServer(t, newIntegrationStore(t, "jane@example.com", "Secret123!"))
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", "cat.png")
+	require.NoError(t, err)
+	part.Write([]byte("png-bytes"))
+	require.NoError(t, writer.Close())
+
+	recorder := ts.request(t, http.MethodPost, "/api/v1/upload", writer.FormDataContentType(), &body, 1)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	var response struct {
+		URL string `json:"url"`
+	}
+	decodeData(t, recorder, &response)
+	assert.Equal(t, ts.uploads.URL("image", "cat.png"), response.URL)
+	require.Len(t, ts.uploads.Uploads(), 1)
+	assert.Equal(t, []byte("png-bytes"), ts.uploads.Uploads()[0].Data)
+
+	// Uploads are counted per user, so anonymous clients are turned away
+	anonymous, contentType := uploadForm(t, "cat.png", 10)
+	recorder = ts.request(t, http.MethodPost, "/api/v1/upload", contentType, anonymous, 0)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code, recorder.Body.String())
+	assert.Len(t, ts.uploads.Uploads(), 1)
+}
+
+// uploadForm is a multipart form with a file of size bytes
+func uploadForm(t *testing.T, name string, size int) (*bytes.Buffer, string) {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", name)
+	require.NoError(t, err)
+	part.Write(bytes.Repeat([]byte("a"), size))
+	require.NoError(t, writer.Close())
+	return &body, writer.FormDataContentType()
+}
+
+func TestIntegrationUploadQuotaSharedThroughRedis(t *testing.T) {
+	store := newIntegrationStore(t, "jane@example.com", "Secret123!")
+	quota := withConfig(func(cfg *config.Config) {
+		cfg.UploadQuotaBackend = "redis"
+		cfg.UploadDailyQuotaBytes = 1000
+		cfg.UploadDailyQuotaFiles = 2
+	})
+	first := newTestServer(t, store, withRedis(), quota)
+	second := newTestServer(t, store, func(ts *testServer, cfg *config.Config) {
+		ts.redis = first.redis
+	}, withRedis(), quota)
+
+	body, contentType := uploadForm(t, "cat.png", 300)
+	recorder := first.request(t, http.MethodPost, "/api/v1/upload", contentType, body, 1)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	assert.Equal(t, "1000", recorder.Header().Get("X-Upload-Quota-Bytes-Limit"))
+	assert.Equal(t, "1", recorder.Header().Get("X-Upload-Quota-Files-Remaining"))
+	assert.NotEmpty(t, recorder.Header().Get("X-Upload-Quota-Reset"))
+
+	body, contentType = uploadForm(t, "talk.mp3", 300)
+	recorder = second.request(t, http.MethodPost, "/api/v1/upload-audio", contentType, body, 1)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	assert.Equal(t, "0", recorder.Header().Get("X-Upload-Quota-Files-Remaining"))
+
+	// The third file is over the quota counted by both instances
+	body, contentType = uploadForm(t, "dog.png", 10)
+	recorder = first.request(t, http.MethodPost, "/api/v1/upload", contentType, body, 1)
+	require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	assert.NotEmpty(t, recorder.Header().Get("Retry-After"))
+	assert.Equal(t, "400", recorder.Header().Get("X-Upload-Quota-Bytes-Remaining"))
+	assert.Contains(t, recorder.Body.String(), "limit of 2 files a day reached, quota resets at "+
+		time.Now().UTC().Truncate(24*time.Hour).Add(24*time.Hour).Format(time.RFC3339))
+	assert.Len(t, first.uploads.Uploads(), 1)
+
+	// Other users have a quota of their own
+	body, contentType = uploadForm(t, "dog.png", 10)
+	recorder = first.request(t, http.MethodPost, "/api/v1/upload", contentType, body, 2)
+	assert.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+}
+
+func TestIntegrationClientConfig(t *testing.T) {
+	store := newIntegrationStore(t, "jane@example.com", "Secret123!")
+	store.flags = []db.FeatureFlag{{Key: "ai_scoring_v2", Enabled: true, AllowedUserIds: []int32{1}}}
+	ts := newTestServer(t, store, withConfig(func(cfg *config.Config) {
+		cfg.FeatureFlags = "adaptive_exam"
+		cfg.ClientMinAppVersion = "1.1.0"
+		cfg.ClientMaintenanceNotice = "Maintenance on Sunday"
+		cfg.UploadDailyQuotaFiles = 5
+	}))
+
+	recorder := ts.requestJSON(t, http.MethodGet, "/api/v1/client-config?app_version=1.0.3", nil, 1)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	var response clientConfigResponse
+	decodeData(t, recorder, &response)
+	assert.Equal(t, map[string]bool{"ai_scoring_v2": true, "adaptive_exam": true}, response.Features)
+	assert.Equal(t, "1.1.0", response.App.MinVersion)
+	assert.True(t, response.App.UpdateRequired)
+	assert.True(t, response.AI.WritingScoring)
+	assert.Equal(t, ts.config.AIMaxWritingWords, response.AI.Limits.Kinds["writing"].MaxWords)
+	assert.Equal(t, "Maintenance on Sunday", response.Maintenance.Notice)
+	assert.False(t, response.Maintenance.ReadOnly)
+	assert.Equal(t, int64(5), response.Quotas.UploadDailyFiles)
+
+	// Flags are evaluated for each user
+	_, err := ts.readOnly.Enable(context.Background(), "Restoring the database", "admin")
+	require.NoError(t, err)
+	recorder = ts.requestJSON(t, http.MethodGet, "/api/v1/client-config?app_version=1.1.0", nil, 2)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	response = clientConfigResponse{}
+	decodeData(t, recorder, &response)
+	assert.False(t, response.Features["ai_scoring_v2"])
+	assert.False(t, response.App.UpdateRequired)
+	assert.True(t, response.Maintenance.ReadOnly)
+	assert.Equal(t, "Restoring the database", response.Maintenance.Reason)
+
+	recorder = ts.requestJSON(t, http.MethodGet, "/api/v1/client-config?app_version=latest", nil, 1)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestIntegrationAnalyzeTextCallsAnalyzeService(t *testing.T) {
+	ts := newTestServer(t, newIntegrationStore(t, "jane@example.com", "Secret123!"))
+	ts.analyze.SetLevel("B2")
+
+	require.Eventually(t, ts.analyzeService.IsHealthy, 5*time.Second, 10*time.Millisecond)
+	recorder := ts.requestJSON(t, http.MethodPost, "/api/v1/analyze/text",
+		analyzeTextRequest{Text: "Quarterly revenue increased"}, 1)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	assert.Contains(t, recorder.Body.String(), `"level":"B2"`)
+	assert.Equal(t, []string{"Quarterly revenue increased"}, ts.analyze.Texts())
+}
+
+func TestIntegrationRateLimitsSharedThroughRedis(t *testing.T) {
+	store := newIntegrationStore(t, "jane@example.com", "Secret123!")
+	limited := withConfig(func(cfg *config.Config) {
+		cfg.RateLimitEnabled = true
+		cfg.RateLimitRequests = 1
+		cfg.RateLimitBurst = 2
+	})
+	first := newTestServer(t, store, withRedis(), limited)
+	// A second instance shares the Redis server of the first
+	second := newTestServer(t, store, func(ts *testServer, cfg *config.Config) {
+		ts.redis = first.redis
+	}, withRedis(), limited)
+
+	assert.Equal(t, http.StatusOK, first.requestJSON(t, http.MethodGet, "/health", nil, 0).Code)
+	assert.Equal(t, http.StatusOK, second.requestJSON(t, http.MethodGet, "/health", nil, 0).Code)
+	recorder := first.requestJSON(t, http.MethodGet, "/health", nil, 0)
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	assert.True(t, strings.HasPrefix(recorder.Header().Get("RateLimit-Policy"), "2;w=2"))
+}
+
+func TestIntegrationReadingOthersWritingIsLogged(t *testing.T) {
+	store := newIntegrationStore(t, "jane@example.com", "Secret123!")
+	store.writings = []db.UserWriting{{ID: 5, UserID: 1, SubmissionText: "Dear Sir or Madam", SubmittedAt: time.Now()}}
+	ts := newTestServer(t, store)
+
+	// Reading one's own submission is not logged
+	recorder := ts.requestJSON(t, http.MethodGet, "/api/v1/writing/submissions/5", nil, 1)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	assert.Empty(t, store.accessLogs)
+
+	recorder = ts.requestJSON(t, http.MethodGet, "/api/v1/writing/submissions/5", nil, 2)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	recorder = ts.requestJSON(t, http.MethodGet, "/api/v1/users/me/access-log", nil, 1)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	var entries []accesslog.Entry
+	decodeData(t, recorder, &entries)
+	require.Len(t, entries, 1)
+	require.NotNil(t, entries[0].AccessorID)
+	assert.Equal(t, int32(2), *entries[0].AccessorID)
+	assert.Equal(t, accesslog.ResourceWriting, entries[0].ResourceType)
+	assert.Equal(t, int32(5), entries[0].ResourceID)
+	assert.Equal(t, accesslog.PurposeUnspecified, entries[0].Purpose)
+
+	// The reader's own log stays empty
+	recorder = ts.requestJSON(t, http.MethodGet, "/api/v1/users/me/access-log", nil, 2)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	decodeData(t, recorder, &entries)
+	assert.Empty(t, entries)
+}
+
+func TestIntegrationIntegrityScoreIsShownToGraders(t *testing.T) {
+	store := newIntegrationStore(t, "jane@example.com", "Secret123!")
+	store.attempts = []db.ExamAttempt{
+		{AttemptID: 3, UserID: 1, ExamID: 1, StartTime: time.Now().Add(-time.Hour), Status: db.ExamStatusEnumInProgress},
+		{AttemptID: 4, UserID: 1, ExamID: 1, StartTime: time.Now().Add(-time.Hour), Status: db.ExamStatusEnumCompleted},
+	}
+	store.permissions = map[int32][]string{3: {rbac.PermExamGrade}}
+	ts := newTestServer(t, store)
+
+	events := map[string]interface{}{"events": []map[string]interface{}{
+		{"type": "tab_switch", "duration_ms": 2000},
+		{"type": "paste", "paste_length": 300},
+	}}
+	recorder := ts.requestJSON(t, http.MethodPost, "/api/v1/exam-attempts/3/integrity-events", events, 1)
+	require.Equal(t, http.StatusCreated, recorder.Code, recorder.Body.String())
+
+	// Events can only be reported while the attempt is in progress, by its owner
+	recorder = ts.requestJSON(t, http.MethodPost, "/api/v1/exam-attempts/4/integrity-events", events, 1)
+	assert.Equal(t, http.StatusConflict, recorder.Code, recorder.Body.String())
+	recorder = ts.requestJSON(t, http.MethodPost, "/api/v1/exam-attempts/3/integrity-events", events, 2)
+	assert.Equal(t, http.StatusNotFound, recorder.Code, recorder.Body.String())
+
+	// The owner sees no score
+	recorder = ts.requestJSON(t, http.MethodGet, "/api/v1/exam-attempts/3", nil, 1)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	var attempt ExamAttemptResponse
+	decodeData(t, recorder, &attempt)
+	assert.Nil(t, attempt.Integrity)
+	recorder = ts.requestJSON(t, http.MethodGet, "/api/v1/exam-attempts/3/integrity", nil, 1)
+	assert.Equal(t, http.StatusForbidden, recorder.Code, recorder.Body.String())
+
+	// Other users without exams.grade cannot read the attempt
+	recorder = ts.requestJSON(t, http.MethodGet, "/api/v1/exam-attempts/3", nil, 2)
+	assert.Equal(t, http.StatusNotFound, recorder.Code, recorder.Body.String())
+
+	recorder = ts.requestJSON(t, http.MethodGet, "/api/v1/exam-attempts/3", nil, 3)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	decodeData(t, recorder, &attempt)
+	require.NotNil(t, attempt.Integrity)
+	assert.Equal(t, 85, attempt.Integrity.Score)
+	assert.Equal(t, int64(1), attempt.Integrity.TabSwitches)
+	assert.Equal(t, int64(300), attempt.Integrity.PastedChars)
+
+	recorder = ts.requestJSON(t, http.MethodGet, "/api/v1/exam-attempts/3/integrity", nil, 3)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	recorder = ts.requestJSON(t, http.MethodGet, "/api/v1/users/me/access-log", nil, 1)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	var entries []accesslog.Entry
+	decodeData(t, recorder, &entries)
+	require.Len(t, entries, 2)
+	assert.Equal(t, accesslog.ResourceExamAttempt, entries[0].ResourceType)
+	assert.Equal(t, accesslog.ResourceIntegrityEvents, entries[1].ResourceType)
+}
+
+func TestIntegrationAttemptReviewLinksVocabulary(t *testing.T) {
+	store := newIntegrationStore(t, "jane@example.com", "Secret123!")
+	store.attempts = []db.ExamAttempt{{AttemptID: 3, UserID: 1, ExamID: 1, Status: db.ExamStatusEnumCompleted}}
+	store.answers = []db.ListUserAnswersByAttemptWithQuestionsRow{{
+		UserAnswerID:  1,
+		AttemptID:     3,
+		QuestionID:    8,
+		QuestionTitle: "Who will take over the invoices?",
+		Explanation:   "The manager takes over billing.",
+	}}
+	store.words = []db.ListWordsForLinkingRow{
+		{ID: 1, Word: "invoice", ShortMean: "hóa đơn"},
+		{ID: 2, Word: "take over"},
+		{ID: 3, Word: "billing"},
+	}
+	store.studySetWords = []db.ListStudySetWordsForUserRow{{WordID: 1, StudySetID: 9}}
+	ts := newTestServer(t, store)
+
+	recorder := ts.requestJSON(t, http.MethodGet, "/api/v1/exam-attempts/3/answers", nil, 1)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	var review AttemptAnswersResponse
+	decodeData(t, recorder, &review)
+
+	require.Len(t, review.Answers, 1)
+	links := review.Answers[0].VocabularyLinks
+	require.Len(t, links, 3)
+	assert.Equal(t, "question", links[0].Field)
+	assert.Equal(t, int32(2), links[0].WordID)
+	assert.Equal(t, 9, links[0].Start)
+	assert.Equal(t, int32(1), links[1].WordID)
+	assert.Equal(t, "explanation", links[2].Field)
+	assert.Equal(t, int32(3), links[2].WordID)
+
+	require.Len(t, review.Vocabulary, 3)
+	assert.Equal(t, "take over", review.Vocabulary[0].Word)
+	assert.Empty(t, review.Vocabulary[0].StudySetIDs)
+	assert.Equal(t, []int32{9}, review.Vocabulary[1].StudySetIDs)
+	assert.Equal(t, http.MethodPost, review.Vocabulary[1].AddToStudySet.Method)
+	assert.Equal(t, int32(1), review.Vocabulary[1].AddToStudySet.WordID)
+}
+
+func TestIntegrationListeningAudioPlaysOnce(t *testing.T) {
+	store := newIntegrationStore(t, "jane@example.com", "Secret123!")
+	store.attempts = []db.ExamAttempt{
+		{AttemptID: 3, UserID: 1, ExamID: 1, StartTime: time.Now().Add(-time.Hour), Status: db.ExamStatusEnumInProgress},
+	}
+	store.questionAudio = map[int32]string{8: "https://media.fake/audio/q8.mp3", 9: ""}
+	store.permissions = map[int32][]string{3: {rbac.PermExamGrade}}
+	ts := newTestServer(t, store)
+
+	recorder := ts.requestJSON(t, http.MethodPost, "/api/v1/exam-attempts/3/questions/9/playback-token", nil, 1)
+	assert.Equal(t, http.StatusNotFound, recorder.Code, recorder.Body.String())
+	recorder = ts.requestJSON(t, http.MethodPost, "/api/v1/exam-attempts/3/questions/8/playback-token", nil, 2)
+	assert.Equal(t, http.StatusNotFound, recorder.Code, recorder.Body.String())
+
+	recorder = ts.requestJSON(t, http.MethodPost, "/api/v1/exam-attempts/3/questions/8/playback-token", nil, 1)
+	require.Equal(t, http.StatusCreated, recorder.Code, recorder.Body.String())
+	var grant playback.Grant
+	decodeData(t, recorder, &grant)
+	assert.Equal(t, int32(1), grant.MaxPlays)
+	require.NotEmpty(t, grant.Token)
+
+	play := map[string]string{"token": grant.Token}
+	recorder = ts.requestJSON(t, http.MethodPost, "/api/v1/exam-attempts/3/playback", play, 1)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	var audio playback.Playback
+	decodeData(t, recorder, &audio)
+	assert.Contains(t, audio.URL, "https://media.fake/audio/q8.mp3?expires=")
+	assert.Zero(t, audio.PlaysLeft)
+
+	recorder = ts.requestJSON(t, http.MethodPost, "/api/v1/exam-attempts/3/playback", play, 1)
+	assert.Equal(t, http.StatusConflict, recorder.Code, recorder.Body.String())
+
+	// The refused replay lowers the integrity score
+	recorder = ts.requestJSON(t, http.MethodGet, "/api/v1/exam-attempts/3", nil, 3)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	var attempt ExamAttemptResponse
+	decodeData(t, recorder, &attempt)
+	require.NotNil(t, attempt.Integrity)
+	assert.Equal(t, int64(1), attempt.Integrity.AudioPlays)
+	assert.Equal(t, int64(1), attempt.Integrity.DeniedReplays)
+	assert.Equal(t, 95, attempt.Integrity.Score)
+}
+
+func TestIntegrationProctoringPhotosNeedConsent(t *testing.T) {
+	store := newIntegrationStore(t, "jane@example.com", "Secret123!")
+	store.attempts = []db.ExamAttempt{
+		{AttemptID: 3, UserID: 1, ExamID: 1, StartTime: time.Now().Add(-time.Hour), Status: db.ExamStatusEnumInProgress},
+	}
+	store.permissions = map[int32][]string{3: {"admin.access", rbac.PermExamGrade}}
+	ts := newTestServer(t, store, withConfig(func(cfg *config.Config) {
+		cfg.ProctoringEnabled = true
+	}))
+
+	snapshot := func() *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		part, err := writer.CreateFormFile("photo", "webcam.jpg")
+		require.NoError(t, err)
+		part.Write([]byte("\xff\xd8\xff\xe0 webcam"))
+		require.NoError(t, writer.Close())
+		return ts.request(t, http.MethodPost, "/api/v1/exam-attempts/3/proctoring/photos", writer.FormDataContentType(), &body, 1)
+	}
+
+	recorder := snapshot()
+	assert.Equal(t, http.StatusForbidden, recorder.Code, recorder.Body.String())
+
+	recorder = ts.requestJSON(t, http.MethodGet, "/api/v1/exam-attempts/3/proctoring", nil, 1)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	var status proctoring.Status
+	decodeData(t, recorder, &status)
+	assert.True(t, status.ConsentRequired)
+
+	consent := map[string]string{"policy_version": status.PolicyVersion}
+	recorder = ts.requestJSON(t, http.MethodPost, "/api/v1/exam-attempts/3/proctoring/consent", consent, 1)
+	require.Equal(t, http.StatusCreated, recorder.Code, recorder.Body.String())
+	require.Len(t, store.consents, 1)
+	assert.Equal(t, testUserAgent, store.consents[0].UserAgent)
+
+	recorder = snapshot()
+	require.Equal(t, http.StatusCreated, recorder.Code, recorder.Body.String())
+	recorder = snapshot()
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code, recorder.Body.String())
+	require.Len(t, ts.uploads.Uploads(), 1)
+	assert.Equal(t, "private_image", ts.uploads.Uploads()[0].Kind)
+
+	// Only reviewers see the snapshots, and their reads are logged
+	recorder = ts.requestJSON(t, http.MethodGet, "/api/v1/admin/proctoring/attempts/3/photos", nil, 1)
+	assert.Equal(t, http.StatusForbidden, recorder.Code, recorder.Body.String())
+	recorder = ts.requestJSON(t, http.MethodGet, "/api/v1/admin/proctoring/attempts/3/photos", nil, 3)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	var photos []proctoring.Photo
+	decodeData(t, recorder, &photos)
+	require.Len(t, photos, 1)
+	assert.Contains(t, photos[0].URL, "signature=fake")
+
+	recorder = ts.requestJSON(t, http.MethodGet, "/api/v1/users/me/access-log", nil, 1)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	var entries []accesslog.Entry
+	decodeData(t, recorder, &entries)
+	require.Len(t, entries, 1)
+	assert.Equal(t, accesslog.ResourceProctoringPhotos, entries[0].ResourceType)
+}
+
+func TestIntegrationPortfolioShareLink(t *testing.T) {
+	store := newIntegrationStore(t, "jane@example.com", "Secret123!")
+	store.writings = []db.UserWriting{
+		{ID: 1, UserID: 1, SubmissionText: "Dear hiring manager", AiScore: sql.NullString{String: "150.00", Valid: true}},
+		{ID: 2, UserID: 1, SubmissionText: "Not evaluated yet"},
+	}
+	ts := newTestServer(t, store)
+
+	recorder := ts.requestJSON(t, http.MethodGet, "/api/v1/users/me/portfolio", nil, 1)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	var mine portfolio.Portfolio
+	decodeData(t, recorder, &mine)
+	require.Len(t, mine.Writings, 1)
+	assert.Equal(t, 150.0, mine.Writings[0].Score)
+
+	recorder = ts.requestJSON(t, http.MethodPost, "/api/v1/users/me/portfolio/shares", map[string]int{"expires_in_days": 400}, 1)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code, recorder.Body.String())
+	recorder = ts.requestJSON(t, http.MethodPost, "/api/v1/users/me/portfolio/shares", map[string]string{"label": "Acme"}, 1)
+	require.Equal(t, http.StatusCreated, recorder.Code, recorder.Body.String())
+	var link portfolioShareLinkResponse
+	decodeData(t, recorder, &link)
+	assert.True(t, strings.HasSuffix(link.URL, "/api/v1/portfolio/"+link.Token), link.URL)
+
+	// Anyone with the link sees the portfolio without the owner's email
+	recorder = ts.requestJSON(t, http.MethodGet, "/api/v1/portfolio/"+link.Token, nil, 0)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	assert.NotContains(t, recorder.Body.String(), "jane@example.com")
+	var shared portfolio.Portfolio
+	decodeData(t, recorder, &shared)
+	assert.Equal(t, "jane", shared.Username)
+	assert.Len(t, shared.Writings, 1)
+
+	recorder = ts.requestJSON(t, http.MethodDelete, fmt.Sprintf("/api/v1/users/me/portfolio/shares/%d", link.ID), nil, 1)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	recorder = ts.requestJSON(t, http.MethodGet, "/api/v1/portfolio/"+link.Token, nil, 0)
+	assert.Equal(t, http.StatusNotFound, recorder.Code, recorder.Body.String())
+}
+
+func TestIntegrationPreferredLanguage(t *testing.T) {
+	store := newIntegrationStore(t, "jane@example.com", "Secret123!")
+	ts := newTestServer(t, store)
+
+	recorder := ts.requestJSON(t, http.MethodGet, "/api/v1/users/me", nil, 1)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	assert.Equal(t, "en", recorder.Header().Get("Content-Language"))
+	assert.Equal(t, "default", recorder.Header().Get("X-Language-Source"))
+
+	recorder = ts.requestJSON(t, http.MethodPut, "/api/v1/users/me/language", map[string]string{"language": "fr"}, 1)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code, recorder.Body.String())
+	recorder = ts.requestJSON(t, http.MethodPut, "/api/v1/users/me/language", map[string]string{"language": "vi"}, 1)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	var profile UserProfileResponse
+	decodeData(t, recorder, &profile)
+	assert.Equal(t, "vi", profile.PreferredLanguage)
+
+	// Requests that do not ask for a language use the profile's
+	recorder = ts.requestJSON(t, http.MethodGet, "/api/v1/users/me", nil, 1)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	assert.Equal(t, "vi", recorder.Header().Get("Content-Language"))
+	assert.Equal(t, "profile", recorder.Header().Get("X-Language-Source"))
+
+	// ?lang= overrides it
+	recorder = ts.requestJSON(t, http.MethodGet, "/api/v1/users/me?lang=en", nil, 1)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	assert.Equal(t, "en", recorder.Header().Get("Content-Language"))
+	assert.Equal(t, "query", recorder.Header().Get("X-Language-Source"))
+
+	// Other users keep the default
+	recorder = ts.requestJSON(t, http.MethodGet, "/api/v1/users/me", nil, 2)
+	assert.Equal(t, "default", recorder.Header().Get("X-Language-Source"))
+}
+
+func TestIntegrationSpeakingGrading(t *testing.T) {
+	store := newIntegrationStore(t, "jane@example.com", "Secret123!")
+	store.permissions = map[int32][]string{3: {rbac.PermSpeakingEvaluate}, 4: {rbac.PermSpeakingEvaluate}}
+	ts := newTestServer(t, store)
+
+	scores := map[string]int{}
+	for _, criterion := range grading.Rubric {
+		scores[criterion.Key] = 3
+	}
+
+	// Learners cannot grade
+	recorder := ts.requestJSON(t, http.MethodPost, "/api/v1/speaking/grading/turns/5/claim", nil, 1)
+	assert.Equal(t, http.StatusForbidden, recorder.Code, recorder.Body.String())
+
+	recorder = ts.requestJSON(t, http.MethodPost, "/api/v1/speaking/grading/turns/6/claim", nil, 3)
+	assert.Equal(t, http.StatusNotFound, recorder.Code, recorder.Body.String())
+	recorder = ts.requestJSON(t, http.MethodPost, "/api/v1/speaking/grading/turns/5/claim", nil, 3)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	// A second teacher cannot claim or grade the same turn
+	recorder = ts.requestJSON(t, http.MethodPost, "/api/v1/speaking/grading/turns/5/claim", nil, 4)
+	assert.Equal(t, http.StatusConflict, recorder.Code, recorder.Body.String())
+	recorder = ts.requestJSON(t, http.MethodPost, "/api/v1/speaking/grading/turns/5/grade", map[string]interface{}{"scores": scores}, 4)
+	assert.Equal(t, http.StatusConflict, recorder.Code, recorder.Body.String())
+
+	recorder = ts.requestJSON(t, http.MethodPost, "/api/v1/speaking/grading/turns/5/grade", map[string]interface{}{"scores": map[string]int{"grammar": 3}}, 3)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code, recorder.Body.String())
+	recorder = ts.requestJSON(t, http.MethodPost, "/api/v1/speaking/grading/turns/5/grade",
+		map[string]interface{}{"scores": scores, "comment": "Excellent answer"}, 3)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	recorder = ts.requestJSON(t, http.MethodGet, "/api/v1/speaking/grades", nil, 1)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	var grades []grading.Grade
+	decodeData(t, recorder, &grades)
+	require.Len(t, grades, 1)
+	assert.Equal(t, 18, grades[0].TotalScore)
+	assert.Equal(t, "Excellent answer", grades[0].Comment)
+}
+
+func TestIntegrationDrainRefusesNewAIWork(t *testing.T) {
+	store := newIntegrationStore(t, "jane@example.com", "Secret123!")
+	store.permissions = map[int32][]string{3: {"admin.access", "system.manage"}}
+	ts := newTestServer(t, store)
+
+	recorder := ts.requestJSON(t, http.MethodPost, "/api/v1/admin/system/drain", nil, 1)
+	assert.Equal(t, http.StatusForbidden, recorder.Code, recorder.Body.String())
+	recorder = ts.requestJSON(t, http.MethodPost, "/api/v1/admin/system/drain", map[string]int{"timeout_seconds": 120}, 3)
+	require.Equal(t, http.StatusAccepted, recorder.Code, recorder.Body.String())
+	var status drain.Status
+	decodeData(t, recorder, &status)
+	assert.Equal(t, "user3", status.StartedBy)
+	assert.Equal(t, 2*time.Minute, status.Deadline.Sub(*status.StartedAt))
+
+	recorder = ts.requestJSON(t, http.MethodPost, "/api/v1/writing/score", scoreWritingRequest{Text: "Short text."}, 1)
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code, recorder.Body.String())
+	assert.Equal(t, "30", recorder.Header().Get("Retry-After"))
+	assert.Empty(t, ts.openAI.ChatRequests())
+
+	// Nothing is in flight, so the instance can stop
+	recorder = ts.requestJSON(t, http.MethodGet, "/api/v1/admin/system/drain", nil, 3)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	decodeData(t, recorder, &status)
+	assert.Equal(t, drain.StateDrained, status.State)
+	assert.Equal(t, int64(0), status.InFlightRequests)
+	assert.Equal(t, map[string]int{"background_jobs": 0}, status.PendingWork)
+	assert.True(t, status.SafeToShutdown)
+
+	recorder = ts.requestJSON(t, http.MethodDelete, "/api/v1/admin/system/drain", nil, 3)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	recorder = ts.requestJSON(t, http.MethodDelete, "/api/v1/admin/system/drain", nil, 3)
+	assert.Equal(t, http.StatusConflict, recorder.Code, recorder.Body.String())
+	recorder = ts.requestJSON(t, http.MethodPost, "/api/v1/writing/score", scoreWritingRequest{Text: "Short text."}, 1)
+	assert.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+}
+
+func TestIntegrationReadOnlyModeRefusesWrites(t *testing.T) {
+	store := newIntegrationStore(t, "jane@example.com", "Secret123!")
+	store.permissions = map[int32][]string{3: {"admin.access", "system.manage"}}
+	ts := newTestServer(t, store)
+
+	recorder := ts.requestJSON(t, http.MethodPost, "/api/v1/admin/system/read-only", nil, 1)
+	assert.Equal(t, http.StatusForbidden, recorder.Code, recorder.Body.String())
+	recorder = ts.requestJSON(t, http.MethodPost, "/api/v1/admin/system/read-only", map[string]string{"reason": "Running migrations"}, 3)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	var state readonly.State
+	decodeData(t, recorder, &state)
+	assert.True(t, state.Enabled)
+	assert.Equal(t, "user3", state.SetBy)
+
+	// Writes are refused, reads are served
+	recorder = ts.requestJSON(t, http.MethodPost, "/api/v1/writing/score", scoreWritingRequest{Text: "Short text."}, 1)
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code, recorder.Body.String())
+	assert.Equal(t, "120", recorder.Header().Get("Retry-After"))
+	assert.Empty(t, ts.openAI.ChatRequests())
+	recorder = ts.requestJSON(t, http.MethodGet, "/api/v1/admin/system/read-only", nil, 3)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	decodeData(t, recorder, &state)
+	assert.Equal(t, "Running migrations", state.Reason)
+
+	recorder = ts.requestJSON(t, http.MethodDelete, "/api/v1/admin/system/read-only", nil, 3)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	recorder = ts.requestJSON(t, http.MethodPost, "/api/v1/writing/score", scoreWritingRequest{Text: "Short text."}, 1)
+	assert.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+}
+
+func TestIntegrationReadOnlyModeKeepsSignIn(t *testing.T) {
+	store := newIntegrationStore(t, "jane@example.com", "Secret123!")
+	ts := newTestServer(t, store)
+	_, err := ts.readOnly.Enable(context.Background(), "Restoring the database", "admin")
+	require.NoError(t, err)
+
+	recorder := ts.requestJSON(t, http.MethodPost, "/api/auth/login",
+		loginUserRequest{Email: "jane@example.com", Password: "Secret123!"}, 0)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	var login loginUserResponse
+	decodeData(t, recorder, &login)
+	// Sessions are not written during maintenance
+	assert.Empty(t, store.sessions)
+
+	recorder = ts.requestJSON(t, http.MethodPost, "/api/auth/refresh-token",
+		refreshTokenRequest{RefreshToken: login.RefreshToken}, 0)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	var refreshed refreshTokenResponse
+	decodeData(t, recorder, &refreshed)
+	assert.NotEmpty(t, refreshed.AccessToken)
+
+	// Wrong passwords are not counted either
+	recorder = ts.requestJSON(t, http.MethodPost, "/api/auth/login",
+		loginUserRequest{Email: "jane@example.com", Password: "wrong-password"}, 0)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code, recorder.Body.String())
+	assert.Empty(t, store.lockouts)
+
+	// Registering still writes, so it waits for the maintenance
+	recorder = ts.requestJSON(t, http.MethodPost, "/api/auth/register",
+		registerUserRequest{Username: "john", Email: "john@example.com", Password: "Secret123!"}, 0)
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code, recorder.Body.String())
+}
+
+func TestIntegrationWebSocketConnectionsRequireMonitorPermission(t *testing.T) {
+	store := newIntegrationStore(t, "jane@example.com", "Secret123!")
+	store.permissions = map[int32][]string{3: {"admin.access", "system.monitor"}}
+	ts := newTestServer(t, store)
+
+	recorder := ts.requestJSON(t, http.MethodGet, "/api/v1/admin/websocket/connections", nil, 1)
+	assert.Equal(t, http.StatusForbidden, recorder.Code, recorder.Body.String())
+	recorder = ts.requestJSON(t, http.MethodGet, "/api/v1/admin/websocket/connections", nil, 3)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	var response webSocketConnectionsResponse
+	decodeData(t, recorder, &response)
+	assert.Equal(t, 0, response.Stats.Connections)
+	assert.Empty(t, response.Connections)
+}
+
+func TestIntegrationScoreWritingUsesPromptRubric(t *testing.T) {
+	store := newIntegrationStore(t, "jane@example.com", "Secret123!")
+	ts := newTestServer(t, store)
+	ts.openAI.Reply(`{
+  "score": 120,
+  "band": "6",
+  "criteria": {
+    "task_response": {"score": 160, "feedback": "Explains the delay"},
+    "organization": {"score": 140, "feedback": "Missing a closing"}
+  },
+  "feedback": {"overall": "Clear reply"},
+  "suggestions": ["Add a closing line"],
+  "confidence": 0.9
+}`)
+
+	promptID := int32(3)
+	recorder := ts.requestJSON(t, http.MethodPost, "/api/v1/writing/score",
+		scoreWritingRequest{Text: "Dear Ms. Lee, the shipment is late because of the storm.", PromptID: &promptID}, 1)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	var response scoreWritingResponse
+	decodeData(t, recorder, &response)
+	// The score is the weighted average of the criteria
+	assert.Equal(t, 152, response.Score)
+	assert.Equal(t, "Business email", response.Rubric)
+	assert.Equal(t, []ai.CriterionScore{
+		{Key: "task_response", Name: "Task completion", Weight: 60, Score: 160, Feedback: "Explains the delay"},
+		{Key: "organization", Name: "Organization", Weight: 40, Score: 140, Feedback: "Missing a closing"},
+	}, response.Criteria)
+	assert.Equal(t, "Missing a closing", response.Feedback["organization"])
+
+	requests := ts.openAI.ChatRequests()
+	require.Len(t, requests, 1)
+	prompt := requests[0].Messages[1].Content
+	assert.Contains(t, prompt, "Reply to your manager about the delayed shipment")
+	assert.Contains(t, prompt, `Task completion (key "task_response", 60% of the score)`)
+	assert.NotContains(t, prompt, "language_use")
+}
+
+func TestIntegrationWritingRubricsRequirePublishPermission(t *testing.T) {
+	store := newIntegrationStore(t, "jane@example.com", "Secret123!")
+	store.permissions = map[int32][]string{3: {"admin.access", "content.publish"}}
+	ts := newTestServer(t, store)
+	topicID := int32(2)
+	rubric := writingRubricRequest{
+		TopicID: &topicID,
+		Name:    "Business email",
+		Criteria: []rubricCriterionRequest{
+			{Key: "task_response", Name: "Task completion", Weight: 60},
+			{Key: "organization", Name: "Organization", Weight: 30},
+		},
+	}
+
+	recorder := ts.requestJSON(t, http.MethodPost, "/api/v1/admin/writing-rubrics", rubric, 1)
+	assert.Equal(t, http.StatusForbidden, recorder.Code, recorder.Body.String())
+	recorder = ts.requestJSON(t, http.MethodPost, "/api/v1/admin/writing-rubrics", rubric, 3)
+	require.Equal(t, http.StatusBadRequest, recorder.Code, recorder.Body.String())
+	assert.Contains(t, recorder.Body.String(), "weights add up to 90 instead of 100")
+}
+
+func TestIntegrationExportUsersDecryptsEmails(t *testing.T) {
+	cfg := config.Config{
+		PIIEncryptionEnabled: true,
+		PIIEncryptionKeys:    "k1:" + strings.Repeat("0", 63) + "1",
+		PIIActiveKeyID:       "k1",
+		PIIBlindIndexKey:     strings.Repeat("x", 32),
+	}
+	protector, err := pii.NewProtector(cfg)
+	require.NoError(t, err)
+	encrypted, err := protector.Encrypt("jane@example.com")
+	require.NoError(t, err)
+
+	store := newIntegrationStore(t, "jane@example.com", "Secret123!")
+	store.users[0].Email = sql.NullString{}
+	store.users[0].EmailEncrypted = sql.NullString{String: encrypted, Valid: true}
+	store.permissions = map[int32][]string{3: {"admin.access"}}
+	ts := newTestServer(t, store, withConfig(func(c *config.Config) {
+		c.PIIEncryptionEnabled = cfg.PIIEncryptionEnabled
+		c.PIIEncryptionKeys = cfg.PIIEncryptionKeys
+		c.PIIActiveKeyID = cfg.PIIActiveKeyID
+		c.PIIBlindIndexKey = cfg.PIIBlindIndexKey
+	}))
+
+	recorder := ts.requestJSON(t, http.MethodGet, "/api/v1/users?format=csv", nil, 3)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	assert.Contains(t, recorder.Body.String(), "1,jane,jane@example.com,")
+	assert.NotContains(t, recorder.Body.String(), encrypted)
+}
+
+func TestIntegrationSearchBackupCatalog(t *testing.T) {
+	store := newIntegrationStore(t, "jane@example.com", "Secret123!")
+	store.permissions = map[int32][]string{3: {"admin.access"}}
+	day := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	store.backups = []db.Backup{
+		{Filename: "automatic_backup_20261003_030000.sql.gz.enc", Type: "automatic", Status: "completed", Size: 4096,
+			Checksum: "abc", Encrypted: true, KeyID: "k2", AppVersion: "1.2.0", DbVersion: "16.4", CreatedAt: day.Add(51 * time.Hour)},
+		{Filename: "manual_backup_20261002_120000.sql", Type: "manual", Status: "completed", Description: "Before the vocabulary import",
+			CreatedAt: day.Add(36 * time.Hour)},
+		{Filename: "manual_backup_20261001_120000.sql", Type: "manual", Status: "failed", CreatedAt: day.Add(12 * time.Hour)},
+		{Filename: "automatic_backup_20260930_030000.sql.gz", Type: "automatic", Status: "completed", CreatedAt: day.Add(-21 * time.Hour)},
+	}
+	ts := newTestServer(t, store)
+
+	list := func(query string) []backupListItem {
+		t.Helper()
+		recorder := ts.requestJSON(t, http.MethodGet, "/api/v1/admin/backups"+query, nil, 3)
+		require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+		var backups []backupListItem
+		decodeData(t, recorder, &backups)
+		return backups
+	}
+
+	backups := list("")
+	require.Len(t, backups, 3)
+	assert.Equal(t, "k2", backups[0].KeyID)
+	assert.Equal(t, "1.2.0", backups[0].AppVersion)
+	assert.Equal(t, "16.4", backups[0].DBVersion)
+	assert.True(t, backups[0].Encrypted)
+
+	backups = list("?type=automatic&from=2026-10-01T00:00:00Z")
+	require.Len(t, backups, 1)
+	assert.Equal(t, "automatic_backup_20261003_030000.sql.gz.enc", backups[0].Filename)
+	backups = list("?to=2026-10-03T00:00:00Z&q=VOCABULARY")
+	require.Len(t, backups, 1)
+	assert.Equal(t, "manual_backup_20261002_120000.sql", backups[0].Filename)
+	assert.Empty(t, list("?q=nothing"))
+
+	recorder := ts.requestJSON(t, http.MethodGet, "/api/v1/admin/backups?from=yesterday", nil, 3)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code, recorder.Body.String())
+	recorder = ts.requestJSON(t, http.MethodGet, "/api/v1/admin/backups?from=2026-10-02T00:00:00Z&to=2026-10-01T00:00:00Z", nil, 3)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code, recorder.Body.String())
+	recorder = ts.requestJSON(t, http.MethodGet, "/api/v1/admin/backups", nil, 1)
+	assert.Equal(t, http.StatusForbidden, recorder.Code, recorder.Body.String())
+}
+
+func TestIntegrationCancelRestoreWhileReadOnly(t *testing.T) {
+	// The database tools hang until the restore is cancelled
+	dir := t.TempDir()
+	tool := filepath.Join(dir, "pg-tool")
+	require.NoError(t, os.WriteFile(tool, []byte("#!/bin/sh\nexec sleep 60\n"), 0755))
+	backupDir := filepath.Join(dir, "backups")
+	require.NoError(t, os.MkdirAll(backupDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(backupDir, "manual_backup_20261002_120000.sql"),
+		[]byte("SET client_encoding = 'UTF8';\n"), 0644))
+	t.Setenv("BACKUP_DIR", backupDir)
+	t.Setenv("BACKUP_PSQL_PATH", tool)
+	t.Setenv("BACKUP_PG_DUMP_PATH", tool)
+	t.Setenv("BACKUP_VALIDATE_BEFORE_RESTORE", "false")
+	t.Setenv("BACKUP_MAX_RETRIES", "1")
+
+	store := newIntegrationStore(t, "jane@example.com", "Secret123!")
+	store.permissions = map[int32][]string{3: {"admin.access"}}
+	ts := newTestServer(t, store)
+
+	recorder := ts.requestJSON(t, http.MethodPost, "/api/v1/admin/backups/enhanced/restore",
+		map[string]any{"filename": "manual_backup_20261002_120000.sql", "async": true}, 3)
+	require.Equal(t, http.StatusAccepted, recorder.Code, recorder.Body.String())
+	var restore backup.Restore
+	decodeData(t, recorder, &restore)
+	require.True(t, ts.readOnly.Status(context.Background()).Enabled)
+
+	// The restore keeps the API read-only, but can still be cancelled
+	recorder = ts.requestJSON(t, http.MethodPost, "/api/v1/admin/backups/restores/"+restore.ID+"/cancel", nil, 3)
+	require.Equal(t, http.StatusAccepted, recorder.Code, recorder.Body.String())
+
+	require.Eventually(t, func() bool {
+		polled, err := ts.backupRestores.Get(restore.ID)
+		return err == nil && polled.State != backup.RestoreRunning
+	}, 10*time.Second, 20*time.Millisecond)
+	polled, err := ts.backupRestores.Get(restore.ID)
+	require.NoError(t, err)
+	assert.Equal(t, backup.RestoreCanceled, polled.State, polled.Error)
+	assert.Eventually(t, func() bool {
+		return !ts.readOnly.Status(context.Background()).Enabled
+	}, 5*time.Second, 20*time.Millisecond)
+}
+
+func TestIntegrationFollowRestore(t *testing.T) {
+	store := newIntegrationStore(t, "jane@example.com", "Secret123!")
+	store.permissions = map[int32][]string{3: {"admin.access"}}
+	ts := newTestServer(t, store)
+
+	// Only administrators follow restores on the admin topic
+	assert.True(t, ts.authorizeTopic(3, backup.RestoreTopic))
+	assert.False(t, ts.authorizeTopic(1, backup.RestoreTopic))
+	assert.True(t, ts.authorizeTopic(1, "upgrades"))
+
+	restore, restoreCtx, err := ts.backupRestores.Start(context.Background(), "manual_backup_20261002_120000.sql", nil, "admin")
+	require.NoError(t, err)
+
+	recorder := ts.requestJSON(t, http.MethodPost, "/api/v1/admin/backups/enhanced/restore",
+		map[string]any{"filename": "manual_backup_20261002_120000.sql", "async": true}, 3)
+	assert.Equal(t, http.StatusConflict, recorder.Code, recorder.Body.String())
+
+	recorder = ts.requestJSON(t, http.MethodGet, "/api/v1/admin/backups/restores/"+restore.ID, nil, 3)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	var polled backup.Restore
+	decodeData(t, recorder, &polled)
+	assert.Equal(t, backup.RestoreRunning, polled.State)
+	assert.Equal(t, backup.PhasePreparing, polled.Progress.Phase)
+
+	recorder = ts.requestJSON(t, http.MethodPost, "/api/v1/admin/backups/restores/"+restore.ID+"/cancel", nil, 3)
+	require.Equal(t, http.StatusAccepted, recorder.Code, recorder.Body.String())
+	assert.ErrorIs(t, restoreCtx.Err(), context.Canceled)
+	ts.backupRestores.Finish(restore.ID, nil, restoreCtx.Err())
+
+	recorder = ts.requestJSON(t, http.MethodGet, "/api/v1/admin/backups/restores", nil, 3)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	var restores []backup.Restore
+	decodeData(t, recorder, &restores)
+	require.Len(t, restores, 1)
+	assert.Equal(t, backup.RestoreCanceled, restores[0].State)
+
+	recorder = ts.requestJSON(t, http.MethodPost, "/api/v1/admin/backups/restores/"+restore.ID+"/cancel", nil, 3)
+	assert.Equal(t, http.StatusConflict, recorder.Code, recorder.Body.String())
+	recorder = ts.requestJSON(t, http.MethodGet, "/api/v1/admin/backups/restores/missing", nil, 3)
+	assert.Equal(t, http.StatusNotFound, recorder.Code, recorder.Body.String())
+	recorder = ts.requestJSON(t, http.MethodGet, "/api/v1/admin/backups/restores", nil, 1)
+	assert.Equal(t, http.StatusForbidden, recorder.Code, recorder.Body.String())
+}
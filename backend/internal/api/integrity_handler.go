@@ -0,0 +1,179 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/accesslog"
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/integrity"
+	"github.com/toeic-app/internal/rbac"
+	"github.com/toeic-app/internal/token"
+)
+
+// staffIntegritySummary lets users with exams.grade read another user's
+// attempt and returns its integrity score. The read is recorded in the
+// owner's access log. It writes an error response and returns false when
+// the reader may not see the attempt or the read could not be recorded.
+func (server *Server) staffIntegritySummary(ctx *gin.Context, readerID int32, attempt db.ExamAttempt, resourceType string) (*integrity.Summary, bool) {
+	check, err := server.rbacService.CheckPermission(ctx, readerID, rbac.PermExamGrade)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_verify_exam_attempt", err)
+		return nil, false
+	}
+	if !check.HasPermission {
+		ErrorResponse(ctx, http.StatusNotFound, "exam_attempt_not_found", sql.ErrNoRows)
+		return nil, false
+	}
+	if !server.recordDataAccess(ctx, accesslog.Access{
+		SubjectID:    attempt.UserID,
+		ResourceType: resourceType,
+		ResourceID:   attempt.AttemptID,
+	}) {
+		return nil, false
+	}
+
+	summary, err := server.integrity.Summarize(ctx, attempt.AttemptID)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_retrieve_integrity_report", err)
+		return nil, false
+	}
+	return summary, true
+}
+
+// inProgressAttempt returns one of the caller's attempts that is still in
+// progress. It writes an error response and returns false otherwise.
+func (server *Server) inProgressAttempt(ctx *gin.Context, attemptID int32) (db.ExamAttempt, bool) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	attempt, err := server.store.GetExamAttemptByUser(ctx, db.GetExamAttemptByUserParams{
+		AttemptID: attemptID,
+		UserID:    authPayload.ID,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ErrorResponse(ctx, http.StatusNotFound, "exam_attempt_not_found", err)
+			return db.ExamAttempt{}, false
+		}
+		ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_retrieve_exam_attempt", err)
+		return db.ExamAttempt{}, false
+	}
+	if attempt.Status != db.ExamStatusEnumInProgress {
+		ErrorResponse(ctx, http.StatusConflict, "exam_attempt_not_in_progress", nil)
+		return db.ExamAttempt{}, false
+	}
+	return attempt, true
+}
+
+type integrityEventRequest struct {
+	Type string `json:"type" binding:"required,oneof=focus_loss tab_switch paste"`
+	// OccurredAt defaults to the time the event is received
+	OccurredAt  *time.Time `json:"occurred_at"`
+	DurationMs  int32      `json:"duration_ms" binding:"min=0"`
+	PasteLength int32      `json:"paste_length" binding:"min=0"`
+}
+
+type reportIntegrityEventsRequest struct {
+	Events []integrityEventRequest `json:"events" binding:"required,min=1,max=100,dive"`
+}
+
+// @Summary     Report exam integrity events
+// @Description Records focus losses, tab switches and pastes that happened during one of your in-progress exam attempts. Send up to 100 events at a time. Times outside the attempt are moved to its start or to now. Teachers see the resulting integrity score.
+// @Tags        exam-attempts
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Exam Attempt ID"
+// @Param       request body reportIntegrityEventsRequest true "Events"
+// @Success     201 {object} Response "Integrity events recorded successfully"
+// @Failure     400 {object} Response "Invalid request"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     404 {object} Response "Exam attempt not found"
+// @Failure     409 {object} Response "Exam attempt is not in progress"
+// @Failure     500 {object} Response "Failed to record integrity events"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/exam-attempts/{id}/integrity-events [post]
+func (server *Server) reportIntegrityEvents(ctx *gin.Context) {
+	var uri getExamAttemptRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "invalid_attempt_id", err)
+		return
+	}
+	var req reportIntegrityEventsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "invalid_request_body", err)
+		return
+	}
+	attempt, ok := server.inProgressAttempt(ctx, uri.AttemptID)
+	if !ok {
+		return
+	}
+
+	events := make([]integrity.Event, 0, len(req.Events))
+	for _, event := range req.Events {
+		recorded := integrity.Event{Type: event.Type, DurationMs: event.DurationMs, PasteLength: event.PasteLength}
+		if event.OccurredAt != nil {
+			recorded.OccurredAt = *event.OccurredAt
+		}
+		events = append(events, recorded)
+	}
+	if err := server.integrity.Record(ctx, attempt, events); err != nil {
+		if errors.Is(err, integrity.ErrUnknownEventType) {
+			ErrorResponse(ctx, http.StatusBadRequest, "invalid_request_body", err)
+			return
+		}
+		ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_record_integrity_events", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusCreated, "integrity_events_recorded_successfully", nil)
+}
+
+// @Summary     Get exam attempt integrity
+// @Description Returns the integrity score of an attempt with the focus losses, tab switches and pastes reported during it, oldest first. The score starts at 100 and is lowered for every event and every minute out of focus; levels are clean (90 and above), review (60 and above) and suspicious. Requires exams.grade. Reads of other users' attempts are recorded in their access log.
+// @Tags        exam-attempts
+// @Produce     json
+// @Param       id path int true "Exam Attempt ID"
+// @Param       X-Access-Purpose header string false "Why the attempt is read: support, grading, moderation, legal or unspecified"
+// @Success     200 {object} Response{data=integrity.Report} "Integrity report retrieved successfully"
+// @Failure     400 {object} Response "Invalid attempt ID or access purpose"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     403 {object} Response "Permission denied"
+// @Failure     404 {object} Response "Exam attempt not found"
+// @Failure     500 {object} Response "Failed to retrieve integrity report"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/exam-attempts/{id}/integrity [get]
+func (server *Server) getAttemptIntegrity(ctx *gin.Context) {
+	var uri getExamAttemptRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "invalid_attempt_id", err)
+		return
+	}
+
+	attempt, err := server.store.GetExamAttempt(ctx, uri.AttemptID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ErrorResponse(ctx, http.StatusNotFound, "exam_attempt_not_found", err)
+			return
+		}
+		ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_retrieve_exam_attempt", err)
+		return
+	}
+	if !server.recordDataAccess(ctx, accesslog.Access{
+		SubjectID:    attempt.UserID,
+		ResourceType: accesslog.ResourceIntegrityEvents,
+		ResourceID:   attempt.AttemptID,
+	}) {
+		return
+	}
+
+	report, err := server.integrity.Report(ctx, attempt)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_retrieve_integrity_report", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "integrity_report_retrieved_successfully", report)
+}
@@ -0,0 +1,212 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/jobs"
+	"github.com/toeic-app/internal/token"
+)
+
+// createJobRequest starts a background job of a registered kind
+type createJobRequest struct {
+	Kind   string          `json:"kind" binding:"required" example:"account_data"`
+	Params json.RawMessage `json:"params" swaggertype:"object"`
+}
+
+type listJobsQuery struct {
+	Limit  int32 `form:"limit,default=20" binding:"min=1,max=100"`
+	Offset int32 `form:"offset" binding:"min=0"`
+}
+
+// jobError maps job service errors to responses
+func jobError(ctx *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, jobs.ErrJobNotFound):
+		ErrorResponse(ctx, http.StatusNotFound, "Job not found", err)
+	case errors.Is(err, jobs.ErrUnknownKind), errors.Is(err, jobs.ErrInvalidParams):
+		ErrorResponse(ctx, http.StatusBadRequest, err.Error(), err)
+	case errors.Is(err, jobs.ErrJobFinished):
+		ErrorResponse(ctx, http.StatusConflict, "Job already finished", err)
+	case errors.Is(err, jobs.ErrNoResult):
+		ErrorResponse(ctx, http.StatusConflict, "Job has no result to download", err)
+	default:
+		ErrorResponse(ctx, http.StatusInternalServerError, message, err)
+	}
+}
+
+// @Summary     Start a background job
+// @Description Queues a long-running export or report and returns at once. Follow it with GET /api/v1/jobs/{id} or the job.progress WebSocket events, then download the file from GET /api/v1/jobs/{id}/result. Kinds: account_data exports everything stored about me as JSON; regrade_answers (params: question_ids; requires exams.grade) re-grades past answers to questions whose correct answer was fixed, rescores the completed attempts, refreshes their leaderboards and notifies the users with exam.answers_regraded events, reporting the changed attempts as JSON.
+// @Tags        jobs
+// @Accept      json
+// @Produce     json
+// @Param       job body createJobRequest true "Job kind and parameters"
+// @Success     202 {object} Response{data=jobs.Job} "Job queued successfully"
+// @Failure     400 {object} Response "Invalid request parameters"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     403 {object} Response "Permission denied"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/jobs [post]
+func (server *Server) createJob(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	var req createJobRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request parameters", err)
+		return
+	}
+
+	handler, ok := server.jobs.Handler(req.Kind)
+	if !ok {
+		ErrorResponse(ctx, http.StatusBadRequest, jobs.ErrUnknownKind.Error(), jobs.ErrUnknownKind)
+		return
+	}
+	if handler.Permission != "" {
+		check, err := server.rbacService.CheckPermission(ctx, authPayload.ID, handler.Permission)
+		if err != nil {
+			ErrorResponse(ctx, http.StatusInternalServerError, "Failed to check permission", err)
+			return
+		}
+		if !check.HasPermission {
+			ErrorResponse(ctx, http.StatusForbidden, "Permission denied", nil)
+			return
+		}
+	}
+
+	job, err := server.jobs.Create(ctx, authPayload.ID, req.Kind, req.Params)
+	if err != nil {
+		jobError(ctx, err, "Failed to queue job")
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusAccepted, "Job queued successfully", job)
+}
+
+// @Summary     List my background jobs
+// @Description Lists the jobs I started, newest first
+// @Tags        jobs
+// @Produce     json
+// @Param       limit query int false "Maximum jobs" default(20)
+// @Param       offset query int false "Jobs to skip" default(0)
+// @Success     200 {object} Response{data=[]jobs.Job} "Jobs retrieved successfully"
+// @Failure     400 {object} Response "Invalid query parameters"
+// @Failure     401 {object} Response "Unauthorized"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/jobs [get]
+func (server *Server) listJobs(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	var query listJobsQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	list, total, err := server.jobs.List(ctx, authPayload.ID, query.Limit, query.Offset)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to list jobs", err)
+		return
+	}
+
+	PaginatedResponse(ctx, http.StatusOK, "Jobs retrieved successfully", list,
+		NewPagination(query.Limit, query.Offset, len(list)).WithTotal(total))
+}
+
+// @Summary     Get a background job
+// @Description Returns the status and progress of one of my jobs
+// @Tags        jobs
+// @Produce     json
+// @Param       id path int true "Job ID"
+// @Success     200 {object} Response{data=jobs.Job} "Job retrieved successfully"
+// @Failure     400 {object} Response "Invalid job ID"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     404 {object} Response "Job not found"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/jobs/{id} [get]
+func (server *Server) getJob(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid job ID", err)
+		return
+	}
+
+	job, err := server.jobs.Get(ctx, authPayload.ID, int32(id))
+	if err != nil {
+		jobError(ctx, err, "Failed to get job")
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Job retrieved successfully", job)
+}
+
+// @Summary     Cancel a background job
+// @Description Stops one of my jobs while it is queued or running. Its partial result is discarded.
+// @Tags        jobs
+// @Produce     json
+// @Param       id path int true "Job ID"
+// @Success     200 {object} Response{data=jobs.Job} "Job cancelled successfully"
+// @Failure     400 {object} Response "Invalid job ID"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     404 {object} Response "Job not found"
+// @Failure     409 {object} Response "Job already finished"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/jobs/{id}/cancel [post]
+func (server *Server) cancelJob(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid job ID", err)
+		return
+	}
+
+	job, err := server.jobs.Cancel(ctx, authPayload.ID, int32(id))
+	if err != nil {
+		jobError(ctx, err, "Failed to cancel job")
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Job cancelled successfully", job)
+}
+
+// @Summary     Download the result of a background job
+// @Description Downloads the file one of my succeeded jobs produced, until the result expires
+// @Tags        jobs
+// @Produce     octet-stream
+// @Param       id path int true "Job ID"
+// @Success     200 {file} file "Job result"
+// @Failure     400 {object} Response "Invalid job ID"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     404 {object} Response "Job not found"
+// @Failure     409 {object} Response "Job has no result to download"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/jobs/{id}/result [get]
+func (server *Server) downloadJobResult(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid job ID", err)
+		return
+	}
+
+	result, err := server.jobs.Result(ctx, authPayload.ID, int32(id))
+	if err != nil {
+		jobError(ctx, err, "Failed to get job result")
+		return
+	}
+	if _, err := os.Stat(result.Path); err != nil {
+		ErrorResponse(ctx, http.StatusNotFound, "Job result file not found", err)
+		return
+	}
+
+	ctx.Header("Content-Type", result.ContentType)
+	ctx.Header("Cache-Control", "no-store")
+	ctx.FileAttachment(result.Path, result.Name)
+}
@@ -0,0 +1,168 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	configPkg "github.com/toeic-app/internal/config"
+	"github.com/toeic-app/internal/leader"
+	"github.com/toeic-app/internal/logger"
+)
+
+// newLeaderElector picks the election backend from configuration. Without
+// leader election the instance always acts as leader.
+func newLeaderElector(config configPkg.Config, dbConn *sql.DB) *leader.Elector {
+	instanceID := config.InstanceID
+	if instanceID == "" {
+		instanceID = leader.DefaultInstanceID()
+	}
+	interval := config.LeaderElectionTTL / 3
+
+	if !config.LeaderElectionEnabled {
+		return leader.NewElector(leader.NewLocalBackend(instanceID), instanceID, interval)
+	}
+
+	var backend leader.Backend
+	switch config.LeaderElectionBackend {
+	case "redis":
+		client := redis.NewClient(&redis.Options{
+			Addr:     config.RedisAddr,
+			Password: config.RedisPassword,
+			DB:       config.RedisDB,
+		})
+		backend = leader.NewRedisBackend(client, config.LeaderElectionKey, instanceID, config.LeaderElectionTTL)
+	default:
+		backend = leader.NewPostgresBackend(dbConn, config.LeaderElectionLockID, instanceID)
+	}
+
+	logger.Info("Leader election enabled (backend: %s, instance: %s)", backend.Name(), instanceID)
+	return leader.NewElector(backend, instanceID, interval)
+}
+
+// StartLeaderElection begins competing for leadership. Scheduled tasks start
+// once this instance is elected.
+func (server *Server) StartLeaderElection(ctx context.Context) {
+	server.leaderElector.Start(ctx)
+}
+
+// IsLeader reports whether this instance should run scheduled tasks
+func (server *Server) IsLeader() bool {
+	return server.leaderElector == nil || server.leaderElector.IsLeader()
+}
+
+// startLeaderTasks starts schedulers that must only run on one instance
+func (server *Server) startLeaderTasks() {
+	if server.migrationBackup != nil {
+		server.migrationBackup.Do(func() { go server.backupBeforePendingMigrations() })
+	}
+	if server.enhancedBackupScheduler != nil && !server.enhancedBackupScheduler.IsRunning() {
+		if err := server.enhancedBackupScheduler.Start(); err != nil {
+			logger.Error("Failed to start backup scheduler on leader: %v", err)
+		}
+	}
+	if server.backupMonitor != nil && !server.backupMonitor.IsVerifying() {
+		if err := server.backupMonitor.StartVerification(); err != nil {
+			logger.Error("Failed to start backup verification on leader: %v", err)
+		}
+	}
+	if server.achievements != nil && !server.achievements.IsRunning() {
+		if err := server.achievements.Start(server.config.AchievementEvalInterval); err != nil {
+			logger.Error("Failed to start achievement evaluator on leader: %v", err)
+		}
+	}
+	if server.billing != nil && !server.billing.IsRunning() {
+		if err := server.billing.Start(server.config.BillingReconcileInterval); err != nil {
+			logger.Error("Failed to start subscription reconciler on leader: %v", err)
+		}
+	}
+	if server.related != nil && !server.related.IsRunning() {
+		if err := server.related.Start(server.config.RelatedContentRebuildInterval); err != nil {
+			logger.Error("Failed to start related content rebuilder on leader: %v", err)
+		}
+	}
+	if server.calibration != nil && !server.calibration.IsRunning() {
+		if err := server.calibration.Start(server.config.CalibrationInterval); err != nil {
+			logger.Error("Failed to start difficulty calibrator on leader: %v", err)
+		}
+	}
+	if server.predictions != nil && !server.predictions.IsRunning() {
+		if err := server.predictions.Start(server.config.PredictionInterval); err != nil {
+			logger.Error("Failed to start score predictor on leader: %v", err)
+		}
+	}
+	if server.accessLog != nil && !server.accessLog.IsRunning() {
+		if err := server.accessLog.Start(server.config.DataAccessLogPruneInterval); err != nil {
+			logger.Error("Failed to start data access log pruner on leader: %v", err)
+		}
+	}
+	if server.warehouse != nil && !server.warehouse.IsRunning() {
+		if err := server.warehouse.Start(server.config.WarehouseExportInterval); err != nil {
+			logger.Error("Failed to start warehouse exporter on leader: %v", err)
+		}
+	}
+}
+
+// stopLeaderTasks stops leader-only schedulers after losing leadership
+func (server *Server) stopLeaderTasks() {
+	if server.enhancedBackupScheduler != nil && server.enhancedBackupScheduler.IsRunning() {
+		if err := server.enhancedBackupScheduler.Stop(); err != nil {
+			logger.Error("Failed to stop backup scheduler: %v", err)
+		}
+	}
+	if server.backupMonitor != nil && server.backupMonitor.IsVerifying() {
+		if err := server.backupMonitor.StopVerification(); err != nil {
+			logger.Error("Failed to stop backup verification: %v", err)
+		}
+	}
+	if server.achievements != nil && server.achievements.IsRunning() {
+		if err := server.achievements.Stop(); err != nil {
+			logger.Error("Failed to stop achievement evaluator: %v", err)
+		}
+	}
+	if server.billing != nil && server.billing.IsRunning() {
+		if err := server.billing.Stop(); err != nil {
+			logger.Error("Failed to stop subscription reconciler: %v", err)
+		}
+	}
+	if server.related != nil && server.related.IsRunning() {
+		if err := server.related.Stop(); err != nil {
+			logger.Error("Failed to stop related content rebuilder: %v", err)
+		}
+	}
+	if server.calibration != nil && server.calibration.IsRunning() {
+		if err := server.calibration.Stop(); err != nil {
+			logger.Error("Failed to stop difficulty calibrator: %v", err)
+		}
+	}
+	if server.predictions != nil && server.predictions.IsRunning() {
+		if err := server.predictions.Stop(); err != nil {
+			logger.Error("Failed to stop score predictor: %v", err)
+		}
+	}
+	if server.accessLog != nil && server.accessLog.IsRunning() {
+		if err := server.accessLog.Stop(); err != nil {
+			logger.Error("Failed to stop data access log pruner: %v", err)
+		}
+	}
+	if server.warehouse != nil && server.warehouse.IsRunning() {
+		if err := server.warehouse.Stop(); err != nil {
+			logger.Error("Failed to stop warehouse exporter: %v", err)
+		}
+	}
+}
+
+// @Summary Get leader election status
+// @Description Show whether this instance is the leader and which instance currently leads
+// @Tags admin
+// @Produce json
+// @Success 200 {object} Response{data=leader.Status} "Leader status retrieved successfully"
+// @Failure 401 {object} Response "Unauthorized"
+// @Failure 403 {object} Response "Forbidden"
+// @Security ApiKeyAuth
+// @Router /api/v1/admin/system/leader [get]
+func (server *Server) getLeaderStatus(ctx *gin.Context) {
+	SuccessResponse(ctx, http.StatusOK, "Leader status retrieved successfully", server.leaderElector.Status(ctx))
+}
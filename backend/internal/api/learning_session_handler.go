@@ -1,17 +1,20 @@
 package api
 
 import (
-	"database/sql"
 	"encoding/json"
+	"errors"
 	"math/rand"
 	"net/http"
-	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/sqlc-dev/pqtype"
 	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/learningservice"
+	"github.com/toeic-app/internal/logger"
+	"github.com/toeic-app/internal/preferences"
+	"github.com/toeic-app/internal/social"
 	"github.com/toeic-app/internal/token"
+	"github.com/toeic-app/internal/vocabstats"
 )
 
 // LearningSessionResponse represents a learning session response
@@ -60,11 +63,13 @@ type FlashcardQuestion struct {
 	Level         int32  `json:"level"`
 }
 
-// MultipleChoiceQuestion represents a multiple choice question
+// MultipleChoiceQuestion represents a multiple choice question on one sense of a word
 type MultipleChoiceQuestion struct {
 	WordID        int32    `json:"word_id"`
 	Word          string   `json:"word"`
 	Pronunciation string   `json:"pronunciation,omitempty"`
+	SenseID       *int32   `json:"sense_id,omitempty"` // Send back with the attempt
+	PartOfSpeech  string   `json:"part_of_speech,omitempty"`
 	Options       []string `json:"options"`
 	CorrectAnswer string   `json:"correct_answer"`
 }
@@ -106,14 +111,44 @@ type SessionStats struct {
 	AccuracyPercentage float64 `json:"accuracy_percentage"`
 }
 
+// LearningSessionSummaryResponse represents a completed learning session
+// with everything the end-of-session summary shows
+type LearningSessionSummaryResponse struct {
+	LearningSessionResponse
+	Stats         SessionStats                  `json:"stats"`
+	Words         []learningservice.WordOutcome `json:"words"`
+	ReviewWordIDs []int32                       `json:"review_word_ids"` // Words answered wrong, most often missed first
+	XPEarned      int32                         `json:"xp_earned"`       // Includes the bonus of a streak milestone
+	Streak        *social.StreakUpdate          `json:"streak,omitempty"` // Left out when the activity could not be recorded
+}
+
 // createLearningSessionRequest defines the structure for creating a learning session
 type createLearningSessionRequest struct {
 	StudySetID    *int32        `json:"study_set_id,omitempty"`
 	SessionType   string        `json:"session_type" binding:"required,oneof=flashcard match quiz type"`
-	WordLimit     int32         `json:"word_limit,default=10" binding:"min=1,max=50"`
+	WordLimit     int32         `json:"word_limit" binding:"omitempty,min=1,max=50"` // Defaults to the session preferences of the user
 	SessionConfig *SessionConfig `json:"session_config,omitempty"`
 }
 
+// applySessionPreferences fills the session settings missing from a request with the user's defaults
+func applySessionPreferences(req *createLearningSessionRequest, defaults preferences.Session) {
+	if req.WordLimit == 0 {
+		req.WordLimit = defaults.WordLimit
+	}
+	if req.SessionConfig == nil {
+		req.SessionConfig = &SessionConfig{}
+	}
+	if req.SessionConfig.TimeLimit == nil && defaults.TimeLimit > 0 {
+		req.SessionConfig.TimeLimit = &defaults.TimeLimit
+	}
+	if req.SessionConfig.ShowHints == nil {
+		req.SessionConfig.ShowHints = &defaults.ShowHints
+	}
+	if req.SessionConfig.ShuffleAnswers == nil {
+		req.SessionConfig.ShuffleAnswers = &defaults.ShuffleAnswers
+	}
+}
+
 // SessionConfig represents optional configuration for a learning session
 type SessionConfig struct {
 	TimeLimit       *int32 `json:"time_limit,omitempty"`        // Time limit in seconds
@@ -126,6 +161,7 @@ type SessionConfig struct {
 // submitLearningAttemptRequest defines the structure for submitting a learning attempt
 type submitLearningAttemptRequest struct {
 	WordID           int32  `json:"word_id" binding:"required,min=1"`
+	SenseID          *int32 `json:"sense_id,omitempty" binding:"omitempty,min=1"` // The sense a quiz question asked for
 	AttemptType      string `json:"attempt_type" binding:"required"`
 	UserAnswer       string `json:"user_answer"`
 	ResponseTimeMs   *int32 `json:"response_time_ms,omitempty"`
@@ -231,7 +267,7 @@ func NewLearningSessionResponse(session db.LearningSession) LearningSessionRespo
 }
 
 // @Summary Start a new learning session
-// @Description Start a new vocabulary learning session
+// @Description Start a new vocabulary learning session. The word limit, time limit, hints and answer shuffling default to the session preferences of the user.
 // @Tags learning
 // @Accept json
 // @Produce json
@@ -251,68 +287,32 @@ func (server *Server) startLearningSession(ctx *gin.Context) {
 
 	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
 
-	// Get words for the session
-	var words []db.Word
-	var err error
-
-	if req.StudySetID != nil {
-		// Get words from study set
-		studySetWords, err := server.store.GetStudySetWords(ctx, *req.StudySetID)
-		if err != nil {
-			ErrorResponse(ctx, http.StatusInternalServerError, "Failed to get study set words", err)
-			return
-		}
-
-		// Convert to []db.Word
-		for _, studySetWord := range studySetWords {
-			words = append(words, studySetWord.Word)
-		}
-
-		// Check if user has access to the study set
-		studySet, err := server.store.GetStudySet(ctx, *req.StudySetID)
-		if err != nil {
-			ErrorResponse(ctx, http.StatusNotFound, "Study set not found", err)
-			return
-		}
-		if studySet.UserID != authPayload.ID && (!studySet.IsPublic.Valid || !studySet.IsPublic.Bool) {
-			ErrorResponse(ctx, http.StatusForbidden, "Access denied to study set", nil)
-			return
-		}
-	} else {
-		// Get words that need review
-		reviewWords, err := server.store.GetWordsNeedingReview(ctx, db.GetWordsNeedingReviewParams{
-			UserID:       authPayload.ID,
-			MasteryLevel: sql.NullInt32{Int32: 8, Valid: true}, // Words with mastery level < 8
-			Limit:        req.WordLimit,
-		})
-		if err != nil {
-			ErrorResponse(ctx, http.StatusInternalServerError, "Failed to get words for review", err)
-			return
-		}
-
-		// Extract words from the result
-		for _, review := range reviewWords {
-			words = append(words, review.Word)
-		}
+	// Fill the settings the client left out from the user's session preferences
+	prefs, err := server.preferences.Get(ctx, authPayload.ID)
+	if err != nil {
+		logger.Warn("Using default session preferences for user %d: %v", authPayload.ID, err)
 	}
+	applySessionPreferences(&req, prefs.Session)
 
-	if len(words) == 0 {
-		ErrorResponse(ctx, http.StatusBadRequest, "No words available for learning session", nil)
+	// Get words for the session
+	words, err := server.learning.SessionWords(ctx, authPayload.ID, req.StudySetID, req.WordLimit)
+	if err != nil {
+		learningServiceError(ctx, err, "Failed to get words for learning session")
 		return
 	}
 
-	// Limit the number of words
-	if len(words) > int(req.WordLimit) {
-		words = words[:req.WordLimit]
-	}
-
 	// Generate session questions based on session type
 	sessionData := make(map[string]interface{})
 	switch req.SessionType {
 	case "flashcard":
 		sessionData["questions"] = generateFlashcardQuestions(words)
 	case "quiz":
-		sessionData["questions"] = generateMultipleChoiceQuestions(words)
+		questions, err := server.generateMultipleChoiceQuestions(ctx, words)
+		if err != nil {
+			ErrorResponse(ctx, http.StatusInternalServerError, "Failed to generate quiz questions", err)
+			return
+		}
+		sessionData["questions"] = questions
 	case "match":
 		sessionData["pairs"] = generateMatchPairs(words)
 	case "type":
@@ -338,20 +338,14 @@ func (server *Server) startLearningSession(ctx *gin.Context) {
 		}
 	}
 
-	sessionDataJSON, _ := json.Marshal(sessionData)
-
-	var studySetID sql.NullInt32
-	if req.StudySetID != nil {
-		studySetID = sql.NullInt32{Int32: *req.StudySetID, Valid: true}
-	}
-
 	// Create the learning session
-	session, err := server.store.CreateLearningSession(ctx, db.CreateLearningSessionParams{
-		UserID:         authPayload.ID,
-		StudySetID:     studySetID,
-		SessionType:    req.SessionType,
-		TotalQuestions: sql.NullInt32{Int32: int32(len(words)), Valid: true},
-		SessionData:    pqtype.NullRawMessage{RawMessage: sessionDataJSON, Valid: true},
+	session, err := server.learning.StartSession(ctx, learningservice.NewSession{
+		UserID:      authPayload.ID,
+		StudySetID:  req.StudySetID,
+		SessionType: req.SessionType,
+		Words:       int32(len(words)),
+		WordIDs:     wordIDs(words),
+		Data:        sessionData,
 	})
 	if err != nil {
 		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to create learning session", err)
@@ -384,16 +378,9 @@ func (server *Server) getLearningSession(ctx *gin.Context) {
 
 	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
 
-	session, err := server.store.GetLearningSession(ctx, db.GetLearningSessionParams{
-		ID:     req.ID,
-		UserID: authPayload.ID,
-	})
+	session, err := server.learning.Session(ctx, authPayload.ID, req.ID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ErrorResponse(ctx, http.StatusNotFound, "Learning session not found", err)
-			return
-		}
-		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve learning session", err)
+		learningServiceError(ctx, err, "Failed to retrieve learning session")
 		return
 	}
 
@@ -430,77 +417,29 @@ func (server *Server) submitLearningAttempt(ctx *gin.Context) {
 
 	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
 
-	// Verify session belongs to user
-	_, err := server.store.GetLearningSession(ctx, db.GetLearningSessionParams{
-		ID:     uriReq.ID,
-		UserID: authPayload.ID,
-	})
-	if err != nil {
-		if err == sql.ErrNoRows {
-			ErrorResponse(ctx, http.StatusNotFound, "Learning session not found", err)
-			return
-		}
-		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve learning session", err)
-		return
-	}
-
-	// Get the word to determine correct answer
-	word, err := server.store.GetWord(ctx, req.WordID)
-	if err != nil {
-		ErrorResponse(ctx, http.StatusNotFound, "Word not found", err)
-		return
-	}
-
-	// Determine if answer is correct based on attempt type
-	isCorrect := false
-	correctAnswer := word.ShortMean
-	switch req.AttemptType {
-	case "flashcard":
-		// For flashcard, any non-empty answer is considered an attempt
-		isCorrect = true // User self-reports correctness in flashcard mode
-	case "multiple_choice", "quiz":
-		isCorrect = req.UserAnswer == correctAnswer
-	case "type":
-		// For type mode, we do a more flexible comparison
-		isCorrect = compareTypedAnswer(req.UserAnswer, correctAnswer, word.Word)
-	case "match":
-		isCorrect = req.UserAnswer == correctAnswer
-	}
-
-	// Create learning attempt
-	var responseTime sql.NullInt32
-	if req.ResponseTimeMs != nil {
-		responseTime = sql.NullInt32{Int32: *req.ResponseTimeMs, Valid: true}
-	}
-
-	var difficultyRating sql.NullInt32
-	if req.DifficultyRating != nil {
-		difficultyRating = sql.NullInt32{Int32: *req.DifficultyRating, Valid: true}
-	}
-
-	attempt, err := server.store.CreateLearningAttempt(ctx, db.CreateLearningAttemptParams{
-		SessionID:        uriReq.ID,
+	// The session must belong to the user
+	graded, err := server.learning.SubmitAttempt(ctx, authPayload.ID, uriReq.ID, learningservice.Attempt{
 		WordID:           req.WordID,
+		SenseID:          req.SenseID,
 		AttemptType:      req.AttemptType,
-		UserAnswer:       sql.NullString{String: req.UserAnswer, Valid: req.UserAnswer != ""},
-		CorrectAnswer:    correctAnswer,
-		IsCorrect:        isCorrect,
-		ResponseTimeMs:   responseTime,
-		DifficultyRating: difficultyRating,
+		UserAnswer:       req.UserAnswer,
+		ResponseTimeMs:   req.ResponseTimeMs,
+		DifficultyRating: req.DifficultyRating,
 	})
 	if err != nil {
-		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to create learning attempt", err)
+		learningServiceError(ctx, err, "Failed to create learning attempt")
 		return
 	}
+	attempt := graded.Attempt
 
 	// Update vocabulary statistics
-	go server.updateVocabularyStats(authPayload.ID, req.WordID, isCorrect, req.ResponseTimeMs, req.DifficultyRating)
+	server.updateVocabularyStats(authPayload.ID, req.WordID, attempt.IsCorrect, req.ResponseTimeMs, req.DifficultyRating)
 
 	response := LearningAttemptResponse{
 		ID:            attempt.ID,
 		SessionID:     attempt.SessionID,
 		WordID:        attempt.WordID,
-		Word:          word.Word,
+		Word:          graded.Word.Word,
 		AttemptType:   attempt.AttemptType,
 		CorrectAnswer: attempt.CorrectAnswer,
 		IsCorrect:     attempt.IsCorrect,
@@ -521,13 +460,13 @@ func (server *Server) submitLearningAttempt(ctx *gin.Context) {
 }
 
 // @Summary Complete learning session
-// @Description Mark a learning session as completed
+// @Description Mark a learning session as completed and get its summary: per-word outcomes, words to review, mastery changes, XP earned and the streak
 // @Tags learning
 // @Accept json
 // @Produce json
 // @Param id path int true "Session ID"
 // @Param request body completeSessionRequest true "Session completion data"
-// @Success 200 {object} Response{data=LearningSessionResponse} "Learning session completed successfully"
+// @Success 200 {object} Response{data=LearningSessionSummaryResponse} "Learning session completed successfully"
 // @Failure 400 {object} Response "Invalid request body or session ID"
 // @Failure 401 {object} Response "Unauthorized"
 // @Failure 404 {object} Response "Session not found"
@@ -549,59 +488,49 @@ func (server *Server) completeLearningSession(ctx *gin.Context) {
 
 	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
 
-	// Get session stats
-	stats, err := server.store.GetSessionStats(ctx, uriReq.ID)
+	// Statistics the client reports are stored instead of the calculated ones
+	var reported *learningservice.Stats
+	if req.FinalStats != nil {
+		finalStats := learningservice.Stats(*req.FinalStats)
+		reported = &finalStats
+	}
+	completion, err := server.learning.CompleteSession(ctx, authPayload.ID, uriReq.ID, reported)
 	if err != nil {
-		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to get session statistics", err)
+		learningServiceError(ctx, err, "Failed to complete learning session")
 		return
 	}
+	session, stats := completion.Session, completion.Stats
 
-	// Prepare session data
-	sessionData := make(map[string]interface{})
-	
-	// Add final stats
-	finalStats := SessionStats{
-		TotalAttempts:     int32(stats.TotalAttempts),
-		CorrectAttempts:   int32(stats.CorrectAttempts),
-		AvgResponseTime:   stats.AvgResponseTime,
-		AvgDifficulty:     stats.AvgDifficulty,
-		AccuracyPercentage: float64(stats.CorrectAttempts) / float64(stats.TotalAttempts) * 100,
+	xp := social.LearningSessionXP(stats.CorrectAttempts)
+	streak, err := server.recordActivity(ctx, authPayload.ID, social.ActivityLearningSessionCompleted, map[string]interface{}{
+		"session_id":      session.ID,
+		"correct_answers": stats.CorrectAttempts,
+		"total_questions": stats.TotalAttempts,
+	}, xp)
+
+	response := LearningSessionSummaryResponse{
+		LearningSessionResponse: NewLearningSessionResponse(session),
+		Stats:                   SessionStats(stats),
+		Words:                   completion.Words,
+		ReviewWordIDs:           completion.ReviewWordIDs,
 	}
-	
-	// Use provided final stats if available, otherwise use calculated ones
-	if req.FinalStats != nil {
-		sessionData["final_stats"] = *req.FinalStats
-	} else {
-		sessionData["final_stats"] = finalStats
-	}
-
-	sessionDataJSON, _ := json.Marshal(sessionData)
-	now := time.Now()
-
-	// Update session as completed
-	session, err := server.store.UpdateLearningSession(ctx, db.UpdateLearningSessionParams{
-		ID:             uriReq.ID,
-		UserID:         authPayload.ID,
-		CompletedAt:    sql.NullTime{Time: now, Valid: true},
-		TotalQuestions: sql.NullInt32{Int32: int32(stats.TotalAttempts), Valid: true},
-		CorrectAnswers: sql.NullInt32{Int32: int32(stats.CorrectAttempts), Valid: true},
-		SessionData:    pqtype.NullRawMessage{RawMessage: sessionDataJSON, Valid: true},
-	})
-	if err != nil {
-		if err == sql.ErrNoRows {
-			ErrorResponse(ctx, http.StatusNotFound, "Learning session not found", err)
-			return
-		}
-		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to complete learning session", err)
-		return
+	if err == nil {
+		response.XPEarned = xp + streak.BonusXP
+		response.Streak = &streak
 	}
-
-	response := NewLearningSessionResponse(session)
 	SuccessResponse(ctx, http.StatusOK, "Learning session completed successfully", response)
 }
 
 // Helper functions for generating different types of questions
 
+func wordIDs(words []db.Word) []int32 {
+	ids := make([]int32, len(words))
+	for i, word := range words {
+		ids[i] = word.ID
+	}
+	return ids
+}
+
 func generateFlashcardQuestions(words []db.Word) []FlashcardQuestion {
 	var questions []FlashcardQuestion
 	for _, word := range words {
@@ -616,46 +545,30 @@ func generateFlashcardQuestions(words []db.Word) []FlashcardQuestion {
 	return questions
 }
 
-func generateMultipleChoiceQuestions(words []db.Word) []MultipleChoiceQuestion {
-	var questions []MultipleChoiceQuestion
-	rand.Seed(time.Now().UnixNano())
+// generateMultipleChoiceQuestions asks for one sense of each word, with
+// meanings of other words of the same part of speech as wrong options
+func (server *Server) generateMultipleChoiceQuestions(ctx *gin.Context, words []db.Word) ([]MultipleChoiceQuestion, error) {
+	quiz, err := server.senses.Quiz(ctx, words, rand.New(rand.NewSource(time.Now().UnixNano())))
+	if err != nil {
+		return nil, err
+	}
 
+	questions := make([]MultipleChoiceQuestion, 0, len(words))
 	for i, word := range words {
-		options := []string{word.ShortMean}
-		
-		// Add 3 random wrong options from other words
-		for len(options) < 4 {
-			randomIndex := rand.Intn(len(words))
-			if randomIndex != i && words[randomIndex].ShortMean != word.ShortMean {
-				// Check if option already exists
-				exists := false
-				for _, opt := range options {
-					if opt == words[randomIndex].ShortMean {
-						exists = true
-						break
-					}
-				}
-				if !exists {
-					options = append(options, words[randomIndex].ShortMean)
-				}
-			}
-		}
-
-		// Shuffle options
-		for i := range options {
-			j := rand.Intn(i + 1)
-			options[i], options[j] = options[j], options[i]
-		}
-
-		questions = append(questions, MultipleChoiceQuestion{
+		question := MultipleChoiceQuestion{
 			WordID:        word.ID,
 			Word:          word.Word,
 			Pronunciation: word.Pronounce,
-			Options:       options,
-			CorrectAnswer: word.ShortMean,
-		})
+			PartOfSpeech:  quiz[i].Sense.PartOfSpeech,
+			Options:       quiz[i].Options,
+			CorrectAnswer: quiz[i].Sense.Meaning,
+		}
+		if quiz[i].Sense.ID != 0 {
+			question.SenseID = &quiz[i].Sense.ID
+		}
+		questions = append(questions, question)
 	}
-	return questions
+	return questions, nil
 }
 
 func generateMatchPairs(words []db.Word) []MatchPair {
@@ -684,18 +597,36 @@ func generateTypeQuestions(words []db.Word) []FlashcardQuestion {
 	return generateFlashcardQuestions(words)
 }
 
-func compareTypedAnswer(userAnswer, correctAnswer, word string) bool {
-	// Simple comparison for now - can be made more sophisticated
-	userLower := strings.ToLower(strings.TrimSpace(userAnswer))
-	correctLower := strings.ToLower(strings.TrimSpace(correctAnswer))
-	wordLower := strings.ToLower(strings.TrimSpace(word))
-	
-	// Exact match with correct answer or the word itself
-	return userLower == correctLower || userLower == wordLower
+// learningServiceError responds to an error of the learning session
+// service, with message when the error is unexpected
+func learningServiceError(ctx *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, learningservice.ErrStudySetNotFound):
+		ErrorResponse(ctx, http.StatusNotFound, "Study set not found", err)
+	case errors.Is(err, learningservice.ErrStudySetDenied):
+		ErrorResponse(ctx, http.StatusForbidden, "Access denied to study set", nil)
+	case errors.Is(err, learningservice.ErrNoWords):
+		ErrorResponse(ctx, http.StatusBadRequest, "No words available for learning session", nil)
+	case errors.Is(err, learningservice.ErrSessionNotFound):
+		ErrorResponse(ctx, http.StatusNotFound, "Learning session not found", err)
+	case errors.Is(err, learningservice.ErrWordNotFound):
+		ErrorResponse(ctx, http.StatusNotFound, "Word not found", err)
+	case errors.Is(err, learningservice.ErrSenseMismatch):
+		ErrorResponse(ctx, http.StatusBadRequest, "Sense does not belong to the word", nil)
+	default:
+		ErrorResponse(ctx, http.StatusInternalServerError, message, err)
+	}
 }
 
-// updateVocabularyStats updates vocabulary statistics in a goroutine
+// updateVocabularyStats queues an attempt for the vocabulary statistics,
+// which are written in batches without blocking the response
 func (server *Server) updateVocabularyStats(userID, wordID int32, isCorrect bool, responseTimeMs, difficultyRating *int32) {
-	// This would be called in a goroutine to update stats without blocking the response
-	// Implementation would use the vocabulary stats queries we created
+	server.vocabStats.Record(vocabstats.Attempt{
+		UserID:           userID,
+		WordID:           wordID,
+		Correct:          isCorrect,
+		ResponseTimeMs:   responseTimeMs,
+		DifficultyRating: difficultyRating,
+		At:               time.Now(),
+	})
 }
@@ -0,0 +1,521 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/logger"
+	"github.com/toeic-app/internal/lti"
+)
+
+// newLTIService loads the tool key; LTI is disabled without one
+func newLTIService(path string, store db.Querier) *lti.Service {
+	if path == "" {
+		return nil
+	}
+	key, err := lti.LoadToolKey(path)
+	if err != nil {
+		logger.Error("LTI disabled: %v", err)
+		return nil
+	}
+	return lti.NewService(store, key)
+}
+
+// ltiLoginRequest is a third-party initiated login sent by an LMS
+type ltiLoginRequest struct {
+	Issuer        string `form:"iss" binding:"required"`
+	LoginHint     string `form:"login_hint" binding:"required"`
+	TargetLinkURI string `form:"target_link_uri"`
+	MessageHint   string `form:"lti_message_hint"`
+	ClientID      string `form:"client_id"`
+	DeploymentID  string `form:"lti_deployment_id"`
+}
+
+// ltiLaunchRequest is the authentication response an LMS posts to the tool
+type ltiLaunchRequest struct {
+	IDToken          string `form:"id_token"`
+	State            string `form:"state"`
+	Error            string `form:"error"`
+	ErrorDescription string `form:"error_description"`
+}
+
+// ltiLaunchResponse is the session of a verified launch
+type ltiLaunchResponse struct {
+	User         UserResponse      `json:"user"`
+	AccessToken  string            `json:"access_token"`
+	RefreshToken string            `json:"refresh_token"`
+	Launch       *lti.LaunchResult `json:"launch"`
+}
+
+// registerLTIPlatformRequest registers an LMS deployment
+type registerLTIPlatformRequest struct {
+	Name         string `json:"name" binding:"required,max=255"`
+	Issuer       string `json:"issuer" binding:"required,url"`
+	ClientID     string `json:"client_id" binding:"required"`
+	DeploymentID string `json:"deployment_id" binding:"required"`
+	AuthLoginURL string `json:"auth_login_url" binding:"required,url"`
+	AuthTokenURL string `json:"auth_token_url" binding:"required,url"`
+	JWKSURL      string `json:"jwks_url" binding:"required,url"`
+}
+
+// @Summary     LTI login initiation
+// @Description OIDC third-party initiated login from an LMS. Redirects the browser to the platform authorization endpoint.
+// @Tags        lti
+// @Param       iss query string true "Platform issuer"
+// @Param       login_hint query string true "Opaque user hint"
+// @Param       target_link_uri query string false "Launch target"
+// @Param       lti_message_hint query string false "Opaque message hint"
+// @Param       client_id query string false "Client ID of the tool"
+// @Param       lti_deployment_id query string false "Deployment ID"
+// @Success     302 "Redirect to the platform"
+// @Failure     400 {object} Response "Invalid login request or unknown platform"
+// @Failure     503 {object} Response "LTI is disabled"
+// @Router      /api/v1/lti/login [get]
+// @Router      /api/v1/lti/login [post]
+func (server *Server) ltiLogin(ctx *gin.Context) {
+	if server.lti == nil {
+		ErrorResponse(ctx, http.StatusServiceUnavailable, "LTI is disabled", nil)
+		return
+	}
+
+	var req ltiLoginRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid login request", err)
+		return
+	}
+
+	redirect, err := server.lti.Login(ctx, lti.LoginRequest{
+		Issuer:        req.Issuer,
+		LoginHint:     req.LoginHint,
+		TargetLinkURI: req.TargetLinkURI,
+		MessageHint:   req.MessageHint,
+		ClientID:      req.ClientID,
+		DeploymentID:  req.DeploymentID,
+	}, externalURL(ctx, "/api/v1/lti/launch"))
+	if err != nil {
+		if errors.Is(err, lti.ErrUnknownPlatform) || errors.Is(err, lti.ErrInvalidLaunch) {
+			ErrorResponse(ctx, http.StatusBadRequest, "Unknown LTI platform", err)
+			return
+		}
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to start LTI login", err)
+		return
+	}
+
+	ctx.Redirect(http.StatusFound, redirect)
+}
+
+// @Summary     LTI launch
+// @Description Receives the launch ID token posted by the LMS, signs the user in (creating an account on first launch) and records the course membership and resource link. Redirects to LTI_LAUNCH_REDIRECT_URL with the session in the URL fragment, or returns it as JSON when no redirect is configured.
+// @Tags        lti
+// @Accept      x-www-form-urlencoded
+// @Produce     json
+// @Param       id_token formData string true "Launch ID token"
+// @Param       state formData string true "Login state"
+// @Success     200 {object} Response{data=ltiLaunchResponse} "LTI launch successful"
+// @Success     303 "Redirect to the web app"
+// @Failure     400 {object} Response "Platform returned an error"
+// @Failure     401 {object} Response "Invalid launch"
+// @Failure     503 {object} Response "LTI is disabled"
+// @Router      /api/v1/lti/launch [post]
+func (server *Server) ltiLaunch(ctx *gin.Context) {
+	if server.lti == nil {
+		ErrorResponse(ctx, http.StatusServiceUnavailable, "LTI is disabled", nil)
+		return
+	}
+
+	var req ltiLaunchRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid launch request", err)
+		return
+	}
+	if req.Error != "" {
+		ErrorResponse(ctx, http.StatusBadRequest, "Platform returned an error: "+req.Error, errors.New(req.ErrorDescription))
+		return
+	}
+
+	launch, err := server.lti.Launch(ctx, req.IDToken, req.State)
+	if err != nil {
+		if errors.Is(err, lti.ErrInvalidState) || errors.Is(err, lti.ErrInvalidLaunch) {
+			ErrorResponse(ctx, http.StatusUnauthorized, "Invalid LTI launch", err)
+			return
+		}
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to process LTI launch", err)
+		return
+	}
+
+	user, err := server.store.GetUser(ctx, launch.UserID)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to get user", err)
+		return
+	}
+
+	accessToken, err := server.tokenMaker.CreateToken(
+		user.ID,
+		user.Username,
+		time.Duration(server.config.AccessTokenDuration)*time.Second,
+	)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to create access token", err)
+		return
+	}
+
+	refreshToken, err := server.tokenMaker.CreateToken(
+		user.ID,
+		user.Username,
+		time.Duration(server.config.RefreshTokenDuration)*time.Second,
+	)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to create refresh token", err)
+		return
+	}
+
+	if server.config.LTILaunchRedirectURL != "" {
+		// The fragment keeps the tokens out of server logs and referrers
+		fragment := url.Values{
+			"access_token":  {accessToken},
+			"refresh_token": {refreshToken},
+			"is_instructor": {strconv.FormatBool(launch.IsInstructor)},
+		}
+		if launch.ExamID != 0 {
+			fragment.Set("exam_id", strconv.Itoa(int(launch.ExamID)))
+		}
+		if launch.ContextID != 0 {
+			fragment.Set("context_id", strconv.Itoa(int(launch.ContextID)))
+		}
+		ctx.Redirect(http.StatusSeeOther, server.config.LTILaunchRedirectURL+"#"+fragment.Encode())
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "LTI launch successful", ltiLaunchResponse{
+		User:         NewUserResponse(user),
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		Launch:       launch,
+	})
+}
+
+// @Summary     LTI tool keys
+// @Description Public keys of the tool, registered with platforms to verify grade passback and roster requests
+// @Tags        lti
+// @Produce     json
+// @Success     200 {object} lti.JWKS "Key set"
+// @Failure     503 {object} Response "LTI is disabled"
+// @Router      /api/v1/lti/jwks [get]
+func (server *Server) ltiJWKS(ctx *gin.Context) {
+	if server.lti == nil {
+		ErrorResponse(ctx, http.StatusServiceUnavailable, "LTI is disabled", nil)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, server.lti.JWKS())
+}
+
+// submitLTIScore passes a completed attempt score back to LMS gradebooks
+// without holding up the response
+func (server *Server) submitLTIScore(attempt db.ExamAttempt) {
+	if server.lti == nil {
+		return
+	}
+	go func() {
+		bgCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		if err := server.lti.SubmitAttemptScore(bgCtx, attempt); err != nil {
+			logger.Warn("Failed to pass score of attempt %d back to LMS: %v", attempt.AttemptID, err)
+		}
+	}()
+}
+
+// @Summary     Register LTI platform
+// @Description Registers an LMS deployment (Moodle, Canvas, ...) allowed to launch the app (admin only)
+// @Tags        admin
+// @Accept      json
+// @Produce     json
+// @Param       platform body registerLTIPlatformRequest true "Platform registration"
+// @Success     201 {object} Response{data=db.LtiPlatform} "LTI platform registered successfully"
+// @Failure     400 {object} Response "Invalid request parameters"
+// @Failure     503 {object} Response "LTI is disabled"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/lti/platforms [post]
+func (server *Server) registerLTIPlatform(ctx *gin.Context) {
+	if server.lti == nil {
+		ErrorResponse(ctx, http.StatusServiceUnavailable, "LTI is disabled", nil)
+		return
+	}
+
+	var req registerLTIPlatformRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request parameters", err)
+		return
+	}
+
+	platform, err := server.lti.RegisterPlatform(ctx, db.CreateLTIPlatformParams{
+		Name:         req.Name,
+		Issuer:       req.Issuer,
+		ClientID:     req.ClientID,
+		DeploymentID: req.DeploymentID,
+		AuthLoginUrl: req.AuthLoginURL,
+		AuthTokenUrl: req.AuthTokenURL,
+		JwksUrl:      req.JWKSURL,
+	})
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to register LTI platform", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusCreated, "LTI platform registered successfully", platform)
+}
+
+// @Summary     List LTI platforms
+// @Description Lists registered LMS deployments (admin only)
+// @Tags        admin
+// @Produce     json
+// @Success     200 {object} Response{data=[]db.LtiPlatform} "LTI platforms retrieved successfully"
+// @Failure     503 {object} Response "LTI is disabled"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/lti/platforms [get]
+func (server *Server) listLTIPlatforms(ctx *gin.Context) {
+	if server.lti == nil {
+		ErrorResponse(ctx, http.StatusServiceUnavailable, "LTI is disabled", nil)
+		return
+	}
+
+	platforms, err := server.lti.Platforms(ctx)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to list LTI platforms", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "LTI platforms retrieved successfully", platforms)
+}
+
+// @Summary     Delete LTI platform
+// @Description Removes an LMS deployment with its courses and rosters. Provisioned accounts are kept but can no longer be launched into. (admin only)
+// @Tags        admin
+// @Produce     json
+// @Param       id path int true "Platform ID"
+// @Success     200 {object} Response "LTI platform deleted successfully"
+// @Failure     404 {object} Response "LTI platform not found"
+// @Failure     503 {object} Response "LTI is disabled"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/lti/platforms/{id} [delete]
+func (server *Server) deleteLTIPlatform(ctx *gin.Context) {
+	if server.lti == nil {
+		ErrorResponse(ctx, http.StatusServiceUnavailable, "LTI is disabled", nil)
+		return
+	}
+
+	id, ok := parseLTIID(ctx)
+	if !ok {
+		return
+	}
+
+	if err := server.lti.DeletePlatform(ctx, id); err != nil {
+		if errors.Is(err, lti.ErrPlatformNotFound) {
+			ErrorResponse(ctx, http.StatusNotFound, "LTI platform not found", err)
+			return
+		}
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to delete LTI platform", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "LTI platform deleted successfully", nil)
+}
+
+// @Summary     List LTI courses
+// @Description Lists the LMS courses the app was launched from (admin only)
+// @Tags        admin
+// @Produce     json
+// @Param       limit query int false "Maximum number of courses (default 50)"
+// @Param       offset query int false "Number of courses to skip"
+// @Success     200 {object} Response{data=[]db.LtiContext} "LTI courses retrieved successfully"
+// @Failure     503 {object} Response "LTI is disabled"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/lti/courses [get]
+func (server *Server) listLTICourses(ctx *gin.Context) {
+	if server.lti == nil {
+		ErrorResponse(ctx, http.StatusServiceUnavailable, "LTI is disabled", nil)
+		return
+	}
+
+	var req struct {
+		Limit  int32 `form:"limit" binding:"omitempty,min=1,max=200"`
+		Offset int32 `form:"offset" binding:"omitempty,min=0"`
+	}
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+	if req.Limit == 0 {
+		req.Limit = 50
+	}
+
+	courses, err := server.lti.Courses(ctx, req.Limit, req.Offset)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to list LTI courses", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "LTI courses retrieved successfully", courses)
+}
+
+// @Summary     Get LTI course roster
+// @Description Lists the members of an LMS course with their roles (admin only)
+// @Tags        admin
+// @Produce     json
+// @Param       id path int true "Course ID"
+// @Success     200 {object} Response{data=[]db.ListLTIContextMembersRow} "LTI course roster retrieved successfully"
+// @Failure     404 {object} Response "LTI course not found"
+// @Failure     503 {object} Response "LTI is disabled"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/lti/courses/{id}/members [get]
+func (server *Server) listLTICourseMembers(ctx *gin.Context) {
+	if server.lti == nil {
+		ErrorResponse(ctx, http.StatusServiceUnavailable, "LTI is disabled", nil)
+		return
+	}
+
+	id, ok := parseLTIID(ctx)
+	if !ok {
+		return
+	}
+
+	members, err := server.lti.Roster(ctx, id)
+	if err != nil {
+		if errors.Is(err, lti.ErrContextNotFound) {
+			ErrorResponse(ctx, http.StatusNotFound, "LTI course not found", err)
+			return
+		}
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to get LTI course roster", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "LTI course roster retrieved successfully", members)
+}
+
+// @Summary     Sync LTI course roster
+// @Description Reads the course roster from the LMS names and roles service, creating accounts for new members and deactivating members who left (admin only)
+// @Tags        admin
+// @Produce     json
+// @Param       id path int true "Course ID"
+// @Success     200 {object} Response{data=lti.RosterSyncResult} "LTI course roster synced successfully"
+// @Failure     404 {object} Response "LTI course not found"
+// @Failure     409 {object} Response "The course has no roster service"
+// @Failure     502 {object} Response "Roster sync failed"
+// @Failure     503 {object} Response "LTI is disabled"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/lti/courses/{id}/roster-sync [post]
+func (server *Server) syncLTICourseRoster(ctx *gin.Context) {
+	if server.lti == nil {
+		ErrorResponse(ctx, http.StatusServiceUnavailable, "LTI is disabled", nil)
+		return
+	}
+
+	id, ok := parseLTIID(ctx)
+	if !ok {
+		return
+	}
+
+	result, err := server.lti.SyncRoster(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, lti.ErrContextNotFound):
+			ErrorResponse(ctx, http.StatusNotFound, "LTI course not found", err)
+		case errors.Is(err, lti.ErrNoRosterService):
+			ErrorResponse(ctx, http.StatusConflict, "The course has no roster service", err)
+		default:
+			ErrorResponse(ctx, http.StatusBadGateway, "Roster sync failed", err)
+		}
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "LTI course roster synced successfully", result)
+}
+
+// @Summary     List LTI score submissions
+// @Description Lists recent grade passbacks to LMS gradebooks by status (admin only)
+// @Tags        admin
+// @Produce     json
+// @Param       status query string false "sent or failed (default failed)"
+// @Success     200 {object} Response{data=[]db.LtiScoreSubmission} "LTI score submissions retrieved successfully"
+// @Failure     400 {object} Response "Invalid status"
+// @Failure     503 {object} Response "LTI is disabled"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/lti/score-submissions [get]
+func (server *Server) listLTIScoreSubmissions(ctx *gin.Context) {
+	if server.lti == nil {
+		ErrorResponse(ctx, http.StatusServiceUnavailable, "LTI is disabled", nil)
+		return
+	}
+
+	status := ctx.DefaultQuery("status", "failed")
+	if status != "sent" && status != "failed" {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid status", nil)
+		return
+	}
+
+	submissions, err := server.lti.ScoreSubmissions(ctx, status, 100)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to list LTI score submissions", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "LTI score submissions retrieved successfully", submissions)
+}
+
+// @Summary     Resubmit LTI score
+// @Description Passes the score of a completed exam attempt back to LMS gradebooks again, for example after a failed submission (admin only)
+// @Tags        admin
+// @Produce     json
+// @Param       attempt_id path int true "Exam attempt ID"
+// @Success     200 {object} Response "LTI score resubmitted successfully"
+// @Failure     404 {object} Response "Exam attempt not found"
+// @Failure     502 {object} Response "Score submission failed"
+// @Failure     503 {object} Response "LTI is disabled"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/lti/score-submissions/{attempt_id}/retry [post]
+func (server *Server) retryLTIScoreSubmission(ctx *gin.Context) {
+	if server.lti == nil {
+		ErrorResponse(ctx, http.StatusServiceUnavailable, "LTI is disabled", nil)
+		return
+	}
+
+	attemptID, err := strconv.ParseInt(ctx.Param("attempt_id"), 10, 32)
+	if err != nil || attemptID <= 0 {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid attempt ID", err)
+		return
+	}
+
+	attempt, err := server.store.GetExamAttempt(ctx, int32(attemptID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ErrorResponse(ctx, http.StatusNotFound, "Exam attempt not found", err)
+			return
+		}
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to get exam attempt", err)
+		return
+	}
+
+	if err := server.lti.SubmitAttemptScore(ctx, attempt); err != nil {
+		ErrorResponse(ctx, http.StatusBadGateway, "Score submission failed", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "LTI score resubmitted successfully", nil)
+}
+
+func parseLTIID(ctx *gin.Context) (int32, bool) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 32)
+	if err != nil || id <= 0 {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid ID", err)
+		return 0, false
+	}
+	return int32(id), true
+}
@@ -0,0 +1,248 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/logger"
+	"github.com/toeic-app/internal/security"
+	"github.com/toeic-app/internal/token"
+)
+
+// TOTPCodeHeader carries the current authenticator code on step-up protected requests
+const TOTPCodeHeader = "X-TOTP-Code"
+
+// totpIssuer is shown in authenticator apps
+const totpIssuer = "TOEIC App"
+
+var (
+	errMFANotEnrolled  = errors.New("TOTP is not enabled for this account")
+	errTOTPCodeMissing = errors.New("TOTP code is required in the " + TOTPCodeHeader + " header")
+	errTOTPCodeInvalid = errors.New("invalid or already used TOTP code")
+)
+
+// MFAStatusResponse reports the second factor of the current user
+type MFAStatusResponse struct {
+	TOTPEnabled bool       `json:"totp_enabled"`
+	EnabledAt   *time.Time `json:"enabled_at,omitempty"`
+}
+
+// TOTPEnrollmentResponse contains the secret to add to an authenticator app
+type TOTPEnrollmentResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// totpCodeRequest confirms a TOTP operation with a code
+type totpCodeRequest struct {
+	Code string `json:"code" binding:"required,len=6,numeric"`
+}
+
+// verifyTOTPCode checks a code of a user's enabled TOTP factor and consumes its time step
+func (server *Server) verifyTOTPCode(ctx *gin.Context, userID int32, code string) error {
+	mfa, err := server.store.GetUserMFA(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !mfa.EnabledAt.Valid) {
+		return errMFANotEnrolled
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load TOTP settings: %w", err)
+	}
+	if code == "" {
+		return errTOTPCodeMissing
+	}
+
+	step, ok := security.ValidateTOTP(mfa.TotpSecret, code, time.Now())
+	if !ok {
+		return errTOTPCodeInvalid
+	}
+
+	// Each code is accepted once, the update fails for replayed steps
+	updated, err := server.store.UseUserMFAStep(ctx, db.UseUserMFAStepParams{UserID: userID, LastUsedStep: step})
+	if err != nil {
+		return fmt.Errorf("failed to record TOTP use: %w", err)
+	}
+	if updated == 0 {
+		return errTOTPCodeInvalid
+	}
+	return nil
+}
+
+// isTOTPFailure reports whether err is a rejected code rather than an internal error
+func isTOTPFailure(err error) bool {
+	return errors.Is(err, errMFANotEnrolled) || errors.Is(err, errTOTPCodeMissing) || errors.Is(err, errTOTPCodeInvalid)
+}
+
+// requireTOTP verifies the X-TOTP-Code header of the authenticated user.
+// It writes the error response and returns false when verification fails.
+func (server *Server) requireTOTP(ctx *gin.Context) bool {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	err := server.verifyTOTPCode(ctx, authPayload.ID, ctx.GetHeader(TOTPCodeHeader))
+	switch {
+	case err == nil:
+		return true
+	case isTOTPFailure(err):
+		logger.Warn("TOTP step-up failed for user %d on %s: %v", authPayload.ID, ctx.Request.URL.Path, err)
+		ErrorResponse(ctx, http.StatusForbidden, "Two-factor verification failed", err)
+	default:
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to verify two-factor code", err)
+	}
+	return false
+}
+
+// @Summary     Get two-factor status
+// @Description Reports whether the current user has TOTP two-factor authentication enabled
+// @Tags        users
+// @Produce     json
+// @Success     200 {object} Response{data=MFAStatusResponse} "Two-factor status retrieved successfully"
+// @Failure     401 {object} Response "Unauthorized"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/users/me/mfa [get]
+func (server *Server) getMFAStatus(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	response := MFAStatusResponse{}
+	mfa, err := server.store.GetUserMFA(ctx, authPayload.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to get two-factor status", err)
+		return
+	}
+	if err == nil && mfa.EnabledAt.Valid {
+		response.TOTPEnabled = true
+		response.EnabledAt = &mfa.EnabledAt.Time
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Two-factor status retrieved successfully", response)
+}
+
+// @Summary     Start TOTP enrollment
+// @Description Generates a new TOTP secret. It becomes active after confirming a code with the activate endpoint. Enrolling again replaces a pending secret.
+// @Tags        users
+// @Produce     json
+// @Success     200 {object} Response{data=TOTPEnrollmentResponse} "TOTP enrollment started"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     409 {object} Response "TOTP is already enabled"
+// @Failure     500 {object} Response "Failed to start TOTP enrollment"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/users/me/mfa/totp [post]
+func (server *Server) enrollTOTP(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	user, err := server.store.GetUser(ctx, authPayload.ID)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to start TOTP enrollment", err)
+		return
+	}
+
+	// An enabled factor can only be replaced after disabling it with a valid code,
+	// otherwise a stolen access token would be enough to take over the second factor
+	existing, err := server.store.GetUserMFA(ctx, user.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to start TOTP enrollment", err)
+		return
+	}
+	if err == nil && existing.EnabledAt.Valid {
+		ErrorResponse(ctx, http.StatusConflict, "TOTP is already enabled, disable it first", nil)
+		return
+	}
+
+	secret, err := security.GenerateTOTPSecret()
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to start TOTP enrollment", err)
+		return
+	}
+	if _, err := server.store.UpsertUserMFASecret(ctx, db.UpsertUserMFASecretParams{UserID: user.ID, TotpSecret: secret}); err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to start TOTP enrollment", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "TOTP enrollment started", TOTPEnrollmentResponse{
+		Secret:          secret,
+		ProvisioningURI: security.TOTPProvisioningURI(totpIssuer, user.Email.String, secret),
+	})
+}
+
+// @Summary     Activate TOTP
+// @Description Confirms TOTP enrollment with a code from the authenticator app
+// @Tags        users
+// @Accept      json
+// @Produce     json
+// @Param       request body totpCodeRequest true "Current authenticator code"
+// @Success     200 {object} Response "TOTP enabled successfully"
+// @Failure     400 {object} Response "Invalid code or no pending enrollment"
+// @Failure     401 {object} Response "Unauthorized"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/users/me/mfa/totp/activate [post]
+func (server *Server) activateTOTP(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	var req totpCodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request parameters", err)
+		return
+	}
+
+	mfa, err := server.store.GetUserMFA(ctx, authPayload.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		ErrorResponse(ctx, http.StatusBadRequest, "No pending TOTP enrollment", nil)
+		return
+	}
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to enable TOTP", err)
+		return
+	}
+
+	step, ok := security.ValidateTOTP(mfa.TotpSecret, req.Code, time.Now())
+	if !ok {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid TOTP code", errTOTPCodeInvalid)
+		return
+	}
+	if err := server.store.EnableUserMFA(ctx, db.EnableUserMFAParams{UserID: authPayload.ID, LastUsedStep: step}); err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to enable TOTP", err)
+		return
+	}
+
+	logger.Info("User %d enabled TOTP two-factor authentication", authPayload.ID)
+	SuccessResponse(ctx, http.StatusOK, "TOTP enabled successfully", nil)
+}
+
+// @Summary     Disable TOTP
+// @Description Removes the TOTP factor of the current user. Requires a current code.
+// @Tags        users
+// @Accept      json
+// @Produce     json
+// @Param       request body totpCodeRequest true "Current authenticator code"
+// @Success     200 {object} Response "TOTP disabled successfully"
+// @Failure     400 {object} Response "Invalid request parameters"
+// @Failure     403 {object} Response "Invalid TOTP code"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/users/me/mfa/totp [delete]
+func (server *Server) disableTOTP(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	var req totpCodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request parameters", err)
+		return
+	}
+
+	if err := server.verifyTOTPCode(ctx, authPayload.ID, req.Code); isTOTPFailure(err) {
+		ErrorResponse(ctx, http.StatusForbidden, "Two-factor verification failed", err)
+		return
+	} else if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to verify two-factor code", err)
+		return
+	}
+	if err := server.store.DeleteUserMFA(ctx, authPayload.ID); err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to disable TOTP", err)
+		return
+	}
+
+	logger.Info("User %d disabled TOTP two-factor authentication", authPayload.ID)
+	SuccessResponse(ctx, http.StatusOK, "TOTP disabled successfully", nil)
+}
@@ -0,0 +1,222 @@
+package api
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/config"
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/logger"
+	"github.com/toeic-app/internal/oauth"
+	"github.com/toeic-app/internal/token"
+)
+
+// newOAuthService creates the social login service, or returns nil when no
+// provider is configured. Without a usable key Apple accepts ID tokens only.
+func newOAuthService(config config.Config, store db.Querier) *oauth.Service {
+	var providers []oauth.Provider
+	if clientIDs := config.GoogleClientIDs(); len(clientIDs) > 0 {
+		providers = append(providers, oauth.Google(clientIDs, config.OAuthGoogleClientSecret))
+	}
+	if clientIDs := config.AppleClientIDs(); len(clientIDs) > 0 {
+		var key *ecdsa.PrivateKey
+		if config.OAuthApplePrivateKeyPath != "" {
+			var err error
+			if key, err = oauth.LoadAppleKey(config.OAuthApplePrivateKeyPath); err != nil {
+				logger.Error("Apple authorization codes disabled: %v", err)
+			}
+		}
+		providers = append(providers, oauth.Apple(clientIDs, config.OAuthAppleTeamID, config.OAuthAppleKeyID, key))
+	}
+	if len(providers) == 0 {
+		return nil
+	}
+	service := oauth.NewService(store, providers...)
+	logger.Info("Social login enabled for: %s", strings.Join(service.Providers(), ", "))
+	return service
+}
+
+// oauthLinkRequest proves a provider account with the ID token of the
+// provider's sign-in SDK, or an authorization code exchanged for one
+type oauthLinkRequest struct {
+	IDToken     string `json:"id_token"`                             // Required unless code is given
+	Code        string `json:"code"`                                 // Authorization code, exchanged when no ID token is given
+	RedirectURI string `json:"redirect_uri" binding:"omitempty,url"` // Redirect URI the code was issued for
+	Nonce       string `json:"nonce"`                                // Nonce sent to the provider, checked against the token
+}
+
+func (req oauthLinkRequest) credentials() oauth.Credentials {
+	return oauth.Credentials{
+		IDToken:     strings.TrimSpace(req.IDToken),
+		Code:        strings.TrimSpace(req.Code),
+		RedirectURI: req.RedirectURI,
+		Nonce:       req.Nonce,
+	}
+}
+
+// oauthLoginRequest signs in with a provider account
+type oauthLoginRequest struct {
+	oauthLinkRequest
+	// Name of a new user when the token has none, as Apple shares it with the app only
+	Name string `json:"name" binding:"omitempty,max=50"`
+}
+
+// oauthLoginResponse defines the structure for social login responses.
+// It includes user details, access token, refresh token, and security configuration.
+type oauthLoginResponse struct {
+	User           UserResponse   `json:"user"`
+	AccessToken    string         `json:"access_token"`
+	RefreshToken   string         `json:"refresh_token"`
+	SecurityConfig SecurityConfig `json:"security_config"`
+	Created        bool           `json:"created"` // The sign-in created the account
+}
+
+// @Summary     Sign in with Google or Apple
+// @Description Verifies an ID token of the provider, or exchanges an authorization code for one, and returns the app's tokens. The first sign-in creates an account, with the email of the provider account when the provider verified it. Accounts are never linked by email: when the email is already registered the request fails with 409, and the owner signs in with their password and links the provider with POST /api/auth/oauth/{provider}/link.
+// @Tags        auth
+// @Accept      json
+// @Produce     json
+// @Param       provider path string true "Provider" Enums(google, apple)
+// @Param       login body oauthLoginRequest true "Provider credentials"
+// @Success     200 {object} Response{data=oauthLoginResponse} "Login successful"
+// @Success     201 {object} Response{data=oauthLoginResponse} "User registered successfully"
+// @Failure     400 {object} Response "Invalid request"
+// @Failure     401 {object} Response "Invalid provider token"
+// @Failure     404 {object} Response "Unknown or disabled provider"
+// @Failure     409 {object} Response "Email already registered"
+// @Failure     423 {object} Response "Account is locked"
+// @Failure     503 {object} Response "Social login is disabled, or read-only maintenance prevents creating the account"
+// @Router      /api/auth/oauth/{provider} [post]
+func (server *Server) oauthLogin(ctx *gin.Context) {
+	if server.oauth == nil {
+		ErrorResponse(ctx, http.StatusServiceUnavailable, "Social login is disabled", nil)
+		return
+	}
+
+	var req oauthLoginRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request parameters", err)
+		return
+	}
+
+	var result oauth.SignIn
+	var err error
+	if server.isReadOnly(ctx) {
+		// Linked users sign in during maintenance; accounts cannot be created
+		result.UserID, err = server.oauth.LinkedUser(ctx, ctx.Param("provider"), req.credentials())
+		if errors.Is(err, oauth.ErrNotLinked) {
+			server.readOnlyResponse(ctx)
+			return
+		}
+	} else {
+		result, err = server.oauth.SignIn(ctx, ctx.Param("provider"), req.credentials(), strings.TrimSpace(req.Name))
+	}
+	if err != nil {
+		oauthError(ctx, err, "Failed to sign in with provider")
+		return
+	}
+
+	user, err := server.store.GetUser(ctx, result.UserID)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to find user", err)
+		return
+	}
+	if !server.ensureUnlocked(ctx, user.ID) {
+		return
+	}
+	server.recordSignIn(ctx, user.ID)
+
+	accessToken, err := server.tokenMaker.CreateToken(
+		user.ID,
+		user.Username,
+		time.Duration(server.config.AccessTokenDuration)*time.Second,
+	)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to create access token", err)
+		return
+	}
+
+	refreshToken, err := server.tokenMaker.CreateToken(
+		user.ID,
+		user.Username,
+		time.Duration(server.config.RefreshTokenDuration)*time.Second,
+	)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to create refresh token", err)
+		return
+	}
+
+	response := oauthLoginResponse{
+		User:           NewUserResponse(user),
+		AccessToken:    accessToken,
+		RefreshToken:   refreshToken,
+		SecurityConfig: server.clientSecurityConfig(user.ID),
+		Created:        result.Created,
+	}
+	if result.Created {
+		SuccessResponse(ctx, http.StatusCreated, "User registered successfully", response)
+		return
+	}
+	SuccessResponse(ctx, http.StatusOK, "Login successful", response)
+}
+
+// @Summary     Link a Google or Apple account
+// @Description Links the provider account of an ID token or authorization code to the current user, so it signs in to this account. A user links one account of each provider, and a provider account is linked to one user.
+// @Tags        auth
+// @Accept      json
+// @Produce     json
+// @Param       provider path string true "Provider" Enums(google, apple)
+// @Param       link body oauthLinkRequest true "Provider credentials"
+// @Success     200 {object} Response{data=oauth.Identity} "Account linked successfully"
+// @Failure     400 {object} Response "Invalid request"
+// @Failure     401 {object} Response "Invalid provider token"
+// @Failure     404 {object} Response "Unknown or disabled provider"
+// @Failure     409 {object} Response "Provider account is linked to another user"
+// @Failure     503 {object} Response "Social login is disabled"
+// @Security    ApiKeyAuth
+// @Router      /api/auth/oauth/{provider}/link [post]
+func (server *Server) linkOAuthAccount(ctx *gin.Context) {
+	if server.oauth == nil {
+		ErrorResponse(ctx, http.StatusServiceUnavailable, "Social login is disabled", nil)
+		return
+	}
+
+	var req oauthLinkRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request parameters", err)
+		return
+	}
+
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+	identity, err := server.oauth.Link(ctx, ctx.Param("provider"), authPayload.ID, req.credentials())
+	if err != nil {
+		oauthError(ctx, err, "Failed to link account")
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Account linked successfully", identity)
+}
+
+// oauthError writes the response of a social login error
+func oauthError(ctx *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, oauth.ErrUnknownProvider):
+		ErrorResponse(ctx, http.StatusNotFound, "Unknown sign-in provider", err)
+	case errors.Is(err, oauth.ErrNoCredentials), errors.Is(err, oauth.ErrCodeUnsupported):
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request parameters", err)
+	case errors.Is(err, oauth.ErrInvalidToken):
+		ErrorResponse(ctx, http.StatusUnauthorized, "Invalid provider token", err)
+	case errors.Is(err, oauth.ErrEmailRegistered):
+		ErrorResponse(ctx, http.StatusConflict, "Email already registered", err)
+	case errors.Is(err, oauth.ErrLinkedToOther):
+		ErrorResponse(ctx, http.StatusConflict, "Provider account is linked to another user", err)
+	case errors.Is(err, oauth.ErrProviderLinked):
+		ErrorResponse(ctx, http.StatusConflict, "Another account of this provider is already linked", err)
+	default:
+		ErrorResponse(ctx, http.StatusInternalServerError, message, err)
+	}
+}
@@ -9,7 +9,8 @@ import (
 	"github.com/toeic-app/internal/performance"
 )
 
-// PerformanceController handles performance monitoring endpoints
+// PerformanceController handles performance monitoring endpoints. Its routes
+// are not registered, so they are left out of the swagger document.
 type PerformanceController struct {
 	monitor *performance.PerformanceMonitor
 }
@@ -32,14 +33,6 @@ func getUserID(c *gin.Context) int32 {
 }
 
 // GetPerformanceMetrics gets comprehensive performance metrics
-// @Summary Get database performance metrics
-// @Description Retrieves comprehensive database performance metrics including index usage, table stats, and cache hit ratios
-// @Tags performance
-// @Security ApiKeyAuth
-// @Produce json
-// @Success 200 {object} performance.PerformanceMetrics
-// @Failure 500 {object} Response "Server error"
-// @Router /api/v1/performance/metrics [get]
 func (pc *PerformanceController) GetPerformanceMetrics(c *gin.Context) {
 	metrics, err := pc.monitor.GetPerformanceMetrics(c.Request.Context())
 	if err != nil {
@@ -57,18 +50,10 @@ func (pc *PerformanceController) GetPerformanceMetrics(c *gin.Context) {
 		"user_id":   getUserID(c),
 	}, "Performance metrics retrieved successfully")
 
-	c.JSON(http.StatusOK, metrics)
+	SuccessResponse(c, http.StatusOK, "Performance metrics retrieved successfully", metrics)
 }
 
 // GetIndexUsageStats gets index usage statistics
-// @Summary Get index usage statistics
-// @Description Retrieves detailed index usage statistics for performance analysis
-// @Tags performance
-// @Security ApiKeyAuth
-// @Produce json
-// @Success 200 {array} performance.IndexUsageStats
-// @Failure 500 {object} Response "Server error"
-// @Router /api/v1/performance/indexes [get]
 func (pc *PerformanceController) GetIndexUsageStats(c *gin.Context) {
 	stats, err := pc.monitor.GetIndexUsageStats(c.Request.Context())
 	if err != nil {
@@ -88,18 +73,10 @@ func (pc *PerformanceController) GetIndexUsageStats(c *gin.Context) {
 		"count":     len(stats),
 	}, "Index usage statistics retrieved successfully")
 
-	c.JSON(http.StatusOK, stats)
+	SuccessResponse(c, http.StatusOK, "Index usage statistics retrieved successfully", stats)
 }
 
 // GetTableStats gets table statistics
-// @Summary Get table statistics
-// @Description Retrieves detailed table statistics including sequential scans and size information
-// @Tags performance
-// @Security ApiKeyAuth
-// @Produce json
-// @Success 200 {array} performance.TableStats
-// @Failure 500 {object} Response "Server error"
-// @Router /api/v1/performance/tables [get]
 func (pc *PerformanceController) GetTableStats(c *gin.Context) {
 	stats, err := pc.monitor.GetTableStats(c.Request.Context())
 	if err != nil {
@@ -118,18 +95,10 @@ func (pc *PerformanceController) GetTableStats(c *gin.Context) {
 		"count":     len(stats),
 	}, "Table statistics retrieved successfully")
 
-	c.JSON(http.StatusOK, stats)
+	SuccessResponse(c, http.StatusOK, "Table statistics retrieved successfully", stats)
 }
 
 // GetCacheHitRatio gets cache hit ratio statistics
-// @Summary Get cache hit ratio
-// @Description Retrieves cache hit ratio statistics for buffer and index caches
-// @Tags performance
-// @Security ApiKeyAuth
-// @Produce json
-// @Success 200 {object} performance.CacheStats
-// @Failure 500 {object} Response "Server error"
-// @Router /api/v1/performance/cache [get]
 func (pc *PerformanceController) GetCacheHitRatio(c *gin.Context) {
 	stats, err := pc.monitor.GetCacheHitRatio(c.Request.Context())
 	if err != nil {
@@ -149,18 +118,10 @@ func (pc *PerformanceController) GetCacheHitRatio(c *gin.Context) {
 		"index_hit":  stats.IndexCacheHitRatio,
 	}, "Cache hit ratio retrieved successfully")
 
-	c.JSON(http.StatusOK, stats)
+	SuccessResponse(c, http.StatusOK, "Cache hit ratio retrieved successfully", stats)
 }
 
 // GetOptimizationRecommendations gets optimization recommendations
-// @Summary Get optimization recommendations
-// @Description Retrieves automated recommendations for database optimization
-// @Tags performance
-// @Security ApiKeyAuth
-// @Produce json
-// @Success 200 {array} performance.OptimizationRecommendation
-// @Failure 500 {object} Response "Server error"
-// @Router /api/v1/performance/recommendations [get]
 func (pc *PerformanceController) GetOptimizationRecommendations(c *gin.Context) {
 	recommendations, err := pc.monitor.GetOptimizationRecommendations(c.Request.Context())
 	if err != nil {
@@ -180,18 +141,10 @@ func (pc *PerformanceController) GetOptimizationRecommendations(c *gin.Context)
 		"count":     len(recommendations),
 	}, "Optimization recommendations retrieved successfully")
 
-	c.JSON(http.StatusOK, recommendations)
+	SuccessResponse(c, http.StatusOK, "Optimization recommendations retrieved successfully", recommendations)
 }
 
 // RunOptimization executes database optimization
-// @Summary Run database optimization
-// @Description Executes automated database optimization procedures
-// @Tags performance
-// @Security ApiKeyAuth
-// @Produce json
-// @Success 200 {object} map[string]string
-// @Failure 500 {object} Response "Server error"
-// @Router /api/v1/performance/optimize [post]
 func (pc *PerformanceController) RunOptimization(c *gin.Context) {
 	result, err := pc.monitor.RunOptimization(c.Request.Context())
 	if err != nil {
@@ -211,21 +164,13 @@ func (pc *PerformanceController) RunOptimization(c *gin.Context) {
 		"result":    result,
 	}, "Database optimization executed successfully")
 
-	c.JSON(http.StatusOK, map[string]string{
+	SuccessResponse(c, http.StatusOK, "Database optimization executed successfully", map[string]string{
 		"result":    result,
 		"timestamp": time.Now().Format(time.RFC3339),
 	})
 }
 
 // PerformanceDashboard provides a comprehensive performance dashboard
-// @Summary Get performance dashboard
-// @Description Provides a comprehensive performance dashboard with key metrics and alerts
-// @Tags performance
-// @Security ApiKeyAuth
-// @Produce json
-// @Success 200 {object} map[string]interface{}
-// @Failure 500 {object} Response "Server error"
-// @Router /api/v1/performance/dashboard [get]
 func (pc *PerformanceController) PerformanceDashboard(c *gin.Context) {
 	// Get comprehensive metrics
 	metrics, err := pc.monitor.GetPerformanceMetrics(c.Request.Context())
@@ -276,7 +221,7 @@ func (pc *PerformanceController) PerformanceDashboard(c *gin.Context) {
 		"user_id":   getUserID(c),
 	}, "Performance dashboard generated successfully")
 
-	c.JSON(http.StatusOK, dashboard)
+	SuccessResponse(c, http.StatusOK, "Performance dashboard generated successfully", dashboard)
 }
 
 // Helper functions for performance analysis
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/placement"
+	"github.com/toeic-app/internal/social"
+	"github.com/toeic-app/internal/token"
+)
+
+// placementAnswerRequest answers the current item of a placement test
+type placementAnswerRequest struct {
+	Answer string `json:"answer" binding:"required"`
+}
+
+// placementErrorStatus maps placement errors to HTTP statuses
+func placementErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, placement.ErrTestNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, placement.ErrTestFinished), errors.Is(err, placement.ErrNoPendingItem):
+		return http.StatusConflict
+	case errors.Is(err, placement.ErrNoItems):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// @Summary     Start placement test
+// @Description Starts a short adaptive placement test, or resumes the one in progress. Each skill (vocabulary, listening, reading) gets a few items whose level follows the previous answers.
+// @Tags        placement
+// @Produce     json
+// @Success     200 {object} Response{data=placement.Test} "Placement test started"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     503 {object} Response "No placement items are available"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/placement-tests [post]
+func (server *Server) startPlacementTest(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	test, err := server.placement.Start(ctx, authPayload.ID)
+	if err != nil {
+		ErrorResponse(ctx, placementErrorStatus(err), "Failed to start placement test", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Placement test started", test)
+}
+
+// @Summary     Get placement test
+// @Description Returns a placement test of the current user with its current item or result
+// @Tags        placement
+// @Produce     json
+// @Param       id path int true "Placement test ID"
+// @Success     200 {object} Response{data=placement.Test} "Placement test retrieved successfully"
+// @Failure     400 {object} Response "Invalid placement test ID"
+// @Failure     404 {object} Response "Placement test not found"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/placement-tests/{id} [get]
+func (server *Server) getPlacementTest(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	testID, err := strconv.ParseInt(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid placement test ID", err)
+		return
+	}
+
+	test, err := server.placement.Get(ctx, authPayload.ID, int32(testID))
+	if err != nil {
+		ErrorResponse(ctx, placementErrorStatus(err), "Failed to get placement test", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Placement test retrieved successfully", test)
+}
+
+// @Summary     Answer placement item
+// @Description Answers the current item and returns the next one. The last answer completes the test, records the estimated levels and score on the profile and adds words at the vocabulary level to the review queue.
+// @Tags        placement
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Placement test ID"
+// @Param       request body placementAnswerRequest true "Selected option"
+// @Success     200 {object} Response{data=placement.AnswerOutcome} "Answer recorded"
+// @Failure     400 {object} Response "Invalid request parameters"
+// @Failure     404 {object} Response "Placement test not found"
+// @Failure     409 {object} Response "Placement test is already finished"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/placement-tests/{id}/answers [post]
+func (server *Server) answerPlacementItem(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	testID, err := strconv.ParseInt(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid placement test ID", err)
+		return
+	}
+
+	var req placementAnswerRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request parameters", err)
+		return
+	}
+
+	outcome, err := server.placement.Answer(ctx, authPayload.ID, int32(testID), req.Answer)
+	if err != nil {
+		ErrorResponse(ctx, placementErrorStatus(err), "Failed to record answer", err)
+		return
+	}
+	if outcome.Test.Status == placement.StatusCompleted && outcome.Test.Result != nil {
+		server.recordActivity(ctx, authPayload.ID, social.ActivityPlacementCompleted, map[string]interface{}{
+			"test_id":         outcome.Test.ID,
+			"estimated_score": outcome.Test.Result.EstimatedScore,
+		}, social.XPPlacementCompleted)
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Answer recorded", outcome)
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/playback"
+)
+
+type issuePlaybackTokenRequest struct {
+	AttemptID  int32 `uri:"id" binding:"required,min=1"`
+	QuestionID int32 `uri:"question_id" binding:"required,min=1"`
+}
+
+// @Summary     Issue a listening playback token
+// @Description Issues a token for playing the audio of a question in one of your in-progress exam attempts. Each question's audio can be played LISTENING_MAX_PLAYS times per attempt (once by default); asking for a new token replaces the old one but does not give more plays.
+// @Tags        exam-attempts
+// @Produce     json
+// @Param       id path int true "Exam Attempt ID"
+// @Param       question_id path int true "Question ID"
+// @Success     201 {object} Response{data=playback.Grant} "Playback token issued successfully"
+// @Failure     400 {object} Response "Invalid attempt or question ID"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     404 {object} Response "Exam attempt, question or audio not found"
+// @Failure     409 {object} Response "Exam attempt is not in progress"
+// @Failure     500 {object} Response "Failed to issue playback token"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/exam-attempts/{id}/questions/{question_id}/playback-token [post]
+func (server *Server) issuePlaybackToken(ctx *gin.Context) {
+	var uri issuePlaybackTokenRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "invalid_request_parameters", err)
+		return
+	}
+	attempt, ok := server.inProgressAttempt(ctx, uri.AttemptID)
+	if !ok {
+		return
+	}
+
+	grant, err := server.playback.Issue(ctx, attempt, uri.QuestionID)
+	if err != nil {
+		switch {
+		case errors.Is(err, playback.ErrQuestionNotFound):
+			ErrorResponse(ctx, http.StatusNotFound, "question_not_found", err)
+		case errors.Is(err, playback.ErrNoAudio):
+			ErrorResponse(ctx, http.StatusNotFound, "question_audio_not_found", err)
+		default:
+			ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_issue_playback_token", err)
+		}
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusCreated, "playback_token_issued_successfully", grant)
+}
+
+type playListeningAudioRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// @Summary     Play listening audio
+// @Description Uses up one play of a playback token and returns a signed URL of the question's audio that works for LISTENING_URL_TTL seconds. Plays, and replays refused because the limit was reached or the token expired, are recorded for the attempt's integrity score.
+// @Tags        exam-attempts
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Exam Attempt ID"
+// @Param       request body playListeningAudioRequest true "Playback token"
+// @Success     200 {object} Response{data=playback.Playback} "Audio URL issued successfully"
+// @Failure     400 {object} Response "Invalid request"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     404 {object} Response "Exam attempt or playback token not found"
+// @Failure     409 {object} Response "Exam attempt is not in progress or the play limit was reached"
+// @Failure     410 {object} Response "Playback token expired"
+// @Failure     500 {object} Response "Failed to issue audio URL"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/exam-attempts/{id}/playback [post]
+func (server *Server) playListeningAudio(ctx *gin.Context) {
+	var uri getExamAttemptRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "invalid_attempt_id", err)
+		return
+	}
+	var req playListeningAudioRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "invalid_request_body", err)
+		return
+	}
+	attempt, ok := server.inProgressAttempt(ctx, uri.AttemptID)
+	if !ok {
+		return
+	}
+
+	audio, err := server.playback.Play(ctx, attempt, req.Token)
+	if err != nil {
+		switch {
+		case errors.Is(err, playback.ErrInvalidToken):
+			ErrorResponse(ctx, http.StatusNotFound, "playback_token_not_found", err)
+		case errors.Is(err, playback.ErrQuestionNotFound), errors.Is(err, playback.ErrNoAudio):
+			ErrorResponse(ctx, http.StatusNotFound, "question_audio_not_found", err)
+		case errors.Is(err, playback.ErrPlayLimitReached):
+			ErrorResponse(ctx, http.StatusConflict, "play_limit_reached", err)
+		case errors.Is(err, playback.ErrTokenExpired):
+			ErrorResponse(ctx, http.StatusGone, "playback_token_expired", err)
+		default:
+			ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_issue_audio_url", err)
+		}
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "audio_url_issued_successfully", audio)
+}
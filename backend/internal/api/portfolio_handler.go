@@ -0,0 +1,164 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/portfolio"
+	"github.com/toeic-app/internal/token"
+)
+
+// sharePortfolioRequest creates a public link to the portfolio
+type sharePortfolioRequest struct {
+	Label         string `json:"label" binding:"max=100" example:"Acme Corp recruiter"`
+	ExpiresInDays int    `json:"expires_in_days" binding:"min=0" example:"7"`
+}
+
+// portfolioShareLinkResponse is a share link with its public URL. URL is
+// only returned when the link is created.
+type portfolioShareLinkResponse struct {
+	portfolio.ShareLink
+	URL string `json:"url,omitempty"`
+}
+
+// @Summary     Get my portfolio
+// @Description Returns my best evaluated writing submissions and speaking sessions with weekly score progression for charts
+// @Tags        users
+// @Produce     json
+// @Success     200 {object} Response{data=portfolio.Portfolio} "Portfolio retrieved successfully"
+// @Failure     401 {object} Response "Unauthorized"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/users/me/portfolio [get]
+func (server *Server) getMyPortfolio(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	result, err := server.portfolio.Build(ctx, authPayload.ID)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to build portfolio", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Portfolio retrieved successfully", result)
+}
+
+// @Summary     Share my portfolio
+// @Description Creates a public link to my portfolio that employers or teachers can open without an account. The link expires after the given number of days (default and maximum are configured). The token is only returned once.
+// @Tags        users
+// @Accept      json
+// @Produce     json
+// @Param       link body sharePortfolioRequest false "Label and lifetime of the link"
+// @Success     201 {object} Response{data=portfolioShareLinkResponse} "Portfolio share link created successfully"
+// @Failure     400 {object} Response "Invalid request parameters"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     409 {object} Response "Too many active share links"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/users/me/portfolio/shares [post]
+func (server *Server) shareMyPortfolio(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	var req sharePortfolioRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ErrorResponse(ctx, http.StatusBadRequest, "Invalid request parameters", err)
+			return
+		}
+	}
+
+	link, err := server.portfolio.Share(ctx, authPayload.ID, req.Label, req.ExpiresInDays)
+	if err != nil {
+		switch {
+		case errors.Is(err, portfolio.ErrInvalidDuration):
+			ErrorResponse(ctx, http.StatusBadRequest, "Share link lifetime is out of range", err)
+		case errors.Is(err, portfolio.ErrTooManyLinks):
+			ErrorResponse(ctx, http.StatusConflict, "Too many active share links", err)
+		default:
+			ErrorResponse(ctx, http.StatusInternalServerError, "Failed to create share link", err)
+		}
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusCreated, "Portfolio share link created successfully", portfolioShareLinkResponse{
+		ShareLink: *link,
+		URL:       externalURL(ctx, "/api/v1/portfolio/"+link.Token),
+	})
+}
+
+// @Summary     List my portfolio share links
+// @Description Lists my share links, newest first, with how often each was viewed
+// @Tags        users
+// @Produce     json
+// @Success     200 {object} Response{data=[]portfolio.ShareLink} "Portfolio share links retrieved successfully"
+// @Failure     401 {object} Response "Unauthorized"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/users/me/portfolio/shares [get]
+func (server *Server) listMyPortfolioShares(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	links, err := server.portfolio.Links(ctx, authPayload.ID)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to list share links", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Portfolio share links retrieved successfully", links)
+}
+
+// @Summary     Revoke a portfolio share link
+// @Description Stops a share link from working before it expires
+// @Tags        users
+// @Produce     json
+// @Param       id path int true "Share link ID"
+// @Success     200 {object} Response{data=portfolio.ShareLink} "Portfolio share link revoked successfully"
+// @Failure     400 {object} Response "Invalid share link ID"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     404 {object} Response "Share link not found"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/users/me/portfolio/shares/{id} [delete]
+func (server *Server) revokeMyPortfolioShare(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid share link ID", err)
+		return
+	}
+
+	link, err := server.portfolio.Revoke(ctx, authPayload.ID, int32(id))
+	if err != nil {
+		if errors.Is(err, portfolio.ErrLinkNotFound) {
+			ErrorResponse(ctx, http.StatusNotFound, "Share link not found", err)
+			return
+		}
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to revoke share link", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Portfolio share link revoked successfully", link)
+}
+
+// @Summary     Shared portfolio
+// @Description Returns the portfolio a share link points to. Authenticated by the token in the URL; each request counts as a view.
+// @Tags        portfolio
+// @Produce     json
+// @Param       token path string true "Share link token"
+// @Success     200 {object} Response{data=portfolio.Portfolio} "Portfolio retrieved successfully"
+// @Failure     404 {object} Response "Portfolio not found"
+// @Router      /api/v1/portfolio/{token} [get]
+func (server *Server) getSharedPortfolio(ctx *gin.Context) {
+	result, err := server.portfolio.View(ctx, ctx.Param("token"))
+	if err != nil {
+		// Unknown, expired and revoked links look the same to the caller
+		if errors.Is(err, portfolio.ErrLinkNotFound) {
+			ErrorResponse(ctx, http.StatusNotFound, "Portfolio not found", err)
+			return
+		}
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to build portfolio", err)
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Header("X-Robots-Tag", "noindex")
+	SuccessResponse(ctx, http.StatusOK, "Portfolio retrieved successfully", result)
+}
@@ -0,0 +1,38 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/prediction"
+	"github.com/toeic-app/internal/token"
+)
+
+// @Summary     Get my predicted score
+// @Description Estimates my full test score from my latest answer to each question in completed attempts, including part practice. The listening and reading abilities are fitted to the accuracy on each question weighted by its difficulty, calibrated from answers when available, and reported on the 5-495 section scale with 95% confidence intervals that narrow as more questions are answered; parts lists the accuracy per part. Predictions are recalculated after each completed attempt and nightly as question difficulty is recalibrated.
+// @Tags        users
+// @Produce     json
+// @Success     200 {object} Response{data=prediction.Prediction} "Predicted score retrieved successfully"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     404 {object} Response "Not enough answers to predict a score"
+// @Failure     500 {object} Response "Failed to predict score"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/users/me/predicted-score [get]
+func (server *Server) getMyPredictedScore(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	predicted, err := server.predictions.Get(ctx, authPayload.ID)
+	if errors.Is(err, prediction.ErrNotEnoughAnswers) {
+		ErrorResponseWithMessage(ctx, http.StatusNotFound,
+			fmt.Sprintf("Answer at least %d questions in exam attempts or part practice to get a predicted score", server.config.PredictionMinAnswers), err)
+		return
+	}
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to predict score", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Predicted score retrieved successfully", predicted)
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/preferences"
+	"github.com/toeic-app/internal/token"
+)
+
+// @Summary     Get my preferences
+// @Description Returns the effective preferences of the current user: theme, quiet hours, notification channels and learning session defaults. Keys the user never set have their default value.
+// @Tags        users
+// @Produce     json
+// @Success     200 {object} Response{data=preferences.Preferences} "Preferences retrieved successfully"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     500 {object} Response "Failed to retrieve preferences"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/users/me/preferences [get]
+func (server *Server) getMyPreferences(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	prefs, err := server.preferences.Get(ctx, authPayload.ID)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve preferences", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Preferences retrieved successfully", prefs)
+}
+
+// @Summary     Update my preferences
+// @Description Applies a JSON merge patch to the preferences of the current user. Only the keys present are changed and null resets a key to its default. Unknown keys and invalid values are rejected.
+// @Tags        users
+// @Accept      json
+// @Produce     json
+// @Param       preferences body object true "Preferences to change"
+// @Success     200 {object} Response{data=preferences.Preferences} "Preferences updated successfully"
+// @Failure     400 {object} Response "Invalid preferences"
+// @Failure     401 {object} Response "Unauthorized"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/users/me/preferences [patch]
+func (server *Server) updateMyPreferences(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	var patch map[string]interface{}
+	if err := ctx.ShouldBindJSON(&patch); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request parameters", err)
+		return
+	}
+
+	prefs, err := server.preferences.Update(ctx, authPayload.ID, patch)
+	if errors.Is(err, preferences.ErrInvalidPreferences) {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid preferences", err)
+		return
+	}
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to update preferences", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Preferences updated successfully", prefs)
+}
+
+// @Summary     Get preferences schema
+// @Description Returns the JSON schema that preference updates are validated against
+// @Tags        users
+// @Produce     json
+// @Success     200 {object} Response "Preferences schema retrieved successfully"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/users/me/preferences/schema [get]
+func (server *Server) getPreferencesSchema(ctx *gin.Context) {
+	SuccessResponse(ctx, http.StatusOK, "Preferences schema retrieved successfully", json.RawMessage(preferences.SchemaJSON))
+}
@@ -0,0 +1,374 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/accesslog"
+	"github.com/toeic-app/internal/config"
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/proctoring"
+	"github.com/toeic-app/internal/token"
+)
+
+// newProctoringService returns nil when proctoring is disabled
+func newProctoringService(cfg config.Config, store db.Querier, storage proctoring.Storage) *proctoring.Service {
+	if !cfg.ProctoringEnabled {
+		return nil
+	}
+	return proctoring.NewService(store, storage, proctoring.Options{
+		Folder:              cfg.ProctoringFolder,
+		PolicyVersion:       cfg.ProctoringPolicyVersion,
+		CaptureInterval:     cfg.ProctoringCaptureInterval,
+		MaxPhotoBytes:       cfg.ProctoringMaxPhotoBytes,
+		MaxPhotosPerAttempt: cfg.ProctoringMaxPhotosPerAttempt,
+		URLTTL:              cfg.ProctoringURLTTL,
+		RetentionDays:       cfg.ProctoringRetentionDays,
+	})
+}
+
+// proctoringEnabled writes an error response and returns false when
+// proctoring is disabled
+func (server *Server) proctoringEnabled(ctx *gin.Context) bool {
+	if server.proctoring == nil {
+		ErrorResponse(ctx, http.StatusServiceUnavailable, "proctoring_disabled", nil)
+		return false
+	}
+	return true
+}
+
+// @Summary     Get exam attempt proctoring status
+// @Description Returns whether one of your exam attempts is proctored: the version of the proctoring policy to consent to, your consent, how often to capture a webcam snapshot, the size and count limits, how many snapshots were taken and how many days they are kept.
+// @Tags        exam-attempts
+// @Produce     json
+// @Param       id path int true "Exam Attempt ID"
+// @Success     200 {object} Response{data=proctoring.Status} "Proctoring status retrieved successfully"
+// @Failure     400 {object} Response "Invalid attempt ID"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     404 {object} Response "Exam attempt not found"
+// @Failure     500 {object} Response "Failed to retrieve proctoring status"
+// @Failure     503 {object} Response "Proctoring is disabled"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/exam-attempts/{id}/proctoring [get]
+func (server *Server) getProctoringStatus(ctx *gin.Context) {
+	if !server.proctoringEnabled(ctx) {
+		return
+	}
+	var uri getExamAttemptRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "invalid_attempt_id", err)
+		return
+	}
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	attempt, err := server.store.GetExamAttemptByUser(ctx, db.GetExamAttemptByUserParams{
+		AttemptID: uri.AttemptID,
+		UserID:    authPayload.ID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ErrorResponse(ctx, http.StatusNotFound, "exam_attempt_not_found", err)
+			return
+		}
+		ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_retrieve_exam_attempt", err)
+		return
+	}
+
+	status, err := server.proctoring.Status(ctx, attempt)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_retrieve_proctoring_status", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "proctoring_status_retrieved_successfully", status)
+}
+
+type proctoringConsentRequest struct {
+	// PolicyVersion is the version of the notice the learner accepted
+	PolicyVersion string `json:"policy_version" binding:"required,max=20"`
+}
+
+// @Summary     Consent to exam proctoring
+// @Description Records that you accept webcam snapshots during one of your in-progress exam attempts under the given version of the proctoring policy. It must be the current version returned by the proctoring status. Your IP address and user agent are stored with the consent.
+// @Tags        exam-attempts
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Exam Attempt ID"
+// @Param       request body proctoringConsentRequest true "Accepted policy"
+// @Success     201 {object} Response{data=proctoring.Consent} "Proctoring consent recorded successfully"
+// @Failure     400 {object} Response "Invalid request"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     404 {object} Response "Exam attempt not found"
+// @Failure     409 {object} Response "Exam attempt is not in progress or the policy version is outdated"
+// @Failure     500 {object} Response "Failed to record proctoring consent"
+// @Failure     503 {object} Response "Proctoring is disabled"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/exam-attempts/{id}/proctoring/consent [post]
+func (server *Server) consentToProctoring(ctx *gin.Context) {
+	if !server.proctoringEnabled(ctx) {
+		return
+	}
+	var uri getExamAttemptRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "invalid_attempt_id", err)
+		return
+	}
+	var req proctoringConsentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "invalid_request_body", err)
+		return
+	}
+	attempt, ok := server.inProgressAttempt(ctx, uri.AttemptID)
+	if !ok {
+		return
+	}
+
+	consent, err := server.proctoring.Consent(ctx, attempt, req.PolicyVersion, ctx.ClientIP(), ctx.Request.UserAgent())
+	if err != nil {
+		if errors.Is(err, proctoring.ErrPolicyMismatch) {
+			ErrorResponse(ctx, http.StatusConflict, "proctoring_policy_outdated", err)
+			return
+		}
+		ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_record_proctoring_consent", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusCreated, "proctoring_consent_recorded_successfully", consent)
+}
+
+// @Summary     Upload a proctoring snapshot
+// @Description Stores a webcam snapshot of one of your in-progress exam attempts, after you consented to the current proctoring policy. Send a JPEG or PNG image of at most PROCTORING_MAX_PHOTO_BYTES every PROCTORING_CAPTURE_INTERVAL seconds; snapshots less than half an interval apart are refused. Snapshots are stored privately and deleted after PROCTORING_RETENTION_DAYS.
+// @Tags        exam-attempts
+// @Accept      multipart/form-data
+// @Produce     json
+// @Param       id path int true "Exam Attempt ID"
+// @Param       photo formData file true "JPEG or PNG snapshot"
+// @Param       captured_at formData string false "When the snapshot was taken, RFC 3339; defaults to now"
+// @Success     201 {object} Response{data=proctoring.Photo} "Proctoring photo stored successfully"
+// @Failure     400 {object} Response "Invalid request or image type"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     403 {object} Response "Consent to the current proctoring policy is required"
+// @Failure     404 {object} Response "Exam attempt not found"
+// @Failure     409 {object} Response "Exam attempt is not in progress or has reached its snapshot limit"
+// @Failure     413 {object} Response "Snapshot too large"
+// @Failure     429 {object} Response "Snapshots captured too often"
+// @Failure     500 {object} Response "Failed to store proctoring photo"
+// @Failure     503 {object} Response "Proctoring is disabled"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/exam-attempts/{id}/proctoring/photos [post]
+func (server *Server) uploadProctoringPhoto(ctx *gin.Context) {
+	if !server.proctoringEnabled(ctx) {
+		return
+	}
+	var uri getExamAttemptRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "invalid_attempt_id", err)
+		return
+	}
+	file, err := ctx.FormFile("photo")
+	if err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "proctoring_photo_missing", err)
+		return
+	}
+	if file.Size > server.config.ProctoringMaxPhotoBytes {
+		ErrorResponse(ctx, http.StatusRequestEntityTooLarge, "proctoring_photo_too_large", proctoring.ErrPhotoTooLarge)
+		return
+	}
+	var capturedAt time.Time
+	if value := ctx.PostForm("captured_at"); value != "" {
+		if capturedAt, err = time.Parse(time.RFC3339, value); err != nil {
+			ErrorResponse(ctx, http.StatusBadRequest, "invalid_request_body", err)
+			return
+		}
+	}
+	attempt, ok := server.inProgressAttempt(ctx, uri.AttemptID)
+	if !ok {
+		return
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_read_proctoring_photo", err)
+		return
+	}
+	defer src.Close()
+	data, err := io.ReadAll(io.LimitReader(src, server.config.ProctoringMaxPhotoBytes+1))
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_read_proctoring_photo", err)
+		return
+	}
+
+	photo, err := server.proctoring.Upload(ctx, attempt, proctoring.Snapshot{Data: data, CapturedAt: capturedAt})
+	if err != nil {
+		switch {
+		case errors.Is(err, proctoring.ErrUnsupportedImage):
+			ErrorResponse(ctx, http.StatusBadRequest, "unsupported_proctoring_photo", err)
+		case errors.Is(err, proctoring.ErrConsentRequired):
+			ErrorResponse(ctx, http.StatusForbidden, "proctoring_consent_required", err)
+		case errors.Is(err, proctoring.ErrPhotoLimitReached):
+			ErrorResponse(ctx, http.StatusConflict, "proctoring_photo_limit_reached", err)
+		case errors.Is(err, proctoring.ErrPhotoTooLarge):
+			ErrorResponse(ctx, http.StatusRequestEntityTooLarge, "proctoring_photo_too_large", err)
+		case errors.Is(err, proctoring.ErrTooFrequent):
+			ErrorResponse(ctx, http.StatusTooManyRequests, "proctoring_photos_too_frequent", err)
+		default:
+			ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_store_proctoring_photo", err)
+		}
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusCreated, "proctoring_photo_stored_successfully", photo)
+}
+
+type listProctoredAttemptsQuery struct {
+	FlaggedOnly bool  `form:"flagged_only"`
+	Limit       int32 `form:"limit,default=20" binding:"min=1,max=100"`
+	Offset      int32 `form:"offset" binding:"min=0"`
+}
+
+// @Summary     List proctored exam attempts
+// @Description Lists exam attempts with webcam snapshots, most recently captured first, with how many snapshots were taken and flagged. Requires exams.grade. (admin only)
+// @Tags        admin
+// @Produce     json
+// @Param       flagged_only query bool false "Only attempts with flagged snapshots"
+// @Param       limit query int false "Maximum attempts" default(20)
+// @Param       offset query int false "Attempts to skip" default(0)
+// @Success     200 {object} Response{data=[]proctoring.AttemptSummary} "Proctored attempts retrieved successfully"
+// @Failure     400 {object} Response "Invalid query parameters"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     403 {object} Response "Permission denied"
+// @Failure     500 {object} Response "Failed to retrieve proctored attempts"
+// @Failure     503 {object} Response "Proctoring is disabled"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/proctoring/attempts [get]
+func (server *Server) listProctoredAttempts(ctx *gin.Context) {
+	if !server.proctoringEnabled(ctx) {
+		return
+	}
+	var query listProctoredAttemptsQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "invalid_query_parameters", err)
+		return
+	}
+
+	attempts, total, err := server.proctoring.Attempts(ctx, query.FlaggedOnly, query.Limit, query.Offset)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_retrieve_proctored_attempts", err)
+		return
+	}
+
+	PaginatedResponse(ctx, http.StatusOK, "proctored_attempts_retrieved_successfully", attempts,
+		NewPagination(query.Limit, query.Offset, len(attempts)).WithTotal(total))
+}
+
+// @Summary     List proctoring snapshots of an attempt
+// @Description Returns the webcam snapshots of an exam attempt in capture order, each with a signed URL that works for PROCTORING_URL_TTL seconds. Requires exams.grade. The read is recorded in the learner's access log. (admin only)
+// @Tags        admin
+// @Produce     json
+// @Param       id path int true "Exam Attempt ID"
+// @Param       X-Access-Purpose header string false "Why the snapshots are read: support, grading, moderation, legal or unspecified"
+// @Success     200 {object} Response{data=[]proctoring.Photo} "Proctoring photos retrieved successfully"
+// @Failure     400 {object} Response "Invalid attempt ID or access purpose"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     403 {object} Response "Permission denied"
+// @Failure     404 {object} Response "Exam attempt not found"
+// @Failure     500 {object} Response "Failed to retrieve proctoring photos"
+// @Failure     503 {object} Response "Proctoring is disabled"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/proctoring/attempts/{id}/photos [get]
+func (server *Server) listProctoringPhotos(ctx *gin.Context) {
+	if !server.proctoringEnabled(ctx) {
+		return
+	}
+	var uri getExamAttemptRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "invalid_attempt_id", err)
+		return
+	}
+	attempt, err := server.store.GetExamAttempt(ctx, uri.AttemptID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ErrorResponse(ctx, http.StatusNotFound, "exam_attempt_not_found", err)
+			return
+		}
+		ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_retrieve_exam_attempt", err)
+		return
+	}
+	if !server.recordDataAccess(ctx, accesslog.Access{
+		SubjectID:    attempt.UserID,
+		ResourceType: accesslog.ResourceProctoringPhotos,
+		ResourceID:   attempt.AttemptID,
+	}) {
+		return
+	}
+
+	photos, err := server.proctoring.Photos(ctx, attempt.AttemptID)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_retrieve_proctoring_photos", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "proctoring_photos_retrieved_successfully", photos)
+}
+
+type reviewProctoringPhotoURI struct {
+	PhotoID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type reviewProctoringPhotoRequest struct {
+	Flagged *bool `json:"flagged" binding:"required"`
+	// Note replaces the previous note; omit it to clear the note
+	Note *string `json:"note" binding:"omitempty,max=1000"`
+}
+
+// @Summary     Review a proctoring snapshot
+// @Description Flags a webcam snapshot as suspicious, or clears the flag, with an optional note. The reviewer and time are recorded. Requires exams.grade. (admin only)
+// @Tags        admin
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Photo ID"
+// @Param       request body reviewProctoringPhotoRequest true "Review"
+// @Success     200 {object} Response{data=proctoring.Photo} "Proctoring photo reviewed successfully"
+// @Failure     400 {object} Response "Invalid request"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     403 {object} Response "Permission denied"
+// @Failure     404 {object} Response "Photo not found"
+// @Failure     500 {object} Response "Failed to review proctoring photo"
+// @Failure     503 {object} Response "Proctoring is disabled"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/proctoring/photos/{id} [patch]
+func (server *Server) reviewProctoringPhoto(ctx *gin.Context) {
+	if !server.proctoringEnabled(ctx) {
+		return
+	}
+	var uri reviewProctoringPhotoURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "invalid_photo_id", err)
+		return
+	}
+	var req reviewProctoringPhotoRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "invalid_request_body", err)
+		return
+	}
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	photo, err := server.proctoring.Review(ctx, uri.PhotoID, authPayload.ID, proctoring.Review{
+		Flagged: *req.Flagged,
+		Note:    req.Note,
+	})
+	if err != nil {
+		if errors.Is(err, proctoring.ErrPhotoNotFound) {
+			ErrorResponse(ctx, http.StatusNotFound, "proctoring_photo_not_found", err)
+			return
+		}
+		ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_review_proctoring_photo", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "proctoring_photo_reviewed_successfully", photo)
+}
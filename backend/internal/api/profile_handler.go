@@ -0,0 +1,228 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/i18n"
+	"github.com/toeic-app/internal/logger"
+	"github.com/toeic-app/internal/token"
+)
+
+// examDateLayout is the format of exam dates in profile requests and responses
+const examDateLayout = "2006-01-02"
+
+// UserProfileResponse is the profile of the current user including study goals
+type UserProfileResponse struct {
+	FullName          string `json:"full_name,omitempty"`
+	Bio               string `json:"bio,omitempty"`
+	AvatarURL         string `json:"avatar_url,omitempty"`
+	TargetScore       *int32 `json:"target_score,omitempty" example:"750"`
+	ExamDate          string `json:"exam_date,omitempty" example:"2026-12-20" format:"date"`
+	DailyMinutes      *int32 `json:"daily_minutes,omitempty" example:"45"`
+	NativeLanguage    string `json:"native_language,omitempty" example:"vi"`
+	PlacementScore    *int32 `json:"placement_score,omitempty" example:"520"`
+	PreferredLanguage string `json:"preferred_language,omitempty" example:"vi"`
+	UpdatedAt         string `json:"updated_at,omitempty" format:"date-time"`
+}
+
+// NewUserProfileResponse creates a UserProfileResponse from a profile model
+func NewUserProfileResponse(profile db.UserProfile) UserProfileResponse {
+	resp := UserProfileResponse{
+		FullName:          profile.FullName.String,
+		Bio:               profile.Bio.String,
+		AvatarURL:         profile.AvatarUrl.String,
+		NativeLanguage:    profile.NativeLanguage.String,
+		PreferredLanguage: profile.PreferredLanguage.String,
+		UpdatedAt:         profile.UpdatedAt.Format(time.RFC3339),
+	}
+	if profile.TargetScore.Valid {
+		resp.TargetScore = &profile.TargetScore.Int32
+	}
+	if profile.DailyMinutes.Valid {
+		resp.DailyMinutes = &profile.DailyMinutes.Int32
+	}
+	if profile.PlacementScore.Valid {
+		resp.PlacementScore = &profile.PlacementScore.Int32
+	}
+	if profile.ExamDate.Valid {
+		resp.ExamDate = profile.ExamDate.Time.Format(examDateLayout)
+	}
+	return resp
+}
+
+// updateProfileRequest replaces the profile of the current user. Omitted fields are cleared.
+type updateProfileRequest struct {
+	FullName       string `json:"full_name" binding:"max=255"`
+	Bio            string `json:"bio" binding:"max=2000"`
+	AvatarURL      string `json:"avatar_url" binding:"omitempty,url,max=255"`
+	TargetScore    *int32 `json:"target_score" binding:"omitempty,min=10,toeic_score"`
+	ExamDate       string `json:"exam_date" binding:"omitempty,datetime=2006-01-02"`
+	DailyMinutes   *int32 `json:"daily_minutes" binding:"omitempty,min=5,max=600"`
+	NativeLanguage string `json:"native_language" binding:"omitempty,language_code"`
+}
+
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+func nullInt32(value *int32) sql.NullInt32 {
+	if value == nil {
+		return sql.NullInt32{}
+	}
+	return sql.NullInt32{Int32: *value, Valid: true}
+}
+
+// @Summary     Get my profile
+// @Description Returns the profile and study goals of the current user
+// @Tags        users
+// @Produce     json
+// @Success     200 {object} Response{data=UserProfileResponse} "Profile retrieved successfully"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     500 {object} Response "Failed to retrieve profile"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/users/me/profile [get]
+func (server *Server) getMyProfile(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	profile, err := server.store.GetUserProfileByUserID(ctx, sql.NullInt32{Int32: authPayload.ID, Valid: true})
+	if errors.Is(err, sql.ErrNoRows) {
+		SuccessResponse(ctx, http.StatusOK, "Profile retrieved successfully", UserProfileResponse{})
+		return
+	}
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve profile", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Profile retrieved successfully", NewUserProfileResponse(profile))
+}
+
+// @Summary     Update my profile
+// @Description Replaces the profile and study goals of the current user. The goals drive the generated study plan.
+// @Tags        users
+// @Accept      json
+// @Produce     json
+// @Param       profile body updateProfileRequest true "Profile and study goals"
+// @Success     200 {object} Response{data=UserProfileResponse} "Profile updated successfully"
+// @Failure     400 {object} Response "Invalid request parameters"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     500 {object} Response "Failed to update profile"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/users/me/profile [put]
+func (server *Server) updateMyProfile(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	var req updateProfileRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request parameters", err)
+		return
+	}
+
+	arg := db.UpsertUserProfileParams{
+		UserID:         sql.NullInt32{Int32: authPayload.ID, Valid: true},
+		FullName:       nullString(req.FullName),
+		Bio:            nullString(req.Bio),
+		AvatarUrl:      nullString(req.AvatarURL),
+		TargetScore:    nullInt32(req.TargetScore),
+		DailyMinutes:   nullInt32(req.DailyMinutes),
+		NativeLanguage: nullString(req.NativeLanguage),
+	}
+	if req.ExamDate != "" {
+		examDate, err := time.Parse(examDateLayout, req.ExamDate)
+		if err != nil {
+			ErrorResponse(ctx, http.StatusBadRequest, "Invalid exam date, expected YYYY-MM-DD", err)
+			return
+		}
+		arg.ExamDate = sql.NullTime{Time: examDate, Valid: true}
+	}
+
+	profile, err := server.store.UpsertUserProfile(ctx, arg)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to update profile", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Profile updated successfully", NewUserProfileResponse(profile))
+}
+
+// updatePreferredLanguageRequest sets the preferred language of the current
+// user. An empty language clears it.
+type updatePreferredLanguageRequest struct {
+	Language string `json:"language" binding:"omitempty,oneof=en vi" example:"vi"`
+}
+
+// @Summary     Set my preferred language
+// @Description Sets the language of API messages for requests of the current user that do not ask for one. The language of a request is, by precedence: the lang query parameter (for debugging), the X-Language header, the Accept-Language header, this preferred language, then English. Responses tell the language in Content-Language and where it came from in X-Language-Source (query, header, accept-language, profile or default).
+// @Tags        users
+// @Accept      json
+// @Produce     json
+// @Param       language body updatePreferredLanguageRequest true "Preferred language, empty to clear it"
+// @Success     200 {object} Response{data=UserProfileResponse} "Preferred language updated successfully"
+// @Failure     400 {object} Response "Invalid request parameters"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     500 {object} Response "Failed to update preferred language"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/users/me/language [put]
+func (server *Server) updateMyPreferredLanguage(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	var req updatePreferredLanguageRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request parameters", err)
+		return
+	}
+
+	profile, err := server.store.SetUserPreferredLanguage(ctx, db.SetUserPreferredLanguageParams{
+		UserID:            sql.NullInt32{Int32: authPayload.ID, Valid: true},
+		PreferredLanguage: nullString(req.Language),
+	})
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to update preferred language", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Preferred language updated successfully", NewUserProfileResponse(profile))
+}
+
+// applyPreferredLanguage uses the preferred language of an authenticated
+// user when the request did not ask for a language. The profile is only
+// read in that case.
+func (server *Server) applyPreferredLanguage(ctx *gin.Context, userID int32) {
+	if i18n.GetLanguageSource(ctx) != i18n.SourceDefault {
+		return
+	}
+	preferred, err := server.store.GetUserPreferredLanguage(ctx, sql.NullInt32{Int32: userID, Valid: true})
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			logger.Warn("Failed to read preferred language of user %d: %v", userID, err)
+		}
+		return
+	}
+	i18n.ApplyPreferredLanguage(ctx, preferred.String)
+}
+
+// @Summary     Get my study plan
+// @Description Generates a personalized study plan from the profile goals (target score, exam date, daily availability, native language) and the current progress of the user
+// @Tags        users
+// @Produce     json
+// @Success     200 {object} Response{data=studyplan.Plan} "Study plan generated successfully"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     500 {object} Response "Failed to generate study plan"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/users/me/study-plan [get]
+func (server *Server) getMyStudyPlan(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	plan, err := server.studyPlans.Generate(ctx, authPayload.ID)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to generate study plan", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Study plan generated successfully", plan)
+}
@@ -0,0 +1,345 @@
+package api
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/apikey"
+	"github.com/toeic-app/internal/authoring"
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+// apiKeyHeader carries the key of a third-party tool on public API requests
+const apiKeyHeader = "X-API-Key"
+
+// publicAPIPrefix is the path of the public API group; usage is recorded per
+// route below it
+const publicAPIPrefix = "/api/v1/public"
+
+type publicListRequest struct {
+	Limit  int32 `form:"limit,default=20" binding:"min=1,max=100"`
+	Offset int32 `form:"offset,default=0" binding:"min=0"`
+}
+
+type publicSearchRequest struct {
+	Query  string `form:"query" binding:"required"`
+	Limit  int32  `form:"limit,default=20" binding:"min=1,max=100"`
+	Offset int32  `form:"offset,default=0" binding:"min=0"`
+}
+
+// publicAPIAuth authenticates the API key of a request and enforces its
+// per-minute limit and daily quota. Quota headers are set on every response.
+func (server *Server) publicAPIAuth() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		// Errors and throttled responses must never be served from a shared cache
+		ctx.Header("Cache-Control", "no-store")
+
+		plaintext := ctx.GetHeader(apiKeyHeader)
+		if plaintext == "" {
+			ErrorResponse(ctx, http.StatusUnauthorized, "API key required", fmt.Errorf("missing %s header", apiKeyHeader))
+			ctx.Abort()
+			return
+		}
+
+		key, err := server.apiKeys.Authenticate(ctx, plaintext)
+		if err != nil {
+			if errors.Is(err, apikey.ErrInvalidKey) || errors.Is(err, apikey.ErrKeyRevoked) {
+				ErrorResponse(ctx, http.StatusUnauthorized, err.Error(), err)
+				ctx.Abort()
+				return
+			}
+			ErrorResponse(ctx, http.StatusInternalServerError, "Failed to verify API key", err)
+			ctx.Abort()
+			return
+		}
+
+		endpoint := strings.TrimPrefix(ctx.FullPath(), publicAPIPrefix)
+		decision := server.apiKeys.Allow(key, endpoint)
+
+		ctx.Header("X-RateLimit-Limit", strconv.Itoa(int(decision.Limit)))
+		ctx.Header("X-RateLimit-Remaining", strconv.FormatInt(max(decision.Remaining, 0), 10))
+		ctx.Header("X-RateLimit-Reset", strconv.FormatInt(decision.Reset.Unix(), 10))
+
+		if !decision.Allowed {
+			retryAfter := int(decision.RetryAfter.Seconds())
+			if retryAfter < 1 {
+				retryAfter = 1
+			}
+			ctx.Header("Retry-After", strconv.Itoa(retryAfter))
+
+			message := "Rate limit exceeded"
+			if decision.Reason == apikey.ReasonQuotaExceeded {
+				message = "Daily quota exceeded"
+			}
+			ErrorResponse(ctx, http.StatusTooManyRequests, message, errors.New(decision.Reason))
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// publicResponse writes a cacheable public API response. The ETag is derived
+// from the body, so unchanged data is answered with 304 Not Modified. Meta
+// only carries the page: the request ID and timing would change the ETag of
+// every response, so they are left to the X-Request-ID header.
+func (server *Server) publicResponse(ctx *gin.Context, data any, page *Pagination) {
+	resp := Response{Status: "success", Data: data}
+	if page != nil {
+		resp.Meta = &Meta{Pagination: page}
+	}
+	body, err := json.Marshal(resp)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to encode response", err)
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	maxAge := server.config.PublicAPICacheMaxAge
+
+	ctx.Header("ETag", etag)
+	// Cached copies are keyed by API key so the edge never serves a response
+	// to a caller that did not authenticate
+	ctx.Header("Vary", apiKeyHeader)
+	ctx.Header("Cache-Control", fmt.Sprintf("public, max-age=%d, stale-while-revalidate=%d", maxAge, maxAge))
+
+	if etagMatches(ctx.GetHeader("If-None-Match"), etag) {
+		ctx.Status(http.StatusNotModified)
+		return
+	}
+	ctx.Data(http.StatusOK, "application/json; charset=utf-8", body)
+}
+
+// etagMatches reports whether an If-None-Match header lists etag
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// @Summary     List words (public API)
+// @Description Lists vocabulary words with pagination. Requires an API key in the X-API-Key header; responses carry X-RateLimit-* quota headers and are cacheable (ETag, Cache-Control).
+// @Tags        public
+// @Produce     json
+// @Param       X-API-Key header string true "API key"
+// @Param       limit query int false "Page size (1-100, default 20)"
+// @Param       offset query int false "Offset (default 0)"
+// @Success     200 {object} Response{data=[]WordResponse} "Words"
+// @Success     304 "Not modified"
+// @Failure     400 {object} Response "Invalid query parameters"
+// @Failure     401 {object} Response "Missing, invalid or revoked API key"
+// @Failure     429 {object} Response "Rate limit or daily quota exceeded"
+// @Router      /api/v1/public/words [get]
+func (server *Server) publicListWords(ctx *gin.Context) {
+	var req publicListRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	words, err := server.store.ListWords(ctx, db.ListWordsParams{Limit: req.Limit, Offset: req.Offset})
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to list words", err)
+		return
+	}
+
+	responses := make([]WordResponse, 0, len(words))
+	for _, word := range words {
+		responses = append(responses, NewWordResponse(word))
+	}
+	server.publicResponse(ctx, responses, NewPagination(req.Limit, req.Offset, len(responses)))
+}
+
+// @Summary     Search words (public API)
+// @Description Searches vocabulary words. Requires an API key in the X-API-Key header.
+// @Tags        public
+// @Produce     json
+// @Param       X-API-Key header string true "API key"
+// @Param       query query string true "Search term"
+// @Param       limit query int false "Page size (1-100, default 20)"
+// @Param       offset query int false "Offset (default 0)"
+// @Success     200 {object} Response{data=[]WordResponse} "Matching words"
+// @Success     304 "Not modified"
+// @Failure     400 {object} Response "Invalid query parameters"
+// @Failure     401 {object} Response "Missing, invalid or revoked API key"
+// @Failure     429 {object} Response "Rate limit or daily quota exceeded"
+// @Router      /api/v1/public/words/search [get]
+func (server *Server) publicSearchWords(ctx *gin.Context) {
+	var req publicSearchRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	words, err := server.store.SearchWords(ctx, db.SearchWordsParams{
+		Column1: sql.NullString{String: req.Query, Valid: true},
+		Limit:   req.Limit,
+		Offset:  req.Offset,
+	})
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to search words", err)
+		return
+	}
+
+	responses := make([]WordResponse, 0, len(words))
+	for _, word := range words {
+		responses = append(responses, NewWordResponse(word))
+	}
+	server.publicResponse(ctx, responses, NewPagination(req.Limit, req.Offset, len(responses)))
+}
+
+// @Summary     Get a word (public API)
+// @Description Returns a vocabulary word with its meanings, synonyms and conjugation. Requires an API key in the X-API-Key header.
+// @Tags        public
+// @Produce     json
+// @Param       X-API-Key header string true "API key"
+// @Param       id path int true "Word ID"
+// @Success     200 {object} Response{data=WordResponse} "Word"
+// @Success     304 "Not modified"
+// @Failure     400 {object} Response "Invalid word ID"
+// @Failure     401 {object} Response "Missing, invalid or revoked API key"
+// @Failure     404 {object} Response "Word not found"
+// @Failure     429 {object} Response "Rate limit or daily quota exceeded"
+// @Router      /api/v1/public/words/{id} [get]
+func (server *Server) publicGetWord(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 32)
+	if err != nil || id <= 0 {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid word ID", err)
+		return
+	}
+
+	word, err := server.store.GetWord(ctx, int32(id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ErrorResponse(ctx, http.StatusNotFound, "Word not found", err)
+			return
+		}
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to get word", err)
+		return
+	}
+
+	response := NewWordResponse(word)
+	response.Senses = server.wordSenses(ctx, word.ID)
+	server.publicResponse(ctx, response, nil)
+}
+
+// @Summary     List grammars (public API)
+// @Description Lists grammar topics with pagination. Requires an API key in the X-API-Key header.
+// @Tags        public
+// @Produce     json
+// @Param       X-API-Key header string true "API key"
+// @Param       limit query int false "Page size (1-100, default 20)"
+// @Param       offset query int false "Offset (default 0)"
+// @Success     200 {object} Response{data=[]GrammarResponse} "Grammars"
+// @Success     304 "Not modified"
+// @Failure     400 {object} Response "Invalid query parameters"
+// @Failure     401 {object} Response "Missing, invalid or revoked API key"
+// @Failure     429 {object} Response "Rate limit or daily quota exceeded"
+// @Router      /api/v1/public/grammars [get]
+func (server *Server) publicListGrammars(ctx *gin.Context) {
+	var req publicListRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	grammars, err := server.store.ListGrammars(ctx, db.ListGrammarsParams{Limit: req.Limit, Offset: req.Offset})
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to list grammars", err)
+		return
+	}
+
+	responses := make([]GrammarResponse, 0, len(grammars))
+	for _, grammar := range grammars {
+		responses = append(responses, NewGrammarResponse(grammar))
+	}
+	server.publicResponse(ctx, responses, NewPagination(req.Limit, req.Offset, len(responses)))
+}
+
+// @Summary     Search grammars (public API)
+// @Description Searches grammar topics. Requires an API key in the X-API-Key header.
+// @Tags        public
+// @Produce     json
+// @Param       X-API-Key header string true "API key"
+// @Param       query query string true "Search term"
+// @Param       limit query int false "Page size (1-100, default 20)"
+// @Param       offset query int false "Offset (default 0)"
+// @Success     200 {object} Response{data=[]GrammarResponse} "Matching grammars"
+// @Success     304 "Not modified"
+// @Failure     400 {object} Response "Invalid query parameters"
+// @Failure     401 {object} Response "Missing, invalid or revoked API key"
+// @Failure     429 {object} Response "Rate limit or daily quota exceeded"
+// @Router      /api/v1/public/grammars/search [get]
+func (server *Server) publicSearchGrammars(ctx *gin.Context) {
+	var req publicSearchRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	grammars, err := server.store.SearchGrammars(ctx, db.SearchGrammarsParams{
+		Column1: sql.NullString{String: req.Query, Valid: true},
+		Limit:   req.Limit,
+		Offset:  req.Offset,
+	})
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to search grammars", err)
+		return
+	}
+
+	responses := make([]GrammarResponse, 0, len(grammars))
+	for _, grammar := range grammars {
+		responses = append(responses, NewGrammarResponse(grammar))
+	}
+	server.publicResponse(ctx, responses, NewPagination(req.Limit, req.Offset, len(responses)))
+}
+
+// @Summary     Get a grammar (public API)
+// @Description Returns a grammar topic with its contents. Requires an API key in the X-API-Key header.
+// @Tags        public
+// @Produce     json
+// @Param       X-API-Key header string true "API key"
+// @Param       id path int true "Grammar ID"
+// @Success     200 {object} Response{data=GrammarResponse} "Grammar"
+// @Success     304 "Not modified"
+// @Failure     400 {object} Response "Invalid grammar ID"
+// @Failure     401 {object} Response "Missing, invalid or revoked API key"
+// @Failure     404 {object} Response "Grammar not found"
+// @Failure     429 {object} Response "Rate limit or daily quota exceeded"
+// @Router      /api/v1/public/grammars/{id} [get]
+func (server *Server) publicGetGrammar(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 32)
+	if err != nil || id <= 0 {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid grammar ID", err)
+		return
+	}
+
+	grammar, err := server.store.GetGrammar(ctx, int32(id))
+	if err == nil && grammar.Status != authoring.StatusPublished {
+		err = sql.ErrNoRows
+	}
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ErrorResponse(ctx, http.StatusNotFound, "Grammar not found", err)
+			return
+		}
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to get grammar", err)
+		return
+	}
+
+	server.publicResponse(ctx, NewGrammarResponse(grammar), nil)
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// checkQuestionDuplicatesRequest is a question to compare with the bank
+type checkQuestionDuplicatesRequest struct {
+	Title           string   `json:"title" binding:"required" example:"The quarterly report ___ by Friday."`
+	PossibleAnswers []string `json:"possible_answers" example:"is finished,will be finished,finishing,finish"`
+	// QuestionID excludes an existing question from its own matches
+	QuestionID int32 `json:"question_id" binding:"min=0"`
+}
+
+type listDuplicateQuestionsQuery struct {
+	MinSimilarity float32 `form:"min_similarity" binding:"omitempty,min=0.3,max=1"`
+	Limit         int32   `form:"limit,default=20" binding:"min=1,max=100"`
+	Offset        int32   `form:"offset" binding:"min=0"`
+}
+
+// @Summary     Check a question for duplicates
+// @Description Lists existing questions whose normalized title and answer options are identical (exact) or very similar to the given question, exact matches first, so authors can check before saving
+// @Tags        questions
+// @Accept      json
+// @Produce     json
+// @Param       question body checkQuestionDuplicatesRequest true "Question to check"
+// @Success     200 {object} Response{data=[]duplicates.Candidate} "Duplicate candidates retrieved successfully"
+// @Failure     400 {object} Response "Invalid request body"
+// @Failure     401 {object} Response "Unauthorized"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/questions/duplicates [post]
+func (server *Server) checkQuestionDuplicates(ctx *gin.Context) {
+	var req checkQuestionDuplicatesRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	candidates, err := server.duplicates.Candidates(ctx, req.Title, req.PossibleAnswers, req.QuestionID)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to check question for duplicates", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Duplicate candidates retrieved successfully", candidates)
+}
+
+// @Summary     Duplicate questions report
+// @Description Lists pairs of questions in the bank that are exact or near duplicates of each other, exact duplicates first, then most similar first. Requires exams.update. (admin only)
+// @Tags        admin
+// @Produce     json
+// @Param       min_similarity query number false "Smallest similarity from 0.3 to 1; defaults to the configured threshold"
+// @Param       limit query int false "Maximum pairs" default(20)
+// @Param       offset query int false "Pairs to skip" default(0)
+// @Success     200 {object} Response{data=[]duplicates.Pair} "Duplicate questions retrieved successfully"
+// @Failure     400 {object} Response "Invalid query parameters"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     403 {object} Response "Permission denied"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/question-duplicates [get]
+func (server *Server) listDuplicateQuestions(ctx *gin.Context) {
+	var query listDuplicateQuestionsQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	pairs, total, err := server.duplicates.Report(ctx, query.MinSimilarity, query.Limit, query.Offset)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to list duplicate questions", err)
+		return
+	}
+
+	PaginatedResponse(ctx, http.StatusOK, "Duplicate questions retrieved successfully", pairs,
+		NewPagination(query.Limit, query.Offset, len(pairs)).WithTotal(total))
+}
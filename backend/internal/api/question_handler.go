@@ -1,25 +1,38 @@
 package api
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/billing"
+	"github.com/toeic-app/internal/choices"
 	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/duplicates"
+	"github.com/toeic-app/internal/jobs"
+	"github.com/toeic-app/internal/logger"
+	"github.com/toeic-app/internal/token"
 )
 
 // QuestionResponse defines the structure for question information returned to clients.
+// PossibleAnswers and TrueAnswer repeat the texts of Options and of the
+// correct option for older clients.
 type QuestionResponse struct {
-	QuestionID      int32    `json:"question_id"`
-	ContentID       int32    `json:"content_id"`
-	Title           string   `json:"title"`
-	MediaURL        string   `json:"media_url,omitempty"`
-	ImageURL        string   `json:"image_url,omitempty"`
-	PossibleAnswers []string `json:"possible_answers"`
-	TrueAnswer      string   `json:"true_answer"`
-	Explanation     string   `json:"explanation"`
-	Keywords        string   `json:"keywords,omitempty"`
+	QuestionID      int32            `json:"question_id"`
+	ContentID       int32            `json:"content_id"`
+	Title           string           `json:"title"`
+	MediaURL        string           `json:"media_url,omitempty"`
+	ImageURL        string           `json:"image_url,omitempty"`
+	Options         []choices.Option `json:"options"`
+	AnswerKey       string           `json:"answer_key" example:"A"`
+	PossibleAnswers []string         `json:"possible_answers"`
+	TrueAnswer      string           `json:"true_answer"`
+	Explanation     string           `json:"explanation"`
+	Keywords        string           `json:"keywords,omitempty"`
+	Difficulty      int16            `json:"difficulty,omitempty"`
 }
 
 // NewQuestionResponse creates a QuestionResponse from a db.Question model
@@ -41,33 +54,48 @@ func NewQuestionResponse(question db.Question) QuestionResponse {
 		Title:           question.Title,
 		MediaURL:        mediaURL,
 		ImageURL:        imageURL,
+		Options:         choices.Of(question),
+		AnswerKey:       choices.AnswerOf(question),
 		PossibleAnswers: question.PossibleAnswers,
 		TrueAnswer:      question.TrueAnswer,
 		Explanation:     question.Explanation,
 		Keywords:        keywords,
+		Difficulty:      question.Difficulty.Int16,
 	}
 }
 
-// createQuestionRequest defines the structure for creating a new question
+// createQuestionResponse is the created question with the existing
+// questions it may duplicate
+type createQuestionResponse struct {
+	QuestionResponse
+	Duplicates []duplicates.Candidate `json:"duplicates"`
+}
+
+// createQuestionRequest defines the structure for creating a new question.
+// Older clients send the option texts in PossibleAnswers and the text of
+// the correct one in TrueAnswer instead of Options and AnswerKey.
 type createQuestionRequest struct {
-	ContentID       int32    `json:"content_id" binding:"required,min=1"`
-	Title           string   `json:"title" binding:"required"`
-	MediaURL        string   `json:"media_url,omitempty"`
-	ImageURL        string   `json:"image_url,omitempty"`
-	PossibleAnswers []string `json:"possible_answers" binding:"required,min=1"`
-	TrueAnswer      string   `json:"true_answer" binding:"required"`
-	Explanation     string   `json:"explanation" binding:"required"`
-	Keywords        string   `json:"keywords,omitempty"`
+	ContentID       int32            `json:"content_id" binding:"required,min=1"`
+	Title           string           `json:"title" binding:"required" sanitize:"plain"`
+	MediaURL        string           `json:"media_url,omitempty"`
+	ImageURL        string           `json:"image_url,omitempty"`
+	Options         []choices.Option `json:"options" binding:"required_without=PossibleAnswers"`
+	AnswerKey       string           `json:"answer_key" binding:"required_without=TrueAnswer" example:"A"`
+	PossibleAnswers []string         `json:"possible_answers" binding:"required_without=Options" sanitize:"plain"`
+	TrueAnswer      string           `json:"true_answer" binding:"required_without=AnswerKey" sanitize:"plain"`
+	Explanation     string           `json:"explanation" binding:"required" sanitize:"markdown"`
+	Keywords        string           `json:"keywords,omitempty"`
+	Difficulty      int16            `json:"difficulty,omitempty" binding:"omitempty,difficulty_level"` // 1 (easiest) to 6, same scale as word levels
 }
 
 // @Summary     Create a new question
-// @Description Add a new question to content
+// @Description Add a new question to content. Answer options are keyed A, B, C... in options, with the key of the correct one in answer_key; older clients may send possible_answers and true_answer texts instead, which are keyed in order. The response lists existing questions with the same or a very similar title and answer options so duplicates can be reviewed.
 // @Tags        questions
 // @Accept      json
 // @Produce     json
 // @Param       question body createQuestionRequest true "Question object to create"
-// @Success     201 {object} Response{data=QuestionResponse} "Question created successfully"
-// @Failure     400 {object} Response "Invalid request body"
+// @Success     201 {object} Response{data=createQuestionResponse} "Question created successfully"
+// @Failure     400 {object} Response "Invalid request body or answer options"
 // @Failure     500 {object} Response "Failed to create question"
 // @Security    ApiKeyAuth
 // @Router      /api/v1/questions [post]
@@ -77,6 +105,7 @@ func (server *Server) createQuestion(ctx *gin.Context) {
 		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
 		return
 	}
+	server.sanitizer.Struct(&req)
 
 	// Handle optional string fields
 	var mediaURL, imageURL, keywords sql.NullString
@@ -90,15 +119,37 @@ func (server *Server) createQuestion(ctx *gin.Context) {
 		keywords = sql.NullString{String: req.Keywords, Valid: true}
 	}
 
+	options, answerKey, err := choices.Change{
+		Options:    req.Options,
+		Texts:      req.PossibleAnswers,
+		AnswerKey:  req.AnswerKey,
+		TrueAnswer: req.TrueAnswer,
+	}.Apply(nil, "")
+	if err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid answer options", err)
+		return
+	}
+	answer, _ := choices.Find(options, answerKey)
+
 	arg := db.CreateQuestionParams{
 		ContentID:       req.ContentID,
 		Title:           req.Title,
 		MediaUrl:        mediaURL,
 		ImageUrl:        imageURL,
-		PossibleAnswers: req.PossibleAnswers,
-		TrueAnswer:      req.TrueAnswer,
+		PossibleAnswers: choices.Texts(options),
+		TrueAnswer:      answer.Text,
 		Explanation:     req.Explanation,
 		Keywords:        keywords,
+		Difficulty:      sql.NullInt16{Int16: req.Difficulty, Valid: req.Difficulty > 0},
+		Options:         choices.Encode(options),
+		AnswerKey:       answerKey,
+	}
+
+	// Look for duplicates before saving so the new question does not match itself
+	candidates, err := server.duplicates.Candidates(ctx, req.Title, arg.PossibleAnswers, 0)
+	if err != nil {
+		// A failed check should not stop authoring
+		logger.Warn("Failed to check question for duplicates: %v", err)
 	}
 
 	question, err := server.store.CreateQuestion(ctx, arg)
@@ -107,7 +158,13 @@ func (server *Server) createQuestion(ctx *gin.Context) {
 		return
 	}
 
-	SuccessResponse(ctx, http.StatusCreated, "Question created successfully", NewQuestionResponse(question))
+	if candidates == nil {
+		candidates = []duplicates.Candidate{}
+	}
+	SuccessResponse(ctx, http.StatusCreated, "Question created successfully", createQuestionResponse{
+		QuestionResponse: NewQuestionResponse(question),
+		Duplicates:       candidates,
+	})
 }
 
 // getQuestionRequest defines the structure for requests to get a question by ID
@@ -187,27 +244,59 @@ func (server *Server) listQuestionsByContent(ctx *gin.Context) {
 	SuccessResponse(ctx, http.StatusOK, "Questions retrieved successfully", questionResponses)
 }
 
-// updateQuestionRequest defines the structure for updating an existing question
+// updateQuestionRequest defines the structure for updating an existing question.
+// As on creation, PossibleAnswers and TrueAnswer are the texts older
+// clients send instead of Options and AnswerKey.
 type updateQuestionRequest struct {
-	ContentID       *int32   `json:"content_id,omitempty" binding:"omitempty,min=1"`
-	Title           *string  `json:"title,omitempty"`
-	MediaURL        *string  `json:"media_url,omitempty"`
-	ImageURL        *string  `json:"image_url,omitempty"`
-	PossibleAnswers []string `json:"possible_answers,omitempty" binding:"omitempty,min=1"`
-	TrueAnswer      *string  `json:"true_answer,omitempty"`
-	Explanation     *string  `json:"explanation,omitempty"`
-	Keywords        *string  `json:"keywords,omitempty"`
+	ContentID       *int32           `json:"content_id,omitempty" binding:"omitempty,min=1"`
+	Title           *string          `json:"title,omitempty" sanitize:"plain"`
+	MediaURL        *string          `json:"media_url,omitempty"`
+	ImageURL        *string          `json:"image_url,omitempty"`
+	Options         []choices.Option `json:"options,omitempty"`
+	AnswerKey       *string          `json:"answer_key,omitempty" example:"A"`
+	PossibleAnswers []string         `json:"possible_answers,omitempty" binding:"omitempty,min=1" sanitize:"plain"`
+	TrueAnswer      *string          `json:"true_answer,omitempty" sanitize:"plain"`
+	Explanation     *string          `json:"explanation,omitempty" sanitize:"markdown"`
+	Keywords        *string          `json:"keywords,omitempty"`
+	Difficulty      *int16           `json:"difficulty,omitempty" binding:"omitempty,difficulty_level"`
+}
+
+// updateQuestionResponse is the updated question, with the job re-grading
+// its past answers when the correct answer changed
+type updateQuestionResponse struct {
+	QuestionResponse
+	RegradeJob *jobs.Job `json:"regrade_job,omitempty"`
+}
+
+// queueRegrade starts a regrade_answers job for questions whose correct
+// answer changed, owned by userID. Failing to queue it does not fail the
+// edit; the job can be started again from POST /api/v1/jobs.
+func (server *Server) queueRegrade(ctx context.Context, userID int32, questionIDs []int32) *jobs.Job {
+	if len(questionIDs) == 0 {
+		return nil
+	}
+	params, err := json.Marshal(jobs.RegradeParams{QuestionIDs: questionIDs})
+	if err != nil {
+		logger.Warn("Failed to encode re-grade of questions %v: %v", questionIDs, err)
+		return nil
+	}
+	job, err := server.jobs.Create(ctx, userID, jobs.KindRegradeAnswers, params)
+	if err != nil {
+		logger.Warn("Failed to queue re-grade of questions %v: %v", questionIDs, err)
+		return nil
+	}
+	return job
 }
 
 // @Summary     Update a question
-// @Description Update an existing question by ID
+// @Description Update an existing question by ID. Options sent as possible_answers texts keep the keys of texts that were already options, so past answers keep pointing at the same options. When the correct option changes, a regrade_answers job is queued that re-grades past answers, rescores completed attempts and notifies their users; follow it with GET /api/v1/jobs/{id}.
 // @Tags        questions
 // @Accept      json
 // @Produce     json
 // @Param       id path int true "Question ID"
 // @Param       question body updateQuestionRequest true "Question fields to update"
-// @Success     200 {object} Response{data=QuestionResponse} "Question updated successfully"
-// @Failure     400 {object} Response "Invalid request body or question ID"
+// @Success     200 {object} Response{data=updateQuestionResponse} "Question updated successfully"
+// @Failure     400 {object} Response "Invalid request body, question ID or answer options"
 // @Failure     404 {object} Response "Question not found"
 // @Failure     500 {object} Response "Failed to update question"
 // @Security    ApiKeyAuth
@@ -224,6 +313,7 @@ func (server *Server) updateQuestion(ctx *gin.Context) {
 		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
 		return
 	}
+	server.sanitizer.Struct(&req)
 
 	// Get existing question to update only provided fields
 	existingQuestion, err := server.store.GetQuestion(ctx, int32(questionID))
@@ -238,15 +328,14 @@ func (server *Server) updateQuestion(ctx *gin.Context) {
 
 	// Prepare update parameters
 	arg := db.UpdateQuestionParams{
-		QuestionID:      int32(questionID),
-		ContentID:       existingQuestion.ContentID,
-		Title:           existingQuestion.Title,
-		MediaUrl:        existingQuestion.MediaUrl,
-		ImageUrl:        existingQuestion.ImageUrl,
-		PossibleAnswers: existingQuestion.PossibleAnswers,
-		TrueAnswer:      existingQuestion.TrueAnswer,
-		Explanation:     existingQuestion.Explanation,
-		Keywords:        existingQuestion.Keywords,
+		QuestionID:  int32(questionID),
+		ContentID:   existingQuestion.ContentID,
+		Title:       existingQuestion.Title,
+		MediaUrl:    existingQuestion.MediaUrl,
+		ImageUrl:    existingQuestion.ImageUrl,
+		Explanation: existingQuestion.Explanation,
+		Keywords:    existingQuestion.Keywords,
+		Difficulty:  existingQuestion.Difficulty,
 	}
 
 	// Update only provided fields
@@ -262,18 +351,39 @@ func (server *Server) updateQuestion(ctx *gin.Context) {
 	if req.ImageURL != nil {
 		arg.ImageUrl = sql.NullString{String: *req.ImageURL, Valid: true}
 	}
-	if len(req.PossibleAnswers) > 0 {
-		arg.PossibleAnswers = req.PossibleAnswers
-	}
-	if req.TrueAnswer != nil {
-		arg.TrueAnswer = *req.TrueAnswer
-	}
 	if req.Explanation != nil {
 		arg.Explanation = *req.Explanation
 	}
 	if req.Keywords != nil {
 		arg.Keywords = sql.NullString{String: *req.Keywords, Valid: true}
 	}
+	if req.Difficulty != nil {
+		arg.Difficulty = sql.NullInt16{Int16: *req.Difficulty, Valid: true}
+	}
+
+	// Options and the correct answer are kept by key; the legacy texts
+	// follow them
+	change := choices.Change{Texts: req.PossibleAnswers}
+	if len(req.Options) > 0 {
+		change.Options = req.Options
+	}
+	if req.AnswerKey != nil {
+		change.AnswerKey = *req.AnswerKey
+	}
+	if req.TrueAnswer != nil {
+		change.TrueAnswer = *req.TrueAnswer
+	}
+	previousKey := choices.AnswerOf(existingQuestion)
+	options, answerKey, err := change.Apply(choices.Of(existingQuestion), previousKey)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid answer options", err)
+		return
+	}
+	answer, _ := choices.Find(options, answerKey)
+	arg.Options = choices.Encode(options)
+	arg.AnswerKey = answerKey
+	arg.PossibleAnswers = choices.Texts(options)
+	arg.TrueAnswer = answer.Text
 
 	question, err := server.store.UpdateQuestion(ctx, arg)
 	if err != nil {
@@ -281,7 +391,13 @@ func (server *Server) updateQuestion(ctx *gin.Context) {
 		return
 	}
 
-	SuccessResponse(ctx, http.StatusOK, "Question updated successfully", NewQuestionResponse(question))
+	response := updateQuestionResponse{QuestionResponse: NewQuestionResponse(question)}
+	if question.AnswerKey != previousKey {
+		authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+		response.RegradeJob = server.queueRegrade(ctx, authPayload.ID, []int32{question.QuestionID})
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Question updated successfully", response)
 }
 
 // @Summary     Delete a question
@@ -319,6 +435,7 @@ func (server *Server) deleteQuestion(ctx *gin.Context) {
 // @Param       id path int true "Exam ID"
 // @Success     200 {object} Response{data=ExamQuestionsResponse} "Questions retrieved successfully"
 // @Failure     400 {object} Response "Invalid exam ID"
+// @Failure     402 {object} Response "Premium exam requires a subscription"
 // @Failure     404 {object} Response "Exam not found"
 // @Failure     500 {object} Response "Failed to retrieve questions"
 // @Security    ApiKeyAuth
@@ -340,6 +457,12 @@ func (server *Server) getExamQuestions(ctx *gin.Context) {
 		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve exam", err)
 		return
 	}
+	if exam.IsPremium {
+		authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+		if !server.requireEntitlement(ctx, authPayload.ID, billing.EntitlementPremiumExams) {
+			return
+		}
+	}
 
 	// Get all parts for this exam
 	parts, err := server.store.ListPartsByExam(ctx, int32(examID))
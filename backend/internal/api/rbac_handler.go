@@ -124,7 +124,7 @@ func toPermissionResponse(perm rbac.Permission) PermissionResponse {
 // @Failure     403 {object} Response "Insufficient permissions"
 // @Failure     500 {object} Response "Server error"
 // @Security    ApiKeyAuth
-// @Router      /api/v1/admin/roles [post]
+// @Router      /api/v1/rbac/roles [post]
 func (server *Server) createRole(ctx *gin.Context) {
 	var req CreateRoleRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
@@ -155,7 +155,7 @@ func (server *Server) createRole(ctx *gin.Context) {
 // @Failure     404 {object} Response "Role not found"
 // @Failure     500 {object} Response "Server error"
 // @Security    ApiKeyAuth
-// @Router      /api/v1/admin/roles/{id} [get]
+// @Router      /api/v1/rbac/roles/{id} [get]
 func (server *Server) getRole(ctx *gin.Context) {
 	roleID, err := strconv.ParseInt(ctx.Param("id"), 10, 32)
 	if err != nil {
@@ -187,7 +187,7 @@ func (server *Server) getRole(ctx *gin.Context) {
 // @Failure     403 {object} Response "Insufficient permissions"
 // @Failure     500 {object} Response "Server error"
 // @Security    ApiKeyAuth
-// @Router      /api/v1/admin/roles [get]
+// @Router      /api/v1/rbac/roles [get]
 func (server *Server) listRoles(ctx *gin.Context) {
 	roles, err := server.rbacService.ListRoles(ctx)
 	if err != nil {
@@ -217,7 +217,7 @@ func (server *Server) listRoles(ctx *gin.Context) {
 // @Failure     404 {object} Response "Role not found"
 // @Failure     500 {object} Response "Server error"
 // @Security    ApiKeyAuth
-// @Router      /api/v1/admin/roles/{id} [put]
+// @Router      /api/v1/rbac/roles/{id} [put]
 func (server *Server) updateRole(ctx *gin.Context) {
 	roleID, err := strconv.ParseInt(ctx.Param("id"), 10, 32)
 	if err != nil {
@@ -258,7 +258,7 @@ func (server *Server) updateRole(ctx *gin.Context) {
 // @Failure     404 {object} Response "Role not found"
 // @Failure     500 {object} Response "Server error"
 // @Security    ApiKeyAuth
-// @Router      /api/v1/admin/roles/{id} [delete]
+// @Router      /api/v1/rbac/roles/{id} [delete]
 func (server *Server) deleteRole(ctx *gin.Context) {
 	roleID, err := strconv.ParseInt(ctx.Param("id"), 10, 32)
 	if err != nil {
@@ -294,7 +294,7 @@ func (server *Server) deleteRole(ctx *gin.Context) {
 // @Failure     404 {object} Response "User or role not found"
 // @Failure     500 {object} Response "Server error"
 // @Security    ApiKeyAuth
-// @Router      /api/v1/admin/user-roles [post]
+// @Router      /api/v1/rbac/user-roles [post]
 func (server *Server) assignRole(ctx *gin.Context) {
 	var req AssignRoleRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
@@ -343,7 +343,7 @@ func (server *Server) assignRole(ctx *gin.Context) {
 // @Failure     403 {object} Response "Insufficient permissions"
 // @Failure     500 {object} Response "Server error"
 // @Security    ApiKeyAuth
-// @Router      /api/v1/admin/users/{user_id}/roles/{role_id} [delete]
+// @Router      /api/v1/rbac/user-roles/{user_id}/{role_id} [delete]
 func (server *Server) removeRole(ctx *gin.Context) {
 	userID, err := strconv.ParseInt(ctx.Param("user_id"), 10, 32)
 	if err != nil {
@@ -378,7 +378,7 @@ func (server *Server) removeRole(ctx *gin.Context) {
 // @Failure     404 {object} Response "User not found"
 // @Failure     500 {object} Response "Server error"
 // @Security    ApiKeyAuth
-// @Router      /api/v1/users/{user_id}/roles [get]
+// @Router      /api/v1/rbac/user-roles/user/{user_id} [get]
 func (server *Server) getUserRoles(ctx *gin.Context) {
 	userID, err := strconv.ParseInt(ctx.Param("user_id"), 10, 32)
 	if err != nil {
@@ -418,7 +418,7 @@ func (server *Server) getUserRoles(ctx *gin.Context) {
 // @Failure     404 {object} Response "User not found"
 // @Failure     500 {object} Response "Server error"
 // @Security    ApiKeyAuth
-// @Router      /api/v1/users/{user_id}/permissions [get]
+// @Router      /api/v1/rbac/permissions/user/{user_id} [get]
 func (server *Server) getUserPermissions(ctx *gin.Context) {
 	userID, err := strconv.ParseInt(ctx.Param("user_id"), 10, 32)
 	if err != nil {
@@ -460,7 +460,7 @@ func (server *Server) getUserPermissions(ctx *gin.Context) {
 // @Failure     403 {object} Response "Insufficient permissions"
 // @Failure     500 {object} Response "Server error"
 // @Security    ApiKeyAuth
-// @Router      /api/v1/admin/check-permission [post]
+// @Router      /api/v1/rbac/permissions/check [post]
 func (server *Server) checkPermission(ctx *gin.Context) {
 	var req CheckPermissionRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
@@ -499,35 +499,24 @@ func (server *Server) checkPermission(ctx *gin.Context) {
 // @Failure     404 {object} Response "Role not found"
 // @Failure     500 {object} Response "Server error"
 // @Security    ApiKeyAuth
-// @Router      /api/v1/admin/roles/users/{roleName} [get]
+// @Router      /api/v1/rbac/user-roles/role/{roleName} [get]
 func (server *Server) getUsersByRole(ctx *gin.Context) {
 	roleName := ctx.Param("roleName")
 	if roleName == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"status":  "error",
-			"message": "Role name is required",
-			"code":    "RBAC_ROLE_NAME_REQUIRED",
-		})
+		ErrorResponse(ctx, http.StatusBadRequest, "Role name is required", nil)
 		return
 	}
 
 	users, err := server.rbacService.GetUsersByRole(ctx, roleName)
 	if err != nil {
 		logger.Error("Failed to get users by role %s: %v", roleName, err)
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"status":  "error",
-			"message": "Failed to retrieve users by role",
-			"code":    "RBAC_GET_USERS_BY_ROLE_FAILED",
-		})
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve users by role", err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{
-		"status": "success",
-		"data": gin.H{
-			"role":  roleName,
-			"users": users,
-		},
+	SuccessResponse(ctx, http.StatusOK, "Users retrieved successfully", gin.H{
+		"role":  roleName,
+		"users": users,
 	})
 }
 
@@ -541,16 +530,12 @@ func (server *Server) getUsersByRole(ctx *gin.Context) {
 // @Failure     403 {object} Response "Insufficient permissions"
 // @Failure     500 {object} Response "Server error"
 // @Security    ApiKeyAuth
-// @Router      /api/v1/admin/permissions [get]
+// @Router      /api/v1/rbac/permissions [get]
 func (server *Server) listPermissions(ctx *gin.Context) {
 	permissions, err := server.rbacService.GetAllPermissions(ctx)
 	if err != nil {
 		logger.Error("Failed to list permissions: %v", err)
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"status":  "error",
-			"message": "Failed to retrieve permissions",
-			"code":    "RBAC_LIST_PERMISSIONS_FAILED",
-		})
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve permissions", err)
 		return
 	}
 
@@ -559,12 +544,9 @@ func (server *Server) listPermissions(ctx *gin.Context) {
 		permissionResponses = append(permissionResponses, toPermissionResponse(perm))
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{
-		"status": "success",
-		"data": gin.H{
-			"permissions": permissionResponses,
-			"total":       len(permissionResponses),
-		},
+	SuccessResponse(ctx, http.StatusOK, "Permissions retrieved successfully", gin.H{
+		"permissions": permissionResponses,
+		"total":       len(permissionResponses),
 	})
 }
 
@@ -574,15 +556,15 @@ func (server *Server) listPermissions(ctx *gin.Context) {
 // @Tags        rbac
 // @Accept      json
 // @Produce     json
-// @Param       role_id       path int32  true  "Role ID"
-// @Param       permission_id  path int32  true  "Permission ID"
+// @Param       id path int32 true "Role ID"
+// @Param       assignment body object{role_id=int32,permission_id=int32} true "Role and permission"
 // @Success     200 {object} Response "Permission assigned successfully"
 // @Failure     400 {object} Response "Invalid request"
 // @Failure     403 {object} Response "Insufficient permissions"
 // @Failure     404 {object} Response "Role or permission not found"
 // @Failure     500 {object} Response "Server error"
 // @Security    ApiKeyAuth
-// @Router      /api/v1/admin/roles/{role_id}/permissions/{permission_id} [post]
+// @Router      /api/v1/rbac/roles/{id}/permissions [post]
 func (server *Server) assignPermissionToRole(ctx *gin.Context) {
 	type AssignPermissionRequest struct {
 		RoleID       int32 `json:"role_id" binding:"required"`
@@ -591,56 +573,35 @@ func (server *Server) assignPermissionToRole(ctx *gin.Context) {
 
 	var req AssignPermissionRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"status":  "error",
-			"message": "Invalid request format",
-			"code":    "RBAC_INVALID_REQUEST",
-			"details": err.Error(),
-		})
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request format", err)
 		return
 	}
 
 	// Get current user for audit trail
 	payload, exists := ctx.Get(AuthorizationPayloadKey)
 	if !exists {
-		ctx.JSON(http.StatusUnauthorized, gin.H{
-			"status":  "error",
-			"message": "Authentication required",
-			"code":    "RBAC_AUTH_REQUIRED",
-		})
+		ErrorResponse(ctx, http.StatusUnauthorized, "Authentication required", nil)
 		return
 	}
 
 	authPayload, ok := payload.(*token.Payload)
 	if !ok {
-		ctx.JSON(http.StatusUnauthorized, gin.H{
-			"status":  "error",
-			"message": "Invalid authentication token",
-			"code":    "RBAC_INVALID_TOKEN",
-		})
+		ErrorResponse(ctx, http.StatusUnauthorized, "Invalid authentication token", nil)
 		return
 	}
 
 	err := server.rbacService.AssignPermissionToRole(ctx, req.RoleID, req.PermissionID, authPayload.ID)
 	if err != nil {
 		logger.Error("Failed to assign permission %d to role %d: %v", req.PermissionID, req.RoleID, err)
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"status":  "error",
-			"message": "Failed to assign permission to role",
-			"code":    "RBAC_ASSIGN_PERMISSION_FAILED",
-		})
-		return
-	}
-
-	ctx.JSON(http.StatusOK, gin.H{
-		"status":  "success",
-		"message": "Permission assigned to role successfully",
-		"data": gin.H{
-			"role_id":       req.RoleID,
-			"permission_id": req.PermissionID,
-			"assigned_by":   authPayload.ID,
-			"assigned_at":   time.Now().Format(time.RFC3339),
-		},
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to assign permission to role", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Permission assigned to role successfully", gin.H{
+		"role_id":       req.RoleID,
+		"permission_id": req.PermissionID,
+		"assigned_by":   authPayload.ID,
+		"assigned_at":   time.Now().Format(time.RFC3339),
 	})
 }
 
@@ -650,15 +611,16 @@ func (server *Server) assignPermissionToRole(ctx *gin.Context) {
 // @Tags        rbac
 // @Accept      json
 // @Produce     json
-// @Param       role_id       path int32  true  "Role ID"
-// @Param       permission_id  path int32  true  "Permission ID"
+// @Param       id path int32 true "Role ID"
+// @Param       permission_id path int32 true "Permission ID"
+// @Param       removal body object{role_id=int32,permission_id=int32} true "Role and permission"
 // @Success     200 {object} Response "Permission removed successfully"
 // @Failure     400 {object} Response "Invalid request"
 // @Failure     403 {object} Response "Insufficient permissions"
 // @Failure     404 {object} Response "Role or permission not found"
 // @Failure     500 {object} Response "Server error"
 // @Security    ApiKeyAuth
-// @Router      /api/v1/admin/roles/{role_id}/permissions/{permission_id} [delete]
+// @Router      /api/v1/rbac/roles/{id}/permissions/{permission_id} [delete]
 func (server *Server) removePermissionFromRole(ctx *gin.Context) {
 	type RemovePermissionRequest struct {
 		RoleID       int32 `json:"role_id" binding:"required"`
@@ -667,34 +629,21 @@ func (server *Server) removePermissionFromRole(ctx *gin.Context) {
 
 	var req RemovePermissionRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"status":  "error",
-			"message": "Invalid request format",
-			"code":    "RBAC_INVALID_REQUEST",
-			"details": err.Error(),
-		})
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request format", err)
 		return
 	}
 
 	err := server.rbacService.RemovePermissionFromRole(ctx, req.RoleID, req.PermissionID)
 	if err != nil {
 		logger.Error("Failed to remove permission %d from role %d: %v", req.PermissionID, req.RoleID, err)
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"status":  "error",
-			"message": "Failed to remove permission from role",
-			"code":    "RBAC_REMOVE_PERMISSION_FAILED",
-		})
-		return
-	}
-
-	ctx.JSON(http.StatusOK, gin.H{
-		"status":  "success",
-		"message": "Permission removed from role successfully",
-		"data": gin.H{
-			"role_id":       req.RoleID,
-			"permission_id": req.PermissionID,
-			"removed_at":    time.Now().Format(time.RFC3339),
-		},
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to remove permission from role", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Permission removed from role successfully", gin.H{
+		"role_id":       req.RoleID,
+		"permission_id": req.PermissionID,
+		"removed_at":    time.Now().Format(time.RFC3339),
 	})
 }
 
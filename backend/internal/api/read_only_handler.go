@@ -0,0 +1,144 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/logger"
+	"github.com/toeic-app/internal/readonly"
+	"github.com/toeic-app/internal/token"
+)
+
+// readOnlyExemptRoutes accept writes while read-only: the switch itself, the
+// restores that switch it on and cancelling them, and signing in and out,
+// so sessions outlive the maintenance and admins can switch it off. The
+// auth handlers skip their own writes while read-only.
+var readOnlyExemptRoutes = map[string]bool{
+	"/api/v1/admin/system/read-only":            true,
+	"/api/v1/admin/backups/restore":             true,
+	"/api/v1/admin/backups/enhanced/restore":    true,
+	"/api/v1/admin/backups/restores/:id/cancel": true,
+	"/api/auth/login":                           true,
+	"/api/auth/refresh-token":                   true,
+	"/api/auth/logout":                          true,
+	"/api/auth/oauth/:provider":                 true,
+}
+
+type enableReadOnlyRequest struct {
+	// Why writes are refused, shown to admins checking the status
+	Reason string `json:"reason" binding:"omitempty,max=200" example:"Restoring last night's backup"`
+}
+
+// rejectWritesWhileReadOnly refuses every write while the API is read-only;
+// reads keep being served
+func (server *Server) rejectWritesWhileReadOnly() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		switch ctx.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			ctx.Next()
+			return
+		}
+		if readOnlyExemptRoutes[ctx.FullPath()] || !server.isReadOnly(ctx) {
+			ctx.Next()
+			return
+		}
+		server.readOnlyResponse(ctx)
+		ctx.Abort()
+	}
+}
+
+// isReadOnly reports whether the API refuses writes
+func (server *Server) isReadOnly(ctx *gin.Context) bool {
+	return server.readOnly.Status(ctx.Request.Context()).Enabled
+}
+
+// readOnlyResponse refuses a request that has to write while read-only
+func (server *Server) readOnlyResponse(ctx *gin.Context) {
+	ctx.Header("Retry-After", strconv.Itoa(int(server.config.ReadOnlyRetryAfter.Seconds())))
+	ErrorResponse(ctx, http.StatusServiceUnavailable, "The service is read-only during database maintenance, please retry later", nil)
+}
+
+// enterReadOnly keeps the API read-only until the returned function is
+// called, so nothing is written to the database while it is restored. On
+// failure the error response is sent and ok is false.
+func (server *Server) enterReadOnly(ctx *gin.Context, reason string) (release func(), ok bool) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+	release, err := server.readOnly.Acquire(ctx.Request.Context(), reason, authPayload.Username)
+	if err != nil {
+		logger.Error("Failed to switch to read-only mode for %s: %v", reason, err)
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to switch to read-only mode", err)
+		return nil, false
+	}
+	return release, true
+}
+
+// @Summary     Get read-only mode
+// @Description Returns whether the API refuses writes, why and since when. static is set while READ_ONLY_MODE forces it, in which case it can only be switched off in the configuration. Requires the system.manage permission. (admin only)
+// @Tags        system
+// @Produce     json
+// @Success     200 {object} Response{data=readonly.State} "Read-only mode retrieved successfully"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     403 {object} Response "Permission denied"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/system/read-only [get]
+func (server *Server) getReadOnlyMode(ctx *gin.Context) {
+	SuccessResponse(ctx, http.StatusOK, "Read-only mode retrieved successfully", server.readOnly.Status(ctx.Request.Context()))
+}
+
+// @Summary     Switch on read-only mode
+// @Description Makes every instance refuse writes with 503 and a Retry-After header while reads keep being served, for database restores and migrations. Instances pick the switch up within READ_ONLY_REFRESH_INTERVAL. Backup restores switch it on by themselves. Requires the system.manage permission. (admin only)
+// @Tags        system
+// @Accept      json
+// @Produce     json
+// @Param       read_only body enableReadOnlyRequest false "Reason for the maintenance"
+// @Success     200 {object} Response{data=readonly.State} "Read-only mode switched on"
+// @Failure     400 {object} Response "Invalid request parameters"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     403 {object} Response "Permission denied"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/system/read-only [post]
+func (server *Server) enableReadOnlyMode(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	var req enableReadOnlyRequest
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ErrorResponse(ctx, http.StatusBadRequest, "Invalid request parameters", err)
+			return
+		}
+	}
+
+	state, err := server.readOnly.Enable(ctx.Request.Context(), req.Reason, authPayload.Username)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to switch on read-only mode", err)
+		return
+	}
+	logger.Warn("Read-only mode switched on by %s: %s", authPayload.Username, req.Reason)
+	SuccessResponse(ctx, http.StatusOK, "Read-only mode switched on", state)
+}
+
+// @Summary     Switch off read-only mode
+// @Description Lets every instance accept writes again. Requires the system.manage permission. (admin only)
+// @Tags        system
+// @Produce     json
+// @Success     200 {object} Response{data=readonly.State} "Read-only mode switched off"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     403 {object} Response "Permission denied"
+// @Failure     409 {object} Response "Read-only mode is forced by READ_ONLY_MODE"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/system/read-only [delete]
+func (server *Server) disableReadOnlyMode(ctx *gin.Context) {
+	state, err := server.readOnly.Disable(ctx.Request.Context())
+	if err != nil {
+		if errors.Is(err, readonly.ErrStatic) {
+			ErrorResponse(ctx, http.StatusConflict, "Read-only mode is forced by READ_ONLY_MODE", err)
+			return
+		}
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to switch off read-only mode", err)
+		return
+	}
+	logger.Info("Read-only mode switched off, accepting writes again")
+	SuccessResponse(ctx, http.StatusOK, "Read-only mode switched off", state)
+}
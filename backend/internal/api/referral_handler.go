@@ -0,0 +1,64 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/token"
+)
+
+// referralReportRequest selects the period of the referral report
+type referralReportRequest struct {
+	Days int32 `form:"days,default=30" binding:"min=1,max=365"`
+	Top  int32 `form:"top,default=10" binding:"min=1,max=100"`
+}
+
+// @Summary     Get my referral code
+// @Description Returns the referral code of the current user, created on first request, with the number of sign-ups and conversions it brought and the premium days earned
+// @Tags        users
+// @Produce     json
+// @Success     200 {object} Response{data=referral.Summary} "Referral code retrieved successfully"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     500 {object} Response "Failed to retrieve referral code"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/users/me/referral [get]
+func (server *Server) getMyReferral(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	summary, err := server.referrals.Summary(ctx, authPayload.ID)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve referral code", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Referral code retrieved successfully", summary)
+}
+
+// @Summary     Referral conversion report
+// @Description Counts referrals created in the last days by status, the conversion rate, the reasons referrals were rejected as self-referrals and the top referrers (admin only)
+// @Tags        admin
+// @Produce     json
+// @Param       days query int false "Period in days (max 365)" default(30)
+// @Param       top query int false "Number of top referrers (max 100)" default(10)
+// @Success     200 {object} Response{data=referral.Report} "Referral report retrieved successfully"
+// @Failure     400 {object} Response "Invalid query parameters"
+// @Failure     403 {object} Response "Insufficient permissions"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/referrals/report [get]
+func (server *Server) getReferralReport(ctx *gin.Context) {
+	var req referralReportRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	since := time.Now().AddDate(0, 0, -int(req.Days))
+	report, err := server.referrals.Report(ctx, since, req.Top)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve referral report", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Referral report retrieved successfully", report)
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/logger"
+	"github.com/toeic-app/internal/related"
+)
+
+// relatedContent returns the related block of a detail response. Related
+// content is a suggestion, so failing to load it never fails the response.
+func (server *Server) relatedContent(ctx *gin.Context, contentType string, id int32) []related.Item {
+	items, err := server.related.For(ctx, contentType, id)
+	if err != nil {
+		logger.Warn("Failed to load related content of %s %d: %v", contentType, id, err)
+		return nil
+	}
+	return items
+}
+
+// @Summary     Get related content
+// @Description Lists words, grammar, example sentences and questions related to a piece of content, grouped by type and best first within each type. Links are precomputed from tags and co-occurrence; reason is one of definition, curated, tag, co-occurrence or mention.
+// @Tags        related
+// @Produce     json
+// @Param       type path string true "word, grammar, example or question"
+// @Param       id path int true "Content ID"
+// @Success     200 {object} Response{data=[]related.Item} "Related content retrieved successfully"
+// @Failure     400 {object} Response "Invalid content type or ID"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/related/{type}/{id} [get]
+func (server *Server) getRelatedContent(ctx *gin.Context) {
+	contentType := ctx.Param("type")
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 32)
+	if err != nil || id <= 0 {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid content ID", err)
+		return
+	}
+
+	items, err := server.related.For(ctx, contentType, int32(id))
+	if err != nil {
+		if errors.Is(err, related.ErrUnknownType) {
+			ErrorResponse(ctx, http.StatusBadRequest, "Content type must be word, grammar, example or question", err)
+			return
+		}
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to get related content", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Related content retrieved successfully", items)
+}
+
+// @Summary     Related content status
+// @Description Reports how many related content links are stored and when they were last computed (admin only)
+// @Tags        admin
+// @Produce     json
+// @Success     200 {object} Response{data=related.Summary} "Related content status retrieved successfully"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/related-content [get]
+func (server *Server) getRelatedContentStatus(ctx *gin.Context) {
+	summary, err := server.related.Summary(ctx)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to get related content status", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Related content status retrieved successfully", summary)
+}
+
+// @Summary     Rebuild related content
+// @Description Recomputes every related content link now instead of waiting for the next scheduled rebuild (admin only)
+// @Tags        admin
+// @Produce     json
+// @Success     200 {object} Response{data=related.Stats} "Related content rebuilt successfully"
+// @Failure     409 {object} Response "A rebuild is already running"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/related-content/rebuild [post]
+func (server *Server) rebuildRelatedContent(ctx *gin.Context) {
+	stats, err := server.related.Rebuild(ctx)
+	if err != nil {
+		if errors.Is(err, related.ErrRebuildInProgress) {
+			ErrorResponse(ctx, http.StatusConflict, err.Error(), err)
+			return
+		}
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to rebuild related content", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Related content rebuilt successfully", stats)
+}
@@ -2,19 +2,88 @@ package api
 
 import (
 	"fmt"
+	"math"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/toeic-app/internal/i18n"
 	"github.com/toeic-app/internal/logger"
+	"github.com/toeic-app/internal/middleware"
 )
 
-// Response is a standardized API response structure
+// paginationKey holds the page a list handler is answering with
+const paginationKey = "response_pagination"
+
+// Response is a standardized API response structure. Every handler writes it
+// through SuccessResponse, PaginatedResponse or ErrorResponse.
 type Response struct {
-	Status   string `json:"status"`
-	Message  string `json:"message,omitempty"`
-	Data     any    `json:"data,omitempty"`
-	Error    string `json:"error,omitempty"`
-	Language string `json:"language,omitempty"`
+	Status   string        `json:"status" enums:"success,error" example:"success"`
+	Message  string        `json:"message,omitempty" example:"Retrieved successfully"`
+	Data     any           `json:"data,omitempty"`
+	Error    *ErrorDetails `json:"error,omitempty"`
+	Language string        `json:"language,omitempty" enums:"en,vi" example:"en"`
+	// Timestamp and TraceID are set on error responses
+	Timestamp string `json:"timestamp,omitempty" format:"date-time" example:"2026-05-01T13:45:00Z"`
+	TraceID   string `json:"trace_id,omitempty" example:"4bf92f3577b34da6"`
+	Meta      *Meta  `json:"meta,omitempty"`
+}
+
+// Meta describes the request a response answers
+type Meta struct {
+	RequestID string `json:"request_id,omitempty" example:"0b6f4c1e-8a2d-4f7b-9c3e-5d1a2b3c4d5e"`
+	// ElapsedMS is the time spent on the request until the response was written
+	ElapsedMS  float64     `json:"elapsed_ms,omitempty" example:"12.34"`
+	Pagination *Pagination `json:"pagination,omitempty"`
+}
+
+// Pagination describes the page returned by a list endpoint
+type Pagination struct {
+	Limit  int32 `json:"limit" example:"20"`
+	Offset int32 `json:"offset" example:"40"`
+	// Count is the number of items on this page
+	Count int `json:"count" example:"20"`
+	// Total is the number of items on all pages, when known
+	Total   *int64 `json:"total,omitempty" example:"137"`
+	HasMore bool   `json:"has_more" example:"true"`
+}
+
+// NewPagination describes a page of count items read with limit and offset.
+// Without a total, a full page is assumed to have more after it.
+func NewPagination(limit, offset int32, count int) *Pagination {
+	return &Pagination{Limit: limit, Offset: offset, Count: count, HasMore: limit > 0 && count >= int(limit)}
+}
+
+// WithTotal sets the total number of items and derives HasMore from it
+func (p *Pagination) WithTotal(total int64) *Pagination {
+	p.Total = &total
+	p.HasMore = int64(p.Offset)+int64(p.Count) < total
+	return p
+}
+
+// requestID returns the ID of the request, set by middleware.RequestIDMiddleware
+func requestID(c *gin.Context) string {
+	if id := c.GetString(middleware.RequestIDKey); id != "" {
+		return id
+	}
+	return c.GetHeader("X-Request-ID")
+}
+
+// responseMeta builds the meta block of a response
+func responseMeta(c *gin.Context) *Meta {
+	meta := &Meta{RequestID: requestID(c)}
+	if started := c.GetTime(middleware.RequestStartedKey); !started.IsZero() {
+		meta.ElapsedMS = math.Round(float64(time.Since(started).Microseconds())/10) / 100
+	}
+	if page, ok := c.Get(paginationKey); ok {
+		meta.Pagination, _ = page.(*Pagination)
+	}
+	return meta
+}
+
+// PaginatedResponse returns a standard success response for a page of a list
+func PaginatedResponse(c *gin.Context, statusCode int, messageKey string, data any, page *Pagination) {
+	c.Set(paginationKey, page)
+	SuccessResponse(c, statusCode, messageKey, data)
 }
 
 // SuccessResponse returns a standard success response with i18n support
@@ -31,6 +100,7 @@ func SuccessResponse(c *gin.Context, statusCode int, messageKey string, data int
 		Message:  translatedMessage,
 		Data:     data,
 		Language: string(lang),
+		Meta:     responseMeta(c),
 	}
 
 	// Create structured log fields
@@ -44,7 +114,7 @@ func SuccessResponse(c *gin.Context, statusCode int, messageKey string, data int
 		"language":           string(lang),
 		"client_ip":          c.ClientIP(),
 		"user_agent":         c.GetHeader("User-Agent"),
-		"request_id":         c.GetHeader("X-Request-ID"),
+		"request_id":         requestID(c),
 		"has_data":           data != nil,
 	}
 
@@ -73,6 +143,7 @@ func SuccessResponseWithMessage(c *gin.Context, statusCode int, message string,
 		Message:  message,
 		Data:     data,
 		Language: string(lang),
+		Meta:     responseMeta(c),
 	}
 
 	// Create structured log fields
@@ -85,7 +156,7 @@ func SuccessResponseWithMessage(c *gin.Context, statusCode int, message string,
 		"language":       string(lang),
 		"client_ip":      c.ClientIP(),
 		"user_agent":     c.GetHeader("User-Agent"),
-		"request_id":     c.GetHeader("X-Request-ID"),
+		"request_id":     requestID(c),
 		"has_data":       data != nil,
 	}
 
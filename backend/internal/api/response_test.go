@@ -0,0 +1,137 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toeic-app/internal/i18n"
+	"github.com/toeic-app/internal/middleware"
+)
+
+func serveEnvelope(t *testing.T, handler gin.HandlerFunc, requestID string) map[string]any {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.RequestIDMiddleware())
+	router.GET("/test", handler)
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	if requestID != "" {
+		req.Header.Set("X-Request-ID", requestID)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	return body
+}
+
+func TestSuccessResponseMeta(t *testing.T) {
+	body := serveEnvelope(t, func(c *gin.Context) {
+		time.Sleep(2 * time.Millisecond)
+		SuccessResponse(c, http.StatusOK, "Words retrieved successfully", []string{"invoice"})
+	}, "req-123")
+
+	assert.Equal(t, "success", body["status"])
+	assert.Equal(t, []any{"invoice"}, body["data"])
+	meta := body["meta"].(map[string]any)
+	assert.Equal(t, "req-123", meta["request_id"])
+	assert.GreaterOrEqual(t, meta["elapsed_ms"], 2.0)
+	assert.NotContains(t, meta, "pagination")
+}
+
+func TestPaginatedResponseMeta(t *testing.T) {
+	body := serveEnvelope(t, func(c *gin.Context) {
+		PaginatedResponse(c, http.StatusOK, "Words retrieved successfully", []int{1, 2}, NewPagination(2, 10, 2))
+	}, "")
+
+	meta := body["meta"].(map[string]any)
+	assert.NotEmpty(t, meta["request_id"], "an ID is generated when the client sends none")
+	assert.Equal(t, map[string]any{"limit": 2.0, "offset": 10.0, "count": 2.0, "has_more": true}, meta["pagination"])
+
+	page := NewPagination(20, 40, 2).WithTotal(42)
+	assert.False(t, page.HasMore)
+	assert.Equal(t, int64(42), *page.Total)
+}
+
+func TestErrorResponseMeta(t *testing.T) {
+	body := serveEnvelope(t, func(c *gin.Context) {
+		ErrorResponse(c, http.StatusBadRequest, "Invalid query parameters", errors.New("limit must be positive"))
+	}, "req-456")
+
+	assert.Equal(t, "error", body["status"])
+	assert.Equal(t, "req-456", body["meta"].(map[string]any)["request_id"])
+}
+
+func TestErrorResponseNamesInvalidFields(t *testing.T) {
+	middleware.RegisterValidators()
+	body := serveEnvelope(t, func(c *gin.Context) {
+		i18n.SetLanguageInContext(c, i18n.LanguageVietnamese)
+		var req struct {
+			Score string `json:"score" binding:"required,toeic_score"`
+		}
+		c.Request.Body = io.NopCloser(strings.NewReader(`{"score":"1200"}`))
+		err := c.ShouldBindJSON(&req)
+		ErrorResponse(c, http.StatusBadRequest, "Invalid request body", err)
+	}, "")
+
+	details := body["error"].(map[string]any)
+	assert.Equal(t, "Invalid request body", details["message"])
+	assert.Equal(t, map[string]any{"score": "score phải là điểm TOEIC từ 0 đến 990"}, details["fields"])
+}
+
+// rawJSONAllowed lists the handlers that must not use the envelope because
+// their format is fixed by a standard
+var rawJSONAllowed = map[string]bool{
+	"lti_handler.go:ltiJWKS": true, // RFC 7517 key set fetched by LTI platforms
+}
+
+// TestHandlersUseEnvelope keeps handlers from writing JSON bodies that bypass
+// SuccessResponse, PaginatedResponse and ErrorResponse
+func TestHandlersUseEnvelope(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	require.NoError(t, err)
+
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") || file == "response.go" || file == "enhanced_response.go" {
+			continue
+		}
+		parsed, err := parser.ParseFile(fset, file, nil, 0)
+		require.NoError(t, err)
+
+		for _, decl := range parsed.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil || rawJSONAllowed[file+":"+fn.Name.Name] {
+				continue
+			}
+			ast.Inspect(fn.Body, func(node ast.Node) bool {
+				call, ok := node.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				if sel, ok := call.Fun.(*ast.SelectorExpr); ok {
+					switch sel.Sel.Name {
+					case "JSON", "IndentedJSON", "PureJSON", "AbortWithStatusJSON":
+						t.Errorf("%s: %s writes a raw JSON body; use SuccessResponse or ErrorResponse",
+							fset.Position(call.Pos()), fn.Name.Name)
+					}
+				}
+				return true
+			})
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/retention"
+	"github.com/toeic-app/internal/token"
+)
+
+// @Summary     Get data retention settings
+// @Description Lists the default retention period of each data category, the periods organizations (LTI platforms) chose for their users, and the report of the latest purge on this instance. A period of 0 days keeps data forever. (admin only)
+// @Tags        admin
+// @Produce     json
+// @Success     200 {object} Response{data=retention.Settings} "Retention settings retrieved successfully"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/retention [get]
+func (server *Server) getRetentionSettings(ctx *gin.Context) {
+	settings, err := server.retention.Settings(ctx)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to get retention settings", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Retention settings retrieved successfully", settings)
+}
+
+type setRetentionOverrideRequest struct {
+	Category      string `json:"category" binding:"required"`
+	RetentionDays *int32 `json:"retention_days" binding:"required,min=0"`
+}
+
+// @Summary     Set organization retention
+// @Description Sets how long the speaking_audio or ai_feedback data of an organization's users is kept, replacing the default period. Users of several organizations keep their data for the longest of their periods; 0 days keeps it forever. (admin only)
+// @Tags        admin
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Organization (LTI platform) ID"
+// @Param       request body setRetentionOverrideRequest true "Category and period"
+// @Success     200 {object} Response{data=retention.Override} "Retention override saved successfully"
+// @Failure     400 {object} Response "Invalid request or category"
+// @Failure     404 {object} Response "Organization not found"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/retention/organizations/{id} [put]
+func (server *Server) setRetentionOverride(ctx *gin.Context) {
+	id, ok := parseLTIID(ctx)
+	if !ok {
+		return
+	}
+	var req setRetentionOverrideRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	override, err := server.retention.SetOverride(ctx, id, req.Category, *req.RetentionDays, authPayload.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, retention.ErrUnknownCategory), errors.Is(err, retention.ErrNotOverridable),
+			errors.Is(err, retention.ErrInvalidRetention):
+			ErrorResponse(ctx, http.StatusBadRequest, "Invalid category", err)
+		case errors.Is(err, retention.ErrUnknownOrganization):
+			ErrorResponse(ctx, http.StatusNotFound, "Organization not found", err)
+		default:
+			ErrorResponse(ctx, http.StatusInternalServerError, "Failed to save retention override", err)
+		}
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Retention override saved successfully", override)
+}
+
+// @Summary     Remove organization retention
+// @Description Makes an organization's users use the default retention period of a category again (admin only)
+// @Tags        admin
+// @Produce     json
+// @Param       id path int true "Organization (LTI platform) ID"
+// @Param       category path string true "speaking_audio or ai_feedback"
+// @Success     200 {object} Response "Retention override removed successfully"
+// @Failure     400 {object} Response "Invalid category"
+// @Failure     404 {object} Response "Retention override not found"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/retention/organizations/{id}/{category} [delete]
+func (server *Server) removeRetentionOverride(ctx *gin.Context) {
+	id, ok := parseLTIID(ctx)
+	if !ok {
+		return
+	}
+
+	removed, err := server.retention.RemoveOverride(ctx, id, ctx.Param("category"))
+	if err != nil {
+		if errors.Is(err, retention.ErrUnknownCategory) || errors.Is(err, retention.ErrNotOverridable) {
+			ErrorResponse(ctx, http.StatusBadRequest, "Invalid category", err)
+			return
+		}
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to remove retention override", err)
+		return
+	}
+	if !removed {
+		ErrorResponse(ctx, http.StatusNotFound, "Retention override not found", nil)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Retention override removed successfully", nil)
+}
+
+// @Summary     Preview retention purge
+// @Description Reports how many recordings, AI feedback entries and log files a purge would remove now, without removing anything (admin only)
+// @Tags        admin
+// @Produce     json
+// @Success     200 {object} Response{data=retention.Report} "Retention report generated successfully"
+// @Failure     409 {object} Response "A purge is already in progress"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/retention/report [get]
+func (server *Server) getRetentionReport(ctx *gin.Context) {
+	server.runRetention(ctx, true, "Retention report generated successfully")
+}
+
+// @Summary     Purge expired data
+// @Description Removes expired recordings, AI feedback and this instance's old log files now instead of waiting for the next scheduled purge. Scores are kept. Failed deletions are reported and retried by the next purge. (admin only)
+// @Tags        admin
+// @Produce     json
+// @Success     200 {object} Response{data=retention.Report} "Expired data purged successfully"
+// @Failure     409 {object} Response "A purge is already in progress"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/retention/purge [post]
+func (server *Server) purgeExpiredData(ctx *gin.Context) {
+	server.runRetention(ctx, false, "Expired data purged successfully")
+}
+
+func (server *Server) runRetention(ctx *gin.Context, dryRun bool, message string) {
+	report, err := server.retention.Run(ctx, dryRun)
+	if err != nil {
+		if errors.Is(err, retention.ErrPurgeInProgress) {
+			ErrorResponse(ctx, http.StatusConflict, "A purge is already in progress", err)
+			return
+		}
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to run retention policy", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, message, report)
+}
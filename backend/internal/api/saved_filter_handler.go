@@ -0,0 +1,206 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/savedfilter"
+	"github.com/toeic-app/internal/token"
+)
+
+// listFilterQuery holds the filter parameters of the words, exams and study
+// set lists: a saved filter and fields overriding it
+type listFilterQuery struct {
+	FilterID int32 `form:"filter_id" binding:"omitempty,min=1"`
+	savedfilter.Criteria
+}
+
+// savedFilterError writes the response of a failed saved filter operation
+func savedFilterError(ctx *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, savedfilter.ErrNotFound):
+		ErrorResponse(ctx, http.StatusNotFound, "Saved filter not found", err)
+	case errors.Is(err, savedfilter.ErrDuplicateName):
+		ErrorResponse(ctx, http.StatusConflict, err.Error(), err)
+	case errors.Is(err, savedfilter.ErrLimitReached):
+		ErrorResponse(ctx, http.StatusUnprocessableEntity, err.Error(), err)
+	case errors.Is(err, savedfilter.ErrInvalidResource), errors.Is(err, savedfilter.ErrInvalidCriteria),
+		errors.Is(err, savedfilter.ErrInvalidName), errors.Is(err, savedfilter.ErrWrongResource):
+		ErrorResponse(ctx, http.StatusBadRequest, err.Error(), err)
+	default:
+		ErrorResponse(ctx, http.StatusInternalServerError, message, err)
+	}
+}
+
+// listFilter returns the criteria a list of resource is filtered with. It
+// writes the error response and returns false when they are invalid.
+func (server *Server) listFilter(ctx *gin.Context, resource string, query listFilterQuery) (savedfilter.Criteria, bool) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+	criteria, err := server.savedFilters.Resolve(ctx, authPayload.ID, query.FilterID, resource, query.Criteria)
+	if err != nil {
+		savedFilterError(ctx, err, "Failed to apply saved filter")
+		return savedfilter.Criteria{}, false
+	}
+	return criteria, true
+}
+
+type savedFilterURI struct {
+	ID int32 `uri:"id" binding:"required,min=1"`
+}
+
+type listSavedFiltersRequest struct {
+	Resource string `form:"resource"`
+}
+
+type createSavedFilterRequest struct {
+	Resource string               `json:"resource" binding:"required" example:"words"`
+	Name     string               `json:"name" binding:"required" sanitize:"plain" example:"Business words level 3+"`
+	Criteria savedfilter.Criteria `json:"criteria"`
+}
+
+type updateSavedFilterRequest struct {
+	Name     string               `json:"name" binding:"required" sanitize:"plain" example:"Business words level 3+"`
+	Criteria savedfilter.Criteria `json:"criteria"`
+}
+
+// @Summary     List saved filters
+// @Description Lists the saved filters of the current user, optionally for one resource (words, exams or study_sets)
+// @Tags        filters
+// @Produce     json
+// @Param       resource query string false "words, exams or study_sets"
+// @Success     200 {object} Response{data=[]savedfilter.Filter} "Saved filters retrieved successfully"
+// @Failure     400 {object} Response "Unknown resource"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/filters [get]
+func (server *Server) listSavedFilters(ctx *gin.Context) {
+	var req listSavedFiltersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	filters, err := server.savedFilters.List(ctx, authPayload.ID, req.Resource)
+	if err != nil {
+		savedFilterError(ctx, err, "Failed to list saved filters")
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Saved filters retrieved successfully", filters)
+}
+
+// @Summary     Save a filter
+// @Description Saves a named filter set for a list. Words filter on query, min_level, max_level, tags and list; exams on query, premium and unlocked; study sets on query and public. Apply it with ?filter_id= on GET /words, /exams, /study-sets or /study-sets/public.
+// @Tags        filters
+// @Accept      json
+// @Produce     json
+// @Param       filter body createSavedFilterRequest true "Filter"
+// @Success     201 {object} Response{data=savedfilter.Filter} "Saved filter created successfully"
+// @Failure     400 {object} Response "Invalid name, resource or criteria"
+// @Failure     409 {object} Response "A filter with this name already exists"
+// @Failure     422 {object} Response "Saved filter limit reached"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/filters [post]
+func (server *Server) createSavedFilter(ctx *gin.Context) {
+	var req createSavedFilterRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	server.sanitizer.Struct(&req)
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	filter, err := server.savedFilters.Create(ctx, authPayload.ID, req.Resource, req.Name, req.Criteria)
+	if err != nil {
+		savedFilterError(ctx, err, "Failed to save filter")
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusCreated, "Saved filter created successfully", filter)
+}
+
+// @Summary     Get a saved filter
+// @Tags        filters
+// @Produce     json
+// @Param       id path int true "Filter ID"
+// @Success     200 {object} Response{data=savedfilter.Filter} "Saved filter retrieved successfully"
+// @Failure     404 {object} Response "Saved filter not found"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/filters/{id} [get]
+func (server *Server) getSavedFilter(ctx *gin.Context) {
+	var uri savedFilterURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid filter ID", err)
+		return
+	}
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	filter, err := server.savedFilters.Get(ctx, authPayload.ID, uri.ID)
+	if err != nil {
+		savedFilterError(ctx, err, "Failed to get saved filter")
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Saved filter retrieved successfully", filter)
+}
+
+// @Summary     Update a saved filter
+// @Description Renames a saved filter and replaces its criteria. The resource cannot change.
+// @Tags        filters
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Filter ID"
+// @Param       filter body updateSavedFilterRequest true "Filter"
+// @Success     200 {object} Response{data=savedfilter.Filter} "Saved filter updated successfully"
+// @Failure     400 {object} Response "Invalid name or criteria"
+// @Failure     404 {object} Response "Saved filter not found"
+// @Failure     409 {object} Response "A filter with this name already exists"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/filters/{id} [put]
+func (server *Server) updateSavedFilter(ctx *gin.Context) {
+	var uri savedFilterURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid filter ID", err)
+		return
+	}
+	var req updateSavedFilterRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	server.sanitizer.Struct(&req)
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	filter, err := server.savedFilters.Update(ctx, authPayload.ID, uri.ID, req.Name, req.Criteria)
+	if err != nil {
+		savedFilterError(ctx, err, "Failed to update saved filter")
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Saved filter updated successfully", filter)
+}
+
+// @Summary     Delete a saved filter
+// @Tags        filters
+// @Produce     json
+// @Param       id path int true "Filter ID"
+// @Success     200 {object} Response "Saved filter deleted successfully"
+// @Failure     404 {object} Response "Saved filter not found"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/filters/{id} [delete]
+func (server *Server) deleteSavedFilter(ctx *gin.Context) {
+	var uri savedFilterURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid filter ID", err)
+		return
+	}
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	if err := server.savedFilters.Delete(ctx, authPayload.ID, uri.ID); err != nil {
+		savedFilterError(ctx, err, "Failed to delete saved filter")
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Saved filter deleted successfully", nil)
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/security"
+	"github.com/toeic-app/internal/token"
+)
+
+// securityEventDetailsKey holds extra details a handler attaches to the security event of its request
+const securityEventDetailsKey = "security_event_details"
+
+// adminPathPrefixes are the routes whose mutating requests are exported as admin actions
+var adminPathPrefixes = []string{"/api/v1/admin", "/api/v1/rbac"}
+
+// addSecurityEventDetail attaches a detail to the security event emitted for this request
+func addSecurityEventDetail(ctx *gin.Context, key string, value interface{}) {
+	details, ok := ctx.Get(securityEventDetailsKey)
+	if !ok {
+		details = map[string]interface{}{}
+		ctx.Set(securityEventDetailsKey, details)
+	}
+	details.(map[string]interface{})[key] = value
+}
+
+// isAdminAction reports whether a request changes state on an admin route
+func isAdminAction(ctx *gin.Context) bool {
+	switch ctx.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	for _, prefix := range adminPathPrefixes {
+		if strings.HasPrefix(ctx.Request.URL.Path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// classifySecurityEvent derives the security event of a completed request, if any
+func classifySecurityEvent(ctx *gin.Context) (security.SecurityEvent, bool) {
+	event := security.SecurityEvent{
+		Source:    "api",
+		IPAddress: ctx.ClientIP(),
+		UserAgent: ctx.Request.UserAgent(),
+		Path:      ctx.Request.URL.Path,
+		Method:    ctx.Request.Method,
+		Details:   map[string]interface{}{"status": ctx.Writer.Status()},
+	}
+
+	switch status := ctx.Writer.Status(); {
+	case status == http.StatusUnauthorized:
+		event.Type = security.EventTypeAuthFailure
+		event.Severity = security.SeverityMedium
+		event.Description = "Authentication failed"
+	case status == http.StatusForbidden:
+		event.Type = security.EventTypePermissionDenied
+		event.Severity = security.SeverityMedium
+		event.Description = "Access denied"
+	case status == http.StatusTooManyRequests:
+		event.Type = security.EventTypeRateLimitExceeded
+		event.Severity = security.SeverityLow
+		event.Description = "Rate limit exceeded"
+	case isAdminAction(ctx):
+		event.Type = security.EventTypeAdminAction
+		event.Severity = security.SeverityLow
+		event.Description = "Admin action: " + ctx.Request.Method + " " + ctx.FullPath()
+	default:
+		return event, false
+	}
+
+	if payload, ok := ctx.Get(AuthorizationPayloadKey); ok {
+		if authPayload, ok := payload.(*token.Payload); ok {
+			event.UserID = int64(authPayload.ID)
+		}
+	}
+	if details, ok := ctx.Get(securityEventDetailsKey); ok {
+		for key, value := range details.(map[string]interface{}) {
+			event.Details[key] = value
+		}
+	}
+	return event, true
+}
+
+// securityEventMiddleware exports auth failures, permission denials, rate
+// limit rejections and admin actions to the security event stream
+func (server *Server) securityEventMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Next()
+
+		if event, ok := classifySecurityEvent(ctx); ok {
+			server.securityEvents.Emit(event)
+		}
+	}
+}
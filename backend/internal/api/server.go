@@ -2,36 +2,83 @@ package api
 
 import (
 	"context"
-	"crypto/tls"
 	"database/sql"
 	"fmt"
 	"net/http"
+	"os"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	swaggerFiles "github.com/swaggo/files"
-	ginSwagger "github.com/swaggo/gin-swagger"
+	"github.com/toeic-app/internal/accesslog"
+	"github.com/toeic-app/internal/account"
+	"github.com/toeic-app/internal/achievement"
 	"github.com/toeic-app/internal/ai"
+	"github.com/toeic-app/internal/ailimit"
 	"github.com/toeic-app/internal/analyze"
+	"github.com/toeic-app/internal/apikey"
+	"github.com/toeic-app/internal/authoring"
 	"github.com/toeic-app/internal/backup"
+	"github.com/toeic-app/internal/billing"
+	"github.com/toeic-app/internal/bulkedit"
 	"github.com/toeic-app/internal/cache"
+	"github.com/toeic-app/internal/calendar"
+	"github.com/toeic-app/internal/calibration"
 	configPkg "github.com/toeic-app/internal/config"
 	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/dependencies"
+	"github.com/toeic-app/internal/dictionary"
+	"github.com/toeic-app/internal/drain"
+	"github.com/toeic-app/internal/duplicates"
 	"github.com/toeic-app/internal/errors"
+	"github.com/toeic-app/internal/examservice"
+	"github.com/toeic-app/internal/featureflags"
+	"github.com/toeic-app/internal/grading"
 	"github.com/toeic-app/internal/i18n"
+	"github.com/toeic-app/internal/integrity"
+	"github.com/toeic-app/internal/jobs"
+	"github.com/toeic-app/internal/leader"
+	"github.com/toeic-app/internal/learningservice"
 	"github.com/toeic-app/internal/logger"
+	"github.com/toeic-app/internal/lti"
 	"github.com/toeic-app/internal/middleware"
 	"github.com/toeic-app/internal/monitoring"
 	"github.com/toeic-app/internal/notification"
+	"github.com/toeic-app/internal/oauth"
 	"github.com/toeic-app/internal/performance"
+	"github.com/toeic-app/internal/pii"
+	"github.com/toeic-app/internal/placement"
+	"github.com/toeic-app/internal/playback"
+	"github.com/toeic-app/internal/portfolio"
+	"github.com/toeic-app/internal/prediction"
+	"github.com/toeic-app/internal/preferences"
+	"github.com/toeic-app/internal/proctoring"
 	"github.com/toeic-app/internal/rbac"
+	"github.com/toeic-app/internal/readonly"
+	"github.com/toeic-app/internal/referral"
+	"github.com/toeic-app/internal/related"
+	"github.com/toeic-app/internal/retention"
+	"github.com/toeic-app/internal/sanitize"
+	"github.com/toeic-app/internal/savedfilter"
 	"github.com/toeic-app/internal/scheduler"
+	"github.com/toeic-app/internal/security"
+	"github.com/toeic-app/internal/social"
+	"github.com/toeic-app/internal/studyplan"
+	"github.com/toeic-app/internal/suggest"
 	"github.com/toeic-app/internal/token"
+	"github.com/toeic-app/internal/transcript"
+	"github.com/toeic-app/internal/tts"
 	"github.com/toeic-app/internal/upgrade"
 	"github.com/toeic-app/internal/uploader"
+	"github.com/toeic-app/internal/vocabstats"
+	"github.com/toeic-app/internal/warehouse"
 	"github.com/toeic-app/internal/websocket"
-	"golang.org/x/net/http2"
-	"golang.org/x/net/http2/h2c"
+	"github.com/toeic-app/internal/wordlink"
+	"github.com/toeic-app/internal/wordlist"
+	"github.com/toeic-app/internal/wordsense"
+	"github.com/toeic-app/internal/writingrubric"
+	"github.com/toeic-app/internal/writingservice"
+	"github.com/toeic-app/internal/writingtopic"
 )
 
 // @BasePath /api/v1
@@ -43,15 +90,20 @@ import (
 // Server serves HTTP requests for our banking service.
 type Server struct {
 	config                  configPkg.Config
+	configReloader          *configPkg.Reloader // Live configuration for hot-reloadable settings
 	store                   db.Querier
 	tokenMaker              token.Maker
 	router                  *gin.Engine
-	uploader                *uploader.CloudinaryUploader
+	uploader                uploader.Uploader
 	rateLimiter             *middleware.AdvancedRateLimit      // Store the rate limiter instance
 	httpServer              *http.Server                       // Store the HTTP server instance
 	backupScheduler         *scheduler.BackupScheduler         // Automated backup scheduler
 	enhancedBackupScheduler *scheduler.EnhancedBackupScheduler // Enhanced backup scheduler
 	backupManager           *backup.BackupManager              // Enhanced backup manager
+	backupCatalog           backup.Catalog                     // Records the backups that were made
+	backupRestores          *backup.Restores                   // Restores run by this instance, followed on admin.restores
+	migrationBackup         *sync.Once                         // Backs up before pending migrations once; nil when disabled
+	backupMonitor           *backup.SimpleBackupMonitor        // Re-verifies stored backups; nil when BACKUP_REVERIFY_INTERVAL is 0
 	notificationManager     *notification.NotificationManager  // Notification manager for backup events
 	cache                   cache.Cache                        // Cache instance
 	serviceCache            *cache.ServiceCache                // Service layer cache
@@ -68,11 +120,13 @@ type Server struct {
 	analyzeService *analyze.Service // Text analysis service for writing enhancement
 
 	// AI Scoring service
-	aiScoringService *ai.ScoringService // AI-based writing scoring service
+	aiScoringService ai.Provider    // AI-based writing scoring service
+	aiLimits         ailimit.Limits // Limits of the text sent to the AI provider
 
 	// Real-time upgrade notifications
-	wsManager      *websocket.Manager // WebSocket manager for real-time connections
-	upgradeService *upgrade.Service   // Upgrade notification service
+	wsManager       *websocket.Manager // WebSocket manager for real-time connections
+	upgradeService  *upgrade.Service   // Upgrade notification service
+	upgradeRollouts *upgrade.Rollouts  // Upgrade notifications targeted at registered devices
 
 	// Cache management components
 	cacheManager     *cache.CacheManager     // Advanced cache coordinator
@@ -83,6 +137,163 @@ type Server struct {
 	rbacService    *rbac.Service              // Role-based access control service
 	rbacMiddleware *middleware.RBACMiddleware // RBAC middleware
 
+	// Feature flags for gradual rollouts and kill switches
+	featureFlags *featureflags.Service
+
+	// Leader election so scheduled tasks only run on one instance
+	leaderElector *leader.Elector
+
+	// Rich text sanitization applied to content at write time
+	sanitizer *sanitize.Sanitizer
+
+	// Per-user daily upload quota
+	uploadQuota *uploader.QuotaService
+
+	// HMAC request signing with nonce replay protection
+	requestSigner *middleware.RequestSigner
+	corsPolicy    *middleware.CORSPolicy
+
+	// CAPTCHA challenges on auth endpoints, nil when disabled
+	botDetector     *security.BotDetector
+	captchaVerifier *security.CaptchaVerifier
+
+	// Uploaded backups waiting for approval
+	backupQuarantine *backup.Quarantine
+
+	// Security event export to a SIEM, nil when SECURITY_EVENT_SINK is none
+	securityEvents *security.EventStream
+
+	// Personalized study plans from profile goals and progress
+	studyPlans *studyplan.Service
+
+	// Adaptive onboarding placement tests
+	placement *placement.Service
+
+	// Typed user preferences (quiet hours, notifications, session defaults, theme)
+	preferences *preferences.Service
+
+	// Follows, activity feed and weekly XP
+	social *social.Service
+
+	// Badge rules evaluated on the activity log
+	achievements *achievement.Service
+
+	// Referral codes, attribution at registration and rewards
+	referrals *referral.Service
+
+	// Plans, subscriptions and entitlements; nil verifier disables App Store webhooks
+	billing          *billing.Service
+	appStoreVerifier *billing.AppStoreVerifier
+
+	// External dictionary lookups for missing word data; nil when disabled
+	dictionary *dictionary.Service
+
+	// Text-to-speech for short phrases; nil when disabled
+	tts *tts.Service
+
+	// Signed ICS feeds of the study plan
+	calendar *calendar.Service
+
+	// Best writing and speaking work with public share links
+	portfolio *portfolio.Service
+
+	// Question difficulty calibrated from answers
+	calibration *calibration.Service
+
+	// Full test scores predicted from answers
+	predictions *prediction.Service
+
+	// Teacher grading queue for recorded speaking turns
+	grading *grading.Service
+
+	// Exact and near duplicate questions in the bank
+	duplicates *duplicates.Service
+
+	// Taxonomy of writing prompt topics
+	writingTopics *writingtopic.Service
+
+	// Rubrics AI scoring uses per writing task and prompt
+	writingRubrics *writingrubric.Service
+
+	// Background exports and reports with progress tracking
+	jobs *jobs.Service
+	// Refuses new work and tracks what is left while draining for a deployment
+	drain *drain.Controller
+	// Refuses writes on every instance while the database is restored or
+	// migrated
+	readOnly *readonly.Mode
+
+	// Health of the database, Redis, AI provider and other dependencies and
+	// of the features using them
+	dependencies *dependencies.Graph
+
+	// LTI 1.3 launches, grade passback and roster sync; nil when disabled
+	lti *lti.Service
+	// Social login with Google and Apple; nil when no provider is configured
+	oauth *oauth.Service
+
+	// API keys and quotas of the public word and grammar API
+	apiKeys *apikey.Service
+
+	// Imports of standard vocabulary lists (NGSL, TSL, ...)
+	wordLists *wordlist.Service
+
+	// Draft, review and publishing of grammar and writing prompts
+	authoring *authoring.Service
+
+	// Precomputed links between words, grammar, examples and questions
+	related *related.Service
+
+	// Meanings of words with their part of speech and register
+	senses *wordsense.Service
+
+	// Search box completion for words and grammar titles
+	suggestions *suggest.Service
+
+	// Transactional partial updates of many questions or contents
+	bulkEdit *bulkedit.Service
+
+	// Buffers learning attempts into per-word vocabulary statistics
+	vocabStats *vocabstats.Recorder
+
+	// Named filter sets users apply to list endpoints
+	savedFilters *savedfilter.Service
+
+	// Account locks after failed sign-ins and sign-in sessions
+	accounts *account.Service
+
+	// Reads of personal data by other users, shown to the data subject
+	accessLog *accesslog.Service
+
+	// Dictionary words found in reviewed questions and explanations
+	wordLinks *wordlink.Service
+
+	// Focus losses, tab switches and pastes during exam attempts
+	integrity *integrity.Service
+
+	// Play limits of listening audio during exam attempts
+	playback *playback.Service
+
+	// Transcripts of listening questions, revealed after answering
+	transcripts *transcript.Service
+
+	// Webcam snapshots of exam attempts, nil when proctoring is disabled
+	proctoring *proctoring.Service
+
+	// Export of the activity log to an analytics warehouse, nil when disabled
+	warehouse *warehouse.Service
+
+	// Rules for exam attempts, learning sessions and writing submissions,
+	// shared by every transport
+	exams    examservice.Service
+	learning learningservice.Service
+	writings writingservice.Service
+	// Refreshes an exam's cached leaderboard when an attempt is completed
+	leaderboards *examservice.LeaderboardRefresher
+
+	// Scheduled purges of expired recordings, AI feedback and log files
+	retention *retention.Service
+
 	// Error metrics for monitoring
 	errorMetrics *errors.ErrorMetrics // Error metrics for monitoring
 
@@ -90,16 +301,40 @@ type Server struct {
 	monitoringService *monitoring.AdvancedMonitoringService // Advanced monitoring service with Week 4 features
 }
 
+// Providers are the external services the server calls. Unset providers are
+// created from the configuration; tests set fakes.
+type Providers struct {
+	// Uploader stores media, Cloudinary by default
+	Uploader uploader.Uploader
+	// Scoring scores writing and speaking, OpenAI by default
+	Scoring ai.Provider
+	// Synthesizer turns phrases into speech, OpenAI by default when TTS is configured
+	Synthesizer tts.Synthesizer
+}
+
 // NewServer creates a new HTTP server and setup routing.
 func NewServer(config configPkg.Config, store db.Querier, dbConn *sql.DB) (*Server, error) {
+	return NewServerWithProviders(config, store, dbConn, Providers{})
+}
+
+// NewServerWithProviders creates a server calling the given external services
+func NewServerWithProviders(config configPkg.Config, store db.Querier, dbConn *sql.DB, providers Providers) (*Server, error) {
 	tokenMaker, err := token.NewJWTMaker(config.TokenSymmetricKey)
 	if err != nil {
 		return nil, err
 	}
-	cloudinaryUploader, err := uploader.NewCloudinaryUploader(config)
+	// Transparently encrypt user PII when keys are configured
+	store, err = pii.WrapStore(config, store)
 	if err != nil {
 		return nil, err
 	}
+	mediaUploader := providers.Uploader
+	if mediaUploader == nil {
+		mediaUploader, err = uploader.NewCloudinaryUploader(config)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	// Initialize cache if enabled
 	var cacheInstance cache.Cache
@@ -211,15 +446,18 @@ func NewServer(config configPkg.Config, store db.Querier, dbConn *sql.DB) (*Serv
 	}
 
 	// Initialize AI scoring service
-	logger.Info("Initializing AI scoring service...")
-	aiScoringService := ai.NewScoringService(config.OpenAIAPIKey, config.OpenAIAPIURL)
-	logger.Info("AI scoring service initialized with OpenAI ChatGPT")
+	aiScoringService := providers.Scoring
+	if aiScoringService == nil {
+		logger.Info("Initializing AI scoring service...")
+		aiScoringService = ai.NewScoringService(config.OpenAIAPIKey, config.OpenAIAPIURL)
+		logger.Info("AI scoring service initialized with OpenAI ChatGPT")
+	}
 	// Initialize the server
 	server := &Server{
 		config:              config,
 		store:               store,
 		tokenMaker:          tokenMaker,
-		uploader:            cloudinaryUploader,
+		uploader:            mediaUploader,
 		cache:               cacheInstance,
 		serviceCache:        serviceCache,
 		httpCache:           httpCache,
@@ -235,9 +473,17 @@ func NewServer(config configPkg.Config, store db.Querier, dbConn *sql.DB) (*Serv
 		cacheWarmer:         cacheWarmer,      // Add cache warmer
 	}
 
+	// Hot reload of rate limits, cache TTLs and feature flags
+	server.configReloader = configPkg.NewReloader(config)
+	server.configReloader.OnReload(server.applyReloadedConfig)
+
 	// Initialize RBAC system
 	server.rbacService = rbac.NewService(store)
 	server.rbacMiddleware = middleware.NewRBACMiddleware(server.rbacService)
+	wsManager.SetTopicAuthorizer(server.authorizeTopic)
+
+	// Initialize feature flags (cached when the cache is enabled)
+	server.featureFlags = featureflags.NewService(store, cacheInstance)
 
 	logger.Info("Performance optimizations initialized: Object Pool, Response Optimizer, Background Processor")
 	logger.Info("WebSocket manager and upgrade service initialized")
@@ -310,6 +556,9 @@ func NewServer(config configPkg.Config, store db.Querier, dbConn *sql.DB) (*Serv
 	}
 
 	server.monitoringService = monitoring.NewAdvancedMonitoringService(dbConn, cacheInstance, config, advancedMonitoringConfig)
+	if monitor := server.monitoringService.GetMonitor(); monitor != nil {
+		wsManager.SetObserver(monitor.WebSocketMetrics())
+	}
 	logger.Info("Advanced monitoring system initialized with Week 4 features: SLA monitoring, anomaly detection, capacity planning, business analytics, security monitoring, and performance optimization")
 
 	// Initialize enhanced backup system
@@ -322,28 +571,182 @@ func NewServer(config configPkg.Config, store db.Querier, dbConn *sql.DB) (*Serv
 	server.notificationManager = notification.NewNotificationManager(backupConfig)
 
 	// Initialize enhanced backup manager
-	server.backupManager = backup.NewBackupManager(backupConfig, config)
+	server.backupCatalog = backup.NewCatalog(store)
+	server.backupRestores = backup.NewRestores(wsManager)
+	server.backupManager = server.newBackupManager(backupConfig)
+	if backupConfig.MigrationBackupOnStartup && !backupConfig.SkipMigrationBackup {
+		// Run on the leader
+		server.migrationBackup = &sync.Once{}
+	}
+	if backupConfig.ReverifyInterval > 0 {
+		// Started on the leader
+		server.backupMonitor = backup.NewSimpleBackupMonitor(backupConfig)
+		server.backupMonitor.SetVerifier(server.backupManager)
+	}
 
 	// Initialize enhanced backup scheduler
 	server.enhancedBackupScheduler = scheduler.NewEnhancedBackupScheduler(backupConfig, config)
+	server.enhancedBackupScheduler.SetCatalog(server.backupCatalog)
+	server.enhancedBackupScheduler.SetAssetSigner(mediaUploader)
 
 	logger.Info("Enhanced backup system initialized successfully")
 
+	// Initialize leader election
+	server.leaderElector = newLeaderElector(config, dbConn)
+	server.leaderElector.OnElected(server.startLeaderTasks)
+	server.leaderElector.OnRevoked(server.stopLeaderTasks)
+
+	// Initialize rich text sanitization
+	server.sanitizer = sanitize.New(config)
+
+	// Initialize upload quota tracking
+	server.uploadQuota = newUploadQuota(config, store)
+
+	// Initialize CAPTCHA protection for auth endpoints
+	server.botDetector, server.captchaVerifier = newCaptchaProtection(config)
+
+	// Uploaded backups are quarantined until approved
+	server.backupQuarantine = backup.NewQuarantine(backupQuarantineDir)
+
+	// Initialize security event export
+	server.securityEvents, err = security.NewEventStreamFromConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize security event stream: %w", err)
+	}
+
+	// Initialize study plan generation
+	server.studyPlans = studyplan.NewService(store)
+	server.placement = placement.NewService(store)
+	// Real-time user notifications honor the preferences of their recipient
+	server.preferences = preferences.NewService(store)
+	server.social = social.NewService(store, server.preferences.Gate(wsManager, preferences.CategorySocial))
+	server.achievements = achievement.NewService(store, server.preferences.Gate(wsManager, preferences.CategoryAchievements))
+	server.referrals = referral.NewService(store, server.rbacService, config.ReferralRewardDays)
+	server.billing = billing.NewService(store, config.FreeAIScoresPerDay)
+	server.appStoreVerifier = newAppStoreVerifier(config.AppStoreRootCertPath)
+	if config.DictionaryAPIURL != "" {
+		server.dictionary = dictionary.NewService(store,
+			dictionary.NewFreeDictionaryProvider(config.DictionaryAPIURL, 15*time.Second),
+			cacheInstance, config.DictionaryCacheTTL,
+			float64(config.DictionaryRequestsPerMinute)/60, config.DictionaryRetryFailedAfter)
+	}
+	synthesizer := providers.Synthesizer
+	if synthesizer == nil && config.TTSAPIURL != "" && config.OpenAIAPIKey != "" {
+		synthesizer = tts.NewOpenAISynthesizer(config.OpenAIAPIKey, config.TTSAPIURL, config.TTSModel)
+	}
+	if synthesizer != nil {
+		server.tts = tts.NewService(store, synthesizer, mediaUploader, config.TTSDefaultVoice, config.TTSMaxTextLength)
+	}
+	server.calendar = calendar.NewService(store, server.studyPlans, config.TokenSymmetricKey)
+	server.portfolio = portfolio.NewService(store, portfolio.Options{
+		MaxItems:         config.PortfolioMaxItems,
+		HistoryWeeks:     config.PortfolioHistoryWeeks,
+		DefaultShareDays: config.PortfolioShareDefaultDays,
+		MaxShareDays:     config.PortfolioShareMaxDays,
+		MaxActiveLinks:   config.PortfolioMaxActiveLinks,
+	})
+	server.lti = newLTIService(config.LTIPrivateKeyPath, store)
+	server.oauth = newOAuthService(config, store)
+	server.apiKeys = apikey.NewService(store, apikey.Limits{
+		DailyQuota:        int32(config.PublicAPIDailyQuota),
+		RequestsPerMinute: int32(config.PublicAPIRequestsPerMinute),
+	})
+	server.apiKeys.Start(15 * time.Second)
+	server.wordLists = wordlist.NewService(store, wordlist.DefaultTagger())
+	server.authoring = authoring.NewService(store)
+	server.authoring.Start(time.Minute)
+	server.related = related.NewService(store)
+	server.calibration = calibration.NewService(store, calibration.Options{MinResponses: config.CalibrationMinResponses})
+	server.predictions = prediction.NewService(store, prediction.Options{
+		MinAnswers: config.PredictionMinAnswers,
+		MaxAnswers: config.PredictionMaxAnswers,
+	})
+	server.grading = grading.NewService(store, server.preferences.Gate(wsManager, preferences.CategoryGrades),
+		grading.Options{ClaimTTL: config.GradingClaimTTL})
+	server.duplicates = duplicates.NewService(store, duplicates.Options{
+		MinSimilarity: float32(config.QuestionDuplicateMinSimilarity) / 100,
+		MaxCandidates: config.QuestionDuplicateMaxCandidates,
+	})
+	server.writingTopics = writingtopic.NewService(store)
+	server.writingRubrics = writingrubric.NewService(store)
+	runner, _ := os.Hostname()
+	server.jobs = jobs.NewService(store, wsManager, jobs.Options{
+		Dir:       config.JobsDir,
+		Workers:   config.JobWorkers,
+		ResultTTL: config.JobResultTTL,
+		Runner:    runner,
+	})
+	server.jobs.Register(jobs.KindAccountData, jobs.AccountData(store))
+	server.drain = drain.NewController()
+	server.drain.AddWork("background_jobs", server.jobs.Pending)
+	server.readOnly = readonly.NewMode(readonly.NewStore(config), func() bool {
+		return server.configReloader.Current().ReadOnlyMode
+	}, config.ReadOnlyRefreshInterval)
+	server.upgradeRollouts = upgrade.NewRollouts(store, upgradeService, wsManager, upgrade.RolloutOptions{
+		ActiveWithin: time.Duration(config.UpgradeDeviceActiveDays) * 24 * time.Hour,
+	})
+	server.senses = wordsense.NewService(store)
+	server.suggestions = suggest.NewService(store, cacheInstance, config.SearchSuggestCacheTTL)
+	server.bulkEdit = bulkedit.NewService(dbConn)
+	server.vocabStats = vocabstats.NewRecorder(dbConn)
+	server.vocabStats.Start(10 * time.Second)
+	server.savedFilters = savedfilter.NewService(store, config.SavedFiltersPerUser)
+	server.accounts = account.NewService(store, config.AccountLockoutThreshold, config.AccountLockoutDuration,
+		time.Duration(config.RefreshTokenDuration)*time.Second)
+	server.accessLog = accesslog.NewService(store, time.Duration(config.DataAccessLogRetentionDays)*24*time.Hour)
+	server.integrity = integrity.NewService(store)
+	server.wordLinks = wordlink.NewService(store, config.WordLinkCacheTTL)
+	server.warehouse = newWarehouseExporter(config, store)
+	server.leaderboards = examservice.NewLeaderboardRefresher(wsManager)
+	server.exams = newExamService(store, serviceCache, server.ClearUserCache, server.leaderboards, server.predictions)
+	server.leaderboards.Start(server.exams)
+	server.jobs.Register(jobs.KindRegradeAnswers,
+		jobs.RegradeAnswers(server.exams, server.preferences.Gate(wsManager, preferences.CategoryGrades)))
+	server.learning = learningservice.NewService(store, server.senses)
+	server.dependencies = newDependencyGraph(config, dbConn, cacheInstance)
+	server.aiLimits = ailimit.NewLimits(config)
+	server.writings = writingservice.NewService(store,
+		graphScorer{Provider: server.aiScoringService, graph: server.dependencies}, server.billing, server.writingRubrics, server.aiLimits)
+	server.playback = playback.NewService(store, mediaUploader, server.integrity, playback.Options{
+		MaxPlays: int32(config.ListeningMaxPlays),
+		TokenTTL: config.ListeningTokenTTL,
+		URLTTL:   config.ListeningURLTTL,
+	})
+	server.transcripts = transcript.NewService(store)
+	server.proctoring = newProctoringService(config, store, mediaUploader)
+	server.retention = retention.NewService(store, mediaUploader, retention.Options{
+		Policy: retention.Policy{
+			SpeakingAudioDays: config.RetentionSpeakingAudioDays,
+			AIFeedbackDays:    config.RetentionAIFeedbackDays,
+			LogDays:           config.RetentionLogDays,
+			// Snapshots are purged even after proctoring is turned off
+			ProctoringPhotoDays: config.ProctoringRetentionDays,
+		},
+		LogDir:   config.RetentionLogDir,
+		DryRun:   config.RetentionDryRun,
+		IsLeader: server.IsLeader,
+	})
+	server.retention.Start(config.RetentionPurgeInterval)
+
 	// Setup routes
 	server.setupRouter()
 	return server, nil
 }
 
 // @Summary Upload an image file
-// @Description Uploads an image file to Cloudinary and returns the URL.
+// @Description Uploads an image file to Cloudinary and returns the URL. Requires authentication, which anonymous clients did not need before. Uploads count towards the daily quota of the user, described by the X-Upload-Quota-* headers.
 // @Tags Uploads
 // @Accept multipart/form-data
 // @Produce json
 // @Param file formData file true "Image file to upload"
-// @Success 200 {object} object{url=string} "Successfully uploaded image"
+// @Success 200 {object} Response{data=object{url=string}} "Successfully uploaded image"
 // @Failure 400 {object} Response "Bad Request - File not found or invalid"
+// @Failure 401 {object} Response "Unauthorized"
+// @Failure 413 {object} Response "Request body too large"
+// @Failure 429 {object} Response "Daily upload quota exceeded"
 // @Failure 500 {object} Response "Internal Server Error - Error opening or uploading file"
-// @Router /upload [post]
+// @Security ApiKeyAuth
+// @Router /api/v1/upload [post]
 func (server *Server) uploadFile(ctx *gin.Context) {
 	file, err := ctx.FormFile("file")
 	if err != nil {
@@ -352,6 +755,11 @@ func (server *Server) uploadFile(ctx *gin.Context) {
 		return
 	}
 
+	reservation, ok := server.reserveUpload(ctx, file.Size)
+	if !ok {
+		return
+	}
+
 	// TODO: consider generating a more unique filename or using a folder structure
 	// For now, using the original filename.
 	// Be cautious about security implications if filenames are user-controlled and not sanitized.
@@ -359,6 +767,7 @@ func (server *Server) uploadFile(ctx *gin.Context) {
 
 	src, err := file.Open()
 	if err != nil {
+		server.releaseUpload(ctx, reservation)
 		logger.Error("Error opening uploaded file: %v", err)
 		ErrorResponse(ctx, http.StatusInternalServerError, "Error opening uploaded file", err)
 		return
@@ -367,24 +776,30 @@ func (server *Server) uploadFile(ctx *gin.Context) {
 
 	imageURL, err := server.uploader.UploadImage(ctx.Request.Context(), src, filename)
 	if err != nil {
+		server.releaseUpload(ctx, reservation)
 		logger.Error("Error uploading image to cloudinary: %v", err)
 		ErrorResponse(ctx, http.StatusInternalServerError, "Error uploading image to cloudinary", err)
 		return
 	}
+	server.commitUpload(ctx, reservation)
 
-	ctx.JSON(http.StatusOK, gin.H{"url": imageURL})
+	SuccessResponse(ctx, http.StatusOK, "Image uploaded successfully", gin.H{"url": imageURL})
 }
 
 // @Summary Upload an audio file
-// @Description Uploads an audio file to Cloudinary and returns the URL.
+// @Description Uploads an audio file to Cloudinary and returns the URL. Requires authentication, which anonymous clients did not need before. Uploads count towards the daily quota of the user, described by the X-Upload-Quota-* headers.
 // @Tags Uploads
 // @Accept multipart/form-data
 // @Produce json
 // @Param file formData file true "Audio file to upload"
-// @Success 200 {object} object{url=string} "Successfully uploaded audio"
+// @Success 200 {object} Response{data=object{url=string}} "Successfully uploaded audio"
 // @Failure 400 {object} Response "Bad Request - File not found or invalid"
+// @Failure 401 {object} Response "Unauthorized"
+// @Failure 413 {object} Response "Request body too large"
+// @Failure 429 {object} Response "Daily upload quota exceeded"
 // @Failure 500 {object} Response "Internal Server Error - Error opening or uploading file"
-// @Router /upload-audio [post]
+// @Security ApiKeyAuth
+// @Router /api/v1/upload-audio [post]
 func (server *Server) uploadAudioFile(ctx *gin.Context) {
 	file, err := ctx.FormFile("file")
 	if err != nil {
@@ -393,10 +808,16 @@ func (server *Server) uploadAudioFile(ctx *gin.Context) {
 		return
 	}
 
+	reservation, ok := server.reserveUpload(ctx, file.Size)
+	if !ok {
+		return
+	}
+
 	filename := file.Filename
 
 	src, err := file.Open()
 	if err != nil {
+		server.releaseUpload(ctx, reservation)
 		logger.Error("Error opening uploaded file: %v", err)
 		ErrorResponse(ctx, http.StatusInternalServerError, "Error opening uploaded file", err)
 		return
@@ -405,22 +826,36 @@ func (server *Server) uploadAudioFile(ctx *gin.Context) {
 
 	audioURL, err := server.uploader.UploadAudio(ctx.Request.Context(), src, filename)
 	if err != nil {
+		server.releaseUpload(ctx, reservation)
 		logger.Error("Error uploading audio to cloudinary: %v", err)
 		ErrorResponse(ctx, http.StatusInternalServerError, "Error uploading audio to cloudinary", err)
 		return
 	}
-	ctx.JSON(http.StatusOK, gin.H{"url": audioURL})
+	server.commitUpload(ctx, reservation)
+	SuccessResponse(ctx, http.StatusOK, "Audio uploaded successfully", gin.H{"url": audioURL})
 }
 
 func (server *Server) setupRouter() {
 	router := gin.New() // Create a new clean router without default middleware
 
+	// Request DTOs are validated with the rules of the validation registry
+	middleware.RegisterValidators()
+
 	// Initialize error metrics for monitoring
 	errorMetrics := errors.NewErrorMetrics()
 
 	// Enhanced error handling configuration
 	errorConfig := middleware.DefaultErrorHandlerConfig()
 
+	// Tag every request with an ID and start time; responses report both in meta
+	router.Use(middleware.RequestIDMiddleware())
+
+	// Count requests in flight so a drain knows when they finished
+	router.Use(server.trackRequests())
+
+	// Refuse writes during database maintenance
+	router.Use(server.rejectWritesWhileReadOnly())
+
 	// Apply enhanced recovery middleware instead of default gin.Recovery()
 	router.Use(middleware.Recovery(errorConfig, errorMetrics))
 
@@ -431,8 +866,10 @@ func (server *Server) setupRouter() {
 	server.errorMetrics = errorMetrics
 
 	// Apply other middleware
-	router.Use(middleware.Logger())            // Our custom logger
-	router.Use(middleware.CORS(server.config)) // Enable CORS with config
+	server.corsPolicy = middleware.NewCORSPolicy(server.config)
+	router.Use(middleware.Logger())              // Our custom logger
+	router.Use(server.corsPolicy.Middleware())   // Enable CORS with config
+	router.Use(server.securityEventMiddleware()) // Export security events to the SIEM
 
 	// Apply monitoring middleware if enabled
 	if server.monitoringService != nil && server.monitoringService.GetMonitor() != nil {
@@ -456,8 +893,26 @@ func (server *Server) setupRouter() {
 	if server.config.HTTPCacheEnabled && server.httpCache != nil {
 		logger.Info("Enabling HTTP cache with TTL: %v", server.config.HTTPCacheTTL)
 		router.Use(server.httpCache.Middleware())
-	} // Apply advanced security middleware for enhanced protection beyond JWT
+	}
+
+	// Apply request size limits: small for JSON APIs, larger for upload routes.
+	// Registered before advanced security so signed bodies are read within the limit.
+	router.Use(middleware.RouteSizeLimit(server.config.MaxRequestBodySize, []middleware.SizeLimitRule{
+		{PathPrefix: "/api/v1/upload", MaxBytes: server.config.MaxUploadBodySize}, // /upload and /upload-audio
+		{PathPrefix: "/api/v1/admin/backups/upload", MaxBytes: server.config.MaxBackupUploadSize},
+	}))
+	logger.Info("Request size limiting enabled (default: %d bytes, uploads: %d bytes, backups: %d bytes)",
+		server.config.MaxRequestBodySize, server.config.MaxUploadBodySize, server.config.MaxBackupUploadSize)
+
+	// Apply advanced security middleware for enhanced protection beyond JWT
 	advancedSecurity := middleware.NewAdvancedSecurityMiddleware(server.config, server.tokenMaker)
+	var nonceStore middleware.NonceStore = middleware.NewMemoryNonceStore()
+	if server.cache != nil {
+		nonceStore = middleware.NewCacheNonceStore(server.cache)
+	}
+	server.requestSigner = middleware.NewRequestSigner(server.config, nonceStore)
+	advancedSecurity.SetRequestSigner(server.requestSigner)
+	advancedSecurity.SetCORSPolicy(server.corsPolicy)
 	router.Use(advancedSecurity.Middleware())
 	logger.Info("Advanced security middleware enabled - additional headers required for authentication")
 	// Apply enhanced security headers middleware
@@ -469,10 +924,6 @@ func (server *Server) setupRouter() {
 	// logger.Info("HTTPS redirect middleware enabled for production")
 	logger.Info("HTTPS redirect middleware DISABLED for debugging")
 
-	// Apply request size limits
-	router.Use(middleware.RequestSizeLimit(10 * 1024 * 1024)) // 10MB limit
-	logger.Info("Request size limiting enabled (10MB)")
-
 	// Apply enhanced input validation
 	inputConfig := middleware.DefaultInputValidationConfig()
 	router.Use(middleware.EnhancedInputValidation(inputConfig))
@@ -497,7 +948,7 @@ func (server *Server) setupRouter() {
 		if server.monitoringService.GetHealthService() != nil {
 			router.GET("/health/detailed", server.monitoringService.GetHealthService().GetHealthHandler())
 			router.GET("/health/live", server.monitoringService.GetHealthService().GetLivenessHandler())
-			router.GET("/health/ready", server.monitoringService.GetHealthService().GetReadinessHandler())
+			router.GET("/health/ready", server.refuseWhileDraining(), server.monitoringService.GetHealthService().GetReadinessHandler())
 		}
 
 		// Alert endpoints
@@ -517,10 +968,13 @@ func (server *Server) setupRouter() {
 	// Authentication routes
 	authGroup := router.Group("/api/auth")
 	{
-		authGroup.POST("/login", server.loginUser)
-		authGroup.POST("/register", server.registerUser)
+		authGroup.GET("/captcha", server.getCaptchaStatus)
+		authGroup.POST("/login", server.captchaMiddleware(), server.loginUser)
+		authGroup.POST("/register", server.captchaMiddleware(), server.registerUser)
 		authGroup.POST("/refresh-token", server.refreshToken)
 		authGroup.POST("/logout", server.logoutUser)
+		authGroup.POST("/oauth/:provider", server.oauthLogin)
+		authGroup.POST("/oauth/:provider/link", server.authMiddleware(), server.linkOAuthAccount)
 	}
 
 	// API v1 group
@@ -528,8 +982,16 @@ func (server *Server) setupRouter() {
 	{
 		// Public routes
 		v1.POST("/users", server.createUser)
-		v1.POST("/upload", server.uploadFile)
-		v1.POST("/upload-audio", server.uploadAudioFile)
+
+		// Upload routes, authenticated so the daily quota can be tracked per user
+		uploadRoutes := v1.Group("")
+		uploadRoutes.Use(server.authMiddleware())
+		{
+			uploadRoutes.POST("/upload", server.uploadFile)
+			uploadRoutes.POST("/upload-audio", server.uploadAudioFile)
+			uploadRoutes.GET("/uploads/quota", server.getUploadQuota)
+		}
+
 		// Grammar routes (publicly accessible for now, consider auth later if needed)
 		grammarsPublic := v1.Group("/grammars")
 		{
@@ -567,6 +1029,44 @@ func (server *Server) setupRouter() {
 			i18nRoutes.GET("/stats", server.getI18nStats)         // Get i18n statistics
 			i18nRoutes.GET("/translate", server.testTranslation)  // Test translation
 		}
+		v1.GET("/errors", server.listErrorCodes) // Error codes of error responses
+
+		// Billing provider webhooks, authenticated by provider signatures
+		webhooks := v1.Group("/billing/webhooks")
+		{
+			webhooks.POST("/stripe", server.stripeWebhook)
+			webhooks.POST("/google-play", server.googlePlayWebhook)
+			webhooks.POST("/app-store", server.appStoreWebhook)
+		}
+
+		// Calendar subscriptions, authenticated by the signed feed token
+		v1.GET("/calendar/:token", server.getCalendarFeedICS)
+
+		// Shared portfolios, authenticated by the share link token
+		v1.GET("/portfolio/:token", server.getSharedPortfolio)
+
+		// LTI 1.3 launches from LMS platforms, authenticated by platform signatures
+		ltiRoutes := v1.Group("/lti")
+		{
+			ltiRoutes.GET("/login", server.ltiLogin)
+			ltiRoutes.POST("/login", server.ltiLogin)
+			ltiRoutes.POST("/launch", server.ltiLaunch)
+			ltiRoutes.GET("/jwks", server.ltiJWKS)
+		}
+
+		// Read-only word and grammar API for third-party tools, authenticated by API key
+		if server.config.PublicAPIEnabled {
+			publicRoutes := v1.Group("/public")
+			publicRoutes.Use(server.publicAPIAuth())
+			{
+				publicRoutes.GET("/words", server.publicListWords)
+				publicRoutes.GET("/words/search", server.publicSearchWords)
+				publicRoutes.GET("/words/:id", server.publicGetWord)
+				publicRoutes.GET("/grammars", server.publicListGrammars)
+				publicRoutes.GET("/grammars/search", server.publicSearchGrammars)
+				publicRoutes.GET("/grammars/:id", server.publicGetGrammar)
+			}
+		}
 
 		// Protected routes requiring authentication
 		authRoutes := v1.Group("/")
@@ -591,7 +1091,7 @@ func (server *Server) setupRouter() {
 					userRoles.POST("", server.assignRole)
 					userRoles.DELETE("/:user_id/:role_id", server.removeRole)
 					userRoles.GET("/user/:user_id", server.getUserRoles)
-					userRoles.GET("/role/:role_id", server.getUsersByRole)
+					userRoles.GET("/role/:roleName", server.getUsersByRole)
 				}
 
 				// Permission checking
@@ -615,18 +1115,30 @@ func (server *Server) setupRouter() {
 					backups.GET("/download/:filename", server.downloadBackup) // Download a backup
 					backups.DELETE("/:filename", server.deleteBackup)         // Delete a backup
 					backups.POST("/restore", server.restoreBackup)            // Restore from a backup
-					backups.POST("/upload", server.uploadBackup)              // Upload a backup file
+					backups.POST("/upload", server.uploadBackup)              // Upload a backup file into quarantine
+
+					// Quarantined uploads become restorable only after approval
+					backups.GET("/quarantine", server.listQuarantinedBackups)
+					backups.DELETE("/quarantine/:filename", server.rejectQuarantinedBackup)
+					backups.POST("/quarantine/:filename/approve",
+						server.rbacMiddleware.RequirePermission("system", "restore"), server.approveQuarantinedBackup)
 
 					// Enhanced backup routes
 					backups.POST("/enhanced", server.createEnhancedBackup)          // Create enhanced backup
 					backups.POST("/enhanced/restore", server.restoreEnhancedBackup) // Restore with enhanced features
 					backups.GET("/status", server.getBackupStatus)                  // Get backup system status
 					backups.POST("/validate/:filename", server.validateBackupFile)  // Validate backup file
+					backups.GET("/assets/:filename", server.getBackupAssets)        // Media assets of a full backup
 					backups.GET("/schedules", server.getBackupSchedules)            // Get backup schedules
 					backups.POST("/schedules", server.addBackupSchedule)            // Add backup schedule
 					backups.DELETE("/schedules/:id", server.removeBackupSchedule)   // Remove backup schedule
 					backups.GET("/history", server.getBackupHistory)                // Get backup history
 					backups.POST("/cleanup", server.cleanupOldBackupsHandler)       // Manual cleanup
+					backups.POST("/reconcile", server.reconcileBackups)             // Reconcile catalog and storage
+					backups.GET("/restores", server.listRestores)                   // Restores run by this instance
+					backups.GET("/restores/:id", server.getRestore)                 // Poll the progress of a restore
+					backups.GET("/restore/:id/progress", server.getRestore)         // Same, for polling clients
+					backups.POST("/restores/:id/cancel", server.cancelRestore)      // Stop a running restore
 				} // Cache management routes
 				if server.config.CacheEnabled {
 					cacheRoutes := adminRoutes.Group("/cache")
@@ -650,6 +1162,192 @@ func (server *Server) setupRouter() {
 						concurrencyRoutes.POST("/reset", server.resetConcurrencyMetrics) // Reset concurrency metrics
 					}
 				}
+				// Admin feature flag routes
+				featureFlagAdmin := adminRoutes.Group("/feature-flags")
+				featureFlagAdmin.Use(server.rbacMiddleware.RequirePermission("feature_flags", "manage"))
+				{
+					featureFlagAdmin.GET("", server.listFeatureFlags)
+					featureFlagAdmin.POST("", server.createFeatureFlag)
+					featureFlagAdmin.GET("/:key", server.getFeatureFlag)
+					featureFlagAdmin.PUT("/:key", server.updateFeatureFlag)
+					featureFlagAdmin.DELETE("/:key", server.deleteFeatureFlag)
+				}
+				// Admin referral report
+				adminRoutes.GET("/referrals/report",
+					server.rbacMiddleware.RequirePermission("referrals", "read"), server.getReferralReport)
+				// Admin billing routes
+				billingAdmin := adminRoutes.Group("/billing")
+				billingAdmin.Use(server.rbacMiddleware.RequirePermission("billing", "manage"))
+				{
+					billingAdmin.GET("/plans", server.adminListPlans)
+					billingAdmin.POST("/plans", server.createPlan)
+					billingAdmin.PUT("/plans/:id", server.updatePlan)
+					billingAdmin.POST("/subscriptions", server.grantSubscription)
+					billingAdmin.GET("/events", server.listBillingEvents)
+				}
+				// Admin LMS integration routes
+				ltiAdmin := adminRoutes.Group("/lti")
+				ltiAdmin.Use(server.rbacMiddleware.RequirePermission("lti", "manage"))
+				{
+					ltiAdmin.POST("/platforms", server.registerLTIPlatform)
+					ltiAdmin.GET("/platforms", server.listLTIPlatforms)
+					ltiAdmin.DELETE("/platforms/:id", server.deleteLTIPlatform)
+					ltiAdmin.GET("/courses", server.listLTICourses)
+					ltiAdmin.GET("/courses/:id/members", server.listLTICourseMembers)
+					ltiAdmin.POST("/courses/:id/roster-sync", server.syncLTICourseRoster)
+					ltiAdmin.GET("/score-submissions", server.listLTIScoreSubmissions)
+					ltiAdmin.POST("/score-submissions/:attempt_id/retry", server.retryLTIScoreSubmission)
+				}
+
+				// Admin public API key routes
+				apiKeysAdmin := adminRoutes.Group("/api-keys")
+				apiKeysAdmin.Use(server.rbacMiddleware.RequirePermission("api_keys", "manage"))
+				{
+					apiKeysAdmin.POST("", server.createAPIKey)
+					apiKeysAdmin.GET("", server.listAPIKeys)
+					apiKeysAdmin.GET("/usage", server.getAPIUsageDashboard)
+					apiKeysAdmin.PATCH("/:id", server.updateAPIKeyLimits)
+					apiKeysAdmin.DELETE("/:id", server.revokeAPIKey)
+					apiKeysAdmin.GET("/:id/usage", server.getAPIKeyUsage)
+				}
+
+				// Admin question difficulty calibration routes
+				calibrationAdmin := adminRoutes.Group("/question-calibration")
+				calibrationAdmin.Use(server.rbacMiddleware.RequirePermission("exams", "update"))
+				{
+					calibrationAdmin.GET("", server.getQuestionCalibrationStatus)
+					calibrationAdmin.GET("/questions", server.listQuestionCalibrations)
+					calibrationAdmin.POST("/run", server.calibrateQuestionDifficulty)
+				}
+
+				// Admin writing topic taxonomy routes
+				writingTopicsAdmin := adminRoutes.Group("/writing-topics")
+				writingTopicsAdmin.Use(server.rbacMiddleware.RequirePermission("content", "publish"))
+				{
+					writingTopicsAdmin.GET("", server.listWritingTopicsWithCounts)
+					writingTopicsAdmin.POST("", server.createWritingTopic)
+					writingTopicsAdmin.PUT("/:id", server.updateWritingTopic)
+					writingTopicsAdmin.DELETE("/:id", server.deleteWritingTopic)
+				}
+
+				// Admin writing rubric routes
+				writingRubricsAdmin := adminRoutes.Group("/writing-rubrics")
+				writingRubricsAdmin.Use(server.rbacMiddleware.RequirePermission("content", "publish"))
+				{
+					writingRubricsAdmin.GET("", server.listWritingRubrics)
+					writingRubricsAdmin.POST("", server.createWritingRubric)
+					writingRubricsAdmin.GET("/:id", server.getWritingRubric)
+					writingRubricsAdmin.PUT("/:id", server.updateWritingRubric)
+					writingRubricsAdmin.DELETE("/:id", server.deleteWritingRubric)
+				}
+
+				// Admin duplicate question report
+				adminRoutes.GET("/question-duplicates",
+					server.rbacMiddleware.RequirePermission("exams", "update"), server.listDuplicateQuestions)
+
+				// Admin related content routes
+				relatedContent := adminRoutes.Group("/related-content")
+				{
+					relatedContent.GET("", server.getRelatedContentStatus)
+					relatedContent.POST("/rebuild", server.rebuildRelatedContent)
+				}
+
+				// Admin analytics warehouse routes
+				warehouseAdmin := adminRoutes.Group("/warehouse")
+				warehouseAdmin.Use(server.rbacMiddleware.RequirePermission("analytics", "export"))
+				{
+					warehouseAdmin.GET("", server.getWarehouseStatus)
+					warehouseAdmin.POST("/backfill", server.backfillWarehouse)
+				}
+
+				// Admin proctoring review routes
+				proctoringAdmin := adminRoutes.Group("/proctoring")
+				proctoringAdmin.Use(server.rbacMiddleware.RequirePermission("exams", "grade"))
+				{
+					proctoringAdmin.GET("/attempts", server.listProctoredAttempts)
+					proctoringAdmin.GET("/attempts/:id/photos", server.listProctoringPhotos)
+					proctoringAdmin.PATCH("/photos/:id", server.reviewProctoringPhoto)
+				}
+
+				// Admin data retention routes
+				retentionAdmin := adminRoutes.Group("/retention")
+				retentionAdmin.Use(server.rbacMiddleware.RequirePermission("system", "settings"))
+				{
+					retentionAdmin.GET("", server.getRetentionSettings)
+					retentionAdmin.PUT("/organizations/:id", server.setRetentionOverride)
+					retentionAdmin.DELETE("/organizations/:id/:category", server.removeRetentionOverride)
+					retentionAdmin.GET("/report", server.getRetentionReport)
+					retentionAdmin.POST("/purge", server.purgeExpiredData)
+				}
+
+				// Admin bulk edit routes
+				bulkEdit := adminRoutes.Group("")
+				bulkEdit.Use(server.rbacMiddleware.RequirePermission("content", "update"))
+				{
+					bulkEdit.PATCH("/questions/bulk", server.bulkEditQuestions)
+					bulkEdit.POST("/questions/bulk/csv", server.bulkEditQuestionsCSV)
+					bulkEdit.PATCH("/contents/bulk", server.bulkEditContents)
+					bulkEdit.POST("/contents/bulk/csv", server.bulkEditContentsCSV)
+				}
+
+				// Admin word list import routes
+				wordImports := adminRoutes.Group("/word-imports")
+				wordImports.Use(server.rbacMiddleware.RequirePermission("words", "import"))
+				{
+					wordImports.POST("", server.previewWordImport)
+					wordImports.GET("", server.listWordImports)
+					wordImports.GET("/:id", server.getWordImport)
+					wordImports.POST("/:id/commit", server.commitWordImport)
+					wordImports.DELETE("/:id", server.discardWordImport)
+				}
+
+				// Admin dictionary backfill routes
+				dictionaryAdmin := adminRoutes.Group("/dictionary")
+				dictionaryAdmin.Use(server.rbacMiddleware.RequirePermission("dictionary", "manage"))
+				{
+					dictionaryAdmin.GET("/backfill", server.getDictionaryBackfill)
+					dictionaryAdmin.POST("/backfill", server.startDictionaryBackfill)
+					dictionaryAdmin.POST("/words/:id/lookup", server.lookupWordInDictionary)
+				}
+				// Admin user account routes
+				accountAdmin := adminRoutes.Group("/users/:id")
+				{
+					accountAdmin.GET("/account",
+						server.rbacMiddleware.RequirePermission("users", "read"), server.getUserAccount)
+					accountAdmin.POST("/lock",
+						server.rbacMiddleware.RequirePermission("users", "update"), server.lockUserAccount)
+					accountAdmin.POST("/unlock",
+						server.rbacMiddleware.RequirePermission("users", "update"), server.unlockUserAccount)
+				}
+				// Admin achievement badge routes
+				badgeAdmin := adminRoutes.Group("/badges")
+				badgeAdmin.Use(server.rbacMiddleware.RequirePermission("badges", "manage"))
+				{
+					badgeAdmin.GET("", server.listBadges)
+					badgeAdmin.POST("", server.createBadge)
+					badgeAdmin.PUT("/:id", server.updateBadge)
+					badgeAdmin.DELETE("/:id", server.deleteBadge)
+				}
+				// Admin system configuration routes
+				systemAdmin := adminRoutes.Group("/system")
+				systemAdmin.Use(server.rbacMiddleware.RequirePermission("system", "manage"))
+				{
+					systemAdmin.GET("/config", server.getReloadableConfig)  // Get live reloadable settings
+					systemAdmin.POST("/config/reload", server.reloadConfig) // Reload configuration
+					systemAdmin.GET("/leader", server.getLeaderStatus)      // Leader election status
+					systemAdmin.GET("/info", server.getSystemInfo)          // Build, config and subsystem diagnostics
+					systemAdmin.GET("/cors", server.getCORSPolicy)          // Effective CORS policy
+
+					// Draining before a deployment
+					systemAdmin.POST("/drain", server.startDrain)
+					systemAdmin.GET("/drain", server.getDrainStatus)
+					systemAdmin.DELETE("/drain", server.stopDrain)
+
+					// Read-only mode for database maintenance
+					systemAdmin.GET("/read-only", server.getReadOnlyMode)
+					systemAdmin.POST("/read-only", server.enableReadOnlyMode)
+					systemAdmin.DELETE("/read-only", server.disableReadOnlyMode)
+				}
 				// Admin upgrade management routes
 				upgradeAdmin := adminRoutes.Group("/upgrade")
 				upgradeAdmin.Use(server.rbacMiddleware.RequirePermission("system", "manage"))
@@ -657,6 +1355,23 @@ func (server *Server) setupRouter() {
 					upgradeAdmin.GET("/stats", server.getUpgradeStats) // Get upgrade statistics
 					upgradeAdmin.POST("/versions", server.addVersion)  // Add new version
 					upgradeAdmin.POST("/notify", server.notifyUpgrade) // Send upgrade notification
+
+					// Rollouts targeted at registered devices
+					upgradeAdmin.GET("/rollouts", server.listUpgradeRollouts)                         // Targeted rollouts with delivery counts
+					upgradeAdmin.POST("/rollouts", server.createUpgradeRollout)                       // Send to devices matching targeting rules
+					upgradeAdmin.GET("/rollouts/:id/deliveries", server.listUpgradeRolloutDeliveries) // Per-device delivery and acknowledgement
+				}
+				// Dependency health for incident triage
+				monitoringAdmin := adminRoutes.Group("/monitoring")
+				monitoringAdmin.Use(server.rbacMiddleware.RequirePermission("system", "monitor"))
+				{
+					monitoringAdmin.GET("/dependencies", server.getDependencyGraph)
+				}
+				// WebSocket connections of this instance for debugging
+				websocketAdmin := adminRoutes.Group("/websocket")
+				websocketAdmin.Use(server.rbacMiddleware.RequirePermission("system", "monitor"))
+				{
+					websocketAdmin.GET("/connections", server.listWebSocketConnections)
 				} // Admin i18n management routes
 				i18nAdmin := adminRoutes.Group("/i18n")
 				i18nAdmin.Use(server.rbacMiddleware.RequirePermission("i18n", "manage"))
@@ -668,14 +1383,60 @@ func (server *Server) setupRouter() {
 				}
 			}
 
+			// Feature flags evaluated for the current user
+			authRoutes.GET("/feature-flags", server.getMyFeatureFlags)
+
+			// Settings the apps adapt to
+			authRoutes.GET("/client-config", server.getClientConfig)
+
 			users := authRoutes.Group("/users")
 			{
 				users.GET("/me", server.getCurrentUser)
+				users.GET("/me/profile", server.getMyProfile)
+				users.PUT("/me/profile", server.updateMyProfile)
+				users.PUT("/me/language", server.updateMyPreferredLanguage)
+				users.GET("/me/study-plan", server.getMyStudyPlan)
+				users.GET("/me/predicted-score", server.getMyPredictedScore)
+				users.GET("/me/calendar", server.getMyCalendarFeed)
+				users.PUT("/me/calendar", server.configureMyCalendarFeed)
+				users.POST("/me/calendar/regenerate", server.regenerateMyCalendarFeed)
+				users.DELETE("/me/calendar", server.revokeMyCalendarFeed)
+				users.GET("/me/portfolio", server.getMyPortfolio)
+				users.GET("/me/portfolio/shares", server.listMyPortfolioShares)
+				users.POST("/me/portfolio/shares", server.shareMyPortfolio)
+				users.DELETE("/me/portfolio/shares/:id", server.revokeMyPortfolioShare)
+				users.GET("/me/badges", server.listMyBadges)
+				users.GET("/me/access-log", server.listMyAccessLog)
+				users.GET("/me/preferences", server.getMyPreferences)
+				users.PATCH("/me/preferences", server.updateMyPreferences)
+				users.GET("/me/preferences/schema", server.getPreferencesSchema)
+				users.GET("/me/referral", server.getMyReferral)
+				users.GET("/me/subscription", server.getMySubscription)
+				users.POST("/me/subscriptions/link", server.linkPurchase)
+				users.GET("/me/mfa", server.getMFAStatus)
+				users.POST("/me/mfa/totp", server.enrollTOTP)
+				users.POST("/me/mfa/totp/activate", server.activateTOTP)
+				users.DELETE("/me/mfa/totp", server.disableTOTP)
 				users.GET("/:id", server.getUser)
 				users.GET("", server.listUsers)
 				users.PUT("/:id", server.updateUser)
 				users.DELETE("/:id", server.deleteUser)
 			}
+			// Text-to-speech for example sentences and speaking prompts
+			authRoutes.GET("/tts", server.textToSpeech)
+
+			authRoutes.GET("/search/suggest", server.searchSuggestions)
+
+			// Saved filters for the words, exams and study set lists
+			filters := authRoutes.Group("/filters")
+			{
+				filters.GET("", server.listSavedFilters)
+				filters.POST("", server.createSavedFilter)
+				filters.GET("/:id", server.getSavedFilter)
+				filters.PUT("/:id", server.updateSavedFilter)
+				filters.DELETE("/:id", server.deleteSavedFilter)
+			}
+
 			words := authRoutes.Group("/words")
 			{
 				words.GET("/:id", server.getWord)
@@ -684,6 +1445,15 @@ func (server *Server) setupRouter() {
 				words.POST("", server.createWord)
 				words.PUT("/:id", server.updateWord)
 				words.DELETE("/:id", server.deleteWord)
+				words.GET("/tags", server.listWordTags)
+				words.GET("/tags/:tag", server.listWordsByTag)
+				words.GET("/lists", server.listWordLists)
+				words.GET("/lists/:list", server.listWordListEntries)
+				words.GET("/:id/senses", server.listWordSenses)
+				canEditSenses := server.rbacMiddleware.RequirePermission("content", "update")
+				words.POST("/:id/senses", canEditSenses, server.createWordSense)
+				words.PUT("/:id/senses/:sense_id", canEditSenses, server.updateWordSense)
+				words.DELETE("/:id/senses/:sense_id", canEditSenses, server.deleteWordSense)
 			}
 
 			// Study Sets routes
@@ -699,6 +1469,30 @@ func (server *Server) setupRouter() {
 				studySets.DELETE("/:id/words/:word_id", server.removeWordFromStudySet)
 			}
 
+			// Placement test routes
+			authRoutes.GET("/subscriptions/plans", server.listPlans)
+
+			placementTests := authRoutes.Group("/placement-tests")
+			{
+				placementTests.POST("", server.startPlacementTest)
+				placementTests.GET("/:id", server.getPlacementTest)
+				placementTests.POST("/:id/answers", server.answerPlacementItem)
+			}
+
+			// Social routes
+			socialRoutes := authRoutes.Group("/social")
+			{
+				socialRoutes.POST("/follows/:user_id", server.followUser)
+				socialRoutes.DELETE("/follows/:user_id", server.unfollowUser)
+				socialRoutes.GET("/following", server.listFollowing)
+				socialRoutes.GET("/followers", server.listFollowers)
+				socialRoutes.GET("/feed", server.getActivityFeed)
+				socialRoutes.GET("/users/:user_id/activities", server.listUserActivities)
+				socialRoutes.GET("/weekly-xp", server.getWeeklyXP)
+				socialRoutes.GET("/settings", server.getSocialSettings)
+				socialRoutes.PUT("/settings", server.updateSocialSettings)
+			}
+
 			// Learning Sessions routes
 			learning := authRoutes.Group("/learning")
 			{
@@ -717,11 +1511,36 @@ func (server *Server) setupRouter() {
 				vocabulary.GET("/review", server.getWordsNeedingReview)
 				vocabulary.PUT("/words/:word_id/mastery", server.updateWordMastery)
 			} // Protected Grammar routes (e.g., for admin management)
+			// Direct writes skip review, so they need the publish permission
 			grammarsProtected := authRoutes.Group("/grammars")
 			{
-				grammarsProtected.POST("", server.createGrammar)
-				grammarsProtected.PUT("/:id", server.updateGrammar)
-				grammarsProtected.DELETE("/:id", server.deleteGrammar)
+				grammarsProtected.POST("", server.rbacMiddleware.RequirePermission("content", "publish"), server.createGrammar)
+				grammarsProtected.PUT("/:id", server.rbacMiddleware.RequirePermission("content", "publish"), server.updateGrammar)
+				grammarsProtected.DELETE("/:id", server.rbacMiddleware.RequirePermission("content", "delete"), server.deleteGrammar)
+			}
+
+			// Content authoring workflow: draft -> review -> published
+			authoringRoutes := authRoutes.Group("/authoring")
+			{
+				canCreate := server.rbacMiddleware.RequirePermission("content", "create")
+				canEdit := server.rbacMiddleware.RequirePermission("content", "update")
+				canPublish := server.rbacMiddleware.RequirePermission("content", "publish")
+
+				authoringRoutes.POST("/grammars", canCreate, server.createGrammarDraft)
+				authoringRoutes.POST("/grammars/:id/revisions", canEdit, server.startGrammarRevision)
+				authoringRoutes.GET("/grammars/:id/versions", canEdit, server.listGrammarVersions)
+				authoringRoutes.GET("/grammars/:id/history", canEdit, server.listGrammarHistory)
+				authoringRoutes.POST("/writing-prompts", canCreate, server.createWritingPromptDraft)
+				authoringRoutes.POST("/writing-prompts/:id/revisions", canEdit, server.startWritingPromptRevision)
+				authoringRoutes.GET("/writing-prompts/:id/versions", canEdit, server.listWritingPromptVersions)
+				authoringRoutes.GET("/writing-prompts/:id/history", canEdit, server.listWritingPromptHistory)
+				authoringRoutes.GET("/revisions", canEdit, server.listRevisions)
+				authoringRoutes.GET("/revisions/:id", canEdit, server.getRevision)
+				authoringRoutes.PUT("/revisions/:id", canEdit, server.editRevision)
+				authoringRoutes.POST("/revisions/:id/submit", canEdit, server.submitRevision)
+				authoringRoutes.POST("/revisions/:id/discard", canEdit, server.discardRevision)
+				authoringRoutes.POST("/revisions/:id/approve", canPublish, server.approveRevision)
+				authoringRoutes.POST("/revisions/:id/reject", canPublish, server.rejectRevision)
 			}
 
 			// Protected upgrade routes (for authenticated users)
@@ -730,8 +1549,25 @@ func (server *Server) setupRouter() {
 				upgradeProtected.GET("/ws", server.upgradeWebSocket)                  // WebSocket upgrade
 				upgradeProtected.POST("/subscribe", server.subscribeToUpgrades)       // Subscribe to notifications
 				upgradeProtected.POST("/unsubscribe", server.unsubscribeFromUpgrades) // Unsubscribe from notifications
+
+				// Device registration and rollout acknowledgement
+				upgradeProtected.POST("/devices", server.registerUpgradeDevice)              // Register a device and fetch missed rollouts
+				upgradeProtected.POST("/rollouts/:id/ack", server.acknowledgeUpgradeRollout) // Acknowledge a rollout on a device
 			}
 
+			// Background jobs of the current user
+			jobRoutes := authRoutes.Group("/jobs")
+			{
+				jobRoutes.POST("", server.refuseWhileDraining(), server.createJob)
+				jobRoutes.GET("", server.listJobs)
+				jobRoutes.GET("/:id", server.getJob)
+				jobRoutes.POST("/:id/cancel", server.cancelJob)
+				jobRoutes.GET("/:id/result", server.downloadJobResult)
+			}
+
+			// Related content of words, grammar, examples and questions
+			authRoutes.GET("/related/:type/:id", server.getRelatedContent)
+
 			// Example routes
 			examples := authRoutes.Group("/examples")
 			{
@@ -781,9 +1617,16 @@ func (server *Server) setupRouter() {
 			questions := authRoutes.Group("/questions")
 			{
 				questions.POST("", server.createQuestion)
+				questions.POST("/duplicates", server.checkQuestionDuplicates)
 				questions.GET("/:id", server.getQuestion)
 				questions.PUT("/:id", server.updateQuestion)
 				questions.DELETE("/:id", server.deleteQuestion)
+				questions.GET("/:id/transcript",
+					server.rbacMiddleware.RequirePermission("exams", "update"), server.getQuestionTranscript)
+				questions.PUT("/:id/transcript",
+					server.rbacMiddleware.RequirePermission("exams", "update"), server.setQuestionTranscript)
+				questions.DELETE("/:id/transcript",
+					server.rbacMiddleware.RequirePermission("exams", "update"), server.deleteQuestionTranscript)
 			} // User Word Progress routes
 			userWordProgress := authRoutes.Group("/user-word-progress")
 			{
@@ -797,29 +1640,33 @@ func (server *Server) setupRouter() {
 			} // Writing routes
 			writing := authRoutes.Group("/writing")
 			{
+				// Writing topic taxonomy
+				writing.GET("/topics", server.listWritingTopics)
 				// Prompt submissions - separate to avoid wildcard conflict
 				writing.GET("/prompt-submissions/:prompt_id", server.listUserWritingsByPromptID)
 				// Writing prompt routes
 				{
 					prompts := writing.Group("/prompts")
 					{
-						prompts.POST("", server.createWritingPrompt)
+						prompts.POST("", server.rbacMiddleware.RequirePermission("content", "publish"), server.createWritingPrompt)
 						prompts.GET("/:id", server.getWritingPrompt)
 						prompts.GET("", server.listWritingPrompts)
-						prompts.PUT("/:id", server.updateWritingPrompt)
-						prompts.DELETE("/:id", server.deleteWritingPrompt)
+						prompts.PUT("/:id", server.rbacMiddleware.RequirePermission("content", "publish"), server.updateWritingPrompt)
+						prompts.DELETE("/:id", server.rbacMiddleware.RequirePermission("content", "delete"), server.deleteWritingPrompt)
 					}
 				} // User writing submissions routes
 				submissions := writing.Group("/submissions")
 				{
 					submissions.POST("", server.createUserWriting)
+					// Full-text search for teachers reviewing their learners' writing
+					submissions.GET("/search", server.rbacMiddleware.RequirePermission("writing", "evaluate"), server.searchUserWritings)
 					submissions.GET("/:id", server.getUserWriting)
 					submissions.PUT("/:id", server.updateUserWriting)
 					submissions.DELETE("/:id", server.deleteUserWriting)
 				}
 
 				// AI scoring route
-				writing.POST("/score", server.scoreWriting)
+				writing.POST("/score", server.refuseWhileDraining(), server.aiRateLimit(), server.scoreWriting)
 
 				// User-specific writing submissions
 				writing.GET("/users/:user_id/submissions", server.listUserWritingsByUserID)
@@ -848,10 +1695,25 @@ func (server *Server) setupRouter() {
 					turns.PUT("/:id", server.updateSpeakingTurn)
 					turns.DELETE("/:id", server.deleteSpeakingTurn)
 				}
+
+				// Teacher grades of my recorded turns
+				speaking.GET("/grades", server.listMySpeakingGrades)
+
+				// Teacher grading queue
+				grades := speaking.Group("/grading")
+				grades.Use(server.rbacMiddleware.RequirePermission("speaking", "evaluate"))
+				{
+					grades.GET("/rubric", server.getSpeakingGradingRubric)
+					grades.GET("/queue", server.listSpeakingGradingQueue)
+					grades.POST("/turns/:id/claim", server.claimSpeakingTurn)
+					grades.DELETE("/turns/:id/claim", server.releaseSpeakingTurn)
+					grades.POST("/turns/:id/grade", server.gradeSpeakingTurn)
+				}
 			} // Exam Attempt routes
 			examAttempts := authRoutes.Group("/exam-attempts")
 			{
-				examAttempts.POST("", server.createExamAttempt)
+				examAttempts.POST("", server.refuseWhileDraining(), server.createExamAttempt)
+				examAttempts.POST("/practice", server.refuseWhileDraining(), server.startPartPractice)
 				examAttempts.GET("/:id", server.getExamAttempt)
 				examAttempts.GET("", server.listUserExamAttempts)
 				examAttempts.PUT("/:id", server.updateExamAttempt)
@@ -859,6 +1721,15 @@ func (server *Server) setupRouter() {
 				examAttempts.POST("/:id/complete", server.completeExamAttempt)
 				examAttempts.POST("/:id/abandon", server.abandonExamAttempt)
 				examAttempts.GET("/stats", server.getExamAttemptStats)
+				examAttempts.POST("/:id/integrity-events", server.reportIntegrityEvents)
+				examAttempts.GET("/:id/integrity",
+					server.rbacMiddleware.RequirePermission("exams", "grade"), server.getAttemptIntegrity)
+				examAttempts.POST("/:id/questions/:question_id/playback-token", server.issuePlaybackToken)
+				examAttempts.POST("/:id/playback", server.playListeningAudio)
+				examAttempts.GET("/:id/questions/:question_id/transcript", server.revealTranscript)
+				examAttempts.GET("/:id/proctoring", server.getProctoringStatus)
+				examAttempts.POST("/:id/proctoring/consent", server.consentToProctoring)
+				examAttempts.POST("/:id/proctoring/photos", server.uploadProctoringPhoto)
 				// Nested routes for specific exam attempts
 				examAttempts.GET("/:id/answers", server.getUserAnswersByAttempt)
 				examAttempts.GET("/:id/score", server.getAttemptScore)
@@ -890,14 +1761,14 @@ func (server *Server) setupRouter() {
 			if server.aiScoringService != nil {
 				ai := authRoutes.Group("/ai")
 				{
-					ai.POST("/generate-speaking-response", server.generateSpeakingResponse)
+					ai.POST("/generate-speaking-response", server.refuseWhileDraining(), server.aiRateLimit(), server.generateSpeakingResponse)
 				}
 			}
 		}
 	}
 
-	// API documentation with custom URL and configuration options
-	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	// API documentation and its self-check at /swagger/validate
+	router.GET("/swagger/*any", server.swaggerHandler())
 
 	// Log all routes for debugging
 	logger.Debug("API Routes:")
@@ -951,72 +1822,33 @@ func (server *Server) Start(address string) error {
 		}()
 	}
 
-	// Create a proper http.Server with reasonable timeouts
-	server.httpServer = &http.Server{
-		Addr:         address,
-		Handler:      server.router,
-		ReadTimeout:  10 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  120 * time.Second,
+	// Fail jobs interrupted by the last shutdown and expire old results
+	if err := server.jobs.Start(time.Hour); err != nil {
+		logger.Error("Failed to start background jobs: %v", err)
 	}
-	// Configure HTTP/2 if enabled
-	if server.config.HTTP2Enabled {
-		logger.Info("Enabling HTTP/2 support")
-
-		// Configure HTTP/2 server settings
-		http2.ConfigureServer(server.httpServer, &http2.Server{
-			MaxConcurrentStreams: server.config.HTTP2MaxStreams,
-			IdleTimeout:          time.Duration(server.config.HTTP2IdleTimeout) * time.Second,
-			MaxReadFrameSize:     1048576, // 1MB
-		})
-
-		// Configure TLS for HTTP/2 if TLS is enabled
-		if server.config.TLSEnabled {
-			logger.Info("Configuring TLS for HTTP/2")
-			server.httpServer.TLSConfig = &tls.Config{
-				NextProtos: []string{"h2", "http/1.1"}, // Enable HTTP/2 and fallback to HTTP/1.1
-				MinVersion: tls.VersionTLS12,           // HTTP/2 requires TLS 1.2+
-			}
-
-			logger.Info("Starting HTTPS server with HTTP/2 support on %s", address)
-			logger.Info("Using TLS certificate: %s", server.config.TLSCertFile)
-			logger.Info("Using TLS key: %s", server.config.TLSKeyFile)
-
-			// Run the HTTPS server with HTTP/2
-			return server.httpServer.ListenAndServeTLS(server.config.TLSCertFile, server.config.TLSKeyFile)
-		} else {
-			// HTTP/2 without TLS (h2c - HTTP/2 Cleartext)
-			logger.Warn("HTTP/2 without TLS is enabled (h2c). This provides limited functionality and is not recommended for production.")
-
-			// Configure h2c (HTTP/2 Cleartext)
-			h2s := &http2.Server{
-				MaxConcurrentStreams: server.config.HTTP2MaxStreams,
-				IdleTimeout:          time.Duration(server.config.HTTP2IdleTimeout) * time.Second,
-				MaxReadFrameSize:     1048576, // 1MB
-			}
 
-			// Wrap the router with h2c handler
-			server.httpServer.Handler = h2c.NewHandler(server.router, h2s)
-			logger.Info("Starting HTTP server with HTTP/2 cleartext (h2c) support on %s", address)
-		}
-	} else if server.config.TLSEnabled {
-		// TLS enabled but HTTP/2 disabled - use HTTPS with HTTP/1.1 only
-		logger.Info("Starting HTTPS server with HTTP/1.1 on %s", address)
-		logger.Info("Using TLS certificate: %s", server.config.TLSCertFile)
-		logger.Info("Using TLS key: %s", server.config.TLSKeyFile)
+	// Probe the dependencies in the background
+	server.dependencies.Start(server.config.DependencyCheckInterval)
 
-		return server.httpServer.ListenAndServeTLS(server.config.TLSCertFile, server.config.TLSKeyFile)
+	// Create the http.Server from configuration (timeouts, TLS, HTTP/2)
+	httpServer, err := server.newHTTPServer(address)
+	if err != nil {
+		return err
 	}
+	server.httpServer = httpServer
 
-	logger.Info("Starting HTTP server on %s", address)
-	// Run the server (HTTP or h2c)
-	return server.httpServer.ListenAndServe()
+	return server.listenAndServe(httpServer)
 }
 
 // Shutdown gracefully stops the server and cleans up resources
 func (server *Server) Shutdown(ctx context.Context) error {
 	logger.Info("Shutting down HTTP server...")
 
+	// Refuse new work and fail readiness, unless a drain already started
+	if server.drain != nil {
+		server.drain.Start(server.config.DrainTimeout, "shutdown")
+	}
+
 	// Shut down the HTTP server first, so it stops accepting new requests
 	var err error
 	if server.httpServer != nil {
@@ -1035,12 +1867,29 @@ func (server *Server) Shutdown(ctx context.Context) error {
 		logger.Info("Rate limiter shutdown complete")
 	}
 
+	// Close the Redis client of upload quotas
+	if err := server.uploadQuota.Close(); err != nil {
+		logger.Warn("Failed to close upload quota Redis client: %v", err)
+	}
+
+	// Flush buffered security events
+	if server.securityEvents != nil {
+		if err := server.securityEvents.Close(ctx); err != nil {
+			logger.Error("Error closing security event stream: %v", err)
+		}
+	}
+
 	// Stop the token maker to clean up blacklist resources
 	if server.tokenMaker != nil {
 		server.tokenMaker.Stop()
 		logger.Info("Token blacklist cleanup stopped")
 	}
 
+	// Stop refreshing leaderboards before their subscribers go away
+	if server.leaderboards != nil {
+		server.leaderboards.Stop()
+	}
+
 	// Shutdown WebSocket manager
 	if server.wsManager != nil {
 		if err := server.wsManager.Shutdown(ctx); err != nil {
@@ -1055,6 +1904,58 @@ func (server *Server) Shutdown(ctx context.Context) error {
 		logger.Info("Monitoring service stopped successfully")
 	}
 
+	// Step down as leader so another instance can take over scheduled tasks
+	if server.leaderElector != nil {
+		server.leaderElector.Stop()
+	}
+
+	// Stop publishing scheduled content
+	if server.authoring != nil {
+		server.authoring.Stop()
+	}
+
+	// Let queued background jobs finish until the drain deadline, then
+	// cancel the rest
+	if server.drain != nil {
+		status := server.drain.Wait(ctx)
+		if status.PendingWork["background_jobs"] > 0 {
+			logger.Warn("Cancelling %d background jobs left at shutdown", status.PendingWork["background_jobs"])
+		}
+	}
+	if server.jobs != nil {
+		server.jobs.Stop()
+	}
+
+	// Stop probing dependencies
+	if server.dependencies != nil {
+		server.dependencies.Stop()
+	}
+
+	// Write buffered public API usage
+	if server.apiKeys != nil {
+		server.apiKeys.Stop()
+	}
+
+	// Write buffered vocabulary statistics
+	if server.vocabStats != nil {
+		server.vocabStats.Stop()
+	}
+
+	// Cancel a running warehouse backfill
+	if server.warehouse != nil {
+		server.warehouse.Close()
+	}
+
+	// Stop scheduled retention purges
+	if server.retention != nil {
+		server.retention.Stop()
+	}
+
+	// Cancel a running dictionary backfill
+	if server.dictionary != nil {
+		server.dictionary.Stop()
+	}
+
 	// Stop cache manager if available
 	if server.cacheManager != nil {
 		logger.Info("Stopping cache manager...")
@@ -1085,6 +1986,20 @@ func (server *Server) CleanupOldBackups(maxAge time.Duration) error {
 	return server.backupManager.CleanupOldBackups(maxAge)
 }
 
+// EnforceBackupRetention removes the backups the configured retention
+// policy does not keep
+func (server *Server) EnforceBackupRetention(ctx context.Context) error {
+	if server.backupManager == nil {
+		return fmt.Errorf("backup manager not initialized")
+	}
+	plan, deleted, err := server.backupManager.EnforceRetention(ctx)
+	if err != nil {
+		return err
+	}
+	logger.Info("Backup retention kept %d backups and removed %d", len(plan.Keep), deleted)
+	return nil
+}
+
 // StartAutomaticBackups starts the automatic backup system
 func (server *Server) StartAutomaticBackups(ctx context.Context) error {
 	if server.backupManager == nil {
@@ -1105,3 +2020,9 @@ func (server *Server) StopAutomaticBackups() error {
 	logger.Info("Automatic backup cleanup requested")
 	return nil
 }
+
+// WatchConfigReload reloads the reloadable settings whenever the process receives SIGHUP
+func (server *Server) WatchConfigReload(ctx context.Context) {
+	server.configReloader.WatchSignals(ctx)
+	logger.Info("Config hot reload enabled (send SIGHUP or POST /api/v1/admin/system/config/reload)")
+}
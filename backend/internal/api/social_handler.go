@@ -0,0 +1,364 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/logger"
+	"github.com/toeic-app/internal/social"
+	"github.com/toeic-app/internal/token"
+)
+
+// listFollowsRequest pages through followed users or followers
+type listFollowsRequest struct {
+	Limit  int32 `form:"limit,default=20" binding:"min=1,max=100"`
+	Offset int32 `form:"offset,default=0" binding:"min=0"`
+}
+
+// listActivitiesRequest pages through activities, newest first
+type listActivitiesRequest struct {
+	Before int64 `form:"before" binding:"min=0"`
+	Limit  int32 `form:"limit,default=20" binding:"min=1,max=100"`
+}
+
+// updateSocialSettingsRequest replaces the social privacy settings of the current user
+type updateSocialSettingsRequest struct {
+	ActivityVisibility string `json:"activity_visibility" binding:"required,oneof=public followers private"`
+	ShareScores        bool   `json:"share_scores"`
+}
+
+// FollowedUserResponse is a user in a follow list
+type FollowedUserResponse struct {
+	ID         int32     `json:"id"`
+	Username   string    `json:"username"`
+	FollowedAt time.Time `json:"followed_at"`
+}
+
+// FollowListResponse is a page of a follow list with the follow counts of the current user
+type FollowListResponse struct {
+	Users     []FollowedUserResponse `json:"users"`
+	Followers int64                  `json:"followers"`
+	Following int64                  `json:"following"`
+}
+
+// socialErrorStatus maps social errors to HTTP statuses
+func socialErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, social.ErrSelfFollow):
+		return http.StatusBadRequest
+	case errors.Is(err, social.ErrUserNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, social.ErrActivityHidden):
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// recordActivity adds an activity to the feed and returns the user's streak.
+// A referred user converts with their first activity. Failures are logged
+// and do not fail the request.
+func (server *Server) recordActivity(ctx context.Context, userID int32, activityType string, payload map[string]interface{}, xp int32) (social.StreakUpdate, error) {
+	streak, err := server.social.Record(ctx, userID, activityType, payload, xp)
+	if err != nil {
+		logger.Warn("Failed to record %s activity for user %d: %v", activityType, userID, err)
+	}
+	if err := server.referrals.Convert(ctx, userID); err != nil {
+		logger.Warn("Failed to convert referral of user %d: %v", userID, err)
+	}
+	return streak, err
+}
+
+func parseUserIDParam(ctx *gin.Context) (int32, bool) {
+	userID, err := strconv.ParseInt(ctx.Param("user_id"), 10, 32)
+	if err != nil || userID <= 0 {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid user ID", err)
+		return 0, false
+	}
+	return int32(userID), true
+}
+
+// @Summary     Follow user
+// @Description Follows another user. The followed user receives a social.new_follower WebSocket event.
+// @Tags        social
+// @Produce     json
+// @Param       user_id path int true "User ID"
+// @Success     201 {object} Response "User followed"
+// @Success     200 {object} Response "Already following user"
+// @Failure     400 {object} Response "Invalid user ID or self-follow"
+// @Failure     404 {object} Response "User not found"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/social/follows/{user_id} [post]
+func (server *Server) followUser(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+	followeeID, ok := parseUserIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	created, err := server.social.Follow(ctx, authPayload.ID, followeeID)
+	if err != nil {
+		ErrorResponse(ctx, socialErrorStatus(err), "Failed to follow user", err)
+		return
+	}
+	if !created {
+		SuccessResponse(ctx, http.StatusOK, "Already following user", nil)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusCreated, "User followed", nil)
+}
+
+// @Summary     Unfollow user
+// @Description Stops following a user
+// @Tags        social
+// @Produce     json
+// @Param       user_id path int true "User ID"
+// @Success     200 {object} Response "User unfollowed"
+// @Failure     400 {object} Response "Invalid user ID"
+// @Failure     404 {object} Response "Not following user"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/social/follows/{user_id} [delete]
+func (server *Server) unfollowUser(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+	followeeID, ok := parseUserIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	removed, err := server.social.Unfollow(ctx, authPayload.ID, followeeID)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to unfollow user", err)
+		return
+	}
+	if !removed {
+		ErrorResponse(ctx, http.StatusNotFound, "Not following user", nil)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "User unfollowed", nil)
+}
+
+// @Summary     List followed users
+// @Description Lists the users followed by the current user
+// @Tags        social
+// @Produce     json
+// @Param       limit query int false "Page size (max 100)" default(20)
+// @Param       offset query int false "Offset" default(0)
+// @Success     200 {object} Response{data=FollowListResponse} "Followed users retrieved successfully"
+// @Failure     400 {object} Response "Invalid query parameters"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/social/following [get]
+func (server *Server) listFollowing(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	var req listFollowsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	rows, err := server.store.ListFollowing(ctx, db.ListFollowingParams{
+		FollowerID: authPayload.ID,
+		Limit:      req.Limit,
+		Offset:     req.Offset,
+	})
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to list followed users", err)
+		return
+	}
+	users := make([]FollowedUserResponse, 0, len(rows))
+	for _, row := range rows {
+		users = append(users, FollowedUserResponse{ID: row.ID, Username: row.Username, FollowedAt: row.FollowedAt})
+	}
+
+	server.respondFollowList(ctx, authPayload.ID, users, "Followed users retrieved successfully")
+}
+
+// @Summary     List followers
+// @Description Lists the users following the current user
+// @Tags        social
+// @Produce     json
+// @Param       limit query int false "Page size (max 100)" default(20)
+// @Param       offset query int false "Offset" default(0)
+// @Success     200 {object} Response{data=FollowListResponse} "Followers retrieved successfully"
+// @Failure     400 {object} Response "Invalid query parameters"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/social/followers [get]
+func (server *Server) listFollowers(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	var req listFollowsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	rows, err := server.store.ListFollowers(ctx, db.ListFollowersParams{
+		FolloweeID: authPayload.ID,
+		Limit:      req.Limit,
+		Offset:     req.Offset,
+	})
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to list followers", err)
+		return
+	}
+	users := make([]FollowedUserResponse, 0, len(rows))
+	for _, row := range rows {
+		users = append(users, FollowedUserResponse{ID: row.ID, Username: row.Username, FollowedAt: row.FollowedAt})
+	}
+
+	server.respondFollowList(ctx, authPayload.ID, users, "Followers retrieved successfully")
+}
+
+func (server *Server) respondFollowList(ctx *gin.Context, userID int32, users []FollowedUserResponse, message string) {
+	counts, err := server.store.GetFollowCounts(ctx, userID)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to count follows", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, message, FollowListResponse{
+		Users:     users,
+		Followers: counts.Followers,
+		Following: counts.Following,
+	})
+}
+
+// @Summary     Get activity feed
+// @Description Returns recent activities (completed mock tests, streak milestones...) of followed users, newest first. Private activities are excluded and scores are only shown when their owner shares them. Pass the ID of the last activity as before to get the next page.
+// @Tags        social
+// @Produce     json
+// @Param       before query int false "Return activities older than this activity ID"
+// @Param       limit query int false "Page size (max 100)" default(20)
+// @Success     200 {object} Response{data=[]social.Activity} "Feed retrieved successfully"
+// @Failure     400 {object} Response "Invalid query parameters"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/social/feed [get]
+func (server *Server) getActivityFeed(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	var req listActivitiesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	feed, err := server.social.Feed(ctx, authPayload.ID, req.Before, req.Limit)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve feed", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Feed retrieved successfully", feed)
+}
+
+// @Summary     List user activities
+// @Description Returns the activities of a user, subject to their visibility setting
+// @Tags        social
+// @Produce     json
+// @Param       user_id path int true "User ID"
+// @Param       before query int false "Return activities older than this activity ID"
+// @Param       limit query int false "Page size (max 100)" default(20)
+// @Success     200 {object} Response{data=[]social.Activity} "Activities retrieved successfully"
+// @Failure     400 {object} Response "Invalid request parameters"
+// @Failure     403 {object} Response "Activity of this user is not visible"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/social/users/{user_id}/activities [get]
+func (server *Server) listUserActivities(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+	userID, ok := parseUserIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	var req listActivitiesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	activities, err := server.social.UserActivities(ctx, authPayload.ID, userID, req.Before, req.Limit)
+	if err != nil {
+		ErrorResponse(ctx, socialErrorStatus(err), "Failed to retrieve activities", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Activities retrieved successfully", activities)
+}
+
+// @Summary     Compare weekly XP
+// @Description Ranks the XP earned since Monday (UTC) by the current user and the users they follow, with the current daily streak
+// @Tags        social
+// @Produce     json
+// @Success     200 {object} Response{data=social.WeeklyXP} "Weekly XP retrieved successfully"
+// @Failure     401 {object} Response "Unauthorized"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/social/weekly-xp [get]
+func (server *Server) getWeeklyXP(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	weekly, err := server.social.WeeklyXP(ctx, authPayload.ID, time.Now())
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve weekly XP", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Weekly XP retrieved successfully", weekly)
+}
+
+// @Summary     Get social settings
+// @Description Returns who can see the activities of the current user and whether scores are shared
+// @Tags        social
+// @Produce     json
+// @Success     200 {object} Response{data=social.Settings} "Social settings retrieved successfully"
+// @Failure     401 {object} Response "Unauthorized"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/social/settings [get]
+func (server *Server) getSocialSettings(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	settings, err := server.social.Settings(ctx, authPayload.ID)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve social settings", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Social settings retrieved successfully", settings)
+}
+
+// @Summary     Update social settings
+// @Description Sets who can see the activities of the current user (public, followers or private) and whether scores are shown to others
+// @Tags        social
+// @Accept      json
+// @Produce     json
+// @Param       settings body updateSocialSettingsRequest true "Social settings"
+// @Success     200 {object} Response{data=social.Settings} "Social settings updated successfully"
+// @Failure     400 {object} Response "Invalid request parameters"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/social/settings [put]
+func (server *Server) updateSocialSettings(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	var req updateSocialSettingsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request parameters", err)
+		return
+	}
+
+	settings, err := server.social.UpdateSettings(ctx, authPayload.ID, social.Settings{
+		ActivityVisibility: req.ActivityVisibility,
+		ShareScores:        req.ShareScores,
+	})
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to update social settings", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Social settings updated successfully", settings)
+}
@@ -11,7 +11,9 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/sqlc-dev/pqtype"
+	"github.com/toeic-app/internal/accesslog"
 	"github.com/toeic-app/internal/ai"
+	"github.com/toeic-app/internal/ailimit"
 	db "github.com/toeic-app/internal/db/sqlc"
 	"github.com/toeic-app/internal/logger"
 )
@@ -200,17 +202,40 @@ func (server *Server) createSpeakingSession(ctx *gin.Context) {
 	SuccessResponse(ctx, http.StatusCreated, "Speaking session created successfully", NewSpeakingSessionResponse(session))
 }
 
+// speakingSessionAccess describes a read of a speaking session for the access log
+func speakingSessionAccess(session db.SpeakingSession) accesslog.Access {
+	return accesslog.Access{SubjectID: session.UserID, ResourceType: accesslog.ResourceSpeakingSession, ResourceID: session.ID}
+}
+
+// speakingTurnAccesses describes reads of turns of a session for the access
+// log. Turns carry recordings of the session's user.
+func (server *Server) speakingTurnAccesses(ctx *gin.Context, sessionID int32, turns []db.SpeakingTurn) ([]accesslog.Access, error) {
+	if len(turns) == 0 {
+		return nil, nil
+	}
+	session, err := server.store.GetSpeakingSession(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find owner of speaking session %d: %w", sessionID, err)
+	}
+	accesses := make([]accesslog.Access, 0, len(turns))
+	for _, turn := range turns {
+		accesses = append(accesses, accesslog.Access{SubjectID: session.UserID, ResourceType: accesslog.ResourceSpeakingTurn, ResourceID: turn.ID})
+	}
+	return accesses, nil
+}
+
 // getSpeakingSessionRequest defines the structure for requests to get a speaking session by ID
 type getSpeakingSessionRequest struct {
 	ID int32 `uri:"id" binding:"required,min=1"`
 }
 
 // @Summary     Get a speaking session by ID
-// @Description Retrieve a specific speaking session by its ID
+// @Description Retrieve a specific speaking session by its ID. Reading another user's session is recorded in their access log.
 // @Tags        speaking
 // @Accept      json
 // @Produce     json
 // @Param       id path int true "Speaking Session ID"
+// @Param       X-Access-Purpose header string false "Purpose of reading another user's data: support, grading, moderation, legal or unspecified"
 // @Success     200 {object} Response{data=SpeakingSessionResponse} "Speaking session retrieved successfully"
 // @Failure     400 {object} Response "Invalid session ID"
 // @Failure     404 {object} Response "Speaking session not found"
@@ -232,17 +257,21 @@ func (server *Server) getSpeakingSession(ctx *gin.Context) {
 		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve speaking session", err)
 		return
 	}
+	if !server.recordDataAccess(ctx, speakingSessionAccess(session)) {
+		return
+	}
 
 	logger.Debug("Retrieved speaking session with ID: %d", session.ID)
 	SuccessResponse(ctx, http.StatusOK, "Speaking session retrieved successfully", NewSpeakingSessionResponse(session))
 }
 
 // @Summary     List speaking sessions by user ID
-// @Description Get a list of all speaking sessions for a specific user
+// @Description Get a list of all speaking sessions for a specific user. Reading another user's sessions is recorded in their access log.
 // @Tags        speaking
 // @Accept      json
 // @Produce     json
 // @Param       user_id path int true "User ID"
+// @Param       X-Access-Purpose header string false "Purpose of reading another user's data: support, grading, moderation, legal or unspecified"
 // @Success     200 {object} Response{data=[]SpeakingSessionResponse} "Speaking sessions retrieved successfully"
 // @Failure     400 {object} Response "Invalid user ID"
 // @Failure     500 {object} Response "Failed to retrieve speaking sessions"
@@ -261,10 +290,15 @@ func (server *Server) listSpeakingSessionsByUserID(ctx *gin.Context) {
 		return
 	}
 
+	accesses := make([]accesslog.Access, 0, len(sessions))
 	var sessionResponses []SpeakingSessionResponse
 	for _, session := range sessions {
+		accesses = append(accesses, speakingSessionAccess(session))
 		sessionResponses = append(sessionResponses, NewSpeakingSessionResponse(session))
 	}
+	if !server.recordDataAccess(ctx, accesses...) {
+		return
+	}
 
 	// Ensure we return an empty array instead of null if no results
 	if sessionResponses == nil {
@@ -475,11 +509,12 @@ type getSpeakingTurnRequest struct {
 }
 
 // @Summary     Get a speaking turn by ID
-// @Description Retrieve a specific speaking turn by its ID
+// @Description Retrieve a specific speaking turn by its ID. Reading a turn of another user's session is recorded in their access log.
 // @Tags        speaking
 // @Accept      json
 // @Produce     json
 // @Param       id path int true "Speaking Turn ID"
+// @Param       X-Access-Purpose header string false "Purpose of reading another user's data: support, grading, moderation, legal or unspecified"
 // @Success     200 {object} Response{data=SpeakingTurnResponse} "Speaking turn retrieved successfully"
 // @Failure     400 {object} Response "Invalid turn ID"
 // @Failure     404 {object} Response "Speaking turn not found"
@@ -501,17 +536,26 @@ func (server *Server) getSpeakingTurn(ctx *gin.Context) {
 		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve speaking turn", err)
 		return
 	}
+	accesses, err := server.speakingTurnAccesses(ctx, turn.SessionID, []db.SpeakingTurn{turn})
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve speaking turn", err)
+		return
+	}
+	if !server.recordDataAccess(ctx, accesses...) {
+		return
+	}
 
 	logger.Debug("Retrieved speaking turn with ID: %d", turn.ID)
 	SuccessResponse(ctx, http.StatusOK, "Speaking turn retrieved successfully", NewSpeakingTurnResponse(turn))
 }
 
 // @Summary     List speaking turns by session ID
-// @Description Get a list of all speaking turns for a specific session
+// @Description Get a list of all speaking turns for a specific session. Reading the turns of another user's session is recorded in their access log.
 // @Tags        speaking
 // @Accept      json
 // @Produce     json
 // @Param       id path int true "Session ID"
+// @Param       X-Access-Purpose header string false "Purpose of reading another user's data: support, grading, moderation, legal or unspecified"
 // @Success     200 {object} Response{data=[]SpeakingTurnResponse} "Speaking turns retrieved successfully"
 // @Failure     400 {object} Response "Invalid session ID"
 // @Failure     500 {object} Response "Failed to retrieve speaking turns"
@@ -529,6 +573,14 @@ func (server *Server) listSpeakingTurnsBySessionID(ctx *gin.Context) {
 		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve speaking turns", err)
 		return
 	}
+	accesses, err := server.speakingTurnAccesses(ctx, int32(sessionID), turns)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve speaking turns", err)
+		return
+	}
+	if !server.recordDataAccess(ctx, accesses...) {
+		return
+	}
 
 	var turnResponses []SpeakingTurnResponse
 	for _, turn := range turns {
@@ -670,17 +722,18 @@ func (server *Server) deleteSpeakingTurn(ctx *gin.Context) {
 type GenerateSpeakingRequest struct {
 	UserMessage         string `json:"user_message" binding:"required"`
 	ConversationContext string `json:"conversation_context"`
-	Difficulty          string `json:"difficulty"`
+	Difficulty          string `json:"difficulty" binding:"omitempty,difficulty_level"`
 }
 
 // GenerateSpeakingResponse response structure
 type GenerateSpeakingResponseData struct {
 	Response    string `json:"response"`
 	ProcessedAt string `json:"processed_at"`
+	Truncated   bool   `json:"truncated,omitempty"` // Set when only part of the message or context was sent to the AI
 }
 
 // @Summary     Generate AI speaking response
-// @Description Generate an AI response for speaking practice based on user input and conversation context
+// @Description Generate an AI response for speaking practice based on user input and conversation context. Messages over AI_MAX_SPEAKING_WORDS or AI_MAX_SPEAKING_CHARS and context over AI_MAX_CONTEXT_CHARS are refused with 413, or truncated when AI_OVERSIZE_MODE is truncate: the message keeps its beginning and the context its most recent part. Requests count against the AI rate limit bucket.
 // @Tags        ai
 // @Accept      json
 // @Produce     json
@@ -688,6 +741,8 @@ type GenerateSpeakingResponseData struct {
 // @Success     200 {object} Response{data=GenerateSpeakingResponseData} "Speaking response generated successfully"
 // @Failure     400 {object} Response "Invalid request parameters"
 // @Failure     401 {object} Response "Unauthorized"
+// @Failure     413 {object} Response "Message or conversation context too long"
+// @Failure     429 {object} Response "AI rate limit exceeded"
 // @Failure     503 {object} Response "AI service unavailable"
 // @Failure     500 {object} Response "Server error"
 // @Security    ApiKeyAuth
@@ -705,10 +760,20 @@ func (server *Server) generateSpeakingResponse(ctx *gin.Context) {
 		return
 	}
 
+	// Oversized texts are refused or cut before they reach the AI
+	message, ok := server.checkAIText(ctx, ailimit.KindSpeaking, req.UserMessage)
+	if !ok {
+		return
+	}
+	conversation, ok := server.checkAIText(ctx, ailimit.KindConversationContext, req.ConversationContext)
+	if !ok {
+		return
+	}
+
 	// Create AI request
 	aiReq := ai.AISpeakingRequest{
-		UserMessage:         req.UserMessage,
-		ConversationContext: req.ConversationContext,
+		UserMessage:         message.Text,
+		ConversationContext: conversation.Text,
 		Difficulty:          req.Difficulty,
 	}
 
@@ -723,6 +788,7 @@ func (server *Server) generateSpeakingResponse(ctx *gin.Context) {
 	response := GenerateSpeakingResponseData{
 		Response:    aiResponse.Response,
 		ProcessedAt: aiResponse.ProcessedAt.Format(time.RFC3339),
+		Truncated:   message.Truncated || conversation.Truncated,
 	}
 
 	logger.Debug("Generated AI speaking response successfully")
@@ -6,6 +6,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/savedfilter"
 	"github.com/toeic-app/internal/token"
 )
 
@@ -55,6 +56,7 @@ type getStudySetRequest struct {
 type listStudySetsRequest struct {
 	Limit  int32 `form:"limit,default=10" binding:"min=1,max=50"`
 	Offset int32 `form:"offset,default=0" binding:"min=0"`
+	listFilterQuery
 }
 
 // NewStudySetResponse creates a StudySetResponse from database model
@@ -184,14 +186,18 @@ func (server *Server) getStudySet(ctx *gin.Context) {
 }
 
 // @Summary List user's study sets
-// @Description List study sets created by the current user
+// @Description List study sets created by the current user, optionally filtered by a saved filter and the filter fields overriding it
 // @Tags study-sets
 // @Accept json
 // @Produce json
 // @Param limit query int false "Limit" default(10)
 // @Param offset query int false "Offset" default(0)
+// @Param filter_id query int false "Saved filter ID"
+// @Param query query string false "Substring of the study set name"
+// @Param public query bool false "Public sets only, or private ones only"
 // @Success 200 {object} Response{data=[]StudySetResponse} "Study sets retrieved successfully"
-// @Failure 400 {object} Response "Invalid query parameters"
+// @Failure 400 {object} Response "Invalid query parameters or filter"
+// @Failure 404 {object} Response "Saved filter not found"
 // @Failure 401 {object} Response "Unauthorized"
 // @Failure 500 {object} Response "Failed to retrieve study sets"
 // @Security ApiKeyAuth
@@ -205,13 +211,21 @@ func (server *Server) listUserStudySets(ctx *gin.Context) {
 
 	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
 
-	arg := db.ListUserStudySetsParams{
-		UserID: authPayload.ID,
-		Limit:  req.Limit,
-		Offset: req.Offset,
+	criteria, ok := server.listFilter(ctx, savedfilter.ResourceStudySets, req.listFilterQuery)
+	if !ok {
+		return
+	}
+	var studySets []db.StudySet
+	var err error
+	if criteria.IsZero() {
+		studySets, err = server.store.ListUserStudySets(ctx, db.ListUserStudySetsParams{
+			UserID: authPayload.ID,
+			Limit:  req.Limit,
+			Offset: req.Offset,
+		})
+	} else {
+		studySets, err = server.store.ListStudySetsFiltered(ctx, criteria.StudySetParams(authPayload.ID, req.Limit, req.Offset))
 	}
-
-	studySets, err := server.store.ListUserStudySets(ctx, arg)
 	if err != nil {
 		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve study sets", err)
 		return
@@ -238,14 +252,17 @@ func (server *Server) listUserStudySets(ctx *gin.Context) {
 }
 
 // @Summary List public study sets
-// @Description List publicly available study sets
+// @Description List publicly available study sets, optionally filtered by a saved filter and the filter fields overriding it
 // @Tags study-sets
 // @Accept json
 // @Produce json
 // @Param limit query int false "Limit" default(10)
 // @Param offset query int false "Offset" default(0)
+// @Param filter_id query int false "Saved filter ID"
+// @Param query query string false "Substring of the study set name"
 // @Success 200 {object} Response{data=[]StudySetResponse} "Public study sets retrieved successfully"
-// @Failure 400 {object} Response "Invalid query parameters"
+// @Failure 400 {object} Response "Invalid query parameters or filter"
+// @Failure 404 {object} Response "Saved filter not found"
 // @Failure 500 {object} Response "Failed to retrieve public study sets"
 // @Security ApiKeyAuth
 // @Router /api/v1/study-sets/public [get]
@@ -256,12 +273,20 @@ func (server *Server) listPublicStudySets(ctx *gin.Context) {
 		return
 	}
 
-	arg := db.ListPublicStudySetsParams{
-		Limit:  req.Limit,
-		Offset: req.Offset,
+	criteria, ok := server.listFilter(ctx, savedfilter.ResourceStudySets, req.listFilterQuery)
+	if !ok {
+		return
+	}
+	var studySets []db.StudySet
+	var err error
+	if criteria.IsZero() {
+		studySets, err = server.store.ListPublicStudySets(ctx, db.ListPublicStudySetsParams{
+			Limit:  req.Limit,
+			Offset: req.Offset,
+		})
+	} else {
+		studySets, err = server.store.ListStudySetsFiltered(ctx, criteria.StudySetParams(0, req.Limit, req.Offset))
 	}
-
-	studySets, err := server.store.ListPublicStudySets(ctx, arg)
 	if err != nil {
 		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve public study sets", err)
 		return
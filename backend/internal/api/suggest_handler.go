@@ -0,0 +1,42 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/suggest"
+)
+
+type suggestRequest struct {
+	Query string `form:"q" binding:"required"`
+	Limit int    `form:"limit" binding:"omitempty,min=1,max=20"`
+}
+
+// @Summary     Search suggestions
+// @Description Completes a search query with words and published grammar titles. Matches starting with the query come first, then, for queries of three or more letters, fuzzy trigram matches that forgive typos. match is exact, prefix or fuzzy. Results are cached briefly.
+// @Tags        search
+// @Produce     json
+// @Param       q query string true "What the user has typed so far"
+// @Param       limit query int false "Suggestions of each type (1-20, default 5)"
+// @Success     200 {object} Response{data=suggest.Result} "Suggestions retrieved successfully"
+// @Failure     400 {object} Response "Missing query"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/search/suggest [get]
+func (server *Server) searchSuggestions(ctx *gin.Context) {
+	var req suggestRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+	if req.Limit == 0 {
+		req.Limit = suggest.DefaultLimit
+	}
+
+	result, err := server.suggestions.Suggest(ctx, req.Query, req.Limit)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to get suggestions", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Suggestions retrieved successfully", result)
+}
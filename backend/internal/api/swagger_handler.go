@@ -0,0 +1,348 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
+	"github.com/swaggo/swag"
+	"github.com/toeic-app/internal/ai"
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/errors"
+)
+
+// Checks of the swagger self-check
+const (
+	SwaggerCheckDocument   = "document"       // The document is valid JSON
+	SwaggerCheckReferences = "references"     // Every $ref names a definition
+	SwaggerCheckEnvelope   = "error_envelope" // Error responses use the response envelope
+	SwaggerCheckErrorCodes = "error_codes"    // The error code enum matches the error catalog
+	SwaggerCheckEnums      = "enums"          // Enums match their Go constants
+	SwaggerCheckExamples   = "examples"       // The envelope has example values
+)
+
+// swaggerEnvelope are the definitions of the response envelope, whose
+// fields must have examples
+var swaggerEnvelope = []string{"api.Response", "api.ErrorDetails", "api.Meta", "api.Pagination", "errors.CatalogEntry"}
+
+// swaggerCheck is the outcome of a check of the swagger self-check
+type swaggerCheck struct {
+	Name   string `json:"name" example:"references"`
+	Passed bool   `json:"passed" example:"true"`
+	Detail string `json:"detail,omitempty" example:"1824 references resolve"`
+}
+
+// swaggerValidationResponse is the outcome of the swagger self-check
+type swaggerValidationResponse struct {
+	Valid       bool           `json:"valid" example:"true"`
+	Operations  int            `json:"operations" example:"512"`
+	Definitions int            `json:"definitions" example:"640"`
+	Checks      []swaggerCheck `json:"checks"`
+	// Warnings are documented routes turned off by the configuration and
+	// API routes without documentation
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+func (r *swaggerValidationResponse) check(name string, problems []string, passed string) {
+	if len(problems) == 0 {
+		r.Checks = append(r.Checks, swaggerCheck{Name: name, Passed: true, Detail: passed})
+		return
+	}
+	detail := strings.Join(problems[:min(len(problems), 10)], "; ")
+	if len(problems) > 10 {
+		detail += fmt.Sprintf("; and %d more", len(problems)-10)
+	}
+	r.Checks = append(r.Checks, swaggerCheck{Name: name, Detail: detail})
+}
+
+// swaggerHandler serves the swagger UI and documents, and the self-check
+// at /swagger/validate, which the catch-all route would otherwise shadow
+func (server *Server) swaggerHandler() gin.HandlerFunc {
+	ui := ginSwagger.WrapHandler(swaggerFiles.Handler)
+	return func(ctx *gin.Context) {
+		if ctx.Param("any") == "/validate" {
+			server.validateSwagger(ctx)
+			return
+		}
+		ui(ctx)
+	}
+}
+
+// @Summary     Validate the API documentation
+// @Description Checks the swagger document the server serves: every reference resolves, error responses use the response envelope, the error code enum matches the error catalog, the exam status and TOEIC band enums match the server's values and the envelope has examples. Documented routes turned off by the configuration and undocumented API routes are reported as warnings.
+// @Tags        system
+// @Produce     json
+// @Success     200 {object} Response{data=swaggerValidationResponse} "Swagger documentation validated"
+// @Router      /swagger/validate [get]
+func (server *Server) validateSwagger(ctx *gin.Context) {
+	doc, err := swag.ReadDoc()
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Swagger documentation is not available", err)
+		return
+	}
+	// The outcome is in data.valid; the check itself succeeded either way
+	report := validateSwaggerDoc(doc, server.router.Routes())
+	SuccessResponse(ctx, http.StatusOK, "Swagger documentation validated", report)
+}
+
+// @Summary     List error codes
+// @Description Lists the codes of error.code in error responses with their HTTP status, category, severity and meaning
+// @Tags        system
+// @Produce     json
+// @Success     200 {object} Response{data=[]errors.CatalogEntry} "Error codes retrieved successfully"
+// @Router      /api/v1/errors [get]
+func (server *Server) listErrorCodes(ctx *gin.Context) {
+	SuccessResponse(ctx, http.StatusOK, "Error codes retrieved successfully", errors.Catalog())
+}
+
+// swaggerDefinitionRef matches references to definitions
+var swaggerDefinitionRef = regexp.MustCompile(`^#/definitions/(.+)$`)
+
+// validateSwaggerDoc runs the swagger self-check on a document and the
+// routes of the router
+func validateSwaggerDoc(doc string, routes gin.RoutesInfo) swaggerValidationResponse {
+	var report swaggerValidationResponse
+	var spec struct {
+		Paths       map[string]map[string]map[string]any `json:"paths"`
+		Definitions map[string]map[string]any            `json:"definitions"`
+	}
+	if err := json.Unmarshal([]byte(doc), &spec); err != nil {
+		report.check(SwaggerCheckDocument, []string{err.Error()}, "")
+		return report
+	}
+	for _, operations := range spec.Paths {
+		report.Operations += len(operations)
+	}
+	report.Definitions = len(spec.Definitions)
+	report.check(SwaggerCheckDocument, nil, fmt.Sprintf("%d operations, %d definitions", report.Operations, report.Definitions))
+
+	// References
+	var raw any
+	json.Unmarshal([]byte(doc), &raw)
+	references := 0
+	missing := make(map[string]bool)
+	walkSwagger(raw, func(key string, value any) {
+		if key != "$ref" {
+			return
+		}
+		references++
+		ref, _ := value.(string)
+		match := swaggerDefinitionRef.FindStringSubmatch(ref)
+		if match == nil || spec.Definitions[match[1]] == nil {
+			missing[ref] = true
+		}
+	})
+	report.check(SwaggerCheckReferences, sortedKeys(missing), fmt.Sprintf("%d references resolve", references))
+
+	// Error envelope
+	var envelopeProblems []string
+	response := spec.Definitions["api.Response"]
+	for _, property := range []string{"status", "error"} {
+		if swaggerProperty(response, property) == nil {
+			envelopeProblems = append(envelopeProblems, "api.Response has no "+property)
+		}
+	}
+	if swaggerRef(swaggerProperty(response, "error")) != "api.ErrorDetails" {
+		envelopeProblems = append(envelopeProblems, "api.Response.error is not api.ErrorDetails")
+	}
+	if swaggerRef(swaggerProperty(spec.Definitions["api.ErrorDetails"], "code")) != "errors.ErrorCode" {
+		envelopeProblems = append(envelopeProblems, "api.ErrorDetails.code is not errors.ErrorCode")
+	}
+	errorResponses := 0
+	for path, operations := range spec.Paths {
+		for method, operation := range operations {
+			responses, _ := operation["responses"].(map[string]any)
+			for status, documented := range responses {
+				if code, err := strconv.Atoi(status); err != nil || code < 400 {
+					continue
+				}
+				errorResponses++
+				schema, _ := documented.(map[string]any)["schema"].(map[string]any)
+				if swaggerRef(schema) != "api.Response" {
+					envelopeProblems = append(envelopeProblems, fmt.Sprintf("%s %s %s", strings.ToUpper(method), path, status))
+				}
+			}
+		}
+	}
+	sort.Strings(envelopeProblems)
+	report.check(SwaggerCheckEnvelope, envelopeProblems, fmt.Sprintf("%d error responses use the envelope", errorResponses))
+
+	// Error codes
+	codes := make([]string, 0, len(errors.Codes()))
+	for _, code := range errors.Codes() {
+		codes = append(codes, string(code))
+	}
+	report.check(SwaggerCheckErrorCodes, compareEnum(spec.Definitions, "errors.ErrorCode", codes),
+		fmt.Sprintf("%d error codes documented", len(codes)))
+
+	// Enums
+	statuses := []string{string(db.ExamStatusEnumInProgress), string(db.ExamStatusEnumCompleted), string(db.ExamStatusEnumAbandoned)}
+	var bands []string
+	for _, band := range []ai.TOEICBand{ai.BandLevel1, ai.BandLevel2, ai.BandLevel3, ai.BandLevel4, ai.BandLevel5,
+		ai.BandLevel6, ai.BandLevel7, ai.BandLevel8, ai.BandLevel9, ai.BandLevel10} {
+		bands = append(bands, string(band))
+	}
+	report.check(SwaggerCheckEnums, append(compareEnum(spec.Definitions, "db.ExamStatusEnum", statuses),
+		compareEnum(spec.Definitions, "ai.TOEICBand", bands)...), "exam statuses and TOEIC bands match")
+
+	// Examples
+	var withoutExample []string
+	for _, name := range swaggerEnvelope {
+		properties, _ := spec.Definitions[name]["properties"].(map[string]any)
+		for property, schema := range properties {
+			schema, _ := schema.(map[string]any)
+			switch schema["type"] {
+			case "string", "integer", "number", "boolean":
+				if _, ok := schema["example"]; !ok {
+					withoutExample = append(withoutExample, name+"."+property)
+				}
+			}
+		}
+	}
+	sort.Strings(withoutExample)
+	report.check(SwaggerCheckExamples, withoutExample, "the envelope has examples")
+
+	// Routes
+	registered := newRouteSet(routes)
+	documented := make(map[string]bool, report.Operations)
+	for path, operations := range spec.Paths {
+		for method := range operations {
+			documented[routeKey(strings.ToUpper(method), path)] = true
+			if !registered.has(strings.ToUpper(method), path) {
+				report.Warnings = append(report.Warnings, fmt.Sprintf("%s %s is documented but turned off", strings.ToUpper(method), path))
+			}
+		}
+	}
+	for _, route := range routes {
+		if strings.HasPrefix(route.Path, "/api/") && !documented[routeKey(route.Method, route.Path)] {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("%s %s is not documented", route.Method, route.Path))
+		}
+	}
+	sort.Strings(report.Warnings)
+
+	report.Valid = true
+	for _, check := range report.Checks {
+		report.Valid = report.Valid && check.Passed
+	}
+	return report
+}
+
+var (
+	swaggerParam = regexp.MustCompile(`\{[^/}]+\}`)
+	ginParam     = regexp.MustCompile(`[:*][^/]+`)
+)
+
+// routeKey names a route independently of its parameter names
+func routeKey(method, path string) string {
+	path = swaggerParam.ReplaceAllString(path, "{}")
+	return method + " " + ginParam.ReplaceAllString(path, "{}")
+}
+
+// routeSet tells which routes a router serves. Catch-all routes serve
+// every path below them, like /swagger/validate.
+type routeSet struct {
+	keys     map[string]bool
+	prefixes []string
+}
+
+func newRouteSet(routes gin.RoutesInfo) routeSet {
+	set := routeSet{keys: make(map[string]bool, len(routes))}
+	for _, route := range routes {
+		set.keys[routeKey(route.Method, route.Path)] = true
+		if i := strings.Index(route.Path, "/*"); i >= 0 {
+			set.prefixes = append(set.prefixes, routeKey(route.Method, route.Path[:i+1]))
+		}
+	}
+	return set
+}
+
+// has reports whether a route, with swagger or gin parameters, is served
+func (s routeSet) has(method, path string) bool {
+	key := routeKey(method, path)
+	if s.keys[key] {
+		return true
+	}
+	for _, prefix := range s.prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// walkSwagger calls visit with every key and value of a decoded document
+func walkSwagger(value any, visit func(key string, value any)) {
+	switch value := value.(type) {
+	case map[string]any:
+		for key, child := range value {
+			visit(key, child)
+			walkSwagger(child, visit)
+		}
+	case []any:
+		for _, child := range value {
+			walkSwagger(child, visit)
+		}
+	}
+}
+
+// swaggerProperty returns a property of a definition
+func swaggerProperty(definition map[string]any, name string) map[string]any {
+	properties, _ := definition["properties"].(map[string]any)
+	property, _ := properties[name].(map[string]any)
+	return property
+}
+
+// swaggerRef returns the definition a schema refers to, directly or
+// through allOf as swag writes references with a description
+func swaggerRef(schema map[string]any) string {
+	if ref, ok := schema["$ref"].(string); ok {
+		return strings.TrimPrefix(ref, "#/definitions/")
+	}
+	if allOf, ok := schema["allOf"].([]any); ok && len(allOf) > 0 {
+		first, _ := allOf[0].(map[string]any)
+		return swaggerRef(first)
+	}
+	return ""
+}
+
+// compareEnum lists the values a definition's enum lacks or has in excess
+func compareEnum(definitions map[string]map[string]any, name string, want []string) []string {
+	definition, ok := definitions[name]
+	if !ok {
+		return []string{name + " is not documented"}
+	}
+	var documented []string
+	values, _ := definition["enum"].([]any)
+	for _, value := range values {
+		documented = append(documented, fmt.Sprint(value))
+	}
+	var problems []string
+	for _, value := range want {
+		if !slices.Contains(documented, value) {
+			problems = append(problems, fmt.Sprintf("%s lacks %s", name, value))
+		}
+	}
+	for _, value := range documented {
+		if !slices.Contains(want, value) {
+			problems = append(problems, fmt.Sprintf("%s has unknown %s", name, value))
+		}
+	}
+	return problems
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"net/http"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/buildinfo"
+	"github.com/toeic-app/internal/security"
+)
+
+// SystemInfoResponse describes the running instance for debugging across environments
+type SystemInfoResponse struct {
+	Build      buildinfo.Info         `json:"build"`
+	Runtime    SystemRuntimeInfo      `json:"runtime"`
+	Subsystems SystemSubsystemsInfo   `json:"subsystems"`
+	Config     map[string]interface{} `json:"config"`
+}
+
+// SystemRuntimeInfo holds process level details
+type SystemRuntimeInfo struct {
+	Hostname   string `json:"hostname"`
+	InstanceID string `json:"instance_id"`
+	IsLeader   bool   `json:"is_leader"`
+	GinMode    string `json:"gin_mode"`
+	StartedAt  string `json:"started_at"`
+	Uptime     string `json:"uptime"`
+	Goroutines int    `json:"goroutines"`
+	NumCPU     int    `json:"num_cpu"`
+}
+
+// SystemSubsystemsInfo lists which optional subsystems are active
+type SystemSubsystemsInfo struct {
+	Cache           string   `json:"cache"`
+	HTTPCache       bool     `json:"http_cache"`
+	RateLimiting    bool     `json:"rate_limiting"`
+	Concurrency     bool     `json:"concurrency"`
+	AIProvider      string   `json:"ai_provider"`
+	AIModel         string   `json:"ai_model,omitempty"`
+	GoogleAI        bool     `json:"google_ai"`
+	AnalyzeService  bool     `json:"analyze_service"`
+	TLS             string   `json:"tls"`
+	HTTP2           bool     `json:"http2"`
+	LeaderElection  string   `json:"leader_election"`
+	Monitoring      bool     `json:"monitoring"`
+	StaticFeatures  []string `json:"static_features"`
+	SecurityHeaders bool     `json:"security_headers"`
+
+	SecurityEvents *security.EventStreamStats `json:"security_events,omitempty"`
+}
+
+func (server *Server) subsystemsInfo() SystemSubsystemsInfo {
+	cfg := server.configReloader.Current()
+
+	cacheType := "disabled"
+	if cfg.CacheEnabled && server.cache != nil {
+		cacheType = cfg.CacheType
+	}
+
+	aiProvider := "none"
+	if cfg.OpenAIAPIKey != "" {
+		aiProvider = "openai"
+	}
+
+	tlsMode := "disabled"
+	if cfg.TLSEnabled {
+		tlsMode = "certificate"
+		if cfg.TLSAutocertEnabled {
+			tlsMode = "autocert"
+		}
+	}
+
+	leaderElection := "disabled"
+	if cfg.LeaderElectionEnabled {
+		leaderElection = cfg.LeaderElectionBackend
+	}
+
+	info := SystemSubsystemsInfo{
+		Cache:           cacheType,
+		HTTPCache:       cfg.HTTPCacheEnabled && server.httpCache != nil,
+		RateLimiting:    cfg.RateLimitEnabled,
+		Concurrency:     cfg.ConcurrencyEnabled,
+		AIProvider:      aiProvider,
+		AIModel:         cfg.OpenAIModel,
+		GoogleAI:        cfg.GoogleAIAPIKey != "",
+		AnalyzeService:  cfg.AnalyzeServiceEnabled,
+		TLS:             tlsMode,
+		HTTP2:           cfg.HTTP2Enabled,
+		LeaderElection:  leaderElection,
+		Monitoring:      server.monitoringService != nil,
+		StaticFeatures:  cfg.EnabledFeatures(),
+		SecurityHeaders: cfg.SecurityHeadersEnabled,
+	}
+	if server.securityEvents != nil {
+		stats := server.securityEvents.Stats()
+		info.SecurityEvents = &stats
+	}
+	return info
+}
+
+// @Summary Get system information
+// @Description Build version, git SHA, redacted configuration, enabled subsystems and dependency versions of this instance
+// @Tags admin
+// @Produce json
+// @Success 200 {object} Response{data=SystemInfoResponse} "System information retrieved successfully"
+// @Failure 401 {object} Response "Unauthorized"
+// @Failure 403 {object} Response "Forbidden"
+// @Security ApiKeyAuth
+// @Router /api/v1/admin/system/info [get]
+func (server *Server) getSystemInfo(ctx *gin.Context) {
+	hostname, _ := os.Hostname()
+	leaderStatus := server.leaderElector.Status(ctx)
+
+	response := SystemInfoResponse{
+		Build: buildinfo.Get(),
+		Runtime: SystemRuntimeInfo{
+			Hostname:   hostname,
+			InstanceID: leaderStatus.InstanceID,
+			IsLeader:   leaderStatus.IsLeader,
+			GinMode:    gin.Mode(),
+			StartedAt:  startTime.Format(time.RFC3339),
+			Uptime:     time.Since(startTime).Round(time.Second).String(),
+			Goroutines: runtime.NumGoroutine(),
+			NumCPU:     runtime.NumCPU(),
+		},
+		Subsystems: server.subsystemsInfo(),
+		Config:     server.configReloader.Current().Redacted(),
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "System information retrieved successfully", response)
+}
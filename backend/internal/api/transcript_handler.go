@@ -0,0 +1,177 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/token"
+	"github.com/toeic-app/internal/transcript"
+)
+
+type questionTranscriptRequest struct {
+	QuestionID int32 `uri:"id" binding:"required,min=1"`
+}
+
+type setQuestionTranscriptRequest struct {
+	Transcript string `json:"transcript" binding:"required,max=20000" example:"Woman: Has the shipment arrived yet? Man: It's due this afternoon."`
+}
+
+type revealTranscriptRequest struct {
+	AttemptID  int32 `uri:"id" binding:"required,min=1"`
+	QuestionID int32 `uri:"question_id" binding:"required,min=1"`
+}
+
+// @Summary     Get a question transcript
+// @Description Returns the transcript of a listening question for editing. Learners reveal transcripts through their exam attempts instead. Requires exams.update.
+// @Tags        questions
+// @Produce     json
+// @Param       id path int true "Question ID"
+// @Success     200 {object} Response{data=transcript.Transcript} "Transcript retrieved successfully"
+// @Failure     400 {object} Response "Invalid question ID"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     403 {object} Response "Permission denied"
+// @Failure     404 {object} Response "Question has no transcript"
+// @Failure     500 {object} Response "Failed to retrieve transcript"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/questions/{id}/transcript [get]
+func (server *Server) getQuestionTranscript(ctx *gin.Context) {
+	var uri questionTranscriptRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "invalid_question_id", err)
+		return
+	}
+
+	stored, err := server.transcripts.Get(ctx, uri.QuestionID)
+	if err != nil {
+		transcriptError(ctx, err, "failed_to_retrieve_transcript")
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "transcript_retrieved_successfully", stored)
+}
+
+// @Summary     Set a question transcript
+// @Description Stores the transcript of the audio of a listening question, replacing the one it had. Questions without audio have no transcript. Learners can reveal it in exams with transcripts_enabled. Requires exams.update.
+// @Tags        questions
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Question ID"
+// @Param       request body setQuestionTranscriptRequest true "Transcript, at most 20000 characters"
+// @Success     200 {object} Response{data=transcript.Transcript} "Transcript saved successfully"
+// @Failure     400 {object} Response "Invalid request, or the question has no audio"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     403 {object} Response "Permission denied"
+// @Failure     404 {object} Response "Question not found"
+// @Failure     500 {object} Response "Failed to save transcript"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/questions/{id}/transcript [put]
+func (server *Server) setQuestionTranscript(ctx *gin.Context) {
+	var uri questionTranscriptRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "invalid_question_id", err)
+		return
+	}
+	var req setQuestionTranscriptRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "invalid_request_body", err)
+		return
+	}
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	stored, err := server.transcripts.Set(ctx, uri.QuestionID, authPayload.ID, req.Transcript)
+	if err != nil {
+		transcriptError(ctx, err, "failed_to_save_transcript")
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "transcript_saved_successfully", stored)
+}
+
+// @Summary     Delete a question transcript
+// @Description Removes the transcript of a listening question. Requires exams.update.
+// @Tags        questions
+// @Produce     json
+// @Param       id path int true "Question ID"
+// @Success     200 {object} Response "Transcript deleted successfully"
+// @Failure     400 {object} Response "Invalid question ID"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     403 {object} Response "Permission denied"
+// @Failure     404 {object} Response "Question has no transcript"
+// @Failure     500 {object} Response "Failed to delete transcript"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/questions/{id}/transcript [delete]
+func (server *Server) deleteQuestionTranscript(ctx *gin.Context) {
+	var uri questionTranscriptRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "invalid_question_id", err)
+		return
+	}
+
+	if err := server.transcripts.Delete(ctx, uri.QuestionID); err != nil {
+		transcriptError(ctx, err, "failed_to_delete_transcript")
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "transcript_deleted_successfully", nil)
+}
+
+// @Summary     Reveal a listening transcript
+// @Description Returns the transcript of a listening question in one of your exam attempts, if its exam has transcripts_enabled. In part practice (mode practice) it is revealed once the question is answered in the attempt, after which the answer cannot be updated or deleted; in a full test (mode test) once the attempt is completed.
+// @Tags        exam-attempts
+// @Produce     json
+// @Param       id path int true "Exam Attempt ID"
+// @Param       question_id path int true "Question ID"
+// @Success     200 {object} Response{data=transcript.Transcript} "Transcript revealed successfully"
+// @Failure     400 {object} Response "Invalid attempt or question ID"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     403 {object} Response "Transcript not revealed yet: transcript_locked_until_answered or transcript_locked_until_completed"
+// @Failure     404 {object} Response "Exam attempt, question or transcript not found, or the exam offers no transcripts"
+// @Failure     500 {object} Response "Failed to reveal transcript"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/exam-attempts/{id}/questions/{question_id}/transcript [get]
+func (server *Server) revealTranscript(ctx *gin.Context) {
+	var uri revealTranscriptRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "invalid_request_parameters", err)
+		return
+	}
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	attempt, err := server.exams.OwnedAttempt(ctx, authPayload.ID, uri.AttemptID)
+	if err != nil {
+		examServiceError(ctx, err, "failed_to_retrieve_exam_attempt")
+		return
+	}
+
+	revealed, err := server.transcripts.Reveal(ctx, attempt, uri.QuestionID)
+	if err != nil {
+		transcriptError(ctx, err, "failed_to_reveal_transcript")
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "transcript_revealed_successfully", revealed)
+}
+
+// transcriptError responds to an error of the transcript service, with
+// message when the error is unexpected
+func transcriptError(ctx *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, transcript.ErrQuestionNotFound):
+		ErrorResponse(ctx, http.StatusNotFound, "question_not_found", err)
+	case errors.Is(err, transcript.ErrNoTranscript):
+		ErrorResponse(ctx, http.StatusNotFound, "transcript_not_found", err)
+	case errors.Is(err, transcript.ErrDisabled):
+		ErrorResponse(ctx, http.StatusNotFound, "transcripts_disabled", err)
+	case errors.Is(err, transcript.ErrNotListening):
+		ErrorResponse(ctx, http.StatusBadRequest, "question_has_no_audio", err)
+	case errors.Is(err, transcript.ErrEmpty):
+		ErrorResponse(ctx, http.StatusBadRequest, "transcript_is_empty", err)
+	case errors.Is(err, transcript.ErrNotAnswered):
+		ErrorResponse(ctx, http.StatusForbidden, "transcript_locked_until_answered", err)
+	case errors.Is(err, transcript.ErrNotCompleted):
+		ErrorResponse(ctx, http.StatusForbidden, "transcript_locked_until_completed", err)
+	default:
+		ErrorResponse(ctx, http.StatusInternalServerError, message, err)
+	}
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/tts"
+)
+
+// textToSpeechRequest selects the phrase and voice to synthesize
+type textToSpeechRequest struct {
+	Text  string `form:"text" binding:"required"`
+	Voice string `form:"voice"`
+}
+
+// @Summary     Text to speech
+// @Description Returns a signed URL of the text spoken in the given voice. Each phrase is synthesized once and then served from media storage. Used for example sentences and speaking scenario prompts.
+// @Tags        tts
+// @Produce     json
+// @Param       text query string true "Phrase to speak"
+// @Param       voice query string false "Voice (defaults to TTS_DEFAULT_VOICE)"
+// @Success     200 {object} Response{data=tts.Clip} "Speech retrieved successfully"
+// @Failure     400 {object} Response "Invalid text or voice"
+// @Failure     502 {object} Response "Speech synthesis failed"
+// @Failure     503 {object} Response "Text-to-speech is disabled"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/tts [get]
+func (server *Server) textToSpeech(ctx *gin.Context) {
+	if server.tts == nil {
+		ErrorResponse(ctx, http.StatusServiceUnavailable, "Text-to-speech is disabled", nil)
+		return
+	}
+
+	var req textToSpeechRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	clip, err := server.tts.Speak(ctx, req.Text, req.Voice)
+	if err != nil {
+		switch {
+		case errors.Is(err, tts.ErrEmptyText), errors.Is(err, tts.ErrTextTooLong):
+			ErrorResponse(ctx, http.StatusBadRequest, "Invalid text", err)
+		case errors.Is(err, tts.ErrInvalidVoice):
+			ErrorResponse(ctx, http.StatusBadRequest, "Unsupported voice", err)
+		default:
+			ErrorResponse(ctx, http.StatusBadGateway, "Speech synthesis failed", err)
+		}
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Speech retrieved successfully", clip)
+}
@@ -228,7 +228,7 @@ func (server *Server) unsubscribeFromUpgrades(ctx *gin.Context) {
 // @Failure 401 {object} Response "Unauthorized"
 // @Failure 403 {object} Response "Forbidden - Admin access required"
 // @Security ApiKeyAuth
-// @Router /api/v1/upgrade/stats [get]
+// @Router /api/v1/admin/upgrade/stats [get]
 func (server *Server) getUpgradeStats(ctx *gin.Context) {
 	stats := server.upgradeService.GetStats()
 	SuccessResponse(ctx, http.StatusOK, "Upgrade statistics retrieved", stats)
@@ -245,7 +245,7 @@ func (server *Server) getUpgradeStats(ctx *gin.Context) {
 // @Failure 401 {object} Response "Unauthorized"
 // @Failure 403 {object} Response "Forbidden - Admin access required"
 // @Security ApiKeyAuth
-// @Router /api/v1/upgrade/versions [post]
+// @Router /api/v1/admin/upgrade/versions [post]
 func (server *Server) addVersion(ctx *gin.Context) {
 	var req addVersionRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
@@ -275,7 +275,7 @@ func (server *Server) addVersion(ctx *gin.Context) {
 }
 
 // @Summary Send upgrade notification (Admin only)
-// @Description Send upgrade notification to users
+// @Description Send upgrade notification to connected users. To target registered devices by platform, installed version, segment or percentage, use /api/v1/admin/upgrade/rollouts instead
 // @Tags upgrade
 // @Accept json
 // @Produce json
@@ -286,7 +286,7 @@ func (server *Server) addVersion(ctx *gin.Context) {
 // @Failure 403 {object} Response "Forbidden - Admin access required"
 // @Failure 404 {object} Response "Version not found"
 // @Security ApiKeyAuth
-// @Router /api/v1/upgrade/notify [post]
+// @Router /api/v1/admin/upgrade/notify [post]
 func (server *Server) notifyUpgrade(ctx *gin.Context) {
 	var req notifyUpgradeRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
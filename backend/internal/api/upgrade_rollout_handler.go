@@ -0,0 +1,244 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/token"
+	"github.com/toeic-app/internal/upgrade"
+	"github.com/toeic-app/internal/websocket"
+)
+
+// registerUpgradeDeviceRequest identifies the device and its installed version
+type registerUpgradeDeviceRequest struct {
+	DeviceID   string `json:"device_id" binding:"required,max=128" example:"6f1c2a4e-android"`
+	Platform   string `json:"platform" binding:"required,max=20" example:"android"`
+	AppVersion string `json:"app_version" binding:"required,max=32" example:"1.1.0"`
+}
+
+// registerUpgradeDeviceResponse is the registered device with the rollouts
+// it missed while offline
+type registerUpgradeDeviceResponse struct {
+	Device        *upgrade.Device                 `json:"device"`
+	Notifications []websocket.UpgradeNotification `json:"notifications"`
+}
+
+// acknowledgeUpgradeRolloutRequest names the device that showed the rollout
+type acknowledgeUpgradeRolloutRequest struct {
+	DeviceID string `json:"device_id" binding:"required,max=128" example:"6f1c2a4e-android"`
+}
+
+// createUpgradeRolloutRequest sends a version to the devices matching the
+// targeting rules
+type createUpgradeRolloutRequest struct {
+	Version       string   `json:"version" binding:"required" example:"1.2.0"`
+	Platforms     []string `json:"platforms" example:"android,ios"`
+	MinAppVersion string   `json:"min_app_version" example:"1.0.0"`
+	MaxAppVersion string   `json:"max_app_version" example:"1.1.9"`
+	Segments      []string `json:"segments" example:"premium"`
+	Percentage    *int32   `json:"percentage" binding:"omitempty,min=0,max=100" example:"25"`
+}
+
+type listUpgradeRolloutsQuery struct {
+	Limit  int32 `form:"limit,default=20" binding:"min=1,max=100"`
+	Offset int32 `form:"offset" binding:"min=0"`
+}
+
+type listUpgradeDeliveriesQuery struct {
+	Status string `form:"status" binding:"omitempty,oneof=pending delivered acknowledged"`
+	Limit  int32  `form:"limit,default=20" binding:"min=1,max=100"`
+	Offset int32  `form:"offset" binding:"min=0"`
+}
+
+// @Summary     Register a device for upgrade notifications
+// @Description Records the platform and installed version of one of my devices so targeted rollouts can reach it. Clients call this on start; the response includes the rollouts the device missed while offline, which are then counted as delivered.
+// @Tags        upgrade
+// @Accept      json
+// @Produce     json
+// @Param       device body registerUpgradeDeviceRequest true "Device and installed version"
+// @Success     200 {object} Response{data=registerUpgradeDeviceResponse} "Device registered successfully"
+// @Failure     400 {object} Response "Invalid request parameters"
+// @Failure     401 {object} Response "Unauthorized"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/upgrade/devices [post]
+func (server *Server) registerUpgradeDevice(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	var req registerUpgradeDeviceRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request parameters", err)
+		return
+	}
+
+	device, notifications, err := server.upgradeRollouts.RegisterDevice(ctx, authPayload.ID, req.DeviceID, req.Platform, req.AppVersion)
+	if err != nil {
+		if errors.Is(err, upgrade.ErrInvalidRules) {
+			ErrorResponse(ctx, http.StatusBadRequest, err.Error(), err)
+			return
+		}
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to register device", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Device registered successfully", registerUpgradeDeviceResponse{
+		Device:        device,
+		Notifications: notifications,
+	})
+}
+
+// @Summary     Acknowledge an upgrade rollout
+// @Description Records that one of my devices showed the upgrade notification of a rollout. Acknowledging again is harmless.
+// @Tags        upgrade
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Rollout ID"
+// @Param       ack body acknowledgeUpgradeRolloutRequest true "Device that showed the notification"
+// @Success     200 {object} Response "Upgrade rollout acknowledged successfully"
+// @Failure     400 {object} Response "Invalid request parameters"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     404 {object} Response "Rollout was not sent to this device"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/upgrade/rollouts/{id}/ack [post]
+func (server *Server) acknowledgeUpgradeRollout(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid rollout ID", err)
+		return
+	}
+
+	var req acknowledgeUpgradeRolloutRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request parameters", err)
+		return
+	}
+
+	if err := server.upgradeRollouts.Acknowledge(ctx, authPayload.ID, int32(id), req.DeviceID); err != nil {
+		if errors.Is(err, upgrade.ErrDeliveryNotFound) {
+			ErrorResponse(ctx, http.StatusNotFound, "Rollout was not sent to this device", err)
+			return
+		}
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to acknowledge upgrade rollout", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Upgrade rollout acknowledged successfully", nil)
+}
+
+// @Summary     Send a targeted upgrade rollout (admin only)
+// @Description Notifies the registered devices matching the targeting rules of a version: platforms, an inclusive range of installed versions, segments (role names of the device's user) and a percentage of the matching devices. Omitted rules match every device and the percentage defaults to 100. Devices already on the version are skipped and offline devices receive the rollout when they next register. Requires system.manage.
+// @Tags        upgrade
+// @Accept      json
+// @Produce     json
+// @Param       rollout body createUpgradeRolloutRequest true "Version and targeting rules"
+// @Success     201 {object} Response{data=upgrade.Rollout} "Upgrade rollout sent successfully"
+// @Failure     400 {object} Response "Invalid request parameters"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     403 {object} Response "Permission denied"
+// @Failure     404 {object} Response "Version not found"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/upgrade/rollouts [post]
+func (server *Server) createUpgradeRollout(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	var req createUpgradeRolloutRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request parameters", err)
+		return
+	}
+
+	rules := upgrade.Rules{
+		Platforms:     req.Platforms,
+		MinAppVersion: req.MinAppVersion,
+		MaxAppVersion: req.MaxAppVersion,
+		Segments:      req.Segments,
+		Percentage:    100,
+	}
+	if req.Percentage != nil {
+		rules.Percentage = *req.Percentage
+	}
+
+	rollout, err := server.upgradeRollouts.Send(ctx, req.Version, rules, authPayload.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, upgrade.ErrVersionNotFound):
+			ErrorResponse(ctx, http.StatusNotFound, "Version not found", err)
+		case errors.Is(err, upgrade.ErrInvalidRules):
+			ErrorResponse(ctx, http.StatusBadRequest, err.Error(), err)
+		default:
+			ErrorResponse(ctx, http.StatusInternalServerError, "Failed to send upgrade rollout", err)
+		}
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusCreated, "Upgrade rollout sent successfully", rollout)
+}
+
+// @Summary     List upgrade rollouts (admin only)
+// @Description Lists targeted rollouts, newest first, with how many devices each targeted, reached and had acknowledge it. Requires system.manage.
+// @Tags        upgrade
+// @Produce     json
+// @Param       limit query int false "Maximum rollouts" default(20)
+// @Param       offset query int false "Rollouts to skip" default(0)
+// @Success     200 {object} Response{data=[]upgrade.Rollout} "Upgrade rollouts retrieved successfully"
+// @Failure     400 {object} Response "Invalid query parameters"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     403 {object} Response "Permission denied"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/upgrade/rollouts [get]
+func (server *Server) listUpgradeRollouts(ctx *gin.Context) {
+	var query listUpgradeRolloutsQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	rollouts, total, err := server.upgradeRollouts.List(ctx, query.Limit, query.Offset)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to list upgrade rollouts", err)
+		return
+	}
+
+	PaginatedResponse(ctx, http.StatusOK, "Upgrade rollouts retrieved successfully", rollouts,
+		NewPagination(query.Limit, query.Offset, len(rollouts)).WithTotal(total))
+}
+
+// @Summary     List deliveries of an upgrade rollout (admin only)
+// @Description Lists the devices a rollout targeted with their delivery and acknowledgement times, optionally of one status. Requires system.manage.
+// @Tags        upgrade
+// @Produce     json
+// @Param       id path int true "Rollout ID"
+// @Param       status query string false "Delivery status" Enums(pending, delivered, acknowledged)
+// @Param       limit query int false "Maximum deliveries" default(20)
+// @Param       offset query int false "Deliveries to skip" default(0)
+// @Success     200 {object} Response{data=[]upgrade.Delivery} "Upgrade deliveries retrieved successfully"
+// @Failure     400 {object} Response "Invalid query parameters"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     403 {object} Response "Permission denied"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/upgrade/rollouts/{id}/deliveries [get]
+func (server *Server) listUpgradeRolloutDeliveries(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid rollout ID", err)
+		return
+	}
+
+	var query listUpgradeDeliveriesQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	deliveries, total, err := server.upgradeRollouts.Deliveries(ctx, int32(id), query.Status, query.Limit, query.Offset)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to list upgrade deliveries", err)
+		return
+	}
+
+	PaginatedResponse(ctx, http.StatusOK, "Upgrade deliveries retrieved successfully", deliveries,
+		NewPagination(query.Limit, query.Offset, len(deliveries)).WithTotal(total))
+}
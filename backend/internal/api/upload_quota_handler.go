@@ -0,0 +1,137 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	configPkg "github.com/toeic-app/internal/config"
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/logger"
+	"github.com/toeic-app/internal/token"
+	"github.com/toeic-app/internal/uploader"
+)
+
+// newUploadQuota creates the upload quota service, counting uploads in
+// Redis when UPLOAD_QUOTA_BACKEND is redis
+func newUploadQuota(config configPkg.Config, store db.Querier) *uploader.QuotaService {
+	quota := uploader.NewQuotaService(store, config.UploadDailyQuotaBytes, config.UploadDailyQuotaFiles)
+	if config.UploadQuotaBackend == "redis" && quota.Enabled() {
+		quota.UseRedis(redis.NewClient(&redis.Options{
+			Addr:         config.RedisAddr,
+			Password:     config.RedisPassword,
+			DB:           config.RedisDB,
+			DialTimeout:  time.Second,
+			ReadTimeout:  200 * time.Millisecond,
+			WriteTimeout: 200 * time.Millisecond,
+		}), config.UploadQuotaKeyPrefix)
+		logger.Info("Upload quotas counted in Redis at %s", config.RedisAddr)
+	}
+	return quota
+}
+
+// setUploadQuotaHeaders exposes the limits, the remaining quota and when it
+// resets, as a Unix timestamp, to clients
+func setUploadQuotaHeaders(ctx *gin.Context, usage uploader.QuotaUsage) {
+	if usage.BytesLimit > 0 {
+		ctx.Header("X-Upload-Quota-Bytes-Limit", strconv.FormatInt(usage.BytesLimit, 10))
+		ctx.Header("X-Upload-Quota-Bytes-Remaining", strconv.FormatInt(usage.BytesRemaining, 10))
+	}
+	if usage.FilesLimit > 0 {
+		ctx.Header("X-Upload-Quota-Files-Limit", strconv.FormatInt(usage.FilesLimit, 10))
+		ctx.Header("X-Upload-Quota-Files-Remaining", strconv.FormatInt(usage.FilesRemaining, 10))
+	}
+	ctx.Header("X-Upload-Quota-Reset", strconv.FormatInt(usage.ResetsAt.Unix(), 10))
+}
+
+// reserveUpload holds the authenticated user's quota for an upload of size
+// bytes. It writes the error response and returns false when the upload
+// must be rejected. The reservation is committed with commitUpload once
+// the file is stored, or released with releaseUpload when storing fails.
+func (server *Server) reserveUpload(ctx *gin.Context, size int64) (uploader.Reservation, bool) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+	if !server.uploadQuota.Enabled() {
+		return uploader.Reservation{UserID: authPayload.ID, Size: size}, true
+	}
+
+	reservation, err := server.uploadQuota.Reserve(ctx, authPayload.ID, size)
+	if errors.Is(err, uploader.ErrQuotaExceeded) {
+		usage := reservation.Usage
+		logger.Warn("User %d exceeded daily upload quota (%d bytes, %d files used)",
+			authPayload.ID, usage.BytesUsed, usage.FilesCount)
+		server.uploadQuotaExceeded(ctx, usage, size)
+		return reservation, false
+	}
+	if err != nil {
+		logger.Error("Failed to check upload quota: %v", err)
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to check upload quota", err)
+		return reservation, false
+	}
+
+	setUploadQuotaHeaders(ctx, reservation.Usage)
+	return reservation, true
+}
+
+// uploadQuotaExceeded answers an upload of size bytes over the quota with
+// 429, telling which limit was reached and when the quota resets
+func (server *Server) uploadQuotaExceeded(ctx *gin.Context, usage uploader.QuotaUsage, size int64) {
+	retryAfter := max(int(time.Until(usage.ResetsAt).Seconds()), 1)
+	setUploadQuotaHeaders(ctx, usage)
+	ctx.Header("Retry-After", strconv.Itoa(retryAfter))
+
+	var exceeded string
+	if usage.FilesLimit > 0 && usage.FilesCount+1 > usage.FilesLimit {
+		exceeded = fmt.Sprintf("limit of %d files a day reached", usage.FilesLimit)
+	} else {
+		exceeded = fmt.Sprintf("%d bytes left of %d bytes a day, file is %d bytes", usage.BytesRemaining, usage.BytesLimit, size)
+	}
+	ErrorResponse(ctx, http.StatusTooManyRequests, "Daily upload quota exceeded",
+		fmt.Errorf("%s, quota resets at %s", exceeded, usage.ResetsAt.Format(time.RFC3339)))
+}
+
+// commitUpload records a stored upload in the user's daily usage
+func (server *Server) commitUpload(ctx *gin.Context, reservation uploader.Reservation) {
+	if !server.uploadQuota.Enabled() {
+		return
+	}
+
+	usage, err := server.uploadQuota.Commit(ctx, reservation)
+	if err != nil {
+		// The file is already uploaded, do not fail the request
+		logger.Warn("Failed to record upload for user %d: %v", reservation.UserID, err)
+		return
+	}
+	setUploadQuotaHeaders(ctx, usage)
+}
+
+// releaseUpload gives back the quota of an upload that failed
+func (server *Server) releaseUpload(ctx *gin.Context, reservation uploader.Reservation) {
+	if server.uploadQuota.Enabled() {
+		server.uploadQuota.Release(ctx, reservation)
+	}
+}
+
+// @Summary     Get upload quota
+// @Description Get the current user's upload usage and remaining quota for today (UTC). A limit of 0 means unlimited.
+// @Tags        Uploads
+// @Produce     json
+// @Success     200 {object} Response{data=uploader.QuotaUsage} "Upload quota retrieved successfully"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     500 {object} Response "Failed to get upload quota"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/uploads/quota [get]
+func (server *Server) getUploadQuota(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	usage, err := server.uploadQuota.Usage(ctx, authPayload.ID)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to get upload quota", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Upload quota retrieved successfully", usage)
+}
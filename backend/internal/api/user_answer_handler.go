@@ -1,17 +1,20 @@
 package api
 
 import (
-	"database/sql"
-	"encoding/json"
-	"fmt"
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/accesslog"
+	"github.com/toeic-app/internal/choices"
 	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/examservice"
+	"github.com/toeic-app/internal/integrity"
 	"github.com/toeic-app/internal/logger"
 	"github.com/toeic-app/internal/token"
+	"github.com/toeic-app/internal/wordlink"
 )
 
 // UserAnswerResponse defines the structure for user answer information returned to clients
@@ -19,7 +22,8 @@ type UserAnswerResponse struct {
 	UserAnswerID   int32      `json:"user_answer_id"`
 	AttemptID      int32      `json:"attempt_id"`
 	QuestionID     int32      `json:"question_id"`
-	SelectedAnswer string     `json:"selected_answer"`
+	SelectedKey    string     `json:"selected_key" example:"B"`
+	SelectedAnswer string     `json:"selected_answer"` // Text of the selected option
 	IsCorrect      bool       `json:"is_correct"`
 	AnswerTime     *time.Time `json:"answer_time,omitempty"`
 	CreatedAt      time.Time  `json:"created_at"`
@@ -28,10 +32,15 @@ type UserAnswerResponse struct {
 // UserAnswerWithQuestionResponse includes question details
 type UserAnswerWithQuestionResponse struct {
 	UserAnswerResponse
-	QuestionTitle   string   `json:"question_title,omitempty"`
-	TrueAnswer      string   `json:"true_answer,omitempty"`
-	Explanation     string   `json:"explanation,omitempty"`
-	PossibleAnswers []string `json:"possible_answers,omitempty"`
+	QuestionTitle   string           `json:"question_title,omitempty"`
+	Options         []choices.Option `json:"options,omitempty"`
+	AnswerKey       string           `json:"answer_key,omitempty" example:"A"`
+	TrueAnswer      string           `json:"true_answer,omitempty"`
+	Explanation     string           `json:"explanation,omitempty"`
+	PossibleAnswers []string         `json:"possible_answers,omitempty"`
+	// VocabularyLinks are dictionary words found in the question title and
+	// explanation; see AttemptAnswersResponse.Vocabulary for the words
+	VocabularyLinks []wordlink.Link `json:"vocabulary_links,omitempty"`
 }
 
 // AttemptAnswersResponse provides all answers for an attempt
@@ -40,6 +49,21 @@ type AttemptAnswersResponse struct {
 	TotalAnswered int32                            `json:"total_answered"`
 	CorrectCount  int32                            `json:"correct_count"`
 	Answers       []UserAnswerWithQuestionResponse `json:"answers"`
+	// Vocabulary lists the words linked in the answers once, with the
+	// reader's study sets that contain them
+	Vocabulary []VocabularyWord `json:"vocabulary"`
+	// Integrity is only shown to teachers and administrators reading
+	// another user's answers
+	Integrity *integrity.Summary `json:"integrity,omitempty"`
+}
+
+// reviewLinks collects the vocabulary links of all answers
+func reviewLinks(answers []UserAnswerWithQuestionResponse) []wordlink.Link {
+	var links []wordlink.Link
+	for _, answer := range answers {
+		links = append(links, answer.VocabularyLinks...)
+	}
+	return links
 }
 
 // AttemptScoreResponse provides scoring information
@@ -48,6 +72,7 @@ type AttemptScoreResponse struct {
 	TotalQuestions  int32   `json:"total_questions"`
 	CorrectAnswers  int32   `json:"correct_answers"`
 	CalculatedScore float64 `json:"calculated_score"`
+	MaxScore        int32   `json:"max_score" example:"990"`
 }
 
 // BulkUserAnswerRequest defines the structure for bulk submitting user answers
@@ -56,10 +81,12 @@ type BulkUserAnswerRequest struct {
 	Answers   []UserAnswerSubmission `json:"answers" binding:"required,min=1"`
 }
 
-// UserAnswerSubmission defines individual answer submission in bulk request
+// UserAnswerSubmission defines individual answer submission in bulk request.
+// The option is selected by key; older clients send its text instead.
 type UserAnswerSubmission struct {
 	QuestionID     int32  `json:"question_id" binding:"required,min=1"`
-	SelectedAnswer string `json:"selected_answer" binding:"required"`
+	SelectedKey    string `json:"selected_key" binding:"required_without=SelectedAnswer" example:"B"`
+	SelectedAnswer string `json:"selected_answer" binding:"required_without=SelectedKey"`
 }
 
 // BulkUserAnswerResponse provides response for bulk submission
@@ -75,7 +102,8 @@ type BulkUserAnswerResponse struct {
 // FailedAnswerSubmission represents answers that failed to submit
 type FailedAnswerSubmission struct {
 	QuestionID     int32  `json:"question_id"`
-	SelectedAnswer string `json:"selected_answer"`
+	SelectedKey    string `json:"selected_key,omitempty"`
+	SelectedAnswer string `json:"selected_answer,omitempty"`
 	Error          string `json:"error"`
 }
 
@@ -85,6 +113,7 @@ func NewUserAnswerResponse(userAnswer db.UserAnswer) UserAnswerResponse {
 		UserAnswerID:   userAnswer.UserAnswerID,
 		AttemptID:      userAnswer.AttemptID,
 		QuestionID:     userAnswer.QuestionID,
+		SelectedKey:    userAnswer.SelectedKey,
 		SelectedAnswer: userAnswer.SelectedAnswer,
 		IsCorrect:      userAnswer.IsCorrect,
 		CreatedAt:      userAnswer.CreatedAt,
@@ -105,15 +134,20 @@ func NewUserAnswerWithQuestionResponse(userAnswerWithQuestion db.ListUserAnswers
 			UserAnswerID:   userAnswerWithQuestion.UserAnswerID,
 			AttemptID:      userAnswerWithQuestion.AttemptID,
 			QuestionID:     userAnswerWithQuestion.QuestionID,
+			SelectedKey:    userAnswerWithQuestion.SelectedKey,
 			SelectedAnswer: userAnswerWithQuestion.SelectedAnswer,
 			IsCorrect:      userAnswerWithQuestion.IsCorrect,
 			CreatedAt:      userAnswerWithQuestion.CreatedAt,
 		},
 		QuestionTitle:   userAnswerWithQuestion.QuestionTitle,
+		AnswerKey:       userAnswerWithQuestion.AnswerKey,
 		TrueAnswer:      userAnswerWithQuestion.TrueAnswer,
 		Explanation:     userAnswerWithQuestion.Explanation,
 		PossibleAnswers: userAnswerWithQuestion.PossibleAnswers,
 	}
+	if options, err := choices.Decode(userAnswerWithQuestion.Options); err == nil && len(options) > 0 {
+		response.Options = options
+	}
 
 	// Handle nullable AnswerTime
 	if userAnswerWithQuestion.AnswerTime.Valid {
@@ -123,11 +157,13 @@ func NewUserAnswerWithQuestionResponse(userAnswerWithQuestion db.ListUserAnswers
 	return response
 }
 
-// createUserAnswerRequest defines the structure for creating a new user answer
+// createUserAnswerRequest defines the structure for creating a new user
+// answer. The option is selected by key; older clients send its text instead.
 type createUserAnswerRequest struct {
 	AttemptID      int32  `json:"attempt_id" binding:"required,min=1"`
 	QuestionID     int32  `json:"question_id" binding:"required,min=1"`
-	SelectedAnswer string `json:"selected_answer" binding:"required"`
+	SelectedKey    string `json:"selected_key" binding:"required_without=SelectedAnswer" example:"B"`
+	SelectedAnswer string `json:"selected_answer" binding:"required_without=SelectedKey"`
 }
 
 // getUserAnswerRequest defines the structure for getting a user answer by ID
@@ -137,17 +173,18 @@ type getUserAnswerRequest struct {
 
 // updateUserAnswerRequest defines the structure for updating a user answer
 type updateUserAnswerRequest struct {
-	SelectedAnswer string `json:"selected_answer" binding:"required"`
+	SelectedKey    string `json:"selected_key" binding:"required_without=SelectedAnswer" example:"B"`
+	SelectedAnswer string `json:"selected_answer" binding:"required_without=SelectedKey"`
 }
 
 // @Summary     Submit a user answer
-// @Description Submit an answer for a question in an exam attempt
+// @Description Submit an answer for a question in an exam attempt. The option is selected by selected_key; selected_answer, the text of the option, is accepted from older clients. Answers that are not an option of the question are rejected.
 // @Tags        user-answers
 // @Accept      json
 // @Produce     json
 // @Param       answer body createUserAnswerRequest true "User answer to submit"
 // @Success     201 {object} Response{data=UserAnswerResponse} "Answer submitted successfully"
-// @Failure     400 {object} Response "Invalid request body"
+// @Failure     400 {object} Response "Invalid request body or answer not an option"
 // @Failure     401 {object} Response "Unauthorized"
 // @Failure     404 {object} Response "Attempt or question not found"
 // @Failure     409 {object} Response "Answer already exists for this question"
@@ -174,70 +211,17 @@ func (server *Server) createUserAnswer(ctx *gin.Context) {
 		return
 	}
 
-	// Verify the attempt belongs to the user
-	_, err := server.store.GetExamAttemptByUser(ctx, db.GetExamAttemptByUserParams{
-		AttemptID: req.AttemptID,
-		UserID:    authPayload.ID,
-	})
-	if err != nil {
-		if err == sql.ErrNoRows {
-			ErrorResponse(ctx, http.StatusNotFound, "exam_attempt_not_found", err)
-			return
-		}
-		ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_verify_exam_attempt", err)
-		return
-	}
-
-	// Check if answer already exists for this question
-	_, err = server.store.GetUserAnswerByAttemptAndQuestion(ctx, db.GetUserAnswerByAttemptAndQuestionParams{
-		AttemptID:  req.AttemptID,
-		QuestionID: req.QuestionID,
-	})
-	if err == nil {
-		ErrorResponse(ctx, http.StatusConflict, "answer_already_exists", nil)
-		return
-	} else if err != sql.ErrNoRows {
-		ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_check_existing_answer", err)
-		return
-	}
-
-	// Get question to check correct answer
-	question, err := server.store.GetQuestion(ctx, req.QuestionID)
-	// fmt.Println("question:", question)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			ErrorResponse(ctx, http.StatusNotFound, "question_not_found", err)
-			return
-		}
-		ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_retrieve_question", err)
-		return
-	}
-
-	// Determine if answer is correct
-	isCorrect := req.SelectedAnswer == question.TrueAnswer
-
-	// Create the user answer
-	arg := db.CreateUserAnswerParams{
+	userAnswer, err := server.exams.SubmitAnswer(ctx, authPayload.ID, examservice.Submission{
 		AttemptID:      req.AttemptID,
 		QuestionID:     req.QuestionID,
+		SelectedKey:    req.SelectedKey,
 		SelectedAnswer: req.SelectedAnswer,
-		IsCorrect:      isCorrect,
-		AnswerTime:     sql.NullTime{Time: time.Now(), Valid: true},
-	}
-
-	userAnswer, err := server.store.CreateUserAnswer(ctx, arg)
+	})
 	if err != nil {
-		ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_create_user_answer", err)
+		examServiceError(ctx, err, "failed_to_create_user_answer")
 		return
 	}
 
-	// Clear user cache
-	if server.serviceCache != nil {
-		go func() {
-			server.ClearUserCache(int64(authPayload.ID))
-		}()
-	}
-
 	response := NewUserAnswerResponse(userAnswer)
 	SuccessResponse(ctx, http.StatusCreated, "user_answer_created_successfully", response)
 }
@@ -275,67 +259,27 @@ func (server *Server) getUserAnswer(ctx *gin.Context) {
 		return
 	}
 
-	// Check cache first
-	cacheKey := fmt.Sprintf("user_answer:%d", req.UserAnswerID)
-	if server.serviceCache != nil {
-		var cachedBytes []byte
-		if err := server.serviceCache.Get(ctx, cacheKey, &cachedBytes); err == nil {
-			var userAnswerResp UserAnswerResponse
-			if err := json.Unmarshal(cachedBytes, &userAnswerResp); err == nil {
-				SuccessResponse(ctx, http.StatusOK, "user_answer_retrieved_successfully", userAnswerResp)
-				return
-			}
-		}
-	}
-
-	userAnswer, err := server.store.GetUserAnswer(ctx, req.UserAnswerID)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			ErrorResponse(ctx, http.StatusNotFound, "user_answer_not_found", err)
-			return
-		}
-		ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_retrieve_user_answer", err)
-		return
-	}
-
-	// Verify the answer belongs to user's attempt
-	_, err = server.store.GetExamAttemptByUser(ctx, db.GetExamAttemptByUserParams{
-		AttemptID: userAnswer.AttemptID,
-		UserID:    authPayload.ID,
-	})
+	userAnswer, err := server.exams.Answer(ctx, authPayload.ID, req.UserAnswerID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ErrorResponse(ctx, http.StatusNotFound, "user_answer_not_found", err)
-			return
-		}
-		ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_verify_answer_ownership", err)
+		examServiceError(ctx, err, "failed_to_retrieve_user_answer")
 		return
 	}
 
 	response := NewUserAnswerResponse(userAnswer)
-
-	// Cache the results
-	if server.serviceCache != nil {
-		go func() {
-			if responseBytes, err := json.Marshal(response); err == nil {
-				server.serviceCache.Set(ctx, cacheKey, responseBytes, 30*time.Minute)
-			}
-		}()
-	}
-
 	SuccessResponse(ctx, http.StatusOK, "user_answer_retrieved_successfully", response)
 }
 
 // @Summary     Update a user answer
-// @Description Update a user's answer for a question
+// @Description Update the option selected in a user's answer, by selected_key or, from older clients, by selected_answer text
 // @Tags        user-answers
 // @Accept      json
 // @Produce     json
 // @Param       id path int true "User Answer ID"
 // @Param       answer body updateUserAnswerRequest true "Updated answer data"
 // @Success     200 {object} Response{data=UserAnswerResponse} "Answer updated successfully"
-// @Failure     400 {object} Response "Invalid request"
+// @Failure     400 {object} Response "Invalid request or answer not an option"
 // @Failure     401 {object} Response "Unauthorized"
+// @Failure     403 {object} Response "The transcript of the question was revealed in the part practice: answer_locked_after_transcript"
 // @Failure     404 {object} Response "Answer not found"
 // @Failure     500 {object} Response "Failed to update answer"
 // @Security    ApiKeyAuth
@@ -366,69 +310,24 @@ func (server *Server) updateUserAnswer(ctx *gin.Context) {
 		return
 	}
 
-	// Get existing answer to verify ownership
-	userAnswer, err := server.store.GetUserAnswer(ctx, req.UserAnswerID)
+	// The answer is checked again against the question
+	updatedAnswer, err := server.exams.UpdateAnswer(ctx, authPayload.ID, req.UserAnswerID, updateReq.SelectedKey, updateReq.SelectedAnswer)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ErrorResponse(ctx, http.StatusNotFound, "user_answer_not_found", err)
-			return
-		}
-		ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_retrieve_user_answer", err)
+		examServiceError(ctx, err, "failed_to_update_user_answer")
 		return
 	}
 
-	// Verify the answer belongs to user's attempt
-	_, err = server.store.GetExamAttemptByUser(ctx, db.GetExamAttemptByUserParams{
-		AttemptID: userAnswer.AttemptID,
-		UserID:    authPayload.ID,
-	})
-	if err != nil {
-		if err == sql.ErrNoRows {
-			ErrorResponse(ctx, http.StatusNotFound, "user_answer_not_found", err)
-			return
-		}
-		ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_verify_answer_ownership", err)
-		return
-	}
-
-	// Get question to check correct answer
-	question, err := server.store.GetQuestion(ctx, userAnswer.QuestionID)
-	if err != nil {
-		ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_retrieve_question", err)
-		return
-	}
-
-	// Determine if new answer is correct
-	isCorrect := updateReq.SelectedAnswer == question.TrueAnswer
-
-	// Update the user answer
-	updatedAnswer, err := server.store.UpdateUserAnswer(ctx, db.UpdateUserAnswerParams{
-		UserAnswerID:   req.UserAnswerID,
-		SelectedAnswer: updateReq.SelectedAnswer,
-		IsCorrect:      isCorrect,
-	})
-	if err != nil {
-		ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_update_user_answer", err)
-		return
-	}
-
-	// Clear user cache
-	if server.serviceCache != nil {
-		go func() {
-			server.ClearUserCache(int64(authPayload.ID))
-		}()
-	}
-
 	response := NewUserAnswerResponse(updatedAnswer)
 	SuccessResponse(ctx, http.StatusOK, "user_answer_updated_successfully", response)
 }
 
 // @Summary     Get answers by attempt
-// @Description Get all answers for a specific exam attempt
+// @Description Get all answers for a specific exam attempt. Users with the exams.grade permission may read the answers of other users' attempts, which is recorded in their access log. Dictionary words found in question titles and explanations are returned as vocabulary_links, with character offsets, and listed once in vocabulary with the request that adds them to a study set.
 // @Tags        user-answers
 // @Accept      json
 // @Produce     json
 // @Param       id path int true "Attempt ID"
+// @Param       X-Access-Purpose header string false "Purpose of reading another user's data: support, grading, moderation, legal or unspecified"
 // @Success     200 {object} Response{data=AttemptAnswersResponse} "Answers retrieved successfully"
 // @Failure     400 {object} Response "Invalid attempt ID"
 // @Failure     401 {object} Response "Unauthorized"
@@ -457,35 +356,24 @@ func (server *Server) getUserAnswersByAttempt(ctx *gin.Context) {
 		return
 	}
 
-	// Check cache first
-	cacheKey := fmt.Sprintf("attempt_answers:%d", attemptID)
-	if server.serviceCache != nil {
-		var cachedBytes []byte
-		if err := server.serviceCache.Get(ctx, cacheKey, &cachedBytes); err == nil {
-			var answersResp AttemptAnswersResponse
-			if err := json.Unmarshal(cachedBytes, &answersResp); err == nil {
-				SuccessResponse(ctx, http.StatusOK, "attempt_answers_retrieved_successfully", answersResp)
-				return
-			}
-		}
-	}
-
-	// Verify the attempt belongs to the user
-	_, err = server.store.GetExamAttemptByUser(ctx, db.GetExamAttemptByUserParams{
-		AttemptID: int32(attemptID),
-		UserID:    authPayload.ID,
-	})
+	// Verify the attempt belongs to the user or the user grades exams. This
+	// runs before the cache so cached answers are only served to them.
+	attempt, err := server.exams.Attempt(ctx, int32(attemptID))
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ErrorResponse(ctx, http.StatusNotFound, "exam_attempt_not_found", err)
+		examServiceError(ctx, err, "failed_to_verify_exam_attempt")
+		return
+	}
+	var integritySummary *integrity.Summary
+	if attempt.UserID != authPayload.ID {
+		summary, ok := server.staffIntegritySummary(ctx, authPayload.ID, attempt, accesslog.ResourceExamAnswers)
+		if !ok {
 			return
 		}
-		ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_verify_exam_attempt", err)
-		return
+		integritySummary = summary
 	}
 
 	// Get answers with question details
-	answersWithQuestions, err := server.store.ListUserAnswersByAttemptWithQuestions(ctx, int32(attemptID))
+	answersWithQuestions, err := server.exams.AttemptAnswers(ctx, int32(attemptID))
 	if err != nil {
 		ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_retrieve_answers", err)
 		return
@@ -497,6 +385,8 @@ func (server *Server) getUserAnswersByAttempt(ctx *gin.Context) {
 
 	for i, answerWithQuestion := range answersWithQuestions {
 		answers[i] = NewUserAnswerWithQuestionResponse(answerWithQuestion)
+		answers[i].VocabularyLinks = server.vocabularyLinks(ctx, answerWithQuestion.QuestionID,
+			answerWithQuestion.QuestionTitle, answerWithQuestion.Explanation)
 		if answerWithQuestion.IsCorrect {
 			correctCount++
 		}
@@ -509,15 +399,8 @@ func (server *Server) getUserAnswersByAttempt(ctx *gin.Context) {
 		Answers:       answers,
 	}
 
-	// Cache the results
-	if server.serviceCache != nil {
-		go func() {
-			if responseBytes, err := json.Marshal(response); err == nil {
-				server.serviceCache.Set(ctx, cacheKey, responseBytes, 10*time.Minute)
-			}
-		}()
-	}
-
+	response.Integrity = integritySummary
+	response.Vocabulary = server.reviewVocabulary(ctx, authPayload.ID, reviewLinks(answers))
 	SuccessResponse(ctx, http.StatusOK, "attempt_answers_retrieved_successfully", response)
 }
 
@@ -555,66 +438,24 @@ func (server *Server) getAttemptScore(ctx *gin.Context) {
 		return
 	}
 
-	// Check cache first
-	cacheKey := fmt.Sprintf("attempt_score:%d", attemptID)
-	if server.serviceCache != nil {
-		var cachedBytes []byte
-		if err := server.serviceCache.Get(ctx, cacheKey, &cachedBytes); err == nil {
-			var scoreResp AttemptScoreResponse
-			if err := json.Unmarshal(cachedBytes, &scoreResp); err == nil {
-				SuccessResponse(ctx, http.StatusOK, "attempt_score_retrieved_successfully", scoreResp)
-				return
-			}
-		}
-	}
-
-	// Verify the attempt belongs to the user
-	_, err = server.store.GetExamAttemptByUser(ctx, db.GetExamAttemptByUserParams{
-		AttemptID: int32(attemptID),
-		UserID:    authPayload.ID,
-	})
+	score, err := server.exams.Score(ctx, authPayload.ID, int32(attemptID))
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ErrorResponse(ctx, http.StatusNotFound, "exam_attempt_not_found", err)
-			return
-		}
-		ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_verify_exam_attempt", err)
-		return
-	}
-
-	// Get score data
-	scoreData, err := server.store.GetAttemptScore(ctx, int32(attemptID))
-	if err != nil {
-		ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_retrieve_attempt_score", err)
-		return
-	}
-
-	calculatedScore, err := strconv.ParseFloat(scoreData.CalculatedScore, 64)
-	if err != nil {
-		ErrorResponse(ctx, http.StatusInternalServerError, "invalid_calculated_score_format", err)
+		examServiceError(ctx, err, "failed_to_retrieve_attempt_score")
 		return
 	}
 	response := AttemptScoreResponse{
 		AttemptID:       int32(attemptID),
-		TotalQuestions:  int32(scoreData.TotalQuestions),
-		CorrectAnswers:  int32(scoreData.CorrectAnswers),
-		CalculatedScore: calculatedScore,
-	}
-
-	// Cache the results
-	if server.serviceCache != nil {
-		go func() {
-			if responseBytes, err := json.Marshal(response); err == nil {
-				server.serviceCache.Set(ctx, cacheKey, responseBytes, 15*time.Minute)
-			}
-		}()
+		TotalQuestions:  score.TotalQuestions,
+		CorrectAnswers:  score.CorrectAnswers,
+		CalculatedScore: score.CalculatedScore,
+		MaxScore:        score.MaxScore,
 	}
 
 	SuccessResponse(ctx, http.StatusOK, "attempt_score_retrieved_successfully", response)
 }
 
 // @Summary     Delete a user answer
-// @Description Delete a user's answer (admin only or own incomplete attempts)
+// @Description Delete a user's answer (admin only or own incomplete attempts). Answers of a part practice whose transcript was revealed cannot be deleted.
 // @Tags        user-answers
 // @Accept      json
 // @Produce     json
@@ -647,17 +488,6 @@ func (server *Server) deleteUserAnswer(ctx *gin.Context) {
 		return
 	}
 
-	// Get existing answer to verify ownership
-	userAnswer, err := server.store.GetUserAnswer(ctx, req.UserAnswerID)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			ErrorResponse(ctx, http.StatusNotFound, "user_answer_not_found", err)
-			return
-		}
-		ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_retrieve_user_answer", err)
-		return
-	}
-
 	// Check if user is admin or if it's their own answer
 	isAdmin, err := server.IsUserAdmin(ctx, authPayload.ID)
 	if err != nil {
@@ -665,48 +495,23 @@ func (server *Server) deleteUserAnswer(ctx *gin.Context) {
 		return
 	}
 
-	if !isAdmin {
-		// Non-admin users can only delete their own answers from incomplete attempts
-		attempt, err := server.store.GetExamAttemptByUser(ctx, db.GetExamAttemptByUserParams{
-			AttemptID: userAnswer.AttemptID,
-			UserID:    authPayload.ID,
-		})
-		if err != nil {
-			if err == sql.ErrNoRows {
-				ErrorResponse(ctx, http.StatusNotFound, "user_answer_not_found", err)
-				return
-			}
-			ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_verify_answer_ownership", err)
-			return
-		}
-
-		// Only allow deletion from in-progress attempts
-		if attempt.Status != db.ExamStatusEnumInProgress {
-			ErrorResponse(ctx, http.StatusForbidden, "cannot_delete_answer_from_completed_attempt", nil)
-			return
-		}
+	// Non-admin users can only delete their own answers from incomplete attempts
+	err = server.exams.DeleteAnswer(ctx, authPayload.ID, req.UserAnswerID, isAdmin)
+	if errors.Is(err, examservice.ErrAttemptClosed) {
+		ErrorResponse(ctx, http.StatusForbidden, "cannot_delete_answer_from_completed_attempt", nil)
+		return
 	}
-
-	// Delete the user answer
-	err = server.store.DeleteUserAnswer(ctx, req.UserAnswerID)
 	if err != nil {
-		ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_delete_user_answer", err)
+		examServiceError(ctx, err, "failed_to_delete_user_answer")
 		return
 	}
 
-	// Clear user cache
-	if server.serviceCache != nil {
-		go func() {
-			server.ClearUserCache(int64(authPayload.ID))
-		}()
-	}
-
 	logger.Info("User answer %d deleted by user %d", req.UserAnswerID, authPayload.ID)
 	SuccessResponse(ctx, http.StatusOK, "user_answer_deleted_successfully", nil)
 }
 
 // @Summary     Submit user answers in bulk
-// @Description Submit multiple answers for an exam attempt
+// @Description Submit multiple answers for an exam attempt. Options are selected by selected_key or, from older clients, by selected_answer text; answers that are not an option are listed in failed_answers with the error invalid_option.
 // @Tags        user-answers
 // @Accept      json
 // @Produce     json
@@ -738,122 +543,46 @@ func (server *Server) submitBulkUserAnswers(ctx *gin.Context) {
 		return
 	}
 
-	// Verify the attempt belongs to the user
-	_, err := server.store.GetExamAttemptByUser(ctx, db.GetExamAttemptByUserParams{
-		AttemptID: req.AttemptID,
-		UserID:    authPayload.ID,
-	})
-	if err != nil {
-		if err == sql.ErrNoRows {
-			ErrorResponse(ctx, http.StatusNotFound, "exam_attempt_not_found", err)
-			return
+	submissions := make([]examservice.Submission, len(req.Answers))
+	for i, answer := range req.Answers {
+		submissions[i] = examservice.Submission{
+			QuestionID:     answer.QuestionID,
+			SelectedKey:    answer.SelectedKey,
+			SelectedAnswer: answer.SelectedAnswer,
 		}
-		ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_verify_exam_attempt", err)
+	}
+	result, err := server.exams.SubmitAnswers(ctx, authPayload.ID, req.AttemptID, submissions)
+	if err != nil {
+		examServiceError(ctx, err, "failed_to_retrieve_question")
 		return
 	}
 
-	// Prepare response
 	bulkResponse := BulkUserAnswerResponse{
 		AttemptID:      req.AttemptID,
-		TotalSubmitted: int32(len(req.Answers)),
-		Answers:        make([]UserAnswerResponse, 0, len(req.Answers)),
-		FailedAnswers:  make([]FailedAnswerSubmission, 0),
+		TotalSubmitted: int32(len(result.Answers)),
+		TotalCorrect:   result.Correct,
+		Answers:        make([]UserAnswerResponse, len(result.Answers)),
+		FailedAnswers:  make([]FailedAnswerSubmission, len(result.Failed)),
 	}
-
-	// Process each answer submission
-	correctCount := int32(0)
-	for _, answer := range req.Answers {
-		// Check if answer already exists for this question
-		_, err := server.store.GetUserAnswerByAttemptAndQuestion(ctx, db.GetUserAnswerByAttemptAndQuestionParams{
-			AttemptID:  req.AttemptID,
-			QuestionID: answer.QuestionID,
-		})
-		if err == nil {
-			// Answer already exists, add to failed answers
-			bulkResponse.FailedAnswers = append(bulkResponse.FailedAnswers, FailedAnswerSubmission{
-				QuestionID:     answer.QuestionID,
-				SelectedAnswer: answer.SelectedAnswer,
-				Error:          "answer_already_exists",
-			})
-			continue
-		} else if err != sql.ErrNoRows {
-			bulkResponse.FailedAnswers = append(bulkResponse.FailedAnswers, FailedAnswerSubmission{
-				QuestionID:     answer.QuestionID,
-				SelectedAnswer: answer.SelectedAnswer,
-				Error:          "failed_to_check_existing_answer",
-			})
-			continue
-		}
-
-		// Get question to check correct answer
-		question, err := server.store.GetQuestion(ctx, answer.QuestionID)
-		if err != nil {
-			if err == sql.ErrNoRows {
-				// Question not found, add to failed answers
-				bulkResponse.FailedAnswers = append(bulkResponse.FailedAnswers, FailedAnswerSubmission{
-					QuestionID:     answer.QuestionID,
-					SelectedAnswer: answer.SelectedAnswer,
-					Error:          "question_not_found",
-				})
-				continue
-			}
-			ErrorResponse(ctx, http.StatusInternalServerError, "failed_to_retrieve_question", err)
-			return
-		}
-
-		// Determine if answer is correct
-		isCorrect := answer.SelectedAnswer == question.TrueAnswer
-		if isCorrect {
-			correctCount++
-		}
-
-		// Create the user answer
-		arg := db.CreateUserAnswerParams{
-			AttemptID:      req.AttemptID,
-			QuestionID:     answer.QuestionID,
-			SelectedAnswer: answer.SelectedAnswer,
-			IsCorrect:      isCorrect,
-			AnswerTime:     sql.NullTime{Time: time.Now(), Valid: true},
-		}
-
-		userAnswer, err := server.store.CreateUserAnswer(ctx, arg)
-		if err != nil {
-			// Failed to create answer, add to failed answers
-			bulkResponse.FailedAnswers = append(bulkResponse.FailedAnswers, FailedAnswerSubmission{
-				QuestionID:     answer.QuestionID,
-				SelectedAnswer: answer.SelectedAnswer,
-				Error:          "failed_to_create_user_answer",
-			})
-			continue
-		}
-
-		// Add to successful responses
-		bulkResponse.Answers = append(bulkResponse.Answers, NewUserAnswerResponse(userAnswer))
+	for i, answer := range result.Answers {
+		bulkResponse.Answers[i] = NewUserAnswerResponse(answer)
+	}
+	for i, failed := range result.Failed {
+		bulkResponse.FailedAnswers[i] = FailedAnswerSubmission(failed)
 	}
-
-	// Update response with correct count
-	bulkResponse.TotalCorrect = correctCount
-	bulkResponse.TotalSubmitted = int32(len(bulkResponse.Answers))
 
 	// Calculate score for successfully submitted answers
 	score := float64(0)
-	if len(bulkResponse.Answers) > 0 {
-		score = float64(correctCount) / float64(len(bulkResponse.Answers)) * 100
+	if len(result.Answers) > 0 {
+		score = float64(result.Correct) / float64(len(result.Answers)) * 100
 	}
 
 	bulkResponse.Score = &AttemptScoreResponse{
 		AttemptID:       req.AttemptID,
-		TotalQuestions:  int32(len(bulkResponse.Answers)),
-		CorrectAnswers:  correctCount,
+		TotalQuestions:  int32(len(result.Answers)),
+		CorrectAnswers:  result.Correct,
 		CalculatedScore: score,
 	}
 
-	// Clear user cache if we have successful submissions
-	if len(bulkResponse.Answers) > 0 && server.serviceCache != nil {
-		go func() {
-			server.ClearUserCache(int64(authPayload.ID))
-		}()
-	}
-
 	SuccessResponse(ctx, http.StatusCreated, "user_answers_bulk_submitted_successfully", bulkResponse)
 }
@@ -4,9 +4,11 @@ import (
 	"database/sql"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/export"
 	"github.com/toeic-app/internal/token"
 	"github.com/toeic-app/internal/util"
 )
@@ -28,7 +30,6 @@ type createUserRequest struct {
 // @Success     201 {object} Response{data=UserResponse} "User created successfully"
 // @Failure     400 {object} Response "Invalid request parameters or validation failure"
 // @Failure     500 {object} Response "Server error during user creation"
-// @Security    ApiKeyAuth
 // @Router      /api/v1/users [post]
 func (server *Server) createUser(ctx *gin.Context) {
 	var req createUserRequest
@@ -55,7 +56,7 @@ func (server *Server) createUser(ctx *gin.Context) {
 
 	arg := db.CreateUserParams{
 		Username:     req.Username,
-		Email:        req.Email,
+		Email:        sql.NullString{String: req.Email, Valid: true},
 		PasswordHash: hashedPassword,
 	}
 	user, err := server.store.CreateUser(ctx, arg)
@@ -109,18 +110,29 @@ type listUsersRequest struct {
 }
 
 // @Summary     List users
-// @Description Get a list of users with pagination. Allows for browsing through users.
+// @Description Get a list of users with pagination. Allows for browsing through users. With format=csv or format=xlsx every user is downloaded as a file and limit and offset are ignored (admin only).
 // @Tags        users
 // @Accept      json
-// @Produce     json
+// @Produce     json,text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
 // @Param       limit query int true "Number of users to return per page" minimum(1) maximum(100) default(10)
 // @Param       offset query int false "Offset for pagination" minimum(0) default(0)
+// @Param       format query string false "Download every user as a file" Enums(csv, xlsx)
 // @Success     200 {object} Response{data=[]UserResponse} "Users retrieved successfully"
 // @Failure     400 {object} Response "Invalid query parameters"
+// @Failure     403 {object} Response "Exporting users requires admin access"
 // @Failure     500 {object} Response "Server error during user listing"
 // @Security    ApiKeyAuth
 // @Router      /api/v1/users [get]
 func (server *Server) listUsers(ctx *gin.Context) {
+	format, ok := exportFormat(ctx)
+	if !ok {
+		return
+	}
+	if format != "" {
+		server.exportUsers(ctx, format)
+		return
+	}
+
 	var req listUsersRequest
 	if err := ctx.ShouldBindQuery(&req); err != nil {
 		ErrorResponse(ctx, http.StatusBadRequest, "Invalid query parameters", err)
@@ -145,7 +157,47 @@ func (server *Server) listUsers(ctx *gin.Context) {
 	if userResponses == nil {
 		userResponses = []UserResponse{}
 	}
-	SuccessResponse(ctx, http.StatusOK, "Users retrieved successfully", userResponses)
+	PaginatedResponse(ctx, http.StatusOK, "Users retrieved successfully", userResponses, NewPagination(req.Limit, req.Offset, len(userResponses)))
+}
+
+// exportUsers streams every user to an admin, reading them in batches
+func (server *Server) exportUsers(ctx *gin.Context, format export.Format) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+	isAdmin, err := server.IsUserAdmin(ctx, authPayload.ID)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to check permissions", err)
+		return
+	}
+	if !isAdmin {
+		ErrorResponse(ctx, http.StatusForbidden, "Exporting users requires admin access", nil)
+		return
+	}
+
+	header := []string{"id", "username", "email", "created_at", "updated_at"}
+	streamExport(ctx, format, "users", header, func(write func([]string) error) error {
+		var lastID int32
+		for {
+			users, err := server.store.ListUsersAfter(ctx, db.ListUsersAfterParams{ID: lastID, Limit: exportBatchSize})
+			if err != nil {
+				return err
+			}
+			for _, user := range users {
+				if err := write([]string{
+					strconv.Itoa(int(user.ID)),
+					user.Username,
+					user.Email.String,
+					user.CreatedAt.UTC().Format(time.RFC3339),
+					user.UpdatedAt.UTC().Format(time.RFC3339),
+				}); err != nil {
+					return err
+				}
+			}
+			if len(users) < exportBatchSize {
+				return nil
+			}
+			lastID = users[len(users)-1].ID
+		}
+	})
 }
 
 // updateUserRequest defines the structure for updating user information.
@@ -208,7 +260,7 @@ func (server *Server) updateUser(ctx *gin.Context) {
 			ErrorResponse(ctx, http.StatusBadRequest, "Invalid email format", nil)
 			return
 		}
-		arg.Email = req.Email
+		arg.Email = sql.NullString{String: req.Email, Valid: true}
 	}
 
 	if req.Password != "" {
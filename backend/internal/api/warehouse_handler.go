@@ -0,0 +1,115 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/config"
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/logger"
+	"github.com/toeic-app/internal/warehouse"
+)
+
+// newWarehouseExporter creates the analytics warehouse exporter, or returns
+// nil when the export is disabled or its warehouse cannot be configured
+func newWarehouseExporter(cfg config.Config, store db.Querier) *warehouse.Service {
+	if !cfg.WarehouseExportEnabled {
+		return nil
+	}
+	var sink warehouse.Sink
+	var err error
+	switch cfg.WarehouseBackend {
+	case "bigquery":
+		sink, err = warehouse.NewBigQuery(cfg.BigQueryProject, cfg.BigQueryDataset, cfg.BigQueryCredentialsFile)
+	default:
+		sink, err = warehouse.NewClickHouse(cfg.ClickHouseURL, cfg.ClickHouseDatabase, cfg.ClickHouseUser, cfg.ClickHousePassword)
+	}
+	if err != nil {
+		logger.Error("Warehouse export disabled: %v", err)
+		return nil
+	}
+	return warehouse.NewService(store, sink, warehouse.Options{
+		Table:     cfg.WarehouseTable,
+		BatchSize: int32(cfg.WarehouseBatchSize),
+		Scrubber: warehouse.NewScrubber(cfg.WarehouseHashKey,
+			splitFields(cfg.WarehousePIIDropFields), splitFields(cfg.WarehousePIIHashFields)),
+	})
+}
+
+// splitFields splits a comma-separated list of field names
+func splitFields(list string) []string {
+	var fields []string
+	for _, field := range strings.Split(list, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// @Summary     Get warehouse export status
+// @Description Returns the warehouse and table activities are exported to, the ID of the last exported activity, and the state of the latest export and backfill on this instance (admin only)
+// @Tags        admin
+// @Produce     json
+// @Success     200 {object} Response{data=warehouse.Status} "Warehouse export status retrieved successfully"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/warehouse [get]
+func (server *Server) getWarehouseStatus(ctx *gin.Context) {
+	if server.warehouse == nil {
+		ErrorResponse(ctx, http.StatusServiceUnavailable, "Warehouse export is disabled", nil)
+		return
+	}
+	status, err := server.warehouse.Status(ctx)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to get warehouse export status", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Warehouse export status retrieved successfully", status)
+}
+
+type warehouseBackfillRequest struct {
+	From time.Time `json:"from" binding:"required"`
+	To   time.Time `json:"to" binding:"required"`
+}
+
+// @Summary     Backfill the analytics warehouse
+// @Description Exports the activities recorded from `from` up to, not including, `to` again, in the background. Events the warehouse already has are not duplicated, and the regular export continues from where it was. Follow the progress at GET /api/v1/admin/warehouse. (admin only)
+// @Tags        admin
+// @Accept      json
+// @Produce     json
+// @Param       request body warehouseBackfillRequest true "Time range"
+// @Success     202 {object} Response{data=warehouse.BackfillStatus} "Warehouse backfill started"
+// @Failure     400 {object} Response "Invalid time range"
+// @Failure     409 {object} Response "A backfill is already running"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/warehouse/backfill [post]
+func (server *Server) backfillWarehouse(ctx *gin.Context) {
+	if server.warehouse == nil {
+		ErrorResponse(ctx, http.StatusServiceUnavailable, "Warehouse export is disabled", nil)
+		return
+	}
+	var req warehouseBackfillRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request", err)
+		return
+	}
+
+	status, err := server.warehouse.StartBackfill(req.From, req.To)
+	if err != nil {
+		switch {
+		case errors.Is(err, warehouse.ErrInvalidRange):
+			ErrorResponse(ctx, http.StatusBadRequest, "Invalid time range", err)
+		case errors.Is(err, warehouse.ErrBackfillRunning):
+			ErrorResponse(ctx, http.StatusConflict, "A backfill is already running", err)
+		default:
+			ErrorResponse(ctx, http.StatusInternalServerError, "Failed to start warehouse backfill", err)
+		}
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusAccepted, "Warehouse backfill started", status)
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/logger"
+	"github.com/toeic-app/internal/websocket"
+)
+
+// authorizeTopic lets only administrators, with the admin.access
+// permission, subscribe to the admin. topics
+func (server *Server) authorizeTopic(userID int32, topic string) bool {
+	if !strings.HasPrefix(topic, websocket.AdminTopicPrefix) {
+		return true
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	check, err := server.rbacService.CheckPermissionByResourceAction(ctx, userID, "admin", "access")
+	if err != nil {
+		logger.Warn("Failed to check whether user %d may subscribe to %s: %v", userID, topic, err)
+		return false
+	}
+	return check.HasPermission
+}
+
+// webSocketConnectionsResponse lists the WebSocket connections of the
+// instance that served the request
+type webSocketConnectionsResponse struct {
+	Stats       websocket.Stats            `json:"stats"`
+	Connections []websocket.ConnectionInfo `json:"connections"`
+}
+
+// @Summary     List WebSocket connections
+// @Description Lists the open WebSocket connections of the instance serving the request, oldest first, with the user, connect time, last activity and subscribed topics, plus its connection and message counters. Each instance only knows its own connections. Requires the system.monitor permission. (admin only)
+// @Tags        monitoring
+// @Produce     json
+// @Success     200 {object} Response{data=webSocketConnectionsResponse} "WebSocket connections retrieved successfully"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     403 {object} Response "Permission denied"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/websocket/connections [get]
+func (server *Server) listWebSocketConnections(ctx *gin.Context) {
+	SuccessResponse(ctx, http.StatusOK, "WebSocket connections retrieved successfully", webSocketConnectionsResponse{
+		Stats:       server.wsManager.Stats(),
+		Connections: server.wsManager.Connections(),
+	})
+}
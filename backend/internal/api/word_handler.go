@@ -11,6 +11,9 @@ import (
 	"github.com/sqlc-dev/pqtype"
 	db "github.com/toeic-app/internal/db/sqlc" // Adjust import path if necessary
 	"github.com/toeic-app/internal/logger"
+	"github.com/toeic-app/internal/related"
+	"github.com/toeic-app/internal/savedfilter"
+	"github.com/toeic-app/internal/wordsense"
 )
 
 // WordState represents the word state structure
@@ -71,6 +74,11 @@ type WordResponse struct {
 	Snym          []SynonymData    `json:"snym,omitempty"`
 	Freq          float32          `json:"freq"`
 	Conjugation   *ConjugationData `json:"conjugation,omitempty"`
+	AudioURL      string           `json:"audio_url,omitempty"`
+	// Senses and RelatedContent are only filled on the word detail endpoint
+	// and after the word is updated
+	Senses         []wordsense.Sense `json:"senses,omitempty"`
+	RelatedContent []related.Item    `json:"related_content,omitempty"`
 }
 
 // NewWordResponse creates a WordResponse from db.Word model
@@ -83,6 +91,7 @@ func NewWordResponse(word db.Word) WordResponse {
 		DescriptLevel: word.DescriptLevel,
 		ShortMean:     word.ShortMean,
 		Freq:          word.Freq,
+		AudioURL:      word.AudioUrl.String,
 	}
 
 	// Parse JSON fields if they are valid
@@ -111,7 +120,7 @@ func NewWordResponse(word db.Word) WordResponse {
 type createWordRequest struct {
 	Word          string           `json:"word" binding:"required"`
 	Pronounce     string           `json:"pronounce" binding:"required"`
-	Level         int32            `json:"level" binding:"required"`
+	Level         int32            `json:"level" binding:"required,difficulty_level"`
 	DescriptLevel string           `json:"descript_level" binding:"required"`
 	ShortMean     string           `json:"short_mean" binding:"required"`
 	Means         []MeaningData    `json:"means,omitempty"`
@@ -154,8 +163,11 @@ func (server *Server) createWord(ctx *gin.Context) {
 		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to create word", err)
 		return
 	}
+	server.wordLinks.Invalidate()
+	response := NewWordResponse(word)
+	response.Senses = server.syncWordSenses(ctx, word)
 
-	ctx.JSON(http.StatusOK, word)
+	SuccessResponse(ctx, http.StatusOK, "Word created successfully", response)
 }
 
 type getWordRequest struct {
@@ -188,6 +200,8 @@ func (server *Server) getWord(ctx *gin.Context) {
 		if err := server.serviceCache.Get(ctx, cacheKey, &cachedWord); err == nil {
 			logger.Debug("Word %d retrieved from cache", req.ID)
 			wordResponse := NewWordResponse(cachedWord)
+			wordResponse.Senses = server.wordSenses(ctx, cachedWord.ID)
+			wordResponse.RelatedContent = server.relatedContent(ctx, related.TypeWord, cachedWord.ID)
 			SuccessResponse(ctx, http.StatusOK, "Word retrieved successfully", wordResponse)
 			return
 		}
@@ -210,7 +224,7 @@ func (server *Server) getWord(ctx *gin.Context) {
 			bgCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 			defer cancel()
 
-			if err := server.serviceCache.Set(bgCtx, cacheKey, word, server.config.CacheDefaultTTL); err != nil {
+			if err := server.serviceCache.Set(bgCtx, cacheKey, word, server.configReloader.Current().CacheDefaultTTL); err != nil {
 				logger.Warn("Failed to cache word %d: %v", req.ID, err)
 			} else {
 				logger.Debug("Word %d cached successfully", req.ID)
@@ -219,23 +233,33 @@ func (server *Server) getWord(ctx *gin.Context) {
 	}
 
 	wordResponse := NewWordResponse(word)
+	wordResponse.Senses = server.wordSenses(ctx, word.ID)
+	wordResponse.RelatedContent = server.relatedContent(ctx, related.TypeWord, word.ID)
 	SuccessResponse(ctx, http.StatusOK, "Word retrieved successfully", wordResponse)
 }
 
 type listWordsRequest struct {
 	Limit  int32 `form:"limit,default=10"`
 	Offset int32 `form:"offset,default=0"`
+	listFilterQuery
 }
 
 // @Summary List words
-// @Description List words with pagination
+// @Description List words with pagination, optionally filtered by a saved filter and the filter fields overriding it
 // @Tags words
 // @Accept json
 // @Produce json
 // @Param limit query int false "Limit" default(10)
 // @Param offset query int false "Offset" default(0)
+// @Param filter_id query int false "Saved filter ID"
+// @Param query query string false "Substring of the word or its short meaning"
+// @Param min_level query int false "Minimum level"
+// @Param max_level query int false "Maximum level"
+// @Param tags query string false "Comma separated tags"
+// @Param list query string false "Word list name, e.g. TOEIC"
 // @Success 200 {object} Response{data=[]WordResponse} "List of words"
-// @Failure 400 {object} Response "Invalid query parameters"
+// @Failure 400 {object} Response "Invalid query parameters or filter"
+// @Failure 404 {object} Response "Saved filter not found"
 // @Failure 500 {object} Response "Failed to list words"
 // @Router /api/v1/words [get]
 // @Security ApiKeyAuth
@@ -245,10 +269,20 @@ func (server *Server) listWords(ctx *gin.Context) {
 		ErrorResponse(ctx, http.StatusBadRequest, "Invalid query parameters", err)
 		return
 	}
-	words, err := server.store.ListWords(ctx, db.ListWordsParams{
-		Limit:  req.Limit,
-		Offset: req.Offset,
-	})
+	criteria, ok := server.listFilter(ctx, savedfilter.ResourceWords, req.listFilterQuery)
+	if !ok {
+		return
+	}
+	var words []db.Word
+	var err error
+	if criteria.IsZero() {
+		words, err = server.store.ListWords(ctx, db.ListWordsParams{
+			Limit:  req.Limit,
+			Offset: req.Offset,
+		})
+	} else {
+		words, err = server.store.ListWordsFiltered(ctx, criteria.WordParams(req.Limit, req.Offset))
+	}
 	if err != nil {
 		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to list words", err)
 		return
@@ -264,14 +298,14 @@ func (server *Server) listWords(ctx *gin.Context) {
 		wordResponses = []WordResponse{}
 	}
 
-	SuccessResponse(ctx, http.StatusOK, "Words retrieved successfully", wordResponses)
+	PaginatedResponse(ctx, http.StatusOK, "Words retrieved successfully", wordResponses, NewPagination(req.Limit, req.Offset, len(wordResponses)))
 }
 
 type updateWordRequest struct {
 	ID            int32            `json:"id" binding:"required,min=1"`
 	Word          string           `json:"word"`
 	Pronounce     string           `json:"pronounce"`
-	Level         int32            `json:"level"`
+	Level         int32            `json:"level" binding:"omitempty,difficulty_level"`
 	DescriptLevel string           `json:"descript_level"`
 	ShortMean     string           `json:"short_mean"`
 	Means         []MeaningData    `json:"means,omitempty"`
@@ -321,6 +355,7 @@ func (server *Server) updateWord(ctx *gin.Context) {
 		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to update word", err)
 		return
 	}
+	server.wordLinks.Invalidate()
 
 	// Clear cache for the updated word
 	if server.config.CacheEnabled && server.serviceCache != nil {
@@ -338,6 +373,7 @@ func (server *Server) updateWord(ctx *gin.Context) {
 	}
 
 	wordResponse := NewWordResponse(word)
+	wordResponse.Senses = server.syncWordSenses(ctx, word)
 	SuccessResponse(ctx, http.StatusOK, "Word updated successfully", wordResponse)
 }
 
@@ -369,6 +405,7 @@ func (server *Server) deleteWord(ctx *gin.Context) {
 		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to delete word", err)
 		return
 	}
+	server.wordLinks.Invalidate()
 
 	// Clear cache for the deleted word
 	if server.config.CacheEnabled && server.serviceCache != nil {
@@ -433,7 +470,7 @@ func (server *Server) searchWords(ctx *gin.Context) {
 		wordResponses = []WordResponse{}
 	}
 
-	SuccessResponse(ctx, http.StatusOK, "Words found successfully", wordResponses)
+	PaginatedResponse(ctx, http.StatusOK, "Words found successfully", wordResponses, NewPagination(req.Limit, req.Offset, len(wordResponses)))
 }
 
 // Helper functions to convert structured types to pqtype.NullRawMessage
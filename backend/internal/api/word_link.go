@@ -0,0 +1,87 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/logger"
+	"github.com/toeic-app/internal/wordlink"
+)
+
+// Fields of a reviewed question that are searched for dictionary words
+const (
+	linkFieldQuestion    = "question"
+	linkFieldExplanation = "explanation"
+)
+
+// StudySetAction is the request that adds a linked word to one of the
+// reader's study sets; clients fill in the study set ID
+type StudySetAction struct {
+	Method string `json:"method" example:"POST"`
+	Path   string `json:"path" example:"/api/v1/study-sets/{study_set_id}/words"`
+	WordID int32  `json:"word_id"`
+}
+
+// VocabularyWord is a dictionary word linked in a review
+type VocabularyWord struct {
+	wordlink.Entry
+	// StudySetIDs are the reader's study sets that already contain the word
+	StudySetIDs   []int32        `json:"study_set_ids"`
+	AddToStudySet StudySetAction `json:"add_to_study_set"`
+}
+
+// vocabularyLinks finds dictionary words in a reviewed question and its
+// explanation. Links are a study aid, so failing to find them never fails
+// the review.
+func (server *Server) vocabularyLinks(ctx *gin.Context, questionID int32, question, explanation string) []wordlink.Link {
+	var links []wordlink.Link
+	for _, field := range []struct{ name, text string }{
+		{linkFieldQuestion, question},
+		{linkFieldExplanation, explanation},
+	} {
+		found, err := server.wordLinks.Links(ctx, field.name, field.text)
+		if err != nil {
+			logger.Warn("Failed to link vocabulary of question %d: %v", questionID, err)
+			return nil
+		}
+		links = append(links, found...)
+	}
+	return links
+}
+
+// reviewVocabulary lists the words linked in a review with the reader's
+// study sets that contain them
+func (server *Server) reviewVocabulary(ctx *gin.Context, readerID int32, links []wordlink.Link) []VocabularyWord {
+	entries, err := server.wordLinks.Entries(ctx, links)
+	if err != nil {
+		logger.Warn("Failed to load linked vocabulary: %v", err)
+		return nil
+	}
+	wordIDs := make([]int32, len(entries))
+	for i, entry := range entries {
+		wordIDs[i] = entry.WordID
+	}
+	saved, err := server.wordLinks.SavedIn(ctx, readerID, wordIDs)
+	if err != nil {
+		logger.Warn("Failed to load study sets of linked vocabulary: %v", err)
+		saved = nil
+	}
+
+	words := make([]VocabularyWord, 0, len(entries))
+	for _, entry := range entries {
+		studySetIDs := saved[entry.WordID]
+		if studySetIDs == nil {
+			studySetIDs = []int32{}
+		}
+		words = append(words, VocabularyWord{
+			Entry:       entry,
+			StudySetIDs: studySetIDs,
+			AddToStudySet: StudySetAction{
+				Method: http.MethodPost,
+				Path:   "/api/v1/study-sets/{study_set_id}/words",
+				WordID: entry.WordID,
+			},
+		})
+	}
+	return words
+}
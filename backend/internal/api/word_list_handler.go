@@ -0,0 +1,331 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/token"
+	"github.com/toeic-app/internal/wordlist"
+)
+
+// maxWordListUploadSize bounds uploaded vocabulary lists; NGSL with all
+// metadata columns is well under 1 MB
+const maxWordListUploadSize = 5 << 20
+
+type listWordImportsRequest struct {
+	Limit  int32 `form:"limit,default=20" binding:"min=1,max=100"`
+	Offset int32 `form:"offset,default=0" binding:"min=0"`
+}
+
+type listWordsPageRequest struct {
+	Limit  int32 `form:"limit,default=50" binding:"min=1,max=200"`
+	Offset int32 `form:"offset,default=0" binding:"min=0"`
+}
+
+// wordListEntryResponse is a word of a standard vocabulary list
+type wordListEntryResponse struct {
+	Rank          int32   `json:"rank,omitempty"`
+	Frequency     float64 `json:"frequency,omitempty"`
+	WordID        int32   `json:"word_id"`
+	Word          string  `json:"word"`
+	Level         int32   `json:"level"`
+	DescriptLevel string  `json:"descript_level"`
+	ShortMean     string  `json:"short_mean"`
+}
+
+// parseWordImportID reads the import ID path parameter
+func parseWordImportID(ctx *gin.Context) (int32, bool) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 32)
+	if err != nil || id <= 0 {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid import ID", err)
+		return 0, false
+	}
+	return int32(id), true
+}
+
+// wordImportError writes the response of a failed import operation
+func wordImportError(ctx *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, wordlist.ErrImportNotFound):
+		ErrorResponse(ctx, http.StatusNotFound, "Word import not found", err)
+	case errors.Is(err, wordlist.ErrImportFinished):
+		ErrorResponse(ctx, http.StatusConflict, err.Error(), err)
+	case errors.Is(err, wordlist.ErrInvalidListName), errors.Is(err, wordlist.ErrInvalidTag),
+		errors.Is(err, wordlist.ErrEmptyList), errors.Is(err, wordlist.ErrMissingWordColumn),
+		errors.Is(err, wordlist.ErrInvalidFile), errors.Is(err, wordlist.ErrTooManyEntries):
+		ErrorResponse(ctx, http.StatusBadRequest, err.Error(), err)
+	default:
+		ErrorResponse(ctx, http.StatusInternalServerError, message, err)
+	}
+}
+
+// @Summary     Preview a word list import
+// @Description Uploads a CSV vocabulary list (NGSL, TSL, ...) and reports the changes importing it would make: new words, existing words joining the list or gaining tags, duplicates and invalid rows. Nothing changes until the import is committed. Recognized columns: word/lemma/headword (required), rank/"sfi rank", frequency/freq/sfi/u, level, cefr, meaning, tags (";" separated). Words are auto-tagged with topics such as business and travel.
+// @Tags        admin
+// @Accept      multipart/form-data
+// @Produce     json
+// @Param       file formData file true "CSV list with a header row"
+// @Param       list formData string true "List name, e.g. NGSL or TSL"
+// @Param       tags formData string false "Comma separated tags added to every word"
+// @Success     201 {object} Response{data=wordlist.Import} "Word import previewed successfully"
+// @Failure     400 {object} Response "Invalid list"
+// @Failure     413 {object} Response "List file too large"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/word-imports [post]
+func (server *Server) previewWordImport(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	file, header, err := ctx.Request.FormFile("file")
+	if err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "No list file found in request", err)
+		return
+	}
+	defer file.Close()
+
+	if header.Size > maxWordListUploadSize {
+		ErrorResponse(ctx, http.StatusRequestEntityTooLarge, "List file too large",
+			fmt.Errorf("maximum size is %d bytes", maxWordListUploadSize))
+		return
+	}
+
+	var tags []string
+	if value := ctx.PostForm("tags"); value != "" {
+		tags = strings.Split(value, ",")
+	}
+
+	imp, err := server.wordLists.Preview(ctx, wordlist.PreviewRequest{
+		ListName:  ctx.PostForm("list"),
+		Filename:  header.Filename,
+		Tags:      tags,
+		CreatedBy: authPayload.ID,
+	}, io.LimitReader(file, maxWordListUploadSize))
+	if err != nil {
+		wordImportError(ctx, err, "Failed to preview word import")
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusCreated, "Word import previewed successfully", imp)
+}
+
+// @Summary     List word list imports
+// @Description Lists recent word list imports with their summaries (admin only)
+// @Tags        admin
+// @Produce     json
+// @Param       limit query int false "Page size (1-100, default 20)"
+// @Param       offset query int false "Offset (default 0)"
+// @Success     200 {object} Response{data=[]wordlist.Import} "Word imports retrieved successfully"
+// @Failure     400 {object} Response "Invalid query parameters"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/word-imports [get]
+func (server *Server) listWordImports(ctx *gin.Context) {
+	var req listWordImportsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	imports, err := server.wordLists.List(ctx, req.Limit, req.Offset)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to list word imports", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Word imports retrieved successfully", imports)
+}
+
+// @Summary     Get a word list import
+// @Description Returns an import with its per-word diff (admin only)
+// @Tags        admin
+// @Produce     json
+// @Param       id path int true "Import ID"
+// @Success     200 {object} Response{data=wordlist.Import} "Word import retrieved successfully"
+// @Failure     404 {object} Response "Word import not found"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/word-imports/{id} [get]
+func (server *Server) getWordImport(ctx *gin.Context) {
+	id, ok := parseWordImportID(ctx)
+	if !ok {
+		return
+	}
+
+	imp, err := server.wordLists.Get(ctx, id)
+	if err != nil {
+		wordImportError(ctx, err, "Failed to get word import")
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Word import retrieved successfully", imp)
+}
+
+// @Summary     Commit a word list import
+// @Description Applies a previewed import: creates new words, adds words to the list with their rank and frequency, and tags them. Existing words keep their level, meaning and frequency. (admin only)
+// @Tags        admin
+// @Produce     json
+// @Param       id path int true "Import ID"
+// @Success     200 {object} Response{data=wordlist.Import} "Word import committed successfully"
+// @Failure     404 {object} Response "Word import not found"
+// @Failure     409 {object} Response "Word import already committed or discarded"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/word-imports/{id}/commit [post]
+func (server *Server) commitWordImport(ctx *gin.Context) {
+	id, ok := parseWordImportID(ctx)
+	if !ok {
+		return
+	}
+
+	imp, err := server.wordLists.Commit(ctx, id)
+	if err != nil {
+		wordImportError(ctx, err, "Failed to commit word import")
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Word import committed successfully", imp)
+}
+
+// @Summary     Discard a word list import
+// @Description Drops a previewed import without applying it (admin only)
+// @Tags        admin
+// @Produce     json
+// @Param       id path int true "Import ID"
+// @Success     200 {object} Response "Word import discarded successfully"
+// @Failure     404 {object} Response "Word import not found"
+// @Failure     409 {object} Response "Word import already committed or discarded"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/word-imports/{id} [delete]
+func (server *Server) discardWordImport(ctx *gin.Context) {
+	id, ok := parseWordImportID(ctx)
+	if !ok {
+		return
+	}
+
+	if err := server.wordLists.Discard(ctx, id); err != nil {
+		wordImportError(ctx, err, "Failed to discard word import")
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Word import discarded successfully", nil)
+}
+
+// @Summary     List word tags
+// @Description Returns the topic tags of words with the number of words per tag
+// @Tags        words
+// @Produce     json
+// @Success     200 {object} Response{data=[]db.ListWordTagCountsRow} "Word tags retrieved successfully"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/words/tags [get]
+func (server *Server) listWordTags(ctx *gin.Context) {
+	tags, err := server.store.ListWordTagCounts(ctx)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to list word tags", err)
+		return
+	}
+	if tags == nil {
+		tags = []db.ListWordTagCountsRow{}
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Word tags retrieved successfully", tags)
+}
+
+// @Summary     List words by tag
+// @Description Returns the words with a topic tag, most frequent first
+// @Tags        words
+// @Produce     json
+// @Param       tag path string true "Tag, e.g. business or travel"
+// @Param       limit query int false "Page size (1-200, default 50)"
+// @Param       offset query int false "Offset (default 0)"
+// @Success     200 {object} Response{data=[]WordResponse} "Words retrieved successfully"
+// @Failure     400 {object} Response "Invalid query parameters"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/words/tags/{tag} [get]
+func (server *Server) listWordsByTag(ctx *gin.Context) {
+	var req listWordsPageRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	words, err := server.store.ListWordsByTag(ctx, db.ListWordsByTagParams{
+		Tag:    strings.ToLower(ctx.Param("tag")),
+		Limit:  req.Limit,
+		Offset: req.Offset,
+	})
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to list words", err)
+		return
+	}
+
+	responses := make([]WordResponse, 0, len(words))
+	for _, word := range words {
+		responses = append(responses, NewWordResponse(word))
+	}
+	PaginatedResponse(ctx, http.StatusOK, "Words retrieved successfully", responses, NewPagination(req.Limit, req.Offset, len(responses)))
+}
+
+// @Summary     List word lists
+// @Description Returns the imported standard vocabulary lists with their number of words
+// @Tags        words
+// @Produce     json
+// @Success     200 {object} Response{data=[]db.ListWordListsRow} "Word lists retrieved successfully"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/words/lists [get]
+func (server *Server) listWordLists(ctx *gin.Context) {
+	lists, err := server.store.ListWordLists(ctx)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to list word lists", err)
+		return
+	}
+	if lists == nil {
+		lists = []db.ListWordListsRow{}
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Word lists retrieved successfully", lists)
+}
+
+// @Summary     List the words of a word list
+// @Description Returns the words of a standard vocabulary list in list rank order
+// @Tags        words
+// @Produce     json
+// @Param       list path string true "List name, e.g. NGSL"
+// @Param       limit query int false "Page size (1-200, default 50)"
+// @Param       offset query int false "Offset (default 0)"
+// @Success     200 {object} Response{data=[]wordListEntryResponse} "Word list retrieved successfully"
+// @Failure     400 {object} Response "Invalid query parameters"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/words/lists/{list} [get]
+func (server *Server) listWordListEntries(ctx *gin.Context) {
+	var req listWordsPageRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+
+	entries, err := server.store.ListWordListEntries(ctx, db.ListWordListEntriesParams{
+		ListName: strings.ToUpper(ctx.Param("list")),
+		Limit:    req.Limit,
+		Offset:   req.Offset,
+	})
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to list word list entries", err)
+		return
+	}
+
+	responses := make([]wordListEntryResponse, 0, len(entries))
+	for _, entry := range entries {
+		responses = append(responses, wordListEntryResponse{
+			Rank:          entry.Rank.Int32,
+			Frequency:     entry.Frequency.Float64,
+			WordID:        entry.ID,
+			Word:          entry.Word,
+			Level:         entry.Level,
+			DescriptLevel: entry.DescriptLevel,
+			ShortMean:     entry.ShortMean,
+		})
+	}
+	PaginatedResponse(ctx, http.StatusOK, "Word list retrieved successfully", responses, NewPagination(req.Limit, req.Offset, len(responses)))
+}
@@ -0,0 +1,207 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/logger"
+	"github.com/toeic-app/internal/wordsense"
+)
+
+// wordSenses returns the senses block of a word response. The means JSON
+// still carries the meanings, so failing to load senses never fails the
+// response.
+func (server *Server) wordSenses(ctx *gin.Context, wordID int32) []wordsense.Sense {
+	senses, err := server.senses.List(ctx, wordID)
+	if err != nil {
+		logger.Warn("Failed to load senses of word %d: %v", wordID, err)
+		return nil
+	}
+	return senses
+}
+
+// syncWordSenses rebuilds the senses of a word after its meanings were written
+func (server *Server) syncWordSenses(ctx *gin.Context, word db.Word) []wordsense.Sense {
+	senses, err := server.senses.Sync(ctx, word)
+	if err != nil {
+		logger.Error("Failed to update senses of word %d: %v", word.ID, err)
+		return nil
+	}
+	return senses
+}
+
+// forgetCachedWord drops a word from the cache after its meanings changed
+func (server *Server) forgetCachedWord(wordID int32) {
+	if !server.config.CacheEnabled || server.serviceCache == nil {
+		return
+	}
+	cacheKey := server.serviceCache.GenerateKey("word", wordID)
+	go func() {
+		bgCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := server.serviceCache.Delete(bgCtx, cacheKey); err != nil {
+			logger.Warn("Failed to clear cache for word %d: %v", wordID, err)
+		}
+	}()
+}
+
+// wordSenseError writes the response of a failed sense operation
+func wordSenseError(ctx *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, wordsense.ErrWordNotFound):
+		ErrorResponse(ctx, http.StatusNotFound, "Word not found", err)
+	case errors.Is(err, wordsense.ErrSenseNotFound):
+		ErrorResponse(ctx, http.StatusNotFound, "Sense not found", err)
+	case errors.Is(err, wordsense.ErrEmptyMeaning), errors.Is(err, wordsense.ErrInvalidPartOfSpeech),
+		errors.Is(err, wordsense.ErrInvalidRegister):
+		ErrorResponse(ctx, http.StatusBadRequest, err.Error(), err)
+	default:
+		ErrorResponse(ctx, http.StatusInternalServerError, message, err)
+	}
+}
+
+type wordSenseURI struct {
+	ID      int32 `uri:"id" binding:"required,min=1"`
+	SenseID int32 `uri:"sense_id" binding:"omitempty,min=1"`
+}
+
+// wordSenseRequest is a sense of a word. Register is detected from notes such
+// as "(thông tục)" at the start of the meaning when left empty.
+type wordSenseRequest struct {
+	PartOfSpeech string  `json:"part_of_speech" binding:"omitempty,max=30" example:"verb"`
+	Meaning      string  `json:"meaning" binding:"required" example:"đặt trước, giữ chỗ"`
+	Register     string  `json:"register" binding:"omitempty,max=30" example:"informal"`
+	Examples     []int32 `json:"examples"`
+}
+
+func (r wordSenseRequest) draft() wordsense.Draft {
+	return wordsense.Draft{PartOfSpeech: r.PartOfSpeech, Meaning: r.Meaning, Register: r.Register, Examples: r.Examples}
+}
+
+// @Summary     List word senses
+// @Description Lists the senses of a word in order. Each sense is one meaning with its part of speech (noun, verb, adjective, adverb, preposition, conjunction, pronoun, interjection, determiner, affix, abbreviation or phrase) and register (informal, slang, archaic, rare, american, figurative, literary, business, legal or technical; empty when neutral).
+// @Tags        words
+// @Produce     json
+// @Param       id path int true "Word ID"
+// @Success     200 {object} Response{data=[]wordsense.Sense} "Word senses retrieved successfully"
+// @Failure     400 {object} Response "Invalid word ID"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/words/{id}/senses [get]
+func (server *Server) listWordSenses(ctx *gin.Context) {
+	var uri wordSenseURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid word ID", err)
+		return
+	}
+
+	senses, err := server.senses.List(ctx, uri.ID)
+	if err != nil {
+		wordSenseError(ctx, err, "Failed to list word senses")
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Word senses retrieved successfully", senses)
+}
+
+// @Summary     Add a word sense
+// @Description Appends a sense to a word. The means of the word are rebuilt from its senses.
+// @Tags        words
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Word ID"
+// @Param       sense body wordSenseRequest true "Sense"
+// @Success     201 {object} Response{data=wordsense.Sense} "Word sense added successfully"
+// @Failure     400 {object} Response "Invalid request body or unknown part of speech or register"
+// @Failure     403 {object} Response "Missing content.update permission"
+// @Failure     404 {object} Response "Word not found"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/words/{id}/senses [post]
+func (server *Server) createWordSense(ctx *gin.Context) {
+	var uri wordSenseURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid word ID", err)
+		return
+	}
+	var req wordSenseRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	sense, err := server.senses.Add(ctx, uri.ID, req.draft())
+	if err != nil {
+		wordSenseError(ctx, err, "Failed to add word sense")
+		return
+	}
+	server.forgetCachedWord(uri.ID)
+
+	SuccessResponse(ctx, http.StatusCreated, "Word sense added successfully", sense)
+}
+
+// @Summary     Update a word sense
+// @Description Replaces a sense of a word. The means of the word are rebuilt from its senses.
+// @Tags        words
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Word ID"
+// @Param       sense_id path int true "Sense ID"
+// @Param       sense body wordSenseRequest true "Sense"
+// @Success     200 {object} Response{data=wordsense.Sense} "Word sense updated successfully"
+// @Failure     400 {object} Response "Invalid request body or unknown part of speech or register"
+// @Failure     403 {object} Response "Missing content.update permission"
+// @Failure     404 {object} Response "Sense not found"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/words/{id}/senses/{sense_id} [put]
+func (server *Server) updateWordSense(ctx *gin.Context) {
+	var uri wordSenseURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid word or sense ID", err)
+		return
+	}
+	var req wordSenseRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	sense, err := server.senses.Update(ctx, uri.ID, uri.SenseID, req.draft())
+	if err != nil {
+		wordSenseError(ctx, err, "Failed to update word sense")
+		return
+	}
+	server.forgetCachedWord(uri.ID)
+
+	SuccessResponse(ctx, http.StatusOK, "Word sense updated successfully", sense)
+}
+
+// @Summary     Delete a word sense
+// @Description Removes a sense of a word. The means of the word are rebuilt from its senses.
+// @Tags        words
+// @Produce     json
+// @Param       id path int true "Word ID"
+// @Param       sense_id path int true "Sense ID"
+// @Success     200 {object} Response "Word sense deleted successfully"
+// @Failure     403 {object} Response "Missing content.update permission"
+// @Failure     404 {object} Response "Sense not found"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/words/{id}/senses/{sense_id} [delete]
+func (server *Server) deleteWordSense(ctx *gin.Context) {
+	var uri wordSenseURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid word or sense ID", err)
+		return
+	}
+
+	if err := server.senses.Delete(ctx, uri.ID, uri.SenseID); err != nil {
+		wordSenseError(ctx, err, "Failed to delete word sense")
+		return
+	}
+	server.forgetCachedWord(uri.ID)
+
+	SuccessResponse(ctx, http.StatusOK, "Word sense deleted successfully", nil)
+}
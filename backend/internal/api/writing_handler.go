@@ -3,16 +3,23 @@ package api
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/sqlc-dev/pqtype"
+	"github.com/toeic-app/internal/accesslog"
 	"github.com/toeic-app/internal/ai"
+	"github.com/toeic-app/internal/ailimit"
+	"github.com/toeic-app/internal/authoring"
+	"github.com/toeic-app/internal/billing"
 	db "github.com/toeic-app/internal/db/sqlc"
 	"github.com/toeic-app/internal/logger"
 	"github.com/toeic-app/internal/token"
+	"github.com/toeic-app/internal/writingservice"
+	"github.com/toeic-app/internal/writingtopic"
 )
 
 // WritingPromptResponse defines the structure for writing prompt information returned to clients
@@ -22,6 +29,7 @@ type WritingPromptResponse struct {
 	PromptText      string    `json:"prompt_text"`
 	Topic           *string   `json:"topic,omitempty"`
 	DifficultyLevel *string   `json:"difficulty_level,omitempty"`
+	TopicID         *int32    `json:"topic_id,omitempty"` // Writing topic the prompt is filed under
 	CreatedAt       time.Time `json:"created_at"`
 }
 
@@ -52,6 +60,29 @@ func NewWritingPromptResponse(prompt db.WritingPrompt) WritingPromptResponse {
 	}
 }
 
+// newWritingPromptResponses creates responses for prompts with the topics they are filed under
+func (server *Server) newWritingPromptResponses(ctx *gin.Context, prompts []db.WritingPrompt) []WritingPromptResponse {
+	ids := make([]int32, 0, len(prompts))
+	for _, prompt := range prompts {
+		ids = append(ids, prompt.ID)
+	}
+	topics, err := server.writingTopics.TopicsOf(ctx, ids)
+	if err != nil {
+		// Prompts are still useful without their topic
+		logger.Warn("Failed to get topics of writing prompts: %v", err)
+	}
+
+	responses := make([]WritingPromptResponse, 0, len(prompts))
+	for _, prompt := range prompts {
+		response := NewWritingPromptResponse(prompt)
+		if topicID, ok := topics[prompt.ID]; ok {
+			response.TopicID = &topicID
+		}
+		responses = append(responses, response)
+	}
+	return responses
+}
+
 // UserWritingResponse defines the structure for user writing information returned to clients
 // @Description Response object for user writing submissions
 type UserWritingResponse struct {
@@ -111,13 +142,14 @@ func NewUserWritingResponse(writing db.UserWriting) UserWritingResponse {
 // createWritingPromptRequest defines the structure for creating a new writing prompt
 type createWritingPromptRequest struct {
 	UserID          *int32  `json:"user_id,omitempty"`
-	PromptText      string  `json:"prompt_text" binding:"required"`
-	Topic           *string `json:"topic,omitempty"`
-	DifficultyLevel *string `json:"difficulty_level,omitempty"`
+	PromptText      string  `json:"prompt_text" binding:"required" sanitize:"markdown"`
+	Topic           *string `json:"topic,omitempty" sanitize:"plain"`
+	DifficultyLevel *string `json:"difficulty_level,omitempty" binding:"omitempty,difficulty_level"`
+	TopicID         *int32  `json:"topic_id,omitempty" binding:"omitempty,min=1"`
 }
 
 // @Summary     Create a new writing prompt
-// @Description Add a new writing prompt to the database
+// @Description Add a new writing prompt to the database, optionally filed under a writing topic
 // @Tags        writing
 // @Accept      json
 // @Produce     json
@@ -133,6 +165,7 @@ func (server *Server) createWritingPrompt(ctx *gin.Context) {
 		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
 		return
 	}
+	server.sanitizer.Struct(&req)
 
 	var userID sql.NullInt32
 	if req.UserID != nil {
@@ -158,6 +191,17 @@ func (server *Server) createWritingPrompt(ctx *gin.Context) {
 		}
 	}
 
+	if req.TopicID != nil {
+		if _, err := server.writingTopics.Get(ctx, *req.TopicID); err != nil {
+			if errors.Is(err, writingtopic.ErrTopicNotFound) {
+				ErrorResponse(ctx, http.StatusBadRequest, "Writing topic not found", err)
+				return
+			}
+			ErrorResponse(ctx, http.StatusInternalServerError, "Failed to create writing prompt", err)
+			return
+		}
+	}
+
 	arg := db.CreateWritingPromptParams{
 		UserID:          userID,
 		PromptText:      req.PromptText,
@@ -170,8 +214,17 @@ func (server *Server) createWritingPrompt(ctx *gin.Context) {
 		return
 	}
 
+	response := NewWritingPromptResponse(prompt)
+	if req.TopicID != nil {
+		if err := server.writingTopics.Assign(ctx, prompt.ID, req.TopicID); err != nil {
+			ErrorResponse(ctx, http.StatusInternalServerError, "Failed to file writing prompt under topic", err)
+			return
+		}
+		response.TopicID = req.TopicID
+	}
+
 	logger.Debug("Created new writing prompt with ID: %d", prompt.ID)
-	SuccessResponse(ctx, http.StatusCreated, "Writing prompt created successfully", NewWritingPromptResponse(prompt))
+	SuccessResponse(ctx, http.StatusCreated, "Writing prompt created successfully", response)
 }
 
 // getWritingPromptRequest defines the structure for requests to get a writing prompt by ID
@@ -198,6 +251,10 @@ func (server *Server) getWritingPrompt(ctx *gin.Context) {
 		return
 	}
 	prompt, err := server.store.GetWritingPrompt(ctx, req.ID)
+	if err == nil && prompt.Status != authoring.StatusPublished {
+		// Drafts are only visible through the authoring endpoints
+		err = sql.ErrNoRows
+	}
 	if err != nil {
 		if err == sql.ErrNoRows {
 			ErrorResponse(ctx, http.StatusNotFound, "Writing prompt not found", err)
@@ -208,48 +265,66 @@ func (server *Server) getWritingPrompt(ctx *gin.Context) {
 	}
 
 	logger.Debug("Retrieved writing prompt with ID: %d", prompt.ID)
-	SuccessResponse(ctx, http.StatusOK, "Writing prompt retrieved successfully", NewWritingPromptResponse(prompt))
+	SuccessResponse(ctx, http.StatusOK, "Writing prompt retrieved successfully", server.newWritingPromptResponses(ctx, []db.WritingPrompt{prompt})[0])
+}
+
+// listWritingPromptsQuery filters writing prompts
+type listWritingPromptsQuery struct {
+	TopicID int32 `form:"topic_id" binding:"min=0"`
 }
 
 // @Summary     List all writing prompts
-// @Description Get a list of all writing prompts
+// @Description Get a list of all writing prompts. With topic_id, only prompts filed under that writing topic or one of its subtopics are listed.
 // @Tags        writing
 // @Accept      json
 // @Produce     json
+// @Param       topic_id query int false "Writing topic ID"
 // @Success     200 {object} Response{data=[]WritingPromptResponse} "Writing prompts retrieved successfully"
+// @Failure     400 {object} Response "Invalid query parameters"
+// @Failure     404 {object} Response "Writing topic not found"
 // @Failure     500 {object} Response "Failed to retrieve writing prompts"
 // @Security    ApiKeyAuth
 // @Router      /api/v1/writing/prompts [get]
 func (server *Server) listWritingPrompts(ctx *gin.Context) {
-	prompts, err := server.store.ListWritingPrompts(ctx)
-	if err != nil {
-		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve writing prompts", err)
+	var query listWritingPromptsQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid query parameters", err)
 		return
 	}
 
-	var promptResponses []WritingPromptResponse
-	for _, prompt := range prompts {
-		promptResponses = append(promptResponses, NewWritingPromptResponse(prompt))
+	var prompts []db.WritingPrompt
+	var err error
+	if query.TopicID != 0 {
+		prompts, err = server.writingTopics.Prompts(ctx, query.TopicID)
+	} else {
+		prompts, err = server.store.ListWritingPrompts(ctx)
 	}
-
-	// Ensure we return an empty array instead of null if no results
-	if promptResponses == nil {
-		promptResponses = []WritingPromptResponse{}
+	if err != nil {
+		if errors.Is(err, writingtopic.ErrTopicNotFound) {
+			ErrorResponse(ctx, http.StatusNotFound, "Writing topic not found", err)
+			return
+		}
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve writing prompts", err)
+		return
 	}
 
+	promptResponses := server.newWritingPromptResponses(ctx, prompts)
+
 	logger.Debug("Retrieved %d writing prompts", len(promptResponses))
 	SuccessResponse(ctx, http.StatusOK, "Writing prompts retrieved successfully", promptResponses)
 }
 
 // updateWritingPromptRequest defines the structure for updating an existing writing prompt
 type updateWritingPromptRequest struct {
-	PromptText      *string `json:"prompt_text,omitempty"`
-	Topic           *string `json:"topic,omitempty"`
-	DifficultyLevel *string `json:"difficulty_level,omitempty"`
+	PromptText      *string `json:"prompt_text,omitempty" sanitize:"markdown"`
+	Topic           *string `json:"topic,omitempty" sanitize:"plain"`
+	DifficultyLevel *string `json:"difficulty_level,omitempty" binding:"omitempty,difficulty_level"`
+	// TopicID files the prompt under a writing topic; 0 removes it from the taxonomy
+	TopicID *int32 `json:"topic_id,omitempty" binding:"omitempty,min=0"`
 }
 
 // @Summary     Update a writing prompt
-// @Description Update an existing writing prompt by ID
+// @Description Update an existing writing prompt by ID. topic_id files the prompt under a writing topic; 0 removes it from the taxonomy.
 // @Tags        writing
 // @Accept      json
 // @Produce     json
@@ -273,6 +348,7 @@ func (server *Server) updateWritingPrompt(ctx *gin.Context) {
 		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
 		return
 	}
+	server.sanitizer.Struct(&req)
 
 	// Get existing prompt to update only provided fields
 	existingPrompt, err := server.store.GetWritingPrompt(ctx, int32(promptID))
@@ -308,6 +384,20 @@ func (server *Server) updateWritingPrompt(ctx *gin.Context) {
 			Valid:  true,
 		}
 	}
+	if req.TopicID != nil {
+		var topicID *int32
+		if *req.TopicID != 0 {
+			topicID = req.TopicID
+		}
+		if err := server.writingTopics.Assign(ctx, int32(promptID), topicID); err != nil {
+			if errors.Is(err, writingtopic.ErrTopicNotFound) {
+				ErrorResponse(ctx, http.StatusBadRequest, "Writing topic not found", err)
+				return
+			}
+			ErrorResponse(ctx, http.StatusInternalServerError, "Failed to file writing prompt under topic", err)
+			return
+		}
+	}
 	prompt, err := server.store.UpdateWritingPrompt(ctx, arg)
 	if err != nil {
 		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to update writing prompt", err)
@@ -315,7 +405,7 @@ func (server *Server) updateWritingPrompt(ctx *gin.Context) {
 	}
 
 	logger.Debug("Updated writing prompt with ID: %d", prompt.ID)
-	SuccessResponse(ctx, http.StatusOK, "Writing prompt updated successfully", NewWritingPromptResponse(prompt))
+	SuccessResponse(ctx, http.StatusOK, "Writing prompt updated successfully", server.newWritingPromptResponses(ctx, []db.WritingPrompt{prompt})[0])
 }
 
 // @Summary     Delete a writing prompt
@@ -376,38 +466,13 @@ func (server *Server) createUserWriting(ctx *gin.Context) {
 		return
 	}
 
-	var promptID sql.NullInt32
-	if req.PromptID != nil {
-		promptID = sql.NullInt32{
-			Int32: *req.PromptID,
-			Valid: true,
-		}
-	}
-
-	var aiFeedback pqtype.NullRawMessage
-	if req.AIFeedback != nil {
-		aiFeedback = pqtype.NullRawMessage{
-			RawMessage: req.AIFeedback,
-			Valid:      true,
-		}
-	}
-
-	var aiScore sql.NullString
-	if req.AIScore != nil {
-		aiScore = sql.NullString{
-			String: strconv.FormatFloat(*req.AIScore, 'f', 2, 64),
-			Valid:  true,
-		}
-	}
-
-	arg := db.CreateUserWritingParams{
-		UserID:         req.UserID,
-		PromptID:       promptID,
-		SubmissionText: req.SubmissionText,
-		AiFeedback:     aiFeedback,
-		AiScore:        aiScore,
-	}
-	writing, err := server.store.CreateUserWriting(ctx, arg)
+	writing, err := server.writings.Create(ctx, writingservice.NewSubmission{
+		UserID:     req.UserID,
+		PromptID:   req.PromptID,
+		Text:       req.SubmissionText,
+		AIFeedback: req.AIFeedback,
+		AIScore:    req.AIScore,
+	})
 	if err != nil {
 		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to create user writing submission", err)
 		return
@@ -417,17 +482,31 @@ func (server *Server) createUserWriting(ctx *gin.Context) {
 	SuccessResponse(ctx, http.StatusCreated, "User writing submission created successfully", NewUserWritingResponse(writing))
 }
 
+// writingAccess describes a read of a writing submission for the access log
+func writingAccess(writing db.UserWriting) accesslog.Access {
+	return accesslog.Access{SubjectID: writing.UserID, ResourceType: accesslog.ResourceWriting, ResourceID: writing.ID}
+}
+
+func writingAccesses(writings []db.UserWriting) []accesslog.Access {
+	accesses := make([]accesslog.Access, 0, len(writings))
+	for _, writing := range writings {
+		accesses = append(accesses, writingAccess(writing))
+	}
+	return accesses
+}
+
 // getUserWritingRequest defines the structure for requests to get a user writing by ID
 type getUserWritingRequest struct {
 	ID int32 `uri:"id" binding:"required,min=1"`
 }
 
 // @Summary     Get a user writing submission by ID
-// @Description Retrieve a specific user writing submission by its ID
+// @Description Retrieve a specific user writing submission by its ID. Reading another user's submission is recorded in their access log.
 // @Tags        writing
 // @Accept      json
 // @Produce     json
 // @Param       id path int true "User Writing Submission ID"
+// @Param       X-Access-Purpose header string false "Purpose of reading another user's data: support, grading, moderation, legal or unspecified"
 // @Success     200 {object} Response{data=UserWritingResponse} "User writing submission retrieved successfully"
 // @Failure     400 {object} Response "Invalid submission ID"
 // @Failure     404 {object} Response "User writing submission not found"
@@ -440,13 +519,12 @@ func (server *Server) getUserWriting(ctx *gin.Context) {
 		ErrorResponse(ctx, http.StatusBadRequest, "Invalid submission ID", err)
 		return
 	}
-	writing, err := server.store.GetUserWriting(ctx, req.ID)
+	writing, err := server.writings.Get(ctx, req.ID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ErrorResponse(ctx, http.StatusNotFound, "User writing submission not found", err)
-			return
-		}
-		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve user writing submission", err)
+		writingServiceError(ctx, err, "Failed to retrieve user writing submission")
+		return
+	}
+	if !server.recordDataAccess(ctx, writingAccess(writing)) {
 		return
 	}
 
@@ -454,17 +532,92 @@ func (server *Server) getUserWriting(ctx *gin.Context) {
 	SuccessResponse(ctx, http.StatusOK, "User writing submission retrieved successfully", NewUserWritingResponse(writing))
 }
 
+// searchUserWritingsQuery filters a full-text search of writing submissions
+type searchUserWritingsQuery struct {
+	Q        string    `form:"q" binding:"required,min=2,max=200" example:"subject verb agreement"`
+	ClassID  int32     `form:"class_id" binding:"min=0"`
+	UserID   int32     `form:"user_id" binding:"min=0"`
+	From     time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To       time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00" binding:"omitempty,gtfield=From"`
+	MinScore *float64  `form:"min_score" binding:"omitempty,min=0,max=200"`
+	MaxScore *float64  `form:"max_score" binding:"omitempty,min=0,max=200"`
+	Limit    int32     `form:"limit,default=20" binding:"min=1,max=100"`
+	Offset   int32     `form:"offset" binding:"min=0"`
+}
+
+// @Summary     Search writing submissions
+// @Description Full-text search over the text and AI feedback of the writing submissions of learners in my organizations, best match first, for reviewing common error patterns. q takes words, "quoted phrases", or and -excluded words. The matching passages are returned with matches marked **like this**. class_id only searches learners of an LTI course I am a member of. Matches are recorded in the learners' access logs. Requires writing.evaluate.
+// @Tags        writing
+// @Produce     json
+// @Param       q query string true "Words or phrases to find"
+// @Param       class_id query int false "Only learners of this class (LTI course ID)"
+// @Param       user_id query int false "Only this learner"
+// @Param       from query string false "Only submissions made at or after this time (RFC 3339)"
+// @Param       to query string false "Only submissions made before this time (RFC 3339)"
+// @Param       min_score query number false "Lowest AI score"
+// @Param       max_score query number false "Highest AI score"
+// @Param       limit query int false "Maximum submissions" default(20)
+// @Param       offset query int false "Submissions to skip" default(0)
+// @Param       X-Access-Purpose header string false "Purpose of reading another user's data: support, grading, moderation, legal or unspecified"
+// @Success     200 {object} Response{data=[]writingservice.Match} "Writing submissions found"
+// @Failure     400 {object} Response "Invalid query parameters"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     403 {object} Response "Permission denied"
+// @Failure     500 {object} Response "Failed to search writing submissions"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/writing/submissions/search [get]
+func (server *Server) searchUserWritings(ctx *gin.Context) {
+	var query searchUserWritingsQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid query parameters", err)
+		return
+	}
+	if query.MinScore != nil && query.MaxScore != nil && *query.MinScore > *query.MaxScore {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid query parameters", errors.New("min_score is above max_score"))
+		return
+	}
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	matches, total, err := server.writings.Search(ctx, writingservice.SearchQuery{
+		TeacherID: authPayload.ID,
+		Text:      strings.TrimSpace(query.Q),
+		ClassID:   query.ClassID,
+		UserID:    query.UserID,
+		From:      query.From,
+		To:        query.To,
+		MinScore:  query.MinScore,
+		MaxScore:  query.MaxScore,
+		Limit:     query.Limit,
+		Offset:    query.Offset,
+	})
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to search writing submissions", err)
+		return
+	}
+	accesses := make([]accesslog.Access, 0, len(matches))
+	for _, match := range matches {
+		accesses = append(accesses, accesslog.Access{SubjectID: match.UserID, ResourceType: accesslog.ResourceWriting, ResourceID: match.ID})
+	}
+	if !server.recordDataAccess(ctx, accesses...) {
+		return
+	}
+
+	PaginatedResponse(ctx, http.StatusOK, "Writing submissions found", matches,
+		NewPagination(query.Limit, query.Offset, len(matches)).WithTotal(total))
+}
+
 // listUserWritingsByUserIDRequest defines the structure for listing user writings by user ID
 type listUserWritingsByUserIDRequest struct {
 	UserID int32 `uri:"user_id" binding:"required,min=1"`
 }
 
 // @Summary     List user writing submissions by user ID
-// @Description Get a list of all writing submissions for a specific user
+// @Description Get a list of all writing submissions for a specific user. Reading another user's submissions is recorded in their access log.
 // @Tags        writing
 // @Accept      json
 // @Produce     json
 // @Param       user_id path int true "User ID"
+// @Param       X-Access-Purpose header string false "Purpose of reading another user's data: support, grading, moderation, legal or unspecified"
 // @Success     200 {object} Response{data=[]UserWritingResponse} "User writing submissions retrieved successfully"
 // @Failure     400 {object} Response "Invalid user ID"
 // @Failure     500 {object} Response "Failed to retrieve user writing submissions"
@@ -477,11 +630,14 @@ func (server *Server) listUserWritingsByUserID(ctx *gin.Context) {
 		return
 	}
 
-	writings, err := server.store.ListUserWritingsByUserID(ctx, req.UserID)
+	writings, err := server.writings.ListByUser(ctx, req.UserID)
 	if err != nil {
 		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve user writing submissions", err)
 		return
 	}
+	if !server.recordDataAccess(ctx, writingAccesses(writings)...) {
+		return
+	}
 	var writingResponses []UserWritingResponse
 	for _, writing := range writings {
 		writingResponses = append(writingResponses, NewUserWritingResponse(writing))
@@ -502,11 +658,12 @@ type listUserWritingsByPromptIDRequest struct {
 }
 
 // @Summary     List user writing submissions by prompt ID
-// @Description Get a list of all writing submissions for a specific prompt
+// @Description Get a list of all writing submissions for a specific prompt. Reads of other users' submissions are recorded in their access logs.
 // @Tags        writing
 // @Accept      json
 // @Produce     json
 // @Param       prompt_id path int true "Prompt ID"
+// @Param       X-Access-Purpose header string false "Purpose of reading another user's data: support, grading, moderation, legal or unspecified"
 // @Success     200 {object} Response{data=[]UserWritingResponse} "User writing submissions retrieved successfully"
 // @Failure     400 {object} Response "Invalid prompt ID"
 // @Failure     500 {object} Response "Failed to retrieve user writing submissions"
@@ -519,17 +676,14 @@ func (server *Server) listUserWritingsByPromptID(ctx *gin.Context) {
 		return
 	}
 
-	// Create NullInt32 for the query
-	promptID := sql.NullInt32{
-		Int32: req.PromptID,
-		Valid: true,
-	}
-
-	writings, err := server.store.ListUserWritingsByPromptID(ctx, promptID)
+	writings, err := server.writings.ListByPrompt(ctx, req.PromptID)
 	if err != nil {
 		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve user writing submissions", err)
 		return
 	}
+	if !server.recordDataAccess(ctx, writingAccesses(writings)...) {
+		return
+	}
 	var writingResponses []UserWritingResponse
 	for _, writing := range writings {
 		writingResponses = append(writingResponses, NewUserWritingResponse(writing))
@@ -579,57 +733,15 @@ func (server *Server) updateUserWriting(ctx *gin.Context) {
 		return
 	}
 
-	// Get existing writing to update only provided fields
-	existingWriting, err := server.store.GetUserWriting(ctx, int32(writingID))
-	if err != nil {
-		if err == sql.ErrNoRows {
-			ErrorResponse(ctx, http.StatusNotFound, "User writing submission not found", err)
-			return
-		}
-		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve user writing submission for update", err)
-		return
-	}
-	// Prepare update parameters
-	submissionText := existingWriting.SubmissionText
-	if req.SubmissionText != nil {
-		submissionText = *req.SubmissionText
-	}
-
-	aiFeedback := existingWriting.AiFeedback
-	if req.AIFeedback != nil {
-		aiFeedback = pqtype.NullRawMessage{
-			RawMessage: req.AIFeedback,
-			Valid:      true,
-		}
-	}
-
-	aiScore := existingWriting.AiScore
-	if req.AIScore != nil {
-		aiScore = sql.NullString{
-			String: strconv.FormatFloat(*req.AIScore, 'f', 2, 64),
-			Valid:  true,
-		}
-	}
-
-	evaluatedAt := existingWriting.EvaluatedAt
-	if req.EvaluatedAt != nil {
-		evaluatedAt = sql.NullTime{
-			Time:  *req.EvaluatedAt,
-			Valid: true,
-		}
-	}
-
-	arg := db.UpdateUserWritingParams{
-		ID:             int32(writingID),
-		SubmissionText: submissionText,
-		AiFeedback:     aiFeedback,
-		AiScore:        aiScore,
-		EvaluatedAt:    evaluatedAt,
-	}
-
-	writing, err := server.store.UpdateUserWriting(ctx, arg)
+	// Only the provided fields are updated
+	writing, err := server.writings.Update(ctx, int32(writingID), writingservice.Changes{
+		Text:        req.SubmissionText,
+		AIFeedback:  req.AIFeedback,
+		AIScore:     req.AIScore,
+		EvaluatedAt: req.EvaluatedAt,
+	})
 	if err != nil {
-		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to update user writing submission", err)
+		writingServiceError(ctx, err, "Failed to update user writing submission")
 		return
 	}
 
@@ -654,7 +766,7 @@ func (server *Server) deleteUserWriting(ctx *gin.Context) {
 		return
 	}
 
-	err = server.store.DeleteUserWriting(ctx, int32(writingID))
+	err = server.writings.Delete(ctx, int32(writingID))
 	if err != nil {
 		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to delete user writing submission", err)
 		return
@@ -665,25 +777,29 @@ func (server *Server) deleteUserWriting(ctx *gin.Context) {
 
 // scoreWritingRequest defines the structure for AI scoring request
 type scoreWritingRequest struct {
-	SubmissionID *int32 `json:"submission_id,omitempty"` // If provided, update this submission
-	Text         string `json:"text"`                    // Text to score (required if no submission_id)
+	SubmissionID *int32 `json:"submission_id,omitempty"`                       // If provided, update this submission
+	Text         string `json:"text"`                                          // Text to score (required if no submission_id)
+	PromptID     *int32 `json:"prompt_id,omitempty" binding:"omitempty,min=1"` // Prompt the text answers, selecting its rubric
 }
 
 // scoreWritingResponse defines the response structure for AI scoring
 type scoreWritingResponse struct {
 	UserID      int32                  `json:"user_id"`
-	Score       int                    `json:"score"`       // 0-200 TOEIC writing score
-	Band        string                 `json:"band"`        // TOEIC band level
-	Feedback    map[string]interface{} `json:"feedback"`    // Detailed feedback
-	Suggestions []string               `json:"suggestions"` // Improvement suggestions
-	Confidence  float64                `json:"confidence"`  // AI confidence score (0-1)
+	Score       int                    `json:"score" example:"150"` // 0-200 TOEIC writing score
+	Band        ai.TOEICBand           `json:"band" example:"7"`    // TOEIC band level
+	Feedback    map[string]interface{} `json:"feedback"`            // Detailed feedback
+	Rubric      string                 `json:"rubric,omitempty"`    // Rubric the writing was scored on
+	Criteria    []ai.CriterionScore    `json:"criteria,omitempty"`  // Weighted scores on the rubric criteria
+	Suggestions []string               `json:"suggestions"`         // Improvement suggestions
+	Confidence  float64                `json:"confidence"`          // AI confidence score (0-1)
 	ProcessedAt string                 `json:"processed_at"`
-	Text        string                 `json:"text"`
+	Text        string                 `json:"text"`                // Text scored, the beginning of a truncated text
+	Truncated   bool                   `json:"truncated,omitempty"` // Set when the text was over the writing limit and truncated
 	PromptID    *int32                 `json:"prompt_id,omitempty"`
 }
 
 // @Summary Score writing submission using AI
-// @Description Score a writing submission using AI to get TOEIC band assessment and detailed feedback. If submission_id is provided, the submission will be updated with AI scores.
+// @Description Score a writing submission using AI to get TOEIC band assessment and detailed feedback. If submission_id is provided, the submission will be updated with AI scores. The writing is scored on the rubric of its prompt, the prompt of the submission or prompt_id, and criteria holds the score of each weighted rubric criterion. Texts over AI_MAX_WRITING_WORDS or AI_MAX_WRITING_CHARS are refused with 413, or scored on their beginning when AI_OVERSIZE_MODE is truncate. Requests count against the AI rate limit bucket.
 // @Tags writing
 // @Accept json
 // @Produce json
@@ -691,6 +807,9 @@ type scoreWritingResponse struct {
 // @Success 200 {object} Response{data=scoreWritingResponse} "Writing scored successfully"
 // @Failure 400 {object} Response "Invalid request parameters"
 // @Failure 401 {object} Response "Unauthorized"
+// @Failure 402 {object} Response "Daily AI scoring limit reached"
+// @Failure 413 {object} Response "Text too long for AI scoring"
+// @Failure 429 {object} Response "AI rate limit exceeded"
 // @Failure 503 {object} Response "AI scoring service unavailable"
 // @Failure 500 {object} Response "Server error"
 // @Security ApiKeyAuth
@@ -715,149 +834,57 @@ func (server *Server) scoreWriting(ctx *gin.Context) {
 		return
 	}
 
-	var textToScore string
-	var existingSubmission *db.UserWriting
-	var promptID *int32
-
-	// If submission_id is provided, get the submission and check if it already has AI scores
-	if req.SubmissionID != nil {
-		submission, err := server.store.GetUserWriting(ctx, *req.SubmissionID)
-		if err != nil {
-			if err == sql.ErrNoRows {
-				ErrorResponse(ctx, http.StatusNotFound, "Writing submission not found", err)
-				return
-			}
-			ErrorResponse(ctx, http.StatusInternalServerError, "Failed to retrieve writing submission", err)
-			return
-		}
-
-		// Check if user owns this submission
-		if submission.UserID != authPayload.ID {
-			ErrorResponse(ctx, http.StatusForbidden, "You can only score your own submissions", nil)
-			return
-		}
-
-		existingSubmission = &submission
-		textToScore = submission.SubmissionText
-
-		// Get prompt ID if available
-		if submission.PromptID.Valid {
-			promptID = &submission.PromptID.Int32
-		}
-
-		// Check if this submission already has AI scores - if yes, return cached result
-		if submission.AiScore.Valid && submission.AiFeedback.Valid {
-			logger.Info("Returning cached AI score for submission %d", *req.SubmissionID)
-
-			// Parse existing feedback
-			var feedbackMap map[string]interface{}
-			if err := json.Unmarshal(submission.AiFeedback.RawMessage, &feedbackMap); err != nil {
-				logger.Warn("Failed to parse existing AI feedback, regenerating score: %v", err)
-			} else {
-				// Return cached result
-				aiScore, _ := strconv.ParseFloat(submission.AiScore.String, 64)
-				response := scoreWritingResponse{
-					UserID:      authPayload.ID,
-					Score:       int(aiScore),
-					Band:        string(ai.BandLevel1), // You might want to store band separately
-					Feedback:    feedbackMap,
-					Suggestions: []string{}, // Extract from feedback if needed
-					Confidence:  0.9,        // High confidence for cached results
-					ProcessedAt: submission.EvaluatedAt.Time.Format(time.RFC3339),
-					Text:        textToScore,
-				}
-
-				SuccessResponse(ctx, http.StatusOK, "Cached AI score retrieved", response)
-				return
-			}
-		}
-	} else {
-		// If no submission_id, use the provided text
-		if req.Text == "" {
-			ErrorResponse(ctx, http.StatusBadRequest, "Either submission_id or text must be provided", nil)
-			return
-		}
-		textToScore = req.Text
-	}
-
-	// Check if AI scoring service is available
-	if server.aiScoringService == nil {
-		ErrorResponse(ctx, http.StatusServiceUnavailable, "AI scoring service not available", nil)
-		return
-	}
-
-	if !server.aiScoringService.IsHealthy() {
-		ErrorResponse(ctx, http.StatusServiceUnavailable, "AI scoring service is not healthy", nil)
-		return
-	}
-
-	// Create AI scoring request
-	aiReq := ai.AIScoreRequest{
-		Text:     textToScore,
-		PromptID: promptID,
-		UserID:   authPayload.ID,
-	}
-
-	// Score the writing using AI
-	aiResponse, err := server.aiScoringService.ScoreWriting(ctx, aiReq)
+	// Submissions scored before return their kept score
+	result, err := server.writings.Score(ctx, authPayload.ID, writingservice.ScoreRequest{
+		SubmissionID: req.SubmissionID,
+		Text:         req.Text,
+		PromptID:     req.PromptID,
+	})
 	if err != nil {
-		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to score writing", err)
+		writingServiceError(ctx, err, "Failed to score writing")
 		return
 	}
 
-	// Convert feedback to map for JSON response
-	feedbackMap := map[string]interface{}{
-		"grammar":       aiResponse.Feedback.Grammar,
-		"vocabulary":    aiResponse.Feedback.Vocabulary,
-		"organization":  aiResponse.Feedback.Organization,
-		"development":   aiResponse.Feedback.Development,
-		"task_response": aiResponse.Feedback.TaskResponse,
-		"language_use":  aiResponse.Feedback.LanguageUse,
-		"overall":       aiResponse.Feedback.Overall,
-	}
-
 	response := scoreWritingResponse{
 		UserID:      authPayload.ID,
-		Score:       aiResponse.Score,
-		Band:        string(aiResponse.Band),
-		Feedback:    feedbackMap,
-		Suggestions: aiResponse.Suggestions,
-		Confidence:  aiResponse.Confidence,
-		ProcessedAt: aiResponse.ProcessedAt.Format(time.RFC3339),
-		Text:        textToScore,
-	}
-
-	// If submission ID is provided, update the submission with AI score immediately
-	if req.SubmissionID != nil && existingSubmission != nil {
-		aiFeedbackJSON, _ := json.Marshal(feedbackMap)
-		aiScore := float64(aiResponse.Score)
-		evaluatedAt := time.Now()
-
-		updateParams := db.UpdateUserWritingParams{
-			ID:             *req.SubmissionID,
-			SubmissionText: existingSubmission.SubmissionText, // Keep existing text
-			AiFeedback: pqtype.NullRawMessage{
-				RawMessage: aiFeedbackJSON,
-				Valid:      true,
-			},
-			AiScore: sql.NullString{
-				String: strconv.FormatFloat(aiScore, 'f', 2, 64),
-				Valid:  true,
-			},
-			EvaluatedAt: sql.NullTime{
-				Time:  evaluatedAt,
-				Valid: true,
-			},
-		}
-
-		if _, err := server.store.UpdateUserWriting(ctx, updateParams); err != nil {
-			logger.Error("Failed to update writing submission %d with AI score: %v", *req.SubmissionID, err)
-			// Continue anyway and return the score, but log the error
-		} else {
-			logger.Info("Updated writing submission %d with AI score %d", *req.SubmissionID, aiResponse.Score)
-		}
+		Score:       result.Score,
+		Band:        ai.TOEICBand(result.Band),
+		Feedback:    result.Feedback,
+		Rubric:      result.Rubric,
+		Criteria:    result.Criteria,
+		Suggestions: result.Suggestions,
+		Confidence:  result.Confidence,
+		ProcessedAt: result.ProcessedAt.Format(time.RFC3339),
+		Text:        result.Text,
+		Truncated:   result.Truncated,
+		PromptID:    req.PromptID,
+	}
+	if result.Cached {
+		SuccessResponse(ctx, http.StatusOK, "Cached AI score retrieved", response)
+		return
 	}
 
-	logger.Info("Scored writing for user %d: Score=%d, Band=%s", authPayload.ID, aiResponse.Score, aiResponse.Band)
+	logger.Info("Scored writing for user %d: Score=%d, Band=%s", authPayload.ID, result.Score, result.Band)
 	SuccessResponse(ctx, http.StatusOK, "Writing scored successfully", response)
 }
+
+// writingServiceError responds to an error of the writing service, with
+// message when the error is unexpected
+func writingServiceError(ctx *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, writingservice.ErrSubmissionNotFound):
+		ErrorResponse(ctx, http.StatusNotFound, "User writing submission not found", err)
+	case errors.Is(err, writingservice.ErrNotOwner):
+		ErrorResponse(ctx, http.StatusForbidden, "You can only score your own submissions", nil)
+	case errors.Is(err, writingservice.ErrNoText):
+		ErrorResponse(ctx, http.StatusBadRequest, "Either submission_id or text must be provided", nil)
+	case errors.Is(err, writingservice.ErrScorerUnavailable), errors.Is(err, writingservice.ErrScorerUnhealthy):
+		ErrorResponse(ctx, http.StatusServiceUnavailable, "AI scoring service not available", err)
+	case errors.Is(err, ailimit.ErrTooLong):
+		ErrorResponse(ctx, http.StatusRequestEntityTooLarge, "Text too long for AI scoring", err)
+	case errors.Is(err, billing.ErrQuotaExceeded):
+		ErrorResponse(ctx, http.StatusPaymentRequired, "Daily AI scoring limit reached, upgrade for unlimited scoring", err)
+	default:
+		ErrorResponse(ctx, http.StatusInternalServerError, message, err)
+	}
+}
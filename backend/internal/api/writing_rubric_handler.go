@@ -0,0 +1,206 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/ai"
+	"github.com/toeic-app/internal/token"
+	"github.com/toeic-app/internal/writingrubric"
+)
+
+// rubricCriterionRequest is a criterion of a writing rubric
+type rubricCriterionRequest struct {
+	Key         string `json:"key" binding:"required,max=32" example:"task_response"`
+	Name        string `json:"name" binding:"required,max=100" sanitize:"plain" example:"Task completion"`
+	Description string `json:"description" binding:"max=500" sanitize:"plain" example:"Every request or question of the email is answered"`
+	// Percentage of the score; the weights of a rubric add up to 100
+	Weight int `json:"weight" binding:"min=1,max=100" example:"40"`
+}
+
+// writingRubricRequest creates or changes a writing rubric. topic_id and
+// prompt_id are only read when the rubric is created.
+type writingRubricRequest struct {
+	TopicID      *int32                   `json:"topic_id,omitempty" binding:"omitempty,min=1" example:"2"`
+	PromptID     *int32                   `json:"prompt_id,omitempty" binding:"omitempty,min=1"`
+	Name         string                   `json:"name" binding:"required,max=100" sanitize:"plain" example:"Business email"`
+	Instructions string                   `json:"instructions" binding:"max=2000" sanitize:"plain" example:"The response answers an email in a tone fit for business."`
+	Criteria     []rubricCriterionRequest `json:"criteria" binding:"required,min=1,dive"`
+}
+
+func (req writingRubricRequest) input() writingrubric.Input {
+	criteria := make([]ai.RubricCriterion, len(req.Criteria))
+	for i, criterion := range req.Criteria {
+		criteria[i] = ai.RubricCriterion(criterion)
+	}
+	return writingrubric.Input{
+		TopicID:      req.TopicID,
+		PromptID:     req.PromptID,
+		Name:         req.Name,
+		Instructions: req.Instructions,
+		Criteria:     criteria,
+	}
+}
+
+// @Summary     List writing rubrics
+// @Description Returns the rubrics AI scoring uses, those of writing topics first. A prompt is scored on its own rubric, else on the rubric of its topic or the nearest parent topic that has one, else on the default TOEIC writing rubric. Requires content.publish. (admin only)
+// @Tags        admin
+// @Produce     json
+// @Success     200 {object} Response{data=[]writingrubric.Rubric} "Writing rubrics retrieved successfully"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     403 {object} Response "Permission denied"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/writing-rubrics [get]
+func (server *Server) listWritingRubrics(ctx *gin.Context) {
+	rubrics, err := server.writingRubrics.List(ctx)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to list writing rubrics", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Writing rubrics retrieved successfully", rubrics)
+}
+
+// @Summary     Get a writing rubric
+// @Description Returns a writing rubric with its criteria. Requires content.publish. (admin only)
+// @Tags        admin
+// @Produce     json
+// @Param       id path int true "Writing rubric ID"
+// @Success     200 {object} Response{data=writingrubric.Rubric} "Writing rubric retrieved successfully"
+// @Failure     400 {object} Response "Invalid writing rubric ID"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     403 {object} Response "Permission denied"
+// @Failure     404 {object} Response "Writing rubric not found"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/writing-rubrics/{id} [get]
+func (server *Server) getWritingRubric(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid writing rubric ID", err)
+		return
+	}
+
+	rubric, err := server.writingRubrics.Get(ctx, int32(id))
+	if err != nil {
+		writingRubricError(ctx, err, "Failed to get writing rubric")
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Writing rubric retrieved successfully", rubric)
+}
+
+// @Summary     Create a writing rubric
+// @Description Adds the rubric of a writing topic (topic_id), such as a TOEIC writing task, or of a single prompt (prompt_id). Criteria keys are lowercase letters, digits and underscores and the weights add up to 100. Requires content.publish. (admin only)
+// @Tags        admin
+// @Accept      json
+// @Produce     json
+// @Param       rubric body writingRubricRequest true "Writing rubric"
+// @Success     201 {object} Response{data=writingrubric.Rubric} "Writing rubric created successfully"
+// @Failure     400 {object} Response "Invalid request body or criteria"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     403 {object} Response "Permission denied"
+// @Failure     404 {object} Response "Writing topic or prompt not found"
+// @Failure     409 {object} Response "The writing topic or prompt already has a rubric"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/writing-rubrics [post]
+func (server *Server) createWritingRubric(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+
+	var req writingRubricRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	server.sanitizer.Struct(&req)
+
+	rubric, err := server.writingRubrics.Create(ctx, req.input(), authPayload.ID)
+	if err != nil {
+		writingRubricError(ctx, err, "Failed to create writing rubric")
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusCreated, "Writing rubric created successfully", rubric)
+}
+
+// @Summary     Update a writing rubric
+// @Description Replaces the name, instructions and criteria of a rubric; its topic or prompt stays. Submissions scored before keep their scores. Requires content.publish. (admin only)
+// @Tags        admin
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Writing rubric ID"
+// @Param       rubric body writingRubricRequest true "Writing rubric"
+// @Success     200 {object} Response{data=writingrubric.Rubric} "Writing rubric updated successfully"
+// @Failure     400 {object} Response "Invalid request body or criteria"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     403 {object} Response "Permission denied"
+// @Failure     404 {object} Response "Writing rubric not found"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/writing-rubrics/{id} [put]
+func (server *Server) updateWritingRubric(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AuthorizationPayloadKey).(*token.Payload)
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid writing rubric ID", err)
+		return
+	}
+
+	var req writingRubricRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	server.sanitizer.Struct(&req)
+
+	rubric, err := server.writingRubrics.Update(ctx, int32(id), req.input(), authPayload.ID)
+	if err != nil {
+		writingRubricError(ctx, err, "Failed to update writing rubric")
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Writing rubric updated successfully", rubric)
+}
+
+// @Summary     Delete a writing rubric
+// @Description Removes a rubric; its prompts are scored on the rubric of their parent topic, or the default rubric, again. Requires content.publish. (admin only)
+// @Tags        admin
+// @Produce     json
+// @Param       id path int true "Writing rubric ID"
+// @Success     200 {object} Response "Writing rubric deleted successfully"
+// @Failure     400 {object} Response "Invalid writing rubric ID"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     403 {object} Response "Permission denied"
+// @Failure     404 {object} Response "Writing rubric not found"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/writing-rubrics/{id} [delete]
+func (server *Server) deleteWritingRubric(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid writing rubric ID", err)
+		return
+	}
+
+	if err := server.writingRubrics.Delete(ctx, int32(id)); err != nil {
+		writingRubricError(ctx, err, "Failed to delete writing rubric")
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Writing rubric deleted successfully", nil)
+}
+
+// writingRubricError maps writing rubric errors to HTTP responses
+func writingRubricError(ctx *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, writingrubric.ErrRubricNotFound):
+		ErrorResponse(ctx, http.StatusNotFound, "Writing rubric not found", err)
+	case errors.Is(err, writingrubric.ErrTargetNotFound):
+		ErrorResponse(ctx, http.StatusNotFound, err.Error(), err)
+	case errors.Is(err, writingrubric.ErrInvalidTarget), errors.Is(err, writingrubric.ErrInvalidCriteria):
+		ErrorResponse(ctx, http.StatusBadRequest, err.Error(), err)
+	case errors.Is(err, writingrubric.ErrRubricExists):
+		ErrorResponse(ctx, http.StatusConflict, err.Error(), err)
+	default:
+		ErrorResponse(ctx, http.StatusInternalServerError, message, err)
+	}
+}
@@ -0,0 +1,177 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/writingtopic"
+)
+
+// writingTopicRequest creates or changes a writing topic
+type writingTopicRequest struct {
+	ParentID    *int32 `json:"parent_id,omitempty" binding:"omitempty,min=1"`
+	Slug        string `json:"slug" binding:"required,max=64" example:"email-complaint"`
+	Name        string `json:"name" binding:"required,max=100" sanitize:"plain" example:"Complaints"`
+	Description string `json:"description" binding:"max=500" sanitize:"plain" example:"Respond to a customer complaint"`
+	Position    int32  `json:"position" binding:"min=0" example:"2"`
+}
+
+func (req writingTopicRequest) input() writingtopic.Input {
+	return writingtopic.Input{
+		ParentID:    req.ParentID,
+		Slug:        req.Slug,
+		Name:        req.Name,
+		Description: req.Description,
+		Position:    req.Position,
+	}
+}
+
+// @Summary     List writing topics
+// @Description Returns the writing topic taxonomy as a tree of top-level topics and their subtopics, ordered by position. Use a topic ID with GET /api/v1/writing/prompts?topic_id= to list its prompts.
+// @Tags        writing
+// @Produce     json
+// @Success     200 {object} Response{data=[]writingtopic.Topic} "Writing topics retrieved successfully"
+// @Failure     401 {object} Response "Unauthorized"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/writing/topics [get]
+func (server *Server) listWritingTopics(ctx *gin.Context) {
+	topics, err := server.writingTopics.Tree(ctx, false)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to list writing topics", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Writing topics retrieved successfully", topics)
+}
+
+// @Summary     Writing topics with prompt counts
+// @Description Returns the writing topic taxonomy with the number of prompts, and of published prompts, filed under each topic and its subtopics. Requires content.publish. (admin only)
+// @Tags        admin
+// @Produce     json
+// @Success     200 {object} Response{data=[]writingtopic.Topic} "Writing topics retrieved successfully"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     403 {object} Response "Permission denied"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/writing-topics [get]
+func (server *Server) listWritingTopicsWithCounts(ctx *gin.Context) {
+	topics, err := server.writingTopics.Tree(ctx, true)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusInternalServerError, "Failed to list writing topics", err)
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Writing topics retrieved successfully", topics)
+}
+
+// @Summary     Create a writing topic
+// @Description Adds a topic to the taxonomy, at the top level or under parent_id. Requires content.publish. (admin only)
+// @Tags        admin
+// @Accept      json
+// @Produce     json
+// @Param       topic body writingTopicRequest true "Writing topic"
+// @Success     201 {object} Response{data=writingtopic.Topic} "Writing topic created successfully"
+// @Failure     400 {object} Response "Invalid request body"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     403 {object} Response "Permission denied"
+// @Failure     404 {object} Response "Parent writing topic not found"
+// @Failure     409 {object} Response "A writing topic with this slug already exists"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/writing-topics [post]
+func (server *Server) createWritingTopic(ctx *gin.Context) {
+	var req writingTopicRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	server.sanitizer.Struct(&req)
+
+	topic, err := server.writingTopics.Create(ctx, req.input())
+	if err != nil {
+		writingTopicError(ctx, err, "Failed to create writing topic")
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusCreated, "Writing topic created successfully", topic)
+}
+
+// @Summary     Update a writing topic
+// @Description Renames a topic or moves it under another parent; parent_id omitted moves it to the top level. A topic cannot move under one of its own subtopics. Requires content.publish. (admin only)
+// @Tags        admin
+// @Accept      json
+// @Produce     json
+// @Param       id path int true "Writing topic ID"
+// @Param       topic body writingTopicRequest true "Writing topic"
+// @Success     200 {object} Response{data=writingtopic.Topic} "Writing topic updated successfully"
+// @Failure     400 {object} Response "Invalid request body"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     403 {object} Response "Permission denied"
+// @Failure     404 {object} Response "Writing topic not found"
+// @Failure     409 {object} Response "A writing topic with this slug already exists"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/writing-topics/{id} [put]
+func (server *Server) updateWritingTopic(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid writing topic ID", err)
+		return
+	}
+
+	var req writingTopicRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+	server.sanitizer.Struct(&req)
+
+	topic, err := server.writingTopics.Update(ctx, int32(id), req.input())
+	if err != nil {
+		writingTopicError(ctx, err, "Failed to update writing topic")
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Writing topic updated successfully", topic)
+}
+
+// @Summary     Delete a writing topic
+// @Description Removes a topic without subtopics or prompts. Requires content.publish. (admin only)
+// @Tags        admin
+// @Produce     json
+// @Param       id path int true "Writing topic ID"
+// @Success     200 {object} Response "Writing topic deleted successfully"
+// @Failure     400 {object} Response "Invalid writing topic ID"
+// @Failure     401 {object} Response "Unauthorized"
+// @Failure     403 {object} Response "Permission denied"
+// @Failure     404 {object} Response "Writing topic not found"
+// @Failure     409 {object} Response "Writing topic still has subtopics or prompts"
+// @Security    ApiKeyAuth
+// @Router      /api/v1/admin/writing-topics/{id} [delete]
+func (server *Server) deleteWritingTopic(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ErrorResponse(ctx, http.StatusBadRequest, "Invalid writing topic ID", err)
+		return
+	}
+
+	if err := server.writingTopics.Delete(ctx, int32(id)); err != nil {
+		writingTopicError(ctx, err, "Failed to delete writing topic")
+		return
+	}
+
+	SuccessResponse(ctx, http.StatusOK, "Writing topic deleted successfully", nil)
+}
+
+// writingTopicError maps writing topic errors to HTTP responses
+func writingTopicError(ctx *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, writingtopic.ErrTopicNotFound):
+		ErrorResponse(ctx, http.StatusNotFound, "Writing topic not found", err)
+	case errors.Is(err, writingtopic.ErrInvalidSlug), errors.Is(err, writingtopic.ErrInvalidParent):
+		ErrorResponse(ctx, http.StatusBadRequest, err.Error(), err)
+	case errors.Is(err, writingtopic.ErrSlugTaken), errors.Is(err, writingtopic.ErrTopicInUse):
+		ErrorResponse(ctx, http.StatusConflict, err.Error(), err)
+	default:
+		ErrorResponse(ctx, http.StatusInternalServerError, message, err)
+	}
+}
@@ -0,0 +1,621 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"sync"
+	"time"
+
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/logger"
+	"golang.org/x/time/rate"
+)
+
+var (
+	ErrInvalidKey    = errors.New("invalid API key")
+	ErrKeyRevoked    = errors.New("API key revoked")
+	ErrKeyNotFound   = errors.New("API key not found")
+	ErrInvalidLimits = errors.New("daily quota and requests per minute must be positive")
+	ErrInvalidName   = errors.New("API key name is required")
+	ErrInvalidEmail  = errors.New("invalid contact email")
+)
+
+// Reasons a request is refused
+const (
+	ReasonRateLimited   = "rate_limited"
+	ReasonQuotaExceeded = "quota_exceeded"
+)
+
+// keyScheme prefixes every issued key so leaked keys are easy to recognize
+const keyScheme = "tk"
+
+// keyCacheTTL bounds how long a revocation or limit change made on another
+// instance takes to apply here
+const keyCacheTTL = time.Minute
+
+// Limits are the quotas of a key
+type Limits struct {
+	DailyQuota        int32 `json:"daily_quota"`
+	RequestsPerMinute int32 `json:"requests_per_minute"`
+}
+
+func (l Limits) valid() bool {
+	return l.DailyQuota > 0 && l.RequestsPerMinute > 0
+}
+
+// Key is an issued API key without its secret
+type Key struct {
+	ID           int32      `json:"id"`
+	Name         string     `json:"name"`
+	ContactEmail string     `json:"contact_email,omitempty"`
+	Prefix       string     `json:"prefix"`
+	Limits       Limits     `json:"limits"`
+	CreatedAt    time.Time  `json:"created_at"`
+	LastUsedAt   *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+}
+
+// CreatedKey is a new key together with its plaintext value, which is only
+// available when the key is created
+type CreatedKey struct {
+	Key
+	Secret string `json:"key"`
+}
+
+// Decision is the outcome of checking a request against the quotas of a key
+type Decision struct {
+	Allowed    bool
+	Reason     string
+	Limit      int32
+	Remaining  int64
+	Reset      time.Time
+	RetryAfter time.Duration
+}
+
+// UsageDay is the usage of a key on one day
+type UsageDay struct {
+	Day       string           `json:"day"`
+	Requests  int64            `json:"requests"`
+	Throttled int64            `json:"throttled"`
+	Endpoints map[string]int64 `json:"endpoints"`
+}
+
+// Usage is the daily usage of a key
+type Usage struct {
+	Key  Key        `json:"key"`
+	Days []UsageDay `json:"days"`
+}
+
+// KeyTotals is the usage of a key over a period
+type KeyTotals struct {
+	ID         int32  `json:"id"`
+	Name       string `json:"name"`
+	Prefix     string `json:"prefix"`
+	DailyQuota int32  `json:"daily_quota"`
+	Requests   int64  `json:"requests"`
+	Throttled  int64  `json:"throttled"`
+}
+
+type cachedKey struct {
+	key      db.ApiKey
+	loadedAt time.Time
+}
+
+type usageKey struct {
+	keyID    int32
+	day      time.Time
+	endpoint string
+}
+
+type usageCount struct {
+	requests  int64
+	throttled int64
+}
+
+// Service issues API keys and enforces their quotas. Per-minute limits are
+// enforced per instance; daily quotas are shared through the usage table and
+// lag by at most one flush interval across instances.
+type Service struct {
+	store    db.Querier
+	defaults Limits
+	now      func() time.Time
+
+	mu       sync.Mutex
+	keys     map[string]cachedKey
+	limiters map[int32]*rate.Limiter
+	day      time.Time
+	used     map[int32]int64
+	pending  map[usageKey]*usageCount
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewService creates a new API key service issuing keys with the default limits
+func NewService(store db.Querier, defaults Limits) *Service {
+	return &Service{
+		store:    store,
+		defaults: defaults,
+		now:      time.Now,
+		keys:     make(map[string]cachedKey),
+		limiters: make(map[int32]*rate.Limiter),
+		used:     make(map[int32]int64),
+		pending:  make(map[usageKey]*usageCount),
+	}
+}
+
+// Defaults returns the limits of keys created without explicit limits
+func (s *Service) Defaults() Limits {
+	return s.defaults
+}
+
+// Start flushes usage counters to the database every interval
+func (s *Service) Start(interval time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop != nil {
+		return
+	}
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+
+	go func(stop, done chan struct{}) {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := s.Flush(context.Background()); err != nil {
+					logger.Warn("Failed to flush API key usage: %v", err)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}(s.stop, s.done)
+}
+
+// Stop stops the flush loop and writes the remaining usage counters
+func (s *Service) Stop() {
+	s.mu.Lock()
+	stop, done := s.stop, s.done
+	s.stop, s.done = nil, nil
+	s.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.Flush(ctx); err != nil {
+		logger.Warn("Failed to flush API key usage on shutdown: %v", err)
+	}
+}
+
+// Create issues a new key. Zero limits fall back to the defaults.
+func (s *Service) Create(ctx context.Context, name, contactEmail string, limits Limits, createdBy int32) (*CreatedKey, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrInvalidName
+	}
+	contactEmail = strings.TrimSpace(contactEmail)
+	if contactEmail != "" {
+		if _, err := mail.ParseAddress(contactEmail); err != nil {
+			return nil, ErrInvalidEmail
+		}
+	}
+	if limits.DailyQuota == 0 {
+		limits.DailyQuota = s.defaults.DailyQuota
+	}
+	if limits.RequestsPerMinute == 0 {
+		limits.RequestsPerMinute = s.defaults.RequestsPerMinute
+	}
+	if !limits.valid() {
+		return nil, ErrInvalidLimits
+	}
+
+	prefix, err := randomHex(6)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key prefix: %w", err)
+	}
+	secret, err := randomHex(24)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key secret: %w", err)
+	}
+	plaintext := keyScheme + "_" + prefix + "_" + secret
+
+	record, err := s.store.CreateAPIKey(ctx, db.CreateAPIKeyParams{
+		Name:              name,
+		ContactEmail:      contactEmail,
+		Prefix:            prefix,
+		KeyHash:           hashKey(plaintext),
+		DailyQuota:        limits.DailyQuota,
+		RequestsPerMinute: limits.RequestsPerMinute,
+		CreatedBy:         sql.NullInt32{Int32: createdBy, Valid: createdBy != 0},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create API key: %w", err)
+	}
+
+	logger.Info("API key %s (%s) created by user %d", record.Prefix, record.Name, createdBy)
+	return &CreatedKey{Key: newKey(record), Secret: plaintext}, nil
+}
+
+// List returns all issued keys, including revoked ones
+func (s *Service) List(ctx context.Context) ([]Key, error) {
+	records, err := s.store.ListAPIKeys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+
+	keys := make([]Key, 0, len(records))
+	for _, record := range records {
+		keys = append(keys, newKey(record))
+	}
+	return keys, nil
+}
+
+// UpdateLimits changes the quotas of a key
+func (s *Service) UpdateLimits(ctx context.Context, id int32, limits Limits) (*Key, error) {
+	if !limits.valid() {
+		return nil, ErrInvalidLimits
+	}
+
+	record, err := s.store.UpdateAPIKeyLimits(ctx, db.UpdateAPIKeyLimitsParams{
+		ID:                id,
+		DailyQuota:        limits.DailyQuota,
+		RequestsPerMinute: limits.RequestsPerMinute,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to update API key limits: %w", err)
+	}
+
+	s.forget(record.Prefix)
+	key := newKey(record)
+	return &key, nil
+}
+
+// Revoke disables a key. Revoking an already revoked key reports ErrKeyNotFound.
+func (s *Service) Revoke(ctx context.Context, id int32) error {
+	record, err := s.store.RevokeAPIKey(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrKeyNotFound
+		}
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+
+	s.forget(record.Prefix)
+	logger.Info("API key %s (%s) revoked", record.Prefix, record.Name)
+	return nil
+}
+
+// Authenticate returns the key matching a plaintext API key
+func (s *Service) Authenticate(ctx context.Context, plaintext string) (*db.ApiKey, error) {
+	parts := strings.Split(plaintext, "_")
+	if len(parts) != 3 || parts[0] != keyScheme || parts[1] == "" || parts[2] == "" {
+		return nil, ErrInvalidKey
+	}
+	prefix := parts[1]
+
+	record, err := s.lookup(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(record.KeyHash), []byte(hashKey(plaintext))) != 1 {
+		return nil, ErrInvalidKey
+	}
+	if record.RevokedAt.Valid {
+		return nil, ErrKeyRevoked
+	}
+	return &record, nil
+}
+
+func (s *Service) lookup(ctx context.Context, prefix string) (db.ApiKey, error) {
+	now := s.now()
+
+	s.mu.Lock()
+	cached, ok := s.keys[prefix]
+	s.mu.Unlock()
+	if ok && now.Sub(cached.loadedAt) < keyCacheTTL {
+		return cached.key, nil
+	}
+
+	record, err := s.store.GetAPIKeyByPrefix(ctx, prefix)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.ApiKey{}, ErrInvalidKey
+		}
+		return db.ApiKey{}, fmt.Errorf("failed to get API key: %w", err)
+	}
+
+	// Seed the daily counter with what other instances already used today
+	day := startOfDay(now)
+	s.mu.Lock()
+	_, seeded := s.used[record.ID]
+	seeded = seeded && s.day.Equal(day)
+	s.mu.Unlock()
+	var used int64
+	if !seeded {
+		used, err = s.store.GetAPIKeyDailyRequests(ctx, db.GetAPIKeyDailyRequestsParams{ApiKeyID: record.ID, Day: day})
+		if err != nil {
+			return db.ApiKey{}, fmt.Errorf("failed to get API key usage: %w", err)
+		}
+	}
+
+	s.mu.Lock()
+	s.keys[prefix] = cachedKey{key: record, loadedAt: now}
+	s.rollDay(day)
+	if current, ok := s.used[record.ID]; !ok || current < used {
+		s.used[record.ID] = used
+	}
+	s.mu.Unlock()
+	return record, nil
+}
+
+// Allow checks a request against the per-minute limit and the daily quota of
+// a key and counts it toward the usage of endpoint
+func (s *Service) Allow(key *db.ApiKey, endpoint string) Decision {
+	now := s.now()
+	day := startOfDay(now)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rollDay(day)
+
+	decision := Decision{
+		Limit: key.DailyQuota,
+		Reset: day.AddDate(0, 0, 1),
+	}
+	counter := s.counter(usageKey{keyID: key.ID, day: day, endpoint: endpoint})
+	used := s.used[key.ID]
+
+	if used >= int64(key.DailyQuota) {
+		counter.throttled++
+		decision.Reason = ReasonQuotaExceeded
+		decision.RetryAfter = decision.Reset.Sub(now)
+		return decision
+	}
+
+	limiter := s.limiter(key)
+	if !limiter.AllowN(now, 1) {
+		counter.throttled++
+		decision.Reason = ReasonRateLimited
+		decision.Remaining = int64(key.DailyQuota) - used
+		decision.RetryAfter = time.Duration(float64(time.Second) / float64(limiter.Limit()))
+		return decision
+	}
+
+	counter.requests++
+	s.used[key.ID] = used + 1
+	decision.Allowed = true
+	decision.Remaining = int64(key.DailyQuota) - used - 1
+	return decision
+}
+
+// limiter returns the per-minute limiter of a key, replacing it when the
+// limit of the key changed. Callers hold s.mu.
+func (s *Service) limiter(key *db.ApiKey) *rate.Limiter {
+	limit := rate.Limit(float64(key.RequestsPerMinute) / 60)
+	limiter, ok := s.limiters[key.ID]
+	if !ok || limiter.Limit() != limit {
+		limiter = rate.NewLimiter(limit, int(key.RequestsPerMinute))
+		s.limiters[key.ID] = limiter
+	}
+	return limiter
+}
+
+// counter returns the pending usage counter of k. Callers hold s.mu.
+func (s *Service) counter(k usageKey) *usageCount {
+	counter, ok := s.pending[k]
+	if !ok {
+		counter = &usageCount{}
+		s.pending[k] = counter
+	}
+	return counter
+}
+
+// rollDay resets the daily counters at midnight UTC. Callers hold s.mu.
+func (s *Service) rollDay(day time.Time) {
+	if s.day.Equal(day) {
+		return
+	}
+	s.day = day
+	s.used = make(map[int32]int64)
+}
+
+// Flush writes pending usage counters to the database and refreshes the daily
+// counters with the usage recorded by other instances
+func (s *Service) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	batch := s.pending
+	s.pending = make(map[usageKey]*usageCount)
+	s.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	var firstErr error
+	seen := make(map[int32]time.Time)
+	for k, count := range batch {
+		err := s.store.AddAPIKeyUsage(ctx, db.AddAPIKeyUsageParams{
+			ApiKeyID:  k.keyID,
+			Day:       k.day,
+			Endpoint:  k.endpoint,
+			Requests:  count.requests,
+			Throttled: count.throttled,
+		})
+		if err != nil {
+			// Keep the counts for the next flush
+			s.mu.Lock()
+			pending := s.counter(k)
+			pending.requests += count.requests
+			pending.throttled += count.throttled
+			s.mu.Unlock()
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to record API key usage: %w", err)
+			}
+			continue
+		}
+		if count.requests > 0 && k.day.After(seen[k.keyID]) {
+			seen[k.keyID] = k.day
+		}
+	}
+
+	for keyID, day := range seen {
+		if err := s.store.TouchAPIKey(ctx, keyID); err != nil {
+			logger.Debug("Failed to touch API key %d: %v", keyID, err)
+		}
+
+		total, err := s.store.GetAPIKeyDailyRequests(ctx, db.GetAPIKeyDailyRequestsParams{ApiKeyID: keyID, Day: day})
+		if err != nil {
+			logger.Debug("Failed to refresh usage of API key %d: %v", keyID, err)
+			continue
+		}
+
+		s.mu.Lock()
+		if s.day.Equal(day) {
+			// Requests counted since the batch was taken are not in the total yet
+			for k, count := range s.pending {
+				if k.keyID == keyID && k.day.Equal(day) {
+					total += count.requests
+				}
+			}
+			if total > s.used[keyID] {
+				s.used[keyID] = total
+			}
+		}
+		s.mu.Unlock()
+	}
+
+	return firstErr
+}
+
+// Usage returns the daily usage of a key over the last days
+func (s *Service) Usage(ctx context.Context, id int32, days int) (*Usage, error) {
+	record, err := s.store.GetAPIKey(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+	if err := s.Flush(ctx); err != nil {
+		logger.Warn("Failed to flush API key usage: %v", err)
+	}
+
+	rows, err := s.store.ListAPIKeyUsage(ctx, db.ListAPIKeyUsageParams{
+		ApiKeyID: id,
+		Day:      s.since(days),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API key usage: %w", err)
+	}
+
+	usage := &Usage{Key: newKey(record), Days: []UsageDay{}}
+	for _, row := range rows {
+		day := row.Day.Format("2006-01-02")
+		if n := len(usage.Days); n == 0 || usage.Days[n-1].Day != day {
+			usage.Days = append(usage.Days, UsageDay{Day: day, Endpoints: make(map[string]int64)})
+		}
+		current := &usage.Days[len(usage.Days)-1]
+		current.Requests += row.Requests
+		current.Throttled += row.Throttled
+		current.Endpoints[row.Endpoint] += row.Requests
+	}
+	return usage, nil
+}
+
+// Totals returns the usage of every active key over the last days, busiest first
+func (s *Service) Totals(ctx context.Context, days int) ([]KeyTotals, error) {
+	if err := s.Flush(ctx); err != nil {
+		logger.Warn("Failed to flush API key usage: %v", err)
+	}
+
+	rows, err := s.store.ListAPIKeyUsageTotals(ctx, s.since(days))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API key usage totals: %w", err)
+	}
+
+	totals := make([]KeyTotals, 0, len(rows))
+	for _, row := range rows {
+		totals = append(totals, KeyTotals{
+			ID:         row.ApiKeyID,
+			Name:       row.Name,
+			Prefix:     row.Prefix,
+			DailyQuota: row.DailyQuota,
+			Requests:   row.Requests,
+			Throttled:  row.Throttled,
+		})
+	}
+	return totals, nil
+}
+
+// since returns the first day of a period of days ending today
+func (s *Service) since(days int) time.Time {
+	if days < 1 {
+		days = 1
+	}
+	return startOfDay(s.now()).AddDate(0, 0, -(days - 1))
+}
+
+func (s *Service) forget(prefix string) {
+	s.mu.Lock()
+	delete(s.keys, prefix)
+	s.mu.Unlock()
+}
+
+func newKey(record db.ApiKey) Key {
+	key := Key{
+		ID:           record.ID,
+		Name:         record.Name,
+		ContactEmail: record.ContactEmail,
+		Prefix:       record.Prefix,
+		Limits: Limits{
+			DailyQuota:        record.DailyQuota,
+			RequestsPerMinute: record.RequestsPerMinute,
+		},
+		CreatedAt: record.CreatedAt,
+	}
+	if record.LastUsedAt.Valid {
+		key.LastUsedAt = &record.LastUsedAt.Time
+	}
+	if record.RevokedAt.Valid {
+		key.RevokedAt = &record.RevokedAt.Time
+	}
+	return key
+}
+
+// startOfDay returns midnight UTC of the day of t; quotas reset at midnight UTC
+func startOfDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func hashKey(plaintext string) string {
+	sum := sha256.Sum256([]byte(plaintext))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,337 @@
+package apikey
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+// keyStore keeps keys and usage in memory; other Querier methods are not used
+type keyStore struct {
+	db.Querier
+	keys      []db.ApiKey
+	usage     map[usageKey]usageCount
+	failUsage bool
+}
+
+func newKeyStore() *keyStore {
+	return &keyStore{usage: make(map[usageKey]usageCount)}
+}
+
+func (s *keyStore) CreateAPIKey(ctx context.Context, arg db.CreateAPIKeyParams) (db.ApiKey, error) {
+	key := db.ApiKey{
+		ID:                int32(len(s.keys) + 1),
+		Name:              arg.Name,
+		ContactEmail:      arg.ContactEmail,
+		Prefix:            arg.Prefix,
+		KeyHash:           arg.KeyHash,
+		DailyQuota:        arg.DailyQuota,
+		RequestsPerMinute: arg.RequestsPerMinute,
+		CreatedBy:         arg.CreatedBy,
+		CreatedAt:         time.Now(),
+	}
+	s.keys = append(s.keys, key)
+	return key, nil
+}
+
+func (s *keyStore) find(match func(db.ApiKey) bool) (*db.ApiKey, error) {
+	for i := range s.keys {
+		if match(s.keys[i]) {
+			return &s.keys[i], nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (s *keyStore) GetAPIKey(ctx context.Context, id int32) (db.ApiKey, error) {
+	key, err := s.find(func(k db.ApiKey) bool { return k.ID == id })
+	if err != nil {
+		return db.ApiKey{}, err
+	}
+	return *key, nil
+}
+
+func (s *keyStore) GetAPIKeyByPrefix(ctx context.Context, prefix string) (db.ApiKey, error) {
+	key, err := s.find(func(k db.ApiKey) bool { return k.Prefix == prefix })
+	if err != nil {
+		return db.ApiKey{}, err
+	}
+	return *key, nil
+}
+
+func (s *keyStore) ListAPIKeys(ctx context.Context) ([]db.ApiKey, error) {
+	return s.keys, nil
+}
+
+func (s *keyStore) UpdateAPIKeyLimits(ctx context.Context, arg db.UpdateAPIKeyLimitsParams) (db.ApiKey, error) {
+	key, err := s.find(func(k db.ApiKey) bool { return k.ID == arg.ID })
+	if err != nil {
+		return db.ApiKey{}, err
+	}
+	key.DailyQuota = arg.DailyQuota
+	key.RequestsPerMinute = arg.RequestsPerMinute
+	return *key, nil
+}
+
+func (s *keyStore) RevokeAPIKey(ctx context.Context, id int32) (db.ApiKey, error) {
+	key, err := s.find(func(k db.ApiKey) bool { return k.ID == id && !k.RevokedAt.Valid })
+	if err != nil {
+		return db.ApiKey{}, err
+	}
+	key.RevokedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	return *key, nil
+}
+
+func (s *keyStore) TouchAPIKey(ctx context.Context, id int32) error {
+	key, err := s.find(func(k db.ApiKey) bool { return k.ID == id })
+	if err != nil {
+		return err
+	}
+	key.LastUsedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	return nil
+}
+
+func (s *keyStore) AddAPIKeyUsage(ctx context.Context, arg db.AddAPIKeyUsageParams) error {
+	if s.failUsage {
+		return errors.New("connection refused")
+	}
+	k := usageKey{keyID: arg.ApiKeyID, day: arg.Day, endpoint: arg.Endpoint}
+	count := s.usage[k]
+	count.requests += arg.Requests
+	count.throttled += arg.Throttled
+	s.usage[k] = count
+	return nil
+}
+
+func (s *keyStore) GetAPIKeyDailyRequests(ctx context.Context, arg db.GetAPIKeyDailyRequestsParams) (int64, error) {
+	var total int64
+	for k, count := range s.usage {
+		if k.keyID == arg.ApiKeyID && k.day.Equal(arg.Day) {
+			total += count.requests
+		}
+	}
+	return total, nil
+}
+
+func (s *keyStore) ListAPIKeyUsage(ctx context.Context, arg db.ListAPIKeyUsageParams) ([]db.ListAPIKeyUsageRow, error) {
+	var rows []db.ListAPIKeyUsageRow
+	for k, count := range s.usage {
+		if k.keyID == arg.ApiKeyID && !k.day.Before(arg.Day) {
+			rows = append(rows, db.ListAPIKeyUsageRow{Day: k.day, Endpoint: k.endpoint, Requests: count.requests, Throttled: count.throttled})
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Day.Before(rows[j].Day) })
+	return rows, nil
+}
+
+type testClock struct{ t time.Time }
+
+func (c *testClock) now() time.Time { return c.t }
+
+func newTestService(store *keyStore) (*Service, *testClock) {
+	clock := &testClock{t: time.Date(2026, 5, 4, 10, 0, 0, 0, time.UTC)}
+	service := NewService(store, Limits{DailyQuota: 1000, RequestsPerMinute: 60})
+	service.now = clock.now
+	return service, clock
+}
+
+func TestCreateAndAuthenticate(t *testing.T) {
+	store := newKeyStore()
+	service, _ := newTestService(store)
+	ctx := context.Background()
+
+	created, err := service.Create(ctx, " Flashcards app ", "dev@example.com", Limits{}, 7)
+	require.NoError(t, err)
+	assert.Equal(t, "Flashcards app", created.Name)
+	assert.Equal(t, Limits{DailyQuota: 1000, RequestsPerMinute: 60}, created.Limits)
+	assert.Regexp(t, `^tk_[0-9a-f]{12}_[0-9a-f]{48}$`, created.Secret)
+	assert.NotContains(t, store.keys[0].KeyHash, created.Secret)
+
+	key, err := service.Authenticate(ctx, created.Secret)
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, key.ID)
+
+	_, err = service.Authenticate(ctx, created.Secret[:len(created.Secret)-1]+"0")
+	assert.ErrorIs(t, err, ErrInvalidKey)
+	_, err = service.Authenticate(ctx, "tk_000000000000_abc")
+	assert.ErrorIs(t, err, ErrInvalidKey)
+	_, err = service.Authenticate(ctx, "Bearer something")
+	assert.ErrorIs(t, err, ErrInvalidKey)
+
+	require.NoError(t, service.Revoke(ctx, created.ID))
+	_, err = service.Authenticate(ctx, created.Secret)
+	assert.ErrorIs(t, err, ErrKeyRevoked)
+	assert.ErrorIs(t, service.Revoke(ctx, created.ID), ErrKeyNotFound)
+}
+
+func TestCreateValidation(t *testing.T) {
+	service, _ := newTestService(newKeyStore())
+	ctx := context.Background()
+
+	_, err := service.Create(ctx, "  ", "", Limits{}, 1)
+	assert.ErrorIs(t, err, ErrInvalidName)
+	_, err = service.Create(ctx, "App", "not-an-email", Limits{}, 1)
+	assert.ErrorIs(t, err, ErrInvalidEmail)
+	_, err = service.Create(ctx, "App", "", Limits{DailyQuota: -1}, 1)
+	assert.ErrorIs(t, err, ErrInvalidLimits)
+}
+
+func TestAllowEnforcesRateLimit(t *testing.T) {
+	store := newKeyStore()
+	service, clock := newTestService(store)
+	ctx := context.Background()
+
+	created, err := service.Create(ctx, "App", "", Limits{DailyQuota: 100, RequestsPerMinute: 2}, 1)
+	require.NoError(t, err)
+	key, err := service.Authenticate(ctx, created.Secret)
+	require.NoError(t, err)
+
+	assert.True(t, service.Allow(key, "/words").Allowed)
+	assert.True(t, service.Allow(key, "/words").Allowed)
+
+	decision := service.Allow(key, "/words")
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, ReasonRateLimited, decision.Reason)
+	assert.Equal(t, int64(98), decision.Remaining)
+	assert.Equal(t, 30*time.Second, decision.RetryAfter)
+
+	clock.t = clock.t.Add(30 * time.Second)
+	assert.True(t, service.Allow(key, "/words").Allowed)
+}
+
+func TestAllowEnforcesDailyQuota(t *testing.T) {
+	store := newKeyStore()
+	service, clock := newTestService(store)
+	ctx := context.Background()
+
+	created, err := service.Create(ctx, "App", "", Limits{DailyQuota: 3, RequestsPerMinute: 60}, 1)
+	require.NoError(t, err)
+	key, err := service.Authenticate(ctx, created.Secret)
+	require.NoError(t, err)
+
+	for i := 2; i >= 0; i-- {
+		decision := service.Allow(key, "/grammars")
+		require.True(t, decision.Allowed)
+		assert.Equal(t, int64(i), decision.Remaining)
+		assert.Equal(t, int32(3), decision.Limit)
+	}
+
+	decision := service.Allow(key, "/grammars")
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, ReasonQuotaExceeded, decision.Reason)
+	assert.Equal(t, time.Date(2026, 5, 5, 0, 0, 0, 0, time.UTC), decision.Reset)
+	assert.Equal(t, 14*time.Hour, decision.RetryAfter)
+
+	// The quota resets at midnight UTC
+	clock.t = time.Date(2026, 5, 5, 0, 0, 1, 0, time.UTC)
+	assert.True(t, service.Allow(key, "/grammars").Allowed)
+}
+
+func TestQuotaSharedThroughUsageTable(t *testing.T) {
+	store := newKeyStore()
+	service, clock := newTestService(store)
+	ctx := context.Background()
+
+	created, err := service.Create(ctx, "App", "", Limits{DailyQuota: 10, RequestsPerMinute: 60}, 1)
+	require.NoError(t, err)
+
+	// Another instance already served 8 requests today
+	today := time.Date(2026, 5, 4, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, store.AddAPIKeyUsage(ctx, db.AddAPIKeyUsageParams{ApiKeyID: created.ID, Day: today, Endpoint: "/words", Requests: 8}))
+
+	key, err := service.Authenticate(ctx, created.Secret)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), service.Allow(key, "/words").Remaining)
+
+	// ...and one more before the next flush
+	require.NoError(t, store.AddAPIKeyUsage(ctx, db.AddAPIKeyUsageParams{ApiKeyID: created.ID, Day: today, Endpoint: "/words", Requests: 1}))
+	require.NoError(t, service.Flush(ctx))
+
+	decision := service.Allow(key, "/words")
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, ReasonQuotaExceeded, decision.Reason)
+
+	require.NoError(t, service.Flush(ctx))
+	assert.Equal(t, usageCount{requests: 9 + 1, throttled: 1}, store.usage[usageKey{keyID: created.ID, day: today, endpoint: "/words"}])
+	assert.True(t, store.keys[0].LastUsedAt.Valid)
+
+	clock.t = clock.t.Add(time.Hour)
+	_, err = service.Authenticate(ctx, created.Secret)
+	require.NoError(t, err)
+	assert.False(t, service.Allow(key, "/words").Allowed)
+}
+
+func TestFlushKeepsCountsOnFailure(t *testing.T) {
+	store := newKeyStore()
+	service, _ := newTestService(store)
+	ctx := context.Background()
+
+	created, err := service.Create(ctx, "App", "", Limits{}, 1)
+	require.NoError(t, err)
+	key, err := service.Authenticate(ctx, created.Secret)
+	require.NoError(t, err)
+	service.Allow(key, "/words")
+	service.Allow(key, "/words/:id")
+
+	store.failUsage = true
+	assert.Error(t, service.Flush(ctx))
+	assert.Empty(t, store.usage)
+
+	store.failUsage = false
+	require.NoError(t, service.Flush(ctx))
+	assert.Len(t, store.usage, 2)
+}
+
+func TestUpdateLimitsAppliesImmediately(t *testing.T) {
+	store := newKeyStore()
+	service, _ := newTestService(store)
+	ctx := context.Background()
+
+	created, err := service.Create(ctx, "App", "", Limits{DailyQuota: 1, RequestsPerMinute: 60}, 1)
+	require.NoError(t, err)
+	key, err := service.Authenticate(ctx, created.Secret)
+	require.NoError(t, err)
+	assert.True(t, service.Allow(key, "/words").Allowed)
+	assert.False(t, service.Allow(key, "/words").Allowed)
+
+	_, err = service.UpdateLimits(ctx, created.ID, Limits{DailyQuota: 5, RequestsPerMinute: 60})
+	require.NoError(t, err)
+	key, err = service.Authenticate(ctx, created.Secret)
+	require.NoError(t, err)
+	assert.True(t, service.Allow(key, "/words").Allowed)
+
+	_, err = service.UpdateLimits(ctx, 99, Limits{DailyQuota: 5, RequestsPerMinute: 60})
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+func TestUsageGroupsByDay(t *testing.T) {
+	store := newKeyStore()
+	service, clock := newTestService(store)
+	ctx := context.Background()
+
+	created, err := service.Create(ctx, "App", "", Limits{DailyQuota: 100, RequestsPerMinute: 60}, 1)
+	require.NoError(t, err)
+	key, err := service.Authenticate(ctx, created.Secret)
+	require.NoError(t, err)
+
+	service.Allow(key, "/words")
+	service.Allow(key, "/grammars")
+	clock.t = clock.t.AddDate(0, 0, 1)
+	service.Allow(key, "/words")
+
+	usage, err := service.Usage(ctx, created.ID, 7)
+	require.NoError(t, err)
+	require.Len(t, usage.Days, 2)
+	assert.Equal(t, UsageDay{Day: "2026-05-04", Requests: 2, Endpoints: map[string]int64{"/words": 1, "/grammars": 1}}, usage.Days[0])
+	assert.Equal(t, UsageDay{Day: "2026-05-05", Requests: 1, Endpoints: map[string]int64{"/words": 1}}, usage.Days[1])
+
+	_, err = service.Usage(ctx, 42, 7)
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
@@ -0,0 +1,88 @@
+package authoring
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Content types that go through the workflow
+const (
+	TypeGrammar       = "grammar"
+	TypeWritingPrompt = "writing_prompt"
+)
+
+var ErrInvalidContent = errors.New("invalid content")
+
+// Content is the data of one revision of a piece of content
+type Content interface {
+	ContentType() string
+	Validate() error
+}
+
+// GrammarData is the content of a grammar revision
+type GrammarData struct {
+	Level      int32           `json:"level"`
+	Title      string          `json:"title"`
+	Tag        []string        `json:"tag"`
+	GrammarKey string          `json:"grammar_key"`
+	Related    []int32         `json:"related"`
+	Contents   json.RawMessage `json:"contents,omitempty"`
+}
+
+func (d *GrammarData) ContentType() string {
+	return TypeGrammar
+}
+
+func (d *GrammarData) Validate() error {
+	switch {
+	case d.Level < 1:
+		return fmt.Errorf("%w: level must be at least 1", ErrInvalidContent)
+	case strings.TrimSpace(d.Title) == "":
+		return fmt.Errorf("%w: title is required", ErrInvalidContent)
+	case strings.TrimSpace(d.GrammarKey) == "":
+		return fmt.Errorf("%w: grammar_key is required", ErrInvalidContent)
+	}
+	return nil
+}
+
+// WritingPromptData is the content of a writing prompt revision
+type WritingPromptData struct {
+	PromptText      string `json:"prompt_text"`
+	Topic           string `json:"topic,omitempty"`
+	DifficultyLevel string `json:"difficulty_level,omitempty"`
+}
+
+func (d *WritingPromptData) ContentType() string {
+	return TypeWritingPrompt
+}
+
+func (d *WritingPromptData) Validate() error {
+	if strings.TrimSpace(d.PromptText) == "" {
+		return fmt.Errorf("%w: prompt_text is required", ErrInvalidContent)
+	}
+	return nil
+}
+
+// ValidType reports whether contentType goes through the workflow
+func ValidType(contentType string) bool {
+	return contentType == TypeGrammar || contentType == TypeWritingPrompt
+}
+
+// decodeContent reads the stored data of a revision
+func decodeContent(contentType string, data json.RawMessage) (Content, error) {
+	var content Content
+	switch contentType {
+	case TypeGrammar:
+		content = &GrammarData{}
+	case TypeWritingPrompt:
+		content = &WritingPromptData{}
+	default:
+		return nil, ErrUnknownType
+	}
+	if err := json.Unmarshal(data, content); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidContent, err)
+	}
+	return content, nil
+}
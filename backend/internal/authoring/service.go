@@ -0,0 +1,628 @@
+// Package authoring moves grammar and writing prompt content through a
+// draft -> review -> published workflow. Edits are made on revisions, so the
+// version learners see only changes when a reviewer publishes a revision,
+// either right away or at a scheduled time.
+package authoring
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/logger"
+)
+
+var (
+	ErrUnknownType         = errors.New("unknown content type")
+	ErrContentNotFound     = errors.New("content not found")
+	ErrRevisionNotFound    = errors.New("revision not found")
+	ErrRevisionInProgress  = errors.New("content already has a revision in progress")
+	ErrInvalidTransition   = errors.New("revision cannot make this transition")
+	ErrContentTypeMismatch = errors.New("data does not match the content type of the revision")
+)
+
+// Revision statuses
+const (
+	StatusDraft     = "draft"
+	StatusReview    = "review"
+	StatusScheduled = "scheduled"
+	StatusPublished = "published"
+	StatusDiscarded = "discarded"
+)
+
+// History actions
+const (
+	ActionCreated         = "created"
+	ActionRevisionStarted = "revision_started"
+	ActionEdited          = "edited"
+	ActionSubmitted       = "submitted"
+	ActionScheduled       = "scheduled"
+	ActionPublished       = "published"
+	ActionRejected        = "rejected"
+	ActionDiscarded       = "discarded"
+)
+
+// duePublishBatch bounds how many scheduled revisions one run publishes
+const duePublishBatch = 100
+
+// Revision is one version of a piece of content
+type Revision struct {
+	ID          int32           `json:"id"`
+	ContentType string          `json:"content_type"`
+	ContentID   int32           `json:"content_id"`
+	Version     int32           `json:"version"`
+	Status      string          `json:"status"`
+	Data        json.RawMessage `json:"data" swaggertype:"object"`
+	Note        string          `json:"note,omitempty"`
+	PublishAt   *time.Time      `json:"publish_at,omitempty"`
+	CreatedBy   *int32          `json:"created_by,omitempty"`
+	ReviewedBy  *int32          `json:"reviewed_by,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	PublishedAt *time.Time      `json:"published_at,omitempty"`
+}
+
+// HistoryEntry is one change of a piece of content
+type HistoryEntry struct {
+	ID         int64     `json:"id"`
+	RevisionID *int32    `json:"revision_id,omitempty"`
+	Action     string    `json:"action"`
+	FromStatus string    `json:"from_status,omitempty"`
+	ToStatus   string    `json:"to_status,omitempty"`
+	ActorID    *int32    `json:"actor_id,omitempty"`
+	Note       string    `json:"note,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Filter selects revisions to list. Empty fields match everything.
+type Filter struct {
+	Status      string
+	ContentType string
+	Limit       int32
+	Offset      int32
+}
+
+// Service runs the authoring workflow
+type Service struct {
+	store db.Querier
+	now   func() time.Time
+
+	mu   sync.Mutex
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewService creates an authoring service
+func NewService(store db.Querier) *Service {
+	return &Service{store: store, now: time.Now}
+}
+
+// Start publishes scheduled revisions when they are due
+func (s *Service) Start(interval time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop != nil {
+		return
+	}
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+
+	go func(stop, done chan struct{}) {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := s.PublishDue(context.Background()); err != nil {
+					logger.Warn("Failed to publish scheduled content: %v", err)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}(s.stop, s.done)
+}
+
+// Stop stops publishing scheduled revisions
+func (s *Service) Stop() {
+	s.mu.Lock()
+	stop, done := s.stop, s.done
+	s.stop, s.done = nil, nil
+	s.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
+
+// Create adds a piece of content as an unpublished draft with its first revision
+func (s *Service) Create(ctx context.Context, content Content, note string, actor int32) (*Revision, error) {
+	if err := content.Validate(); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(content)
+	if err != nil {
+		return nil, err
+	}
+
+	var contentID int32
+	switch c := content.(type) {
+	case *GrammarData:
+		grammar, err := s.store.CreateGrammarDraft(ctx, db.CreateGrammarDraftParams{
+			Level:      c.Level,
+			Title:      c.Title,
+			Tag:        nonNilStrings(c.Tag),
+			GrammarKey: c.GrammarKey,
+			Related:    nonNilInt32s(c.Related),
+			Contents:   c.Contents,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create grammar draft: %w", err)
+		}
+		contentID = grammar.ID
+	case *WritingPromptData:
+		prompt, err := s.store.CreateWritingPromptDraft(ctx, db.CreateWritingPromptDraftParams{
+			UserID:          sql.NullInt32{Int32: actor, Valid: true},
+			PromptText:      c.PromptText,
+			Topic:           nullString(c.Topic),
+			DifficultyLevel: nullString(c.DifficultyLevel),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create writing prompt draft: %w", err)
+		}
+		contentID = prompt.ID
+	default:
+		return nil, ErrUnknownType
+	}
+
+	revision, err := s.store.CreateContentRevision(ctx, db.CreateContentRevisionParams{
+		ContentType: content.ContentType(),
+		ContentID:   contentID,
+		Data:        data,
+		Note:        note,
+		CreatedBy:   sql.NullInt32{Int32: actor, Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create revision: %w", err)
+	}
+	s.record(ctx, revision, ActionCreated, "", StatusDraft, actor, note)
+	return newRevision(revision), nil
+}
+
+// StartRevision opens a draft revision of published content. Without content
+// the draft starts from the current version.
+func (s *Service) StartRevision(ctx context.Context, contentType string, contentID int32, content Content, note string, actor int32) (*Revision, error) {
+	if !ValidType(contentType) {
+		return nil, ErrUnknownType
+	}
+	current, err := s.current(ctx, contentType, contentID)
+	if err != nil {
+		return nil, err
+	}
+	if content == nil {
+		content = current
+	} else if content.ContentType() != contentType {
+		return nil, ErrContentTypeMismatch
+	}
+	if err := content.Validate(); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(content)
+	if err != nil {
+		return nil, err
+	}
+
+	revision, err := s.store.CreateContentRevision(ctx, db.CreateContentRevisionParams{
+		ContentType: contentType,
+		ContentID:   contentID,
+		Data:        data,
+		Note:        note,
+		CreatedBy:   sql.NullInt32{Int32: actor, Valid: true},
+	})
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return nil, ErrRevisionInProgress
+		}
+		return nil, fmt.Errorf("failed to create revision: %w", err)
+	}
+	s.record(ctx, revision, ActionRevisionStarted, "", StatusDraft, actor, note)
+	return newRevision(revision), nil
+}
+
+// Edit replaces the data of a draft revision
+func (s *Service) Edit(ctx context.Context, id int32, content Content, note string, actor int32) (*Revision, error) {
+	current, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if content.ContentType() != current.ContentType {
+		return nil, ErrContentTypeMismatch
+	}
+	if current.Status != StatusDraft {
+		return nil, fmt.Errorf("%w: only drafts can be edited, revision is %s", ErrInvalidTransition, current.Status)
+	}
+	if err := content.Validate(); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(content)
+	if err != nil {
+		return nil, err
+	}
+
+	revision, err := s.store.UpdateContentRevisionData(ctx, db.UpdateContentRevisionDataParams{ID: id, Data: data, Note: note})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: revision is no longer a draft", ErrInvalidTransition)
+		}
+		return nil, fmt.Errorf("failed to update revision: %w", err)
+	}
+	s.record(ctx, revision, ActionEdited, StatusDraft, StatusDraft, actor, note)
+	return newRevision(revision), nil
+}
+
+// Submit sends a draft to review
+func (s *Service) Submit(ctx context.Context, id int32, note string, actor int32) (*Revision, error) {
+	current, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.move(ctx, current, []string{StatusDraft}, StatusReview, sql.NullTime{}, sql.NullInt32{}, ActionSubmitted, note, actor)
+}
+
+// Approve publishes a revision in review. With a publish time in the future
+// the revision is scheduled instead and published when it is due.
+func (s *Service) Approve(ctx context.Context, id int32, publishAt time.Time, note string, actor int32) (*Revision, error) {
+	current, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	reviewer := sql.NullInt32{Int32: actor, Valid: true}
+	if publishAt.After(s.now()) {
+		return s.move(ctx, current, []string{StatusReview}, StatusScheduled, sql.NullTime{Time: publishAt, Valid: true}, reviewer, ActionScheduled, note, actor)
+	}
+	if current.Status != StatusReview {
+		return nil, fmt.Errorf("%w: only revisions in review can be approved, revision is %s", ErrInvalidTransition, current.Status)
+	}
+	return s.publish(ctx, current, reviewer, note)
+}
+
+// Reject sends a revision in review, or a scheduled one, back to its author
+func (s *Service) Reject(ctx context.Context, id int32, note string, actor int32) (*Revision, error) {
+	current, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.move(ctx, current, []string{StatusReview, StatusScheduled}, StatusDraft, sql.NullTime{}, sql.NullInt32{Int32: actor, Valid: true}, ActionRejected, note, actor)
+}
+
+// Discard abandons a revision that has not been published
+func (s *Service) Discard(ctx context.Context, id int32, note string, actor int32) (*Revision, error) {
+	current, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.move(ctx, current, []string{StatusDraft, StatusReview, StatusScheduled}, StatusDiscarded, sql.NullTime{}, sql.NullInt32{}, ActionDiscarded, note, actor)
+}
+
+// PublishDue publishes scheduled revisions whose time has come. Each revision
+// is claimed with a conditional update, so instances running this at the same
+// time never publish a revision twice.
+func (s *Service) PublishDue(ctx context.Context) (int, error) {
+	due, err := s.store.ListDueContentRevisions(ctx, db.ListDueContentRevisionsParams{
+		PublishAt: sql.NullTime{Time: s.now(), Valid: true},
+		Limit:     duePublishBatch,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list scheduled revisions: %w", err)
+	}
+
+	published := 0
+	for _, revision := range due {
+		_, err := s.publish(ctx, revision, sql.NullInt32{}, "Published on schedule")
+		switch {
+		case err == nil:
+			published++
+		case errors.Is(err, ErrInvalidTransition):
+			// Published by another instance or rejected in the meantime
+		default:
+			logger.Warn("Failed to publish scheduled revision %d of %s %d: %v", revision.ID, revision.ContentType, revision.ContentID, err)
+		}
+	}
+	return published, nil
+}
+
+// Get returns a revision
+func (s *Service) Get(ctx context.Context, id int32) (*Revision, error) {
+	revision, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return newRevision(revision), nil
+}
+
+// List returns revisions, most recently changed first
+func (s *Service) List(ctx context.Context, filter Filter) ([]Revision, error) {
+	rows, err := s.store.ListContentRevisions(ctx, db.ListContentRevisionsParams{
+		Status:      filter.Status,
+		ContentType: filter.ContentType,
+		LimitCount:  filter.Limit,
+		OffsetCount: filter.Offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list revisions: %w", err)
+	}
+	return newRevisions(rows), nil
+}
+
+// Versions returns every revision of a piece of content, newest first
+func (s *Service) Versions(ctx context.Context, contentType string, contentID int32) ([]Revision, error) {
+	if !ValidType(contentType) {
+		return nil, ErrUnknownType
+	}
+	rows, err := s.store.ListContentRevisionsForContent(ctx, db.ListContentRevisionsForContentParams{
+		ContentType: contentType,
+		ContentID:   contentID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list revisions: %w", err)
+	}
+	return newRevisions(rows), nil
+}
+
+// History returns the workflow changes of a piece of content, newest first
+func (s *Service) History(ctx context.Context, contentType string, contentID int32, limit, offset int32) ([]HistoryEntry, error) {
+	if !ValidType(contentType) {
+		return nil, ErrUnknownType
+	}
+	rows, err := s.store.ListContentHistory(ctx, db.ListContentHistoryParams{
+		ContentType: contentType,
+		ContentID:   contentID,
+		Limit:       limit,
+		Offset:      offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list content history: %w", err)
+	}
+
+	entries := make([]HistoryEntry, 0, len(rows))
+	for _, row := range rows {
+		entries = append(entries, HistoryEntry{
+			ID:         row.ID,
+			RevisionID: nullInt32Ptr(row.RevisionID),
+			Action:     row.Action,
+			FromStatus: row.FromStatus,
+			ToStatus:   row.ToStatus,
+			ActorID:    nullInt32Ptr(row.ActorID),
+			Note:       row.Note,
+			CreatedAt:  row.CreatedAt,
+		})
+	}
+	return entries, nil
+}
+
+func (s *Service) get(ctx context.Context, id int32) (db.ContentRevision, error) {
+	revision, err := s.store.GetContentRevision(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return revision, ErrRevisionNotFound
+		}
+		return revision, fmt.Errorf("failed to get revision: %w", err)
+	}
+	return revision, nil
+}
+
+// current reads the live version of a piece of content
+func (s *Service) current(ctx context.Context, contentType string, contentID int32) (Content, error) {
+	switch contentType {
+	case TypeGrammar:
+		grammar, err := s.store.GetGrammar(ctx, contentID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, ErrContentNotFound
+			}
+			return nil, fmt.Errorf("failed to get grammar: %w", err)
+		}
+		return &GrammarData{
+			Level:      grammar.Level,
+			Title:      grammar.Title,
+			Tag:        grammar.Tag,
+			GrammarKey: grammar.GrammarKey,
+			Related:    grammar.Related,
+			Contents:   grammar.Contents,
+		}, nil
+	case TypeWritingPrompt:
+		prompt, err := s.store.GetWritingPrompt(ctx, contentID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, ErrContentNotFound
+			}
+			return nil, fmt.Errorf("failed to get writing prompt: %w", err)
+		}
+		return &WritingPromptData{
+			PromptText:      prompt.PromptText,
+			Topic:           prompt.Topic.String,
+			DifficultyLevel: prompt.DifficultyLevel.String,
+		}, nil
+	}
+	return nil, ErrUnknownType
+}
+
+// move changes the status of a revision if it is in one of the from statuses
+func (s *Service) move(ctx context.Context, current db.ContentRevision, from []string, to string, publishAt sql.NullTime, reviewer sql.NullInt32, action, note string, actor int32) (*Revision, error) {
+	if !slices.Contains(from, current.Status) {
+		return nil, fmt.Errorf("%w: revision is %s", ErrInvalidTransition, current.Status)
+	}
+	revision, err := s.transition(ctx, current, to, publishAt, reviewer)
+	if err != nil {
+		return nil, err
+	}
+	s.record(ctx, revision, action, current.Status, to, actor, note)
+	return newRevision(revision), nil
+}
+
+// transition changes the status of a revision unless it changed since it was read
+func (s *Service) transition(ctx context.Context, current db.ContentRevision, to string, publishAt sql.NullTime, reviewer sql.NullInt32) (db.ContentRevision, error) {
+	revision, err := s.store.TransitionContentRevision(ctx, db.TransitionContentRevisionParams{
+		ToStatus:     to,
+		PublishAt:    publishAt,
+		ReviewedBy:   reviewer,
+		ID:           current.ID,
+		FromStatuses: []string{current.Status},
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return revision, fmt.Errorf("%w: revision changed while it was being updated", ErrInvalidTransition)
+		}
+		return revision, fmt.Errorf("failed to update revision: %w", err)
+	}
+	return revision, nil
+}
+
+// publish claims a revision and makes its data the live version of the content
+func (s *Service) publish(ctx context.Context, current db.ContentRevision, reviewer sql.NullInt32, note string) (*Revision, error) {
+	content, err := decodeContent(current.ContentType, current.Data)
+	if err != nil {
+		return nil, err
+	}
+	revision, err := s.transition(ctx, current, StatusPublished, current.PublishAt, reviewer)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.apply(ctx, current.ContentID, content); err != nil {
+		if errors.Is(err, ErrContentNotFound) {
+			// The content was deleted while the revision was pending
+			if discarded, discardErr := s.transition(ctx, revision, StatusDiscarded, revision.PublishAt, sql.NullInt32{}); discardErr == nil {
+				s.record(ctx, discarded, ActionDiscarded, current.Status, StatusDiscarded, 0, "Content was deleted")
+			}
+			return nil, err
+		}
+		// Put the revision back so publishing can be retried
+		if _, rollbackErr := s.transition(ctx, revision, current.Status, current.PublishAt, current.ReviewedBy); rollbackErr != nil {
+			logger.Error("Failed to restore revision %d after a failed publish: %v", current.ID, rollbackErr)
+		}
+		return nil, err
+	}
+
+	s.record(ctx, revision, ActionPublished, current.Status, StatusPublished, reviewer.Int32, note)
+	return newRevision(revision), nil
+}
+
+// apply writes content as the live version and marks it published
+func (s *Service) apply(ctx context.Context, contentID int32, content Content) error {
+	var err error
+	switch c := content.(type) {
+	case *GrammarData:
+		_, err = s.store.PublishGrammar(ctx, db.PublishGrammarParams{
+			ID:         contentID,
+			Level:      c.Level,
+			Title:      c.Title,
+			Tag:        nonNilStrings(c.Tag),
+			GrammarKey: c.GrammarKey,
+			Related:    nonNilInt32s(c.Related),
+			Contents:   c.Contents,
+		})
+	case *WritingPromptData:
+		_, err = s.store.PublishWritingPrompt(ctx, db.PublishWritingPromptParams{
+			ID:              contentID,
+			PromptText:      c.PromptText,
+			Topic:           nullString(c.Topic),
+			DifficultyLevel: nullString(c.DifficultyLevel),
+		})
+	default:
+		return ErrUnknownType
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrContentNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to publish %s %d: %w", content.ContentType(), contentID, err)
+	}
+	return nil
+}
+
+// record adds a history entry. History is best effort and never fails the
+// change it describes. An actor of 0 is the system.
+func (s *Service) record(ctx context.Context, revision db.ContentRevision, action, from, to string, actor int32, note string) {
+	err := s.store.AddContentHistory(ctx, db.AddContentHistoryParams{
+		ContentType: revision.ContentType,
+		ContentID:   revision.ContentID,
+		RevisionID:  sql.NullInt32{Int32: revision.ID, Valid: true},
+		Action:      action,
+		FromStatus:  from,
+		ToStatus:    to,
+		ActorID:     sql.NullInt32{Int32: actor, Valid: actor != 0},
+		Note:        note,
+	})
+	if err != nil {
+		logger.Warn("Failed to record %s of revision %d: %v", action, revision.ID, err)
+	}
+}
+
+func newRevision(row db.ContentRevision) *Revision {
+	return &Revision{
+		ID:          row.ID,
+		ContentType: row.ContentType,
+		ContentID:   row.ContentID,
+		Version:     row.Version,
+		Status:      row.Status,
+		Data:        row.Data,
+		Note:        row.Note,
+		PublishAt:   nullTimePtr(row.PublishAt),
+		CreatedBy:   nullInt32Ptr(row.CreatedBy),
+		ReviewedBy:  nullInt32Ptr(row.ReviewedBy),
+		CreatedAt:   row.CreatedAt,
+		UpdatedAt:   row.UpdatedAt,
+		PublishedAt: nullTimePtr(row.PublishedAt),
+	}
+}
+
+func newRevisions(rows []db.ContentRevision) []Revision {
+	revisions := make([]Revision, 0, len(rows))
+	for _, row := range rows {
+		revisions = append(revisions, *newRevision(row))
+	}
+	return revisions
+}
+
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+func nullInt32Ptr(n sql.NullInt32) *int32 {
+	if !n.Valid {
+		return nil
+	}
+	return &n.Int32
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+func nonNilInt32s(values []int32) []int32 {
+	if values == nil {
+		return []int32{}
+	}
+	return values
+}
@@ -0,0 +1,276 @@
+package authoring
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+// contentStore keeps grammars, revisions and history in memory; other
+// Querier methods are not used
+type contentStore struct {
+	db.Querier
+	grammars  map[int32]db.Grammar
+	revisions map[int32]db.ContentRevision
+	history   []db.AddContentHistoryParams
+}
+
+func newContentStore() *contentStore {
+	return &contentStore{grammars: make(map[int32]db.Grammar), revisions: make(map[int32]db.ContentRevision)}
+}
+
+func (s *contentStore) CreateGrammarDraft(ctx context.Context, arg db.CreateGrammarDraftParams) (db.Grammar, error) {
+	grammar := db.Grammar{ID: int32(len(s.grammars) + 1), Level: arg.Level, Title: arg.Title, Tag: arg.Tag, GrammarKey: arg.GrammarKey, Related: arg.Related, Contents: arg.Contents, Status: "draft"}
+	s.grammars[grammar.ID] = grammar
+	return grammar, nil
+}
+
+func (s *contentStore) GetGrammar(ctx context.Context, id int32) (db.Grammar, error) {
+	grammar, ok := s.grammars[id]
+	if !ok {
+		return grammar, sql.ErrNoRows
+	}
+	return grammar, nil
+}
+
+func (s *contentStore) PublishGrammar(ctx context.Context, arg db.PublishGrammarParams) (db.Grammar, error) {
+	if _, ok := s.grammars[arg.ID]; !ok {
+		return db.Grammar{}, sql.ErrNoRows
+	}
+	grammar := db.Grammar{ID: arg.ID, Level: arg.Level, Title: arg.Title, Tag: arg.Tag, GrammarKey: arg.GrammarKey, Related: arg.Related, Contents: arg.Contents, Status: "published"}
+	s.grammars[arg.ID] = grammar
+	return grammar, nil
+}
+
+func (s *contentStore) CreateContentRevision(ctx context.Context, arg db.CreateContentRevisionParams) (db.ContentRevision, error) {
+	version := int32(1)
+	for _, revision := range s.revisions {
+		if revision.ContentType != arg.ContentType || revision.ContentID != arg.ContentID {
+			continue
+		}
+		if slices.Contains([]string{StatusDraft, StatusReview, StatusScheduled}, revision.Status) {
+			return db.ContentRevision{}, &pq.Error{Code: "23505"}
+		}
+		version = max(version, revision.Version+1)
+	}
+	revision := db.ContentRevision{ID: int32(len(s.revisions) + 1), ContentType: arg.ContentType, ContentID: arg.ContentID, Version: version, Status: StatusDraft, Data: arg.Data, Note: arg.Note, CreatedBy: arg.CreatedBy}
+	s.revisions[revision.ID] = revision
+	return revision, nil
+}
+
+func (s *contentStore) GetContentRevision(ctx context.Context, id int32) (db.ContentRevision, error) {
+	revision, ok := s.revisions[id]
+	if !ok {
+		return revision, sql.ErrNoRows
+	}
+	return revision, nil
+}
+
+func (s *contentStore) UpdateContentRevisionData(ctx context.Context, arg db.UpdateContentRevisionDataParams) (db.ContentRevision, error) {
+	revision, ok := s.revisions[arg.ID]
+	if !ok || revision.Status != StatusDraft {
+		return db.ContentRevision{}, sql.ErrNoRows
+	}
+	revision.Data, revision.Note = arg.Data, arg.Note
+	s.revisions[arg.ID] = revision
+	return revision, nil
+}
+
+func (s *contentStore) TransitionContentRevision(ctx context.Context, arg db.TransitionContentRevisionParams) (db.ContentRevision, error) {
+	revision, ok := s.revisions[arg.ID]
+	if !ok || !slices.Contains(arg.FromStatuses, revision.Status) {
+		return db.ContentRevision{}, sql.ErrNoRows
+	}
+	revision.Status, revision.PublishAt = arg.ToStatus, arg.PublishAt
+	if arg.ReviewedBy.Valid {
+		revision.ReviewedBy = arg.ReviewedBy
+	}
+	if arg.ToStatus == StatusPublished {
+		revision.PublishedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	}
+	s.revisions[arg.ID] = revision
+	return revision, nil
+}
+
+func (s *contentStore) ListDueContentRevisions(ctx context.Context, arg db.ListDueContentRevisionsParams) ([]db.ContentRevision, error) {
+	var due []db.ContentRevision
+	for _, revision := range s.revisions {
+		if revision.Status == StatusScheduled && !revision.PublishAt.Time.After(arg.PublishAt.Time) {
+			due = append(due, revision)
+		}
+	}
+	return due, nil
+}
+
+func (s *contentStore) AddContentHistory(ctx context.Context, arg db.AddContentHistoryParams) error {
+	s.history = append(s.history, arg)
+	return nil
+}
+
+func (s *contentStore) actions() []string {
+	var actions []string
+	for _, entry := range s.history {
+		actions = append(actions, entry.Action)
+	}
+	return actions
+}
+
+func newGrammar(title string) *GrammarData {
+	return &GrammarData{Level: 1, Title: title, GrammarKey: "present-simple", Contents: json.RawMessage(`[]`)}
+}
+
+func TestCreateDraftIsNotPublished(t *testing.T) {
+	store := newContentStore()
+	service := NewService(store)
+
+	revision, err := service.Create(context.Background(), newGrammar("Present simple"), "", 7)
+	require.NoError(t, err)
+	assert.Equal(t, StatusDraft, revision.Status)
+	assert.Equal(t, int32(1), revision.Version)
+	assert.Equal(t, "draft", store.grammars[revision.ContentID].Status)
+	assert.Equal(t, []string{ActionCreated}, store.actions())
+}
+
+func TestReviewAndPublish(t *testing.T) {
+	store := newContentStore()
+	service := NewService(store)
+	ctx := context.Background()
+
+	revision, err := service.Create(ctx, newGrammar("Present simple"), "", 7)
+	require.NoError(t, err)
+
+	// Drafts cannot skip review
+	_, err = service.Approve(ctx, revision.ID, time.Time{}, "", 9)
+	assert.ErrorIs(t, err, ErrInvalidTransition)
+
+	_, err = service.Submit(ctx, revision.ID, "", 7)
+	require.NoError(t, err)
+
+	// Content in review is locked for edits
+	_, err = service.Edit(ctx, revision.ID, newGrammar("Changed"), "", 7)
+	assert.ErrorIs(t, err, ErrInvalidTransition)
+
+	published, err := service.Approve(ctx, revision.ID, time.Time{}, "looks good", 9)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPublished, published.Status)
+	require.NotNil(t, published.ReviewedBy)
+	assert.Equal(t, int32(9), *published.ReviewedBy)
+	assert.Equal(t, "published", store.grammars[revision.ContentID].Status)
+	assert.Equal(t, []string{ActionCreated, ActionSubmitted, ActionPublished}, store.actions())
+}
+
+func TestRevisionKeepsPublishedVersionUntilApproved(t *testing.T) {
+	store := newContentStore()
+	service := NewService(store)
+	ctx := context.Background()
+	store.grammars[1] = db.Grammar{ID: 1, Level: 1, Title: "Old title", GrammarKey: "key", Status: "published"}
+
+	revision, err := service.StartRevision(ctx, TypeGrammar, 1, nil, "", 7)
+	require.NoError(t, err)
+	assert.JSONEq(t, `"Old title"`, string(mustField(t, revision.Data, "title")))
+
+	_, err = service.StartRevision(ctx, TypeGrammar, 1, nil, "", 8)
+	assert.ErrorIs(t, err, ErrRevisionInProgress)
+
+	_, err = service.Edit(ctx, revision.ID, &WritingPromptData{PromptText: "Describe"}, "", 7)
+	assert.ErrorIs(t, err, ErrContentTypeMismatch)
+
+	_, err = service.Edit(ctx, revision.ID, newGrammar("New title"), "", 7)
+	require.NoError(t, err)
+	_, err = service.Submit(ctx, revision.ID, "", 7)
+	require.NoError(t, err)
+	assert.Equal(t, "Old title", store.grammars[1].Title)
+
+	_, err = service.Approve(ctx, revision.ID, time.Time{}, "", 9)
+	require.NoError(t, err)
+	assert.Equal(t, "New title", store.grammars[1].Title)
+
+	// Once published a new revision can be started
+	next, err := service.StartRevision(ctx, TypeGrammar, 1, nil, "", 7)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), next.Version)
+}
+
+func TestScheduledPublishing(t *testing.T) {
+	store := newContentStore()
+	service := NewService(store)
+	ctx := context.Background()
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	revision, err := service.Create(ctx, newGrammar("Present simple"), "", 7)
+	require.NoError(t, err)
+	_, err = service.Submit(ctx, revision.ID, "", 7)
+	require.NoError(t, err)
+
+	scheduled, err := service.Approve(ctx, revision.ID, now.Add(time.Hour), "", 9)
+	require.NoError(t, err)
+	assert.Equal(t, StatusScheduled, scheduled.Status)
+
+	published, err := service.PublishDue(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, published)
+	assert.Equal(t, "draft", store.grammars[revision.ContentID].Status)
+
+	now = now.Add(2 * time.Hour)
+	published, err = service.PublishDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, published)
+	assert.Equal(t, StatusPublished, store.revisions[revision.ID].Status)
+	assert.Equal(t, "published", store.grammars[revision.ContentID].Status)
+
+	last := store.history[len(store.history)-1]
+	assert.Equal(t, ActionPublished, last.Action)
+	assert.False(t, last.ActorID.Valid)
+}
+
+func TestPublishingDeletedContentDiscardsRevision(t *testing.T) {
+	store := newContentStore()
+	service := NewService(store)
+	ctx := context.Background()
+
+	revision, err := service.Create(ctx, newGrammar("Present simple"), "", 7)
+	require.NoError(t, err)
+	_, err = service.Submit(ctx, revision.ID, "", 7)
+	require.NoError(t, err)
+	delete(store.grammars, revision.ContentID)
+
+	_, err = service.Approve(ctx, revision.ID, time.Time{}, "", 9)
+	assert.ErrorIs(t, err, ErrContentNotFound)
+	assert.Equal(t, StatusDiscarded, store.revisions[revision.ID].Status)
+}
+
+func TestRejectReturnsToDraft(t *testing.T) {
+	store := newContentStore()
+	service := NewService(store)
+	ctx := context.Background()
+
+	revision, err := service.Create(ctx, newGrammar("Present simple"), "", 7)
+	require.NoError(t, err)
+	_, err = service.Submit(ctx, revision.ID, "", 7)
+	require.NoError(t, err)
+
+	rejected, err := service.Reject(ctx, revision.ID, "add examples", 9)
+	require.NoError(t, err)
+	assert.Equal(t, StatusDraft, rejected.Status)
+
+	_, err = service.Discard(ctx, revision.ID, "", 7)
+	require.NoError(t, err)
+	_, err = service.Submit(ctx, revision.ID, "", 7)
+	assert.ErrorIs(t, err, ErrInvalidTransition)
+}
+
+func mustField(t *testing.T, data json.RawMessage, field string) json.RawMessage {
+	t.Helper()
+	var fields map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(data, &fields))
+	return fields[field]
+}
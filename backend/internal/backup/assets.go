@@ -0,0 +1,401 @@
+package backup
+
+import (
+	"archive/tar"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/toeic-app/internal/logger"
+)
+
+// Full backups are written with an asset manifest, <backup>.assets, listing
+// the media assets the database refers to: uploaded images and audio,
+// synthesized speech and proctoring snapshots. With BACKUP_ASSETS_DOWNLOAD
+// the assets are stored in a tar, <backup>.media, encrypted like the
+// backup, so that media storage can be rebuilt along with the database.
+// Both travel with the backup as sidecars and are listed in its manifest.
+const (
+	assetsSuffix       = ".assets"
+	assetArchiveSuffix = ".media"
+)
+
+// Kinds of references to media assets
+const (
+	AssetURL      = "url"       // A delivery URL
+	AssetPublicID = "public_id" // A private asset, delivered through signed URLs
+)
+
+// ErrAssetsNotFound is returned for a backup written without an asset
+// manifest, or without assets when they are extracted
+var ErrAssetsNotFound = errors.New("backup has no media assets")
+
+// AssetColumn is a column referring to media assets
+type AssetColumn struct {
+	Table  string
+	Column string
+	Kind   string // AssetURL or AssetPublicID
+	// ResourceType is image or video; audio is stored as video
+	ResourceType string
+}
+
+// AssetColumns are the columns referring to media assets. Columns missing
+// from the database are skipped.
+var AssetColumns = []AssetColumn{
+	{Table: "user_profiles", Column: "avatar_url", Kind: AssetURL, ResourceType: "image"},
+	{Table: "badges", Column: "icon_url", Kind: AssetURL, ResourceType: "image"},
+	{Table: "questions", Column: "image_url", Kind: AssetURL, ResourceType: "image"},
+	{Table: "questions", Column: "media_url", Kind: AssetURL, ResourceType: "video"},
+	{Table: "words", Column: "audio_url", Kind: AssetURL, ResourceType: "video"},
+	{Table: "speaking_turns", Column: "audio_recording_path", Kind: AssetURL, ResourceType: "video"},
+	{Table: "tts_clips", Column: "public_id", Kind: AssetPublicID, ResourceType: "video"},
+	{Table: "exam_proctoring_photos", Column: "public_id", Kind: AssetPublicID, ResourceType: "image"},
+}
+
+// AssetSigner signs the delivery URLs of private assets so they can be
+// downloaded. *uploader.CloudinaryUploader satisfies it.
+type AssetSigner interface {
+	SignedAudioURL(publicID string) (string, error)
+	SignedImageURL(publicID string, expiresAt time.Time) (string, error)
+}
+
+// Asset is a media asset the database refers to
+type Asset struct {
+	Ref          string   `json:"ref"` // Delivery URL or public ID
+	Kind         string   `json:"kind" example:"url"`
+	ResourceType string   `json:"resource_type" example:"image"`
+	Columns      []string `json:"columns"` // table.column referring to it
+	// File is the asset in the asset archive once downloaded
+	File   string `json:"file,omitempty"`
+	Size   int64  `json:"size,omitempty"`
+	SHA256 string `json:"sha256,omitempty"`
+	// Error tells why a download failed
+	Error string `json:"error,omitempty"`
+}
+
+// AssetManifest lists the media assets of a backup
+type AssetManifest struct {
+	Backup     string    `json:"backup"`
+	CreatedAt  time.Time `json:"created_at"`
+	Assets     []Asset   `json:"assets"`
+	Downloaded int       `json:"downloaded"`
+	Failed     int       `json:"failed"`
+	// Archive is the asset archive, encrypted when the backup is
+	Archive   string `json:"archive,omitempty"`
+	Encrypted bool   `json:"encrypted,omitempty"`
+}
+
+// SetAssetSigner makes the manager download private assets, signing their
+// URLs with signer
+func (bm *BackupManager) SetAssetSigner(signer AssetSigner) {
+	bm.assetSigner = signer
+}
+
+// collectAssets lists the media assets the database refers to
+func (bm *BackupManager) collectAssets(ctx context.Context) ([]Asset, error) {
+	rows, err := bm.query(ctx, "SELECT table_name, column_name FROM information_schema.columns WHERE table_schema = 'public'")
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		if len(row) == 2 {
+			existing[row[0]+"."+row[1]] = true
+		}
+	}
+
+	refs := make(map[AssetColumn][]string)
+	for _, column := range AssetColumns {
+		if !existing[column.Table+"."+column.Column] {
+			continue
+		}
+		rows, err := bm.query(ctx, fmt.Sprintf("SELECT DISTINCT %[1]s FROM %[2]s WHERE %[1]s IS NOT NULL AND %[1]s <> ''", column.Column, column.Table))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list assets of %s.%s: %w", column.Table, column.Column, err)
+		}
+		for _, row := range rows {
+			// Values holding the field separator come back split
+			refs[column] = append(refs[column], strings.Join(row, "|"))
+		}
+	}
+	return mergeAssets(refs), nil
+}
+
+// mergeAssets lists each referenced asset once with the columns referring
+// to it, sorted by reference
+func mergeAssets(refs map[AssetColumn][]string) []Asset {
+	byRef := make(map[string]*Asset)
+	for _, column := range AssetColumns {
+		for _, ref := range refs[column] {
+			ref = strings.TrimSpace(ref)
+			if ref == "" {
+				continue
+			}
+			asset, ok := byRef[ref]
+			if !ok {
+				asset = &Asset{Ref: ref, Kind: column.Kind, ResourceType: column.ResourceType}
+				byRef[ref] = asset
+			}
+			asset.Columns = append(asset.Columns, column.Table+"."+column.Column)
+		}
+	}
+
+	assets := make([]Asset, 0, len(byRef))
+	for _, asset := range byRef {
+		assets = append(assets, *asset)
+	}
+	sort.Slice(assets, func(i, j int) bool { return assets[i].Ref < assets[j].Ref })
+	return assets
+}
+
+// backupAssets writes the asset manifest of a backup in BackupDir and,
+// with DownloadAssets, its asset archive. It returns the manifest.
+func (bm *BackupManager) backupAssets(ctx context.Context, filename string, encrypt bool) (*AssetManifest, error) {
+	assets, err := bm.collectAssets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	manifest := &AssetManifest{Backup: filename, CreatedAt: time.Now(), Assets: assets}
+
+	path := filepath.Join(bm.config.BackupDir, filename)
+	if bm.config.DownloadAssets && len(assets) > 0 {
+		if err := bm.downloadAssets(ctx, manifest, path+assetArchiveSuffix, encrypt); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal asset manifest: %w", err)
+	}
+	if err := os.WriteFile(path+assetsSuffix, data, 0644); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// downloadAssets downloads the assets of manifest into a tar at
+// archivePath, encrypted when encrypt is set. Assets failing to download
+// are recorded in the manifest and skipped.
+func (bm *BackupManager) downloadAssets(ctx context.Context, manifest *AssetManifest, archivePath string, encrypt bool) error {
+	tarPath := archivePath + ".tmp"
+	defer os.Remove(tarPath)
+	out, err := os.Create(tarPath)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	writer := tar.NewWriter(out)
+
+	for i := range manifest.Assets {
+		asset := &manifest.Assets[i]
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := bm.downloadAsset(ctx, writer, asset); err != nil {
+			logger.Warn("Failed to back up media asset %s: %v", asset.Ref, err)
+			asset.Error = err.Error()
+			manifest.Failed++
+			continue
+		}
+		manifest.Downloaded++
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	manifest.Archive = filepath.Base(archivePath)
+	if !encrypt {
+		return os.Rename(tarPath, archivePath)
+	}
+	if _, err := bm.encryptFile(ctx, tarPath, archivePath); err != nil {
+		os.Remove(archivePath)
+		return fmt.Errorf("failed to encrypt media assets: %w", err)
+	}
+	manifest.Encrypted = true
+	return nil
+}
+
+// downloadAsset adds an asset to the asset archive
+func (bm *BackupManager) downloadAsset(ctx context.Context, writer *tar.Writer, asset *Asset) error {
+	source, err := bm.assetURL(asset)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := assetClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("download returned %s", resp.Status)
+	}
+
+	// Kept in a temporary file as tar needs the size up front
+	tmp, err := os.CreateTemp(bm.config.BackupDir, "asset_*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+	body := io.Reader(resp.Body)
+	if bm.config.AssetMaxSize > 0 {
+		body = io.LimitReader(resp.Body, bm.config.AssetMaxSize+1)
+	}
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), body)
+	if err != nil {
+		return err
+	}
+	if bm.config.AssetMaxSize > 0 && size > bm.config.AssetMaxSize {
+		return fmt.Errorf("asset is larger than %d bytes", bm.config.AssetMaxSize)
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	asset.Size = size
+	asset.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	asset.File = assetFileName(asset.Ref, source)
+	if err := writer.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     asset.File,
+		Size:     size,
+		Mode:     0600,
+		ModTime:  time.Now(),
+	}); err != nil {
+		return err
+	}
+	_, err = io.Copy(writer, tmp)
+	return err
+}
+
+// assetURL returns the URL an asset is downloaded from
+func (bm *BackupManager) assetURL(asset *Asset) (string, error) {
+	if asset.Kind == AssetPublicID {
+		if bm.assetSigner == nil {
+			return "", fmt.Errorf("no media storage to sign the URL of a private asset")
+		}
+		if asset.ResourceType == "image" {
+			return bm.assetSigner.SignedImageURL(asset.Ref, time.Now().Add(time.Hour))
+		}
+		return bm.assetSigner.SignedAudioURL(asset.Ref)
+	}
+	parsed, err := url.Parse(asset.Ref)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", fmt.Errorf("not a URL")
+	}
+	if !slices.Contains(bm.config.AssetHosts, parsed.Hostname()) {
+		return "", fmt.Errorf("%s is not a media storage host", parsed.Hostname())
+	}
+	return asset.Ref, nil
+}
+
+// assetClient downloads media assets
+var assetClient = &http.Client{Timeout: 5 * time.Minute}
+
+// assetFileName names an asset in the archive after a hash of its
+// reference, keeping the extension of the URL it was downloaded from
+func assetFileName(ref, source string) string {
+	sum := sha256.Sum256([]byte(ref))
+	name := hex.EncodeToString(sum[:16])
+	if parsed, err := url.Parse(source); err == nil {
+		ext := path.Ext(parsed.Path)
+		if len(ext) > 1 && len(ext) <= 6 && !strings.ContainsAny(ext, "/\\") {
+			name += strings.ToLower(ext)
+		}
+	}
+	return name
+}
+
+// Assets returns the asset manifest of a backup, downloading it from
+// remote storage when there is no local copy of the backup
+func (bm *BackupManager) Assets(ctx context.Context, filename string) (*AssetManifest, error) {
+	if !bm.isValidBackupFilename(filename) {
+		return nil, fmt.Errorf("invalid backup filename")
+	}
+	path, downloaded, err := bm.localCopy(ctx, filename)
+	if err != nil {
+		return nil, err
+	}
+	if downloaded && !bm.config.KeepLocalCopy {
+		defer bm.removeLocal(filename)
+	}
+	return readAssetManifest(path)
+}
+
+// readAssetManifest reads the asset manifest of the backup at path
+func readAssetManifest(path string) (*AssetManifest, error) {
+	data, err := os.ReadFile(path + assetsSuffix)
+	if os.IsNotExist(err) {
+		return nil, ErrAssetsNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var manifest AssetManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("unreadable asset manifest: %w", err)
+	}
+	return &manifest, nil
+}
+
+// ExtractAssets writes the downloaded media assets of a backup into dir,
+// named as in its asset manifest, which it returns. The backup and its
+// manifest are checked first.
+func (bm *BackupManager) ExtractAssets(ctx context.Context, filename, dir string) (*AssetManifest, error) {
+	if !bm.isValidBackupFilename(filename) {
+		return nil, fmt.Errorf("invalid backup filename")
+	}
+	path, downloaded, err := bm.localCopy(ctx, filename)
+	if err != nil {
+		return nil, err
+	}
+	if downloaded && !bm.config.KeepLocalCopy {
+		defer bm.removeLocal(filename)
+	}
+	if _, err := bm.checkManifest(path); err != nil && !errors.Is(err, ErrManifestNotFound) {
+		return nil, err
+	}
+	manifest, err := readAssetManifest(path)
+	if err != nil {
+		return nil, err
+	}
+	if manifest.Archive == "" {
+		return nil, ErrAssetsNotFound
+	}
+
+	archive := path + assetArchiveSuffix
+	if manifest.Encrypted {
+		decrypted := archive + ".decrypted.tmp"
+		defer os.Remove(decrypted)
+		if err := bm.decryptFile(ctx, archive, decrypted); err != nil {
+			return nil, fmt.Errorf("failed to decrypt media assets: %w", err)
+		}
+		archive = decrypted
+	}
+	if err := untarDirectory(archive, dir); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
@@ -0,0 +1,140 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toeic-app/internal/config"
+)
+
+type testSigner struct{ base string }
+
+func (s testSigner) SignedAudioURL(publicID string) (string, error) {
+	return s.base + "/signed/" + publicID + ".mp3", nil
+}
+
+func (s testSigner) SignedImageURL(publicID string, _ time.Time) (string, error) {
+	return s.base + "/signed/" + publicID + ".jpg", nil
+}
+
+func TestMergeAssets(t *testing.T) {
+	assets := mergeAssets(map[AssetColumn][]string{
+		{Table: "questions", Column: "image_url", Kind: AssetURL, ResourceType: "image"}:      {"https://res.cloudinary.com/a.png", ""},
+		{Table: "badges", Column: "icon_url", Kind: AssetURL, ResourceType: "image"}:          {"https://res.cloudinary.com/a.png"},
+		{Table: "tts_clips", Column: "public_id", Kind: AssetPublicID, ResourceType: "video"}: {"tts/clip_1"},
+	})
+
+	require.Len(t, assets, 2)
+	assert.Equal(t, "https://res.cloudinary.com/a.png", assets[0].Ref)
+	assert.Equal(t, []string{"badges.icon_url", "questions.image_url"}, assets[0].Columns)
+	assert.Equal(t, "tts/clip_1", assets[1].Ref)
+	assert.Equal(t, AssetPublicID, assets[1].Kind)
+}
+
+// writeTestAssets downloads assets from a test server into the asset
+// archive of filename and writes its asset manifest
+func writeTestAssets(t *testing.T, bm *BackupManager, filename string, encrypt bool) *AssetManifest {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/audio.mp3", "/signed/tts/clip_1.mp3":
+			w.Write([]byte("audio of " + r.URL.Path))
+		case "/large.png":
+			w.Write([]byte(strings.Repeat("x", 64)))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	bm.config.AssetHosts = []string{"127.0.0.1"}
+	bm.config.AssetMaxSize = 32
+	bm.SetAssetSigner(testSigner{base: srv.URL})
+
+	manifest := &AssetManifest{Backup: filename, Assets: []Asset{
+		{Ref: srv.URL + "/audio.mp3", Kind: AssetURL},
+		{Ref: srv.URL + "/large.png", Kind: AssetURL},
+		{Ref: srv.URL + "/missing.png", Kind: AssetURL},
+		{Ref: "https://example.com/elsewhere.png", Kind: AssetURL},
+		{Ref: "tts/clip_1", Kind: AssetPublicID, ResourceType: "video"},
+	}}
+	path := filepath.Join(bm.config.BackupDir, filename)
+	require.NoError(t, bm.downloadAssets(context.Background(), manifest, path+assetArchiveSuffix, encrypt))
+	data, err := json.Marshal(manifest)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path+assetsSuffix, data, 0644))
+	return manifest
+}
+
+func TestDownloadAssets(t *testing.T) {
+	dir := t.TempDir()
+	bm := NewBackupManager(config.BackupConfig{BackupDir: dir, ManifestKey: testManifestKey}, config.Config{DBName: "toeic"})
+	manifest := writeTestAssets(t, bm, "manual_backup_1.sql", false)
+	writeTestBackup(t, bm, "manual_backup_1.sql")
+
+	assert.Equal(t, 2, manifest.Downloaded)
+	assert.Equal(t, 3, manifest.Failed)
+	assert.Equal(t, "manual_backup_1.sql"+assetArchiveSuffix, manifest.Archive)
+	assert.False(t, manifest.Encrypted)
+	assert.Contains(t, manifest.Assets[1].Error, "larger than 32 bytes")
+	assert.Contains(t, manifest.Assets[2].Error, "404")
+	assert.Contains(t, manifest.Assets[3].Error, "is not a media storage host")
+	assert.True(t, strings.HasSuffix(manifest.Assets[0].File, ".mp3"))
+
+	// The asset manifest and archive are covered by the backup manifest
+	signed, err := bm.VerifyManifest(context.Background(), "manual_backup_1.sql")
+	require.NoError(t, err)
+	assert.True(t, signed)
+	var backupManifest Manifest
+	data, err := os.ReadFile(filepath.Join(dir, "manual_backup_1.sql"+manifestSuffix))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &backupManifest))
+	var names []string
+	for _, file := range backupManifest.Files {
+		names = append(names, file.Name)
+	}
+	assert.Contains(t, names, "manual_backup_1.sql"+assetsSuffix)
+	assert.Contains(t, names, "manual_backup_1.sql"+assetArchiveSuffix)
+
+	read, err := bm.Assets(context.Background(), "manual_backup_1.sql")
+	require.NoError(t, err)
+	assert.Equal(t, manifest.Assets, read.Assets)
+
+	out := t.TempDir()
+	_, err = bm.ExtractAssets(context.Background(), "manual_backup_1.sql", out)
+	require.NoError(t, err)
+	content, err := os.ReadFile(filepath.Join(out, manifest.Assets[4].File))
+	require.NoError(t, err)
+	assert.Equal(t, "audio of /signed/tts/clip_1.mp3", string(content))
+	assert.NoFileExists(t, filepath.Join(out, manifest.Assets[1].File))
+
+	_, err = bm.Assets(context.Background(), "manual_backup_2.sql")
+	assert.ErrorIs(t, err, ErrBackupNotFound)
+}
+
+func TestExtractEncryptedAssets(t *testing.T) {
+	dir := t.TempDir()
+	bm := newEncryptingManager(t, dir, oldTestKey, "2024")
+	manifest := writeTestAssets(t, bm, "manual_backup_1.sql", true)
+	writeTestBackup(t, bm, "manual_backup_1.sql")
+	require.True(t, manifest.Encrypted)
+
+	archive, err := os.ReadFile(filepath.Join(dir, manifest.Archive))
+	require.NoError(t, err)
+	assert.NotContains(t, string(archive), "audio of")
+
+	out := t.TempDir()
+	_, err = bm.ExtractAssets(context.Background(), "manual_backup_1.sql", out)
+	require.NoError(t, err)
+	content, err := os.ReadFile(filepath.Join(out, manifest.Assets[0].File))
+	require.NoError(t, err)
+	assert.Equal(t, "audio of /audio.mp3", string(content))
+}
@@ -5,15 +5,16 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"regexp"
 	"strings"
 	"time"
 
+	"github.com/toeic-app/internal/buildinfo"
 	"github.com/toeic-app/internal/config"
 	"github.com/toeic-app/internal/logger"
 	"github.com/toeic-app/internal/notification"
@@ -25,6 +26,13 @@ type BackupManager struct {
 	config   config.BackupConfig
 	dbConfig config.Config
 	notifier *notification.NotificationManager
+	storage  Storage             // Where backups are kept once written to BackupDir
+	keyring  *Keyring            // Master keys of encrypted backups; nil without keys
+	catalog  Catalog             // Records the backups; nil lists them from storage
+	tools    *util.DatabaseTools // Runs pg_dump, psql and pg_restore
+	// assetSigner signs the URLs of private media assets to back them up;
+	// nil skips them
+	assetSigner AssetSigner
 }
 
 // BackupMetadata holds information about a backup
@@ -35,11 +43,31 @@ type BackupMetadata struct {
 	Description  string    `json:"description"`
 	Compressed   bool      `json:"compressed"`
 	Encrypted    bool      `json:"encrypted"`
+	KeyID        string    `json:"key_id,omitempty"` // Master key wrapping the data key of an encrypted backup
 	Validated    bool      `json:"validated"`
 	Checksum     string    `json:"checksum"`
 	DatabaseName string    `json:"database_name"`
 	Version      string    `json:"version"`
-	Type         string    `json:"type"` // manual, automatic, migration
+	AppVersion   string    `json:"app_version,omitempty"` // Version of the server that made the backup
+	DBVersion    string    `json:"db_version,omitempty"`  // PostgreSQL version the backup was taken from
+	Type         string    `json:"type"`                  // manual, automatic, migration
+	Format       string    `json:"format,omitempty"`      // plain, custom or directory; empty is plain
+
+	// Backup chain; see the Mode constants
+	Mode          string   `json:"mode,omitempty"`           // full, incremental or differential; empty is full
+	Parent        string   `json:"parent,omitempty"`         // Backup an incremental or differential backup builds on
+	Base          string   `json:"base,omitempty"`           // Full backup the chain starts with
+	Tables        []string `json:"tables,omitempty"`         // Tables an incremental or differential backup holds
+	WALPosition   string   `json:"wal_position,omitempty"`   // WAL position when the backup started
+	SchemaVersion string   `json:"schema_version,omitempty"` // Migration applied when the backup started
+	// MigrationTarget is the schema version a migration backup was taken
+	// before migrating to
+	MigrationTarget string           `json:"migration_target,omitempty"`
+	TableWrites     map[string]int64 `json:"table_writes,omitempty"` // Rows written per table when the backup started
+	// Assets is the number of media assets the database referred to, listed
+	// in the asset manifest; AssetsDownloaded of them are in the asset archive
+	Assets           int `json:"assets,omitempty"`
+	AssetsDownloaded int `json:"assets_downloaded,omitempty"`
 }
 
 // BackupResult contains the result of a backup operation
@@ -56,36 +84,195 @@ type BackupResult struct {
 type RestoreResult struct {
 	Success      bool          `json:"success"`
 	Error        string        `json:"error,omitempty"`
-	Duration     time.Duration `json:"duration"`
+	Duration     time.Duration `json:"duration" swaggertype:"integer"`
 	TablesCount  int           `json:"tables_count"`
 	RecordsCount int64         `json:"records_count"`
 	Warnings     []string      `json:"warnings,omitempty"`
+	Chain        []string      `json:"chain,omitempty"`  // Backups replayed, full backup first
+	Tables       []string      `json:"tables,omitempty"` // Tables restored by a selective restore
 }
 
 // NewBackupManager creates a new backup manager
 func NewBackupManager(backupConfig config.BackupConfig, dbConfig config.Config) *BackupManager {
+	tools := util.NewDatabaseTools(backupConfig.Tools, backupConfig.BackupDir)
+	if tools.Containerized() {
+		// Containers see the backup directory at its absolute path
+		if dir, err := filepath.Abs(backupConfig.BackupDir); err == nil {
+			backupConfig.BackupDir = dir
+		}
+	}
+
 	notifier := notification.NewNotificationManager(backupConfig)
 
+	storage, err := NewStorage(backupConfig)
+	if err != nil {
+		logger.Error("Failed to set up %s backup storage, keeping backups locally: %v", backupConfig.StorageType, err)
+		storage = NewLocalStorage(backupConfig.BackupDir)
+	}
+
+	keyring, err := NewKeyring(backupConfig)
+	if err != nil {
+		// Encrypting backups fails until the keys are fixed
+		logger.Error("Invalid backup encryption keys: %v", err)
+	}
+
 	return &BackupManager{
 		config:   backupConfig,
 		dbConfig: dbConfig,
 		notifier: notifier,
+		storage:  storage,
+		keyring:  keyring,
+		tools:    tools,
 	}
 }
 
-// CreateBackup creates a new database backup with enhanced features
+// Tools returns how pg_dump, psql and pg_restore are run
+func (bm *BackupManager) Tools() *util.DatabaseTools {
+	return bm.tools
+}
+
+// CheckTools checks that pg_dump, psql and pg_restore work and are not
+// older than the server. The server is left out when psql cannot reach it.
+func (bm *BackupManager) CheckTools(ctx context.Context) ([]util.ToolStatus, error) {
+	var serverVersion string
+	rows, err := bm.query(ctx, "SHOW server_version")
+	if err != nil {
+		logger.Warn("Failed to read the server version: %v", err)
+	} else if len(rows) == 1 && len(rows[0]) == 1 {
+		serverVersion = rows[0][0]
+	}
+	return bm.tools.Status(ctx, serverVersion)
+}
+
+// Storage returns where backups are kept
+func (bm *BackupManager) Storage() Storage {
+	return bm.storage
+}
+
+// isRemote reports whether backups are kept outside BackupDir
+func (bm *BackupManager) isRemote() bool {
+	return bm.storage.Type() != "local"
+}
+
+// CreateBackup creates a new full database backup with enhanced features
 func (bm *BackupManager) CreateBackup(ctx context.Context, description, backupType string) (*BackupResult, error) {
+	return bm.CreateBackupWithMode(ctx, description, backupType, ModeFull)
+}
+
+// CreateBackupWithMode creates a full, incremental or differential backup.
+// Without a backup to build on, or after a schema change, a full backup is
+// created instead.
+func (bm *BackupManager) CreateBackupWithMode(ctx context.Context, description, backupType, mode string) (*BackupResult, error) {
+	start := time.Now()
+	result, err := bm.createBackup(ctx, description, backupType, mode)
+	bm.recordBackup(ctx, result, time.Since(start), err)
+	if err != nil {
+		filename := ""
+		if result.Metadata != nil {
+			filename = result.Metadata.Filename
+		}
+		bm.notifier.NotifyBackupFailure(filename, err)
+		return result, err
+	}
+
+	// Cleanup old backups, once the new one is cataloged
+	go bm.cleanupOldBackups()
+
+	bm.notifier.NotifyBackupSuccess(&notification.BackupMetadata{
+		Filename:     result.Metadata.Filename,
+		Size:         result.Metadata.Size,
+		CreatedAt:    result.Metadata.CreatedAt,
+		Description:  result.Metadata.Description,
+		Compressed:   result.Metadata.Compressed,
+		Encrypted:    result.Metadata.Encrypted,
+		DatabaseName: result.Metadata.DatabaseName,
+		Type:         result.Metadata.Type,
+	}, result.Duration)
+	return result, nil
+}
+
+func (bm *BackupManager) createBackup(ctx context.Context, description, backupType, mode string) (*BackupResult, error) {
 	startTime := time.Now()
 
-	logger.Info("Starting enhanced backup creation: type=%s, description=%s", backupType, description)
+	logger.Info("Starting enhanced backup creation: type=%s, mode=%s, description=%s", backupType, mode, description)
 
 	result := &BackupResult{
 		Success: false,
 	}
+	if !ValidMode(mode) {
+		result.Error = fmt.Sprintf("invalid backup mode %q", mode)
+		return result, ErrInvalidMode
+	}
+
+	// Record the database state so later backups can tell what changed
+	state, err := bm.captureState(ctx)
+	if err != nil {
+		logger.Warn("Failed to read database state for the backup chain: %v", err)
+		if mode != ModeFull {
+			result.Error = fmt.Sprintf("failed to read database state: %v", err)
+			return result, err
+		}
+		result.Warnings = append(result.Warnings, "Database state not recorded; this backup cannot be built on")
+	} else if err := bm.tools.CheckToolVersionFor(ctx, util.ToolPgDump, state.ServerVersion); err != nil {
+		// pg_dump refuses to dump newer servers
+		result.Error = err.Error()
+		return result, err
+	}
+
+	var parent *BackupMetadata
+	var tables []string
+	if mode != ModeFull {
+		parent, err = bm.findParent(ctx, mode)
+		if err != nil {
+			result.Error = fmt.Sprintf("failed to find parent backup: %v", err)
+			return result, err
+		}
+		switch {
+		case parent == nil:
+			result.Warnings = append(result.Warnings, "No backup to build on; created a full backup")
+			mode = ModeFull
+		case parent.SchemaVersion != state.SchemaVersion:
+			result.Warnings = append(result.Warnings, fmt.Sprintf("Schema changed since %s; created a full backup", parent.Filename))
+			mode, parent = ModeFull, nil
+		case parent.WALPosition == state.WALPosition:
+			logger.Info("Nothing written since %s", parent.Filename)
+		default:
+			tables = changedTables(parent.TableWrites, state.TableWrites)
+			if reason := fullBackupReason(parent, state, tables); reason != "" {
+				result.Warnings = append(result.Warnings, reason+"; created a full backup")
+				mode, parent, tables = ModeFull, nil, nil
+			}
+		}
+	}
+
+	// Only full backups are dumped as archives
+	format := FormatPlain
+	if mode == ModeFull {
+		format = bm.dumpFormat()
+	}
 
 	// Generate filename with timestamp
 	timestamp := time.Now().Format("20060102_150405")
-	baseFilename := fmt.Sprintf("%s_backup_%s.sql", backupType, timestamp)
+	baseFilename := fmt.Sprintf("%s_backup_%s%s", backupType, timestamp, dumpExtension(format))
+	if mode != ModeFull {
+		baseFilename = fmt.Sprintf("%s_%s_backup_%s%s", backupType, mode, timestamp, dumpExtension(format))
+	}
+
+	// Failures from here on are reported and cataloged under this name
+	result.Metadata = &BackupMetadata{
+		Filename:     baseFilename,
+		CreatedAt:    time.Now(),
+		Description:  description,
+		DatabaseName: bm.dbConfig.DBName,
+		AppVersion:   buildinfo.Version,
+		Type:         backupType,
+		Mode:         mode,
+		Format:       format,
+	}
+	if state != nil {
+		result.Metadata.SchemaVersion = state.SchemaVersion
+		result.Metadata.DBVersion = state.ServerVersion
+	}
 
 	// Create backup directory if needed
 	if err := os.MkdirAll(bm.config.BackupDir, 0755); err != nil {
@@ -107,7 +294,14 @@ func (bm *BackupManager) CreateBackup(ctx context.Context, description, backupTy
 			time.Sleep(bm.config.RetryWait * time.Duration(attempt))
 		}
 
-		backupErr = bm.executeBackup(ctx, tempPath)
+		switch {
+		case format != FormatPlain:
+			backupErr = bm.executeArchiveBackup(ctx, tempPath, format)
+		case mode == ModeFull:
+			backupErr = bm.executeBackup(ctx, tempPath)
+		default:
+			backupErr = bm.executeIncrementalBackup(ctx, tempPath, tables, state.Sequences)
+		}
 		if backupErr == nil {
 			break
 		}
@@ -117,9 +311,6 @@ func (bm *BackupManager) CreateBackup(ctx context.Context, description, backupTy
 
 	if backupErr != nil {
 		result.Error = fmt.Sprintf("backup failed after %d attempts: %v", bm.config.MaxRetries, backupErr)
-		if bm.config.NotifyOnFailure {
-			bm.notifier.NotifyBackupFailure("", backupErr)
-		}
 		return result, backupErr
 	}
 
@@ -138,6 +329,7 @@ func (bm *BackupManager) CreateBackup(ctx context.Context, description, backupTy
 			result.Error = fmt.Sprintf("backup validation failed: %v", err)
 			result.Warnings = append(result.Warnings, "Backup validation failed")
 			logger.Warn("Backup validation failed: %v", err)
+			bm.notifier.NotifyValidationIssues(baseFilename, []string{err.Error()})
 		} else {
 			logger.Info("Backup validation successful")
 		}
@@ -150,7 +342,7 @@ func (bm *BackupManager) CreateBackup(ctx context.Context, description, backupTy
 	}
 
 	// Process backup (compress, encrypt if configured)
-	finalPath, processed, err := bm.processBackup(tempPath, backupPath)
+	finalPath, processed, err := bm.processBackup(ctx, tempPath, backupPath, format)
 	if err != nil {
 		result.Error = fmt.Sprintf("failed to process backup: %v", err)
 		return result, err
@@ -171,11 +363,46 @@ func (bm *BackupManager) CreateBackup(ctx context.Context, description, backupTy
 		Description:  description,
 		Compressed:   processed.Compressed,
 		Encrypted:    processed.Encrypted,
+		KeyID:        processed.KeyID,
 		Validated:    bm.config.ValidateAfterBackup,
 		Checksum:     checksum,
 		DatabaseName: bm.dbConfig.DBName,
-		Version:      "1.0", // Could be dynamic based on schema version
+		Version:      "1.0",
+		AppVersion:   buildinfo.Version,
 		Type:         backupType,
+		Mode:         mode,
+		Format:       format,
+	}
+	if backupType == TypeMigration {
+		metadata.MigrationTarget = migrationTarget(ctx)
+	}
+	if state != nil {
+		metadata.WALPosition = state.WALPosition
+		metadata.SchemaVersion = state.SchemaVersion
+		metadata.DBVersion = state.ServerVersion
+		metadata.TableWrites = state.TableWrites
+	}
+	if parent != nil {
+		metadata.Parent = parent.Filename
+		metadata.Base = parent.Base
+		if parent.BackupMode() == ModeFull {
+			metadata.Base = parent.Filename
+		}
+		metadata.Tables = tables
+	}
+
+	// List, and download, the media assets the database refers to
+	if bm.config.Assets && mode == ModeFull {
+		assets, err := bm.backupAssets(ctx, metadata.Filename, processed.Encrypted)
+		if err != nil {
+			logger.Warn("Failed to back up media assets: %v", err)
+			result.Warnings = append(result.Warnings, "Failed to back up media assets")
+		} else {
+			metadata.Assets, metadata.AssetsDownloaded = len(assets.Assets), assets.Downloaded
+			if assets.Failed > 0 {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("%d media assets could not be downloaded", assets.Failed))
+			}
+		}
 	}
 
 	// Save metadata
@@ -183,6 +410,20 @@ func (bm *BackupManager) CreateBackup(ctx context.Context, description, backupTy
 		logger.Warn("Failed to save backup metadata: %v", err)
 		result.Warnings = append(result.Warnings, "Failed to save metadata")
 	}
+	if err := bm.writeManifest(metadata.Filename); err != nil {
+		logger.Warn("Failed to write backup manifest: %v", err)
+		result.Warnings = append(result.Warnings, "Failed to write manifest")
+	}
+
+	// Hand the backup to remote storage
+	if bm.isRemote() {
+		if err := bm.uploadBackup(ctx, metadata.Filename); err != nil {
+			result.Error = fmt.Sprintf("failed to upload backup to %s storage: %v", bm.storage.Type(), err)
+			result.Metadata = metadata
+			return result, err
+		}
+		logger.Info("Backup uploaded to %s storage: %s", bm.storage.Type(), metadata.Filename)
+	}
 
 	result.Success = true
 	result.Metadata = metadata
@@ -190,30 +431,35 @@ func (bm *BackupManager) CreateBackup(ctx context.Context, description, backupTy
 
 	logger.Info("Backup created successfully: %s (size: %d bytes, duration: %v)",
 		metadata.Filename, metadata.Size, result.Duration)
-	// Send success notification
-	if bm.config.NotifyOnSuccess {
-		notificationMetadata := &notification.BackupMetadata{
-			Filename:     metadata.Filename,
-			Size:         metadata.Size,
-			CreatedAt:    metadata.CreatedAt,
-			Description:  metadata.Description,
-			Compressed:   metadata.Compressed,
-			Encrypted:    metadata.Encrypted,
-			DatabaseName: metadata.DatabaseName,
-			Type:         metadata.Type,
-		}
-		bm.notifier.NotifyBackupSuccess(notificationMetadata)
-	}
-
-	// Cleanup old backups
-	go bm.cleanupOldBackups()
 
 	return result, nil
 }
 
-// RestoreBackup restores database from backup with enhanced validation
-func (bm *BackupManager) RestoreBackup(ctx context.Context, filename string) (*RestoreResult, error) {
+// RestoreBackup restores database from backup with enhanced validation.
+// With options.Tables only those tables are restored.
+func (bm *BackupManager) RestoreBackup(ctx context.Context, filename string, options RestoreOptions) (*RestoreResult, error) {
+	result, err := bm.restoreBackup(ctx, filename, options)
+	if err != nil {
+		bm.notifier.NotifyRestoreFailure(filename, err)
+		return result, err
+	}
+	if bm.catalog != nil && options.restoresTable(catalogTable) {
+		// The catalog was restored along with the rest of the database, as
+		// it was when the backup was made
+		if _, err := bm.ReconcileCatalog(ctx, false); err != nil {
+			logger.Warn("Failed to reconcile the backup catalog after restoring %s: %v", filename, err)
+			result.Warnings = append(result.Warnings, "Backup catalog not reconciled with storage")
+		}
+	}
+	progressTracker(ctx).phase(PhaseFinished, "")
+	bm.notifier.NotifyRestoreSuccess(filename, result.Duration, result.Warnings)
+	return result, nil
+}
+
+func (bm *BackupManager) restoreBackup(ctx context.Context, filename string, options RestoreOptions) (*RestoreResult, error) {
 	startTime := time.Now()
+	tracker := progressTracker(ctx)
+	tracker.phase(PhasePreparing, "")
 
 	logger.Info("Starting enhanced database restore from: %s", filename)
 
@@ -226,44 +472,47 @@ func (bm *BackupManager) RestoreBackup(ctx context.Context, filename string) (*R
 		return result, fmt.Errorf("%s", result.Error)
 	}
 
-	backupPath := filepath.Join(bm.config.BackupDir, filename)
-	// Check if backup file exists
-	if _, err := os.Stat(backupPath); os.IsNotExist(err) {
-		result.Error = "backup file not found"
-		return result, fmt.Errorf("%s", result.Error)
-	}
-
-	// Load and validate metadata
-	metadata, err := bm.loadBackupMetadata(filename)
+	// Incremental and differential backups are replayed on top of the
+	// backups they build on, starting with the full backup
+	chain, err := bm.RestoreChain(ctx, filename)
 	if err != nil {
-		logger.Warn("Failed to load backup metadata: %v", err)
-		result.Warnings = append(result.Warnings, "Metadata not available")
+		result.Error = err.Error()
+		return result, err
 	}
-
-	// Pre-restore validation if enabled
-	if bm.config.ValidateBeforeRestore {
-		if err := bm.validateBackup(backupPath); err != nil {
-			result.Error = fmt.Sprintf("pre-restore validation failed: %v", err)
-			return result, fmt.Errorf("%s", result.Error)
+	result.Chain = chain
+
+	sqlPaths := make([]string, 0, len(chain))
+	for _, name := range chain {
+		tracker.phase(PhasePreparing, name)
+		sqlPath, downloaded, err := bm.prepareRestore(ctx, name, result)
+		if downloaded && !bm.config.KeepLocalCopy {
+			defer bm.removeLocal(name)
 		}
-		logger.Info("Pre-restore validation successful")
+		if err != nil {
+			return result, err
+		}
+		if sqlPath != filepath.Join(bm.config.BackupDir, name) {
+			defer os.Remove(sqlPath)
+		}
+		sqlPaths = append(sqlPaths, sqlPath)
 	}
 
-	// Verify checksum if available
-	if metadata != nil && metadata.Checksum != "" {
-		currentChecksum, err := bm.calculateChecksum(backupPath)
-		if err != nil {
-			logger.Warn("Failed to verify backup checksum: %v", err)
-			result.Warnings = append(result.Warnings, "Checksum verification failed")
-		} else if currentChecksum != metadata.Checksum {
-			result.Error = "backup file checksum mismatch - file may be corrupted"
-			return result, fmt.Errorf("%s", result.Error)
-		} else {
-			logger.Info("Backup checksum verified successfully")
+	// Check the tables of a selective restore before changing anything
+	var tables []string
+	if len(options.Tables) > 0 {
+		if tables, result.RecordsCount, err = bm.tableRestoreChain(ctx, sqlPaths, options.Tables); err != nil {
+			result.Error = err.Error()
+			return result, err
 		}
+		result.Tables = tables
+		result.TablesCount = len(tables)
+		tracker.expect(len(tables), result.RecordsCount, nil)
+	} else if err := bm.expectRestore(ctx, tracker, sqlPaths); err != nil {
+		logger.Warn("Failed to count the tables of %s: %v", filename, err)
 	}
 
 	// Create database backup before restore (safety measure)
+	tracker.phase(PhaseSafetyBackup, "")
 	safetyBackupResult, err := bm.CreateBackup(ctx, "Pre-restore safety backup", "safety")
 	if err != nil {
 		logger.Warn("Failed to create safety backup: %v", err)
@@ -271,28 +520,36 @@ func (bm *BackupManager) RestoreBackup(ctx context.Context, filename string) (*R
 	} else {
 		logger.Info("Safety backup created: %s", safetyBackupResult.Metadata.Filename)
 	}
-	// Process backup file (decompress, decrypt if needed)
-	tempPath, err := bm.preprocessBackup(backupPath)
-	if err != nil {
-		result.Error = fmt.Sprintf("failed to preprocess backup: %v", err)
-		return result, fmt.Errorf("%s", result.Error)
+
+	// Nothing was changed yet when the restore was cancelled meanwhile
+	if err := ctx.Err(); err != nil {
+		result.Error = fmt.Sprintf("restore stopped before changing the database: %v", err)
+		return result, err
 	}
-	defer os.Remove(tempPath) // Cleanup temp file
 
-	// Execute restore with retries
-	var restoreErr error
-	for attempt := 1; attempt <= bm.config.MaxRetries; attempt++ {
-		if attempt > 1 {
-			logger.Info("Restore attempt %d/%d", attempt, bm.config.MaxRetries)
-			time.Sleep(bm.config.RetryWait * time.Duration(attempt))
+	if tables != nil {
+		// The tables are replaced in one transaction, so a failure leaves
+		// the database as it was
+		if err := bm.restoreTables(ctx, chain, sqlPaths, tables, result); err != nil {
+			result.Error = err.Error()
+			return result, err
 		}
+		result.Success = true
+		result.Duration = time.Since(startTime)
+		logger.Info("Restored %d tables from: %s (duration: %v)", len(tables), filename, result.Duration)
+		return result, nil
+	}
 
-		restoreErr = bm.executeRestore(ctx, tempPath)
-		if restoreErr == nil {
+	var restoreErr error
+	for i, sqlPath := range sqlPaths {
+		if len(sqlPaths) > 1 {
+			logger.Info("Restoring backup %d/%d of the chain: %s", i+1, len(sqlPaths), chain[i])
+		}
+		tracker.phase(PhaseRestoring, chain[i])
+		if restoreErr = bm.restoreFile(ctx, sqlPath); restoreErr != nil {
+			restoreErr = fmt.Errorf("%s: %w", chain[i], restoreErr)
 			break
 		}
-
-		logger.Warn("Restore attempt %d failed: %v", attempt, restoreErr)
 	}
 
 	if restoreErr != nil {
@@ -301,7 +558,24 @@ func (bm *BackupManager) RestoreBackup(ctx context.Context, filename string) (*R
 		// Attempt to restore from safety backup if available
 		if safetyBackupResult != nil && safetyBackupResult.Success {
 			logger.Info("Attempting to restore from safety backup...")
-			if safetyErr := bm.executeRestore(ctx, filepath.Join(bm.config.BackupDir, safetyBackupResult.Metadata.Filename)); safetyErr != nil {
+			tracker.phase(PhaseRollingBack, safetyBackupResult.Metadata.Filename)
+			// The database is rolled back even when the restore was
+			// cancelled or timed out
+			rollbackCtx := context.WithoutCancel(ctx)
+			safetyPath, downloaded, safetyErr := bm.localCopy(rollbackCtx, safetyBackupResult.Metadata.Filename)
+			if safetyErr == nil {
+				if downloaded && !bm.config.KeepLocalCopy {
+					defer bm.removeLocal(safetyBackupResult.Metadata.Filename)
+				}
+				var sqlPath string
+				if sqlPath, safetyErr = bm.preprocessBackup(rollbackCtx, safetyPath); safetyErr == nil {
+					if sqlPath != safetyPath {
+						defer os.Remove(sqlPath)
+					}
+					safetyErr = bm.executeRestore(rollbackCtx, sqlPath)
+				}
+			}
+			if safetyErr != nil {
 				logger.Error("Failed to restore from safety backup: %v", safetyErr)
 				result.Warnings = append(result.Warnings, "Safety backup restore also failed")
 			} else {
@@ -313,6 +587,7 @@ func (bm *BackupManager) RestoreBackup(ctx context.Context, filename string) (*R
 	}
 
 	// Post-restore validation
+	tracker.phase(PhaseValidating, "")
 	if err := bm.validateDatabaseIntegrity(); err != nil {
 		logger.Warn("Post-restore database validation failed: %v", err)
 		result.Warnings = append(result.Warnings, "Post-restore validation failed")
@@ -323,20 +598,109 @@ func (bm *BackupManager) RestoreBackup(ctx context.Context, filename string) (*R
 
 	logger.Info("Database restored successfully from: %s (duration: %v)", filename, result.Duration)
 
-	// Send notification
-	bm.notifier.NotifyRestoreSuccess(filename, result.Duration)
-
 	return result, nil
 }
 
-// executeBackup performs the actual pg_dump operation
-func (bm *BackupManager) executeBackup(ctx context.Context, outputPath string) error {
-	// Get pg_dump command
-	pgDumpCmd, err := util.GetPgDumpCommand()
+// prepareRestore fetches a backup of a restore chain, checks it against its
+// manifest, decrypts and decompresses it and validates the SQL. It returns the path of the SQL,
+// which is a temporary file unless the backup is plain SQL, and whether the
+// backup was downloaded from remote storage.
+func (bm *BackupManager) prepareRestore(ctx context.Context, filename string, result *RestoreResult) (string, bool, error) {
+	// Pull the backup from remote storage when there is no local copy
+	backupPath, downloaded, err := bm.localCopy(ctx, filename)
+	if errors.Is(err, ErrBackupNotFound) {
+		result.Error = fmt.Sprintf("backup file not found: %s", filename)
+		return "", false, fmt.Errorf("%s", result.Error)
+	}
 	if err != nil {
-		return fmt.Errorf("pg_dump command not found: %w", err)
+		result.Error = fmt.Sprintf("failed to fetch backup: %v", err)
+		return "", false, err
+	}
+
+	// The backup is checked as stored before it is read
+	signed, err := bm.checkManifest(backupPath)
+	switch {
+	case errors.Is(err, ErrManifestNotFound):
+		result.Warnings = append(result.Warnings, fmt.Sprintf("%s has no manifest", filename))
+	case err != nil:
+		result.Error = fmt.Sprintf("manifest verification failed: %v", err)
+		return "", downloaded, fmt.Errorf("%s: %w", filename, err)
+	case !signed && bm.config.ManifestKey != "":
+		result.Warnings = append(result.Warnings, fmt.Sprintf("Manifest of %s is not signed", filename))
+	}
+
+	// Load and validate metadata
+	metadata, err := bm.loadBackupMetadata(filename)
+	if err != nil {
+		logger.Warn("Failed to load backup metadata: %v", err)
+		result.Warnings = append(result.Warnings, "Metadata not available")
+	}
+
+	sqlPath, err := bm.preprocessBackup(ctx, backupPath)
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to preprocess backup: %v", err)
+		return "", downloaded, fmt.Errorf("%s: %w", filename, err)
+	}
+	fail := func(message string) (string, bool, error) {
+		if sqlPath != backupPath {
+			os.Remove(sqlPath)
+		}
+		result.Error = message
+		return "", downloaded, fmt.Errorf("%s", message)
+	}
+
+	// Pre-restore validation if enabled
+	if bm.config.ValidateBeforeRestore {
+		if err := bm.validateBackup(sqlPath); err != nil {
+			return fail(fmt.Sprintf("pre-restore validation failed: %v", err))
+		}
+		logger.Info("Pre-restore validation successful")
+	}
+
+	// The checksum is taken of the SQL before compression and encryption
+	if metadata != nil && metadata.Checksum != "" {
+		currentChecksum, err := bm.calculateChecksum(sqlPath)
+		if err != nil {
+			logger.Warn("Failed to verify backup checksum: %v", err)
+			result.Warnings = append(result.Warnings, "Checksum verification failed")
+		} else if currentChecksum != metadata.Checksum {
+			return fail("backup file checksum mismatch - file may be corrupted")
+		} else {
+			logger.Info("Backup checksum verified successfully")
+		}
+	}
+	return sqlPath, downloaded, nil
+}
+
+// restoreFile restores one SQL file with retries
+func (bm *BackupManager) restoreFile(ctx context.Context, sqlPath string) error {
+	tracker := progressTracker(ctx)
+	tables, rows := tracker.restored()
+	var restoreErr error
+	for attempt := 1; attempt <= bm.config.MaxRetries; attempt++ {
+		if attempt > 1 {
+			logger.Info("Restore attempt %d/%d", attempt, bm.config.MaxRetries)
+			time.Sleep(bm.config.RetryWait * time.Duration(attempt))
+			// The file is loaded again from the start
+			tracker.rewind(tables, rows)
+		}
+
+		restoreErr = bm.executeRestore(ctx, sqlPath)
+		if restoreErr == nil {
+			break
+		}
+
+		logger.Warn("Restore attempt %d failed: %v", attempt, restoreErr)
+		if ctx.Err() != nil {
+			// Cancelled or timed out, so another attempt fails too
+			break
+		}
 	}
+	return restoreErr
+}
 
+// executeBackup performs the actual pg_dump operation
+func (bm *BackupManager) executeBackup(ctx context.Context, outputPath string) error {
 	// Build command arguments
 	args := []string{
 		"--host=" + bm.dbConfig.DBHost,
@@ -349,12 +713,19 @@ func (bm *BackupManager) executeBackup(ctx context.Context, outputPath string) e
 		"--no-owner",
 		"--no-privileges",
 		"--verbose",
-		"--file=" + outputPath,
-		bm.dbConfig.DBName,
 	}
+	// Throttled dumps are written to the file by dumpThrottled
+	throttled := bm.ioLimiter(ctx).throttles()
+	if !throttled {
+		args = append(args, "--file="+outputPath)
+	}
+	args = append(args, bm.dbConfig.DBName)
 
 	// Create command with context for cancellation
-	cmd := exec.CommandContext(ctx, pgDumpCmd, args...)
+	cmd, err := bm.tools.Command(ctx, util.ToolPgDump, args...)
+	if err != nil {
+		return fmt.Errorf("pg_dump command not found: %w", err)
+	}
 
 	// Set environment
 	env := os.Environ()
@@ -363,7 +734,12 @@ func (bm *BackupManager) executeBackup(ctx context.Context, outputPath string) e
 	cmd.Env = env
 
 	// Execute command
-	output, err := cmd.CombinedOutput()
+	var output []byte
+	if throttled {
+		output, err = bm.dumpThrottled(ctx, cmd, outputPath)
+	} else {
+		output, err = cmd.CombinedOutput()
+	}
 	if err != nil {
 		return fmt.Errorf("pg_dump failed: %w, output: %s", err, string(output))
 	}
@@ -371,12 +747,27 @@ func (bm *BackupManager) executeBackup(ctx context.Context, outputPath string) e
 	return nil
 }
 
-// executeRestore performs the actual psql restore operation
+// executeRestore restores a backup with psql, or pg_restore for archives
 func (bm *BackupManager) executeRestore(ctx context.Context, inputPath string) error {
-	// Get psql command
-	psqlCmd, err := util.GetPsqlCommand()
+	_, err := bm.restoreInto(ctx, bm.dbConfig.DBName, inputPath)
+	return err
+}
+
+// restoreInto runs a SQL file on a database of the server with psql and
+// returns its output. psql goes on after failing statements, which are
+// reported in the output. Archives are restored with pg_restore.
+func (bm *BackupManager) restoreInto(ctx context.Context, dbName, inputPath string) (string, error) {
+	format, err := sniffDumpFormat(inputPath)
 	if err != nil {
-		return fmt.Errorf("psql command not found: %w", err)
+		return "", err
+	}
+	// Only tables loaded into the live database count towards the progress
+	var tracker *restoreTracker
+	if dbName == bm.dbConfig.DBName {
+		tracker = progressTracker(ctx)
+	}
+	if format != FormatPlain {
+		return bm.restoreArchive(ctx, dbName, inputPath, format, tracker)
 	}
 
 	// Build command arguments
@@ -384,13 +775,20 @@ func (bm *BackupManager) executeRestore(ctx context.Context, inputPath string) e
 		"--host=" + bm.dbConfig.DBHost,
 		"--port=" + bm.dbConfig.DBPort,
 		"--username=" + bm.dbConfig.DBUser,
-		"--dbname=" + bm.dbConfig.DBName,
+		"--dbname=" + dbName,
 		"--set=client_encoding=UTF8",
-		"--file=" + inputPath,
+	}
+	// Throttled restores feed the file to psql through the throttle
+	throttled := bm.ioLimiter(ctx).throttles()
+	if !throttled {
+		args = append(args, "--file="+inputPath)
 	}
 
 	// Create command with context
-	cmd := exec.CommandContext(ctx, psqlCmd, args...)
+	cmd, err := bm.tools.Command(ctx, util.ToolPsql, args...)
+	if err != nil {
+		return "", fmt.Errorf("psql command not found: %w", err)
+	}
 
 	// Set environment
 	env := os.Environ()
@@ -398,22 +796,41 @@ func (bm *BackupManager) executeRestore(ctx context.Context, inputPath string) e
 	env = append(env, "PGCLIENTENCODING=UTF8")
 	cmd.Env = env
 
+	if throttled {
+		input, err := os.Open(inputPath)
+		if err != nil {
+			return "", err
+		}
+		defer input.Close()
+		cmd.Stdin = bm.throttleReader(ctx, input)
+	}
+
 	// Execute command
-	output, err := cmd.CombinedOutput()
+	output, err := combinedOutput(cmd, tracker)
 	if err != nil {
-		return fmt.Errorf("psql restore failed: %w, output: %s", err, string(output))
+		return string(output), fmt.Errorf("psql restore failed: %w, output: %s", err, string(output))
 	}
 
-	return nil
+	return string(output), nil
+}
+
+// processedBackup tells how processBackup transformed a backup
+type processedBackup struct {
+	Compressed bool
+	Encrypted  bool
+	KeyID      string // Master key wrapping the data key when encrypted
 }
 
-// processBackup handles compression and encryption
-func (bm *BackupManager) processBackup(tempPath, finalPath string) (string, struct{ Compressed, Encrypted bool }, error) {
-	result := struct{ Compressed, Encrypted bool }{false, false}
+// processBackup handles compression and encryption. Archives were
+// compressed by pg_dump and are not compressed again.
+func (bm *BackupManager) processBackup(ctx context.Context, tempPath, finalPath, format string) (string, processedBackup, error) {
+	var result processedBackup
 	currentPath := tempPath
 
 	// Compress if enabled
-	if bm.config.CompressBackups {
+	if bm.config.CompressBackups && format != FormatPlain {
+		result.Compressed = true
+	} else if bm.config.CompressBackups {
 		compressedPath := finalPath + ".gz"
 		if err := bm.compressFile(currentPath, compressedPath); err != nil {
 			return "", result, fmt.Errorf("compression failed: %w", err)
@@ -426,9 +843,12 @@ func (bm *BackupManager) processBackup(tempPath, finalPath string) (string, stru
 	// Encrypt if enabled
 	if bm.config.EncryptBackups {
 		encryptedPath := finalPath + ".enc"
-		if err := bm.encryptFile(currentPath, encryptedPath); err != nil {
+		keyID, err := bm.encryptFile(ctx, currentPath, encryptedPath)
+		if err != nil {
+			os.Remove(encryptedPath)
 			return "", result, fmt.Errorf("encryption failed: %w", err)
 		}
+		result.KeyID = keyID
 		if currentPath != tempPath {
 			os.Remove(currentPath) // Remove intermediate file
 		}
@@ -447,8 +867,6 @@ func (bm *BackupManager) processBackup(tempPath, finalPath string) (string, stru
 	return finalPath, result, nil
 }
 
-// Additional methods for metadata handling, encryption, etc. would be implemented here...
-
 // saveBackupMetadata saves backup metadata to a JSON file
 func (bm *BackupManager) saveBackupMetadata(metadata *BackupMetadata) error {
 	metadataPath := filepath.Join(bm.config.BackupDir, metadata.Filename+".meta")
@@ -482,31 +900,65 @@ func (bm *BackupManager) loadBackupMetadata(filename string) (*BackupMetadata, e
 	return &metadata, nil
 }
 
-// preprocessBackup handles decompression and decryption
-func (bm *BackupManager) preprocessBackup(backupPath string) (string, error) {
-	// For now, just return the original path
-	// In a full implementation, this would handle decompression and decryption
-	return backupPath, nil
+// preprocessBackup decrypts and decompresses a backup into a temporary
+// SQL file next to it. Plain SQL backups are returned as they are.
+func (bm *BackupManager) preprocessBackup(ctx context.Context, backupPath string) (string, error) {
+	currentPath := backupPath
+	name := filepath.Base(backupPath)
+	cleanup := func() {
+		if currentPath != backupPath {
+			os.Remove(currentPath)
+		}
+	}
+
+	if strings.HasSuffix(name, ".enc") {
+		name = strings.TrimSuffix(name, ".enc")
+		decryptedPath := filepath.Join(filepath.Dir(backupPath), name+".decrypted.tmp")
+		if err := bm.decryptFile(ctx, currentPath, decryptedPath); err != nil {
+			os.Remove(decryptedPath)
+			return "", fmt.Errorf("decryption failed: %w", err)
+		}
+		currentPath = decryptedPath
+	}
+
+	if strings.HasSuffix(name, ".gz") {
+		name = strings.TrimSuffix(name, ".gz")
+		decompressedPath := filepath.Join(filepath.Dir(backupPath), name+".restore.tmp")
+		if err := decompressFile(currentPath, decompressedPath); err != nil {
+			os.Remove(decompressedPath)
+			cleanup()
+			return "", fmt.Errorf("decompression failed: %w", err)
+		}
+		cleanup()
+		currentPath = decompressedPath
+	}
+	return currentPath, nil
 }
 
-// encryptFile encrypts a file (placeholder implementation)
-func (bm *BackupManager) encryptFile(sourcePath, destPath string) error {
-	// For now, just copy the file
-	// In a full implementation, this would use AES encryption
+// decompressFile decompresses a gzip file
+func decompressFile(sourcePath, destPath string) error {
 	source, err := os.Open(sourcePath)
 	if err != nil {
 		return err
 	}
 	defer source.Close()
 
+	reader, err := gzip.NewReader(source)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
 	dest, err := os.Create(destPath)
 	if err != nil {
 		return err
 	}
 	defer dest.Close()
 
-	_, err = io.Copy(dest, source)
-	return err
+	if _, err := io.Copy(dest, reader); err != nil {
+		return err
+	}
+	return dest.Close()
 }
 
 // validateDatabaseIntegrity performs post-restore validation
@@ -517,8 +969,126 @@ func (bm *BackupManager) validateDatabaseIntegrity() error {
 	return nil
 }
 
-// validateBackup performs basic SQL syntax validation
+// ValidationReport is the outcome of ValidateBackup
+type ValidationReport struct {
+	Filename       string    `json:"filename"`
+	Valid          bool      `json:"valid"`
+	FileExists     bool      `json:"file_exists"`
+	ReadableFile   bool      `json:"readable_file"` // Decrypted and decompressed
+	ValidStructure bool      `json:"valid_structure"`
+	ChecksumMatch  bool      `json:"checksum_match"`
+	ManifestMatch  bool      `json:"manifest_match"`  // The stored files match their manifest
+	ManifestSigned bool      `json:"manifest_signed"` // The manifest signature was verified
+	Encrypted      bool      `json:"encrypted"`
+	KeyID          string    `json:"key_id,omitempty"`
+	Size           int64     `json:"size"`
+	LastModified   time.Time `json:"last_modified"`
+	Issues         []string  `json:"issues"`
+	Warnings       []string  `json:"warnings"`
+}
+
+// ValidateBackup checks that a backup can be restored: its files are
+// checked against its manifest, it is decrypted and decompressed, its SQL
+// is checked and its checksum compared with the metadata. A backup with issues is reported to the notification channels.
+func (bm *BackupManager) ValidateBackup(ctx context.Context, filename string) (*ValidationReport, error) {
+	report, err := bm.checkBackup(ctx, filename)
+	if err == nil && !report.Valid {
+		bm.notifier.NotifyValidationIssues(filename, report.Issues)
+	}
+	return report, err
+}
+
+func (bm *BackupManager) checkBackup(ctx context.Context, filename string) (*ValidationReport, error) {
+	if !bm.isValidBackupFilename(filename) {
+		return nil, fmt.Errorf("invalid backup filename")
+	}
+	report := &ValidationReport{Filename: filename, Issues: []string{}, Warnings: []string{}}
+
+	backupPath, downloaded, err := bm.localCopy(ctx, filename)
+	if errors.Is(err, ErrBackupNotFound) {
+		return nil, ErrBackupNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if downloaded && !bm.config.KeepLocalCopy {
+		defer bm.removeLocal(filename)
+	}
+	report.FileExists = true
+	if info, err := os.Stat(backupPath); err == nil {
+		report.Size = info.Size()
+		report.LastModified = info.ModTime()
+	}
+
+	report.ManifestSigned, err = bm.checkManifest(backupPath)
+	switch {
+	case errors.Is(err, ErrManifestNotFound):
+		report.Warnings = append(report.Warnings, "No manifest recorded")
+	case err != nil:
+		report.Issues = append(report.Issues, fmt.Sprintf("Manifest verification failed: %v", err))
+	default:
+		report.ManifestMatch = true
+		if !report.ManifestSigned && bm.config.ManifestKey != "" {
+			report.Warnings = append(report.Warnings, "Manifest is not signed")
+		}
+	}
+
+	if strings.HasSuffix(filename, ".enc") {
+		report.Encrypted = true
+		if report.KeyID, err = encryptionKeyID(backupPath); err != nil {
+			report.Issues = append(report.Issues, fmt.Sprintf("Invalid encryption header: %v", err))
+		}
+	}
+
+	sqlPath, err := bm.preprocessBackup(ctx, backupPath)
+	if err != nil {
+		report.Issues = append(report.Issues, fmt.Sprintf("Backup cannot be read: %v", err))
+		return report, nil
+	}
+	if sqlPath != backupPath {
+		defer os.Remove(sqlPath)
+	}
+	report.ReadableFile = true
+
+	if err := bm.validateBackup(sqlPath); err != nil {
+		report.Issues = append(report.Issues, fmt.Sprintf("Invalid SQL: %v", err))
+	} else {
+		report.ValidStructure = true
+	}
+
+	metadata, err := bm.loadBackupMetadata(filename)
+	switch {
+	case err != nil || metadata.Checksum == "":
+		report.Warnings = append(report.Warnings, "No checksum recorded")
+	default:
+		checksum, err := bm.calculateChecksum(sqlPath)
+		if err != nil {
+			report.Issues = append(report.Issues, fmt.Sprintf("Failed to calculate checksum: %v", err))
+		} else if checksum != metadata.Checksum {
+			report.Issues = append(report.Issues, "Checksum mismatch - file may be corrupted")
+		} else {
+			report.ChecksumMatch = true
+		}
+	}
+	if report.Encrypted && bm.keyring != nil && report.KeyID != "" && report.KeyID != bm.keyring.ActiveKeyID() {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("Encrypted with %s, not the active key; run rotate-keys", report.KeyID))
+	}
+
+	report.Valid = len(report.Issues) == 0
+	return report, nil
+}
+
+// validateBackup performs basic SQL syntax validation, or checks the
+// structure of an archive
 func (bm *BackupManager) validateBackup(backupPath string) error {
+	format, err := sniffDumpFormat(backupPath)
+	if err != nil {
+		return err
+	}
+	if format != FormatPlain {
+		return validateArchive(backupPath, format)
+	}
+
 	file, err := os.Open(backupPath)
 	if err != nil {
 		return err
@@ -595,97 +1165,205 @@ func (bm *BackupManager) compressFile(sourcePath, destPath string) error {
 // isValidBackupFilename checks if filename is valid for backup operations
 func (bm *BackupManager) isValidBackupFilename(filename string) bool {
 	// Allow only alphanumeric, underscore, dash, and dot
-	validName := regexp.MustCompile(`^[a-zA-Z0-9_.-]+\.(sql|sql\.gz|sql\.enc|sql\.gz\.enc)$`)
+	validName := regexp.MustCompile(`^[a-zA-Z0-9_.-]+\.(sql|sql\.gz|dump|tar)(\.enc)?$`)
 	return validName.MatchString(filename) && !strings.Contains(filename, "..")
 }
 
-// cleanupOldBackups removes old backups based on retention policy
+// cleanupOldBackups removes the backups the retention policy does not keep
 func (bm *BackupManager) cleanupOldBackups() {
-	logger.Info("Starting backup cleanup process")
+	logger.Info("Starting backup cleanup process (keeping %s)", bm.RetentionPolicy())
 
-	files, err := os.ReadDir(bm.config.BackupDir)
+	plan, deletedCount, err := bm.EnforceRetention(context.Background())
 	if err != nil {
-		logger.Error("Failed to read backup directory: %v", err)
+		logger.Error("Failed to clean up backups: %v", err)
 		return
 	}
 
+	logger.Info("Backup cleanup completed: kept %d backups, removed %d", len(plan.Keep), deletedCount)
+}
+
+// CleanupOldBackups is a public method to clean up old backups with a specific max age
+func (bm *BackupManager) CleanupOldBackups(maxAge time.Duration) error {
+	logger.Info("Starting manual backup cleanup process (max age: %v)", maxAge)
+
+	deletedCount, err := bm.deleteOlderThan(context.Background(), maxAge)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Manual backup cleanup completed: removed %d old backups", deletedCount)
+	return nil
+}
+
+// deleteOlderThan deletes the backups in storage, and their local copies,
+// that are older than maxAge
+func (bm *BackupManager) deleteOlderThan(ctx context.Context, maxAge time.Duration) (int, error) {
+	stores := []Storage{bm.storage}
+	if bm.isRemote() {
+		stores = append(stores, NewLocalStorage(bm.config.BackupDir))
+	}
+
 	now := time.Now()
-	retentionDuration := bm.config.GetRetentionDuration()
 	deletedCount := 0
-
-	for _, file := range files {
-		if file.IsDir() {
-			continue
+	for _, store := range stores {
+		files, err := store.List(ctx)
+		if store == bm.storage && bm.catalog != nil {
+			files, err = bm.catalogedBackups(ctx)
 		}
-
-		// Check if it's a backup file
-		if !bm.isValidBackupFilename(file.Name()) {
-			continue
+		if err != nil {
+			return deletedCount, fmt.Errorf("failed to list %s backups: %w", store.Type(), err)
 		}
 
-		fileInfo, err := file.Info()
-		if err != nil {
-			logger.Warn("Failed to get file info for %s: %v", file.Name(), err)
-			continue
+		// Backups that kept incremental or differential backups build on
+		// stay until those expire too
+		needed := map[string]bool{}
+		if store == bm.storage {
+			var kept []string
+			for _, file := range files {
+				if bm.isValidBackupFilename(file.Name) && now.Sub(file.ModTime) <= maxAge {
+					kept = append(kept, file.Name)
+				}
+			}
+			needed = bm.chainDependencies(ctx, kept)
 		}
 
-		// Check age
-		if now.Sub(fileInfo.ModTime()) > retentionDuration {
-			filePath := filepath.Join(bm.config.BackupDir, file.Name())
-			if err := os.Remove(filePath); err != nil {
-				logger.Warn("Failed to delete old backup %s: %v", file.Name(), err)
+		for _, file := range files {
+			// Check if it's a backup file
+			if !bm.isValidBackupFilename(file.Name) {
 				continue
 			}
 
-			logger.Debug("Deleted old backup: %s (age: %v)", file.Name(), now.Sub(fileInfo.ModTime()))
+			// Check age
+			if now.Sub(file.ModTime) <= maxAge {
+				continue
+			}
+			if needed[file.Name] {
+				logger.Debug("Keeping old backup %s, newer backups build on it", file.Name)
+				continue
+			}
+			if err := store.Delete(ctx, file.Name); err != nil {
+				logger.Warn("Failed to delete old %s backup %s: %v", store.Type(), file.Name, err)
+				continue
+			}
+			if store == bm.storage {
+				bm.setStatus(ctx, file.Name, StatusDeleted)
+			}
+			deleteSidecars(ctx, store, file.Name)
+
+			logger.Debug("Deleted old %s backup: %s (age: %v)", store.Type(), file.Name, now.Sub(file.ModTime))
 			deletedCount++
 		}
 	}
-
-	logger.Info("Backup cleanup completed: removed %d old backups", deletedCount)
+	return deletedCount, nil
 }
 
-// CleanupOldBackups is a public method to clean up old backups with a specific max age
-func (bm *BackupManager) CleanupOldBackups(maxAge time.Duration) error {
-	logger.Info("Starting manual backup cleanup process (max age: %v)", maxAge)
-
-	files, err := os.ReadDir(bm.config.BackupDir)
+// ListBackups returns the backups in storage, newest first. With a catalog
+// the completed backups it records are returned without reading the
+// storage.
+func (bm *BackupManager) ListBackups(ctx context.Context) ([]StoredFile, error) {
+	if bm.catalog != nil {
+		return bm.catalogedBackups(ctx)
+	}
+	files, err := bm.storage.List(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to read backup directory: %w", err)
+		return nil, err
 	}
 
-	now := time.Now()
-	deletedCount := 0
-
+	backups := make([]StoredFile, 0, len(files))
 	for _, file := range files {
-		if file.IsDir() {
-			continue
+		if bm.isValidBackupFilename(file.Name) {
+			backups = append(backups, file)
 		}
+	}
+	sortNewestFirst(backups)
+	return backups, nil
+}
 
-		// Check if it's a backup file
-		if !bm.isValidBackupFilename(file.Name()) {
-			continue
+// DeleteBackup deletes a backup from storage together with its metadata,
+// manifest and local copy. Protected backups are not deleted.
+func (bm *BackupManager) DeleteBackup(ctx context.Context, filename string) error {
+	if !bm.isValidBackupFilename(filename) {
+		return fmt.Errorf("invalid backup filename")
+	}
+	if protected, err := bm.IsProtected(ctx, filename); err != nil {
+		return err
+	} else if protected {
+		return ErrBackupProtected
+	}
+	if err := bm.storage.Delete(ctx, filename); err != nil {
+		if errors.Is(err, ErrBackupNotFound) {
+			bm.setStatus(ctx, filename, StatusMissing)
 		}
+		return err
+	}
+	bm.setStatus(ctx, filename, StatusDeleted)
+	deleteSidecars(ctx, bm.storage, filename)
+	if bm.isRemote() {
+		bm.removeLocal(filename)
+	}
+	return nil
+}
 
-		fileInfo, err := file.Info()
-		if err != nil {
-			logger.Warn("Failed to get file info for %s: %v", file.Name(), err)
-			continue
-		}
+// LocalCopy returns the path of a backup in BackupDir, downloading it from
+// remote storage when there is no local copy
+func (bm *BackupManager) LocalCopy(ctx context.Context, filename string) (string, error) {
+	if !bm.isValidBackupFilename(filename) {
+		return "", fmt.Errorf("invalid backup filename")
+	}
+	path, _, err := bm.localCopy(ctx, filename)
+	return path, err
+}
 
-		// Check age
-		if now.Sub(fileInfo.ModTime()) > maxAge {
-			filePath := filepath.Join(bm.config.BackupDir, file.Name())
-			if err := os.Remove(filePath); err != nil {
-				logger.Warn("Failed to delete old backup %s: %v", file.Name(), err)
-				continue
-			}
+// localCopy returns the local path of a backup and whether it had to be
+// downloaded. Metadata and manifest are downloaded along with the backup
+// when they exist.
+func (bm *BackupManager) localCopy(ctx context.Context, filename string) (string, bool, error) {
+	path := filepath.Join(bm.config.BackupDir, filename)
+	if _, err := os.Stat(path); err == nil {
+		return path, false, nil
+	}
+	if !bm.isRemote() {
+		return "", false, ErrBackupNotFound
+	}
 
-			logger.Debug("Deleted old backup: %s (age: %v)", file.Name(), now.Sub(fileInfo.ModTime()))
-			deletedCount++
+	logger.Info("Downloading backup %s from %s storage", filename, bm.storage.Type())
+	if err := bm.storage.Download(ctx, filename, path); err != nil {
+		return "", false, err
+	}
+	for _, suffix := range sidecarSuffixes {
+		if err := bm.storage.Download(ctx, filename+suffix, path+suffix); err != nil && !errors.Is(err, ErrBackupNotFound) {
+			logger.Warn("Failed to download %s of backup %s: %v", suffix, filename, err)
 		}
 	}
+	return path, true, nil
+}
 
-	logger.Info("Manual backup cleanup completed: removed %d old backups", deletedCount)
+// uploadBackup copies a backup, its metadata and manifest to remote
+// storage, then drops the local copy unless it is kept
+func (bm *BackupManager) uploadBackup(ctx context.Context, filename string) error {
+	path := filepath.Join(bm.config.BackupDir, filename)
+	if err := bm.storage.Upload(ctx, path, filename); err != nil {
+		return err
+	}
+	if err := bm.storeSidecars(ctx, filename); err != nil {
+		return err
+	}
+	if !bm.config.KeepLocalCopy {
+		bm.removeLocal(filename)
+	}
 	return nil
 }
+
+// removeLocal deletes the local copy of a backup and its sidecars
+func (bm *BackupManager) removeLocal(filename string) {
+	path := filepath.Join(bm.config.BackupDir, filename)
+	names := []string{path}
+	for _, suffix := range sidecarSuffixes {
+		names = append(names, path+suffix)
+	}
+	for _, name := range names {
+		if err := os.Remove(name); err != nil && !os.IsNotExist(err) {
+			logger.Warn("Failed to remove local backup file %s: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,419 @@
+package backup
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/logger"
+)
+
+// ErrBackupProtected is returned for deleting a protected backup
+var ErrBackupProtected = errors.New("backup is protected from deletion")
+
+// ErrNoCatalog is returned by operations that need the backup catalog when
+// the manager has none
+var ErrNoCatalog = errors.New("backup catalog not configured")
+
+// Statuses of cataloged backups
+const (
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+	StatusMissing   = "missing" // Recorded as completed but gone from storage
+	StatusDeleted   = "deleted"
+)
+
+// typeImported is the type of backups found in storage without metadata
+const typeImported = "imported"
+
+// CatalogEntry is a backup recorded in the catalog
+type CatalogEntry struct {
+	Filename      string `json:"filename"`
+	Type          string `json:"type"`
+	Mode          string `json:"mode"`
+	Status        string `json:"status"`
+	Description   string `json:"description,omitempty"`
+	DatabaseName  string `json:"database_name,omitempty"`
+	StorageType   string `json:"storage_type"`
+	Size          int64  `json:"size"`
+	Checksum      string `json:"checksum,omitempty"`
+	Compressed    bool   `json:"compressed"`
+	Encrypted     bool   `json:"encrypted"`
+	SchemaVersion string `json:"schema_version,omitempty"`
+	AppVersion    string `json:"app_version,omitempty"`
+	DBVersion     string `json:"db_version,omitempty"`
+	KeyID         string `json:"key_id,omitempty"`
+	// MigrationTarget is the schema version a migration backup was taken
+	// before migrating to, from SchemaVersion
+	MigrationTarget string        `json:"migration_target,omitempty"`
+	Duration        time.Duration `json:"duration" swaggertype:"integer"`
+	Error           string        `json:"error,omitempty"`
+	CreatedAt       time.Time     `json:"created_at"`
+	// Protected backups are not deleted by retention, pruning or by hand
+	Protected bool `json:"protected"`
+}
+
+// CatalogQuery filters the backups of the catalog. Empty fields match
+// every backup.
+type CatalogQuery struct {
+	Status string
+	Type   string
+	From   time.Time // Made at or after
+	To     time.Time // Made before
+	Text   string    // Part of the filename or description, ignoring case
+}
+
+// Catalog records the backups that were made, so they can be listed
+// without scanning the storage
+type Catalog interface {
+	// Record adds a backup, replacing what was recorded under its filename
+	Record(ctx context.Context, entry CatalogEntry) error
+	// Entries returns the backups with a status, newest first
+	Entries(ctx context.Context, status string) ([]CatalogEntry, error)
+	// Search returns the backups matching query, newest first
+	Search(ctx context.Context, query CatalogQuery) ([]CatalogEntry, error)
+	// Recent returns the newest backups of any status
+	Recent(ctx context.Context, limit int) ([]CatalogEntry, error)
+	// SetStatus changes the status of a backup
+	SetStatus(ctx context.Context, filename, status string) error
+	// SetProtected protects a backup from deletion or lifts its protection,
+	// returning ErrBackupNotFound for a backup that is not cataloged
+	SetProtected(ctx context.Context, filename string, protected bool) error
+}
+
+// dbCatalog keeps the catalog in the backups table
+type dbCatalog struct {
+	store db.Querier
+}
+
+// NewCatalog creates a catalog kept in the backups table
+func NewCatalog(store db.Querier) Catalog {
+	return &dbCatalog{store: store}
+}
+
+func (c *dbCatalog) Record(ctx context.Context, entry CatalogEntry) error {
+	_, err := c.store.UpsertBackup(ctx, db.UpsertBackupParams{
+		Filename:        entry.Filename,
+		Type:            entry.Type,
+		Mode:            entry.Mode,
+		Status:          entry.Status,
+		Description:     entry.Description,
+		DatabaseName:    entry.DatabaseName,
+		StorageType:     entry.StorageType,
+		Size:            entry.Size,
+		Checksum:        entry.Checksum,
+		Compressed:      entry.Compressed,
+		Encrypted:       entry.Encrypted,
+		SchemaVersion:   entry.SchemaVersion,
+		DurationMs:      entry.Duration.Milliseconds(),
+		Error:           entry.Error,
+		CreatedAt:       entry.CreatedAt,
+		AppVersion:      entry.AppVersion,
+		DbVersion:       entry.DBVersion,
+		KeyID:           entry.KeyID,
+		MigrationTarget: entry.MigrationTarget,
+	})
+	return err
+}
+
+func (c *dbCatalog) Entries(ctx context.Context, status string) ([]CatalogEntry, error) {
+	rows, err := c.store.ListBackupsByStatus(ctx, status)
+	if err != nil {
+		return nil, err
+	}
+	return catalogEntries(rows), nil
+}
+
+func (c *dbCatalog) Search(ctx context.Context, query CatalogQuery) ([]CatalogEntry, error) {
+	rows, err := c.store.SearchBackups(ctx, db.SearchBackupsParams{
+		Status:      query.Status,
+		Type:        query.Type,
+		CreatedFrom: sql.NullTime{Time: query.From, Valid: !query.From.IsZero()},
+		CreatedTo:   sql.NullTime{Time: query.To, Valid: !query.To.IsZero()},
+		Search:      query.Text,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return catalogEntries(rows), nil
+}
+
+func (c *dbCatalog) Recent(ctx context.Context, limit int) ([]CatalogEntry, error) {
+	rows, err := c.store.ListRecentBackups(ctx, int32(limit))
+	if err != nil {
+		return nil, err
+	}
+	return catalogEntries(rows), nil
+}
+
+func (c *dbCatalog) SetStatus(ctx context.Context, filename, status string) error {
+	return c.store.SetBackupStatus(ctx, db.SetBackupStatusParams{Filename: filename, Status: status})
+}
+
+func (c *dbCatalog) SetProtected(ctx context.Context, filename string, protected bool) error {
+	updated, err := c.store.SetBackupProtected(ctx, db.SetBackupProtectedParams{Filename: filename, Protected: protected})
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return ErrBackupNotFound
+	}
+	return nil
+}
+
+func catalogEntries(rows []db.Backup) []CatalogEntry {
+	entries := make([]CatalogEntry, len(rows))
+	for i, row := range rows {
+		entries[i] = CatalogEntry{
+			Filename:        row.Filename,
+			Type:            row.Type,
+			Mode:            row.Mode,
+			Status:          row.Status,
+			Description:     row.Description,
+			DatabaseName:    row.DatabaseName,
+			StorageType:     row.StorageType,
+			Size:            row.Size,
+			Checksum:        row.Checksum,
+			Compressed:      row.Compressed,
+			Encrypted:       row.Encrypted,
+			SchemaVersion:   row.SchemaVersion,
+			AppVersion:      row.AppVersion,
+			DBVersion:       row.DbVersion,
+			KeyID:           row.KeyID,
+			MigrationTarget: row.MigrationTarget,
+			Protected:       row.Protected,
+			Duration:        time.Duration(row.DurationMs) * time.Millisecond,
+			Error:           row.Error,
+			CreatedAt:       row.CreatedAt,
+		}
+	}
+	return entries
+}
+
+// SetCatalog makes the manager record its backups in catalog and list them
+// from it instead of scanning the storage
+func (bm *BackupManager) SetCatalog(catalog Catalog) {
+	bm.catalog = catalog
+}
+
+// Catalog returns the catalog of the manager, or nil without one
+func (bm *BackupManager) Catalog() Catalog {
+	return bm.catalog
+}
+
+// recordBackup catalogs the outcome of creating a backup. Failures before
+// the backup was named are not recorded.
+func (bm *BackupManager) recordBackup(ctx context.Context, result *BackupResult, duration time.Duration, backupErr error) {
+	if bm.catalog == nil || result.Metadata == nil {
+		return
+	}
+	metadata := result.Metadata
+	entry := bm.metadataEntry(metadata)
+	entry.Duration = duration
+	if backupErr != nil {
+		entry.Status = StatusFailed
+		entry.Error = result.Error
+	}
+	if err := bm.catalog.Record(ctx, entry); err != nil {
+		logger.Warn("Failed to record backup %s in the catalog: %v", metadata.Filename, err)
+	}
+}
+
+// metadataEntry returns the catalog entry of a completed backup
+func (bm *BackupManager) metadataEntry(metadata *BackupMetadata) CatalogEntry {
+	return CatalogEntry{
+		Filename:        metadata.Filename,
+		Type:            metadata.Type,
+		Mode:            metadata.BackupMode(),
+		Status:          StatusCompleted,
+		Description:     metadata.Description,
+		DatabaseName:    metadata.DatabaseName,
+		StorageType:     bm.storage.Type(),
+		Size:            metadata.Size,
+		Checksum:        metadata.Checksum,
+		Compressed:      metadata.Compressed,
+		Encrypted:       metadata.Encrypted,
+		SchemaVersion:   metadata.SchemaVersion,
+		AppVersion:      metadata.AppVersion,
+		DBVersion:       metadata.DBVersion,
+		KeyID:           metadata.KeyID,
+		MigrationTarget: metadata.MigrationTarget,
+		CreatedAt:       metadata.CreatedAt,
+	}
+}
+
+// storedEntry returns the catalog entry of a backup found in storage, from
+// its metadata when it has any
+func (bm *BackupManager) storedEntry(ctx context.Context, file StoredFile, backupType, description string) CatalogEntry {
+	if metadata, err := bm.fetchMetadata(ctx, file.Name); err == nil {
+		entry := bm.metadataEntry(metadata)
+		entry.Filename, entry.Size = file.Name, file.Size
+		return entry
+	}
+	return CatalogEntry{
+		Filename:    file.Name,
+		Type:        backupType,
+		Mode:        ModeFull,
+		Status:      StatusCompleted,
+		Description: description,
+		StorageType: bm.storage.Type(),
+		Size:        file.Size,
+		Compressed:  strings.Contains(file.Name, ".sql.gz"),
+		Encrypted:   strings.HasSuffix(file.Name, ".enc"),
+		CreatedAt:   file.ModTime,
+	}
+}
+
+// TrackBackup catalogs a backup that was put in storage without the
+// manager, such as an approved upload
+func (bm *BackupManager) TrackBackup(ctx context.Context, filename, backupType, description string) error {
+	if bm.catalog == nil {
+		return nil
+	}
+	files, err := bm.storage.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list %s backups: %w", bm.storage.Type(), err)
+	}
+	for _, file := range files {
+		if file.Name == filename {
+			return bm.catalog.Record(ctx, bm.storedEntry(ctx, file, backupType, description))
+		}
+	}
+	return ErrBackupNotFound
+}
+
+// ForgetBackup marks a backup that was deleted without the manager as
+// deleted in the catalog
+func (bm *BackupManager) ForgetBackup(ctx context.Context, filename string) {
+	bm.setStatus(ctx, filename, StatusDeleted)
+}
+
+// ProtectBackup protects a cataloged backup from deletion, or lifts its
+// protection
+func (bm *BackupManager) ProtectBackup(ctx context.Context, filename string, protected bool) error {
+	if bm.catalog == nil {
+		return ErrNoCatalog
+	}
+	if !bm.isValidBackupFilename(filename) {
+		return fmt.Errorf("invalid backup filename")
+	}
+	return bm.catalog.SetProtected(ctx, filename, protected)
+}
+
+// IsProtected reports whether a backup is protected from deletion. Without
+// a catalog no backup is.
+func (bm *BackupManager) IsProtected(ctx context.Context, filename string) (bool, error) {
+	protected, err := bm.protectedBackups(ctx)
+	return protected[filename], err
+}
+
+// protectedBackups returns the protected backups of the catalog
+func (bm *BackupManager) protectedBackups(ctx context.Context) (map[string]bool, error) {
+	if bm.catalog == nil {
+		return nil, nil
+	}
+	entries, err := bm.catalog.Entries(ctx, StatusCompleted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup catalog: %w", err)
+	}
+	protected := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.Protected {
+			protected[entry.Filename] = true
+		}
+	}
+	return protected, nil
+}
+
+func (bm *BackupManager) setStatus(ctx context.Context, filename, status string) {
+	if bm.catalog == nil {
+		return
+	}
+	if err := bm.catalog.SetStatus(ctx, filename, status); err != nil {
+		logger.Warn("Failed to mark backup %s %s in the catalog: %v", filename, status, err)
+	}
+}
+
+// Reconciliation compares the catalog with the storage
+type Reconciliation struct {
+	// Untracked backups are in storage but not cataloged as completed;
+	// they are cataloged from their metadata
+	Untracked []StoredFile `json:"untracked"`
+	// Missing backups are cataloged as completed but gone from storage;
+	// they are marked missing
+	Missing []CatalogEntry `json:"missing"`
+	DryRun  bool           `json:"dry_run"`
+}
+
+// ReconcileCatalog finds the backups the catalog and the storage disagree
+// on and, unless dryRun, brings the catalog in line with the storage
+func (bm *BackupManager) ReconcileCatalog(ctx context.Context, dryRun bool) (*Reconciliation, error) {
+	if bm.catalog == nil {
+		return nil, ErrNoCatalog
+	}
+	files, err := bm.storage.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s backups: %w", bm.storage.Type(), err)
+	}
+	entries, err := bm.catalog.Entries(ctx, StatusCompleted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup catalog: %w", err)
+	}
+
+	report := &Reconciliation{Untracked: []StoredFile{}, Missing: []CatalogEntry{}, DryRun: dryRun}
+	stored := make(map[string]bool, len(files))
+	for _, file := range files {
+		if bm.isValidBackupFilename(file.Name) {
+			stored[file.Name] = true
+		}
+	}
+	cataloged := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		cataloged[entry.Filename] = true
+		if !stored[entry.Filename] {
+			report.Missing = append(report.Missing, entry)
+		}
+	}
+	for _, file := range files {
+		if stored[file.Name] && !cataloged[file.Name] {
+			report.Untracked = append(report.Untracked, file)
+		}
+	}
+	sortNewestFirst(report.Untracked)
+	if dryRun {
+		return report, nil
+	}
+
+	for _, file := range report.Untracked {
+		if err := bm.catalog.Record(ctx, bm.storedEntry(ctx, file, typeImported, "")); err != nil {
+			return report, fmt.Errorf("failed to catalog backup %s: %w", file.Name, err)
+		}
+	}
+	for _, entry := range report.Missing {
+		if err := bm.catalog.SetStatus(ctx, entry.Filename, StatusMissing); err != nil {
+			return report, fmt.Errorf("failed to mark backup %s missing: %w", entry.Filename, err)
+		}
+	}
+	if len(report.Untracked)+len(report.Missing) > 0 {
+		logger.Info("Backup catalog reconciled: %d backups added, %d marked missing", len(report.Untracked), len(report.Missing))
+	}
+	return report, nil
+}
+
+// catalogedBackups returns the completed backups of the catalog as stored
+// files, newest first
+func (bm *BackupManager) catalogedBackups(ctx context.Context) ([]StoredFile, error) {
+	entries, err := bm.catalog.Entries(ctx, StatusCompleted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup catalog: %w", err)
+	}
+	files := make([]StoredFile, len(entries))
+	for i, entry := range entries {
+		files[i] = StoredFile{Name: entry.Filename, Size: entry.Size, ModTime: entry.CreatedAt}
+	}
+	return files, nil
+}
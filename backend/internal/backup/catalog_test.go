@@ -0,0 +1,199 @@
+package backup
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toeic-app/internal/config"
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+// memoryCatalog keeps catalog entries by filename
+type memoryCatalog map[string]CatalogEntry
+
+func (c memoryCatalog) Record(_ context.Context, entry CatalogEntry) error {
+	c[entry.Filename] = entry
+	return nil
+}
+
+func (c memoryCatalog) Entries(_ context.Context, status string) ([]CatalogEntry, error) {
+	var entries []CatalogEntry
+	for _, entry := range c {
+		if entry.Status == status {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].CreatedAt.After(entries[j].CreatedAt) })
+	return entries, nil
+}
+
+func (c memoryCatalog) Search(_ context.Context, query CatalogQuery) ([]CatalogEntry, error) {
+	var entries []CatalogEntry
+	for _, entry := range c {
+		text := strings.ToLower(query.Text)
+		if (query.Status == "" || entry.Status == query.Status) &&
+			(query.Type == "" || entry.Type == query.Type) &&
+			(query.From.IsZero() || !entry.CreatedAt.Before(query.From)) &&
+			(query.To.IsZero() || entry.CreatedAt.Before(query.To)) &&
+			(text == "" || strings.Contains(strings.ToLower(entry.Filename+" "+entry.Description), text)) {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].CreatedAt.After(entries[j].CreatedAt) })
+	return entries, nil
+}
+
+func (c memoryCatalog) Recent(_ context.Context, limit int) ([]CatalogEntry, error) {
+	var entries []CatalogEntry
+	for _, entry := range c {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].CreatedAt.After(entries[j].CreatedAt) })
+	return entries[:min(limit, len(entries))], nil
+}
+
+func (c memoryCatalog) SetStatus(_ context.Context, filename, status string) error {
+	if entry, ok := c[filename]; ok {
+		entry.Status = status
+		c[filename] = entry
+	}
+	return nil
+}
+
+func (c memoryCatalog) SetProtected(_ context.Context, filename string, protected bool) error {
+	entry, ok := c[filename]
+	if !ok {
+		return ErrBackupNotFound
+	}
+	entry.Protected = protected
+	c[filename] = entry
+	return nil
+}
+
+func TestListBackupsFromCatalog(t *testing.T) {
+	dir := t.TempDir()
+	bm := NewBackupManager(config.BackupConfig{BackupDir: dir}, config.Config{DBName: "toeic"})
+	catalog := memoryCatalog{}
+	bm.SetCatalog(catalog)
+	ctx := context.Background()
+
+	now := time.Now()
+	catalog["manual_backup_1.sql"] = CatalogEntry{Filename: "manual_backup_1.sql", Status: StatusCompleted, Size: 10, CreatedAt: now.Add(-time.Hour)}
+	catalog["manual_backup_2.sql"] = CatalogEntry{Filename: "manual_backup_2.sql", Status: StatusCompleted, Size: 20, CreatedAt: now}
+	catalog["manual_backup_3.sql"] = CatalogEntry{Filename: "manual_backup_3.sql", Status: StatusFailed, CreatedAt: now}
+	// Files in storage are not listed until they are cataloged
+	writeChainBackup(t, bm, BackupMetadata{Filename: "manual_backup_4.sql"}, time.Minute)
+
+	files, err := bm.ListBackups(ctx)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	assert.Equal(t, StoredFile{Name: "manual_backup_2.sql", Size: 20, ModTime: now}, files[0])
+
+	bm.recordBackup(ctx, &BackupResult{
+		Error:    "backup failed after 3 attempts: pg_dump exited with status 1",
+		Metadata: &BackupMetadata{Filename: "manual_backup_5.sql", Type: "manual", CreatedAt: now},
+	}, time.Second, errors.New("pg_dump exited with status 1"))
+	assert.Equal(t, StatusFailed, catalog["manual_backup_5.sql"].Status)
+	assert.Equal(t, ModeFull, catalog["manual_backup_5.sql"].Mode)
+	assert.Equal(t, time.Second, catalog["manual_backup_5.sql"].Duration)
+}
+
+func TestReconcileCatalog(t *testing.T) {
+	dir := t.TempDir()
+	bm := NewBackupManager(config.BackupConfig{BackupDir: dir}, config.Config{DBName: "toeic"})
+	catalog := memoryCatalog{}
+	bm.SetCatalog(catalog)
+	ctx := context.Background()
+
+	// Cataloged and stored
+	writeChainBackup(t, bm, BackupMetadata{Filename: "manual_backup_1.sql"}, time.Hour)
+	catalog["manual_backup_1.sql"] = CatalogEntry{Filename: "manual_backup_1.sql", Status: StatusCompleted, CreatedAt: time.Now().Add(-time.Hour)}
+	// Stored with metadata, e.g. made by backup-admin
+	writeChainBackup(t, bm, BackupMetadata{Filename: "automatic_backup_2.sql", Type: "automatic", Checksum: "abc",
+		DatabaseName: "toeic", SchemaVersion: "56", AppVersion: "1.2.0", DBVersion: "16.4", Encrypted: true, KeyID: "k2",
+		CreatedAt: time.Now()}, time.Minute)
+	// Stored without metadata
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "copied.sql.gz"), []byte("gzip"), 0644))
+	// Cataloged but gone
+	catalog["manual_backup_0.sql"] = CatalogEntry{Filename: "manual_backup_0.sql", Status: StatusCompleted, CreatedAt: time.Now().Add(-48 * time.Hour)}
+
+	report, err := bm.ReconcileCatalog(ctx, true)
+	require.NoError(t, err)
+	require.Len(t, report.Untracked, 2)
+	require.Len(t, report.Missing, 1)
+	assert.Equal(t, "manual_backup_0.sql", report.Missing[0].Filename)
+	assert.NotContains(t, catalog, "copied.sql.gz")
+
+	_, err = bm.ReconcileCatalog(ctx, false)
+	require.NoError(t, err)
+	assert.Equal(t, StatusMissing, catalog["manual_backup_0.sql"].Status)
+	imported := catalog["automatic_backup_2.sql"]
+	assert.Equal(t, StatusCompleted, imported.Status)
+	assert.Equal(t, "automatic", imported.Type)
+	assert.Equal(t, "abc", imported.Checksum)
+	assert.Equal(t, "56", imported.SchemaVersion)
+	assert.Equal(t, "1.2.0", imported.AppVersion)
+	assert.Equal(t, "16.4", imported.DBVersion)
+	assert.Equal(t, "k2", imported.KeyID)
+	copied := catalog["copied.sql.gz"]
+	assert.Equal(t, typeImported, copied.Type)
+	assert.True(t, copied.Compressed)
+	assert.Equal(t, int64(4), copied.Size)
+
+	report, err = bm.ReconcileCatalog(ctx, true)
+	require.NoError(t, err)
+	assert.Empty(t, report.Untracked)
+	assert.Empty(t, report.Missing)
+
+	// Deleting through the manager keeps the entry as deleted
+	require.NoError(t, bm.DeleteBackup(ctx, "copied.sql.gz"))
+	assert.Equal(t, StatusDeleted, catalog["copied.sql.gz"].Status)
+
+	_, err = NewBackupManager(config.BackupConfig{BackupDir: dir}, config.Config{}).ReconcileCatalog(ctx, true)
+	assert.ErrorIs(t, err, ErrNoCatalog)
+}
+
+// searchStore answers SearchBackups with rows, keeping the filters
+type searchStore struct {
+	db.Querier
+	params db.SearchBackupsParams
+	rows   []db.Backup
+}
+
+func (s *searchStore) SearchBackups(_ context.Context, arg db.SearchBackupsParams) ([]db.Backup, error) {
+	s.params = arg
+	return s.rows, nil
+}
+
+func TestSearchCatalog(t *testing.T) {
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	store := &searchStore{rows: []db.Backup{{
+		Filename: "automatic_backup_20261002_030000.sql.gz.enc", Type: "automatic", Status: StatusCompleted,
+		Size: 2048, Checksum: "abc", Encrypted: true, KeyID: "k2", AppVersion: "1.2.0", DbVersion: "16.4",
+		SchemaVersion: "60", DurationMs: 1500, CreatedAt: from.Add(27 * time.Hour),
+	}}}
+	catalog := NewCatalog(store)
+
+	entries, err := catalog.Search(context.Background(), CatalogQuery{Status: StatusCompleted, Type: "automatic", From: from, Text: "0302"})
+	require.NoError(t, err)
+	assert.Equal(t, db.SearchBackupsParams{
+		Status:      StatusCompleted,
+		Type:        "automatic",
+		CreatedFrom: sql.NullTime{Time: from, Valid: true},
+		Search:      "0302",
+	}, store.params)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "k2", entries[0].KeyID)
+	assert.Equal(t, "1.2.0", entries[0].AppVersion)
+	assert.Equal(t, "16.4", entries[0].DBVersion)
+	assert.Equal(t, 1500*time.Millisecond, entries[0].Duration)
+}
@@ -0,0 +1,365 @@
+package backup
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/toeic-app/internal/logger"
+	"github.com/toeic-app/internal/util"
+)
+
+// Backup modes. A full backup is a complete pg_dump snapshot. An
+// incremental backup holds the tables written since the previous backup of
+// any mode, a differential one those written since the last full backup.
+const (
+	ModeFull         = "full"
+	ModeIncremental  = "incremental"
+	ModeDifferential = "differential"
+)
+
+var (
+	ErrInvalidMode = errors.New("invalid backup mode")
+	ErrBrokenChain = errors.New("backup chain is incomplete")
+)
+
+// dbState is recorded with every backup so the next incremental or
+// differential one can tell what changed since
+type dbState struct {
+	// WALPosition is pg_current_wal_lsn(); when it did not move nothing
+	// was written
+	WALPosition string
+	// SchemaVersion is the applied migration; changes to the schema need a
+	// full backup
+	SchemaVersion string
+	// ServerVersion is the version of PostgreSQL
+	ServerVersion string
+	// TrackCounts is whether the server counts table writes at all
+	TrackCounts bool
+	// StatsReset is when the statistics of the database were last reset;
+	// zero when they never were
+	StatsReset time.Time
+	// TableWrites counts the rows inserted, updated and deleted per table
+	TableWrites map[string]int64
+	// Sequences are dumped with every incremental backup
+	Sequences []string
+}
+
+// ValidMode reports whether mode is a backup mode
+func ValidMode(mode string) bool {
+	switch mode {
+	case ModeFull, ModeIncremental, ModeDifferential:
+		return true
+	}
+	return false
+}
+
+// BackupMode returns the mode of the backup; backups made before modes
+// existed are full
+func (m *BackupMetadata) BackupMode() string {
+	if m.Mode == "" {
+		return ModeFull
+	}
+	return m.Mode
+}
+
+// captureState reads the WAL position, schema version, server version and
+// write counters of the database
+func (bm *BackupManager) captureState(ctx context.Context) (*dbState, error) {
+	rows, err := bm.query(ctx, `SELECT pg_current_wal_lsn(), (SELECT version FROM schema_migrations LIMIT 1), current_setting('server_version'),
+current_setting('track_counts'),
+COALESCE((SELECT extract(epoch FROM stats_reset)::bigint FROM pg_stat_database WHERE datname = current_database()), 0)`)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) != 1 || len(rows[0]) != 5 {
+		return nil, fmt.Errorf("unexpected WAL position result")
+	}
+	state := &dbState{
+		WALPosition:   rows[0][0],
+		SchemaVersion: rows[0][1],
+		ServerVersion: rows[0][2],
+		TrackCounts:   rows[0][3] == "on",
+		TableWrites:   make(map[string]int64),
+	}
+	statsReset, err := strconv.ParseInt(rows[0][4], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected statistics reset time %q", rows[0][4])
+	}
+	if statsReset > 0 {
+		state.StatsReset = time.Unix(statsReset, 0)
+	}
+
+	rows, err = bm.query(ctx, "SELECT schemaname || '.' || relname, n_tup_ins + n_tup_upd + n_tup_del FROM pg_stat_user_tables WHERE relname <> 'schema_migrations'")
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		if len(row) != 2 {
+			continue
+		}
+		writes, err := strconv.ParseInt(row[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected write count %q for %s", row[1], row[0])
+		}
+		state.TableWrites[row[0]] = writes
+	}
+
+	rows, err = bm.query(ctx, "SELECT schemaname || '.' || sequencename FROM pg_sequences")
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		state.Sequences = append(state.Sequences, row[0])
+	}
+	return state, nil
+}
+
+// query runs a query with psql and returns the rows
+func (bm *BackupManager) query(ctx context.Context, query string) ([][]string, error) {
+	return bm.queryDatabase(ctx, bm.dbConfig.DBName, query)
+}
+
+// queryDatabase runs a query on another database of the server
+func (bm *BackupManager) queryDatabase(ctx context.Context, dbName, query string) ([][]string, error) {
+	args := []string{
+		"--host=" + bm.dbConfig.DBHost,
+		"--port=" + bm.dbConfig.DBPort,
+		"--username=" + bm.dbConfig.DBUser,
+		"--dbname=" + dbName,
+		"--no-psqlrc",
+		"--tuples-only",
+		"--no-align",
+		"--field-separator=|",
+		"--command=" + query,
+	}
+	cmd, err := bm.tools.Command(ctx, util.ToolPsql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("psql command not found: %w", err)
+	}
+	cmd.Env = append(os.Environ(), "PGPASSWORD="+bm.dbConfig.DBPassword, "PGCLIENTENCODING=UTF8")
+
+	output, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("psql query failed: %w, output: %s", err, string(exitErr.Stderr))
+		}
+		return nil, fmt.Errorf("psql query failed: %w", err)
+	}
+
+	var rows [][]string
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			rows = append(rows, strings.Split(line, "|"))
+		}
+	}
+	return rows, scanner.Err()
+}
+
+// changedTables returns the tables written since the parent backup: those
+// whose write counter moved, including counters reset by a statistics
+// reset, and tables the parent did not know
+func changedTables(parent, current map[string]int64) []string {
+	var tables []string
+	for table, writes := range current {
+		if before, ok := parent[table]; !ok || before != writes {
+			tables = append(tables, table)
+		}
+	}
+	sort.Strings(tables)
+	return tables
+}
+
+// fullBackupReason returns why the tables written since parent cannot be
+// told from the write counters, so a full backup is needed instead, or ""
+// when tables are those written. The counters are statistics: they are not
+// kept with track_counts off, start over when the statistics are reset and
+// are reported a moment after the writes. They are only trusted when they
+// account for the WAL written since parent, which callers checked moved.
+func fullBackupReason(parent *BackupMetadata, state *dbState, tables []string) string {
+	switch {
+	case !state.TrackCounts:
+		return "track_counts is off, so table writes are not counted"
+	case state.StatsReset.After(parent.CreatedAt):
+		return fmt.Sprintf("Statistics were reset since %s", parent.Filename)
+	case len(tables) == 0:
+		return fmt.Sprintf("WAL was written since %s but no table writes were counted", parent.Filename)
+	}
+	return ""
+}
+
+// findParent returns the backup an incremental or differential backup
+// builds on: the newest backup of the database for incremental, the newest
+// full backup for differential. Only backups that recorded the database
+// state qualify. It returns nil when there is none.
+func (bm *BackupManager) findParent(ctx context.Context, mode string) (*BackupMetadata, error) {
+	files, err := bm.ListBackups(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Backups are listed newest first
+	for _, file := range files {
+		metadata, err := bm.fetchMetadata(ctx, file.Name)
+		if err != nil {
+			continue
+		}
+		if metadata.DatabaseName != bm.dbConfig.DBName || metadata.TableWrites == nil {
+			continue
+		}
+		if mode == ModeDifferential && metadata.BackupMode() != ModeFull {
+			continue
+		}
+		return metadata, nil
+	}
+	return nil, nil
+}
+
+// fetchMetadata loads the metadata of a backup, downloading it from remote
+// storage when there is no local copy
+func (bm *BackupManager) fetchMetadata(ctx context.Context, filename string) (*BackupMetadata, error) {
+	metadataPath := filepath.Join(bm.config.BackupDir, filename+".meta")
+	if _, err := os.Stat(metadataPath); os.IsNotExist(err) && bm.isRemote() {
+		if err := bm.storage.Download(ctx, filename+".meta", metadataPath); err != nil {
+			return nil, err
+		}
+	}
+	return bm.loadBackupMetadata(filename)
+}
+
+// RestoreChain returns the backups restoring filename replays, oldest
+// first: the full backup, then every incremental or differential backup up
+// to filename. A backup without metadata is restored on its own.
+func (bm *BackupManager) RestoreChain(ctx context.Context, filename string) ([]string, error) {
+	metadata, err := bm.fetchMetadata(ctx, filename)
+	if err != nil {
+		return []string{filename}, nil
+	}
+
+	chain := []string{filename}
+	seen := map[string]bool{filename: true}
+	for metadata.BackupMode() != ModeFull {
+		parent := metadata.Parent
+		if parent == "" || seen[parent] {
+			return nil, fmt.Errorf("%w: %s has no parent backup", ErrBrokenChain, metadata.Filename)
+		}
+		metadata, err = bm.fetchMetadata(ctx, parent)
+		if err != nil {
+			return nil, fmt.Errorf("%w: parent backup %s is missing", ErrBrokenChain, parent)
+		}
+		seen[parent] = true
+		chain = append([]string{parent}, chain...)
+	}
+	return chain, nil
+}
+
+// executeIncrementalBackup writes the rows of tables, and every sequence,
+// as a script that replaces the contents of those tables when restored on
+// top of the parent backup. Foreign keys are not checked while the tables
+// are replaced, which needs a superuser.
+func (bm *BackupManager) executeIncrementalBackup(ctx context.Context, outputPath string, tables, sequences []string) error {
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	fmt.Fprintf(writer, "-- Incremental backup of %d tables\n", len(tables))
+	fmt.Fprintln(writer, "SET session_replication_role = replica;")
+	for _, table := range tables {
+		fmt.Fprintf(writer, "DELETE FROM %s;\n", quoteQualifiedName(table))
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+
+	if len(tables) > 0 {
+		if err := bm.dumpData(ctx, file, append(append([]string{}, tables...), sequences...)); err != nil {
+			return err
+		}
+	}
+
+	if _, err := fmt.Fprintln(file, "SET session_replication_role = DEFAULT;"); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// dumpData appends the data of the given tables and sequences to out
+func (bm *BackupManager) dumpData(ctx context.Context, out io.Writer, relations []string) error {
+	return bm.dumpDataFrom(ctx, bm.dbConfig.DBName, out, relations)
+}
+
+// dumpDataFrom appends the data of relations of another database of the
+// server to out
+func (bm *BackupManager) dumpDataFrom(ctx context.Context, dbName string, out io.Writer, relations []string) error {
+	args := []string{
+		"--host=" + bm.dbConfig.DBHost,
+		"--port=" + bm.dbConfig.DBPort,
+		"--username=" + bm.dbConfig.DBUser,
+		"--format=plain",
+		"--encoding=UTF8",
+		"--data-only",
+		"--no-owner",
+		"--no-privileges",
+	}
+	for _, relation := range relations {
+		args = append(args, "--table="+quoteQualifiedName(relation))
+	}
+	args = append(args, dbName)
+
+	cmd, err := bm.tools.Command(ctx, util.ToolPgDump, args...)
+	if err != nil {
+		return fmt.Errorf("pg_dump command not found: %w", err)
+	}
+	cmd.Env = append(os.Environ(), "PGPASSWORD="+bm.dbConfig.DBPassword, "PGCLIENTENCODING=UTF8")
+	var stderr strings.Builder
+	cmd.Stdout = bm.throttleWriter(ctx, out)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("pg_dump failed: %w, output: %s", err, stderr.String())
+	}
+	return nil
+}
+
+// quoteQualifiedName quotes schema.name for SQL and pg_dump patterns
+func quoteQualifiedName(name string) string {
+	parts := strings.SplitN(name, ".", 2)
+	for i, part := range parts {
+		parts[i] = `"` + strings.ReplaceAll(part, `"`, `""`) + `"`
+	}
+	return strings.Join(parts, ".")
+}
+
+// chainDependencies returns the backups that kept backups build on, which
+// must not be deleted while those are kept
+func (bm *BackupManager) chainDependencies(ctx context.Context, kept []string) map[string]bool {
+	needed := make(map[string]bool)
+	for _, filename := range kept {
+		metadata, err := bm.fetchMetadata(ctx, filename)
+		if err != nil || metadata.BackupMode() == ModeFull {
+			continue
+		}
+		chain, err := bm.RestoreChain(ctx, filename)
+		if err != nil {
+			logger.Warn("Backup %s cannot be restored: %v", filename, err)
+			continue
+		}
+		for _, name := range chain[:len(chain)-1] {
+			needed[name] = true
+		}
+	}
+	return needed
+}
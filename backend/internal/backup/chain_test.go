@@ -0,0 +1,96 @@
+package backup
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toeic-app/internal/config"
+)
+
+func TestChangedTables(t *testing.T) {
+	parent := map[string]int64{"public.users": 10, "public.words": 5, "public.exams": 3}
+	current := map[string]int64{"public.users": 12, "public.words": 5, "public.exams": 1, "public.badges": 0}
+
+	// Moved counters, counters reset by a statistics reset and new tables
+	assert.Equal(t, []string{"public.badges", "public.exams", "public.users"}, changedTables(parent, current))
+	assert.Empty(t, changedTables(current, current))
+}
+
+func TestFullBackupReason(t *testing.T) {
+	parent := &BackupMetadata{Filename: "manual_backup_1.sql", CreatedAt: time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)}
+	state := &dbState{TrackCounts: true, StatsReset: parent.CreatedAt.Add(-24 * time.Hour)}
+
+	assert.Empty(t, fullBackupReason(parent, state, []string{"public.users"}))
+	// The WAL moved, so something was written that the counters missed
+	assert.Contains(t, fullBackupReason(parent, state, nil), "no table writes were counted")
+
+	reset := *state
+	reset.StatsReset = parent.CreatedAt.Add(time.Hour)
+	assert.Contains(t, fullBackupReason(parent, &reset, []string{"public.users"}), "Statistics were reset")
+	untracked := *state
+	untracked.TrackCounts = false
+	assert.Contains(t, fullBackupReason(parent, &untracked, []string{"public.users"}), "track_counts is off")
+	never := *state
+	never.StatsReset = time.Time{}
+	assert.Empty(t, fullBackupReason(parent, &never, []string{"public.users"}))
+}
+
+func TestQuoteQualifiedName(t *testing.T) {
+	assert.Equal(t, `"public"."user_answers"`, quoteQualifiedName("public.user_answers"))
+	assert.Equal(t, `"public"."odd""name"`, quoteQualifiedName(`public.odd"name`))
+}
+
+// writeChainBackup stores a backup file with its metadata, modified age ago
+func writeChainBackup(t *testing.T, bm *BackupManager, metadata BackupMetadata, age time.Duration) {
+	t.Helper()
+	path := filepath.Join(bm.config.BackupDir, metadata.Filename)
+	require.NoError(t, os.WriteFile(path, []byte("SET client_encoding = 'UTF8';\n"), 0644))
+	require.NoError(t, bm.saveBackupMetadata(&metadata))
+	modified := time.Now().Add(-age)
+	require.NoError(t, os.Chtimes(path, modified, modified))
+}
+
+func TestRestoreChainAndRetention(t *testing.T) {
+	dir := t.TempDir()
+	bm := NewBackupManager(config.BackupConfig{BackupDir: dir}, config.Config{DBName: "toeic"})
+	ctx := context.Background()
+	writes := map[string]int64{"public.users": 1}
+
+	writeChainBackup(t, bm, BackupMetadata{Filename: "manual_backup_1.sql", DatabaseName: "toeic", Mode: ModeFull, TableWrites: writes}, 40*24*time.Hour)
+	writeChainBackup(t, bm, BackupMetadata{Filename: "manual_differential_backup_2.sql", DatabaseName: "toeic", Mode: ModeDifferential,
+		Parent: "manual_backup_1.sql", Base: "manual_backup_1.sql", TableWrites: writes}, 35*24*time.Hour)
+	writeChainBackup(t, bm, BackupMetadata{Filename: "manual_incremental_backup_3.sql", DatabaseName: "toeic", Mode: ModeIncremental,
+		Parent: "manual_differential_backup_2.sql", Base: "manual_backup_1.sql", TableWrites: writes}, time.Hour)
+	writeChainBackup(t, bm, BackupMetadata{Filename: "manual_backup_0.sql", DatabaseName: "toeic"}, 50*24*time.Hour)
+
+	chain, err := bm.RestoreChain(ctx, "manual_incremental_backup_3.sql")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"manual_backup_1.sql", "manual_differential_backup_2.sql", "manual_incremental_backup_3.sql"}, chain)
+
+	// Backups made before modes existed are full
+	chain, err = bm.RestoreChain(ctx, "manual_backup_0.sql")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"manual_backup_0.sql"}, chain)
+
+	parent, err := bm.findParent(ctx, ModeIncremental)
+	require.NoError(t, err)
+	assert.Equal(t, "manual_incremental_backup_3.sql", parent.Filename)
+	parent, err = bm.findParent(ctx, ModeDifferential)
+	require.NoError(t, err)
+	assert.Equal(t, "manual_backup_1.sql", parent.Filename)
+
+	// Retention keeps what the recent incremental backup builds on
+	require.NoError(t, bm.CleanupOldBackups(30*24*time.Hour))
+	assert.NoFileExists(t, filepath.Join(dir, "manual_backup_0.sql"))
+	assert.FileExists(t, filepath.Join(dir, "manual_backup_1.sql"))
+	assert.FileExists(t, filepath.Join(dir, "manual_differential_backup_2.sql"))
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "manual_differential_backup_2.sql.meta")))
+	_, err = bm.RestoreChain(ctx, "manual_incremental_backup_3.sql")
+	assert.ErrorIs(t, err, ErrBrokenChain)
+}
@@ -0,0 +1,35 @@
+package backup
+
+import (
+	"errors"
+
+	"github.com/toeic-app/internal/config"
+)
+
+// ErrUnknownDatabase is returned for a database that is not backed up
+var ErrUnknownDatabase = errors.New("database is not configured to be backed up")
+
+// DatabaseNames returns the names of the databases backed up, the
+// application database first
+func DatabaseNames(backupConfig config.BackupConfig) []string {
+	names := []string{config.PrimaryDatabase}
+	for _, database := range backupConfig.Databases {
+		names = append(names, database.Name)
+	}
+	return names
+}
+
+// NewDatabaseManager creates the manager of the database backed up under
+// name: the application database for config.PrimaryDatabase or an empty
+// name, else one of the databases of backupConfig, whose backups are kept
+// apart from those of the application database
+func NewDatabaseManager(backupConfig config.BackupConfig, dbConfig config.Config, name string) (*BackupManager, error) {
+	if name == "" || name == config.PrimaryDatabase {
+		return NewBackupManager(backupConfig, dbConfig), nil
+	}
+	database, ok := backupConfig.Database(name)
+	if !ok {
+		return nil, ErrUnknownDatabase
+	}
+	return NewBackupManager(backupConfig.ForDatabase(database), database.Connection(dbConfig)), nil
+}
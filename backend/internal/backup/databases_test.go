@@ -0,0 +1,67 @@
+package backup
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toeic-app/internal/config"
+)
+
+func TestNewDatabaseManager(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("BACKUP_DIR", dir)
+	t.Setenv("BACKUP_DATABASES", "analytics, Reporting")
+	t.Setenv("BACKUP_DB_ANALYTICS_HOST", "analytics-db")
+	t.Setenv("BACKUP_DB_ANALYTICS_NAME", "toeic_analytics")
+	t.Setenv("BACKUP_DB_ANALYTICS_KEEP_DAILY", "3")
+	t.Setenv("BACKUP_DB_ANALYTICS_TIME", "04:30")
+	backupConfig := config.LoadBackupConfig()
+	require.NoError(t, backupConfig.Validate())
+	assert.Equal(t, []string{config.PrimaryDatabase, "analytics", "reporting"}, DatabaseNames(backupConfig))
+
+	dbConfig := config.Config{DBHost: "localhost", DBPort: "5432", DBUser: "toeic", DBName: "toeic"}
+	bm, err := NewDatabaseManager(backupConfig, dbConfig, "analytics")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "analytics"), bm.config.BackupDir)
+	assert.Equal(t, "analytics-db", bm.dbConfig.DBHost)
+	assert.Equal(t, "5432", bm.dbConfig.DBPort)
+	assert.Equal(t, "toeic_analytics", bm.dbConfig.DBName)
+	assert.Equal(t, "04:30", bm.config.BackupTime)
+	assert.Equal(t, 3, bm.RetentionPolicy().Daily)
+	assert.Equal(t, backupConfig.RetentionWeekly, bm.RetentionPolicy().Weekly)
+	assert.False(t, bm.config.Assets)
+
+	reporting, err := NewDatabaseManager(backupConfig, dbConfig, "reporting")
+	require.NoError(t, err)
+	assert.Equal(t, "localhost", reporting.dbConfig.DBHost)
+	assert.Equal(t, "reporting", reporting.dbConfig.DBName)
+
+	primary, err := NewDatabaseManager(backupConfig, dbConfig, config.PrimaryDatabase)
+	require.NoError(t, err)
+	assert.Equal(t, dir, primary.config.BackupDir)
+	assert.Equal(t, "toeic", primary.dbConfig.DBName)
+
+	_, err = NewDatabaseManager(backupConfig, dbConfig, "billing")
+	assert.ErrorIs(t, err, ErrUnknownDatabase)
+}
+
+func TestValidateBackupDatabases(t *testing.T) {
+	for databases, message := range map[string]string{
+		"primary":             "names the application database",
+		"analytics,analytics": "listed twice",
+		"2fa":                 "must start with a letter",
+	} {
+		t.Setenv("BACKUP_DATABASES", databases)
+		backupConfig := config.LoadBackupConfig()
+		err := backupConfig.Validate()
+		require.Error(t, err, databases)
+		assert.Contains(t, err.Error(), message, databases)
+	}
+
+	t.Setenv("BACKUP_DATABASES", "analytics")
+	t.Setenv("BACKUP_DB_ANALYTICS_TIME", "25:00")
+	backupConfig := config.LoadBackupConfig()
+	assert.ErrorContains(t, backupConfig.Validate(), "BACKUP_DB_ANALYTICS_TIME")
+}
@@ -0,0 +1,405 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/toeic-app/internal/util"
+)
+
+// Dump formats of full backups. Plain backups are SQL scripts run with
+// psql. Custom and directory backups are pg_dump archives restored with
+// pg_restore, which restores them with several workers; directory ones are
+// also dumped with several workers and kept as a tar of the directory.
+// Incremental and differential backups are always plain.
+const (
+	FormatPlain     = "plain"
+	FormatCustom    = "custom"
+	FormatDirectory = "directory"
+)
+
+var ErrInvalidArchive = errors.New("invalid backup archive")
+
+// archiveMagic starts pg_dump custom format archives
+const archiveMagic = "PGDMP"
+
+// archiveTOC is the table of contents of a directory format dump
+const archiveTOC = "toc.dat"
+
+// ValidFormat reports whether format is a dump format
+func ValidFormat(format string) bool {
+	switch format {
+	case FormatPlain, FormatCustom, FormatDirectory:
+		return true
+	}
+	return false
+}
+
+// DumpFormat returns the dump format of the backup; backups made before
+// formats existed are plain
+func (m *BackupMetadata) DumpFormat() string {
+	if m.Format == "" {
+		return FormatPlain
+	}
+	return m.Format
+}
+
+// dumpExtension returns the extension of backups of a dump format
+func dumpExtension(format string) string {
+	switch format {
+	case FormatCustom:
+		return ".dump"
+	case FormatDirectory:
+		return ".tar"
+	}
+	return ".sql"
+}
+
+// dumpFormat returns the configured format of full backups
+func (bm *BackupManager) dumpFormat() string {
+	if bm.config.DumpFormat == "" {
+		return FormatPlain
+	}
+	return bm.config.DumpFormat
+}
+
+// parallelJobs returns the number of pg_dump and pg_restore workers
+func (bm *BackupManager) parallelJobs() int {
+	return max(bm.config.ParallelJobs, 1)
+}
+
+// sniffDumpFormat tells the dump format of a decrypted and decompressed
+// backup from its first bytes
+func sniffDumpFormat(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	header := make([]byte, 512)
+	n, err := io.ReadFull(file, header)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+	header = header[:n]
+	switch {
+	case bytes.HasPrefix(header, []byte(archiveMagic)):
+		return FormatCustom, nil
+	case len(header) >= 262 && string(header[257:262]) == "ustar":
+		return FormatDirectory, nil
+	}
+	return FormatPlain, nil
+}
+
+// archiveDumpArgs returns the pg_dump arguments writing an archive of the
+// database to outputPath
+func (bm *BackupManager) archiveDumpArgs(format, outputPath string) []string {
+	level := 0
+	if bm.config.CompressBackups {
+		level = bm.config.CompressionLevel
+	}
+	args := []string{
+		"--host=" + bm.dbConfig.DBHost,
+		"--port=" + bm.dbConfig.DBPort,
+		"--username=" + bm.dbConfig.DBUser,
+		"--format=" + format,
+		"--encoding=UTF8",
+		"--no-owner",
+		"--no-privileges",
+		"--compress=" + strconv.Itoa(level),
+		"--file=" + outputPath,
+	}
+	// pg_dump only dumps directory archives in parallel
+	if format == FormatDirectory {
+		args = append(args, "--jobs="+strconv.Itoa(bm.parallelJobs()))
+	}
+	return append(args, bm.dbConfig.DBName)
+}
+
+// executeArchiveBackup dumps the database as a custom or directory
+// archive. Directory archives are dumped next to outputPath and written to
+// it as a tar.
+func (bm *BackupManager) executeArchiveBackup(ctx context.Context, outputPath, format string) error {
+	dumpPath := outputPath
+	if format == FormatDirectory {
+		// pg_dump refuses to write into an existing directory, such as one
+		// left by a failed attempt
+		dumpPath = outputPath + ".d"
+		os.RemoveAll(dumpPath)
+		defer os.RemoveAll(dumpPath)
+	}
+
+	args := bm.archiveDumpArgs(format, dumpPath)
+	throttled := bm.ioLimiter(ctx).throttles()
+	if throttled && format == FormatDirectory {
+		// The workers write their own files, so only their number is limited
+		args = singleJob(args)
+	} else if throttled {
+		// Written to the file by dumpThrottled
+		args = slices.DeleteFunc(args, func(arg string) bool { return strings.HasPrefix(arg, "--file=") })
+	}
+	cmd, err := bm.tools.Command(ctx, util.ToolPgDump, args...)
+	if err != nil {
+		return fmt.Errorf("pg_dump command not found: %w", err)
+	}
+	cmd.Env = append(os.Environ(), "PGPASSWORD="+bm.dbConfig.DBPassword, "PGCLIENTENCODING=UTF8")
+	var output []byte
+	if throttled && format == FormatCustom {
+		output, err = bm.dumpThrottled(ctx, cmd, dumpPath)
+	} else {
+		output, err = cmd.CombinedOutput()
+	}
+	if err != nil {
+		return fmt.Errorf("pg_dump failed: %w, output: %s", err, string(output))
+	}
+
+	if format == FormatDirectory {
+		if err := tarDirectory(dumpPath, outputPath); err != nil {
+			return fmt.Errorf("failed to archive dump directory: %w", err)
+		}
+	}
+	return nil
+}
+
+// tarDirectory writes the files of a dump directory to a tar file
+func tarDirectory(dir, tarPath string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	out, err := os.Create(tarPath)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	writer := tar.NewWriter(out)
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		if err := addTarFile(writer, filepath.Join(dir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+func addTarFile(writer *tar.Writer, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	if err := writer.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err = io.Copy(writer, file)
+	return err
+}
+
+// untarDirectory extracts a dump directory written by tarDirectory. Only
+// plain files at the top of the archive are accepted.
+func untarDirectory(tarPath, dir string) error {
+	in, err := os.Open(tarPath)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	reader := tar.NewReader(in)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+		}
+		if header.Typeflag != tar.TypeReg || header.Name != filepath.Base(header.Name) || strings.HasPrefix(header.Name, ".") {
+			return fmt.Errorf("%w: unexpected entry %q", ErrInvalidArchive, header.Name)
+		}
+		if err := extractTarFile(reader, filepath.Join(dir, header.Name)); err != nil {
+			return err
+		}
+	}
+}
+
+func extractTarFile(reader io.Reader, path string) error {
+	out, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	if _, err := io.Copy(out, reader); err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+// validateArchive checks that a pg_dump archive can be given to pg_restore
+func validateArchive(path, format string) error {
+	if format != FormatDirectory {
+		// Custom archives were recognized by their header
+		return nil
+	}
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	reader := tar.NewReader(in)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return fmt.Errorf("%w: %s missing from directory dump", ErrInvalidArchive, archiveTOC)
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+		}
+		if header.Name == archiveTOC {
+			return nil
+		}
+	}
+}
+
+// openArchive returns what to give pg_restore for an archive backup: the
+// file of a custom archive, or the extracted directory of a directory one,
+// which is removed by the returned function
+func openArchive(path, format string) (string, func(), error) {
+	if format != FormatDirectory {
+		return path, func() {}, nil
+	}
+	dir := path + ".d"
+	os.RemoveAll(dir)
+	cleanup := func() { os.RemoveAll(dir) }
+	if err := untarDirectory(path, dir); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("failed to extract directory dump: %w", err)
+	}
+	return dir, cleanup, nil
+}
+
+// archiveRestoreArgs returns the pg_restore arguments restoring an archive
+// into a database of the server
+func (bm *BackupManager) archiveRestoreArgs(dbName, target string) []string {
+	return []string{
+		"--host=" + bm.dbConfig.DBHost,
+		"--port=" + bm.dbConfig.DBPort,
+		"--username=" + bm.dbConfig.DBUser,
+		"--dbname=" + dbName,
+		"--clean",
+		"--if-exists",
+		"--no-owner",
+		"--no-privileges",
+		"--jobs=" + strconv.Itoa(bm.parallelJobs()),
+		target,
+	}
+}
+
+// restoreArchive restores an archive backup with pg_restore and returns
+// its output. Like psql, pg_restore goes on after failing statements,
+// which are reported in the output. With a tracker pg_restore reports the
+// tables it loads.
+func (bm *BackupManager) restoreArchive(ctx context.Context, dbName, inputPath, format string, tracker *restoreTracker) (string, error) {
+	target, cleanup, err := openArchive(inputPath, format)
+	if err != nil {
+		return "", err
+	}
+	defer cleanup()
+
+	args := bm.archiveRestoreArgs(dbName, target)
+	if tracker != nil {
+		args = append([]string{"--verbose"}, args...)
+	}
+	throttled := bm.ioLimiter(ctx).throttles()
+	if throttled && format == FormatCustom {
+		// Fed through the throttle on standard input, which pg_restore
+		// cannot restore in parallel
+		args = slices.DeleteFunc(args[:len(args)-1], func(arg string) bool { return strings.HasPrefix(arg, "--jobs=") })
+	} else if throttled {
+		args = singleJob(args)
+	}
+	cmd, err := bm.tools.Command(ctx, util.ToolPgRestore, args...)
+	if err != nil {
+		return "", fmt.Errorf("pg_restore command not found: %w", err)
+	}
+	cmd.Env = append(os.Environ(), "PGPASSWORD="+bm.dbConfig.DBPassword, "PGCLIENTENCODING=UTF8")
+	if throttled && format == FormatCustom {
+		input, err := os.Open(target)
+		if err != nil {
+			return "", err
+		}
+		defer input.Close()
+		cmd.Stdin = bm.throttleReader(ctx, input)
+	}
+	output, err := combinedOutput(cmd, tracker)
+	if err != nil && !strings.Contains(string(output), "errors ignored on restore") {
+		return string(output), fmt.Errorf("pg_restore failed: %w, output: %s", err, string(output))
+	}
+	return string(output), nil
+}
+
+// readDump adds the tables and rows of a backup to contents. Archives are
+// read from the SQL script pg_restore turns them into.
+func (bm *BackupManager) readDump(ctx context.Context, contents *dumpContents, path string) error {
+	format, err := sniffDumpFormat(path)
+	if err != nil {
+		return err
+	}
+	if format == FormatPlain {
+		return contents.read(path)
+	}
+
+	target, cleanup, err := openArchive(path, format)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	cmd, err := bm.tools.Command(ctx, util.ToolPgRestore, "--file=-", target)
+	if err != nil {
+		return fmt.Errorf("pg_restore command not found: %w", err)
+	}
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	script, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	scanErr := contents.scan(script)
+	io.Copy(io.Discard, script) // Let pg_restore finish writing
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("pg_restore failed: %w, output: %s", err, stderr.String())
+	}
+	return scanErr
+}
@@ -0,0 +1,106 @@
+package backup
+
+import (
+	"archive/tar"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toeic-app/internal/config"
+)
+
+func TestDirectoryDumpArchive(t *testing.T) {
+	dir := t.TempDir()
+	bm := NewBackupManager(config.BackupConfig{BackupDir: dir}, config.Config{DBName: "toeic"})
+
+	// A directory dump as pg_dump writes it
+	dumpDir := filepath.Join(dir, "dump")
+	require.NoError(t, os.Mkdir(dumpDir, 0755))
+	files := map[string]string{archiveTOC: "PGDMP toc", "3001.dat.gz": "words", "3002.dat.gz": "users"}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dumpDir, name), []byte(content), 0644))
+	}
+
+	tarPath := filepath.Join(dir, "manual_backup_1.tar")
+	require.NoError(t, tarDirectory(dumpDir, tarPath))
+	format, err := sniffDumpFormat(tarPath)
+	require.NoError(t, err)
+	assert.Equal(t, FormatDirectory, format)
+	require.NoError(t, bm.validateBackup(tarPath))
+
+	target, cleanup, err := openArchive(tarPath, format)
+	require.NoError(t, err)
+	for name, content := range files {
+		data, err := os.ReadFile(filepath.Join(target, name))
+		require.NoError(t, err)
+		assert.Equal(t, content, string(data))
+	}
+	cleanup()
+	assert.NoDirExists(t, target)
+
+	// A tar without a table of contents is not a dump
+	require.NoError(t, os.Remove(filepath.Join(dumpDir, archiveTOC)))
+	require.NoError(t, tarDirectory(dumpDir, tarPath))
+	assert.ErrorIs(t, bm.validateBackup(tarPath), ErrInvalidArchive)
+}
+
+func TestDirectoryDumpArchiveRejectsPaths(t *testing.T) {
+	dir := t.TempDir()
+	tarPath := filepath.Join(dir, "evil.tar")
+	out, err := os.Create(tarPath)
+	require.NoError(t, err)
+	writer := tar.NewWriter(out)
+	require.NoError(t, writer.WriteHeader(&tar.Header{Name: "../toc.dat", Mode: 0644, Size: 1, Typeflag: tar.TypeReg}))
+	_, err = writer.Write([]byte("x"))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	require.NoError(t, out.Close())
+
+	_, _, err = openArchive(tarPath, FormatDirectory)
+	assert.ErrorIs(t, err, ErrInvalidArchive)
+	assert.NoFileExists(t, filepath.Join(dir, "toc.dat"))
+}
+
+func TestSniffDumpFormat(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		FormatCustom: "PGDMP\x01\x0f\x00\x04\x08\x01\x01",
+		FormatPlain:  "SET client_encoding = 'UTF8';\n",
+	} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		format, err := sniffDumpFormat(path)
+		require.NoError(t, err)
+		assert.Equal(t, name, format)
+	}
+}
+
+func TestArchiveArgs(t *testing.T) {
+	bm := NewBackupManager(config.BackupConfig{CompressBackups: true, CompressionLevel: 6, ParallelJobs: 4}, config.Config{DBName: "toeic"})
+
+	args := bm.archiveDumpArgs(FormatDirectory, "/backups/manual_backup_1.tar.tmp.d")
+	assert.Contains(t, args, "--format=directory")
+	assert.Contains(t, args, "--jobs=4")
+	assert.Contains(t, args, "--compress=6")
+	assert.Equal(t, "toeic", args[len(args)-1])
+	// pg_dump writes custom archives with a single worker
+	assert.NotContains(t, bm.archiveDumpArgs(FormatCustom, "/backups/manual_backup_1.dump.tmp"), "--jobs=4")
+
+	restore := bm.archiveRestoreArgs("toeic_verify", "/backups/manual_backup_1.dump")
+	assert.Contains(t, restore, "--dbname=toeic_verify")
+	assert.Contains(t, restore, "--jobs=4")
+	assert.Contains(t, restore, "--clean")
+
+	for name, valid := range map[string]bool{
+		"manual_backup_1.dump":     true,
+		"manual_backup_1.dump.enc": true,
+		"manual_backup_1.tar":      true,
+		"manual_backup_1.tar.enc":  true,
+		"manual_backup_1.tar.gz":   false,
+		"manual_backup_1.dump.gz":  false,
+	} {
+		assert.Equal(t, valid, bm.isValidBackupFilename(name), name)
+	}
+}
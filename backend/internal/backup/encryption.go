@@ -0,0 +1,508 @@
+package backup
+
+import (
+	"bufio"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/toeic-app/internal/config"
+)
+
+// Encrypted backups use envelope encryption. Every backup gets a random
+// AES-256 data key, which is wrapped by a master key from
+// BACKUP_ENCRYPTION_KEYS or by AWS KMS and stored in the file header.
+// Rotating the master key only rewrites the header.
+//
+// File format:
+//
+//	magic "TOEICBAK", version byte
+//	uint16 key ID length, key ID
+//	uint16 wrapped data key length, wrapped data key
+//	8 byte nonce prefix
+//	chunks: final flag byte, uint32 length, AES-GCM sealed chunk
+//
+// Each chunk is sealed with the nonce prefix and the chunk number as nonce
+// and the final flag as additional data, so reordered or truncated files
+// fail to decrypt.
+const (
+	encryptionMagic   = "TOEICBAK"
+	encryptionVersion = 1
+	encryptionChunk   = 64 * 1024
+	kmsKeyPrefix      = "kms:"
+)
+
+var (
+	// ErrUnknownKey is returned for a backup wrapped with a master key that
+	// is not configured
+	ErrUnknownKey = errors.New("unknown backup encryption key")
+	// ErrNotEncrypted is returned for a file without the encrypted backup header
+	ErrNotEncrypted = errors.New("not an encrypted backup")
+	// ErrCorruptBackup is returned when an encrypted backup fails authentication
+	ErrCorruptBackup = errors.New("encrypted backup is corrupt or was modified")
+)
+
+// Keyring wraps and unwraps backup data keys with the configured master
+// keys
+type Keyring struct {
+	keys     map[string][]byte
+	activeID string
+	kms      *kmsClient // Set when BACKUP_KMS_KEY_ID is configured
+}
+
+// NewKeyring creates a keyring from the backup configuration. It returns
+// nil when no master key is configured.
+func NewKeyring(cfg config.BackupConfig) (*Keyring, error) {
+	ring := &Keyring{keys: map[string][]byte{}, activeID: cfg.EncryptionActiveKeyID}
+	for _, pair := range strings.Split(cfg.EncryptionKeys, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		keyID, hexKey, ok := strings.Cut(pair, ":")
+		keyID = strings.TrimSpace(keyID)
+		if !ok || keyID == "" || strings.HasPrefix(keyID, kmsKeyPrefix) {
+			return nil, fmt.Errorf("backup encryption key must be in KEY_ID:HEX_KEY format")
+		}
+		key, err := hex.DecodeString(strings.TrimSpace(hexKey))
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("backup encryption key %q must be 64 hex characters (32 bytes)", keyID)
+		}
+		ring.keys[keyID] = key
+	}
+
+	if cfg.KMSConfig != nil {
+		client, err := newKMSClient(*cfg.KMSConfig)
+		if err != nil {
+			return nil, err
+		}
+		ring.kms = client
+		ring.activeID = kmsKeyPrefix + cfg.KMSConfig.KeyID
+		return ring, nil
+	}
+	if len(ring.keys) == 0 {
+		return nil, nil
+	}
+	if _, ok := ring.keys[ring.activeID]; !ok {
+		return nil, fmt.Errorf("BACKUP_ENCRYPTION_ACTIVE_KEY_ID %q is not in BACKUP_ENCRYPTION_KEYS", ring.activeID)
+	}
+	return ring, nil
+}
+
+// ActiveKeyID returns the ID of the master key wrapping new data keys
+func (k *Keyring) ActiveKeyID() string {
+	return k.activeID
+}
+
+func (k *Keyring) wrap(ctx context.Context, dataKey []byte) (string, []byte, error) {
+	if k.kms != nil {
+		wrapped, err := k.kms.encrypt(ctx, dataKey)
+		return k.activeID, wrapped, err
+	}
+	wrapped, err := sealKey(k.keys[k.activeID], dataKey)
+	return k.activeID, wrapped, err
+}
+
+func (k *Keyring) unwrap(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	if strings.HasPrefix(keyID, kmsKeyPrefix) {
+		if k.kms == nil {
+			return nil, fmt.Errorf("%w: %s needs BACKUP_KMS_KEY_ID", ErrUnknownKey, keyID)
+		}
+		return k.kms.decrypt(ctx, wrapped)
+	}
+	key, ok := k.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, keyID)
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(wrapped) < gcm.NonceSize() {
+		return nil, ErrCorruptBackup
+	}
+	dataKey, err := gcm.Open(nil, wrapped[:gcm.NonceSize()], wrapped[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key with %s: %w", keyID, ErrCorruptBackup)
+	}
+	return dataKey, nil
+}
+
+// sealKey encrypts a data key with AES-256-GCM and returns nonce|ciphertext
+func sealKey(key, dataKey []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, dataKey, nil), nil
+}
+
+// encryptionHeader starts every encrypted backup
+type encryptionHeader struct {
+	KeyID       string
+	WrappedKey  []byte
+	NoncePrefix [8]byte
+}
+
+func (h encryptionHeader) write(w io.Writer) error {
+	buf := []byte(encryptionMagic)
+	buf = append(buf, encryptionVersion)
+	buf = binary.BigEndian.AppendUint16(buf, uint16(len(h.KeyID)))
+	buf = append(buf, h.KeyID...)
+	buf = binary.BigEndian.AppendUint16(buf, uint16(len(h.WrappedKey)))
+	buf = append(buf, h.WrappedKey...)
+	buf = append(buf, h.NoncePrefix[:]...)
+	_, err := w.Write(buf)
+	return err
+}
+
+func readEncryptionHeader(r io.Reader) (encryptionHeader, error) {
+	var h encryptionHeader
+	magic := make([]byte, len(encryptionMagic)+1)
+	if _, err := io.ReadFull(r, magic); err != nil || string(magic[:len(encryptionMagic)]) != encryptionMagic {
+		return h, ErrNotEncrypted
+	}
+	if magic[len(encryptionMagic)] != encryptionVersion {
+		return h, fmt.Errorf("unsupported encrypted backup version %d", magic[len(encryptionMagic)])
+	}
+	keyID, err := readField(r)
+	if err != nil {
+		return h, err
+	}
+	h.KeyID = string(keyID)
+	if h.WrappedKey, err = readField(r); err != nil {
+		return h, err
+	}
+	if _, err := io.ReadFull(r, h.NoncePrefix[:]); err != nil {
+		return h, ErrCorruptBackup
+	}
+	return h, nil
+}
+
+// readField reads a uint16 length prefixed field
+func readField(r io.Reader) ([]byte, error) {
+	var size uint16
+	if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+		return nil, ErrCorruptBackup
+	}
+	field := make([]byte, size)
+	if _, err := io.ReadFull(r, field); err != nil {
+		return nil, ErrCorruptBackup
+	}
+	return field, nil
+}
+
+func chunkNonce(prefix [8]byte, counter uint32) []byte {
+	nonce := make([]byte, 12)
+	copy(nonce, prefix[:])
+	binary.BigEndian.PutUint32(nonce[8:], counter)
+	return nonce
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// encryptFile encrypts sourcePath into destPath with a new data key and
+// returns the ID of the master key that wrapped it
+func (bm *BackupManager) encryptFile(ctx context.Context, sourcePath, destPath string) (string, error) {
+	if bm.keyring == nil {
+		return "", fmt.Errorf("backup encryption keys are not configured")
+	}
+
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", fmt.Errorf("failed to generate data key: %w", err)
+	}
+	header := encryptionHeader{}
+	if _, err := rand.Read(header.NoncePrefix[:]); err != nil {
+		return "", err
+	}
+	keyID, wrapped, err := bm.keyring.wrap(ctx, dataKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to wrap data key: %w", err)
+	}
+	header.KeyID, header.WrappedKey = keyID, wrapped
+	gcm, err := newGCM(dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	source, err := os.Open(sourcePath)
+	if err != nil {
+		return "", err
+	}
+	defer source.Close()
+	dest, err := os.Create(destPath)
+	if err != nil {
+		return "", err
+	}
+	defer dest.Close()
+
+	writer := bufio.NewWriter(dest)
+	if err := header.write(writer); err != nil {
+		return "", err
+	}
+
+	reader := bufio.NewReaderSize(source, encryptionChunk)
+	chunk := make([]byte, encryptionChunk)
+	var sealed []byte
+	for counter := uint32(0); ; counter++ {
+		n, err := io.ReadFull(reader, chunk)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return "", err
+		}
+		// A short read is the last chunk, unless the file ends exactly on
+		// a chunk boundary, in which case the next read finds nothing
+		final := n < encryptionChunk
+		if !final {
+			if _, peekErr := reader.Peek(1); peekErr == io.EOF {
+				final = true
+			}
+		}
+		flag := []byte{0}
+		if final {
+			flag[0] = 1
+		}
+		sealed = gcm.Seal(sealed[:0], chunkNonce(header.NoncePrefix, counter), chunk[:n], flag)
+		frame := binary.BigEndian.AppendUint32(flag, uint32(len(sealed)))
+		if _, err := writer.Write(frame); err != nil {
+			return "", err
+		}
+		if _, err := writer.Write(sealed); err != nil {
+			return "", err
+		}
+		if final {
+			break
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return "", err
+	}
+	return keyID, dest.Close()
+}
+
+// decryptFile decrypts an encrypted backup into destPath
+func (bm *BackupManager) decryptFile(ctx context.Context, sourcePath, destPath string) error {
+	source, err := os.Open(sourcePath)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+	reader := bufio.NewReaderSize(source, encryptionChunk+5)
+
+	header, err := readEncryptionHeader(reader)
+	if err != nil {
+		return err
+	}
+	if bm.keyring == nil {
+		return fmt.Errorf("%w: %s (no backup encryption keys configured)", ErrUnknownKey, header.KeyID)
+	}
+	dataKey, err := bm.keyring.unwrap(ctx, header.KeyID, header.WrappedKey)
+	if err != nil {
+		return err
+	}
+	gcm, err := newGCM(dataKey)
+	if err != nil {
+		return err
+	}
+
+	dest, err := os.Create(destPath)
+	if err != nil {
+		return err
+	}
+	defer dest.Close()
+	writer := bufio.NewWriter(dest)
+
+	frame := make([]byte, 5)
+	sealed := make([]byte, encryptionChunk+gcm.Overhead())
+	var plain []byte
+	for counter := uint32(0); ; counter++ {
+		if _, err := io.ReadFull(reader, frame); err != nil {
+			// The file ended before the final chunk
+			return ErrCorruptBackup
+		}
+		size := binary.BigEndian.Uint32(frame[1:])
+		if size > uint32(len(sealed)) {
+			return ErrCorruptBackup
+		}
+		if _, err := io.ReadFull(reader, sealed[:size]); err != nil {
+			return ErrCorruptBackup
+		}
+		plain, err = gcm.Open(plain[:0], chunkNonce(header.NoncePrefix, counter), sealed[:size], frame[:1])
+		if err != nil {
+			return ErrCorruptBackup
+		}
+		if _, err := writer.Write(plain); err != nil {
+			return err
+		}
+		if frame[0] == 1 {
+			break
+		}
+	}
+	if _, err := reader.ReadByte(); err != io.EOF {
+		return ErrCorruptBackup
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	return dest.Close()
+}
+
+// encryptionKeyID returns the ID of the master key that wrapped the data key
+// of an encrypted backup
+func encryptionKeyID(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	header, err := readEncryptionHeader(bufio.NewReader(file))
+	if err != nil {
+		return "", err
+	}
+	return header.KeyID, nil
+}
+
+// rewrapFile wraps the data key of an encrypted backup with the active
+// master key. Only the header changes; it reports false when the active key
+// already wrapped it.
+func (bm *BackupManager) rewrapFile(ctx context.Context, path string) (bool, error) {
+	if bm.keyring == nil {
+		return false, fmt.Errorf("backup encryption keys are not configured")
+	}
+	source, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer source.Close()
+	reader := bufio.NewReader(source)
+
+	header, err := readEncryptionHeader(reader)
+	if err != nil {
+		return false, err
+	}
+	if header.KeyID == bm.keyring.ActiveKeyID() {
+		return false, nil
+	}
+	dataKey, err := bm.keyring.unwrap(ctx, header.KeyID, header.WrappedKey)
+	if err != nil {
+		return false, err
+	}
+	if header.KeyID, header.WrappedKey, err = bm.keyring.wrap(ctx, dataKey); err != nil {
+		return false, fmt.Errorf("failed to wrap data key: %w", err)
+	}
+
+	tmp := path + ".rewrap"
+	dest, err := os.Create(tmp)
+	if err != nil {
+		return false, err
+	}
+	if err := header.write(dest); err == nil {
+		_, err = io.Copy(dest, reader)
+	}
+	if closeErr := dest.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return false, err
+	}
+	source.Close()
+	return true, os.Rename(tmp, path)
+}
+
+// RotatedBackup is a backup whose data key RotateKeys rewrapped
+type RotatedBackup struct {
+	Filename string `json:"filename"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+}
+
+// RotateKeys wraps the data keys of encrypted backups that were wrapped
+// with an older master key with the active one. Only the file headers are
+// rewritten. With dryRun the backups are only listed.
+func (bm *BackupManager) RotateKeys(ctx context.Context, dryRun bool) ([]RotatedBackup, error) {
+	if bm.keyring == nil {
+		return nil, fmt.Errorf("backup encryption keys are not configured")
+	}
+	files, err := bm.ListBackups(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rotated := []RotatedBackup{}
+	for _, file := range files {
+		if !strings.HasSuffix(file.Name, ".enc") {
+			continue
+		}
+		path, downloaded, err := bm.localCopy(ctx, file.Name)
+		if err != nil {
+			return rotated, fmt.Errorf("%s: %w", file.Name, err)
+		}
+		keyID, err := encryptionKeyID(path)
+		if err == nil && keyID != bm.keyring.ActiveKeyID() && !dryRun {
+			err = bm.rotateFile(ctx, file.Name, path)
+		}
+		if downloaded && !bm.config.KeepLocalCopy {
+			bm.removeLocal(file.Name)
+		}
+		if err != nil {
+			return rotated, fmt.Errorf("%s: %w", file.Name, err)
+		}
+		if keyID != bm.keyring.ActiveKeyID() {
+			rotated = append(rotated, RotatedBackup{Filename: file.Name, From: keyID, To: bm.keyring.ActiveKeyID()})
+		}
+	}
+	return rotated, nil
+}
+
+// rotateFile rewraps a local backup and its media assets, records the new
+// key in its metadata, writes its manifest again and stores them all
+func (bm *BackupManager) rotateFile(ctx context.Context, filename, path string) error {
+	if _, err := bm.rewrapFile(ctx, path); err != nil {
+		return err
+	}
+	// Media assets are encrypted with the backup
+	if manifest, err := readAssetManifest(path); err == nil && manifest.Encrypted {
+		if _, err := bm.rewrapFile(ctx, path+assetArchiveSuffix); err != nil {
+			return fmt.Errorf("failed to rewrap media assets: %w", err)
+		}
+	}
+	if metadata, err := bm.loadBackupMetadata(filename); err == nil {
+		metadata.KeyID = bm.keyring.ActiveKeyID()
+		if info, err := os.Stat(path); err == nil {
+			metadata.Size = info.Size()
+		}
+		if err := bm.saveBackupMetadata(metadata); err != nil {
+			return fmt.Errorf("failed to save metadata: %w", err)
+		}
+	}
+	// The rewrapped file no longer matches the manifest
+	if err := bm.writeManifest(filename); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	if !bm.isRemote() {
+		return nil
+	}
+	if err := bm.storage.Upload(ctx, path, filename); err != nil {
+		return err
+	}
+	return bm.storeSidecars(ctx, filename)
+}
@@ -0,0 +1,234 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toeic-app/internal/config"
+)
+
+const (
+	oldTestKey = "2024:" + "00112233445566778899aabbccddeeff00112233445566778899aabbccddeeff"
+	newTestKey = "2025:" + "ffeeddccbbaa99887766554433221100ffeeddccbbaa99887766554433221100"
+)
+
+func newEncryptingManager(t *testing.T, dir, keys, activeID string) *BackupManager {
+	t.Helper()
+	cfg := config.BackupConfig{BackupDir: dir, EncryptBackups: true, EncryptionKeys: keys, EncryptionActiveKeyID: activeID}
+	keyring, err := NewKeyring(cfg)
+	require.NoError(t, err)
+	require.NotNil(t, keyring)
+	bm := NewBackupManager(cfg, config.Config{DBName: "toeic"})
+	require.NotNil(t, bm.keyring)
+	return bm
+}
+
+func TestEncryptedBackupRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	bm := newEncryptingManager(t, dir, oldTestKey, "2024")
+	ctx := context.Background()
+
+	// Empty files and files ending on a chunk boundary have a final chunk too
+	for _, size := range []int{0, 100, encryptionChunk, 2*encryptionChunk + 7} {
+		plain := bytes.Repeat([]byte("INSERT;\n"), size/8+1)[:size]
+		source := filepath.Join(dir, "plain.sql")
+		require.NoError(t, os.WriteFile(source, plain, 0644))
+
+		keyID, err := bm.encryptFile(ctx, source, source+".enc")
+		require.NoError(t, err)
+		assert.Equal(t, "2024", keyID)
+		encrypted, err := os.ReadFile(source + ".enc")
+		require.NoError(t, err)
+		assert.True(t, bytes.HasPrefix(encrypted, []byte(encryptionMagic)))
+		if size > 0 {
+			assert.NotContains(t, string(encrypted), "INSERT")
+		}
+
+		require.NoError(t, bm.decryptFile(ctx, source+".enc", source+".out"))
+		decrypted, err := os.ReadFile(source + ".out")
+		require.NoError(t, err)
+		assert.Equal(t, plain, decrypted, "size %d", size)
+	}
+}
+
+func TestEncryptedBackupRejectsTampering(t *testing.T) {
+	dir := t.TempDir()
+	bm := newEncryptingManager(t, dir, oldTestKey, "2024")
+	ctx := context.Background()
+
+	source := filepath.Join(dir, "plain.sql")
+	require.NoError(t, os.WriteFile(source, bytes.Repeat([]byte("SET x = 1;\n"), 10000), 0644))
+	_, err := bm.encryptFile(ctx, source, source+".enc")
+	require.NoError(t, err)
+	encrypted, err := os.ReadFile(source + ".enc")
+	require.NoError(t, err)
+
+	tampered := append([]byte{}, encrypted...)
+	tampered[len(tampered)-20] ^= 1
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tampered.enc"), tampered, 0644))
+	assert.ErrorIs(t, bm.decryptFile(ctx, filepath.Join(dir, "tampered.enc"), filepath.Join(dir, "out")), ErrCorruptBackup)
+
+	// Dropping the final chunk must not pass for a shorter backup
+	truncated := encrypted[:len(encrypted)-1000]
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "truncated.enc"), truncated, 0644))
+	assert.ErrorIs(t, bm.decryptFile(ctx, filepath.Join(dir, "truncated.enc"), filepath.Join(dir, "out")), ErrCorruptBackup)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "plain.enc"), []byte("SET x = 1;\n"), 0644))
+	assert.ErrorIs(t, bm.decryptFile(ctx, filepath.Join(dir, "plain.enc"), filepath.Join(dir, "out")), ErrNotEncrypted)
+
+	other := newEncryptingManager(t, dir, newTestKey, "2025")
+	assert.ErrorIs(t, other.decryptFile(ctx, source+".enc", filepath.Join(dir, "out")), ErrUnknownKey)
+}
+
+func TestNewKeyringValidatesKeys(t *testing.T) {
+	keyring, err := NewKeyring(config.BackupConfig{})
+	require.NoError(t, err)
+	assert.Nil(t, keyring)
+
+	_, err = NewKeyring(config.BackupConfig{EncryptionKeys: "2024:abcd", EncryptionActiveKeyID: "2024"})
+	assert.Error(t, err)
+	_, err = NewKeyring(config.BackupConfig{EncryptionKeys: oldTestKey, EncryptionActiveKeyID: "2025"})
+	assert.Error(t, err)
+}
+
+func TestRotateKeysRewrapsOlderBackups(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	old := newEncryptingManager(t, dir, oldTestKey, "2024")
+	old.config.CompressBackups = true
+
+	sql := []byte("SET client_encoding = 'UTF8';\nCREATE TABLE words (id int);\n")
+	tempPath := filepath.Join(dir, "manual_backup_1.sql.tmp")
+	require.NoError(t, os.WriteFile(tempPath, sql, 0644))
+	finalPath, processed, err := old.processBackup(ctx, tempPath, filepath.Join(dir, "manual_backup_1.sql"), FormatPlain)
+	require.NoError(t, err)
+	assert.Equal(t, "manual_backup_1.sql.gz.enc", filepath.Base(finalPath))
+	assert.True(t, processed.Compressed)
+	assert.True(t, processed.Encrypted)
+	checksum, err := old.calculateChecksum(tempPath)
+	require.NoError(t, err)
+	require.NoError(t, old.saveBackupMetadata(&BackupMetadata{Filename: "manual_backup_1.sql.gz.enc", Checksum: checksum, KeyID: processed.KeyID}))
+	require.NoError(t, old.writeManifest("manual_backup_1.sql.gz.enc"))
+
+	// The new key is added and made active, the old one is still known
+	bm := newEncryptingManager(t, dir, oldTestKey+","+newTestKey, "2025")
+	report, err := bm.ValidateBackup(ctx, "manual_backup_1.sql.gz.enc")
+	require.NoError(t, err)
+	assert.True(t, report.Valid, report.Issues)
+	assert.True(t, report.Encrypted)
+	assert.True(t, report.ChecksumMatch)
+	assert.True(t, report.ManifestMatch)
+	assert.Equal(t, "2024", report.KeyID)
+	require.Len(t, report.Warnings, 1)
+	assert.Contains(t, report.Warnings[0], "rotate-keys")
+
+	rotated, err := bm.RotateKeys(ctx, true)
+	require.NoError(t, err)
+	assert.Equal(t, []RotatedBackup{{Filename: "manual_backup_1.sql.gz.enc", From: "2024", To: "2025"}}, rotated)
+	keyID, err := encryptionKeyID(finalPath)
+	require.NoError(t, err)
+	assert.Equal(t, "2024", keyID, "a dry run leaves the backup alone")
+
+	_, err = bm.RotateKeys(ctx, false)
+	require.NoError(t, err)
+	rotated, err = bm.RotateKeys(ctx, true)
+	require.NoError(t, err)
+	assert.Empty(t, rotated)
+	metadata, err := bm.loadBackupMetadata("manual_backup_1.sql.gz.enc")
+	require.NoError(t, err)
+	assert.Equal(t, "2025", metadata.KeyID)
+
+	// Once rotated the old key can be removed; the manifest was rewritten
+	// with the backup
+	current := newEncryptingManager(t, dir, newTestKey, "2025")
+	report, err = current.ValidateBackup(ctx, "manual_backup_1.sql.gz.enc")
+	require.NoError(t, err)
+	assert.True(t, report.Valid, report.Issues)
+	assert.True(t, report.ManifestMatch)
+	assert.Empty(t, report.Warnings)
+
+	sqlPath, err := current.preprocessBackup(ctx, finalPath)
+	require.NoError(t, err)
+	restored, err := os.ReadFile(sqlPath)
+	require.NoError(t, err)
+	assert.Equal(t, sql, restored)
+}
+
+// fakeKMS wraps data keys by prefixing them, like a KMS key would
+type fakeKMS struct {
+	targets []string
+}
+
+func (f *fakeKMS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.targets = append(f.targets, r.Header.Get("X-Amz-Target"))
+	if !strings.Contains(r.Header.Get("Authorization"), "/us-east-1/kms/aws4_request") {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"__type":"IncompleteSignatureException","message":"bad signature"}`))
+		return
+	}
+	var req struct {
+		KeyId          string
+		Plaintext      []byte
+		CiphertextBlob []byte
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	switch r.Header.Get("X-Amz-Target") {
+	case "TrentService.Encrypt":
+		json.NewEncoder(w).Encode(map[string][]byte{"CiphertextBlob": append([]byte(req.KeyId+"|"), req.Plaintext...)})
+	case "TrentService.Decrypt":
+		_, plain, ok := bytes.Cut(req.CiphertextBlob, []byte("|"))
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"__type":"InvalidCiphertextException","message":"bad blob"}`))
+			return
+		}
+		json.NewEncoder(w).Encode(map[string][]byte{"Plaintext": plain})
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func TestKMSWrappedBackup(t *testing.T) {
+	kms := &fakeKMS{}
+	server := httptest.NewServer(kms)
+	defer server.Close()
+
+	dir := t.TempDir()
+	ctx := context.Background()
+	bm := NewBackupManager(config.BackupConfig{BackupDir: dir, EncryptBackups: true, KMSConfig: &config.KMSBackupConfig{
+		KeyID:           "alias/backups",
+		Region:          "us-east-1",
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "secret",
+		Endpoint:        server.URL,
+	}}, config.Config{})
+	require.NotNil(t, bm.keyring)
+
+	source := filepath.Join(dir, "plain.sql")
+	require.NoError(t, os.WriteFile(source, []byte("SET x = 1;\n"), 0644))
+	keyID, err := bm.encryptFile(ctx, source, source+".enc")
+	require.NoError(t, err)
+	assert.Equal(t, "kms:alias/backups", keyID)
+
+	require.NoError(t, bm.decryptFile(ctx, source+".enc", source+".out"))
+	decrypted, err := os.ReadFile(source + ".out")
+	require.NoError(t, err)
+	assert.Equal(t, "SET x = 1;\n", string(decrypted))
+	assert.Equal(t, []string{"TrentService.Encrypt", "TrentService.Decrypt"}, kms.targets)
+
+	// Backups wrapped with KMS need it configured to be restored
+	local := newEncryptingManager(t, dir, oldTestKey, "2024")
+	assert.ErrorIs(t, local.decryptFile(ctx, source+".enc", source+".out"), ErrUnknownKey)
+}
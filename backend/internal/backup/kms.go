@@ -0,0 +1,108 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/toeic-app/internal/config"
+)
+
+// kmsClient wraps backup data keys with an AWS KMS key through the KMS
+// JSON API
+type kmsClient struct {
+	cfg      config.KMSBackupConfig
+	endpoint string
+	client   *http.Client
+	now      func() time.Time
+}
+
+func newKMSClient(cfg config.KMSBackupConfig) (*kmsClient, error) {
+	if cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, fmt.Errorf("AWS access key and secret key are required for BACKUP_KMS_KEY_ID")
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://kms.%s.amazonaws.com", cfg.Region)
+	}
+	return &kmsClient{
+		cfg:      cfg,
+		endpoint: strings.TrimRight(endpoint, "/") + "/",
+		client:   &http.Client{Timeout: 30 * time.Second},
+		now:      time.Now,
+	}, nil
+}
+
+// encrypt wraps a data key with the configured KMS key
+func (c *kmsClient) encrypt(ctx context.Context, dataKey []byte) ([]byte, error) {
+	var resp struct {
+		CiphertextBlob []byte `json:"CiphertextBlob"`
+	}
+	err := c.call(ctx, "Encrypt", map[string]interface{}{"KeyId": c.cfg.KeyID, "Plaintext": dataKey}, &resp)
+	return resp.CiphertextBlob, err
+}
+
+// decrypt unwraps a data key; the wrapped key names the KMS key itself
+func (c *kmsClient) decrypt(ctx context.Context, wrapped []byte) ([]byte, error) {
+	var resp struct {
+		Plaintext []byte `json:"Plaintext"`
+	}
+	err := c.call(ctx, "Decrypt", map[string]interface{}{"CiphertextBlob": wrapped}, &resp)
+	return resp.Plaintext, err
+}
+
+// call sends a signed request for a KMS action. []byte fields are sent and
+// received base64 encoded, as KMS expects.
+func (c *kmsClient) call(ctx context.Context, action string, body, out interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "TrentService."+action)
+	hash := sha256.Sum256(payload)
+	signV4(req, hex.EncodeToString(hash[:]), awsCredentials{
+		AccessKeyID:     c.cfg.AccessKeyID,
+		SecretAccessKey: c.cfg.SecretAccessKey,
+		Region:          c.cfg.Region,
+		Service:         "kms",
+	}, c.now())
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("KMS %s failed: %w", action, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return fmt.Errorf("KMS %s failed: %w", action, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Type != "" {
+			return fmt.Errorf("KMS %s failed with status %d: %s: %s", action, resp.StatusCode, apiErr.Type, apiErr.Message)
+		}
+		return fmt.Errorf("KMS %s failed with status %d", action, resp.StatusCode)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode KMS %s response: %w", action, err)
+	}
+	return nil
+}
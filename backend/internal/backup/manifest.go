@@ -0,0 +1,225 @@
+package backup
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/toeic-app/internal/logger"
+)
+
+// Every backup is written with a manifest, <backup>.manifest, holding the
+// size and SHA-256 of each of its files as stored: the backup, its
+// metadata and its media assets. With BACKUP_MANIFEST_KEY the manifest is signed with
+// HMAC-SHA256, so that a backup rewritten together with its manifest is
+// caught too. Manifests are checked when a backup is validated or
+// restored, and periodically by SimpleBackupMonitor.
+const (
+	manifestSuffix  = ".manifest"
+	manifestVersion = 1
+)
+
+// sidecarSuffixes are the files kept next to a backup, and copied, moved
+// and deleted with it
+var sidecarSuffixes = []string{".meta", manifestSuffix, assetsSuffix, assetArchiveSuffix}
+
+// manifestSuffixes are the sidecars listed in the manifest
+var manifestSuffixes = []string{".meta", assetsSuffix, assetArchiveSuffix}
+
+var (
+	// ErrManifestNotFound is returned for a backup written without a manifest
+	ErrManifestNotFound = errors.New("backup manifest not found")
+	// ErrManifestMismatch is returned when the files of a backup differ
+	// from its manifest
+	ErrManifestMismatch = errors.New("backup does not match its manifest")
+	// ErrManifestSignature is returned for a manifest whose signature does
+	// not verify with BACKUP_MANIFEST_KEY
+	ErrManifestSignature = errors.New("backup manifest signature is invalid")
+)
+
+// Manifest lists the files of a backup with their checksums
+type Manifest struct {
+	Version   int            `json:"version"`
+	Backup    string         `json:"backup"`
+	CreatedAt time.Time      `json:"created_at"`
+	Files     []ManifestFile `json:"files"`
+	// Signature is the hex HMAC-SHA256 of the manifest without signature
+	Signature string `json:"signature,omitempty"`
+}
+
+// ManifestFile is a file of a backup as stored
+type ManifestFile struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// sign returns the signature of the manifest with key
+func (m Manifest) sign(key string) (string, error) {
+	m.Signature = ""
+	data, err := json.Marshal(m)
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// writeManifest writes the manifest of a backup in BackupDir, after its
+// metadata was saved
+func (bm *BackupManager) writeManifest(filename string) error {
+	manifest := Manifest{Version: manifestVersion, Backup: filename, CreatedAt: time.Now()}
+	names := []string{filename}
+	for _, suffix := range manifestSuffixes {
+		names = append(names, filename+suffix)
+	}
+	for _, name := range names {
+		path := filepath.Join(bm.config.BackupDir, name)
+		info, err := os.Stat(path)
+		if os.IsNotExist(err) && name != filename {
+			continue // Backups are kept without metadata when it failed to save, and without assets
+		}
+		if err != nil {
+			return err
+		}
+		checksum, err := fileSHA256(path)
+		if err != nil {
+			return err
+		}
+		manifest.Files = append(manifest.Files, ManifestFile{Name: name, Size: info.Size(), SHA256: checksum})
+	}
+
+	if bm.config.ManifestKey != "" {
+		signature, err := manifest.sign(bm.config.ManifestKey)
+		if err != nil {
+			return err
+		}
+		manifest.Signature = signature
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	return os.WriteFile(filepath.Join(bm.config.BackupDir, filename+manifestSuffix), data, 0644)
+}
+
+// checkManifest checks the local copy of a backup at path against its
+// manifest. It reports whether the signature was verified: manifests
+// written without BACKUP_MANIFEST_KEY, or read without it, are only
+// checked for corruption.
+func (bm *BackupManager) checkManifest(path string) (bool, error) {
+	data, err := os.ReadFile(path + manifestSuffix)
+	if os.IsNotExist(err) {
+		return false, ErrManifestNotFound
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return false, fmt.Errorf("%w: unreadable manifest: %v", ErrManifestMismatch, err)
+	}
+
+	filename := filepath.Base(path)
+	signed := false
+	if manifest.Signature != "" && bm.config.ManifestKey != "" {
+		signature, err := manifest.sign(bm.config.ManifestKey)
+		if err != nil {
+			return false, err
+		}
+		if !hmac.Equal([]byte(signature), []byte(manifest.Signature)) {
+			return false, ErrManifestSignature
+		}
+		signed = true
+	}
+	if manifest.Backup != filename {
+		return signed, fmt.Errorf("%w: manifest is for %s", ErrManifestMismatch, manifest.Backup)
+	}
+
+	var problems []string
+	listed := false
+	for _, file := range manifest.Files {
+		// Only the backup and its sidecars are listed
+		if file.Name != filename && !slices.ContainsFunc(manifestSuffixes, func(suffix string) bool { return file.Name == filename+suffix }) {
+			problems = append(problems, fmt.Sprintf("%s is not a file of the backup", file.Name))
+			continue
+		}
+		listed = listed || file.Name == filename
+		filePath := filepath.Join(filepath.Dir(path), file.Name)
+		info, err := os.Stat(filePath)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s is missing", file.Name))
+			continue
+		}
+		if info.Size() != file.Size {
+			problems = append(problems, fmt.Sprintf("%s is %d bytes, expected %d", file.Name, info.Size(), file.Size))
+			continue
+		}
+		checksum, err := fileSHA256(filePath)
+		if err != nil {
+			return signed, fmt.Errorf("failed to calculate checksum of %s: %w", file.Name, err)
+		}
+		if checksum != file.SHA256 {
+			problems = append(problems, fmt.Sprintf("%s checksum mismatch", file.Name))
+		}
+	}
+	if !listed {
+		problems = append(problems, fmt.Sprintf("%s is not listed", filename))
+	}
+	if len(problems) > 0 {
+		return signed, fmt.Errorf("%w: %s", ErrManifestMismatch, strings.Join(problems, "; "))
+	}
+	return signed, nil
+}
+
+// VerifyManifest checks a stored backup against its manifest, downloading
+// it from remote storage when there is no local copy. It reports whether
+// the manifest signature was verified, and returns ErrManifestNotFound for
+// a backup without manifest, ErrManifestMismatch for a corrupted one and
+// ErrManifestSignature for a tampered manifest.
+func (bm *BackupManager) VerifyManifest(ctx context.Context, filename string) (bool, error) {
+	if !bm.isValidBackupFilename(filename) {
+		return false, fmt.Errorf("invalid backup filename")
+	}
+	path, downloaded, err := bm.localCopy(ctx, filename)
+	if err != nil {
+		return false, err
+	}
+	if downloaded && !bm.config.KeepLocalCopy {
+		defer bm.removeLocal(filename)
+	}
+	return bm.checkManifest(path)
+}
+
+// storeSidecars uploads the sidecars of a local backup to remote storage
+func (bm *BackupManager) storeSidecars(ctx context.Context, filename string) error {
+	path := filepath.Join(bm.config.BackupDir, filename)
+	for _, suffix := range sidecarSuffixes {
+		if _, err := os.Stat(path + suffix); err != nil {
+			continue
+		}
+		if err := bm.storage.Upload(ctx, path+suffix, filename+suffix); err != nil {
+			return fmt.Errorf("failed to upload %s: %w", filename+suffix, err)
+		}
+	}
+	return nil
+}
+
+// deleteSidecars deletes the sidecars of a backup from a store
+func deleteSidecars(ctx context.Context, store Storage, filename string) {
+	for _, suffix := range sidecarSuffixes {
+		if err := store.Delete(ctx, filename+suffix); err != nil && !errors.Is(err, ErrBackupNotFound) {
+			logger.Warn("Failed to delete %s of %s backup %s: %v", suffix, store.Type(), filename, err)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toeic-app/internal/config"
+)
+
+const testManifestKey = "0123456789abcdef0123456789abcdef"
+
+// writeTestBackup writes a plain backup with its metadata and manifest
+func writeTestBackup(t *testing.T, bm *BackupManager, filename string) string {
+	t.Helper()
+	path := filepath.Join(bm.config.BackupDir, filename)
+	require.NoError(t, os.WriteFile(path, []byte("SET client_encoding = 'UTF8';\nCREATE TABLE words (id int);\n"), 0644))
+	checksum, err := bm.calculateChecksum(path)
+	require.NoError(t, err)
+	require.NoError(t, bm.saveBackupMetadata(&BackupMetadata{Filename: filename, Checksum: checksum}))
+	require.NoError(t, bm.writeManifest(filename))
+	return path
+}
+
+func TestManifestDetectsCorruption(t *testing.T) {
+	dir := t.TempDir()
+	bm := NewBackupManager(config.BackupConfig{BackupDir: dir, ManifestKey: testManifestKey}, config.Config{DBName: "toeic"})
+	path := writeTestBackup(t, bm, "manual_backup_1.sql")
+
+	var manifest Manifest
+	data, err := os.ReadFile(path + manifestSuffix)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &manifest))
+	require.Len(t, manifest.Files, 2)
+	assert.Equal(t, "manual_backup_1.sql", manifest.Files[0].Name)
+	assert.Equal(t, "manual_backup_1.sql.meta", manifest.Files[1].Name)
+	assert.NotEmpty(t, manifest.Signature)
+
+	signed, err := bm.VerifyManifest(context.Background(), "manual_backup_1.sql")
+	require.NoError(t, err)
+	assert.True(t, signed)
+
+	// A flipped byte keeps the size
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	content[10] ^= 0xff
+	require.NoError(t, os.WriteFile(path, content, 0644))
+	_, err = bm.VerifyManifest(context.Background(), "manual_backup_1.sql")
+	assert.ErrorIs(t, err, ErrManifestMismatch)
+	assert.Contains(t, err.Error(), "manual_backup_1.sql checksum mismatch")
+
+	report, err := bm.ValidateBackup(context.Background(), "manual_backup_1.sql")
+	require.NoError(t, err)
+	assert.False(t, report.Valid)
+	assert.False(t, report.ManifestMatch)
+
+	_, _, err = bm.prepareRestore(context.Background(), "manual_backup_1.sql", &RestoreResult{})
+	assert.ErrorIs(t, err, ErrManifestMismatch)
+}
+
+func TestManifestSignature(t *testing.T) {
+	dir := t.TempDir()
+	bm := NewBackupManager(config.BackupConfig{BackupDir: dir, ManifestKey: testManifestKey}, config.Config{DBName: "toeic"})
+	path := writeTestBackup(t, bm, "manual_backup_1.sql")
+
+	// A backup rewritten with a manifest to match, without the key
+	require.NoError(t, os.WriteFile(path, []byte("SET client_encoding = 'UTF8';\nDROP TABLE words;\n"), 0644))
+	forger := NewBackupManager(config.BackupConfig{BackupDir: dir, ManifestKey: "another key of at least 32 characters"}, config.Config{})
+	require.NoError(t, forger.writeManifest("manual_backup_1.sql"))
+	_, err := bm.VerifyManifest(context.Background(), "manual_backup_1.sql")
+	assert.ErrorIs(t, err, ErrManifestSignature)
+
+	// Without the key manifests are only checked for corruption
+	unkeyed := NewBackupManager(config.BackupConfig{BackupDir: dir}, config.Config{})
+	signed, err := unkeyed.VerifyManifest(context.Background(), "manual_backup_1.sql")
+	require.NoError(t, err)
+	assert.False(t, signed)
+
+	require.NoError(t, os.Remove(path+manifestSuffix))
+	_, err = bm.VerifyManifest(context.Background(), "manual_backup_1.sql")
+	assert.ErrorIs(t, err, ErrManifestNotFound)
+}
+
+func TestMonitorReportsCorruptedBackups(t *testing.T) {
+	dir := t.TempDir()
+	cfg := config.BackupConfig{BackupDir: dir, ReverifyInterval: time.Hour}
+	bm := NewBackupManager(cfg, config.Config{DBName: "toeic"})
+	writeTestBackup(t, bm, "manual_backup_1.sql")
+	path := writeTestBackup(t, bm, "manual_backup_2.sql")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "manual_backup_3.sql"), []byte("CREATE TABLE words (id int);\n"), 0644))
+
+	monitor := NewSimpleBackupMonitor(cfg)
+	assert.Equal(t, "unknown", monitor.CheckHealth().Integrity)
+	monitor.SetVerifier(bm)
+
+	summary, err := monitor.VerifyBackups(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, summary.Checked)
+	assert.Equal(t, []string{"manual_backup_3.sql"}, summary.Unverified)
+	assert.Empty(t, summary.Corrupted)
+	assert.Equal(t, "healthy", monitor.CheckHealth().Integrity)
+
+	require.NoError(t, os.WriteFile(path, []byte("truncated"), 0644))
+	summary, err = monitor.VerifyBackups(context.Background())
+	require.NoError(t, err)
+	assert.Contains(t, summary.Corrupted, "manual_backup_2.sql")
+	health := monitor.CheckHealth()
+	assert.Equal(t, "critical", health.Integrity)
+	assert.Equal(t, "critical", health.Overall)
+	assert.Contains(t, health.Issues[len(health.Issues)-1], "manual_backup_2.sql failed verification")
+
+	require.NoError(t, monitor.StartVerification())
+	assert.True(t, monitor.IsVerifying())
+	assert.Error(t, monitor.StartVerification())
+	require.NoError(t, monitor.StopVerification())
+	assert.False(t, monitor.IsVerifying())
+}
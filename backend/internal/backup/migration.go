@@ -0,0 +1,146 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/toeic-app/internal/logger"
+)
+
+// TypeMigration is the type of the backups taken before migrating the schema
+const TypeMigration = "migration"
+
+// migrationBackupReuse is how recent a migration backup between the same
+// schema versions has to be for it to be used instead of a new one, e.g.
+// the backup the server took at startup when the migrations run next
+const migrationBackupReuse = 30 * time.Minute
+
+type migrationTargetKey struct{}
+
+// migrationTarget returns the schema version the migration backup made
+// with ctx is taken before migrating to
+func migrationTarget(ctx context.Context) string {
+	target, _ := ctx.Value(migrationTargetKey{}).(string)
+	return target
+}
+
+// SchemaVersion is the migration state of the database, as golang-migrate
+// records it in schema_migrations
+type SchemaVersion struct {
+	Version uint
+	// Dirty is set when the last migration failed halfway
+	Dirty bool
+	// Empty is set when no migration was ever applied
+	Empty bool
+}
+
+// SchemaVersion reads the migration state of the database
+func (bm *BackupManager) SchemaVersion(ctx context.Context) (SchemaVersion, error) {
+	rows, err := bm.query(ctx, "SELECT to_regclass('public.schema_migrations') IS NOT NULL")
+	if err != nil {
+		return SchemaVersion{}, err
+	}
+	if len(rows) != 1 || len(rows[0]) != 1 || rows[0][0] != "t" {
+		return SchemaVersion{Empty: true}, nil
+	}
+	rows, err = bm.query(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1")
+	if err != nil {
+		return SchemaVersion{}, err
+	}
+	return parseSchemaVersion(rows)
+}
+
+// parseSchemaVersion reads the schema_migrations row psql returned
+func parseSchemaVersion(rows [][]string) (SchemaVersion, error) {
+	if len(rows) == 0 {
+		return SchemaVersion{Empty: true}, nil
+	}
+	if len(rows[0]) != 2 {
+		return SchemaVersion{}, fmt.Errorf("unexpected schema_migrations result")
+	}
+	version, err := strconv.ParseUint(rows[0][0], 10, 64)
+	if err != nil {
+		return SchemaVersion{}, fmt.Errorf("unexpected schema version %q", rows[0][0])
+	}
+	return SchemaVersion{Version: uint(version), Dirty: rows[0][1] == "t"}, nil
+}
+
+// Pending tells whether migrating to target changes the schema. A dirty
+// schema is pending, as the failed migration is run again.
+func (v SchemaVersion) Pending(target uint) bool {
+	return v.Dirty || v.Version < target
+}
+
+// BackupBeforeMigration takes a full backup of type migration when
+// migrating to target would change the schema, so that a failed or
+// unwanted migration can be undone by restoring it. The backup records the
+// schema version it was taken at and target. A migration backup between
+// the same versions made within the last migrationBackupReuse is returned
+// instead of taking another. It returns a nil result when there is nothing
+// to back up: the schema is up to date, or no migration was ever applied
+// and the database holds no data yet.
+func (bm *BackupManager) BackupBeforeMigration(ctx context.Context, target uint) (*BackupResult, error) {
+	current, err := bm.SchemaVersion(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if current.Empty {
+		logger.Info("No migration applied yet, nothing to back up before migrating")
+		return nil, nil
+	}
+	if !current.Pending(target) {
+		logger.Info("Schema is at version %d, nothing to migrate to version %d", current.Version, target)
+		return nil, nil
+	}
+
+	if !current.Dirty {
+		if metadata := bm.recentMigrationBackup(ctx, current.Version, target); metadata != nil {
+			logger.Info("Using migration backup %s made at %s", metadata.Filename, metadata.CreatedAt.Format(time.RFC3339))
+			return &BackupResult{Success: true, Metadata: metadata, Size: metadata.Size}, nil
+		}
+	}
+
+	description := fmt.Sprintf("Before migrating the schema from version %d to %d", current.Version, target)
+	if current.Dirty {
+		description = fmt.Sprintf("Before migrating the schema from dirty version %d to %d", current.Version, target)
+	}
+	ctx = context.WithValue(ctx, migrationTargetKey{}, strconv.FormatUint(uint64(target), 10))
+	result, err := bm.CreateBackup(ctx, description, TypeMigration)
+	if err != nil {
+		return nil, fmt.Errorf("backup before migration failed: %w", err)
+	}
+	return result, nil
+}
+
+// recentMigrationBackup returns the newest migration backup from version
+// to target made within migrationBackupReuse, or nil
+func (bm *BackupManager) recentMigrationBackup(ctx context.Context, version, target uint) *BackupMetadata {
+	files, err := bm.ListBackups(ctx)
+	if err != nil {
+		logger.Warn("Failed to look for a recent migration backup: %v", err)
+		return nil
+	}
+	from, to := strconv.FormatUint(uint64(version), 10), strconv.FormatUint(uint64(target), 10)
+	for _, file := range files {
+		// Newest first
+		if time.Since(file.ModTime) > migrationBackupReuse {
+			return nil
+		}
+		metadata, err := bm.fetchMetadata(ctx, file.Name)
+		if err != nil {
+			continue
+		}
+		if metadata.Type == TypeMigration && metadata.SchemaVersion == from && metadata.MigrationTarget == to {
+			return metadata
+		}
+	}
+	return nil
+}
+
+// Metadata returns the metadata of a backup, downloading it from remote
+// storage when there is no local copy
+func (bm *BackupManager) Metadata(ctx context.Context, filename string) (*BackupMetadata, error) {
+	return bm.fetchMetadata(ctx, filename)
+}
@@ -0,0 +1,50 @@
+package backup
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toeic-app/internal/config"
+)
+
+func TestSchemaVersionPending(t *testing.T) {
+	version, err := parseSchemaVersion([][]string{{"59", "f"}})
+	require.NoError(t, err)
+	assert.Equal(t, SchemaVersion{Version: 59}, version)
+	assert.True(t, version.Pending(60))
+	assert.False(t, version.Pending(59))
+
+	// A failed migration is run again
+	version, err = parseSchemaVersion([][]string{{"60", "t"}})
+	require.NoError(t, err)
+	assert.True(t, version.Dirty)
+	assert.True(t, version.Pending(60))
+
+	version, err = parseSchemaVersion(nil)
+	require.NoError(t, err)
+	assert.True(t, version.Empty)
+
+	_, err = parseSchemaVersion([][]string{{"latest", "f"}})
+	assert.Error(t, err)
+}
+
+func TestRecentMigrationBackup(t *testing.T) {
+	bm := NewBackupManager(config.BackupConfig{BackupDir: t.TempDir()}, config.Config{DBName: "toeic"})
+	ctx := context.Background()
+
+	writeChainBackup(t, bm, BackupMetadata{Filename: "migration_backup_1.sql", Type: TypeMigration,
+		SchemaVersion: "59", MigrationTarget: "60"}, 2*time.Hour)
+	writeChainBackup(t, bm, BackupMetadata{Filename: "migration_backup_2.sql", Type: TypeMigration,
+		SchemaVersion: "60", MigrationTarget: "61"}, 10*time.Minute)
+	writeChainBackup(t, bm, BackupMetadata{Filename: "manual_backup_3.sql", Type: "manual", SchemaVersion: "60"}, time.Minute)
+
+	metadata := bm.recentMigrationBackup(ctx, 60, 61)
+	require.NotNil(t, metadata)
+	assert.Equal(t, "migration_backup_2.sql", metadata.Filename)
+	// Too old to stand for the data of a migration running now
+	assert.Nil(t, bm.recentMigrationBackup(ctx, 59, 60))
+	assert.Nil(t, bm.recentMigrationBackup(ctx, 60, 62))
+}
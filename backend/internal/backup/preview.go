@@ -0,0 +1,276 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/toeic-app/internal/logger"
+)
+
+// Destructive operations of a restore
+const (
+	OperationReplaceRows     = "replace_rows"     // The rows of a live table are replaced by those of the backup
+	OperationDropColumn      = "drop_column"      // A live column the backup does not have is lost with its data
+	OperationChangeType      = "change_type"      // A column gets the type it had in the backup
+	OperationSchemaDowngrade = "schema_downgrade" // The backup was taken at an older migration
+)
+
+// ColumnDiff is a column that differs between the live database and a
+// backup. Live or Backup is nil when the column only exists on one side.
+type ColumnDiff struct {
+	Column string      `json:"column"`
+	Live   *DumpColumn `json:"live,omitempty"`
+	Backup *DumpColumn `json:"backup,omitempty"`
+}
+
+// TableDiff lists the columns of a table that differ between the live
+// database and a backup
+type TableDiff struct {
+	Table          string       `json:"table"`
+	AddedColumns   []ColumnDiff `json:"added_columns,omitempty"`   // Only in the backup
+	DroppedColumns []ColumnDiff `json:"dropped_columns,omitempty"` // Only in the live database
+	ChangedColumns []ColumnDiff `json:"changed_columns,omitempty"` // Other type or nullability
+}
+
+// DestructiveOperation is a change a restore makes that loses live data
+type DestructiveOperation struct {
+	Operation  string `json:"operation"`
+	Table      string `json:"table,omitempty"`
+	Column     string `json:"column,omitempty"`
+	LiveRows   int64  `json:"live_rows,omitempty"` // Estimated from table statistics
+	BackupRows int64  `json:"backup_rows,omitempty"`
+	Detail     string `json:"detail"`
+}
+
+// RestorePreview is the outcome of PreviewRestore
+type RestorePreview struct {
+	Filename            string                 `json:"filename"`
+	Chain               []string               `json:"chain,omitempty"` // Backups that would be restored, full backup first
+	Database            string                 `json:"database"`
+	LiveSchemaVersion   string                 `json:"live_schema_version,omitempty"`
+	BackupSchemaVersion string                 `json:"backup_schema_version,omitempty"`
+	NewTables           []string               `json:"new_tables,omitempty"`     // Only in the backup, created by the restore
+	MissingTables       []string               `json:"missing_tables,omitempty"` // Only in the live database, left as they are
+	ChangedTables       []TableDiff            `json:"changed_tables,omitempty"`
+	Destructive         []DestructiveOperation `json:"destructive,omitempty"`
+	Warnings            []string               `json:"warnings,omitempty"`
+	Duration            time.Duration          `json:"duration" swaggertype:"integer"` // Nanoseconds
+	PreviewedAt         time.Time              `json:"previewed_at"`
+}
+
+// IsDestructive reports whether the restore would lose live data
+func (p *RestorePreview) IsDestructive() bool {
+	return len(p.Destructive) > 0
+}
+
+// liveSchema is the schema of the live database
+type liveSchema struct {
+	Version string
+	Columns map[string][]DumpColumn
+	Rows    map[string]int64 // Estimated from table statistics
+}
+
+// PreviewRestore tells what restoring a backup would change without
+// restoring it: the backups of the chain are fetched and read, and the
+// tables and columns they create are compared with the live database.
+func (bm *BackupManager) PreviewRestore(ctx context.Context, filename string) (*RestorePreview, error) {
+	startTime := time.Now()
+	preview := &RestorePreview{Filename: filename, Database: bm.dbConfig.DBName, PreviewedAt: startTime}
+	defer func() { preview.Duration = time.Since(startTime) }()
+
+	if !bm.isValidBackupFilename(filename) {
+		return nil, fmt.Errorf("invalid backup filename")
+	}
+	chain, err := bm.RestoreChain(ctx, filename)
+	if err != nil {
+		return nil, err
+	}
+	preview.Chain = chain
+
+	prepared := &RestoreResult{}
+	contents := dumpContents{Rows: make(map[string]int64)}
+	for _, name := range chain {
+		sqlPath, downloaded, err := bm.prepareRestore(ctx, name, prepared)
+		if downloaded && !bm.config.KeepLocalCopy {
+			defer bm.removeLocal(name)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if sqlPath != filepath.Join(bm.config.BackupDir, name) {
+			defer os.Remove(sqlPath)
+		}
+		if err := bm.readDump(ctx, &contents, sqlPath); err != nil {
+			return nil, fmt.Errorf("failed to read backup %s: %w", name, err)
+		}
+	}
+	preview.Warnings = prepared.Warnings
+	if metadata, err := bm.fetchMetadata(ctx, filename); err == nil {
+		preview.BackupSchemaVersion = metadata.SchemaVersion
+	}
+
+	live, err := bm.readLiveSchema(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the live schema: %w", err)
+	}
+	preview.LiveSchemaVersion = live.Version
+	diffSchemas(preview, contents, live)
+
+	logger.Info("Restore preview of %s: %d new tables, %d changed tables, %d destructive operations",
+		filename, len(preview.NewTables), len(preview.ChangedTables), len(preview.Destructive))
+	return preview, nil
+}
+
+// readLiveSchema reads the columns, estimated row counts and migration
+// version of the live database
+func (bm *BackupManager) readLiveSchema(ctx context.Context) (*liveSchema, error) {
+	live := &liveSchema{Columns: make(map[string][]DumpColumn), Rows: make(map[string]int64)}
+
+	rows, err := bm.query(ctx, `SELECT n.nspname || '.' || c.relname, a.attname, format_type(a.atttypid, a.atttypmod), a.attnotnull
+		FROM pg_attribute a JOIN pg_class c ON c.oid = a.attrelid JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE c.relkind IN ('r', 'p') AND a.attnum > 0 AND NOT a.attisdropped
+			AND n.nspname NOT LIKE 'pg\_%' AND n.nspname <> 'information_schema'
+		ORDER BY 1, a.attnum`)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		if len(row) != 4 {
+			continue
+		}
+		live.Columns[row[0]] = append(live.Columns[row[0]], DumpColumn{Name: row[1], Type: row[2], NotNull: row[3] == "t"})
+	}
+
+	rows, err = bm.query(ctx, "SELECT schemaname || '.' || relname, n_live_tup FROM pg_stat_user_tables")
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		if len(row) != 2 {
+			continue
+		}
+		count, err := strconv.ParseInt(row[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected row count %q for %s", row[1], row[0])
+		}
+		live.Rows[row[0]] = count
+	}
+
+	if rows, err := bm.query(ctx, "SELECT version FROM schema_migrations LIMIT 1"); err == nil && len(rows) == 1 {
+		live.Version = rows[0][0]
+	}
+	return live, nil
+}
+
+// diffSchemas fills a preview with the differences between the tables a
+// backup creates and the live ones
+func diffSchemas(preview *RestorePreview, contents dumpContents, live *liveSchema) {
+	created := make(map[string]bool, len(contents.Tables))
+	for _, table := range contents.Tables {
+		created[table] = true
+		liveColumns, exists := live.Columns[table]
+		if !exists {
+			preview.NewTables = append(preview.NewTables, table)
+			continue
+		}
+		if diff := diffColumns(table, liveColumns, contents.Columns[table]); diff != nil {
+			preview.ChangedTables = append(preview.ChangedTables, *diff)
+			for _, column := range diff.DroppedColumns {
+				preview.Destructive = append(preview.Destructive, DestructiveOperation{
+					Operation: OperationDropColumn,
+					Table:     table,
+					Column:    column.Column,
+					LiveRows:  live.Rows[table],
+					Detail:    fmt.Sprintf("%s.%s is not in the backup, its data is lost", table, column.Column),
+				})
+			}
+			for _, column := range diff.ChangedColumns {
+				if normalizeType(column.Live.Type) != normalizeType(column.Backup.Type) {
+					preview.Destructive = append(preview.Destructive, DestructiveOperation{
+						Operation: OperationChangeType,
+						Table:     table,
+						Column:    column.Column,
+						Detail:    fmt.Sprintf("%s.%s changes from %s to %s", table, column.Column, column.Live.Type, column.Backup.Type),
+					})
+				}
+			}
+		}
+	}
+	for table := range live.Columns {
+		if !created[table] {
+			preview.MissingTables = append(preview.MissingTables, table)
+		}
+	}
+	sort.Strings(preview.NewTables)
+	sort.Strings(preview.MissingTables)
+
+	// Full backups drop and recreate their tables, incremental ones delete
+	// the rows of theirs; either way the live rows are gone
+	tables := make([]string, 0, len(contents.Rows))
+	for table := range contents.Rows {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	for _, table := range tables {
+		if rows := live.Rows[table]; rows > 0 {
+			preview.Destructive = append(preview.Destructive, DestructiveOperation{
+				Operation:  OperationReplaceRows,
+				Table:      table,
+				LiveRows:   rows,
+				BackupRows: contents.Rows[table],
+				Detail:     fmt.Sprintf("about %d live rows of %s are replaced by %d rows of the backup", rows, table, contents.Rows[table]),
+			})
+		}
+	}
+
+	liveVersion, liveErr := strconv.Atoi(preview.LiveSchemaVersion)
+	backupVersion, backupErr := strconv.Atoi(preview.BackupSchemaVersion)
+	if liveErr == nil && backupErr == nil && backupVersion < liveVersion {
+		preview.Destructive = append(preview.Destructive, DestructiveOperation{
+			Operation: OperationSchemaDowngrade,
+			Detail:    fmt.Sprintf("the schema goes back from migration %d to %d; run the migrations again after restoring", liveVersion, backupVersion),
+		})
+	}
+}
+
+// diffColumns compares the live columns of a table with those the backup
+// creates; nil means they match
+func diffColumns(table string, liveColumns, backupColumns []DumpColumn) *TableDiff {
+	diff := TableDiff{Table: table}
+	backupByName := make(map[string]DumpColumn, len(backupColumns))
+	for _, column := range backupColumns {
+		backupByName[column.Name] = column
+	}
+	liveByName := make(map[string]bool, len(liveColumns))
+	for _, liveColumn := range liveColumns {
+		liveByName[liveColumn.Name] = true
+		backupColumn, ok := backupByName[liveColumn.Name]
+		switch {
+		case !ok:
+			diff.DroppedColumns = append(diff.DroppedColumns, ColumnDiff{Column: liveColumn.Name, Live: &liveColumn})
+		case normalizeType(backupColumn.Type) != normalizeType(liveColumn.Type) || backupColumn.NotNull != liveColumn.NotNull:
+			diff.ChangedColumns = append(diff.ChangedColumns, ColumnDiff{Column: liveColumn.Name, Live: &liveColumn, Backup: &backupColumn})
+		}
+	}
+	for _, backupColumn := range backupColumns {
+		if !liveByName[backupColumn.Name] {
+			diff.AddedColumns = append(diff.AddedColumns, ColumnDiff{Column: backupColumn.Name, Backup: &backupColumn})
+		}
+	}
+	if diff.AddedColumns == nil && diff.DroppedColumns == nil && diff.ChangedColumns == nil {
+		return nil
+	}
+	return &diff
+}
+
+// normalizeType drops the public schema pg_dump puts before the types it
+// created, which format_type leaves out
+func normalizeType(columnType string) string {
+	return strings.TrimPrefix(columnType, "public.")
+}
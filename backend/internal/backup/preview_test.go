@@ -0,0 +1,70 @@
+package backup
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseColumn(t *testing.T) {
+	for line, expected := range map[string]DumpColumn{
+		"    id bigint NOT NULL,": {Name: "id", Type: "bigint", NotNull: true},
+		"    name character varying(255) DEFAULT 'x'::character varying":    {Name: "name", Type: "character varying(255)"},
+		`    "Created At" timestamp with time zone DEFAULT now() NOT NULL,`: {Name: "Created At", Type: "timestamp with time zone", NotNull: true},
+		"    level public.level_kind COLLATE pg_catalog.\"C\",":             {Name: "level", Type: "public.level_kind"},
+	} {
+		column, ok := parseColumn(line)
+		require.True(t, ok, line)
+		assert.Equal(t, expected, column)
+	}
+	_, ok := parseColumn("    CONSTRAINT words_level_check CHECK ((level > 0))")
+	assert.False(t, ok)
+}
+
+func TestDiffSchemas(t *testing.T) {
+	contents := dumpContents{Rows: make(map[string]int64)}
+	require.NoError(t, contents.scan(strings.NewReader(fullDump)))
+	assert.Equal(t, []DumpColumn{{Name: "id", Type: "integer", NotNull: true}, {Name: "word", Type: "text"}}, contents.Columns["public.words"])
+
+	live := &liveSchema{
+		Version: "58",
+		Columns: map[string][]DumpColumn{
+			"public.words": {
+				{Name: "id", Type: "bigint", NotNull: true},
+				{Name: "word", Type: "text"},
+				{Name: "level", Type: "integer"},
+			},
+			"public.schema_migrations": {{Name: "version", Type: "bigint", NotNull: true}, {Name: "dirty", Type: "boolean", NotNull: true}},
+			"public.badges":            {{Name: "id", Type: "integer", NotNull: true}},
+		},
+		Rows: map[string]int64{"public.words": 120, "public.schema_migrations": 1, "public.badges": 4},
+	}
+	preview := &RestorePreview{LiveSchemaVersion: live.Version, BackupSchemaVersion: "56"}
+	diffSchemas(preview, contents, live)
+
+	assert.Equal(t, []string{"public.Order Items"}, preview.NewTables)
+	assert.Equal(t, []string{"public.badges"}, preview.MissingTables)
+	require.Len(t, preview.ChangedTables, 1)
+	words := preview.ChangedTables[0]
+	assert.Equal(t, "public.words", words.Table)
+	require.Len(t, words.DroppedColumns, 1)
+	assert.Equal(t, "level", words.DroppedColumns[0].Column)
+	require.Len(t, words.ChangedColumns, 1)
+	assert.Equal(t, "integer", words.ChangedColumns[0].Backup.Type)
+	assert.Empty(t, words.AddedColumns)
+
+	operations := make([]string, len(preview.Destructive))
+	for i, operation := range preview.Destructive {
+		operations[i] = operation.Operation + " " + operation.Table
+	}
+	assert.Equal(t, []string{
+		"drop_column public.words",
+		"change_type public.words",
+		"replace_rows public.schema_migrations",
+		"replace_rows public.words",
+		"schema_downgrade ",
+	}, operations)
+	assert.True(t, preview.IsDestructive())
+}
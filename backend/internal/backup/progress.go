@@ -0,0 +1,237 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Phases of a restore
+const (
+	PhasePreparing    = "preparing"     // Fetching, verifying and reading the backups
+	PhaseSafetyBackup = "safety_backup" // Backing up the database before it is replaced
+	PhaseStaging      = "staging"       // Restoring the backups of a selective restore into the scratch database
+	PhaseRestoring    = "restoring"
+	PhaseRollingBack  = "rolling_back" // Restoring the safety backup after the restore failed
+	PhaseValidating   = "validating"
+	PhaseFinished     = "finished"
+)
+
+// RestoreProgress tells how far a restore got. Tables and rows count the
+// table data the backups copy; an incremental backup copies its tables
+// again.
+type RestoreProgress struct {
+	Phase string `json:"phase" example:"restoring"`
+	// Backup of the chain being restored
+	Backup         string `json:"backup,omitempty"`
+	TablesRestored int    `json:"tables_restored"`
+	TablesTotal    int    `json:"tables_total"`
+	RowsRestored   int64  `json:"rows_restored"`
+	RowsTotal      int64  `json:"rows_total"`
+	ElapsedMs      int64  `json:"elapsed_ms"`
+	// Percent estimates how much of the restore is done, from its phase and
+	// the rows, or tables, restored. It never goes back, also while rolling
+	// back.
+	Percent float64 `json:"percent" example:"42.5"`
+}
+
+// Share of a restore done when each phase starts. Restoring takes the share
+// up to validating by the rows restored.
+var phasePercent = map[string]float64{
+	PhasePreparing:    0,
+	PhaseSafetyBackup: 5,
+	PhaseStaging:      15,
+	PhaseRestoring:    30,
+	PhaseValidating:   90,
+	PhaseFinished:     100,
+}
+
+// estimate returns the share of the restore done, or 0 while rolling back
+func (p RestoreProgress) estimate() float64 {
+	percent := phasePercent[p.Phase]
+	if p.Phase != PhaseRestoring {
+		return percent
+	}
+	var done float64
+	switch {
+	case p.RowsTotal > 0:
+		done = float64(p.RowsRestored) / float64(p.RowsTotal)
+	case p.TablesTotal > 0:
+		done = float64(p.TablesRestored) / float64(p.TablesTotal)
+	}
+	done = min(done, 1)
+	restoring := percent + (phasePercent[PhaseValidating]-percent)*done
+	return math.Round(restoring*10) / 10
+}
+
+// RestoreProgressFunc receives the progress of a restore whenever it
+// changes
+type RestoreProgressFunc func(RestoreProgress)
+
+type progressKey struct{}
+
+// WithRestoreProgress makes the restore run with ctx report its progress
+func WithRestoreProgress(ctx context.Context, report RestoreProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, &restoreTracker{report: report, started: time.Now()})
+}
+
+// restoreTracker follows the progress of a restore. A nil tracker, of a
+// restore run without WithRestoreProgress, ignores everything.
+type restoreTracker struct {
+	report  RestoreProgressFunc
+	started time.Time
+
+	mu       sync.Mutex
+	progress RestoreProgress
+	// tableRows are the rows the backups copy per table, counted for
+	// archives when pg_restore starts loading a table
+	tableRows map[string]int64
+}
+
+// progressTracker returns the tracker of the restore run with ctx
+func progressTracker(ctx context.Context) *restoreTracker {
+	tracker, _ := ctx.Value(progressKey{}).(*restoreTracker)
+	return tracker
+}
+
+// update changes the progress and reports it
+func (t *restoreTracker) update(change func(*RestoreProgress)) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	change(&t.progress)
+	t.progress.ElapsedMs = time.Since(t.started).Milliseconds()
+	t.progress.Percent = max(t.progress.Percent, t.progress.estimate())
+	t.report(t.progress)
+}
+
+// phase moves the restore to a phase, restoring backup when set
+func (t *restoreTracker) phase(phase, backup string) {
+	t.update(func(progress *RestoreProgress) {
+		progress.Phase, progress.Backup = phase, backup
+	})
+}
+
+// expect sets the tables and rows the restore copies
+func (t *restoreTracker) expect(tables int, rows int64, tableRows map[string]int64) {
+	t.update(func(progress *RestoreProgress) {
+		progress.TablesTotal, progress.RowsTotal = tables, rows
+		t.tableRows = tableRows
+	})
+}
+
+// tableRestored counts a table loaded into the database. Only tables of
+// the restoring phase count; staging and rolling back load others.
+func (t *restoreTracker) tableRestored(rows int64) {
+	t.update(func(progress *RestoreProgress) {
+		if progress.Phase == PhaseRestoring {
+			progress.TablesRestored++
+			progress.RowsRestored += rows
+		}
+	})
+}
+
+// restored returns the tables and rows restored so far
+func (t *restoreTracker) restored() (int, int64) {
+	if t == nil {
+		return 0, 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.progress.TablesRestored, t.progress.RowsRestored
+}
+
+// rewind takes the counts back to what restored returned, before a file
+// is loaded again
+func (t *restoreTracker) rewind(tables int, rows int64) {
+	t.update(func(progress *RestoreProgress) {
+		progress.TablesRestored, progress.RowsRestored = tables, rows
+	})
+}
+
+var (
+	// psql reports the rows of every COPY it ran
+	psqlCopyPattern = regexp.MustCompile(`^COPY (\d+)$`)
+	// pg_restore --verbose reports every table it starts loading
+	pgRestoreDataPattern = regexp.MustCompile(`processing data for table "(.+)"$`)
+)
+
+// outputLine counts the tables a line of psql or pg_restore output
+// reports loaded
+func (t *restoreTracker) outputLine(line string) {
+	if match := psqlCopyPattern.FindStringSubmatch(line); match != nil {
+		rows, _ := strconv.ParseInt(match[1], 10, 64)
+		t.tableRestored(rows)
+		return
+	}
+	if match := pgRestoreDataPattern.FindStringSubmatch(line); match != nil {
+		t.mu.Lock()
+		rows := t.tableRows[match[1]]
+		t.mu.Unlock()
+		t.tableRestored(rows)
+	}
+}
+
+// progressWriter keeps the output of psql or pg_restore and passes its
+// lines to the tracker as they are written
+type progressWriter struct {
+	tracker *restoreTracker
+	output  bytes.Buffer
+	line    []byte
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	w.output.Write(p)
+	w.line = append(w.line, p...)
+	for {
+		end := bytes.IndexByte(w.line, '\n')
+		if end < 0 {
+			return len(p), nil
+		}
+		w.tracker.outputLine(string(bytes.TrimRight(w.line[:end], "\r")))
+		w.line = w.line[end+1:]
+	}
+}
+
+// combinedOutput runs psql or pg_restore like cmd.CombinedOutput, counting
+// the tables it loads when tracked
+func combinedOutput(cmd *exec.Cmd, tracker *restoreTracker) ([]byte, error) {
+	if tracker == nil {
+		return cmd.CombinedOutput()
+	}
+	writer := &progressWriter{tracker: tracker}
+	cmd.Stdout, cmd.Stderr = writer, writer
+	err := cmd.Run()
+	return writer.output.Bytes(), err
+}
+
+// expectRestore reads the backups a restore replays to tell the tracker
+// how many tables and rows they copy
+func (bm *BackupManager) expectRestore(ctx context.Context, tracker *restoreTracker, sqlPaths []string) error {
+	if tracker == nil {
+		return nil
+	}
+	var tables int
+	var rows int64
+	tableRows := make(map[string]int64)
+	for _, sqlPath := range sqlPaths {
+		contents := dumpContents{Rows: make(map[string]int64)}
+		if err := bm.readDump(ctx, &contents, sqlPath); err != nil {
+			return err
+		}
+		tables += len(contents.Rows)
+		for table, count := range contents.Rows {
+			rows += count
+			tableRows[table] = count
+		}
+	}
+	tracker.expect(tables, rows, tableRows)
+	return nil
+}
@@ -0,0 +1,238 @@
+package backup
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/toeic-app/internal/logger"
+)
+
+// States of a restore
+const (
+	RestoreRunning   = "running"
+	RestoreSucceeded = "succeeded"
+	RestoreFailed    = "failed"
+	RestoreCanceled  = "canceled"
+)
+
+// RestoreTopic is the WebSocket topic restores are followed on. Topics
+// starting with admin. are for administrators.
+const RestoreTopic = "admin.restores"
+
+// Events pushed to RestoreTopic
+const (
+	EventRestoreProgress = "restore.progress" // The phase or progress of a running restore changed
+	EventRestoreFinished = "restore.finished" // A restore succeeded, failed or was canceled
+)
+
+// maxFinishedRestores bounds the finished restores kept for polling
+const maxFinishedRestores = 20
+
+var (
+	ErrRestoreRunning  = errors.New("another restore is running")
+	ErrRestoreNotFound = errors.New("restore not found")
+	ErrRestoreFinished = errors.New("restore already finished")
+)
+
+// Publisher delivers a message to the subscribers of a topic.
+// *websocket.Manager satisfies it.
+type Publisher interface {
+	PublishToTopic(topic string, messageType string, data interface{}) error
+}
+
+// Restore is a restore run by this instance
+type Restore struct {
+	ID         string          `json:"id" example:"9f2c4e1a7b3d5f60"`
+	Filename   string          `json:"filename"`
+	Tables     []string        `json:"tables,omitempty"`
+	State      string          `json:"state" example:"running"`
+	StartedBy  string          `json:"started_by"`
+	StartedAt  time.Time       `json:"started_at"`
+	FinishedAt *time.Time      `json:"finished_at,omitempty"`
+	Progress   RestoreProgress `json:"progress"`
+	// CancelRequested is set once the restore was asked to stop. Restoring
+	// stops at once; the database is then rolled back to the safety backup.
+	CancelRequested bool           `json:"cancel_requested,omitempty"`
+	Result          *RestoreResult `json:"result,omitempty"`
+	Error           string         `json:"error,omitempty"`
+}
+
+// trackedRestore is a restore with the means to cancel it
+type trackedRestore struct {
+	Restore
+	cancel context.CancelFunc
+}
+
+// Restores tracks the restores of this instance, so they can be followed
+// on RestoreTopic or polled, and cancelled. One restore runs at a time.
+type Restores struct {
+	publisher Publisher
+
+	mu       sync.Mutex
+	restores []*trackedRestore // Oldest first
+}
+
+// NewRestores creates a restore tracker publishing to publisher, which may
+// be nil
+func NewRestores(publisher Publisher) *Restores {
+	return &Restores{publisher: publisher}
+}
+
+// Start registers a restore of filename and returns it with the context to
+// run it with, which reports its progress and is cancelled by Cancel. It
+// fails with ErrRestoreRunning while another restore runs.
+func (r *Restores) Start(ctx context.Context, filename string, tables []string, startedBy string) (Restore, context.Context, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, restore := range r.restores {
+		if restore.State == RestoreRunning {
+			return restore.Restore, nil, ErrRestoreRunning
+		}
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return Restore{}, nil, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	restore := &trackedRestore{
+		Restore: Restore{
+			ID:        hex.EncodeToString(id),
+			Filename:  filename,
+			Tables:    tables,
+			State:     RestoreRunning,
+			StartedBy: startedBy,
+			StartedAt: time.Now(),
+			Progress:  RestoreProgress{Phase: PhasePreparing},
+		},
+		cancel: cancel,
+	}
+	r.restores = append(r.restores, restore)
+	r.prune()
+	r.publish(EventRestoreProgress, restore.Restore)
+
+	ctx = WithRestoreProgress(ctx, func(progress RestoreProgress) {
+		r.progress(restore, progress)
+	})
+	return restore.Restore, ctx, nil
+}
+
+// progress records the progress of a running restore
+func (r *Restores) progress(restore *trackedRestore, progress RestoreProgress) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if restore.State != RestoreRunning {
+		return
+	}
+	restore.Progress = progress
+	r.publish(EventRestoreProgress, restore.Restore)
+}
+
+// Finish records the outcome of a restore
+func (r *Restores) Finish(id string, result *RestoreResult, err error) Restore {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	restore := r.find(id)
+	if restore == nil {
+		return Restore{}
+	}
+	now := time.Now()
+	restore.FinishedAt = &now
+	restore.Progress.ElapsedMs = now.Sub(restore.StartedAt).Milliseconds()
+	restore.Result = result
+	switch {
+	case err == nil:
+		restore.State = RestoreSucceeded
+	case restore.CancelRequested:
+		restore.State = RestoreCanceled
+		restore.Error = err.Error()
+	default:
+		restore.State = RestoreFailed
+		restore.Error = err.Error()
+	}
+	restore.cancel()
+	r.publish(EventRestoreFinished, restore.Restore)
+	return restore.Restore
+}
+
+// Get returns a restore
+func (r *Restores) Get(id string) (Restore, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	restore := r.find(id)
+	if restore == nil {
+		return Restore{}, ErrRestoreNotFound
+	}
+	return restore.Restore, nil
+}
+
+// List returns the running restore and the last finished ones, newest
+// first
+func (r *Restores) List() []Restore {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	restores := make([]Restore, 0, len(r.restores))
+	for _, restore := range slices.Backward(r.restores) {
+		restores = append(restores, restore.Restore)
+	}
+	return restores
+}
+
+// Cancel asks a running restore to stop
+func (r *Restores) Cancel(id string) (Restore, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	restore := r.find(id)
+	if restore == nil {
+		return Restore{}, ErrRestoreNotFound
+	}
+	if restore.State != RestoreRunning {
+		return restore.Restore, ErrRestoreFinished
+	}
+	if !restore.CancelRequested {
+		restore.CancelRequested = true
+		restore.cancel()
+		r.publish(EventRestoreProgress, restore.Restore)
+	}
+	return restore.Restore, nil
+}
+
+func (r *Restores) find(id string) *trackedRestore {
+	for _, restore := range r.restores {
+		if restore.ID == id {
+			return restore
+		}
+	}
+	return nil
+}
+
+// prune drops the oldest finished restores beyond maxFinishedRestores
+func (r *Restores) prune() {
+	finished := 0
+	for _, restore := range r.restores {
+		if restore.State != RestoreRunning {
+			finished++
+		}
+	}
+	r.restores = slices.DeleteFunc(r.restores, func(restore *trackedRestore) bool {
+		if finished > maxFinishedRestores && restore.State != RestoreRunning {
+			finished--
+			return true
+		}
+		return false
+	})
+}
+
+func (r *Restores) publish(event string, restore Restore) {
+	if r.publisher == nil {
+		return
+	}
+	if err := r.publisher.PublishToTopic(RestoreTopic, event, restore); err != nil {
+		logger.Warn("Failed to publish %s of restore %s: %v", event, restore.ID, err)
+	}
+}
@@ -0,0 +1,143 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// topicRecorder keeps the events published to each topic
+type topicRecorder struct {
+	mu     sync.Mutex
+	events map[string][]string
+	last   Restore
+}
+
+func (r *topicRecorder) PublishToTopic(topic string, messageType string, data interface{}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.events == nil {
+		r.events = make(map[string][]string)
+	}
+	r.events[topic] = append(r.events[topic], messageType)
+	r.last = data.(Restore)
+	return nil
+}
+
+func TestRestoreProgressFromOutput(t *testing.T) {
+	var reported []RestoreProgress
+	ctx := WithRestoreProgress(context.Background(), func(progress RestoreProgress) {
+		reported = append(reported, progress)
+	})
+	tracker := progressTracker(ctx)
+	tracker.expect(3, 20, map[string]int64{"public.words": 15, "public.users": 5})
+
+	// Tables loaded while staging are not counted
+	tracker.phase(PhaseStaging, "full_backup_1.sql")
+	writer := &progressWriter{tracker: tracker}
+	writer.Write([]byte("SET\nCOPY 4\n"))
+	tracker.phase(PhaseRestoring, "full_backup_1.sql")
+	// Lines are counted once complete
+	writer.Write([]byte("SET\nCOP"))
+	writer.Write([]byte("Y 12\r\nCOPY 3\nERROR:  relation \"x\" does not exist\n"))
+	writer.Write([]byte("pg_restore: processing data for table \"public.users\"\n"))
+
+	progress := reported[len(reported)-1]
+	assert.Equal(t, PhaseRestoring, progress.Phase)
+	assert.Equal(t, "full_backup_1.sql", progress.Backup)
+	assert.Equal(t, 3, progress.TablesRestored)
+	assert.Equal(t, int64(20), progress.RowsRestored)
+	assert.Equal(t, 3, progress.TablesTotal)
+	assert.Equal(t, 90.0, progress.Percent)
+	assert.Contains(t, writer.output.String(), "COPY 4\nSET\nCOPY 12\r\n")
+
+	// A retried file is counted again from where it started, but the
+	// percentage does not go back
+	tracker.rewind(1, 12)
+	tables, rows := tracker.restored()
+	assert.Equal(t, 1, tables)
+	assert.Equal(t, int64(12), rows)
+	assert.Equal(t, 90.0, reported[len(reported)-1].Percent)
+
+	// Restores run without WithRestoreProgress are not followed
+	progressTracker(context.Background()).phase(PhaseRestoring, "")
+}
+
+func TestRestoresCancel(t *testing.T) {
+	publisher := &topicRecorder{}
+	restores := NewRestores(publisher)
+
+	restore, ctx, err := restores.Start(context.Background(), "manual_backup_1.sql", nil, "admin")
+	require.NoError(t, err)
+	assert.Equal(t, RestoreRunning, restore.State)
+	assert.Len(t, restore.ID, 16)
+
+	_, _, err = restores.Start(context.Background(), "manual_backup_2.sql", nil, "admin")
+	assert.ErrorIs(t, err, ErrRestoreRunning)
+
+	progressTracker(ctx).phase(PhaseRestoring, "manual_backup_1.sql")
+	polled, err := restores.Get(restore.ID)
+	require.NoError(t, err)
+	assert.Equal(t, PhaseRestoring, polled.Progress.Phase)
+	assert.Equal(t, PhaseRestoring, publisher.last.Progress.Phase)
+
+	canceled, err := restores.Cancel(restore.ID)
+	require.NoError(t, err)
+	assert.True(t, canceled.CancelRequested)
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+
+	finished := restores.Finish(restore.ID, &RestoreResult{Warnings: []string{"Restored from safety backup due to main restore failure"}},
+		errors.New("restore failed after 3 attempts: signal: killed"))
+	assert.Equal(t, RestoreCanceled, finished.State)
+	assert.NotNil(t, finished.FinishedAt)
+	assert.Equal(t, []string{EventRestoreProgress, EventRestoreProgress, EventRestoreProgress, EventRestoreFinished}, publisher.events[RestoreTopic])
+
+	_, err = restores.Cancel(restore.ID)
+	assert.ErrorIs(t, err, ErrRestoreFinished)
+	_, err = restores.Get("missing")
+	assert.ErrorIs(t, err, ErrRestoreNotFound)
+
+	// Progress reported after the restore finished is ignored
+	progressTracker(ctx).phase(PhaseFinished, "")
+	polled, err = restores.Get(restore.ID)
+	require.NoError(t, err)
+	assert.Equal(t, PhaseRestoring, polled.Progress.Phase)
+
+	next, _, err := restores.Start(context.Background(), "manual_backup_2.sql", []string{"public.words"}, "admin")
+	require.NoError(t, err)
+	assert.Equal(t, RestoreSucceeded, restores.Finish(next.ID, &RestoreResult{Success: true}, nil).State)
+	list := restores.List()
+	require.Len(t, list, 2)
+	assert.Equal(t, next.ID, list[0].ID)
+}
+
+func TestRestoresKeepRecentFinished(t *testing.T) {
+	restores := NewRestores(nil)
+	var first string
+	for i := 0; i <= maxFinishedRestores+1; i++ {
+		restore, _, err := restores.Start(context.Background(), "manual_backup_1.sql", nil, "admin")
+		require.NoError(t, err)
+		if i == 0 {
+			first = restore.ID
+		}
+		restores.Finish(restore.ID, nil, errors.New("backup file not found: manual_backup_1.sql"))
+	}
+	assert.Len(t, restores.List(), maxFinishedRestores+1)
+	_, err := restores.Get(first)
+	assert.ErrorIs(t, err, ErrRestoreNotFound)
+}
+
+func TestRestoreProgressEstimate(t *testing.T) {
+	assert.Equal(t, 5.0, RestoreProgress{Phase: PhaseSafetyBackup}.estimate())
+	assert.Equal(t, 30.0, RestoreProgress{Phase: PhaseRestoring}.estimate())
+	assert.Equal(t, 45.0, RestoreProgress{Phase: PhaseRestoring, RowsRestored: 25, RowsTotal: 100}.estimate())
+	// Tables are counted when the backups have no rows
+	assert.Equal(t, 50.0, RestoreProgress{Phase: PhaseRestoring, TablesRestored: 1, TablesTotal: 3}.estimate())
+	assert.Equal(t, 90.0, RestoreProgress{Phase: PhaseRestoring, RowsRestored: 120, RowsTotal: 100}.estimate())
+	assert.Equal(t, 0.0, RestoreProgress{Phase: PhaseRollingBack}.estimate())
+	assert.Equal(t, 100.0, RestoreProgress{Phase: PhaseFinished}.estimate())
+}
@@ -0,0 +1,245 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/toeic-app/internal/config"
+	"github.com/toeic-app/internal/logger"
+)
+
+// ErrInvalidRetention is returned for a retention policy that keeps nothing
+var ErrInvalidRetention = errors.New("a retention policy keeps at least one latest, daily, weekly or monthly backup")
+
+// Reasons a backup is kept by a retention policy
+const (
+	RetainLast      = "last" // One of the newest backups
+	RetainDaily     = "daily"
+	RetainWeekly    = "weekly"
+	RetainMonthly   = "monthly"
+	RetainChain     = "chain"     // A kept incremental or differential backup builds on it
+	RetainProtected = "protected" // Protected in the catalog
+	RetainFull      = "full"      // The newest valid full backup, when no other is kept
+)
+
+// RetentionPolicy keeps backups grandfather-father-son: the newest backup
+// of each of the Daily most recent days that have a backup, of each of the
+// Weekly most recent ISO weeks and of each of the Monthly most recent
+// months, and the Last newest backups whenever they were made. Periods
+// without a backup do not count, so backups are never thinned out while no
+// new ones are made.
+type RetentionPolicy struct {
+	Last    int `json:"last,omitempty"`
+	Daily   int `json:"daily"`
+	Weekly  int `json:"weekly"`
+	Monthly int `json:"monthly"`
+}
+
+// NewRetentionPolicy returns the retention policy of the configuration
+func NewRetentionPolicy(cfg config.BackupConfig) RetentionPolicy {
+	return RetentionPolicy{Daily: cfg.RetentionDaily, Weekly: cfg.RetentionWeekly, Monthly: cfg.RetentionMonthly}
+}
+
+// Validate checks that the policy keeps at least one backup
+func (p RetentionPolicy) Validate() error {
+	if p.Last < 0 || p.Daily < 0 || p.Weekly < 0 || p.Monthly < 0 || p.Last+p.Daily+p.Weekly+p.Monthly == 0 {
+		return ErrInvalidRetention
+	}
+	return nil
+}
+
+func (p RetentionPolicy) String() string {
+	periods := fmt.Sprintf("%d daily, %d weekly, %d monthly", p.Daily, p.Weekly, p.Monthly)
+	if p.Last > 0 {
+		return fmt.Sprintf("%d latest, %s", p.Last, periods)
+	}
+	return periods
+}
+
+// RetainedBackup is a backup kept by a retention policy
+type RetainedBackup struct {
+	StoredFile
+	Reasons []string `json:"reasons"`
+}
+
+// RetentionPlan lists the backups a retention policy keeps and removes,
+// newest first
+type RetentionPlan struct {
+	Policy RetentionPolicy  `json:"policy"`
+	Keep   []RetainedBackup `json:"keep"`
+	Remove []StoredFile     `json:"remove"`
+}
+
+// plan keeps the Last newest backups, then sorts backups into the daily,
+// weekly and monthly periods of their modification time and keeps the
+// newest of each period until the policy has as many periods as it keeps
+func (p RetentionPolicy) plan(files []StoredFile) *RetentionPlan {
+	sorted := append([]StoredFile(nil), files...)
+	sortNewestFirst(sorted)
+
+	periods := []struct {
+		reason string
+		keep   int
+		key    func(time.Time) string
+	}{
+		{RetainDaily, p.Daily, func(t time.Time) string { return t.Format("2006-01-02") }},
+		{RetainWeekly, p.Weekly, func(t time.Time) string {
+			year, week := t.ISOWeek()
+			return fmt.Sprintf("%d-W%02d", year, week)
+		}},
+		{RetainMonthly, p.Monthly, func(t time.Time) string { return t.Format("2006-01") }},
+	}
+
+	reasons := make([][]string, len(sorted))
+	for i := range min(p.Last, len(sorted)) {
+		reasons[i] = append(reasons[i], RetainLast)
+	}
+	for _, period := range periods {
+		seen := make(map[string]bool)
+		for i, file := range sorted {
+			if len(seen) == period.keep {
+				break
+			}
+			key := period.key(file.ModTime.Local())
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			reasons[i] = append(reasons[i], period.reason)
+		}
+	}
+
+	plan := &RetentionPlan{Policy: p, Keep: []RetainedBackup{}, Remove: []StoredFile{}}
+	for i, file := range sorted {
+		if len(reasons[i]) > 0 {
+			plan.Keep = append(plan.Keep, RetainedBackup{StoredFile: file, Reasons: reasons[i]})
+		} else {
+			plan.Remove = append(plan.Remove, file)
+		}
+	}
+	return plan
+}
+
+// retain keeps the named backups for reason, moving them from Remove to
+// Keep; those already kept are kept for reason too
+func (plan *RetentionPlan) retain(reason string, names map[string]bool) {
+	if len(names) == 0 {
+		return
+	}
+	for i, kept := range plan.Keep {
+		if names[kept.Name] && !slices.Contains(kept.Reasons, reason) {
+			plan.Keep[i].Reasons = append(kept.Reasons, reason)
+		}
+	}
+	remove := plan.Remove[:0]
+	for _, file := range plan.Remove {
+		if names[file.Name] {
+			plan.Keep = append(plan.Keep, RetainedBackup{StoredFile: file, Reasons: []string{reason}})
+		} else {
+			remove = append(remove, file)
+		}
+	}
+	plan.Remove = remove
+	sort.SliceStable(plan.Keep, func(i, j int) bool {
+		return plan.Keep[i].ModTime.After(plan.Keep[j].ModTime)
+	})
+}
+
+// RetentionPolicy returns the configured retention policy
+func (bm *BackupManager) RetentionPolicy() RetentionPolicy {
+	return NewRetentionPolicy(bm.config)
+}
+
+// PlanRetention works out which backups in storage a retention policy keeps
+// and which it removes, without removing any. Protected backups, and the
+// backups kept incremental or differential backups build on, are kept
+// whatever the policy.
+func (bm *BackupManager) PlanRetention(ctx context.Context, policy RetentionPolicy) (*RetentionPlan, error) {
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	files, err := bm.ListBackups(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s backups: %w", bm.storage.Type(), err)
+	}
+	protected, err := bm.protectedBackups(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := policy.plan(files)
+	plan.retain(RetainProtected, protected)
+	kept := make([]string, len(plan.Keep))
+	for i, backup := range plan.Keep {
+		kept[i] = backup.Name
+	}
+	plan.retain(RetainChain, bm.chainDependencies(ctx, kept))
+	return plan, nil
+}
+
+// PlanPrune works out which backups a retention policy keeps like
+// PlanRetention, and also keeps the newest full backup whose files match
+// its manifest when the policy would keep no such backup, so the database
+// can always be restored. Backups are downloaded from remote storage to be
+// checked.
+func (bm *BackupManager) PlanPrune(ctx context.Context, policy RetentionPolicy) (*RetentionPlan, error) {
+	plan, err := bm.PlanRetention(ctx, policy)
+	if err != nil {
+		return nil, err
+	}
+	for _, kept := range plan.Keep {
+		if bm.validFullBackup(ctx, kept.Name) {
+			return plan, nil
+		}
+	}
+	for _, file := range plan.Remove {
+		if bm.validFullBackup(ctx, file.Name) {
+			plan.retain(RetainFull, map[string]bool{file.Name: true})
+			break
+		}
+	}
+	return plan, nil
+}
+
+// validFullBackup reports whether a backup restores on its own and its
+// files match its manifest. Backups made before manifests existed count as
+// valid.
+func (bm *BackupManager) validFullBackup(ctx context.Context, filename string) bool {
+	if metadata, err := bm.fetchMetadata(ctx, filename); err == nil && metadata.BackupMode() != ModeFull {
+		return false
+	}
+	if _, err := bm.VerifyManifest(ctx, filename); err != nil && !errors.Is(err, ErrManifestNotFound) {
+		logger.Warn("Backup %s does not match its manifest: %v", filename, err)
+		return false
+	}
+	return true
+}
+
+// ApplyRetention deletes the backups a plan removes, with their metadata
+// and local copies, and returns how many were deleted
+func (bm *BackupManager) ApplyRetention(ctx context.Context, plan *RetentionPlan) int {
+	deleted := 0
+	for _, file := range plan.Remove {
+		if err := bm.DeleteBackup(ctx, file.Name); err != nil {
+			logger.Warn("Failed to delete expired backup %s: %v", file.Name, err)
+			continue
+		}
+		logger.Debug("Deleted expired backup: %s (%s)", file.Name, file.ModTime.Format(time.RFC3339))
+		deleted++
+	}
+	return deleted
+}
+
+// EnforceRetention deletes the backups the configured retention policy
+// does not keep
+func (bm *BackupManager) EnforceRetention(ctx context.Context) (*RetentionPlan, int, error) {
+	plan, err := bm.PlanRetention(ctx, bm.RetentionPolicy())
+	if err != nil {
+		return nil, 0, err
+	}
+	return plan, bm.ApplyRetention(ctx, plan), nil
+}
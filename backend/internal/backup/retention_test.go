@@ -0,0 +1,149 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toeic-app/internal/config"
+)
+
+func TestRetentionPlan(t *testing.T) {
+	at := func(name string, month time.Month, day, hour int) StoredFile {
+		return StoredFile{Name: name, ModTime: time.Date(2025, month, day, hour, 0, 0, 0, time.Local)}
+	}
+	files := []StoredFile{
+		at("jan31.sql", time.January, 31, 3),
+		at("feb28.sql", time.February, 28, 3),
+		at("mar09.sql", time.March, 9, 3),  // Sunday, week 10
+		at("mar10.sql", time.March, 10, 3), // Monday, week 11
+		at("mar14.sql", time.March, 14, 3),
+		at("mar15_early.sql", time.March, 15, 3),
+		at("mar15_late.sql", time.March, 15, 21),
+	}
+
+	plan := RetentionPolicy{Daily: 2, Weekly: 2, Monthly: 3}.plan(files)
+
+	reasons := map[string][]string{}
+	for _, kept := range plan.Keep {
+		reasons[kept.Name] = kept.Reasons
+	}
+	assert.Equal(t, map[string][]string{
+		"mar15_late.sql": {RetainDaily, RetainWeekly, RetainMonthly},
+		"mar14.sql":      {RetainDaily},
+		"mar09.sql":      {RetainWeekly},
+		"feb28.sql":      {RetainMonthly},
+		"jan31.sql":      {RetainMonthly},
+	}, reasons)
+	assert.Equal(t, "mar15_late.sql", plan.Keep[0].Name)
+
+	removed := make([]string, len(plan.Remove))
+	for i, file := range plan.Remove {
+		removed[i] = file.Name
+	}
+	assert.Equal(t, []string{"mar15_early.sql", "mar10.sql"}, removed)
+}
+
+func TestRetentionPolicyValidate(t *testing.T) {
+	assert.NoError(t, RetentionPolicy{Monthly: 1}.Validate())
+	assert.ErrorIs(t, RetentionPolicy{}.Validate(), ErrInvalidRetention)
+	assert.ErrorIs(t, RetentionPolicy{Daily: 7, Weekly: -1}.Validate(), ErrInvalidRetention)
+	assert.NoError(t, RetentionPolicy{Last: 10}.Validate())
+	assert.Equal(t, "10 latest, 0 daily, 8 weekly, 0 monthly", RetentionPolicy{Last: 10, Weekly: 8}.String())
+}
+
+func TestRetentionPlanKeepLast(t *testing.T) {
+	var files []StoredFile
+	for i := range 5 {
+		files = append(files, StoredFile{Name: fmt.Sprintf("manual_backup_%d.sql", i), ModTime: time.Date(2025, time.March, 10+i, 3, 0, 0, 0, time.Local)})
+	}
+
+	plan := RetentionPolicy{Last: 2, Weekly: 1}.plan(files)
+
+	require.Len(t, plan.Keep, 2)
+	assert.Equal(t, []string{RetainLast, RetainWeekly}, plan.Keep[0].Reasons)
+	assert.Equal(t, "manual_backup_3.sql", plan.Keep[1].Name)
+	assert.Equal(t, []string{RetainLast}, plan.Keep[1].Reasons)
+	assert.Len(t, plan.Remove, 3)
+}
+
+func TestPlanPruneKeepsProtectedAndFullBackups(t *testing.T) {
+	dir := t.TempDir()
+	bm := NewBackupManager(config.BackupConfig{BackupDir: dir}, config.Config{DBName: "toeic"})
+	bm.SetCatalog(memoryCatalog{})
+	ctx := context.Background()
+	writes := map[string]int64{"public.users": 1}
+	backup := func(metadata BackupMetadata, age time.Duration) {
+		metadata.DatabaseName, metadata.TableWrites, metadata.CreatedAt = "toeic", writes, time.Now().Add(-age)
+		writeChainBackup(t, bm, metadata, age)
+	}
+
+	backup(BackupMetadata{Filename: "manual_backup_1.sql", Mode: ModeFull}, 72*time.Hour)
+	backup(BackupMetadata{Filename: "manual_backup_2.sql", Mode: ModeFull}, 48*time.Hour)
+	// Builds on a backup that is gone, so it keeps no full backup
+	backup(BackupMetadata{Filename: "manual_incremental_backup_3.sql", Mode: ModeIncremental,
+		Parent: "manual_backup_0.sql", Base: "manual_backup_0.sql"}, time.Minute)
+	_, err := bm.ReconcileCatalog(ctx, false)
+	require.NoError(t, err)
+	kept := func(plan *RetentionPlan) map[string][]string {
+		reasons := map[string][]string{}
+		for _, kept := range plan.Keep {
+			reasons[kept.Name] = kept.Reasons
+		}
+		return reasons
+	}
+
+	// The newest full backup is kept as no other full backup is
+	plan, err := bm.PlanPrune(ctx, RetentionPolicy{Last: 1})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"manual_incremental_backup_3.sql": {RetainLast},
+		"manual_backup_2.sql":             {RetainFull},
+	}, kept(plan))
+	require.Len(t, plan.Remove, 1)
+	assert.Equal(t, "manual_backup_1.sql", plan.Remove[0].Name)
+
+	// A protected full backup is kept instead
+	require.NoError(t, bm.ProtectBackup(ctx, "manual_backup_1.sql", true))
+	assert.ErrorIs(t, bm.ProtectBackup(ctx, "manual_backup_9.sql", true), ErrBackupNotFound)
+	plan, err = bm.PlanPrune(ctx, RetentionPolicy{Last: 1})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"manual_incremental_backup_3.sql": {RetainLast},
+		"manual_backup_1.sql":             {RetainProtected},
+	}, kept(plan))
+	require.Len(t, plan.Remove, 1)
+	assert.Equal(t, "manual_backup_2.sql", plan.Remove[0].Name)
+
+	assert.ErrorIs(t, bm.DeleteBackup(ctx, "manual_backup_1.sql"), ErrBackupProtected)
+	assert.FileExists(t, filepath.Join(dir, "manual_backup_1.sql"))
+	require.NoError(t, bm.ProtectBackup(ctx, "manual_backup_1.sql", false))
+	assert.NoError(t, bm.DeleteBackup(ctx, "manual_backup_1.sql"))
+}
+
+func TestEnforceRetentionKeepsChains(t *testing.T) {
+	dir := t.TempDir()
+	bm := NewBackupManager(config.BackupConfig{BackupDir: dir, RetentionDaily: 1}, config.Config{DBName: "toeic"})
+	writes := map[string]int64{"public.users": 1}
+
+	writeChainBackup(t, bm, BackupMetadata{Filename: "manual_backup_1.sql", DatabaseName: "toeic", Mode: ModeFull, TableWrites: writes}, 72*time.Hour)
+	writeChainBackup(t, bm, BackupMetadata{Filename: "manual_backup_2.sql", DatabaseName: "toeic", Mode: ModeFull, TableWrites: writes}, 48*time.Hour)
+	writeChainBackup(t, bm, BackupMetadata{Filename: "manual_incremental_backup_3.sql", DatabaseName: "toeic", Mode: ModeIncremental,
+		Parent: "manual_backup_2.sql", Base: "manual_backup_2.sql", TableWrites: writes}, time.Minute)
+
+	plan, deleted, err := bm.EnforceRetention(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+	require.Len(t, plan.Keep, 2)
+	assert.Equal(t, "manual_backup_2.sql", plan.Keep[1].Name)
+	assert.Equal(t, []string{RetainChain}, plan.Keep[1].Reasons)
+
+	assert.NoFileExists(t, filepath.Join(dir, "manual_backup_1.sql"))
+	assert.NoFileExists(t, filepath.Join(dir, "manual_backup_1.sql.meta"))
+	assert.FileExists(t, filepath.Join(dir, "manual_backup_2.sql"))
+	assert.FileExists(t, filepath.Join(dir, "manual_incremental_backup_3.sql"))
+}
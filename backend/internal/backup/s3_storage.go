@@ -0,0 +1,282 @@
+package backup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/toeic-app/internal/config"
+)
+
+// emptyPayloadHash is the SHA-256 of an empty body
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// S3Storage keeps backups in an S3 bucket or on an S3 compatible server
+// such as MinIO. Requests are signed with AWS Signature Version 4.
+type S3Storage struct {
+	cfg      config.S3BackupConfig
+	endpoint *url.URL
+	client   *http.Client
+	now      func() time.Time
+}
+
+// NewS3Storage creates a storage for the configured bucket. Without an
+// endpoint the AWS endpoint of the region is used.
+func NewS3Storage(cfg config.S3BackupConfig) (*S3Storage, error) {
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("S3 bucket is required")
+	}
+	if cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, fmt.Errorf("S3 access key and secret key are required")
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	rawEndpoint := cfg.Endpoint
+	if rawEndpoint == "" {
+		rawEndpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", cfg.Region)
+	}
+	endpoint, err := url.Parse(rawEndpoint)
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", rawEndpoint)
+	}
+	return &S3Storage{
+		cfg:      cfg,
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: 30 * time.Minute},
+		now:      time.Now,
+	}, nil
+}
+
+func (s *S3Storage) Type() string {
+	return "s3"
+}
+
+func (s *S3Storage) Upload(ctx context.Context, localPath, name string) error {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	headers := map[string]string{}
+	if s.cfg.StorageClass != "" {
+		headers["x-amz-storage-class"] = s.cfg.StorageClass
+	}
+	resp, err := s.do(ctx, http.MethodPut, s.cfg.Prefix+name, nil, file, size, hex.EncodeToString(hash.Sum(nil)), headers)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (s *S3Storage) Download(ctx context.Context, name, localPath string) error {
+	resp, err := s.do(ctx, http.MethodGet, s.cfg.Prefix+name, nil, nil, 0, emptyPayloadHash, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+		return err
+	}
+	tmp := localPath + ".part"
+	dest, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dest, resp.Body); err != nil {
+		dest.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("failed to download %s: %w", name, err)
+	}
+	if err := dest.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, localPath)
+}
+
+// listBucketResult is the ListObjectsV2 response
+type listBucketResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+func (s *S3Storage) List(ctx context.Context) ([]StoredFile, error) {
+	files := []StoredFile{}
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {s.cfg.Prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		resp, err := s.do(ctx, http.MethodGet, "", query, nil, 0, emptyPayloadHash, nil)
+		if err != nil {
+			return nil, err
+		}
+		var result listBucketResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode bucket listing: %w", err)
+		}
+
+		for _, object := range result.Contents {
+			name := strings.TrimPrefix(object.Key, s.cfg.Prefix)
+			// Objects in "folders" below the prefix are not backups
+			if name == "" || strings.Contains(name, "/") {
+				continue
+			}
+			files = append(files, StoredFile{Name: name, Size: object.Size, ModTime: object.LastModified})
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return files, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+func (s *S3Storage) Delete(ctx context.Context, name string) error {
+	// S3 answers a delete of a missing key with success, so check first
+	head, err := s.do(ctx, http.MethodHead, s.cfg.Prefix+name, nil, nil, 0, emptyPayloadHash, nil)
+	if err != nil {
+		return err
+	}
+	head.Body.Close()
+
+	resp, err := s.do(ctx, http.MethodDelete, s.cfg.Prefix+name, nil, nil, 0, emptyPayloadHash, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// s3Error is the error document S3 returns
+type s3Error struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// do sends a signed request for key, or for the bucket when key is empty.
+// Responses other than 2xx are returned as errors, 404 as ErrBackupNotFound.
+func (s *S3Storage) do(ctx context.Context, method, key string, query url.Values, body io.Reader, size int64, payloadHash string, headers map[string]string) (*http.Response, error) {
+	target := *s.endpoint
+	path := strings.TrimRight(target.Path, "/")
+	if s.cfg.ForcePathStyle {
+		path += "/" + s.cfg.Bucket
+	} else {
+		target.Host = s.cfg.Bucket + "." + target.Host
+	}
+	if key != "" || path == "" {
+		path += "/" + key
+	}
+	target.Path = path
+	target.RawPath = s3EscapePath(path)
+	target.RawQuery = s3CanonicalQuery(query)
+
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	s.sign(req, payloadHash)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("S3 %s %s failed: %w", method, key, err)
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound && key != "" {
+		return nil, ErrBackupNotFound
+	}
+	var apiErr s3Error
+	if err := xml.NewDecoder(io.LimitReader(resp.Body, 64*1024)).Decode(&apiErr); err == nil && apiErr.Code != "" {
+		return nil, fmt.Errorf("S3 %s %s failed with status %d: %s: %s", method, key, resp.StatusCode, apiErr.Code, apiErr.Message)
+	}
+	return nil, fmt.Errorf("S3 %s %s failed with status %d", method, key, resp.StatusCode)
+}
+
+// sign adds the AWS Signature Version 4 headers to req
+func (s *S3Storage) sign(req *http.Request, payloadHash string) {
+	signV4(req, payloadHash, awsCredentials{
+		AccessKeyID:     s.cfg.AccessKeyID,
+		SecretAccessKey: s.cfg.SecretAccessKey,
+		Region:          s.cfg.Region,
+		Service:         "s3",
+	}, s.now())
+}
+
+// s3Escape percent-encodes everything but the unreserved characters, as
+// Signature Version 4 requires
+func s3Escape(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func s3EscapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = s3Escape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// s3CanonicalQuery encodes the query sorted by key
+func s3CanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		for _, value := range query[key] {
+			parts = append(parts, s3Escape(key)+"="+s3Escape(value))
+		}
+	}
+	return strings.Join(parts, "&")
+}
@@ -0,0 +1,157 @@
+package backup
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toeic-app/internal/config"
+)
+
+// fakeS3 is an in-memory bucket answering path-style requests
+type fakeS3 struct {
+	mu      sync.Mutex
+	bucket  string
+	objects map[string][]byte
+	auth    []string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.auth = append(f.auth, r.Header.Get("Authorization"))
+
+	key := strings.TrimPrefix(r.URL.Path, "/"+f.bucket)
+	key = strings.TrimPrefix(key, "/")
+	switch {
+	case r.Method == http.MethodGet && key == "":
+		prefix := r.URL.Query().Get("prefix")
+		var result struct {
+			XMLName  xml.Name `xml:"ListBucketResult"`
+			Contents []struct {
+				Key          string
+				Size         int
+				LastModified string
+			}
+		}
+		for name, data := range f.objects {
+			if strings.HasPrefix(name, prefix) {
+				result.Contents = append(result.Contents, struct {
+					Key          string
+					Size         int
+					LastModified string
+				}{name, len(data), "2026-05-01T10:00:00.000Z"})
+			}
+		}
+		xml.NewEncoder(w).Encode(result)
+	case r.Method == http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		f.objects[key] = data
+	case r.Method == http.MethodGet, r.Method == http.MethodHead:
+		data, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method == http.MethodGet {
+			w.Write(data)
+		}
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func newTestS3Storage(t *testing.T) (*S3Storage, *fakeS3) {
+	fake := &fakeS3{bucket: "backups", objects: map[string][]byte{}}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	storage, err := NewS3Storage(config.S3BackupConfig{
+		Bucket:          "backups",
+		Region:          "eu-west-1",
+		Prefix:          "toeic/",
+		Endpoint:        srv.URL,
+		ForcePathStyle:  true,
+		AccessKeyID:     "minio",
+		SecretAccessKey: "minio-secret",
+	})
+	require.NoError(t, err)
+	storage.now = func() time.Time { return time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC) }
+	return storage, fake
+}
+
+func TestS3StorageRoundTrip(t *testing.T) {
+	storage, fake := newTestS3Storage(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	local := filepath.Join(dir, "backup_20260501.sql")
+	require.NoError(t, os.WriteFile(local, []byte("SELECT 1;"), 0644))
+	require.NoError(t, storage.Upload(ctx, local, "backup_20260501.sql"))
+	assert.Equal(t, []byte("SELECT 1;"), fake.objects["toeic/backup_20260501.sql"])
+	assert.True(t, strings.HasPrefix(fake.auth[0],
+		"AWS4-HMAC-SHA256 Credential=minio/20260501/eu-west-1/s3/aws4_request, SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature="))
+
+	// Objects in folders below the prefix are not listed
+	fake.objects["toeic/archive/old.sql"] = []byte("old")
+	files, err := storage.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, "backup_20260501.sql", files[0].Name)
+	assert.Equal(t, int64(9), files[0].Size)
+
+	restored := filepath.Join(dir, "restored", "backup.sql")
+	require.NoError(t, storage.Download(ctx, "backup_20260501.sql", restored))
+	data, err := os.ReadFile(restored)
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT 1;", string(data))
+
+	require.NoError(t, storage.Delete(ctx, "backup_20260501.sql"))
+	assert.ErrorIs(t, storage.Delete(ctx, "backup_20260501.sql"), ErrBackupNotFound)
+	assert.ErrorIs(t, storage.Download(ctx, "backup_20260501.sql", restored), ErrBackupNotFound)
+}
+
+func TestBackupManagerWithRemoteStorage(t *testing.T) {
+	storage, fake := newTestS3Storage(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	bm := NewBackupManager(config.BackupConfig{BackupDir: dir}, config.Config{})
+	bm.storage = storage
+
+	fake.objects["toeic/backup_new.sql.gz"] = []byte("new")
+	fake.objects["toeic/backup_new.sql.gz.meta"] = []byte("{}")
+	fake.objects["toeic/notes.txt"] = []byte("not a backup")
+
+	backups, err := bm.ListBackups(ctx)
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	assert.Equal(t, "backup_new.sql.gz", backups[0].Name)
+
+	path, err := bm.LocalCopy(ctx, "backup_new.sql.gz")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "backup_new.sql.gz"), path)
+	assert.FileExists(t, path+".meta")
+
+	require.NoError(t, bm.DeleteBackup(ctx, "backup_new.sql.gz"))
+	assert.NotContains(t, fake.objects, "toeic/backup_new.sql.gz")
+	assert.NotContains(t, fake.objects, "toeic/backup_new.sql.gz.meta")
+	assert.NoFileExists(t, path)
+
+	// Cleanup removes old objects from the bucket
+	fake.objects["toeic/backup_old.sql"] = []byte("old")
+	require.NoError(t, bm.CleanupOldBackups(24*time.Hour))
+	assert.NotContains(t, fake.objects, "toeic/backup_old.sql")
+}
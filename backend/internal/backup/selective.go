@@ -0,0 +1,280 @@
+package backup
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/toeic-app/internal/logger"
+)
+
+// ErrUnknownTable is returned when a table to restore is not in the backup
+// or not in the live database
+var ErrUnknownTable = errors.New("unknown table")
+
+// catalogTable is the table of the backup catalog
+const catalogTable = "public.backups"
+
+// RestoreOptions narrows what RestoreBackup restores
+type RestoreOptions struct {
+	// Tables, as name or schema.name, are the only tables restored; the
+	// rest of the database is left as it is. Empty restores everything.
+	Tables []string
+}
+
+// restoresTable reports whether a restore with the options replaces the
+// rows of a table
+func (o RestoreOptions) restoresTable(table string) bool {
+	return len(o.Tables) == 0 || slices.Contains(normalizeTables(o.Tables), table)
+}
+
+// tableReference is a foreign key between two tables of the live database
+type tableReference struct {
+	Table    string // schema.name of the referencing table
+	RefTable string // schema.name of the referenced table
+	Key      foreignKey
+}
+
+// normalizeTables qualifies table names with the public schema and drops
+// duplicates
+func normalizeTables(tables []string) []string {
+	var normalized []string
+	for _, table := range tables {
+		table = strings.TrimSpace(table)
+		if table == "" {
+			continue
+		}
+		if !strings.Contains(table, ".") {
+			table = "public." + table
+		}
+		if !slices.Contains(normalized, table) {
+			normalized = append(normalized, table)
+		}
+	}
+	return normalized
+}
+
+// referenceOrder sorts tables so that the tables a foreign key references
+// come before the tables referencing them. Only references between the
+// given tables count; tables of a reference cycle are sorted by name.
+func referenceOrder(tables []string, references []tableReference) []string {
+	parents := make(map[string]map[string]bool, len(tables))
+	for _, table := range tables {
+		parents[table] = make(map[string]bool)
+	}
+	for _, ref := range references {
+		if ref.Table == ref.RefTable {
+			continue
+		}
+		if _, ok := parents[ref.Table]; ok && slices.Contains(tables, ref.RefTable) {
+			parents[ref.Table][ref.RefTable] = true
+		}
+	}
+
+	remaining := append([]string{}, tables...)
+	sort.Strings(remaining)
+	ordered := make([]string, 0, len(tables))
+	placed := make(map[string]bool, len(tables))
+	for len(remaining) > 0 {
+		next := -1
+		for i, table := range remaining {
+			ready := true
+			for parent := range parents[table] {
+				ready = ready && placed[parent]
+			}
+			if ready {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			// A cycle; foreign keys are only checked once every table is loaded
+			next = 0
+		}
+		placed[remaining[next]] = true
+		ordered = append(ordered, remaining[next])
+		remaining = append(remaining[:next], remaining[next+1:]...)
+	}
+	return ordered
+}
+
+// tableRestoreScript returns the statements run before and after the data
+// of the tables, given in reference order, is loaded. The rows are replaced
+// in one transaction: referencing tables are emptied first, triggers do not
+// fire, so cascades leave the other tables alone, and once the rows are
+// loaded every foreign key touching the tables is checked. A failure rolls
+// everything back.
+func tableRestoreScript(ordered []string, references []tableReference) (string, string) {
+	var head strings.Builder
+	fmt.Fprintf(&head, "-- Restore of %d tables\n", len(ordered))
+	head.WriteString("\\set ON_ERROR_STOP on\n")
+	head.WriteString("BEGIN;\n")
+	head.WriteString("SET LOCAL session_replication_role = replica;\n")
+	for i := len(ordered) - 1; i >= 0; i-- {
+		fmt.Fprintf(&head, "DELETE FROM %s;\n", quoteQualifiedName(ordered[i]))
+	}
+
+	var tail strings.Builder
+	tail.WriteString("SET LOCAL session_replication_role = DEFAULT;\n")
+	for _, ref := range references {
+		if !slices.Contains(ordered, ref.Table) && !slices.Contains(ordered, ref.RefTable) {
+			continue
+		}
+		message := fmt.Sprintf("rows of %s reference missing rows of %s (%s)", ref.Table, ref.RefTable, ref.Key.Name)
+		fmt.Fprintf(&tail, "DO $$BEGIN IF (%s) > 0 THEN RAISE EXCEPTION USING MESSAGE = %s; END IF; END$$;\n",
+			ref.Key.orphanQuery(), quoteLiteral(message))
+	}
+	tail.WriteString("COMMIT;\n")
+	return head.String(), tail.String()
+}
+
+// liveReferences lists the foreign keys of the live database
+func (bm *BackupManager) liveReferences(ctx context.Context) ([]tableReference, error) {
+	rows, err := bm.query(ctx, `SELECT con.conname, cn.nspname || '.' || c.relname, fn.nspname || '.' || f.relname,
+		(SELECT string_agg(quote_ident(a.attname), ',' ORDER BY k.i) FROM unnest(con.conkey) WITH ORDINALITY k(attnum, i)
+			JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum),
+		(SELECT string_agg(quote_ident(a.attname), ',' ORDER BY k.i) FROM unnest(con.confkey) WITH ORDINALITY k(attnum, i)
+			JOIN pg_attribute a ON a.attrelid = con.confrelid AND a.attnum = k.attnum)
+		FROM pg_constraint con
+		JOIN pg_class c ON c.oid = con.conrelid JOIN pg_namespace cn ON cn.oid = c.relnamespace
+		JOIN pg_class f ON f.oid = con.confrelid JOIN pg_namespace fn ON fn.oid = f.relnamespace
+		WHERE con.contype = 'f' ORDER BY 1`)
+	if err != nil {
+		return nil, err
+	}
+	references := make([]tableReference, 0, len(rows))
+	for _, row := range rows {
+		if len(row) != 5 {
+			continue
+		}
+		references = append(references, tableReference{
+			Table:    row[1],
+			RefTable: row[2],
+			Key: foreignKey{Name: row[0], Table: quoteQualifiedName(row[1]), RefTable: quoteQualifiedName(row[2]),
+				Columns: strings.Split(row[3], ","), RefColumns: strings.Split(row[4], ",")},
+		})
+	}
+	return references, nil
+}
+
+// checkTables makes sure the tables to restore are in the backup and in
+// the live database
+func (bm *BackupManager) checkTables(ctx context.Context, tables []string, contents dumpContents) error {
+	rows, err := bm.query(ctx, "SELECT schemaname || '.' || tablename FROM pg_tables")
+	if err != nil {
+		return fmt.Errorf("failed to list the live tables: %w", err)
+	}
+	live := make([]string, 0, len(rows))
+	for _, row := range rows {
+		live = append(live, row[0])
+	}
+	for _, table := range tables {
+		if !slices.Contains(contents.Tables, table) {
+			return fmt.Errorf("%w: %s is not in the backup", ErrUnknownTable, table)
+		}
+		if !slices.Contains(live, table) {
+			return fmt.Errorf("%w: %s is not in the live database, run the migrations first", ErrUnknownTable, table)
+		}
+	}
+	return nil
+}
+
+// restoreTables replaces the rows of some tables with those of a backup
+// chain. The chain is restored into a scratch database, from which the
+// tables are copied in reference order.
+func (bm *BackupManager) restoreTables(ctx context.Context, chain, sqlPaths, tables []string, result *RestoreResult) error {
+	scratch, temporary, err := bm.scratchDatabase(ctx, bm.config.VerifyScratchDB, "restore", time.Now())
+	if err != nil {
+		return err
+	}
+	if temporary {
+		defer bm.dropDatabase(scratch)
+	}
+
+	tracker := progressTracker(ctx)
+	var restoreErrors []string
+	for i, sqlPath := range sqlPaths {
+		tracker.phase(PhaseStaging, chain[i])
+		output, err := bm.restoreInto(ctx, scratch, sqlPath)
+		if err != nil {
+			return fmt.Errorf("failed to restore %s into %s: %w", chain[i], scratch, err)
+		}
+		restoreErrors = append(restoreErrors, psqlErrors(output)...)
+	}
+	if len(restoreErrors) > 0 {
+		logger.Warn("Restoring %s into %s reported errors: %s", chain[len(chain)-1], scratch, summarizeErrors(restoreErrors))
+		result.Warnings = append(result.Warnings, "Backup restored into the scratch database with errors: "+summarizeErrors(restoreErrors))
+	}
+
+	references, err := bm.liveReferences(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list foreign keys: %w", err)
+	}
+	ordered := referenceOrder(tables, references)
+	logger.Info("Restoring tables in reference order: %s", strings.Join(ordered, ", "))
+
+	script, err := os.CreateTemp(bm.config.BackupDir, "table_restore_*.sql")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(script.Name())
+	defer script.Close()
+
+	head, tail := tableRestoreScript(ordered, references)
+	writer := bufio.NewWriter(script)
+	writer.WriteString(head)
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	// One table at a time, so the rows are loaded in reference order
+	for _, table := range ordered {
+		if err := bm.dumpDataFrom(ctx, scratch, script, []string{table}); err != nil {
+			return fmt.Errorf("failed to copy %s from %s: %w", table, scratch, err)
+		}
+	}
+	if _, err := io.WriteString(script, tail); err != nil {
+		return err
+	}
+	if err := script.Close(); err != nil {
+		return err
+	}
+
+	tracker.phase(PhaseRestoring, "")
+	if _, err := bm.restoreInto(ctx, bm.dbConfig.DBName, script.Name()); err != nil {
+		return fmt.Errorf("tables not restored, the database is unchanged: %w", err)
+	}
+	return nil
+}
+
+// tableRestoreChain prepares a selective restore before anything is
+// changed: it reads the backups of the chain and checks the tables to
+// restore. It returns the qualified tables and the rows the backup holds
+// for them.
+func (bm *BackupManager) tableRestoreChain(ctx context.Context, sqlPaths, tables []string) ([]string, int64, error) {
+	if bm.config.VerifyScratchDB != "" && bm.config.VerifyScratchDB == bm.dbConfig.DBName {
+		return nil, 0, ErrScratchIsLive
+	}
+	tables = normalizeTables(tables)
+	contents := dumpContents{Rows: make(map[string]int64)}
+	for _, sqlPath := range sqlPaths {
+		if err := bm.readDump(ctx, &contents, sqlPath); err != nil {
+			return nil, 0, fmt.Errorf("failed to read backup %s: %w", filepath.Base(sqlPath), err)
+		}
+	}
+	if err := bm.checkTables(ctx, tables, contents); err != nil {
+		return nil, 0, err
+	}
+	var records int64
+	for _, table := range tables {
+		records += contents.Rows[table]
+	}
+	return tables, records, nil
+}
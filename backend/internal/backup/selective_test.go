@@ -0,0 +1,52 @@
+package backup
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeTables(t *testing.T) {
+	assert.Equal(t, []string{"public.users", "audit.events"}, normalizeTables([]string{" users", "audit.events", "", "public.users"}))
+
+	assert.True(t, RestoreOptions{}.restoresTable(catalogTable))
+	assert.True(t, RestoreOptions{Tables: []string{"backups"}}.restoresTable(catalogTable))
+	assert.False(t, RestoreOptions{Tables: []string{"users"}}.restoresTable(catalogTable))
+}
+
+func TestReferenceOrder(t *testing.T) {
+	references := []tableReference{
+		{Table: "public.user_answers", RefTable: "public.questions"},
+		{Table: "public.user_answers", RefTable: "public.users"},
+		{Table: "public.questions", RefTable: "public.exams"},
+		{Table: "public.users", RefTable: "public.users"}, // Self reference
+		{Table: "public.words", RefTable: "public.users"}, // Not restored
+	}
+
+	ordered := referenceOrder([]string{"public.user_answers", "public.users", "public.questions", "public.exams"}, references)
+	assert.Equal(t, []string{"public.exams", "public.questions", "public.users", "public.user_answers"}, ordered)
+
+	// A cycle does not lose tables
+	cycle := []tableReference{{Table: "public.a", RefTable: "public.b"}, {Table: "public.b", RefTable: "public.a"}}
+	assert.Equal(t, []string{"public.a", "public.b"}, referenceOrder([]string{"public.b", "public.a"}, cycle))
+}
+
+func TestTableRestoreScript(t *testing.T) {
+	references := []tableReference{
+		{Table: "public.user_answers", RefTable: "public.users", Key: foreignKey{Name: "user_answers_user_id_fkey",
+			Table: `"public"."user_answers"`, RefTable: `"public"."users"`, Columns: []string{"user_id"}, RefColumns: []string{"id"}}},
+		{Table: "public.words", RefTable: "public.exams", Key: foreignKey{Name: "words_exam_id_fkey"}},
+	}
+
+	head, tail := tableRestoreScript([]string{"public.users", "public.user_answers"}, references)
+	// Referencing tables are emptied first
+	assert.Contains(t, head, "BEGIN;\nSET LOCAL session_replication_role = replica;\nDELETE FROM \"public\".\"user_answers\";\nDELETE FROM \"public\".\"users\";\n")
+	assert.Contains(t, head, "ON_ERROR_STOP on")
+
+	// Only foreign keys touching the restored tables are checked
+	assert.Contains(t, tail, "user_answers_user_id_fkey")
+	assert.Contains(t, tail, `SELECT count(*) FROM "public"."user_answers" c WHERE c.user_id IS NOT NULL`)
+	assert.NotContains(t, tail, "words_exam_id_fkey")
+	assert.True(t, strings.HasSuffix(tail, "COMMIT;\n"))
+}
@@ -1,9 +1,13 @@
 package backup
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"sync"
 	"time"
 
@@ -17,6 +21,31 @@ type SimpleBackupMonitor struct {
 	metrics   *SimpleBackupMetrics
 	lastCheck time.Time
 	mutex     sync.RWMutex
+
+	// Periodic verification of stored backups against their manifests
+	verifier     BackupVerifier
+	verification *VerificationSummary // Last verification; nil before the first
+	runMutex     sync.Mutex
+	isVerifying  bool
+	cancel       context.CancelFunc
+	wg           sync.WaitGroup
+}
+
+// BackupVerifier checks stored backups against their manifests.
+// *BackupManager satisfies it.
+type BackupVerifier interface {
+	ListBackups(ctx context.Context) ([]StoredFile, error)
+	VerifyManifest(ctx context.Context, filename string) (bool, error)
+}
+
+// VerificationSummary is the outcome of checking every stored backup
+// against its manifest
+type VerificationSummary struct {
+	CheckedAt  time.Time         `json:"checked_at"`
+	Checked    int               `json:"checked"`
+	Unverified []string          `json:"unverified,omitempty"` // Backups without a manifest
+	Corrupted  map[string]string `json:"corrupted,omitempty"`  // Failing backups and why
+	Error      string            `json:"error,omitempty"`      // Why the backups could not be checked
 }
 
 // SimpleBackupMetrics holds basic backup metrics
@@ -36,6 +65,7 @@ type SimpleHealthStatus struct {
 	BackupDir    string    `json:"backup_dir"`
 	DiskSpace    string    `json:"disk_space"`
 	RecentBackup string    `json:"recent_backup"`
+	Integrity    string    `json:"integrity"` // unknown until backups were verified
 	LastCheck    time.Time `json:"last_check"`
 	Issues       []string  `json:"issues,omitempty"`
 }
@@ -95,6 +125,7 @@ func (sbm *SimpleBackupMonitor) CheckHealth() *SimpleHealthStatus {
 		BackupDir:    "healthy",
 		DiskSpace:    "healthy",
 		RecentBackup: "healthy",
+		Integrity:    "unknown",
 		LastCheck:    time.Now(),
 		Issues:       []string{},
 	}
@@ -130,9 +161,151 @@ func (sbm *SimpleBackupMonitor) CheckHealth() *SimpleHealthStatus {
 		}
 	}
 
+	// Check the last verification of stored backups
+	if summary := sbm.verification; summary != nil {
+		switch {
+		case len(summary.Corrupted) > 0:
+			status.Integrity = "critical"
+			for _, name := range slices.Sorted(maps.Keys(summary.Corrupted)) {
+				status.Issues = append(status.Issues, fmt.Sprintf("Backup %s failed verification: %s", name, summary.Corrupted[name]))
+			}
+			status.Overall = "critical"
+		case summary.Error != "":
+			status.Integrity = "warning"
+			status.Issues = append(status.Issues, fmt.Sprintf("Backups could not be verified: %s", summary.Error))
+			if status.Overall == "healthy" {
+				status.Overall = "warning"
+			}
+		default:
+			status.Integrity = "healthy"
+		}
+	}
+
 	return status
 }
 
+// SetVerifier sets what stored backups are verified with
+func (sbm *SimpleBackupMonitor) SetVerifier(verifier BackupVerifier) {
+	sbm.mutex.Lock()
+	defer sbm.mutex.Unlock()
+	sbm.verifier = verifier
+}
+
+// Verification returns the last verification of stored backups, nil
+// before the first
+func (sbm *SimpleBackupMonitor) Verification() *VerificationSummary {
+	sbm.mutex.RLock()
+	defer sbm.mutex.RUnlock()
+	return sbm.verification
+}
+
+// VerifyBackups checks every stored backup against its manifest. Backups
+// that fail are reported by CheckHealth until a verification passes them.
+func (sbm *SimpleBackupMonitor) VerifyBackups(ctx context.Context) (*VerificationSummary, error) {
+	sbm.mutex.RLock()
+	verifier := sbm.verifier
+	sbm.mutex.RUnlock()
+	if verifier == nil {
+		return nil, fmt.Errorf("no backup verifier set")
+	}
+
+	summary := &VerificationSummary{Corrupted: map[string]string{}}
+	files, err := verifier.ListBackups(ctx)
+	if err != nil {
+		summary.Error = fmt.Sprintf("failed to list backups: %v", err)
+	}
+	for _, file := range files {
+		if ctx.Err() != nil {
+			// Keep the results of the last complete verification
+			return nil, ctx.Err()
+		}
+		_, err := verifier.VerifyManifest(ctx, file.Name)
+		switch {
+		case err == nil:
+			summary.Checked++
+		case errors.Is(err, ErrManifestNotFound):
+			summary.Unverified = append(summary.Unverified, file.Name)
+		case errors.Is(err, ErrManifestMismatch), errors.Is(err, ErrManifestSignature):
+			summary.Checked++
+			summary.Corrupted[file.Name] = err.Error()
+			logger.Error("Backup %s failed verification: %v", file.Name, err)
+		case errors.Is(err, ErrBackupNotFound):
+			// Deleted since it was listed
+		default:
+			logger.Warn("Failed to verify backup %s: %v", file.Name, err)
+			summary.Error = fmt.Sprintf("failed to verify %s: %v", file.Name, err)
+		}
+	}
+	summary.CheckedAt = time.Now()
+
+	sbm.mutex.Lock()
+	sbm.verification = summary
+	sbm.mutex.Unlock()
+	logger.Info("Verified %d backups: %d corrupted, %d without manifest", summary.Checked, len(summary.Corrupted), len(summary.Unverified))
+	return summary, nil
+}
+
+// StartVerification verifies stored backups now and then every
+// BACKUP_REVERIFY_INTERVAL
+func (sbm *SimpleBackupMonitor) StartVerification() error {
+	sbm.runMutex.Lock()
+	defer sbm.runMutex.Unlock()
+
+	if sbm.isVerifying {
+		return fmt.Errorf("backup verification is already running")
+	}
+	interval := sbm.config.ReverifyInterval
+	if interval <= 0 {
+		return fmt.Errorf("backup verification is disabled by BACKUP_REVERIFY_INTERVAL")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	sbm.isVerifying = true
+	sbm.cancel = cancel
+	sbm.wg.Add(1)
+	go func() {
+		defer sbm.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if _, err := sbm.VerifyBackups(ctx); err != nil && ctx.Err() == nil {
+				logger.Warn("Backup verification failed: %v", err)
+			}
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	logger.Info("Backup verification started with interval: %v", interval)
+	return nil
+}
+
+// StopVerification stops verifying stored backups, cancelling the
+// verification in progress
+func (sbm *SimpleBackupMonitor) StopVerification() error {
+	sbm.runMutex.Lock()
+	defer sbm.runMutex.Unlock()
+
+	if !sbm.isVerifying {
+		return fmt.Errorf("backup verification is not running")
+	}
+	sbm.cancel()
+	sbm.wg.Wait()
+	sbm.isVerifying = false
+
+	logger.Info("Backup verification stopped")
+	return nil
+}
+
+// IsVerifying returns whether stored backups are verified periodically
+func (sbm *SimpleBackupMonitor) IsVerifying() bool {
+	sbm.runMutex.Lock()
+	defer sbm.runMutex.Unlock()
+	return sbm.isVerifying
+}
+
 // Helper methods
 
 type backupDirectoryStats struct {
@@ -161,7 +334,7 @@ func (sbm *SimpleBackupMonitor) scanBackupDirectory() (*backupDirectoryStats, er
 
 		// Check for backup files
 		ext := filepath.Ext(file.Name())
-		if ext != ".sql" && ext != ".gz" && ext != ".enc" {
+		if ext != ".sql" && ext != ".gz" && ext != ".dump" && ext != ".tar" && ext != ".enc" {
 			continue
 		}
 
@@ -212,6 +385,8 @@ func (sbm *SimpleBackupMonitor) checkRecentBackup() bool {
 	for _, file := range files {
 		if !file.IsDir() && (filepath.Ext(file.Name()) == ".sql" ||
 			filepath.Ext(file.Name()) == ".gz" ||
+			filepath.Ext(file.Name()) == ".dump" ||
+			filepath.Ext(file.Name()) == ".tar" ||
 			filepath.Ext(file.Name()) == ".enc") {
 			fileInfo, err := file.Info()
 			if err == nil && fileInfo.ModTime().After(since) {
@@ -0,0 +1,158 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/toeic-app/internal/config"
+)
+
+// ErrBackupNotFound is returned for a backup that is not in storage
+var ErrBackupNotFound = errors.New("backup not found in storage")
+
+// StoredFile is a file kept in backup storage
+type StoredFile struct {
+	Name    string    `json:"name"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+// Storage keeps backup files. Backups are always written to the local
+// backup directory first and then handed to the storage.
+type Storage interface {
+	// Type names the backend as in BACKUP_STORAGE_TYPE
+	Type() string
+	// Upload stores the local file under name
+	Upload(ctx context.Context, localPath, name string) error
+	// Download writes the stored file to localPath, or returns
+	// ErrBackupNotFound
+	Download(ctx context.Context, name, localPath string) error
+	// List returns every stored file
+	List(ctx context.Context) ([]StoredFile, error)
+	// Delete removes a stored file, or returns ErrBackupNotFound
+	Delete(ctx context.Context, name string) error
+}
+
+// NewStorage creates the storage configured by BACKUP_STORAGE_TYPE
+func NewStorage(cfg config.BackupConfig) (Storage, error) {
+	switch cfg.StorageType {
+	case "", "local":
+		return NewLocalStorage(cfg.BackupDir), nil
+	case "s3":
+		if cfg.S3Config == nil {
+			return nil, fmt.Errorf("S3 configuration required when storage type is s3")
+		}
+		return NewS3Storage(*cfg.S3Config)
+	default:
+		return nil, fmt.Errorf("unsupported backup storage type %q", cfg.StorageType)
+	}
+}
+
+// LocalStorage keeps backups in a directory
+type LocalStorage struct {
+	dir string
+}
+
+// NewLocalStorage creates a storage for the files of dir
+func NewLocalStorage(dir string) *LocalStorage {
+	return &LocalStorage{dir: dir}
+}
+
+func (s *LocalStorage) Type() string {
+	return "local"
+}
+
+func (s *LocalStorage) Upload(_ context.Context, localPath, name string) error {
+	return copyFile(localPath, filepath.Join(s.dir, name))
+}
+
+func (s *LocalStorage) Download(_ context.Context, name, localPath string) error {
+	err := copyFile(filepath.Join(s.dir, name), localPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrBackupNotFound
+	}
+	return err
+}
+
+func (s *LocalStorage) List(_ context.Context) ([]StoredFile, error) {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []StoredFile{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup directory: %w", err)
+	}
+
+	files := make([]StoredFile, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, StoredFile{Name: entry.Name(), Size: info.Size(), ModTime: info.ModTime()})
+	}
+	return files, nil
+}
+
+func (s *LocalStorage) Delete(_ context.Context, name string) error {
+	err := os.Remove(filepath.Join(s.dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrBackupNotFound
+	}
+	return err
+}
+
+// copyFile copies src to dst unless both are the same file
+func copyFile(src, dst string) error {
+	srcInfo, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	if dstInfo, err := os.Stat(dst); err == nil && os.SameFile(srcInfo, dstInfo) {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+
+	source, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	tmp := dst + ".part"
+	dest, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dest, source); err != nil {
+		dest.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := dest.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, dst)
+}
+
+// sortNewestFirst orders stored files by modification time, newest first
+func sortNewestFirst(files []StoredFile) {
+	sort.Slice(files, func(i, j int) bool {
+		if !files[i].ModTime.Equal(files[j].ModTime) {
+			return files[i].ModTime.After(files[j].ModTime)
+		}
+		return files[i].Name < files[j].Name
+	})
+}
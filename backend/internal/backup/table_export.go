@@ -0,0 +1,287 @@
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/toeic-app/internal/export"
+)
+
+// Formats tables are exported in
+const (
+	ExportCSV    = "csv"    // A header row, then a row per table row
+	ExportNDJSON = "ndjson" // A JSON object per table row
+)
+
+// TableExport selects the rows and columns of a table to export
+type TableExport struct {
+	Table   string
+	Columns []string // Empty exports every column, in table order
+	Where   string   // SQL condition the rows meet; empty exports every row
+	Limit   int64    // Most rows exported; zero exports every row
+}
+
+// ExportTable writes the rows of a table to w as CSV or NDJSON and returns
+// how many it wrote. The rows are read in a read-only transaction, so the
+// condition cannot change data, and streamed as they are read. NULL is an
+// empty CSV cell, bytea is base64 encoded, and json columns are embedded
+// in NDJSON as they are.
+func ExportTable(ctx context.Context, conn *sql.DB, options TableExport, format string, w io.Writer) (int64, error) {
+	if format != ExportCSV && format != ExportNDJSON {
+		return 0, fmt.Errorf("unsupported export format %q, use csv or ndjson", format)
+	}
+	tx, err := conn.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	available, err := tableColumns(ctx, tx, options.Table)
+	if err != nil {
+		return 0, err
+	}
+	columns, err := selectColumns(options.Table, available, options.Columns)
+	if err != nil {
+		return 0, err
+	}
+
+	// The limit is a parameter, so the condition cannot add statements
+	limit := sql.NullInt64{Int64: options.Limit, Valid: options.Limit > 0}
+	rows, err := tx.QueryContext(ctx, exportQuery(options.Table, columns, options.Where), limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query %s: %w", options.Table, err)
+	}
+	defer rows.Close()
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return 0, err
+	}
+
+	var writeRow func(values []any) error
+	var flush func() error
+	if format == ExportCSV {
+		writer, _ := export.NewWriter(export.FormatCSV, w, options.Table)
+		if err := writer.Write(columns); err != nil {
+			return 0, err
+		}
+		cells := make([]string, len(columns))
+		writeRow = func(values []any) error {
+			for i, value := range values {
+				cells[i] = csvValue(value, types[i].DatabaseTypeName())
+			}
+			return writer.Write(cells)
+		}
+		flush = writer.Close
+	} else {
+		buffered := bufio.NewWriter(w)
+		encoder := json.NewEncoder(buffered)
+		encoder.SetEscapeHTML(false)
+		writeRow = func(values []any) error {
+			object := make(orderedObject, len(values))
+			for i, value := range values {
+				object[i] = objectField{Name: columns[i], Value: jsonValue(value, types[i].DatabaseTypeName())}
+			}
+			return encoder.Encode(object)
+		}
+		flush = buffered.Flush
+	}
+
+	var count int64
+	values := make([]any, len(columns))
+	pointers := make([]any, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(pointers...); err != nil {
+			return count, err
+		}
+		if err := writeRow(values); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return count, err
+	}
+	return count, flush()
+}
+
+// ExportableTables lists the tables of the current schema
+func ExportableTables(ctx context.Context, conn *sql.DB) ([]string, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT table_name FROM information_schema.tables
+		WHERE table_schema = current_schema() AND table_type = 'BASE TABLE' ORDER BY table_name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+	return tables, rows.Err()
+}
+
+// tableColumns returns the columns of a table of the current schema, in
+// table order
+func tableColumns(ctx context.Context, tx *sql.Tx, table string) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT column_name FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = $1 ORDER BY ordinal_position`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var columns []string
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return nil, err
+		}
+		columns = append(columns, column)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTable, table)
+	}
+	return columns, nil
+}
+
+// selectColumns returns the requested columns of a table, or all of them
+// when none are requested
+func selectColumns(table string, available, requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		return available, nil
+	}
+	columns := make([]string, 0, len(requested))
+	for _, column := range requested {
+		column = strings.TrimSpace(column)
+		if !slices.Contains(available, column) {
+			return nil, fmt.Errorf("%s has no column %q", table, column)
+		}
+		if !slices.Contains(columns, column) {
+			columns = append(columns, column)
+		}
+	}
+	return columns, nil
+}
+
+// exportQuery returns the query of the exported rows, taking the limit as
+// its parameter
+func exportQuery(table string, columns []string, where string) string {
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = quoteIdentifier(column)
+	}
+	query := "SELECT " + strings.Join(quoted, ", ") + " FROM " + quoteIdentifier(table)
+	if where = strings.TrimSpace(where); where != "" {
+		query += " WHERE (" + where + ")"
+	}
+	return query + " LIMIT $1"
+}
+
+// csvValue formats a column value of databaseType for CSV
+func csvValue(value any, databaseType string) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []byte:
+		if databaseType == "BYTEA" {
+			return base64.StdEncoding.EncodeToString(v)
+		}
+		return string(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// jsonValue returns the JSON value of a column value of databaseType
+func jsonValue(value any, databaseType string) any {
+	bytes, ok := value.([]byte)
+	if !ok {
+		switch v := value.(type) {
+		case time.Time:
+			return v.Format(time.RFC3339Nano)
+		case float64:
+			// NaN and infinity have no JSON number
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				return csvValue(v, databaseType)
+			}
+		}
+		return value
+	}
+	switch databaseType {
+	case "BYTEA":
+		return base64.StdEncoding.EncodeToString(bytes)
+	case "JSON", "JSONB":
+		return json.RawMessage(bytes)
+	case "NUMERIC":
+		// NaN and infinity have no JSON number
+		if json.Valid(bytes) {
+			return json.Number(bytes)
+		}
+		return string(bytes)
+	default:
+		return string(bytes)
+	}
+}
+
+// objectField is a field of an orderedObject
+type objectField struct {
+	Name  string
+	Value any
+}
+
+// orderedObject is a JSON object keeping the order of its fields, so NDJSON
+// rows list their columns in the exported order
+type orderedObject []objectField
+
+func (o orderedObject) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	// Text is written as it is, like the rows of the export
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	b.WriteByte('{')
+	for i, field := range o {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		if err := encoder.Encode(field.Name); err != nil {
+			return nil, err
+		}
+		b.Truncate(b.Len() - 1)
+		b.WriteByte(':')
+		if err := encoder.Encode(field.Value); err != nil {
+			return nil, err
+		}
+		b.Truncate(b.Len() - 1)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
@@ -0,0 +1,69 @@
+package backup
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportQuery(t *testing.T) {
+	assert.Equal(t, `SELECT "id", "word" FROM "words" LIMIT $1`, exportQuery("words", []string{"id", "word"}, "  "))
+	assert.Equal(t, `SELECT "id" FROM "user""s" WHERE (level > 2 OR id = 1) LIMIT $1`,
+		exportQuery(`user"s`, []string{"id"}, "level > 2 OR id = 1"))
+}
+
+func TestSelectColumns(t *testing.T) {
+	available := []string{"id", "word", "level"}
+
+	columns, err := selectColumns("words", available, nil)
+	require.NoError(t, err)
+	assert.Equal(t, available, columns)
+
+	columns, err = selectColumns("words", available, []string{"level", " id", "level"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"level", "id"}, columns)
+
+	_, err = selectColumns("words", available, []string{"meaning"})
+	assert.EqualError(t, err, `words has no column "meaning"`)
+}
+
+func TestCSVValue(t *testing.T) {
+	at := time.Date(2025, 6, 15, 12, 0, 0, 500, time.UTC)
+	assert.Equal(t, "", csvValue(nil, "TEXT"))
+	assert.Equal(t, "AAE=", csvValue([]byte{0, 1}, "BYTEA"))
+	assert.Equal(t, "12.50", csvValue([]byte("12.50"), "NUMERIC"))
+	assert.Equal(t, "2025-06-15T12:00:00.0000005Z", csvValue(at, "TIMESTAMPTZ"))
+	assert.Equal(t, "42", csvValue(int64(42), "INT8"))
+	assert.Equal(t, "0.5", csvValue(0.5, "FLOAT8"))
+	assert.Equal(t, "true", csvValue(true, "BOOL"))
+}
+
+func TestJSONValue(t *testing.T) {
+	assert.Equal(t, json.RawMessage(`{"a":1}`), jsonValue([]byte(`{"a":1}`), "JSONB"))
+	assert.Equal(t, json.Number("12.50"), jsonValue([]byte("12.50"), "NUMERIC"))
+	assert.Equal(t, "NaN", jsonValue([]byte("NaN"), "NUMERIC"))
+	assert.Equal(t, "+Inf", jsonValue(math.Inf(1), "FLOAT8"))
+	assert.Equal(t, "AAE=", jsonValue([]byte{0, 1}, "BYTEA"))
+	assert.Equal(t, "hello", jsonValue([]byte("hello"), "VARCHAR"))
+	assert.Equal(t, int64(7), jsonValue(int64(7), "INT8"))
+	assert.Nil(t, jsonValue(nil, "TEXT"))
+}
+
+func TestOrderedObject(t *testing.T) {
+	object := orderedObject{
+		{Name: "word", Value: "<b>"},
+		{Name: "id", Value: int64(3)},
+		{Name: "tags", Value: json.RawMessage(`["a"]`)},
+		{Name: "note", Value: nil},
+	}
+	var b strings.Builder
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	require.NoError(t, encoder.Encode(object))
+	assert.Equal(t, `{"word":"<b>","id":3,"tags":["a"],"note":null}`+"\n", b.String())
+}
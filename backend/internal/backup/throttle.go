@@ -0,0 +1,244 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/toeic-app/internal/config"
+)
+
+// IOLimit bounds the IO of a backup or restore. Zero fields are unlimited.
+type IOLimit struct {
+	BytesPerSecond int64 `json:"bytes_per_second" binding:"min=0"`
+	// OpsPerSecond counts reads and writes of up to BACKUP_BUFFER_SIZE bytes
+	OpsPerSecond int `json:"ops_per_second" binding:"min=0"`
+}
+
+// Unlimited reports whether the limit lets IO run at full speed
+func (l IOLimit) Unlimited() bool {
+	return l.BytesPerSecond <= 0 && l.OpsPerSecond <= 0
+}
+
+type ioLimitKey struct{}
+
+// WithIOLimit overrides the configured IO limits, and the windows of
+// BACKUP_IO_SCHEDULE, for the backups and restores run with ctx, such as
+// those of a backup schedule
+func WithIOLimit(ctx context.Context, limit IOLimit) context.Context {
+	return context.WithValue(ctx, ioLimitKey{}, limit)
+}
+
+// ioLimiter tells the IO limit of backups and restores at a time
+type ioLimiter struct {
+	override *IOLimit
+	windows  []config.BackupIOWindow
+	fallback IOLimit
+}
+
+// ioLimiter returns the IO limits of the backups and restores run with ctx
+func (bm *BackupManager) ioLimiter(ctx context.Context) ioLimiter {
+	limiter := ioLimiter{
+		fallback: IOLimit{BytesPerSecond: bm.config.IOBytesPerSecond, OpsPerSecond: bm.config.IOOpsPerSecond},
+	}
+	if override, ok := ctx.Value(ioLimitKey{}).(IOLimit); ok {
+		limiter.override = &override
+	}
+	// Checked by BackupConfig.Validate
+	limiter.windows, _ = bm.config.IOWindows()
+	return limiter
+}
+
+// throttles reports whether IO is limited at any time of the day
+func (l ioLimiter) throttles() bool {
+	if l.override != nil {
+		return !l.override.Unlimited()
+	}
+	for _, window := range l.windows {
+		if !(IOLimit{BytesPerSecond: window.BytesPerSecond, OpsPerSecond: window.OpsPerSecond}).Unlimited() {
+			return true
+		}
+	}
+	return !l.fallback.Unlimited()
+}
+
+// at returns the limit at a time: the override, else the first window
+// covering the time, else the configured limits
+func (l ioLimiter) at(t time.Time) IOLimit {
+	if l.override != nil {
+		return *l.override
+	}
+	for _, window := range l.windows {
+		if window.Contains(t) {
+			return IOLimit{BytesPerSecond: window.BytesPerSecond, OpsPerSecond: window.OpsPerSecond}
+		}
+	}
+	return l.fallback
+}
+
+// throttle paces IO to the limit of the current second. The limit is
+// looked up on every operation, so a long dump speeds up or slows down
+// as it enters or leaves a window.
+type throttle struct {
+	ctx     context.Context
+	limiter ioLimiter
+	chunk   int // Largest read or write
+	now     func() time.Time
+	sleep   func(ctx context.Context, d time.Duration) error
+
+	second time.Time // Start of the current second
+	bytes  int64     // Bytes read or written in the current second
+	ops    int       // Operations in the current second
+}
+
+// newThrottle returns the throttle of the backups and restores run with
+// ctx, or nil when IO is never limited
+func (bm *BackupManager) newThrottle(ctx context.Context) *throttle {
+	limiter := bm.ioLimiter(ctx)
+	if !limiter.throttles() {
+		return nil
+	}
+	chunk := bm.config.BufferSize
+	if chunk <= 0 {
+		chunk = 64 * 1024
+	}
+	return &throttle{ctx: ctx, limiter: limiter, chunk: chunk, now: time.Now, sleep: sleepContext}
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// size returns how many of n bytes the next operation may move: what is
+// left of the rate of the current second, or of the next one
+func (t *throttle) size(n int) int {
+	now := t.now()
+	limit := t.limiter.at(now)
+	n = min(n, t.chunk)
+	if limit.BytesPerSecond > 0 {
+		left := limit.BytesPerSecond
+		if now.Sub(t.second) < time.Second && t.bytes < left {
+			left -= t.bytes
+		}
+		n = int(min(int64(n), left))
+	}
+	return max(n, 1)
+}
+
+// wait blocks until an operation moving n bytes fits the limit
+func (t *throttle) wait(n int) error {
+	for {
+		now := t.now()
+		if now.Sub(t.second) >= time.Second {
+			t.second, t.bytes, t.ops = now, 0, 0
+		}
+		limit := t.limiter.at(now)
+		if (limit.BytesPerSecond <= 0 || t.bytes+int64(n) <= limit.BytesPerSecond) &&
+			(limit.OpsPerSecond <= 0 || t.ops < limit.OpsPerSecond) {
+			t.bytes += int64(n)
+			t.ops++
+			return nil
+		}
+		if err := t.sleep(t.ctx, t.second.Add(time.Second).Sub(now)); err != nil {
+			return err
+		}
+	}
+}
+
+// throttledReader reads through a throttle
+type throttledReader struct {
+	reader   io.Reader
+	throttle *throttle
+}
+
+func (r *throttledReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return r.reader.Read(p)
+	}
+	p = p[:r.throttle.size(len(p))]
+	if err := r.throttle.wait(len(p)); err != nil {
+		return 0, err
+	}
+	return r.reader.Read(p)
+}
+
+// throttledWriter writes through a throttle
+type throttledWriter struct {
+	writer   io.Writer
+	throttle *throttle
+}
+
+func (w *throttledWriter) Write(p []byte) (int, error) {
+	written := 0
+	for written < len(p) {
+		chunk := p[written : written+w.throttle.size(len(p)-written)]
+		if err := w.throttle.wait(len(chunk)); err != nil {
+			return written, err
+		}
+		n, err := w.writer.Write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// throttleReader limits reads from r to the IO limits of ctx
+func (bm *BackupManager) throttleReader(ctx context.Context, r io.Reader) io.Reader {
+	if t := bm.newThrottle(ctx); t != nil {
+		return &throttledReader{reader: r, throttle: t}
+	}
+	return r
+}
+
+// throttleWriter limits writes to w to the IO limits of ctx
+func (bm *BackupManager) throttleWriter(ctx context.Context, w io.Writer) io.Writer {
+	if t := bm.newThrottle(ctx); t != nil {
+		return &throttledWriter{writer: w, throttle: t}
+	}
+	return w
+}
+
+// dumpThrottled runs a dump writing to its standard output and writes the
+// output to path through the IO throttle of ctx. It returns what the dump
+// wrote to standard error.
+func (bm *BackupManager) dumpThrottled(ctx context.Context, cmd *exec.Cmd, path string) ([]byte, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var stderr bytes.Buffer
+	cmd.Stdout = bm.throttleWriter(ctx, file)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return stderr.Bytes(), err
+	}
+	return stderr.Bytes(), file.Close()
+}
+
+// singleJob makes pg_dump or pg_restore run one worker, as parallel
+// workers would multiply the IO throttling is meant to limit
+func singleJob(args []string) []string {
+	single := make([]string, len(args))
+	for i, arg := range args {
+		if strings.HasPrefix(arg, "--jobs=") {
+			arg = "--jobs=1"
+		}
+		single[i] = arg
+	}
+	return single
+}
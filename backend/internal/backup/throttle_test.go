@@ -0,0 +1,105 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toeic-app/internal/config"
+)
+
+// fakeClock is the clock of a throttle, advanced by its sleeps
+type fakeClock struct {
+	now    time.Time
+	slept  time.Duration
+	sleeps int
+}
+
+func (c *fakeClock) install(t *throttle) *throttle {
+	t.now = func() time.Time { return c.now }
+	t.sleep = func(ctx context.Context, d time.Duration) error {
+		c.now = c.now.Add(d)
+		c.slept += d
+		c.sleeps++
+		return ctx.Err()
+	}
+	return t
+}
+
+func TestThrottledWriterPacesBytes(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)}
+	bm := &BackupManager{config: config.BackupConfig{IOBytesPerSecond: 100, BufferSize: 64}}
+	throttle := clock.install(bm.newThrottle(context.Background()))
+
+	var out bytes.Buffer
+	writer := &throttledWriter{writer: &out, throttle: throttle}
+	n, err := writer.Write(bytes.Repeat([]byte("x"), 350))
+	require.NoError(t, err)
+	assert.Equal(t, 350, n)
+	assert.Equal(t, 350, out.Len())
+	// 100 bytes a second: the last 50 bytes are written after 3 seconds
+	assert.Equal(t, 3*time.Second, clock.slept)
+}
+
+func TestThrottledReaderPacesOperations(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)}
+	bm := &BackupManager{config: config.BackupConfig{IOOpsPerSecond: 2, BufferSize: 10}}
+	throttle := clock.install(bm.newThrottle(context.Background()))
+
+	data, err := io.ReadAll(&throttledReader{reader: strings.NewReader(strings.Repeat("y", 45)), throttle: throttle})
+	require.NoError(t, err)
+	assert.Len(t, data, 45)
+	// Six reads of at most 10 bytes, the last finding the end, two a second
+	assert.Equal(t, 2*time.Second, clock.slept)
+}
+
+func TestIOLimitSources(t *testing.T) {
+	bm := &BackupManager{config: config.BackupConfig{
+		IOBytesPerSecond: 1 << 20,
+		IOSchedule:       "09:00-18:00=256KB/50, 22:00-06:00=0",
+	}}
+	ctx := context.Background()
+	day := time.Date(2026, 5, 1, 0, 0, 0, 0, time.Local)
+
+	limiter := bm.ioLimiter(ctx)
+	assert.True(t, limiter.throttles())
+	assert.Equal(t, IOLimit{BytesPerSecond: 256 << 10, OpsPerSecond: 50}, limiter.at(day.Add(10*time.Hour)))
+	assert.Equal(t, IOLimit{BytesPerSecond: 1 << 20}, limiter.at(day.Add(19*time.Hour)))
+	// Windows span midnight
+	assert.True(t, limiter.at(day.Add(3*time.Hour)).Unlimited())
+	assert.True(t, limiter.at(day.Add(23*time.Hour)).Unlimited())
+
+	// A schedule's override wins over the windows
+	limiter = bm.ioLimiter(WithIOLimit(ctx, IOLimit{}))
+	assert.False(t, limiter.throttles())
+	assert.Nil(t, bm.newThrottle(WithIOLimit(ctx, IOLimit{})))
+	assert.Equal(t, IOLimit{OpsPerSecond: 5}, bm.ioLimiter(WithIOLimit(ctx, IOLimit{OpsPerSecond: 5})).at(day.Add(10*time.Hour)))
+
+	assert.False(t, (&BackupManager{}).ioLimiter(ctx).throttles())
+
+	for _, schedule := range []string{"09:00-18:00", "9-18=1MB", "09:00-18:00=fast", "09:00-18:00=1MB/-1"} {
+		_, err := (&config.BackupConfig{IOSchedule: schedule}).IOWindows()
+		assert.Error(t, err, schedule)
+	}
+}
+
+func TestThrottleStopsWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	bm := &BackupManager{config: config.BackupConfig{IOBytesPerSecond: 10, BufferSize: 10}}
+	writer := bm.throttleWriter(ctx, io.Discard)
+
+	_, err := writer.Write(make([]byte, 10))
+	require.NoError(t, err)
+	cancel()
+	_, err = writer.Write(make([]byte, 10))
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestSingleJob(t *testing.T) {
+	assert.Equal(t, []string{"--format=directory", "--jobs=1", "toeic"}, singleJob([]string{"--format=directory", "--jobs=4", "toeic"}))
+}
@@ -0,0 +1,361 @@
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// QuarantineStatusPending marks a verified upload waiting for approval
+const QuarantineStatusPending = "pending"
+
+// Upload formats accepted for quarantine
+const (
+	UploadFormatSQL     = "sql"
+	UploadFormatSQLGzip = "sql.gz"
+)
+
+// sniffSize is how much of a file is inspected to detect its type
+const sniffSize = 64 * 1024
+
+var (
+	// ErrInvalidBackupUpload is returned when an uploaded file is not a plain or gzipped SQL dump
+	ErrInvalidBackupUpload = errors.New("invalid backup upload")
+	// ErrChecksumMismatch is returned when the uploaded content does not match the declared SHA-256
+	ErrChecksumMismatch = errors.New("backup checksum mismatch")
+	// ErrUploadTooLarge is returned when an upload exceeds the maximum size
+	ErrUploadTooLarge = errors.New("backup upload too large")
+	// ErrNotQuarantined is returned for unknown quarantine entries
+	ErrNotQuarantined = errors.New("backup is not in quarantine")
+)
+
+var uploadFilenamePattern = regexp.MustCompile(`^[a-zA-Z0-9_.-]+\.(sql|sql\.gz)$`)
+
+// sqlStatementPrefixes are how plain-text pg_dump output and hand-written dumps start
+var sqlStatementPrefixes = []string{
+	"--", "/*", "SET ", "SELECT ", "CREATE ", "ALTER ", "INSERT ", "COPY ", "BEGIN", "DROP ", "\\connect",
+}
+
+// QuarantineEntry describes an uploaded backup waiting for approval
+type QuarantineEntry struct {
+	Filename     string    `json:"filename"`
+	OriginalName string    `json:"original_name"`
+	Description  string    `json:"description"`
+	Format       string    `json:"format"`
+	Size         int64     `json:"size"`
+	SHA256       string    `json:"sha256"`
+	Status       string    `json:"status"`
+	UploadedBy   int32     `json:"uploaded_by"`
+	UploadedAt   time.Time `json:"uploaded_at"`
+}
+
+// Quarantine stores uploaded backups outside the backup directory until an
+// administrator approves them. Restore endpoints only read the backup
+// directory, so a quarantined file can never be restored directly.
+type Quarantine struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewQuarantine creates a quarantine in dir
+func NewQuarantine(dir string) *Quarantine {
+	return &Quarantine{dir: dir}
+}
+
+// ValidateUploadFilename returns the clean name of an upload or an error
+func ValidateUploadFilename(name string) (string, error) {
+	clean := filepath.Base(name)
+	if !uploadFilenamePattern.MatchString(clean) {
+		return "", fmt.Errorf("%w: only .sql and .sql.gz files are allowed", ErrInvalidBackupUpload)
+	}
+	return clean, nil
+}
+
+// looksLikeSQL reports whether the start of a file is a plain-text SQL dump
+func looksLikeSQL(head []byte) error {
+	if len(head) == 0 {
+		return fmt.Errorf("%w: file is empty", ErrInvalidBackupUpload)
+	}
+	if bytes.HasPrefix(head, []byte("PGDMP")) {
+		return fmt.Errorf("%w: pg_dump custom format is not supported, upload a plain SQL dump", ErrInvalidBackupUpload)
+	}
+	if bytes.IndexByte(head, 0) >= 0 {
+		return fmt.Errorf("%w: file contains binary data", ErrInvalidBackupUpload)
+	}
+
+	// The sniffed block may end in the middle of a multi-byte character
+	for trimmed := 0; !utf8.Valid(head) && trimmed < utf8.UTFMax; trimmed++ {
+		head = head[:len(head)-1]
+	}
+	if !utf8.Valid(head) {
+		return fmt.Errorf("%w: file is not UTF-8 text", ErrInvalidBackupUpload)
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(head))
+	scanner.Buffer(make([]byte, 0, sniffSize), sniffSize)
+	for scanner.Scan() {
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		if line == "" {
+			continue
+		}
+		upper := strings.ToUpper(line)
+		for _, prefix := range sqlStatementPrefixes {
+			if strings.HasPrefix(upper, strings.ToUpper(prefix)) {
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: file does not start with SQL", ErrInvalidBackupUpload)
+	}
+	return fmt.Errorf("%w: file does not contain SQL", ErrInvalidBackupUpload)
+}
+
+// detectFormat checks the magic bytes of a file against its extension
+func detectFormat(path, filename string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	head := make([]byte, sniffSize)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	head = head[:n]
+
+	isGzip := len(head) >= 2 && head[0] == 0x1f && head[1] == 0x8b
+	if strings.HasSuffix(filename, ".gz") {
+		if !isGzip {
+			return "", fmt.Errorf("%w: .gz file is not gzip compressed", ErrInvalidBackupUpload)
+		}
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return "", err
+		}
+		reader, err := gzip.NewReader(file)
+		if err != nil {
+			return "", fmt.Errorf("%w: corrupt gzip stream: %v", ErrInvalidBackupUpload, err)
+		}
+		defer reader.Close()
+
+		inner := make([]byte, sniffSize)
+		n, err := io.ReadFull(reader, inner)
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+			return "", fmt.Errorf("%w: corrupt gzip stream: %v", ErrInvalidBackupUpload, err)
+		}
+		return UploadFormatSQLGzip, looksLikeSQL(inner[:n])
+	}
+
+	if isGzip {
+		return "", fmt.Errorf("%w: gzip data must use the .sql.gz extension", ErrInvalidBackupUpload)
+	}
+	return UploadFormatSQL, looksLikeSQL(head)
+}
+
+func (q *Quarantine) filePath(filename string) string {
+	return filepath.Join(q.dir, filename)
+}
+
+func (q *Quarantine) metadataPath(filename string) string {
+	return filepath.Join(q.dir, filename+".json")
+}
+
+func (q *Quarantine) saveEntry(entry *QuarantineEntry) error {
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(q.metadataPath(entry.Filename), data, 0600)
+}
+
+// Save writes an upload into quarantine and verifies it. The declared
+// SHA-256 is required. Files that fail verification are deleted and the
+// verification error is returned.
+func (q *Quarantine) Save(src io.Reader, entry QuarantineEntry, expectedSHA256 string, maxSize int64) (*QuarantineEntry, error) {
+	filename, err := ValidateUploadFilename(entry.OriginalName)
+	if err != nil {
+		return nil, err
+	}
+	expectedSHA256 = strings.ToLower(strings.TrimSpace(expectedSHA256))
+	if len(expectedSHA256) != sha256.Size*2 {
+		return nil, fmt.Errorf("%w: a SHA-256 checksum of the file is required", ErrInvalidBackupUpload)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := os.MkdirAll(q.dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create quarantine directory: %w", err)
+	}
+	if _, err := os.Stat(q.filePath(filename)); err == nil {
+		base := strings.TrimSuffix(strings.TrimSuffix(filename, ".gz"), ".sql")
+		filename = fmt.Sprintf("%s_%s%s", base, time.Now().Format("20060102_150405"), strings.TrimPrefix(filename, base))
+	}
+	path := q.filePath(filename)
+
+	dest, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create quarantine file: %w", err)
+	}
+
+	hash := sha256.New()
+	written, err := io.Copy(io.MultiWriter(dest, hash), io.LimitReader(src, maxSize+1))
+	closeErr := dest.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return nil, fmt.Errorf("failed to write quarantine file: %w", err)
+	}
+
+	reject := func(verifyErr error) (*QuarantineEntry, error) {
+		os.Remove(path)
+		return nil, verifyErr
+	}
+	if written > maxSize {
+		return reject(fmt.Errorf("%w: maximum size is %d bytes", ErrUploadTooLarge, maxSize))
+	}
+	checksum := hex.EncodeToString(hash.Sum(nil))
+	if checksum != expectedSHA256 {
+		return reject(fmt.Errorf("%w: expected %s, got %s", ErrChecksumMismatch, expectedSHA256, checksum))
+	}
+	format, err := detectFormat(path, filename)
+	if err != nil {
+		return reject(err)
+	}
+
+	entry.Filename = filename
+	entry.Format = format
+	entry.Size = written
+	entry.SHA256 = checksum
+	entry.Status = QuarantineStatusPending
+	entry.UploadedAt = time.Now()
+	if err := q.saveEntry(&entry); err != nil {
+		return reject(fmt.Errorf("failed to save quarantine metadata: %w", err))
+	}
+	return &entry, nil
+}
+
+// Get returns a quarantine entry
+func (q *Quarantine) Get(filename string) (*QuarantineEntry, error) {
+	filename, err := ValidateUploadFilename(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(q.metadataPath(filename))
+	if os.IsNotExist(err) {
+		return nil, ErrNotQuarantined
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entry QuarantineEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("failed to read quarantine metadata: %w", err)
+	}
+	return &entry, nil
+}
+
+// List returns all quarantined uploads
+func (q *Quarantine) List() ([]QuarantineEntry, error) {
+	files, err := os.ReadDir(q.dir)
+	if os.IsNotExist(err) {
+		return []QuarantineEntry{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]QuarantineEntry, 0)
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		entry, err := q.Get(strings.TrimSuffix(file.Name(), ".json"))
+		if err != nil {
+			continue
+		}
+		entries = append(entries, *entry)
+	}
+	return entries, nil
+}
+
+// Release re-verifies a quarantined upload and moves it into backupDir, where
+// it becomes restorable. It returns the entry of the released file.
+func (q *Quarantine) Release(filename, backupDir string) (*QuarantineEntry, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entry, err := q.Get(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	// The file could have been modified on disk since it was uploaded
+	checksum, err := fileSHA256(q.filePath(entry.Filename))
+	if err != nil {
+		return nil, err
+	}
+	if checksum != entry.SHA256 {
+		return nil, fmt.Errorf("%w: quarantined file changed since upload", ErrChecksumMismatch)
+	}
+
+	destPath := filepath.Join(backupDir, entry.Filename)
+	if _, err := os.Stat(destPath); err == nil {
+		return nil, fmt.Errorf("a backup named %s already exists", entry.Filename)
+	}
+	if err := os.MkdirAll(backupDir, 0755); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(q.filePath(entry.Filename), destPath); err != nil {
+		return nil, fmt.Errorf("failed to move backup out of quarantine: %w", err)
+	}
+	os.Remove(q.metadataPath(entry.Filename))
+	return entry, nil
+}
+
+// Remove deletes a quarantined upload
+func (q *Quarantine) Remove(filename string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entry, err := q.Get(filename)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(q.filePath(entry.Filename)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.Remove(q.metadataPath(entry.Filename))
+}
+
+// fileSHA256 returns the hex SHA-256 of a file
+func fileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
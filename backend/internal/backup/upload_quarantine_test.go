@@ -0,0 +1,94 @@
+package backup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestQuarantineAcceptsSQLDumps(t *testing.T) {
+	q := NewQuarantine(t.TempDir())
+	dump := []byte("--\n-- PostgreSQL database dump\n--\nSET statement_timeout = 0;\n")
+
+	entry, err := q.Save(bytes.NewReader(dump), QuarantineEntry{OriginalName: "backup.sql"}, checksum(dump), 1024)
+	require.NoError(t, err)
+	assert.Equal(t, UploadFormatSQL, entry.Format)
+	assert.Equal(t, QuarantineStatusPending, entry.Status)
+
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	writer.Write(dump)
+	writer.Close()
+
+	entry, err = q.Save(bytes.NewReader(compressed.Bytes()), QuarantineEntry{OriginalName: "backup.sql.gz"},
+		checksum(compressed.Bytes()), 1024)
+	require.NoError(t, err)
+	assert.Equal(t, UploadFormatSQLGzip, entry.Format)
+}
+
+func TestQuarantineRejectsInvalidUploads(t *testing.T) {
+	q := NewQuarantine(t.TempDir())
+	dump := []byte("CREATE TABLE t (id int);\n")
+
+	tests := []struct {
+		name     string
+		filename string
+		content  []byte
+		checksum string
+		err      error
+	}{
+		{"wrong extension", "backup.exe", dump, checksum(dump), ErrInvalidBackupUpload},
+		{"missing checksum", "backup.sql", dump, "", ErrInvalidBackupUpload},
+		{"checksum mismatch", "backup.sql", dump, checksum([]byte("other")), ErrChecksumMismatch},
+		{"binary content", "backup.sql", []byte("\x7fELF\x00\x01"), checksum([]byte("\x7fELF\x00\x01")), ErrInvalidBackupUpload},
+		{"not sql", "backup.sql", []byte("<html></html>"), checksum([]byte("<html></html>")), ErrInvalidBackupUpload},
+		{"fake gzip", "backup.sql.gz", dump, checksum(dump), ErrInvalidBackupUpload},
+		{"too large", "backup.sql", bytes.Repeat([]byte("-"), 2048), checksum(bytes.Repeat([]byte("-"), 2048)), ErrUploadTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := q.Save(bytes.NewReader(tt.content), QuarantineEntry{OriginalName: tt.filename}, tt.checksum, 1024)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+
+	// Rejected files are not kept
+	entries, err := q.List()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestQuarantineRelease(t *testing.T) {
+	q := NewQuarantine(filepath.Join(t.TempDir(), "quarantine"))
+	backupDir := t.TempDir()
+	dump := []byte("CREATE TABLE t (id int);\n")
+
+	entry, err := q.Save(bytes.NewReader(dump), QuarantineEntry{OriginalName: "backup.sql"}, checksum(dump), 1024)
+	require.NoError(t, err)
+
+	// Tampering after upload is detected
+	require.NoError(t, os.WriteFile(q.filePath(entry.Filename), []byte("DROP TABLE t;\n"), 0600))
+	_, err = q.Release(entry.Filename, backupDir)
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+
+	require.NoError(t, os.WriteFile(q.filePath(entry.Filename), dump, 0600))
+	_, err = q.Release(entry.Filename, backupDir)
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(backupDir, "backup.sql"))
+
+	_, err = q.Get(entry.Filename)
+	assert.ErrorIs(t, err, ErrNotQuarantined)
+}
@@ -0,0 +1,548 @@
+package backup
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/toeic-app/internal/logger"
+)
+
+// ErrScratchIsLive is returned when the scratch database of a restore
+// verification is the application database
+var ErrScratchIsLive = errors.New("the scratch database must not be the application database")
+
+// maxReportedErrors is the most restore errors a verification reports
+const maxReportedErrors = 10
+
+// Checks of a restore verification
+const (
+	CheckPrepare    = "prepare"    // The backups were fetched, decrypted and matched their checksums
+	CheckRestore    = "restore"    // psql restored every statement without errors
+	CheckTables     = "tables"     // Every table created by the backup exists
+	CheckRowCounts  = "row_counts" // Every table holds the rows of the backup
+	CheckReferences = "references" // No row references a missing row
+	CheckSchema     = "schema"     // The migration version matches the backup
+)
+
+// VerifyOptions chooses where a backup is restored to be verified
+type VerifyOptions struct {
+	// ScratchDB overrides BACKUP_VERIFY_SCRATCH_DB
+	ScratchDB string
+	// Keep leaves the temporary database for inspection
+	Keep bool
+}
+
+// VerifyCheck is the outcome of a check of a restore verification
+type VerifyCheck struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// TableVerification compares the rows of a table in the backup with the
+// rows restored
+type TableVerification struct {
+	Table        string `json:"table"`
+	ExpectedRows int64  `json:"expected_rows"`
+	RestoredRows int64  `json:"restored_rows"`
+	Missing      bool   `json:"missing,omitempty"` // The table was not restored
+}
+
+// Passed reports whether the table was restored with every row
+func (t TableVerification) Passed() bool {
+	return !t.Missing && t.ExpectedRows == t.RestoredRows
+}
+
+// VerifyReport is the outcome of VerifyRestore
+type VerifyReport struct {
+	Filename   string              `json:"filename"`
+	Chain      []string            `json:"chain,omitempty"` // Backups restored, full backup first
+	Database   string              `json:"database"`        // Scratch database restored into
+	Temporary  bool                `json:"temporary"`       // Created for the verification
+	Kept       bool                `json:"kept"`            // Left for inspection
+	Passed     bool                `json:"passed"`
+	Checks     []VerifyCheck       `json:"checks"`
+	Tables     []TableVerification `json:"tables,omitempty"`
+	Warnings   []string            `json:"warnings,omitempty"`
+	Duration   time.Duration       `json:"duration"`
+	VerifiedAt time.Time           `json:"verified_at"`
+}
+
+func (r *VerifyReport) check(name string, passed bool, detail string) {
+	r.Checks = append(r.Checks, VerifyCheck{Name: name, Passed: passed, Detail: detail})
+}
+
+// VerifyRestore proves a backup can be restored: it restores the backup,
+// and the backups it builds on, into a scratch database and checks the
+// tables, their row counts and references against the backup. The
+// application database is left alone. An error is returned when the
+// verification could not run; a backup that cannot be restored yields a
+// report that did not pass, which is reported to the notification channels.
+func (bm *BackupManager) VerifyRestore(ctx context.Context, filename string, options VerifyOptions) (*VerifyReport, error) {
+	report, err := bm.verifyRestore(ctx, filename, options)
+	if err == nil && !report.Passed {
+		var issues []string
+		for _, check := range report.Checks {
+			if !check.Passed {
+				issues = append(issues, fmt.Sprintf("%s: %s", check.Name, check.Detail))
+			}
+		}
+		bm.notifier.NotifyValidationIssues(filename, issues)
+	}
+	return report, err
+}
+
+func (bm *BackupManager) verifyRestore(ctx context.Context, filename string, options VerifyOptions) (*VerifyReport, error) {
+	startTime := time.Now()
+	report := &VerifyReport{Filename: filename, VerifiedAt: startTime}
+	defer func() { report.Duration = time.Since(startTime) }()
+
+	if !bm.isValidBackupFilename(filename) {
+		return nil, fmt.Errorf("invalid backup filename")
+	}
+	scratch := options.ScratchDB
+	if scratch == "" {
+		scratch = bm.config.VerifyScratchDB
+	}
+	if scratch != "" && scratch == bm.dbConfig.DBName {
+		return nil, ErrScratchIsLive
+	}
+
+	chain, err := bm.RestoreChain(ctx, filename)
+	if err != nil {
+		return nil, err
+	}
+	report.Chain = chain
+
+	// A backup that cannot be fetched, decrypted or matched with its
+	// checksum is not restorable
+	prepared := &RestoreResult{}
+	sqlPaths := make([]string, 0, len(chain))
+	for _, name := range chain {
+		sqlPath, downloaded, err := bm.prepareRestore(ctx, name, prepared)
+		if downloaded && !bm.config.KeepLocalCopy {
+			defer bm.removeLocal(name)
+		}
+		if err != nil {
+			report.Warnings = prepared.Warnings
+			report.check(CheckPrepare, false, err.Error())
+			return report, nil
+		}
+		if sqlPath != filepath.Join(bm.config.BackupDir, name) {
+			defer os.Remove(sqlPath)
+		}
+		sqlPaths = append(sqlPaths, sqlPath)
+	}
+	report.Warnings = prepared.Warnings
+	report.check(CheckPrepare, true, fmt.Sprintf("%d backups ready", len(chain)))
+
+	expected := dumpContents{Rows: make(map[string]int64)}
+	for _, sqlPath := range sqlPaths {
+		if err := bm.readDump(ctx, &expected, sqlPath); err != nil {
+			return nil, fmt.Errorf("failed to read backup %s: %w", filepath.Base(sqlPath), err)
+		}
+	}
+
+	// Restore into the scratch database
+	scratch, report.Temporary, err = bm.scratchDatabase(ctx, scratch, "verify", startTime)
+	if err != nil {
+		return nil, err
+	}
+	if report.Temporary {
+		if options.Keep {
+			report.Kept = true
+		} else {
+			defer bm.dropDatabase(scratch)
+		}
+	}
+	report.Database = scratch
+	logger.Info("Verifying backup %s by restoring it into %s", filename, scratch)
+
+	var restoreErrors []string
+	for i, sqlPath := range sqlPaths {
+		output, err := bm.restoreInto(ctx, scratch, sqlPath)
+		if err != nil {
+			report.check(CheckRestore, false, fmt.Sprintf("%s: %v", chain[i], err))
+			return report, nil
+		}
+		restoreErrors = append(restoreErrors, psqlErrors(output)...)
+	}
+	if len(restoreErrors) == 0 {
+		report.check(CheckRestore, true, fmt.Sprintf("%d backups restored", len(sqlPaths)))
+	} else {
+		report.check(CheckRestore, false, summarizeErrors(restoreErrors))
+	}
+
+	if err := bm.verifyTables(ctx, scratch, expected, report); err != nil {
+		return nil, err
+	}
+	if err := bm.verifyReferences(ctx, scratch, report); err != nil {
+		return nil, err
+	}
+	bm.verifySchema(ctx, scratch, report)
+
+	report.Passed = true
+	for _, check := range report.Checks {
+		report.Passed = report.Passed && check.Passed
+	}
+	if report.Passed {
+		logger.Info("Restore verification of %s passed", filename)
+	} else {
+		logger.Warn("Restore verification of %s failed", filename)
+	}
+	return report, nil
+}
+
+// verifyTables compares the tables and row counts of the scratch database
+// with those of the backup
+func (bm *BackupManager) verifyTables(ctx context.Context, scratch string, expected dumpContents, report *VerifyReport) error {
+	rows, err := bm.queryDatabase(ctx, scratch, "SELECT schemaname || '.' || relname FROM pg_stat_user_tables")
+	if err != nil {
+		return fmt.Errorf("failed to list restored tables: %w", err)
+	}
+	restored := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		restored[row[0]] = true
+	}
+
+	var missing []string
+	for _, table := range expected.Tables {
+		if !restored[table] {
+			missing = append(missing, table)
+		}
+	}
+	if len(missing) == 0 {
+		report.check(CheckTables, true, fmt.Sprintf("%d tables restored", len(expected.Tables)))
+	} else {
+		report.check(CheckTables, false, "missing tables: "+strings.Join(missing, ", "))
+	}
+
+	tables := make([]string, 0, len(expected.Rows))
+	counts := make([]string, 0, len(expected.Rows))
+	for table := range expected.Rows {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	for _, table := range tables {
+		if restored[table] {
+			counts = append(counts, fmt.Sprintf("SELECT %s, count(*) FROM %s", quoteLiteral(table), quoteQualifiedName(table)))
+		}
+	}
+	restoredRows := make(map[string]int64, len(counts))
+	if len(counts) > 0 {
+		rows, err := bm.queryDatabase(ctx, scratch, strings.Join(counts, " UNION ALL "))
+		if err != nil {
+			return fmt.Errorf("failed to count restored rows: %w", err)
+		}
+		for _, row := range rows {
+			if len(row) != 2 {
+				continue
+			}
+			count, err := strconv.ParseInt(row[1], 10, 64)
+			if err != nil {
+				return fmt.Errorf("unexpected row count %q for %s", row[1], row[0])
+			}
+			restoredRows[row[0]] = count
+		}
+	}
+
+	var mismatched []string
+	for _, table := range tables {
+		verification := TableVerification{
+			Table:        table,
+			ExpectedRows: expected.Rows[table],
+			RestoredRows: restoredRows[table],
+			Missing:      !restored[table],
+		}
+		if !verification.Passed() {
+			mismatched = append(mismatched, table)
+		}
+		report.Tables = append(report.Tables, verification)
+	}
+	if len(mismatched) == 0 {
+		report.check(CheckRowCounts, true, fmt.Sprintf("%d tables hold the rows of the backup", len(tables)))
+	} else {
+		report.check(CheckRowCounts, false, "row counts differ for "+strings.Join(mismatched, ", "))
+	}
+	return nil
+}
+
+// foreignKey is a foreign key constraint of the scratch database
+type foreignKey struct {
+	Name       string
+	Table      string // Quoted
+	Columns    []string
+	RefTable   string // Quoted
+	RefColumns []string
+}
+
+// orphanQuery counts the rows referencing a missing row
+func (fk foreignKey) orphanQuery() string {
+	var notNull, matches []string
+	for i, column := range fk.Columns {
+		notNull = append(notNull, fmt.Sprintf("c.%s IS NOT NULL", column))
+		matches = append(matches, fmt.Sprintf("p.%s = c.%s", fk.RefColumns[i], column))
+	}
+	return fmt.Sprintf("SELECT count(*) FROM %s c WHERE %s AND NOT EXISTS (SELECT 1 FROM %s p WHERE %s)",
+		fk.Table, strings.Join(notNull, " AND "), fk.RefTable, strings.Join(matches, " AND "))
+}
+
+// verifyReferences looks for rows referencing missing rows, which a
+// restore leaves behind when a foreign key could not be created
+func (bm *BackupManager) verifyReferences(ctx context.Context, scratch string, report *VerifyReport) error {
+	rows, err := bm.queryDatabase(ctx, scratch, `SELECT con.conname, con.conrelid::regclass, con.confrelid::regclass,
+		(SELECT string_agg(quote_ident(a.attname), ',' ORDER BY k.i) FROM unnest(con.conkey) WITH ORDINALITY k(attnum, i)
+			JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum),
+		(SELECT string_agg(quote_ident(a.attname), ',' ORDER BY k.i) FROM unnest(con.confkey) WITH ORDINALITY k(attnum, i)
+			JOIN pg_attribute a ON a.attrelid = con.confrelid AND a.attnum = k.attnum)
+		FROM pg_constraint con WHERE con.contype = 'f' ORDER BY 1`)
+	if err != nil {
+		return fmt.Errorf("failed to list foreign keys: %w", err)
+	}
+
+	var broken []string
+	for _, row := range rows {
+		if len(row) != 5 {
+			continue
+		}
+		fk := foreignKey{Name: row[0], Table: row[1], RefTable: row[2], Columns: strings.Split(row[3], ","), RefColumns: strings.Split(row[4], ",")}
+		counted, err := bm.queryDatabase(ctx, scratch, fk.orphanQuery())
+		if err != nil {
+			return fmt.Errorf("failed to check foreign key %s: %w", fk.Name, err)
+		}
+		if len(counted) == 1 && counted[0][0] != "0" {
+			broken = append(broken, fmt.Sprintf("%s (%s rows of %s)", fk.Name, counted[0][0], fk.Table))
+		}
+	}
+	if len(broken) == 0 {
+		report.check(CheckReferences, true, fmt.Sprintf("%d foreign keys hold", len(rows)))
+	} else {
+		report.check(CheckReferences, false, "rows reference missing rows: "+strings.Join(broken, ", "))
+	}
+	return nil
+}
+
+// verifySchema checks the migration version of the scratch database
+// against the version recorded when the backup was taken
+func (bm *BackupManager) verifySchema(ctx context.Context, scratch string, report *VerifyReport) {
+	rows, err := bm.queryDatabase(ctx, scratch, "SELECT version, dirty FROM schema_migrations LIMIT 1")
+	if err != nil || len(rows) != 1 || len(rows[0]) != 2 {
+		report.check(CheckSchema, false, "schema_migrations was not restored")
+		return
+	}
+	version, dirty := rows[0][0], rows[0][1] == "t"
+	if dirty {
+		report.check(CheckSchema, false, fmt.Sprintf("migration %s is marked dirty", version))
+		return
+	}
+	metadata, err := bm.fetchMetadata(ctx, report.Chain[len(report.Chain)-1])
+	if err == nil && metadata.SchemaVersion != "" && metadata.SchemaVersion != version {
+		report.check(CheckSchema, false, fmt.Sprintf("migration %s restored, the backup was taken at %s", version, metadata.SchemaVersion))
+		return
+	}
+	report.check(CheckSchema, true, "migration "+version)
+}
+
+// scratchDatabase empties the scratch database a backup is restored into
+// or, when none is configured, creates a temporary one named after the
+// purpose of the restore. It reports whether the database was created.
+func (bm *BackupManager) scratchDatabase(ctx context.Context, scratch, purpose string, now time.Time) (string, bool, error) {
+	if scratch != "" {
+		if err := bm.resetDatabase(ctx, scratch); err != nil {
+			return "", false, fmt.Errorf("failed to empty scratch database %s: %w", scratch, err)
+		}
+		return scratch, false, nil
+	}
+	scratch = fmt.Sprintf("%s_%s_%s", bm.dbConfig.DBName, purpose, now.Format("20060102_150405"))
+	if _, err := bm.queryDatabase(ctx, "postgres", "CREATE DATABASE "+quoteIdentifier(scratch)); err != nil {
+		return "", false, fmt.Errorf("failed to create temporary database %s, set BACKUP_VERIFY_SCRATCH_DB to use an existing one: %w", scratch, err)
+	}
+	return scratch, true, nil
+}
+
+// resetDatabase drops every schema of a scratch database so that only the
+// backup is left after restoring it
+func (bm *BackupManager) resetDatabase(ctx context.Context, dbName string) error {
+	rows, err := bm.queryDatabase(ctx, dbName, `SELECT nspname FROM pg_namespace
+		WHERE nspname NOT LIKE 'pg\_%' AND nspname <> 'information_schema'`)
+	if err != nil {
+		return err
+	}
+	statements := make([]string, 0, len(rows)+1)
+	for _, row := range rows {
+		statements = append(statements, "DROP SCHEMA "+quoteIdentifier(row[0])+" CASCADE;")
+	}
+	statements = append(statements, "CREATE SCHEMA public;")
+	_, err = bm.queryDatabase(ctx, dbName, strings.Join(statements, " "))
+	return err
+}
+
+// dropDatabase removes a temporary scratch database
+func (bm *BackupManager) dropDatabase(dbName string) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if _, err := bm.queryDatabase(ctx, "postgres", "DROP DATABASE IF EXISTS "+quoteIdentifier(dbName)); err != nil {
+		logger.Warn("Failed to drop temporary database %s: %v", dbName, err)
+	}
+}
+
+// dumpContents are the tables a plain SQL dump creates and the rows it
+// copies into them
+type dumpContents struct {
+	Tables []string
+	// Columns of the tables created, in order
+	Columns map[string][]DumpColumn
+	// Rows per table. A later dump of a chain replaces the rows of the
+	// tables it copies.
+	Rows map[string]int64
+}
+
+// DumpColumn is a column of a table as created by a dump
+type DumpColumn struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"` // As format_type() reports it, e.g. character varying(255)
+	NotNull bool   `json:"not_null"`
+}
+
+// columnTypeEnd matches what follows the type of a column definition
+var columnTypeEnd = regexp.MustCompile(` (?:COLLATE|DEFAULT|NOT NULL|GENERATED|CONSTRAINT) `)
+
+// parseColumn reads a column definition line of a CREATE TABLE statement;
+// table constraints are skipped
+func parseColumn(line string) (DumpColumn, bool) {
+	line = strings.TrimSuffix(strings.TrimSpace(line), ",")
+	if strings.HasPrefix(line, "CONSTRAINT ") {
+		return DumpColumn{}, false
+	}
+	name := identifierRegexp.FindString(line)
+	if name == "" || !strings.HasPrefix(line, name+" ") {
+		return DumpColumn{}, false
+	}
+	definition := line[len(name)+1:] + " "
+	columnType := definition
+	if loc := columnTypeEnd.FindStringIndex(definition); loc != nil {
+		columnType = definition[:loc[0]]
+	}
+	return DumpColumn{
+		Name:    unquoteQualifiedName(name),
+		Type:    strings.TrimSpace(columnType),
+		NotNull: strings.HasSuffix(line, " NOT NULL"),
+	}, true
+}
+
+// identifierPattern matches a plain or quoted identifier of a dump
+const identifierPattern = `(?:"(?:[^"]|"")+"|[\w$]+)`
+
+var (
+	createTablePattern = regexp.MustCompile(`^CREATE (?:UNLOGGED )?TABLE (` + identifierPattern + `\.` + identifierPattern + `)`)
+	copyPattern        = regexp.MustCompile(`^COPY (` + identifierPattern + `\.` + identifierPattern + `)(?: \(.*\))? FROM stdin;`)
+	identifierRegexp   = regexp.MustCompile(identifierPattern)
+)
+
+// read adds the tables and rows of a plain SQL dump
+func (d *dumpContents) read(sqlPath string) error {
+	file, err := os.Open(sqlPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return d.scan(file)
+}
+
+func (d *dumpContents) scan(r io.Reader) error {
+	if d.Columns == nil {
+		d.Columns = make(map[string][]DumpColumn)
+	}
+	reader := bufio.NewReaderSize(r, 64*1024)
+	copying, creating := "", ""
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			line = strings.TrimRight(line, "\r\n")
+			switch {
+			case copying != "":
+				if line == `\.` {
+					copying = ""
+				} else {
+					d.Rows[copying]++
+				}
+			case creating != "":
+				if strings.HasPrefix(line, ")") {
+					creating = ""
+				} else if column, ok := parseColumn(line); ok {
+					d.Columns[creating] = append(d.Columns[creating], column)
+				}
+			case strings.HasPrefix(line, "CREATE "):
+				if match := createTablePattern.FindStringSubmatch(line); match != nil {
+					table := unquoteQualifiedName(match[1])
+					d.Tables = append(d.Tables, table)
+					if strings.HasSuffix(line, "(") {
+						creating = table
+						d.Columns[table] = nil
+					}
+				}
+			case strings.HasPrefix(line, "COPY "):
+				if match := copyPattern.FindStringSubmatch(line); match != nil {
+					copying = unquoteQualifiedName(match[1])
+					d.Rows[copying] = 0
+				}
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// psqlErrors returns the errors psql reported while running a file
+func psqlErrors(output string) []string {
+	var errs []string
+	for _, line := range strings.Split(output, "\n") {
+		if strings.Contains(line, "ERROR:") {
+			errs = append(errs, strings.TrimSpace(line))
+		}
+	}
+	return errs
+}
+
+func summarizeErrors(errs []string) string {
+	summary := fmt.Sprintf("%d statements failed: %s", len(errs), strings.Join(errs[:min(len(errs), maxReportedErrors)], "; "))
+	if len(errs) > maxReportedErrors {
+		summary += "; ..."
+	}
+	return summary
+}
+
+// unquoteQualifiedName turns a schema.name of a dump into the name
+// PostgreSQL reports
+func unquoteQualifiedName(name string) string {
+	var parts []string
+	for _, part := range identifierRegexp.FindAllString(name, -1) {
+		if strings.HasPrefix(part, `"`) {
+			part = strings.ReplaceAll(part[1:len(part)-1], `""`, `"`)
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ".")
+}
+
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func quoteLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
@@ -0,0 +1,92 @@
+package backup
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toeic-app/internal/config"
+)
+
+const fullDump = `SET client_encoding = 'UTF8';
+DROP TABLE IF EXISTS public.words;
+CREATE TABLE public.words (
+    id integer NOT NULL,
+    word text
+);
+CREATE TABLE public."Order Items" (
+    id integer NOT NULL
+);
+CREATE TABLE public.schema_migrations (
+    version bigint NOT NULL,
+    dirty boolean NOT NULL
+);
+COPY public.words (id, word) FROM stdin;
+1	apple
+2	line\nbreak
+3	\N
+\.
+COPY public."Order Items" (id) FROM stdin;
+\.
+COPY public.schema_migrations (version, dirty) FROM stdin;
+56	f
+\.
+ALTER TABLE ONLY public.words ADD CONSTRAINT words_pkey PRIMARY KEY (id);
+`
+
+// An incremental backup replaces the rows of the tables it holds
+const incrementalDump = `-- Incremental backup of 1 tables
+SET session_replication_role = replica;
+DELETE FROM "public"."words";
+COPY public.words (id, word) FROM stdin;
+4	pear
+\.
+SET session_replication_role = DEFAULT;
+`
+
+func TestDumpContentsFollowsChain(t *testing.T) {
+	contents := dumpContents{Rows: make(map[string]int64)}
+	require.NoError(t, contents.scan(strings.NewReader(fullDump)))
+	assert.Equal(t, []string{"public.words", "public.Order Items", "public.schema_migrations"}, contents.Tables)
+	assert.Equal(t, map[string]int64{"public.words": 3, "public.Order Items": 0, "public.schema_migrations": 1}, contents.Rows)
+
+	require.NoError(t, contents.scan(strings.NewReader(incrementalDump)))
+	assert.Len(t, contents.Tables, 3)
+	assert.Equal(t, int64(1), contents.Rows["public.words"])
+	assert.Equal(t, int64(1), contents.Rows["public.schema_migrations"])
+}
+
+func TestOrphanQuery(t *testing.T) {
+	fk := foreignKey{
+		Name:       "answers_attempt_fkey",
+		Table:      "user_answers",
+		Columns:    []string{"attempt_id", `"Part"`},
+		RefTable:   "exam_attempts",
+		RefColumns: []string{"id", "part"},
+	}
+	assert.Equal(t, `SELECT count(*) FROM user_answers c WHERE c.attempt_id IS NOT NULL AND c."Part" IS NOT NULL AND NOT EXISTS (SELECT 1 FROM exam_attempts p WHERE p.id = c.attempt_id AND p.part = c."Part")`, fk.orphanQuery())
+}
+
+func TestPsqlErrors(t *testing.T) {
+	output := "SET\nCREATE TABLE\npsql:backup.sql:12: ERROR:  relation \"words\" already exists\nALTER TABLE\n"
+	assert.Equal(t, []string{`psql:backup.sql:12: ERROR:  relation "words" already exists`}, psqlErrors(output))
+	assert.Empty(t, psqlErrors("SET\nCOPY 3\n"))
+
+	errs := make([]string, 12)
+	for i := range errs {
+		errs[i] = "ERROR: failed"
+	}
+	summary := summarizeErrors(errs)
+	assert.True(t, strings.HasPrefix(summary, "12 statements failed: "))
+	assert.Equal(t, maxReportedErrors, strings.Count(summary, "ERROR"))
+}
+
+func TestVerifyRestoreRefusesApplicationDatabase(t *testing.T) {
+	bm := NewBackupManager(config.BackupConfig{BackupDir: t.TempDir(), VerifyScratchDB: "toeic"}, config.Config{DBName: "toeic"})
+	_, err := bm.VerifyRestore(context.Background(), "manual_backup_1.sql", VerifyOptions{})
+	assert.ErrorIs(t, err, ErrScratchIsLive)
+	_, err = bm.VerifyRestore(context.Background(), "manual_backup_1.sql", VerifyOptions{ScratchDB: "toeic"})
+	assert.ErrorIs(t, err, ErrScratchIsLive)
+}
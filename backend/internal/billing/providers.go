@@ -0,0 +1,462 @@
+package billing
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Billing providers
+const (
+	ProviderStripe     = "stripe"
+	ProviderGooglePlay = "google_play"
+	ProviderAppStore   = "app_store"
+	ProviderManual     = "manual"
+)
+
+// Subscription statuses. Active, trialing, grace period and canceled
+// subscriptions grant their entitlements until the end of the period.
+const (
+	StatusPending     = "pending"
+	StatusActive      = "active"
+	StatusTrialing    = "trialing"
+	StatusGracePeriod = "grace_period"
+	StatusOnHold      = "on_hold"
+	StatusCanceled    = "canceled" // Will not renew; access until the period ends
+	StatusExpired     = "expired"
+	StatusRefunded    = "refunded"
+)
+
+// stripeTolerance is how old a Stripe signature timestamp may be
+const stripeTolerance = 5 * time.Minute
+
+// ErrInvalidSignature is returned for webhook payloads that fail verification
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// ErrInvalidPayload is returned for webhook payloads that cannot be parsed
+var ErrInvalidPayload = errors.New("invalid webhook payload")
+
+// Event is a provider notification normalized to the subscription it changes.
+// Status is empty for events that do not change a subscription.
+type Event struct {
+	Provider   string
+	EventID    string
+	Type       string
+	OccurredAt time.Time
+	ExternalID string     // Stripe subscription ID, Play purchase token or App Store original transaction ID
+	ProductID  string     // Stripe price ID or store product ID
+	UserID     int32      // Set when the provider carries our user ID
+	Status     string     // New subscription status
+	PeriodEnd  *time.Time // End of the paid period when the provider reports it
+}
+
+// HasAccess reports whether a subscription status grants entitlements
+func HasAccess(status string) bool {
+	switch status {
+	case StatusActive, StatusTrialing, StatusGracePeriod, StatusCanceled:
+		return true
+	}
+	return false
+}
+
+func invalidPayload(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidPayload, fmt.Sprintf(format, args...))
+}
+
+// VerifyStripeSignature checks the Stripe-Signature header of a payload:
+// an HMAC-SHA256 of "timestamp.payload" with the endpoint secret, signed
+// within the tolerance window
+func VerifyStripeSignature(payload []byte, header, secret string, now time.Time) error {
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(part), "=")
+		if !found {
+			continue
+		}
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	if timestamp == "" || len(signatures) == 0 {
+		return fmt.Errorf("%w: malformed Stripe-Signature header", ErrInvalidSignature)
+	}
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: invalid timestamp", ErrInvalidSignature)
+	}
+	if age := now.Sub(time.Unix(seconds, 0)); age > stripeTolerance || age < -stripeTolerance {
+		return fmt.Errorf("%w: timestamp outside tolerance", ErrInvalidSignature)
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+	expected := mac.Sum(nil)
+	for _, signature := range signatures {
+		decoded, err := hex.DecodeString(signature)
+		if err == nil && hmac.Equal(decoded, expected) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+type stripeEvent struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Created int64  `json:"created"`
+	Data    struct {
+		Object stripeSubscription `json:"object"`
+	} `json:"data"`
+}
+
+type stripeSubscription struct {
+	ID                string            `json:"id"`
+	Object            string            `json:"object"`
+	Status            string            `json:"status"`
+	CurrentPeriodEnd  int64             `json:"current_period_end"`
+	CancelAtPeriodEnd bool              `json:"cancel_at_period_end"`
+	Metadata          map[string]string `json:"metadata"`
+	Items             struct {
+		Data []struct {
+			CurrentPeriodEnd int64 `json:"current_period_end"`
+			Price            struct {
+				ID string `json:"id"`
+			} `json:"price"`
+		} `json:"data"`
+	} `json:"items"`
+}
+
+// ParseStripe normalizes a verified Stripe event. Subscription lifecycle
+// events change state; the user is read from the user_id metadata set at checkout.
+func ParseStripe(payload []byte) (*Event, error) {
+	var raw stripeEvent
+	if err := json.Unmarshal(payload, &raw); err != nil {
+		return nil, invalidPayload("%v", err)
+	}
+	if raw.ID == "" || raw.Type == "" {
+		return nil, invalidPayload("missing event id or type")
+	}
+	event := &Event{
+		Provider:   ProviderStripe,
+		EventID:    raw.ID,
+		Type:       raw.Type,
+		OccurredAt: time.Unix(raw.Created, 0).UTC(),
+	}
+	if !strings.HasPrefix(raw.Type, "customer.subscription.") || raw.Data.Object.Object != "subscription" {
+		return event, nil
+	}
+
+	sub := raw.Data.Object
+	event.ExternalID = sub.ID
+	periodEnd := sub.CurrentPeriodEnd
+	if len(sub.Items.Data) > 0 {
+		event.ProductID = sub.Items.Data[0].Price.ID
+		// Newer API versions report the period on subscription items
+		if periodEnd == 0 {
+			periodEnd = sub.Items.Data[0].CurrentPeriodEnd
+		}
+	}
+	if periodEnd > 0 {
+		end := time.Unix(periodEnd, 0).UTC()
+		event.PeriodEnd = &end
+	}
+	if userID, err := strconv.ParseInt(sub.Metadata["user_id"], 10, 32); err == nil {
+		event.UserID = int32(userID)
+	}
+
+	switch sub.Status {
+	case "active":
+		event.Status = StatusActive
+		if sub.CancelAtPeriodEnd {
+			event.Status = StatusCanceled
+		}
+	case "trialing":
+		event.Status = StatusTrialing
+		if sub.CancelAtPeriodEnd {
+			event.Status = StatusCanceled
+		}
+	case "past_due":
+		event.Status = StatusGracePeriod
+	case "unpaid", "paused":
+		event.Status = StatusOnHold
+	case "incomplete":
+		event.Status = StatusPending
+	case "canceled", "incomplete_expired":
+		event.Status = StatusExpired
+	}
+	if raw.Type == "customer.subscription.deleted" {
+		event.Status = StatusExpired
+	}
+	return event, nil
+}
+
+type pubSubPush struct {
+	Message struct {
+		Data        string `json:"data"`
+		MessageID   string `json:"messageId"`
+		PublishTime string `json:"publishTime"`
+	} `json:"message"`
+}
+
+type playNotification struct {
+	EventTimeMillis          string `json:"eventTimeMillis"`
+	SubscriptionNotification *struct {
+		NotificationType int    `json:"notificationType"`
+		PurchaseToken    string `json:"purchaseToken"`
+		SubscriptionID   string `json:"subscriptionId"`
+	} `json:"subscriptionNotification"`
+	TestNotification *struct{} `json:"testNotification"`
+}
+
+// playStatuses maps Google Play subscription notification types to statuses
+var playStatuses = map[int]string{
+	1:  StatusActive,      // SUBSCRIPTION_RECOVERED
+	2:  StatusActive,      // SUBSCRIPTION_RENEWED
+	3:  StatusCanceled,    // SUBSCRIPTION_CANCELED
+	4:  StatusActive,      // SUBSCRIPTION_PURCHASED
+	5:  StatusOnHold,      // SUBSCRIPTION_ON_HOLD
+	6:  StatusGracePeriod, // SUBSCRIPTION_IN_GRACE_PERIOD
+	7:  StatusActive,      // SUBSCRIPTION_RESTARTED
+	9:  StatusActive,      // SUBSCRIPTION_DEFERRED
+	10: StatusOnHold,      // SUBSCRIPTION_PAUSED
+	12: StatusRefunded,    // SUBSCRIPTION_REVOKED
+	13: StatusExpired,     // SUBSCRIPTION_EXPIRED
+	20: StatusExpired,     // SUBSCRIPTION_PENDING_PURCHASE_CANCELED
+}
+
+// ParseGooglePlay normalizes a Real-time Developer Notification delivered by
+// a Pub/Sub push subscription. Notifications carry no expiry, so access lasts
+// until an expiry or revocation notification arrives.
+func ParseGooglePlay(payload []byte) (*Event, error) {
+	var push pubSubPush
+	if err := json.Unmarshal(payload, &push); err != nil {
+		return nil, invalidPayload("%v", err)
+	}
+	if push.Message.MessageID == "" {
+		return nil, invalidPayload("missing message id")
+	}
+	data, err := base64.StdEncoding.DecodeString(push.Message.Data)
+	if err != nil {
+		return nil, invalidPayload("message data is not base64: %v", err)
+	}
+	var notification playNotification
+	if err := json.Unmarshal(data, &notification); err != nil {
+		return nil, invalidPayload("%v", err)
+	}
+
+	event := &Event{
+		Provider:   ProviderGooglePlay,
+		EventID:    push.Message.MessageID,
+		Type:       "test",
+		OccurredAt: time.Now().UTC(),
+	}
+	if millis, err := strconv.ParseInt(notification.EventTimeMillis, 10, 64); err == nil {
+		event.OccurredAt = time.UnixMilli(millis).UTC()
+	}
+	sub := notification.SubscriptionNotification
+	if sub == nil {
+		if notification.TestNotification == nil {
+			event.Type = "other"
+		}
+		return event, nil
+	}
+	event.Type = "subscription." + strconv.Itoa(sub.NotificationType)
+	event.ExternalID = sub.PurchaseToken
+	event.ProductID = sub.SubscriptionID
+	event.Status = playStatuses[sub.NotificationType]
+	return event, nil
+}
+
+type appStoreNotification struct {
+	NotificationType string `json:"notificationType"`
+	Subtype          string `json:"subtype"`
+	NotificationUUID string `json:"notificationUUID"`
+	SignedDate       int64  `json:"signedDate"`
+	Data             struct {
+		SignedTransactionInfo string `json:"signedTransactionInfo"`
+	} `json:"data"`
+}
+
+type appStoreTransaction struct {
+	OriginalTransactionID string `json:"originalTransactionId"`
+	ProductID             string `json:"productId"`
+	ExpiresDate           int64  `json:"expiresDate"`
+}
+
+// AppStoreVerifier verifies App Store Server Notifications V2, which are JWS
+// signed by a certificate chaining to the Apple root CA
+type AppStoreVerifier struct {
+	roots *x509.CertPool
+	now   func() time.Time
+}
+
+// NewAppStoreVerifier creates a verifier trusting the given root certificates (PEM or DER)
+func NewAppStoreVerifier(rootCerts ...[]byte) (*AppStoreVerifier, error) {
+	roots := x509.NewCertPool()
+	for _, data := range rootCerts {
+		if roots.AppendCertsFromPEM(data) {
+			continue
+		}
+		cert, err := x509.ParseCertificate(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse App Store root certificate: %w", err)
+		}
+		roots.AddCert(cert)
+	}
+	return &AppStoreVerifier{roots: roots, now: time.Now}, nil
+}
+
+// verifyJWS checks the ES256 signature and x5c chain of a compact JWS and returns its payload
+func (v *AppStoreVerifier) verifyJWS(token string) ([]byte, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed JWS", ErrInvalidSignature)
+	}
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed JWS header", ErrInvalidSignature)
+	}
+	var header struct {
+		Alg string   `json:"alg"`
+		X5c []string `json:"x5c"`
+	}
+	if err := json.Unmarshal(headerJSON, &header); err != nil || header.Alg != "ES256" || len(header.X5c) == 0 {
+		return nil, fmt.Errorf("%w: unsupported JWS header", ErrInvalidSignature)
+	}
+
+	certs := make([]*x509.Certificate, 0, len(header.X5c))
+	for _, encoded := range header.X5c {
+		der, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("%w: malformed certificate", ErrInvalidSignature)
+		}
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, fmt.Errorf("%w: malformed certificate", ErrInvalidSignature)
+		}
+		certs = append(certs, cert)
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	if _, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         v.roots,
+		Intermediates: intermediates,
+		CurrentTime:   v.now(),
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+
+	key, ok := certs[0].PublicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: signing key is not ECDSA", ErrInvalidSignature)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || len(signature) != 64 {
+		return nil, fmt.Errorf("%w: malformed signature", ErrInvalidSignature)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	r := new(big.Int).SetBytes(signature[:32])
+	s := new(big.Int).SetBytes(signature[32:])
+	if !ecdsa.Verify(key, digest[:], r, s) {
+		return nil, ErrInvalidSignature
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, invalidPayload("malformed JWS payload")
+	}
+	return payload, nil
+}
+
+// Parse verifies and normalizes an App Store Server Notification V2 body
+func (v *AppStoreVerifier) Parse(body []byte) (*Event, error) {
+	var envelope struct {
+		SignedPayload string `json:"signedPayload"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil || envelope.SignedPayload == "" {
+		return nil, invalidPayload("missing signedPayload")
+	}
+	payload, err := v.verifyJWS(envelope.SignedPayload)
+	if err != nil {
+		return nil, err
+	}
+	var notification appStoreNotification
+	if err := json.Unmarshal(payload, &notification); err != nil {
+		return nil, invalidPayload("%v", err)
+	}
+	if notification.NotificationUUID == "" {
+		return nil, invalidPayload("missing notificationUUID")
+	}
+
+	event := &Event{
+		Provider:   ProviderAppStore,
+		EventID:    notification.NotificationUUID,
+		Type:       notification.NotificationType,
+		OccurredAt: time.UnixMilli(notification.SignedDate).UTC(),
+	}
+	if notification.Subtype != "" {
+		event.Type += "." + notification.Subtype
+	}
+	if notification.Data.SignedTransactionInfo == "" {
+		return event, nil
+	}
+	transactionJSON, err := v.verifyJWS(notification.Data.SignedTransactionInfo)
+	if err != nil {
+		return nil, err
+	}
+	var transaction appStoreTransaction
+	if err := json.Unmarshal(transactionJSON, &transaction); err != nil {
+		return nil, invalidPayload("%v", err)
+	}
+	event.ExternalID = transaction.OriginalTransactionID
+	event.ProductID = transaction.ProductID
+	if transaction.ExpiresDate > 0 {
+		end := time.UnixMilli(transaction.ExpiresDate).UTC()
+		event.PeriodEnd = &end
+	}
+	event.Status = appStoreStatus(notification.NotificationType, notification.Subtype)
+	return event, nil
+}
+
+// appStoreStatus maps App Store notification types to statuses
+func appStoreStatus(notificationType, subtype string) string {
+	switch notificationType {
+	case "SUBSCRIBED", "DID_RENEW", "OFFER_REDEEMED":
+		return StatusActive
+	case "DID_CHANGE_RENEWAL_STATUS":
+		if subtype == "AUTO_RENEW_DISABLED" {
+			return StatusCanceled
+		}
+		return StatusActive
+	case "DID_FAIL_TO_RENEW":
+		if subtype == "GRACE_PERIOD" {
+			return StatusGracePeriod
+		}
+		return StatusOnHold
+	case "GRACE_PERIOD_EXPIRED":
+		return StatusOnHold
+	case "EXPIRED":
+		return StatusExpired
+	case "REFUND", "REVOKE":
+		return StatusRefunded
+	}
+	return ""
+}
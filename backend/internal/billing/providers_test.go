@@ -0,0 +1,184 @@
+package billing
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func stripeHeader(payload []byte, secret string, at time.Time) string {
+	timestamp := fmt.Sprint(at.Unix())
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "." + string(payload)))
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifyStripeSignature(t *testing.T) {
+	payload := []byte(`{"id":"evt_1"}`)
+	now := time.Unix(1760000000, 0)
+
+	assert.NoError(t, VerifyStripeSignature(payload, stripeHeader(payload, "whsec", now), "whsec", now))
+	assert.True(t, errors.Is(VerifyStripeSignature(payload, stripeHeader(payload, "other", now), "whsec", now), ErrInvalidSignature))
+	assert.True(t, errors.Is(VerifyStripeSignature([]byte(`{"id":"evt_2"}`), stripeHeader(payload, "whsec", now), "whsec", now), ErrInvalidSignature))
+	assert.True(t, errors.Is(VerifyStripeSignature(payload, stripeHeader(payload, "whsec", now.Add(-10*time.Minute)), "whsec", now), ErrInvalidSignature))
+	assert.True(t, errors.Is(VerifyStripeSignature(payload, "garbage", "whsec", now), ErrInvalidSignature))
+}
+
+func TestParseStripe(t *testing.T) {
+	payload := []byte(`{
+		"id": "evt_1", "type": "customer.subscription.updated", "created": 1760000000,
+		"data": {"object": {
+			"id": "sub_1", "object": "subscription", "status": "active", "cancel_at_period_end": true,
+			"metadata": {"user_id": "42"},
+			"items": {"data": [{"current_period_end": 1762592000, "price": {"id": "price_monthly"}}]}
+		}}
+	}`)
+	event, err := ParseStripe(payload)
+	require.NoError(t, err)
+	assert.Equal(t, "sub_1", event.ExternalID)
+	assert.Equal(t, "price_monthly", event.ProductID)
+	assert.Equal(t, int32(42), event.UserID)
+	assert.Equal(t, StatusCanceled, event.Status, "active until the period ends")
+	require.NotNil(t, event.PeriodEnd)
+	assert.Equal(t, int64(1762592000), event.PeriodEnd.Unix())
+
+	other, err := ParseStripe([]byte(`{"id": "evt_2", "type": "invoice.paid", "created": 1760000000, "data": {"object": {"object": "invoice"}}}`))
+	require.NoError(t, err)
+	assert.Empty(t, other.Status)
+
+	_, err = ParseStripe([]byte(`{"type": "x"}`))
+	assert.True(t, errors.Is(err, ErrInvalidPayload))
+}
+
+func TestParseGooglePlay(t *testing.T) {
+	data := base64.StdEncoding.EncodeToString([]byte(`{
+		"eventTimeMillis": "1760000000123",
+		"subscriptionNotification": {"notificationType": 13, "purchaseToken": "token-1", "subscriptionId": "premium_monthly"}
+	}`))
+	event, err := ParseGooglePlay([]byte(`{"message": {"data": "` + data + `", "messageId": "m-1"}}`))
+	require.NoError(t, err)
+	assert.Equal(t, "m-1", event.EventID)
+	assert.Equal(t, "token-1", event.ExternalID)
+	assert.Equal(t, StatusExpired, event.Status)
+	assert.Equal(t, int64(1760000000123), event.OccurredAt.UnixMilli())
+	assert.Nil(t, event.PeriodEnd)
+
+	test := base64.StdEncoding.EncodeToString([]byte(`{"testNotification": {"version": "1.0"}}`))
+	event, err = ParseGooglePlay([]byte(`{"message": {"data": "` + test + `", "messageId": "m-2"}}`))
+	require.NoError(t, err)
+	assert.Equal(t, "test", event.Type)
+	assert.Empty(t, event.Status)
+}
+
+// testCA is a root and leaf certificate pair standing in for Apple's chain
+type testCA struct {
+	rootDER []byte
+	leafDER []byte
+	key     *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T) *testCA {
+	rootKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	root := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test Root"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	rootDER, err := x509.CreateCertificate(rand.Reader, root, root, &rootKey.PublicKey, rootKey)
+	require.NoError(t, err)
+	rootCert, err := x509.ParseCertificate(rootDER)
+	require.NoError(t, err)
+
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	leaf := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "Test Signer"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	leafDER, err := x509.CreateCertificate(rand.Reader, leaf, rootCert, &leafKey.PublicKey, rootKey)
+	require.NoError(t, err)
+	return &testCA{rootDER: rootDER, leafDER: leafDER, key: leafKey}
+}
+
+func (ca *testCA) sign(t *testing.T, claims interface{}) string {
+	header, _ := json.Marshal(map[string]interface{}{
+		"alg": "ES256",
+		"x5c": []string{base64.StdEncoding.EncodeToString(ca.leafDER), base64.StdEncoding.EncodeToString(ca.rootDER)},
+	})
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+	r, s, err := ecdsa.Sign(rand.Reader, ca.key, digest[:])
+	require.NoError(t, err)
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestAppStoreVerifier(t *testing.T) {
+	ca := newTestCA(t)
+	verifier, err := NewAppStoreVerifier(ca.rootDER)
+	require.NoError(t, err)
+
+	transaction := ca.sign(t, map[string]interface{}{
+		"originalTransactionId": "1000000001",
+		"productId":             "premium.monthly",
+		"expiresDate":           1762592000000,
+	})
+	notification := ca.sign(t, map[string]interface{}{
+		"notificationType": "DID_CHANGE_RENEWAL_STATUS",
+		"subtype":          "AUTO_RENEW_DISABLED",
+		"notificationUUID": "uuid-1",
+		"signedDate":       1760000000000,
+		"data":             map[string]string{"signedTransactionInfo": transaction},
+	})
+	body, _ := json.Marshal(map[string]string{"signedPayload": notification})
+
+	event, err := verifier.Parse(body)
+	require.NoError(t, err)
+	assert.Equal(t, "uuid-1", event.EventID)
+	assert.Equal(t, "1000000001", event.ExternalID)
+	assert.Equal(t, "premium.monthly", event.ProductID)
+	assert.Equal(t, StatusCanceled, event.Status)
+	require.NotNil(t, event.PeriodEnd)
+	assert.Equal(t, int64(1762592000000), event.PeriodEnd.UnixMilli())
+
+	// A chain that does not lead to the trusted root is rejected
+	other, err := NewAppStoreVerifier(newTestCA(t).rootDER)
+	require.NoError(t, err)
+	_, err = other.Parse(body)
+	assert.True(t, errors.Is(err, ErrInvalidSignature))
+}
+
+func TestHasAccess(t *testing.T) {
+	assert.True(t, HasAccess(StatusActive))
+	assert.True(t, HasAccess(StatusCanceled))
+	assert.False(t, HasAccess(StatusOnHold))
+	assert.False(t, HasAccess(StatusExpired))
+	assert.False(t, HasAccess(StatusPending))
+}
@@ -0,0 +1,694 @@
+package billing
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/logger"
+)
+
+// Entitlements checked by handlers
+const (
+	EntitlementPremiumExams       = "premium_exams"        // Start premium mock tests
+	EntitlementUnlimitedAIScoring = "unlimited_ai_scoring" // AI writing scoring without the daily quota
+)
+
+// Entitlements lists the entitlements a plan may grant
+var Entitlements = []string{EntitlementPremiumExams, EntitlementUnlimitedAIScoring}
+
+// FeatureAIScoring is the metered feature of users without unlimited AI scoring
+const FeatureAIScoring = "ai_scoring"
+
+// Results of processing a webhook event
+const (
+	ResultProcessed = "processed"
+	ResultIgnored   = "ignored"   // Not a subscription change, or older than the last change applied
+	ResultUnmatched = "unmatched" // No subscription yet; replayed when the purchase is linked
+	ResultFailed    = "failed"    // Reprocessed when the provider delivers it again
+	ResultDuplicate = "duplicate" // Already processed; not stored again
+)
+
+// lapseGrace is how long after the end of its period a subscription without
+// a renewal event is marked expired
+const lapseGrace = 24 * time.Hour
+
+var (
+	ErrPlanNotFound        = errors.New("plan not found")
+	ErrDuplicatePlan       = errors.New("plan code or store product already exists")
+	ErrInvalidPlan         = errors.New("invalid plan")
+	ErrUnknownProduct      = errors.New("store product is not sold by any plan")
+	ErrUnsupportedProvider = errors.New("purchases of this provider cannot be linked")
+	ErrPurchaseLinked      = errors.New("purchase is linked to another user")
+	ErrQuotaExceeded       = errors.New("daily quota exceeded")
+)
+
+// Product is a store product selling a plan
+type Product struct {
+	Provider  string `json:"provider" binding:"required,oneof=stripe google_play app_store"`
+	ProductID string `json:"product_id" binding:"required,max=255"`
+}
+
+// Plan is a plan with the store products that sell it
+type Plan struct {
+	ID           int32     `json:"id"`
+	Code         string    `json:"code"`
+	Name         string    `json:"name"`
+	Description  string    `json:"description"`
+	Entitlements []string  `json:"entitlements"`
+	IsActive     bool      `json:"is_active"`
+	Products     []Product `json:"products"`
+}
+
+// PlanInput creates or updates a plan. The code cannot be changed.
+type PlanInput struct {
+	Code         string    `json:"code" binding:"required,min=2,max=50"`
+	Name         string    `json:"name" binding:"required,max=100"`
+	Description  string    `json:"description"`
+	Entitlements []string  `json:"entitlements"`
+	IsActive     bool      `json:"is_active"`
+	Products     []Product `json:"products" binding:"dive"`
+}
+
+// Subscription is a subscription of a user with its plan code
+type Subscription struct {
+	ID               int32      `json:"id"`
+	Plan             string     `json:"plan"`
+	Provider         string     `json:"provider"`
+	Status           string     `json:"status"`
+	CurrentPeriodEnd *time.Time `json:"current_period_end,omitempty"`
+	Active           bool       `json:"active"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// Quota is the daily use of a metered feature. Limit is -1 when unlimited.
+type Quota struct {
+	Used  int32 `json:"used"`
+	Limit int32 `json:"limit"`
+}
+
+// Status describes what a user is entitled to
+type Status struct {
+	Entitlements  []string       `json:"entitlements"`
+	Subscriptions []Subscription `json:"subscriptions"`
+	AIScoring     Quota          `json:"ai_scoring"`
+}
+
+// Service resolves entitlements and reconciles subscriptions with provider events
+type Service struct {
+	store              db.Querier
+	freeAIScoresPerDay int32
+
+	mutex     sync.Mutex
+	isRunning bool
+	stopChan  chan struct{}
+	wg        sync.WaitGroup
+}
+
+// NewService creates a new billing service. Users without unlimited AI
+// scoring may score freeAIScoresPerDay writings per day.
+func NewService(store db.Querier, freeAIScoresPerDay int32) *Service {
+	return &Service{store: store, freeAIScoresPerDay: freeAIScoresPerDay}
+}
+
+// UserEntitlements returns the entitlements granted to a user by active
+// subscriptions and by time-limited roles named after a plan
+func (s *Service) UserEntitlements(ctx context.Context, userID int32) ([]string, error) {
+	entitlements, err := s.store.ListUserEntitlements(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list entitlements: %w", err)
+	}
+	if entitlements == nil {
+		entitlements = []string{}
+	}
+	return entitlements, nil
+}
+
+// HasEntitlement reports whether a user holds an entitlement
+func (s *Service) HasEntitlement(ctx context.Context, userID int32, entitlement string) (bool, error) {
+	entitlements, err := s.UserEntitlements(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	for _, e := range entitlements {
+		if e == entitlement {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func usageDate(now time.Time) time.Time {
+	return now.UTC().Truncate(24 * time.Hour)
+}
+
+// ConsumeAIScoring counts one AI scoring against the daily quota of users
+// without unlimited AI scoring. It returns whether the use was metered, so a
+// failed scoring can be released, or ErrQuotaExceeded.
+func (s *Service) ConsumeAIScoring(ctx context.Context, userID int32) (bool, error) {
+	unlimited, err := s.HasEntitlement(ctx, userID, EntitlementUnlimitedAIScoring)
+	if err != nil {
+		return false, err
+	}
+	if unlimited {
+		return false, nil
+	}
+	if s.freeAIScoresPerDay <= 0 {
+		return false, ErrQuotaExceeded
+	}
+
+	consumed, err := s.store.ConsumeEntitlementUsage(ctx, db.ConsumeEntitlementUsageParams{
+		UserID:    userID,
+		Feature:   FeatureAIScoring,
+		UsageDate: usageDate(time.Now()),
+		MaxCount:  s.freeAIScoresPerDay,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to record AI scoring use: %w", err)
+	}
+	if consumed == 0 {
+		return false, ErrQuotaExceeded
+	}
+	return true, nil
+}
+
+// ReleaseAIScoring gives back a metered AI scoring that did not complete
+func (s *Service) ReleaseAIScoring(ctx context.Context, userID int32) error {
+	err := s.store.ReleaseEntitlementUsage(ctx, db.ReleaseEntitlementUsageParams{
+		UserID:    userID,
+		Feature:   FeatureAIScoring,
+		UsageDate: usageDate(time.Now()),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to release AI scoring use: %w", err)
+	}
+	return nil
+}
+
+// UserStatus returns the entitlements, subscriptions and quotas of a user
+func (s *Service) UserStatus(ctx context.Context, userID int32) (*Status, error) {
+	entitlements, err := s.UserEntitlements(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	subscriptions, err := s.store.ListUserSubscriptions(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+	plans, err := s.planCodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &Status{
+		Entitlements:  entitlements,
+		Subscriptions: make([]Subscription, 0, len(subscriptions)),
+		AIScoring:     Quota{Limit: -1},
+	}
+	now := time.Now()
+	for _, sub := range subscriptions {
+		info := Subscription{
+			ID:        sub.ID,
+			Plan:      plans[sub.PlanID],
+			Provider:  sub.Provider,
+			Status:    sub.Status,
+			Active:    HasAccess(sub.Status) && (!sub.CurrentPeriodEnd.Valid || sub.CurrentPeriodEnd.Time.After(now)),
+			CreatedAt: sub.CreatedAt,
+		}
+		if sub.CurrentPeriodEnd.Valid {
+			end := sub.CurrentPeriodEnd.Time
+			info.CurrentPeriodEnd = &end
+		}
+		status.Subscriptions = append(status.Subscriptions, info)
+	}
+
+	unlimited := false
+	for _, e := range entitlements {
+		unlimited = unlimited || e == EntitlementUnlimitedAIScoring
+	}
+	if !unlimited {
+		used, err := s.store.GetEntitlementUsage(ctx, db.GetEntitlementUsageParams{
+			UserID:    userID,
+			Feature:   FeatureAIScoring,
+			UsageDate: usageDate(now),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get AI scoring use: %w", err)
+		}
+		status.AIScoring = Quota{Used: used, Limit: s.freeAIScoresPerDay}
+	}
+	return status, nil
+}
+
+func (s *Service) planCodes(ctx context.Context) (map[int32]string, error) {
+	plans, err := s.store.ListPlans(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list plans: %w", err)
+	}
+	codes := make(map[int32]string, len(plans))
+	for _, plan := range plans {
+		codes[plan.ID] = plan.Code
+	}
+	return codes, nil
+}
+
+// ListPlans returns plans with their store products
+func (s *Service) ListPlans(ctx context.Context, activeOnly bool) ([]Plan, error) {
+	var plans []db.Plan
+	var err error
+	if activeOnly {
+		plans, err = s.store.ListActivePlans(ctx)
+	} else {
+		plans, err = s.store.ListPlans(ctx)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list plans: %w", err)
+	}
+	products, err := s.store.ListPlanProducts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list plan products: %w", err)
+	}
+
+	byPlan := make(map[int32][]Product)
+	for _, product := range products {
+		byPlan[product.PlanID] = append(byPlan[product.PlanID], Product{Provider: product.Provider, ProductID: product.ProductID})
+	}
+	result := make([]Plan, 0, len(plans))
+	for _, plan := range plans {
+		result = append(result, newPlan(plan, byPlan[plan.ID]))
+	}
+	return result, nil
+}
+
+func newPlan(plan db.Plan, products []Product) Plan {
+	if products == nil {
+		products = []Product{}
+	}
+	entitlements := plan.Entitlements
+	if entitlements == nil {
+		entitlements = []string{}
+	}
+	return Plan{
+		ID:           plan.ID,
+		Code:         plan.Code,
+		Name:         plan.Name,
+		Description:  plan.Description,
+		Entitlements: entitlements,
+		IsActive:     plan.IsActive,
+		Products:     products,
+	}
+}
+
+// validatePlan checks the entitlements of a plan and that its products are
+// not sold by another plan
+func (s *Service) validatePlan(ctx context.Context, planID int32, input PlanInput) error {
+	for _, entitlement := range input.Entitlements {
+		known := false
+		for _, e := range Entitlements {
+			known = known || e == entitlement
+		}
+		if !known {
+			return fmt.Errorf("%w: unknown entitlement %q", ErrInvalidPlan, entitlement)
+		}
+	}
+	for _, product := range input.Products {
+		plan, err := s.store.GetPlanByProduct(ctx, db.GetPlanByProductParams{Provider: product.Provider, ProductID: product.ProductID})
+		if err == nil && plan.ID != planID {
+			return fmt.Errorf("%w: %s product %s is sold by plan %s", ErrDuplicatePlan, product.Provider, product.ProductID, plan.Code)
+		}
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to check plan product: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *Service) setProducts(ctx context.Context, planID int32, products []Product) error {
+	if err := s.store.DeletePlanProducts(ctx, planID); err != nil {
+		return fmt.Errorf("failed to replace plan products: %w", err)
+	}
+	for _, product := range products {
+		err := s.store.CreatePlanProduct(ctx, db.CreatePlanProductParams{Provider: product.Provider, ProductID: product.ProductID, PlanID: planID})
+		if err != nil {
+			return duplicateOr(err, "failed to add plan product")
+		}
+	}
+	return nil
+}
+
+func duplicateOr(err error, message string) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return ErrDuplicatePlan
+	}
+	return fmt.Errorf("%s: %w", message, err)
+}
+
+// CreatePlan creates a plan and its store products
+func (s *Service) CreatePlan(ctx context.Context, input PlanInput) (*Plan, error) {
+	if err := s.validatePlan(ctx, 0, input); err != nil {
+		return nil, err
+	}
+	plan, err := s.store.CreatePlan(ctx, db.CreatePlanParams{
+		Code:         input.Code,
+		Name:         input.Name,
+		Description:  input.Description,
+		Entitlements: input.Entitlements,
+		IsActive:     input.IsActive,
+	})
+	if err != nil {
+		return nil, duplicateOr(err, "failed to create plan")
+	}
+	if err := s.setProducts(ctx, plan.ID, input.Products); err != nil {
+		return nil, err
+	}
+	result := newPlan(plan, input.Products)
+	return &result, nil
+}
+
+// UpdatePlan updates a plan and replaces its store products
+func (s *Service) UpdatePlan(ctx context.Context, id int32, input PlanInput) (*Plan, error) {
+	if err := s.validatePlan(ctx, id, input); err != nil {
+		return nil, err
+	}
+	plan, err := s.store.UpdatePlan(ctx, db.UpdatePlanParams{
+		ID:           id,
+		Name:         input.Name,
+		Description:  input.Description,
+		Entitlements: input.Entitlements,
+		IsActive:     input.IsActive,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrPlanNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update plan: %w", err)
+	}
+	if err := s.setProducts(ctx, plan.ID, input.Products); err != nil {
+		return nil, err
+	}
+	result := newPlan(plan, input.Products)
+	return &result, nil
+}
+
+// Grant gives a user a manual subscription to a plan for a number of days
+func (s *Service) Grant(ctx context.Context, userID int32, planCode string, days int32) (db.Subscription, error) {
+	plan, err := s.store.GetPlanByCode(ctx, planCode)
+	if errors.Is(err, sql.ErrNoRows) {
+		return db.Subscription{}, ErrPlanNotFound
+	}
+	if err != nil {
+		return db.Subscription{}, fmt.Errorf("failed to get plan: %w", err)
+	}
+	now := time.Now()
+	sub, err := s.store.CreateSubscription(ctx, db.CreateSubscriptionParams{
+		UserID:           userID,
+		PlanID:           plan.ID,
+		Provider:         ProviderManual,
+		ExternalID:       fmt.Sprintf("manual-%d-%d", userID, now.UnixNano()),
+		Status:           StatusActive,
+		CurrentPeriodEnd: sql.NullTime{Time: now.AddDate(0, 0, int(days)), Valid: true},
+		LastEventAt:      sql.NullTime{Time: now, Valid: true},
+	})
+	if err != nil {
+		return sub, fmt.Errorf("failed to grant subscription: %w", err)
+	}
+	return sub, nil
+}
+
+// Link attaches a store purchase made in a mobile app to a user. Provider
+// events received before the purchase was linked are applied right away.
+func (s *Service) Link(ctx context.Context, userID int32, provider, externalID, productID string) (db.Subscription, error) {
+	if provider != ProviderGooglePlay && provider != ProviderAppStore {
+		return db.Subscription{}, ErrUnsupportedProvider
+	}
+	existing, err := s.store.GetSubscriptionByExternalID(ctx, db.GetSubscriptionByExternalIDParams{Provider: provider, ExternalID: externalID})
+	if err == nil {
+		if existing.UserID != userID {
+			return db.Subscription{}, ErrPurchaseLinked
+		}
+		return existing, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return db.Subscription{}, fmt.Errorf("failed to get subscription: %w", err)
+	}
+
+	plan, err := s.store.GetPlanByProduct(ctx, db.GetPlanByProductParams{Provider: provider, ProductID: productID})
+	if errors.Is(err, sql.ErrNoRows) {
+		return db.Subscription{}, ErrUnknownProduct
+	}
+	if err != nil {
+		return db.Subscription{}, fmt.Errorf("failed to get plan: %w", err)
+	}
+	sub, err := s.store.CreateSubscription(ctx, db.CreateSubscriptionParams{
+		UserID:     userID,
+		PlanID:     plan.ID,
+		Provider:   provider,
+		ExternalID: externalID,
+		Status:     StatusPending,
+	})
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return db.Subscription{}, ErrPurchaseLinked
+		}
+		return sub, fmt.Errorf("failed to link purchase: %w", err)
+	}
+
+	events, err := s.store.ListUnmatchedBillingEvents(ctx, db.ListUnmatchedBillingEventsParams{Provider: provider, ExternalID: externalID})
+	if err != nil {
+		return sub, fmt.Errorf("failed to list unmatched events: %w", err)
+	}
+	for _, row := range events {
+		event := &Event{
+			Provider:   row.Provider,
+			EventID:    row.EventID,
+			Type:       row.EventType,
+			OccurredAt: row.OccurredAt,
+			ExternalID: row.ExternalID,
+			ProductID:  row.ProductID,
+			Status:     row.Status,
+		}
+		if row.PeriodEnd.Valid {
+			event.PeriodEnd = &row.PeriodEnd.Time
+		}
+		var result string
+		sub, result, err = s.apply(ctx, sub, event)
+		s.setResult(ctx, row.ID, result, err)
+		if err != nil {
+			return sub, err
+		}
+	}
+	return sub, nil
+}
+
+// HandleEvent records a verified provider event and applies it to its
+// subscription. Redelivered events are skipped unless they failed before.
+func (s *Service) HandleEvent(ctx context.Context, event *Event, payload []byte) (string, error) {
+	arg := db.CreateBillingEventParams{
+		Provider:   event.Provider,
+		EventID:    event.EventID,
+		EventType:  event.Type,
+		ExternalID: event.ExternalID,
+		ProductID:  event.ProductID,
+		Status:     event.Status,
+		OccurredAt: event.OccurredAt,
+		Payload:    json.RawMessage(payload),
+	}
+	if event.PeriodEnd != nil {
+		arg.PeriodEnd = sql.NullTime{Time: *event.PeriodEnd, Valid: true}
+	}
+	id, err := s.store.CreateBillingEvent(ctx, arg)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ResultDuplicate, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to record billing event: %w", err)
+	}
+
+	result, err := s.process(ctx, event)
+	s.setResult(ctx, id, result, err)
+	logger.InfoWithFields(logger.Fields{
+		"provider":   event.Provider,
+		"event_id":   event.EventID,
+		"event_type": event.Type,
+		"result":     result,
+	}, "Billing event handled")
+	return result, err
+}
+
+func (s *Service) setResult(ctx context.Context, id int64, result string, processErr error) {
+	var message sql.NullString
+	if processErr != nil {
+		message = sql.NullString{String: processErr.Error(), Valid: true}
+	}
+	if err := s.store.SetBillingEventResult(ctx, db.SetBillingEventResultParams{ID: id, Result: result, Error: message}); err != nil {
+		logger.Error("Failed to store result of billing event %d: %v", id, err)
+	}
+}
+
+// process applies an event to its subscription, creating it when the event
+// identifies the user
+func (s *Service) process(ctx context.Context, event *Event) (string, error) {
+	if event.Status == "" || event.ExternalID == "" {
+		return ResultIgnored, nil
+	}
+
+	sub, err := s.store.GetSubscriptionByExternalID(ctx, db.GetSubscriptionByExternalIDParams{Provider: event.Provider, ExternalID: event.ExternalID})
+	if err == nil {
+		_, result, err := s.apply(ctx, sub, event)
+		return result, err
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return ResultFailed, fmt.Errorf("failed to get subscription: %w", err)
+	}
+	if event.UserID == 0 {
+		return ResultUnmatched, nil
+	}
+
+	plan, err := s.store.GetPlanByProduct(ctx, db.GetPlanByProductParams{Provider: event.Provider, ProductID: event.ProductID})
+	if errors.Is(err, sql.ErrNoRows) {
+		return ResultFailed, fmt.Errorf("%w: %s", ErrUnknownProduct, event.ProductID)
+	}
+	if err != nil {
+		return ResultFailed, fmt.Errorf("failed to get plan: %w", err)
+	}
+	arg := db.CreateSubscriptionParams{
+		UserID:      event.UserID,
+		PlanID:      plan.ID,
+		Provider:    event.Provider,
+		ExternalID:  event.ExternalID,
+		Status:      event.Status,
+		LastEventAt: sql.NullTime{Time: event.OccurredAt, Valid: true},
+	}
+	if event.PeriodEnd != nil {
+		arg.CurrentPeriodEnd = sql.NullTime{Time: *event.PeriodEnd, Valid: true}
+	}
+	if _, err := s.store.CreateSubscription(ctx, arg); err != nil {
+		return ResultFailed, fmt.Errorf("failed to create subscription: %w", err)
+	}
+	return ResultProcessed, nil
+}
+
+// apply moves a subscription to the state reported by an event. Events older
+// than the last one applied are ignored, so out-of-order delivery cannot
+// revert a newer state.
+func (s *Service) apply(ctx context.Context, sub db.Subscription, event *Event) (db.Subscription, string, error) {
+	if sub.LastEventAt.Valid && event.OccurredAt.Before(sub.LastEventAt.Time) {
+		return sub, ResultIgnored, nil
+	}
+
+	planID := sub.PlanID
+	if event.ProductID != "" {
+		plan, err := s.store.GetPlanByProduct(ctx, db.GetPlanByProductParams{Provider: event.Provider, ProductID: event.ProductID})
+		if err == nil {
+			planID = plan.ID
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return sub, ResultFailed, fmt.Errorf("failed to get plan: %w", err)
+		}
+	}
+	periodEnd := sub.CurrentPeriodEnd
+	if event.PeriodEnd != nil {
+		periodEnd = sql.NullTime{Time: *event.PeriodEnd, Valid: true}
+	}
+
+	updated, err := s.store.UpdateSubscriptionState(ctx, db.UpdateSubscriptionStateParams{
+		ID:               sub.ID,
+		PlanID:           planID,
+		Status:           event.Status,
+		CurrentPeriodEnd: periodEnd,
+		LastEventAt:      sql.NullTime{Time: event.OccurredAt, Valid: true},
+	})
+	if err != nil {
+		return sub, ResultFailed, fmt.Errorf("failed to update subscription: %w", err)
+	}
+	return updated, ResultProcessed, nil
+}
+
+// ListEvents returns received webhook events, newest first, optionally filtered by result
+func (s *Service) ListEvents(ctx context.Context, result string, limit, offset int32) ([]db.BillingEvent, error) {
+	events, err := s.store.ListBillingEvents(ctx, db.ListBillingEventsParams{Result: result, MaxItems: limit, Skip: offset})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list billing events: %w", err)
+	}
+	if events == nil {
+		events = []db.BillingEvent{}
+	}
+	return events, nil
+}
+
+// ExpireLapsed marks subscriptions whose period ended more than a day ago
+// without a renewal event as expired
+func (s *Service) ExpireLapsed(ctx context.Context) (int64, error) {
+	expired, err := s.store.ExpireLapsedSubscriptions(ctx, sql.NullTime{Time: time.Now().Add(-lapseGrace), Valid: true})
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire lapsed subscriptions: %w", err)
+	}
+	if expired > 0 {
+		logger.Info("Expired %d lapsed subscriptions", expired)
+	}
+	return expired, nil
+}
+
+// Start expires lapsed subscriptions periodically until Stop is called
+func (s *Service) Start(interval time.Duration) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.isRunning {
+		return fmt.Errorf("subscription reconciler is already running")
+	}
+	s.isRunning = true
+	s.stopChan = make(chan struct{})
+	s.wg.Add(1)
+	go s.run(interval)
+
+	logger.Info("Subscription reconciler started with interval: %v", interval)
+	return nil
+}
+
+// Stop stops the periodic reconciliation
+func (s *Service) Stop() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.isRunning {
+		return fmt.Errorf("subscription reconciler is not running")
+	}
+	close(s.stopChan)
+	s.wg.Wait()
+	s.isRunning = false
+
+	logger.Info("Subscription reconciler stopped")
+	return nil
+}
+
+// IsRunning returns whether the reconciler is running
+func (s *Service) IsRunning() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.isRunning
+}
+
+func (s *Service) run(interval time.Duration) {
+	defer s.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := s.ExpireLapsed(context.Background()); err != nil {
+				logger.Error("Failed to reconcile subscriptions: %v", err)
+			}
+		case <-s.stopChan:
+			return
+		}
+	}
+}
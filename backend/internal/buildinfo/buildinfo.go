@@ -0,0 +1,76 @@
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Set at build time, e.g.
+//
+//	go build -ldflags "-X github.com/toeic-app/internal/buildinfo.Version=1.2.0 \
+//	  -X github.com/toeic-app/internal/buildinfo.GitCommit=$(git rev-parse HEAD) \
+//	  -X github.com/toeic-app/internal/buildinfo.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	Version   = "1.0.0"
+	GitCommit = ""
+	BuildTime = ""
+)
+
+// Info describes the running binary
+type Info struct {
+	Version      string            `json:"version"`
+	GitCommit    string            `json:"git_commit"`
+	GitModified  bool              `json:"git_modified"`
+	BuildTime    string            `json:"build_time"`
+	GoVersion    string            `json:"go_version"`
+	Platform     string            `json:"platform"`
+	Module       string            `json:"module"`
+	Dependencies map[string]string `json:"dependencies"`
+}
+
+// Get returns build information, falling back to the VCS data embedded by
+// the Go toolchain when ldflags were not set
+func Get() Info {
+	info := Info{
+		Version:      Version,
+		GitCommit:    GitCommit,
+		BuildTime:    BuildTime,
+		GoVersion:    runtime.Version(),
+		Platform:     runtime.GOOS + "/" + runtime.GOARCH,
+		Dependencies: make(map[string]string),
+	}
+
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+
+	info.Module = build.Main.Path
+	for _, setting := range build.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if info.GitCommit == "" {
+				info.GitCommit = setting.Value
+			}
+		case "vcs.time":
+			if info.BuildTime == "" {
+				info.BuildTime = setting.Value
+			}
+		case "vcs.modified":
+			info.GitModified = setting.Value == "true"
+		}
+	}
+
+	for _, dep := range build.Deps {
+		version := dep.Version
+		if dep.Replace != nil {
+			version = dep.Replace.Path + "@" + dep.Replace.Version
+		}
+		info.Dependencies[dep.Path] = version
+	}
+
+	if info.GitCommit == "" {
+		info.GitCommit = "unknown"
+	}
+	return info
+}
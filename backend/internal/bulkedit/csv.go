@@ -0,0 +1,227 @@
+package bulkedit
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/toeic-app/internal/choices"
+)
+
+var (
+	ErrMissingIDColumn = errors.New("file needs a question_id or content_id column")
+	ErrInvalidFile     = errors.New("file is not a readable CSV file")
+)
+
+// RowError is a CSV row that could not be read
+type RowError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// questionColumns and contentColumns map header names to patch fields
+var questionColumns = map[string]string{
+	"question_id":      "id",
+	"id":               "id",
+	"title":            "title",
+	"explanation":      "explanation",
+	"keywords":         "keywords",
+	"add_keywords":     "add_keywords",
+	"remove_keywords":  "remove_keywords",
+	"difficulty":       "difficulty",
+	"options":          "options",
+	"answer_key":       "answer_key",
+	"true_answer":      "true_answer",
+	"possible_answers": "possible_answers",
+}
+
+var contentColumns = map[string]string{
+	"content_id":  "id",
+	"id":          "id",
+	"type":        "type",
+	"description": "description",
+}
+
+// row gives the trimmed cell of a column, and whether the cell has a value
+type row func(name string) (string, bool)
+
+// readRows reads a CSV file with a header row, calling parse for each
+// record. Empty cells leave the field unchanged.
+func readRows(r io.Reader, aliases map[string]string, parse func(line int, cell row) error) ([]RowError, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, ErrMissingIDColumn
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if field, ok := aliases[name]; ok {
+			if _, seen := columns[field]; !seen {
+				columns[field] = i
+			}
+		}
+	}
+	if _, ok := columns["id"]; !ok {
+		return nil, ErrMissingIDColumn
+	}
+
+	var rowErrors []RowError
+	rows := 0
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				rowErrors = append(rowErrors, RowError{Line: parseErr.StartLine, Error: parseErr.Err.Error()})
+				continue
+			}
+			return nil, fmt.Errorf("failed to read file: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+		if rows++; rows > MaxItems {
+			return nil, ErrBatchTooLarge
+		}
+
+		cell := func(name string) (string, bool) {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return "", false
+			}
+			value := strings.TrimSpace(record[i])
+			return value, value != ""
+		}
+		if err := parse(line, cell); err != nil {
+			rowErrors = append(rowErrors, RowError{Line: line, Error: err.Error()})
+		}
+	}
+	return rowErrors, nil
+}
+
+func parseID(cell row) (int32, error) {
+	value, _ := cell("id")
+	id, err := strconv.ParseInt(value, 10, 32)
+	if err != nil || id < 1 {
+		return 0, fmt.Errorf("invalid id %q", value)
+	}
+	return int32(id), nil
+}
+
+// ParseQuestions reads question patches from a CSV file. options are
+// written "A=text;B=text", possible_answers are separated by ";",
+// add_keywords and remove_keywords by ";" or ",". A difficulty of 0 clears
+// it.
+func ParseQuestions(r io.Reader) ([]QuestionPatch, []RowError, error) {
+	var patches []QuestionPatch
+	rowErrors, err := readRows(r, questionColumns, func(line int, cell row) error {
+		id, err := parseID(cell)
+		if err != nil {
+			return err
+		}
+		patch := QuestionPatch{Line: line, QuestionID: id}
+		if value, ok := cell("title"); ok {
+			patch.Title = &value
+		}
+		if value, ok := cell("explanation"); ok {
+			patch.Explanation = &value
+		}
+		if value, ok := cell("keywords"); ok {
+			patch.Keywords = &value
+		}
+		if value, ok := cell("add_keywords"); ok {
+			patch.AddKeywords = splitList(value)
+		}
+		if value, ok := cell("remove_keywords"); ok {
+			patch.RemoveKeywords = splitList(value)
+		}
+		if value, ok := cell("difficulty"); ok {
+			difficulty, err := strconv.ParseInt(value, 10, 16)
+			if err != nil {
+				return fmt.Errorf("invalid difficulty %q", value)
+			}
+			patch.Difficulty = new(int16)
+			*patch.Difficulty = int16(difficulty)
+		}
+		if value, ok := cell("options"); ok {
+			options, err := parseOptions(value)
+			if err != nil {
+				return err
+			}
+			patch.Options = options
+		}
+		if value, ok := cell("answer_key"); ok {
+			value = strings.ToUpper(value)
+			patch.AnswerKey = &value
+		}
+		if value, ok := cell("true_answer"); ok {
+			patch.TrueAnswer = &value
+		}
+		if value, ok := cell("possible_answers"); ok {
+			for _, answer := range strings.Split(value, ";") {
+				patch.PossibleAnswers = append(patch.PossibleAnswers, strings.TrimSpace(answer))
+			}
+		}
+		patches = append(patches, patch)
+		return nil
+	})
+	return patches, rowErrors, err
+}
+
+// ParseContents reads content patches from a CSV file
+func ParseContents(r io.Reader) ([]ContentPatch, []RowError, error) {
+	var patches []ContentPatch
+	rowErrors, err := readRows(r, contentColumns, func(line int, cell row) error {
+		id, err := parseID(cell)
+		if err != nil {
+			return err
+		}
+		patch := ContentPatch{Line: line, ContentID: id}
+		if value, ok := cell("type"); ok {
+			patch.Type = &value
+		}
+		if value, ok := cell("description"); ok {
+			patch.Description = &value
+		}
+		patches = append(patches, patch)
+		return nil
+	})
+	return patches, rowErrors, err
+}
+
+// parseOptions reads options written "A=text;B=text"
+func parseOptions(value string) ([]choices.Option, error) {
+	var options []choices.Option
+	for _, option := range strings.Split(value, ";") {
+		key, text, ok := strings.Cut(option, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid option %q, expected KEY=text", strings.TrimSpace(option))
+		}
+		options = append(options, choices.Option{
+			Key:  strings.ToUpper(strings.TrimSpace(key)),
+			Text: strings.TrimSpace(text),
+		})
+	}
+	return options, nil
+}
+
+func splitList(value string) []string {
+	return SplitKeywords(strings.ReplaceAll(value, ";", ","))
+}
+
+// Rejected is the result of a CSV file with unreadable rows, none of which
+// is applied
+func Rejected(rowErrors []RowError, dryRun bool) *Result {
+	return &Result{DryRun: dryRun, Failed: len(rowErrors), Items: []ItemResult{}, RowErrors: rowErrors}
+}
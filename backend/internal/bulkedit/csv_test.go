@@ -0,0 +1,56 @@
+package bulkedit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toeic-app/internal/choices"
+)
+
+func TestParseQuestions(t *testing.T) {
+	file := "\ufeffQuestion_ID,explanation,difficulty,add_keywords,possible_answers\n" +
+		"1,Fixed explanation,,schedule; agenda,\n" +
+		"\n" +
+		"2,,3,,A;B;C\n" +
+		"x,bad id,,,\n" +
+		"4,,hard,,\n"
+
+	patches, rowErrors, err := ParseQuestions(strings.NewReader(file))
+	require.NoError(t, err)
+	require.Len(t, patches, 2)
+
+	assert.Equal(t, int32(1), patches[0].QuestionID)
+	assert.Equal(t, "Fixed explanation", *patches[0].Explanation)
+	assert.Nil(t, patches[0].Difficulty)
+	assert.Equal(t, []string{"schedule", "agenda"}, patches[0].AddKeywords)
+
+	assert.Equal(t, 4, patches[1].Line)
+	assert.Nil(t, patches[1].Explanation)
+	assert.Equal(t, int16(3), *patches[1].Difficulty)
+	assert.Equal(t, []string{"A", "B", "C"}, patches[1].PossibleAnswers)
+
+	assert.Equal(t, []RowError{
+		{Line: 5, Error: `invalid id "x"`},
+		{Line: 6, Error: `invalid difficulty "hard"`},
+	}, rowErrors)
+}
+
+func TestParseQuestionOptions(t *testing.T) {
+	file := "question_id,options,answer_key\n" +
+		"1,a=At noon; B=At 3 p.m.,b\n" +
+		"2,At noon,\n"
+
+	patches, rowErrors, err := ParseQuestions(strings.NewReader(file))
+	require.NoError(t, err)
+	require.Len(t, patches, 1)
+	assert.Equal(t, []choices.Option{{Key: "A", Text: "At noon"}, {Key: "B", Text: "At 3 p.m."}}, patches[0].Options)
+	assert.Equal(t, "B", *patches[0].AnswerKey)
+	assert.Equal(t, []RowError{{Line: 3, Error: `invalid option "At noon", expected KEY=text`}}, rowErrors)
+}
+
+func TestParseContentsNeedsID(t *testing.T) {
+	_, _, err := ParseContents(strings.NewReader("type,description\ntalk,text\n"))
+	assert.ErrorIs(t, err, ErrMissingIDColumn)
+}
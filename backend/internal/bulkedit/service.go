@@ -0,0 +1,423 @@
+// Package bulkedit applies partial updates to many questions or contents at
+// once. A batch runs in one transaction: it is applied only when every item
+// succeeds, and each item reports what happened to it.
+package bulkedit
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/toeic-app/internal/choices"
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+var (
+	ErrEmptyBatch    = errors.New("batch has no items")
+	ErrBatchTooLarge = fmt.Errorf("batch has more than %d items", MaxItems)
+)
+
+// MaxItems bounds the size of one batch
+const MaxItems = 1000
+
+// Item statuses
+const (
+	// StatusUpdated items were changed, or would be on a dry run
+	StatusUpdated = "updated"
+	// StatusUnchanged items already had the requested values
+	StatusUnchanged = "unchanged"
+	// StatusFailed items could not be applied and made the batch roll back
+	StatusFailed = "failed"
+	// StatusRolledBack items succeeded but were undone because another failed
+	StatusRolledBack = "rolled_back"
+)
+
+// itemError fails a single item rather than the whole batch
+type itemError string
+
+func (e itemError) Error() string { return string(e) }
+
+const (
+	errNotFound      itemError = "not found"
+	errDuplicate     itemError = "appears earlier in the batch"
+	errNoChanges     itemError = "no fields to update"
+	errMissingID     itemError = "id is required"
+	errDifficulty    itemError = "difficulty must be between 1 and 6, or 0 to clear it"
+	errNoAnswers     itemError = "answer options cannot be empty"
+	errAnswerMissing itemError = "correct answer is not one of the options"
+	errEmptyTitle    itemError = "title cannot be empty"
+	errEmptyType     itemError = "type cannot be empty"
+)
+
+// QuestionPatch changes some fields of a question; fields left out keep
+// their value
+type QuestionPatch struct {
+	// Line is the CSV line the patch was read from
+	Line        int     `json:"-"`
+	QuestionID  int32   `json:"question_id"`
+	Title       *string `json:"title,omitempty" sanitize:"plain"`
+	Explanation *string `json:"explanation,omitempty" sanitize:"markdown"`
+	// Options replace the answer options and AnswerKey picks the correct
+	// one; older clients send PossibleAnswers and TrueAnswer texts instead
+	Options         []choices.Option `json:"options,omitempty"`
+	AnswerKey       *string          `json:"answer_key,omitempty" example:"A"`
+	PossibleAnswers []string         `json:"possible_answers,omitempty" sanitize:"plain"`
+	TrueAnswer      *string          `json:"true_answer,omitempty" sanitize:"plain"`
+	// Keywords replaces the comma separated keywords; empty clears them
+	Keywords       *string  `json:"keywords,omitempty"`
+	AddKeywords    []string `json:"add_keywords,omitempty"`
+	RemoveKeywords []string `json:"remove_keywords,omitempty"`
+	// Difficulty is 1 (easiest) to 6; 0 clears it
+	Difficulty *int16 `json:"difficulty,omitempty"`
+}
+
+// ContentPatch changes some fields of a content
+type ContentPatch struct {
+	Line        int     `json:"-"`
+	ContentID   int32   `json:"content_id"`
+	Type        *string `json:"type,omitempty"`
+	Description *string `json:"description,omitempty" sanitize:"rich_text"`
+}
+
+// ItemResult is what happened to one item of a batch
+type ItemResult struct {
+	Index   int      `json:"index"`
+	Line    int      `json:"line,omitempty"` // Set for CSV batches
+	ID      int32    `json:"id"`
+	Status  string   `json:"status"`
+	Changed []string `json:"changed,omitempty"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// Result describes a batch
+type Result struct {
+	// Applied is true when the changes were committed
+	Applied   bool         `json:"applied"`
+	DryRun    bool         `json:"dry_run"`
+	Updated   int          `json:"updated"`
+	Unchanged int          `json:"unchanged"`
+	Failed    int          `json:"failed"`
+	Items     []ItemResult `json:"items"`
+	// RowErrors are CSV rows that could not be read; none of the file is
+	// applied when there are any
+	RowErrors []RowError `json:"row_errors,omitempty"`
+}
+
+// batchItem identifies an item of a batch
+type batchItem struct {
+	ID   int32
+	Line int
+}
+
+// TxFunc runs fn in a transaction, committing when it returns nil and rolling
+// back otherwise
+type TxFunc func(ctx context.Context, fn func(db.Querier) error) error
+
+// Service applies bulk edits
+type Service struct {
+	inTx TxFunc
+}
+
+// NewService creates a bulk edit service running batches on conn
+func NewService(conn *sql.DB) *Service {
+	return &Service{inTx: func(ctx context.Context, fn func(db.Querier) error) error {
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		if err := fn(db.New(tx)); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+		return tx.Commit()
+	}}
+}
+
+// errRollback makes the transaction roll back after every item was tried
+var errRollback = errors.New("rollback")
+
+// run validates every item, applies the valid ones in a transaction and
+// keeps the changes only when nothing failed and this is not a dry run
+func (s *Service) run(ctx context.Context, items []batchItem, dryRun bool, validate func(i int) error,
+	apply func(store db.Querier, i int) ([]string, error)) (*Result, error) {
+	if len(items) == 0 {
+		return nil, ErrEmptyBatch
+	}
+	if len(items) > MaxItems {
+		return nil, ErrBatchTooLarge
+	}
+
+	result := &Result{DryRun: dryRun, Items: make([]ItemResult, len(items))}
+	seen := make(map[int32]bool, len(items))
+	for i, batched := range items {
+		item := &result.Items[i]
+		item.Index, item.Line, item.ID = i, batched.Line, batched.ID
+		err := validate(i)
+		if err == nil && seen[batched.ID] {
+			err = errDuplicate
+		}
+		seen[batched.ID] = true
+		if err != nil {
+			item.Status, item.Error = StatusFailed, err.Error()
+		}
+	}
+
+	err := s.inTx(ctx, func(store db.Querier) error {
+		for i := range result.Items {
+			item := &result.Items[i]
+			if item.Status == StatusFailed {
+				continue
+			}
+			changed, err := apply(store, i)
+			var failure itemError
+			switch {
+			case errors.As(err, &failure):
+				item.Status, item.Error = StatusFailed, err.Error()
+			case err != nil:
+				return err
+			case len(changed) == 0:
+				item.Status = StatusUnchanged
+			default:
+				item.Status, item.Changed = StatusUpdated, changed
+			}
+		}
+		if dryRun || slices.ContainsFunc(result.Items, func(item ItemResult) bool { return item.Status == StatusFailed }) {
+			return errRollback
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errRollback) {
+		return nil, err
+	}
+	result.Applied = err == nil
+
+	for i := range result.Items {
+		item := &result.Items[i]
+		switch item.Status {
+		case StatusFailed:
+			result.Failed++
+		case StatusUnchanged:
+			result.Unchanged++
+		case StatusUpdated:
+			if !result.Applied && !dryRun {
+				item.Status = StatusRolledBack
+				continue
+			}
+			result.Updated++
+		}
+	}
+	return result, nil
+}
+
+// Questions applies question patches. Nothing is kept when an item fails or
+// dryRun is set.
+func (s *Service) Questions(ctx context.Context, patches []QuestionPatch, dryRun bool) (*Result, error) {
+	items := make([]batchItem, len(patches))
+	for i, patch := range patches {
+		items[i] = batchItem{ID: patch.QuestionID, Line: patch.Line}
+	}
+	return s.run(ctx, items, dryRun,
+		func(i int) error { return patches[i].validate() },
+		func(store db.Querier, i int) ([]string, error) {
+			question, err := store.GetQuestionForUpdate(ctx, patches[i].QuestionID)
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, fmt.Errorf("question %w", errNotFound)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to get question: %w", err)
+			}
+			arg, changed, err := patches[i].apply(question)
+			if err != nil || len(changed) == 0 {
+				return nil, err
+			}
+			if _, err := store.UpdateQuestion(ctx, arg); err != nil {
+				return nil, fmt.Errorf("failed to update question: %w", err)
+			}
+			return changed, nil
+		})
+}
+
+// Contents applies content patches. Nothing is kept when an item fails or
+// dryRun is set.
+func (s *Service) Contents(ctx context.Context, patches []ContentPatch, dryRun bool) (*Result, error) {
+	items := make([]batchItem, len(patches))
+	for i, patch := range patches {
+		items[i] = batchItem{ID: patch.ContentID, Line: patch.Line}
+	}
+	return s.run(ctx, items, dryRun,
+		func(i int) error { return patches[i].validate() },
+		func(store db.Querier, i int) ([]string, error) {
+			content, err := store.GetContentForUpdate(ctx, patches[i].ContentID)
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, fmt.Errorf("content %w", errNotFound)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to get content: %w", err)
+			}
+			arg, changed := patches[i].apply(content)
+			if len(changed) == 0 {
+				return nil, nil
+			}
+			if _, err := store.UpdateContent(ctx, arg); err != nil {
+				return nil, fmt.Errorf("failed to update content: %w", err)
+			}
+			return changed, nil
+		})
+}
+
+func (p QuestionPatch) validate() error {
+	switch {
+	case p.QuestionID <= 0:
+		return errMissingID
+	case p.Title == nil && p.Explanation == nil && p.Options == nil && p.AnswerKey == nil &&
+		p.PossibleAnswers == nil && p.TrueAnswer == nil && p.Keywords == nil && p.AddKeywords == nil && p.RemoveKeywords == nil && p.Difficulty == nil:
+		return errNoChanges
+	case p.Title != nil && strings.TrimSpace(*p.Title) == "":
+		return errEmptyTitle
+	case p.Difficulty != nil && (*p.Difficulty < 0 || *p.Difficulty > 6):
+		return errDifficulty
+	case p.Options != nil && len(p.Options) == 0, p.PossibleAnswers != nil && len(p.PossibleAnswers) == 0:
+		return errNoAnswers
+	}
+	return nil
+}
+
+// apply merges a patch into a question and names the fields it changes.
+// Options and the correct answer are compared by key; the legacy texts
+// follow them.
+func (p QuestionPatch) apply(question db.Question) (db.UpdateQuestionParams, []string, error) {
+	arg := db.UpdateQuestionParams{
+		QuestionID:  question.QuestionID,
+		ContentID:   question.ContentID,
+		Title:       question.Title,
+		MediaUrl:    question.MediaUrl,
+		ImageUrl:    question.ImageUrl,
+		Explanation: question.Explanation,
+		Keywords:    question.Keywords,
+		Difficulty:  question.Difficulty,
+	}
+	var changed []string
+	if p.Title != nil && *p.Title != arg.Title {
+		arg.Title = *p.Title
+		changed = append(changed, "title")
+	}
+	if p.Explanation != nil && *p.Explanation != arg.Explanation {
+		arg.Explanation = *p.Explanation
+		changed = append(changed, "explanation")
+	}
+	options, answerKey := choices.Of(question), choices.AnswerOf(question)
+	if p.Options != nil || p.AnswerKey != nil || p.PossibleAnswers != nil || p.TrueAnswer != nil {
+		change := choices.Change{Options: p.Options, Texts: p.PossibleAnswers}
+		if p.AnswerKey != nil {
+			change.AnswerKey = *p.AnswerKey
+		}
+		if p.TrueAnswer != nil {
+			change.TrueAnswer = *p.TrueAnswer
+		}
+		newOptions, newKey, err := change.Apply(options, answerKey)
+		if errors.Is(err, choices.ErrUnknownOption) || errors.Is(err, choices.ErrNoAnswer) {
+			return arg, nil, errAnswerMissing
+		}
+		if err != nil {
+			return arg, nil, itemError(err.Error())
+		}
+		if !slices.Equal(newOptions, options) {
+			changed = append(changed, "options")
+		}
+		if newKey != answerKey {
+			changed = append(changed, "answer_key")
+		}
+		options, answerKey = newOptions, newKey
+	}
+	answer, _ := choices.Find(options, answerKey)
+	arg.Options = choices.Encode(options)
+	arg.AnswerKey = answerKey
+	arg.PossibleAnswers = choices.Texts(options)
+	arg.TrueAnswer = answer.Text
+	if keywords := p.keywords(arg.Keywords); keywords != arg.Keywords {
+		arg.Keywords = keywords
+		changed = append(changed, "keywords")
+	}
+	if p.Difficulty != nil {
+		difficulty := sql.NullInt16{Int16: *p.Difficulty, Valid: *p.Difficulty > 0}
+		if difficulty != arg.Difficulty {
+			arg.Difficulty = difficulty
+			changed = append(changed, "difficulty")
+		}
+	}
+	return arg, changed, nil
+}
+
+// keywords returns the keywords of a question after the patch
+func (p QuestionPatch) keywords(current sql.NullString) sql.NullString {
+	if p.Keywords == nil && p.AddKeywords == nil && p.RemoveKeywords == nil {
+		return current
+	}
+	source := current.String
+	if p.Keywords != nil {
+		source = *p.Keywords
+	}
+	keywords := SplitKeywords(source)
+	for _, keyword := range SplitKeywords(strings.Join(p.AddKeywords, ",")) {
+		if !slices.ContainsFunc(keywords, func(k string) bool { return strings.EqualFold(k, keyword) }) {
+			keywords = append(keywords, keyword)
+		}
+	}
+	for _, keyword := range p.RemoveKeywords {
+		keywords = slices.DeleteFunc(keywords, func(k string) bool { return strings.EqualFold(k, strings.TrimSpace(keyword)) })
+	}
+	if len(keywords) == 0 {
+		// Clearing keywords that were never set is not a change
+		if !current.Valid {
+			return current
+		}
+		return sql.NullString{}
+	}
+	return sql.NullString{String: strings.Join(keywords, ", "), Valid: true}
+}
+
+// SplitKeywords splits comma separated keywords, dropping empty ones
+func SplitKeywords(value string) []string {
+	var keywords []string
+	for _, keyword := range strings.Split(value, ",") {
+		if keyword = strings.TrimSpace(keyword); keyword != "" {
+			keywords = append(keywords, keyword)
+		}
+	}
+	return keywords
+}
+
+func (p ContentPatch) validate() error {
+	switch {
+	case p.ContentID <= 0:
+		return errMissingID
+	case p.Type == nil && p.Description == nil:
+		return errNoChanges
+	case p.Type != nil && strings.TrimSpace(*p.Type) == "":
+		return errEmptyType
+	}
+	return nil
+}
+
+// apply merges a patch into a content and names the fields it changes
+func (p ContentPatch) apply(content db.Content) (db.UpdateContentParams, []string) {
+	arg := db.UpdateContentParams{
+		ContentID:   content.ContentID,
+		PartID:      content.PartID,
+		Type:        content.Type,
+		Description: content.Description,
+	}
+	var changed []string
+	if p.Type != nil && *p.Type != arg.Type {
+		arg.Type = *p.Type
+		changed = append(changed, "type")
+	}
+	if p.Description != nil && *p.Description != arg.Description {
+		arg.Description = *p.Description
+		changed = append(changed, "description")
+	}
+	return arg, changed
+}
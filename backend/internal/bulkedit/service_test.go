@@ -0,0 +1,178 @@
+package bulkedit
+
+import (
+	"context"
+	"database/sql"
+	"maps"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toeic-app/internal/choices"
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+// editStore keeps questions and contents in memory and restores them when a
+// transaction rolls back; other Querier methods are not used
+type editStore struct {
+	db.Querier
+	questions map[int32]db.Question
+	contents  map[int32]db.Content
+}
+
+func newEditStore() *editStore {
+	return &editStore{
+		questions: map[int32]db.Question{
+			1: {QuestionID: 1, ContentID: 1, Title: "Where is the meeting?", PossibleAnswers: []string{"A", "B", "C"}, TrueAnswer: "A",
+				Explanation: "old", Keywords: sql.NullString{String: "where, meeting", Valid: true}, Difficulty: sql.NullInt16{Int16: 2, Valid: true}},
+			2: {QuestionID: 2, ContentID: 1, Title: "Who called?", PossibleAnswers: []string{"A", "B"}, TrueAnswer: "B", Explanation: "old"},
+		},
+		contents: map[int32]db.Content{1: {ContentID: 1, PartID: 1, Type: "conversation", Description: "old"}},
+	}
+}
+
+func (s *editStore) GetQuestionForUpdate(ctx context.Context, id int32) (db.Question, error) {
+	question, ok := s.questions[id]
+	if !ok {
+		return db.Question{}, sql.ErrNoRows
+	}
+	return question, nil
+}
+
+func (s *editStore) UpdateQuestion(ctx context.Context, arg db.UpdateQuestionParams) (db.Question, error) {
+	question := db.Question(arg)
+	s.questions[arg.QuestionID] = question
+	return question, nil
+}
+
+func (s *editStore) GetContentForUpdate(ctx context.Context, id int32) (db.Content, error) {
+	content, ok := s.contents[id]
+	if !ok {
+		return db.Content{}, sql.ErrNoRows
+	}
+	return content, nil
+}
+
+func (s *editStore) UpdateContent(ctx context.Context, arg db.UpdateContentParams) (db.Content, error) {
+	content := db.Content(arg)
+	s.contents[arg.ContentID] = content
+	return content, nil
+}
+
+func newTestService(store *editStore) *Service {
+	return &Service{inTx: func(ctx context.Context, fn func(db.Querier) error) error {
+		questions, contents := maps.Clone(store.questions), maps.Clone(store.contents)
+		if err := fn(store); err != nil {
+			store.questions, store.contents = questions, contents
+			return err
+		}
+		return nil
+	}}
+}
+
+func ptr[T any](value T) *T {
+	return &value
+}
+
+func TestQuestionsApplied(t *testing.T) {
+	store := newEditStore()
+	service := newTestService(store)
+
+	result, err := service.Questions(context.Background(), []QuestionPatch{
+		{QuestionID: 1, Explanation: ptr("new"), AddKeywords: []string{"Meeting", "schedule"}, RemoveKeywords: []string{"where"}, Difficulty: ptr[int16](4)},
+		{QuestionID: 2, Explanation: ptr("old")},
+	}, false)
+	require.NoError(t, err)
+
+	assert.True(t, result.Applied)
+	assert.Equal(t, 1, result.Updated)
+	assert.Equal(t, 1, result.Unchanged)
+	assert.Equal(t, StatusUpdated, result.Items[0].Status)
+	assert.Equal(t, []string{"explanation", "keywords", "difficulty"}, result.Items[0].Changed)
+	assert.Equal(t, StatusUnchanged, result.Items[1].Status)
+
+	question := store.questions[1]
+	assert.Equal(t, "new", question.Explanation)
+	assert.Equal(t, "meeting, schedule", question.Keywords.String)
+	assert.Equal(t, int16(4), question.Difficulty.Int16)
+}
+
+func TestQuestionsRollBackOnFailure(t *testing.T) {
+	store := newEditStore()
+	service := newTestService(store)
+
+	result, err := service.Questions(context.Background(), []QuestionPatch{
+		{QuestionID: 1, Explanation: ptr("new")},
+		{QuestionID: 2, TrueAnswer: ptr("C")},
+		{QuestionID: 3, Explanation: ptr("new")},
+		{QuestionID: 1, Difficulty: ptr[int16](9)},
+	}, false)
+	require.NoError(t, err)
+
+	assert.False(t, result.Applied)
+	assert.Equal(t, 3, result.Failed)
+	assert.Equal(t, StatusRolledBack, result.Items[0].Status)
+	assert.Equal(t, errAnswerMissing.Error(), result.Items[1].Error)
+	assert.Equal(t, "question not found", result.Items[2].Error)
+	assert.Equal(t, errDifficulty.Error(), result.Items[3].Error)
+	assert.Equal(t, "old", store.questions[1].Explanation)
+}
+
+func TestQuestionsDryRun(t *testing.T) {
+	store := newEditStore()
+	service := newTestService(store)
+
+	result, err := service.Questions(context.Background(), []QuestionPatch{
+		{QuestionID: 2, PossibleAnswers: []string{"A", "B", "C"}, Keywords: ptr(""), Difficulty: ptr[int16](0)},
+	}, true)
+	require.NoError(t, err)
+
+	assert.False(t, result.Applied)
+	assert.Equal(t, 1, result.Updated)
+	// Clearing keywords and difficulty that were never set changes nothing
+	assert.Equal(t, []string{"options"}, result.Items[0].Changed)
+	assert.Len(t, store.questions[2].PossibleAnswers, 2)
+}
+
+func TestQuestionsKeyedOptions(t *testing.T) {
+	store := newEditStore()
+	service := newTestService(store)
+
+	result, err := service.Questions(context.Background(), []QuestionPatch{
+		// Reordering texts keeps their keys, so the answer stays B
+		{QuestionID: 2, PossibleAnswers: []string{"B", "A"}},
+		{QuestionID: 1, Options: []choices.Option{{Key: "A", Text: "In room 2"}, {Key: "B", Text: "Upstairs"}}, AnswerKey: ptr("B")},
+	}, false)
+	require.NoError(t, err)
+	require.True(t, result.Applied)
+	assert.Equal(t, []string{"options"}, result.Items[0].Changed)
+	assert.Equal(t, []string{"options", "answer_key"}, result.Items[1].Changed)
+
+	assert.Equal(t, "B", store.questions[2].AnswerKey)
+	assert.Equal(t, []string{"B", "A"}, store.questions[2].PossibleAnswers)
+	// The legacy texts follow the keyed options
+	assert.Equal(t, []string{"In room 2", "Upstairs"}, store.questions[1].PossibleAnswers)
+	assert.Equal(t, "Upstairs", store.questions[1].TrueAnswer)
+
+	result, err = service.Questions(context.Background(), []QuestionPatch{
+		{QuestionID: 1, Options: []choices.Option{{Key: "A", Text: "In room 2"}}},
+	}, false)
+	require.NoError(t, err)
+	assert.Equal(t, errAnswerMissing.Error(), result.Items[0].Error)
+}
+
+func TestBatchChecks(t *testing.T) {
+	service := newTestService(newEditStore())
+
+	_, err := service.Questions(context.Background(), nil, false)
+	assert.ErrorIs(t, err, ErrEmptyBatch)
+
+	result, err := service.Contents(context.Background(), []ContentPatch{
+		{ContentID: 1, Description: ptr("new")},
+		{ContentID: 1, Type: ptr("talk")},
+		{ContentID: 1},
+	}, false)
+	require.NoError(t, err)
+	assert.Equal(t, errDuplicate.Error(), result.Items[1].Error)
+	assert.Equal(t, errNoChanges.Error(), result.Items[2].Error)
+}
@@ -0,0 +1,163 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	// ErrLockHeld is returned when the lock is held by another owner
+	ErrLockHeld = errors.New("lock is held by another owner")
+	// ErrLockLost is returned when renewing or releasing a lock that
+	// expired, and may have been taken by another owner since
+	ErrLockLost = errors.New("lock expired or is held by another owner")
+)
+
+// lockRetryInterval is how often Acquire tries again while the lock is held
+const lockRetryInterval = 100 * time.Millisecond
+
+// Locker hands out short distributed locks kept in Redis. A lock expires
+// after its TTL unless renewed, so a crashed owner cannot hold it forever.
+// Each acquisition gets a fencing token greater than those of the previous
+// owners of the key; storage that is written under the lock can reject
+// writes carrying an older token, from an owner that paused past its TTL.
+type Locker struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewLocker creates a locker keeping its locks under prefix
+func NewLocker(client *redis.Client, prefix string) *Locker {
+	return &Locker{client: client, prefix: prefix}
+}
+
+// Locker returns a locker on the connection of the cache, keeping locks
+// under its key prefix
+func (r *RedisCache) Locker() *Locker {
+	return NewLocker(r.client, r.keyWithPrefix("lock:"))
+}
+
+// Lock is a lock held by this process
+type Lock struct {
+	locker *Locker
+	key    string
+	owner  string
+	token  int64
+}
+
+// acquireLockScript takes the lock if it is free and returns the next
+// fencing token of the key, or 0. The token counter does not expire, so
+// tokens keep increasing across owners.
+var acquireLockScript = redis.NewScript(`
+if redis.call('SET', KEYS[1], ARGV[1], 'NX', 'PX', ARGV[2]) then
+	return redis.call('INCR', KEYS[2])
+end
+return 0
+`)
+
+// renewLockScript extends the lock only if we still own it
+var renewLockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// releaseLockScript deletes the lock only if we still own it
+var releaseLockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// TryAcquire takes the lock on key for ttl, returning ErrLockHeld when
+// another owner holds it
+func (lk *Locker) TryAcquire(ctx context.Context, key string, ttl time.Duration) (*Lock, error) {
+	if ttl <= 0 {
+		return nil, fmt.Errorf("lock TTL must be positive")
+	}
+	owner, err := lockOwner()
+	if err != nil {
+		return nil, err
+	}
+
+	lockKey := lk.prefix + key
+	token, err := acquireLockScript.Run(ctx, lk.client, []string{lockKey, lockKey + ":fence"}, owner, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire lock %s: %w", key, err)
+	}
+	if token == 0 {
+		return nil, ErrLockHeld
+	}
+	return &Lock{locker: lk, key: key, owner: owner, token: token}, nil
+}
+
+// Acquire takes the lock on key for ttl, waiting while another owner holds
+// it until ctx is done
+func (lk *Locker) Acquire(ctx context.Context, key string, ttl time.Duration) (*Lock, error) {
+	ticker := time.NewTicker(lockRetryInterval)
+	defer ticker.Stop()
+
+	for {
+		lock, err := lk.TryAcquire(ctx, key, ttl)
+		if !errors.Is(err, ErrLockHeld) {
+			return lock, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("waiting for lock %s: %w", key, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// Key returns the key the lock was taken on
+func (l *Lock) Key() string {
+	return l.key
+}
+
+// Token returns the fencing token of this acquisition
+func (l *Lock) Token() int64 {
+	return l.token
+}
+
+// Renew extends the lock to expire ttl from now, returning ErrLockLost when
+// it already expired
+func (l *Lock) Renew(ctx context.Context, ttl time.Duration) error {
+	renewed, err := renewLockScript.Run(ctx, l.locker.client, []string{l.locker.prefix + l.key}, l.owner, ttl.Milliseconds()).Int()
+	if err != nil {
+		return fmt.Errorf("failed to renew lock %s: %w", l.key, err)
+	}
+	if renewed == 0 {
+		return ErrLockLost
+	}
+	return nil
+}
+
+// Release frees the lock, returning ErrLockLost when it already expired
+func (l *Lock) Release(ctx context.Context) error {
+	released, err := releaseLockScript.Run(ctx, l.locker.client, []string{l.locker.prefix + l.key}, l.owner).Int()
+	if err != nil {
+		return fmt.Errorf("failed to release lock %s: %w", l.key, err)
+	}
+	if released == 0 {
+		return ErrLockLost
+	}
+	return nil
+}
+
+// lockOwner returns a random value identifying one acquisition of a lock
+func lockOwner() (string, error) {
+	owner := make([]byte, 16)
+	if _, err := rand.Read(owner); err != nil {
+		return "", fmt.Errorf("failed to generate lock owner: %w", err)
+	}
+	return hex.EncodeToString(owner), nil
+}
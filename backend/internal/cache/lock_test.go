@@ -0,0 +1,114 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLocker(t *testing.T) (*Locker, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return NewLocker(client, "toeic:lock:"), server
+}
+
+func TestLockContention(t *testing.T) {
+	locker, server := newTestLocker(t)
+	ctx := context.Background()
+
+	first, err := locker.TryAcquire(ctx, "cache-warming", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, server.Exists("toeic:lock:cache-warming"))
+
+	_, err = locker.TryAcquire(ctx, "cache-warming", time.Minute)
+	assert.ErrorIs(t, err, ErrLockHeld)
+
+	// Other keys are independent
+	other, err := locker.TryAcquire(ctx, "backup", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), other.Token())
+
+	require.NoError(t, first.Release(ctx))
+	second, err := locker.TryAcquire(ctx, "cache-warming", time.Minute)
+	require.NoError(t, err)
+	assert.Greater(t, second.Token(), first.Token())
+
+	// A released lock cannot be released again, nor free its successor
+	assert.ErrorIs(t, first.Release(ctx), ErrLockLost)
+	_, err = locker.TryAcquire(ctx, "cache-warming", time.Minute)
+	assert.ErrorIs(t, err, ErrLockHeld)
+}
+
+func TestLockOnlyOneOfManyWins(t *testing.T) {
+	locker, _ := newTestLocker(t)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	won := 0
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := locker.TryAcquire(context.Background(), "job:daily-digest", time.Minute); err == nil {
+				mu.Lock()
+				won++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1, won)
+}
+
+func TestLockExpiry(t *testing.T) {
+	locker, server := newTestLocker(t)
+	ctx := context.Background()
+
+	first, err := locker.TryAcquire(ctx, "leader", 10*time.Second)
+	require.NoError(t, err)
+
+	// Renewing keeps the lock past its first TTL
+	server.FastForward(8 * time.Second)
+	require.NoError(t, first.Renew(ctx, 10*time.Second))
+	server.FastForward(8 * time.Second)
+	_, err = locker.TryAcquire(ctx, "leader", 10*time.Second)
+	assert.ErrorIs(t, err, ErrLockHeld)
+
+	// Once expired another owner takes it with a newer fencing token
+	server.FastForward(3 * time.Second)
+	second, err := locker.TryAcquire(ctx, "leader", 10*time.Second)
+	require.NoError(t, err)
+	assert.Greater(t, second.Token(), first.Token())
+
+	assert.ErrorIs(t, first.Renew(ctx, 10*time.Second), ErrLockLost)
+	assert.ErrorIs(t, first.Release(ctx), ErrLockLost)
+	require.NoError(t, second.Release(ctx))
+}
+
+func TestAcquireWaits(t *testing.T) {
+	locker, _ := newTestLocker(t)
+	ctx := context.Background()
+
+	held, err := locker.TryAcquire(ctx, "scheduler", time.Minute)
+	require.NoError(t, err)
+
+	timeout, cancel := context.WithTimeout(ctx, 150*time.Millisecond)
+	defer cancel()
+	_, err = locker.Acquire(timeout, "scheduler", time.Minute)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		held.Release(ctx)
+	}()
+	waited, err := locker.Acquire(ctx, "scheduler", time.Minute)
+	require.NoError(t, err)
+	assert.Greater(t, waited.Token(), held.Token())
+}
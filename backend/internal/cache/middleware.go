@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -18,6 +19,7 @@ import (
 type HTTPCacheMiddleware struct {
 	cache  Cache
 	config HTTPCacheConfig
+	ttlMu  sync.RWMutex
 }
 
 // HTTPCacheConfig holds HTTP cache configuration
@@ -82,6 +84,20 @@ func NewHTTPCacheMiddleware(cache Cache, config HTTPCacheConfig) *HTTPCacheMiddl
 	}
 }
 
+// SetDefaultTTL changes the TTL used for newly cached responses
+func (h *HTTPCacheMiddleware) SetDefaultTTL(ttl time.Duration) {
+	h.ttlMu.Lock()
+	defer h.ttlMu.Unlock()
+	h.config.DefaultTTL = ttl
+}
+
+// defaultTTL returns the current TTL for cached responses
+func (h *HTTPCacheMiddleware) defaultTTL() time.Duration {
+	h.ttlMu.RLock()
+	defer h.ttlMu.RUnlock()
+	return h.config.DefaultTTL
+}
+
 // responseWriter wraps gin.ResponseWriter to capture response
 type responseWriter struct {
 	gin.ResponseWriter
@@ -185,7 +201,7 @@ func (h *HTTPCacheMiddleware) Middleware() gin.HandlerFunc {
 					ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 					defer cancel()
 
-					if err := h.cache.Set(ctx, cacheKey, data, h.config.DefaultTTL); err != nil {
+					if err := h.cache.Set(ctx, cacheKey, data, h.defaultTTL()); err != nil {
 						fields := logger.Fields{
 							"component": "http_cache",
 							"cache_key": cacheKey,
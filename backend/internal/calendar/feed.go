@@ -0,0 +1,164 @@
+package calendar
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/toeic-app/internal/studyplan"
+)
+
+// mockTestDuration is the length of a full TOEIC mock test
+const mockTestDuration = 2 * time.Hour
+
+// countdownDays are the days before the exam that get a reminder event
+var countdownDays = []int{30, 14, 7, 3, 1}
+
+// Settings place the study plan on the calendar of a user
+type Settings struct {
+	UserID    int32
+	StudyTime string         // Daily start time as HH:MM
+	Location  *time.Location // Time zone of StudyTime
+	Since     time.Time      // First day of recurring events
+}
+
+// mockTestDays returns the weekdays of mock tests for the weekly count in a plan
+func mockTestDays(perWeek int) []string {
+	switch {
+	case perWeek >= 2:
+		return []string{"WE", "SA"}
+	case perWeek == 1:
+		return []string{"SA"}
+	default:
+		return nil
+	}
+}
+
+// Events lays a study plan out as calendar events: recurring study sessions,
+// recurring mock tests, the exam day and countdown reminders before it
+func Events(plan *studyplan.Plan, settings Settings, now time.Time) ([]Event, error) {
+	hour, minute, err := parseStudyTime(settings.StudyTime)
+	if err != nil {
+		return nil, err
+	}
+	loc := settings.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	since := settings.Since.In(loc)
+	start := time.Date(since.Year(), since.Month(), since.Day(), hour, minute, 0, 0, loc)
+
+	var examDay time.Time
+	examAhead := false
+	if plan.ExamDate != nil {
+		exam := plan.ExamDate.UTC()
+		examDay = time.Date(exam.Year(), exam.Month(), exam.Day(), 0, 0, 0, 0, time.UTC)
+		today := now.In(loc)
+		examAhead = !examDay.Before(time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC))
+	}
+
+	// Recurring events stop the day before the exam, or run on while no exam is planned
+	until := ""
+	if examAhead && examDay.After(start) {
+		until = ";UNTIL=" + examDay.AddDate(0, 0, -1).Format("20060102") + "T235959Z"
+	}
+
+	uid := func(kind string) string {
+		return fmt.Sprintf("%s-%d@toeic-app", kind, settings.UserID)
+	}
+
+	var events []Event
+
+	mockDays := mockTestDays(plan.MockTestsPerWeek)
+	studyRule := "FREQ=DAILY"
+	if len(mockDays) > 0 {
+		studyRule = "FREQ=WEEKLY;BYDAY=" + strings.Join(excludeDays(mockDays), ",")
+	}
+	events = append(events, Event{
+		UID:         uid("study"),
+		Summary:     fmt.Sprintf("TOEIC study (%d min)", plan.DailyMinutes),
+		Description: studyDescription(plan),
+		Start:       start,
+		Duration:    time.Duration(plan.DailyMinutes) * time.Minute,
+		RRule:       studyRule + until,
+	})
+
+	if len(mockDays) > 0 {
+		events = append(events, Event{
+			UID:         uid("mock-test"),
+			Summary:     "TOEIC mock test",
+			Description: "Take a full mock test under exam conditions: 100 listening and 100 reading questions.",
+			Start:       start,
+			Duration:    mockTestDuration,
+			RRule:       "FREQ=WEEKLY;BYDAY=" + strings.Join(mockDays, ",") + until,
+		})
+	}
+
+	if examAhead {
+		events = append(events, Event{
+			UID:         uid("exam"),
+			Summary:     "TOEIC exam",
+			Description: fmt.Sprintf("Exam day. Target score: %d.", plan.TargetScore),
+			Start:       examDay,
+			AllDay:      true,
+		})
+
+		today := now.In(loc)
+		todayDate := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+		for _, days := range countdownDays {
+			day := examDay.AddDate(0, 0, -days)
+			if day.Before(todayDate) {
+				continue
+			}
+			summary := fmt.Sprintf("%d days until the TOEIC exam", days)
+			if days == 1 {
+				summary = "TOEIC exam tomorrow"
+			}
+			events = append(events, Event{
+				UID:     uid(fmt.Sprintf("countdown-%d", days)),
+				Summary: summary,
+				Start:   day,
+				AllDay:  true,
+			})
+		}
+	}
+
+	return events, nil
+}
+
+// excludeDays returns the weekdays not in days, in calendar order
+func excludeDays(days []string) []string {
+	excluded := make(map[string]bool, len(days))
+	for _, day := range days {
+		excluded[day] = true
+	}
+	var rest []string
+	for _, day := range []string{"MO", "TU", "WE", "TH", "FR", "SA", "SU"} {
+		if !excluded[day] {
+			rest = append(rest, day)
+		}
+	}
+	return rest
+}
+
+// studyDescription lists the daily allocation of a plan
+func studyDescription(plan *studyplan.Plan) string {
+	lines := make([]string, 0, len(plan.DailyAllocation)+1)
+	for _, allocation := range plan.DailyAllocation {
+		lines = append(lines, fmt.Sprintf("%s: %d min", allocation.Skill, allocation.Minutes))
+	}
+	if plan.WordsPerDay > 0 {
+		lines = append(lines, fmt.Sprintf("New words: %d", plan.WordsPerDay))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parseStudyTime parses a HH:MM time of day
+func parseStudyTime(value string) (int, int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w: %q", ErrInvalidStudyTime, value)
+	}
+	return t.Hour(), t.Minute(), nil
+}
@@ -0,0 +1,76 @@
+package calendar
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toeic-app/internal/studyplan"
+)
+
+func eventByUID(events []Event, uid string) *Event {
+	for i := range events {
+		if events[i].UID == uid {
+			return &events[i]
+		}
+	}
+	return nil
+}
+
+func TestEventsSchedulesStudyMockTestsAndCountdown(t *testing.T) {
+	now := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	exam := time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC)
+	plan := &studyplan.Plan{
+		TargetScore:      750,
+		ExamDate:         &exam,
+		DailyMinutes:     60,
+		DailyAllocation:  []studyplan.SkillAllocation{{Skill: "vocabulary", Minutes: 20}, {Skill: "listening", Minutes: 40}},
+		WordsPerDay:      10,
+		MockTestsPerWeek: 2,
+	}
+
+	events, err := Events(plan, Settings{UserID: 7, StudyTime: "06:15", Location: time.UTC, Since: now}, now)
+	require.NoError(t, err)
+
+	study := eventByUID(events, "study-7@toeic-app")
+	require.NotNil(t, study)
+	assert.Equal(t, time.Date(2026, 3, 1, 6, 15, 0, 0, time.UTC), study.Start)
+	assert.Equal(t, time.Hour, study.Duration)
+	assert.Equal(t, "FREQ=WEEKLY;BYDAY=MO,TU,TH,FR,SU;UNTIL=20260319T235959Z", study.RRule)
+	assert.Contains(t, study.Description, "listening: 40 min")
+	assert.Contains(t, study.Description, "New words: 10")
+
+	mock := eventByUID(events, "mock-test-7@toeic-app")
+	require.NotNil(t, mock)
+	assert.Equal(t, "FREQ=WEEKLY;BYDAY=WE,SA;UNTIL=20260319T235959Z", mock.RRule)
+
+	examEvent := eventByUID(events, "exam-7@toeic-app")
+	require.NotNil(t, examEvent)
+	assert.True(t, examEvent.AllDay)
+	assert.Equal(t, exam, examEvent.Start)
+
+	// 30 days before the exam has already passed
+	assert.Nil(t, eventByUID(events, "countdown-30-7@toeic-app"))
+	for _, days := range []int{14, 7, 3, 1} {
+		countdown := eventByUID(events, "countdown-"+strconv.Itoa(days)+"-7@toeic-app")
+		require.NotNil(t, countdown, days)
+		assert.Equal(t, exam.AddDate(0, 0, -days), countdown.Start)
+	}
+}
+
+func TestEventsWithoutExamRunOpenEnded(t *testing.T) {
+	now := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	plan := &studyplan.Plan{DailyMinutes: 30}
+
+	events, err := Events(plan, Settings{UserID: 1, StudyTime: "19:00", Since: now}, now)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "FREQ=DAILY", events[0].RRule)
+}
+
+func TestEventsRejectsInvalidStudyTime(t *testing.T) {
+	_, err := Events(&studyplan.Plan{}, Settings{StudyTime: "25:00"}, time.Now())
+	assert.ErrorIs(t, err, ErrInvalidStudyTime)
+}
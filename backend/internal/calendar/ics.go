@@ -0,0 +1,107 @@
+package calendar
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Event is a calendar event in an ICS feed
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Start       time.Time     // For timed events, the wall clock time in Start.Location()
+	AllDay      bool          // Spans the whole day of Start
+	Duration    time.Duration // Length of timed events
+	RRule       string        // Recurrence rule without the RRULE: prefix
+}
+
+// maxLineOctets is the line length limit of RFC 5545 before folding
+const maxLineOctets = 75
+
+// Render writes events as an RFC 5545 calendar
+func Render(name string, events []Event, now time.Time) []byte {
+	var b strings.Builder
+	line := func(content string) {
+		writeFolded(&b, content)
+	}
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//TOEIC App//Study Plan//EN")
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:PUBLISH")
+	line("X-WR-CALNAME:" + escapeText(name))
+	// Ask subscribed clients to refresh twice a day as the plan changes
+	line("REFRESH-INTERVAL;VALUE=DURATION:PT12H")
+	line("X-PUBLISHED-TTL:PT12H")
+
+	stamp := now.UTC().Format("20060102T150405Z")
+	for _, event := range events {
+		line("BEGIN:VEVENT")
+		line("UID:" + event.UID)
+		line("DTSTAMP:" + stamp)
+		if event.AllDay {
+			line("DTSTART;VALUE=DATE:" + event.Start.Format("20060102"))
+			line("DTEND;VALUE=DATE:" + event.Start.AddDate(0, 0, 1).Format("20060102"))
+		} else {
+			line("DTSTART" + formatDateTime(event.Start))
+			line("DURATION:" + formatDuration(event.Duration))
+		}
+		if event.RRule != "" {
+			line("RRULE:" + event.RRule)
+		}
+		line("SUMMARY:" + escapeText(event.Summary))
+		if event.Description != "" {
+			line("DESCRIPTION:" + escapeText(event.Description))
+		}
+		line("TRANSP:TRANSPARENT")
+		line("END:VEVENT")
+	}
+
+	line("END:VCALENDAR")
+	return []byte(b.String())
+}
+
+// formatDateTime formats a timed start with its time zone parameter
+func formatDateTime(t time.Time) string {
+	if t.Location() == time.UTC {
+		return ":" + t.Format("20060102T150405Z")
+	}
+	return ";TZID=" + t.Location().String() + ":" + t.Format("20060102T150405")
+}
+
+// formatDuration formats a duration as an RFC 5545 duration in minutes
+func formatDuration(d time.Duration) string {
+	minutes := int(d.Minutes())
+	if minutes <= 0 {
+		minutes = 1
+	}
+	return "PT" + strconv.Itoa(minutes) + "M"
+}
+
+// escapeText escapes a TEXT property value
+func escapeText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// writeFolded writes a content line, folding it at 75 octets without splitting
+// multi-byte characters
+func writeFolded(b *strings.Builder, content string) {
+	limit := maxLineOctets
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(content[cut]) {
+			cut--
+		}
+		b.WriteString(content[:cut])
+		b.WriteString("\r\n ")
+		content = content[cut:]
+		// Continuation lines start with a space that counts toward the limit
+		limit = maxLineOctets - 1
+	}
+	b.WriteString(content)
+	b.WriteString("\r\n")
+}
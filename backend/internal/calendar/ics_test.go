@@ -0,0 +1,54 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderWritesEvents(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Ho_Chi_Minh")
+	if err != nil {
+		t.Skip("time zone database not available")
+	}
+	now := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+
+	out := string(Render("Plan", []Event{
+		{UID: "a@toeic-app", Summary: "Study; vocab, grammar", Start: time.Date(2026, 3, 2, 19, 30, 0, 0, loc), Duration: 45 * time.Minute, RRule: "FREQ=DAILY"},
+		{UID: "b@toeic-app", Summary: "Exam", Start: time.Date(2026, 4, 5, 0, 0, 0, 0, time.UTC), AllDay: true},
+	}, now))
+
+	assert.True(t, strings.HasPrefix(out, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(out, "END:VCALENDAR\r\n"))
+	assert.Contains(t, out, "DTSTAMP:20260301T080000Z\r\n")
+	assert.Contains(t, out, "DTSTART;TZID=Asia/Ho_Chi_Minh:20260302T193000\r\n")
+	assert.Contains(t, out, "DURATION:PT45M\r\n")
+	assert.Contains(t, out, "RRULE:FREQ=DAILY\r\n")
+	assert.Contains(t, out, `SUMMARY:Study\; vocab\, grammar`+"\r\n")
+	assert.Contains(t, out, "DTSTART;VALUE=DATE:20260405\r\nDTEND;VALUE=DATE:20260406\r\n")
+	assert.Equal(t, 2, strings.Count(out, "BEGIN:VEVENT"))
+}
+
+func TestWriteFoldedKeepsLinesShortAndRunesWhole(t *testing.T) {
+	var b strings.Builder
+	content := "DESCRIPTION:" + strings.Repeat("từ vựng ", 30)
+	writeFolded(&b, content)
+
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\r\n"), "\r\n")
+	assert.Greater(t, len(lines), 1)
+	unfolded := lines[0]
+	for i, line := range lines {
+		assert.LessOrEqual(t, len(line), maxLineOctets)
+		if i > 0 {
+			assert.True(t, strings.HasPrefix(line, " "))
+			unfolded += line[1:]
+		}
+	}
+	assert.Equal(t, content, unfolded)
+}
+
+func TestEscapeText(t *testing.T) {
+	assert.Equal(t, `a\\b\;c\,d\ne`, escapeText("a\\b;c,d\ne"))
+}
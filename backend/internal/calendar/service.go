@@ -0,0 +1,178 @@
+package calendar
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/logger"
+	"github.com/toeic-app/internal/studyplan"
+)
+
+var (
+	ErrFeedNotFound     = errors.New("calendar feed not found")
+	ErrInvalidToken     = errors.New("invalid calendar feed token")
+	ErrInvalidStudyTime = errors.New("study time must be HH:MM")
+	ErrInvalidTimezone  = errors.New("unknown time zone")
+)
+
+// Defaults of a new feed
+const (
+	DefaultStudyTime = "19:00"
+	DefaultTimezone  = "UTC"
+)
+
+// PlanGenerator generates the study plan of a user
+type PlanGenerator interface {
+	Generate(ctx context.Context, userID int32) (*studyplan.Plan, error)
+}
+
+// Feed is the calendar subscription of a user
+type Feed struct {
+	Token          string     `json:"-"`
+	StudyTime      string     `json:"study_time"`
+	Timezone       string     `json:"timezone"`
+	CreatedAt      time.Time  `json:"created_at"`
+	LastAccessedAt *time.Time `json:"last_accessed_at,omitempty"`
+}
+
+// Service manages signed per-user ICS feeds of the study plan
+type Service struct {
+	store  db.Querier
+	plans  PlanGenerator
+	signer *Signer
+}
+
+// NewService creates a new calendar feed service signing tokens with secret
+func NewService(store db.Querier, plans PlanGenerator, secret string) *Service {
+	return &Service{
+		store:  store,
+		plans:  plans,
+		signer: NewSigner(secret),
+	}
+}
+
+// Get returns the feed of a user
+func (s *Service) Get(ctx context.Context, userID int32) (*Feed, error) {
+	record, err := s.store.GetCalendarFeed(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrFeedNotFound
+		}
+		return nil, fmt.Errorf("failed to get calendar feed: %w", err)
+	}
+	return s.newFeed(record), nil
+}
+
+// Configure creates the feed of a user or updates its study time and time zone.
+// Empty values fall back to the defaults.
+func (s *Service) Configure(ctx context.Context, userID int32, studyTime, timezone string) (*Feed, error) {
+	if studyTime == "" {
+		studyTime = DefaultStudyTime
+	}
+	if timezone == "" {
+		timezone = DefaultTimezone
+	}
+	if _, _, err := parseStudyTime(studyTime); err != nil {
+		return nil, err
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidTimezone, timezone)
+	}
+
+	record, err := s.store.UpsertCalendarFeed(ctx, db.UpsertCalendarFeedParams{
+		UserID:    userID,
+		StudyTime: studyTime,
+		Timezone:  timezone,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save calendar feed: %w", err)
+	}
+	return s.newFeed(record), nil
+}
+
+// Rotate issues a new feed token, invalidating the previous URL
+func (s *Service) Rotate(ctx context.Context, userID int32) (*Feed, error) {
+	record, err := s.store.RotateCalendarFeed(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrFeedNotFound
+		}
+		return nil, fmt.Errorf("failed to rotate calendar feed: %w", err)
+	}
+	return s.newFeed(record), nil
+}
+
+// Revoke deletes the feed of a user so its URL stops working
+func (s *Service) Revoke(ctx context.Context, userID int32) error {
+	rows, err := s.store.DeleteCalendarFeed(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete calendar feed: %w", err)
+	}
+	if rows == 0 {
+		return ErrFeedNotFound
+	}
+	return nil
+}
+
+// Render returns the ICS calendar of the feed a token belongs to
+func (s *Service) Render(ctx context.Context, token string) ([]byte, error) {
+	userID, version, err := s.signer.Parse(token)
+	if err != nil {
+		return nil, err
+	}
+
+	record, err := s.store.GetCalendarFeed(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidToken
+		}
+		return nil, fmt.Errorf("failed to get calendar feed: %w", err)
+	}
+	if record.Version != version || !s.signer.Verify(token, userID, version, record.CreatedAt) {
+		return nil, ErrInvalidToken
+	}
+
+	plan, err := s.plans.Generate(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate study plan: %w", err)
+	}
+
+	loc, err := time.LoadLocation(record.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	now := time.Now()
+	events, err := Events(plan, Settings{
+		UserID:    userID,
+		StudyTime: record.StudyTime,
+		Location:  loc,
+		Since:     record.CreatedAt,
+	}, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.store.TouchCalendarFeed(ctx, userID); err != nil {
+		logger.Debug("Failed to record access of calendar feed of user %d: %v", userID, err)
+	}
+
+	return Render("TOEIC study plan", events, now), nil
+}
+
+func (s *Service) newFeed(record db.CalendarFeed) *Feed {
+	feed := &Feed{
+		Token:     s.signer.Token(record.UserID, record.Version, record.CreatedAt),
+		StudyTime: record.StudyTime,
+		Timezone:  record.Timezone,
+		CreatedAt: record.CreatedAt,
+	}
+	if record.LastAccessedAt.Valid {
+		lastAccessed := record.LastAccessedAt.Time
+		feed.LastAccessedAt = &lastAccessed
+	}
+	return feed
+}
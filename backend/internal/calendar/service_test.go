@@ -0,0 +1,145 @@
+package calendar
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/studyplan"
+)
+
+// feedStore keeps feeds in memory; other Querier methods are not used
+type feedStore struct {
+	db.Querier
+	feeds   map[int32]db.CalendarFeed
+	created time.Time
+}
+
+func newFeedStore() *feedStore {
+	return &feedStore{feeds: make(map[int32]db.CalendarFeed), created: time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)}
+}
+
+func (s *feedStore) GetCalendarFeed(ctx context.Context, userID int32) (db.CalendarFeed, error) {
+	feed, ok := s.feeds[userID]
+	if !ok {
+		return db.CalendarFeed{}, sql.ErrNoRows
+	}
+	return feed, nil
+}
+
+func (s *feedStore) UpsertCalendarFeed(ctx context.Context, arg db.UpsertCalendarFeedParams) (db.CalendarFeed, error) {
+	feed, ok := s.feeds[arg.UserID]
+	if !ok {
+		// Each new feed gets a distinct creation time, as in the database
+		s.created = s.created.Add(time.Second)
+		feed = db.CalendarFeed{UserID: arg.UserID, Version: 1, CreatedAt: s.created}
+	}
+	feed.StudyTime = arg.StudyTime
+	feed.Timezone = arg.Timezone
+	s.feeds[arg.UserID] = feed
+	return feed, nil
+}
+
+func (s *feedStore) RotateCalendarFeed(ctx context.Context, userID int32) (db.CalendarFeed, error) {
+	feed, ok := s.feeds[userID]
+	if !ok {
+		return db.CalendarFeed{}, sql.ErrNoRows
+	}
+	feed.Version++
+	s.feeds[userID] = feed
+	return feed, nil
+}
+
+func (s *feedStore) DeleteCalendarFeed(ctx context.Context, userID int32) (int64, error) {
+	if _, ok := s.feeds[userID]; !ok {
+		return 0, nil
+	}
+	delete(s.feeds, userID)
+	return 1, nil
+}
+
+func (s *feedStore) TouchCalendarFeed(ctx context.Context, userID int32) error {
+	feed := s.feeds[userID]
+	feed.LastAccessedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	s.feeds[userID] = feed
+	return nil
+}
+
+type fixedPlans struct{}
+
+func (fixedPlans) Generate(ctx context.Context, userID int32) (*studyplan.Plan, error) {
+	return &studyplan.Plan{DailyMinutes: 30, MockTestsPerWeek: 1}, nil
+}
+
+func TestConfigureValidatesAndDefaults(t *testing.T) {
+	service := NewService(newFeedStore(), fixedPlans{}, "secret")
+	ctx := context.Background()
+
+	feed, err := service.Configure(ctx, 1, "", "")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultStudyTime, feed.StudyTime)
+	assert.Equal(t, DefaultTimezone, feed.Timezone)
+	assert.True(t, strings.HasPrefix(feed.Token, "1.1."))
+
+	_, err = service.Configure(ctx, 1, "7pm", "UTC")
+	assert.ErrorIs(t, err, ErrInvalidStudyTime)
+	_, err = service.Configure(ctx, 1, "07:00", "Mars/Olympus")
+	assert.ErrorIs(t, err, ErrInvalidTimezone)
+}
+
+func TestRenderChecksToken(t *testing.T) {
+	store := newFeedStore()
+	service := NewService(store, fixedPlans{}, "secret")
+	ctx := context.Background()
+
+	feed, err := service.Configure(ctx, 3, "07:00", "UTC")
+	require.NoError(t, err)
+
+	out, err := service.Render(ctx, feed.Token)
+	require.NoError(t, err)
+	assert.Contains(t, string(out), "UID:study-3@toeic-app")
+	assert.True(t, store.feeds[3].LastAccessedAt.Valid)
+
+	// Tampered, foreign-key and malformed tokens are rejected
+	_, err = service.Render(ctx, feed.Token[:len(feed.Token)-1]+"0")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	_, err = NewService(store, fixedPlans{}, "other").Render(ctx, feed.Token)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	_, err = service.Render(ctx, "not-a-token")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestRotateAndRevokeInvalidateOldTokens(t *testing.T) {
+	service := NewService(newFeedStore(), fixedPlans{}, "secret")
+	ctx := context.Background()
+
+	first, err := service.Configure(ctx, 5, "07:00", "UTC")
+	require.NoError(t, err)
+
+	rotated, err := service.Rotate(ctx, 5)
+	require.NoError(t, err)
+	assert.NotEqual(t, first.Token, rotated.Token)
+	_, err = service.Render(ctx, first.Token)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	_, err = service.Render(ctx, rotated.Token)
+	assert.NoError(t, err)
+
+	require.NoError(t, service.Revoke(ctx, 5))
+	_, err = service.Render(ctx, rotated.Token)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	assert.ErrorIs(t, service.Revoke(ctx, 5), ErrFeedNotFound)
+	_, err = service.Rotate(ctx, 5)
+	assert.ErrorIs(t, err, ErrFeedNotFound)
+
+	// A re-created feed starts at version 1 again but earlier URLs stay dead
+	recreated, err := service.Configure(ctx, 5, "07:00", "UTC")
+	require.NoError(t, err)
+	assert.NotEqual(t, first.Token, recreated.Token)
+	_, err = service.Render(ctx, first.Token)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
@@ -0,0 +1,61 @@
+package calendar
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// signatureBytes is the length of the truncated signature in a feed token
+const signatureBytes = 16
+
+// Signer signs feed tokens. A token names the user and feed version and is
+// signed together with the feed creation time, so rotating or deleting and
+// re-creating a feed invalidates every earlier URL.
+type Signer struct {
+	key []byte
+}
+
+// NewSigner derives a feed signing key from the application secret
+func NewSigner(secret string) *Signer {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("calendar-feed"))
+	return &Signer{key: mac.Sum(nil)}
+}
+
+// Token returns the feed token of a feed version
+func (s *Signer) Token(userID, version int32, createdAt time.Time) string {
+	return fmt.Sprintf("%d.%d.%s", userID, version, s.signature(userID, version, createdAt))
+}
+
+// Parse reads the user and version of a token without verifying it
+func (s *Signer) Parse(token string) (userID, version int32, err error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return 0, 0, ErrInvalidToken
+	}
+	user, err := strconv.ParseInt(parts[0], 10, 32)
+	if err != nil || user <= 0 {
+		return 0, 0, ErrInvalidToken
+	}
+	ver, err := strconv.ParseInt(parts[1], 10, 32)
+	if err != nil || ver <= 0 {
+		return 0, 0, ErrInvalidToken
+	}
+	return int32(user), int32(ver), nil
+}
+
+// Verify reports whether a token was signed for the feed version
+func (s *Signer) Verify(token string, userID, version int32, createdAt time.Time) bool {
+	return hmac.Equal([]byte(token), []byte(s.Token(userID, version, createdAt)))
+}
+
+func (s *Signer) signature(userID, version int32, createdAt time.Time) string {
+	mac := hmac.New(sha256.New, s.key)
+	fmt.Fprintf(mac, "%d:%d:%d", userID, version, createdAt.UnixMicro())
+	return hex.EncodeToString(mac.Sum(nil)[:signatureBytes])
+}
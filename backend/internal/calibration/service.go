@@ -0,0 +1,255 @@
+// Package calibration recomputes the empirical difficulty of questions from
+// how often learners answer them correctly. Calibrated difficulty uses the
+// 1-6 scale of authored difficulty and is stored next to it; question
+// selectors prefer it once a question has enough answers.
+package calibration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/logger"
+)
+
+var ErrRunInProgress = errors.New("question difficulty is already being calibrated")
+
+// upsertBatchSize is how many calibrations are written per statement
+const upsertBatchSize = 1000
+
+// levels are the lowest correct rates of difficulty 1 to 5; questions
+// answered correctly less often than the last one are difficulty 6. A
+// learner guessing one of four options is right a quarter of the time.
+var levels = []float64{0.85, 0.70, 0.55, 0.40, 0.25}
+
+// Options configure calibration
+type Options struct {
+	// MinResponses is how many learners must have answered a question
+	// before it is calibrated
+	MinResponses int32
+}
+
+// Stats describes a calibration run
+type Stats struct {
+	Questions  int           `json:"questions"`
+	Removed    int64         `json:"removed"`
+	Duration   time.Duration `json:"duration" swaggertype:"integer"`
+	ComputedAt time.Time     `json:"computed_at"`
+}
+
+// Summary describes the stored calibrations
+type Summary struct {
+	Questions    int64      `json:"questions"`
+	MinResponses int32      `json:"min_responses"`
+	ComputedAt   *time.Time `json:"computed_at,omitempty"`
+}
+
+// Question is the calibrated and authored difficulty of a question
+type Question struct {
+	QuestionID           int32     `json:"question_id"`
+	Title                string    `json:"title"`
+	AuthoredDifficulty   *int16    `json:"authored_difficulty,omitempty"`
+	CalibratedDifficulty int16     `json:"calibrated_difficulty"`
+	CorrectRate          float32   `json:"correct_rate"`
+	Responses            int32     `json:"responses"`
+	ComputedAt           time.Time `json:"computed_at"`
+}
+
+// Service calibrates question difficulty
+type Service struct {
+	store       db.Querier
+	options     Options
+	now         func() time.Time
+	calibrating atomic.Bool
+
+	mutex     sync.Mutex
+	isRunning bool
+	stopChan  chan struct{}
+	wg        sync.WaitGroup
+}
+
+// NewService creates a calibration service
+func NewService(store db.Querier, options Options) *Service {
+	return &Service{store: store, options: options, now: time.Now}
+}
+
+// Difficulty maps the share of correct answers to the 1-6 difficulty scale
+func Difficulty(correctRate float64) int16 {
+	for i, lowest := range levels {
+		if correctRate >= lowest {
+			return int16(i + 1)
+		}
+	}
+	return int16(len(levels) + 1)
+}
+
+// Summary reports how many questions are calibrated and when
+func (s *Service) Summary(ctx context.Context) (Summary, error) {
+	row, err := s.store.GetQuestionCalibrationSummary(ctx)
+	if err != nil {
+		return Summary{}, fmt.Errorf("failed to summarize calibrations: %w", err)
+	}
+	summary := Summary{Questions: row.Questions, MinResponses: s.options.MinResponses}
+	if row.ComputedAt.Valid {
+		summary.ComputedAt = &row.ComputedAt.Time
+	}
+	return summary, nil
+}
+
+// Questions lists calibrated questions whose calibrated difficulty is at
+// least minDrift levels away from the authored one, biggest difference
+// first, with the total count
+func (s *Service) Questions(ctx context.Context, minDrift, limit, offset int32) ([]Question, int64, error) {
+	rows, err := s.store.ListQuestionCalibrations(ctx, db.ListQuestionCalibrationsParams{MinDrift: minDrift, Limit: limit, Offset: offset})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list calibrations: %w", err)
+	}
+	total, err := s.store.CountQuestionCalibrations(ctx, minDrift)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count calibrations: %w", err)
+	}
+
+	questions := make([]Question, 0, len(rows))
+	for _, row := range rows {
+		question := Question{
+			QuestionID:           row.QuestionID,
+			Title:                row.Title,
+			CalibratedDifficulty: row.CalibratedDifficulty,
+			CorrectRate:          row.CorrectRate,
+			Responses:            row.Responses,
+			ComputedAt:           row.ComputedAt,
+		}
+		if row.AuthoredDifficulty.Valid {
+			question.AuthoredDifficulty = &row.AuthoredDifficulty.Int16
+		}
+		questions = append(questions, question)
+	}
+	return questions, total, nil
+}
+
+// Calibrate recomputes the difficulty of every question with enough
+// answers. Questions that no longer have enough answers lose their
+// calibration and fall back to the authored difficulty.
+func (s *Service) Calibrate(ctx context.Context) (Stats, error) {
+	if !s.calibrating.CompareAndSwap(false, true) {
+		return Stats{}, ErrRunInProgress
+	}
+	defer s.calibrating.Store(false)
+
+	started := s.now()
+	// Postgres keeps microseconds; the sweep compares against the stored value
+	computedAt := started.UTC().Truncate(time.Microsecond)
+
+	rows, err := s.store.ListQuestionAnswerStats(ctx, s.options.MinResponses)
+	if err != nil {
+		return Stats{}, fmt.Errorf("failed to load answer statistics: %w", err)
+	}
+	for start := 0; start < len(rows); start += upsertBatchSize {
+		batch := rows[start:min(start+upsertBatchSize, len(rows))]
+		arg := db.UpsertQuestionCalibrationsParams{ComputedAt: computedAt}
+		for _, row := range batch {
+			rate := float64(row.Correct) / float64(row.Responses)
+			arg.QuestionIds = append(arg.QuestionIds, row.QuestionID)
+			arg.Difficulties = append(arg.Difficulties, Difficulty(rate))
+			arg.CorrectRates = append(arg.CorrectRates, float32(rate))
+			arg.Responses = append(arg.Responses, row.Responses)
+		}
+		if err := s.store.UpsertQuestionCalibrations(ctx, arg); err != nil {
+			return Stats{}, fmt.Errorf("failed to store calibrations: %w", err)
+		}
+	}
+
+	removed, err := s.store.DeleteStaleQuestionCalibrations(ctx, computedAt)
+	if err != nil {
+		return Stats{}, fmt.Errorf("failed to remove stale calibrations: %w", err)
+	}
+
+	stats := Stats{
+		Questions:  len(rows),
+		Removed:    removed,
+		Duration:   s.now().Sub(started),
+		ComputedAt: computedAt,
+	}
+	logger.Info("Question difficulty calibrated: %d questions, %d removed in %v", stats.Questions, stats.Removed, stats.Duration)
+	return stats, nil
+}
+
+// Start calibrates periodically until Stop is called. Stale or missing
+// calibrations are recomputed right away.
+func (s *Service) Start(interval time.Duration) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.isRunning {
+		return fmt.Errorf("difficulty calibrator is already running")
+	}
+	s.isRunning = true
+	s.stopChan = make(chan struct{})
+	s.wg.Add(1)
+	go s.run(interval)
+
+	logger.Info("Difficulty calibrator started with interval: %v", interval)
+	return nil
+}
+
+// Stop stops the periodic calibration
+func (s *Service) Stop() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.isRunning {
+		return fmt.Errorf("difficulty calibrator is not running")
+	}
+	close(s.stopChan)
+	s.wg.Wait()
+	s.isRunning = false
+
+	logger.Info("Difficulty calibrator stopped")
+	return nil
+}
+
+// IsRunning returns whether the calibrator is running
+func (s *Service) IsRunning() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.isRunning
+}
+
+func (s *Service) run(interval time.Duration) {
+	defer s.wg.Done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-s.stopChan:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	if summary, err := s.Summary(ctx); err == nil && (summary.ComputedAt == nil || s.now().Sub(*summary.ComputedAt) >= interval) {
+		s.calibrate(ctx)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.calibrate(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *Service) calibrate(ctx context.Context) {
+	if _, err := s.Calibrate(ctx); err != nil && !errors.Is(err, ErrRunInProgress) && ctx.Err() == nil {
+		logger.Error("Failed to calibrate question difficulty: %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package calibration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+// fakeStore keeps calibrations in memory
+type fakeStore struct {
+	db.Querier
+	stats        []db.ListQuestionAnswerStatsRow
+	calibrations map[int32]db.QuestionCalibration
+	minResponses int32
+}
+
+func (s *fakeStore) ListQuestionAnswerStats(_ context.Context, minResponses int32) ([]db.ListQuestionAnswerStatsRow, error) {
+	s.minResponses = minResponses
+	var rows []db.ListQuestionAnswerStatsRow
+	for _, row := range s.stats {
+		if row.Responses >= minResponses {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+func (s *fakeStore) UpsertQuestionCalibrations(_ context.Context, arg db.UpsertQuestionCalibrationsParams) error {
+	for i, id := range arg.QuestionIds {
+		s.calibrations[id] = db.QuestionCalibration{
+			QuestionID:           id,
+			CalibratedDifficulty: arg.Difficulties[i],
+			CorrectRate:          arg.CorrectRates[i],
+			Responses:            arg.Responses[i],
+			ComputedAt:           arg.ComputedAt,
+		}
+	}
+	return nil
+}
+
+func (s *fakeStore) DeleteStaleQuestionCalibrations(_ context.Context, computedAt time.Time) (int64, error) {
+	var removed int64
+	for id, calibration := range s.calibrations {
+		if calibration.ComputedAt.Before(computedAt) {
+			delete(s.calibrations, id)
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+func TestDifficulty(t *testing.T) {
+	cases := map[float64]int16{1: 1, 0.85: 1, 0.8: 2, 0.6: 3, 0.5: 4, 0.3: 5, 0.25: 5, 0.2: 6, 0: 6}
+	for rate, difficulty := range cases {
+		assert.Equal(t, difficulty, Difficulty(rate), "%v", rate)
+	}
+}
+
+func TestCalibrate(t *testing.T) {
+	earlier := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	store := &fakeStore{
+		stats: []db.ListQuestionAnswerStatsRow{
+			{QuestionID: 1, Responses: 40, Correct: 36},
+			{QuestionID: 2, Responses: 30, Correct: 6},
+			{QuestionID: 3, Responses: 5, Correct: 5},
+		},
+		calibrations: map[int32]db.QuestionCalibration{
+			2: {QuestionID: 2, CalibratedDifficulty: 3, ComputedAt: earlier},
+			3: {QuestionID: 3, CalibratedDifficulty: 1, ComputedAt: earlier},
+		},
+	}
+	service := NewService(store, Options{MinResponses: 30})
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	stats, err := service.Calibrate(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int32(30), store.minResponses)
+	assert.Equal(t, 2, stats.Questions)
+	// Question 3 no longer has enough answers
+	assert.Equal(t, int64(1), stats.Removed)
+
+	require.Len(t, store.calibrations, 2)
+	assert.Equal(t, int16(1), store.calibrations[1].CalibratedDifficulty)
+	assert.InDelta(t, 0.9, store.calibrations[1].CorrectRate, 0.0001)
+	assert.Equal(t, int16(6), store.calibrations[2].CalibratedDifficulty)
+	assert.Equal(t, now, store.calibrations[2].ComputedAt)
+}
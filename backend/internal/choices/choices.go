@@ -0,0 +1,215 @@
+// Package choices holds the answer options of questions. Options are keyed
+// A, B, C... and answers refer to an option by its key, so options can be
+// reordered or translated without changing which one an answer picked.
+//
+// Older clients send and read options as plain texts and answers as the
+// text of the option. The texts stay mirrored in the possible_answers,
+// true_answer and selected_answer columns, and the helpers here accept
+// either form.
+package choices
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+var (
+	ErrNoOptions      = errors.New("at least one answer option is required")
+	ErrTooManyOptions = errors.New("too many answer options")
+	ErrInvalidKey     = errors.New("option keys must be a single letter from A to Z")
+	ErrDuplicateKey   = errors.New("option keys must be unique")
+	ErrEmptyText      = errors.New("option text cannot be empty")
+	ErrNoAnswer       = errors.New("the correct option is required")
+	ErrUnknownOption  = errors.New("answer is not one of the question's options")
+)
+
+// keys are the keys options can take, in the order they are handed out
+const keys = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// Option is an answer option of a question
+type Option struct {
+	Key  string `json:"key" binding:"required" example:"A"`
+	Text string `json:"text" binding:"required" sanitize:"plain" example:"At the train station"`
+}
+
+// Validate checks that options are keyed with distinct letters and have
+// texts
+func Validate(options []Option) error {
+	if len(options) == 0 {
+		return ErrNoOptions
+	}
+	if len(options) > len(keys) {
+		return ErrTooManyOptions
+	}
+	seen := make(map[string]bool, len(options))
+	for _, option := range options {
+		if len(option.Key) != 1 || !strings.Contains(keys, option.Key) {
+			return fmt.Errorf("%w: %q", ErrInvalidKey, option.Key)
+		}
+		if seen[option.Key] {
+			return fmt.Errorf("%w: %q", ErrDuplicateKey, option.Key)
+		}
+		seen[option.Key] = true
+		if strings.TrimSpace(option.Text) == "" {
+			return fmt.Errorf("%w: option %s", ErrEmptyText, option.Key)
+		}
+	}
+	return nil
+}
+
+// Find returns the option with a key
+func Find(options []Option, key string) (Option, bool) {
+	for _, option := range options {
+		if option.Key == key {
+			return option, true
+		}
+	}
+	return Option{}, false
+}
+
+// Texts returns the texts of options in order, as older clients read them
+func Texts(options []Option) []string {
+	texts := make([]string, len(options))
+	for i, option := range options {
+		texts[i] = option.Text
+	}
+	return texts
+}
+
+// Rekey keys option texts sent by older clients. A text that is already an
+// option keeps its key, so reordering the texts does not change what past
+// answers picked; new texts get the first free keys.
+func Rekey(current []Option, texts []string) []Option {
+	taken := make(map[string]bool, len(texts))
+	options := make([]Option, len(texts))
+	for i, text := range texts {
+		for _, option := range current {
+			if option.Text == text && !taken[option.Key] {
+				options[i] = option
+				taken[option.Key] = true
+				break
+			}
+		}
+	}
+	next := 0
+	for i, text := range texts {
+		if options[i].Key != "" {
+			continue
+		}
+		for next < len(keys) && taken[keys[next:next+1]] {
+			next++
+		}
+		if next == len(keys) {
+			// Caught by Validate
+			options[i] = Option{Text: text}
+			continue
+		}
+		options[i] = Option{Key: keys[next : next+1], Text: text}
+		taken[options[i].Key] = true
+	}
+	return options
+}
+
+// Resolve returns the option an answer picked, given by key or, by older
+// clients, by the option's text. The key wins when both are given.
+func Resolve(options []Option, key, text string) (Option, error) {
+	if key != "" {
+		if option, ok := Find(options, key); ok {
+			return option, nil
+		}
+		return Option{}, fmt.Errorf("%w: %q", ErrUnknownOption, key)
+	}
+	for _, option := range options {
+		if option.Text == text {
+			return option, nil
+		}
+	}
+	return Option{}, ErrUnknownOption
+}
+
+// Encode returns options as stored in the options column
+func Encode(options []Option) json.RawMessage {
+	if options == nil {
+		options = []Option{}
+	}
+	data, _ := json.Marshal(options) // Options always marshal
+	return data
+}
+
+// Decode reads options as stored in the options column
+func Decode(data json.RawMessage) ([]Option, error) {
+	var options []Option
+	if len(data) == 0 {
+		return options, nil
+	}
+	if err := json.Unmarshal(data, &options); err != nil {
+		return nil, fmt.Errorf("invalid options: %w", err)
+	}
+	return options, nil
+}
+
+// Of returns the options of a question. Questions whose options column was
+// never filled are keyed from their possible answers.
+func Of(question db.Question) []Option {
+	options, err := Decode(question.Options)
+	if err != nil || len(options) == 0 {
+		return Rekey(nil, question.PossibleAnswers)
+	}
+	return options
+}
+
+// AnswerOf returns the key of the correct option of a question. Questions
+// whose answer key was never filled are matched by their true answer.
+func AnswerOf(question db.Question) string {
+	if question.AnswerKey != "" {
+		return question.AnswerKey
+	}
+	if option, err := Resolve(Of(question), "", question.TrueAnswer); err == nil {
+		return option.Key
+	}
+	return ""
+}
+
+// Change edits the options and correct answer of a question. Options come
+// keyed or, from older clients, as texts; the correct answer as a key or as
+// the text of an option. What is left out is kept.
+type Change struct {
+	Options    []Option
+	Texts      []string
+	AnswerKey  string
+	TrueAnswer string
+}
+
+// Apply returns the options and answer key of a question after the change.
+// Without a new correct answer the key is kept, which fails if its option
+// was removed.
+func (c Change) Apply(options []Option, answerKey string) ([]Option, string, error) {
+	switch {
+	case c.Options != nil:
+		options = c.Options
+	case c.Texts != nil:
+		options = Rekey(options, c.Texts)
+	}
+	if err := Validate(options); err != nil {
+		return nil, "", err
+	}
+
+	if c.AnswerKey != "" || c.TrueAnswer != "" {
+		answer, err := Resolve(options, c.AnswerKey, c.TrueAnswer)
+		if err != nil {
+			return nil, "", err
+		}
+		answerKey = answer.Key
+	}
+	if answerKey == "" {
+		return nil, "", ErrNoAnswer
+	}
+	if _, ok := Find(options, answerKey); !ok {
+		return nil, "", fmt.Errorf("%w: the correct option %q was removed", ErrUnknownOption, answerKey)
+	}
+	return options, answerKey, nil
+}
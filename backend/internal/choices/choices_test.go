@@ -0,0 +1,84 @@
+package choices
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+func TestRekeyKeepsKeysOfKnownTexts(t *testing.T) {
+	current := []Option{{Key: "A", Text: "on Monday"}, {Key: "B", Text: "on Tuesday"}, {Key: "C", Text: "on Friday"}}
+
+	options := Rekey(current, []string{"on Friday", "next week", "on Monday"})
+	assert.Equal(t, []Option{{Key: "C", Text: "on Friday"}, {Key: "B", Text: "next week"}, {Key: "A", Text: "on Monday"}}, options)
+
+	assert.Equal(t, []Option{{Key: "A", Text: "yes"}, {Key: "B", Text: "no"}}, Rekey(nil, []string{"yes", "no"}))
+}
+
+func TestValidate(t *testing.T) {
+	require.NoError(t, Validate([]Option{{Key: "A", Text: "yes"}, {Key: "B", Text: "no"}}))
+
+	assert.ErrorIs(t, Validate(nil), ErrNoOptions)
+	assert.ErrorIs(t, Validate([]Option{{Key: "a", Text: "yes"}}), ErrInvalidKey)
+	assert.ErrorIs(t, Validate([]Option{{Key: "AB", Text: "yes"}}), ErrInvalidKey)
+	assert.ErrorIs(t, Validate([]Option{{Key: "A", Text: "yes"}, {Key: "A", Text: "no"}}), ErrDuplicateKey)
+	assert.ErrorIs(t, Validate([]Option{{Key: "A", Text: " "}}), ErrEmptyText)
+}
+
+func TestResolve(t *testing.T) {
+	options := []Option{{Key: "A", Text: "yes"}, {Key: "B", Text: "no"}}
+
+	option, err := Resolve(options, "B", "")
+	require.NoError(t, err)
+	assert.Equal(t, "no", option.Text)
+
+	// Older clients answer with the text
+	option, err = Resolve(options, "", "yes")
+	require.NoError(t, err)
+	assert.Equal(t, "A", option.Key)
+
+	_, err = Resolve(options, "C", "yes")
+	assert.ErrorIs(t, err, ErrUnknownOption)
+	_, err = Resolve(options, "", "maybe")
+	assert.ErrorIs(t, err, ErrUnknownOption)
+}
+
+func TestQuestionWithoutKeyedOptions(t *testing.T) {
+	question := db.Question{PossibleAnswers: []string{"yes", "no"}, TrueAnswer: "no", Options: []byte("[]")}
+	assert.Equal(t, []Option{{Key: "A", Text: "yes"}, {Key: "B", Text: "no"}}, Of(question))
+	assert.Equal(t, "B", AnswerOf(question))
+
+	question.Options = Encode([]Option{{Key: "B", Text: "no"}, {Key: "A", Text: "yes"}})
+	question.AnswerKey = "B"
+	assert.Equal(t, []Option{{Key: "B", Text: "no"}, {Key: "A", Text: "yes"}}, Of(question))
+	assert.Equal(t, "B", AnswerOf(question))
+}
+
+func TestChangeApply(t *testing.T) {
+	current := []Option{{Key: "A", Text: "yes"}, {Key: "B", Text: "no"}}
+
+	// A new answer is resolved by key or text
+	options, key, err := Change{AnswerKey: "A"}.Apply(current, "B")
+	require.NoError(t, err)
+	assert.Equal(t, current, options)
+	assert.Equal(t, "A", key)
+	_, key, err = Change{TrueAnswer: "no"}.Apply(current, "A")
+	require.NoError(t, err)
+	assert.Equal(t, "B", key)
+
+	// Reordered texts keep the answer on the same option
+	options, key, err = Change{Texts: []string{"no", "yes", "maybe"}}.Apply(current, "B")
+	require.NoError(t, err)
+	assert.Equal(t, []Option{{Key: "B", Text: "no"}, {Key: "A", Text: "yes"}, {Key: "C", Text: "maybe"}}, options)
+	assert.Equal(t, "B", key)
+
+	// Removing the correct option needs a new answer
+	_, _, err = Change{Texts: []string{"yes"}}.Apply(current, "B")
+	assert.ErrorIs(t, err, ErrUnknownOption)
+	_, _, err = Change{Options: []Option{{Key: "A", Text: "yes"}}}.Apply(nil, "")
+	assert.ErrorIs(t, err, ErrNoAnswer)
+	_, _, err = Change{Options: []Option{{Key: "A", Text: "yes"}, {Key: "A", Text: "no"}}, AnswerKey: "A"}.Apply(nil, "")
+	assert.ErrorIs(t, err, ErrDuplicateKey)
+}
@@ -3,7 +3,10 @@ package config
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -20,28 +23,91 @@ type BackupConfig struct {
 	BackupInterval    time.Duration `json:"backup_interval"`
 	BackupTime        string        `json:"backup_time"` // Format: "15:04" for daily backup at specific time
 
-	// Retention settings
-	RetentionDays   int  `json:"retention_days"`
-	MaxBackupCount  int  `json:"max_backup_count"`
-	CompressBackups bool `json:"compress_backups"`
+	// Retention settings. Backups are kept grandfather-father-son: the
+	// newest backup of each of the last RetentionDaily days, RetentionWeekly
+	// weeks and RetentionMonthly months that have a backup.
+	RetentionDaily   int  `json:"retention_daily"`
+	RetentionWeekly  int  `json:"retention_weekly"`
+	RetentionMonthly int  `json:"retention_monthly"`
+	MaxBackupCount   int  `json:"max_backup_count"`
+	CompressBackups  bool `json:"compress_backups"`
 
 	// Validation settings
 	ValidateAfterBackup   bool `json:"validate_after_backup"`
 	ValidateBeforeRestore bool `json:"validate_before_restore"`
+	// VerifyScratchDB is the database, on the same server, backups are
+	// restored into to verify them or to copy tables from in a selective
+	// restore; its contents are replaced. Empty creates a temporary
+	// database each time.
+	VerifyScratchDB string `json:"verify_scratch_db"`
+	// ManifestKey signs the manifest of SHA-256 checksums written next to
+	// every backup, so that tampering is told apart from corruption. Empty
+	// writes unsigned manifests.
+	ManifestKey string `json:"-"`
+	// ReverifyInterval is how often stored backups are checked against
+	// their manifests; zero disables the checks
+	ReverifyInterval time.Duration `json:"reverify_interval"`
+	// SkipMigrationBackup lets backup-admin pre-migrate succeed without
+	// backing up the database before migrations are applied
+	SkipMigrationBackup bool `json:"skip_migration_backup"`
+	// MigrationBackupOnStartup makes the leader take a migration backup at
+	// startup when the database is behind the migrations the server was
+	// built with
+	MigrationBackupOnStartup bool `json:"migration_backup_on_startup"`
+
+	// Media asset settings. Full backups list the media assets the database
+	// refers to in an asset manifest; with DownloadAssets the assets are
+	// stored with the backup too, each at most AssetMaxSize bytes; zero is
+	// unlimited. Only URLs on AssetHosts are downloaded, as the URLs come
+	// from the database.
+	Assets         bool     `json:"assets"`
+	DownloadAssets bool     `json:"download_assets"`
+	AssetMaxSize   int64    `json:"asset_max_size"`
+	AssetHosts     []string `json:"asset_hosts"`
 
 	// Security settings
-	EncryptBackups bool   `json:"encrypt_backups"`
-	EncryptionKey  string `json:"encryption_key"`
+	EncryptBackups bool `json:"encrypt_backups"`
+	// EncryptionKeys are comma-separated KEY_ID:HEX_KEY master keys that
+	// wrap the data key of each backup. Keys no longer active stay to
+	// decrypt older backups until they are rotated.
+	EncryptionKeys        string `json:"-"`
+	EncryptionActiveKeyID string `json:"encryption_active_key_id"`
+	// KMSConfig wraps data keys with AWS KMS instead of EncryptionKeys
+	KMSConfig *KMSBackupConfig `json:"kms_config,omitempty"`
 
 	// Storage settings
 	StorageType string             `json:"storage_type"` // local, s3, azure, gcp
 	S3Config    *S3BackupConfig    `json:"s3_config,omitempty"`
 	AzureConfig *AzureBackupConfig `json:"azure_config,omitempty"`
+	// KeepLocalCopy keeps backups in BackupDir after they were uploaded to
+	// remote storage
+	KeepLocalCopy bool `json:"keep_local_copy"`
 
 	// Performance settings
 	CompressionLevel int `json:"compression_level"` // 1-9 for gzip
-	ParallelJobs     int `json:"parallel_jobs"`
-	BufferSize       int `json:"buffer_size"`
+	// DumpFormat is the pg_dump format of full backups: plain SQL, or the
+	// custom and directory archive formats pg_restore restores in parallel
+	DumpFormat string `json:"dump_format"`
+	// ParallelJobs is the number of pg_dump workers of directory format
+	// backups and of pg_restore workers restoring archives
+	ParallelJobs int `json:"parallel_jobs"`
+	BufferSize   int `json:"buffer_size"`
+
+	// IO throttling, so that large dumps and restores do not starve the
+	// application. Backups and restores read and write at most
+	// IOBytesPerSecond bytes and IOOpsPerSecond reads or writes a second;
+	// zero is unlimited. IOSchedule overrides both during time windows.
+	IOBytesPerSecond int64  `json:"io_bytes_per_second"`
+	IOOpsPerSecond   int    `json:"io_ops_per_second"`
+	IOSchedule       string `json:"io_schedule"` // Comma-separated HH:MM-HH:MM=BYTES[/OPS] windows, see IOWindows
+
+	// Tools locates pg_dump, psql and pg_restore, or runs them in a
+	// container when the server has no PostgreSQL client
+	Tools DatabaseToolsConfig `json:"tools"`
+
+	// Databases are backed up besides the application database, each on
+	// its own schedule and with its own retention
+	Databases []BackupDatabase `json:"databases,omitempty"`
 
 	// Monitoring settings
 	NotifyOnSuccess    bool   `json:"notify_on_success"`
@@ -49,6 +115,27 @@ type BackupConfig struct {
 	WebhookURL         string `json:"webhook_url"`
 	SlackWebhookURL    string `json:"slack_webhook_url"`
 	EmailNotifications bool   `json:"email_notifications"`
+	// The least severe events (info, warning or critical) sent to each
+	// notification channel
+	SlackMinSeverity   string `json:"slack_min_severity"`
+	WebhookMinSeverity string `json:"webhook_min_severity"`
+	EmailMinSeverity   string `json:"email_min_severity"`
+	// NotifyTemplateDir holds <event>.tmpl files replacing the default
+	// message templates, e.g. backup.failure.tmpl
+	NotifyTemplateDir string `json:"notify_template_dir"`
+	// SMTPConfig is set when EmailNotifications is enabled
+	SMTPConfig *SMTPNotifyConfig `json:"smtp_config,omitempty"`
+}
+
+// SMTPNotifyConfig holds the mail server backup notifications are sent
+// through
+type SMTPNotifyConfig struct {
+	Host     string   `json:"host"`
+	Port     int      `json:"port"`
+	Username string   `json:"username"`
+	Password string   `json:"-"`
+	From     string   `json:"from"`
+	To       []string `json:"to"`
 }
 
 // S3BackupConfig holds AWS S3 specific configuration
@@ -59,6 +146,21 @@ type S3BackupConfig struct {
 	Bucket          string `json:"bucket"`
 	Prefix          string `json:"prefix"`
 	StorageClass    string `json:"storage_class"` // STANDARD, REDUCED_REDUNDANCY, IA, GLACIER
+	// Endpoint of an S3 compatible server such as MinIO; empty for AWS
+	Endpoint string `json:"endpoint,omitempty"`
+	// ForcePathStyle addresses the bucket in the path instead of the host
+	// name, which MinIO needs
+	ForcePathStyle bool `json:"force_path_style"`
+}
+
+// KMSBackupConfig holds the AWS KMS key wrapping backup data keys
+type KMSBackupConfig struct {
+	KeyID           string `json:"key_id"` // Key ID, ARN or alias
+	Region          string `json:"region"`
+	AccessKeyID     string `json:"-"`
+	SecretAccessKey string `json:"-"`
+	// Endpoint of a KMS compatible server; empty for AWS
+	Endpoint string `json:"endpoint,omitempty"`
 }
 
 // AzureBackupConfig holds Azure specific configuration
@@ -69,6 +171,176 @@ type AzureBackupConfig struct {
 	Prefix        string `json:"prefix"`
 }
 
+// PrimaryDatabase names the application database among the databases
+// backed up
+const PrimaryDatabase = "primary"
+
+// BackupDatabase is a database backed up besides the application database,
+// e.g. a separate analytics database. Its backups are kept in a directory
+// of BackupDir, and under a storage prefix, named after it. Connection
+// settings left empty are those of the application database.
+type BackupDatabase struct {
+	Name     string `json:"name"` // Lowercase, as in BACKUP_DB_<NAME>_* variables
+	Host     string `json:"host,omitempty"`
+	Port     string `json:"port,omitempty"`
+	User     string `json:"user,omitempty"`
+	Password string `json:"-"`
+	DBName   string `json:"db_name"`
+
+	AutoBackupEnabled bool   `json:"auto_backup_enabled"`
+	BackupTime        string `json:"backup_time"` // Daily full backup at HH:MM
+
+	RetentionDaily   int `json:"retention_daily"`
+	RetentionWeekly  int `json:"retention_weekly"`
+	RetentionMonthly int `json:"retention_monthly"`
+	MaxBackupCount   int `json:"max_backup_count"`
+}
+
+// databaseNamePattern matches the names of the databases backed up
+var databaseNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// Database returns the database backed up under name
+func (c *BackupConfig) Database(name string) (BackupDatabase, bool) {
+	for _, database := range c.Databases {
+		if database.Name == name {
+			return database, true
+		}
+	}
+	return BackupDatabase{}, false
+}
+
+// ForDatabase returns the configuration backing up database: its backups
+// are kept in their own directory and storage prefix, on its schedule and
+// with its retention. Media assets and migration backups only concern the
+// application database.
+func (c BackupConfig) ForDatabase(database BackupDatabase) BackupConfig {
+	c.BackupDir = filepath.Join(c.BackupDir, database.Name)
+	if c.S3Config != nil {
+		s3 := *c.S3Config
+		s3.Prefix += database.Name + "/"
+		c.S3Config = &s3
+	}
+	if c.AzureConfig != nil {
+		azure := *c.AzureConfig
+		azure.Prefix += database.Name + "/"
+		c.AzureConfig = &azure
+	}
+	c.AutoBackupEnabled = database.AutoBackupEnabled
+	c.BackupTime = database.BackupTime
+	c.RetentionDaily = database.RetentionDaily
+	c.RetentionWeekly = database.RetentionWeekly
+	c.RetentionMonthly = database.RetentionMonthly
+	c.MaxBackupCount = database.MaxBackupCount
+	c.Assets = false
+	c.MigrationBackupOnStartup = false
+	c.Databases = nil
+	return c
+}
+
+// Connection returns cfg connecting to the database instead of the
+// application database
+func (database BackupDatabase) Connection(cfg Config) Config {
+	if database.Host != "" {
+		cfg.DBHost = database.Host
+	}
+	if database.Port != "" {
+		cfg.DBPort = database.Port
+	}
+	if database.User != "" {
+		cfg.DBUser = database.User
+	}
+	if database.Password != "" {
+		cfg.DBPassword = database.Password
+	}
+	cfg.DBName = database.DBName
+	cfg.DBSource = GetDBSource(cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName)
+	return cfg
+}
+
+// loadBackupDatabases reads the databases listed in BACKUP_DATABASES from
+// their BACKUP_DB_<NAME>_* variables; unset settings are those of cfg
+func loadBackupDatabases(cfg BackupConfig) []BackupDatabase {
+	var databases []BackupDatabase
+	for _, name := range getEnvList("BACKUP_DATABASES") {
+		name = strings.ToLower(name)
+		prefix := "BACKUP_DB_" + strings.ToUpper(name) + "_"
+		databases = append(databases, BackupDatabase{
+			Name:              name,
+			Host:              getEnvString(prefix+"HOST", ""),
+			Port:              getEnvString(prefix+"PORT", ""),
+			User:              getEnvString(prefix+"USER", ""),
+			Password:          getEnvString(prefix+"PASSWORD", ""),
+			DBName:            getEnvString(prefix+"NAME", name),
+			AutoBackupEnabled: getEnvBool(prefix+"AUTO_BACKUP", cfg.AutoBackupEnabled),
+			BackupTime:        getEnvString(prefix+"TIME", cfg.BackupTime),
+			RetentionDaily:    getEnvInt(prefix+"KEEP_DAILY", cfg.RetentionDaily),
+			RetentionWeekly:   getEnvInt(prefix+"KEEP_WEEKLY", cfg.RetentionWeekly),
+			RetentionMonthly:  getEnvInt(prefix+"KEEP_MONTHLY", cfg.RetentionMonthly),
+			MaxBackupCount:    getEnvInt(prefix+"MAX_COUNT", cfg.MaxBackupCount),
+		})
+	}
+	return databases
+}
+
+// validateDatabases checks the databases backed up besides the
+// application database
+func (c *BackupConfig) validateDatabases() error {
+	seen := make(map[string]bool)
+	for _, database := range c.Databases {
+		prefix := "BACKUP_DB_" + strings.ToUpper(database.Name) + "_"
+		switch {
+		case !databaseNamePattern.MatchString(database.Name):
+			return fmt.Errorf("BACKUP_DATABASES: %q must start with a letter and hold only letters, digits and underscores", database.Name)
+		case database.Name == PrimaryDatabase:
+			return fmt.Errorf("BACKUP_DATABASES: %q names the application database", database.Name)
+		case seen[database.Name]:
+			return fmt.Errorf("BACKUP_DATABASES: %q is listed twice", database.Name)
+		case database.DBName == "":
+			return fmt.Errorf("%sNAME cannot be empty", prefix)
+		case database.RetentionDaily < 0 || database.RetentionWeekly < 0 || database.RetentionMonthly < 0,
+			database.RetentionDaily+database.RetentionWeekly+database.RetentionMonthly == 0:
+			return fmt.Errorf("%sKEEP_DAILY, %sKEEP_WEEKLY or %sKEEP_MONTHLY must keep at least one backup", prefix, prefix, prefix)
+		case database.MaxBackupCount < 1:
+			return fmt.Errorf("%sMAX_COUNT must be at least 1", prefix)
+		}
+		if _, err := parseTimeOfDay(database.BackupTime); err != nil {
+			return fmt.Errorf("%sTIME: %w", prefix, err)
+		}
+		seen[database.Name] = true
+	}
+	return nil
+}
+
+// Modes of running the database tools
+const (
+	ToolsModeLocal   = "local"   // Run the installed client tools
+	ToolsModeDocker  = "docker"  // Run them in a throwaway container of Image
+	ToolsModeSidecar = "sidecar" // Run them in the running Container
+)
+
+// DatabaseToolsConfig holds where pg_dump, psql and pg_restore are found
+type DatabaseToolsConfig struct {
+	Mode string `json:"mode"` // local, docker or sidecar
+	// Paths of the tools; empty looks for them in BinDir, then PATH and
+	// the usual install locations. In a container they are run by name
+	// unless set.
+	PgDumpPath    string `json:"pg_dump_path,omitempty"`
+	PsqlPath      string `json:"psql_path,omitempty"`
+	PgRestorePath string `json:"pg_restore_path,omitempty"`
+	BinDir        string `json:"bin_dir,omitempty"`
+	// DockerPath is the docker CLI of the docker and sidecar modes
+	DockerPath string `json:"docker_path,omitempty"`
+	// Image is the image of the docker mode, which should match the major
+	// version of the server, e.g. postgres:16-alpine
+	Image string `json:"image,omitempty"`
+	// Network the docker mode container joins; host reaches a database on
+	// localhost, a compose network one named after its service
+	Network string `json:"network,omitempty"`
+	// Container of the sidecar mode, which must see the backup directory
+	// at the same path as the server
+	Container string `json:"container,omitempty"`
+}
+
 // LoadBackupConfig loads backup configuration from environment variables
 func LoadBackupConfig() BackupConfig {
 	cfg := BackupConfig{
@@ -82,29 +354,68 @@ func LoadBackupConfig() BackupConfig {
 		BackupInterval:    getEnvDuration("BACKUP_INTERVAL", 24*time.Hour),
 		BackupTime:        getEnvString("BACKUP_TIME", "03:00"), // 3 AM default
 
-		RetentionDays:   getEnvInt("BACKUP_RETENTION_DAYS", 30),
-		MaxBackupCount:  getEnvInt("BACKUP_MAX_COUNT", 100),
-		CompressBackups: getEnvBool("BACKUP_COMPRESS", true),
+		RetentionDaily:   getEnvInt("BACKUP_KEEP_DAILY", 7),
+		RetentionWeekly:  getEnvInt("BACKUP_KEEP_WEEKLY", 4),
+		RetentionMonthly: getEnvInt("BACKUP_KEEP_MONTHLY", 12),
+		MaxBackupCount:   getEnvInt("BACKUP_MAX_COUNT", 100),
+		CompressBackups:  getEnvBool("BACKUP_COMPRESS", true),
 
-		ValidateAfterBackup:   getEnvBool("BACKUP_VALIDATE_AFTER", true),
-		ValidateBeforeRestore: getEnvBool("BACKUP_VALIDATE_BEFORE_RESTORE", true),
+		ValidateAfterBackup:      getEnvBool("BACKUP_VALIDATE_AFTER", true),
+		ValidateBeforeRestore:    getEnvBool("BACKUP_VALIDATE_BEFORE_RESTORE", true),
+		VerifyScratchDB:          getEnvString("BACKUP_VERIFY_SCRATCH_DB", ""),
+		ManifestKey:              getEnvString("BACKUP_MANIFEST_KEY", ""),
+		ReverifyInterval:         getEnvDuration("BACKUP_REVERIFY_INTERVAL", 24*time.Hour),
+		SkipMigrationBackup:      getEnvBool("MIGRATION_SKIP_BACKUP", false),
+		MigrationBackupOnStartup: getEnvBool("MIGRATION_BACKUP_ON_STARTUP", true),
 
-		EncryptBackups: getEnvBool("BACKUP_ENCRYPT", false),
-		EncryptionKey:  getEnvString("BACKUP_ENCRYPTION_KEY", ""),
+		Assets:         getEnvBool("BACKUP_ASSETS", true),
+		DownloadAssets: getEnvBool("BACKUP_ASSETS_DOWNLOAD", false),
+		AssetMaxSize:   getEnvSize("BACKUP_ASSETS_MAX_SIZE", 100<<20),
+		AssetHosts:     getEnvList("BACKUP_ASSETS_HOSTS"),
 
-		StorageType: getEnvString("BACKUP_STORAGE_TYPE", "local"),
+		EncryptBackups:        getEnvBool("BACKUP_ENCRYPT", false),
+		EncryptionKeys:        getEnvString("BACKUP_ENCRYPTION_KEYS", ""),
+		EncryptionActiveKeyID: getEnvString("BACKUP_ENCRYPTION_ACTIVE_KEY_ID", ""),
+
+		StorageType:   getEnvString("BACKUP_STORAGE_TYPE", "local"),
+		KeepLocalCopy: getEnvBool("BACKUP_KEEP_LOCAL", true),
 
 		CompressionLevel: getEnvInt("BACKUP_COMPRESSION_LEVEL", 6),
+		DumpFormat:       getEnvString("BACKUP_DUMP_FORMAT", "plain"),
 		ParallelJobs:     getEnvInt("BACKUP_PARALLEL_JOBS", 1),
 		BufferSize:       getEnvInt("BACKUP_BUFFER_SIZE", 64*1024), // 64KB
+		IOBytesPerSecond: getEnvSize("BACKUP_IO_MAX_RATE", 0),
+		IOOpsPerSecond:   getEnvInt("BACKUP_IO_MAX_OPS", 0),
+		IOSchedule:       getEnvString("BACKUP_IO_SCHEDULE", ""),
 
 		NotifyOnSuccess:    getEnvBool("BACKUP_NOTIFY_SUCCESS", false),
 		NotifyOnFailure:    getEnvBool("BACKUP_NOTIFY_FAILURE", true),
 		WebhookURL:         getEnvString("BACKUP_WEBHOOK_URL", ""),
 		SlackWebhookURL:    getEnvString("BACKUP_SLACK_WEBHOOK_URL", ""),
 		EmailNotifications: getEnvBool("BACKUP_EMAIL_NOTIFICATIONS", false),
+		SlackMinSeverity:   getEnvString("BACKUP_SLACK_MIN_SEVERITY", "info"),
+		WebhookMinSeverity: getEnvString("BACKUP_WEBHOOK_MIN_SEVERITY", "info"),
+		EmailMinSeverity:   getEnvString("BACKUP_EMAIL_MIN_SEVERITY", "warning"),
+		NotifyTemplateDir:  getEnvString("BACKUP_NOTIFY_TEMPLATE_DIR", ""),
+
+		Tools: DatabaseToolsConfig{
+			Mode:          getEnvString("BACKUP_TOOLS_MODE", ToolsModeLocal),
+			PgDumpPath:    getEnvString("BACKUP_PG_DUMP_PATH", ""),
+			PsqlPath:      getEnvString("BACKUP_PSQL_PATH", ""),
+			PgRestorePath: getEnvString("BACKUP_PG_RESTORE_PATH", ""),
+			BinDir:        getEnvString("BACKUP_TOOLS_BIN_DIR", ""),
+			DockerPath:    getEnvString("BACKUP_TOOLS_DOCKER_PATH", "docker"),
+			Image:         getEnvString("BACKUP_TOOLS_IMAGE", ""),
+			Network:       getEnvString("BACKUP_TOOLS_NETWORK", "host"),
+			Container:     getEnvString("BACKUP_TOOLS_CONTAINER", ""),
+		},
 	}
 
+	if len(cfg.AssetHosts) == 0 {
+		cfg.AssetHosts = []string{"res.cloudinary.com"}
+	}
+	cfg.Databases = loadBackupDatabases(cfg)
+
 	// Load cloud storage configs if needed
 	if cfg.StorageType == "s3" {
 		cfg.S3Config = &S3BackupConfig{
@@ -114,6 +425,29 @@ func LoadBackupConfig() BackupConfig {
 			Bucket:          getEnvString("BACKUP_S3_BUCKET", ""),
 			Prefix:          getEnvString("BACKUP_S3_PREFIX", "toeic-backups/"),
 			StorageClass:    getEnvString("BACKUP_S3_STORAGE_CLASS", "STANDARD"),
+			Endpoint:        getEnvString("BACKUP_S3_ENDPOINT", ""),
+			ForcePathStyle:  getEnvBool("BACKUP_S3_FORCE_PATH_STYLE", false),
+		}
+	}
+
+	if keyID := getEnvString("BACKUP_KMS_KEY_ID", ""); keyID != "" {
+		cfg.KMSConfig = &KMSBackupConfig{
+			KeyID:           keyID,
+			Region:          getEnvString("AWS_REGION", "us-east-1"),
+			AccessKeyID:     getEnvString("AWS_ACCESS_KEY_ID", ""),
+			SecretAccessKey: getEnvString("AWS_SECRET_ACCESS_KEY", ""),
+			Endpoint:        getEnvString("BACKUP_KMS_ENDPOINT", ""),
+		}
+	}
+
+	if cfg.EmailNotifications {
+		cfg.SMTPConfig = &SMTPNotifyConfig{
+			Host:     getEnvString("BACKUP_SMTP_HOST", ""),
+			Port:     getEnvInt("BACKUP_SMTP_PORT", 587),
+			Username: getEnvString("BACKUP_SMTP_USERNAME", ""),
+			Password: getEnvString("BACKUP_SMTP_PASSWORD", ""),
+			From:     getEnvString("BACKUP_EMAIL_FROM", ""),
+			To:       getEnvList("BACKUP_EMAIL_TO"),
 		}
 	}
 
@@ -143,8 +477,12 @@ func (c *BackupConfig) Validate() error {
 		return fmt.Errorf("max retries must be at least 1")
 	}
 
-	if c.RetentionDays < 1 {
-		return fmt.Errorf("retention days must be at least 1")
+	if c.RetentionDaily < 0 || c.RetentionWeekly < 0 || c.RetentionMonthly < 0 {
+		return fmt.Errorf("backups kept per day, week and month cannot be negative")
+	}
+
+	if c.RetentionDaily+c.RetentionWeekly+c.RetentionMonthly == 0 {
+		return fmt.Errorf("BACKUP_KEEP_DAILY, BACKUP_KEEP_WEEKLY or BACKUP_KEEP_MONTHLY must keep at least one backup")
 	}
 
 	if c.MaxBackupCount < 1 {
@@ -155,24 +493,173 @@ func (c *BackupConfig) Validate() error {
 		return fmt.Errorf("compression level must be between 1 and 9")
 	}
 
+	switch c.DumpFormat {
+	case "", "plain", "custom", "directory":
+	default:
+		return fmt.Errorf("BACKUP_DUMP_FORMAT must be plain, custom or directory")
+	}
+
+	if c.ParallelJobs < 1 {
+		return fmt.Errorf("BACKUP_PARALLEL_JOBS must be at least 1")
+	}
+
+	if c.IOBytesPerSecond < 0 || c.IOOpsPerSecond < 0 {
+		return fmt.Errorf("BACKUP_IO_MAX_RATE and BACKUP_IO_MAX_OPS cannot be negative")
+	}
+
+	if _, err := c.IOWindows(); err != nil {
+		return fmt.Errorf("BACKUP_IO_SCHEDULE: %w", err)
+	}
+
+	if c.ManifestKey != "" && len(c.ManifestKey) < 32 {
+		return fmt.Errorf("BACKUP_MANIFEST_KEY must be at least 32 characters")
+	}
+
+	if c.AssetMaxSize < 0 {
+		return fmt.Errorf("BACKUP_ASSETS_MAX_SIZE cannot be negative")
+	}
+
+	if c.ReverifyInterval < 0 {
+		return fmt.Errorf("BACKUP_REVERIFY_INTERVAL cannot be negative")
+	}
+
+	switch c.Tools.Mode {
+	case "", ToolsModeLocal:
+	case ToolsModeDocker:
+		if c.Tools.Image == "" {
+			return fmt.Errorf("BACKUP_TOOLS_IMAGE required when BACKUP_TOOLS_MODE is docker")
+		}
+	case ToolsModeSidecar:
+		if c.Tools.Container == "" {
+			return fmt.Errorf("BACKUP_TOOLS_CONTAINER required when BACKUP_TOOLS_MODE is sidecar")
+		}
+	default:
+		return fmt.Errorf("BACKUP_TOOLS_MODE must be local, docker or sidecar")
+	}
+
+	if err := c.validateDatabases(); err != nil {
+		return err
+	}
+
 	if c.StorageType == "s3" && c.S3Config == nil {
 		return fmt.Errorf("S3 configuration required when storage type is s3")
 	}
 
+	if c.StorageType == "s3" && c.S3Config.Bucket == "" {
+		return fmt.Errorf("BACKUP_S3_BUCKET required when storage type is s3")
+	}
+
 	if c.StorageType == "azure" && c.AzureConfig == nil {
 		return fmt.Errorf("azure configuration required when storage type is azure")
 	}
 
-	if c.EncryptBackups && c.EncryptionKey == "" {
-		return fmt.Errorf("encryption key required when backup encryption is enabled")
+	if c.EncryptBackups && c.KMSConfig == nil && (c.EncryptionKeys == "" || c.EncryptionActiveKeyID == "") {
+		return fmt.Errorf("BACKUP_ENCRYPTION_KEYS and BACKUP_ENCRYPTION_ACTIVE_KEY_ID, or BACKUP_KMS_KEY_ID, required when backup encryption is enabled")
+	}
+
+	if c.EmailNotifications && (c.SMTPConfig == nil || c.SMTPConfig.Host == "" || c.SMTPConfig.From == "" || len(c.SMTPConfig.To) == 0) {
+		return fmt.Errorf("BACKUP_SMTP_HOST, BACKUP_EMAIL_FROM and BACKUP_EMAIL_TO required when email notifications are enabled")
+	}
+
+	for name, severity := range map[string]string{
+		"BACKUP_SLACK_MIN_SEVERITY":   c.SlackMinSeverity,
+		"BACKUP_WEBHOOK_MIN_SEVERITY": c.WebhookMinSeverity,
+		"BACKUP_EMAIL_MIN_SEVERITY":   c.EmailMinSeverity,
+	} {
+		switch severity {
+		case "", "info", "warning", "critical":
+		default:
+			return fmt.Errorf("%s must be info, warning or critical", name)
+		}
 	}
 
 	return nil
 }
 
-// GetRetentionDuration returns the retention period as a duration
-func (c *BackupConfig) GetRetentionDuration() time.Duration {
-	return time.Duration(c.RetentionDays) * 24 * time.Hour
+// BackupIOWindow is a daily time window with its own IO limits. Windows
+// ending before they start span midnight.
+type BackupIOWindow struct {
+	Start          time.Duration // Since midnight
+	End            time.Duration // Since midnight
+	BytesPerSecond int64         // Zero is unlimited
+	OpsPerSecond   int           // Zero is unlimited
+}
+
+// Contains reports whether the window covers a time of day
+func (w BackupIOWindow) Contains(t time.Time) bool {
+	since := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+	if w.Start <= w.End {
+		return since >= w.Start && since < w.End
+	}
+	return since >= w.Start || since < w.End
+}
+
+// IOWindows parses IOSchedule, windows like 09:00-18:00=5MB/200 limiting
+// IO to 5 MiB and 200 operations a second during business hours, or
+// 02:00-05:00=0 lifting the limits at night. The first window covering a
+// time applies.
+func (c *BackupConfig) IOWindows() ([]BackupIOWindow, error) {
+	var windows []BackupIOWindow
+	for _, entry := range strings.Split(c.IOSchedule, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		span, limits, ok := strings.Cut(entry, "=")
+		start, end, okSpan := strings.Cut(span, "-")
+		if !ok || !okSpan {
+			return nil, fmt.Errorf("%q is not HH:MM-HH:MM=BYTES[/OPS]", entry)
+		}
+		var window BackupIOWindow
+		var err error
+		if window.Start, err = parseTimeOfDay(start); err != nil {
+			return nil, fmt.Errorf("%q: %w", entry, err)
+		}
+		if window.End, err = parseTimeOfDay(end); err != nil {
+			return nil, fmt.Errorf("%q: %w", entry, err)
+		}
+		rate, ops, hasOps := strings.Cut(limits, "/")
+		if window.BytesPerSecond, err = parseSize(rate); err != nil {
+			return nil, fmt.Errorf("%q: %w", entry, err)
+		}
+		if hasOps {
+			if window.OpsPerSecond, err = strconv.Atoi(strings.TrimSpace(ops)); err != nil || window.OpsPerSecond < 0 {
+				return nil, fmt.Errorf("%q: invalid operations per second %q", entry, ops)
+			}
+		}
+		windows = append(windows, window)
+	}
+	return windows, nil
+}
+
+// parseTimeOfDay parses HH:MM into the time since midnight
+func parseTimeOfDay(value string) (time.Duration, error) {
+	parsed, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	return time.Duration(parsed.Hour())*time.Hour + time.Duration(parsed.Minute())*time.Minute, nil
+}
+
+// parseSize parses a byte count with an optional KB, MB or GB suffix, in
+// powers of 1024
+func parseSize(original string) (int64, error) {
+	value := strings.ToUpper(strings.TrimSpace(original))
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix     string
+		multiplier int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}} {
+		if strings.HasSuffix(value, unit.suffix) {
+			value, multiplier = strings.TrimSpace(strings.TrimSuffix(value, unit.suffix)), unit.multiplier
+			break
+		}
+	}
+	size, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("invalid size %q", original)
+	}
+	return size * multiplier, nil
 }
 
 // Helper functions for environment variables
@@ -201,6 +688,16 @@ func getEnvInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// getEnvSize reads a byte count such as 512KB or 10MB
+func getEnvSize(key string, defaultValue int64) int64 {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := parseSize(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
 func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
 	if value := os.Getenv(key); value != "" {
 		if parsed, err := time.ParseDuration(value); err == nil {
@@ -209,3 +706,14 @@ func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
 	}
 	return defaultValue
 }
+
+// getEnvList returns the non-empty items of a comma-separated variable
+func getEnvList(key string) []string {
+	var items []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -5,6 +5,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -14,52 +15,69 @@ import (
 // The values are read from environment variables or .env file.
 type Config struct {
 	// Database configuration
-	DBDriver             string        `mapstructure:"DB_DRIVER"`
-	DBSource             string        `mapstructure:"DB_SOURCE"`
-	DBHost               string        `mapstructure:"DB_HOST"`
-	DBPort               string        `mapstructure:"DB_PORT"`
+	DBDriver             string        `mapstructure:"DB_DRIVER" validate:"required"`
+	DBSource             string        `mapstructure:"DB_SOURCE" secret:"true"`
+	DBHost               string        `mapstructure:"DB_HOST" validate:"required"`
+	DBPort               string        `mapstructure:"DB_PORT" validate:"required,numeric"`
 	DBUser               string        `mapstructure:"DB_USER"`
-	DBPassword           string        `mapstructure:"DB_PASSWORD"`
-	DBName               string        `mapstructure:"DB_NAME"`
-	ServerAddress        string        `mapstructure:"SERVER_ADDRESS"`
-	TokenSymmetricKey    string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
-	AccessTokenDuration  time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
-	RefreshTokenDuration int64         `mapstructure:"REFRESH_TOKEN_DURATION"`
-	CloudinaryURL        string        `mapstructure:"CLOUDINARY_URL"`
+	DBPassword           string        `mapstructure:"DB_PASSWORD" secret:"true"`
+	DBName               string        `mapstructure:"DB_NAME" validate:"required"`
+	ServerAddress        string        `mapstructure:"SERVER_ADDRESS" validate:"required,hostname_port"`
+	TokenSymmetricKey    string        `mapstructure:"TOKEN_SYMMETRIC_KEY" secret:"true" validate:"required,min=32"`
+	AccessTokenDuration  time.Duration `mapstructure:"ACCESS_TOKEN_DURATION" validate:"gt=0"`
+	RefreshTokenDuration int64         `mapstructure:"REFRESH_TOKEN_DURATION" validate:"gt=0"`
+	CloudinaryURL        string        `mapstructure:"CLOUDINARY_URL" secret:"true" validate:"required"`
 
 	// Security configuration
 	DisableHTTPSRedirect bool `mapstructure:"DISABLE_HTTPS_REDIRECT"`
 
 	// Google AI configuration
-	GoogleAIAPIKey string `mapstructure:"GOOGLE_AI_API_KEY"`
+	GoogleAIAPIKey string `mapstructure:"GOOGLE_AI_API_KEY" secret:"true"`
 
 	// Rate limiting configuration
 	RateLimitEnabled   bool          `mapstructure:"RATE_LIMIT_ENABLED"`
-	RateLimitRequests  int           `mapstructure:"RATE_LIMIT_REQUESTS"`   // Requests per second
-	RateLimitBurst     int           `mapstructure:"RATE_LIMIT_BURST"`      // Maximum burst size
-	RateLimitExpiresIn time.Duration `mapstructure:"RATE_LIMIT_EXPIRES_IN"` // Expiration time for visitor entries
+	RateLimitRequests  int           `mapstructure:"RATE_LIMIT_REQUESTS" validate:"min=1"` // Requests per second
+	RateLimitBurst     int           `mapstructure:"RATE_LIMIT_BURST" validate:"min=1"`    // Maximum burst size
+	RateLimitExpiresIn time.Duration `mapstructure:"RATE_LIMIT_EXPIRES_IN"`                // Expiration time for visitor entries
+
+	// Rate limit storage: memory, or redis to share limits between instances
+	RateLimitBackend   string `mapstructure:"RATE_LIMIT_BACKEND" validate:"oneof=memory redis"`
+	RateLimitKeyPrefix string `mapstructure:"RATE_LIMIT_KEY_PREFIX"` // Redis key prefix of request logs
+
 	// Auth rate limiting configuration (for login/register endpoints)
 	AuthRateLimitEnabled  bool `mapstructure:"AUTH_RATE_LIMIT_ENABLED"`
-	AuthRateLimitRequests int  `mapstructure:"AUTH_RATE_LIMIT_REQUESTS"` // Requests per second
-	AuthRateLimitBurst    int  `mapstructure:"AUTH_RATE_LIMIT_BURST"`    // Maximum burst size
+	AuthRateLimitRequests int  `mapstructure:"AUTH_RATE_LIMIT_REQUESTS" validate:"min=1"` // Requests per second
+	AuthRateLimitBurst    int  `mapstructure:"AUTH_RATE_LIMIT_BURST" validate:"min=1"`    // Maximum burst size
 	// CORS configuration
-	CORSAllowedOrigins string `mapstructure:"CORS_ALLOWED_ORIGINS"` // Comma-separated list of allowed origins
+	AppEnv                        string        `mapstructure:"APP_ENV" validate:"oneof=development staging production"` // Deployment environment, selects the per-environment origins
+	CORSAllowedOrigins            string        `mapstructure:"CORS_ALLOWED_ORIGINS"`                                    // Comma-separated origins allowed in every environment (reloadable)
+	CORSAllowedOriginsDevelopment string        `mapstructure:"CORS_ALLOWED_ORIGINS_DEVELOPMENT"`                        // Additional origins allowed in development (reloadable)
+	CORSAllowedOriginsStaging     string        `mapstructure:"CORS_ALLOWED_ORIGINS_STAGING"`                            // Additional origins allowed in staging (reloadable)
+	CORSAllowedOriginsProduction  string        `mapstructure:"CORS_ALLOWED_ORIGINS_PRODUCTION"`                         // Additional origins allowed in production (reloadable)
+	CORSMaxAge                    time.Duration `mapstructure:"CORS_MAX_AGE" validate:"gte=0"`                           // How long browsers may cache preflight responses (reloadable)
+
+	// Feature flags (reloadable)
+	FeatureFlags string `mapstructure:"FEATURE_FLAGS"` // Comma-separated list of enabled features
+
+	// Client configuration served to the apps (reloadable)
+	ClientMinAppVersion     string `mapstructure:"CLIENT_MIN_APP_VERSION" validate:"omitempty,semver"` // Oldest app version allowed to keep running
+	ClientMaintenanceNotice string `mapstructure:"CLIENT_MAINTENANCE_NOTICE"`                          // Shown by the apps, e.g. ahead of planned maintenance
 
 	// Cache configuration
 	CacheEnabled    bool          `mapstructure:"CACHE_ENABLED"`
-	CacheType       string        `mapstructure:"CACHE_TYPE"`        // "memory" or "redis"
-	CacheDefaultTTL time.Duration `mapstructure:"CACHE_DEFAULT_TTL"` // Default TTL for cache entries
-	CacheMaxEntries int           `mapstructure:"CACHE_MAX_ENTRIES"` // Max entries for memory cache
-	CacheCleanupInt time.Duration `mapstructure:"CACHE_CLEANUP_INT"` // Cleanup interval for memory cache
+	CacheType       string        `mapstructure:"CACHE_TYPE" validate:"oneof=memory redis"` // "memory" or "redis"
+	CacheDefaultTTL time.Duration `mapstructure:"CACHE_DEFAULT_TTL" validate:"gt=0"`        // Default TTL for cache entries
+	CacheMaxEntries int           `mapstructure:"CACHE_MAX_ENTRIES" validate:"min=1"`       // Max entries for memory cache
+	CacheCleanupInt time.Duration `mapstructure:"CACHE_CLEANUP_INT"`                        // Cleanup interval for memory cache
 
 	// Redis cache configuration
 	RedisAddr     string `mapstructure:"REDIS_ADDR"`
-	RedisPassword string `mapstructure:"REDIS_PASSWORD"`
+	RedisPassword string `mapstructure:"REDIS_PASSWORD" secret:"true"`
 	RedisDB       int    `mapstructure:"REDIS_DB"`
 	RedisPoolSize int    `mapstructure:"REDIS_POOL_SIZE"`
 	// HTTP cache configuration
 	HTTPCacheEnabled bool          `mapstructure:"HTTP_CACHE_ENABLED"`
-	HTTPCacheTTL     time.Duration `mapstructure:"HTTP_CACHE_TTL"`
+	HTTPCacheTTL     time.Duration `mapstructure:"HTTP_CACHE_TTL" validate:"gte=0"`
 
 	// Advanced cache configuration for 1M users scalability
 	CacheShardCount         int  `mapstructure:"CACHE_SHARD_COUNT"`         // Number of Redis shards
@@ -68,9 +86,9 @@ type Config struct {
 	CacheCompressionEnabled bool `mapstructure:"CACHE_COMPRESSION_ENABLED"` // Enable compression
 
 	// Cache performance settings
-	CacheMaxMemoryUsage int64  `mapstructure:"CACHE_MAX_MEMORY_USAGE"` // Max memory usage in bytes
-	CacheEvictionPolicy string `mapstructure:"CACHE_EVICTION_POLICY"`  // "lru", "lfu", "ttl"
-	CacheMetricsEnabled bool   `mapstructure:"CACHE_METRICS_ENABLED"`  // Enable metrics collection
+	CacheMaxMemoryUsage int64  `mapstructure:"CACHE_MAX_MEMORY_USAGE"`                             // Max memory usage in bytes
+	CacheEvictionPolicy string `mapstructure:"CACHE_EVICTION_POLICY" validate:"oneof=lru lfu ttl"` // "lru", "lfu", "ttl"
+	CacheMetricsEnabled bool   `mapstructure:"CACHE_METRICS_ENABLED"`                              // Enable metrics collection
 
 	// Concurrency management configuration
 	ConcurrencyEnabled      bool `mapstructure:"CONCURRENCY_ENABLED"`
@@ -85,45 +103,279 @@ type Config struct {
 	CircuitBreakerThreshold int  `mapstructure:"CIRCUIT_BREAKER_THRESHOLD"` // Circuit breaker failure threshold
 	RequestTimeoutSeconds   int  `mapstructure:"REQUEST_TIMEOUT_SECONDS"`   // Request timeout in seconds
 	HealthCheckInterval     int  `mapstructure:"HEALTH_CHECK_INTERVAL"`     // Health check interval in seconds
+	// Leader election configuration (multi-instance deployments)
+	InstanceID            string        `mapstructure:"INSTANCE_ID"` // Defaults to hostname-pid
+	LeaderElectionEnabled bool          `mapstructure:"LEADER_ELECTION_ENABLED"`
+	LeaderElectionBackend string        `mapstructure:"LEADER_ELECTION_BACKEND" validate:"oneof=postgres redis"`
+	LeaderElectionLockID  int64         `mapstructure:"LEADER_ELECTION_LOCK_ID"` // Postgres advisory lock key
+	LeaderElectionKey     string        `mapstructure:"LEADER_ELECTION_KEY"`     // Redis lease key
+	LeaderElectionTTL     time.Duration `mapstructure:"LEADER_ELECTION_TTL" validate:"gt=0"`
 	// Analyze service configuration
 	AnalyzeServiceEnabled bool          `mapstructure:"ANALYZE_SERVICE_ENABLED"`
-	AnalyzeServiceURL     string        `mapstructure:"ANALYZE_SERVICE_URL"`
+	AnalyzeServiceURL     string        `mapstructure:"ANALYZE_SERVICE_URL" validate:"required_if=AnalyzeServiceEnabled true,omitempty,url"`
 	AnalyzeServiceTimeout time.Duration `mapstructure:"ANALYZE_SERVICE_TIMEOUT"`
+	AnalyzeAttemptTimeout time.Duration `mapstructure:"ANALYZE_SERVICE_ATTEMPT_TIMEOUT"`
+	AnalyzeMaxRetries     int           `mapstructure:"ANALYZE_SERVICE_MAX_RETRIES" validate:"gte=0"`
+	AnalyzeRetryBackoff   time.Duration `mapstructure:"ANALYZE_SERVICE_RETRY_BACKOFF"`
+	AnalyzeHedgeDelay     time.Duration `mapstructure:"ANALYZE_SERVICE_HEDGE_DELAY"` // 0 disables hedged requests
+	AnalyzeMaxConns       int           `mapstructure:"ANALYZE_SERVICE_MAX_CONNS"`
+	AnalyzeDegradedMode   bool          `mapstructure:"ANALYZE_SERVICE_DEGRADED_MODE"`
 
 	// OpenAI AI Scoring configuration
-	OpenAIAPIKey  string        `mapstructure:"OPENAI_API_KEY"`
+	OpenAIAPIKey  string        `mapstructure:"OPENAI_API_KEY" secret:"true"`
 	OpenAIAPIURL  string        `mapstructure:"OPENAI_API_URL"`
 	OpenAIModel   string        `mapstructure:"OPENAI_MODEL"`
 	OpenAITimeout time.Duration `mapstructure:"OPENAI_TIMEOUT"`
 
+	// Limits of the text of a submission sent to the AI provider, 0 disables a limit
+	AIMaxWritingWords    int    `mapstructure:"AI_MAX_WRITING_WORDS" validate:"min=0"`
+	AIMaxWritingChars    int    `mapstructure:"AI_MAX_WRITING_CHARS" validate:"min=0"`
+	AIMaxSpeakingWords   int    `mapstructure:"AI_MAX_SPEAKING_WORDS" validate:"min=0"` // A speaking practice message
+	AIMaxSpeakingChars   int    `mapstructure:"AI_MAX_SPEAKING_CHARS" validate:"min=0"`
+	AIMaxContextChars    int    `mapstructure:"AI_MAX_CONTEXT_CHARS" validate:"min=0"` // Conversation context of a speaking message
+	AIOversizeMode       string `mapstructure:"AI_OVERSIZE_MODE" validate:"oneof=reject truncate"`
+	AIRateLimitPerMinute int    `mapstructure:"AI_RATE_LIMIT_PER_MINUTE" validate:"min=1"` // Per user, apart from RATE_LIMIT_*
+	AIRateLimitPerHour   int    `mapstructure:"AI_RATE_LIMIT_PER_HOUR" validate:"min=1"`
+
 	// Performance settings
 
+	// HTTP server configuration
+	ServerReadTimeout       time.Duration `mapstructure:"SERVER_READ_TIMEOUT" validate:"gt=0"`
+	ServerReadHeaderTimeout time.Duration `mapstructure:"SERVER_READ_HEADER_TIMEOUT" validate:"gt=0"`
+	ServerWriteTimeout      time.Duration `mapstructure:"SERVER_WRITE_TIMEOUT" validate:"gt=0"`
+	ServerIdleTimeout       time.Duration `mapstructure:"SERVER_IDLE_TIMEOUT" validate:"gt=0"`
+	ServerMaxHeaderBytes    int           `mapstructure:"SERVER_MAX_HEADER_BYTES" validate:"min=1024"`
+
 	// Security configuration
 	TLSEnabled             bool   `mapstructure:"TLS_ENABLED"`
-	TLSCertFile            string `mapstructure:"TLS_CERT_FILE"`
-	TLSKeyFile             string `mapstructure:"TLS_KEY_FILE"`
+	TLSCertFile            string `mapstructure:"TLS_CERT_FILE" validate:"required_if=TLSEnabled true TLSAutocertEnabled false"`
+	TLSKeyFile             string `mapstructure:"TLS_KEY_FILE" validate:"required_if=TLSEnabled true TLSAutocertEnabled false"`
+	TLSAutocertEnabled     bool   `mapstructure:"TLS_AUTOCERT_ENABLED"`                                                // Obtain certificates from Let's Encrypt
+	TLSAutocertDomains     string `mapstructure:"TLS_AUTOCERT_DOMAINS" validate:"required_if=TLSAutocertEnabled true"` // Comma-separated list of domains
+	TLSAutocertCacheDir    string `mapstructure:"TLS_AUTOCERT_CACHE_DIR"`                                              // Directory for cached certificates
 	HTTP2Enabled           bool   `mapstructure:"HTTP2_ENABLED"`
 	HTTP2MaxStreams        uint32 `mapstructure:"HTTP2_MAX_STREAMS"`
 	HTTP2IdleTimeout       int    `mapstructure:"HTTP2_IDLE_TIMEOUT"`
 	SecurityHeadersEnabled bool   `mapstructure:"SECURITY_HEADERS_ENABLED"`
 	InputValidationEnabled bool   `mapstructure:"INPUT_VALIDATION_ENABLED"`
+	RequestSigningMode     string `mapstructure:"REQUEST_SIGNING_MODE" validate:"oneof=off optional required"`
+	RequestSigningKeys     string `mapstructure:"REQUEST_SIGNING_KEYS" secret:"true" validate:"required_if=RequestSigningMode required"` // Comma-separated KEY_ID:SECRET pairs, one per app release
+
+	// Rich text sanitization applied when content is written
+	SanitizeEnabled        bool   `mapstructure:"SANITIZE_ENABLED"`
+	SanitizeRichTextPolicy string `mapstructure:"SANITIZE_RICH_TEXT_POLICY" validate:"oneof=strict basic ugc"` // Policy for HTML fields
+	SanitizeMarkdownPolicy string `mapstructure:"SANITIZE_MARKDOWN_POLICY" validate:"oneof=strict basic ugc"`  // Policy for Markdown fields
+
+	// Request body size limits per route group and upload quotas
+	MaxRequestBodySize    int64  `mapstructure:"MAX_REQUEST_BODY_SIZE" validate:"gt=0"`     // JSON APIs
+	MaxUploadBodySize     int64  `mapstructure:"MAX_UPLOAD_BODY_SIZE" validate:"gt=0"`      // Image and audio uploads
+	MaxBackupUploadSize   int64  `mapstructure:"MAX_BACKUP_UPLOAD_SIZE" validate:"gt=0"`    // Admin backup uploads
+	UploadDailyQuotaBytes int64  `mapstructure:"UPLOAD_DAILY_QUOTA_BYTES" validate:"min=0"` // Per user, 0 disables
+	UploadDailyQuotaFiles int64  `mapstructure:"UPLOAD_DAILY_QUOTA_FILES" validate:"min=0"` // Per user, 0 disables
+	UploadQuotaBackend    string `mapstructure:"UPLOAD_QUOTA_BACKEND" validate:"oneof=database redis"`
+	UploadQuotaKeyPrefix  string `mapstructure:"UPLOAD_QUOTA_KEY_PREFIX"` // Redis key prefix of daily counters
+
+	// Approving uploaded backups out of quarantine requires a TOTP code
+	BackupApprovalRequireMFA bool `mapstructure:"BACKUP_APPROVAL_REQUIRE_MFA"`
 
 	// Database security
-	DBSSLMode         string `mapstructure:"DB_SSL_MODE"`
+	DBSSLMode         string `mapstructure:"DB_SSL_MODE" validate:"oneof=disable allow prefer require verify-ca verify-full"`
 	DBSSLCert         string `mapstructure:"DB_SSL_CERT"`
 	DBSSLKey          string `mapstructure:"DB_SSL_KEY"`
 	DBSSLRootCert     string `mapstructure:"DB_SSL_ROOT_CERT"`
 	DBAuditLogEnabled bool   `mapstructure:"DB_AUDIT_LOG_ENABLED"`
 
 	// Secrets management
-	MasterEncryptionKey    string `mapstructure:"MASTER_ENCRYPTION_KEY"`
-	EncryptionSalt         string `mapstructure:"ENCRYPTION_SALT"`
+	MasterEncryptionKey    string `mapstructure:"MASTER_ENCRYPTION_KEY" secret:"true"`
+	EncryptionSalt         string `mapstructure:"ENCRYPTION_SALT" secret:"true"`
 	SecretsRotationEnabled bool   `mapstructure:"SECRETS_ROTATION_ENABLED"`
 
 	// Security monitoring
 	SecurityMonitoringEnabled bool   `mapstructure:"SECURITY_MONITORING_ENABLED"`
 	SecurityAlertsEnabled     bool   `mapstructure:"SECURITY_ALERTS_ENABLED"`
-	SecurityLogLevel          string `mapstructure:"SECURITY_LOG_LEVEL"`
+	SecurityLogLevel          string `mapstructure:"SECURITY_LOG_LEVEL" validate:"oneof=debug info warn error"`
+
+	// Security event export to a SIEM
+	SecurityEventSink          string        `mapstructure:"SECURITY_EVENT_SINK" validate:"oneof=none syslog webhook kafka"`
+	SecurityEventSyslogNetwork string        `mapstructure:"SECURITY_EVENT_SYSLOG_NETWORK" validate:"oneof=udp tcp"`
+	SecurityEventSyslogAddress string        `mapstructure:"SECURITY_EVENT_SYSLOG_ADDRESS" validate:"required_if=SecurityEventSink syslog"`
+	SecurityEventWebhookURL    string        `mapstructure:"SECURITY_EVENT_WEBHOOK_URL" validate:"required_if=SecurityEventSink webhook,omitempty,url"`
+	SecurityEventWebhookToken  string        `mapstructure:"SECURITY_EVENT_WEBHOOK_TOKEN" secret:"true"`
+	SecurityEventKafkaRESTURL  string        `mapstructure:"SECURITY_EVENT_KAFKA_REST_URL" validate:"required_if=SecurityEventSink kafka,omitempty,url"` // Kafka REST Proxy base URL
+	SecurityEventKafkaTopic    string        `mapstructure:"SECURITY_EVENT_KAFKA_TOPIC"`
+	SecurityEventBufferSize    int           `mapstructure:"SECURITY_EVENT_BUFFER_SIZE" validate:"min=1"`
+	SecurityEventBatchSize     int           `mapstructure:"SECURITY_EVENT_BATCH_SIZE" validate:"min=1"`
+	SecurityEventFlushInterval time.Duration `mapstructure:"SECURITY_EVENT_FLUSH_INTERVAL" validate:"gt=0"`
+	SecurityEventMaxRetries    int           `mapstructure:"SECURITY_EVENT_MAX_RETRIES" validate:"min=0"`
+
+	// CAPTCHA challenge on auth endpoints after suspicious behavior
+	CaptchaEnabled           bool          `mapstructure:"CAPTCHA_ENABLED"`
+	CaptchaProvider          string        `mapstructure:"CAPTCHA_PROVIDER" validate:"oneof=hcaptcha turnstile"`
+	CaptchaSiteKey           string        `mapstructure:"CAPTCHA_SITE_KEY"`
+	CaptchaSecretKey         string        `mapstructure:"CAPTCHA_SECRET_KEY" secret:"true" validate:"required_if=CaptchaEnabled true"`
+	CaptchaFailureThreshold  int           `mapstructure:"CAPTCHA_FAILURE_THRESHOLD" validate:"min=0"`  // Failed logins before a challenge, 0 disables
+	CaptchaVelocityThreshold int           `mapstructure:"CAPTCHA_VELOCITY_THRESHOLD" validate:"min=0"` // Auth requests per IP before a challenge, 0 disables
+	CaptchaWindow            time.Duration `mapstructure:"CAPTCHA_WINDOW" validate:"gt=0"`
+
+	// Application-level encryption of PII columns (user emails and names)
+	PIIEncryptionEnabled bool   `mapstructure:"PII_ENCRYPTION_ENABLED"`
+	PIIEncryptionKeys    string `mapstructure:"PII_ENCRYPTION_KEYS" secret:"true" validate:"required_if=PIIEncryptionEnabled true"` // Comma-separated KEY_ID:HEX_KEY key encryption keys
+	PIIActiveKeyID       string `mapstructure:"PII_ACTIVE_KEY_ID" validate:"required_if=PIIEncryptionEnabled true"`                 // Key used to wrap new data keys
+	PIIBlindIndexKey     string `mapstructure:"PII_BLIND_INDEX_KEY" secret:"true" validate:"required_if=PIIEncryptionEnabled true"`
+
+	// Badge rules are evaluated on new activities at this interval (leader only)
+	AchievementEvalInterval time.Duration `mapstructure:"ACHIEVEMENT_EVAL_INTERVAL" validate:"gt=0"`
+
+	// Days of the premium role granted to a referrer per converted referral, 0 disables rewards
+	ReferralRewardDays int32 `mapstructure:"REFERRAL_REWARD_DAYS" validate:"min=0,max=365"`
+
+	// Subscriptions and billing webhooks. A provider's webhook is disabled until its secret is set.
+	StripeWebhookSecret      string        `mapstructure:"STRIPE_WEBHOOK_SECRET" secret:"true"`
+	GooglePlayWebhookToken   string        `mapstructure:"GOOGLE_PLAY_WEBHOOK_TOKEN" secret:"true"` // Token in the Pub/Sub push endpoint URL
+	AppStoreRootCertPath     string        `mapstructure:"APP_STORE_ROOT_CERT_PATH"`                // Apple root CA used to verify App Store notifications
+	FreeAIScoresPerDay       int32         `mapstructure:"FREE_AI_SCORES_PER_DAY" validate:"min=0"` // AI scorings per day without unlimited_ai_scoring
+	BillingReconcileInterval time.Duration `mapstructure:"BILLING_RECONCILE_INTERVAL" validate:"gt=0"`
+
+	// External dictionary used to fill missing definitions, IPA and audio of words
+	DictionaryAPIURL            string        `mapstructure:"DICTIONARY_API_URL" validate:"omitempty,url"` // Empty disables dictionary lookups
+	DictionaryRequestsPerMinute int           `mapstructure:"DICTIONARY_REQUESTS_PER_MINUTE" validate:"gt=0"`
+	DictionaryCacheTTL          time.Duration `mapstructure:"DICTIONARY_CACHE_TTL"`
+	DictionaryRetryFailedAfter  time.Duration `mapstructure:"DICTIONARY_RETRY_FAILED_AFTER"`
+
+	// Text-to-speech for short phrases, synthesized with OPENAI_API_KEY and cached in media storage
+	TTSAPIURL        string `mapstructure:"TTS_API_URL" validate:"omitempty,url"` // Empty disables text-to-speech
+	TTSModel         string `mapstructure:"TTS_MODEL"`
+	TTSDefaultVoice  string `mapstructure:"TTS_DEFAULT_VOICE"`
+	TTSMaxTextLength int    `mapstructure:"TTS_MAX_TEXT_LENGTH" validate:"gt=0"`
+
+	// LTI 1.3 tool for LMS launches, grade passback and roster sync
+	LTIPrivateKeyPath    string `mapstructure:"LTI_PRIVATE_KEY_PATH"`                             // Empty disables LTI
+	LTILaunchRedirectURL string `mapstructure:"LTI_LAUNCH_REDIRECT_URL" validate:"omitempty,url"` // Web app page that receives the session of a launch
+
+	// Social login with Google and Apple accounts
+	OAuthGoogleClientIDs     string `mapstructure:"OAUTH_GOOGLE_CLIENT_IDS"`                  // Comma-separated web and mobile client IDs; empty disables Google sign-in
+	OAuthGoogleClientSecret  string `mapstructure:"OAUTH_GOOGLE_CLIENT_SECRET" secret:"true"` // Exchanges authorization codes for the first client ID; empty accepts ID tokens only
+	OAuthAppleClientIDs      string `mapstructure:"OAUTH_APPLE_CLIENT_IDS"`                   // Comma-separated services and bundle IDs; empty disables Apple sign-in
+	OAuthAppleTeamID         string `mapstructure:"OAUTH_APPLE_TEAM_ID" validate:"required_with=OAuthApplePrivateKeyPath"`
+	OAuthAppleKeyID          string `mapstructure:"OAUTH_APPLE_KEY_ID" validate:"required_with=OAuthApplePrivateKeyPath"`
+	OAuthApplePrivateKeyPath string `mapstructure:"OAUTH_APPLE_PRIVATE_KEY_PATH"` // Sign in with Apple key (.p8) signing client secrets; empty accepts ID tokens only
+
+	// Public read-only API for third-party tools
+	PublicAPIEnabled           bool `mapstructure:"PUBLIC_API_ENABLED"`
+	PublicAPIDailyQuota        int  `mapstructure:"PUBLIC_API_DAILY_QUOTA" validate:"gt=0"`         // Default daily quota of new keys
+	PublicAPIRequestsPerMinute int  `mapstructure:"PUBLIC_API_REQUESTS_PER_MINUTE" validate:"gt=0"` // Default per-minute limit of new keys
+	PublicAPICacheMaxAge       int  `mapstructure:"PUBLIC_API_CACHE_MAX_AGE" validate:"gte=0"`      // Seconds responses may be cached by clients and CDNs
+
+	// Readiness probe
+	ReadinessRequired    string        `mapstructure:"READINESS_REQUIRED"`                       // Comma-separated dependencies that must be up to receive traffic
+	ReadinessGracePeriod time.Duration `mapstructure:"READINESS_GRACE_PERIOD" validate:"gte=0"`  // How long a required dependency may be down before the instance is taken out of rotation
+	ReadinessCacheMaxAge time.Duration `mapstructure:"READINESS_CACHE_MAX_AGE" validate:"gte=0"` // How long a dependency check is reused by the readiness probe
+
+	// Related content
+	RelatedContentRebuildInterval time.Duration `mapstructure:"RELATED_CONTENT_REBUILD_INTERVAL" validate:"gt=0"` // How often links between words, grammar, examples and questions are recomputed
+
+	// Search suggestions
+	SearchSuggestCacheTTL time.Duration `mapstructure:"SEARCH_SUGGEST_CACHE_TTL" validate:"gte=0"` // How long suggestions for a query are cached; 0 disables caching
+
+	// Saved filters
+	SavedFiltersPerUser int `mapstructure:"SAVED_FILTERS_PER_USER" validate:"gte=0"` // Filters a user may save; 0 means no limit
+
+	// Account lockout
+	AccountLockoutThreshold int           `mapstructure:"ACCOUNT_LOCKOUT_THRESHOLD" validate:"gte=0"` // Failed sign-ins that lock an account; 0 disables
+	AccountLockoutDuration  time.Duration `mapstructure:"ACCOUNT_LOCKOUT_DURATION" validate:"gt=0"`
+
+	// Data access log
+	DataAccessLogRetentionDays int           `mapstructure:"DATA_ACCESS_LOG_RETENTION_DAYS" validate:"gte=0"` // How long reads of personal data by other users are kept; 0 keeps them forever
+	DataAccessLogPruneInterval time.Duration `mapstructure:"DATA_ACCESS_LOG_PRUNE_INTERVAL" validate:"gt=0"`
+
+	// Data retention
+	RetentionSpeakingAudioDays int           `mapstructure:"RETENTION_SPEAKING_AUDIO_DAYS" validate:"gte=0"` // Days speaking recordings are kept; 0 keeps them forever
+	RetentionAIFeedbackDays    int           `mapstructure:"RETENTION_AI_FEEDBACK_DAYS" validate:"gte=0"`    // Days raw AI feedback is kept; scores are kept forever
+	RetentionLogDays           int           `mapstructure:"RETENTION_LOG_DAYS" validate:"gte=0"`            // Days daily log files are kept
+	RetentionLogDir            string        `mapstructure:"RETENTION_LOG_DIR"`
+	RetentionPurgeInterval     time.Duration `mapstructure:"RETENTION_PURGE_INTERVAL" validate:"gt=0"`
+	RetentionDryRun            bool          `mapstructure:"RETENTION_DRY_RUN"` // Scheduled purges only log what they would remove
+
+	// Vocabulary links
+	WordLinkCacheTTL time.Duration `mapstructure:"WORD_LINK_CACHE_TTL" validate:"gt=0"` // How long the dictionary used to link words in reviews is kept
+
+	// Listening playback
+	ListeningMaxPlays int           `mapstructure:"LISTENING_MAX_PLAYS" validate:"gt=0"` // Plays of each question's audio per exam attempt
+	ListeningTokenTTL time.Duration `mapstructure:"LISTENING_TOKEN_TTL" validate:"gt=0"` // How long a playback token can be redeemed
+	ListeningURLTTL   time.Duration `mapstructure:"LISTENING_URL_TTL" validate:"gt=0"`   // How long a signed audio URL works
+
+	// Analytics warehouse export of the activity log (leader only)
+	WarehouseExportEnabled  bool          `mapstructure:"WAREHOUSE_EXPORT_ENABLED"`
+	WarehouseBackend        string        `mapstructure:"WAREHOUSE_BACKEND" validate:"oneof=clickhouse bigquery"`
+	WarehouseTable          string        `mapstructure:"WAREHOUSE_TABLE" validate:"required"`
+	WarehouseExportInterval time.Duration `mapstructure:"WAREHOUSE_EXPORT_INTERVAL" validate:"gt=0"`
+	WarehouseBatchSize      int           `mapstructure:"WAREHOUSE_BATCH_SIZE" validate:"gt=0"`
+	WarehouseHashKey        string        `mapstructure:"WAREHOUSE_HASH_KEY" secret:"true" validate:"required_if=WarehouseExportEnabled true"` // Key of the hashes that replace user IDs
+	WarehousePIIDropFields  string        `mapstructure:"WAREHOUSE_PII_DROP_FIELDS"`                                                           // Comma-separated payload fields removed before export
+	WarehousePIIHashFields  string        `mapstructure:"WAREHOUSE_PII_HASH_FIELDS"`                                                           // Comma-separated payload fields replaced by their hash
+	ClickHouseURL           string        `mapstructure:"CLICKHOUSE_URL" validate:"omitempty,url"`
+	ClickHouseDatabase      string        `mapstructure:"CLICKHOUSE_DATABASE"`
+	ClickHouseUser          string        `mapstructure:"CLICKHOUSE_USER"`
+	ClickHousePassword      string        `mapstructure:"CLICKHOUSE_PASSWORD" secret:"true"`
+	BigQueryProject         string        `mapstructure:"BIGQUERY_PROJECT"`
+	BigQueryDataset         string        `mapstructure:"BIGQUERY_DATASET"`
+	BigQueryCredentialsFile string        `mapstructure:"BIGQUERY_CREDENTIALS_FILE"` // Service account key file
+
+	// Exam proctoring
+	ProctoringEnabled             bool          `mapstructure:"PROCTORING_ENABLED"`
+	ProctoringFolder              string        `mapstructure:"PROCTORING_FOLDER" validate:"required"`                // Private media folder of the snapshots
+	ProctoringPolicyVersion       string        `mapstructure:"PROCTORING_POLICY_VERSION" validate:"required,max=20"` // Version of the notice learners consent to
+	ProctoringCaptureInterval     time.Duration `mapstructure:"PROCTORING_CAPTURE_INTERVAL" validate:"gt=0"`          // How often clients capture a snapshot
+	ProctoringMaxPhotoBytes       int64         `mapstructure:"PROCTORING_MAX_PHOTO_BYTES" validate:"gt=0"`
+	ProctoringMaxPhotosPerAttempt int64         `mapstructure:"PROCTORING_MAX_PHOTOS_PER_ATTEMPT" validate:"gt=0"`
+	ProctoringURLTTL              time.Duration `mapstructure:"PROCTORING_URL_TTL" validate:"gt=0"`                // How long a signed snapshot URL works
+	ProctoringRetentionDays       int           `mapstructure:"PROCTORING_RETENTION_DAYS" validate:"gte=1,lte=90"` // Snapshots cannot be kept forever
+
+	// Writing and speaking portfolio
+	PortfolioMaxItems         int32 `mapstructure:"PORTFOLIO_MAX_ITEMS" validate:"gte=1,lte=50"`      // Best writings and speaking sessions shown
+	PortfolioHistoryWeeks     int   `mapstructure:"PORTFOLIO_HISTORY_WEEKS" validate:"gte=1,lte=104"` // How far back score progression goes
+	PortfolioShareDefaultDays int   `mapstructure:"PORTFOLIO_SHARE_DEFAULT_DAYS" validate:"gte=1,ltefield=PortfolioShareMaxDays"`
+	PortfolioShareMaxDays     int   `mapstructure:"PORTFOLIO_SHARE_MAX_DAYS" validate:"gte=1,lte=365"` // Share links always expire
+	PortfolioMaxActiveLinks   int64 `mapstructure:"PORTFOLIO_MAX_ACTIVE_LINKS" validate:"gte=1"`
+
+	// Question difficulty calibration
+	CalibrationInterval     time.Duration `mapstructure:"CALIBRATION_INTERVAL" validate:"gt=0"`       // How often question difficulty is recomputed from answers
+	CalibrationMinResponses int32         `mapstructure:"CALIBRATION_MIN_RESPONSES" validate:"gte=1"` // Learners who must have answered a question first
+
+	// Predicted scores
+	PredictionInterval   time.Duration `mapstructure:"PREDICTION_INTERVAL" validate:"gt=0"`     // How often every predicted score is recomputed
+	PredictionMinAnswers int           `mapstructure:"PREDICTION_MIN_ANSWERS" validate:"gte=1"` // Questions a learner must have answered first
+	PredictionMaxAnswers int32         `mapstructure:"PREDICTION_MAX_ANSWERS" validate:"gte=1"` // Latest answers a prediction is based on
+
+	// Speaking grading queue
+	GradingClaimTTL time.Duration `mapstructure:"GRADING_CLAIM_TTL" validate:"gt=0"` // How long a teacher keeps a claimed turn before others can take it
+
+	// Question duplicate detection
+	QuestionDuplicateMinSimilarity int   `mapstructure:"QUESTION_DUPLICATE_MIN_SIMILARITY" validate:"gte=30,lte=100"` // Percent; the trigram index only finds matches of 30 or more
+	QuestionDuplicateMaxCandidates int32 `mapstructure:"QUESTION_DUPLICATE_MAX_CANDIDATES" validate:"gte=1,lte=50"`   // Matches returned when a question is created
+
+	// Targeted upgrade rollouts
+	UpgradeDeviceActiveDays int `mapstructure:"UPGRADE_DEVICE_ACTIVE_DAYS" validate:"gte=1"` // Devices not registered for longer are left out of rollouts
+
+	// Background jobs
+	JobsDir      string        `mapstructure:"JOBS_DIR"`                       // Where the files jobs produce are kept
+	JobWorkers   int           `mapstructure:"JOB_WORKERS" validate:"gte=1"`   // Jobs run at the same time on each instance
+	JobResultTTL time.Duration `mapstructure:"JOB_RESULT_TTL" validate:"gt=0"` // How long job results can be downloaded
+
+	// Dependency graph
+	DependencyCheckInterval time.Duration `mapstructure:"DEPENDENCY_CHECK_INTERVAL" validate:"gt=0"`    // How often dependencies are probed
+	DependencyProbeTimeout  time.Duration `mapstructure:"DEPENDENCY_PROBE_TIMEOUT" validate:"gt=0"`     // Longest a single probe may take
+	DependencySlowThreshold time.Duration `mapstructure:"DEPENDENCY_SLOW_THRESHOLD_MS" validate:"gt=0"` // Average probe latency above which a dependency is degraded
+
+	// Draining
+	DrainTimeout time.Duration `mapstructure:"DRAIN_TIMEOUT" validate:"gt=0"` // Time in-flight requests and queued jobs get to finish when draining
+
+	// Read-only mode
+	ReadOnlyMode            bool          `mapstructure:"READ_ONLY_MODE"`                                // Refuse writes until unset (reloadable)
+	ReadOnlyBackend         string        `mapstructure:"READ_ONLY_BACKEND" validate:"oneof=file redis"` // Where the runtime switch is shared
+	ReadOnlyStateFile       string        `mapstructure:"READ_ONLY_STATE_FILE"`                          // State file of the file backend
+	ReadOnlyRefreshInterval time.Duration `mapstructure:"READ_ONLY_REFRESH_INTERVAL" validate:"gt=0"`    // How often instances read the switch
+	ReadOnlyRetryAfter      time.Duration `mapstructure:"READ_ONLY_RETRY_AFTER" validate:"gt=0"`         // Retry-After sent with refused writes
 }
 
 // LoadEnv loads environment variables from .env file
@@ -181,10 +433,21 @@ func GetEnvAsBool(key string, defaultValue bool) bool {
 	return value
 }
 
-// DefaultConfig returns configuration from environment variables
+// DefaultConfig returns configuration from the config file (CONFIG_FILE) and
+// environment variables. The process exits if the configuration is invalid.
 func DefaultConfig() Config {
 	// Load environment variables from .env file
 	LoadEnv()
+
+	cfg, err := Load(LoaderOptions{ConfigFile: GetEnv("CONFIG_FILE", "")})
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	return cfg
+}
+
+// buildConfig reads every setting from the environment, applying defaults
+func buildConfig() Config {
 	// Get database configuration
 	dbDriver := GetEnv("DB_DRIVER", "postgres")
 	dbHost := GetEnv("DB_HOST", "localhost")
@@ -213,10 +476,6 @@ func DefaultConfig() Config {
 	// Get Cloudinary configuration
 	cloudinaryURL := GetEnv("CLOUDINARY_URL", "")
 
-	if cloudinaryURL == "" {
-		log.Fatal("CLOUDINARY_URL environment variable is required")
-	}
-
 	// Get security configuration
 	disableHTTPSRedirect := GetEnvAsBool("DISABLE_HTTPS_REDIRECT", false)
 
@@ -231,11 +490,23 @@ func DefaultConfig() Config {
 	rateLimitRequests := int(GetEnvAsInt("RATE_LIMIT_REQUESTS", 10))                              // 10 reqs/sec by default
 	rateLimitBurst := int(GetEnvAsInt("RATE_LIMIT_BURST", 20))                                    // 20 burst by default
 	rateLimitExpiresIn := time.Duration(GetEnvAsInt("RATE_LIMIT_EXPIRES_IN", 3600)) * time.Second // 1 hour by default
+	rateLimitBackend := GetEnv("RATE_LIMIT_BACKEND", "memory")
+	rateLimitKeyPrefix := GetEnv("RATE_LIMIT_KEY_PREFIX", "toeic:ratelimit:")
 	// Get auth rate limiting configuration
 	authRateLimitEnabled := GetEnv("AUTH_RATE_LIMIT_ENABLED", "true") == "true"
 	authRateLimitRequests := int(GetEnvAsInt("AUTH_RATE_LIMIT_REQUESTS", 3)) // 3 reqs/sec by default (more restricted)
 	authRateLimitBurst := int(GetEnvAsInt("AUTH_RATE_LIMIT_BURST", 5))       // 5 burst by default	// Get CORS configuration
+	appEnv := GetEnv("APP_ENV", "development")
 	corsAllowedOrigins := GetEnv("CORS_ALLOWED_ORIGINS", "http://localhost:3000,http://localhost:8080,http://192.168.31.37:8000,flutter-app://toeic-app")
+	corsAllowedOriginsDevelopment := GetEnv("CORS_ALLOWED_ORIGINS_DEVELOPMENT", "http://localhost:8000,http://127.0.0.1:8000")
+	corsAllowedOriginsStaging := GetEnv("CORS_ALLOWED_ORIGINS_STAGING", "")
+	corsAllowedOriginsProduction := GetEnv("CORS_ALLOWED_ORIGINS_PRODUCTION", "")
+	corsMaxAge := time.Duration(GetEnvAsInt("CORS_MAX_AGE", 600)) * time.Second // 10 minutes by default
+
+	// Get feature flags configuration
+	featureFlags := GetEnv("FEATURE_FLAGS", "")
+	clientMinAppVersion := GetEnv("CLIENT_MIN_APP_VERSION", "")
+	clientMaintenanceNotice := GetEnv("CLIENT_MAINTENANCE_NOTICE", "")
 
 	// Get cache configuration
 	cacheEnabled := GetEnv("CACHE_ENABLED", "true") == "true"
@@ -274,10 +545,23 @@ func DefaultConfig() Config {
 	circuitBreakerThreshold := int(GetEnvAsInt("CIRCUIT_BREAKER_THRESHOLD", 10))
 	requestTimeoutSeconds := int(GetEnvAsInt("REQUEST_TIMEOUT_SECONDS", 30))
 	healthCheckInterval := int(GetEnvAsInt("HEALTH_CHECK_INTERVAL", 30))
+	// Get leader election configuration
+	instanceID := GetEnv("INSTANCE_ID", "")
+	leaderElectionEnabled := GetEnv("LEADER_ELECTION_ENABLED", "false") == "true"
+	leaderElectionBackend := GetEnv("LEADER_ELECTION_BACKEND", "postgres")
+	leaderElectionLockID := GetEnvAsInt("LEADER_ELECTION_LOCK_ID", 0x746f656963) // "toeic" in ASCII
+	leaderElectionKey := GetEnv("LEADER_ELECTION_KEY", "toeic:leader")
+	leaderElectionTTL := time.Duration(GetEnvAsInt("LEADER_ELECTION_TTL", 15)) * time.Second
 	// Get analyze service configuration
 	analyzeServiceEnabled := GetEnv("ANALYZE_SERVICE_ENABLED", "true") == "true"
 	analyzeServiceURL := GetEnv("ANALYZE_SERVICE_URL", "http://localhost:9000")
 	analyzeServiceTimeout := time.Duration(GetEnvAsInt("ANALYZE_SERVICE_TIMEOUT", 30)) * time.Second
+	analyzeAttemptTimeout := time.Duration(GetEnvAsInt("ANALYZE_SERVICE_ATTEMPT_TIMEOUT", 10)) * time.Second
+	analyzeMaxRetries := int(GetEnvAsInt("ANALYZE_SERVICE_MAX_RETRIES", 2))
+	analyzeRetryBackoff := time.Duration(GetEnvAsInt("ANALYZE_SERVICE_RETRY_BACKOFF_MS", 200)) * time.Millisecond
+	analyzeHedgeDelay := time.Duration(GetEnvAsInt("ANALYZE_SERVICE_HEDGE_DELAY_MS", 0)) * time.Millisecond
+	analyzeMaxConns := int(GetEnvAsInt("ANALYZE_SERVICE_MAX_CONNS", 32))
+	analyzeDegradedMode := GetEnvAsBool("ANALYZE_SERVICE_DEGRADED_MODE", true)
 
 	// Get OpenAI AI Scoring configuration
 	openAIAPIKey := GetEnv("OPENAI_API_KEY", "")
@@ -285,15 +569,49 @@ func DefaultConfig() Config {
 	openAIModel := GetEnv("OPENAI_MODEL", "gpt-4")
 	openAITimeout := time.Duration(GetEnvAsInt("OPENAI_TIMEOUT", 60)) * time.Second
 
+	// AI submission limits
+	aiMaxWritingWords := int(GetEnvAsInt("AI_MAX_WRITING_WORDS", 1000))
+	aiMaxWritingChars := int(GetEnvAsInt("AI_MAX_WRITING_CHARS", 8000))
+	aiMaxSpeakingWords := int(GetEnvAsInt("AI_MAX_SPEAKING_WORDS", 200))
+	aiMaxSpeakingChars := int(GetEnvAsInt("AI_MAX_SPEAKING_CHARS", 1500))
+	aiMaxContextChars := int(GetEnvAsInt("AI_MAX_CONTEXT_CHARS", 6000))
+	aiOversizeMode := GetEnv("AI_OVERSIZE_MODE", "reject")
+	aiRateLimitPerMinute := int(GetEnvAsInt("AI_RATE_LIMIT_PER_MINUTE", 5))
+	aiRateLimitPerHour := int(GetEnvAsInt("AI_RATE_LIMIT_PER_HOUR", 60))
+
+	// Get HTTP server configuration
+	serverReadTimeout := time.Duration(GetEnvAsInt("SERVER_READ_TIMEOUT", 10)) * time.Second
+	serverReadHeaderTimeout := time.Duration(GetEnvAsInt("SERVER_READ_HEADER_TIMEOUT", 5)) * time.Second
+	serverWriteTimeout := time.Duration(GetEnvAsInt("SERVER_WRITE_TIMEOUT", 15)) * time.Second
+	serverIdleTimeout := time.Duration(GetEnvAsInt("SERVER_IDLE_TIMEOUT", 120)) * time.Second
+	serverMaxHeaderBytes := int(GetEnvAsInt("SERVER_MAX_HEADER_BYTES", 1<<20)) // 1MB by default
+
 	// Get security configuration
 	tlsEnabled := GetEnv("TLS_ENABLED", "false") == "true"
 	tlsCertFile := GetEnv("TLS_CERT_FILE", "")
 	tlsKeyFile := GetEnv("TLS_KEY_FILE", "")
+	tlsAutocertEnabled := GetEnv("TLS_AUTOCERT_ENABLED", "false") == "true"
+	tlsAutocertDomains := GetEnv("TLS_AUTOCERT_DOMAINS", "")
+	tlsAutocertCacheDir := GetEnv("TLS_AUTOCERT_CACHE_DIR", "./certs")
 	http2Enabled := GetEnv("HTTP2_ENABLED", "false") == "true"
 	http2MaxStreams := uint32(GetEnvAsInt("HTTP2_MAX_STREAMS", 250))
 	http2IdleTimeout := GetEnvAsInt("HTTP2_IDLE_TIMEOUT", 120)
 	securityHeadersEnabled := GetEnv("SECURITY_HEADERS_ENABLED", "true") == "true"
 	inputValidationEnabled := GetEnv("INPUT_VALIDATION_ENABLED", "true") == "true"
+	requestSigningMode := GetEnv("REQUEST_SIGNING_MODE", "off")
+	requestSigningKeys := GetEnv("REQUEST_SIGNING_KEYS", "")
+	sanitizeEnabled := GetEnv("SANITIZE_ENABLED", "true") == "true"
+	sanitizeRichTextPolicy := GetEnv("SANITIZE_RICH_TEXT_POLICY", "ugc")
+	sanitizeMarkdownPolicy := GetEnv("SANITIZE_MARKDOWN_POLICY", "basic")
+
+	maxRequestBodySize := GetEnvAsInt("MAX_REQUEST_BODY_SIZE", 1*1024*1024)
+	maxUploadBodySize := GetEnvAsInt("MAX_UPLOAD_BODY_SIZE", 50*1024*1024)
+	maxBackupUploadSize := GetEnvAsInt("MAX_BACKUP_UPLOAD_SIZE", 1024*1024*1024)
+	backupApprovalRequireMFA := GetEnvAsBool("BACKUP_APPROVAL_REQUIRE_MFA", true)
+	uploadDailyQuotaBytes := GetEnvAsInt("UPLOAD_DAILY_QUOTA_BYTES", 200*1024*1024)
+	uploadDailyQuotaFiles := GetEnvAsInt("UPLOAD_DAILY_QUOTA_FILES", 100)
+	uploadQuotaBackend := GetEnv("UPLOAD_QUOTA_BACKEND", "database")
+	uploadQuotaKeyPrefix := GetEnv("UPLOAD_QUOTA_KEY_PREFIX", "toeic:uploadquota:")
 
 	// Get database security configuration
 	dbSSLMode := GetEnv("DB_SSL_MODE", "prefer")
@@ -312,6 +630,164 @@ func DefaultConfig() Config {
 	securityAlertsEnabled := GetEnv("SECURITY_ALERTS_ENABLED", "true") == "true"
 	securityLogLevel := GetEnv("SECURITY_LOG_LEVEL", "info")
 
+	// Security event export
+	securityEventSink := GetEnv("SECURITY_EVENT_SINK", "none")
+	securityEventSyslogNetwork := GetEnv("SECURITY_EVENT_SYSLOG_NETWORK", "udp")
+	securityEventSyslogAddress := GetEnv("SECURITY_EVENT_SYSLOG_ADDRESS", "")
+	securityEventWebhookURL := GetEnv("SECURITY_EVENT_WEBHOOK_URL", "")
+	securityEventWebhookToken := GetEnv("SECURITY_EVENT_WEBHOOK_TOKEN", "")
+	securityEventKafkaRESTURL := GetEnv("SECURITY_EVENT_KAFKA_REST_URL", "")
+	securityEventKafkaTopic := GetEnv("SECURITY_EVENT_KAFKA_TOPIC", "security-events")
+	securityEventBufferSize := int(GetEnvAsInt("SECURITY_EVENT_BUFFER_SIZE", 1000))
+	securityEventBatchSize := int(GetEnvAsInt("SECURITY_EVENT_BATCH_SIZE", 100))
+	securityEventFlushInterval := time.Duration(GetEnvAsInt("SECURITY_EVENT_FLUSH_INTERVAL", 5)) * time.Second
+	securityEventMaxRetries := int(GetEnvAsInt("SECURITY_EVENT_MAX_RETRIES", 3))
+
+	// Get CAPTCHA configuration
+	captchaEnabled := GetEnvAsBool("CAPTCHA_ENABLED", false)
+	captchaProvider := GetEnv("CAPTCHA_PROVIDER", "turnstile")
+	captchaSiteKey := GetEnv("CAPTCHA_SITE_KEY", "")
+	captchaSecretKey := GetEnv("CAPTCHA_SECRET_KEY", "")
+	captchaFailureThreshold := int(GetEnvAsInt("CAPTCHA_FAILURE_THRESHOLD", 3))
+	captchaVelocityThreshold := int(GetEnvAsInt("CAPTCHA_VELOCITY_THRESHOLD", 20))
+	captchaWindow := time.Duration(GetEnvAsInt("CAPTCHA_WINDOW", 900)) * time.Second
+
+	// PII encryption
+	piiEncryptionEnabled := GetEnvAsBool("PII_ENCRYPTION_ENABLED", false)
+	piiEncryptionKeys := GetEnv("PII_ENCRYPTION_KEYS", "")
+	piiActiveKeyID := GetEnv("PII_ACTIVE_KEY_ID", "")
+	piiBlindIndexKey := GetEnv("PII_BLIND_INDEX_KEY", "")
+
+	// Achievements
+	achievementEvalInterval := time.Duration(GetEnvAsInt("ACHIEVEMENT_EVAL_INTERVAL", 30)) * time.Second
+
+	// Referrals
+	referralRewardDays := int32(GetEnvAsInt("REFERRAL_REWARD_DAYS", 7))
+
+	// Subscriptions
+	stripeWebhookSecret := GetEnv("STRIPE_WEBHOOK_SECRET", "")
+	googlePlayWebhookToken := GetEnv("GOOGLE_PLAY_WEBHOOK_TOKEN", "")
+	appStoreRootCertPath := GetEnv("APP_STORE_ROOT_CERT_PATH", "")
+	freeAIScoresPerDay := int32(GetEnvAsInt("FREE_AI_SCORES_PER_DAY", 3))
+	billingReconcileInterval := time.Duration(GetEnvAsInt("BILLING_RECONCILE_INTERVAL", 3600)) * time.Second
+
+	// External dictionary
+	dictionaryAPIURL := GetEnv("DICTIONARY_API_URL", "https://api.dictionaryapi.dev/api/v2/entries/en")
+	dictionaryRequestsPerMinute := int(GetEnvAsInt("DICTIONARY_REQUESTS_PER_MINUTE", 60))
+	dictionaryCacheTTL := time.Duration(GetEnvAsInt("DICTIONARY_CACHE_TTL", 720)) * time.Hour
+	dictionaryRetryFailedAfter := time.Duration(GetEnvAsInt("DICTIONARY_RETRY_FAILED_AFTER", 24)) * time.Hour
+
+	// Text-to-speech
+	ttsAPIURL := GetEnv("TTS_API_URL", "https://api.openai.com/v1/audio/speech")
+	ttsModel := GetEnv("TTS_MODEL", "tts-1")
+	ttsDefaultVoice := GetEnv("TTS_DEFAULT_VOICE", "alloy")
+	ttsMaxTextLength := int(GetEnvAsInt("TTS_MAX_TEXT_LENGTH", 300))
+
+	// LTI 1.3
+	ltiPrivateKeyPath := GetEnv("LTI_PRIVATE_KEY_PATH", "")
+	ltiLaunchRedirectURL := GetEnv("LTI_LAUNCH_REDIRECT_URL", "")
+
+	// Social login
+	oauthGoogleClientIDs := GetEnv("OAUTH_GOOGLE_CLIENT_IDS", "")
+	oauthGoogleClientSecret := GetEnv("OAUTH_GOOGLE_CLIENT_SECRET", "")
+	oauthAppleClientIDs := GetEnv("OAUTH_APPLE_CLIENT_IDS", "")
+	oauthAppleTeamID := GetEnv("OAUTH_APPLE_TEAM_ID", "")
+	oauthAppleKeyID := GetEnv("OAUTH_APPLE_KEY_ID", "")
+	oauthApplePrivateKeyPath := GetEnv("OAUTH_APPLE_PRIVATE_KEY_PATH", "")
+
+	// Public API
+	publicAPIEnabled := GetEnvAsBool("PUBLIC_API_ENABLED", true)
+	publicAPIDailyQuota := int(GetEnvAsInt("PUBLIC_API_DAILY_QUOTA", 10000))
+	publicAPIRequestsPerMinute := int(GetEnvAsInt("PUBLIC_API_REQUESTS_PER_MINUTE", 60))
+	publicAPICacheMaxAge := int(GetEnvAsInt("PUBLIC_API_CACHE_MAX_AGE", 3600))
+
+	// Readiness probe
+	readinessRequired := GetEnv("READINESS_REQUIRED", "database,migrations,cache")
+	readinessGracePeriod := time.Duration(GetEnvAsInt("READINESS_GRACE_PERIOD", 15)) * time.Second
+	readinessCacheMaxAge := time.Duration(GetEnvAsInt("READINESS_CACHE_MAX_AGE", 2)) * time.Second
+
+	// Related content
+	relatedContentRebuildInterval := time.Duration(GetEnvAsInt("RELATED_CONTENT_REBUILD_INTERVAL", 21600)) * time.Second
+	searchSuggestCacheTTL := time.Duration(GetEnvAsInt("SEARCH_SUGGEST_CACHE_TTL", 300)) * time.Second
+	savedFiltersPerUser := int(GetEnvAsInt("SAVED_FILTERS_PER_USER", 25))
+	accountLockoutThreshold := int(GetEnvAsInt("ACCOUNT_LOCKOUT_THRESHOLD", 10))
+	accountLockoutDuration := time.Duration(GetEnvAsInt("ACCOUNT_LOCKOUT_DURATION", 900)) * time.Second
+	dataAccessLogRetentionDays := int(GetEnvAsInt("DATA_ACCESS_LOG_RETENTION_DAYS", 365))
+	dataAccessLogPruneInterval := time.Duration(GetEnvAsInt("DATA_ACCESS_LOG_PRUNE_INTERVAL", 86400)) * time.Second
+
+	// Data retention
+	retentionSpeakingAudioDays := int(GetEnvAsInt("RETENTION_SPEAKING_AUDIO_DAYS", 90))
+	retentionAIFeedbackDays := int(GetEnvAsInt("RETENTION_AI_FEEDBACK_DAYS", 365))
+	retentionLogDays := int(GetEnvAsInt("RETENTION_LOG_DAYS", 30))
+	retentionLogDir := GetEnv("RETENTION_LOG_DIR", "logs")
+	retentionPurgeInterval := time.Duration(GetEnvAsInt("RETENTION_PURGE_INTERVAL", 86400)) * time.Second
+	retentionDryRun := GetEnvAsBool("RETENTION_DRY_RUN", false)
+
+	// Vocabulary links
+	wordLinkCacheTTL := time.Duration(GetEnvAsInt("WORD_LINK_CACHE_TTL", 600)) * time.Second
+
+	// Listening playback
+	listeningMaxPlays := int(GetEnvAsInt("LISTENING_MAX_PLAYS", 1))
+	listeningTokenTTL := time.Duration(GetEnvAsInt("LISTENING_TOKEN_TTL", 1800)) * time.Second
+	listeningURLTTL := time.Duration(GetEnvAsInt("LISTENING_URL_TTL", 120)) * time.Second
+
+	// Analytics warehouse export
+	warehouseExportEnabled := GetEnvAsBool("WAREHOUSE_EXPORT_ENABLED", false)
+	warehouseBackend := GetEnv("WAREHOUSE_BACKEND", "clickhouse")
+	warehouseTable := GetEnv("WAREHOUSE_TABLE", "product_events")
+	warehouseExportInterval := time.Duration(GetEnvAsInt("WAREHOUSE_EXPORT_INTERVAL", 300)) * time.Second
+	warehouseBatchSize := int(GetEnvAsInt("WAREHOUSE_BATCH_SIZE", 1000))
+	warehouseHashKey := GetEnv("WAREHOUSE_HASH_KEY", "")
+	warehousePIIDropFields := GetEnv("WAREHOUSE_PII_DROP_FIELDS", "email,phone,name,full_name,username,ip,ip_address,address")
+	warehousePIIHashFields := GetEnv("WAREHOUSE_PII_HASH_FIELDS", "user_id,follower_id,followee_id")
+	clickHouseURL := GetEnv("CLICKHOUSE_URL", "http://localhost:8123")
+	clickHouseDatabase := GetEnv("CLICKHOUSE_DATABASE", "analytics")
+	clickHouseUser := GetEnv("CLICKHOUSE_USER", "")
+	clickHousePassword := GetEnv("CLICKHOUSE_PASSWORD", "")
+	bigQueryProject := GetEnv("BIGQUERY_PROJECT", "")
+	bigQueryDataset := GetEnv("BIGQUERY_DATASET", "analytics")
+	bigQueryCredentialsFile := GetEnv("BIGQUERY_CREDENTIALS_FILE", "")
+
+	// Exam proctoring
+	proctoringEnabled := GetEnvAsBool("PROCTORING_ENABLED", false)
+	proctoringFolder := GetEnv("PROCTORING_FOLDER", "proctoring")
+	proctoringPolicyVersion := GetEnv("PROCTORING_POLICY_VERSION", "2026-10")
+	proctoringCaptureInterval := time.Duration(GetEnvAsInt("PROCTORING_CAPTURE_INTERVAL", 60)) * time.Second
+	proctoringMaxPhotoBytes := GetEnvAsInt("PROCTORING_MAX_PHOTO_BYTES", 512*1024)
+	proctoringMaxPhotosPerAttempt := GetEnvAsInt("PROCTORING_MAX_PHOTOS_PER_ATTEMPT", 300)
+	proctoringURLTTL := time.Duration(GetEnvAsInt("PROCTORING_URL_TTL", 300)) * time.Second
+	proctoringRetentionDays := int(GetEnvAsInt("PROCTORING_RETENTION_DAYS", 30))
+
+	// Writing and speaking portfolio
+	portfolioMaxItems := int32(GetEnvAsInt("PORTFOLIO_MAX_ITEMS", 5))
+	portfolioHistoryWeeks := int(GetEnvAsInt("PORTFOLIO_HISTORY_WEEKS", 26))
+	portfolioShareDefaultDays := int(GetEnvAsInt("PORTFOLIO_SHARE_DEFAULT_DAYS", 7))
+	portfolioShareMaxDays := int(GetEnvAsInt("PORTFOLIO_SHARE_MAX_DAYS", 30))
+	portfolioMaxActiveLinks := GetEnvAsInt("PORTFOLIO_MAX_ACTIVE_LINKS", 10)
+
+	// Question difficulty calibration
+	calibrationInterval := time.Duration(GetEnvAsInt("CALIBRATION_INTERVAL", 86400)) * time.Second
+	calibrationMinResponses := int32(GetEnvAsInt("CALIBRATION_MIN_RESPONSES", 30))
+	predictionInterval := time.Duration(GetEnvAsInt("PREDICTION_INTERVAL", 86400)) * time.Second
+	predictionMinAnswers := int(GetEnvAsInt("PREDICTION_MIN_ANSWERS", 20))
+	predictionMaxAnswers := int32(GetEnvAsInt("PREDICTION_MAX_ANSWERS", 500))
+	gradingClaimTTL := time.Duration(GetEnvAsInt("GRADING_CLAIM_TTL", 900)) * time.Second
+	questionDuplicateMinSimilarity := int(GetEnvAsInt("QUESTION_DUPLICATE_MIN_SIMILARITY", 60))
+	questionDuplicateMaxCandidates := int32(GetEnvAsInt("QUESTION_DUPLICATE_MAX_CANDIDATES", 5))
+	upgradeDeviceActiveDays := int(GetEnvAsInt("UPGRADE_DEVICE_ACTIVE_DAYS", 90))
+	jobsDir := GetEnv("JOBS_DIR", "./jobs")
+	jobWorkers := int(GetEnvAsInt("JOB_WORKERS", 2))
+	jobResultTTL := time.Duration(GetEnvAsInt("JOB_RESULT_TTL", 86400)) * time.Second
+	dependencyCheckInterval := time.Duration(GetEnvAsInt("DEPENDENCY_CHECK_INTERVAL", 30)) * time.Second
+	dependencyProbeTimeout := time.Duration(GetEnvAsInt("DEPENDENCY_PROBE_TIMEOUT", 5)) * time.Second
+	dependencySlowThreshold := time.Duration(GetEnvAsInt("DEPENDENCY_SLOW_THRESHOLD_MS", 1000)) * time.Millisecond
+	drainTimeout := time.Duration(GetEnvAsInt("DRAIN_TIMEOUT", 60)) * time.Second
+	readOnlyMode := GetEnvAsBool("READ_ONLY_MODE", false)
+	readOnlyBackend := GetEnv("READ_ONLY_BACKEND", "file")
+	readOnlyStateFile := GetEnv("READ_ONLY_STATE_FILE", "./maintenance/read_only.json")
+	readOnlyRefreshInterval := time.Duration(GetEnvAsInt("READ_ONLY_REFRESH_INTERVAL", 2)) * time.Second
+	readOnlyRetryAfter := time.Duration(GetEnvAsInt("READ_ONLY_RETRY_AFTER", 120)) * time.Second
+
 	return Config{
 		// Database configuration
 		DBDriver:   dbDriver,
@@ -339,12 +815,26 @@ func DefaultConfig() Config {
 		RateLimitRequests:  rateLimitRequests,
 		RateLimitBurst:     rateLimitBurst,
 		RateLimitExpiresIn: rateLimitExpiresIn,
+		RateLimitBackend:   rateLimitBackend,
+		RateLimitKeyPrefix: rateLimitKeyPrefix,
 		// Auth rate limiting configuration
 		AuthRateLimitEnabled:  authRateLimitEnabled,
 		AuthRateLimitRequests: authRateLimitRequests,
 		AuthRateLimitBurst:    authRateLimitBurst,
 		// CORS configuration
-		CORSAllowedOrigins: corsAllowedOrigins,
+		AppEnv:                        appEnv,
+		CORSAllowedOrigins:            corsAllowedOrigins,
+		CORSAllowedOriginsDevelopment: corsAllowedOriginsDevelopment,
+		CORSAllowedOriginsStaging:     corsAllowedOriginsStaging,
+		CORSAllowedOriginsProduction:  corsAllowedOriginsProduction,
+		CORSMaxAge:                    corsMaxAge,
+
+		// Feature flags
+		FeatureFlags: featureFlags,
+
+		// Client configuration
+		ClientMinAppVersion:     clientMinAppVersion,
+		ClientMaintenanceNotice: clientMaintenanceNotice,
 
 		// Cache configuration
 		CacheEnabled:    cacheEnabled,
@@ -384,10 +874,23 @@ func DefaultConfig() Config {
 		CircuitBreakerThreshold: circuitBreakerThreshold,
 		RequestTimeoutSeconds:   requestTimeoutSeconds,
 		HealthCheckInterval:     healthCheckInterval,
+		// Leader election configuration
+		InstanceID:            instanceID,
+		LeaderElectionEnabled: leaderElectionEnabled,
+		LeaderElectionBackend: leaderElectionBackend,
+		LeaderElectionLockID:  leaderElectionLockID,
+		LeaderElectionKey:     leaderElectionKey,
+		LeaderElectionTTL:     leaderElectionTTL,
 		// Analyze service configuration
 		AnalyzeServiceEnabled: analyzeServiceEnabled,
 		AnalyzeServiceURL:     analyzeServiceURL,
 		AnalyzeServiceTimeout: analyzeServiceTimeout,
+		AnalyzeAttemptTimeout: analyzeAttemptTimeout,
+		AnalyzeMaxRetries:     analyzeMaxRetries,
+		AnalyzeRetryBackoff:   analyzeRetryBackoff,
+		AnalyzeHedgeDelay:     analyzeHedgeDelay,
+		AnalyzeMaxConns:       analyzeMaxConns,
+		AnalyzeDegradedMode:   analyzeDegradedMode,
 
 		// OpenAI AI Scoring configuration
 		OpenAIAPIKey:  openAIAPIKey,
@@ -395,15 +898,51 @@ func DefaultConfig() Config {
 		OpenAIModel:   openAIModel,
 		OpenAITimeout: openAITimeout,
 
+		// AI submission limits
+		AIMaxWritingWords:    aiMaxWritingWords,
+		AIMaxWritingChars:    aiMaxWritingChars,
+		AIMaxSpeakingWords:   aiMaxSpeakingWords,
+		AIMaxSpeakingChars:   aiMaxSpeakingChars,
+		AIMaxContextChars:    aiMaxContextChars,
+		AIOversizeMode:       aiOversizeMode,
+		AIRateLimitPerMinute: aiRateLimitPerMinute,
+		AIRateLimitPerHour:   aiRateLimitPerHour,
+
+		// HTTP server configuration
+		ServerReadTimeout:       serverReadTimeout,
+		ServerReadHeaderTimeout: serverReadHeaderTimeout,
+		ServerWriteTimeout:      serverWriteTimeout,
+		ServerIdleTimeout:       serverIdleTimeout,
+		ServerMaxHeaderBytes:    serverMaxHeaderBytes,
+
 		// Security configuration
 		TLSEnabled:             tlsEnabled,
 		TLSCertFile:            tlsCertFile,
 		TLSKeyFile:             tlsKeyFile,
+		TLSAutocertEnabled:     tlsAutocertEnabled,
+		TLSAutocertDomains:     tlsAutocertDomains,
+		TLSAutocertCacheDir:    tlsAutocertCacheDir,
 		HTTP2Enabled:           http2Enabled,
 		HTTP2MaxStreams:        http2MaxStreams,
 		HTTP2IdleTimeout:       int(http2IdleTimeout),
 		SecurityHeadersEnabled: securityHeadersEnabled,
 		InputValidationEnabled: inputValidationEnabled,
+		RequestSigningMode:     requestSigningMode,
+		RequestSigningKeys:     requestSigningKeys,
+
+		SanitizeEnabled:        sanitizeEnabled,
+		SanitizeRichTextPolicy: sanitizeRichTextPolicy,
+		SanitizeMarkdownPolicy: sanitizeMarkdownPolicy,
+
+		MaxRequestBodySize:    maxRequestBodySize,
+		MaxUploadBodySize:     maxUploadBodySize,
+		MaxBackupUploadSize:   maxBackupUploadSize,
+		UploadDailyQuotaBytes: uploadDailyQuotaBytes,
+		UploadDailyQuotaFiles: uploadDailyQuotaFiles,
+		UploadQuotaBackend:    uploadQuotaBackend,
+		UploadQuotaKeyPrefix:  uploadQuotaKeyPrefix,
+
+		BackupApprovalRequireMFA: backupApprovalRequireMFA,
 
 		// Database security
 		DBSSLMode:         dbSSLMode,
@@ -421,5 +960,264 @@ func DefaultConfig() Config {
 		SecurityMonitoringEnabled: securityMonitoringEnabled,
 		SecurityAlertsEnabled:     securityAlertsEnabled,
 		SecurityLogLevel:          securityLogLevel,
+
+		// Security event export
+		SecurityEventSink:          securityEventSink,
+		SecurityEventSyslogNetwork: securityEventSyslogNetwork,
+		SecurityEventSyslogAddress: securityEventSyslogAddress,
+		SecurityEventWebhookURL:    securityEventWebhookURL,
+		SecurityEventWebhookToken:  securityEventWebhookToken,
+		SecurityEventKafkaRESTURL:  securityEventKafkaRESTURL,
+		SecurityEventKafkaTopic:    securityEventKafkaTopic,
+		SecurityEventBufferSize:    securityEventBufferSize,
+		SecurityEventBatchSize:     securityEventBatchSize,
+		SecurityEventFlushInterval: securityEventFlushInterval,
+		SecurityEventMaxRetries:    securityEventMaxRetries,
+
+		// CAPTCHA
+		CaptchaEnabled:           captchaEnabled,
+		CaptchaProvider:          captchaProvider,
+		CaptchaSiteKey:           captchaSiteKey,
+		CaptchaSecretKey:         captchaSecretKey,
+		CaptchaFailureThreshold:  captchaFailureThreshold,
+		CaptchaVelocityThreshold: captchaVelocityThreshold,
+		CaptchaWindow:            captchaWindow,
+
+		// PII encryption
+		PIIEncryptionEnabled: piiEncryptionEnabled,
+		PIIEncryptionKeys:    piiEncryptionKeys,
+		PIIActiveKeyID:       piiActiveKeyID,
+		PIIBlindIndexKey:     piiBlindIndexKey,
+
+		// Achievements
+		AchievementEvalInterval: achievementEvalInterval,
+
+		// Referrals
+		ReferralRewardDays: referralRewardDays,
+
+		// Subscriptions
+		StripeWebhookSecret:      stripeWebhookSecret,
+		GooglePlayWebhookToken:   googlePlayWebhookToken,
+		AppStoreRootCertPath:     appStoreRootCertPath,
+		FreeAIScoresPerDay:       freeAIScoresPerDay,
+		BillingReconcileInterval: billingReconcileInterval,
+
+		// External dictionary
+		DictionaryAPIURL:            dictionaryAPIURL,
+		DictionaryRequestsPerMinute: dictionaryRequestsPerMinute,
+		DictionaryCacheTTL:          dictionaryCacheTTL,
+		DictionaryRetryFailedAfter:  dictionaryRetryFailedAfter,
+
+		// Text-to-speech
+		TTSAPIURL:        ttsAPIURL,
+		TTSModel:         ttsModel,
+		TTSDefaultVoice:  ttsDefaultVoice,
+		TTSMaxTextLength: ttsMaxTextLength,
+
+		// LTI 1.3
+		LTIPrivateKeyPath:    ltiPrivateKeyPath,
+		LTILaunchRedirectURL: ltiLaunchRedirectURL,
+
+		// Social login
+		OAuthGoogleClientIDs:     oauthGoogleClientIDs,
+		OAuthGoogleClientSecret:  oauthGoogleClientSecret,
+		OAuthAppleClientIDs:      oauthAppleClientIDs,
+		OAuthAppleTeamID:         oauthAppleTeamID,
+		OAuthAppleKeyID:          oauthAppleKeyID,
+		OAuthApplePrivateKeyPath: oauthApplePrivateKeyPath,
+
+		// Public API
+		PublicAPIEnabled:           publicAPIEnabled,
+		PublicAPIDailyQuota:        publicAPIDailyQuota,
+		PublicAPIRequestsPerMinute: publicAPIRequestsPerMinute,
+		PublicAPICacheMaxAge:       publicAPICacheMaxAge,
+
+		// Readiness probe
+		ReadinessRequired:    readinessRequired,
+		ReadinessGracePeriod: readinessGracePeriod,
+		ReadinessCacheMaxAge: readinessCacheMaxAge,
+
+		// Related content
+		RelatedContentRebuildInterval: relatedContentRebuildInterval,
+
+		// Search suggestions
+		SearchSuggestCacheTTL: searchSuggestCacheTTL,
+
+		// Saved filters
+		SavedFiltersPerUser: savedFiltersPerUser,
+
+		// Account lockout
+		AccountLockoutThreshold: accountLockoutThreshold,
+		AccountLockoutDuration:  accountLockoutDuration,
+
+		// Data access log
+		DataAccessLogRetentionDays: dataAccessLogRetentionDays,
+		DataAccessLogPruneInterval: dataAccessLogPruneInterval,
+
+		// Data retention
+		RetentionSpeakingAudioDays: retentionSpeakingAudioDays,
+		RetentionAIFeedbackDays:    retentionAIFeedbackDays,
+		RetentionLogDays:           retentionLogDays,
+		RetentionLogDir:            retentionLogDir,
+		RetentionPurgeInterval:     retentionPurgeInterval,
+		RetentionDryRun:            retentionDryRun,
+
+		// Vocabulary links
+		WordLinkCacheTTL: wordLinkCacheTTL,
+
+		// Listening playback
+		ListeningMaxPlays: listeningMaxPlays,
+		ListeningTokenTTL: listeningTokenTTL,
+		ListeningURLTTL:   listeningURLTTL,
+
+		// Analytics warehouse export
+		WarehouseExportEnabled:  warehouseExportEnabled,
+		WarehouseBackend:        warehouseBackend,
+		WarehouseTable:          warehouseTable,
+		WarehouseExportInterval: warehouseExportInterval,
+		WarehouseBatchSize:      warehouseBatchSize,
+		WarehouseHashKey:        warehouseHashKey,
+		WarehousePIIDropFields:  warehousePIIDropFields,
+		WarehousePIIHashFields:  warehousePIIHashFields,
+		ClickHouseURL:           clickHouseURL,
+		ClickHouseDatabase:      clickHouseDatabase,
+		ClickHouseUser:          clickHouseUser,
+		ClickHousePassword:      clickHousePassword,
+		BigQueryProject:         bigQueryProject,
+		BigQueryDataset:         bigQueryDataset,
+		BigQueryCredentialsFile: bigQueryCredentialsFile,
+
+		// Exam proctoring
+		ProctoringEnabled:             proctoringEnabled,
+		ProctoringFolder:              proctoringFolder,
+		ProctoringPolicyVersion:       proctoringPolicyVersion,
+		ProctoringCaptureInterval:     proctoringCaptureInterval,
+		ProctoringMaxPhotoBytes:       proctoringMaxPhotoBytes,
+		ProctoringMaxPhotosPerAttempt: proctoringMaxPhotosPerAttempt,
+		ProctoringURLTTL:              proctoringURLTTL,
+		ProctoringRetentionDays:       proctoringRetentionDays,
+
+		// Writing and speaking portfolio
+		PortfolioMaxItems:         portfolioMaxItems,
+		PortfolioHistoryWeeks:     portfolioHistoryWeeks,
+		PortfolioShareDefaultDays: portfolioShareDefaultDays,
+		PortfolioShareMaxDays:     portfolioShareMaxDays,
+		PortfolioMaxActiveLinks:   portfolioMaxActiveLinks,
+
+		// Question difficulty calibration
+		CalibrationInterval:     calibrationInterval,
+		CalibrationMinResponses: calibrationMinResponses,
+		PredictionInterval:      predictionInterval,
+		PredictionMinAnswers:    predictionMinAnswers,
+		PredictionMaxAnswers:    predictionMaxAnswers,
+
+		// Speaking grading queue
+		GradingClaimTTL: gradingClaimTTL,
+
+		// Question duplicate detection
+		QuestionDuplicateMinSimilarity: questionDuplicateMinSimilarity,
+		QuestionDuplicateMaxCandidates: questionDuplicateMaxCandidates,
+
+		// Targeted upgrade rollouts
+		UpgradeDeviceActiveDays: upgradeDeviceActiveDays,
+
+		// Background jobs
+		JobsDir:      jobsDir,
+		JobWorkers:   jobWorkers,
+		JobResultTTL: jobResultTTL,
+
+		// Dependency graph
+		DependencyCheckInterval: dependencyCheckInterval,
+		DependencyProbeTimeout:  dependencyProbeTimeout,
+		DependencySlowThreshold: dependencySlowThreshold,
+
+		// Draining
+		DrainTimeout: drainTimeout,
+
+		// Read-only mode
+		ReadOnlyMode:            readOnlyMode,
+		ReadOnlyBackend:         readOnlyBackend,
+		ReadOnlyStateFile:       readOnlyStateFile,
+		ReadOnlyRefreshInterval: readOnlyRefreshInterval,
+		ReadOnlyRetryAfter:      readOnlyRetryAfter,
+	}
+}
+
+// IsFeatureEnabled reports whether a feature is listed in FEATURE_FLAGS
+func (c Config) IsFeatureEnabled(name string) bool {
+	for _, flag := range c.EnabledFeatures() {
+		if strings.EqualFold(flag, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// RequiredDependencies returns the health checks listed in READINESS_REQUIRED
+func (c Config) RequiredDependencies() []string {
+	dependencies := []string{}
+	for _, name := range strings.Split(c.ReadinessRequired, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			dependencies = append(dependencies, name)
+		}
+	}
+	return dependencies
+}
+
+// EnabledFeatures returns the features listed in FEATURE_FLAGS
+func (c Config) EnabledFeatures() []string {
+	features := []string{}
+	for _, flag := range strings.Split(c.FeatureFlags, ",") {
+		if flag = strings.TrimSpace(flag); flag != "" {
+			features = append(features, flag)
+		}
+	}
+	return features
+}
+
+// GoogleClientIDs returns the client IDs listed in OAUTH_GOOGLE_CLIENT_IDS
+func (c Config) GoogleClientIDs() []string {
+	return commaSeparated(c.OAuthGoogleClientIDs)
+}
+
+// AppleClientIDs returns the client IDs listed in OAUTH_APPLE_CLIENT_IDS
+func (c Config) AppleClientIDs() []string {
+	return commaSeparated(c.OAuthAppleClientIDs)
+}
+
+// commaSeparated returns the non-empty items of a comma-separated list
+func commaSeparated(list string) []string {
+	items := []string{}
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// CORSOrigins returns the origins allowed in the app environment: those of
+// CORS_ALLOWED_ORIGINS followed by those of the environment's own list
+func (c Config) CORSOrigins() []string {
+	lists := []string{c.CORSAllowedOrigins}
+	switch c.AppEnv {
+	case "development":
+		lists = append(lists, c.CORSAllowedOriginsDevelopment)
+	case "staging":
+		lists = append(lists, c.CORSAllowedOriginsStaging)
+	case "production":
+		lists = append(lists, c.CORSAllowedOriginsProduction)
+	}
+
+	origins := []string{}
+	seen := make(map[string]bool)
+	for _, list := range lists {
+		for _, origin := range strings.Split(list, ",") {
+			if origin = strings.TrimSpace(origin); origin != "" && !seen[origin] {
+				seen[origin] = true
+				origins = append(origins, origin)
+			}
+		}
 	}
+	return origins
 }
@@ -0,0 +1,210 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+	"gopkg.in/yaml.v3"
+)
+
+// LoaderOptions describes the configuration layers used by Load.
+// Precedence, from lowest to highest: built-in defaults, YAML file,
+// environment variables (including .env), command line overrides.
+type LoaderOptions struct {
+	ConfigFile string            // Path to a YAML file with KEY: value pairs
+	Overrides  map[string]string // KEY=VALUE overrides from command line flags
+}
+
+// overrideFlag collects repeated --set KEY=VALUE flags
+type overrideFlag map[string]string
+
+func (o overrideFlag) String() string {
+	pairs := make([]string, 0, len(o))
+	for k, v := range o {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (o overrideFlag) Set(value string) error {
+	key, val, ok := strings.Cut(value, "=")
+	if !ok || strings.TrimSpace(key) == "" {
+		return fmt.Errorf("override must be in KEY=VALUE format: %q", value)
+	}
+	o[strings.ToUpper(strings.TrimSpace(key))] = val
+	return nil
+}
+
+// ParseFlags parses the --config and --set command line flags.
+// The CONFIG_FILE environment variable is used when --config is not given.
+func ParseFlags(args []string) (LoaderOptions, error) {
+	overrides := overrideFlag{}
+	fs := flag.NewFlagSet("config", flag.ContinueOnError)
+	configFile := fs.String("config", GetEnv("CONFIG_FILE", ""), "Path to YAML configuration file")
+	fs.Var(overrides, "set", "Override a configuration value (KEY=VALUE), can be repeated")
+
+	if err := fs.Parse(args); err != nil {
+		return LoaderOptions{}, err
+	}
+
+	return LoaderOptions{
+		ConfigFile: *configFile,
+		Overrides:  overrides,
+	}, nil
+}
+
+// loaderState remembers which environment keys were populated by the loader
+// so that a reload can replace them without clobbering real environment values.
+type loaderState struct {
+	mu      sync.Mutex
+	applied map[string]originalEnv // keys set by the file/flag layers
+	options LoaderOptions          // options used by the last successful load
+}
+
+// originalEnv is the environment value a layer replaced
+type originalEnv struct {
+	value  string
+	exists bool
+}
+
+var state = &loaderState{
+	applied: make(map[string]originalEnv),
+}
+
+// setLayerEnv sets an environment key, remembering the value it replaced
+func (s *loaderState) setLayerEnv(key, value string) {
+	if _, seen := s.applied[key]; !seen {
+		prev, exists := os.LookupEnv(key)
+		s.applied[key] = originalEnv{value: prev, exists: exists}
+	}
+	os.Setenv(key, value)
+}
+
+// Load builds the configuration from all layers and validates it.
+func Load(opts LoaderOptions) (Config, error) {
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	fileValues, err := readConfigFile(opts.ConfigFile)
+	if err != nil {
+		return Config{}, err
+	}
+
+	// Forget values applied by a previous load so removed keys fall back to defaults
+	for key, original := range state.applied {
+		if original.exists {
+			os.Setenv(key, original.value)
+		} else {
+			os.Unsetenv(key)
+		}
+	}
+	state.applied = make(map[string]originalEnv)
+
+	// File values only fill gaps left by the real environment
+	for key, value := range fileValues {
+		if _, inEnv := os.LookupEnv(key); inEnv {
+			continue
+		}
+		state.setLayerEnv(key, value)
+	}
+
+	// Command line overrides win over everything
+	for key, value := range opts.Overrides {
+		state.setLayerEnv(key, value)
+	}
+
+	cfg := buildConfig()
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+
+	state.options = opts
+	return cfg, nil
+}
+
+// reload re-reads all layers using the options of the last successful Load.
+func reload() (Config, error) {
+	state.mu.Lock()
+	opts := state.options
+	state.mu.Unlock()
+
+	return Load(opts)
+}
+
+// readConfigFile reads a flat YAML file of KEY: value pairs.
+// Keys match the environment variable names (e.g. RATE_LIMIT_REQUESTS).
+func readConfigFile(path string) (map[string]string, error) {
+	values := make(map[string]string)
+	if path == "" {
+		return values, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	raw := make(map[string]interface{})
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	for key, value := range raw {
+		switch v := value.(type) {
+		case nil:
+			continue
+		case map[string]interface{}, []interface{}:
+			return nil, fmt.Errorf("config key %s must be a scalar value", key)
+		default:
+			values[strings.ToUpper(key)] = fmt.Sprint(v)
+		}
+	}
+
+	return values, nil
+}
+
+// configValidator validates Config using the `validate` struct tags
+var configValidator = newConfigValidator()
+
+func newConfigValidator() *validator.Validate {
+	v := validator.New()
+	// Report environment variable names instead of Go field names
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		if name := field.Tag.Get("mapstructure"); name != "" {
+			return name
+		}
+		return field.Name
+	})
+	return v
+}
+
+// Validate checks the configuration against its struct-tag rules
+func (c Config) Validate() error {
+	err := configValidator.Struct(c)
+	if err == nil {
+		return nil
+	}
+
+	validationErrs, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return fmt.Errorf("config validation failed: %w", err)
+	}
+
+	problems := make([]string, 0, len(validationErrs))
+	for _, fieldErr := range validationErrs {
+		rule := fieldErr.Tag()
+		if fieldErr.Param() != "" {
+			rule = fmt.Sprintf("%s=%s", rule, fieldErr.Param())
+		}
+		problems = append(problems, fmt.Sprintf("%s (rule: %s)", fieldErr.Field(), rule))
+	}
+
+	return fmt.Errorf("invalid configuration: %s", strings.Join(problems, "; "))
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// redactedValue replaces secret values in config snapshots
+const redactedValue = "[REDACTED]"
+
+// sensitiveKeyParts flags settings as secret by name, in addition to the `secret` tag
+var sensitiveKeyParts = []string{"PASSWORD", "SECRET", "API_KEY", "SYMMETRIC_KEY", "ENCRYPTION_KEY", "SALT", "TOKEN"}
+
+// Redacted returns the configuration keyed by environment variable name with
+// secrets replaced, safe to expose on admin diagnostics endpoints
+func (c Config) Redacted() map[string]interface{} {
+	snapshot := make(map[string]interface{})
+
+	value := reflect.ValueOf(c)
+	typ := value.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		key := field.Tag.Get("mapstructure")
+		if key == "" {
+			key = field.Name
+		}
+
+		fieldValue := value.Field(i).Interface()
+		if isSecretField(field, key) {
+			if !reflect.ValueOf(fieldValue).IsZero() {
+				snapshot[key] = redactedValue
+			} else {
+				snapshot[key] = ""
+			}
+			continue
+		}
+
+		if duration, ok := fieldValue.(time.Duration); ok {
+			snapshot[key] = duration.String()
+			continue
+		}
+		snapshot[key] = fieldValue
+	}
+
+	return snapshot
+}
+
+func isSecretField(field reflect.StructField, key string) bool {
+	if field.Tag.Get("secret") == "true" {
+		return true
+	}
+	// Durations like ACCESS_TOKEN_DURATION are not secrets
+	if field.Type == reflect.TypeOf(time.Duration(0)) || field.Type.Kind() == reflect.Int64 {
+		return false
+	}
+	for _, part := range sensitiveKeyParts {
+		if strings.Contains(key, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// String avoids leaking secrets when a Config is printed
+func (c Config) String() string {
+	return fmt.Sprintf("%v", c.Redacted())
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// Reloader keeps the live configuration and applies reloadable settings
+// (rate limits, cache TTLs, feature flags, client configuration, request
+// signing keys, read-only mode) without restarting the server.
+type Reloader struct {
+	mu        sync.RWMutex
+	current   Config
+	listeners []func(Config)
+}
+
+// NewReloader creates a reloader seeded with the startup configuration
+func NewReloader(cfg Config) *Reloader {
+	return &Reloader{current: cfg}
+}
+
+// Current returns the live configuration
+func (r *Reloader) Current() Config {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.current
+}
+
+// OnReload registers a callback invoked after every successful reload
+func (r *Reloader) OnReload(fn func(Config)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.listeners = append(r.listeners, fn)
+}
+
+// Reload re-reads all configuration layers and applies the reloadable settings.
+// Settings that require a restart (database, server address, TLS, ...) keep
+// their startup values. An invalid configuration leaves the current one untouched.
+func (r *Reloader) Reload() (Config, error) {
+	fresh, err := reload()
+	if err != nil {
+		return r.Current(), err
+	}
+
+	r.mu.Lock()
+	r.current = applyReloadable(r.current, fresh)
+	cfg := r.current
+	listeners := append([]func(Config){}, r.listeners...)
+	r.mu.Unlock()
+
+	for _, fn := range listeners {
+		fn(cfg)
+	}
+
+	return cfg, nil
+}
+
+// WatchSignals reloads the configuration whenever the process receives SIGHUP
+func (r *Reloader) WatchSignals(ctx context.Context) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGHUP)
+
+	go func() {
+		defer signal.Stop(sigs)
+		for {
+			select {
+			case <-sigs:
+				if _, err := r.Reload(); err != nil {
+					log.Printf("Config reload failed, keeping current configuration: %v", err)
+					continue
+				}
+				log.Printf("Configuration reloaded after SIGHUP")
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// applyReloadable copies the settings that are safe to change at runtime
+func applyReloadable(current, fresh Config) Config {
+	current.RateLimitRequests = fresh.RateLimitRequests
+	current.RateLimitBurst = fresh.RateLimitBurst
+	current.AuthRateLimitRequests = fresh.AuthRateLimitRequests
+	current.AuthRateLimitBurst = fresh.AuthRateLimitBurst
+	current.CacheDefaultTTL = fresh.CacheDefaultTTL
+	current.HTTPCacheTTL = fresh.HTTPCacheTTL
+	current.FeatureFlags = fresh.FeatureFlags
+	current.ClientMinAppVersion = fresh.ClientMinAppVersion
+	current.ClientMaintenanceNotice = fresh.ClientMaintenanceNotice
+	current.RequestSigningMode = fresh.RequestSigningMode
+	current.RequestSigningKeys = fresh.RequestSigningKeys
+	current.ReadOnlyMode = fresh.ReadOnlyMode
+	current.CORSAllowedOrigins = fresh.CORSAllowedOrigins
+	current.CORSAllowedOriginsDevelopment = fresh.CORSAllowedOriginsDevelopment
+	current.CORSAllowedOriginsStaging = fresh.CORSAllowedOriginsStaging
+	current.CORSAllowedOriginsProduction = fresh.CORSAllowedOriginsProduction
+	current.CORSMaxAge = fresh.CORSMaxAge
+	return current
+}
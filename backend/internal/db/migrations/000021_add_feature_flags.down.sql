@@ -0,0 +1,7 @@
+-- Drop feature flags
+
+DELETE FROM permissions WHERE name = 'feature_flags.manage';
+
+DROP TRIGGER IF EXISTS update_feature_flags_updated_at ON feature_flags;
+
+DROP TABLE IF EXISTS feature_flags;
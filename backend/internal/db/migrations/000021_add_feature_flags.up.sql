@@ -0,0 +1,34 @@
+-- Create feature_flags table
+CREATE TABLE feature_flags (
+    id SERIAL PRIMARY KEY,
+    key VARCHAR(100) UNIQUE NOT NULL,
+    description TEXT,
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    rollout_percentage INTEGER NOT NULL DEFAULT 0 CHECK (rollout_percentage BETWEEN 0 AND 100),
+    allowed_roles TEXT[] NOT NULL DEFAULT '{}',
+    allowed_user_ids INTEGER[] NOT NULL DEFAULT '{}',
+    allowed_orgs TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL
+);
+
+-- Add updated_at trigger for feature_flags
+CREATE TRIGGER update_feature_flags_updated_at
+BEFORE UPDATE ON feature_flags
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- Insert default flags (disabled until rolled out)
+INSERT INTO feature_flags (key, description) VALUES
+    ('ai_scoring_v2', 'Second generation AI scoring for writing and speaking'),
+    ('adaptive_exam', 'Adaptive exam that adjusts question difficulty to the user');
+
+-- Permission for managing feature flags
+INSERT INTO permissions (name, resource, action, description) VALUES
+    ('feature_flags.manage', 'feature_flags', 'manage', 'Create, update and delete feature flags');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r, permissions p
+WHERE r.name = 'admin'
+AND p.name = 'feature_flags.manage';
@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS update_user_upload_usage_updated_at ON user_upload_usage;
+DROP TABLE IF EXISTS user_upload_usage;
@@ -0,0 +1,18 @@
+-- Track daily upload usage per user for upload quotas
+CREATE TABLE user_upload_usage (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    usage_date DATE NOT NULL,
+    bytes_used BIGINT NOT NULL DEFAULT 0,
+    files_count INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+    PRIMARY KEY (user_id, usage_date)
+);
+
+CREATE INDEX idx_user_upload_usage_date ON user_upload_usage(usage_date);
+
+-- Add updated_at trigger for user_upload_usage
+CREATE TRIGGER update_user_upload_usage_updated_at
+BEFORE UPDATE ON user_upload_usage
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
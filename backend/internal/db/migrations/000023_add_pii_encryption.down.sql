@@ -0,0 +1,8 @@
+-- Run `pii-admin decrypt` before rolling back, otherwise encrypted emails are lost
+-- and restoring the NOT NULL constraint fails.
+ALTER TABLE user_profiles DROP COLUMN IF EXISTS full_name_encrypted;
+
+DROP INDEX IF EXISTS idx_users_email_bidx;
+ALTER TABLE users DROP COLUMN IF EXISTS email_bidx;
+ALTER TABLE users DROP COLUMN IF EXISTS email_encrypted;
+ALTER TABLE users ALTER COLUMN email SET NOT NULL;
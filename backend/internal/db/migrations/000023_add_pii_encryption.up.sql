@@ -0,0 +1,11 @@
+-- Application-level encryption of PII columns.
+-- Encrypted values are envelope encrypted (see internal/pii); the plaintext
+-- columns are cleared once a row is encrypted, so they become nullable.
+ALTER TABLE users ALTER COLUMN email DROP NOT NULL;
+ALTER TABLE users ADD COLUMN email_encrypted TEXT;
+ALTER TABLE users ADD COLUMN email_bidx VARCHAR(64);
+
+-- Blind index used for login lookups on encrypted emails
+CREATE UNIQUE INDEX idx_users_email_bidx ON users(email_bidx) WHERE email_bidx IS NOT NULL;
+
+ALTER TABLE user_profiles ADD COLUMN full_name_encrypted TEXT;
@@ -0,0 +1,15 @@
+-- TOTP second factor, required to approve sensitive admin operations
+CREATE TABLE user_mfa (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    totp_secret TEXT NOT NULL,
+    enabled_at TIMESTAMP WITH TIME ZONE,
+    last_used_step BIGINT NOT NULL DEFAULT 0, -- Rejects replayed codes
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL
+);
+
+-- Add updated_at trigger for user_mfa
+CREATE TRIGGER update_user_mfa_updated_at
+BEFORE UPDATE ON user_mfa
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,7 @@
+ALTER TABLE user_profiles
+    DROP COLUMN IF EXISTS native_language,
+    DROP COLUMN IF EXISTS daily_minutes,
+    DROP COLUMN IF EXISTS exam_date,
+    DROP COLUMN IF EXISTS target_score;
+
+DROP INDEX IF EXISTS idx_user_profiles_user_id;
@@ -0,0 +1,14 @@
+-- Study goals drive the personalized study plan
+
+-- Keep the most recent profile of users that have several before making user_id unique
+DELETE FROM user_profiles p
+USING user_profiles newer
+WHERE p.user_id = newer.user_id AND p.id < newer.id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_profiles_user_id ON user_profiles(user_id);
+
+ALTER TABLE user_profiles
+    ADD COLUMN target_score INTEGER CHECK (target_score BETWEEN 10 AND 990),
+    ADD COLUMN exam_date DATE,
+    ADD COLUMN daily_minutes INTEGER CHECK (daily_minutes BETWEEN 5 AND 600),
+    ADD COLUMN native_language VARCHAR(10);
@@ -0,0 +1,10 @@
+ALTER TABLE user_profiles
+    DROP COLUMN IF EXISTS placement_completed_at,
+    DROP COLUMN IF EXISTS placement_levels,
+    DROP COLUMN IF EXISTS placement_score;
+
+DROP TRIGGER IF EXISTS update_placement_tests_updated_at ON placement_tests;
+DROP TABLE IF EXISTS placement_tests;
+
+DROP INDEX IF EXISTS idx_questions_difficulty;
+ALTER TABLE questions DROP COLUMN IF EXISTS difficulty;
@@ -0,0 +1,32 @@
+-- Authored question difficulty on the same 1-6 scale as word levels
+ALTER TABLE questions ADD COLUMN difficulty SMALLINT CHECK (difficulty BETWEEN 1 AND 6);
+
+CREATE INDEX IF NOT EXISTS idx_questions_difficulty ON questions(difficulty);
+
+-- Adaptive onboarding placement tests
+CREATE TABLE placement_tests (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'in_progress' CHECK (status IN ('in_progress', 'completed', 'abandoned')),
+    state JSONB NOT NULL, -- Per-skill staircase and the pending item
+    result JSONB,
+    started_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL
+);
+
+-- A user has at most one test in progress
+CREATE UNIQUE INDEX IF NOT EXISTS idx_placement_tests_active_user ON placement_tests(user_id) WHERE status = 'in_progress';
+CREATE INDEX IF NOT EXISTS idx_placement_tests_user_id ON placement_tests(user_id, started_at DESC);
+
+-- Add updated_at trigger for placement_tests
+CREATE TRIGGER update_placement_tests_updated_at
+BEFORE UPDATE ON placement_tests
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- Latest placement result, read by the study plan generator
+ALTER TABLE user_profiles
+    ADD COLUMN placement_score INTEGER CHECK (placement_score BETWEEN 10 AND 990),
+    ADD COLUMN placement_levels JSONB,
+    ADD COLUMN placement_completed_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS update_user_social_settings_updated_at ON user_social_settings;
+DROP TABLE IF EXISTS user_social_settings;
+DROP TABLE IF EXISTS user_activities;
+DROP TABLE IF EXISTS user_follows;
@@ -0,0 +1,37 @@
+-- Users following other users
+CREATE TABLE user_follows (
+    follower_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    followee_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+    PRIMARY KEY (follower_id, followee_id),
+    CONSTRAINT no_self_follow CHECK (follower_id <> followee_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_follows_followee ON user_follows(followee_id);
+
+-- Learning activity shown in the feed of followers and summed into weekly XP
+CREATE TABLE user_activities (
+    id BIGSERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    xp INTEGER NOT NULL DEFAULT 0 CHECK (xp >= 0),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_activities_user_created ON user_activities(user_id, created_at DESC);
+
+-- Who may see a user's activity: public, followers (default) or private
+CREATE TABLE user_social_settings (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    activity_visibility VARCHAR(20) NOT NULL DEFAULT 'followers' CHECK (activity_visibility IN ('public', 'followers', 'private')),
+    share_scores BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL
+);
+
+-- Add updated_at trigger for user_social_settings
+CREATE TRIGGER update_user_social_settings_updated_at
+BEFORE UPDATE ON user_social_settings
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,7 @@
+DELETE FROM permissions WHERE name = 'badges.manage';
+
+DROP INDEX IF EXISTS idx_vocabulary_stats_user_mastery;
+DROP TABLE IF EXISTS event_cursors;
+DROP TABLE IF EXISTS user_badges;
+DROP TRIGGER IF EXISTS update_badges_updated_at ON badges;
+DROP TABLE IF EXISTS badges;
@@ -0,0 +1,58 @@
+-- Badge definitions. A badge is awarded when its rule reaches the threshold:
+--   activity_count  number of activities of activity_type
+--   streak_days     current daily activity streak
+--   words_mastered  words at mastery level 8 or above
+--   total_xp        XP earned from all activities
+CREATE TABLE badges (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(50) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    icon_url VARCHAR(255),
+    rule_type VARCHAR(30) NOT NULL CHECK (rule_type IN ('activity_count', 'streak_days', 'words_mastered', 'total_xp')),
+    activity_type VARCHAR(50),
+    threshold INTEGER NOT NULL CHECK (threshold > 0),
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+    CONSTRAINT activity_count_needs_type CHECK (rule_type <> 'activity_count' OR activity_type IS NOT NULL)
+);
+
+-- Add updated_at trigger for badges
+CREATE TRIGGER update_badges_updated_at
+BEFORE UPDATE ON badges
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- Badges earned by users
+CREATE TABLE user_badges (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    badge_id INTEGER NOT NULL REFERENCES badges(id) ON DELETE CASCADE,
+    activity_id BIGINT REFERENCES user_activities(id) ON DELETE SET NULL,
+    awarded_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+    PRIMARY KEY (user_id, badge_id)
+);
+
+-- Position of event consumers in the user_activities log
+CREATE TABLE event_cursors (
+    name VARCHAR(50) PRIMARY KEY,
+    last_id BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_vocabulary_stats_user_mastery ON vocabulary_stats(user_id, mastery_level);
+
+INSERT INTO badges (code, name, description, rule_type, activity_type, threshold) VALUES
+    ('first_exam', 'First Steps', 'Complete your first mock test', 'activity_count', 'exam_completed', 1),
+    ('streak_30', 'Unstoppable', 'Study 30 days in a row', 'streak_days', NULL, 30),
+    ('words_mastered_1000', 'Word Master', 'Master 1000 words', 'words_mastered', NULL, 1000);
+
+-- Permission for managing badge definitions
+INSERT INTO permissions (name, resource, action, description) VALUES
+    ('badges.manage', 'badges', 'manage', 'Create, update and delete achievement badges');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r, permissions p
+WHERE r.name = 'admin'
+AND p.name = 'badges.manage';
@@ -0,0 +1,15 @@
+-- Preferences set by each user. Only keys the user changed are stored;
+-- defaults are applied by the application. Keys are validated against the
+-- preferences JSON schema before they are written.
+CREATE TABLE user_preferences (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    preferences JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL
+);
+
+-- Add updated_at trigger for user_preferences
+CREATE TRIGGER update_user_preferences_updated_at
+BEFORE UPDATE ON user_preferences
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
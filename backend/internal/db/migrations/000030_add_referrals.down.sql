@@ -0,0 +1,5 @@
+DELETE FROM permissions WHERE name = 'referrals.read';
+DELETE FROM roles WHERE name = 'premium';
+
+DROP TABLE IF EXISTS referrals;
+DROP TABLE IF EXISTS referral_codes;
@@ -0,0 +1,42 @@
+-- Referral code of each user, created the first time it is requested
+CREATE TABLE referral_codes (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    code VARCHAR(16) NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL
+);
+
+-- Registrations attributed to a referral code. A referral converts when the
+-- referred user completes their first learning activity, which rewards the
+-- referrer. Suspected self-referrals are kept as rejected for the report.
+CREATE TABLE referrals (
+    id SERIAL PRIMARY KEY,
+    referrer_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    referred_id INTEGER NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    code VARCHAR(16) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'converted', 'rejected')),
+    reject_reason VARCHAR(50),
+    signup_ip VARCHAR(45),
+    reward_days INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+    converted_at TIMESTAMP WITH TIME ZONE,
+    rewarded_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT no_self_referral CHECK (referrer_id <> referred_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_referrals_referrer ON referrals(referrer_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_referrals_created ON referrals(created_at);
+
+-- Time-limited role granted as a referral reward
+INSERT INTO roles (name, description) VALUES
+    ('premium', 'Premium learner, granted for a limited time by rewards')
+ON CONFLICT (name) DO NOTHING;
+
+-- Permission for the referral report
+INSERT INTO permissions (name, resource, action, description) VALUES
+    ('referrals.read', 'referrals', 'read', 'View the referral conversion report');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r, permissions p
+WHERE r.name = 'admin'
+AND p.name = 'referrals.read';
@@ -0,0 +1,9 @@
+DELETE FROM permissions WHERE name = 'billing.manage';
+
+ALTER TABLE exams DROP COLUMN IF EXISTS is_premium;
+
+DROP TABLE IF EXISTS entitlement_usage;
+DROP TABLE IF EXISTS billing_events;
+DROP TABLE IF EXISTS subscriptions;
+DROP TABLE IF EXISTS plan_products;
+DROP TABLE IF EXISTS plans;
@@ -0,0 +1,99 @@
+-- Plans sold through any billing provider. Each plan grants a set of
+-- entitlements, which handlers check instead of looking at plans directly.
+CREATE TABLE plans (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(50) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    entitlements TEXT[] NOT NULL DEFAULT '{}',
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL
+);
+
+CREATE TRIGGER update_plans_updated_at
+BEFORE UPDATE ON plans
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- Store products (Stripe prices, Google Play and App Store product IDs) that sell a plan
+CREATE TABLE plan_products (
+    provider VARCHAR(20) NOT NULL CHECK (provider IN ('stripe', 'google_play', 'app_store')),
+    product_id VARCHAR(255) NOT NULL,
+    plan_id INTEGER NOT NULL REFERENCES plans(id) ON DELETE CASCADE,
+    PRIMARY KEY (provider, product_id)
+);
+
+-- Subscriptions as last reported by their provider. Manual subscriptions are granted by admins.
+CREATE TABLE subscriptions (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    plan_id INTEGER NOT NULL REFERENCES plans(id),
+    provider VARCHAR(20) NOT NULL CHECK (provider IN ('stripe', 'google_play', 'app_store', 'manual')),
+    external_id VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'active', 'trialing', 'grace_period', 'on_hold', 'canceled', 'expired', 'refunded')),
+    current_period_end TIMESTAMP WITH TIME ZONE,
+    last_event_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+    UNIQUE (provider, external_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_subscriptions_user ON subscriptions(user_id);
+
+CREATE TRIGGER update_subscriptions_updated_at
+BEFORE UPDATE ON subscriptions
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- Webhook events received from providers, normalized. The unique key makes
+-- redelivered events no-ops; unmatched events are replayed when the purchase
+-- is linked to a user.
+CREATE TABLE billing_events (
+    id BIGSERIAL PRIMARY KEY,
+    provider VARCHAR(20) NOT NULL,
+    event_id VARCHAR(255) NOT NULL,
+    event_type VARCHAR(100) NOT NULL,
+    external_id VARCHAR(255) NOT NULL DEFAULT '',
+    product_id VARCHAR(255) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT '',
+    period_end TIMESTAMP WITH TIME ZONE,
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    payload JSONB NOT NULL,
+    result VARCHAR(20) NOT NULL DEFAULT 'received'
+        CHECK (result IN ('received', 'processed', 'ignored', 'unmatched', 'failed')),
+    error TEXT,
+    received_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL,
+    UNIQUE (provider, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_billing_events_subscription ON billing_events(provider, external_id, occurred_at);
+CREATE INDEX IF NOT EXISTS idx_billing_events_result ON billing_events(result, received_at DESC);
+
+-- Daily use of metered features by users without the matching entitlement
+CREATE TABLE entitlement_usage (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    feature VARCHAR(50) NOT NULL,
+    usage_date DATE NOT NULL,
+    count INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (user_id, feature, usage_date)
+);
+
+-- Exams reserved for users with the premium_exams entitlement
+ALTER TABLE exams ADD COLUMN is_premium BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Default plan. The premium role granted by referral rewards carries the
+-- entitlements of the plan with the same code.
+INSERT INTO plans (code, name, description, entitlements) VALUES
+    ('premium', 'Premium', 'Premium mock tests and unlimited AI scoring', ARRAY['premium_exams', 'unlimited_ai_scoring']);
+
+-- Permission for plans, manual subscriptions and billing events
+INSERT INTO permissions (name, resource, action, description) VALUES
+    ('billing.manage', 'billing', 'manage', 'Manage plans, subscriptions and billing events');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r, permissions p
+WHERE r.name = 'admin'
+AND p.name = 'billing.manage';
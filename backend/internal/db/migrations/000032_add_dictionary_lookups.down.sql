@@ -0,0 +1,5 @@
+DELETE FROM permissions WHERE name = 'dictionary.manage';
+
+DROP TABLE IF EXISTS word_lookups;
+
+ALTER TABLE words DROP COLUMN IF EXISTS audio_url;
@@ -0,0 +1,25 @@
+-- Pronunciation audio fetched from an external dictionary
+ALTER TABLE words ADD COLUMN IF NOT EXISTS audio_url TEXT;
+
+-- Outcome of the last external dictionary lookup per word, so the backfill
+-- does not ask the provider again for words it does not know
+CREATE TABLE word_lookups (
+    word_id INT PRIMARY KEY REFERENCES words(id) ON DELETE CASCADE,
+    provider VARCHAR(50) NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('found', 'not_found', 'failed')),
+    error TEXT,
+    attempts INT NOT NULL DEFAULT 1,
+    looked_up_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_word_lookups_status ON word_lookups(status, looked_up_at);
+
+-- Permission for dictionary backfills
+INSERT INTO permissions (name, resource, action, description) VALUES
+    ('dictionary.manage', 'dictionary', 'manage', 'Fill missing word data from external dictionaries');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r, permissions p
+WHERE r.name = 'admin'
+AND p.name = 'dictionary.manage';
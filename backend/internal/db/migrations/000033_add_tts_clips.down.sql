@@ -0,0 +1 @@
+DROP TABLE IF EXISTS tts_clips;
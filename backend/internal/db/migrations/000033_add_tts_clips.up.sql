@@ -0,0 +1,14 @@
+-- Synthesized speech stored in media storage, keyed by a hash of the
+-- provider, voice and normalized text so each phrase is synthesized once
+CREATE TABLE tts_clips (
+    hash CHAR(64) PRIMARY KEY,
+    voice VARCHAR(50) NOT NULL,
+    text TEXT NOT NULL,
+    public_id TEXT NOT NULL,
+    size_bytes INT NOT NULL,
+    hits INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_tts_clips_last_used ON tts_clips(last_used_at);
@@ -0,0 +1,11 @@
+-- Per-user ICS feeds of the study plan. The feed URL is signed over the user
+-- and version, so bumping the version invalidates previously shared URLs.
+CREATE TABLE calendar_feeds (
+    user_id INT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    version INT NOT NULL DEFAULT 1,
+    study_time VARCHAR(5) NOT NULL DEFAULT '19:00',
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_accessed_at TIMESTAMPTZ
+);
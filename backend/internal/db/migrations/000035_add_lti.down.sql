@@ -0,0 +1,9 @@
+DELETE FROM permissions WHERE name = 'lti.manage';
+
+DROP TABLE IF EXISTS lti_score_submissions;
+DROP TABLE IF EXISTS lti_resource_links;
+DROP TABLE IF EXISTS lti_context_members;
+DROP TABLE IF EXISTS lti_contexts;
+DROP TABLE IF EXISTS lti_users;
+DROP TABLE IF EXISTS lti_launch_states;
+DROP TABLE IF EXISTS lti_platforms;
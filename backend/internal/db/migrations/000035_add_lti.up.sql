@@ -0,0 +1,100 @@
+-- LMS platforms registered for LTI 1.3 launches. A platform is identified by
+-- issuer, client ID and deployment ID as sent in the launch token.
+CREATE TABLE lti_platforms (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    issuer TEXT NOT NULL,
+    client_id TEXT NOT NULL,
+    deployment_id TEXT NOT NULL,
+    auth_login_url TEXT NOT NULL,
+    auth_token_url TEXT NOT NULL,
+    jwks_url TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (issuer, client_id, deployment_id)
+);
+
+-- OIDC login states awaiting the launch, consumed once
+CREATE TABLE lti_launch_states (
+    state VARCHAR(64) PRIMARY KEY,
+    nonce VARCHAR(64) NOT NULL,
+    platform_id INT NOT NULL REFERENCES lti_platforms(id) ON DELETE CASCADE,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_lti_launch_states_expires_at ON lti_launch_states(expires_at);
+
+-- Accounts provisioned for LMS users, keyed by the platform subject
+CREATE TABLE lti_users (
+    platform_id INT NOT NULL REFERENCES lti_platforms(id) ON DELETE CASCADE,
+    subject TEXT NOT NULL,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (platform_id, subject)
+);
+
+CREATE INDEX IF NOT EXISTS idx_lti_users_user_id ON lti_users(user_id);
+
+-- LMS courses the app was launched from, with their service endpoints
+CREATE TABLE lti_contexts (
+    id SERIAL PRIMARY KEY,
+    platform_id INT NOT NULL REFERENCES lti_platforms(id) ON DELETE CASCADE,
+    context_id TEXT NOT NULL,
+    title TEXT NOT NULL DEFAULT '',
+    memberships_url TEXT,
+    lineitems_url TEXT,
+    roster_synced_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (platform_id, context_id)
+);
+
+-- Course rosters, from launches and names and roles service syncs
+CREATE TABLE lti_context_members (
+    context_id INT NOT NULL REFERENCES lti_contexts(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    roles TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'inactive')),
+    synced_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (context_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_lti_context_members_user_id ON lti_context_members(user_id);
+
+-- Resource links placed in courses, optionally bound to an exam whose
+-- attempt scores are passed back to the gradebook line item
+CREATE TABLE lti_resource_links (
+    id SERIAL PRIMARY KEY,
+    context_id INT NOT NULL REFERENCES lti_contexts(id) ON DELETE CASCADE,
+    resource_link_id TEXT NOT NULL,
+    title TEXT NOT NULL DEFAULT '',
+    exam_id INT REFERENCES exams(exam_id) ON DELETE SET NULL,
+    lineitem_url TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (context_id, resource_link_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_lti_resource_links_exam_id ON lti_resource_links(exam_id) WHERE lineitem_url IS NOT NULL;
+
+-- Outcome of passing an attempt score back to a line item
+CREATE TABLE lti_score_submissions (
+    attempt_id INT NOT NULL REFERENCES exam_attempts(attempt_id) ON DELETE CASCADE,
+    resource_link_id INT NOT NULL REFERENCES lti_resource_links(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('sent', 'failed')),
+    error TEXT,
+    attempts INT NOT NULL DEFAULT 1,
+    submitted_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (attempt_id, resource_link_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_lti_score_submissions_status ON lti_score_submissions(status, submitted_at DESC);
+
+-- Permission for LMS integration management
+INSERT INTO permissions (name, resource, action, description) VALUES
+    ('lti.manage', 'lti', 'manage', 'Register LMS platforms and sync course rosters');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r, permissions p
+WHERE r.name = 'admin'
+AND p.name = 'lti.manage';
@@ -0,0 +1,4 @@
+DELETE FROM permissions WHERE name = 'api_keys.manage';
+
+DROP TABLE IF EXISTS api_key_usage;
+DROP TABLE IF EXISTS api_keys;
@@ -0,0 +1,38 @@
+-- API keys issued to third-party tools for the public read-only API.
+-- Only a SHA-256 hash of the key is stored; the prefix identifies the key
+-- without revealing it.
+CREATE TABLE api_keys (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    contact_email VARCHAR(255) NOT NULL DEFAULT '',
+    prefix VARCHAR(16) NOT NULL UNIQUE,
+    key_hash CHAR(64) NOT NULL,
+    daily_quota INT NOT NULL CHECK (daily_quota > 0),
+    requests_per_minute INT NOT NULL CHECK (requests_per_minute > 0),
+    created_by INT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ
+);
+
+-- Daily request counts per key and endpoint
+CREATE TABLE api_key_usage (
+    api_key_id INT NOT NULL REFERENCES api_keys(id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    endpoint VARCHAR(50) NOT NULL,
+    requests BIGINT NOT NULL DEFAULT 0,
+    throttled BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (api_key_id, day, endpoint)
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_key_usage_day ON api_key_usage(day);
+
+-- Permission for public API key management
+INSERT INTO permissions (name, resource, action, description) VALUES
+    ('api_keys.manage', 'api_keys', 'manage', 'Issue and revoke public API keys and view their usage');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r, permissions p
+WHERE r.name = 'admin'
+AND p.name = 'api_keys.manage';
@@ -0,0 +1,5 @@
+DELETE FROM permissions WHERE name = 'words.import';
+
+DROP TABLE IF EXISTS word_imports;
+DROP TABLE IF EXISTS word_tags;
+DROP TABLE IF EXISTS word_list_entries;
@@ -0,0 +1,49 @@
+-- Membership of words in standard vocabulary lists (NGSL, TSL, ...) with
+-- the rank and frequency the list assigns them
+CREATE TABLE word_list_entries (
+    list_name VARCHAR(50) NOT NULL,
+    word_id INT NOT NULL REFERENCES words(id) ON DELETE CASCADE,
+    rank INT,
+    frequency REAL,
+    added_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (list_name, word_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_word_list_entries_word_id ON word_list_entries(word_id);
+
+-- Topic tags of words (business, travel, ...)
+CREATE TABLE word_tags (
+    word_id INT NOT NULL REFERENCES words(id) ON DELETE CASCADE,
+    tag VARCHAR(50) NOT NULL,
+    source VARCHAR(20) NOT NULL CHECK (source IN ('import', 'auto', 'manual')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (word_id, tag)
+);
+
+CREATE INDEX IF NOT EXISTS idx_word_tags_tag ON word_tags(tag);
+
+-- Word list imports. A preview stores the planned changes; committing applies them.
+CREATE TABLE word_imports (
+    id SERIAL PRIMARY KEY,
+    list_name VARCHAR(50) NOT NULL,
+    filename VARCHAR(255) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'committed', 'discarded')),
+    summary JSONB NOT NULL,
+    items JSONB NOT NULL,
+    result JSONB,
+    created_by INT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_word_imports_created_at ON word_imports(created_at DESC);
+
+-- Permission for importing word lists
+INSERT INTO permissions (name, resource, action, description) VALUES
+    ('words.import', 'words', 'import', 'Import standard vocabulary lists');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r, permissions p
+WHERE r.name = 'admin'
+AND p.name = 'words.import';
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS content_history;
+DROP TABLE IF EXISTS content_revisions;
+
+DROP INDEX IF EXISTS idx_grammars_status;
+ALTER TABLE writing_prompts DROP COLUMN IF EXISTS status;
+ALTER TABLE grammars DROP COLUMN IF EXISTS status;
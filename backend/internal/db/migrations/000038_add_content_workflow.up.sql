@@ -0,0 +1,52 @@
+-- Whether content has a published version. Public endpoints only serve
+-- published content; content created through the authoring workflow stays a
+-- draft until its first revision is published.
+ALTER TABLE grammars ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'published'
+    CHECK (status IN ('draft', 'published'));
+ALTER TABLE writing_prompts ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'published'
+    CHECK (status IN ('draft', 'published'));
+
+CREATE INDEX IF NOT EXISTS idx_grammars_status ON grammars(status);
+
+-- Revisions of grammar and prompt content moving through
+-- draft -> review -> (scheduled ->) published
+CREATE TABLE content_revisions (
+    id SERIAL PRIMARY KEY,
+    content_type VARCHAR(30) NOT NULL CHECK (content_type IN ('grammar', 'writing_prompt')),
+    content_id INT NOT NULL,
+    version INT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'draft'
+        CHECK (status IN ('draft', 'review', 'scheduled', 'published', 'discarded')),
+    data JSONB NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    publish_at TIMESTAMPTZ,
+    created_by INT REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_by INT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    published_at TIMESTAMPTZ,
+    UNIQUE (content_type, content_id, version)
+);
+
+-- A piece of content has at most one revision in progress
+CREATE UNIQUE INDEX IF NOT EXISTS idx_content_revisions_open
+    ON content_revisions(content_type, content_id)
+    WHERE status IN ('draft', 'review', 'scheduled');
+CREATE INDEX IF NOT EXISTS idx_content_revisions_status ON content_revisions(status, publish_at);
+
+-- Every workflow transition of a piece of content
+CREATE TABLE content_history (
+    id BIGSERIAL PRIMARY KEY,
+    content_type VARCHAR(30) NOT NULL,
+    content_id INT NOT NULL,
+    revision_id INT REFERENCES content_revisions(id) ON DELETE SET NULL,
+    action VARCHAR(30) NOT NULL,
+    from_status VARCHAR(20) NOT NULL DEFAULT '',
+    to_status VARCHAR(20) NOT NULL DEFAULT '',
+    actor_id INT REFERENCES users(id) ON DELETE SET NULL,
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_content_history_content
+    ON content_history(content_type, content_id, created_at DESC);
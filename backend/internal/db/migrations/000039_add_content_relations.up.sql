@@ -0,0 +1,15 @@
+-- Precomputed links between words, grammar, example sentences and questions.
+-- Rebuilt periodically from tags and co-occurrence; every row of one rebuild
+-- shares its computed_at so rows the rebuild did not produce can be swept.
+CREATE TABLE content_relations (
+    source_type VARCHAR(20) NOT NULL CHECK (source_type IN ('word', 'grammar', 'example', 'question')),
+    source_id INT NOT NULL,
+    target_type VARCHAR(20) NOT NULL CHECK (target_type IN ('word', 'grammar', 'example', 'question')),
+    target_id INT NOT NULL,
+    score REAL NOT NULL,
+    reason VARCHAR(20) NOT NULL,
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (source_type, source_id, target_type, target_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_content_relations_computed_at ON content_relations(computed_at);
@@ -0,0 +1,77 @@
+-- Senses of a word: one row per meaning with its part of speech and register.
+-- words.short_mean stays the one-line summary shown in lists.
+CREATE TABLE word_senses (
+    id SERIAL PRIMARY KEY,
+    word_id INT NOT NULL REFERENCES words(id) ON DELETE CASCADE,
+    position INT NOT NULL,
+    part_of_speech VARCHAR(30) NOT NULL DEFAULT '',
+    meaning TEXT NOT NULL,
+    register VARCHAR(30) NOT NULL DEFAULT '',
+    examples INT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (word_id, position)
+);
+
+CREATE INDEX IF NOT EXISTS idx_word_senses_part_of_speech ON word_senses(part_of_speech);
+
+-- Split the means JSON of existing words into senses. The part of speech and
+-- register rules match internal/wordsense, which applies them to later edits.
+INSERT INTO word_senses (word_id, position, part_of_speech, meaning, register, examples)
+SELECT w.id,
+       ROW_NUMBER() OVER (PARTITION BY w.id ORDER BY k.ord, m.ord),
+       CASE
+           WHEN k.kind LIKE 'danh từ%' OR k.kind IN ('noun', 'n') THEN 'noun'
+           WHEN k.kind LIKE '%động từ%' OR k.kind IN ('verb', 'v') THEN 'verb'
+           WHEN k.kind LIKE 'tính từ%' OR k.kind IN ('adjective', 'adj') THEN 'adjective'
+           WHEN k.kind LIKE 'phó từ%' OR k.kind IN ('adverb', 'adv') THEN 'adverb'
+           WHEN k.kind LIKE 'giới từ%' OR k.kind IN ('preposition', 'prep') THEN 'preposition'
+           WHEN k.kind LIKE 'liên từ%' OR k.kind IN ('conjunction', 'conj') THEN 'conjunction'
+           WHEN k.kind LIKE 'đại từ%' OR k.kind IN ('pronoun', 'pron') THEN 'pronoun'
+           WHEN k.kind LIKE 'thán từ%' OR k.kind IN ('interjection', 'interj') THEN 'interjection'
+           WHEN k.kind LIKE 'từ xác định%' OR k.kind LIKE 'định ngữ%' OR k.kind = 'determiner' THEN 'determiner'
+           WHEN k.kind LIKE 'tiền tố%' OR k.kind LIKE 'hậu tố%' THEN 'affix'
+           WHEN k.kind LIKE 'viết tắt%' THEN 'abbreviation'
+           WHEN k.kind LIKE 'cụm từ%' THEN 'phrase'
+           ELSE ''
+       END,
+       m.mean,
+       CASE
+           WHEN m.mean LIKE '(thông tục)%' THEN 'informal'
+           WHEN m.mean LIKE '(từ lóng)%' THEN 'slang'
+           WHEN m.mean LIKE '(từ cổ%' THEN 'archaic'
+           WHEN m.mean LIKE '(từ hiếm%' THEN 'rare'
+           WHEN m.mean LIKE '(từ Mỹ%' THEN 'american'
+           WHEN m.mean LIKE '(nghĩa bóng)%' THEN 'figurative'
+           WHEN m.mean LIKE '(thơ ca)%' OR m.mean LIKE '(văn học)%' THEN 'literary'
+           WHEN m.mean LIKE '(thương nghiệp)%' OR m.mean LIKE '(thương mại)%' OR m.mean LIKE '(kinh tế)%' THEN 'business'
+           WHEN m.mean LIKE '(pháp lý)%' THEN 'legal'
+           WHEN m.mean LIKE '(kỹ thuật)%' OR m.mean LIKE '(tin học)%' THEN 'technical'
+           ELSE ''
+       END,
+       m.examples
+FROM words w
+CROSS JOIN LATERAL (
+    SELECT LOWER(TRIM(COALESCE(value->>'kind', ''))) AS kind, value->'means' AS means, ord
+    FROM jsonb_array_elements(w.means) WITH ORDINALITY AS kinds(value, ord)
+) k
+CROSS JOIN LATERAL (
+    SELECT TRIM(value->>'mean') AS mean,
+           ARRAY(
+               SELECT e::int FROM jsonb_array_elements_text(
+                   CASE WHEN jsonb_typeof(value->'examples') = 'array' THEN value->'examples' ELSE '[]'::jsonb END
+               ) AS e
+           ) AS examples,
+           ord
+    FROM jsonb_array_elements(CASE WHEN jsonb_typeof(k.means) = 'array' THEN k.means ELSE '[]'::jsonb END)
+         WITH ORDINALITY AS means(value, ord)
+) m
+WHERE jsonb_typeof(w.means) = 'array'
+  AND COALESCE(m.mean, '') <> '';
+
+-- Words without structured meanings get their short meaning as the only sense
+INSERT INTO word_senses (word_id, position, meaning)
+SELECT w.id, 1, w.short_mean
+FROM words w
+WHERE TRIM(w.short_mean) <> ''
+  AND NOT EXISTS (SELECT 1 FROM word_senses s WHERE s.word_id = w.id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_grammars_lower_title_prefix;
+DROP INDEX IF EXISTS idx_words_lower_word_prefix;
@@ -0,0 +1,4 @@
+-- Prefix lookups for search suggestions. Fuzzy matches use the trigram
+-- indexes from 000009.
+CREATE INDEX IF NOT EXISTS idx_words_lower_word_prefix ON words (LOWER(word) text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_grammars_lower_title_prefix ON grammars (LOWER(title) text_pattern_ops);
@@ -0,0 +1,12 @@
+-- Named filter sets saved by users and applied to list endpoints with
+-- ?filter_id=. criteria holds the filter fields of internal/savedfilter.
+CREATE TABLE saved_filters (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    resource VARCHAR(30) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    criteria JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, resource, name)
+);
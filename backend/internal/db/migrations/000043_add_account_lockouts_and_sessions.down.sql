@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS user_sessions;
+DROP TABLE IF EXISTS account_lockouts;
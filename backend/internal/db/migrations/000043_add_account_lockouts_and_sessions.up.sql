@@ -0,0 +1,25 @@
+-- Failed sign-ins and locks per account. A row with locked_until in the
+-- future blocks sign-in; failed_attempts counts failures since the last
+-- successful sign-in or lock.
+CREATE TABLE account_lockouts (
+    user_id INT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    failed_attempts INT NOT NULL DEFAULT 0,
+    locked_until TIMESTAMPTZ,
+    reason VARCHAR(100) NOT NULL DEFAULT '',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Sign-ins of users, kept until their refresh token lifetime has passed.
+-- Refreshing tokens from the same client extends the session.
+CREATE TABLE user_sessions (
+    id BIGSERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    ip_address VARCHAR(64) NOT NULL DEFAULT '',
+    user_agent VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    ended_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_user_sessions_user ON user_sessions(user_id, last_seen_at DESC);
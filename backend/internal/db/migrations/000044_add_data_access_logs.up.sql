@@ -0,0 +1,14 @@
+-- Reads of a user's writings, speaking sessions and answers by other users,
+-- shown to that user and removed after the retention period.
+CREATE TABLE data_access_logs (
+    id BIGSERIAL PRIMARY KEY,
+    subject_user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    accessor_user_id INT REFERENCES users(id) ON DELETE SET NULL,
+    resource_type VARCHAR(50) NOT NULL,
+    resource_id INT NOT NULL,
+    purpose VARCHAR(50) NOT NULL,
+    accessed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_data_access_logs_subject ON data_access_logs(subject_user_id, accessed_at DESC);
+CREATE INDEX idx_data_access_logs_accessed_at ON data_access_logs(accessed_at);
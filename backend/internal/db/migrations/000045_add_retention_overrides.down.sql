@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_speaking_turns_audio_timestamp;
+DROP TABLE IF EXISTS retention_overrides;
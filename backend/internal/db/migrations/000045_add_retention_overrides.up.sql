@@ -0,0 +1,14 @@
+-- Retention periods organizations, i.e. LMS platforms registered for LTI,
+-- set for the data of their users in place of the configured defaults.
+-- 0 days keeps the data forever.
+CREATE TABLE retention_overrides (
+    platform_id INT NOT NULL REFERENCES lti_platforms(id) ON DELETE CASCADE,
+    category VARCHAR(50) NOT NULL,
+    retention_days INT NOT NULL CHECK (retention_days >= 0),
+    updated_by INT REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (platform_id, category)
+);
+
+CREATE INDEX idx_speaking_turns_audio_timestamp ON speaking_turns("timestamp")
+WHERE audio_recording_path IS NOT NULL;
@@ -0,0 +1,15 @@
+-- Focus losses, tab switches and pastes reported by the client while an
+-- exam attempt is in progress, used to compute its integrity score.
+CREATE TABLE exam_attempt_integrity_events (
+    id BIGSERIAL PRIMARY KEY,
+    attempt_id INT NOT NULL REFERENCES exam_attempts(attempt_id) ON DELETE CASCADE,
+    event_type VARCHAR(20) NOT NULL CHECK (event_type IN ('focus_loss', 'tab_switch', 'paste')),
+    occurred_at TIMESTAMPTZ NOT NULL,
+    -- Milliseconds the exam was out of focus, for focus losses and tab switches
+    duration_ms INT NOT NULL DEFAULT 0 CHECK (duration_ms >= 0),
+    -- Characters pasted, for pastes
+    paste_length INT NOT NULL DEFAULT 0 CHECK (paste_length >= 0),
+    received_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_exam_attempt_integrity_events_attempt ON exam_attempt_integrity_events(attempt_id, occurred_at);
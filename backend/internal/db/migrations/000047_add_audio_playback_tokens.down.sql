@@ -0,0 +1,6 @@
+DELETE FROM exam_attempt_integrity_events WHERE event_type IN ('audio_play', 'audio_replay_denied');
+ALTER TABLE exam_attempt_integrity_events DROP CONSTRAINT exam_attempt_integrity_events_event_type_check;
+ALTER TABLE exam_attempt_integrity_events ADD CONSTRAINT exam_attempt_integrity_events_event_type_check
+    CHECK (event_type IN ('focus_loss', 'tab_switch', 'paste'));
+
+DROP TABLE IF EXISTS audio_playback_tokens;
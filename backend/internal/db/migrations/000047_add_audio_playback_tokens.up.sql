@@ -0,0 +1,21 @@
+-- Listening audio is played through tokens issued per question of an exam
+-- attempt. Each play consumes one of max_plays and yields a short-lived
+-- signed URL; tokens stop working at expires_at.
+CREATE TABLE audio_playback_tokens (
+    id SERIAL PRIMARY KEY,
+    attempt_id INT NOT NULL REFERENCES exam_attempts(attempt_id) ON DELETE CASCADE,
+    question_id INT NOT NULL REFERENCES questions(question_id) ON DELETE CASCADE,
+    -- SHA-256 of the token handed to the client
+    token_hash TEXT NOT NULL UNIQUE,
+    max_plays INT NOT NULL CHECK (max_plays > 0),
+    plays_used INT NOT NULL DEFAULT 0 CHECK (plays_used >= 0),
+    expires_at TIMESTAMPTZ NOT NULL,
+    last_played_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (attempt_id, question_id)
+);
+
+-- Plays and refused replays of listening audio count towards the integrity score
+ALTER TABLE exam_attempt_integrity_events DROP CONSTRAINT exam_attempt_integrity_events_event_type_check;
+ALTER TABLE exam_attempt_integrity_events ADD CONSTRAINT exam_attempt_integrity_events_event_type_check
+    CHECK (event_type IN ('focus_loss', 'tab_switch', 'paste', 'audio_play', 'audio_replay_denied'));
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS exam_proctoring_photos;
+DROP TABLE IF EXISTS exam_proctoring_consents;
@@ -0,0 +1,32 @@
+-- Learners agree to webcam snapshots before they are captured. The policy
+-- version records which wording of the proctoring notice they accepted.
+CREATE TABLE exam_proctoring_consents (
+    id SERIAL PRIMARY KEY,
+    attempt_id INT NOT NULL REFERENCES exam_attempts(attempt_id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    policy_version VARCHAR(20) NOT NULL,
+    ip_address VARCHAR(64) NOT NULL DEFAULT '',
+    user_agent VARCHAR(255) NOT NULL DEFAULT '',
+    consented_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (attempt_id)
+);
+
+-- Snapshots are stored privately in the media storage under public_id and
+-- only handed out through short-lived signed URLs. Rows outlive their
+-- attempt so the retention engine can still delete the stored image.
+CREATE TABLE exam_proctoring_photos (
+    id BIGSERIAL PRIMARY KEY,
+    attempt_id INT REFERENCES exam_attempts(attempt_id) ON DELETE SET NULL,
+    user_id INT NOT NULL,
+    public_id TEXT NOT NULL UNIQUE,
+    size_bytes INT NOT NULL CHECK (size_bytes > 0),
+    captured_at TIMESTAMPTZ NOT NULL,
+    received_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    flagged BOOLEAN NOT NULL DEFAULT FALSE,
+    review_note TEXT,
+    reviewed_by INT REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_exam_proctoring_photos_attempt ON exam_proctoring_photos(attempt_id, captured_at);
+CREATE INDEX idx_exam_proctoring_photos_received_at ON exam_proctoring_photos(received_at);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS portfolio_share_links;
@@ -0,0 +1,15 @@
+-- Learners share their writing and speaking portfolio with employers or
+-- teachers through public links. Only a hash of the link token is stored.
+CREATE TABLE portfolio_share_links (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    label VARCHAR(100) NOT NULL DEFAULT '',
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    revoked_at TIMESTAMPTZ,
+    view_count INT NOT NULL DEFAULT 0,
+    last_viewed_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_portfolio_share_links_user ON portfolio_share_links(user_id, created_at);
@@ -0,0 +1,12 @@
+-- Question difficulty calibrated from how often learners answer correctly,
+-- on the same 1-6 scale as the authored questions.difficulty. Only the
+-- first answer of each learner to a question counts.
+CREATE TABLE question_calibrations (
+    question_id INT PRIMARY KEY REFERENCES questions(question_id) ON DELETE CASCADE,
+    calibrated_difficulty SMALLINT NOT NULL CHECK (calibrated_difficulty BETWEEN 1 AND 6),
+    correct_rate REAL NOT NULL CHECK (correct_rate BETWEEN 0 AND 1),
+    responses INT NOT NULL CHECK (responses > 0),
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_question_calibrations_computed_at ON question_calibrations(computed_at);
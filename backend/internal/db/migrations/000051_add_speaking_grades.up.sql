@@ -0,0 +1,18 @@
+-- Teacher grades of learners' recorded speaking turns. A row is created
+-- when a teacher claims a turn so no other teacher grades it at the same
+-- time; claims lapse at claim_expires_at unless the turn was graded.
+CREATE TABLE speaking_grades (
+    id SERIAL PRIMARY KEY,
+    turn_id INT NOT NULL UNIQUE REFERENCES speaking_turns(id) ON DELETE CASCADE,
+    student_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    grader_id INT REFERENCES users(id) ON DELETE SET NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'claimed' CHECK (status IN ('claimed', 'graded')),
+    claimed_at TIMESTAMPTZ NOT NULL,
+    claim_expires_at TIMESTAMPTZ NOT NULL,
+    scores JSONB,
+    total_score INT,
+    comment TEXT,
+    graded_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_speaking_grades_student ON speaking_grades(student_id, graded_at DESC) WHERE status = 'graded';
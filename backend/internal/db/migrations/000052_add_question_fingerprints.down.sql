@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_questions_fingerprint_trgm;
+DROP INDEX IF EXISTS idx_questions_fingerprint_hash;
+DROP FUNCTION IF EXISTS question_fingerprint(TEXT, TEXT[]);
@@ -0,0 +1,14 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Normalized text of a question used to find duplicates: the title and
+-- answer options, lowercased, with punctuation and repeated whitespace
+-- collapsed to single spaces
+CREATE OR REPLACE FUNCTION question_fingerprint(title TEXT, answers TEXT[]) RETURNS TEXT
+LANGUAGE sql IMMUTABLE PARALLEL SAFE AS $$
+    SELECT btrim(regexp_replace(lower(title || ' ' || array_to_string(answers, ' ')), '[^[:alnum:]]+', ' ', 'g'))
+$$;
+
+-- Exact duplicates share the hash of the fingerprint; near duplicates are
+-- found by trigram similarity
+CREATE INDEX idx_questions_fingerprint_hash ON questions (md5(question_fingerprint(title, possible_answers)));
+CREATE INDEX idx_questions_fingerprint_trgm ON questions USING gin (question_fingerprint(title, possible_answers) gin_trgm_ops);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS writing_prompt_topics;
+DROP TABLE IF EXISTS writing_topics;
@@ -0,0 +1,40 @@
+-- Managed taxonomy of writing prompt topics. Topics form a tree; a prompt
+-- belongs to at most one topic and filtering by a topic includes its
+-- descendants. writing_prompts.topic stays as a free-text label.
+CREATE TABLE writing_topics (
+    id SERIAL PRIMARY KEY,
+    parent_id INT REFERENCES writing_topics(id) ON DELETE RESTRICT,
+    slug VARCHAR(64) NOT NULL UNIQUE CHECK (slug ~ '^[a-z0-9]+(-[a-z0-9]+)*$'),
+    name VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    position INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (parent_id <> id)
+);
+
+CREATE INDEX idx_writing_topics_parent_id ON writing_topics(parent_id);
+
+CREATE TABLE writing_prompt_topics (
+    prompt_id INT PRIMARY KEY REFERENCES writing_prompts(id) ON DELETE CASCADE,
+    topic_id INT NOT NULL REFERENCES writing_topics(id) ON DELETE RESTRICT
+);
+
+CREATE INDEX idx_writing_prompt_topics_topic_id ON writing_prompt_topics(topic_id);
+
+-- The TOEIC writing tasks
+INSERT INTO writing_topics (slug, name, description, position) VALUES
+    ('picture-description', 'Picture description', 'Write a sentence based on a picture', 1),
+    ('business-email', 'Business email', 'Respond to a written request', 2),
+    ('opinion-essay', 'Opinion essay', 'Write an essay stating and supporting an opinion', 3);
+
+INSERT INTO writing_topics (parent_id, slug, name, position)
+SELECT t.id, c.slug, c.name, c.position
+FROM writing_topics t
+JOIN (VALUES
+    ('business-email', 'email-request', 'Requests', 1),
+    ('business-email', 'email-complaint', 'Complaints', 2),
+    ('business-email', 'email-invitation', 'Invitations', 3),
+    ('opinion-essay', 'essay-workplace', 'Workplace', 1),
+    ('opinion-essay', 'essay-education', 'Education', 2),
+    ('opinion-essay', 'essay-technology', 'Technology', 3)
+) AS c(parent, slug, name, position) ON c.parent = t.slug;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS upgrade_deliveries;
+DROP TABLE IF EXISTS upgrade_rollouts;
+DROP TABLE IF EXISTS upgrade_devices;
@@ -0,0 +1,42 @@
+-- Devices running the app. Clients register on start so upgrade
+-- notifications can target them by platform and installed version.
+CREATE TABLE upgrade_devices (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    device_id VARCHAR(128) NOT NULL,
+    platform VARCHAR(20) NOT NULL,
+    app_version VARCHAR(32) NOT NULL,
+    last_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, device_id)
+);
+
+CREATE INDEX idx_upgrade_devices_platform ON upgrade_devices(platform, last_seen_at);
+
+-- Upgrade notifications sent to the devices matching a set of targeting
+-- rules. Empty platforms, segments or version bounds match every device.
+CREATE TABLE upgrade_rollouts (
+    id SERIAL PRIMARY KEY,
+    version VARCHAR(32) NOT NULL,
+    platforms TEXT[] NOT NULL DEFAULT '{}',
+    min_app_version VARCHAR(32) NOT NULL DEFAULT '',
+    max_app_version VARCHAR(32) NOT NULL DEFAULT '',
+    segments TEXT[] NOT NULL DEFAULT '{}',
+    percentage INT NOT NULL DEFAULT 100 CHECK (percentage BETWEEN 0 AND 100),
+    created_by INT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- One row per targeted device. Devices that were offline stay pending
+-- until they next register.
+CREATE TABLE upgrade_deliveries (
+    rollout_id INT NOT NULL REFERENCES upgrade_rollouts(id) ON DELETE CASCADE,
+    device_id INT NOT NULL REFERENCES upgrade_devices(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivered', 'acknowledged')),
+    delivered_at TIMESTAMPTZ,
+    acknowledged_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (rollout_id, device_id)
+);
+
+CREATE INDEX idx_upgrade_deliveries_pending ON upgrade_deliveries(device_id) WHERE status = 'pending';
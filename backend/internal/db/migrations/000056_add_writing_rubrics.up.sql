@@ -0,0 +1,43 @@
+-- Rubrics the AI scores writing against: weighted criteria, and
+-- instructions added to the scoring prompt. A rubric belongs to a writing
+-- task (a writing topic) or to a single prompt. A prompt is scored with its
+-- own rubric, else with the rubric of its topic or of the nearest ancestor
+-- topic that has one, else with the built-in TOEIC rubric.
+CREATE TABLE writing_rubrics (
+    id SERIAL PRIMARY KEY,
+    topic_id INT UNIQUE REFERENCES writing_topics(id) ON DELETE CASCADE,
+    prompt_id INT UNIQUE REFERENCES writing_prompts(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    instructions TEXT NOT NULL DEFAULT '',
+    -- [{"key", "name", "description", "weight"}], weights in percent adding up to 100
+    criteria JSONB NOT NULL,
+    updated_by INT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK ((topic_id IS NULL) <> (prompt_id IS NULL))
+);
+
+-- Rubrics of the TOEIC writing tasks
+INSERT INTO writing_rubrics (topic_id, name, instructions, criteria)
+SELECT t.id, r.name, r.instructions, r.criteria::jsonb
+FROM writing_topics t
+JOIN (VALUES
+    ('picture-description', 'Picture description',
+     'The response is a single sentence that must use both given words and describe the picture.',
+     '[{"key": "grammar", "name": "Grammar", "description": "The sentence is grammatically correct", "weight": 50},
+       {"key": "relevance", "name": "Relevance to the picture", "description": "The sentence describes what the picture shows and uses both given words", "weight": 50}]'),
+    ('business-email', 'Business email',
+     'The response answers an email. It must complete every task the email asks for in a tone fit for business.',
+     '[{"key": "task_response", "name": "Task completion", "description": "Every request or question of the email is answered", "weight": 40},
+       {"key": "organization", "name": "Organization", "description": "Greeting, body and closing, with one purpose per paragraph", "weight": 20},
+       {"key": "tone", "name": "Tone and register", "description": "Polite and appropriate for the reader", "weight": 15},
+       {"key": "grammar", "name": "Grammar", "description": "Accuracy and variety of grammatical structures", "weight": 15},
+       {"key": "vocabulary", "name": "Vocabulary", "description": "Range and accuracy of word choice", "weight": 10}]'),
+    ('opinion-essay', 'Opinion essay',
+     'The response is an essay of at least 300 words stating and supporting an opinion on the question.',
+     '[{"key": "task_response", "name": "Position", "description": "States a clear opinion that answers the question", "weight": 20},
+       {"key": "development", "name": "Development", "description": "Reasons are supported with explanations, details and examples", "weight": 25},
+       {"key": "organization", "name": "Organization", "description": "Logical structure with an introduction, body and conclusion", "weight": 20},
+       {"key": "grammar", "name": "Grammar", "description": "Accuracy and variety of grammatical structures", "weight": 20},
+       {"key": "vocabulary", "name": "Vocabulary", "description": "Range, accuracy and appropriateness of word choice", "weight": 15}]')
+) AS r(slug, name, instructions, criteria) ON r.slug = t.slug;
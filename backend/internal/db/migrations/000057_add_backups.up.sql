@@ -0,0 +1,28 @@
+-- Catalog of database backups. Listing, status and cleanup read it instead
+-- of scanning the backup storage; backups found in storage but not here, or
+-- recorded here but gone from storage, are reconciled by the backup manager.
+-- The table is part of the backups it describes, so a restore rolls it back
+-- and the catalog is reconciled with the storage afterwards.
+CREATE TABLE backups (
+    id SERIAL PRIMARY KEY,
+    filename VARCHAR(255) NOT NULL UNIQUE,
+    type VARCHAR(32) NOT NULL,
+    mode VARCHAR(16) NOT NULL DEFAULT 'full',
+    -- completed, failed, missing (gone from storage) or deleted
+    status VARCHAR(16) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    database_name VARCHAR(100) NOT NULL DEFAULT '',
+    storage_type VARCHAR(16) NOT NULL DEFAULT 'local',
+    size BIGINT NOT NULL DEFAULT 0,
+    -- SHA-256 of the SQL before compression and encryption
+    checksum VARCHAR(64) NOT NULL DEFAULT '',
+    compressed BOOLEAN NOT NULL DEFAULT FALSE,
+    encrypted BOOLEAN NOT NULL DEFAULT FALSE,
+    schema_version VARCHAR(32) NOT NULL DEFAULT '',
+    duration_ms BIGINT NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_backups_status_created_at ON backups (status, created_at DESC);
@@ -0,0 +1,10 @@
+COMMENT ON COLUMN user_answers.selected_answer IS 'User selected answer text';
+COMMENT ON COLUMN questions.true_answer IS NULL;
+COMMENT ON COLUMN questions.possible_answers IS NULL;
+
+DROP TABLE IF EXISTS question_option_mismatches;
+
+ALTER TABLE user_answers DROP COLUMN IF EXISTS selected_key;
+ALTER TABLE questions
+    DROP COLUMN IF EXISTS answer_key,
+    DROP COLUMN IF EXISTS options;
@@ -0,0 +1,77 @@
+-- Answer options of questions keyed A, B, C... so answers refer to an
+-- option by its key instead of its text
+ALTER TABLE questions
+    ADD COLUMN options JSONB NOT NULL DEFAULT '[]',
+    ADD COLUMN answer_key TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE user_answers
+    ADD COLUMN selected_key TEXT NOT NULL DEFAULT '';
+
+-- Key the existing options in their order
+UPDATE questions q
+SET options = COALESCE((
+    SELECT jsonb_agg(jsonb_build_object('key', chr(64 + n::int), 'text', answer) ORDER BY n)
+    FROM unnest(q.possible_answers) WITH ORDINALITY AS a(answer, n)
+), '[]');
+
+-- Answers whose text does not match exactly one option, ignoring
+-- surrounding whitespace, are left without a key and listed here to be
+-- fixed by hand. matching_options is 0 when no option matched.
+CREATE TABLE question_option_mismatches (
+    id SERIAL PRIMARY KEY,
+    question_id INTEGER NOT NULL REFERENCES questions(question_id) ON DELETE CASCADE,
+    user_answer_id INTEGER REFERENCES user_answers(user_answer_id) ON DELETE CASCADE,
+    answer_text TEXT NOT NULL,
+    matching_options INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW() NOT NULL
+);
+
+-- Point the correct answers and the answers given at their option
+UPDATE questions q
+SET answer_key = m.answer_key
+FROM (
+    SELECT q.question_id, MIN(o->>'key') AS answer_key
+    FROM questions q, jsonb_array_elements(q.options) o
+    WHERE btrim(o->>'text') = btrim(q.true_answer)
+    GROUP BY q.question_id
+    HAVING COUNT(*) = 1
+) m
+WHERE q.question_id = m.question_id;
+
+UPDATE user_answers ua
+SET selected_key = m.selected_key
+FROM (
+    SELECT ua.user_answer_id, MIN(o->>'key') AS selected_key
+    FROM user_answers ua
+    JOIN questions q ON q.question_id = ua.question_id,
+    jsonb_array_elements(q.options) o
+    WHERE btrim(o->>'text') = btrim(ua.selected_answer)
+    GROUP BY ua.user_answer_id
+    HAVING COUNT(*) = 1
+) m
+WHERE ua.user_answer_id = m.user_answer_id;
+
+INSERT INTO question_option_mismatches (question_id, answer_text, matching_options)
+SELECT q.question_id, q.true_answer, (
+    SELECT COUNT(*) FROM jsonb_array_elements(q.options) o
+    WHERE btrim(o->>'text') = btrim(q.true_answer)
+)
+FROM questions q
+WHERE q.answer_key = '' AND jsonb_array_length(q.options) > 0;
+
+INSERT INTO question_option_mismatches (question_id, user_answer_id, answer_text, matching_options)
+SELECT ua.question_id, ua.user_answer_id, ua.selected_answer, (
+    SELECT COUNT(*) FROM jsonb_array_elements(q.options) o
+    WHERE btrim(o->>'text') = btrim(ua.selected_answer)
+)
+FROM user_answers ua
+JOIN questions q ON q.question_id = ua.question_id
+WHERE ua.selected_key = '' AND jsonb_array_length(q.options) > 0;
+
+COMMENT ON COLUMN questions.options IS 'Answer options as [{"key": "A", "text": "..."}]';
+COMMENT ON COLUMN questions.answer_key IS 'Key of the correct option';
+COMMENT ON COLUMN questions.possible_answers IS 'Texts of the options, kept in step with options for older clients';
+COMMENT ON COLUMN questions.true_answer IS 'Text of the correct option, kept in step with answer_key for older clients';
+COMMENT ON COLUMN user_answers.selected_key IS 'Key of the selected option';
+COMMENT ON COLUMN user_answers.selected_answer IS 'Text of the selected option, kept in step with selected_key for older clients';
+COMMENT ON TABLE question_option_mismatches IS 'Answers that did not match exactly one option when options were keyed';
@@ -0,0 +1,3 @@
+ALTER TABLE user_profiles ADD COLUMN preferred_language VARCHAR(10);
+
+COMMENT ON COLUMN user_profiles.preferred_language IS 'Language of API messages when the request does not ask for one';
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_backups_type_created_at;
+
+ALTER TABLE backups
+    DROP COLUMN IF EXISTS key_id,
+    DROP COLUMN IF EXISTS db_version,
+    DROP COLUMN IF EXISTS app_version;
@@ -0,0 +1,12 @@
+-- Metadata of cataloged backups, so they can be searched without reading
+-- the metadata files next to them
+ALTER TABLE backups
+    ADD COLUMN app_version VARCHAR(32) NOT NULL DEFAULT '',
+    ADD COLUMN db_version VARCHAR(64) NOT NULL DEFAULT '',
+    ADD COLUMN key_id VARCHAR(64) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN backups.app_version IS 'Version of the server that made the backup';
+COMMENT ON COLUMN backups.db_version IS 'PostgreSQL server version the backup was taken from';
+COMMENT ON COLUMN backups.key_id IS 'Master key wrapping the data key of an encrypted backup';
+
+CREATE INDEX idx_backups_type_created_at ON backups (type, created_at DESC);
@@ -0,0 +1,7 @@
+-- Links migration backups to the schema versions they were taken between:
+-- schema_version is the version backed up, migration_target the version
+-- the migration went to
+ALTER TABLE backups
+    ADD COLUMN migration_target VARCHAR(32) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN backups.migration_target IS 'Schema version a migration backup was taken before migrating to';
@@ -0,0 +1,5 @@
+-- Protected backups are never deleted by retention, pruning or by hand
+ALTER TABLE backups
+    ADD COLUMN protected BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN backups.protected IS 'Whether the backup may not be deleted';
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_user_writings_submitted_at;
+DROP INDEX IF EXISTS idx_user_writings_search;
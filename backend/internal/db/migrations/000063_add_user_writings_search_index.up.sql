@@ -0,0 +1,7 @@
+-- Full-text search over writing submissions and the text of their AI
+-- feedback. Queries have to use the same expression to use the index.
+CREATE INDEX IF NOT EXISTS idx_user_writings_search ON user_writings USING gin (
+    (to_tsvector('english', submission_text) || jsonb_to_tsvector('english', COALESCE(ai_feedback, '{}'::jsonb), '["string"]'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_writings_submitted_at ON user_writings (submitted_at DESC);
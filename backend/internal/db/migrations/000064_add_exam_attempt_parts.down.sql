@@ -0,0 +1,9 @@
+DELETE FROM exam_attempts WHERE part_id IS NOT NULL;
+
+COMMENT ON COLUMN exam_attempts.score IS 'TOEIC score (0-990), NULL if not completed';
+
+DROP INDEX IF EXISTS idx_exam_attempts_part_id;
+
+ALTER TABLE exam_attempts
+    DROP COLUMN IF EXISTS time_limit_minutes,
+    DROP COLUMN IF EXISTS part_id;
@@ -0,0 +1,12 @@
+-- Part practice: attempts scoped to a single part of an exam, with their
+-- own time limit and scored as the percentage of correct answers. They are
+-- left out of the exam's leaderboard and of full test statistics.
+ALTER TABLE exam_attempts
+    ADD COLUMN part_id INTEGER REFERENCES parts(part_id) ON DELETE CASCADE,
+    ADD COLUMN time_limit_minutes INTEGER CHECK (time_limit_minutes > 0);
+
+CREATE INDEX idx_exam_attempts_part_id ON exam_attempts (part_id) WHERE part_id IS NOT NULL;
+
+COMMENT ON COLUMN exam_attempts.part_id IS 'Part practiced, NULL for a full test';
+COMMENT ON COLUMN exam_attempts.time_limit_minutes IS 'Time limit of a part practice, NULL to use the exam''s';
+COMMENT ON COLUMN exam_attempts.score IS 'TOEIC score (0-990), or percentage of correct answers (0-100) for a part practice, NULL if not completed';
@@ -0,0 +1,18 @@
+-- Full test score predicted for each learner from their answers in
+-- completed attempts, including part practice. Recomputed nightly, as
+-- question difficulty is recalibrated, and after each completed attempt.
+CREATE TABLE score_predictions (
+    user_id INT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    score SMALLINT NOT NULL CHECK (score BETWEEN 10 AND 990),
+    score_low SMALLINT NOT NULL CHECK (score_low BETWEEN 10 AND 990),
+    score_high SMALLINT NOT NULL CHECK (score_high BETWEEN 10 AND 990),
+    answers INT NOT NULL CHECK (answers > 0),
+    prediction JSONB NOT NULL,
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (score_low <= score AND score <= score_high)
+);
+
+COMMENT ON COLUMN score_predictions.score_low IS 'Lower bound of the 95% confidence interval of score';
+COMMENT ON COLUMN score_predictions.score_high IS 'Upper bound of the 95% confidence interval of score';
+COMMENT ON COLUMN score_predictions.answers IS 'Answered questions the prediction is based on';
+COMMENT ON COLUMN score_predictions.prediction IS 'Section scores and per-part accuracy the score is made of';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS question_transcripts;
+ALTER TABLE exams DROP COLUMN IF EXISTS transcripts_enabled;
@@ -0,0 +1,13 @@
+-- Transcripts of the audio of listening questions. Learners read them in
+-- part practice once they answered the question, and in a full test once
+-- the attempt is completed, for exams that offer transcripts.
+ALTER TABLE exams ADD COLUMN transcripts_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN exams.transcripts_enabled IS 'Whether learners can reveal the transcripts of the exam''s listening questions';
+
+CREATE TABLE question_transcripts (
+    question_id INT PRIMARY KEY REFERENCES questions(question_id) ON DELETE CASCADE,
+    transcript TEXT NOT NULL CHECK (length(btrim(transcript)) > 0),
+    updated_by INT REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
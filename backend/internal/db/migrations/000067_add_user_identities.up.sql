@@ -0,0 +1,16 @@
+-- Google and Apple accounts linked to local users for social login. A
+-- provider account signs in the user it is linked to; accounts are never
+-- linked by email, only on sign-up or by the signed-in user.
+CREATE TABLE user_identities (
+    provider VARCHAR(20) NOT NULL,
+    subject TEXT NOT NULL,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (provider, subject),
+    UNIQUE (user_id, provider)
+);
+
+COMMENT ON COLUMN user_identities.provider IS 'Identity provider: google or apple';
+COMMENT ON COLUMN user_identities.subject IS 'Stable ID of the user at the provider, the sub claim of its ID tokens';
+COMMENT ON COLUMN user_identities.last_used_at IS 'When the provider account last signed in';
@@ -0,0 +1,9 @@
+-- Transcripts revealed in part practice before the attempt was completed.
+-- The answer to a question whose transcript was revealed is final, so the
+-- transcript cannot help to correct it.
+CREATE TABLE transcript_reveals (
+    attempt_id INT NOT NULL REFERENCES exam_attempts(attempt_id) ON DELETE CASCADE,
+    question_id INT NOT NULL REFERENCES questions(question_id) ON DELETE CASCADE,
+    revealed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (attempt_id, question_id)
+);
@@ -0,0 +1,39 @@
+// Package migrations holds the schema migrations applied with golang-migrate.
+// They are embedded so the binary knows which schema version it was built
+// against.
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+//go:embed *.sql
+var files embed.FS
+
+// FS returns the migration files
+func FS() fs.FS {
+	return files
+}
+
+// LatestVersion returns the version of the newest migration
+func LatestVersion() uint {
+	entries, err := fs.ReadDir(files, ".")
+	if err != nil {
+		return 0
+	}
+	var latest uint
+	for _, entry := range entries {
+		prefix, _, ok := strings.Cut(entry.Name(), "_")
+		if !ok {
+			continue
+		}
+		version, err := strconv.ParseUint(prefix, 10, 64)
+		if err == nil && uint(version) > latest {
+			latest = uint(version)
+		}
+	}
+	return latest
+}
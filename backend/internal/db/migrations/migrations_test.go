@@ -0,0 +1,27 @@
+package migrations
+
+import (
+	"fmt"
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatestVersion(t *testing.T) {
+	up, err := fs.Glob(FS(), "*.up.sql")
+	require.NoError(t, err)
+	require.NotEmpty(t, up)
+
+	// Glob returns names in lexical order and versions are zero padded
+	newest := up[len(up)-1]
+	assert.True(t, strings.HasPrefix(newest, fmt.Sprintf("%06d_", LatestVersion())), newest)
+
+	// Every migration can be rolled back
+	for _, name := range up {
+		_, err := fs.Stat(FS(), strings.TrimSuffix(name, ".up.sql")+".down.sql")
+		assert.NoError(t, err, name)
+	}
+}
@@ -0,0 +1,133 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"os"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openTestSchema connects to the database in TEST_DB_SOURCE and works in a
+// new schema, dropped when the test ends. The test is skipped without one.
+func openTestSchema(t *testing.T) *sql.Conn {
+	t.Helper()
+	source := os.Getenv("TEST_DB_SOURCE")
+	if source == "" {
+		t.Skip("TEST_DB_SOURCE is not set")
+	}
+	conn, err := sql.Open("postgres", source)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	ctx := context.Background()
+	session, err := conn.Conn(ctx)
+	require.NoError(t, err)
+	schema := fmt.Sprintf("migration_test_%d", time.Now().UnixNano())
+	_, err = session.ExecContext(ctx, "CREATE SCHEMA "+schema+"; SET search_path TO "+schema)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		session.ExecContext(context.Background(), "DROP SCHEMA "+schema+" CASCADE")
+		session.Close()
+	})
+	return session
+}
+
+func execFile(t *testing.T, conn *sql.Conn, name string) {
+	t.Helper()
+	query, err := fs.ReadFile(FS(), name)
+	require.NoError(t, err)
+	_, err = conn.ExecContext(context.Background(), string(query))
+	require.NoError(t, err, name)
+}
+
+func TestQuestionOptionsKeysAnswersByTrimmedText(t *testing.T) {
+	conn := openTestSchema(t)
+	ctx := context.Background()
+
+	// The columns of questions and user_answers the migration reads
+	_, err := conn.ExecContext(ctx, `
+CREATE TABLE questions (
+    question_id SERIAL PRIMARY KEY,
+    possible_answers TEXT[] NOT NULL,
+    true_answer TEXT NOT NULL
+);
+CREATE TABLE user_answers (
+    user_answer_id SERIAL PRIMARY KEY,
+    question_id INTEGER NOT NULL REFERENCES questions(question_id) ON DELETE CASCADE,
+    selected_answer TEXT NOT NULL
+);
+INSERT INTO questions (question_id, possible_answers, true_answer) VALUES
+    (1, ARRAY['on time', 'late ', 'early'], ' late'),
+    (2, ARRAY['yes', 'no', 'yes'], 'yes'),
+    (3, ARRAY['red', 'blue'], 'green'),
+    (4, ARRAY[]::TEXT[], 'written answer');
+INSERT INTO user_answers (user_answer_id, question_id, selected_answer) VALUES
+    (1, 1, 'early'),
+    (2, 1, 'soon'),
+    (3, 2, 'no'),
+    (4, 2, 'yes');
+`)
+	require.NoError(t, err)
+
+	execFile(t, conn, "000058_add_question_options.up.sql")
+
+	answerKeys := map[int]string{}
+	rows, err := conn.QueryContext(ctx, "SELECT question_id, answer_key FROM questions")
+	require.NoError(t, err)
+	for rows.Next() {
+		var id int
+		var key string
+		require.NoError(t, rows.Scan(&id, &key))
+		answerKeys[id] = key
+	}
+	require.NoError(t, rows.Err())
+	assert.Equal(t, map[int]string{1: "B", 2: "", 3: "", 4: ""}, answerKeys)
+
+	selectedKeys := map[int]string{}
+	rows, err = conn.QueryContext(ctx, "SELECT user_answer_id, selected_key FROM user_answers")
+	require.NoError(t, err)
+	for rows.Next() {
+		var id int
+		var key string
+		require.NoError(t, rows.Scan(&id, &key))
+		selectedKeys[id] = key
+	}
+	require.NoError(t, rows.Err())
+	assert.Equal(t, map[int]string{1: "C", 2: "", 3: "B", 4: ""}, selectedKeys)
+
+	// Duplicate and unmatched texts are collected instead of guessed
+	type mismatch struct {
+		questionID   int
+		userAnswerID sql.NullInt32
+		text         string
+		matching     int
+	}
+	var mismatches []mismatch
+	rows, err = conn.QueryContext(ctx, `SELECT question_id, user_answer_id, answer_text, matching_options
+FROM question_option_mismatches ORDER BY question_id, user_answer_id NULLS FIRST`)
+	require.NoError(t, err)
+	for rows.Next() {
+		var m mismatch
+		require.NoError(t, rows.Scan(&m.questionID, &m.userAnswerID, &m.text, &m.matching))
+		mismatches = append(mismatches, m)
+	}
+	require.NoError(t, rows.Err())
+	assert.Equal(t, []mismatch{
+		{questionID: 1, userAnswerID: sql.NullInt32{Int32: 2, Valid: true}, text: "soon", matching: 0},
+		{questionID: 2, text: "yes", matching: 2},
+		{questionID: 2, userAnswerID: sql.NullInt32{Int32: 4, Valid: true}, text: "yes", matching: 2},
+		{questionID: 3, text: "green", matching: 0},
+	}, mismatches)
+
+	execFile(t, conn, "000058_add_question_options.down.sql")
+	var exists bool
+	require.NoError(t, conn.QueryRowContext(ctx, "SELECT to_regclass('question_option_mismatches') IS NOT NULL").Scan(&exists))
+	assert.False(t, exists)
+}
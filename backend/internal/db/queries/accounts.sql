@@ -0,0 +1,69 @@
+-- name: GetAccountLockout :one
+SELECT * FROM account_lockouts
+WHERE user_id = $1;
+
+-- name: RecordFailedLogin :one
+INSERT INTO account_lockouts (user_id, failed_attempts)
+VALUES ($1, 1)
+ON CONFLICT (user_id) DO UPDATE SET
+  failed_attempts = account_lockouts.failed_attempts + 1,
+  updated_at = NOW()
+RETURNING *;
+
+-- name: LockAccount :one
+INSERT INTO account_lockouts (user_id, failed_attempts, locked_until, reason)
+VALUES ($1, 0, $2, $3)
+ON CONFLICT (user_id) DO UPDATE SET
+  failed_attempts = 0,
+  locked_until = EXCLUDED.locked_until,
+  reason = EXCLUDED.reason,
+  updated_at = NOW()
+RETURNING *;
+
+-- name: UnlockAccount :execrows
+DELETE FROM account_lockouts
+WHERE user_id = $1;
+
+-- name: ResetFailedLogins :exec
+-- Keeps locks that have not expired
+DELETE FROM account_lockouts
+WHERE user_id = $1 AND (locked_until IS NULL OR locked_until <= NOW());
+
+-- name: CreateUserSession :one
+INSERT INTO user_sessions (user_id, ip_address, user_agent, expires_at)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: TouchUserSession :execrows
+-- Extends the latest open session of the client
+UPDATE user_sessions
+SET last_seen_at = NOW(), ip_address = $3, expires_at = $4
+WHERE id = (
+  SELECT s.id FROM user_sessions s
+  WHERE s.user_id = $1 AND s.user_agent = $2 AND s.ended_at IS NULL AND s.expires_at > NOW()
+  ORDER BY s.last_seen_at DESC
+  LIMIT 1
+);
+
+-- name: EndUserSession :execrows
+-- Ends the latest open session of the client
+UPDATE user_sessions
+SET ended_at = NOW()
+WHERE id = (
+  SELECT s.id FROM user_sessions s
+  WHERE s.user_id = $1 AND s.user_agent = $2 AND s.ended_at IS NULL AND s.expires_at > NOW()
+  ORDER BY s.last_seen_at DESC
+  LIMIT 1
+);
+
+-- name: ListUserSessions :many
+-- Only open sessions unless include_closed is set
+SELECT * FROM user_sessions
+WHERE user_id = sqlc.arg(user_id)
+  AND (sqlc.arg(include_closed)::bool OR (ended_at IS NULL AND expires_at > NOW()))
+ORDER BY last_seen_at DESC
+LIMIT sqlc.arg('limit')::int;
+
+-- name: DeleteStaleUserSessions :execrows
+DELETE FROM user_sessions
+WHERE user_id = $1 AND (expires_at < $2 OR ended_at < $2);
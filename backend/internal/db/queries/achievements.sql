@@ -0,0 +1,90 @@
+-- name: CreateBadge :one
+INSERT INTO badges (
+  code,
+  name,
+  description,
+  icon_url,
+  rule_type,
+  activity_type,
+  threshold,
+  is_active
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING *;
+
+-- name: GetBadge :one
+SELECT * FROM badges
+WHERE id = $1;
+
+-- name: ListBadges :many
+SELECT * FROM badges
+ORDER BY id;
+
+-- name: UpdateBadge :one
+UPDATE badges
+SET
+  name = $2,
+  description = $3,
+  icon_url = $4,
+  rule_type = $5,
+  activity_type = $6,
+  threshold = $7,
+  is_active = $8
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteBadge :execrows
+DELETE FROM badges
+WHERE id = $1;
+
+-- name: ListUnearnedBadges :many
+SELECT * FROM badges b
+WHERE b.is_active
+  AND NOT EXISTS (
+    SELECT 1 FROM user_badges ub
+    WHERE ub.badge_id = b.id AND ub.user_id = $1
+  )
+ORDER BY b.id;
+
+-- name: AwardBadge :execrows
+INSERT INTO user_badges (user_id, badge_id, activity_id)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, badge_id) DO NOTHING;
+
+-- name: ListUserBadges :many
+SELECT b.id, b.code, b.name, b.description, b.icon_url, ub.awarded_at
+FROM user_badges ub
+JOIN badges b ON b.id = ub.badge_id
+WHERE ub.user_id = $1
+ORDER BY ub.awarded_at DESC, b.id;
+
+-- name: GetEventCursor :one
+SELECT last_id FROM event_cursors
+WHERE name = $1;
+
+-- name: UpsertEventCursor :exec
+INSERT INTO event_cursors (name, last_id)
+VALUES ($1, $2)
+ON CONFLICT (name) DO UPDATE
+SET last_id = EXCLUDED.last_id, updated_at = NOW();
+
+-- name: ListActivitiesAfter :many
+SELECT * FROM user_activities
+WHERE id > sqlc.arg(after_id)
+ORDER BY id
+LIMIT sqlc.arg(max_items);
+
+-- name: CountUserActivitiesByType :many
+SELECT type, COUNT(*) AS count
+FROM user_activities
+WHERE user_id = $1
+GROUP BY type;
+
+-- name: GetUserTotalXP :one
+SELECT COALESCE(SUM(xp), 0)::bigint AS total_xp
+FROM user_activities
+WHERE user_id = $1;
+
+-- name: CountMasteredWords :one
+SELECT COUNT(*) FROM vocabulary_stats
+WHERE user_id = $1 AND mastery_level >= 8;
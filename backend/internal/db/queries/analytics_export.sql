@@ -0,0 +1,7 @@
+-- name: ListActivitiesBetween :many
+SELECT * FROM user_activities
+WHERE created_at >= sqlc.arg(from_time)
+  AND created_at < sqlc.arg(to_time)
+  AND id > sqlc.arg(after_id)
+ORDER BY id
+LIMIT sqlc.arg(max_items);
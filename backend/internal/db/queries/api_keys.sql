@@ -0,0 +1,64 @@
+-- name: CreateAPIKey :one
+INSERT INTO api_keys (name, contact_email, prefix, key_hash, daily_quota, requests_per_minute, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetAPIKey :one
+SELECT * FROM api_keys
+WHERE id = $1;
+
+-- name: GetAPIKeyByPrefix :one
+SELECT * FROM api_keys
+WHERE prefix = $1;
+
+-- name: ListAPIKeys :many
+SELECT * FROM api_keys
+ORDER BY id;
+
+-- name: UpdateAPIKeyLimits :one
+UPDATE api_keys
+SET daily_quota = $2, requests_per_minute = $3
+WHERE id = $1
+RETURNING *;
+
+-- name: RevokeAPIKey :one
+UPDATE api_keys
+SET revoked_at = NOW()
+WHERE id = $1 AND revoked_at IS NULL
+RETURNING *;
+
+-- name: TouchAPIKey :exec
+UPDATE api_keys
+SET last_used_at = NOW()
+WHERE id = $1;
+
+-- name: AddAPIKeyUsage :exec
+INSERT INTO api_key_usage (api_key_id, day, endpoint, requests, throttled)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (api_key_id, day, endpoint) DO UPDATE
+SET requests = api_key_usage.requests + EXCLUDED.requests,
+    throttled = api_key_usage.throttled + EXCLUDED.throttled;
+
+-- name: GetAPIKeyDailyRequests :one
+SELECT COALESCE(SUM(requests), 0)::bigint AS requests
+FROM api_key_usage
+WHERE api_key_id = $1 AND day = $2;
+
+-- name: ListAPIKeyUsage :many
+SELECT day, endpoint, requests, throttled
+FROM api_key_usage
+WHERE api_key_id = $1 AND day >= $2
+ORDER BY day, endpoint;
+
+-- name: ListAPIKeyUsageTotals :many
+SELECT k.id AS api_key_id,
+       k.name,
+       k.prefix,
+       k.daily_quota,
+       COALESCE(SUM(u.requests), 0)::bigint AS requests,
+       COALESCE(SUM(u.throttled), 0)::bigint AS throttled
+FROM api_keys k
+LEFT JOIN api_key_usage u ON u.api_key_id = k.id AND u.day >= $1
+WHERE k.revoked_at IS NULL
+GROUP BY k.id, k.name, k.prefix, k.daily_quota
+ORDER BY requests DESC, k.id;
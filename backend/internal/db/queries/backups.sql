@@ -0,0 +1,63 @@
+-- name: UpsertBackup :one
+-- Records a backup, replacing what was recorded under its filename
+INSERT INTO backups (
+    filename, type, mode, status, description, database_name, storage_type, size,
+    checksum, compressed, encrypted, schema_version, duration_ms, error, created_at,
+    app_version, db_version, key_id, migration_target
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19
+)
+ON CONFLICT (filename) DO UPDATE SET
+    type = EXCLUDED.type,
+    mode = EXCLUDED.mode,
+    status = EXCLUDED.status,
+    description = EXCLUDED.description,
+    database_name = EXCLUDED.database_name,
+    storage_type = EXCLUDED.storage_type,
+    size = EXCLUDED.size,
+    checksum = EXCLUDED.checksum,
+    compressed = EXCLUDED.compressed,
+    encrypted = EXCLUDED.encrypted,
+    schema_version = EXCLUDED.schema_version,
+    duration_ms = EXCLUDED.duration_ms,
+    error = EXCLUDED.error,
+    created_at = EXCLUDED.created_at,
+    app_version = EXCLUDED.app_version,
+    db_version = EXCLUDED.db_version,
+    key_id = EXCLUDED.key_id,
+    migration_target = EXCLUDED.migration_target,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: ListBackupsByStatus :many
+SELECT * FROM backups
+WHERE status = $1
+ORDER BY created_at DESC, id DESC;
+
+-- name: ListRecentBackups :many
+SELECT * FROM backups
+ORDER BY created_at DESC, id DESC
+LIMIT $1;
+
+-- name: SearchBackups :many
+-- Lists the backups matching the filters, newest first. An empty status,
+-- type or search text and a null bound match every backup.
+SELECT * FROM backups
+WHERE (sqlc.arg(status)::text = '' OR status = sqlc.arg(status))
+  AND (sqlc.arg(type)::text = '' OR type = sqlc.arg(type))
+  AND (sqlc.narg(created_from)::timestamptz IS NULL OR created_at >= sqlc.narg(created_from))
+  AND (sqlc.narg(created_to)::timestamptz IS NULL OR created_at < sqlc.narg(created_to))
+  AND (sqlc.arg(search)::text = ''
+       OR filename ILIKE '%' || sqlc.arg(search) || '%'
+       OR description ILIKE '%' || sqlc.arg(search) || '%')
+ORDER BY created_at DESC, id DESC;
+
+-- name: SetBackupStatus :exec
+UPDATE backups
+SET status = $2, updated_at = NOW()
+WHERE filename = $1;
+
+-- name: SetBackupProtected :execrows
+UPDATE backups
+SET protected = $2, updated_at = NOW()
+WHERE filename = $1;
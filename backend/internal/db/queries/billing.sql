@@ -0,0 +1,153 @@
+-- name: ListPlans :many
+SELECT * FROM plans
+ORDER BY id;
+
+-- name: ListActivePlans :many
+SELECT * FROM plans
+WHERE is_active = TRUE
+ORDER BY id;
+
+-- name: GetPlan :one
+SELECT * FROM plans
+WHERE id = $1;
+
+-- name: GetPlanByCode :one
+SELECT * FROM plans
+WHERE code = $1;
+
+-- name: GetPlanByProduct :one
+SELECT p.* FROM plans p
+JOIN plan_products pp ON pp.plan_id = p.id
+WHERE pp.provider = $1 AND pp.product_id = $2;
+
+-- name: CreatePlan :one
+INSERT INTO plans (
+  code,
+  name,
+  description,
+  entitlements,
+  is_active
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: UpdatePlan :one
+UPDATE plans
+SET name = $2, description = $3, entitlements = $4, is_active = $5
+WHERE id = $1
+RETURNING *;
+
+-- name: ListPlanProducts :many
+SELECT * FROM plan_products
+ORDER BY plan_id, provider, product_id;
+
+-- name: CreatePlanProduct :exec
+INSERT INTO plan_products (provider, product_id, plan_id)
+VALUES ($1, $2, $3);
+
+-- name: DeletePlanProducts :exec
+DELETE FROM plan_products
+WHERE plan_id = $1;
+
+-- name: GetSubscriptionByExternalID :one
+SELECT * FROM subscriptions
+WHERE provider = $1 AND external_id = $2;
+
+-- name: ListUserSubscriptions :many
+SELECT * FROM subscriptions
+WHERE user_id = $1
+ORDER BY created_at DESC;
+
+-- name: CreateSubscription :one
+INSERT INTO subscriptions (
+  user_id,
+  plan_id,
+  provider,
+  external_id,
+  status,
+  current_period_end,
+  last_event_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: UpdateSubscriptionState :one
+UPDATE subscriptions
+SET plan_id = $2, status = $3, current_period_end = $4, last_event_at = $5
+WHERE id = $1
+RETURNING *;
+
+-- name: ExpireLapsedSubscriptions :execrows
+UPDATE subscriptions
+SET status = 'expired'
+WHERE status IN ('active', 'trialing', 'grace_period', 'canceled')
+  AND current_period_end < $1;
+
+-- name: ListUserEntitlements :many
+SELECT entitlement FROM (
+  SELECT unnest(p.entitlements) AS entitlement
+  FROM subscriptions s
+  JOIN plans p ON p.id = s.plan_id
+  WHERE s.user_id = $1
+    AND s.status IN ('active', 'trialing', 'grace_period', 'canceled')
+    AND (s.current_period_end IS NULL OR s.current_period_end > NOW())
+  UNION
+  SELECT unnest(p.entitlements) AS entitlement
+  FROM user_roles ur
+  JOIN roles r ON r.id = ur.role_id
+  JOIN plans p ON p.code = r.name
+  WHERE ur.user_id = $1
+    AND (ur.expires_at IS NULL OR ur.expires_at > NOW())
+) e
+ORDER BY entitlement;
+
+-- name: CreateBillingEvent :one
+INSERT INTO billing_events (
+  provider,
+  event_id,
+  event_type,
+  external_id,
+  product_id,
+  status,
+  period_end,
+  occurred_at,
+  payload
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9
+)
+ON CONFLICT (provider, event_id) DO UPDATE
+SET result = 'received', error = NULL
+WHERE billing_events.result = 'failed'
+RETURNING id;
+
+-- name: SetBillingEventResult :exec
+UPDATE billing_events
+SET result = $2, error = $3
+WHERE id = $1;
+
+-- name: ListUnmatchedBillingEvents :many
+SELECT * FROM billing_events
+WHERE provider = $1 AND external_id = $2 AND result = 'unmatched'
+ORDER BY occurred_at, id;
+
+-- name: ListBillingEvents :many
+SELECT * FROM billing_events
+WHERE sqlc.arg(result)::text = '' OR result = sqlc.arg(result)::text
+ORDER BY received_at DESC
+LIMIT sqlc.arg(max_items) OFFSET sqlc.arg(skip);
+
+-- name: ConsumeEntitlementUsage :execrows
+INSERT INTO entitlement_usage (user_id, feature, usage_date, count)
+VALUES ($1, $2, $3, 1)
+ON CONFLICT (user_id, feature, usage_date)
+DO UPDATE SET count = entitlement_usage.count + 1
+WHERE entitlement_usage.count < sqlc.arg(max_count);
+
+-- name: ReleaseEntitlementUsage :exec
+UPDATE entitlement_usage
+SET count = GREATEST(count - 1, 0)
+WHERE user_id = $1 AND feature = $2 AND usage_date = $3;
+
+-- name: GetEntitlementUsage :one
+SELECT COALESCE(MAX(count), 0)::integer AS count FROM entitlement_usage
+WHERE user_id = $1 AND feature = $2 AND usage_date = $3;
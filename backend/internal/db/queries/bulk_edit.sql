@@ -0,0 +1,9 @@
+-- name: GetQuestionForUpdate :one
+SELECT * FROM questions
+WHERE question_id = $1
+FOR UPDATE;
+
+-- name: GetContentForUpdate :one
+SELECT * FROM contents
+WHERE content_id = $1
+FOR UPDATE;
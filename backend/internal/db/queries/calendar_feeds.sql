@@ -0,0 +1,27 @@
+-- name: GetCalendarFeed :one
+SELECT * FROM calendar_feeds
+WHERE user_id = $1;
+
+-- name: UpsertCalendarFeed :one
+INSERT INTO calendar_feeds (user_id, study_time, timezone)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id) DO UPDATE
+SET study_time = EXCLUDED.study_time,
+    timezone = EXCLUDED.timezone,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: RotateCalendarFeed :one
+UPDATE calendar_feeds
+SET version = version + 1, updated_at = NOW()
+WHERE user_id = $1
+RETURNING *;
+
+-- name: DeleteCalendarFeed :execrows
+DELETE FROM calendar_feeds
+WHERE user_id = $1;
+
+-- name: TouchCalendarFeed :exec
+UPDATE calendar_feeds
+SET last_accessed_at = NOW()
+WHERE user_id = $1;
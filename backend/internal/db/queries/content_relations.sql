@@ -0,0 +1,53 @@
+-- name: UpsertContentRelations :exec
+INSERT INTO content_relations (source_type, source_id, target_type, target_id, score, reason, computed_at)
+SELECT unnest(sqlc.arg(source_types)::text[]),
+       unnest(sqlc.arg(source_ids)::int[]),
+       unnest(sqlc.arg(target_types)::text[]),
+       unnest(sqlc.arg(target_ids)::int[]),
+       unnest(sqlc.arg(scores)::real[]),
+       unnest(sqlc.arg(reasons)::text[]),
+       sqlc.arg(computed_at)
+ON CONFLICT (source_type, source_id, target_type, target_id) DO UPDATE
+SET score = EXCLUDED.score,
+    reason = EXCLUDED.reason,
+    computed_at = EXCLUDED.computed_at;
+
+-- name: DeleteStaleContentRelations :execrows
+DELETE FROM content_relations
+WHERE computed_at < $1;
+
+-- name: ListRelatedContent :many
+-- Targets that were deleted or unpublished since the last rebuild are skipped
+SELECT r.target_type, r.target_id, r.score, r.reason,
+       COALESCE(w.word, g.title, e.title, q.title, '')::text AS label,
+       COALESCE(w.short_mean, e.meaning, '')::text AS detail,
+       q.content_id AS parent_id
+FROM content_relations r
+LEFT JOIN words w ON r.target_type = 'word' AND w.id = r.target_id
+LEFT JOIN grammars g ON r.target_type = 'grammar' AND g.id = r.target_id AND g.status = 'published'
+LEFT JOIN examples e ON r.target_type = 'example' AND e.id = r.target_id
+LEFT JOIN questions q ON r.target_type = 'question' AND q.question_id = r.target_id
+WHERE r.source_type = $1 AND r.source_id = $2
+  AND COALESCE(w.id, g.id, e.id, q.question_id) IS NOT NULL
+ORDER BY r.target_type, r.score DESC, r.target_id;
+
+-- name: GetContentRelationsSummary :one
+SELECT COUNT(*) AS relations, MAX(computed_at)::timestamptz AS computed_at
+FROM content_relations;
+
+-- name: ListWordsForRelations :many
+SELECT id, word, means FROM words
+ORDER BY id;
+
+-- name: ListAllWordTags :many
+SELECT word_id, tag FROM word_tags
+ORDER BY word_id, tag;
+
+-- name: ListPublishedGrammarsForRelations :many
+SELECT id, title, tag, related, contents FROM grammars
+WHERE status = 'published'
+ORDER BY id;
+
+-- name: ListQuestionsForRelations :many
+SELECT question_id, title, possible_answers, explanation, keywords FROM questions
+ORDER BY question_id;
@@ -0,0 +1,126 @@
+-- name: CreateContentRevision :one
+INSERT INTO content_revisions (
+    content_type,
+    content_id,
+    version,
+    status,
+    data,
+    note,
+    created_by
+) VALUES (
+    sqlc.arg(content_type),
+    sqlc.arg(content_id),
+    (SELECT COALESCE(MAX(version), 0) + 1 FROM content_revisions
+     WHERE content_type = sqlc.arg(content_type) AND content_id = sqlc.arg(content_id)),
+    'draft',
+    sqlc.arg(data),
+    sqlc.arg(note),
+    sqlc.arg(created_by)
+) RETURNING *;
+
+-- name: GetContentRevision :one
+SELECT * FROM content_revisions
+WHERE id = $1 LIMIT 1;
+
+-- name: ListContentRevisions :many
+SELECT * FROM content_revisions
+WHERE (sqlc.arg(status)::text = '' OR status = sqlc.arg(status))
+  AND (sqlc.arg(content_type)::text = '' OR content_type = sqlc.arg(content_type))
+ORDER BY updated_at DESC, id DESC
+LIMIT sqlc.arg(limit_count)
+OFFSET sqlc.arg(offset_count);
+
+-- name: ListContentRevisionsForContent :many
+SELECT * FROM content_revisions
+WHERE content_type = $1 AND content_id = $2
+ORDER BY version DESC;
+
+-- name: UpdateContentRevisionData :one
+UPDATE content_revisions
+SET data = $2, note = $3, updated_at = NOW()
+WHERE id = $1 AND status = 'draft'
+RETURNING *;
+
+-- name: TransitionContentRevision :one
+UPDATE content_revisions
+SET status = sqlc.arg(to_status),
+    publish_at = sqlc.arg(publish_at),
+    reviewed_by = COALESCE(sqlc.narg(reviewed_by), reviewed_by),
+    published_at = CASE WHEN sqlc.arg(to_status) = 'published' THEN NOW() ELSE published_at END,
+    updated_at = NOW()
+WHERE id = sqlc.arg(id) AND status = ANY(sqlc.arg(from_statuses)::text[])
+RETURNING *;
+
+-- name: ListDueContentRevisions :many
+SELECT * FROM content_revisions
+WHERE status = 'scheduled' AND publish_at <= $1
+ORDER BY publish_at
+LIMIT $2;
+
+-- name: AddContentHistory :exec
+INSERT INTO content_history (
+    content_type,
+    content_id,
+    revision_id,
+    action,
+    from_status,
+    to_status,
+    actor_id,
+    note
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+);
+
+-- name: ListContentHistory :many
+SELECT * FROM content_history
+WHERE content_type = $1 AND content_id = $2
+ORDER BY created_at DESC, id DESC
+LIMIT $3
+OFFSET $4;
+
+-- name: CreateGrammarDraft :one
+INSERT INTO grammars (
+    level,
+    title,
+    tag,
+    grammar_key,
+    related,
+    contents,
+    status
+) VALUES (
+    $1, $2, $3, $4, $5, $6, 'draft'
+) RETURNING *;
+
+-- name: PublishGrammar :one
+UPDATE grammars
+SET
+    level = $2,
+    title = $3,
+    tag = $4,
+    grammar_key = $5,
+    related = $6,
+    contents = $7,
+    status = 'published'
+WHERE id = $1
+RETURNING *;
+
+-- name: CreateWritingPromptDraft :one
+INSERT INTO writing_prompts (
+    user_id,
+    prompt_text,
+    topic,
+    difficulty_level,
+    status
+) VALUES (
+    $1, $2, $3, $4, 'draft'
+) RETURNING *;
+
+-- name: PublishWritingPrompt :one
+UPDATE writing_prompts
+SET
+    prompt_text = $2,
+    topic = $3,
+    difficulty_level = $4,
+    status = 'published'
+WHERE id = $1
+RETURNING *;
@@ -0,0 +1,24 @@
+-- name: CreateDataAccessLogs :exec
+INSERT INTO data_access_logs (subject_user_id, accessor_user_id, resource_type, resource_id, purpose)
+SELECT unnest(sqlc.arg(subject_user_ids)::int[]),
+       sqlc.arg(accessor_user_id)::int,
+       unnest(sqlc.arg(resource_types)::text[]),
+       unnest(sqlc.arg(resource_ids)::int[]),
+       sqlc.arg(purpose)::text;
+
+-- name: ListDataAccessLogsBySubject :many
+SELECT l.id, l.accessor_user_id, COALESCE(u.username, '')::text AS accessor_username,
+       l.resource_type, l.resource_id, l.purpose, l.accessed_at
+FROM data_access_logs l
+LEFT JOIN users u ON u.id = l.accessor_user_id
+WHERE l.subject_user_id = $1
+ORDER BY l.accessed_at DESC, l.id DESC
+LIMIT $2 OFFSET $3;
+
+-- name: CountDataAccessLogsBySubject :one
+SELECT COUNT(*) FROM data_access_logs
+WHERE subject_user_id = $1;
+
+-- name: DeleteDataAccessLogsBefore :execrows
+DELETE FROM data_access_logs
+WHERE accessed_at < $1;
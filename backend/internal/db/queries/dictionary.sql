@@ -0,0 +1,36 @@
+-- name: ListWordsMissingDictionaryData :many
+SELECT w.* FROM words w
+LEFT JOIN word_lookups l ON l.word_id = w.id
+WHERE (w.pronounce = '' OR w.short_mean = '' OR w.means IS NULL OR w.means = '[]'::jsonb OR w.audio_url IS NULL)
+  AND (l.word_id IS NULL OR (l.status = 'failed' AND l.looked_up_at < sqlc.arg(retry_before)))
+ORDER BY w.freq DESC, w.id
+LIMIT sqlc.arg(max_items);
+
+-- name: CountWordsMissingDictionaryData :one
+SELECT COUNT(*) FROM words
+WHERE pronounce = '' OR short_mean = '' OR means IS NULL OR means = '[]'::jsonb OR audio_url IS NULL;
+
+-- name: FillWordDictionaryData :one
+UPDATE words
+SET
+    pronounce = CASE WHEN pronounce = '' THEN sqlc.arg(pronounce) ELSE pronounce END,
+    short_mean = CASE WHEN short_mean = '' THEN sqlc.arg(short_mean) ELSE short_mean END,
+    means = CASE WHEN means IS NULL OR means = '[]'::jsonb THEN sqlc.narg(means) ELSE means END,
+    audio_url = COALESCE(audio_url, sqlc.narg(audio_url))
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: UpsertWordLookup :exec
+INSERT INTO word_lookups (word_id, provider, status, error)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (word_id) DO UPDATE
+SET provider = EXCLUDED.provider,
+    status = EXCLUDED.status,
+    error = EXCLUDED.error,
+    attempts = word_lookups.attempts + 1,
+    looked_up_at = NOW();
+
+-- name: CountWordLookupsByStatus :many
+SELECT status, COUNT(*) AS count FROM word_lookups
+GROUP BY status
+ORDER BY status;
@@ -3,9 +3,11 @@ INSERT INTO exam_attempts (
     user_id,
     exam_id,
     start_time,
-    status
+    status,
+    part_id,
+    time_limit_minutes
 ) VALUES (
-    $1, $2, $3, $4
+    $1, $2, $3, $4, $5, $6
 ) RETURNING *;
 
 -- name: GetExamAttempt :one
@@ -29,8 +31,11 @@ ORDER BY start_time DESC
 LIMIT $2 OFFSET $3;
 
 -- name: GetActiveExamAttempt :one
+-- The active full test of an exam when part_id is NULL, else the active
+-- practice of the part
 SELECT * FROM exam_attempts
-WHERE user_id = $1 AND exam_id = $2 AND status = 'in_progress'
+WHERE user_id = sqlc.arg(user_id) AND exam_id = sqlc.arg(exam_id) AND status = 'in_progress'
+  AND part_id IS NOT DISTINCT FROM sqlc.narg(part_id)::int
 ORDER BY start_time DESC
 LIMIT 1;
 
@@ -62,6 +67,14 @@ SET
 WHERE attempt_id = $1
 RETURNING *;
 
+-- name: RescoreExamAttempt :one
+UPDATE exam_attempts
+SET 
+    score = $2,
+    updated_at = NOW()
+WHERE attempt_id = $1 AND status = 'completed'
+RETURNING *;
+
 -- name: CompleteExamAttempt :one
 UPDATE exam_attempts
 SET 
@@ -94,14 +107,15 @@ SELECT COUNT(*) FROM exam_attempts
 WHERE exam_id = $1;
 
 -- name: GetExamAttemptStats :one
+-- Scores are of full tests; part practice is scored on another scale
 SELECT 
     COUNT(*) as total_attempts,
     COUNT(CASE WHEN status = 'completed' THEN 1 END) as completed_attempts,
     COUNT(CASE WHEN status = 'in_progress' THEN 1 END) as in_progress_attempts,
     COUNT(CASE WHEN status = 'abandoned' THEN 1 END) as abandoned_attempts,
-    AVG(score) as average_score,
-    MAX(score) as highest_score,
-    MIN(score) as lowest_score
+    COALESCE(AVG(score) FILTER (WHERE part_id IS NULL), 0)::float8 as average_score,
+    MAX(score) FILTER (WHERE part_id IS NULL) as highest_score,
+    MIN(score) FILTER (WHERE part_id IS NULL) as lowest_score
 FROM exam_attempts
 WHERE user_id = $1;
 
@@ -114,6 +128,6 @@ SELECT
     ROW_NUMBER() OVER (ORDER BY ea.score DESC, ea.end_time ASC) as rank
 FROM exam_attempts ea
 JOIN users u ON ea.user_id = u.id
-WHERE ea.exam_id = $1 AND ea.status = 'completed' AND ea.score IS NOT NULL
+WHERE ea.exam_id = $1 AND ea.status = 'completed' AND ea.score IS NOT NULL AND ea.part_id IS NULL
 ORDER BY ea.score DESC, ea.end_time ASC
 LIMIT $2 OFFSET $3;
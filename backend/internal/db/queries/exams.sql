@@ -2,9 +2,11 @@
 INSERT INTO exams (
     title,
     time_limit_minutes,
-    is_unlocked
+    is_unlocked,
+    is_premium,
+    transcripts_enabled
 ) VALUES (
-    $1, $2, $3
+    $1, $2, $3, $4, $5
 ) RETURNING *;
 
 -- name: GetExam :one
@@ -20,7 +22,9 @@ UPDATE exams
 SET
     title = $2,
     time_limit_minutes = $3,
-    is_unlocked = $4
+    is_unlocked = $4,
+    is_premium = $5,
+    transcripts_enabled = $6
 WHERE exam_id = $1
 RETURNING *;
 
@@ -0,0 +1,34 @@
+-- name: CreateFeatureFlag :one
+INSERT INTO feature_flags (
+    key,
+    description,
+    enabled,
+    rollout_percentage,
+    allowed_roles,
+    allowed_user_ids,
+    allowed_orgs
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: GetFeatureFlagByKey :one
+SELECT * FROM feature_flags WHERE key = $1 LIMIT 1;
+
+-- name: ListFeatureFlags :many
+SELECT * FROM feature_flags ORDER BY key;
+
+-- name: UpdateFeatureFlag :one
+UPDATE feature_flags
+SET
+    description = $2,
+    enabled = $3,
+    rollout_percentage = $4,
+    allowed_roles = $5,
+    allowed_user_ids = $6,
+    allowed_orgs = $7,
+    updated_at = NOW()
+WHERE key = $1
+RETURNING *;
+
+-- name: DeleteFeatureFlag :exec
+DELETE FROM feature_flags WHERE key = $1;
@@ -16,11 +16,12 @@ WHERE id = $1 LIMIT 1;
 
 -- name: BatchGetGrammars :many
 SELECT * FROM grammars
-WHERE id = ANY($1::int[])
+WHERE id = ANY($1::int[]) AND status = 'published'
 ORDER BY id;
 
 -- name: ListGrammars :many
 SELECT * FROM grammars
+WHERE status = 'published'
 ORDER BY id
 LIMIT $1
 OFFSET $2;
@@ -43,11 +44,12 @@ WHERE id = $1;
 
 -- name: SearchGrammars :many
 SELECT * FROM grammars
-WHERE
+WHERE status = 'published' AND (
     title ILIKE '%' || $1 || '%' OR
     grammar_key ILIKE '%' || $1 || '%' OR
     EXISTS (SELECT 1 FROM unnest(tag) AS t WHERE t ILIKE '%' || $1 || '%') OR
     contents::text ILIKE '%' || $1 || '%'
+)
 ORDER BY 
     CASE 
         WHEN LOWER(title) = LOWER($1) THEN 1
@@ -63,19 +65,20 @@ OFFSET $3;
 
 -- name: GetRandomGrammar :one
 SELECT * FROM grammars
+WHERE status = 'published'
 ORDER BY RANDOM()
 LIMIT 1;
 
 -- name: ListGrammarsByLevel :many
 SELECT * FROM grammars
-WHERE level = $1
+WHERE level = $1 AND status = 'published'
 ORDER BY id
 LIMIT $2
 OFFSET $3;
 
 -- name: ListGrammarsByTag :many
 SELECT * FROM grammars
-WHERE $1 = ANY(tag)
+WHERE $1 = ANY(tag) AND status = 'published'
 ORDER BY level, id
 LIMIT $2
 OFFSET $3;
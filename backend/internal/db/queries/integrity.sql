@@ -0,0 +1,23 @@
+-- name: CreateIntegrityEvents :exec
+INSERT INTO exam_attempt_integrity_events (attempt_id, event_type, occurred_at, duration_ms, paste_length)
+SELECT sqlc.arg(attempt_id)::int,
+       unnest(sqlc.arg(event_types)::text[]),
+       unnest(sqlc.arg(occurred_ats)::text[])::timestamptz,
+       unnest(sqlc.arg(durations_ms)::int[]),
+       unnest(sqlc.arg(paste_lengths)::int[]);
+
+-- name: ListIntegrityEvents :many
+SELECT * FROM exam_attempt_integrity_events
+WHERE attempt_id = $1
+ORDER BY occurred_at, id
+LIMIT $2;
+
+-- name: SummarizeIntegrityEvents :many
+SELECT event_type,
+       COUNT(*) AS events,
+       COALESCE(SUM(duration_ms), 0)::bigint AS duration_ms,
+       COALESCE(SUM(paste_length), 0)::bigint AS paste_length
+FROM exam_attempt_integrity_events
+WHERE attempt_id = $1
+GROUP BY event_type
+ORDER BY event_type;
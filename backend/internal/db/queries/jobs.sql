@@ -0,0 +1,63 @@
+-- name: CreateJob :one
+INSERT INTO jobs (kind, user_id, runner, params, created_at)
+VALUES ($1, $2, $3, $4, sqlc.arg(created_at)::timestamptz)
+RETURNING *;
+
+-- name: GetJob :one
+SELECT * FROM jobs WHERE id = $1;
+
+-- name: ListUserJobs :many
+SELECT * FROM jobs
+WHERE user_id = $1
+ORDER BY created_at DESC, id DESC
+LIMIT $2 OFFSET $3;
+
+-- name: CountUserJobs :one
+SELECT COUNT(*) FROM jobs WHERE user_id = $1;
+
+-- name: StartJob :one
+-- Moves a queued job to running; cancelled jobs are left alone
+UPDATE jobs
+SET status = 'running', started_at = sqlc.arg(started_at)::timestamptz
+WHERE id = sqlc.arg(id)::int AND status = 'queued'
+RETURNING *;
+
+-- name: UpdateJobProgress :exec
+UPDATE jobs
+SET progress = sqlc.arg(progress)::int, message = sqlc.arg(message)::text
+WHERE id = sqlc.arg(id)::int AND status = 'running';
+
+-- name: FinishJob :one
+-- Records the outcome of a running job; a job cancelled meanwhile keeps
+-- its cancelled status
+UPDATE jobs
+SET status = sqlc.arg(status)::text,
+    progress = CASE WHEN sqlc.arg(status)::text = 'succeeded' THEN 100 ELSE progress END,
+    error = sqlc.narg(error),
+    result_path = sqlc.narg(result_path),
+    result_name = sqlc.narg(result_name),
+    result_type = sqlc.narg(result_type),
+    result_size = sqlc.narg(result_size),
+    finished_at = sqlc.arg(finished_at)::timestamptz,
+    expires_at = sqlc.narg(expires_at)
+WHERE id = sqlc.arg(id)::int AND status = 'running'
+RETURNING *;
+
+-- name: CancelJob :one
+-- Cancels a queued or running job of the user
+UPDATE jobs
+SET status = 'canceled', finished_at = sqlc.arg(finished_at)::timestamptz
+WHERE id = sqlc.arg(id)::int AND user_id = sqlc.arg(user_id)::int AND status IN ('queued', 'running')
+RETURNING *;
+
+-- name: FailInterruptedJobs :execrows
+-- Fails the jobs a previous process of the runner left queued or running
+UPDATE jobs
+SET status = 'failed', error = 'interrupted by a server restart', finished_at = sqlc.arg(finished_at)::timestamptz
+WHERE runner = sqlc.arg(runner)::text AND status IN ('queued', 'running');
+
+-- name: DeleteExpiredJobs :many
+-- Deletes finished jobs whose result expired and returns their files
+DELETE FROM jobs
+WHERE expires_at <= sqlc.arg(now)::timestamptz
+RETURNING COALESCE(result_path, '')::text AS result_path;
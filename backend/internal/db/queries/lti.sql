@@ -0,0 +1,121 @@
+-- name: CreateLTIPlatform :one
+INSERT INTO lti_platforms (name, issuer, client_id, deployment_id, auth_login_url, auth_token_url, jwks_url)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetLTIPlatform :one
+SELECT * FROM lti_platforms
+WHERE id = $1;
+
+-- name: ListLTIPlatforms :many
+SELECT * FROM lti_platforms
+ORDER BY id;
+
+-- name: ListLTIPlatformsByIssuer :many
+SELECT * FROM lti_platforms
+WHERE issuer = $1
+ORDER BY id;
+
+-- name: DeleteLTIPlatform :execrows
+DELETE FROM lti_platforms
+WHERE id = $1;
+
+-- name: CreateLTILaunchState :exec
+INSERT INTO lti_launch_states (state, nonce, platform_id, expires_at)
+VALUES ($1, $2, $3, $4);
+
+-- name: ConsumeLTILaunchState :one
+DELETE FROM lti_launch_states
+WHERE state = $1 AND expires_at > NOW()
+RETURNING *;
+
+-- name: DeleteExpiredLTILaunchStates :execrows
+DELETE FROM lti_launch_states
+WHERE expires_at <= NOW();
+
+-- name: GetLTIUser :one
+SELECT user_id FROM lti_users
+WHERE platform_id = $1 AND subject = $2;
+
+-- name: CreateLTIUser :execrows
+INSERT INTO lti_users (platform_id, subject, user_id)
+VALUES ($1, $2, $3)
+ON CONFLICT (platform_id, subject) DO NOTHING;
+
+-- name: UpsertLTIContext :one
+INSERT INTO lti_contexts (platform_id, context_id, title, memberships_url, lineitems_url)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (platform_id, context_id) DO UPDATE
+SET title = EXCLUDED.title,
+    memberships_url = COALESCE(EXCLUDED.memberships_url, lti_contexts.memberships_url),
+    lineitems_url = COALESCE(EXCLUDED.lineitems_url, lti_contexts.lineitems_url),
+    updated_at = NOW()
+RETURNING *;
+
+-- name: GetLTIContext :one
+SELECT * FROM lti_contexts
+WHERE id = $1;
+
+-- name: ListLTIContexts :many
+SELECT * FROM lti_contexts
+ORDER BY updated_at DESC
+LIMIT $1 OFFSET $2;
+
+-- name: MarkLTIContextRosterSynced :exec
+UPDATE lti_contexts
+SET roster_synced_at = NOW()
+WHERE id = $1;
+
+-- name: UpsertLTIContextMember :exec
+INSERT INTO lti_context_members (context_id, user_id, roles, status, synced_at)
+VALUES ($1, $2, $3, $4, NOW())
+ON CONFLICT (context_id, user_id) DO UPDATE
+SET roles = EXCLUDED.roles,
+    status = EXCLUDED.status,
+    synced_at = NOW();
+
+-- name: DeactivateLTIContextMembers :execrows
+UPDATE lti_context_members
+SET status = 'inactive'
+WHERE context_id = sqlc.arg(context_id) AND status = 'active' AND NOT (user_id = ANY(sqlc.arg(active_user_ids)::int[]));
+
+-- name: ListLTIContextMembers :many
+SELECT m.context_id, m.user_id, u.username, m.roles, m.status, m.synced_at
+FROM lti_context_members m
+JOIN users u ON u.id = m.user_id
+WHERE m.context_id = $1
+ORDER BY u.username;
+
+-- name: UpsertLTIResourceLink :one
+INSERT INTO lti_resource_links (context_id, resource_link_id, title, exam_id, lineitem_url)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (context_id, resource_link_id) DO UPDATE
+SET title = EXCLUDED.title,
+    exam_id = COALESCE(EXCLUDED.exam_id, lti_resource_links.exam_id),
+    lineitem_url = COALESCE(EXCLUDED.lineitem_url, lti_resource_links.lineitem_url),
+    updated_at = NOW()
+RETURNING *;
+
+-- name: ListLTIGradeTargets :many
+SELECT rl.id AS resource_link_id, rl.lineitem_url, u.subject, p.id AS platform_id
+FROM lti_resource_links rl
+JOIN lti_contexts c ON c.id = rl.context_id
+JOIN lti_context_members m ON m.context_id = c.id AND m.user_id = sqlc.arg(user_id) AND m.status = 'active'
+JOIN lti_users u ON u.platform_id = c.platform_id AND u.user_id = sqlc.arg(user_id)
+JOIN lti_platforms p ON p.id = c.platform_id
+WHERE rl.exam_id = sqlc.arg(exam_id) AND rl.lineitem_url IS NOT NULL;
+
+-- name: UpsertLTIScoreSubmission :exec
+INSERT INTO lti_score_submissions (attempt_id, resource_link_id, status, error)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (attempt_id, resource_link_id) DO UPDATE
+SET status = EXCLUDED.status,
+    error = EXCLUDED.error,
+    attempts = lti_score_submissions.attempts + 1,
+    submitted_at = NOW();
+
+-- name: ListLTIScoreSubmissions :many
+SELECT * FROM lti_score_submissions
+WHERE status = $1
+ORDER BY submitted_at DESC
+LIMIT $2;
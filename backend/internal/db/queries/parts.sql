@@ -26,3 +26,14 @@ RETURNING *;
 -- name: DeletePart :exec
 DELETE FROM parts
 WHERE part_id = $1;
+
+-- name: CountPartQuestions :one
+-- Questions of a part and of its whole exam, to give the part a share of
+-- the exam's time limit
+SELECT
+    COUNT(*) FILTER (WHERE c.part_id = sqlc.arg(part_id))::int AS part_questions,
+    COUNT(*)::int AS exam_questions
+FROM questions q
+JOIN contents c ON c.content_id = q.content_id
+JOIN parts p ON p.part_id = c.part_id
+WHERE p.exam_id = sqlc.arg(exam_id);
@@ -0,0 +1,55 @@
+-- name: CreatePlacementTest :one
+INSERT INTO placement_tests (
+  user_id,
+  state
+) VALUES (
+  $1, $2
+)
+RETURNING *;
+
+-- name: GetPlacementTest :one
+SELECT * FROM placement_tests
+WHERE id = $1 LIMIT 1;
+
+-- name: GetActivePlacementTest :one
+SELECT * FROM placement_tests
+WHERE user_id = $1 AND status = 'in_progress'
+LIMIT 1;
+
+-- name: UpdatePlacementTestState :exec
+UPDATE placement_tests
+SET state = $2
+WHERE id = $1 AND status = 'in_progress';
+
+-- name: CompletePlacementTest :one
+UPDATE placement_tests
+SET
+  status = 'completed',
+  state = $2,
+  result = $3,
+  completed_at = NOW()
+WHERE id = $1 AND status = 'in_progress'
+RETURNING *;
+
+-- name: AbandonPlacementTest :exec
+UPDATE placement_tests
+SET status = 'abandoned'
+WHERE id = $1 AND status = 'in_progress';
+
+-- name: GetPlacementQuestion :one
+-- Difficulty calibrated from answers is preferred over the authored one
+SELECT q.question_id, q.title, q.media_url, q.image_url, q.possible_answers, q.true_answer,
+       COALESCE(qc.calibrated_difficulty, q.difficulty)::smallint AS difficulty
+FROM questions q
+LEFT JOIN question_calibrations qc ON qc.question_id = q.question_id
+WHERE (q.media_url IS NOT NULL) = sqlc.arg(listening)::boolean
+  AND NOT (q.question_id = ANY(sqlc.arg(exclude_ids)::int[]))
+ORDER BY ABS(COALESCE(qc.calibrated_difficulty, q.difficulty, 3) - sqlc.arg(level)::int), random()
+LIMIT 1;
+
+-- name: ListPlacementWords :many
+SELECT * FROM words
+WHERE level = sqlc.arg(level)
+  AND NOT (id = ANY(sqlc.arg(exclude_ids)::int[]))
+ORDER BY random()
+LIMIT sqlc.arg(max_words);
@@ -0,0 +1,29 @@
+-- name: GetExamQuestionAudio :one
+SELECT q.question_id, q.media_url
+FROM questions q
+JOIN contents c ON q.content_id = c.content_id
+JOIN parts p ON c.part_id = p.part_id
+WHERE q.question_id = $1 AND p.exam_id = $2;
+
+-- name: IssuePlaybackToken :one
+-- Issuing again for the same question replaces the token and its expiry
+-- but keeps the plays already used
+INSERT INTO audio_playback_tokens (attempt_id, question_id, token_hash, max_plays, expires_at)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (attempt_id, question_id) DO UPDATE
+SET token_hash = EXCLUDED.token_hash,
+    expires_at = EXCLUDED.expires_at
+RETURNING *;
+
+-- name: GetPlaybackToken :one
+SELECT * FROM audio_playback_tokens
+WHERE token_hash = $1;
+
+-- name: ConsumePlaybackToken :one
+UPDATE audio_playback_tokens
+SET plays_used = plays_used + 1,
+    last_played_at = sqlc.arg(now)::timestamptz
+WHERE id = sqlc.arg(id)
+  AND plays_used < max_plays
+  AND expires_at > sqlc.arg(now)::timestamptz
+RETURNING *;
@@ -0,0 +1,78 @@
+-- name: ListBestUserWritings :many
+-- The highest scored evaluated submissions of a user
+SELECT uw.id, uw.prompt_id, uw.submission_text, uw.ai_score, uw.submitted_at, uw.evaluated_at,
+       COALESCE(wp.prompt_text, '')::text AS prompt_text,
+       COALESCE(wp.topic, '')::text AS topic
+FROM user_writings uw
+LEFT JOIN writing_prompts wp ON wp.id = uw.prompt_id
+WHERE uw.user_id = $1 AND uw.ai_score IS NOT NULL
+ORDER BY uw.ai_score DESC, uw.submitted_at DESC
+LIMIT $2;
+
+-- name: ListBestSpeakingSessions :many
+-- The speaking sessions of a user with the highest average score of their
+-- turns
+SELECT s.id, COALESCE(s.session_topic, '')::text AS session_topic, s.start_time, s.end_time,
+       COUNT(t.id)::int AS turns,
+       AVG(t.ai_score)::float8 AS average_score,
+       MAX(t.ai_score)::float8 AS best_score
+FROM speaking_sessions s
+JOIN speaking_turns t ON t.session_id = s.id AND t.speaker_type = 'user' AND t.ai_score IS NOT NULL
+WHERE s.user_id = $1
+GROUP BY s.id
+ORDER BY average_score DESC, s.start_time DESC
+LIMIT $2;
+
+-- name: GetWritingScoreProgression :many
+-- Weekly scores of a user's evaluated writing submissions since a time
+SELECT date_trunc('week', COALESCE(evaluated_at, submitted_at))::timestamptz AS week,
+       COUNT(*)::int AS submissions,
+       AVG(ai_score)::float8 AS average_score,
+       MAX(ai_score)::float8 AS best_score
+FROM user_writings
+WHERE user_id = $1 AND ai_score IS NOT NULL
+  AND COALESCE(evaluated_at, submitted_at) >= sqlc.arg(since)::timestamptz
+GROUP BY week
+ORDER BY week;
+
+-- name: GetSpeakingScoreProgression :many
+-- Weekly scores of a user's speaking turns since a time
+SELECT date_trunc('week', t."timestamp")::timestamptz AS week,
+       COUNT(*)::int AS submissions,
+       AVG(t.ai_score)::float8 AS average_score,
+       MAX(t.ai_score)::float8 AS best_score
+FROM speaking_turns t
+JOIN speaking_sessions s ON s.id = t.session_id
+WHERE s.user_id = $1 AND t.speaker_type = 'user' AND t.ai_score IS NOT NULL
+  AND t."timestamp" >= sqlc.arg(since)::timestamptz
+GROUP BY week
+ORDER BY week;
+
+-- name: CreatePortfolioShareLink :one
+INSERT INTO portfolio_share_links (user_id, token_hash, label, expires_at)
+VALUES ($1, $2, $3, sqlc.arg(expires_at)::timestamptz)
+RETURNING *;
+
+-- name: ListPortfolioShareLinks :many
+SELECT * FROM portfolio_share_links
+WHERE user_id = $1
+ORDER BY created_at DESC, id DESC;
+
+-- name: CountActivePortfolioShareLinks :one
+SELECT COUNT(*) FROM portfolio_share_links
+WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > sqlc.arg(now)::timestamptz;
+
+-- name: RevokePortfolioShareLink :one
+UPDATE portfolio_share_links
+SET revoked_at = COALESCE(revoked_at, sqlc.arg(revoked_at)::timestamptz)
+WHERE id = sqlc.arg(id) AND user_id = sqlc.arg(user_id)
+RETURNING *;
+
+-- name: ViewPortfolioShareLink :one
+-- Counts a view of a link that is neither revoked nor expired
+UPDATE portfolio_share_links
+SET view_count = view_count + 1,
+    last_viewed_at = sqlc.arg(viewed_at)::timestamptz
+WHERE token_hash = sqlc.arg(token_hash) AND revoked_at IS NULL
+  AND expires_at > sqlc.arg(viewed_at)::timestamptz
+RETURNING *;
@@ -0,0 +1,86 @@
+-- name: RecordProctoringConsent :one
+-- Consenting again, e.g. to a newer policy, replaces the earlier consent
+INSERT INTO exam_proctoring_consents (attempt_id, user_id, policy_version, ip_address, user_agent, consented_at)
+VALUES ($1, $2, $3, $4, $5, sqlc.arg(consented_at)::timestamptz)
+ON CONFLICT (attempt_id) DO UPDATE
+SET policy_version = EXCLUDED.policy_version,
+    ip_address = EXCLUDED.ip_address,
+    user_agent = EXCLUDED.user_agent,
+    consented_at = EXCLUDED.consented_at
+RETURNING *;
+
+-- name: GetProctoringConsent :one
+SELECT * FROM exam_proctoring_consents
+WHERE attempt_id = $1;
+
+-- name: CreateProctoringPhoto :one
+INSERT INTO exam_proctoring_photos (attempt_id, user_id, public_id, size_bytes, captured_at, received_at)
+VALUES ($1, $2, $3, $4, sqlc.arg(captured_at)::timestamptz, sqlc.arg(received_at)::timestamptz)
+RETURNING *;
+
+-- name: CountProctoringPhotos :one
+SELECT COUNT(*) FROM exam_proctoring_photos
+WHERE attempt_id = $1;
+
+-- name: GetLatestProctoringPhoto :one
+SELECT * FROM exam_proctoring_photos
+WHERE attempt_id = $1
+ORDER BY received_at DESC, id DESC
+LIMIT 1;
+
+-- name: ListProctoringPhotos :many
+SELECT * FROM exam_proctoring_photos
+WHERE attempt_id = $1
+ORDER BY captured_at, id;
+
+-- name: GetProctoringPhoto :one
+SELECT * FROM exam_proctoring_photos
+WHERE id = $1;
+
+-- name: ReviewProctoringPhoto :one
+UPDATE exam_proctoring_photos
+SET flagged = sqlc.arg(flagged),
+    review_note = sqlc.narg(review_note),
+    reviewed_by = sqlc.arg(reviewed_by),
+    reviewed_at = sqlc.arg(reviewed_at)::timestamptz
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: ListProctoredAttempts :many
+-- Attempts with snapshots, most recently captured first
+SELECT a.attempt_id, a.user_id, a.exam_id, a.status, a.start_time,
+       COUNT(p.id)::int AS photos,
+       COUNT(p.id) FILTER (WHERE p.flagged)::int AS flagged_photos,
+       MAX(p.captured_at)::timestamptz AS last_captured_at
+FROM exam_attempts a
+JOIN exam_proctoring_photos p ON p.attempt_id = a.attempt_id
+GROUP BY a.attempt_id
+HAVING NOT sqlc.arg(flagged_only)::bool OR COUNT(p.id) FILTER (WHERE p.flagged) > 0
+ORDER BY last_captured_at DESC, a.attempt_id DESC
+LIMIT sqlc.arg('limit')::int OFFSET sqlc.arg('offset')::int;
+
+-- name: CountProctoredAttempts :one
+SELECT COUNT(*) FROM (
+  SELECT p.attempt_id
+  FROM exam_proctoring_photos p
+  WHERE p.attempt_id IS NOT NULL
+  GROUP BY p.attempt_id
+  HAVING NOT sqlc.arg(flagged_only)::bool OR COUNT(*) FILTER (WHERE p.flagged) > 0
+) proctored;
+
+-- name: ListExpiredProctoringPhotos :many
+SELECT id, public_id
+FROM exam_proctoring_photos
+WHERE id > sqlc.arg(after_id)
+  AND received_at < sqlc.arg(cutoff)::timestamptz
+ORDER BY id
+LIMIT sqlc.arg(max_items);
+
+-- name: CountExpiredProctoringPhotos :one
+SELECT COUNT(*)
+FROM exam_proctoring_photos
+WHERE received_at < sqlc.arg(cutoff)::timestamptz;
+
+-- name: DeleteProctoringPhotos :execrows
+DELETE FROM exam_proctoring_photos
+WHERE id = ANY(sqlc.arg(ids)::bigint[]);
@@ -0,0 +1,54 @@
+-- name: ListQuestionAnswerStats :many
+-- Counts the first answer of each learner to a question, so retakes do not
+-- make questions look easier
+WITH first_answers AS (
+    SELECT DISTINCT ON (ua.question_id, ea.user_id) ua.question_id, ua.is_correct
+    FROM user_answers ua
+    JOIN exam_attempts ea ON ea.attempt_id = ua.attempt_id
+    ORDER BY ua.question_id, ea.user_id, ua.created_at, ua.user_answer_id
+)
+SELECT question_id,
+       COUNT(*)::int AS responses,
+       COUNT(*) FILTER (WHERE is_correct)::int AS correct
+FROM first_answers
+GROUP BY question_id
+HAVING COUNT(*) >= sqlc.arg(min_responses)::int
+ORDER BY question_id;
+
+-- name: UpsertQuestionCalibrations :exec
+INSERT INTO question_calibrations (question_id, calibrated_difficulty, correct_rate, responses, computed_at)
+SELECT unnest(sqlc.arg(question_ids)::int[]),
+       unnest(sqlc.arg(difficulties)::smallint[]),
+       unnest(sqlc.arg(correct_rates)::real[]),
+       unnest(sqlc.arg(responses)::int[]),
+       sqlc.arg(computed_at)
+ON CONFLICT (question_id) DO UPDATE
+SET calibrated_difficulty = EXCLUDED.calibrated_difficulty,
+    correct_rate = EXCLUDED.correct_rate,
+    responses = EXCLUDED.responses,
+    computed_at = EXCLUDED.computed_at;
+
+-- name: DeleteStaleQuestionCalibrations :execrows
+DELETE FROM question_calibrations
+WHERE computed_at < $1;
+
+-- name: GetQuestionCalibrationSummary :one
+SELECT COUNT(*) AS questions, MAX(computed_at)::timestamptz AS computed_at
+FROM question_calibrations;
+
+-- name: ListQuestionCalibrations :many
+-- Questions whose calibrated difficulty differs most from the authored one
+-- come first; questions without an authored difficulty always qualify
+SELECT qc.question_id, q.title, q.difficulty AS authored_difficulty,
+       qc.calibrated_difficulty, qc.correct_rate, qc.responses, qc.computed_at
+FROM question_calibrations qc
+JOIN questions q ON q.question_id = qc.question_id
+WHERE q.difficulty IS NULL OR ABS(qc.calibrated_difficulty - q.difficulty) >= sqlc.arg(min_drift)::int
+ORDER BY ABS(qc.calibrated_difficulty - COALESCE(q.difficulty, qc.calibrated_difficulty)) DESC, q.difficulty IS NULL DESC, qc.question_id
+LIMIT sqlc.arg('limit')::int OFFSET sqlc.arg('offset')::int;
+
+-- name: CountQuestionCalibrations :one
+SELECT COUNT(*)
+FROM question_calibrations qc
+JOIN questions q ON q.question_id = qc.question_id
+WHERE q.difficulty IS NULL OR ABS(qc.calibrated_difficulty - q.difficulty) >= sqlc.arg(min_drift)::int;
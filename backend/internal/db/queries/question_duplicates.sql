@@ -0,0 +1,40 @@
+-- name: FindSimilarQuestions :many
+-- Questions with the same fingerprint as the given text, then questions
+-- whose fingerprint is at least min_similarity similar, most similar first.
+-- The % operator uses the trigram index with the default threshold of 0.3.
+WITH input AS (
+    SELECT question_fingerprint(sqlc.arg(title)::text, sqlc.arg(possible_answers)::text[]) AS fingerprint
+)
+SELECT q.question_id, q.content_id, q.title, q.possible_answers, q.true_answer,
+       (md5(question_fingerprint(q.title, q.possible_answers)) = md5(i.fingerprint))::bool AS exact,
+       similarity(question_fingerprint(q.title, q.possible_answers), i.fingerprint)::real AS similarity
+FROM questions q, input i
+WHERE q.question_id <> sqlc.arg(exclude_id)::int
+  AND (md5(question_fingerprint(q.title, q.possible_answers)) = md5(i.fingerprint)
+       OR (question_fingerprint(q.title, q.possible_answers) % i.fingerprint
+           AND similarity(question_fingerprint(q.title, q.possible_answers), i.fingerprint) >= sqlc.arg(min_similarity)::real))
+ORDER BY exact DESC, similarity DESC, q.question_id
+LIMIT sqlc.arg(max_results)::int;
+
+-- name: ListDuplicateQuestionPairs :many
+-- Pairs of questions in the bank that are exact or near duplicates of each
+-- other, exact duplicates first, then most similar first
+SELECT a.question_id, a.content_id, a.title,
+       b.question_id AS duplicate_id, b.content_id AS duplicate_content_id, b.title AS duplicate_title,
+       (md5(question_fingerprint(a.title, a.possible_answers)) = md5(question_fingerprint(b.title, b.possible_answers)))::bool AS exact,
+       similarity(question_fingerprint(a.title, a.possible_answers), question_fingerprint(b.title, b.possible_answers))::real AS similarity
+FROM questions a
+JOIN questions b ON b.question_id > a.question_id
+ AND (md5(question_fingerprint(a.title, a.possible_answers)) = md5(question_fingerprint(b.title, b.possible_answers))
+      OR (question_fingerprint(a.title, a.possible_answers) % question_fingerprint(b.title, b.possible_answers)
+          AND similarity(question_fingerprint(a.title, a.possible_answers), question_fingerprint(b.title, b.possible_answers)) >= sqlc.arg(min_similarity)::real))
+ORDER BY exact DESC, similarity DESC, a.question_id, b.question_id
+LIMIT sqlc.arg('limit')::int OFFSET sqlc.arg('offset')::int;
+
+-- name: CountDuplicateQuestionPairs :one
+SELECT COUNT(*)
+FROM questions a
+JOIN questions b ON b.question_id > a.question_id
+ AND (md5(question_fingerprint(a.title, a.possible_answers)) = md5(question_fingerprint(b.title, b.possible_answers))
+      OR (question_fingerprint(a.title, a.possible_answers) % question_fingerprint(b.title, b.possible_answers)
+          AND similarity(question_fingerprint(a.title, a.possible_answers), question_fingerprint(b.title, b.possible_answers)) >= sqlc.arg(min_similarity)::real));
@@ -0,0 +1,38 @@
+-- name: GetQuestionTranscript :one
+SELECT * FROM question_transcripts
+WHERE question_id = $1;
+
+-- name: UpsertQuestionTranscript :one
+INSERT INTO question_transcripts (question_id, transcript, updated_by, updated_at)
+VALUES ($1, $2, $3, NOW())
+ON CONFLICT (question_id) DO UPDATE
+SET transcript = EXCLUDED.transcript,
+    updated_by = EXCLUDED.updated_by,
+    updated_at = EXCLUDED.updated_at
+RETURNING *;
+
+-- name: DeleteQuestionTranscript :execrows
+DELETE FROM question_transcripts
+WHERE question_id = $1;
+
+-- name: GetAttemptTranscript :one
+-- A question of an exam with whether the exam offers transcripts and the
+-- question's transcript, if it has one
+SELECT q.question_id, p.part_id, e.transcripts_enabled, qt.transcript, qt.updated_at
+FROM questions q
+JOIN contents c ON c.content_id = q.content_id
+JOIN parts p ON p.part_id = c.part_id
+JOIN exams e ON e.exam_id = p.exam_id
+LEFT JOIN question_transcripts qt ON qt.question_id = q.question_id
+WHERE q.question_id = $1 AND p.exam_id = $2;
+
+-- name: RecordTranscriptReveal :exec
+INSERT INTO transcript_reveals (attempt_id, question_id)
+VALUES ($1, $2)
+ON CONFLICT (attempt_id, question_id) DO NOTHING;
+
+-- name: IsTranscriptRevealed :one
+SELECT EXISTS (
+    SELECT 1 FROM transcript_reveals
+    WHERE attempt_id = $1 AND question_id = $2
+);
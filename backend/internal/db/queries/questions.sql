@@ -7,15 +7,23 @@ INSERT INTO questions (
     possible_answers,
     true_answer,
     explanation,
-    keywords
+    keywords,
+    difficulty,
+    options,
+    answer_key
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
 ) RETURNING *;
 
 -- name: GetQuestion :one
 SELECT * FROM questions
 WHERE question_id = $1 LIMIT 1;
 
+-- name: GetQuestionPartID :one
+SELECT c.part_id FROM questions q
+JOIN contents c ON c.content_id = q.content_id
+WHERE q.question_id = $1;
+
 -- name: ListQuestionsByContent :many
 SELECT * FROM questions
 WHERE content_id = $1
@@ -31,7 +39,10 @@ SET
     possible_answers = $6,
     true_answer = $7,
     explanation = $8,
-    keywords = $9
+    keywords = $9,
+    difficulty = $10,
+    options = $11,
+    answer_key = $12
 WHERE question_id = $1
 RETURNING *;
 
@@ -0,0 +1,78 @@
+-- name: CreateReferralCode :execrows
+INSERT INTO referral_codes (user_id, code)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: GetReferralCodeByUser :one
+SELECT * FROM referral_codes
+WHERE user_id = $1;
+
+-- name: GetReferralCode :one
+SELECT * FROM referral_codes
+WHERE code = $1;
+
+-- name: CreateReferral :one
+INSERT INTO referrals (
+  referrer_id,
+  referred_id,
+  code,
+  status,
+  reject_reason,
+  signup_ip
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: CountReferralsFromIP :one
+SELECT COUNT(*) FROM referrals
+WHERE referrer_id = $1 AND signup_ip = $2 AND created_at >= $3;
+
+-- name: ConvertReferral :one
+UPDATE referrals
+SET status = 'converted', converted_at = NOW()
+WHERE referred_id = $1 AND status = 'pending'
+RETURNING *;
+
+-- name: MarkReferralRewarded :exec
+UPDATE referrals
+SET rewarded_at = NOW(), reward_days = $2
+WHERE id = $1;
+
+-- name: GetReferrerStats :one
+SELECT
+  COUNT(*) FILTER (WHERE status <> 'rejected') AS signups,
+  COUNT(*) FILTER (WHERE status = 'converted') AS conversions,
+  COALESCE(SUM(reward_days), 0)::bigint AS reward_days
+FROM referrals
+WHERE referrer_id = $1;
+
+-- name: GetReferralSummary :one
+SELECT
+  COUNT(*) AS total,
+  COUNT(*) FILTER (WHERE status = 'pending') AS pending,
+  COUNT(*) FILTER (WHERE status = 'converted') AS converted,
+  COUNT(*) FILTER (WHERE status = 'rejected') AS rejected,
+  COALESCE(SUM(reward_days), 0)::bigint AS reward_days
+FROM referrals
+WHERE created_at >= $1;
+
+-- name: ListReferralRejectReasons :many
+SELECT reject_reason, COUNT(*) AS count
+FROM referrals
+WHERE status = 'rejected' AND created_at >= $1
+GROUP BY reject_reason
+ORDER BY count DESC;
+
+-- name: ListTopReferrers :many
+SELECT
+  r.referrer_id,
+  u.username,
+  COUNT(*) FILTER (WHERE r.status <> 'rejected') AS signups,
+  COUNT(*) FILTER (WHERE r.status = 'converted') AS conversions,
+  COUNT(*) FILTER (WHERE r.status = 'rejected') AS rejected
+FROM referrals r
+JOIN users u ON u.id = r.referrer_id
+WHERE r.created_at >= sqlc.arg(since)
+GROUP BY r.referrer_id, u.username
+ORDER BY conversions DESC, signups DESC, r.referrer_id
+LIMIT sqlc.arg(max_items);
@@ -0,0 +1,124 @@
+-- name: UpsertRetentionOverride :one
+INSERT INTO retention_overrides (platform_id, category, retention_days, updated_by)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (platform_id, category) DO UPDATE SET
+  retention_days = EXCLUDED.retention_days,
+  updated_by = EXCLUDED.updated_by,
+  updated_at = NOW()
+RETURNING *;
+
+-- name: DeleteRetentionOverride :execrows
+DELETE FROM retention_overrides
+WHERE platform_id = $1 AND category = $2;
+
+-- name: ListRetentionOverrides :many
+SELECT o.platform_id, p.name AS platform_name, o.category, o.retention_days, o.updated_by, o.updated_at
+FROM retention_overrides o
+JOIN lti_platforms p ON p.id = o.platform_id
+ORDER BY p.name, o.category;
+
+-- name: ListExpiredSpeakingAudio :many
+-- Users of several organizations keep their data for the longest period
+WITH policy AS (
+  SELECT lu.user_id, MAX(o.retention_days) AS days
+  FROM lti_users lu
+  JOIN retention_overrides o ON o.platform_id = lu.platform_id
+  WHERE o.category = 'speaking_audio'
+  GROUP BY lu.user_id
+)
+SELECT t.id, t.audio_recording_path::text AS audio_recording_path
+FROM speaking_turns t
+JOIN speaking_sessions s ON s.id = t.session_id
+LEFT JOIN policy p ON p.user_id = s.user_id
+WHERE t.audio_recording_path IS NOT NULL
+  AND t.id > sqlc.arg(after_id)
+  AND COALESCE(p.days, sqlc.arg(default_days)::int) > 0
+  AND t."timestamp" < sqlc.arg(now)::timestamptz - make_interval(days => COALESCE(p.days, sqlc.arg(default_days)::int))
+ORDER BY t.id
+LIMIT sqlc.arg(max_items);
+
+-- name: CountExpiredSpeakingAudio :one
+WITH policy AS (
+  SELECT lu.user_id, MAX(o.retention_days) AS days
+  FROM lti_users lu
+  JOIN retention_overrides o ON o.platform_id = lu.platform_id
+  WHERE o.category = 'speaking_audio'
+  GROUP BY lu.user_id
+)
+SELECT COUNT(*)
+FROM speaking_turns t
+JOIN speaking_sessions s ON s.id = t.session_id
+LEFT JOIN policy p ON p.user_id = s.user_id
+WHERE t.audio_recording_path IS NOT NULL
+  AND COALESCE(p.days, sqlc.arg(default_days)::int) > 0
+  AND t."timestamp" < sqlc.arg(now)::timestamptz - make_interval(days => COALESCE(p.days, sqlc.arg(default_days)::int));
+
+-- name: ClearSpeakingAudio :execrows
+UPDATE speaking_turns
+SET audio_recording_path = NULL
+WHERE id = ANY(sqlc.arg(ids)::int[]);
+
+-- name: ClearExpiredWritingFeedback :execrows
+WITH policy AS (
+  SELECT lu.user_id, MAX(o.retention_days) AS days
+  FROM lti_users lu
+  JOIN retention_overrides o ON o.platform_id = lu.platform_id
+  WHERE o.category = 'ai_feedback'
+  GROUP BY lu.user_id
+)
+UPDATE user_writings w
+SET ai_feedback = NULL
+FROM user_writings e
+LEFT JOIN policy p ON p.user_id = e.user_id
+WHERE w.id = e.id
+  AND e.ai_feedback IS NOT NULL
+  AND COALESCE(p.days, sqlc.arg(default_days)::int) > 0
+  AND COALESCE(e.evaluated_at, e.submitted_at) < sqlc.arg(now)::timestamptz - make_interval(days => COALESCE(p.days, sqlc.arg(default_days)::int));
+
+-- name: CountExpiredWritingFeedback :one
+WITH policy AS (
+  SELECT lu.user_id, MAX(o.retention_days) AS days
+  FROM lti_users lu
+  JOIN retention_overrides o ON o.platform_id = lu.platform_id
+  WHERE o.category = 'ai_feedback'
+  GROUP BY lu.user_id
+)
+SELECT COUNT(*)
+FROM user_writings e
+LEFT JOIN policy p ON p.user_id = e.user_id
+WHERE e.ai_feedback IS NOT NULL
+  AND COALESCE(p.days, sqlc.arg(default_days)::int) > 0
+  AND COALESCE(e.evaluated_at, e.submitted_at) < sqlc.arg(now)::timestamptz - make_interval(days => COALESCE(p.days, sqlc.arg(default_days)::int));
+
+-- name: ClearExpiredSpeakingEvaluations :execrows
+WITH policy AS (
+  SELECT lu.user_id, MAX(o.retention_days) AS days
+  FROM lti_users lu
+  JOIN retention_overrides o ON o.platform_id = lu.platform_id
+  WHERE o.category = 'ai_feedback'
+  GROUP BY lu.user_id
+)
+UPDATE speaking_turns t
+SET ai_evaluation = NULL
+FROM speaking_sessions s
+LEFT JOIN policy p ON p.user_id = s.user_id
+WHERE s.id = t.session_id
+  AND t.ai_evaluation IS NOT NULL
+  AND COALESCE(p.days, sqlc.arg(default_days)::int) > 0
+  AND t."timestamp" < sqlc.arg(now)::timestamptz - make_interval(days => COALESCE(p.days, sqlc.arg(default_days)::int));
+
+-- name: CountExpiredSpeakingEvaluations :one
+WITH policy AS (
+  SELECT lu.user_id, MAX(o.retention_days) AS days
+  FROM lti_users lu
+  JOIN retention_overrides o ON o.platform_id = lu.platform_id
+  WHERE o.category = 'ai_feedback'
+  GROUP BY lu.user_id
+)
+SELECT COUNT(*)
+FROM speaking_turns t
+JOIN speaking_sessions s ON s.id = t.session_id
+LEFT JOIN policy p ON p.user_id = s.user_id
+WHERE t.ai_evaluation IS NOT NULL
+  AND COALESCE(p.days, sqlc.arg(default_days)::int) > 0
+  AND t."timestamp" < sqlc.arg(now)::timestamptz - make_interval(days => COALESCE(p.days, sqlc.arg(default_days)::int));
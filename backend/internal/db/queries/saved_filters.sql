@@ -0,0 +1,61 @@
+-- name: ListSavedFilters :many
+-- An empty resource lists the filters of every resource
+SELECT * FROM saved_filters
+WHERE user_id = $1 AND (sqlc.arg(resource)::text = '' OR resource = sqlc.arg(resource))
+ORDER BY resource, name;
+
+-- name: GetSavedFilter :one
+SELECT * FROM saved_filters
+WHERE id = $1 AND user_id = $2;
+
+-- name: CountSavedFilters :one
+SELECT COUNT(*) FROM saved_filters
+WHERE user_id = $1;
+
+-- name: CreateSavedFilter :one
+INSERT INTO saved_filters (user_id, resource, name, criteria)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: UpdateSavedFilter :one
+UPDATE saved_filters
+SET name = $3,
+    criteria = $4,
+    updated_at = NOW()
+WHERE id = $1 AND user_id = $2
+RETURNING *;
+
+-- name: DeleteSavedFilter :execrows
+DELETE FROM saved_filters
+WHERE id = $1 AND user_id = $2;
+
+-- name: ListWordsFiltered :many
+-- Null filters match every word; tags match words with any of them
+SELECT w.* FROM words w
+WHERE (sqlc.narg(query)::text IS NULL
+       OR strpos(LOWER(w.word), LOWER(sqlc.narg(query))) > 0
+       OR strpos(LOWER(w.short_mean), LOWER(sqlc.narg(query))) > 0)
+  AND (sqlc.narg(min_level)::int IS NULL OR w.level >= sqlc.narg(min_level))
+  AND (sqlc.narg(max_level)::int IS NULL OR w.level <= sqlc.narg(max_level))
+  AND (cardinality(sqlc.arg(tags)::text[]) = 0
+       OR EXISTS (SELECT 1 FROM word_tags t WHERE t.word_id = w.id AND t.tag = ANY(sqlc.arg(tags)::text[])))
+  AND (sqlc.narg(list_name)::text IS NULL
+       OR EXISTS (SELECT 1 FROM word_list_entries e WHERE e.word_id = w.id AND e.list_name = sqlc.narg(list_name)))
+ORDER BY w.id
+LIMIT sqlc.arg('limit')::int OFFSET sqlc.arg('offset')::int;
+
+-- name: ListExamsFiltered :many
+SELECT * FROM exams
+WHERE (sqlc.narg(query)::text IS NULL OR strpos(LOWER(title), LOWER(sqlc.narg(query))) > 0)
+  AND (sqlc.narg(is_premium)::bool IS NULL OR is_premium = sqlc.narg(is_premium))
+  AND (sqlc.narg(is_unlocked)::bool IS NULL OR is_unlocked = sqlc.narg(is_unlocked))
+ORDER BY exam_id;
+
+-- name: ListStudySetsFiltered :many
+-- Lists the sets of one user, or public sets when user_id is null
+SELECT * FROM study_sets
+WHERE (CASE WHEN sqlc.narg(user_id)::int IS NULL THEN is_public = TRUE ELSE user_id = sqlc.narg(user_id) END)
+  AND (sqlc.narg(query)::text IS NULL OR strpos(LOWER(name), LOWER(sqlc.narg(query))) > 0)
+  AND (sqlc.narg(is_public)::bool IS NULL OR COALESCE(is_public, FALSE) = sqlc.narg(is_public))
+ORDER BY updated_at DESC
+LIMIT sqlc.arg('limit')::int OFFSET sqlc.arg('offset')::int;
@@ -0,0 +1,57 @@
+-- name: ListPredictionAnswers :many
+-- The latest answer of a learner to each question in their completed
+-- attempts, most recent first. Difficulty calibrated from answers is
+-- preferred over the authored one.
+WITH latest AS (
+    SELECT DISTINCT ON (ua.question_id) ua.question_id, ua.is_correct, ua.created_at
+    FROM user_answers ua
+    JOIN exam_attempts ea ON ea.attempt_id = ua.attempt_id
+    WHERE ea.user_id = sqlc.arg(user_id)::int AND ea.status = 'completed'
+    ORDER BY ua.question_id, ua.created_at DESC, ua.user_answer_id DESC
+)
+SELECT l.question_id, l.is_correct, p.part_id, p.title AS part_title,
+       (q.media_url IS NOT NULL)::boolean AS listening,
+       COALESCE(qc.calibrated_difficulty, q.difficulty)::smallint AS difficulty
+FROM latest l
+JOIN questions q ON q.question_id = l.question_id
+JOIN contents c ON c.content_id = q.content_id
+JOIN parts p ON p.part_id = c.part_id
+LEFT JOIN question_calibrations qc ON qc.question_id = l.question_id
+ORDER BY l.created_at DESC, l.question_id
+LIMIT sqlc.arg('limit')::int;
+
+-- name: UpsertScorePrediction :exec
+INSERT INTO score_predictions (user_id, score, score_low, score_high, answers, prediction, computed_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (user_id) DO UPDATE
+SET score = EXCLUDED.score,
+    score_low = EXCLUDED.score_low,
+    score_high = EXCLUDED.score_high,
+    answers = EXCLUDED.answers,
+    prediction = EXCLUDED.prediction,
+    computed_at = EXCLUDED.computed_at;
+
+-- name: GetScorePrediction :one
+SELECT user_id, score, score_low, score_high, answers, prediction, computed_at
+FROM score_predictions
+WHERE user_id = $1;
+
+-- name: DeleteScorePrediction :execrows
+DELETE FROM score_predictions
+WHERE user_id = $1;
+
+-- name: ListScorePredictionUsers :many
+-- Learners with a stored prediction or a completed attempt, after a user
+-- ID, so every prediction can be recomputed in batches
+SELECT user_id FROM (
+    SELECT sp.user_id FROM score_predictions sp
+    UNION
+    SELECT ea.user_id FROM exam_attempts ea WHERE ea.status = 'completed'
+) learners
+WHERE user_id > sqlc.arg(after_user_id)::int
+ORDER BY user_id
+LIMIT sqlc.arg('limit')::int;
+
+-- name: GetOldestScorePrediction :one
+SELECT MIN(computed_at)::timestamptz AS computed_at
+FROM score_predictions;
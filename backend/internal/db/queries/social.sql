@@ -0,0 +1,110 @@
+-- name: FollowUser :execrows
+INSERT INTO user_follows (
+  follower_id,
+  followee_id
+) VALUES (
+  $1, $2
+)
+ON CONFLICT DO NOTHING;
+
+-- name: UnfollowUser :execrows
+DELETE FROM user_follows
+WHERE follower_id = $1 AND followee_id = $2;
+
+-- name: IsFollowing :one
+SELECT EXISTS (
+  SELECT 1 FROM user_follows
+  WHERE follower_id = $1 AND followee_id = $2
+);
+
+-- name: GetFollowCounts :one
+SELECT
+  (SELECT COUNT(*) FROM user_follows WHERE followee_id = sqlc.arg(user_id)) AS followers,
+  (SELECT COUNT(*) FROM user_follows WHERE follower_id = sqlc.arg(user_id)) AS following;
+
+-- name: ListFollowing :many
+SELECT u.id, u.username, f.created_at AS followed_at
+FROM user_follows f
+JOIN users u ON u.id = f.followee_id
+WHERE f.follower_id = $1
+ORDER BY f.created_at DESC
+LIMIT $2 OFFSET $3;
+
+-- name: ListFollowers :many
+SELECT u.id, u.username, f.created_at AS followed_at
+FROM user_follows f
+JOIN users u ON u.id = f.follower_id
+WHERE f.followee_id = $1
+ORDER BY f.created_at DESC
+LIMIT $2 OFFSET $3;
+
+-- name: ListFollowerIDs :many
+SELECT follower_id FROM user_follows
+WHERE followee_id = $1
+ORDER BY follower_id
+LIMIT $2;
+
+-- name: CreateUserActivity :one
+INSERT INTO user_activities (
+  user_id,
+  type,
+  payload,
+  xp
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: ListUserActivityDays :many
+SELECT DISTINCT (created_at AT TIME ZONE 'UTC')::date AS day
+FROM user_activities
+WHERE user_id = $1
+ORDER BY day DESC
+LIMIT $2;
+
+-- name: ListUserActivities :many
+SELECT * FROM user_activities
+WHERE user_id = sqlc.arg(user_id) AND id < sqlc.arg(before_id)
+ORDER BY id DESC
+LIMIT sqlc.arg(max_items);
+
+-- name: ListFeedActivities :many
+SELECT a.id, a.user_id, u.username, a.type, a.payload, a.xp, a.created_at,
+  COALESCE(s.share_scores, FALSE)::boolean AS share_scores
+FROM user_follows f
+JOIN user_activities a ON a.user_id = f.followee_id
+JOIN users u ON u.id = a.user_id
+LEFT JOIN user_social_settings s ON s.user_id = a.user_id
+WHERE f.follower_id = sqlc.arg(follower_id)
+  AND COALESCE(s.activity_visibility, 'followers') <> 'private'
+  AND a.id < sqlc.arg(before_id)
+ORDER BY a.id DESC
+LIMIT sqlc.arg(max_items);
+
+-- name: ListWeeklyXP :many
+SELECT u.id AS user_id, u.username, COALESCE(SUM(a.xp), 0)::bigint AS xp
+FROM users u
+LEFT JOIN user_activities a ON a.user_id = u.id AND a.created_at >= sqlc.arg(since)
+LEFT JOIN user_social_settings s ON s.user_id = u.id
+WHERE u.id = sqlc.arg(user_id)
+  OR (u.id IN (SELECT followee_id FROM user_follows WHERE follower_id = sqlc.arg(user_id))
+    AND COALESCE(s.activity_visibility, 'followers') <> 'private')
+GROUP BY u.id, u.username
+ORDER BY xp DESC, u.id;
+
+-- name: GetSocialSettings :one
+SELECT * FROM user_social_settings
+WHERE user_id = $1;
+
+-- name: UpsertSocialSettings :one
+INSERT INTO user_social_settings (
+  user_id,
+  activity_visibility,
+  share_scores
+) VALUES (
+  $1, $2, $3
+)
+ON CONFLICT (user_id) DO UPDATE SET
+  activity_visibility = EXCLUDED.activity_visibility,
+  share_scores = EXCLUDED.share_scores
+RETURNING *;
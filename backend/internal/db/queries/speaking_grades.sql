@@ -0,0 +1,96 @@
+-- name: ListSpeakingGradingQueue :many
+-- Recorded turns of learners who share an organization with the grader,
+-- oldest first, that are neither graded nor claimed by another grader.
+-- Organizations are the LTI platforms users were provisioned from.
+SELECT t.id AS turn_id, t.session_id, s.user_id AS student_id, u.username AS student_username,
+       COALESCE(s.session_topic, '')::text AS session_topic, t.text_spoken,
+       t.audio_recording_path::text AS audio_url, t.ai_score, t."timestamp" AS spoken_at,
+       g.claim_expires_at
+FROM speaking_turns t
+JOIN speaking_sessions s ON s.id = t.session_id
+JOIN users u ON u.id = s.user_id
+LEFT JOIN speaking_grades g ON g.turn_id = t.id
+WHERE t.speaker_type = 'user' AND t.audio_recording_path IS NOT NULL
+  AND s.user_id <> sqlc.arg(grader_id)::int
+  AND EXISTS (
+    SELECT 1 FROM lti_users su
+    JOIN lti_users tu ON tu.platform_id = su.platform_id
+    WHERE su.user_id = s.user_id AND tu.user_id = sqlc.arg(grader_id)::int
+      AND (sqlc.narg(organization_id)::int IS NULL OR su.platform_id = sqlc.narg(organization_id)::int)
+  )
+  AND (g.id IS NULL OR (g.status = 'claimed' AND (g.grader_id = sqlc.arg(grader_id)::int OR g.claim_expires_at <= sqlc.arg(now)::timestamptz)))
+ORDER BY t."timestamp", t.id
+LIMIT sqlc.arg('limit')::int OFFSET sqlc.arg('offset')::int;
+
+-- name: CountSpeakingGradingQueue :one
+SELECT COUNT(*)
+FROM speaking_turns t
+JOIN speaking_sessions s ON s.id = t.session_id
+LEFT JOIN speaking_grades g ON g.turn_id = t.id
+WHERE t.speaker_type = 'user' AND t.audio_recording_path IS NOT NULL
+  AND s.user_id <> sqlc.arg(grader_id)::int
+  AND EXISTS (
+    SELECT 1 FROM lti_users su
+    JOIN lti_users tu ON tu.platform_id = su.platform_id
+    WHERE su.user_id = s.user_id AND tu.user_id = sqlc.arg(grader_id)::int
+      AND (sqlc.narg(organization_id)::int IS NULL OR su.platform_id = sqlc.narg(organization_id)::int)
+  )
+  AND (g.id IS NULL OR (g.status = 'claimed' AND (g.grader_id = sqlc.arg(grader_id)::int OR g.claim_expires_at <= sqlc.arg(now)::timestamptz)));
+
+-- name: GetGradableSpeakingTurn :one
+-- A recorded turn of a learner who shares an organization with the grader
+SELECT t.id AS turn_id, s.user_id AS student_id
+FROM speaking_turns t
+JOIN speaking_sessions s ON s.id = t.session_id
+WHERE t.id = sqlc.arg(turn_id)::int AND t.speaker_type = 'user' AND t.audio_recording_path IS NOT NULL
+  AND s.user_id <> sqlc.arg(grader_id)::int
+  AND EXISTS (
+    SELECT 1 FROM lti_users su
+    JOIN lti_users tu ON tu.platform_id = su.platform_id
+    WHERE su.user_id = s.user_id AND tu.user_id = sqlc.arg(grader_id)::int
+  );
+
+-- name: ClaimSpeakingTurn :one
+-- Claims a turn unless another grader holds an unexpired claim or it was
+-- graded; claiming again extends the claim
+INSERT INTO speaking_grades (turn_id, student_id, grader_id, claimed_at, claim_expires_at)
+VALUES ($1, $2, $3, sqlc.arg(claimed_at)::timestamptz, sqlc.arg(claim_expires_at)::timestamptz)
+ON CONFLICT (turn_id) DO UPDATE
+SET grader_id = EXCLUDED.grader_id,
+    claimed_at = EXCLUDED.claimed_at,
+    claim_expires_at = EXCLUDED.claim_expires_at
+WHERE speaking_grades.status = 'claimed'
+  AND (speaking_grades.grader_id = EXCLUDED.grader_id OR speaking_grades.claim_expires_at <= EXCLUDED.claimed_at)
+RETURNING *;
+
+-- name: ReleaseSpeakingTurn :execrows
+DELETE FROM speaking_grades
+WHERE turn_id = $1 AND grader_id = $2 AND status = 'claimed';
+
+-- name: GradeSpeakingTurn :one
+-- Only the grader holding an unexpired claim can grade a turn
+UPDATE speaking_grades
+SET status = 'graded',
+    scores = sqlc.arg(scores),
+    total_score = sqlc.arg(total_score),
+    comment = sqlc.narg(comment),
+    graded_at = sqlc.arg(graded_at)::timestamptz
+WHERE turn_id = sqlc.arg(turn_id) AND grader_id = sqlc.arg(grader_id) AND status = 'claimed'
+  AND claim_expires_at > sqlc.arg(graded_at)::timestamptz
+RETURNING *;
+
+-- name: ListStudentSpeakingGrades :many
+SELECT g.turn_id, t.session_id, COALESCE(s.session_topic, '')::text AS session_topic, t.text_spoken,
+       g.scores, g.total_score::int AS total_score, g.comment, COALESCE(u.username, '')::text AS grader_username,
+       g.graded_at::timestamptz AS graded_at
+FROM speaking_grades g
+JOIN speaking_turns t ON t.id = g.turn_id
+JOIN speaking_sessions s ON s.id = t.session_id
+LEFT JOIN users u ON u.id = g.grader_id
+WHERE g.student_id = $1 AND g.status = 'graded'
+ORDER BY g.graded_at DESC, g.id DESC
+LIMIT sqlc.arg('limit')::int OFFSET sqlc.arg('offset')::int;
+
+-- name: CountStudentSpeakingGrades :one
+SELECT COUNT(*) FROM speaking_grades
+WHERE student_id = $1 AND status = 'graded';
@@ -0,0 +1,26 @@
+-- name: SuggestWords :many
+-- Words starting with the query, then words similar to it when fuzzy
+-- matching is on. prefix is the escaped lowercase query followed by %.
+SELECT id, word, short_mean,
+       (LOWER(word) = sqlc.arg(query)::text) AS exact,
+       (LOWER(word) LIKE sqlc.arg(prefix)::text) AS is_prefix,
+       similarity(word, sqlc.arg(query)::text)::real AS score
+FROM words
+WHERE LOWER(word) LIKE sqlc.arg(prefix)::text
+   OR (sqlc.arg(fuzzy)::bool AND word % sqlc.arg(query)::text)
+ORDER BY exact DESC, is_prefix DESC, score DESC, freq DESC, id
+LIMIT sqlc.arg(max_results)::int;
+
+-- name: SuggestGrammars :many
+-- Published grammar whose title starts with the query, then grammar whose
+-- title contains a word similar to it when fuzzy matching is on
+SELECT id, title, grammar_key,
+       (LOWER(title) = sqlc.arg(query)::text) AS exact,
+       (LOWER(title) LIKE sqlc.arg(prefix)::text) AS is_prefix,
+       word_similarity(sqlc.arg(query)::text, title)::real AS score
+FROM grammars
+WHERE status = 'published'
+  AND (LOWER(title) LIKE sqlc.arg(prefix)::text
+       OR (sqlc.arg(fuzzy)::bool AND sqlc.arg(query)::text <% title))
+ORDER BY exact DESC, is_prefix DESC, score DESC, level, id
+LIMIT sqlc.arg(max_results)::int;
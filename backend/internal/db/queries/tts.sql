@@ -0,0 +1,13 @@
+-- name: GetTTSClip :one
+SELECT * FROM tts_clips
+WHERE hash = $1;
+
+-- name: CreateTTSClip :exec
+INSERT INTO tts_clips (hash, voice, text, public_id, size_bytes)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (hash) DO NOTHING;
+
+-- name: TouchTTSClip :exec
+UPDATE tts_clips
+SET hits = hits + 1, last_used_at = NOW()
+WHERE hash = $1;
@@ -0,0 +1,94 @@
+-- name: UpsertUpgradeDevice :one
+-- Registers a device of a user or refreshes its platform and version
+INSERT INTO upgrade_devices (user_id, device_id, platform, app_version, last_seen_at)
+VALUES ($1, $2, $3, $4, sqlc.arg(last_seen_at)::timestamptz)
+ON CONFLICT (user_id, device_id) DO UPDATE
+SET platform = EXCLUDED.platform,
+    app_version = EXCLUDED.app_version,
+    last_seen_at = EXCLUDED.last_seen_at
+RETURNING *;
+
+-- name: ListUpgradeDeviceCandidates :many
+-- Devices seen since the given time on one of the platforms whose user has
+-- one of the roles. Empty platforms or segments match every device.
+SELECT d.id, d.user_id, u.username, d.device_id, d.platform, d.app_version
+FROM upgrade_devices d
+JOIN users u ON u.id = d.user_id
+WHERE d.last_seen_at >= sqlc.arg(seen_since)::timestamptz
+  AND (cardinality(sqlc.arg(platforms)::text[]) = 0 OR d.platform = ANY(sqlc.arg(platforms)::text[]))
+  AND (cardinality(sqlc.arg(segments)::text[]) = 0 OR EXISTS (
+    SELECT 1 FROM user_roles ur
+    JOIN roles r ON r.id = ur.role_id
+    WHERE ur.user_id = d.user_id AND r.name = ANY(sqlc.arg(segments)::text[])
+  ))
+ORDER BY d.id;
+
+-- name: CreateUpgradeRollout :one
+INSERT INTO upgrade_rollouts (version, platforms, min_app_version, max_app_version, segments, percentage, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: CreateUpgradeDeliveries :exec
+-- Adds a pending delivery of a rollout for each device
+INSERT INTO upgrade_deliveries (rollout_id, device_id)
+SELECT sqlc.arg(rollout_id)::int, unnest(sqlc.arg(device_ids)::int[])
+ON CONFLICT DO NOTHING;
+
+-- name: MarkUpgradeDeliveriesDelivered :execrows
+UPDATE upgrade_deliveries
+SET status = 'delivered', delivered_at = sqlc.arg(delivered_at)::timestamptz
+WHERE rollout_id = sqlc.arg(rollout_id)::int
+  AND device_id = ANY(sqlc.arg(device_ids)::int[])
+  AND status = 'pending';
+
+-- name: DeliverPendingUpgradeDeliveries :many
+-- Marks the pending deliveries of a device delivered and returns them
+UPDATE upgrade_deliveries d
+SET status = 'delivered', delivered_at = sqlc.arg(delivered_at)::timestamptz
+FROM upgrade_rollouts r
+WHERE r.id = d.rollout_id AND d.device_id = sqlc.arg(device_id)::int AND d.status = 'pending'
+RETURNING d.rollout_id, r.version;
+
+-- name: AcknowledgeUpgradeDelivery :execrows
+-- Acknowledging again keeps the first acknowledgement time
+UPDATE upgrade_deliveries d
+SET status = 'acknowledged',
+    delivered_at = COALESCE(d.delivered_at, sqlc.arg(acknowledged_at)::timestamptz),
+    acknowledged_at = COALESCE(d.acknowledged_at, sqlc.arg(acknowledged_at)::timestamptz)
+FROM upgrade_devices v
+WHERE v.id = d.device_id AND d.rollout_id = sqlc.arg(rollout_id)::int
+  AND v.user_id = sqlc.arg(user_id)::int AND v.device_id = sqlc.arg(device_id)::text;
+
+-- name: ListUpgradeRollouts :many
+-- Rollouts, newest first, with how many devices they reached
+SELECT r.id, r.version, r.platforms, r.min_app_version, r.max_app_version, r.segments,
+       r.percentage, r.created_by, r.created_at,
+       COUNT(d.device_id) AS targeted,
+       COUNT(d.device_id) FILTER (WHERE d.status IN ('delivered', 'acknowledged')) AS delivered,
+       COUNT(d.device_id) FILTER (WHERE d.status = 'acknowledged') AS acknowledged
+FROM upgrade_rollouts r
+LEFT JOIN upgrade_deliveries d ON d.rollout_id = r.id
+GROUP BY r.id
+ORDER BY r.created_at DESC, r.id DESC
+LIMIT $1 OFFSET $2;
+
+-- name: CountUpgradeRollouts :one
+SELECT COUNT(*) FROM upgrade_rollouts;
+
+-- name: ListUpgradeDeliveries :many
+-- Deliveries of a rollout with their device, optionally of one status
+SELECT d.device_id AS id, v.user_id, u.username, v.device_id, v.platform, v.app_version,
+       d.status, d.delivered_at, d.acknowledged_at, d.created_at
+FROM upgrade_deliveries d
+JOIN upgrade_devices v ON v.id = d.device_id
+JOIN users u ON u.id = v.user_id
+WHERE d.rollout_id = sqlc.arg(rollout_id)::int
+  AND (sqlc.narg(status)::text IS NULL OR d.status = sqlc.narg(status)::text)
+ORDER BY d.device_id
+LIMIT sqlc.arg('limit')::int OFFSET sqlc.arg('offset')::int;
+
+-- name: CountUpgradeDeliveries :one
+SELECT COUNT(*)
+FROM upgrade_deliveries d
+WHERE d.rollout_id = sqlc.arg(rollout_id)::int
+  AND (sqlc.narg(status)::text IS NULL OR d.status = sqlc.narg(status)::text);
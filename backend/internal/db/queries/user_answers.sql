@@ -4,9 +4,10 @@ INSERT INTO user_answers (
     question_id,
     selected_answer,
     is_correct,
-    answer_time
+    answer_time,
+    selected_key
 ) VALUES (
-    $1, $2, $3, $4, $5
+    $1, $2, $3, $4, $5, $6
 ) RETURNING *;
 
 -- name: GetUserAnswer :one
@@ -28,17 +29,27 @@ SELECT
     q.title as question_title,
     q.true_answer,
     q.explanation,
-    q.possible_answers
+    q.possible_answers,
+    q.answer_key,
+    q.options
 FROM user_answers ua
 JOIN questions q ON ua.question_id = q.question_id
 WHERE ua.attempt_id = $1
 ORDER BY ua.answer_time;
 
+-- name: RegradeUserAnswers :many
+UPDATE user_answers
+SET is_correct = (selected_key = sqlc.arg(answer_key)::text)
+WHERE question_id = sqlc.arg(question_id)
+  AND is_correct <> (selected_key = sqlc.arg(answer_key)::text)
+RETURNING user_answer_id, attempt_id, is_correct;
+
 -- name: UpdateUserAnswer :one
 UPDATE user_answers
 SET 
     selected_answer = $2,
     is_correct = $3,
+    selected_key = $4,
     answer_time = NOW()
 WHERE user_answer_id = $1
 RETURNING *;
@@ -48,6 +59,7 @@ UPDATE user_answers
 SET 
     selected_answer = $3,
     is_correct = $4,
+    selected_key = $5,
     answer_time = NOW()
 WHERE attempt_id = $1 AND question_id = $2
 RETURNING *;
@@ -69,14 +81,16 @@ SELECT COUNT(*) FROM user_answers
 WHERE attempt_id = $1 AND is_correct = true;
 
 -- name: GetAttemptScore :one
+-- Scales the share of correct answers to max_score: 990 for a full test,
+-- 100 for a part practice. An attempt without answers scores 0.
 SELECT 
     COUNT(*) as total_questions,
     COUNT(CASE WHEN is_correct = true THEN 1 END) as correct_answers,
-    ROUND(
-        (COUNT(CASE WHEN is_correct = true THEN 1 END)::numeric / COUNT(*)::numeric) * 990, 2
-    ) as calculated_score
+    COALESCE(ROUND(
+        (COUNT(CASE WHEN is_correct = true THEN 1 END)::numeric / NULLIF(COUNT(*), 0)::numeric) * sqlc.arg(max_score)::int, 2
+    ), 0)::text as calculated_score
 FROM user_answers
-WHERE attempt_id = $1;
+WHERE attempt_id = sqlc.arg(attempt_id);
 
 -- name: GetQuestionAnalytics :many
 SELECT 
@@ -102,6 +116,7 @@ SELECT
     ua.*,
     q.title as question_title,
     q.true_answer,
+    q.answer_key,
     ea.exam_id,
     e.title as exam_title
 FROM user_answers ua
@@ -0,0 +1,17 @@
+-- name: GetUserIdentity :one
+SELECT user_id FROM user_identities
+WHERE provider = $1 AND subject = $2;
+
+-- name: GetUserIdentityByUser :one
+SELECT * FROM user_identities
+WHERE user_id = $1 AND provider = $2;
+
+-- name: CreateUserIdentity :execrows
+INSERT INTO user_identities (provider, subject, user_id)
+VALUES ($1, $2, $3)
+ON CONFLICT DO NOTHING;
+
+-- name: TouchUserIdentity :exec
+UPDATE user_identities
+SET last_used_at = NOW()
+WHERE provider = $1 AND subject = $2;
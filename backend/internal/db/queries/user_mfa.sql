@@ -0,0 +1,31 @@
+-- name: GetUserMFA :one
+SELECT * FROM user_mfa
+WHERE user_id = $1;
+
+-- name: UpsertUserMFASecret :one
+INSERT INTO user_mfa (
+    user_id,
+    totp_secret
+) VALUES (
+    $1, $2
+)
+ON CONFLICT (user_id) DO UPDATE
+SET totp_secret = EXCLUDED.totp_secret,
+    enabled_at = NULL,
+    last_used_step = 0
+RETURNING *;
+
+-- name: EnableUserMFA :exec
+UPDATE user_mfa
+SET enabled_at = NOW(),
+    last_used_step = $2
+WHERE user_id = $1;
+
+-- name: UseUserMFAStep :execrows
+UPDATE user_mfa
+SET last_used_step = $2
+WHERE user_id = $1 AND last_used_step < $2;
+
+-- name: DeleteUserMFA :exec
+DELETE FROM user_mfa
+WHERE user_id = $1;
@@ -0,0 +1,10 @@
+-- name: GetUserPreferences :one
+SELECT * FROM user_preferences
+WHERE user_id = $1;
+
+-- name: UpsertUserPreferences :one
+INSERT INTO user_preferences (user_id, preferences)
+VALUES ($1, $2)
+ON CONFLICT (user_id) DO UPDATE
+SET preferences = EXCLUDED.preferences
+RETURNING *;
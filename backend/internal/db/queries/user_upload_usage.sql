@@ -0,0 +1,41 @@
+-- name: GetUserUploadUsage :one
+SELECT * FROM user_upload_usage
+WHERE user_id = $1 AND usage_date = $2;
+
+-- name: AddUserUploadUsage :one
+INSERT INTO user_upload_usage (
+    user_id,
+    usage_date,
+    bytes_used,
+    files_count
+) VALUES (
+    $1, $2, $3, 1
+)
+ON CONFLICT (user_id, usage_date) DO UPDATE
+SET bytes_used = user_upload_usage.bytes_used + EXCLUDED.bytes_used,
+    files_count = user_upload_usage.files_count + 1
+RETURNING *;
+
+
+-- name: ReserveUserUploadUsage :one
+-- Returns no row when the upload would exceed a limit (0 for unlimited)
+INSERT INTO user_upload_usage (
+    user_id,
+    usage_date,
+    bytes_used,
+    files_count
+) VALUES (
+    $1, $2, $3, 1
+)
+ON CONFLICT (user_id, usage_date) DO UPDATE
+SET bytes_used = user_upload_usage.bytes_used + EXCLUDED.bytes_used,
+    files_count = user_upload_usage.files_count + 1
+WHERE (sqlc.arg(max_bytes)::bigint = 0 OR user_upload_usage.bytes_used + EXCLUDED.bytes_used <= sqlc.arg(max_bytes)::bigint)
+  AND (sqlc.arg(max_files)::integer = 0 OR user_upload_usage.files_count < sqlc.arg(max_files)::integer)
+RETURNING *;
+
+-- name: ReleaseUserUploadUsage :exec
+UPDATE user_upload_usage
+SET bytes_used = GREATEST(bytes_used - $3, 0),
+    files_count = GREATEST(files_count - 1, 0)
+WHERE user_id = $1 AND usage_date = $2;
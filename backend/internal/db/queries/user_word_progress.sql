@@ -58,3 +58,16 @@ JOIN user_word_progress ON words.id = user_word_progress.word_id
 WHERE user_word_progress.user_id = $1
 ORDER BY user_word_progress.created_at DESC
 LIMIT $2 OFFSET $3;
+
+-- name: SeedUserWordProgress :execrows
+INSERT INTO user_word_progress (
+  user_id,
+  word_id,
+  next_review_at
+)
+SELECT sqlc.arg(user_id), id, NOW()
+FROM words
+WHERE level = sqlc.arg(level)
+ORDER BY freq DESC, id
+LIMIT sqlc.arg(max_words)
+ON CONFLICT (user_id, word_id) DO NOTHING;
@@ -8,13 +8,21 @@ ORDER BY id
 LIMIT $1
 OFFSET $2;
 
+-- name: ListUsersAfter :many
+SELECT * FROM users
+WHERE id > $1
+ORDER BY id
+LIMIT $2;
+
 -- name: CreateUser :one
 INSERT INTO users (
   username,
   email,
+  email_encrypted,
+  email_bidx,
   password_hash
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $3, $4, $5
 )
 RETURNING *;
 
@@ -23,7 +31,9 @@ UPDATE users
 SET 
   username = $2,
   email = $3,
-  password_hash = $4,
+  email_encrypted = $4,
+  email_bidx = $5,
+  password_hash = $6,
   updated_at = NOW()
 WHERE id = $1
 RETURNING *;
@@ -34,4 +44,90 @@ WHERE id = $1;
 
 -- name: GetUserByEmail :one
 SELECT * FROM users
-WHERE email = $1 LIMIT 1;
\ No newline at end of file
+WHERE email = $1 LIMIT 1;
+
+-- name: GetUserByEmailBidx :one
+SELECT * FROM users
+WHERE email_bidx = $1 LIMIT 1;
+
+-- name: UpdateUserEmailEncryption :exec
+UPDATE users
+SET
+  email = $2,
+  email_encrypted = $3,
+  email_bidx = $4
+WHERE id = $1;
+
+-- name: ListUserProfilesAfter :many
+SELECT * FROM user_profiles
+WHERE id > $1
+ORDER BY id
+LIMIT $2;
+
+-- name: UpdateUserProfileFullNameEncryption :exec
+UPDATE user_profiles
+SET
+  full_name = $2,
+  full_name_encrypted = $3
+WHERE id = $1;
+
+-- name: GetUserProfileByUserID :one
+SELECT * FROM user_profiles
+WHERE user_id = $1 LIMIT 1;
+
+-- name: GetUserPreferredLanguage :one
+SELECT preferred_language FROM user_profiles
+WHERE user_id = $1 LIMIT 1;
+
+-- name: SetUserPreferredLanguage :one
+INSERT INTO user_profiles (
+  user_id,
+  preferred_language
+) VALUES (
+  $1, $2
+)
+ON CONFLICT (user_id) DO UPDATE SET
+  preferred_language = EXCLUDED.preferred_language,
+  updated_at = NOW()
+RETURNING *;
+
+-- name: UpsertUserProfile :one
+INSERT INTO user_profiles (
+  user_id,
+  full_name,
+  full_name_encrypted,
+  bio,
+  avatar_url,
+  target_score,
+  exam_date,
+  daily_minutes,
+  native_language
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9
+)
+ON CONFLICT (user_id) DO UPDATE SET
+  full_name = EXCLUDED.full_name,
+  full_name_encrypted = EXCLUDED.full_name_encrypted,
+  bio = EXCLUDED.bio,
+  avatar_url = EXCLUDED.avatar_url,
+  target_score = EXCLUDED.target_score,
+  exam_date = EXCLUDED.exam_date,
+  daily_minutes = EXCLUDED.daily_minutes,
+  native_language = EXCLUDED.native_language,
+  updated_at = NOW()
+RETURNING *;
+
+-- name: UpdateUserProfilePlacement :exec
+INSERT INTO user_profiles (
+  user_id,
+  placement_score,
+  placement_levels,
+  placement_completed_at
+) VALUES (
+  $1, $2, $3, NOW()
+)
+ON CONFLICT (user_id) DO UPDATE SET
+  placement_score = EXCLUDED.placement_score,
+  placement_levels = EXCLUDED.placement_levels,
+  placement_completed_at = EXCLUDED.placement_completed_at,
+  updated_at = NOW();
@@ -14,9 +14,9 @@ INSERT INTO vocabulary_stats (
 )
 ON CONFLICT (user_id, word_id)
 DO UPDATE SET
-  total_attempts = vocabulary_stats.total_attempts + EXCLUDED.total_attempts,
-  correct_attempts = vocabulary_stats.correct_attempts + EXCLUDED.correct_attempts,
-  total_response_time_ms = vocabulary_stats.total_response_time_ms + EXCLUDED.total_response_time_ms,
+  total_attempts = COALESCE(vocabulary_stats.total_attempts, 0) + COALESCE(EXCLUDED.total_attempts, 0),
+  correct_attempts = COALESCE(vocabulary_stats.correct_attempts, 0) + COALESCE(EXCLUDED.correct_attempts, 0),
+  total_response_time_ms = COALESCE(vocabulary_stats.total_response_time_ms, 0) + COALESCE(EXCLUDED.total_response_time_ms, 0),
   mastery_level = EXCLUDED.mastery_level,
   last_attempt_at = EXCLUDED.last_attempt_at,
   updated_at = NOW()
@@ -26,6 +26,11 @@ RETURNING *;
 SELECT * FROM vocabulary_stats
 WHERE user_id = $1 AND word_id = $2;
 
+-- name: GetVocabularyStatsForUpdate :one
+SELECT * FROM vocabulary_stats
+WHERE user_id = $1 AND word_id = $2
+FOR UPDATE;
+
 -- name: ListUserVocabularyStats :many
 SELECT sqlc.embed(vocabulary_stats), sqlc.embed(words)
 FROM vocabulary_stats
@@ -34,6 +39,10 @@ WHERE vocabulary_stats.user_id = $1
 ORDER BY vocabulary_stats.last_attempt_at DESC
 LIMIT $2 OFFSET $3;
 
+-- name: ListVocabularyMastery :many
+SELECT word_id, mastery_level FROM vocabulary_stats
+WHERE user_id = $1 AND word_id = ANY(sqlc.arg(word_ids)::int[]);
+
 -- name: GetWordsNeedingReview :many
 SELECT sqlc.embed(words), sqlc.embed(vocabulary_stats)
 FROM words
@@ -0,0 +1,11 @@
+-- name: ListWordsForLinking :many
+SELECT id, word, pronounce, short_mean, level FROM words
+ORDER BY id;
+
+-- name: ListStudySetWordsForUser :many
+SELECT sw.word_id, sw.study_set_id
+FROM study_set_words sw
+JOIN study_sets s ON s.id = sw.study_set_id
+WHERE s.user_id = sqlc.arg(user_id)
+  AND sw.word_id = ANY(sqlc.arg(word_ids)::int[])
+ORDER BY sw.word_id, sw.study_set_id;
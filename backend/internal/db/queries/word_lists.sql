@@ -0,0 +1,73 @@
+-- name: ListWordsByLowerText :many
+SELECT id, word FROM words
+WHERE LOWER(word) = ANY(sqlc.arg(words)::text[]);
+
+-- name: UpsertWordListEntry :exec
+INSERT INTO word_list_entries (list_name, word_id, rank, frequency)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (list_name, word_id) DO UPDATE
+SET rank = EXCLUDED.rank,
+    frequency = EXCLUDED.frequency;
+
+-- name: ListWordListEntriesForWords :many
+SELECT word_id, rank FROM word_list_entries
+WHERE list_name = sqlc.arg(list_name) AND word_id = ANY(sqlc.arg(word_ids)::int[]);
+
+-- name: ListWordLists :many
+SELECT list_name, COUNT(*)::bigint AS words
+FROM word_list_entries
+GROUP BY list_name
+ORDER BY list_name;
+
+-- name: ListWordListEntries :many
+SELECT e.rank, e.frequency, w.id, w.word, w.level, w.descript_level, w.short_mean
+FROM word_list_entries e
+JOIN words w ON w.id = e.word_id
+WHERE e.list_name = $1
+ORDER BY e.rank NULLS LAST, w.word
+LIMIT $2
+OFFSET $3;
+
+-- name: AddWordTag :exec
+INSERT INTO word_tags (word_id, tag, source)
+VALUES ($1, $2, $3)
+ON CONFLICT (word_id, tag) DO NOTHING;
+
+-- name: ListWordTagsForWords :many
+SELECT word_id, tag FROM word_tags
+WHERE word_id = ANY(sqlc.arg(word_ids)::int[]);
+
+-- name: ListWordTagCounts :many
+SELECT tag, COUNT(*)::bigint AS words
+FROM word_tags
+GROUP BY tag
+ORDER BY tag;
+
+-- name: ListWordsByTag :many
+SELECT w.* FROM words w
+JOIN word_tags t ON t.word_id = w.id
+WHERE t.tag = $1
+ORDER BY w.freq DESC, w.id
+LIMIT $2
+OFFSET $3;
+
+-- name: CreateWordImport :one
+INSERT INTO word_imports (list_name, filename, summary, items, created_by)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetWordImport :one
+SELECT * FROM word_imports
+WHERE id = $1;
+
+-- name: ListWordImports :many
+SELECT * FROM word_imports
+ORDER BY created_at DESC, id DESC
+LIMIT $1
+OFFSET $2;
+
+-- name: FinishWordImport :one
+UPDATE word_imports
+SET status = $2, result = $3, finished_at = NOW()
+WHERE id = $1 AND status = 'pending'
+RETURNING *;
@@ -0,0 +1,54 @@
+-- name: ListWordSenses :many
+SELECT * FROM word_senses
+WHERE word_id = $1
+ORDER BY position;
+
+-- name: ListWordSensesForWords :many
+SELECT * FROM word_senses
+WHERE word_id = ANY(sqlc.arg(word_ids)::int[])
+ORDER BY word_id, position;
+
+-- name: CreateWordSense :one
+-- Senses are appended after the last sense of the word
+INSERT INTO word_senses (word_id, position, part_of_speech, meaning, register, examples)
+SELECT sqlc.arg(word_id), COALESCE(MAX(position), 0) + 1, sqlc.arg(part_of_speech), sqlc.arg(meaning), sqlc.arg(register), sqlc.arg(examples)::int[]
+FROM word_senses
+WHERE word_id = sqlc.arg(word_id)
+RETURNING *;
+
+-- name: UpdateWordSense :one
+UPDATE word_senses
+SET part_of_speech = $3,
+    meaning = $4,
+    register = $5,
+    examples = sqlc.arg(examples)::int[],
+    updated_at = NOW()
+WHERE id = $1 AND word_id = $2
+RETURNING *;
+
+-- name: DeleteWordSense :execrows
+DELETE FROM word_senses
+WHERE id = $1 AND word_id = $2;
+
+-- name: DeleteWordSenses :exec
+DELETE FROM word_senses
+WHERE word_id = $1;
+
+-- name: ListDistractorSenses :many
+-- Random senses with a neutral register for each part of speech, taken from
+-- words outside the quiz
+SELECT id, word_id, position, part_of_speech, meaning, register, examples, created_at, updated_at
+FROM (
+    SELECT s.*, ROW_NUMBER() OVER (PARTITION BY s.part_of_speech ORDER BY random()) AS pick
+    FROM word_senses s
+    WHERE s.part_of_speech = ANY(sqlc.arg(parts_of_speech)::text[])
+      AND s.register = ''
+      AND NOT s.word_id = ANY(sqlc.arg(exclude_word_ids)::int[])
+) picked
+WHERE pick <= sqlc.arg(per_part)::int
+ORDER BY part_of_speech;
+
+-- name: UpdateWordMeans :exec
+UPDATE words
+SET means = $2
+WHERE id = $1;
@@ -55,7 +55,7 @@ LIMIT $2
 OFFSET $3;
 
 -- name: SearchWordsFast :many
-SELECT id, word, pronounce, level, descript_level, short_mean, means, snym, freq, conjugation FROM words
+SELECT id, word, pronounce, level, descript_level, short_mean, means, snym, freq, conjugation, audio_url FROM words
 WHERE
     word % $1 OR
     short_mean % $1 OR
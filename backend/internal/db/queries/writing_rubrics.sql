@@ -0,0 +1,39 @@
+-- name: ListWritingRubrics :many
+SELECT * FROM writing_rubrics
+ORDER BY prompt_id NULLS FIRST, topic_id, id;
+
+-- name: GetWritingRubric :one
+SELECT * FROM writing_rubrics
+WHERE id = $1;
+
+-- name: GetWritingRubricForPrompt :one
+WITH RECURSIVE chain AS (
+    SELECT t.id, t.parent_id, 0 AS depth
+    FROM writing_topics t
+    JOIN writing_prompt_topics pt ON pt.topic_id = t.id
+    WHERE pt.prompt_id = $1
+    UNION ALL
+    SELECT t.id, t.parent_id, c.depth + 1
+    FROM writing_topics t
+    JOIN chain c ON t.id = c.parent_id
+)
+SELECT r.* FROM writing_rubrics r
+LEFT JOIN chain c ON c.id = r.topic_id
+WHERE r.prompt_id = $1 OR c.id IS NOT NULL
+ORDER BY r.prompt_id IS NULL, c.depth
+LIMIT 1;
+
+-- name: CreateWritingRubric :one
+INSERT INTO writing_rubrics (topic_id, prompt_id, name, instructions, criteria, updated_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: UpdateWritingRubric :one
+UPDATE writing_rubrics
+SET name = $2, instructions = $3, criteria = $4, updated_by = $5, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteWritingRubric :execrows
+DELETE FROM writing_rubrics
+WHERE id = $1;
@@ -0,0 +1,55 @@
+-- name: ListWritingTopics :many
+-- Every topic with how many prompts, and how many published prompts, are
+-- filed directly under it
+SELECT t.id, t.parent_id, t.slug, t.name, t.description, t.position,
+       COUNT(p.id) AS prompts,
+       COUNT(p.id) FILTER (WHERE p.status = 'published') AS published_prompts
+FROM writing_topics t
+LEFT JOIN writing_prompt_topics pt ON pt.topic_id = t.id
+LEFT JOIN writing_prompts p ON p.id = pt.prompt_id
+GROUP BY t.id
+ORDER BY t.position, t.name, t.id;
+
+-- name: GetWritingTopic :one
+SELECT * FROM writing_topics
+WHERE id = $1;
+
+-- name: CreateWritingTopic :one
+INSERT INTO writing_topics (parent_id, slug, name, description, position)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: UpdateWritingTopic :one
+UPDATE writing_topics
+SET parent_id = $2, slug = $3, name = $4, description = $5, position = $6
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteWritingTopic :execrows
+DELETE FROM writing_topics
+WHERE id = $1;
+
+-- name: SetWritingPromptTopic :exec
+INSERT INTO writing_prompt_topics (prompt_id, topic_id)
+VALUES ($1, $2)
+ON CONFLICT (prompt_id) DO UPDATE SET topic_id = EXCLUDED.topic_id;
+
+-- name: ClearWritingPromptTopic :exec
+DELETE FROM writing_prompt_topics
+WHERE prompt_id = $1;
+
+-- name: ListWritingPromptTopics :many
+SELECT prompt_id, topic_id FROM writing_prompt_topics
+WHERE prompt_id = ANY(sqlc.arg(prompt_ids)::int[]);
+
+-- name: ListWritingPromptsByTopic :many
+-- Published prompts filed under a topic or any of its descendants, newest first
+WITH RECURSIVE subtree AS (
+    SELECT id FROM writing_topics WHERE id = $1
+    UNION ALL
+    SELECT t.id FROM writing_topics t JOIN subtree s ON t.parent_id = s.id
+)
+SELECT p.* FROM writing_prompts p
+JOIN writing_prompt_topics pt ON pt.prompt_id = p.id
+WHERE p.status = 'published' AND pt.topic_id IN (SELECT id FROM subtree)
+ORDER BY p.created_at DESC;
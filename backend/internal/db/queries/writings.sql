@@ -14,6 +14,7 @@ WHERE id = $1 LIMIT 1;
 
 -- name: ListWritingPrompts :many
 SELECT * FROM writing_prompts
+WHERE status = 'published'
 ORDER BY created_at DESC;
 
 -- name: UpdateWritingPrompt :one
@@ -68,3 +69,62 @@ RETURNING *;
 -- name: DeleteUserWriting :exec
 DELETE FROM user_writings
 WHERE id = $1;
+
+-- name: SearchUserWritings :many
+-- Writing submissions of learners who share an organization with the
+-- teacher whose text or AI feedback match the search, best match first,
+-- with the matching passages. Organizations are the LTI platforms users were
+-- provisioned from and classes the LTI courses both are active members of.
+-- Null filters match every submission.
+SELECT w.id, w.user_id, u.username, w.prompt_id, w.ai_score, w.submitted_at,
+       ts_rank(to_tsvector('english', w.submission_text) || jsonb_to_tsvector('english', COALESCE(w.ai_feedback, '{}'::jsonb), '["string"]'), q.query)::float8 AS rank,
+       ts_headline('english', w.submission_text, q.query, 'StartSel=**, StopSel=**, MaxFragments=3, MaxWords=20, MinWords=5')::text AS text_headline,
+       (CASE WHEN jsonb_to_tsvector('english', COALESCE(w.ai_feedback, '{}'::jsonb), '["string"]') @@ q.query
+            THEN ts_headline('english', COALESCE((SELECT string_agg(v #>> '{}', ' ') FROM jsonb_path_query(w.ai_feedback, 'strict $.** ? (@.type() == "string")') AS v), ''), q.query, 'StartSel=**, StopSel=**, MaxFragments=3, MaxWords=20, MinWords=5')
+            ELSE '' END)::text AS feedback_headline
+FROM user_writings w
+JOIN users u ON u.id = w.user_id
+CROSS JOIN websearch_to_tsquery('english', sqlc.arg(search)::text) AS q(query)
+WHERE (to_tsvector('english', w.submission_text) || jsonb_to_tsvector('english', COALESCE(w.ai_feedback, '{}'::jsonb), '["string"]')) @@ q.query
+  AND w.user_id <> sqlc.arg(teacher_id)::int
+  AND EXISTS (
+    SELECT 1 FROM lti_users su
+    JOIN lti_users tu ON tu.platform_id = su.platform_id
+    WHERE su.user_id = w.user_id AND tu.user_id = sqlc.arg(teacher_id)::int
+  )
+  AND (sqlc.narg(class_id)::int IS NULL OR EXISTS (
+    SELECT 1 FROM lti_context_members sm
+    JOIN lti_context_members tm ON tm.context_id = sm.context_id
+    WHERE sm.context_id = sqlc.narg(class_id)::int AND sm.user_id = w.user_id AND sm.status = 'active'
+      AND tm.user_id = sqlc.arg(teacher_id)::int AND tm.status = 'active'
+  ))
+  AND (sqlc.narg(user_id)::int IS NULL OR w.user_id = sqlc.narg(user_id)::int)
+  AND (sqlc.narg(submitted_from)::timestamptz IS NULL OR w.submitted_at >= sqlc.narg(submitted_from)::timestamptz)
+  AND (sqlc.narg(submitted_to)::timestamptz IS NULL OR w.submitted_at < sqlc.narg(submitted_to)::timestamptz)
+  AND (sqlc.narg(min_score)::numeric IS NULL OR w.ai_score >= sqlc.narg(min_score)::numeric)
+  AND (sqlc.narg(max_score)::numeric IS NULL OR w.ai_score <= sqlc.narg(max_score)::numeric)
+ORDER BY rank DESC, w.submitted_at DESC, w.id DESC
+LIMIT sqlc.arg('limit')::int OFFSET sqlc.arg('offset')::int;
+
+-- name: CountUserWritingSearch :one
+SELECT COUNT(*)
+FROM user_writings w
+CROSS JOIN websearch_to_tsquery('english', sqlc.arg(search)::text) AS q(query)
+WHERE (to_tsvector('english', w.submission_text) || jsonb_to_tsvector('english', COALESCE(w.ai_feedback, '{}'::jsonb), '["string"]')) @@ q.query
+  AND w.user_id <> sqlc.arg(teacher_id)::int
+  AND EXISTS (
+    SELECT 1 FROM lti_users su
+    JOIN lti_users tu ON tu.platform_id = su.platform_id
+    WHERE su.user_id = w.user_id AND tu.user_id = sqlc.arg(teacher_id)::int
+  )
+  AND (sqlc.narg(class_id)::int IS NULL OR EXISTS (
+    SELECT 1 FROM lti_context_members sm
+    JOIN lti_context_members tm ON tm.context_id = sm.context_id
+    WHERE sm.context_id = sqlc.narg(class_id)::int AND sm.user_id = w.user_id AND sm.status = 'active'
+      AND tm.user_id = sqlc.arg(teacher_id)::int AND tm.status = 'active'
+  ))
+  AND (sqlc.narg(user_id)::int IS NULL OR w.user_id = sqlc.narg(user_id)::int)
+  AND (sqlc.narg(submitted_from)::timestamptz IS NULL OR w.submitted_at >= sqlc.narg(submitted_from)::timestamptz)
+  AND (sqlc.narg(submitted_to)::timestamptz IS NULL OR w.submitted_at < sqlc.narg(submitted_to)::timestamptz)
+  AND (sqlc.narg(min_score)::numeric IS NULL OR w.ai_score >= sqlc.narg(min_score)::numeric)
+  AND (sqlc.narg(max_score)::numeric IS NULL OR w.ai_score <= sqlc.narg(max_score)::numeric);
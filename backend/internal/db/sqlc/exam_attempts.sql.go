@@ -18,7 +18,7 @@ SET
     end_time = NOW(),
     updated_at = NOW()
 WHERE attempt_id = $1
-RETURNING attempt_id, user_id, exam_id, start_time, end_time, score, status, created_at, updated_at
+RETURNING attempt_id, user_id, exam_id, start_time, end_time, score, status, created_at, updated_at, part_id, time_limit_minutes
 `
 
 func (q *Queries) AbandonExamAttempt(ctx context.Context, attemptID int32) (ExamAttempt, error) {
@@ -34,6 +34,8 @@ func (q *Queries) AbandonExamAttempt(ctx context.Context, attemptID int32) (Exam
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.PartID,
+		&i.TimeLimitMinutes,
 	)
 	return i, err
 }
@@ -46,7 +48,7 @@ SET
     score = $2,
     updated_at = NOW()
 WHERE attempt_id = $1
-RETURNING attempt_id, user_id, exam_id, start_time, end_time, score, status, created_at, updated_at
+RETURNING attempt_id, user_id, exam_id, start_time, end_time, score, status, created_at, updated_at, part_id, time_limit_minutes
 `
 
 type CompleteExamAttemptParams struct {
@@ -67,6 +69,8 @@ func (q *Queries) CompleteExamAttempt(ctx context.Context, arg CompleteExamAttem
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.PartID,
+		&i.TimeLimitMinutes,
 	)
 	return i, err
 }
@@ -100,17 +104,21 @@ INSERT INTO exam_attempts (
     user_id,
     exam_id,
     start_time,
-    status
+    status,
+    part_id,
+    time_limit_minutes
 ) VALUES (
-    $1, $2, $3, $4
-) RETURNING attempt_id, user_id, exam_id, start_time, end_time, score, status, created_at, updated_at
+    $1, $2, $3, $4, $5, $6
+) RETURNING attempt_id, user_id, exam_id, start_time, end_time, score, status, created_at, updated_at, part_id, time_limit_minutes
 `
 
 type CreateExamAttemptParams struct {
-	UserID    int32          `json:"user_id"`
-	ExamID    int32          `json:"exam_id"`
-	StartTime time.Time      `json:"start_time"`
-	Status    ExamStatusEnum `json:"status"`
+	UserID           int32          `json:"user_id"`
+	ExamID           int32          `json:"exam_id"`
+	StartTime        time.Time      `json:"start_time"`
+	Status           ExamStatusEnum `json:"status"`
+	PartID           sql.NullInt32  `json:"part_id"`
+	TimeLimitMinutes sql.NullInt32  `json:"time_limit_minutes"`
 }
 
 func (q *Queries) CreateExamAttempt(ctx context.Context, arg CreateExamAttemptParams) (ExamAttempt, error) {
@@ -119,6 +127,8 @@ func (q *Queries) CreateExamAttempt(ctx context.Context, arg CreateExamAttemptPa
 		arg.ExamID,
 		arg.StartTime,
 		arg.Status,
+		arg.PartID,
+		arg.TimeLimitMinutes,
 	)
 	var i ExamAttempt
 	err := row.Scan(
@@ -131,6 +141,8 @@ func (q *Queries) CreateExamAttempt(ctx context.Context, arg CreateExamAttemptPa
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.PartID,
+		&i.TimeLimitMinutes,
 	)
 	return i, err
 }
@@ -146,19 +158,23 @@ func (q *Queries) DeleteExamAttempt(ctx context.Context, attemptID int32) error
 }
 
 const getActiveExamAttempt = `-- name: GetActiveExamAttempt :one
-SELECT attempt_id, user_id, exam_id, start_time, end_time, score, status, created_at, updated_at FROM exam_attempts
+SELECT attempt_id, user_id, exam_id, start_time, end_time, score, status, created_at, updated_at, part_id, time_limit_minutes FROM exam_attempts
 WHERE user_id = $1 AND exam_id = $2 AND status = 'in_progress'
+  AND part_id IS NOT DISTINCT FROM $3::int
 ORDER BY start_time DESC
 LIMIT 1
 `
 
 type GetActiveExamAttemptParams struct {
-	UserID int32 `json:"user_id"`
-	ExamID int32 `json:"exam_id"`
+	UserID int32         `json:"user_id"`
+	ExamID int32         `json:"exam_id"`
+	PartID sql.NullInt32 `json:"part_id"`
 }
 
+// The active full test of an exam when part_id is NULL, else the active
+// practice of the part
 func (q *Queries) GetActiveExamAttempt(ctx context.Context, arg GetActiveExamAttemptParams) (ExamAttempt, error) {
-	row := q.db.QueryRowContext(ctx, getActiveExamAttempt, arg.UserID, arg.ExamID)
+	row := q.db.QueryRowContext(ctx, getActiveExamAttempt, arg.UserID, arg.ExamID, arg.PartID)
 	var i ExamAttempt
 	err := row.Scan(
 		&i.AttemptID,
@@ -170,12 +186,14 @@ func (q *Queries) GetActiveExamAttempt(ctx context.Context, arg GetActiveExamAtt
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.PartID,
+		&i.TimeLimitMinutes,
 	)
 	return i, err
 }
 
 const getExamAttempt = `-- name: GetExamAttempt :one
-SELECT attempt_id, user_id, exam_id, start_time, end_time, score, status, created_at, updated_at FROM exam_attempts
+SELECT attempt_id, user_id, exam_id, start_time, end_time, score, status, created_at, updated_at, part_id, time_limit_minutes FROM exam_attempts
 WHERE attempt_id = $1 LIMIT 1
 `
 
@@ -192,12 +210,14 @@ func (q *Queries) GetExamAttempt(ctx context.Context, attemptID int32) (ExamAtte
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.PartID,
+		&i.TimeLimitMinutes,
 	)
 	return i, err
 }
 
 const getExamAttemptByUser = `-- name: GetExamAttemptByUser :one
-SELECT attempt_id, user_id, exam_id, start_time, end_time, score, status, created_at, updated_at FROM exam_attempts
+SELECT attempt_id, user_id, exam_id, start_time, end_time, score, status, created_at, updated_at, part_id, time_limit_minutes FROM exam_attempts
 WHERE attempt_id = $1 AND user_id = $2 LIMIT 1
 `
 
@@ -219,6 +239,8 @@ func (q *Queries) GetExamAttemptByUser(ctx context.Context, arg GetExamAttemptBy
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.PartID,
+		&i.TimeLimitMinutes,
 	)
 	return i, err
 }
@@ -229,9 +251,9 @@ SELECT
     COUNT(CASE WHEN status = 'completed' THEN 1 END) as completed_attempts,
     COUNT(CASE WHEN status = 'in_progress' THEN 1 END) as in_progress_attempts,
     COUNT(CASE WHEN status = 'abandoned' THEN 1 END) as abandoned_attempts,
-    AVG(score) as average_score,
-    MAX(score) as highest_score,
-    MIN(score) as lowest_score
+    COALESCE(AVG(score) FILTER (WHERE part_id IS NULL), 0)::float8 as average_score,
+    MAX(score) FILTER (WHERE part_id IS NULL) as highest_score,
+    MIN(score) FILTER (WHERE part_id IS NULL) as lowest_score
 FROM exam_attempts
 WHERE user_id = $1
 `
@@ -246,6 +268,7 @@ type GetExamAttemptStatsRow struct {
 	LowestScore        interface{} `json:"lowest_score"`
 }
 
+// Scores are of full tests; part practice is scored on another scale
 func (q *Queries) GetExamAttemptStats(ctx context.Context, userID int32) (GetExamAttemptStatsRow, error) {
 	row := q.db.QueryRowContext(ctx, getExamAttemptStats, userID)
 	var i GetExamAttemptStatsRow
@@ -270,7 +293,7 @@ SELECT
     ROW_NUMBER() OVER (ORDER BY ea.score DESC, ea.end_time ASC) as rank
 FROM exam_attempts ea
 JOIN users u ON ea.user_id = u.id
-WHERE ea.exam_id = $1 AND ea.status = 'completed' AND ea.score IS NOT NULL
+WHERE ea.exam_id = $1 AND ea.status = 'completed' AND ea.score IS NOT NULL AND ea.part_id IS NULL
 ORDER BY ea.score DESC, ea.end_time ASC
 LIMIT $2 OFFSET $3
 `
@@ -319,7 +342,7 @@ func (q *Queries) GetExamLeaderboard(ctx context.Context, arg GetExamLeaderboard
 }
 
 const listExamAttemptsByExam = `-- name: ListExamAttemptsByExam :many
-SELECT attempt_id, user_id, exam_id, start_time, end_time, score, status, created_at, updated_at FROM exam_attempts
+SELECT attempt_id, user_id, exam_id, start_time, end_time, score, status, created_at, updated_at, part_id, time_limit_minutes FROM exam_attempts
 WHERE exam_id = $1
 ORDER BY start_time DESC
 LIMIT $2 OFFSET $3
@@ -350,6 +373,8 @@ func (q *Queries) ListExamAttemptsByExam(ctx context.Context, arg ListExamAttemp
 			&i.Status,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.PartID,
+			&i.TimeLimitMinutes,
 		); err != nil {
 			return nil, err
 		}
@@ -365,7 +390,7 @@ func (q *Queries) ListExamAttemptsByExam(ctx context.Context, arg ListExamAttemp
 }
 
 const listExamAttemptsByUser = `-- name: ListExamAttemptsByUser :many
-SELECT attempt_id, user_id, exam_id, start_time, end_time, score, status, created_at, updated_at FROM exam_attempts
+SELECT attempt_id, user_id, exam_id, start_time, end_time, score, status, created_at, updated_at, part_id, time_limit_minutes FROM exam_attempts
 WHERE user_id = $1
 ORDER BY start_time DESC
 LIMIT $2 OFFSET $3
@@ -396,6 +421,8 @@ func (q *Queries) ListExamAttemptsByUser(ctx context.Context, arg ListExamAttemp
 			&i.Status,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.PartID,
+			&i.TimeLimitMinutes,
 		); err != nil {
 			return nil, err
 		}
@@ -410,6 +437,39 @@ func (q *Queries) ListExamAttemptsByUser(ctx context.Context, arg ListExamAttemp
 	return items, nil
 }
 
+const rescoreExamAttempt = `-- name: RescoreExamAttempt :one
+UPDATE exam_attempts
+SET 
+    score = $2,
+    updated_at = NOW()
+WHERE attempt_id = $1 AND status = 'completed'
+RETURNING attempt_id, user_id, exam_id, start_time, end_time, score, status, created_at, updated_at, part_id, time_limit_minutes
+`
+
+type RescoreExamAttemptParams struct {
+	AttemptID int32          `json:"attempt_id"`
+	Score     sql.NullString `json:"score"`
+}
+
+func (q *Queries) RescoreExamAttempt(ctx context.Context, arg RescoreExamAttemptParams) (ExamAttempt, error) {
+	row := q.db.QueryRowContext(ctx, rescoreExamAttempt, arg.AttemptID, arg.Score)
+	var i ExamAttempt
+	err := row.Scan(
+		&i.AttemptID,
+		&i.UserID,
+		&i.ExamID,
+		&i.StartTime,
+		&i.EndTime,
+		&i.Score,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.PartID,
+		&i.TimeLimitMinutes,
+	)
+	return i, err
+}
+
 const updateExamAttemptScore = `-- name: UpdateExamAttemptScore :one
 UPDATE exam_attempts
 SET 
@@ -424,7 +484,7 @@ SET
     END,
     updated_at = NOW()
 WHERE attempt_id = $1
-RETURNING attempt_id, user_id, exam_id, start_time, end_time, score, status, created_at, updated_at
+RETURNING attempt_id, user_id, exam_id, start_time, end_time, score, status, created_at, updated_at, part_id, time_limit_minutes
 `
 
 type UpdateExamAttemptScoreParams struct {
@@ -445,6 +505,8 @@ func (q *Queries) UpdateExamAttemptScore(ctx context.Context, arg UpdateExamAtte
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.PartID,
+		&i.TimeLimitMinutes,
 	)
 	return i, err
 }
@@ -459,7 +521,7 @@ SET
     END,
     updated_at = NOW()
 WHERE attempt_id = $1
-RETURNING attempt_id, user_id, exam_id, start_time, end_time, score, status, created_at, updated_at
+RETURNING attempt_id, user_id, exam_id, start_time, end_time, score, status, created_at, updated_at, part_id, time_limit_minutes
 `
 
 type UpdateExamAttemptStatusParams struct {
@@ -480,6 +542,8 @@ func (q *Queries) UpdateExamAttemptStatus(ctx context.Context, arg UpdateExamAtt
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.PartID,
+		&i.TimeLimitMinutes,
 	)
 	return i, err
 }
@@ -13,26 +13,38 @@ const createExam = `-- name: CreateExam :one
 INSERT INTO exams (
     title,
     time_limit_minutes,
-    is_unlocked
+    is_unlocked,
+    is_premium,
+    transcripts_enabled
 ) VALUES (
-    $1, $2, $3
-) RETURNING exam_id, title, time_limit_minutes, is_unlocked
+    $1, $2, $3, $4, $5
+) RETURNING exam_id, title, time_limit_minutes, is_unlocked, is_premium, transcripts_enabled
 `
 
 type CreateExamParams struct {
-	Title            string `json:"title"`
-	TimeLimitMinutes int32  `json:"time_limit_minutes"`
-	IsUnlocked       bool   `json:"is_unlocked"`
+	Title              string `json:"title"`
+	TimeLimitMinutes   int32  `json:"time_limit_minutes"`
+	IsUnlocked         bool   `json:"is_unlocked"`
+	IsPremium          bool   `json:"is_premium"`
+	TranscriptsEnabled bool   `json:"transcripts_enabled"`
 }
 
 func (q *Queries) CreateExam(ctx context.Context, arg CreateExamParams) (Exam, error) {
-	row := q.db.QueryRowContext(ctx, createExam, arg.Title, arg.TimeLimitMinutes, arg.IsUnlocked)
+	row := q.db.QueryRowContext(ctx, createExam,
+		arg.Title,
+		arg.TimeLimitMinutes,
+		arg.IsUnlocked,
+		arg.IsPremium,
+		arg.TranscriptsEnabled,
+	)
 	var i Exam
 	err := row.Scan(
 		&i.ExamID,
 		&i.Title,
 		&i.TimeLimitMinutes,
 		&i.IsUnlocked,
+		&i.IsPremium,
+		&i.TranscriptsEnabled,
 	)
 	return i, err
 }
@@ -48,7 +60,7 @@ func (q *Queries) DeleteExam(ctx context.Context, examID int32) error {
 }
 
 const getExam = `-- name: GetExam :one
-SELECT exam_id, title, time_limit_minutes, is_unlocked FROM exams
+SELECT exam_id, title, time_limit_minutes, is_unlocked, is_premium, transcripts_enabled FROM exams
 WHERE exam_id = $1 LIMIT 1
 `
 
@@ -60,12 +72,14 @@ func (q *Queries) GetExam(ctx context.Context, examID int32) (Exam, error) {
 		&i.Title,
 		&i.TimeLimitMinutes,
 		&i.IsUnlocked,
+		&i.IsPremium,
+		&i.TranscriptsEnabled,
 	)
 	return i, err
 }
 
 const listExams = `-- name: ListExams :many
-SELECT exam_id, title, time_limit_minutes, is_unlocked FROM exams
+SELECT exam_id, title, time_limit_minutes, is_unlocked, is_premium, transcripts_enabled FROM exams
 ORDER BY exam_id
 `
 
@@ -83,6 +97,8 @@ func (q *Queries) ListExams(ctx context.Context) ([]Exam, error) {
 			&i.Title,
 			&i.TimeLimitMinutes,
 			&i.IsUnlocked,
+			&i.IsPremium,
+			&i.TranscriptsEnabled,
 		); err != nil {
 			return nil, err
 		}
@@ -102,16 +118,20 @@ UPDATE exams
 SET
     title = $2,
     time_limit_minutes = $3,
-    is_unlocked = $4
+    is_unlocked = $4,
+    is_premium = $5,
+    transcripts_enabled = $6
 WHERE exam_id = $1
-RETURNING exam_id, title, time_limit_minutes, is_unlocked
+RETURNING exam_id, title, time_limit_minutes, is_unlocked, is_premium, transcripts_enabled
 `
 
 type UpdateExamParams struct {
-	ExamID           int32  `json:"exam_id"`
-	Title            string `json:"title"`
-	TimeLimitMinutes int32  `json:"time_limit_minutes"`
-	IsUnlocked       bool   `json:"is_unlocked"`
+	ExamID             int32  `json:"exam_id"`
+	Title              string `json:"title"`
+	TimeLimitMinutes   int32  `json:"time_limit_minutes"`
+	IsUnlocked         bool   `json:"is_unlocked"`
+	IsPremium          bool   `json:"is_premium"`
+	TranscriptsEnabled bool   `json:"transcripts_enabled"`
 }
 
 func (q *Queries) UpdateExam(ctx context.Context, arg UpdateExamParams) (Exam, error) {
@@ -120,6 +140,8 @@ func (q *Queries) UpdateExam(ctx context.Context, arg UpdateExamParams) (Exam, e
 		arg.Title,
 		arg.TimeLimitMinutes,
 		arg.IsUnlocked,
+		arg.IsPremium,
+		arg.TranscriptsEnabled,
 	)
 	var i Exam
 	err := row.Scan(
@@ -127,6 +149,8 @@ func (q *Queries) UpdateExam(ctx context.Context, arg UpdateExamParams) (Exam, e
 		&i.Title,
 		&i.TimeLimitMinutes,
 		&i.IsUnlocked,
+		&i.IsPremium,
+		&i.TranscriptsEnabled,
 	)
 	return i, err
 }
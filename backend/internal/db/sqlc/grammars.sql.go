@@ -14,8 +14,8 @@ import (
 )
 
 const batchGetGrammars = `-- name: BatchGetGrammars :many
-SELECT id, level, title, tag, grammar_key, related, contents FROM grammars
-WHERE id = ANY($1::int[])
+SELECT id, level, title, tag, grammar_key, related, contents, status FROM grammars
+WHERE id = ANY($1::int[]) AND status = 'published'
 ORDER BY id
 `
 
@@ -36,6 +36,7 @@ func (q *Queries) BatchGetGrammars(ctx context.Context, dollar_1 []int32) ([]Gra
 			&i.GrammarKey,
 			pq.Array(&i.Related),
 			&i.Contents,
+			&i.Status,
 		); err != nil {
 			return nil, err
 		}
@@ -60,7 +61,7 @@ INSERT INTO grammars (
     contents
 ) VALUES (
     $1, $2, $3, $4, $5, $6
-) RETURNING id, level, title, tag, grammar_key, related, contents
+) RETURNING id, level, title, tag, grammar_key, related, contents, status
 `
 
 type CreateGrammarParams struct {
@@ -90,6 +91,7 @@ func (q *Queries) CreateGrammar(ctx context.Context, arg CreateGrammarParams) (G
 		&i.GrammarKey,
 		pq.Array(&i.Related),
 		&i.Contents,
+		&i.Status,
 	)
 	return i, err
 }
@@ -105,7 +107,7 @@ func (q *Queries) DeleteGrammar(ctx context.Context, id int32) error {
 }
 
 const getGrammar = `-- name: GetGrammar :one
-SELECT id, level, title, tag, grammar_key, related, contents FROM grammars
+SELECT id, level, title, tag, grammar_key, related, contents, status FROM grammars
 WHERE id = $1 LIMIT 1
 `
 
@@ -120,12 +122,14 @@ func (q *Queries) GetGrammar(ctx context.Context, id int32) (Grammar, error) {
 		&i.GrammarKey,
 		pq.Array(&i.Related),
 		&i.Contents,
+		&i.Status,
 	)
 	return i, err
 }
 
 const getRandomGrammar = `-- name: GetRandomGrammar :one
-SELECT id, level, title, tag, grammar_key, related, contents FROM grammars
+SELECT id, level, title, tag, grammar_key, related, contents, status FROM grammars
+WHERE status = 'published'
 ORDER BY RANDOM()
 LIMIT 1
 `
@@ -141,12 +145,14 @@ func (q *Queries) GetRandomGrammar(ctx context.Context) (Grammar, error) {
 		&i.GrammarKey,
 		pq.Array(&i.Related),
 		&i.Contents,
+		&i.Status,
 	)
 	return i, err
 }
 
 const listGrammars = `-- name: ListGrammars :many
-SELECT id, level, title, tag, grammar_key, related, contents FROM grammars
+SELECT id, level, title, tag, grammar_key, related, contents, status FROM grammars
+WHERE status = 'published'
 ORDER BY id
 LIMIT $1
 OFFSET $2
@@ -174,6 +180,7 @@ func (q *Queries) ListGrammars(ctx context.Context, arg ListGrammarsParams) ([]G
 			&i.GrammarKey,
 			pq.Array(&i.Related),
 			&i.Contents,
+			&i.Status,
 		); err != nil {
 			return nil, err
 		}
@@ -189,8 +196,8 @@ func (q *Queries) ListGrammars(ctx context.Context, arg ListGrammarsParams) ([]G
 }
 
 const listGrammarsByLevel = `-- name: ListGrammarsByLevel :many
-SELECT id, level, title, tag, grammar_key, related, contents FROM grammars
-WHERE level = $1
+SELECT id, level, title, tag, grammar_key, related, contents, status FROM grammars
+WHERE level = $1 AND status = 'published'
 ORDER BY id
 LIMIT $2
 OFFSET $3
@@ -219,6 +226,7 @@ func (q *Queries) ListGrammarsByLevel(ctx context.Context, arg ListGrammarsByLev
 			&i.GrammarKey,
 			pq.Array(&i.Related),
 			&i.Contents,
+			&i.Status,
 		); err != nil {
 			return nil, err
 		}
@@ -234,8 +242,8 @@ func (q *Queries) ListGrammarsByLevel(ctx context.Context, arg ListGrammarsByLev
 }
 
 const listGrammarsByTag = `-- name: ListGrammarsByTag :many
-SELECT id, level, title, tag, grammar_key, related, contents FROM grammars
-WHERE $1 = ANY(tag)
+SELECT id, level, title, tag, grammar_key, related, contents, status FROM grammars
+WHERE $1 = ANY(tag) AND status = 'published'
 ORDER BY level, id
 LIMIT $2
 OFFSET $3
@@ -264,6 +272,7 @@ func (q *Queries) ListGrammarsByTag(ctx context.Context, arg ListGrammarsByTagPa
 			&i.GrammarKey,
 			pq.Array(&i.Related),
 			&i.Contents,
+			&i.Status,
 		); err != nil {
 			return nil, err
 		}
@@ -279,12 +288,13 @@ func (q *Queries) ListGrammarsByTag(ctx context.Context, arg ListGrammarsByTagPa
 }
 
 const searchGrammars = `-- name: SearchGrammars :many
-SELECT id, level, title, tag, grammar_key, related, contents FROM grammars
-WHERE
+SELECT id, level, title, tag, grammar_key, related, contents, status FROM grammars
+WHERE status = 'published' AND (
     title ILIKE '%' || $1 || '%' OR
     grammar_key ILIKE '%' || $1 || '%' OR
     EXISTS (SELECT 1 FROM unnest(tag) AS t WHERE t ILIKE '%' || $1 || '%') OR
     contents::text ILIKE '%' || $1 || '%'
+)
 ORDER BY 
     CASE 
         WHEN LOWER(title) = LOWER($1) THEN 1
@@ -322,6 +332,7 @@ func (q *Queries) SearchGrammars(ctx context.Context, arg SearchGrammarsParams)
 			&i.GrammarKey,
 			pq.Array(&i.Related),
 			&i.Contents,
+			&i.Status,
 		); err != nil {
 			return nil, err
 		}
@@ -346,7 +357,7 @@ SET
     related = $6,
     contents = $7
 WHERE id = $1
-RETURNING id, level, title, tag, grammar_key, related, contents
+RETURNING id, level, title, tag, grammar_key, related, contents, status
 `
 
 type UpdateGrammarParams struct {
@@ -378,6 +389,7 @@ func (q *Queries) UpdateGrammar(ctx context.Context, arg UpdateGrammarParams) (G
 		&i.GrammarKey,
 		pq.Array(&i.Related),
 		&i.Contents,
+		&i.Status,
 	)
 	return i, err
 }
@@ -35,6 +35,295 @@ func (e *ExamStatusEnum) Scan(src interface{}) error {
 	return nil
 }
 
+type AccountLockout struct {
+	UserID         int32        `json:"user_id"`
+	FailedAttempts int32        `json:"failed_attempts"`
+	LockedUntil    sql.NullTime `json:"locked_until"`
+	Reason         string       `json:"reason"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+}
+
+type ApiKey struct {
+	ID                int32         `json:"id"`
+	Name              string        `json:"name"`
+	ContactEmail      string        `json:"contact_email"`
+	Prefix            string        `json:"prefix"`
+	KeyHash           string        `json:"key_hash"`
+	DailyQuota        int32         `json:"daily_quota"`
+	RequestsPerMinute int32         `json:"requests_per_minute"`
+	CreatedBy         sql.NullInt32 `json:"created_by"`
+	CreatedAt         time.Time     `json:"created_at"`
+	LastUsedAt        sql.NullTime  `json:"last_used_at"`
+	RevokedAt         sql.NullTime  `json:"revoked_at"`
+}
+
+type ApiKeyUsage struct {
+	ApiKeyID  int32     `json:"api_key_id"`
+	Day       time.Time `json:"day"`
+	Endpoint  string    `json:"endpoint"`
+	Requests  int64     `json:"requests"`
+	Throttled int64     `json:"throttled"`
+}
+
+type AudioPlaybackToken struct {
+	ID           int32        `json:"id"`
+	AttemptID    int32        `json:"attempt_id"`
+	QuestionID   int32        `json:"question_id"`
+	TokenHash    string       `json:"token_hash"`
+	MaxPlays     int32        `json:"max_plays"`
+	PlaysUsed    int32        `json:"plays_used"`
+	ExpiresAt    time.Time    `json:"expires_at"`
+	LastPlayedAt sql.NullTime `json:"last_played_at"`
+	CreatedAt    time.Time    `json:"created_at"`
+}
+
+type Backup struct {
+	ID              int32     `json:"id"`
+	Filename        string    `json:"filename"`
+	Type            string    `json:"type"`
+	Mode            string    `json:"mode"`
+	Status          string    `json:"status"`
+	Description     string    `json:"description"`
+	DatabaseName    string    `json:"database_name"`
+	StorageType     string    `json:"storage_type"`
+	Size            int64     `json:"size"`
+	Checksum        string    `json:"checksum"`
+	Compressed      bool      `json:"compressed"`
+	Encrypted       bool      `json:"encrypted"`
+	SchemaVersion   string    `json:"schema_version"`
+	DurationMs      int64     `json:"duration_ms"`
+	Error           string    `json:"error"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	AppVersion      string    `json:"app_version"`
+	DbVersion       string    `json:"db_version"`
+	KeyID           string    `json:"key_id"`
+	MigrationTarget string    `json:"migration_target"`
+	Protected       bool      `json:"protected"`
+}
+
+type Badge struct {
+	ID           int32          `json:"id"`
+	Code         string         `json:"code"`
+	Name         string         `json:"name"`
+	Description  string         `json:"description"`
+	IconUrl      sql.NullString `json:"icon_url"`
+	RuleType     string         `json:"rule_type"`
+	ActivityType sql.NullString `json:"activity_type"`
+	Threshold    int32          `json:"threshold"`
+	IsActive     bool           `json:"is_active"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+}
+
+type BillingEvent struct {
+	ID         int64           `json:"id"`
+	Provider   string          `json:"provider"`
+	EventID    string          `json:"event_id"`
+	EventType  string          `json:"event_type"`
+	ExternalID string          `json:"external_id"`
+	ProductID  string          `json:"product_id"`
+	Status     string          `json:"status"`
+	PeriodEnd  sql.NullTime    `json:"period_end"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Payload    json.RawMessage `json:"payload"`
+	Result     string          `json:"result"`
+	Error      sql.NullString  `json:"error"`
+	ReceivedAt time.Time       `json:"received_at"`
+}
+
+type CalendarFeed struct {
+	UserID         int32        `json:"user_id"`
+	Version        int32        `json:"version"`
+	StudyTime      string       `json:"study_time"`
+	Timezone       string       `json:"timezone"`
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+	LastAccessedAt sql.NullTime `json:"last_accessed_at"`
+}
+
+type ContentHistory struct {
+	ID          int64         `json:"id"`
+	ContentType string        `json:"content_type"`
+	ContentID   int32         `json:"content_id"`
+	RevisionID  sql.NullInt32 `json:"revision_id"`
+	Action      string        `json:"action"`
+	FromStatus  string        `json:"from_status"`
+	ToStatus    string        `json:"to_status"`
+	ActorID     sql.NullInt32 `json:"actor_id"`
+	Note        string        `json:"note"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+type ContentRelation struct {
+	SourceType string    `json:"source_type"`
+	SourceID   int32     `json:"source_id"`
+	TargetType string    `json:"target_type"`
+	TargetID   int32     `json:"target_id"`
+	Score      float32   `json:"score"`
+	Reason     string    `json:"reason"`
+	ComputedAt time.Time `json:"computed_at"`
+}
+
+type ContentRevision struct {
+	ID          int32           `json:"id"`
+	ContentType string          `json:"content_type"`
+	ContentID   int32           `json:"content_id"`
+	Version     int32           `json:"version"`
+	Status      string          `json:"status"`
+	Data        json.RawMessage `json:"data"`
+	Note        string          `json:"note"`
+	PublishAt   sql.NullTime    `json:"publish_at"`
+	CreatedBy   sql.NullInt32   `json:"created_by"`
+	ReviewedBy  sql.NullInt32   `json:"reviewed_by"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	PublishedAt sql.NullTime    `json:"published_at"`
+}
+
+type DataAccessLog struct {
+	ID             int64         `json:"id"`
+	SubjectUserID  int32         `json:"subject_user_id"`
+	AccessorUserID sql.NullInt32 `json:"accessor_user_id"`
+	ResourceType   string        `json:"resource_type"`
+	ResourceID     int32         `json:"resource_id"`
+	Purpose        string        `json:"purpose"`
+	AccessedAt     time.Time     `json:"accessed_at"`
+}
+
+type EntitlementUsage struct {
+	UserID    int32     `json:"user_id"`
+	Feature   string    `json:"feature"`
+	UsageDate time.Time `json:"usage_date"`
+	Count     int32     `json:"count"`
+}
+
+type EventCursor struct {
+	Name      string    `json:"name"`
+	LastID    int64     `json:"last_id"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ExamAttemptIntegrityEvent struct {
+	ID          int64     `json:"id"`
+	AttemptID   int32     `json:"attempt_id"`
+	EventType   string    `json:"event_type"`
+	OccurredAt  time.Time `json:"occurred_at"`
+	DurationMs  int32     `json:"duration_ms"`
+	PasteLength int32     `json:"paste_length"`
+	ReceivedAt  time.Time `json:"received_at"`
+}
+
+type ExamProctoringConsent struct {
+	ID            int32     `json:"id"`
+	AttemptID     int32     `json:"attempt_id"`
+	UserID        int32     `json:"user_id"`
+	PolicyVersion string    `json:"policy_version"`
+	IpAddress     string    `json:"ip_address"`
+	UserAgent     string    `json:"user_agent"`
+	ConsentedAt   time.Time `json:"consented_at"`
+}
+
+type ExamProctoringPhoto struct {
+	ID         int64          `json:"id"`
+	AttemptID  sql.NullInt32  `json:"attempt_id"`
+	UserID     int32          `json:"user_id"`
+	PublicID   string         `json:"public_id"`
+	SizeBytes  int32          `json:"size_bytes"`
+	CapturedAt time.Time      `json:"captured_at"`
+	ReceivedAt time.Time      `json:"received_at"`
+	Flagged    bool           `json:"flagged"`
+	ReviewNote sql.NullString `json:"review_note"`
+	ReviewedBy sql.NullInt32  `json:"reviewed_by"`
+	ReviewedAt sql.NullTime   `json:"reviewed_at"`
+}
+
+type Job struct {
+	ID         int32           `json:"id"`
+	Kind       string          `json:"kind"`
+	UserID     int32           `json:"user_id"`
+	Runner     string          `json:"runner"`
+	Status     string          `json:"status"`
+	Progress   int32           `json:"progress"`
+	Message    string          `json:"message"`
+	Params     json.RawMessage `json:"params"`
+	Error      sql.NullString  `json:"error"`
+	ResultPath sql.NullString  `json:"result_path"`
+	ResultName sql.NullString  `json:"result_name"`
+	ResultType sql.NullString  `json:"result_type"`
+	ResultSize sql.NullInt64   `json:"result_size"`
+	CreatedAt  time.Time       `json:"created_at"`
+	StartedAt  sql.NullTime    `json:"started_at"`
+	FinishedAt sql.NullTime    `json:"finished_at"`
+	ExpiresAt  sql.NullTime    `json:"expires_at"`
+}
+
+type LtiContext struct {
+	ID             int32          `json:"id"`
+	PlatformID     int32          `json:"platform_id"`
+	ContextID      string         `json:"context_id"`
+	Title          string         `json:"title"`
+	MembershipsUrl sql.NullString `json:"memberships_url"`
+	LineitemsUrl   sql.NullString `json:"lineitems_url"`
+	RosterSyncedAt sql.NullTime   `json:"roster_synced_at"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+type LtiContextMember struct {
+	ContextID int32     `json:"context_id"`
+	UserID    int32     `json:"user_id"`
+	Roles     string    `json:"roles"`
+	Status    string    `json:"status"`
+	SyncedAt  time.Time `json:"synced_at"`
+}
+
+type LtiLaunchState struct {
+	State      string    `json:"state"`
+	Nonce      string    `json:"nonce"`
+	PlatformID int32     `json:"platform_id"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+type LtiPlatform struct {
+	ID           int32     `json:"id"`
+	Name         string    `json:"name"`
+	Issuer       string    `json:"issuer"`
+	ClientID     string    `json:"client_id"`
+	DeploymentID string    `json:"deployment_id"`
+	AuthLoginUrl string    `json:"auth_login_url"`
+	AuthTokenUrl string    `json:"auth_token_url"`
+	JwksUrl      string    `json:"jwks_url"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type LtiResourceLink struct {
+	ID             int32          `json:"id"`
+	ContextID      int32          `json:"context_id"`
+	ResourceLinkID string         `json:"resource_link_id"`
+	Title          string         `json:"title"`
+	ExamID         sql.NullInt32  `json:"exam_id"`
+	LineitemUrl    sql.NullString `json:"lineitem_url"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+type LtiScoreSubmission struct {
+	AttemptID      int32          `json:"attempt_id"`
+	ResourceLinkID int32          `json:"resource_link_id"`
+	Status         string         `json:"status"`
+	Error          sql.NullString `json:"error"`
+	Attempts       int32          `json:"attempts"`
+	SubmittedAt    time.Time      `json:"submitted_at"`
+}
+
+type LtiUser struct {
+	PlatformID int32     `json:"platform_id"`
+	Subject    string    `json:"subject"`
+	UserID     int32     `json:"user_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
 type NullExamStatusEnum struct {
 	ExamStatusEnum ExamStatusEnum `json:"exam_status_enum"`
 	Valid          bool           `json:"valid"` // Valid is true if ExamStatusEnum is not NULL
@@ -70,6 +359,9 @@ type Exam struct {
 	Title            string `json:"title"`
 	TimeLimitMinutes int32  `json:"time_limit_minutes"`
 	IsUnlocked       bool   `json:"is_unlocked"`
+	IsPremium        bool   `json:"is_premium"`
+	// Whether learners can reveal the transcripts of the exam's listening questions
+	TranscriptsEnabled bool `json:"transcripts_enabled"`
 }
 
 // Track user exam attempts with timing and scoring
@@ -79,11 +371,15 @@ type ExamAttempt struct {
 	ExamID    int32        `json:"exam_id"`
 	StartTime time.Time    `json:"start_time"`
 	EndTime   sql.NullTime `json:"end_time"`
-	// TOEIC score (0-990), NULL if not completed
+	// TOEIC score (0-990), or percentage of correct answers (0-100) for a part practice, NULL if not completed
 	Score     sql.NullString `json:"score"`
 	Status    ExamStatusEnum `json:"status"`
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
+	// Part practiced, NULL for a full test
+	PartID sql.NullInt32 `json:"part_id"`
+	// Time limit of a part practice, NULL to use the exam's
+	TimeLimitMinutes sql.NullInt32 `json:"time_limit_minutes"`
 }
 
 type Example struct {
@@ -92,6 +388,19 @@ type Example struct {
 	Meaning string `json:"meaning"`
 }
 
+type FeatureFlag struct {
+	ID                int32          `json:"id"`
+	Key               string         `json:"key"`
+	Description       sql.NullString `json:"description"`
+	Enabled           bool           `json:"enabled"`
+	RolloutPercentage int32          `json:"rollout_percentage"`
+	AllowedRoles      []string       `json:"allowed_roles"`
+	AllowedUserIds    []int32        `json:"allowed_user_ids"`
+	AllowedOrgs       []string       `json:"allowed_orgs"`
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+}
+
 type Grammar struct {
 	ID         int32           `json:"id"`
 	Level      int32           `json:"level"`
@@ -100,6 +409,7 @@ type Grammar struct {
 	GrammarKey string          `json:"grammar_key"`
 	Related    []int32         `json:"related"`
 	Contents   json.RawMessage `json:"contents"`
+	Status     string          `json:"status"`
 }
 
 type LearningAttempt struct {
@@ -142,16 +452,116 @@ type Permission struct {
 	CreatedAt   time.Time      `json:"created_at"`
 }
 
+type PlacementTest struct {
+	ID          int32                 `json:"id"`
+	UserID      int32                 `json:"user_id"`
+	Status      string                `json:"status"`
+	State       json.RawMessage       `json:"state"`
+	Result      pqtype.NullRawMessage `json:"result"`
+	StartedAt   time.Time             `json:"started_at"`
+	CompletedAt sql.NullTime          `json:"completed_at"`
+	UpdatedAt   time.Time             `json:"updated_at"`
+}
+
+type Plan struct {
+	ID           int32     `json:"id"`
+	Code         string    `json:"code"`
+	Name         string    `json:"name"`
+	Description  string    `json:"description"`
+	Entitlements []string  `json:"entitlements"`
+	IsActive     bool      `json:"is_active"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type PlanProduct struct {
+	Provider  string `json:"provider"`
+	ProductID string `json:"product_id"`
+	PlanID    int32  `json:"plan_id"`
+}
+
+type PortfolioShareLink struct {
+	ID           int32        `json:"id"`
+	UserID       int32        `json:"user_id"`
+	TokenHash    string       `json:"token_hash"`
+	Label        string       `json:"label"`
+	ExpiresAt    time.Time    `json:"expires_at"`
+	CreatedAt    time.Time    `json:"created_at"`
+	RevokedAt    sql.NullTime `json:"revoked_at"`
+	ViewCount    int32        `json:"view_count"`
+	LastViewedAt sql.NullTime `json:"last_viewed_at"`
+}
+
 type Question struct {
-	QuestionID      int32          `json:"question_id"`
-	ContentID       int32          `json:"content_id"`
-	Title           string         `json:"title"`
-	MediaUrl        sql.NullString `json:"media_url"`
-	ImageUrl        sql.NullString `json:"image_url"`
-	PossibleAnswers []string       `json:"possible_answers"`
-	TrueAnswer      string         `json:"true_answer"`
-	Explanation     string         `json:"explanation"`
-	Keywords        sql.NullString `json:"keywords"`
+	QuestionID int32          `json:"question_id"`
+	ContentID  int32          `json:"content_id"`
+	Title      string         `json:"title"`
+	MediaUrl   sql.NullString `json:"media_url"`
+	ImageUrl   sql.NullString `json:"image_url"`
+	// Texts of the options, kept in step with options for older clients
+	PossibleAnswers []string `json:"possible_answers"`
+	// Text of the correct option, kept in step with answer_key for older clients
+	TrueAnswer  string         `json:"true_answer"`
+	Explanation string         `json:"explanation"`
+	Keywords    sql.NullString `json:"keywords"`
+	Difficulty  sql.NullInt16  `json:"difficulty"`
+	// Answer options as [{"key": "A", "text": "..."}]
+	Options json.RawMessage `json:"options"`
+	// Key of the correct option
+	AnswerKey string `json:"answer_key"`
+}
+
+type QuestionCalibration struct {
+	QuestionID           int32     `json:"question_id"`
+	CalibratedDifficulty int16     `json:"calibrated_difficulty"`
+	CorrectRate          float32   `json:"correct_rate"`
+	Responses            int32     `json:"responses"`
+	ComputedAt           time.Time `json:"computed_at"`
+}
+
+// Answers that did not match exactly one option when options were keyed
+type QuestionOptionMismatch struct {
+	ID              int32         `json:"id"`
+	QuestionID      int32         `json:"question_id"`
+	UserAnswerID    sql.NullInt32 `json:"user_answer_id"`
+	AnswerText      string        `json:"answer_text"`
+	MatchingOptions int32         `json:"matching_options"`
+	CreatedAt       time.Time     `json:"created_at"`
+}
+
+type QuestionTranscript struct {
+	QuestionID int32         `json:"question_id"`
+	Transcript string        `json:"transcript"`
+	UpdatedBy  sql.NullInt32 `json:"updated_by"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+}
+
+type Referral struct {
+	ID           int32          `json:"id"`
+	ReferrerID   int32          `json:"referrer_id"`
+	ReferredID   int32          `json:"referred_id"`
+	Code         string         `json:"code"`
+	Status       string         `json:"status"`
+	RejectReason sql.NullString `json:"reject_reason"`
+	SignupIp     sql.NullString `json:"signup_ip"`
+	RewardDays   int32          `json:"reward_days"`
+	CreatedAt    time.Time      `json:"created_at"`
+	ConvertedAt  sql.NullTime   `json:"converted_at"`
+	RewardedAt   sql.NullTime   `json:"rewarded_at"`
+}
+
+type ReferralCode struct {
+	UserID    int32     `json:"user_id"`
+	Code      string    `json:"code"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type RetentionOverride struct {
+	PlatformID    int32         `json:"platform_id"`
+	Category      string        `json:"category"`
+	RetentionDays int32         `json:"retention_days"`
+	UpdatedBy     sql.NullInt32 `json:"updated_by"`
+	UpdatedAt     time.Time     `json:"updated_at"`
 }
 
 type Role struct {
@@ -168,6 +578,44 @@ type RolePermission struct {
 	CreatedAt    time.Time `json:"created_at"`
 }
 
+type SavedFilter struct {
+	ID        int32           `json:"id"`
+	UserID    int32           `json:"user_id"`
+	Resource  string          `json:"resource"`
+	Name      string          `json:"name"`
+	Criteria  json.RawMessage `json:"criteria"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+type ScorePrediction struct {
+	UserID int32 `json:"user_id"`
+	Score  int16 `json:"score"`
+	// Lower bound of the 95% confidence interval of score
+	ScoreLow int16 `json:"score_low"`
+	// Upper bound of the 95% confidence interval of score
+	ScoreHigh int16 `json:"score_high"`
+	// Answered questions the prediction is based on
+	Answers int32 `json:"answers"`
+	// Section scores and per-part accuracy the score is made of
+	Prediction json.RawMessage `json:"prediction"`
+	ComputedAt time.Time       `json:"computed_at"`
+}
+
+type SpeakingGrade struct {
+	ID             int32                 `json:"id"`
+	TurnID         int32                 `json:"turn_id"`
+	StudentID      int32                 `json:"student_id"`
+	GraderID       sql.NullInt32         `json:"grader_id"`
+	Status         string                `json:"status"`
+	ClaimedAt      time.Time             `json:"claimed_at"`
+	ClaimExpiresAt time.Time             `json:"claim_expires_at"`
+	Scores         pqtype.NullRawMessage `json:"scores"`
+	TotalScore     sql.NullInt32         `json:"total_score"`
+	Comment        sql.NullString        `json:"comment"`
+	GradedAt       sql.NullTime          `json:"graded_at"`
+}
+
 type SpeakingSession struct {
 	ID           int32          `json:"id"`
 	UserID       int32          `json:"user_id"`
@@ -204,13 +652,85 @@ type StudySetWord struct {
 	CreatedAt  time.Time `json:"created_at"`
 }
 
+type Subscription struct {
+	ID               int32        `json:"id"`
+	UserID           int32        `json:"user_id"`
+	PlanID           int32        `json:"plan_id"`
+	Provider         string       `json:"provider"`
+	ExternalID       string       `json:"external_id"`
+	Status           string       `json:"status"`
+	CurrentPeriodEnd sql.NullTime `json:"current_period_end"`
+	LastEventAt      sql.NullTime `json:"last_event_at"`
+	CreatedAt        time.Time    `json:"created_at"`
+	UpdatedAt        time.Time    `json:"updated_at"`
+}
+
+type TranscriptReveal struct {
+	AttemptID  int32     `json:"attempt_id"`
+	QuestionID int32     `json:"question_id"`
+	RevealedAt time.Time `json:"revealed_at"`
+}
+
+type TtsClip struct {
+	Hash       string    `json:"hash"`
+	Voice      string    `json:"voice"`
+	Text       string    `json:"text"`
+	PublicID   string    `json:"public_id"`
+	SizeBytes  int32     `json:"size_bytes"`
+	Hits       int32     `json:"hits"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+}
+
+type UpgradeDelivery struct {
+	RolloutID      int32        `json:"rollout_id"`
+	DeviceID       int32        `json:"device_id"`
+	Status         string       `json:"status"`
+	DeliveredAt    sql.NullTime `json:"delivered_at"`
+	AcknowledgedAt sql.NullTime `json:"acknowledged_at"`
+	CreatedAt      time.Time    `json:"created_at"`
+}
+
+type UpgradeDevice struct {
+	ID         int32     `json:"id"`
+	UserID     int32     `json:"user_id"`
+	DeviceID   string    `json:"device_id"`
+	Platform   string    `json:"platform"`
+	AppVersion string    `json:"app_version"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type UpgradeRollout struct {
+	ID            int32         `json:"id"`
+	Version       string        `json:"version"`
+	Platforms     []string      `json:"platforms"`
+	MinAppVersion string        `json:"min_app_version"`
+	MaxAppVersion string        `json:"max_app_version"`
+	Segments      []string      `json:"segments"`
+	Percentage    int32         `json:"percentage"`
+	CreatedBy     sql.NullInt32 `json:"created_by"`
+	CreatedAt     time.Time     `json:"created_at"`
+}
+
 type User struct {
-	ID           int32     `json:"id"`
-	Username     string    `json:"username"`
-	Email        string    `json:"email"`
-	PasswordHash string    `json:"password_hash"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID             int32          `json:"id"`
+	Username       string         `json:"username"`
+	Email          sql.NullString `json:"email"`
+	PasswordHash   string         `json:"password_hash"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	EmailEncrypted sql.NullString `json:"email_encrypted"`
+	EmailBidx      sql.NullString `json:"email_bidx"`
+}
+
+type UserActivity struct {
+	ID        int64           `json:"id"`
+	UserID    int32           `json:"user_id"`
+	Type      string          `json:"type"`
+	Payload   json.RawMessage `json:"payload"`
+	Xp        int32           `json:"xp"`
+	CreatedAt time.Time       `json:"created_at"`
 }
 
 // Store user answers for each question in an exam attempt
@@ -218,22 +738,74 @@ type UserAnswer struct {
 	UserAnswerID int32 `json:"user_answer_id"`
 	AttemptID    int32 `json:"attempt_id"`
 	QuestionID   int32 `json:"question_id"`
-	// User selected answer text
+	// Text of the selected option, kept in step with selected_key for older clients
 	SelectedAnswer string `json:"selected_answer"`
 	// Whether the answer is correct
 	IsCorrect  bool         `json:"is_correct"`
 	AnswerTime sql.NullTime `json:"answer_time"`
 	CreatedAt  time.Time    `json:"created_at"`
+	// Key of the selected option
+	SelectedKey string `json:"selected_key"`
+}
+
+type UserBadge struct {
+	UserID     int32         `json:"user_id"`
+	BadgeID    int32         `json:"badge_id"`
+	ActivityID sql.NullInt64 `json:"activity_id"`
+	AwardedAt  time.Time     `json:"awarded_at"`
+}
+
+type UserFollow struct {
+	FollowerID int32     `json:"follower_id"`
+	FolloweeID int32     `json:"followee_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type UserIdentity struct {
+	// Identity provider: google or apple
+	Provider string `json:"provider"`
+	// Stable ID of the user at the provider, the sub claim of its ID tokens
+	Subject   string    `json:"subject"`
+	UserID    int32     `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+	// When the provider account last signed in
+	LastUsedAt time.Time `json:"last_used_at"`
+}
+
+type UserMfa struct {
+	UserID       int32        `json:"user_id"`
+	TotpSecret   string       `json:"totp_secret"`
+	EnabledAt    sql.NullTime `json:"enabled_at"`
+	LastUsedStep int64        `json:"last_used_step"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+}
+
+type UserPreference struct {
+	UserID      int32           `json:"user_id"`
+	Preferences json.RawMessage `json:"preferences"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
 }
 
 type UserProfile struct {
-	ID        int32          `json:"id"`
-	UserID    sql.NullInt32  `json:"user_id"`
-	FullName  sql.NullString `json:"full_name"`
-	Bio       sql.NullString `json:"bio"`
-	AvatarUrl sql.NullString `json:"avatar_url"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
+	ID                   int32                 `json:"id"`
+	UserID               sql.NullInt32         `json:"user_id"`
+	FullName             sql.NullString        `json:"full_name"`
+	Bio                  sql.NullString        `json:"bio"`
+	AvatarUrl            sql.NullString        `json:"avatar_url"`
+	CreatedAt            time.Time             `json:"created_at"`
+	UpdatedAt            time.Time             `json:"updated_at"`
+	FullNameEncrypted    sql.NullString        `json:"full_name_encrypted"`
+	TargetScore          sql.NullInt32         `json:"target_score"`
+	ExamDate             sql.NullTime          `json:"exam_date"`
+	DailyMinutes         sql.NullInt32         `json:"daily_minutes"`
+	NativeLanguage       sql.NullString        `json:"native_language"`
+	PlacementScore       sql.NullInt32         `json:"placement_score"`
+	PlacementLevels      pqtype.NullRawMessage `json:"placement_levels"`
+	PlacementCompletedAt sql.NullTime          `json:"placement_completed_at"`
+	// Language of API messages when the request does not ask for one
+	PreferredLanguage sql.NullString `json:"preferred_language"`
 }
 
 type UserRole struct {
@@ -244,6 +816,34 @@ type UserRole struct {
 	ExpiresAt  sql.NullTime  `json:"expires_at"`
 }
 
+type UserSession struct {
+	ID         int64        `json:"id"`
+	UserID     int32        `json:"user_id"`
+	IpAddress  string       `json:"ip_address"`
+	UserAgent  string       `json:"user_agent"`
+	CreatedAt  time.Time    `json:"created_at"`
+	LastSeenAt time.Time    `json:"last_seen_at"`
+	ExpiresAt  time.Time    `json:"expires_at"`
+	EndedAt    sql.NullTime `json:"ended_at"`
+}
+
+type UserSocialSetting struct {
+	UserID             int32     `json:"user_id"`
+	ActivityVisibility string    `json:"activity_visibility"`
+	ShareScores        bool      `json:"share_scores"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+type UserUploadUsage struct {
+	UserID     int32     `json:"user_id"`
+	UsageDate  time.Time `json:"usage_date"`
+	BytesUsed  int64     `json:"bytes_used"`
+	FilesCount int32     `json:"files_count"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
 type UserWordProgress struct {
 	UserID         int32        `json:"user_id"`
 	WordID         int32        `json:"word_id"`
@@ -292,6 +892,56 @@ type Word struct {
 	Snym          pqtype.NullRawMessage `json:"snym"`
 	Freq          float32               `json:"freq"`
 	Conjugation   pqtype.NullRawMessage `json:"conjugation"`
+	AudioUrl      sql.NullString        `json:"audio_url"`
+}
+
+type WordImport struct {
+	ID         int32                 `json:"id"`
+	ListName   string                `json:"list_name"`
+	Filename   string                `json:"filename"`
+	Status     string                `json:"status"`
+	Summary    json.RawMessage       `json:"summary"`
+	Items      json.RawMessage       `json:"items"`
+	Result     pqtype.NullRawMessage `json:"result"`
+	CreatedBy  sql.NullInt32         `json:"created_by"`
+	CreatedAt  time.Time             `json:"created_at"`
+	FinishedAt sql.NullTime          `json:"finished_at"`
+}
+
+type WordListEntry struct {
+	ListName  string          `json:"list_name"`
+	WordID    int32           `json:"word_id"`
+	Rank      sql.NullInt32   `json:"rank"`
+	Frequency sql.NullFloat64 `json:"frequency"`
+	AddedAt   time.Time       `json:"added_at"`
+}
+
+type WordLookup struct {
+	WordID     int32          `json:"word_id"`
+	Provider   string         `json:"provider"`
+	Status     string         `json:"status"`
+	Error      sql.NullString `json:"error"`
+	Attempts   int32          `json:"attempts"`
+	LookedUpAt time.Time      `json:"looked_up_at"`
+}
+
+type WordSense struct {
+	ID           int32     `json:"id"`
+	WordID       int32     `json:"word_id"`
+	Position     int32     `json:"position"`
+	PartOfSpeech string    `json:"part_of_speech"`
+	Meaning      string    `json:"meaning"`
+	Register     string    `json:"register"`
+	Examples     []int32   `json:"examples"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type WordTag struct {
+	WordID    int32     `json:"word_id"`
+	Tag       string    `json:"tag"`
+	Source    string    `json:"source"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type WritingPrompt struct {
@@ -301,4 +951,32 @@ type WritingPrompt struct {
 	Topic           sql.NullString `json:"topic"`
 	DifficultyLevel sql.NullString `json:"difficulty_level"`
 	CreatedAt       time.Time      `json:"created_at"`
+	Status          string         `json:"status"`
+}
+
+type WritingPromptTopic struct {
+	PromptID int32 `json:"prompt_id"`
+	TopicID  int32 `json:"topic_id"`
+}
+
+type WritingRubric struct {
+	ID           int32           `json:"id"`
+	TopicID      sql.NullInt32   `json:"topic_id"`
+	PromptID     sql.NullInt32   `json:"prompt_id"`
+	Name         string          `json:"name"`
+	Instructions string          `json:"instructions"`
+	Criteria     json.RawMessage `json:"criteria"`
+	UpdatedBy    sql.NullInt32   `json:"updated_by"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+}
+
+type WritingTopic struct {
+	ID          int32         `json:"id"`
+	ParentID    sql.NullInt32 `json:"parent_id"`
+	Slug        string        `json:"slug"`
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Position    int32         `json:"position"`
+	CreatedAt   time.Time     `json:"created_at"`
 }
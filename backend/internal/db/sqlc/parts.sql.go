@@ -9,6 +9,35 @@ import (
 	"context"
 )
 
+const countPartQuestions = `-- name: CountPartQuestions :one
+SELECT
+    COUNT(*) FILTER (WHERE c.part_id = $1::int)::int AS part_questions,
+    COUNT(*)::int AS exam_questions
+FROM questions q
+JOIN contents c ON c.content_id = q.content_id
+JOIN parts p ON p.part_id = c.part_id
+WHERE p.exam_id = $2::int
+`
+
+type CountPartQuestionsParams struct {
+	PartID int32 `json:"part_id"`
+	ExamID int32 `json:"exam_id"`
+}
+
+type CountPartQuestionsRow struct {
+	PartQuestions int32 `json:"part_questions"`
+	ExamQuestions int32 `json:"exam_questions"`
+}
+
+// Questions of a part and of its whole exam, to give the part a share of
+// the exam's time limit
+func (q *Queries) CountPartQuestions(ctx context.Context, arg CountPartQuestionsParams) (CountPartQuestionsRow, error) {
+	row := q.db.QueryRowContext(ctx, countPartQuestions, arg.PartID, arg.ExamID)
+	var i CountPartQuestionsRow
+	err := row.Scan(&i.PartQuestions, &i.ExamQuestions)
+	return i, err
+}
+
 const createPart = `-- name: CreatePart :one
 INSERT INTO parts (
     exam_id,
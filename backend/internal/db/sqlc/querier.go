@@ -7,29 +7,89 @@ package db
 import (
 	"context"
 	"database/sql"
+	"time"
 )
 
 type Querier interface {
 	AbandonExamAttempt(ctx context.Context, attemptID int32) (ExamAttempt, error)
+	AbandonPlacementTest(ctx context.Context, id int32) error
+	// Acknowledging again keeps the first acknowledgement time
+	AcknowledgeUpgradeDelivery(ctx context.Context, arg AcknowledgeUpgradeDeliveryParams) (int64, error)
+	AddAPIKeyUsage(ctx context.Context, arg AddAPIKeyUsageParams) error
+	AddContentHistory(ctx context.Context, arg AddContentHistoryParams) error
+	AddUserUploadUsage(ctx context.Context, arg AddUserUploadUsageParams) (UserUploadUsage, error)
+	AddWordTag(ctx context.Context, arg AddWordTagParams) error
 	AddWordToStudySet(ctx context.Context, arg AddWordToStudySetParams) error
 	AssignPermissionToRole(ctx context.Context, arg AssignPermissionToRoleParams) error
 	AssignRoleToUser(ctx context.Context, arg AssignRoleToUserParams) error
+	AwardBadge(ctx context.Context, arg AwardBadgeParams) (int64, error)
 	BatchGetExamples(ctx context.Context, dollar_1 []int32) ([]Example, error)
 	BatchGetGrammars(ctx context.Context, dollar_1 []int32) ([]Grammar, error)
+	// Cancels a queued or running job of the user
+	CancelJob(ctx context.Context, arg CancelJobParams) (Job, error)
 	CheckUserPermission(ctx context.Context, arg CheckUserPermissionParams) (bool, error)
 	CheckUserPermissionByResourceAction(ctx context.Context, arg CheckUserPermissionByResourceActionParams) (bool, error)
+	// Claims a turn unless another grader holds an unexpired claim or it was
+	// graded; claiming again extends the claim
+	ClaimSpeakingTurn(ctx context.Context, arg ClaimSpeakingTurnParams) (SpeakingGrade, error)
 	CleanupExpiredRoles(ctx context.Context) error
+	ClearExpiredSpeakingEvaluations(ctx context.Context, arg ClearExpiredSpeakingEvaluationsParams) (int64, error)
+	ClearExpiredWritingFeedback(ctx context.Context, arg ClearExpiredWritingFeedbackParams) (int64, error)
+	ClearSpeakingAudio(ctx context.Context, ids []int32) (int64, error)
+	ClearWritingPromptTopic(ctx context.Context, promptID int32) error
 	CompleteExamAttempt(ctx context.Context, arg CompleteExamAttemptParams) (ExamAttempt, error)
+	CompletePlacementTest(ctx context.Context, arg CompletePlacementTestParams) (PlacementTest, error)
+	ConsumeEntitlementUsage(ctx context.Context, arg ConsumeEntitlementUsageParams) (int64, error)
+	ConsumeLTILaunchState(ctx context.Context, state string) (LtiLaunchState, error)
+	ConsumePlaybackToken(ctx context.Context, arg ConsumePlaybackTokenParams) (AudioPlaybackToken, error)
+	ConvertReferral(ctx context.Context, referredID int32) (Referral, error)
+	CountActivePortfolioShareLinks(ctx context.Context, arg CountActivePortfolioShareLinksParams) (int64, error)
 	CountCorrectAnswersByAttempt(ctx context.Context, attemptID int32) (int64, error)
+	CountDataAccessLogsBySubject(ctx context.Context, subjectUserID int32) (int64, error)
+	CountDuplicateQuestionPairs(ctx context.Context, minSimilarity float32) (int64, error)
 	CountExamAttemptsByExam(ctx context.Context, examID int32) (int64, error)
 	CountExamAttemptsByUser(ctx context.Context, userID int32) (int64, error)
+	CountExpiredProctoringPhotos(ctx context.Context, cutoff time.Time) (int64, error)
+	CountExpiredSpeakingAudio(ctx context.Context, arg CountExpiredSpeakingAudioParams) (int64, error)
+	CountExpiredSpeakingEvaluations(ctx context.Context, arg CountExpiredSpeakingEvaluationsParams) (int64, error)
+	CountExpiredWritingFeedback(ctx context.Context, arg CountExpiredWritingFeedbackParams) (int64, error)
+	CountMasteredWords(ctx context.Context, userID int32) (int64, error)
+	// Questions of a part and of its whole exam, to give the part a share of
+	// the exam's time limit
+	CountPartQuestions(ctx context.Context, arg CountPartQuestionsParams) (CountPartQuestionsRow, error)
+	CountProctoredAttempts(ctx context.Context, flaggedOnly bool) (int64, error)
+	CountProctoringPhotos(ctx context.Context, attemptID sql.NullInt32) (int64, error)
+	CountQuestionCalibrations(ctx context.Context, minDrift int32) (int64, error)
+	CountReferralsFromIP(ctx context.Context, arg CountReferralsFromIPParams) (int64, error)
+	CountSavedFilters(ctx context.Context, userID int32) (int64, error)
+	CountSpeakingGradingQueue(ctx context.Context, arg CountSpeakingGradingQueueParams) (int64, error)
+	CountStudentSpeakingGrades(ctx context.Context, studentID int32) (int64, error)
+	CountUpgradeDeliveries(ctx context.Context, arg CountUpgradeDeliveriesParams) (int64, error)
+	CountUpgradeRollouts(ctx context.Context) (int64, error)
+	CountUserActivitiesByType(ctx context.Context, userID int32) ([]CountUserActivitiesByTypeRow, error)
 	CountUserAnswersByAttempt(ctx context.Context, attemptID int32) (int64, error)
+	CountUserJobs(ctx context.Context, userID int32) (int64, error)
+	CountUserWritingSearch(ctx context.Context, arg CountUserWritingSearchParams) (int64, error)
+	CountWordLookupsByStatus(ctx context.Context) ([]CountWordLookupsByStatusRow, error)
 	CountWordsInStudySet(ctx context.Context, studySetID int32) (int64, error)
+	CountWordsMissingDictionaryData(ctx context.Context) (int64, error)
+	CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (ApiKey, error)
+	CreateBadge(ctx context.Context, arg CreateBadgeParams) (Badge, error)
+	CreateBillingEvent(ctx context.Context, arg CreateBillingEventParams) (int64, error)
 	CreateContent(ctx context.Context, arg CreateContentParams) (Content, error)
+	CreateContentRevision(ctx context.Context, arg CreateContentRevisionParams) (ContentRevision, error)
+	CreateDataAccessLogs(ctx context.Context, arg CreateDataAccessLogsParams) error
 	CreateExam(ctx context.Context, arg CreateExamParams) (Exam, error)
 	CreateExamAttempt(ctx context.Context, arg CreateExamAttemptParams) (ExamAttempt, error)
 	CreateExample(ctx context.Context, arg CreateExampleParams) (Example, error)
+	CreateFeatureFlag(ctx context.Context, arg CreateFeatureFlagParams) (FeatureFlag, error)
 	CreateGrammar(ctx context.Context, arg CreateGrammarParams) (Grammar, error)
+	CreateGrammarDraft(ctx context.Context, arg CreateGrammarDraftParams) (Grammar, error)
+	CreateIntegrityEvents(ctx context.Context, arg CreateIntegrityEventsParams) error
+	CreateJob(ctx context.Context, arg CreateJobParams) (Job, error)
+	CreateLTILaunchState(ctx context.Context, arg CreateLTILaunchStateParams) error
+	CreateLTIPlatform(ctx context.Context, arg CreateLTIPlatformParams) (LtiPlatform, error)
+	CreateLTIUser(ctx context.Context, arg CreateLTIUserParams) (int64, error)
 	CreateLearningAttempt(ctx context.Context, arg CreateLearningAttemptParams) (LearningAttempt, error)
 	// Learning Sessions and Attempts Queries
 	CreateLearningSession(ctx context.Context, arg CreateLearningSessionParams) (LearningSession, error)
@@ -37,146 +97,503 @@ type Querier interface {
 	CreateOrUpdateVocabularyStats(ctx context.Context, arg CreateOrUpdateVocabularyStatsParams) (VocabularyStat, error)
 	CreatePart(ctx context.Context, arg CreatePartParams) (Part, error)
 	CreatePermission(ctx context.Context, arg CreatePermissionParams) (Permission, error)
+	CreatePlacementTest(ctx context.Context, arg CreatePlacementTestParams) (PlacementTest, error)
+	CreatePlan(ctx context.Context, arg CreatePlanParams) (Plan, error)
+	CreatePlanProduct(ctx context.Context, arg CreatePlanProductParams) error
+	CreatePortfolioShareLink(ctx context.Context, arg CreatePortfolioShareLinkParams) (PortfolioShareLink, error)
+	CreateProctoringPhoto(ctx context.Context, arg CreateProctoringPhotoParams) (ExamProctoringPhoto, error)
 	CreateQuestion(ctx context.Context, arg CreateQuestionParams) (Question, error)
+	CreateReferral(ctx context.Context, arg CreateReferralParams) (Referral, error)
+	CreateReferralCode(ctx context.Context, arg CreateReferralCodeParams) (int64, error)
 	CreateRole(ctx context.Context, arg CreateRoleParams) (Role, error)
+	CreateSavedFilter(ctx context.Context, arg CreateSavedFilterParams) (SavedFilter, error)
 	CreateSpeakingSession(ctx context.Context, arg CreateSpeakingSessionParams) (SpeakingSession, error)
 	CreateSpeakingTurn(ctx context.Context, arg CreateSpeakingTurnParams) (SpeakingTurn, error)
 	// Study Sets Queries
 	CreateStudySet(ctx context.Context, arg CreateStudySetParams) (StudySet, error)
+	CreateSubscription(ctx context.Context, arg CreateSubscriptionParams) (Subscription, error)
+	CreateTTSClip(ctx context.Context, arg CreateTTSClipParams) error
+	// Adds a pending delivery of a rollout for each device
+	CreateUpgradeDeliveries(ctx context.Context, arg CreateUpgradeDeliveriesParams) error
+	CreateUpgradeRollout(ctx context.Context, arg CreateUpgradeRolloutParams) (UpgradeRollout, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateUserActivity(ctx context.Context, arg CreateUserActivityParams) (UserActivity, error)
 	CreateUserAnswer(ctx context.Context, arg CreateUserAnswerParams) (UserAnswer, error)
+	CreateUserIdentity(ctx context.Context, arg CreateUserIdentityParams) (int64, error)
+	CreateUserSession(ctx context.Context, arg CreateUserSessionParams) (UserSession, error)
 	CreateUserWordProgress(ctx context.Context, arg CreateUserWordProgressParams) (UserWordProgress, error)
 	CreateUserWriting(ctx context.Context, arg CreateUserWritingParams) (UserWriting, error)
 	CreateWord(ctx context.Context, arg CreateWordParams) (Word, error)
+	CreateWordImport(ctx context.Context, arg CreateWordImportParams) (WordImport, error)
+	// Senses are appended after the last sense of the word
+	CreateWordSense(ctx context.Context, arg CreateWordSenseParams) (WordSense, error)
 	CreateWritingPrompt(ctx context.Context, arg CreateWritingPromptParams) (WritingPrompt, error)
+	CreateWritingPromptDraft(ctx context.Context, arg CreateWritingPromptDraftParams) (WritingPrompt, error)
+	CreateWritingRubric(ctx context.Context, arg CreateWritingRubricParams) (WritingRubric, error)
+	CreateWritingTopic(ctx context.Context, arg CreateWritingTopicParams) (WritingTopic, error)
+	DeactivateLTIContextMembers(ctx context.Context, arg DeactivateLTIContextMembersParams) (int64, error)
+	DeleteBadge(ctx context.Context, id int32) (int64, error)
+	DeleteCalendarFeed(ctx context.Context, userID int32) (int64, error)
 	DeleteContent(ctx context.Context, contentID int32) error
+	DeleteDataAccessLogsBefore(ctx context.Context, accessedAt time.Time) (int64, error)
 	DeleteExam(ctx context.Context, examID int32) error
 	DeleteExamAttempt(ctx context.Context, attemptID int32) error
 	DeleteExample(ctx context.Context, id int32) error
+	// Deletes finished jobs whose result expired and returns their files
+	DeleteExpiredJobs(ctx context.Context, now time.Time) ([]string, error)
+	DeleteExpiredLTILaunchStates(ctx context.Context) (int64, error)
+	DeleteFeatureFlag(ctx context.Context, key string) error
 	DeleteGrammar(ctx context.Context, id int32) error
+	DeleteLTIPlatform(ctx context.Context, id int32) (int64, error)
 	DeleteLearningSession(ctx context.Context, arg DeleteLearningSessionParams) error
 	DeletePart(ctx context.Context, partID int32) error
 	DeletePermission(ctx context.Context, id int32) error
+	DeletePlanProducts(ctx context.Context, planID int32) error
+	DeleteProctoringPhotos(ctx context.Context, ids []int64) (int64, error)
 	DeleteQuestion(ctx context.Context, questionID int32) error
+	DeleteQuestionTranscript(ctx context.Context, questionID int32) (int64, error)
+	DeleteRetentionOverride(ctx context.Context, arg DeleteRetentionOverrideParams) (int64, error)
 	DeleteRole(ctx context.Context, id int32) error
+	DeleteSavedFilter(ctx context.Context, arg DeleteSavedFilterParams) (int64, error)
+	DeleteScorePrediction(ctx context.Context, userID int32) (int64, error)
 	DeleteSpeakingSession(ctx context.Context, id int32) error
 	DeleteSpeakingTurn(ctx context.Context, id int32) error
+	DeleteStaleContentRelations(ctx context.Context, computedAt time.Time) (int64, error)
+	DeleteStaleQuestionCalibrations(ctx context.Context, computedAt time.Time) (int64, error)
+	DeleteStaleUserSessions(ctx context.Context, arg DeleteStaleUserSessionsParams) (int64, error)
 	DeleteStudySet(ctx context.Context, arg DeleteStudySetParams) error
 	DeleteUser(ctx context.Context, id int32) error
 	DeleteUserAnswer(ctx context.Context, userAnswerID int32) error
 	DeleteUserAnswersByAttempt(ctx context.Context, attemptID int32) error
+	DeleteUserMFA(ctx context.Context, userID int32) error
 	DeleteUserWordProgress(ctx context.Context, arg DeleteUserWordProgressParams) error
 	DeleteUserWriting(ctx context.Context, id int32) error
 	DeleteVocabularyStats(ctx context.Context, arg DeleteVocabularyStatsParams) error
 	DeleteWord(ctx context.Context, id int32) error
+	DeleteWordSense(ctx context.Context, arg DeleteWordSenseParams) (int64, error)
+	DeleteWordSenses(ctx context.Context, wordID int32) error
 	DeleteWritingPrompt(ctx context.Context, id int32) error
+	DeleteWritingRubric(ctx context.Context, id int32) (int64, error)
+	DeleteWritingTopic(ctx context.Context, id int32) (int64, error)
+	// Marks the pending deliveries of a device delivered and returns them
+	DeliverPendingUpgradeDeliveries(ctx context.Context, arg DeliverPendingUpgradeDeliveriesParams) ([]DeliverPendingUpgradeDeliveriesRow, error)
+	EnableUserMFA(ctx context.Context, arg EnableUserMFAParams) error
+	// Ends the latest open session of the client
+	EndUserSession(ctx context.Context, arg EndUserSessionParams) (int64, error)
+	ExpireLapsedSubscriptions(ctx context.Context, currentPeriodEnd sql.NullTime) (int64, error)
+	// Fails the jobs a previous process of the runner left queued or running
+	FailInterruptedJobs(ctx context.Context, arg FailInterruptedJobsParams) (int64, error)
+	FillWordDictionaryData(ctx context.Context, arg FillWordDictionaryDataParams) (Word, error)
+	// Questions with the same fingerprint as the given text, then questions
+	// whose fingerprint is at least min_similarity similar, most similar first.
+	// The % operator uses the trigram index with the default threshold of 0.3.
+	FindSimilarQuestions(ctx context.Context, arg FindSimilarQuestionsParams) ([]FindSimilarQuestionsRow, error)
+	// Records the outcome of a running job; a job cancelled meanwhile keeps
+	// its cancelled status
+	FinishJob(ctx context.Context, arg FinishJobParams) (Job, error)
+	FinishWordImport(ctx context.Context, arg FinishWordImportParams) (WordImport, error)
+	FollowUser(ctx context.Context, arg FollowUserParams) (int64, error)
+	GetAPIKey(ctx context.Context, id int32) (ApiKey, error)
+	GetAPIKeyByPrefix(ctx context.Context, prefix string) (ApiKey, error)
+	GetAPIKeyDailyRequests(ctx context.Context, arg GetAPIKeyDailyRequestsParams) (int64, error)
+	GetAccountLockout(ctx context.Context, userID int32) (AccountLockout, error)
+	// The active full test of an exam when part_id is NULL, else the active
+	// practice of the part
 	GetActiveExamAttempt(ctx context.Context, arg GetActiveExamAttemptParams) (ExamAttempt, error)
+	GetActivePlacementTest(ctx context.Context, userID int32) (PlacementTest, error)
 	GetAllUserSavedWords(ctx context.Context, arg GetAllUserSavedWordsParams) ([]GetAllUserSavedWordsRow, error)
-	GetAttemptScore(ctx context.Context, attemptID int32) (GetAttemptScoreRow, error)
+	// Scales the share of correct answers to max_score: 990 for a full test,
+	// 100 for a part practice. An attempt without answers scores 0.
+	GetAttemptScore(ctx context.Context, arg GetAttemptScoreParams) (GetAttemptScoreRow, error)
+	// A question of an exam with whether the exam offers transcripts and the
+	// question's transcript, if it has one
+	GetAttemptTranscript(ctx context.Context, arg GetAttemptTranscriptParams) (GetAttemptTranscriptRow, error)
+	GetBadge(ctx context.Context, id int32) (Badge, error)
+	GetCalendarFeed(ctx context.Context, userID int32) (CalendarFeed, error)
 	GetContent(ctx context.Context, contentID int32) (Content, error)
+	GetContentForUpdate(ctx context.Context, contentID int32) (Content, error)
+	GetContentRelationsSummary(ctx context.Context) (GetContentRelationsSummaryRow, error)
+	GetContentRevision(ctx context.Context, id int32) (ContentRevision, error)
+	GetEntitlementUsage(ctx context.Context, arg GetEntitlementUsageParams) (int32, error)
+	GetEventCursor(ctx context.Context, name string) (int64, error)
 	GetExam(ctx context.Context, examID int32) (Exam, error)
 	GetExamAttempt(ctx context.Context, attemptID int32) (ExamAttempt, error)
 	GetExamAttemptByUser(ctx context.Context, arg GetExamAttemptByUserParams) (ExamAttempt, error)
+	// Scores are of full tests; part practice is scored on another scale
 	GetExamAttemptStats(ctx context.Context, userID int32) (GetExamAttemptStatsRow, error)
 	GetExamLeaderboard(ctx context.Context, arg GetExamLeaderboardParams) ([]GetExamLeaderboardRow, error)
+	GetExamQuestionAudio(ctx context.Context, arg GetExamQuestionAudioParams) (GetExamQuestionAudioRow, error)
 	GetExample(ctx context.Context, id int32) (Example, error)
+	GetFeatureFlagByKey(ctx context.Context, key string) (FeatureFlag, error)
+	GetFollowCounts(ctx context.Context, userID int32) (GetFollowCountsRow, error)
+	// A recorded turn of a learner who shares an organization with the grader
+	GetGradableSpeakingTurn(ctx context.Context, arg GetGradableSpeakingTurnParams) (GetGradableSpeakingTurnRow, error)
 	GetGrammar(ctx context.Context, id int32) (Grammar, error)
+	GetJob(ctx context.Context, id int32) (Job, error)
+	GetLTIContext(ctx context.Context, id int32) (LtiContext, error)
+	GetLTIPlatform(ctx context.Context, id int32) (LtiPlatform, error)
+	GetLTIUser(ctx context.Context, arg GetLTIUserParams) (int32, error)
+	GetLatestProctoringPhoto(ctx context.Context, attemptID sql.NullInt32) (ExamProctoringPhoto, error)
 	GetLearningAttempt(ctx context.Context, id int32) (LearningAttempt, error)
 	GetLearningSession(ctx context.Context, arg GetLearningSessionParams) (LearningSession, error)
+	GetOldestScorePrediction(ctx context.Context) (sql.NullTime, error)
 	GetPart(ctx context.Context, partID int32) (Part, error)
 	GetPermission(ctx context.Context, id int32) (Permission, error)
 	GetPermissionByName(ctx context.Context, name string) (Permission, error)
+	// Difficulty calibrated from answers is preferred over the authored one
+	GetPlacementQuestion(ctx context.Context, arg GetPlacementQuestionParams) (GetPlacementQuestionRow, error)
+	GetPlacementTest(ctx context.Context, id int32) (PlacementTest, error)
+	GetPlan(ctx context.Context, id int32) (Plan, error)
+	GetPlanByCode(ctx context.Context, code string) (Plan, error)
+	GetPlanByProduct(ctx context.Context, arg GetPlanByProductParams) (Plan, error)
+	GetPlaybackToken(ctx context.Context, tokenHash string) (AudioPlaybackToken, error)
 	GetPopularWords(ctx context.Context, arg GetPopularWordsParams) ([]Word, error)
+	GetProctoringConsent(ctx context.Context, attemptID int32) (ExamProctoringConsent, error)
+	GetProctoringPhoto(ctx context.Context, id int64) (ExamProctoringPhoto, error)
 	GetQuestion(ctx context.Context, questionID int32) (Question, error)
 	GetQuestionAnalytics(ctx context.Context, examID int32) ([]GetQuestionAnalyticsRow, error)
+	GetQuestionCalibrationSummary(ctx context.Context) (GetQuestionCalibrationSummaryRow, error)
+	GetQuestionForUpdate(ctx context.Context, questionID int32) (Question, error)
+	GetQuestionPartID(ctx context.Context, questionID int32) (int32, error)
+	GetQuestionTranscript(ctx context.Context, questionID int32) (QuestionTranscript, error)
 	GetRandomGrammar(ctx context.Context) (Grammar, error)
+	GetReferralCode(ctx context.Context, code string) (ReferralCode, error)
+	GetReferralCodeByUser(ctx context.Context, userID int32) (ReferralCode, error)
+	GetReferralSummary(ctx context.Context, createdAt time.Time) (GetReferralSummaryRow, error)
+	GetReferrerStats(ctx context.Context, referrerID int32) (GetReferrerStatsRow, error)
 	GetRole(ctx context.Context, id int32) (Role, error)
 	GetRoleByName(ctx context.Context, name string) (Role, error)
 	GetRolePermissions(ctx context.Context, roleID int32) ([]Permission, error)
+	GetSavedFilter(ctx context.Context, arg GetSavedFilterParams) (SavedFilter, error)
+	GetScorePrediction(ctx context.Context, userID int32) (ScorePrediction, error)
 	GetSessionStats(ctx context.Context, sessionID int32) (GetSessionStatsRow, error)
+	GetSocialSettings(ctx context.Context, userID int32) (UserSocialSetting, error)
+	// Weekly scores of a user's speaking turns since a time
+	GetSpeakingScoreProgression(ctx context.Context, arg GetSpeakingScoreProgressionParams) ([]GetSpeakingScoreProgressionRow, error)
 	GetSpeakingSession(ctx context.Context, id int32) (SpeakingSession, error)
 	GetSpeakingTurn(ctx context.Context, id int32) (SpeakingTurn, error)
 	GetStudySet(ctx context.Context, id int32) (StudySet, error)
 	GetStudySetWithWords(ctx context.Context, id int32) ([]GetStudySetWithWordsRow, error)
 	GetStudySetWords(ctx context.Context, studySetID int32) ([]GetStudySetWordsRow, error)
+	GetSubscriptionByExternalID(ctx context.Context, arg GetSubscriptionByExternalIDParams) (Subscription, error)
+	GetTTSClip(ctx context.Context, hash string) (TtsClip, error)
 	GetUser(ctx context.Context, id int32) (User, error)
 	GetUserAnswer(ctx context.Context, userAnswerID int32) (UserAnswer, error)
 	GetUserAnswerByAttemptAndQuestion(ctx context.Context, arg GetUserAnswerByAttemptAndQuestionParams) (UserAnswer, error)
 	GetUserAnswerHistory(ctx context.Context, arg GetUserAnswerHistoryParams) ([]GetUserAnswerHistoryRow, error)
-	GetUserByEmail(ctx context.Context, email string) (User, error)
+	GetUserByEmail(ctx context.Context, email sql.NullString) (User, error)
+	GetUserByEmailBidx(ctx context.Context, emailBidx sql.NullString) (User, error)
+	GetUserIdentity(ctx context.Context, arg GetUserIdentityParams) (int32, error)
+	GetUserIdentityByUser(ctx context.Context, arg GetUserIdentityByUserParams) (UserIdentity, error)
 	GetUserLearningProgress(ctx context.Context, userID int32) (GetUserLearningProgressRow, error)
+	GetUserMFA(ctx context.Context, userID int32) (UserMfa, error)
 	GetUserMasteryDistribution(ctx context.Context, userID int32) ([]GetUserMasteryDistributionRow, error)
 	GetUserPermissions(ctx context.Context, userID int32) ([]Permission, error)
+	GetUserPreferences(ctx context.Context, userID int32) (UserPreference, error)
+	GetUserPreferredLanguage(ctx context.Context, userID sql.NullInt32) (sql.NullString, error)
+	GetUserProfileByUserID(ctx context.Context, userID sql.NullInt32) (UserProfile, error)
 	GetUserRoleAssignments(ctx context.Context, userID int32) ([]GetUserRoleAssignmentsRow, error)
 	GetUserRoles(ctx context.Context, userID int32) ([]Role, error)
+	GetUserTotalXP(ctx context.Context, userID int32) (int64, error)
+	GetUserUploadUsage(ctx context.Context, arg GetUserUploadUsageParams) (UserUploadUsage, error)
 	GetUserWordProgress(ctx context.Context, arg GetUserWordProgressParams) (UserWordProgress, error)
 	GetUserWriting(ctx context.Context, id int32) (UserWriting, error)
 	GetUsersByRole(ctx context.Context, name string) ([]int32, error)
 	GetVocabularyStats(ctx context.Context, arg GetVocabularyStatsParams) (VocabularyStat, error)
+	GetVocabularyStatsForUpdate(ctx context.Context, arg GetVocabularyStatsForUpdateParams) (VocabularyStat, error)
 	GetWord(ctx context.Context, id int32) (Word, error)
+	GetWordImport(ctx context.Context, id int32) (WordImport, error)
 	GetWordWithProgress(ctx context.Context, arg GetWordWithProgressParams) (GetWordWithProgressRow, error)
 	GetWordsByLevel(ctx context.Context, arg GetWordsByLevelParams) ([]Word, error)
 	GetWordsForReview(ctx context.Context, userID int32) ([]GetWordsForReviewRow, error)
 	GetWordsNeedingReview(ctx context.Context, arg GetWordsNeedingReviewParams) ([]GetWordsNeedingReviewRow, error)
 	GetWritingPrompt(ctx context.Context, id int32) (WritingPrompt, error)
+	GetWritingRubric(ctx context.Context, id int32) (WritingRubric, error)
+	GetWritingRubricForPrompt(ctx context.Context, promptID int32) (WritingRubric, error)
+	// Weekly scores of a user's evaluated writing submissions since a time
+	GetWritingScoreProgression(ctx context.Context, arg GetWritingScoreProgressionParams) ([]GetWritingScoreProgressionRow, error)
+	GetWritingTopic(ctx context.Context, id int32) (WritingTopic, error)
+	// Only the grader holding an unexpired claim can grade a turn
+	GradeSpeakingTurn(ctx context.Context, arg GradeSpeakingTurnParams) (SpeakingGrade, error)
+	IsFollowing(ctx context.Context, arg IsFollowingParams) (bool, error)
+	IsTranscriptRevealed(ctx context.Context, arg IsTranscriptRevealedParams) (bool, error)
+	// Issuing again for the same question replaces the token and its expiry
+	// but keeps the plays already used
+	IssuePlaybackToken(ctx context.Context, arg IssuePlaybackTokenParams) (AudioPlaybackToken, error)
+	ListAPIKeyUsage(ctx context.Context, arg ListAPIKeyUsageParams) ([]ListAPIKeyUsageRow, error)
+	ListAPIKeyUsageTotals(ctx context.Context, day time.Time) ([]ListAPIKeyUsageTotalsRow, error)
+	ListAPIKeys(ctx context.Context) ([]ApiKey, error)
+	ListActivePlans(ctx context.Context) ([]Plan, error)
+	ListActivitiesAfter(ctx context.Context, arg ListActivitiesAfterParams) ([]UserActivity, error)
+	ListActivitiesBetween(ctx context.Context, arg ListActivitiesBetweenParams) ([]UserActivity, error)
+	ListAllWordTags(ctx context.Context) ([]ListAllWordTagsRow, error)
+	ListBackupsByStatus(ctx context.Context, status string) ([]Backup, error)
+	ListBadges(ctx context.Context) ([]Badge, error)
+	// The speaking sessions of a user with the highest average score of their
+	// turns
+	ListBestSpeakingSessions(ctx context.Context, arg ListBestSpeakingSessionsParams) ([]ListBestSpeakingSessionsRow, error)
+	// The highest scored evaluated submissions of a user
+	ListBestUserWritings(ctx context.Context, arg ListBestUserWritingsParams) ([]ListBestUserWritingsRow, error)
+	ListBillingEvents(ctx context.Context, arg ListBillingEventsParams) ([]BillingEvent, error)
+	ListContentHistory(ctx context.Context, arg ListContentHistoryParams) ([]ContentHistory, error)
+	ListContentRevisions(ctx context.Context, arg ListContentRevisionsParams) ([]ContentRevision, error)
+	ListContentRevisionsForContent(ctx context.Context, arg ListContentRevisionsForContentParams) ([]ContentRevision, error)
 	ListContentsByPart(ctx context.Context, partID int32) ([]Content, error)
+	ListDataAccessLogsBySubject(ctx context.Context, arg ListDataAccessLogsBySubjectParams) ([]ListDataAccessLogsBySubjectRow, error)
+	// Random senses with a neutral register for each part of speech, taken from
+	// words outside the quiz
+	ListDistractorSenses(ctx context.Context, arg ListDistractorSensesParams) ([]WordSense, error)
+	ListDueContentRevisions(ctx context.Context, arg ListDueContentRevisionsParams) ([]ContentRevision, error)
+	// Pairs of questions in the bank that are exact or near duplicates of each
+	// other, exact duplicates first, then most similar first
+	ListDuplicateQuestionPairs(ctx context.Context, arg ListDuplicateQuestionPairsParams) ([]ListDuplicateQuestionPairsRow, error)
 	ListExamAttemptsByExam(ctx context.Context, arg ListExamAttemptsByExamParams) ([]ExamAttempt, error)
 	ListExamAttemptsByUser(ctx context.Context, arg ListExamAttemptsByUserParams) ([]ExamAttempt, error)
 	ListExamples(ctx context.Context) ([]Example, error)
 	ListExams(ctx context.Context) ([]Exam, error)
+	ListExamsFiltered(ctx context.Context, arg ListExamsFilteredParams) ([]Exam, error)
+	ListExpiredProctoringPhotos(ctx context.Context, arg ListExpiredProctoringPhotosParams) ([]ListExpiredProctoringPhotosRow, error)
+	ListExpiredSpeakingAudio(ctx context.Context, arg ListExpiredSpeakingAudioParams) ([]ListExpiredSpeakingAudioRow, error)
+	ListFeatureFlags(ctx context.Context) ([]FeatureFlag, error)
+	ListFeedActivities(ctx context.Context, arg ListFeedActivitiesParams) ([]ListFeedActivitiesRow, error)
+	ListFollowerIDs(ctx context.Context, arg ListFollowerIDsParams) ([]int32, error)
+	ListFollowers(ctx context.Context, arg ListFollowersParams) ([]ListFollowersRow, error)
+	ListFollowing(ctx context.Context, arg ListFollowingParams) ([]ListFollowingRow, error)
 	ListGrammars(ctx context.Context, arg ListGrammarsParams) ([]Grammar, error)
 	ListGrammarsByLevel(ctx context.Context, arg ListGrammarsByLevelParams) ([]Grammar, error)
 	ListGrammarsByTag(ctx context.Context, arg ListGrammarsByTagParams) ([]Grammar, error)
+	ListIntegrityEvents(ctx context.Context, arg ListIntegrityEventsParams) ([]ExamAttemptIntegrityEvent, error)
+	ListLTIContextMembers(ctx context.Context, contextID int32) ([]ListLTIContextMembersRow, error)
+	ListLTIContexts(ctx context.Context, arg ListLTIContextsParams) ([]LtiContext, error)
+	ListLTIGradeTargets(ctx context.Context, arg ListLTIGradeTargetsParams) ([]ListLTIGradeTargetsRow, error)
+	ListLTIPlatforms(ctx context.Context) ([]LtiPlatform, error)
+	ListLTIPlatformsByIssuer(ctx context.Context, issuer string) ([]LtiPlatform, error)
+	ListLTIScoreSubmissions(ctx context.Context, arg ListLTIScoreSubmissionsParams) ([]LtiScoreSubmission, error)
 	ListPartsByExam(ctx context.Context, examID int32) ([]Part, error)
 	ListPermissions(ctx context.Context) ([]Permission, error)
 	ListPermissionsByResource(ctx context.Context, resource string) ([]Permission, error)
+	ListPlacementWords(ctx context.Context, arg ListPlacementWordsParams) ([]Word, error)
+	ListPlanProducts(ctx context.Context) ([]PlanProduct, error)
+	ListPlans(ctx context.Context) ([]Plan, error)
+	ListPortfolioShareLinks(ctx context.Context, userID int32) ([]PortfolioShareLink, error)
+	// The latest answer of a learner to each question in their completed
+	// attempts, most recent first. Difficulty calibrated from answers is
+	// preferred over the authored one.
+	ListPredictionAnswers(ctx context.Context, arg ListPredictionAnswersParams) ([]ListPredictionAnswersRow, error)
+	ListProctoredAttempts(ctx context.Context, arg ListProctoredAttemptsParams) ([]ListProctoredAttemptsRow, error)
+	ListProctoringPhotos(ctx context.Context, attemptID sql.NullInt32) ([]ExamProctoringPhoto, error)
 	ListPublicStudySets(ctx context.Context, arg ListPublicStudySetsParams) ([]StudySet, error)
+	ListPublishedGrammarsForRelations(ctx context.Context) ([]ListPublishedGrammarsForRelationsRow, error)
+	// Counts the first answer of each learner to a question, so retakes do not
+	// make questions look easier
+	ListQuestionAnswerStats(ctx context.Context, minResponses int32) ([]ListQuestionAnswerStatsRow, error)
+	// Questions whose calibrated difficulty differs most from the authored one
+	// come first; questions without an authored difficulty always qualify
+	ListQuestionCalibrations(ctx context.Context, arg ListQuestionCalibrationsParams) ([]ListQuestionCalibrationsRow, error)
 	ListQuestionsByContent(ctx context.Context, contentID int32) ([]Question, error)
+	ListQuestionsForRelations(ctx context.Context) ([]ListQuestionsForRelationsRow, error)
+	ListRecentBackups(ctx context.Context, limit int32) ([]Backup, error)
+	ListReferralRejectReasons(ctx context.Context, createdAt time.Time) ([]ListReferralRejectReasonsRow, error)
+	// Targets that were deleted or unpublished since the last rebuild are skipped
+	ListRelatedContent(ctx context.Context, arg ListRelatedContentParams) ([]ListRelatedContentRow, error)
+	ListRetentionOverrides(ctx context.Context) ([]ListRetentionOverridesRow, error)
 	ListRoles(ctx context.Context) ([]Role, error)
+	// An empty resource lists the filters of every resource
+	ListSavedFilters(ctx context.Context, arg ListSavedFiltersParams) ([]SavedFilter, error)
+	// Learners with a stored prediction or a completed attempt, after a user
+	// ID, so every prediction can be recomputed in batches
+	ListScorePredictionUsers(ctx context.Context, arg ListScorePredictionUsersParams) ([]int32, error)
 	ListSessionAttempts(ctx context.Context, sessionID int32) ([]ListSessionAttemptsRow, error)
+	// Recorded turns of learners who share an organization with the grader,
+	// oldest first, that are neither graded nor claimed by another grader.
+	// Organizations are the LTI platforms users were provisioned from.
+	ListSpeakingGradingQueue(ctx context.Context, arg ListSpeakingGradingQueueParams) ([]ListSpeakingGradingQueueRow, error)
 	ListSpeakingSessionsByUserID(ctx context.Context, userID int32) ([]SpeakingSession, error)
 	ListSpeakingTurnsBySessionID(ctx context.Context, sessionID int32) ([]SpeakingTurn, error)
+	ListStudentSpeakingGrades(ctx context.Context, arg ListStudentSpeakingGradesParams) ([]ListStudentSpeakingGradesRow, error)
+	ListStudySetWordsForUser(ctx context.Context, arg ListStudySetWordsForUserParams) ([]ListStudySetWordsForUserRow, error)
+	// Lists the sets of one user, or public sets when user_id is null
+	ListStudySetsFiltered(ctx context.Context, arg ListStudySetsFilteredParams) ([]StudySet, error)
+	ListTopReferrers(ctx context.Context, arg ListTopReferrersParams) ([]ListTopReferrersRow, error)
+	ListUnearnedBadges(ctx context.Context, userID int32) ([]Badge, error)
+	ListUnmatchedBillingEvents(ctx context.Context, arg ListUnmatchedBillingEventsParams) ([]BillingEvent, error)
+	// Deliveries of a rollout with their device, optionally of one status
+	ListUpgradeDeliveries(ctx context.Context, arg ListUpgradeDeliveriesParams) ([]ListUpgradeDeliveriesRow, error)
+	// Devices seen since the given time on one of the platforms whose user has
+	// one of the roles. Empty platforms or segments match every device.
+	ListUpgradeDeviceCandidates(ctx context.Context, arg ListUpgradeDeviceCandidatesParams) ([]ListUpgradeDeviceCandidatesRow, error)
+	// Rollouts, newest first, with how many devices they reached
+	ListUpgradeRollouts(ctx context.Context, arg ListUpgradeRolloutsParams) ([]ListUpgradeRolloutsRow, error)
+	ListUserActivities(ctx context.Context, arg ListUserActivitiesParams) ([]UserActivity, error)
+	ListUserActivityDays(ctx context.Context, arg ListUserActivityDaysParams) ([]time.Time, error)
 	ListUserAnswersByAttempt(ctx context.Context, attemptID int32) ([]UserAnswer, error)
 	ListUserAnswersByAttemptWithQuestions(ctx context.Context, attemptID int32) ([]ListUserAnswersByAttemptWithQuestionsRow, error)
+	ListUserBadges(ctx context.Context, userID int32) ([]ListUserBadgesRow, error)
+	ListUserEntitlements(ctx context.Context, userID int32) ([]string, error)
+	ListUserJobs(ctx context.Context, arg ListUserJobsParams) ([]Job, error)
 	ListUserLearningSessions(ctx context.Context, arg ListUserLearningSessionsParams) ([]LearningSession, error)
+	ListUserProfilesAfter(ctx context.Context, arg ListUserProfilesAfterParams) ([]UserProfile, error)
+	// Only open sessions unless include_closed is set
+	ListUserSessions(ctx context.Context, arg ListUserSessionsParams) ([]UserSession, error)
 	ListUserStudySets(ctx context.Context, arg ListUserStudySetsParams) ([]StudySet, error)
+	ListUserSubscriptions(ctx context.Context, userID int32) ([]Subscription, error)
 	ListUserVocabularyStats(ctx context.Context, arg ListUserVocabularyStatsParams) ([]ListUserVocabularyStatsRow, error)
 	ListUserWordProgressByNextReview(ctx context.Context, arg ListUserWordProgressByNextReviewParams) ([]UserWordProgress, error)
 	ListUserWritingsByPromptID(ctx context.Context, promptID sql.NullInt32) ([]UserWriting, error)
 	ListUserWritingsByUserID(ctx context.Context, userID int32) ([]UserWriting, error)
 	ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error)
+	ListUsersAfter(ctx context.Context, arg ListUsersAfterParams) ([]User, error)
 	ListUsersWithRole(ctx context.Context, roleID int32) ([]User, error)
+	ListVocabularyMastery(ctx context.Context, arg ListVocabularyMasteryParams) ([]ListVocabularyMasteryRow, error)
+	ListWeeklyXP(ctx context.Context, arg ListWeeklyXPParams) ([]ListWeeklyXPRow, error)
+	ListWordImports(ctx context.Context, arg ListWordImportsParams) ([]WordImport, error)
+	ListWordListEntries(ctx context.Context, arg ListWordListEntriesParams) ([]ListWordListEntriesRow, error)
+	ListWordListEntriesForWords(ctx context.Context, arg ListWordListEntriesForWordsParams) ([]ListWordListEntriesForWordsRow, error)
+	ListWordLists(ctx context.Context) ([]ListWordListsRow, error)
+	ListWordSenses(ctx context.Context, wordID int32) ([]WordSense, error)
+	ListWordSensesForWords(ctx context.Context, wordIds []int32) ([]WordSense, error)
+	ListWordTagCounts(ctx context.Context) ([]ListWordTagCountsRow, error)
+	ListWordTagsForWords(ctx context.Context, wordIds []int32) ([]ListWordTagsForWordsRow, error)
 	ListWords(ctx context.Context, arg ListWordsParams) ([]Word, error)
+	ListWordsByLowerText(ctx context.Context, words []string) ([]ListWordsByLowerTextRow, error)
+	ListWordsByTag(ctx context.Context, arg ListWordsByTagParams) ([]Word, error)
+	// Null filters match every word; tags match words with any of them
+	ListWordsFiltered(ctx context.Context, arg ListWordsFilteredParams) ([]Word, error)
+	ListWordsForLinking(ctx context.Context) ([]ListWordsForLinkingRow, error)
+	ListWordsForRelations(ctx context.Context) ([]ListWordsForRelationsRow, error)
+	ListWordsMissingDictionaryData(ctx context.Context, arg ListWordsMissingDictionaryDataParams) ([]Word, error)
+	ListWritingPromptTopics(ctx context.Context, promptIds []int32) ([]WritingPromptTopic, error)
 	ListWritingPrompts(ctx context.Context) ([]WritingPrompt, error)
+	// Published prompts filed under a topic or any of its descendants, newest first
+	ListWritingPromptsByTopic(ctx context.Context, id int32) ([]WritingPrompt, error)
+	ListWritingRubrics(ctx context.Context) ([]WritingRubric, error)
+	// Every topic with how many prompts, and how many published prompts, are
+	// filed directly under it
+	ListWritingTopics(ctx context.Context) ([]ListWritingTopicsRow, error)
+	LockAccount(ctx context.Context, arg LockAccountParams) (AccountLockout, error)
+	MarkLTIContextRosterSynced(ctx context.Context, id int32) error
+	MarkReferralRewarded(ctx context.Context, arg MarkReferralRewardedParams) error
+	MarkUpgradeDeliveriesDelivered(ctx context.Context, arg MarkUpgradeDeliveriesDeliveredParams) (int64, error)
+	PublishGrammar(ctx context.Context, arg PublishGrammarParams) (Grammar, error)
+	PublishWritingPrompt(ctx context.Context, arg PublishWritingPromptParams) (WritingPrompt, error)
+	RecordFailedLogin(ctx context.Context, userID int32) (AccountLockout, error)
+	RecordProctoringConsent(ctx context.Context, arg RecordProctoringConsentParams) (ExamProctoringConsent, error)
+	RecordTranscriptReveal(ctx context.Context, arg RecordTranscriptRevealParams) error
+	RegradeUserAnswers(ctx context.Context, arg RegradeUserAnswersParams) ([]RegradeUserAnswersRow, error)
+	ReleaseEntitlementUsage(ctx context.Context, arg ReleaseEntitlementUsageParams) error
+	ReleaseSpeakingTurn(ctx context.Context, arg ReleaseSpeakingTurnParams) (int64, error)
+	ReleaseUserUploadUsage(ctx context.Context, arg ReleaseUserUploadUsageParams) error
 	RemovePermissionFromRole(ctx context.Context, arg RemovePermissionFromRoleParams) error
 	RemoveRoleFromUser(ctx context.Context, arg RemoveRoleFromUserParams) error
 	RemoveWordFromStudySet(ctx context.Context, arg RemoveWordFromStudySetParams) error
+	RescoreExamAttempt(ctx context.Context, arg RescoreExamAttemptParams) (ExamAttempt, error)
+	// Returns no row when the upload would exceed a limit (0 for unlimited)
+	ReserveUserUploadUsage(ctx context.Context, arg ReserveUserUploadUsageParams) (UserUploadUsage, error)
+	// Keeps locks that have not expired
+	ResetFailedLogins(ctx context.Context, userID int32) error
+	ReviewProctoringPhoto(ctx context.Context, arg ReviewProctoringPhotoParams) (ExamProctoringPhoto, error)
+	RevokeAPIKey(ctx context.Context, id int32) (ApiKey, error)
+	RevokePortfolioShareLink(ctx context.Context, arg RevokePortfolioShareLinkParams) (PortfolioShareLink, error)
+	RotateCalendarFeed(ctx context.Context, userID int32) (CalendarFeed, error)
+	// Lists the backups matching the filters, newest first. An empty status,
+	// type or search text and a null bound match every backup.
+	SearchBackups(ctx context.Context, arg SearchBackupsParams) ([]Backup, error)
 	SearchGrammars(ctx context.Context, arg SearchGrammarsParams) ([]Grammar, error)
+	// Writing submissions of learners who share an organization with the
+	// teacher whose text or AI feedback match the search, best match first,
+	// with the matching passages. Organizations are the LTI platforms users were
+	// provisioned from and classes the LTI courses both are active members of.
+	// Null filters match every submission.
+	SearchUserWritings(ctx context.Context, arg SearchUserWritingsParams) ([]SearchUserWritingsRow, error)
 	SearchWords(ctx context.Context, arg SearchWordsParams) ([]Word, error)
 	SearchWordsFast(ctx context.Context, arg SearchWordsFastParams) ([]Word, error)
 	SearchWordsFullText(ctx context.Context, arg SearchWordsFullTextParams) ([]SearchWordsFullTextRow, error)
+	SeedUserWordProgress(ctx context.Context, arg SeedUserWordProgressParams) (int64, error)
+	SetBackupProtected(ctx context.Context, arg SetBackupProtectedParams) (int64, error)
+	SetBackupStatus(ctx context.Context, arg SetBackupStatusParams) error
+	SetBillingEventResult(ctx context.Context, arg SetBillingEventResultParams) error
+	SetUserPreferredLanguage(ctx context.Context, arg SetUserPreferredLanguageParams) (UserProfile, error)
+	SetWritingPromptTopic(ctx context.Context, arg SetWritingPromptTopicParams) error
+	// Moves a queued job to running; cancelled jobs are left alone
+	StartJob(ctx context.Context, arg StartJobParams) (Job, error)
+	// Published grammar whose title starts with the query, then grammar whose
+	// title contains a word similar to it when fuzzy matching is on
+	SuggestGrammars(ctx context.Context, arg SuggestGrammarsParams) ([]SuggestGrammarsRow, error)
+	// Words starting with the query, then words similar to it when fuzzy
+	// matching is on. prefix is the escaped lowercase query followed by %.
+	SuggestWords(ctx context.Context, arg SuggestWordsParams) ([]SuggestWordsRow, error)
+	SummarizeIntegrityEvents(ctx context.Context, attemptID int32) ([]SummarizeIntegrityEventsRow, error)
+	TouchAPIKey(ctx context.Context, id int32) error
+	TouchCalendarFeed(ctx context.Context, userID int32) error
+	TouchTTSClip(ctx context.Context, hash string) error
+	TouchUserIdentity(ctx context.Context, arg TouchUserIdentityParams) error
+	// Extends the latest open session of the client
+	TouchUserSession(ctx context.Context, arg TouchUserSessionParams) (int64, error)
+	TransitionContentRevision(ctx context.Context, arg TransitionContentRevisionParams) (ContentRevision, error)
+	UnfollowUser(ctx context.Context, arg UnfollowUserParams) (int64, error)
+	UnlockAccount(ctx context.Context, userID int32) (int64, error)
+	UpdateAPIKeyLimits(ctx context.Context, arg UpdateAPIKeyLimitsParams) (ApiKey, error)
+	UpdateBadge(ctx context.Context, arg UpdateBadgeParams) (Badge, error)
 	UpdateContent(ctx context.Context, arg UpdateContentParams) (Content, error)
+	UpdateContentRevisionData(ctx context.Context, arg UpdateContentRevisionDataParams) (ContentRevision, error)
 	UpdateExam(ctx context.Context, arg UpdateExamParams) (Exam, error)
 	UpdateExamAttemptScore(ctx context.Context, arg UpdateExamAttemptScoreParams) (ExamAttempt, error)
 	UpdateExamAttemptStatus(ctx context.Context, arg UpdateExamAttemptStatusParams) (ExamAttempt, error)
 	UpdateExample(ctx context.Context, arg UpdateExampleParams) (Example, error)
+	UpdateFeatureFlag(ctx context.Context, arg UpdateFeatureFlagParams) (FeatureFlag, error)
 	UpdateGrammar(ctx context.Context, arg UpdateGrammarParams) (Grammar, error)
+	UpdateJobProgress(ctx context.Context, arg UpdateJobProgressParams) error
 	UpdateLearningSession(ctx context.Context, arg UpdateLearningSessionParams) (LearningSession, error)
 	UpdatePart(ctx context.Context, arg UpdatePartParams) (Part, error)
 	UpdatePermission(ctx context.Context, arg UpdatePermissionParams) (Permission, error)
+	UpdatePlacementTestState(ctx context.Context, arg UpdatePlacementTestStateParams) error
+	UpdatePlan(ctx context.Context, arg UpdatePlanParams) (Plan, error)
 	UpdateQuestion(ctx context.Context, arg UpdateQuestionParams) (Question, error)
 	UpdateRole(ctx context.Context, arg UpdateRoleParams) (Role, error)
+	UpdateSavedFilter(ctx context.Context, arg UpdateSavedFilterParams) (SavedFilter, error)
 	UpdateSpeakingSession(ctx context.Context, arg UpdateSpeakingSessionParams) (SpeakingSession, error)
 	UpdateSpeakingTurn(ctx context.Context, arg UpdateSpeakingTurnParams) (SpeakingTurn, error)
 	UpdateStudySet(ctx context.Context, arg UpdateStudySetParams) (StudySet, error)
+	UpdateSubscriptionState(ctx context.Context, arg UpdateSubscriptionStateParams) (Subscription, error)
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
 	UpdateUserAnswer(ctx context.Context, arg UpdateUserAnswerParams) (UserAnswer, error)
 	UpdateUserAnswerByAttemptAndQuestion(ctx context.Context, arg UpdateUserAnswerByAttemptAndQuestionParams) (UserAnswer, error)
+	UpdateUserEmailEncryption(ctx context.Context, arg UpdateUserEmailEncryptionParams) error
+	UpdateUserProfileFullNameEncryption(ctx context.Context, arg UpdateUserProfileFullNameEncryptionParams) error
+	UpdateUserProfilePlacement(ctx context.Context, arg UpdateUserProfilePlacementParams) error
 	UpdateUserWordProgress(ctx context.Context, arg UpdateUserWordProgressParams) (UserWordProgress, error)
 	UpdateUserWriting(ctx context.Context, arg UpdateUserWritingParams) (UserWriting, error)
 	UpdateWord(ctx context.Context, arg UpdateWordParams) (Word, error)
 	UpdateWordMastery(ctx context.Context, arg UpdateWordMasteryParams) (VocabularyStat, error)
+	UpdateWordMeans(ctx context.Context, arg UpdateWordMeansParams) error
+	UpdateWordSense(ctx context.Context, arg UpdateWordSenseParams) (WordSense, error)
 	UpdateWritingPrompt(ctx context.Context, arg UpdateWritingPromptParams) (WritingPrompt, error)
+	UpdateWritingRubric(ctx context.Context, arg UpdateWritingRubricParams) (WritingRubric, error)
+	UpdateWritingTopic(ctx context.Context, arg UpdateWritingTopicParams) (WritingTopic, error)
+	// Records a backup, replacing what was recorded under its filename
+	UpsertBackup(ctx context.Context, arg UpsertBackupParams) (Backup, error)
+	UpsertCalendarFeed(ctx context.Context, arg UpsertCalendarFeedParams) (CalendarFeed, error)
+	UpsertContentRelations(ctx context.Context, arg UpsertContentRelationsParams) error
+	UpsertEventCursor(ctx context.Context, arg UpsertEventCursorParams) error
+	UpsertLTIContext(ctx context.Context, arg UpsertLTIContextParams) (LtiContext, error)
+	UpsertLTIContextMember(ctx context.Context, arg UpsertLTIContextMemberParams) error
+	UpsertLTIResourceLink(ctx context.Context, arg UpsertLTIResourceLinkParams) (LtiResourceLink, error)
+	UpsertLTIScoreSubmission(ctx context.Context, arg UpsertLTIScoreSubmissionParams) error
+	UpsertQuestionCalibrations(ctx context.Context, arg UpsertQuestionCalibrationsParams) error
+	UpsertQuestionTranscript(ctx context.Context, arg UpsertQuestionTranscriptParams) (QuestionTranscript, error)
+	UpsertRetentionOverride(ctx context.Context, arg UpsertRetentionOverrideParams) (RetentionOverride, error)
+	UpsertScorePrediction(ctx context.Context, arg UpsertScorePredictionParams) error
+	UpsertSocialSettings(ctx context.Context, arg UpsertSocialSettingsParams) (UserSocialSetting, error)
+	// Registers a device of a user or refreshes its platform and version
+	UpsertUpgradeDevice(ctx context.Context, arg UpsertUpgradeDeviceParams) (UpgradeDevice, error)
+	UpsertUserMFASecret(ctx context.Context, arg UpsertUserMFASecretParams) (UserMfa, error)
+	UpsertUserPreferences(ctx context.Context, arg UpsertUserPreferencesParams) (UserPreference, error)
+	UpsertUserProfile(ctx context.Context, arg UpsertUserProfileParams) (UserProfile, error)
+	UpsertWordListEntry(ctx context.Context, arg UpsertWordListEntryParams) error
+	UpsertWordLookup(ctx context.Context, arg UpsertWordLookupParams) error
+	UseUserMFAStep(ctx context.Context, arg UseUserMFAStepParams) (int64, error)
+	// Counts a view of a link that is neither revoked nor expired
+	ViewPortfolioShareLink(ctx context.Context, arg ViewPortfolioShareLinkParams) (PortfolioShareLink, error)
 }
 
 var _ Querier = (*Queries)(nil)
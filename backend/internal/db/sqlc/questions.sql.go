@@ -8,6 +8,7 @@ package db
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 
 	"github.com/lib/pq"
 )
@@ -21,21 +22,27 @@ INSERT INTO questions (
     possible_answers,
     true_answer,
     explanation,
-    keywords
+    keywords,
+    difficulty,
+    options,
+    answer_key
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8
-) RETURNING question_id, content_id, title, media_url, image_url, possible_answers, true_answer, explanation, keywords
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+) RETURNING question_id, content_id, title, media_url, image_url, possible_answers, true_answer, explanation, keywords, difficulty, options, answer_key
 `
 
 type CreateQuestionParams struct {
-	ContentID       int32          `json:"content_id"`
-	Title           string         `json:"title"`
-	MediaUrl        sql.NullString `json:"media_url"`
-	ImageUrl        sql.NullString `json:"image_url"`
-	PossibleAnswers []string       `json:"possible_answers"`
-	TrueAnswer      string         `json:"true_answer"`
-	Explanation     string         `json:"explanation"`
-	Keywords        sql.NullString `json:"keywords"`
+	ContentID       int32           `json:"content_id"`
+	Title           string          `json:"title"`
+	MediaUrl        sql.NullString  `json:"media_url"`
+	ImageUrl        sql.NullString  `json:"image_url"`
+	PossibleAnswers []string        `json:"possible_answers"`
+	TrueAnswer      string          `json:"true_answer"`
+	Explanation     string          `json:"explanation"`
+	Keywords        sql.NullString  `json:"keywords"`
+	Difficulty      sql.NullInt16   `json:"difficulty"`
+	Options         json.RawMessage `json:"options"`
+	AnswerKey       string          `json:"answer_key"`
 }
 
 func (q *Queries) CreateQuestion(ctx context.Context, arg CreateQuestionParams) (Question, error) {
@@ -48,6 +55,9 @@ func (q *Queries) CreateQuestion(ctx context.Context, arg CreateQuestionParams)
 		arg.TrueAnswer,
 		arg.Explanation,
 		arg.Keywords,
+		arg.Difficulty,
+		arg.Options,
+		arg.AnswerKey,
 	)
 	var i Question
 	err := row.Scan(
@@ -60,6 +70,9 @@ func (q *Queries) CreateQuestion(ctx context.Context, arg CreateQuestionParams)
 		&i.TrueAnswer,
 		&i.Explanation,
 		&i.Keywords,
+		&i.Difficulty,
+		&i.Options,
+		&i.AnswerKey,
 	)
 	return i, err
 }
@@ -75,7 +88,7 @@ func (q *Queries) DeleteQuestion(ctx context.Context, questionID int32) error {
 }
 
 const getQuestion = `-- name: GetQuestion :one
-SELECT question_id, content_id, title, media_url, image_url, possible_answers, true_answer, explanation, keywords FROM questions
+SELECT question_id, content_id, title, media_url, image_url, possible_answers, true_answer, explanation, keywords, difficulty, options, answer_key FROM questions
 WHERE question_id = $1 LIMIT 1
 `
 
@@ -92,12 +105,28 @@ func (q *Queries) GetQuestion(ctx context.Context, questionID int32) (Question,
 		&i.TrueAnswer,
 		&i.Explanation,
 		&i.Keywords,
+		&i.Difficulty,
+		&i.Options,
+		&i.AnswerKey,
 	)
 	return i, err
 }
 
+const getQuestionPartID = `-- name: GetQuestionPartID :one
+SELECT c.part_id FROM questions q
+JOIN contents c ON c.content_id = q.content_id
+WHERE q.question_id = $1
+`
+
+func (q *Queries) GetQuestionPartID(ctx context.Context, questionID int32) (int32, error) {
+	row := q.db.QueryRowContext(ctx, getQuestionPartID, questionID)
+	var part_id int32
+	err := row.Scan(&part_id)
+	return part_id, err
+}
+
 const listQuestionsByContent = `-- name: ListQuestionsByContent :many
-SELECT question_id, content_id, title, media_url, image_url, possible_answers, true_answer, explanation, keywords FROM questions
+SELECT question_id, content_id, title, media_url, image_url, possible_answers, true_answer, explanation, keywords, difficulty, options, answer_key FROM questions
 WHERE content_id = $1
 ORDER BY question_id
 `
@@ -121,6 +150,9 @@ func (q *Queries) ListQuestionsByContent(ctx context.Context, contentID int32) (
 			&i.TrueAnswer,
 			&i.Explanation,
 			&i.Keywords,
+			&i.Difficulty,
+			&i.Options,
+			&i.AnswerKey,
 		); err != nil {
 			return nil, err
 		}
@@ -145,21 +177,27 @@ SET
     possible_answers = $6,
     true_answer = $7,
     explanation = $8,
-    keywords = $9
+    keywords = $9,
+    difficulty = $10,
+    options = $11,
+    answer_key = $12
 WHERE question_id = $1
-RETURNING question_id, content_id, title, media_url, image_url, possible_answers, true_answer, explanation, keywords
+RETURNING question_id, content_id, title, media_url, image_url, possible_answers, true_answer, explanation, keywords, difficulty, options, answer_key
 `
 
 type UpdateQuestionParams struct {
-	QuestionID      int32          `json:"question_id"`
-	ContentID       int32          `json:"content_id"`
-	Title           string         `json:"title"`
-	MediaUrl        sql.NullString `json:"media_url"`
-	ImageUrl        sql.NullString `json:"image_url"`
-	PossibleAnswers []string       `json:"possible_answers"`
-	TrueAnswer      string         `json:"true_answer"`
-	Explanation     string         `json:"explanation"`
-	Keywords        sql.NullString `json:"keywords"`
+	QuestionID      int32           `json:"question_id"`
+	ContentID       int32           `json:"content_id"`
+	Title           string          `json:"title"`
+	MediaUrl        sql.NullString  `json:"media_url"`
+	ImageUrl        sql.NullString  `json:"image_url"`
+	PossibleAnswers []string        `json:"possible_answers"`
+	TrueAnswer      string          `json:"true_answer"`
+	Explanation     string          `json:"explanation"`
+	Keywords        sql.NullString  `json:"keywords"`
+	Difficulty      sql.NullInt16   `json:"difficulty"`
+	Options         json.RawMessage `json:"options"`
+	AnswerKey       string          `json:"answer_key"`
 }
 
 func (q *Queries) UpdateQuestion(ctx context.Context, arg UpdateQuestionParams) (Question, error) {
@@ -173,6 +211,9 @@ func (q *Queries) UpdateQuestion(ctx context.Context, arg UpdateQuestionParams)
 		arg.TrueAnswer,
 		arg.Explanation,
 		arg.Keywords,
+		arg.Difficulty,
+		arg.Options,
+		arg.AnswerKey,
 	)
 	var i Question
 	err := row.Scan(
@@ -185,6 +226,9 @@ func (q *Queries) UpdateQuestion(ctx context.Context, arg UpdateQuestionParams)
 		&i.TrueAnswer,
 		&i.Explanation,
 		&i.Keywords,
+		&i.Difficulty,
+		&i.Options,
+		&i.AnswerKey,
 	)
 	return i, err
 }
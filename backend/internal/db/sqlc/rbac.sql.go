@@ -546,7 +546,7 @@ func (q *Queries) ListRoles(ctx context.Context) ([]Role, error) {
 }
 
 const listUsersWithRole = `-- name: ListUsersWithRole :many
-SELECT u.id, u.username, u.email, u.password_hash, u.created_at, u.updated_at FROM users u
+SELECT u.id, u.username, u.email, u.password_hash, u.created_at, u.updated_at, u.email_encrypted, u.email_bidx FROM users u
 JOIN user_roles ur ON u.id = ur.user_id
 WHERE ur.role_id = $1
 AND (ur.expires_at IS NULL OR ur.expires_at > NOW())
@@ -569,6 +569,8 @@ func (q *Queries) ListUsersWithRole(ctx context.Context, roleID int32) ([]User,
 			&i.PasswordHash,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.EmailEncrypted,
+			&i.EmailBidx,
 		); err != nil {
 			return nil, err
 		}
@@ -8,6 +8,7 @@ package db
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/lib/pq"
@@ -43,10 +44,11 @@ INSERT INTO user_answers (
     question_id,
     selected_answer,
     is_correct,
-    answer_time
+    answer_time,
+    selected_key
 ) VALUES (
-    $1, $2, $3, $4, $5
-) RETURNING user_answer_id, attempt_id, question_id, selected_answer, is_correct, answer_time, created_at
+    $1, $2, $3, $4, $5, $6
+) RETURNING user_answer_id, attempt_id, question_id, selected_answer, is_correct, answer_time, created_at, selected_key
 `
 
 type CreateUserAnswerParams struct {
@@ -55,6 +57,7 @@ type CreateUserAnswerParams struct {
 	SelectedAnswer string       `json:"selected_answer"`
 	IsCorrect      bool         `json:"is_correct"`
 	AnswerTime     sql.NullTime `json:"answer_time"`
+	SelectedKey    string       `json:"selected_key"`
 }
 
 func (q *Queries) CreateUserAnswer(ctx context.Context, arg CreateUserAnswerParams) (UserAnswer, error) {
@@ -64,6 +67,7 @@ func (q *Queries) CreateUserAnswer(ctx context.Context, arg CreateUserAnswerPara
 		arg.SelectedAnswer,
 		arg.IsCorrect,
 		arg.AnswerTime,
+		arg.SelectedKey,
 	)
 	var i UserAnswer
 	err := row.Scan(
@@ -74,6 +78,7 @@ func (q *Queries) CreateUserAnswer(ctx context.Context, arg CreateUserAnswerPara
 		&i.IsCorrect,
 		&i.AnswerTime,
 		&i.CreatedAt,
+		&i.SelectedKey,
 	)
 	return i, err
 }
@@ -102,21 +107,28 @@ const getAttemptScore = `-- name: GetAttemptScore :one
 SELECT 
     COUNT(*) as total_questions,
     COUNT(CASE WHEN is_correct = true THEN 1 END) as correct_answers,
-    ROUND(
-        (COUNT(CASE WHEN is_correct = true THEN 1 END)::numeric / COUNT(*)::numeric) * 990, 2
-    ) as calculated_score
+    COALESCE(ROUND(
+        (COUNT(CASE WHEN is_correct = true THEN 1 END)::numeric / NULLIF(COUNT(*), 0)::numeric) * $1::int, 2
+    ), 0)::text as calculated_score
 FROM user_answers
-WHERE attempt_id = $1
+WHERE attempt_id = $2
 `
 
+type GetAttemptScoreParams struct {
+	MaxScore  int32 `json:"max_score"`
+	AttemptID int32 `json:"attempt_id"`
+}
+
 type GetAttemptScoreRow struct {
 	TotalQuestions  int64  `json:"total_questions"`
 	CorrectAnswers  int64  `json:"correct_answers"`
 	CalculatedScore string `json:"calculated_score"`
 }
 
-func (q *Queries) GetAttemptScore(ctx context.Context, attemptID int32) (GetAttemptScoreRow, error) {
-	row := q.db.QueryRowContext(ctx, getAttemptScore, attemptID)
+// Scales the share of correct answers to max_score: 990 for a full test,
+// 100 for a part practice. An attempt without answers scores 0.
+func (q *Queries) GetAttemptScore(ctx context.Context, arg GetAttemptScoreParams) (GetAttemptScoreRow, error) {
+	row := q.db.QueryRowContext(ctx, getAttemptScore, arg.MaxScore, arg.AttemptID)
 	var i GetAttemptScoreRow
 	err := row.Scan(&i.TotalQuestions, &i.CorrectAnswers, &i.CalculatedScore)
 	return i, err
@@ -180,7 +192,7 @@ func (q *Queries) GetQuestionAnalytics(ctx context.Context, examID int32) ([]Get
 }
 
 const getUserAnswer = `-- name: GetUserAnswer :one
-SELECT user_answer_id, attempt_id, question_id, selected_answer, is_correct, answer_time, created_at FROM user_answers
+SELECT user_answer_id, attempt_id, question_id, selected_answer, is_correct, answer_time, created_at, selected_key FROM user_answers
 WHERE user_answer_id = $1 LIMIT 1
 `
 
@@ -195,12 +207,13 @@ func (q *Queries) GetUserAnswer(ctx context.Context, userAnswerID int32) (UserAn
 		&i.IsCorrect,
 		&i.AnswerTime,
 		&i.CreatedAt,
+		&i.SelectedKey,
 	)
 	return i, err
 }
 
 const getUserAnswerByAttemptAndQuestion = `-- name: GetUserAnswerByAttemptAndQuestion :one
-SELECT user_answer_id, attempt_id, question_id, selected_answer, is_correct, answer_time, created_at FROM user_answers
+SELECT user_answer_id, attempt_id, question_id, selected_answer, is_correct, answer_time, created_at, selected_key FROM user_answers
 WHERE attempt_id = $1 AND question_id = $2 LIMIT 1
 `
 
@@ -220,15 +233,17 @@ func (q *Queries) GetUserAnswerByAttemptAndQuestion(ctx context.Context, arg Get
 		&i.IsCorrect,
 		&i.AnswerTime,
 		&i.CreatedAt,
+		&i.SelectedKey,
 	)
 	return i, err
 }
 
 const getUserAnswerHistory = `-- name: GetUserAnswerHistory :many
 SELECT 
-    ua.user_answer_id, ua.attempt_id, ua.question_id, ua.selected_answer, ua.is_correct, ua.answer_time, ua.created_at,
+    ua.user_answer_id, ua.attempt_id, ua.question_id, ua.selected_answer, ua.is_correct, ua.answer_time, ua.created_at, ua.selected_key,
     q.title as question_title,
     q.true_answer,
+    q.answer_key,
     ea.exam_id,
     e.title as exam_title
 FROM user_answers ua
@@ -256,8 +271,10 @@ type GetUserAnswerHistoryRow struct {
 	IsCorrect      bool         `json:"is_correct"`
 	AnswerTime     sql.NullTime `json:"answer_time"`
 	CreatedAt      time.Time    `json:"created_at"`
+	SelectedKey    string       `json:"selected_key"`
 	QuestionTitle  string       `json:"question_title"`
 	TrueAnswer     string       `json:"true_answer"`
+	AnswerKey      string       `json:"answer_key"`
 	ExamID         int32        `json:"exam_id"`
 	ExamTitle      string       `json:"exam_title"`
 }
@@ -279,8 +296,10 @@ func (q *Queries) GetUserAnswerHistory(ctx context.Context, arg GetUserAnswerHis
 			&i.IsCorrect,
 			&i.AnswerTime,
 			&i.CreatedAt,
+			&i.SelectedKey,
 			&i.QuestionTitle,
 			&i.TrueAnswer,
+			&i.AnswerKey,
 			&i.ExamID,
 			&i.ExamTitle,
 		); err != nil {
@@ -298,7 +317,7 @@ func (q *Queries) GetUserAnswerHistory(ctx context.Context, arg GetUserAnswerHis
 }
 
 const listUserAnswersByAttempt = `-- name: ListUserAnswersByAttempt :many
-SELECT user_answer_id, attempt_id, question_id, selected_answer, is_correct, answer_time, created_at FROM user_answers
+SELECT user_answer_id, attempt_id, question_id, selected_answer, is_correct, answer_time, created_at, selected_key FROM user_answers
 WHERE attempt_id = $1
 ORDER BY answer_time
 `
@@ -320,6 +339,7 @@ func (q *Queries) ListUserAnswersByAttempt(ctx context.Context, attemptID int32)
 			&i.IsCorrect,
 			&i.AnswerTime,
 			&i.CreatedAt,
+			&i.SelectedKey,
 		); err != nil {
 			return nil, err
 		}
@@ -336,11 +356,13 @@ func (q *Queries) ListUserAnswersByAttempt(ctx context.Context, attemptID int32)
 
 const listUserAnswersByAttemptWithQuestions = `-- name: ListUserAnswersByAttemptWithQuestions :many
 SELECT 
-    ua.user_answer_id, ua.attempt_id, ua.question_id, ua.selected_answer, ua.is_correct, ua.answer_time, ua.created_at,
+    ua.user_answer_id, ua.attempt_id, ua.question_id, ua.selected_answer, ua.is_correct, ua.answer_time, ua.created_at, ua.selected_key,
     q.title as question_title,
     q.true_answer,
     q.explanation,
-    q.possible_answers
+    q.possible_answers,
+    q.answer_key,
+    q.options
 FROM user_answers ua
 JOIN questions q ON ua.question_id = q.question_id
 WHERE ua.attempt_id = $1
@@ -348,17 +370,20 @@ ORDER BY ua.answer_time
 `
 
 type ListUserAnswersByAttemptWithQuestionsRow struct {
-	UserAnswerID    int32        `json:"user_answer_id"`
-	AttemptID       int32        `json:"attempt_id"`
-	QuestionID      int32        `json:"question_id"`
-	SelectedAnswer  string       `json:"selected_answer"`
-	IsCorrect       bool         `json:"is_correct"`
-	AnswerTime      sql.NullTime `json:"answer_time"`
-	CreatedAt       time.Time    `json:"created_at"`
-	QuestionTitle   string       `json:"question_title"`
-	TrueAnswer      string       `json:"true_answer"`
-	Explanation     string       `json:"explanation"`
-	PossibleAnswers []string     `json:"possible_answers"`
+	UserAnswerID    int32           `json:"user_answer_id"`
+	AttemptID       int32           `json:"attempt_id"`
+	QuestionID      int32           `json:"question_id"`
+	SelectedAnswer  string          `json:"selected_answer"`
+	IsCorrect       bool            `json:"is_correct"`
+	AnswerTime      sql.NullTime    `json:"answer_time"`
+	CreatedAt       time.Time       `json:"created_at"`
+	SelectedKey     string          `json:"selected_key"`
+	QuestionTitle   string          `json:"question_title"`
+	TrueAnswer      string          `json:"true_answer"`
+	Explanation     string          `json:"explanation"`
+	PossibleAnswers []string        `json:"possible_answers"`
+	AnswerKey       string          `json:"answer_key"`
+	Options         json.RawMessage `json:"options"`
 }
 
 func (q *Queries) ListUserAnswersByAttemptWithQuestions(ctx context.Context, attemptID int32) ([]ListUserAnswersByAttemptWithQuestionsRow, error) {
@@ -378,10 +403,13 @@ func (q *Queries) ListUserAnswersByAttemptWithQuestions(ctx context.Context, att
 			&i.IsCorrect,
 			&i.AnswerTime,
 			&i.CreatedAt,
+			&i.SelectedKey,
 			&i.QuestionTitle,
 			&i.TrueAnswer,
 			&i.Explanation,
 			pq.Array(&i.PossibleAnswers),
+			&i.AnswerKey,
+			&i.Options,
 		); err != nil {
 			return nil, err
 		}
@@ -396,24 +424,73 @@ func (q *Queries) ListUserAnswersByAttemptWithQuestions(ctx context.Context, att
 	return items, nil
 }
 
+const regradeUserAnswers = `-- name: RegradeUserAnswers :many
+UPDATE user_answers
+SET is_correct = (selected_key = $2::text)
+WHERE question_id = $1
+  AND is_correct <> (selected_key = $2::text)
+RETURNING user_answer_id, attempt_id, is_correct
+`
+
+type RegradeUserAnswersParams struct {
+	QuestionID int32  `json:"question_id"`
+	AnswerKey  string `json:"answer_key"`
+}
+
+type RegradeUserAnswersRow struct {
+	UserAnswerID int32 `json:"user_answer_id"`
+	AttemptID    int32 `json:"attempt_id"`
+	IsCorrect    bool  `json:"is_correct"`
+}
+
+func (q *Queries) RegradeUserAnswers(ctx context.Context, arg RegradeUserAnswersParams) ([]RegradeUserAnswersRow, error) {
+	rows, err := q.db.QueryContext(ctx, regradeUserAnswers, arg.QuestionID, arg.AnswerKey)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []RegradeUserAnswersRow{}
+	for rows.Next() {
+		var i RegradeUserAnswersRow
+		if err := rows.Scan(&i.UserAnswerID, &i.AttemptID, &i.IsCorrect); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateUserAnswer = `-- name: UpdateUserAnswer :one
 UPDATE user_answers
 SET 
     selected_answer = $2,
     is_correct = $3,
+    selected_key = $4,
     answer_time = NOW()
 WHERE user_answer_id = $1
-RETURNING user_answer_id, attempt_id, question_id, selected_answer, is_correct, answer_time, created_at
+RETURNING user_answer_id, attempt_id, question_id, selected_answer, is_correct, answer_time, created_at, selected_key
 `
 
 type UpdateUserAnswerParams struct {
 	UserAnswerID   int32  `json:"user_answer_id"`
 	SelectedAnswer string `json:"selected_answer"`
 	IsCorrect      bool   `json:"is_correct"`
+	SelectedKey    string `json:"selected_key"`
 }
 
 func (q *Queries) UpdateUserAnswer(ctx context.Context, arg UpdateUserAnswerParams) (UserAnswer, error) {
-	row := q.db.QueryRowContext(ctx, updateUserAnswer, arg.UserAnswerID, arg.SelectedAnswer, arg.IsCorrect)
+	row := q.db.QueryRowContext(ctx, updateUserAnswer,
+		arg.UserAnswerID,
+		arg.SelectedAnswer,
+		arg.IsCorrect,
+		arg.SelectedKey,
+	)
 	var i UserAnswer
 	err := row.Scan(
 		&i.UserAnswerID,
@@ -423,6 +500,7 @@ func (q *Queries) UpdateUserAnswer(ctx context.Context, arg UpdateUserAnswerPara
 		&i.IsCorrect,
 		&i.AnswerTime,
 		&i.CreatedAt,
+		&i.SelectedKey,
 	)
 	return i, err
 }
@@ -432,9 +510,10 @@ UPDATE user_answers
 SET 
     selected_answer = $3,
     is_correct = $4,
+    selected_key = $5,
     answer_time = NOW()
 WHERE attempt_id = $1 AND question_id = $2
-RETURNING user_answer_id, attempt_id, question_id, selected_answer, is_correct, answer_time, created_at
+RETURNING user_answer_id, attempt_id, question_id, selected_answer, is_correct, answer_time, created_at, selected_key
 `
 
 type UpdateUserAnswerByAttemptAndQuestionParams struct {
@@ -442,6 +521,7 @@ type UpdateUserAnswerByAttemptAndQuestionParams struct {
 	QuestionID     int32  `json:"question_id"`
 	SelectedAnswer string `json:"selected_answer"`
 	IsCorrect      bool   `json:"is_correct"`
+	SelectedKey    string `json:"selected_key"`
 }
 
 func (q *Queries) UpdateUserAnswerByAttemptAndQuestion(ctx context.Context, arg UpdateUserAnswerByAttemptAndQuestionParams) (UserAnswer, error) {
@@ -450,6 +530,7 @@ func (q *Queries) UpdateUserAnswerByAttemptAndQuestion(ctx context.Context, arg
 		arg.QuestionID,
 		arg.SelectedAnswer,
 		arg.IsCorrect,
+		arg.SelectedKey,
 	)
 	var i UserAnswer
 	err := row.Scan(
@@ -460,6 +541,7 @@ func (q *Queries) UpdateUserAnswerByAttemptAndQuestion(ctx context.Context, arg
 		&i.IsCorrect,
 		&i.AnswerTime,
 		&i.CreatedAt,
+		&i.SelectedKey,
 	)
 	return i, err
 }
@@ -310,6 +310,34 @@ func (q *Queries) ListUserWordProgressByNextReview(ctx context.Context, arg List
 	return items, nil
 }
 
+const seedUserWordProgress = `-- name: SeedUserWordProgress :execrows
+INSERT INTO user_word_progress (
+  user_id,
+  word_id,
+  next_review_at
+)
+SELECT $1, id, NOW()
+FROM words
+WHERE level = $2
+ORDER BY freq DESC, id
+LIMIT $3
+ON CONFLICT (user_id, word_id) DO NOTHING
+`
+
+type SeedUserWordProgressParams struct {
+	UserID   int32 `json:"user_id"`
+	Level    int32 `json:"level"`
+	MaxWords int32 `json:"max_words"`
+}
+
+func (q *Queries) SeedUserWordProgress(ctx context.Context, arg SeedUserWordProgressParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, seedUserWordProgress, arg.UserID, arg.Level, arg.MaxWords)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const updateUserWordProgress = `-- name: UpdateUserWordProgress :one
 UPDATE user_word_progress
 SET
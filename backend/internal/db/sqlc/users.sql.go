@@ -7,27 +7,40 @@ package db
 
 import (
 	"context"
+	"database/sql"
+
+	"github.com/sqlc-dev/pqtype"
 )
 
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (
   username,
   email,
+  email_encrypted,
+  email_bidx,
   password_hash
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $3, $4, $5
 )
-RETURNING id, username, email, password_hash, created_at, updated_at
+RETURNING id, username, email, password_hash, created_at, updated_at, email_encrypted, email_bidx
 `
 
 type CreateUserParams struct {
-	Username     string `json:"username"`
-	Email        string `json:"email"`
-	PasswordHash string `json:"password_hash"`
+	Username       string         `json:"username"`
+	Email          sql.NullString `json:"email"`
+	EmailEncrypted sql.NullString `json:"email_encrypted"`
+	EmailBidx      sql.NullString `json:"email_bidx"`
+	PasswordHash   string         `json:"password_hash"`
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, createUser, arg.Username, arg.Email, arg.PasswordHash)
+	row := q.db.QueryRowContext(ctx, createUser,
+		arg.Username,
+		arg.Email,
+		arg.EmailEncrypted,
+		arg.EmailBidx,
+		arg.PasswordHash,
+	)
 	var i User
 	err := row.Scan(
 		&i.ID,
@@ -36,6 +49,8 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.PasswordHash,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.EmailEncrypted,
+		&i.EmailBidx,
 	)
 	return i, err
 }
@@ -51,7 +66,7 @@ func (q *Queries) DeleteUser(ctx context.Context, id int32) error {
 }
 
 const getUser = `-- name: GetUser :one
-SELECT id, username, email, password_hash, created_at, updated_at FROM users
+SELECT id, username, email, password_hash, created_at, updated_at, email_encrypted, email_bidx FROM users
 WHERE id = $1 LIMIT 1
 `
 
@@ -65,16 +80,18 @@ func (q *Queries) GetUser(ctx context.Context, id int32) (User, error) {
 		&i.PasswordHash,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.EmailEncrypted,
+		&i.EmailBidx,
 	)
 	return i, err
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, username, email, password_hash, created_at, updated_at FROM users
+SELECT id, username, email, password_hash, created_at, updated_at, email_encrypted, email_bidx FROM users
 WHERE email = $1 LIMIT 1
 `
 
-func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
+func (q *Queries) GetUserByEmail(ctx context.Context, email sql.NullString) (User, error) {
 	row := q.db.QueryRowContext(ctx, getUserByEmail, email)
 	var i User
 	err := row.Scan(
@@ -84,12 +101,128 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.PasswordHash,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.EmailEncrypted,
+		&i.EmailBidx,
+	)
+	return i, err
+}
+
+const getUserByEmailBidx = `-- name: GetUserByEmailBidx :one
+SELECT id, username, email, password_hash, created_at, updated_at, email_encrypted, email_bidx FROM users
+WHERE email_bidx = $1 LIMIT 1
+`
+
+func (q *Queries) GetUserByEmailBidx(ctx context.Context, emailBidx sql.NullString) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByEmailBidx, emailBidx)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Email,
+		&i.PasswordHash,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.EmailEncrypted,
+		&i.EmailBidx,
 	)
 	return i, err
 }
 
+const getUserPreferredLanguage = `-- name: GetUserPreferredLanguage :one
+SELECT preferred_language FROM user_profiles
+WHERE user_id = $1 LIMIT 1
+`
+
+func (q *Queries) GetUserPreferredLanguage(ctx context.Context, userID sql.NullInt32) (sql.NullString, error) {
+	row := q.db.QueryRowContext(ctx, getUserPreferredLanguage, userID)
+	var preferred_language sql.NullString
+	err := row.Scan(&preferred_language)
+	return preferred_language, err
+}
+
+const getUserProfileByUserID = `-- name: GetUserProfileByUserID :one
+SELECT id, user_id, full_name, bio, avatar_url, created_at, updated_at, full_name_encrypted, target_score, exam_date, daily_minutes, native_language, placement_score, placement_levels, placement_completed_at, preferred_language FROM user_profiles
+WHERE user_id = $1 LIMIT 1
+`
+
+func (q *Queries) GetUserProfileByUserID(ctx context.Context, userID sql.NullInt32) (UserProfile, error) {
+	row := q.db.QueryRowContext(ctx, getUserProfileByUserID, userID)
+	var i UserProfile
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.FullName,
+		&i.Bio,
+		&i.AvatarUrl,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.FullNameEncrypted,
+		&i.TargetScore,
+		&i.ExamDate,
+		&i.DailyMinutes,
+		&i.NativeLanguage,
+		&i.PlacementScore,
+		&i.PlacementLevels,
+		&i.PlacementCompletedAt,
+		&i.PreferredLanguage,
+	)
+	return i, err
+}
+
+const listUserProfilesAfter = `-- name: ListUserProfilesAfter :many
+SELECT id, user_id, full_name, bio, avatar_url, created_at, updated_at, full_name_encrypted, target_score, exam_date, daily_minutes, native_language, placement_score, placement_levels, placement_completed_at, preferred_language FROM user_profiles
+WHERE id > $1
+ORDER BY id
+LIMIT $2
+`
+
+type ListUserProfilesAfterParams struct {
+	ID    int32 `json:"id"`
+	Limit int32 `json:"limit"`
+}
+
+func (q *Queries) ListUserProfilesAfter(ctx context.Context, arg ListUserProfilesAfterParams) ([]UserProfile, error) {
+	rows, err := q.db.QueryContext(ctx, listUserProfilesAfter, arg.ID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []UserProfile
+	for rows.Next() {
+		var i UserProfile
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.FullName,
+			&i.Bio,
+			&i.AvatarUrl,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.FullNameEncrypted,
+			&i.TargetScore,
+			&i.ExamDate,
+			&i.DailyMinutes,
+			&i.NativeLanguage,
+			&i.PlacementScore,
+			&i.PlacementLevels,
+			&i.PlacementCompletedAt,
+			&i.PreferredLanguage,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listUsers = `-- name: ListUsers :many
-SELECT id, username, email, password_hash, created_at, updated_at FROM users
+SELECT id, username, email, password_hash, created_at, updated_at, email_encrypted, email_bidx FROM users
 ORDER BY id
 LIMIT $1
 OFFSET $2
@@ -116,6 +249,8 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, e
 			&i.PasswordHash,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.EmailEncrypted,
+			&i.EmailBidx,
 		); err != nil {
 			return nil, err
 		}
@@ -130,22 +265,112 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, e
 	return items, nil
 }
 
+const listUsersAfter = `-- name: ListUsersAfter :many
+SELECT id, username, email, password_hash, created_at, updated_at, email_encrypted, email_bidx FROM users
+WHERE id > $1
+ORDER BY id
+LIMIT $2
+`
+
+type ListUsersAfterParams struct {
+	ID    int32 `json:"id"`
+	Limit int32 `json:"limit"`
+}
+
+func (q *Queries) ListUsersAfter(ctx context.Context, arg ListUsersAfterParams) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, listUsersAfter, arg.ID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.Email,
+			&i.PasswordHash,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.EmailEncrypted,
+			&i.EmailBidx,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setUserPreferredLanguage = `-- name: SetUserPreferredLanguage :one
+INSERT INTO user_profiles (
+  user_id,
+  preferred_language
+) VALUES (
+  $1, $2
+)
+ON CONFLICT (user_id) DO UPDATE SET
+  preferred_language = EXCLUDED.preferred_language,
+  updated_at = NOW()
+RETURNING id, user_id, full_name, bio, avatar_url, created_at, updated_at, full_name_encrypted, target_score, exam_date, daily_minutes, native_language, placement_score, placement_levels, placement_completed_at, preferred_language
+`
+
+type SetUserPreferredLanguageParams struct {
+	UserID            sql.NullInt32  `json:"user_id"`
+	PreferredLanguage sql.NullString `json:"preferred_language"`
+}
+
+func (q *Queries) SetUserPreferredLanguage(ctx context.Context, arg SetUserPreferredLanguageParams) (UserProfile, error) {
+	row := q.db.QueryRowContext(ctx, setUserPreferredLanguage, arg.UserID, arg.PreferredLanguage)
+	var i UserProfile
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.FullName,
+		&i.Bio,
+		&i.AvatarUrl,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.FullNameEncrypted,
+		&i.TargetScore,
+		&i.ExamDate,
+		&i.DailyMinutes,
+		&i.NativeLanguage,
+		&i.PlacementScore,
+		&i.PlacementLevels,
+		&i.PlacementCompletedAt,
+		&i.PreferredLanguage,
+	)
+	return i, err
+}
+
 const updateUser = `-- name: UpdateUser :one
 UPDATE users
 SET 
   username = $2,
   email = $3,
-  password_hash = $4,
+  email_encrypted = $4,
+  email_bidx = $5,
+  password_hash = $6,
   updated_at = NOW()
 WHERE id = $1
-RETURNING id, username, email, password_hash, created_at, updated_at
+RETURNING id, username, email, password_hash, created_at, updated_at, email_encrypted, email_bidx
 `
 
 type UpdateUserParams struct {
-	ID           int32  `json:"id"`
-	Username     string `json:"username"`
-	Email        string `json:"email"`
-	PasswordHash string `json:"password_hash"`
+	ID             int32          `json:"id"`
+	Username       string         `json:"username"`
+	Email          sql.NullString `json:"email"`
+	EmailEncrypted sql.NullString `json:"email_encrypted"`
+	EmailBidx      sql.NullString `json:"email_bidx"`
+	PasswordHash   string         `json:"password_hash"`
 }
 
 func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error) {
@@ -153,6 +378,8 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		arg.ID,
 		arg.Username,
 		arg.Email,
+		arg.EmailEncrypted,
+		arg.EmailBidx,
 		arg.PasswordHash,
 	)
 	var i User
@@ -163,6 +390,153 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.PasswordHash,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.EmailEncrypted,
+		&i.EmailBidx,
+	)
+	return i, err
+}
+
+const updateUserEmailEncryption = `-- name: UpdateUserEmailEncryption :exec
+UPDATE users
+SET
+  email = $2,
+  email_encrypted = $3,
+  email_bidx = $4
+WHERE id = $1
+`
+
+type UpdateUserEmailEncryptionParams struct {
+	ID             int32          `json:"id"`
+	Email          sql.NullString `json:"email"`
+	EmailEncrypted sql.NullString `json:"email_encrypted"`
+	EmailBidx      sql.NullString `json:"email_bidx"`
+}
+
+func (q *Queries) UpdateUserEmailEncryption(ctx context.Context, arg UpdateUserEmailEncryptionParams) error {
+	_, err := q.db.ExecContext(ctx, updateUserEmailEncryption,
+		arg.ID,
+		arg.Email,
+		arg.EmailEncrypted,
+		arg.EmailBidx,
+	)
+	return err
+}
+
+const updateUserProfilePlacement = `-- name: UpdateUserProfilePlacement :exec
+INSERT INTO user_profiles (
+  user_id,
+  placement_score,
+  placement_levels,
+  placement_completed_at
+) VALUES (
+  $1, $2, $3, NOW()
+)
+ON CONFLICT (user_id) DO UPDATE SET
+  placement_score = EXCLUDED.placement_score,
+  placement_levels = EXCLUDED.placement_levels,
+  placement_completed_at = EXCLUDED.placement_completed_at,
+  updated_at = NOW()
+`
+
+type UpdateUserProfilePlacementParams struct {
+	UserID          sql.NullInt32         `json:"user_id"`
+	PlacementScore  sql.NullInt32         `json:"placement_score"`
+	PlacementLevels pqtype.NullRawMessage `json:"placement_levels"`
+}
+
+func (q *Queries) UpdateUserProfilePlacement(ctx context.Context, arg UpdateUserProfilePlacementParams) error {
+	_, err := q.db.ExecContext(ctx, updateUserProfilePlacement, arg.UserID, arg.PlacementScore, arg.PlacementLevels)
+	return err
+}
+
+const updateUserProfileFullNameEncryption = `-- name: UpdateUserProfileFullNameEncryption :exec
+UPDATE user_profiles
+SET
+  full_name = $2,
+  full_name_encrypted = $3
+WHERE id = $1
+`
+
+type UpdateUserProfileFullNameEncryptionParams struct {
+	ID                int32          `json:"id"`
+	FullName          sql.NullString `json:"full_name"`
+	FullNameEncrypted sql.NullString `json:"full_name_encrypted"`
+}
+
+func (q *Queries) UpdateUserProfileFullNameEncryption(ctx context.Context, arg UpdateUserProfileFullNameEncryptionParams) error {
+	_, err := q.db.ExecContext(ctx, updateUserProfileFullNameEncryption, arg.ID, arg.FullName, arg.FullNameEncrypted)
+	return err
+}
+
+const upsertUserProfile = `-- name: UpsertUserProfile :one
+INSERT INTO user_profiles (
+  user_id,
+  full_name,
+  full_name_encrypted,
+  bio,
+  avatar_url,
+  target_score,
+  exam_date,
+  daily_minutes,
+  native_language
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9
+)
+ON CONFLICT (user_id) DO UPDATE SET
+  full_name = EXCLUDED.full_name,
+  full_name_encrypted = EXCLUDED.full_name_encrypted,
+  bio = EXCLUDED.bio,
+  avatar_url = EXCLUDED.avatar_url,
+  target_score = EXCLUDED.target_score,
+  exam_date = EXCLUDED.exam_date,
+  daily_minutes = EXCLUDED.daily_minutes,
+  native_language = EXCLUDED.native_language,
+  updated_at = NOW()
+RETURNING id, user_id, full_name, bio, avatar_url, created_at, updated_at, full_name_encrypted, target_score, exam_date, daily_minutes, native_language, placement_score, placement_levels, placement_completed_at, preferred_language
+`
+
+type UpsertUserProfileParams struct {
+	UserID            sql.NullInt32  `json:"user_id"`
+	FullName          sql.NullString `json:"full_name"`
+	FullNameEncrypted sql.NullString `json:"full_name_encrypted"`
+	Bio               sql.NullString `json:"bio"`
+	AvatarUrl         sql.NullString `json:"avatar_url"`
+	TargetScore       sql.NullInt32  `json:"target_score"`
+	ExamDate          sql.NullTime   `json:"exam_date"`
+	DailyMinutes      sql.NullInt32  `json:"daily_minutes"`
+	NativeLanguage    sql.NullString `json:"native_language"`
+}
+
+func (q *Queries) UpsertUserProfile(ctx context.Context, arg UpsertUserProfileParams) (UserProfile, error) {
+	row := q.db.QueryRowContext(ctx, upsertUserProfile,
+		arg.UserID,
+		arg.FullName,
+		arg.FullNameEncrypted,
+		arg.Bio,
+		arg.AvatarUrl,
+		arg.TargetScore,
+		arg.ExamDate,
+		arg.DailyMinutes,
+		arg.NativeLanguage,
+	)
+	var i UserProfile
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.FullName,
+		&i.Bio,
+		&i.AvatarUrl,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.FullNameEncrypted,
+		&i.TargetScore,
+		&i.ExamDate,
+		&i.DailyMinutes,
+		&i.NativeLanguage,
+		&i.PlacementScore,
+		&i.PlacementLevels,
+		&i.PlacementCompletedAt,
+		&i.PreferredLanguage,
 	)
 	return i, err
 }
@@ -8,6 +8,8 @@ package db
 import (
 	"context"
 	"database/sql"
+
+	"github.com/lib/pq"
 )
 
 const createOrUpdateVocabularyStats = `-- name: CreateOrUpdateVocabularyStats :one
@@ -25,9 +27,9 @@ INSERT INTO vocabulary_stats (
 )
 ON CONFLICT (user_id, word_id)
 DO UPDATE SET
-  total_attempts = vocabulary_stats.total_attempts + EXCLUDED.total_attempts,
-  correct_attempts = vocabulary_stats.correct_attempts + EXCLUDED.correct_attempts,
-  total_response_time_ms = vocabulary_stats.total_response_time_ms + EXCLUDED.total_response_time_ms,
+  total_attempts = COALESCE(vocabulary_stats.total_attempts, 0) + COALESCE(EXCLUDED.total_attempts, 0),
+  correct_attempts = COALESCE(vocabulary_stats.correct_attempts, 0) + COALESCE(EXCLUDED.correct_attempts, 0),
+  total_response_time_ms = COALESCE(vocabulary_stats.total_response_time_ms, 0) + COALESCE(EXCLUDED.total_response_time_ms, 0),
   mastery_level = EXCLUDED.mastery_level,
   last_attempt_at = EXCLUDED.last_attempt_at,
   updated_at = NOW()
@@ -187,6 +189,35 @@ func (q *Queries) GetVocabularyStats(ctx context.Context, arg GetVocabularyStats
 	return i, err
 }
 
+const getVocabularyStatsForUpdate = `-- name: GetVocabularyStatsForUpdate :one
+SELECT id, user_id, word_id, total_attempts, correct_attempts, total_response_time_ms, mastery_level, last_attempt_at, created_at, updated_at FROM vocabulary_stats
+WHERE user_id = $1 AND word_id = $2
+FOR UPDATE
+`
+
+type GetVocabularyStatsForUpdateParams struct {
+	UserID int32 `json:"user_id"`
+	WordID int32 `json:"word_id"`
+}
+
+func (q *Queries) GetVocabularyStatsForUpdate(ctx context.Context, arg GetVocabularyStatsForUpdateParams) (VocabularyStat, error) {
+	row := q.db.QueryRowContext(ctx, getVocabularyStatsForUpdate, arg.UserID, arg.WordID)
+	var i VocabularyStat
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.WordID,
+		&i.TotalAttempts,
+		&i.CorrectAttempts,
+		&i.TotalResponseTimeMs,
+		&i.MasteryLevel,
+		&i.LastAttemptAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const getWordsNeedingReview = `-- name: GetWordsNeedingReview :many
 SELECT words.id, words.word, words.pronounce, words.level, words.descript_level, words.short_mean, words.means, words.snym, words.freq, words.conjugation, vocabulary_stats.id, vocabulary_stats.user_id, vocabulary_stats.word_id, vocabulary_stats.total_attempts, vocabulary_stats.correct_attempts, vocabulary_stats.total_response_time_ms, vocabulary_stats.mastery_level, vocabulary_stats.last_attempt_at, vocabulary_stats.created_at, vocabulary_stats.updated_at
 FROM words
@@ -315,6 +346,44 @@ func (q *Queries) ListUserVocabularyStats(ctx context.Context, arg ListUserVocab
 	return items, nil
 }
 
+const listVocabularyMastery = `-- name: ListVocabularyMastery :many
+SELECT word_id, mastery_level FROM vocabulary_stats
+WHERE user_id = $1 AND word_id = ANY($2::int[])
+`
+
+type ListVocabularyMasteryParams struct {
+	UserID  int32   `json:"user_id"`
+	WordIds []int32 `json:"word_ids"`
+}
+
+type ListVocabularyMasteryRow struct {
+	WordID       int32         `json:"word_id"`
+	MasteryLevel sql.NullInt32 `json:"mastery_level"`
+}
+
+func (q *Queries) ListVocabularyMastery(ctx context.Context, arg ListVocabularyMasteryParams) ([]ListVocabularyMasteryRow, error) {
+	rows, err := q.db.QueryContext(ctx, listVocabularyMastery, arg.UserID, pq.Array(arg.WordIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListVocabularyMasteryRow
+	for rows.Next() {
+		var i ListVocabularyMasteryRow
+		if err := rows.Scan(&i.WordID, &i.MasteryLevel); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateWordMastery = `-- name: UpdateWordMastery :one
 UPDATE vocabulary_stats
 SET
@@ -25,7 +25,7 @@ INSERT INTO words (
     conjugation
 ) VALUES (
     $1, $2, $3, $4, $5, $6, $7, $8, $9
-) RETURNING id, word, pronounce, level, descript_level, short_mean, means, snym, freq, conjugation
+) RETURNING id, word, pronounce, level, descript_level, short_mean, means, snym, freq, conjugation, audio_url
 `
 
 type CreateWordParams struct {
@@ -64,6 +64,7 @@ func (q *Queries) CreateWord(ctx context.Context, arg CreateWordParams) (Word, e
 		&i.Snym,
 		&i.Freq,
 		&i.Conjugation,
+		&i.AudioUrl,
 	)
 	return i, err
 }
@@ -79,7 +80,7 @@ func (q *Queries) DeleteWord(ctx context.Context, id int32) error {
 }
 
 const getPopularWords = `-- name: GetPopularWords :many
-SELECT id, word, pronounce, level, descript_level, short_mean, means, snym, freq, conjugation FROM words
+SELECT id, word, pronounce, level, descript_level, short_mean, means, snym, freq, conjugation, audio_url FROM words
 ORDER BY freq DESC, level
 LIMIT $1
 OFFSET $2
@@ -110,6 +111,7 @@ func (q *Queries) GetPopularWords(ctx context.Context, arg GetPopularWordsParams
 			&i.Snym,
 			&i.Freq,
 			&i.Conjugation,
+			&i.AudioUrl,
 		); err != nil {
 			return nil, err
 		}
@@ -125,7 +127,7 @@ func (q *Queries) GetPopularWords(ctx context.Context, arg GetPopularWordsParams
 }
 
 const getWord = `-- name: GetWord :one
-SELECT id, word, pronounce, level, descript_level, short_mean, means, snym, freq, conjugation FROM words
+SELECT id, word, pronounce, level, descript_level, short_mean, means, snym, freq, conjugation, audio_url FROM words
 WHERE id = $1 LIMIT 1
 `
 
@@ -143,12 +145,13 @@ func (q *Queries) GetWord(ctx context.Context, id int32) (Word, error) {
 		&i.Snym,
 		&i.Freq,
 		&i.Conjugation,
+		&i.AudioUrl,
 	)
 	return i, err
 }
 
 const getWordsByLevel = `-- name: GetWordsByLevel :many
-SELECT id, word, pronounce, level, descript_level, short_mean, means, snym, freq, conjugation FROM words
+SELECT id, word, pronounce, level, descript_level, short_mean, means, snym, freq, conjugation, audio_url FROM words
 WHERE level = $1
 ORDER BY freq DESC, id
 LIMIT $2
@@ -181,6 +184,7 @@ func (q *Queries) GetWordsByLevel(ctx context.Context, arg GetWordsByLevelParams
 			&i.Snym,
 			&i.Freq,
 			&i.Conjugation,
+			&i.AudioUrl,
 		); err != nil {
 			return nil, err
 		}
@@ -196,7 +200,7 @@ func (q *Queries) GetWordsByLevel(ctx context.Context, arg GetWordsByLevelParams
 }
 
 const listWords = `-- name: ListWords :many
-SELECT id, word, pronounce, level, descript_level, short_mean, means, snym, freq, conjugation FROM words
+SELECT id, word, pronounce, level, descript_level, short_mean, means, snym, freq, conjugation, audio_url FROM words
 ORDER BY id
 LIMIT $1
 OFFSET $2
@@ -227,6 +231,7 @@ func (q *Queries) ListWords(ctx context.Context, arg ListWordsParams) ([]Word, e
 			&i.Snym,
 			&i.Freq,
 			&i.Conjugation,
+			&i.AudioUrl,
 		); err != nil {
 			return nil, err
 		}
@@ -242,7 +247,7 @@ func (q *Queries) ListWords(ctx context.Context, arg ListWordsParams) ([]Word, e
 }
 
 const searchWords = `-- name: SearchWords :many
-SELECT id, word, pronounce, level, descript_level, short_mean, means, snym, freq, conjugation FROM words
+SELECT id, word, pronounce, level, descript_level, short_mean, means, snym, freq, conjugation, audio_url FROM words
 WHERE
     word ILIKE '%' || $1 || '%' OR
     short_mean ILIKE '%' || $1 || '%' OR
@@ -288,6 +293,7 @@ func (q *Queries) SearchWords(ctx context.Context, arg SearchWordsParams) ([]Wor
 			&i.Snym,
 			&i.Freq,
 			&i.Conjugation,
+			&i.AudioUrl,
 		); err != nil {
 			return nil, err
 		}
@@ -303,7 +309,7 @@ func (q *Queries) SearchWords(ctx context.Context, arg SearchWordsParams) ([]Wor
 }
 
 const searchWordsFast = `-- name: SearchWordsFast :many
-SELECT id, word, pronounce, level, descript_level, short_mean, means, snym, freq, conjugation FROM words
+SELECT id, word, pronounce, level, descript_level, short_mean, means, snym, freq, conjugation, audio_url FROM words
 WHERE
     word % $1 OR
     short_mean % $1 OR
@@ -343,6 +349,7 @@ func (q *Queries) SearchWordsFast(ctx context.Context, arg SearchWordsFastParams
 			&i.Snym,
 			&i.Freq,
 			&i.Conjugation,
+			&i.AudioUrl,
 		); err != nil {
 			return nil, err
 		}
@@ -358,7 +365,7 @@ func (q *Queries) SearchWordsFast(ctx context.Context, arg SearchWordsFastParams
 }
 
 const searchWordsFullText = `-- name: SearchWordsFullText :many
-SELECT id, word, pronounce, level, descript_level, short_mean, means, snym, freq, conjugation, 
+SELECT id, word, pronounce, level, descript_level, short_mean, means, snym, freq, conjugation, audio_url, 
        ts_rank(to_tsvector('english', word || ' ' || short_mean || ' ' || COALESCE(means::text, '') || ' ' || COALESCE(snym::text, '')), 
                plainto_tsquery('english', $1)) as rank
 FROM words
@@ -386,6 +393,7 @@ type SearchWordsFullTextRow struct {
 	Snym          pqtype.NullRawMessage `json:"snym"`
 	Freq          float32               `json:"freq"`
 	Conjugation   pqtype.NullRawMessage `json:"conjugation"`
+	AudioUrl      sql.NullString        `json:"audio_url"`
 	Rank          float32               `json:"rank"`
 }
 
@@ -409,6 +417,7 @@ func (q *Queries) SearchWordsFullText(ctx context.Context, arg SearchWordsFullTe
 			&i.Snym,
 			&i.Freq,
 			&i.Conjugation,
+			&i.AudioUrl,
 			&i.Rank,
 		); err != nil {
 			return nil, err
@@ -437,7 +446,7 @@ SET
     freq = $9,
     conjugation = $10
 WHERE id = $1
-RETURNING id, word, pronounce, level, descript_level, short_mean, means, snym, freq, conjugation
+RETURNING id, word, pronounce, level, descript_level, short_mean, means, snym, freq, conjugation, audio_url
 `
 
 type UpdateWordParams struct {
@@ -478,6 +487,7 @@ func (q *Queries) UpdateWord(ctx context.Context, arg UpdateWordParams) (Word, e
 		&i.Snym,
 		&i.Freq,
 		&i.Conjugation,
+		&i.AudioUrl,
 	)
 	return i, err
 }
@@ -8,10 +8,62 @@ package db
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/sqlc-dev/pqtype"
 )
 
+const countUserWritingSearch = `-- name: CountUserWritingSearch :one
+SELECT COUNT(*)
+FROM user_writings w
+CROSS JOIN websearch_to_tsquery('english', $1::text) AS q(query)
+WHERE (to_tsvector('english', w.submission_text) || jsonb_to_tsvector('english', COALESCE(w.ai_feedback, '{}'::jsonb), '["string"]')) @@ q.query
+  AND w.user_id <> $2::int
+  AND EXISTS (
+    SELECT 1 FROM lti_users su
+    JOIN lti_users tu ON tu.platform_id = su.platform_id
+    WHERE su.user_id = w.user_id AND tu.user_id = $2::int
+  )
+  AND ($3::int IS NULL OR EXISTS (
+    SELECT 1 FROM lti_context_members sm
+    JOIN lti_context_members tm ON tm.context_id = sm.context_id
+    WHERE sm.context_id = $3::int AND sm.user_id = w.user_id AND sm.status = 'active'
+      AND tm.user_id = $2::int AND tm.status = 'active'
+  ))
+  AND ($4::int IS NULL OR w.user_id = $4::int)
+  AND ($5::timestamptz IS NULL OR w.submitted_at >= $5::timestamptz)
+  AND ($6::timestamptz IS NULL OR w.submitted_at < $6::timestamptz)
+  AND ($7::numeric IS NULL OR w.ai_score >= $7::numeric)
+  AND ($8::numeric IS NULL OR w.ai_score <= $8::numeric)
+`
+
+type CountUserWritingSearchParams struct {
+	Search        string         `json:"search"`
+	TeacherID     int32          `json:"teacher_id"`
+	ClassID       sql.NullInt32  `json:"class_id"`
+	UserID        sql.NullInt32  `json:"user_id"`
+	SubmittedFrom sql.NullTime   `json:"submitted_from"`
+	SubmittedTo   sql.NullTime   `json:"submitted_to"`
+	MinScore      sql.NullString `json:"min_score"`
+	MaxScore      sql.NullString `json:"max_score"`
+}
+
+func (q *Queries) CountUserWritingSearch(ctx context.Context, arg CountUserWritingSearchParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countUserWritingSearch,
+		arg.Search,
+		arg.TeacherID,
+		arg.ClassID,
+		arg.UserID,
+		arg.SubmittedFrom,
+		arg.SubmittedTo,
+		arg.MinScore,
+		arg.MaxScore,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createUserWriting = `-- name: CreateUserWriting :one
 INSERT INTO user_writings (
     user_id,
@@ -63,7 +115,7 @@ INSERT INTO writing_prompts (
     difficulty_level
 ) VALUES (
     $1, $2, $3, $4
-) RETURNING id, user_id, prompt_text, topic, difficulty_level, created_at
+) RETURNING id, user_id, prompt_text, topic, difficulty_level, created_at, status
 `
 
 type CreateWritingPromptParams struct {
@@ -88,6 +140,7 @@ func (q *Queries) CreateWritingPrompt(ctx context.Context, arg CreateWritingProm
 		&i.Topic,
 		&i.DifficultyLevel,
 		&i.CreatedAt,
+		&i.Status,
 	)
 	return i, err
 }
@@ -135,7 +188,7 @@ func (q *Queries) GetUserWriting(ctx context.Context, id int32) (UserWriting, er
 }
 
 const getWritingPrompt = `-- name: GetWritingPrompt :one
-SELECT id, user_id, prompt_text, topic, difficulty_level, created_at FROM writing_prompts
+SELECT id, user_id, prompt_text, topic, difficulty_level, created_at, status FROM writing_prompts
 WHERE id = $1 LIMIT 1
 `
 
@@ -149,6 +202,7 @@ func (q *Queries) GetWritingPrompt(ctx context.Context, id int32) (WritingPrompt
 		&i.Topic,
 		&i.DifficultyLevel,
 		&i.CreatedAt,
+		&i.Status,
 	)
 	return i, err
 }
@@ -232,7 +286,8 @@ func (q *Queries) ListUserWritingsByUserID(ctx context.Context, userID int32) ([
 }
 
 const listWritingPrompts = `-- name: ListWritingPrompts :many
-SELECT id, user_id, prompt_text, topic, difficulty_level, created_at FROM writing_prompts
+SELECT id, user_id, prompt_text, topic, difficulty_level, created_at, status FROM writing_prompts
+WHERE status = 'published'
 ORDER BY created_at DESC
 `
 
@@ -252,6 +307,118 @@ func (q *Queries) ListWritingPrompts(ctx context.Context) ([]WritingPrompt, erro
 			&i.Topic,
 			&i.DifficultyLevel,
 			&i.CreatedAt,
+			&i.Status,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchUserWritings = `-- name: SearchUserWritings :many
+-- Writing submissions of learners who share an organization with the
+-- teacher whose text or AI feedback match the search, best match first,
+-- with the matching passages. Organizations are the LTI platforms users were
+-- provisioned from and classes the LTI courses both are active members of.
+-- Null filters match every submission.
+SELECT w.id, w.user_id, u.username, w.prompt_id, w.ai_score, w.submitted_at,
+       ts_rank(to_tsvector('english', w.submission_text) || jsonb_to_tsvector('english', COALESCE(w.ai_feedback, '{}'::jsonb), '["string"]'), q.query)::float8 AS rank,
+       ts_headline('english', w.submission_text, q.query, 'StartSel=**, StopSel=**, MaxFragments=3, MaxWords=20, MinWords=5')::text AS text_headline,
+       (CASE WHEN jsonb_to_tsvector('english', COALESCE(w.ai_feedback, '{}'::jsonb), '["string"]') @@ q.query
+            THEN ts_headline('english', COALESCE((SELECT string_agg(v #>> '{}', ' ') FROM jsonb_path_query(w.ai_feedback, 'strict $.** ? (@.type() == "string")') AS v), ''), q.query, 'StartSel=**, StopSel=**, MaxFragments=3, MaxWords=20, MinWords=5')
+            ELSE '' END)::text AS feedback_headline
+FROM user_writings w
+JOIN users u ON u.id = w.user_id
+CROSS JOIN websearch_to_tsquery('english', $1::text) AS q(query)
+WHERE (to_tsvector('english', w.submission_text) || jsonb_to_tsvector('english', COALESCE(w.ai_feedback, '{}'::jsonb), '["string"]')) @@ q.query
+  AND w.user_id <> $2::int
+  AND EXISTS (
+    SELECT 1 FROM lti_users su
+    JOIN lti_users tu ON tu.platform_id = su.platform_id
+    WHERE su.user_id = w.user_id AND tu.user_id = $2::int
+  )
+  AND ($3::int IS NULL OR EXISTS (
+    SELECT 1 FROM lti_context_members sm
+    JOIN lti_context_members tm ON tm.context_id = sm.context_id
+    WHERE sm.context_id = $3::int AND sm.user_id = w.user_id AND sm.status = 'active'
+      AND tm.user_id = $2::int AND tm.status = 'active'
+  ))
+  AND ($4::int IS NULL OR w.user_id = $4::int)
+  AND ($5::timestamptz IS NULL OR w.submitted_at >= $5::timestamptz)
+  AND ($6::timestamptz IS NULL OR w.submitted_at < $6::timestamptz)
+  AND ($7::numeric IS NULL OR w.ai_score >= $7::numeric)
+  AND ($8::numeric IS NULL OR w.ai_score <= $8::numeric)
+ORDER BY rank DESC, w.submitted_at DESC, w.id DESC
+LIMIT $9::int OFFSET $10::int
+`
+
+type SearchUserWritingsParams struct {
+	Search        string         `json:"search"`
+	TeacherID     int32          `json:"teacher_id"`
+	ClassID       sql.NullInt32  `json:"class_id"`
+	UserID        sql.NullInt32  `json:"user_id"`
+	SubmittedFrom sql.NullTime   `json:"submitted_from"`
+	SubmittedTo   sql.NullTime   `json:"submitted_to"`
+	MinScore      sql.NullString `json:"min_score"`
+	MaxScore      sql.NullString `json:"max_score"`
+	Limit         int32          `json:"limit"`
+	Offset        int32          `json:"offset"`
+}
+
+type SearchUserWritingsRow struct {
+	ID               int32          `json:"id"`
+	UserID           int32          `json:"user_id"`
+	Username         string         `json:"username"`
+	PromptID         sql.NullInt32  `json:"prompt_id"`
+	AiScore          sql.NullString `json:"ai_score"`
+	SubmittedAt      time.Time      `json:"submitted_at"`
+	Rank             float64        `json:"rank"`
+	TextHeadline     string         `json:"text_headline"`
+	FeedbackHeadline string         `json:"feedback_headline"`
+}
+
+// Writing submissions of learners who share an organization with the
+// teacher whose text or AI feedback match the search, best match first,
+// with the matching passages. Organizations are the LTI platforms users were
+// provisioned from and classes the LTI courses both are active members of.
+// Null filters match every submission.
+func (q *Queries) SearchUserWritings(ctx context.Context, arg SearchUserWritingsParams) ([]SearchUserWritingsRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchUserWritings,
+		arg.Search,
+		arg.TeacherID,
+		arg.ClassID,
+		arg.UserID,
+		arg.SubmittedFrom,
+		arg.SubmittedTo,
+		arg.MinScore,
+		arg.MaxScore,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchUserWritingsRow
+	for rows.Next() {
+		var i SearchUserWritingsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Username,
+			&i.PromptID,
+			&i.AiScore,
+			&i.SubmittedAt,
+			&i.Rank,
+			&i.TextHeadline,
+			&i.FeedbackHeadline,
 		); err != nil {
 			return nil, err
 		}
@@ -316,7 +483,7 @@ SET
     topic = $3,
     difficulty_level = $4
 WHERE id = $1
-RETURNING id, user_id, prompt_text, topic, difficulty_level, created_at
+RETURNING id, user_id, prompt_text, topic, difficulty_level, created_at, status
 `
 
 type UpdateWritingPromptParams struct {
@@ -341,6 +508,7 @@ func (q *Queries) UpdateWritingPrompt(ctx context.Context, arg UpdateWritingProm
 		&i.Topic,
 		&i.DifficultyLevel,
 		&i.CreatedAt,
+		&i.Status,
 	)
 	return i, err
 }
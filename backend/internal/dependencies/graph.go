@@ -0,0 +1,377 @@
+// Package dependencies models the services the application relies on, such
+// as the database, Redis and the AI provider, as a graph. Dependencies are
+// probed in the background and the features that use them inherit their
+// health, so incident triage and degraded-mode decisions share one view.
+package dependencies
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/toeic-app/internal/logger"
+)
+
+// Status is the health of a node or edge
+type Status string
+
+const (
+	StatusHealthy  Status = "healthy"
+	StatusDegraded Status = "degraded"
+	StatusDown     Status = "down"
+	StatusUnknown  Status = "unknown" // Not probed yet
+)
+
+// Node kinds
+const (
+	KindDependency = "dependency"
+	KindFeature    = "feature"
+)
+
+// ErrUnknownNode is returned for a node that is not in the graph
+var ErrUnknownNode = errors.New("unknown dependency graph node")
+
+// Probe checks a dependency once and returns why it is unusable
+type Probe func(ctx context.Context) error
+
+// Options tune the scoring of probes
+type Options struct {
+	// Timeout bounds a single probe
+	Timeout time.Duration
+	// SlowThreshold marks a dependency degraded when its probes take longer
+	// on average
+	SlowThreshold time.Duration
+	// Window is how many recent probes a score is computed from
+	Window int
+}
+
+// Node is a dependency or a feature with its current health
+type Node struct {
+	Name   string `json:"name" example:"database"`
+	Kind   string `json:"kind" example:"dependency"`
+	Status Status `json:"status" example:"healthy"`
+	// Score is the health from 0 to 100: the share of recent probes that
+	// succeeded, halved while probes are slow. Features take the score of
+	// their weakest critical dependency.
+	Score       int        `json:"score" example:"100"`
+	LatencyMs   int64      `json:"latency_ms" example:"3"`
+	LastError   string     `json:"last_error,omitempty"`
+	LastChecked *time.Time `json:"last_checked,omitempty"`
+}
+
+// Edge is the use of a dependency by a feature
+type Edge struct {
+	From string `json:"from" example:"writing_scoring"`
+	To   string `json:"to" example:"ai_provider"`
+	// Critical edges take the feature down with the dependency; the others
+	// only degrade it
+	Critical  bool   `json:"critical" example:"true"`
+	Status    Status `json:"status" example:"healthy"`
+	Score     int    `json:"score" example:"100"`
+	LatencyMs int64  `json:"latency_ms" example:"120"`
+}
+
+// Report is a snapshot of the whole graph
+type Report struct {
+	Status Status `json:"status" example:"healthy"`
+	// Degraded lists the nodes that are not healthy
+	Degraded []string `json:"degraded"`
+	Nodes    []Node   `json:"nodes"`
+	Edges    []Edge   `json:"edges"`
+}
+
+type sample struct {
+	latency time.Duration
+	err     error
+}
+
+type dependency struct {
+	probe       Probe
+	samples     []sample // Oldest first, at most Window
+	lastChecked time.Time
+}
+
+type edge struct {
+	to       string
+	critical bool
+}
+
+// Graph holds the dependencies, the features using them and the recent
+// probe results
+type Graph struct {
+	options Options
+
+	mu           sync.RWMutex
+	dependencies map[string]*dependency
+	features     map[string][]edge
+
+	stop chan struct{}
+	done chan struct{}
+	now  func() time.Time
+}
+
+// NewGraph creates an empty graph
+func NewGraph(options Options) *Graph {
+	if options.Timeout <= 0 {
+		options.Timeout = 5 * time.Second
+	}
+	if options.SlowThreshold <= 0 {
+		options.SlowThreshold = time.Second
+	}
+	if options.Window <= 0 {
+		options.Window = 20
+	}
+	return &Graph{
+		options:      options,
+		dependencies: make(map[string]*dependency),
+		features:     make(map[string][]edge),
+		now:          time.Now,
+	}
+}
+
+// AddDependency registers a dependency checked by probe
+func (g *Graph) AddDependency(name string, probe Probe) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.dependencies[name] = &dependency{probe: probe}
+}
+
+// Depends records that a feature uses a dependency. Dependencies that are
+// not registered, such as services that are not configured, are ignored.
+func (g *Graph) Depends(feature, name string, critical bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, ok := g.features[feature]; !ok {
+		g.features[feature] = nil
+	}
+	if _, ok := g.dependencies[name]; !ok {
+		return
+	}
+	g.features[feature] = append(g.features[feature], edge{to: name, critical: critical})
+}
+
+// Check probes every dependency once, concurrently
+func (g *Graph) Check(ctx context.Context) {
+	g.mu.RLock()
+	probes := make(map[string]Probe, len(g.dependencies))
+	for name, dep := range g.dependencies {
+		probes[name] = dep.probe
+	}
+	g.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	for name, probe := range probes {
+		wg.Add(1)
+		go func(name string, probe Probe) {
+			defer wg.Done()
+			probeCtx, cancel := context.WithTimeout(ctx, g.options.Timeout)
+			start := g.now()
+			err := probe(probeCtx)
+			cancel()
+			g.record(name, sample{latency: g.now().Sub(start), err: err})
+		}(name, probe)
+	}
+	wg.Wait()
+}
+
+func (g *Graph) record(name string, s sample) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	dep, ok := g.dependencies[name]
+	if !ok {
+		return
+	}
+	before := dep.status(g.options.SlowThreshold)
+	dep.samples = append(dep.samples, s)
+	if len(dep.samples) > g.options.Window {
+		dep.samples = dep.samples[len(dep.samples)-g.options.Window:]
+	}
+	dep.lastChecked = g.now()
+
+	if after := dep.status(g.options.SlowThreshold); after != before && before != StatusUnknown {
+		logger.Warn("Dependency %s changed from %s to %s", name, before, after)
+	}
+}
+
+// Start probes the dependencies now and then every interval until Stop
+func (g *Graph) Start(interval time.Duration) {
+	g.stop = make(chan struct{})
+	g.done = make(chan struct{})
+	go func() {
+		defer close(g.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			g.Check(context.Background())
+			select {
+			case <-g.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop ends the background probes started by Start
+func (g *Graph) Stop() {
+	if g.stop == nil {
+		return
+	}
+	close(g.stop)
+	<-g.done
+	g.stop = nil
+}
+
+// Status returns the health of a dependency or feature
+func (g *Graph) Status(name string) (Status, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if dep, ok := g.dependencies[name]; ok {
+		return dep.status(g.options.SlowThreshold), nil
+	}
+	if _, ok := g.features[name]; ok {
+		return g.feature(name).Status, nil
+	}
+	return "", ErrUnknownNode
+}
+
+// Available reports whether a node can be used. Unknown nodes and nodes
+// that have not been probed yet are assumed available, so a missing probe
+// never turns a feature off.
+func (g *Graph) Available(name string) bool {
+	status, err := g.Status(name)
+	return err != nil || status != StatusDown
+}
+
+// Report returns a snapshot of every node and edge, sorted by name
+func (g *Graph) Report() Report {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	report := Report{Status: StatusHealthy, Degraded: []string{}, Nodes: []Node{}, Edges: []Edge{}}
+	for name := range g.dependencies {
+		report.Nodes = append(report.Nodes, g.dependencyNode(name))
+	}
+	for name, edges := range g.features {
+		report.Nodes = append(report.Nodes, g.feature(name))
+		for _, e := range edges {
+			target := g.dependencyNode(e.to)
+			report.Edges = append(report.Edges, Edge{
+				From:      name,
+				To:        e.to,
+				Critical:  e.critical,
+				Status:    target.Status,
+				Score:     target.Score,
+				LatencyMs: target.LatencyMs,
+			})
+		}
+	}
+
+	sort.Slice(report.Nodes, func(i, j int) bool { return report.Nodes[i].Name < report.Nodes[j].Name })
+	sort.Slice(report.Edges, func(i, j int) bool {
+		if report.Edges[i].From != report.Edges[j].From {
+			return report.Edges[i].From < report.Edges[j].From
+		}
+		return report.Edges[i].To < report.Edges[j].To
+	})
+	for _, node := range report.Nodes {
+		switch node.Status {
+		case StatusDown:
+			report.Status = StatusDown
+			report.Degraded = append(report.Degraded, node.Name)
+		case StatusDegraded:
+			if report.Status == StatusHealthy {
+				report.Status = StatusDegraded
+			}
+			report.Degraded = append(report.Degraded, node.Name)
+		}
+	}
+	return report
+}
+
+// dependencyNode describes a dependency; callers hold the lock
+func (g *Graph) dependencyNode(name string) Node {
+	dep := g.dependencies[name]
+	node := Node{Name: name, Kind: KindDependency, Status: dep.status(g.options.SlowThreshold)}
+	if len(dep.samples) == 0 {
+		return node
+	}
+	last := dep.samples[len(dep.samples)-1]
+	checked := dep.lastChecked
+	node.LastChecked = &checked
+	node.LatencyMs = last.latency.Milliseconds()
+	if last.err != nil {
+		node.LastError = last.err.Error()
+	}
+	node.Score = dep.score(g.options.SlowThreshold)
+	return node
+}
+
+// feature derives the health of a feature from its edges; callers hold the
+// lock
+func (g *Graph) feature(name string) Node {
+	node := Node{Name: name, Kind: KindFeature, Status: StatusHealthy, Score: 100}
+	probed := false
+	for _, e := range g.features[name] {
+		target := g.dependencyNode(e.to)
+		if target.Status == StatusUnknown {
+			continue
+		}
+		probed = true
+		if target.LatencyMs > node.LatencyMs {
+			node.LatencyMs = target.LatencyMs
+		}
+
+		score, status := target.Score, target.Status
+		if !e.critical {
+			// A lost optional dependency costs at most half the score
+			score = 50 + score/2
+			if status == StatusDown {
+				status = StatusDegraded
+			}
+		}
+		if score < node.Score {
+			node.Score = score
+		}
+		if status == StatusDown || (status == StatusDegraded && node.Status == StatusHealthy) {
+			node.Status = status
+		}
+	}
+	if !probed && len(g.features[name]) > 0 {
+		node.Status, node.Score = StatusUnknown, 0
+	}
+	return node
+}
+
+// status is down when the last probe failed, degraded when earlier probes
+// failed or probes are slow
+func (d *dependency) status(slow time.Duration) Status {
+	if len(d.samples) == 0 {
+		return StatusUnknown
+	}
+	if d.samples[len(d.samples)-1].err != nil {
+		return StatusDown
+	}
+	if d.score(slow) < 80 {
+		return StatusDegraded
+	}
+	return StatusHealthy
+}
+
+func (d *dependency) score(slow time.Duration) int {
+	var succeeded int
+	var latency time.Duration
+	for _, s := range d.samples {
+		if s.err == nil {
+			succeeded++
+			latency += s.latency
+		}
+	}
+	score := succeeded * 100 / len(d.samples)
+	if succeeded > 0 && latency/time.Duration(succeeded) > slow {
+		score /= 2
+	}
+	return score
+}
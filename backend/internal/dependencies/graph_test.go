@@ -0,0 +1,112 @@
+package dependencies
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// switchable returns the error it holds
+type switchable struct {
+	err error
+}
+
+func (s *switchable) probe(context.Context) error {
+	return s.err
+}
+
+func newTestGraph() (*Graph, map[string]*switchable) {
+	graph := NewGraph(Options{Window: 4, SlowThreshold: time.Second})
+	probes := map[string]*switchable{}
+	for _, name := range []string{"database", "redis", "ai_provider"} {
+		probes[name] = &switchable{}
+		graph.AddDependency(name, probes[name].probe)
+	}
+	graph.Depends("api", "database", true)
+	graph.Depends("api", "redis", false)
+	graph.Depends("writing_scoring", "ai_provider", true)
+	graph.Depends("writing_scoring", "cloudinary", true)
+	return graph, probes
+}
+
+func TestFeaturesInheritDependencyHealth(t *testing.T) {
+	graph, probes := newTestGraph()
+	ctx := context.Background()
+
+	status, err := graph.Status("api")
+	require.NoError(t, err)
+	assert.Equal(t, StatusUnknown, status)
+	assert.True(t, graph.Available("writing_scoring"))
+
+	graph.Check(ctx)
+	report := graph.Report()
+	assert.Equal(t, StatusHealthy, report.Status)
+	assert.Len(t, report.Nodes, 5)
+	// The edge to the unregistered cloudinary is left out
+	assert.Len(t, report.Edges, 3)
+
+	// Losing an optional dependency degrades the feature
+	probes["redis"].err = errors.New("connection refused")
+	graph.Check(ctx)
+	status, _ = graph.Status("api")
+	assert.Equal(t, StatusDegraded, status)
+	assert.True(t, graph.Available("api"))
+
+	// Losing a critical one takes it down
+	probes["ai_provider"].err = errors.New("timeout")
+	graph.Check(ctx)
+	assert.False(t, graph.Available("writing_scoring"))
+	report = graph.Report()
+	assert.Equal(t, StatusDown, report.Status)
+	assert.Equal(t, []string{"ai_provider", "api", "redis", "writing_scoring"}, report.Degraded)
+	for _, edge := range report.Edges {
+		if edge.To == "ai_provider" {
+			assert.Equal(t, StatusDown, edge.Status)
+			assert.Equal(t, 66, edge.Score)
+		}
+	}
+
+	_, err = graph.Status("missing")
+	assert.ErrorIs(t, err, ErrUnknownNode)
+}
+
+func TestRecoveredDependencyStaysDegradedWhileScoreIsLow(t *testing.T) {
+	graph, probes := newTestGraph()
+	ctx := context.Background()
+
+	probes["database"].err = errors.New("down")
+	graph.Check(ctx)
+	graph.Check(ctx)
+	probes["database"].err = nil
+	graph.Check(ctx)
+
+	status, _ := graph.Status("database")
+	assert.Equal(t, StatusDegraded, status)
+	graph.Check(ctx)
+	graph.Check(ctx)
+	graph.Check(ctx)
+	status, _ = graph.Status("database")
+	assert.Equal(t, StatusHealthy, status)
+}
+
+func TestSlowDependencyIsDegraded(t *testing.T) {
+	graph := NewGraph(Options{SlowThreshold: time.Second})
+	clock := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	// Every reading of the clock advances it, so each probe takes 2 seconds
+	graph.now = func() time.Time {
+		clock = clock.Add(2 * time.Second)
+		return clock
+	}
+	graph.AddDependency("analyze_service", func(context.Context) error { return nil })
+	graph.Check(context.Background())
+
+	report := graph.Report()
+	require.Len(t, report.Nodes, 1)
+	assert.Equal(t, StatusDegraded, report.Nodes[0].Status)
+	assert.Equal(t, 50, report.Nodes[0].Score)
+	assert.Equal(t, int64(2000), report.Nodes[0].LatencyMs)
+}
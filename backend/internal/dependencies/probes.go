@@ -0,0 +1,80 @@
+package dependencies
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/toeic-app/internal/cache"
+)
+
+// Pinger is satisfied by *sql.DB
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// PingProbe checks a database connection
+func PingProbe(db Pinger) Probe {
+	return db.PingContext
+}
+
+// CacheProbe writes and reads back a key, which reaches Redis when the cache
+// is backed by it
+func CacheProbe(c cache.Cache) Probe {
+	return func(ctx context.Context) error {
+		const key = "dependency_probe"
+		if err := c.Set(ctx, key, []byte("ok"), time.Minute); err != nil {
+			return fmt.Errorf("set failed: %w", err)
+		}
+		if _, err := c.Get(ctx, key); err != nil {
+			return fmt.Errorf("get failed: %w", err)
+		}
+		return nil
+	}
+}
+
+// HTTPProbe requests url and fails on network errors and server errors. Any
+// other status means the service answered, which is enough for endpoints
+// that need credentials.
+func HTTPProbe(client *http.Client, url string) Probe {
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return fmt.Errorf("%s returned status %d", url, resp.StatusCode)
+		}
+		return nil
+	}
+}
+
+// DirProbe checks that files can be written to each directory, creating the
+// directories that are missing
+func DirProbe(dirs ...string) Probe {
+	return func(ctx context.Context) error {
+		for _, dir := range dirs {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return err
+			}
+			file, err := os.CreateTemp(dir, ".probe-*")
+			if err != nil {
+				return err
+			}
+			name := file.Name()
+			file.Close()
+			if err := os.Remove(name); err != nil {
+				return fmt.Errorf("failed to remove %s: %w", filepath.Base(name), err)
+			}
+		}
+		return nil
+	}
+}
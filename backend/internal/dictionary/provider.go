@@ -0,0 +1,150 @@
+package dictionary
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNotFound    = errors.New("word not found in dictionary")
+	ErrRateLimited = errors.New("dictionary provider rate limit exceeded")
+)
+
+// Entry is a dictionary entry normalized across providers
+type Entry struct {
+	Word     string    `json:"word"`
+	Phonetic string    `json:"phonetic,omitempty"`
+	AudioURL string    `json:"audio_url,omitempty"`
+	Meanings []Meaning `json:"meanings,omitempty"`
+}
+
+// Meaning groups the definitions of a word by part of speech
+type Meaning struct {
+	PartOfSpeech string   `json:"part_of_speech"`
+	Definitions  []string `json:"definitions"`
+}
+
+// Provider looks words up in an external dictionary
+type Provider interface {
+	// Name identifies the provider in lookup records
+	Name() string
+	// Lookup returns ErrNotFound when the provider does not know the word
+	Lookup(ctx context.Context, word string) (*Entry, error)
+}
+
+// FreeDictionaryProvider looks words up in the Free Dictionary API
+// (https://dictionaryapi.dev), which needs no API key
+type FreeDictionaryProvider struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewFreeDictionaryProvider creates a provider for the given entries endpoint,
+// e.g. https://api.dictionaryapi.dev/api/v2/entries/en
+func NewFreeDictionaryProvider(baseURL string, timeout time.Duration) *FreeDictionaryProvider {
+	return &FreeDictionaryProvider{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// Name implements Provider
+func (p *FreeDictionaryProvider) Name() string {
+	return "freedictionary"
+}
+
+type freeDictionaryEntry struct {
+	Word      string `json:"word"`
+	Phonetic  string `json:"phonetic"`
+	Phonetics []struct {
+		Text  string `json:"text"`
+		Audio string `json:"audio"`
+	} `json:"phonetics"`
+	Meanings []struct {
+		PartOfSpeech string `json:"partOfSpeech"`
+		Definitions  []struct {
+			Definition string `json:"definition"`
+		} `json:"definitions"`
+	} `json:"meanings"`
+}
+
+// Lookup implements Provider
+func (p *FreeDictionaryProvider) Lookup(ctx context.Context, word string) (*Entry, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"/"+url.PathEscape(word), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, ErrNotFound
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return nil, ErrRateLimited
+	case resp.StatusCode != http.StatusOK:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("dictionary returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var entries []freeDictionaryEntry
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil, ErrNotFound
+	}
+
+	return mergeFreeDictionaryEntries(word, entries), nil
+}
+
+// mergeFreeDictionaryEntries folds the homonym entries the API returns into one
+func mergeFreeDictionaryEntries(word string, entries []freeDictionaryEntry) *Entry {
+	entry := &Entry{Word: word}
+	meanings := make(map[string]int)
+
+	for _, e := range entries {
+		if entry.Phonetic == "" {
+			entry.Phonetic = e.Phonetic
+		}
+		for _, phonetic := range e.Phonetics {
+			if entry.Phonetic == "" {
+				entry.Phonetic = phonetic.Text
+			}
+			if entry.AudioURL == "" && phonetic.Audio != "" {
+				entry.AudioURL = phonetic.Audio
+				// Older entries use protocol-relative URLs
+				if strings.HasPrefix(entry.AudioURL, "//") {
+					entry.AudioURL = "https:" + entry.AudioURL
+				}
+			}
+		}
+		for _, m := range e.Meanings {
+			i, ok := meanings[m.PartOfSpeech]
+			if !ok {
+				i = len(entry.Meanings)
+				meanings[m.PartOfSpeech] = i
+				entry.Meanings = append(entry.Meanings, Meaning{PartOfSpeech: m.PartOfSpeech})
+			}
+			for _, d := range m.Definitions {
+				if d.Definition != "" {
+					entry.Meanings[i].Definitions = append(entry.Meanings[i].Definitions, d.Definition)
+				}
+			}
+		}
+	}
+
+	return entry
+}
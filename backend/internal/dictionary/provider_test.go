@@ -0,0 +1,50 @@
+package dictionary
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFreeDictionaryProviderLookup(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/entries/en/hello":
+			w.Write([]byte(`[
+				{"word": "hello", "phonetics": [{"text": "/həˈləʊ/", "audio": ""}, {"audio": "//ssl.gstatic.com/hello.mp3"}],
+				 "meanings": [{"partOfSpeech": "noun", "definitions": [{"definition": "A greeting."}]}]},
+				{"word": "hello", "phonetic": "/hɛˈləʊ/",
+				 "meanings": [{"partOfSpeech": "noun", "definitions": [{"definition": "An utterance of hello."}]},
+				              {"partOfSpeech": "verb", "definitions": [{"definition": "To greet with hello."}]}]}
+			]`))
+		case "/entries/en/busy":
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"title": "No Definitions Found"}`))
+		}
+	}))
+	defer server.Close()
+
+	provider := NewFreeDictionaryProvider(server.URL+"/entries/en/", time.Second)
+
+	entry, err := provider.Lookup(context.Background(), "hello")
+	require.NoError(t, err)
+	assert.Equal(t, "/həˈləʊ/", entry.Phonetic)
+	assert.Equal(t, "https://ssl.gstatic.com/hello.mp3", entry.AudioURL)
+	require.Len(t, entry.Meanings, 2, "homonym entries are merged by part of speech")
+	assert.Equal(t, []string{"A greeting.", "An utterance of hello."}, entry.Meanings[0].Definitions)
+	assert.Equal(t, "verb", entry.Meanings[1].PartOfSpeech)
+
+	_, err = provider.Lookup(context.Background(), "qwzx")
+	assert.True(t, errors.Is(err, ErrNotFound))
+
+	_, err = provider.Lookup(context.Background(), "busy")
+	assert.True(t, errors.Is(err, ErrRateLimited))
+}
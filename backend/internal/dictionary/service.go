@@ -0,0 +1,378 @@
+package dictionary
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sqlc-dev/pqtype"
+	"github.com/toeic-app/internal/cache"
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/logger"
+	"github.com/toeic-app/internal/wordsense"
+	"golang.org/x/time/rate"
+)
+
+// Lookup statuses recorded per word
+const (
+	LookupFound    = "found"
+	LookupNotFound = "not_found"
+	LookupFailed   = "failed"
+)
+
+const (
+	// notFoundCacheTTL is shorter than the entry TTL since providers add words
+	notFoundCacheTTL = 24 * time.Hour
+	// maxDefinitionsPerKind keeps generated meanings as concise as curated ones
+	maxDefinitionsPerKind = 3
+	// backfillBatchSize is the number of words fetched from the database at once
+	backfillBatchSize = 100
+	// maxFieldLength matches the VARCHAR(255) columns of the words table
+	maxFieldLength = 255
+)
+
+var ErrBackfillRunning = errors.New("a dictionary backfill is already running")
+
+// Service fills missing word data from an external dictionary, with a cache
+// in front of the provider and a rate limit on outgoing requests
+type Service struct {
+	store            db.Querier
+	provider         Provider
+	cache            cache.Cache
+	cacheTTL         time.Duration
+	limiter          *rate.Limiter
+	retryFailedAfter time.Duration
+
+	senses           *wordsense.Service
+
+	mu      sync.Mutex
+	lastRun *BackfillRun
+	cancel  context.CancelFunc
+}
+
+// BackfillRun describes the progress of a backfill job
+type BackfillRun struct {
+	Running    bool       `json:"running"`
+	Limit      int        `json:"limit"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Scanned    int        `json:"scanned"`
+	Updated    int        `json:"updated"`
+	NotFound   int        `json:"not_found"`
+	Failed     int        `json:"failed"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// Status summarizes missing word data and past lookups
+type Status struct {
+	Provider     string           `json:"provider"`
+	MissingWords int64            `json:"missing_words"`
+	Lookups      map[string]int64 `json:"lookups"`
+	LastRun      *BackfillRun     `json:"last_run,omitempty"`
+}
+
+// cachedLookup is stored in the cache; a nil entry records a miss
+type cachedLookup struct {
+	Entry *Entry `json:"entry"`
+}
+
+// NewService creates a new dictionary service. The cache is optional.
+// requestsPerSecond limits calls to the provider; failed lookups are retried
+// by the backfill once retryFailedAfter has passed.
+func NewService(store db.Querier, provider Provider, lookupCache cache.Cache, cacheTTL time.Duration, requestsPerSecond float64, retryFailedAfter time.Duration) *Service {
+	if requestsPerSecond <= 0 {
+		requestsPerSecond = 1
+	}
+	return &Service{
+		store:            store,
+		provider:         provider,
+		cache:            lookupCache,
+		cacheTTL:         cacheTTL,
+		limiter:          rate.NewLimiter(rate.Limit(requestsPerSecond), 1),
+		retryFailedAfter: retryFailedAfter,
+		senses:           wordsense.NewService(store),
+	}
+}
+
+// Lookup returns the dictionary entry for a word, using the cache when available
+func (s *Service) Lookup(ctx context.Context, word string) (*Entry, error) {
+	word = strings.ToLower(strings.TrimSpace(word))
+	if word == "" {
+		return nil, ErrNotFound
+	}
+
+	key := cacheKey(s.provider.Name(), word)
+	if s.cache != nil {
+		if data, err := s.cache.Get(ctx, key); err == nil {
+			var cached cachedLookup
+			if err := json.Unmarshal(data, &cached); err == nil {
+				if cached.Entry == nil {
+					return nil, ErrNotFound
+				}
+				return cached.Entry, nil
+			}
+		}
+	}
+
+	if err := s.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	entry, err := s.provider.Lookup(ctx, word)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		s.cacheLookup(ctx, key, nil, notFoundCacheTTL)
+		return nil, ErrNotFound
+	case err != nil:
+		return nil, err
+	}
+
+	s.cacheLookup(ctx, key, entry, s.cacheTTL)
+	return entry, nil
+}
+
+func (s *Service) cacheLookup(ctx context.Context, key string, entry *Entry, ttl time.Duration) {
+	if s.cache == nil {
+		return
+	}
+	data, err := json.Marshal(cachedLookup{Entry: entry})
+	if err != nil {
+		return
+	}
+	if err := s.cache.Set(ctx, key, data, ttl); err != nil {
+		logger.Debug("Failed to cache dictionary lookup %s: %v", key, err)
+	}
+}
+
+// EnrichWord looks a word up and fills its empty fields. Fields that already
+// have data are left untouched. Returns ErrNotFound when the provider does not
+// know the word.
+func (s *Service) EnrichWord(ctx context.Context, word db.Word) (db.Word, error) {
+	entry, err := s.Lookup(ctx, word.Word)
+	if err != nil {
+		// Rate limits and cancellation say nothing about the word itself
+		if !errors.Is(err, ErrRateLimited) && ctx.Err() == nil {
+			status := LookupFailed
+			if errors.Is(err, ErrNotFound) {
+				status = LookupNotFound
+			}
+			s.recordLookup(ctx, word.ID, status, err)
+		}
+		return word, err
+	}
+
+	updated, err := s.store.FillWordDictionaryData(ctx, toWordData(word.ID, entry))
+	if err != nil {
+		s.recordLookup(ctx, word.ID, LookupFailed, err)
+		return word, fmt.Errorf("failed to update word: %w", err)
+	}
+
+	// Senses follow the meanings the lookup filled in
+	if !bytes.Equal(word.Means.RawMessage, updated.Means.RawMessage) || word.ShortMean != updated.ShortMean {
+		if _, err := s.senses.Sync(ctx, updated); err != nil {
+			logger.Warn("Failed to update senses of word %d: %v", word.ID, err)
+		}
+	}
+
+	s.recordLookup(ctx, word.ID, LookupFound, nil)
+	return updated, nil
+}
+
+func (s *Service) recordLookup(ctx context.Context, wordID int32, status string, lookupErr error) {
+	params := db.UpsertWordLookupParams{
+		WordID:   wordID,
+		Provider: s.provider.Name(),
+		Status:   status,
+	}
+	if lookupErr != nil && status == LookupFailed {
+		params.Error = sql.NullString{String: lookupErr.Error(), Valid: true}
+	}
+	if err := s.store.UpsertWordLookup(ctx, params); err != nil {
+		logger.Warn("Failed to record dictionary lookup for word %d: %v", wordID, err)
+	}
+}
+
+// toWordData converts an entry into the words table representation
+func toWordData(wordID int32, entry *Entry) db.FillWordDictionaryDataParams {
+	type mean struct {
+		Mean string `json:"mean"`
+	}
+	type meaning struct {
+		Kind  string `json:"kind"`
+		Means []mean `json:"means"`
+	}
+
+	params := db.FillWordDictionaryDataParams{
+		ID:        wordID,
+		Pronounce: truncate(entry.Phonetic, maxFieldLength),
+	}
+	if entry.AudioURL != "" {
+		params.AudioUrl = sql.NullString{String: entry.AudioURL, Valid: true}
+	}
+
+	meanings := make([]meaning, 0, len(entry.Meanings))
+	for _, m := range entry.Meanings {
+		if len(m.Definitions) == 0 {
+			continue
+		}
+		if params.ShortMean == "" {
+			params.ShortMean = truncate(m.Definitions[0], maxFieldLength)
+		}
+		definitions := m.Definitions
+		if len(definitions) > maxDefinitionsPerKind {
+			definitions = definitions[:maxDefinitionsPerKind]
+		}
+		item := meaning{Kind: m.PartOfSpeech}
+		for _, definition := range definitions {
+			item.Means = append(item.Means, mean{Mean: definition})
+		}
+		meanings = append(meanings, item)
+	}
+	if len(meanings) > 0 {
+		if data, err := json.Marshal(meanings); err == nil {
+			params.Means = pqtype.NullRawMessage{RawMessage: data, Valid: true}
+		}
+	}
+
+	return params
+}
+
+// StartBackfill starts a background job that enriches up to limit words with
+// missing data, most frequent first
+func (s *Service) StartBackfill(limit int) (BackfillRun, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.lastRun != nil && s.lastRun.Running {
+		return *s.lastRun, ErrBackfillRunning
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.lastRun = &BackfillRun{Running: true, Limit: limit, StartedAt: time.Now()}
+	run := *s.lastRun
+
+	go s.backfill(ctx, limit)
+	return run, nil
+}
+
+// Stop cancels a running backfill
+func (s *Service) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		s.cancel()
+	}
+}
+
+func (s *Service) backfill(ctx context.Context, limit int) {
+	logger.Info("Dictionary backfill started (limit %d)", limit)
+	var runErr error
+	defer func() {
+		s.mu.Lock()
+		now := time.Now()
+		s.lastRun.Running = false
+		s.lastRun.FinishedAt = &now
+		if runErr != nil {
+			s.lastRun.Error = runErr.Error()
+		}
+		run := *s.lastRun
+		s.cancel = nil
+		s.mu.Unlock()
+		logger.Info("Dictionary backfill finished: scanned=%d updated=%d not_found=%d failed=%d",
+			run.Scanned, run.Updated, run.NotFound, run.Failed)
+	}()
+
+	retryBefore := time.Now().Add(-s.retryFailedAfter)
+	for scanned := 0; scanned < limit; {
+		batch := limit - scanned
+		if batch > backfillBatchSize {
+			batch = backfillBatchSize
+		}
+		words, err := s.store.ListWordsMissingDictionaryData(ctx, db.ListWordsMissingDictionaryDataParams{
+			RetryBefore: retryBefore,
+			MaxItems:    int32(batch),
+		})
+		if err != nil {
+			runErr = fmt.Errorf("failed to list words: %w", err)
+			return
+		}
+		if len(words) == 0 {
+			return
+		}
+
+		for _, word := range words {
+			_, err := s.EnrichWord(ctx, word)
+			scanned++
+
+			s.mu.Lock()
+			s.lastRun.Scanned++
+			switch {
+			case err == nil:
+				s.lastRun.Updated++
+			case errors.Is(err, ErrNotFound):
+				s.lastRun.NotFound++
+			default:
+				s.lastRun.Failed++
+			}
+			s.mu.Unlock()
+
+			if errors.Is(err, ErrRateLimited) || ctx.Err() != nil {
+				runErr = err
+				if runErr == nil {
+					runErr = ctx.Err()
+				}
+				return
+			}
+		}
+	}
+}
+
+// Status returns counts of missing data and lookups with the last backfill run
+func (s *Service) Status(ctx context.Context) (*Status, error) {
+	missing, err := s.store.CountWordsMissingDictionaryData(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count words: %w", err)
+	}
+	rows, err := s.store.CountWordLookupsByStatus(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count lookups: %w", err)
+	}
+
+	status := &Status{
+		Provider:     s.provider.Name(),
+		MissingWords: missing,
+		Lookups:      make(map[string]int64, len(rows)),
+	}
+	for _, row := range rows {
+		status.Lookups[row.Status] = row.Count
+	}
+
+	s.mu.Lock()
+	if s.lastRun != nil {
+		run := *s.lastRun
+		status.LastRun = &run
+	}
+	s.mu.Unlock()
+
+	return status, nil
+}
+
+func cacheKey(provider, word string) string {
+	return "dictionary:" + provider + ":" + word
+}
+
+// truncate shortens s to at most max runes
+func truncate(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	return string([]rune(s)[:max])
+}
@@ -0,0 +1,95 @@
+package dictionary
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toeic-app/internal/cache"
+)
+
+// countingProvider answers from a fixed set of entries and counts calls
+type countingProvider struct {
+	entries map[string]*Entry
+	calls   int
+}
+
+func (p *countingProvider) Name() string { return "test" }
+
+func (p *countingProvider) Lookup(ctx context.Context, word string) (*Entry, error) {
+	p.calls++
+	if entry, ok := p.entries[word]; ok {
+		return entry, nil
+	}
+	return nil, ErrNotFound
+}
+
+func TestLookupUsesCache(t *testing.T) {
+	provider := &countingProvider{entries: map[string]*Entry{"meeting": {Word: "meeting", Phonetic: "/ˈmiːtɪŋ/"}}}
+	memory := cache.NewMemoryCache(cache.CacheConfig{MaxEntries: 100, DefaultTTL: time.Minute})
+	service := NewService(nil, provider, memory, time.Hour, 1000, time.Hour)
+
+	for i := 0; i < 3; i++ {
+		entry, err := service.Lookup(context.Background(), " Meeting ")
+		require.NoError(t, err)
+		assert.Equal(t, "/ˈmiːtɪŋ/", entry.Phonetic)
+
+		_, err = service.Lookup(context.Background(), "qwzx")
+		assert.True(t, errors.Is(err, ErrNotFound))
+	}
+	assert.Equal(t, 2, provider.calls, "hits and misses are both cached")
+}
+
+func TestLookupIsRateLimited(t *testing.T) {
+	provider := &countingProvider{}
+	service := NewService(nil, provider, nil, time.Hour, 1, time.Hour)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := service.Lookup(ctx, "first")
+	assert.True(t, errors.Is(err, ErrNotFound))
+	_, err = service.Lookup(ctx, "second")
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, ErrNotFound), "the second call cannot get a token before the deadline")
+	assert.Equal(t, 1, provider.calls)
+}
+
+func TestToWordData(t *testing.T) {
+	entry := &Entry{
+		Word:     "schedule",
+		Phonetic: "/ˈʃɛdjuːl/",
+		AudioURL: "https://example.com/schedule.mp3",
+		Meanings: []Meaning{
+			{PartOfSpeech: "noun", Definitions: []string{strings.Repeat("a", 300), "b", "c", "d"}},
+			{PartOfSpeech: "verb", Definitions: []string{"To plan for a time."}},
+			{PartOfSpeech: "adjective"},
+		},
+	}
+
+	params := toWordData(7, entry)
+	assert.Equal(t, int32(7), params.ID)
+	assert.Equal(t, "/ˈʃɛdjuːl/", params.Pronounce)
+	assert.Len(t, params.ShortMean, maxFieldLength)
+	assert.Equal(t, "https://example.com/schedule.mp3", params.AudioUrl.String)
+	require.True(t, params.Means.Valid)
+
+	var means []struct {
+		Kind  string `json:"kind"`
+		Means []struct {
+			Mean string `json:"mean"`
+		} `json:"means"`
+	}
+	require.NoError(t, json.Unmarshal(params.Means.RawMessage, &means))
+	require.Len(t, means, 2)
+	assert.Len(t, means[0].Means, maxDefinitionsPerKind)
+	assert.Equal(t, "verb", means[1].Kind)
+
+	empty := toWordData(8, &Entry{Word: "x"})
+	assert.False(t, empty.Means.Valid)
+	assert.False(t, empty.AudioUrl.Valid)
+}
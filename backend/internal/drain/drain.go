@@ -0,0 +1,171 @@
+// Package drain takes an instance out of service before a deployment stops
+// it. While draining, new exam attempts and AI work are refused, requests
+// in flight and queued background work get until a deadline to finish, and
+// the progress is reported until the instance is safe to shut down.
+package drain
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Drain states
+const (
+	StateServing  = "serving"
+	StateDraining = "draining"
+	StateDrained  = "drained"
+)
+
+// pollInterval is how often Wait checks whether draining finished
+const pollInterval = 100 * time.Millisecond
+
+var ErrNotDraining = errors.New("instance is not draining")
+
+// Work reports how many units of background work are left, such as queued
+// and running jobs
+type Work func() int
+
+// Status is the progress of a drain
+type Status struct {
+	State            string         `json:"state"`
+	StartedAt        *time.Time     `json:"started_at,omitempty"`
+	Deadline         *time.Time     `json:"deadline,omitempty"`
+	StartedBy        string         `json:"started_by,omitempty"`
+	InFlightRequests int64          `json:"in_flight_requests"`
+	PendingWork      map[string]int `json:"pending_work"`
+	DeadlineExceeded bool           `json:"deadline_exceeded"`
+	// SafeToShutdown is set once everything finished or the deadline
+	// passed; work left at the deadline is cancelled by the shutdown
+	SafeToShutdown bool `json:"safe_to_shutdown"`
+}
+
+// Controller tracks requests in flight and the drain state of the instance
+type Controller struct {
+	now      func() time.Time
+	inFlight atomic.Int64
+
+	mu        sync.Mutex
+	draining  bool
+	startedAt time.Time
+	deadline  time.Time
+	startedBy string
+	work      map[string]Work
+}
+
+// NewController creates a controller for an instance that is serving
+func NewController() *Controller {
+	return &Controller{
+		now:  time.Now,
+		work: make(map[string]Work),
+	}
+}
+
+// AddWork registers background work a drain waits for
+func (c *Controller) AddWork(name string, pending Work) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.work[name] = pending
+}
+
+// Track counts a request as in flight until the returned function is called
+func (c *Controller) Track() func() {
+	c.inFlight.Add(1)
+	var once sync.Once
+	return func() {
+		once.Do(func() { c.inFlight.Add(-1) })
+	}
+}
+
+// Draining reports whether new work is refused
+func (c *Controller) Draining() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.draining
+}
+
+// Start begins draining with the given time for work to finish. Starting
+// again while draining keeps the first deadline.
+func (c *Controller) Start(timeout time.Duration, startedBy string) Status {
+	c.mu.Lock()
+	if !c.draining {
+		c.draining = true
+		c.startedAt = c.now()
+		c.deadline = c.startedAt.Add(timeout)
+		c.startedBy = startedBy
+	}
+	c.mu.Unlock()
+	return c.Status()
+}
+
+// Resume cancels a drain so the instance accepts new work again
+func (c *Controller) Resume() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.draining {
+		return ErrNotDraining
+	}
+	c.draining = false
+	c.startedAt, c.deadline, c.startedBy = time.Time{}, time.Time{}, ""
+	return nil
+}
+
+// Status reports the drain progress
+func (c *Controller) Status() Status {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	status := Status{
+		State:            StateServing,
+		InFlightRequests: c.inFlight.Load(),
+		PendingWork:      make(map[string]int, len(c.work)),
+	}
+	names := make([]string, 0, len(c.work))
+	for name := range c.work {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	idle := status.InFlightRequests == 0
+	for _, name := range names {
+		pending := c.work[name]()
+		status.PendingWork[name] = pending
+		if pending > 0 {
+			idle = false
+		}
+	}
+	if !c.draining {
+		return status
+	}
+
+	startedAt, deadline := c.startedAt, c.deadline
+	status.StartedAt, status.Deadline = &startedAt, &deadline
+	status.StartedBy = c.startedBy
+	status.DeadlineExceeded = !c.now().Before(deadline)
+	status.State = StateDraining
+	if idle {
+		status.State = StateDrained
+	}
+	status.SafeToShutdown = idle || status.DeadlineExceeded
+	return status
+}
+
+// Wait blocks until the drain is safe to shut down or ctx is done, and
+// returns the last status
+func (c *Controller) Wait(ctx context.Context) Status {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		status := c.Status()
+		if status.State == StateServing || status.SafeToShutdown {
+			return status
+		}
+		select {
+		case <-ctx.Done():
+			return status
+		case <-ticker.C:
+		}
+	}
+}
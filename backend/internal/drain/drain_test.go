@@ -0,0 +1,67 @@
+package drain
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrainWaitsForRequestsAndWork(t *testing.T) {
+	controller := NewController()
+	clock := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	controller.now = func() time.Time { return clock }
+	jobs := 2
+	controller.AddWork("background_jobs", func() int { return jobs })
+
+	done := controller.Track()
+	status := controller.Status()
+	assert.Equal(t, StateServing, status.State)
+	assert.False(t, controller.Draining())
+	assert.ErrorIs(t, controller.Resume(), ErrNotDraining)
+
+	status = controller.Start(time.Minute, "admin")
+	assert.True(t, controller.Draining())
+	assert.Equal(t, StateDraining, status.State)
+	assert.Equal(t, int64(1), status.InFlightRequests)
+	assert.Equal(t, map[string]int{"background_jobs": 2}, status.PendingWork)
+	assert.False(t, status.SafeToShutdown)
+
+	// Starting again keeps the first deadline
+	clock = clock.Add(30 * time.Second)
+	status = controller.Start(time.Hour, "shutdown")
+	assert.Equal(t, "admin", status.StartedBy)
+	assert.Equal(t, clock.Add(30*time.Second), *status.Deadline)
+
+	done()
+	done()
+	jobs = 0
+	status = controller.Wait(context.Background())
+	assert.Equal(t, StateDrained, status.State)
+	assert.Equal(t, int64(0), status.InFlightRequests)
+	assert.True(t, status.SafeToShutdown)
+
+	require.NoError(t, controller.Resume())
+	assert.Equal(t, StateServing, controller.Status().State)
+}
+
+func TestDrainIsSafeToShutdownAtDeadline(t *testing.T) {
+	controller := NewController()
+	clock := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	controller.now = func() time.Time { return clock }
+	controller.AddWork("background_jobs", func() int { return 1 })
+
+	controller.Start(time.Minute, "admin")
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	status := controller.Wait(ctx)
+	assert.False(t, status.SafeToShutdown)
+
+	clock = clock.Add(time.Minute)
+	status = controller.Status()
+	assert.Equal(t, StateDraining, status.State)
+	assert.True(t, status.DeadlineExceeded)
+	assert.True(t, status.SafeToShutdown)
+}
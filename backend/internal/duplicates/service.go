@@ -0,0 +1,131 @@
+// Package duplicates finds questions in the bank that repeat each other.
+// Questions are compared by their fingerprint, the normalized text of the
+// title and answer options computed by the question_fingerprint database
+// function: identical fingerprints are exact duplicates and fingerprints
+// with a high trigram similarity are near duplicates.
+package duplicates
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+// Options configure duplicate detection
+type Options struct {
+	// MinSimilarity is the trigram similarity, from 0.3 to 1, at which two
+	// questions are near duplicates
+	MinSimilarity float32
+	// MaxCandidates is how many matches are returned for a new question
+	MaxCandidates int32
+}
+
+// Candidate is a question of the bank that may duplicate a new question
+type Candidate struct {
+	QuestionID      int32    `json:"question_id"`
+	ContentID       int32    `json:"content_id"`
+	Title           string   `json:"title"`
+	PossibleAnswers []string `json:"possible_answers"`
+	TrueAnswer      string   `json:"true_answer"`
+	Exact           bool     `json:"exact"`
+	Similarity      float64  `json:"similarity"`
+}
+
+// Pair is two questions of the bank that duplicate each other
+type Pair struct {
+	QuestionID         int32   `json:"question_id"`
+	ContentID          int32   `json:"content_id"`
+	Title              string  `json:"title"`
+	DuplicateID        int32   `json:"duplicate_id"`
+	DuplicateContentID int32   `json:"duplicate_content_id"`
+	DuplicateTitle     string  `json:"duplicate_title"`
+	Exact              bool    `json:"exact"`
+	Similarity         float64 `json:"similarity"`
+}
+
+// Service detects duplicate questions
+type Service struct {
+	store   db.Querier
+	options Options
+}
+
+// NewService creates a duplicate detection service
+func NewService(store db.Querier, options Options) *Service {
+	return &Service{store: store, options: options}
+}
+
+// Candidates returns the questions most similar to a question with the
+// given title and answer options, exact duplicates first. excludeID skips
+// the question itself when an existing question is checked; pass 0 for new
+// questions.
+func (s *Service) Candidates(ctx context.Context, title string, answers []string, excludeID int32) ([]Candidate, error) {
+	if answers == nil {
+		answers = []string{}
+	}
+	rows, err := s.store.FindSimilarQuestions(ctx, db.FindSimilarQuestionsParams{
+		Title:           title,
+		PossibleAnswers: answers,
+		ExcludeID:       excludeID,
+		MinSimilarity:   s.options.MinSimilarity,
+		MaxResults:      s.options.MaxCandidates,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find similar questions: %w", err)
+	}
+
+	candidates := make([]Candidate, 0, len(rows))
+	for _, row := range rows {
+		candidates = append(candidates, Candidate{
+			QuestionID:      row.QuestionID,
+			ContentID:       row.ContentID,
+			Title:           row.Title,
+			PossibleAnswers: row.PossibleAnswers,
+			TrueAnswer:      row.TrueAnswer,
+			Exact:           row.Exact,
+			Similarity:      round(row.Similarity),
+		})
+	}
+	return candidates, nil
+}
+
+// Report lists pairs of duplicate questions in the bank, exact duplicates
+// first, with the total count. minSimilarity 0 uses the configured
+// threshold.
+func (s *Service) Report(ctx context.Context, minSimilarity float32, limit, offset int32) ([]Pair, int64, error) {
+	if minSimilarity == 0 {
+		minSimilarity = s.options.MinSimilarity
+	}
+	rows, err := s.store.ListDuplicateQuestionPairs(ctx, db.ListDuplicateQuestionPairsParams{
+		MinSimilarity: minSimilarity,
+		Limit:         limit,
+		Offset:        offset,
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list duplicate questions: %w", err)
+	}
+	total, err := s.store.CountDuplicateQuestionPairs(ctx, minSimilarity)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count duplicate questions: %w", err)
+	}
+
+	pairs := make([]Pair, 0, len(rows))
+	for _, row := range rows {
+		pairs = append(pairs, Pair{
+			QuestionID:         row.QuestionID,
+			ContentID:          row.ContentID,
+			Title:              row.Title,
+			DuplicateID:        row.DuplicateID,
+			DuplicateContentID: row.DuplicateContentID,
+			DuplicateTitle:     row.DuplicateTitle,
+			Exact:              row.Exact,
+			Similarity:         round(row.Similarity),
+		})
+	}
+	return pairs, total, nil
+}
+
+func round(similarity float32) float64 {
+	return math.Round(float64(similarity)*100) / 100
+}
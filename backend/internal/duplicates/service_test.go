@@ -0,0 +1,64 @@
+package duplicates
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+// fakeStore records the parameters of duplicate lookups
+type fakeStore struct {
+	db.Querier
+	similar  db.FindSimilarQuestionsParams
+	minPairs float32
+}
+
+func (s *fakeStore) FindSimilarQuestions(_ context.Context, arg db.FindSimilarQuestionsParams) ([]db.FindSimilarQuestionsRow, error) {
+	s.similar = arg
+	return []db.FindSimilarQuestionsRow{
+		{QuestionID: 4, Title: "The report ___ by Friday.", Exact: true, Similarity: 1},
+		{QuestionID: 9, Title: "The reports ___ by Friday.", Similarity: 0.8235294},
+	}, nil
+}
+
+func (s *fakeStore) ListDuplicateQuestionPairs(_ context.Context, arg db.ListDuplicateQuestionPairsParams) ([]db.ListDuplicateQuestionPairsRow, error) {
+	s.minPairs = arg.MinSimilarity
+	return []db.ListDuplicateQuestionPairsRow{{QuestionID: 4, DuplicateID: 9, Similarity: 0.8235294}}, nil
+}
+
+func (s *fakeStore) CountDuplicateQuestionPairs(_ context.Context, minSimilarity float32) (int64, error) {
+	return 1, nil
+}
+
+func TestCandidates(t *testing.T) {
+	store := &fakeStore{}
+	service := NewService(store, Options{MinSimilarity: 0.6, MaxCandidates: 5})
+
+	candidates, err := service.Candidates(context.Background(), "The report ___ by Friday.", nil, 0)
+	require.NoError(t, err)
+	assert.Equal(t, float32(0.6), store.similar.MinSimilarity)
+	assert.Equal(t, int32(5), store.similar.MaxResults)
+	assert.NotNil(t, store.similar.PossibleAnswers)
+	require.Len(t, candidates, 2)
+	assert.True(t, candidates[0].Exact)
+	assert.Equal(t, 0.82, candidates[1].Similarity)
+}
+
+func TestReport(t *testing.T) {
+	store := &fakeStore{}
+	service := NewService(store, Options{MinSimilarity: 0.6, MaxCandidates: 5})
+
+	pairs, total, err := service.Report(context.Background(), 0, 20, 0)
+	require.NoError(t, err)
+	assert.Equal(t, float32(0.6), store.minPairs)
+	assert.Equal(t, int64(1), total)
+	require.Len(t, pairs, 1)
+	assert.Equal(t, int32(9), pairs[0].DuplicateID)
+
+	_, _, err = service.Report(context.Background(), 0.9, 20, 0)
+	require.NoError(t, err)
+	assert.Equal(t, float32(0.9), store.minPairs)
+}
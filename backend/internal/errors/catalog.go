@@ -0,0 +1,73 @@
+package errors
+
+// CatalogEntry documents an error code of API error responses
+type CatalogEntry struct {
+	Code        ErrorCode     `json:"code" example:"NOT_FOUND"`
+	Status      int           `json:"status" example:"404"`
+	Category    ErrorCategory `json:"category" swaggertype:"string" example:"CLIENT"`
+	Severity    ErrorSeverity `json:"severity" swaggertype:"string" example:"LOW"`
+	Description string        `json:"description" example:"The resource does not exist or is not visible to the user"`
+}
+
+// descriptions tell clients what each error code means. Every ErrorCode
+// must be listed; the catalog is served to clients and checked against the
+// swagger document.
+var descriptions = []struct {
+	Code        ErrorCode
+	Description string
+}{
+	{ErrCodeUnauthorized, "The request has no valid credentials"},
+	{ErrCodeForbidden, "The user lacks the permission the request needs"},
+	{ErrCodeInvalidCredentials, "The email or password is wrong"},
+	{ErrCodeTokenExpired, "The access token expired; refresh it and retry"},
+	{ErrCodeTokenInvalid, "The access token is malformed or was revoked"},
+	{ErrCodeAccountLocked, "The account is locked after repeated failed sign-ins"},
+	{ErrCodeValidationFailed, "Fields of the request failed validation; error.fields names them"},
+	{ErrCodeInvalidInput, "The request is malformed or has invalid parameters"},
+	{ErrCodeMissingField, "A required field is missing"},
+	{ErrCodeInvalidFormat, "A field does not have the expected format"},
+	{ErrCodeNotFound, "The resource does not exist or is not visible to the user"},
+	{ErrCodeAlreadyExists, "A resource with the same unique values exists"},
+	{ErrCodeConflict, "The request conflicts with the current state of the resource"},
+	{ErrCodeRangeNotSatisfiable, "The requested byte range is outside the file"},
+	{ErrCodePayloadTooLarge, "The request body or upload is too large"},
+	{ErrCodeDatabaseError, "The database failed to run the request"},
+	{ErrCodeConnectionFailed, "The database could not be reached"},
+	{ErrCodeTransactionFailed, "The changes could not be committed and were rolled back"},
+	{ErrCodeConstraintViolation, "The changes break a database constraint"},
+	{ErrCodeExternalService, "A service the request depends on failed"},
+	{ErrCodeServiceUnavailable, "The service is temporarily unavailable; retry later"},
+	{ErrCodeTimeout, "The request took too long"},
+	{ErrCodeBusinessLogic, "The request breaks a rule of the application"},
+	{ErrCodeInsufficientData, "There is not enough data to answer the request yet"},
+	{ErrCodeInvalidOperation, "The operation is not allowed in the current state"},
+	{ErrCodeInternalServer, "An unexpected error occurred"},
+	{ErrCodeFileSystem, "A file could not be read or written"},
+	{ErrCodeMemoryLimit, "The request needs more memory than allowed"},
+	{ErrCodeRateLimited, "Too many requests; retry after the Retry-After header"},
+}
+
+// Catalog lists every error code with its HTTP status, category, severity
+// and meaning
+func Catalog() []CatalogEntry {
+	entries := make([]CatalogEntry, 0, len(descriptions))
+	for _, d := range descriptions {
+		entries = append(entries, CatalogEntry{
+			Code:        d.Code,
+			Status:      d.Code.GetHTTPStatus(),
+			Category:    d.Code.GetCategory(),
+			Severity:    d.Code.GetSeverity(),
+			Description: d.Description,
+		})
+	}
+	return entries
+}
+
+// Codes lists every error code in catalog order
+func Codes() []ErrorCode {
+	codes := make([]ErrorCode, 0, len(descriptions))
+	for _, d := range descriptions {
+		codes = append(codes, d.Code)
+	}
+	return codes
+}
@@ -16,6 +16,7 @@ const (
 	ErrCodeInvalidCredentials ErrorCode = "INVALID_CREDENTIALS"
 	ErrCodeTokenExpired       ErrorCode = "TOKEN_EXPIRED"
 	ErrCodeTokenInvalid       ErrorCode = "TOKEN_INVALID"
+	ErrCodeAccountLocked      ErrorCode = "ACCOUNT_LOCKED"
 
 	// Validation errors
 	ErrCodeValidationFailed ErrorCode = "VALIDATION_FAILED"
@@ -24,9 +25,11 @@ const (
 	ErrCodeInvalidFormat    ErrorCode = "INVALID_FORMAT"
 
 	// Resource errors
-	ErrCodeNotFound      ErrorCode = "NOT_FOUND"
-	ErrCodeAlreadyExists ErrorCode = "ALREADY_EXISTS"
-	ErrCodeConflict      ErrorCode = "CONFLICT"
+	ErrCodeNotFound            ErrorCode = "NOT_FOUND"
+	ErrCodeAlreadyExists       ErrorCode = "ALREADY_EXISTS"
+	ErrCodeConflict            ErrorCode = "CONFLICT"
+	ErrCodeRangeNotSatisfiable ErrorCode = "RANGE_NOT_SATISFIABLE"
+	ErrCodePayloadTooLarge     ErrorCode = "PAYLOAD_TOO_LARGE"
 
 	// Database errors
 	ErrCodeDatabaseError       ErrorCode = "DATABASE_ERROR"
@@ -158,12 +161,18 @@ func (code ErrorCode) GetHTTPStatus() int {
 		return http.StatusUnauthorized
 	case ErrCodeForbidden:
 		return http.StatusForbidden
+	case ErrCodeAccountLocked:
+		return http.StatusLocked
 	case ErrCodeValidationFailed, ErrCodeInvalidInput, ErrCodeMissingField, ErrCodeInvalidFormat:
 		return http.StatusBadRequest
 	case ErrCodeNotFound:
 		return http.StatusNotFound
 	case ErrCodeAlreadyExists, ErrCodeConflict:
 		return http.StatusConflict
+	case ErrCodeRangeNotSatisfiable:
+		return http.StatusRequestedRangeNotSatisfiable
+	case ErrCodePayloadTooLarge:
+		return http.StatusRequestEntityTooLarge
 	case ErrCodeServiceUnavailable:
 		return http.StatusServiceUnavailable
 	case ErrCodeTimeout:
@@ -182,9 +191,9 @@ func (code ErrorCode) GetHTTPStatus() int {
 func (code ErrorCode) GetSeverity() ErrorSeverity {
 	switch code {
 	case ErrCodeValidationFailed, ErrCodeInvalidInput, ErrCodeMissingField, ErrCodeInvalidFormat,
-		ErrCodeNotFound, ErrCodeUnauthorized, ErrCodeForbidden:
+		ErrCodeNotFound, ErrCodeRangeNotSatisfiable, ErrCodePayloadTooLarge, ErrCodeUnauthorized, ErrCodeForbidden:
 		return SeverityLow
-	case ErrCodeAlreadyExists, ErrCodeConflict, ErrCodeInvalidCredentials, ErrCodeTokenExpired:
+	case ErrCodeAlreadyExists, ErrCodeConflict, ErrCodeInvalidCredentials, ErrCodeTokenExpired, ErrCodeAccountLocked:
 		return SeverityMedium
 	case ErrCodeDatabaseError, ErrCodeConnectionFailed, ErrCodeExternalService, ErrCodeTimeout:
 		return SeverityHigh
@@ -200,7 +209,8 @@ func (code ErrorCode) GetCategory() ErrorCategory {
 	switch code {
 	case ErrCodeValidationFailed, ErrCodeInvalidInput, ErrCodeMissingField, ErrCodeInvalidFormat:
 		return CategoryValidation
-	case ErrCodeUnauthorized, ErrCodeForbidden, ErrCodeInvalidCredentials, ErrCodeTokenExpired, ErrCodeTokenInvalid:
+	case ErrCodeUnauthorized, ErrCodeForbidden, ErrCodeInvalidCredentials, ErrCodeTokenExpired, ErrCodeTokenInvalid,
+		ErrCodeAccountLocked:
 		return CategoryAuth
 	case ErrCodeDatabaseError, ErrCodeConnectionFailed, ErrCodeTransactionFailed, ErrCodeConstraintViolation:
 		return CategoryDatabase
@@ -208,7 +218,8 @@ func (code ErrorCode) GetCategory() ErrorCategory {
 		return CategoryExternal
 	case ErrCodeBusinessLogic, ErrCodeInsufficientData, ErrCodeInvalidOperation:
 		return CategoryBusiness
-	case ErrCodeNotFound, ErrCodeAlreadyExists, ErrCodeConflict, ErrCodeRateLimited:
+	case ErrCodeNotFound, ErrCodeAlreadyExists, ErrCodeConflict, ErrCodeRangeNotSatisfiable, ErrCodePayloadTooLarge,
+		ErrCodeRateLimited:
 		return CategoryClient
 	default:
 		return CategoryServer
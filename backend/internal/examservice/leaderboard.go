@@ -0,0 +1,231 @@
+package examservice
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/logger"
+)
+
+// EventLeaderboardUpdated is the WebSocket message published to
+// LeaderboardTopic after an exam's leaderboard was refreshed
+const EventLeaderboardUpdated = "exam.leaderboard_updated"
+
+const (
+	// maxLeaderboardPages bounds the pages of an exam's leaderboard that
+	// are refreshed; pages read after that stay cached until they expire
+	maxLeaderboardPages = 20
+	// leaderboardQueueSize bounds the exams waiting for a refresh
+	leaderboardQueueSize = 256
+	// leaderboardRefreshTimeout bounds the refresh of one exam
+	leaderboardRefreshTimeout = 30 * time.Second
+)
+
+// firstLeaderboardPage is the page clients read by default, always refreshed
+var firstLeaderboardPage = pageBounds{Limit: 10, Offset: 0}
+
+// pageBounds are the limit and offset a leaderboard page was read with
+type pageBounds struct {
+	Limit  int32 `json:"limit"`
+	Offset int32 `json:"offset"`
+}
+
+// LeaderboardTopic is the WebSocket topic of an exam's leaderboard updates
+func LeaderboardTopic(examID int32) string {
+	return fmt.Sprintf("exam.%d.leaderboard", examID)
+}
+
+// LeaderboardPage is a page of an exam's leaderboard
+type LeaderboardPage struct {
+	Limit   int32              `json:"limit"`
+	Offset  int32              `json:"offset"`
+	Entries []LeaderboardEntry `json:"entries"`
+}
+
+// LeaderboardUpdate is the data of EventLeaderboardUpdated
+type LeaderboardUpdate struct {
+	ExamID      int32             `json:"exam_id"`
+	Pages       []LeaderboardPage `json:"pages"`
+	RefreshedAt time.Time         `json:"refreshed_at"`
+}
+
+// leaderboardPagesKey holds the pages of an exam's leaderboard read recently
+func leaderboardPagesKey(examID int32) string {
+	return fmt.Sprintf("exams:leaderboard_pages:%d", examID)
+}
+
+// leaderboardPage reads a page of an exam's leaderboard and caches it under
+// a version
+func (s *service) leaderboardPage(ctx context.Context, examID int32, version int64, limit, offset int32) ([]LeaderboardEntry, error) {
+	rows, err := s.store.GetExamLeaderboard(ctx, db.GetExamLeaderboardParams{
+		ExamID: examID,
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]LeaderboardEntry, len(rows))
+	for i, row := range rows {
+		entries[i] = LeaderboardEntry{
+			UserID:   row.UserID,
+			Username: row.Username,
+			Score:    row.Score.String,
+			EndTime:  row.EndTime.Time,
+			Rank:     row.Rank,
+		}
+	}
+	s.cache(ctx, leaderboardKey(examID, version, limit, offset), entries, leaderboardTTL)
+	return entries, nil
+}
+
+// rememberLeaderboardPage records a page read from an exam's leaderboard,
+// so that it is refreshed when an attempt is completed
+func (s *service) rememberLeaderboardPage(ctx context.Context, examID, limit, offset int32) {
+	var pages []pageBounds
+	s.cached(ctx, leaderboardPagesKey(examID), &pages)
+	page := pageBounds{Limit: limit, Offset: offset}
+	if len(pages) >= maxLeaderboardPages || slices.Contains(pages, page) {
+		return
+	}
+	s.cache(ctx, leaderboardPagesKey(examID), append(pages, page), leaderboardTTL)
+}
+
+func (s *service) RefreshLeaderboard(ctx context.Context, examID int32) ([]LeaderboardPage, error) {
+	if _, err := s.Exam(ctx, examID); err != nil {
+		return nil, err
+	}
+	var remembered []pageBounds
+	s.cached(ctx, leaderboardPagesKey(examID), &remembered)
+	bounds := []pageBounds{firstLeaderboardPage}
+	for _, page := range remembered {
+		if !slices.Contains(bounds, page) {
+			bounds = append(bounds, page)
+		}
+	}
+
+	// The pages are cached under a new version before readers switch to
+	// it, so they never see a mix of old and new pages
+	version := s.now().UnixNano()
+	pages := make([]LeaderboardPage, 0, len(bounds))
+	for _, page := range bounds {
+		entries, err := s.leaderboardPage(ctx, examID, version, page.Limit, page.Offset)
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, LeaderboardPage{Limit: page.Limit, Offset: page.Offset, Entries: entries})
+	}
+	s.cache(ctx, leaderboardVersionKey(examID), version, leaderboardTTL)
+	if len(remembered) > 0 {
+		// Pages still cached are not read again, so keep remembering them
+		s.cache(ctx, leaderboardPagesKey(examID), remembered, leaderboardTTL)
+	}
+	return pages, nil
+}
+
+// Publisher delivers a message to the subscribers of a topic.
+// *websocket.Manager satisfies it.
+type Publisher interface {
+	PublishToTopic(topic string, messageType string, data interface{}) error
+}
+
+// LeaderboardRefresher refreshes the cached leaderboard of an exam as soon
+// as one of its attempts is completed and pushes the new pages to the
+// exam's LeaderboardTopic. Completions of an exam waiting for a refresh are
+// handled by that one refresh.
+type LeaderboardRefresher struct {
+	publisher Publisher
+	queue     chan int32
+
+	mu      sync.Mutex
+	pending map[int32]bool // Exams in the queue
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// NewLeaderboardRefresher creates a refresher publishing to publisher.
+// Subscribe its Completed method as Options.AttemptCompleted.
+func NewLeaderboardRefresher(publisher Publisher) *LeaderboardRefresher {
+	return &LeaderboardRefresher{
+		publisher: publisher,
+		queue:     make(chan int32, leaderboardQueueSize),
+		pending:   make(map[int32]bool),
+	}
+}
+
+// Completed queues a refresh of the leaderboard of a completed attempt's
+// exam. When the queue is full the leaderboard is refreshed when its cache
+// expires. Part practice is not on the leaderboard.
+func (r *LeaderboardRefresher) Completed(attempt db.ExamAttempt) {
+	if attempt.PartID.Valid {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.pending[attempt.ExamID] {
+		return
+	}
+	select {
+	case r.queue <- attempt.ExamID:
+		r.pending[attempt.ExamID] = true
+	default:
+		logger.Warn("Leaderboard refresh queue is full, exam %d is refreshed when its cache expires", attempt.ExamID)
+	}
+}
+
+// Start refreshes queued leaderboards with service in the background
+func (r *LeaderboardRefresher) Start(service Service) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stop != nil {
+		return
+	}
+	r.stop = make(chan struct{})
+	r.done = make(chan struct{})
+
+	go func(stop, done chan struct{}) {
+		defer close(done)
+		for {
+			select {
+			case examID := <-r.queue:
+				r.mu.Lock()
+				delete(r.pending, examID)
+				r.mu.Unlock()
+				r.refresh(service, examID)
+			case <-stop:
+				return
+			}
+		}
+	}(r.stop, r.done)
+}
+
+// Stop stops refreshing, after the refresh in progress
+func (r *LeaderboardRefresher) Stop() {
+	r.mu.Lock()
+	stop, done := r.stop, r.done
+	r.stop, r.done = nil, nil
+	r.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
+
+func (r *LeaderboardRefresher) refresh(service Service, examID int32) {
+	ctx, cancel := context.WithTimeout(context.Background(), leaderboardRefreshTimeout)
+	defer cancel()
+	pages, err := service.RefreshLeaderboard(ctx, examID)
+	if err != nil {
+		logger.Warn("Failed to refresh the leaderboard of exam %d: %v", examID, err)
+		return
+	}
+	update := LeaderboardUpdate{ExamID: examID, Pages: pages, RefreshedAt: time.Now()}
+	if err := r.publisher.PublishToTopic(LeaderboardTopic(examID), EventLeaderboardUpdated, update); err != nil {
+		logger.Warn("Failed to publish the leaderboard of exam %d: %v", examID, err)
+	}
+}
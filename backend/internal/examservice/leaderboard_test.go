@@ -0,0 +1,113 @@
+package examservice
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+// leaderboardStore ranks the completed attempts of the fake store
+type leaderboardStore struct {
+	*fakeStore
+	reads int
+}
+
+func (s *leaderboardStore) GetExam(ctx context.Context, examID int32) (db.Exam, error) {
+	if examID != 3 {
+		return db.Exam{}, sql.ErrNoRows
+	}
+	return db.Exam{ExamID: examID}, nil
+}
+
+func (s *leaderboardStore) CompleteExamAttempt(ctx context.Context, arg db.CompleteExamAttemptParams) (db.ExamAttempt, error) {
+	attempt := s.attempts[arg.AttemptID]
+	attempt.Status = db.ExamStatusEnumCompleted
+	attempt.Score = arg.Score
+	s.attempts[arg.AttemptID] = attempt
+	return attempt, nil
+}
+
+func (s *leaderboardStore) GetExamLeaderboard(ctx context.Context, arg db.GetExamLeaderboardParams) ([]db.GetExamLeaderboardRow, error) {
+	s.reads++
+	var rows []db.GetExamLeaderboardRow
+	for _, id := range []int32{1, 2} {
+		if attempt := s.attempts[id]; attempt.ExamID == arg.ExamID && attempt.Status == db.ExamStatusEnumCompleted {
+			rows = append(rows, db.GetExamLeaderboardRow{UserID: attempt.UserID, Score: attempt.Score, Rank: int64(len(rows) + 1)})
+		}
+	}
+	return rows, nil
+}
+
+// topicPublisher records the messages published to topics
+type topicPublisher chan LeaderboardUpdate
+
+func (p topicPublisher) PublishToTopic(topic string, messageType string, data interface{}) error {
+	if topic == "exam.3.leaderboard" && messageType == EventLeaderboardUpdated {
+		p <- data.(LeaderboardUpdate)
+	}
+	return nil
+}
+
+func TestRefreshLeaderboardRecachesReadPages(t *testing.T) {
+	store := &leaderboardStore{fakeStore: newFakeStore()}
+	service := NewService(store, Options{Cache: mapCache{}})
+	ctx := context.Background()
+
+	entries, err := service.Leaderboard(ctx, 3, 5, 0)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	store.attempts[1] = db.ExamAttempt{AttemptID: 1, UserID: 7, ExamID: 3, Status: db.ExamStatusEnumCompleted,
+		Score: sql.NullString{String: "900", Valid: true}}
+	pages, err := service.RefreshLeaderboard(ctx, 3)
+	require.NoError(t, err)
+	// The first page and the page read before
+	require.Len(t, pages, 2)
+	assert.Equal(t, int32(10), pages[0].Limit)
+	assert.Equal(t, int32(5), pages[1].Limit)
+	assert.Len(t, pages[1].Entries, 2)
+
+	// Reads are answered from the refreshed pages
+	reads := store.reads
+	entries, err = service.Leaderboard(ctx, 3, 5, 0)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, reads, store.reads)
+
+	_, err = service.RefreshLeaderboard(ctx, 4)
+	assert.ErrorIs(t, err, ErrExamNotFound)
+}
+
+func TestLeaderboardRefresherPublishesCompletions(t *testing.T) {
+	store := &leaderboardStore{fakeStore: newFakeStore()}
+	published := make(topicPublisher, 1)
+	refresher := NewLeaderboardRefresher(published)
+	service := NewService(store, Options{Cache: mapCache{}, AttemptCompleted: refresher.Completed})
+	refresher.Start(service)
+	defer refresher.Stop()
+
+	_, err := service.CompleteAttempt(context.Background(), 7, 1, "900")
+	require.NoError(t, err)
+
+	select {
+	case update := <-published:
+		assert.Equal(t, int32(3), update.ExamID)
+		require.Len(t, update.Pages, 1)
+		assert.Len(t, update.Pages[0].Entries, 2)
+	case <-time.After(5 * time.Second):
+		t.Fatal("leaderboard update not published")
+	}
+}
+
+func TestLeaderboardRefresherSkipsPartPractice(t *testing.T) {
+	refresher := NewLeaderboardRefresher(make(topicPublisher, 1))
+	refresher.Completed(db.ExamAttempt{ExamID: 3, PartID: sql.NullInt32{Int32: 5, Valid: true}})
+	assert.Empty(t, refresher.queue)
+	refresher.Completed(db.ExamAttempt{ExamID: 3})
+	assert.Len(t, refresher.queue, 1)
+}
@@ -0,0 +1,967 @@
+// Package examservice holds the rules for exam attempts and the answers
+// given in them: who may read or change an attempt, which status changes
+// are allowed, how answers are checked and what is cached. The HTTP
+// handlers only translate requests and errors, so the rules can be reused
+// by other transports and tested without a router.
+package examservice
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/toeic-app/internal/choices"
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/logger"
+)
+
+var (
+	ErrExamNotFound     = errors.New("exam not found")
+	ErrAttemptNotFound  = errors.New("exam attempt not found")
+	ErrActiveAttempt    = errors.New("user already has an active attempt for this exam")
+	ErrAttemptClosed    = errors.New("exam attempt is no longer in progress")
+	ErrInvalidStatus    = errors.New("invalid exam attempt status")
+	ErrNoUpdate         = errors.New("no update fields provided")
+	ErrQuestionNotFound = errors.New("question not found")
+	ErrAnswerNotFound   = errors.New("user answer not found")
+	ErrAnswerExists     = errors.New("answer already exists for this question")
+	ErrInvalidOption    = errors.New("selected answer is not an option of the question")
+	ErrPartNotFound     = errors.New("part not found")
+	ErrPartEmpty        = errors.New("part has no questions to practice")
+	ErrNotInPart        = errors.New("question is not in the practiced part")
+	ErrAnswerRevealed   = errors.New("answer cannot change once its transcript was revealed")
+)
+
+// Scales attempts are scored on
+const (
+	FullTestMaxScore = 990 // TOEIC Listening & Reading score
+	PracticeMaxScore = 100 // Percentage of correct answers
+)
+
+// How long results are cached
+const (
+	attemptsTTL    = 5 * time.Minute
+	statsTTL       = 10 * time.Minute
+	leaderboardTTL = 30 * time.Minute
+	answerTTL      = 30 * time.Minute
+	answersTTL     = 10 * time.Minute
+	scoreTTL       = 15 * time.Minute
+)
+
+// Errors recorded for answers a bulk submission skipped
+const (
+	FailureAnswerExists  = "answer_already_exists"
+	FailureCheckExisting = "failed_to_check_existing_answer"
+	FailureNoQuestion    = "question_not_found"
+	FailureCreate        = "failed_to_create_user_answer"
+	FailureInvalidOption = "invalid_option"
+	FailureNotInPart     = "question_not_in_part"
+)
+
+// Cache stores results as JSON. *cache.ServiceCache satisfies it.
+type Cache interface {
+	Get(ctx context.Context, key string, result interface{}) error
+	Set(ctx context.Context, key string, data interface{}, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+}
+
+// Service manages exam attempts and their answers. Methods taking a user
+// ID only act on that user's attempts; privileged callers, such as
+// administrators, are marked by the caller.
+type Service interface {
+	// Exam returns an exam, or ErrExamNotFound
+	Exam(ctx context.Context, examID int32) (db.Exam, error)
+	// StartAttempt starts an attempt. If the user already has one in
+	// progress for the exam, it is returned with ErrActiveAttempt.
+	StartAttempt(ctx context.Context, userID, examID int32) (db.ExamAttempt, error)
+	// Part returns a part of an exam, or ErrPartNotFound
+	Part(ctx context.Context, partID int32) (db.Part, error)
+	// StartPartPractice starts an attempt at a single part of an exam,
+	// lasting timeLimit minutes or, when zero, the part's share of the
+	// exam's time limit. Only questions of the part may be answered, the
+	// attempt is scored on PracticeMaxScore and it is left out of the
+	// leaderboard and statistics. If the user already practices the part,
+	// that attempt is returned with ErrActiveAttempt.
+	StartPartPractice(ctx context.Context, userID, partID, timeLimit int32) (db.ExamAttempt, error)
+	// Attempt returns any user's attempt, for callers that checked they
+	// may read it
+	Attempt(ctx context.Context, attemptID int32) (db.ExamAttempt, error)
+	// OwnedAttempt returns an attempt of the user, or ErrAttemptNotFound
+	OwnedAttempt(ctx context.Context, userID, attemptID int32) (db.ExamAttempt, error)
+	ListAttempts(ctx context.Context, userID, limit, offset int32) ([]db.ExamAttempt, error)
+	UpdateAttempt(ctx context.Context, userID, attemptID int32, update AttemptUpdate) (db.ExamAttempt, error)
+	// CompleteAttempt completes an attempt with a score, which is calculated
+	// from the answers when empty and always for part practice
+	CompleteAttempt(ctx context.Context, userID, attemptID int32, score string) (db.ExamAttempt, error)
+	AbandonAttempt(ctx context.Context, userID, attemptID int32) (db.ExamAttempt, error)
+	// DeleteAttempt deletes an attempt with its answers. Unprivileged users
+	// may only delete their own attempts while they are in progress.
+	DeleteAttempt(ctx context.Context, userID, attemptID int32, privileged bool) error
+	Stats(ctx context.Context, userID int32) (Stats, error)
+	Leaderboard(ctx context.Context, examID, limit, offset int32) ([]LeaderboardEntry, error)
+	// RefreshLeaderboard recomputes and caches the pages of an exam's
+	// leaderboard that were read recently, and the first page, returning them
+	RefreshLeaderboard(ctx context.Context, examID int32) ([]LeaderboardPage, error)
+
+	// SubmitAnswer checks and stores the answer to one question
+	SubmitAnswer(ctx context.Context, userID int32, submission Submission) (db.UserAnswer, error)
+	// SubmitAnswers stores the answers to several questions of an attempt,
+	// skipping those that cannot be stored
+	SubmitAnswers(ctx context.Context, userID, attemptID int32, submissions []Submission) (BulkResult, error)
+	Answer(ctx context.Context, userID, answerID int32) (db.UserAnswer, error)
+	// UpdateAnswer changes the option selected in an answer, given by key
+	// or, by older clients, by text. Answers of a part practice cannot
+	// change once the question's transcript was revealed.
+	UpdateAnswer(ctx context.Context, userID, answerID int32, selectedKey, selectedAnswer string) (db.UserAnswer, error)
+	// DeleteAnswer deletes an answer. Unprivileged users may only delete
+	// answers of their own attempts while they are in progress, and not
+	// those whose transcript was revealed.
+	DeleteAnswer(ctx context.Context, userID, answerID int32, privileged bool) error
+	// AttemptAnswers lists the answers of any user's attempt with their
+	// questions, for callers that checked they may read it
+	AttemptAnswers(ctx context.Context, attemptID int32) ([]db.ListUserAnswersByAttemptWithQuestionsRow, error)
+	// Score scores the answers of an attempt of the user
+	Score(ctx context.Context, userID, attemptID int32) (Score, error)
+
+	// RegradeQuestion checks the past answers to a question again after
+	// its correct answer was fixed, returning the answers that changed.
+	// Rescore their attempts afterwards.
+	RegradeQuestion(ctx context.Context, questionID int32) ([]db.RegradeUserAnswersRow, error)
+	// RescoreAttempt recalculates the score of a completed attempt from
+	// its answers and clears the cached results it appears in
+	RescoreAttempt(ctx context.Context, attemptID int32) (Rescore, error)
+}
+
+// Options configures the service. Both fields are optional.
+type Options struct {
+	Cache Cache
+	// UserChanged is called in the background after a user's attempts or
+	// answers changed, to clear other caches holding their progress
+	UserChanged func(userID int32)
+	// AttemptCompleted is called in the background after an attempt was
+	// completed or its score changed, to refresh the exam's leaderboard
+	AttemptCompleted func(attempt db.ExamAttempt)
+}
+
+// AttemptUpdate changes the score, which completes the attempt, or else
+// the status of an attempt. Part practice is scored from its answers
+// whatever the score given.
+type AttemptUpdate struct {
+	Status *string
+	Score  *string
+}
+
+// Submission is the option selected for a question, by key or, from
+// older clients, by its text
+type Submission struct {
+	AttemptID      int32
+	QuestionID     int32
+	SelectedKey    string
+	SelectedAnswer string
+}
+
+// FailedSubmission is an answer a bulk submission skipped, with one of
+// the Failure codes
+type FailedSubmission struct {
+	QuestionID     int32
+	SelectedKey    string
+	SelectedAnswer string
+	Error          string
+}
+
+// BulkResult is the outcome of a bulk submission
+type BulkResult struct {
+	Answers []db.UserAnswer
+	Failed  []FailedSubmission
+	Correct int32
+}
+
+// Score is the scaled score of the answers of an attempt
+type Score struct {
+	TotalQuestions  int32   `json:"total_questions"`
+	CorrectAnswers  int32   `json:"correct_answers"`
+	CalculatedScore float64 `json:"calculated_score"`
+	MaxScore        int32   `json:"max_score"`
+}
+
+// Stats summarizes the attempts of a user. Scores are nil until an
+// attempt was scored.
+type Stats struct {
+	TotalAttempts      int64    `json:"total_attempts"`
+	CompletedAttempts  int64    `json:"completed_attempts"`
+	InProgressAttempts int64    `json:"in_progress_attempts"`
+	AbandonedAttempts  int64    `json:"abandoned_attempts"`
+	AverageScore       *float64 `json:"average_score,omitempty"`
+	HighestScore       *float64 `json:"highest_score,omitempty"`
+	LowestScore        *float64 `json:"lowest_score,omitempty"`
+}
+
+// LeaderboardEntry is a ranked completed attempt
+type LeaderboardEntry struct {
+	UserID   int32     `json:"user_id"`
+	Username string    `json:"username"`
+	Score    string    `json:"score"`
+	EndTime  time.Time `json:"end_time"`
+	Rank     int64     `json:"rank"`
+}
+
+// Rescore is the outcome of rescoring an attempt. Scores are empty for
+// attempts that were not completed, which are not scored.
+type Rescore struct {
+	AttemptID int32  `json:"attempt_id"`
+	UserID    int32  `json:"user_id"`
+	ExamID    int32  `json:"exam_id"`
+	OldScore  string `json:"old_score,omitempty"`
+	NewScore  string `json:"new_score,omitempty"`
+}
+
+// MaxScore returns the highest score of an attempt: PracticeMaxScore for
+// part practice, else FullTestMaxScore
+func MaxScore(attempt db.ExamAttempt) int32 {
+	if attempt.PartID.Valid {
+		return PracticeMaxScore
+	}
+	return FullTestMaxScore
+}
+
+// Changed reports whether the score of the attempt changed
+func (r Rescore) Changed() bool {
+	old, updated := scoreValue(r.OldScore), scoreValue(r.NewScore)
+	if old == nil || updated == nil {
+		return r.OldScore != r.NewScore
+	}
+	return *old != *updated
+}
+
+type service struct {
+	store   db.Querier
+	options Options
+	now     func() time.Time
+}
+
+// NewService creates an exam attempt service
+func NewService(store db.Querier, options Options) Service {
+	return &service{store: store, options: options, now: time.Now}
+}
+
+// Cache keys
+func attemptsKey(userID, limit, offset int32) string {
+	return fmt.Sprintf("exams:attempts:%d:%d:%d", userID, limit, offset)
+}
+
+func statsKey(userID int32) string {
+	return fmt.Sprintf("exams:stats:%d", userID)
+}
+
+func leaderboardKey(examID int32, version int64, limit, offset int32) string {
+	return fmt.Sprintf("exams:leaderboard:%d:%d:%d:%d", examID, version, limit, offset)
+}
+
+// leaderboardVersionKey holds the version of an exam's cached leaderboard
+// pages; changing it makes every page stale at once
+func leaderboardVersionKey(examID int32) string {
+	return fmt.Sprintf("exams:leaderboard_version:%d", examID)
+}
+
+func answerKey(answerID int32) string {
+	return fmt.Sprintf("exams:answer:%d", answerID)
+}
+
+func answersKey(attemptID int32) string {
+	return fmt.Sprintf("exams:attempt_answers:%d", attemptID)
+}
+
+func scoreKey(attemptID int32) string {
+	return fmt.Sprintf("exams:attempt_score:%d", attemptID)
+}
+
+// cached reads key into result, reporting whether it was found
+func (s *service) cached(ctx context.Context, key string, result interface{}) bool {
+	return s.options.Cache != nil && s.options.Cache.Get(ctx, key, result) == nil
+}
+
+func (s *service) cache(ctx context.Context, key string, value interface{}, ttl time.Duration) {
+	if s.options.Cache == nil {
+		return
+	}
+	if err := s.options.Cache.Set(ctx, key, value, ttl); err != nil {
+		logger.Warn("Failed to cache %s: %v", key, err)
+	}
+}
+
+// forget removes cached results made stale by a change to a user's
+// attempts
+func (s *service) forget(ctx context.Context, userID int32, keys ...string) {
+	if s.options.Cache != nil {
+		for _, key := range append(keys, statsKey(userID)) {
+			if err := s.options.Cache.Delete(ctx, key); err != nil {
+				logger.Warn("Failed to clear cached %s: %v", key, err)
+			}
+		}
+	}
+	if s.options.UserChanged != nil {
+		go s.options.UserChanged(userID)
+	}
+}
+
+// completed tells the AttemptCompleted subscriber about a completed attempt
+func (s *service) completed(attempt db.ExamAttempt) {
+	if s.options.AttemptCompleted != nil {
+		go s.options.AttemptCompleted(attempt)
+	}
+}
+
+func (s *service) Exam(ctx context.Context, examID int32) (db.Exam, error) {
+	exam, err := s.store.GetExam(ctx, examID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return exam, ErrExamNotFound
+	}
+	return exam, err
+}
+
+func (s *service) StartAttempt(ctx context.Context, userID, examID int32) (db.ExamAttempt, error) {
+	if _, err := s.Exam(ctx, examID); err != nil {
+		return db.ExamAttempt{}, err
+	}
+	return s.startAttempt(ctx, db.CreateExamAttemptParams{UserID: userID, ExamID: examID})
+}
+
+// startAttempt creates an attempt unless the user has one in progress
+// for the same exam, or part of it
+func (s *service) startAttempt(ctx context.Context, arg db.CreateExamAttemptParams) (db.ExamAttempt, error) {
+	active, err := s.store.GetActiveExamAttempt(ctx, db.GetActiveExamAttemptParams{
+		UserID: arg.UserID,
+		ExamID: arg.ExamID,
+		PartID: arg.PartID,
+	})
+	if err == nil {
+		return active, ErrActiveAttempt
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return db.ExamAttempt{}, fmt.Errorf("failed to check active attempt: %w", err)
+	}
+
+	arg.StartTime = s.now()
+	arg.Status = db.ExamStatusEnumInProgress
+	attempt, err := s.store.CreateExamAttempt(ctx, arg)
+	if err != nil {
+		return attempt, err
+	}
+	s.forget(ctx, arg.UserID)
+	return attempt, nil
+}
+
+func (s *service) Part(ctx context.Context, partID int32) (db.Part, error) {
+	part, err := s.store.GetPart(ctx, partID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return part, ErrPartNotFound
+	}
+	return part, err
+}
+
+func (s *service) StartPartPractice(ctx context.Context, userID, partID, timeLimit int32) (db.ExamAttempt, error) {
+	part, err := s.Part(ctx, partID)
+	if err != nil {
+		return db.ExamAttempt{}, err
+	}
+	exam, err := s.Exam(ctx, part.ExamID)
+	if err != nil {
+		return db.ExamAttempt{}, err
+	}
+	counts, err := s.store.CountPartQuestions(ctx, db.CountPartQuestionsParams{PartID: partID, ExamID: exam.ExamID})
+	if err != nil {
+		return db.ExamAttempt{}, err
+	}
+	if counts.PartQuestions == 0 {
+		return db.ExamAttempt{}, ErrPartEmpty
+	}
+	if timeLimit <= 0 {
+		timeLimit = practiceTimeLimit(exam.TimeLimitMinutes, counts.PartQuestions, counts.ExamQuestions)
+	}
+	return s.startAttempt(ctx, db.CreateExamAttemptParams{
+		UserID:           userID,
+		ExamID:           exam.ExamID,
+		PartID:           sql.NullInt32{Int32: partID, Valid: true},
+		TimeLimitMinutes: sql.NullInt32{Int32: timeLimit, Valid: true},
+	})
+}
+
+// practiceTimeLimit gives a part the share of the exam's time limit its
+// questions have, rounded up to whole minutes
+func practiceTimeLimit(examMinutes, partQuestions, examQuestions int32) int32 {
+	if examQuestions <= 0 || partQuestions >= examQuestions {
+		return max(examMinutes, 1)
+	}
+	minutes := (int64(examMinutes)*int64(partQuestions) + int64(examQuestions) - 1) / int64(examQuestions)
+	return int32(max(minutes, 1))
+}
+
+// finalScore returns the score an attempt is completed with: the given
+// one, unless it is empty or the attempt is a part practice, which are
+// scored from their answers
+func (s *service) finalScore(ctx context.Context, attempt db.ExamAttempt, given string) (string, error) {
+	if given != "" && !attempt.PartID.Valid {
+		return given, nil
+	}
+	row, err := s.store.GetAttemptScore(ctx, db.GetAttemptScoreParams{
+		MaxScore:  MaxScore(attempt),
+		AttemptID: attempt.AttemptID,
+	})
+	if err != nil {
+		return "", err
+	}
+	return row.CalculatedScore, nil
+}
+
+func (s *service) Attempt(ctx context.Context, attemptID int32) (db.ExamAttempt, error) {
+	attempt, err := s.store.GetExamAttempt(ctx, attemptID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return attempt, ErrAttemptNotFound
+	}
+	return attempt, err
+}
+
+func (s *service) OwnedAttempt(ctx context.Context, userID, attemptID int32) (db.ExamAttempt, error) {
+	attempt, err := s.store.GetExamAttemptByUser(ctx, db.GetExamAttemptByUserParams{
+		AttemptID: attemptID,
+		UserID:    userID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return attempt, ErrAttemptNotFound
+	}
+	return attempt, err
+}
+
+func (s *service) ListAttempts(ctx context.Context, userID, limit, offset int32) ([]db.ExamAttempt, error) {
+	key := attemptsKey(userID, limit, offset)
+	var attempts []db.ExamAttempt
+	if s.cached(ctx, key, &attempts) {
+		return attempts, nil
+	}
+	attempts, err := s.store.ListExamAttemptsByUser(ctx, db.ListExamAttemptsByUserParams{
+		UserID: userID,
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.cache(ctx, key, attempts, attemptsTTL)
+	return attempts, nil
+}
+
+// parseStatus maps a client status to its enum value
+func parseStatus(status string) (db.ExamStatusEnum, error) {
+	switch status {
+	case "in_progress":
+		return db.ExamStatusEnumInProgress, nil
+	case "completed":
+		return db.ExamStatusEnumCompleted, nil
+	case "abandoned":
+		return db.ExamStatusEnumAbandoned, nil
+	}
+	return "", ErrInvalidStatus
+}
+
+func (s *service) UpdateAttempt(ctx context.Context, userID, attemptID int32, update AttemptUpdate) (db.ExamAttempt, error) {
+	if update.Score == nil && update.Status == nil {
+		return db.ExamAttempt{}, ErrNoUpdate
+	}
+	attempt, err := s.OwnedAttempt(ctx, userID, attemptID)
+	if err != nil {
+		return db.ExamAttempt{}, err
+	}
+
+	if update.Score != nil {
+		score, scoreErr := s.finalScore(ctx, attempt, *update.Score)
+		if scoreErr != nil {
+			return db.ExamAttempt{}, scoreErr
+		}
+		attempt, err = s.store.UpdateExamAttemptScore(ctx, db.UpdateExamAttemptScoreParams{
+			AttemptID: attemptID,
+			Score:     sql.NullString{String: score, Valid: true},
+		})
+	} else {
+		status, statusErr := parseStatus(*update.Status)
+		if statusErr != nil {
+			return db.ExamAttempt{}, statusErr
+		}
+		attempt, err = s.store.UpdateExamAttemptStatus(ctx, db.UpdateExamAttemptStatusParams{
+			AttemptID: attemptID,
+			Status:    status,
+		})
+	}
+	if err != nil {
+		return attempt, err
+	}
+	s.forget(ctx, userID)
+	if attempt.Status == db.ExamStatusEnumCompleted {
+		s.completed(attempt)
+	}
+	return attempt, nil
+}
+
+func (s *service) CompleteAttempt(ctx context.Context, userID, attemptID int32, score string) (db.ExamAttempt, error) {
+	attempt, err := s.OwnedAttempt(ctx, userID, attemptID)
+	if err != nil {
+		return db.ExamAttempt{}, err
+	}
+	if score, err = s.finalScore(ctx, attempt, score); err != nil {
+		return db.ExamAttempt{}, err
+	}
+	attempt, err = s.store.CompleteExamAttempt(ctx, db.CompleteExamAttemptParams{
+		AttemptID: attemptID,
+		Score:     sql.NullString{String: score, Valid: true},
+	})
+	if err != nil {
+		return attempt, err
+	}
+	s.forget(ctx, userID)
+	s.completed(attempt)
+	return attempt, nil
+}
+
+func (s *service) AbandonAttempt(ctx context.Context, userID, attemptID int32) (db.ExamAttempt, error) {
+	if _, err := s.OwnedAttempt(ctx, userID, attemptID); err != nil {
+		return db.ExamAttempt{}, err
+	}
+	attempt, err := s.store.AbandonExamAttempt(ctx, attemptID)
+	if err != nil {
+		return attempt, err
+	}
+	s.forget(ctx, userID)
+	return attempt, nil
+}
+
+func (s *service) DeleteAttempt(ctx context.Context, userID, attemptID int32, privileged bool) error {
+	if !privileged {
+		attempt, err := s.OwnedAttempt(ctx, userID, attemptID)
+		if err != nil {
+			return err
+		}
+		if attempt.Status != db.ExamStatusEnumInProgress {
+			return ErrAttemptClosed
+		}
+	}
+	if err := s.store.DeleteExamAttempt(ctx, attemptID); err != nil {
+		return err
+	}
+	s.forget(ctx, userID, answersKey(attemptID), scoreKey(attemptID))
+	return nil
+}
+
+// scoreValue reads a numeric aggregate, which is nil when no attempt was
+// scored
+func scoreValue(value interface{}) *float64 {
+	var score float64
+	var err error
+	switch v := value.(type) {
+	case float64:
+		score = v
+	case int64:
+		score = float64(v)
+	case []byte:
+		score, err = strconv.ParseFloat(string(v), 64)
+	case string:
+		score, err = strconv.ParseFloat(v, 64)
+	default:
+		return nil
+	}
+	if err != nil {
+		return nil
+	}
+	return &score
+}
+
+func (s *service) Stats(ctx context.Context, userID int32) (Stats, error) {
+	key := statsKey(userID)
+	var stats Stats
+	if s.cached(ctx, key, &stats) {
+		return stats, nil
+	}
+	row, err := s.store.GetExamAttemptStats(ctx, userID)
+	if err != nil {
+		return stats, err
+	}
+	stats = Stats{
+		TotalAttempts:      row.TotalAttempts,
+		CompletedAttempts:  row.CompletedAttempts,
+		InProgressAttempts: row.InProgressAttempts,
+		AbandonedAttempts:  row.AbandonedAttempts,
+		HighestScore:       scoreValue(row.HighestScore),
+		LowestScore:        scoreValue(row.LowestScore),
+	}
+	if stats.HighestScore != nil {
+		average := row.AverageScore
+		stats.AverageScore = &average
+	}
+	s.cache(ctx, key, stats, statsTTL)
+	return stats, nil
+}
+
+func (s *service) Leaderboard(ctx context.Context, examID, limit, offset int32) ([]LeaderboardEntry, error) {
+	if _, err := s.Exam(ctx, examID); err != nil {
+		return nil, err
+	}
+	var version int64
+	s.cached(ctx, leaderboardVersionKey(examID), &version)
+	var entries []LeaderboardEntry
+	if s.cached(ctx, leaderboardKey(examID, version, limit, offset), &entries) {
+		return entries, nil
+	}
+	s.rememberLeaderboardPage(ctx, examID, limit, offset)
+	return s.leaderboardPage(ctx, examID, version, limit, offset)
+}
+
+// checkAnswer returns the option of the question an answer selected, by
+// key or text, and whether it is the correct one
+func (s *service) checkAnswer(ctx context.Context, questionID int32, selectedKey, selectedAnswer string) (choices.Option, bool, error) {
+	question, err := s.store.GetQuestion(ctx, questionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return choices.Option{}, false, ErrQuestionNotFound
+	}
+	if err != nil {
+		return choices.Option{}, false, err
+	}
+	option, err := choices.Resolve(choices.Of(question), selectedKey, selectedAnswer)
+	if err != nil {
+		return option, false, ErrInvalidOption
+	}
+	return option, option.Key == choices.AnswerOf(question), nil
+}
+
+// createAnswer stores an answer to an attempt the caller checked belongs
+// to the user. Errors come with the Failure code a bulk submission records
+// for them, or none when the submission cannot go on.
+func (s *service) createAnswer(ctx context.Context, attempt db.ExamAttempt, submission Submission) (db.UserAnswer, string, error) {
+	_, err := s.store.GetUserAnswerByAttemptAndQuestion(ctx, db.GetUserAnswerByAttemptAndQuestionParams{
+		AttemptID:  submission.AttemptID,
+		QuestionID: submission.QuestionID,
+	})
+	if err == nil {
+		return db.UserAnswer{}, FailureAnswerExists, ErrAnswerExists
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return db.UserAnswer{}, FailureCheckExisting, fmt.Errorf("failed to check existing answer: %w", err)
+	}
+	if attempt.PartID.Valid {
+		partID, err := s.store.GetQuestionPartID(ctx, submission.QuestionID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.UserAnswer{}, FailureNoQuestion, ErrQuestionNotFound
+		}
+		if err != nil {
+			return db.UserAnswer{}, "", err
+		}
+		if partID != attempt.PartID.Int32 {
+			return db.UserAnswer{}, FailureNotInPart, ErrNotInPart
+		}
+	}
+
+	option, correct, err := s.checkAnswer(ctx, submission.QuestionID, submission.SelectedKey, submission.SelectedAnswer)
+	if errors.Is(err, ErrQuestionNotFound) {
+		return db.UserAnswer{}, FailureNoQuestion, err
+	}
+	if errors.Is(err, ErrInvalidOption) {
+		return db.UserAnswer{}, FailureInvalidOption, err
+	}
+	if err != nil {
+		return db.UserAnswer{}, "", err
+	}
+	answer, err := s.store.CreateUserAnswer(ctx, db.CreateUserAnswerParams{
+		AttemptID:      submission.AttemptID,
+		QuestionID:     submission.QuestionID,
+		SelectedAnswer: option.Text,
+		IsCorrect:      correct,
+		AnswerTime:     sql.NullTime{Time: s.now(), Valid: true},
+		SelectedKey:    option.Key,
+	})
+	if err != nil {
+		return answer, FailureCreate, err
+	}
+	return answer, "", nil
+}
+
+func (s *service) SubmitAnswer(ctx context.Context, userID int32, submission Submission) (db.UserAnswer, error) {
+	attempt, err := s.OwnedAttempt(ctx, userID, submission.AttemptID)
+	if err != nil {
+		return db.UserAnswer{}, err
+	}
+	answer, _, err := s.createAnswer(ctx, attempt, submission)
+	if err != nil {
+		return answer, err
+	}
+	s.forget(ctx, userID, answersKey(submission.AttemptID), scoreKey(submission.AttemptID))
+	return answer, nil
+}
+
+func (s *service) SubmitAnswers(ctx context.Context, userID, attemptID int32, submissions []Submission) (BulkResult, error) {
+	result := BulkResult{
+		Answers: make([]db.UserAnswer, 0, len(submissions)),
+		Failed:  make([]FailedSubmission, 0),
+	}
+	attempt, err := s.OwnedAttempt(ctx, userID, attemptID)
+	if err != nil {
+		return result, err
+	}
+
+	for _, submission := range submissions {
+		submission.AttemptID = attemptID
+		answer, failure, err := s.createAnswer(ctx, attempt, submission)
+		if err != nil {
+			if failure == "" {
+				return result, err
+			}
+			result.Failed = append(result.Failed, FailedSubmission{
+				QuestionID:     submission.QuestionID,
+				SelectedKey:    submission.SelectedKey,
+				SelectedAnswer: submission.SelectedAnswer,
+				Error:          failure,
+			})
+			continue
+		}
+		if answer.IsCorrect {
+			result.Correct++
+		}
+		result.Answers = append(result.Answers, answer)
+	}
+
+	if len(result.Answers) > 0 {
+		s.forget(ctx, userID, answersKey(attemptID), scoreKey(attemptID))
+	}
+	return result, nil
+}
+
+// ownedAnswer returns an answer with its attempt, if the attempt belongs
+// to the user
+func (s *service) ownedAnswer(ctx context.Context, userID, answerID int32) (db.UserAnswer, db.ExamAttempt, error) {
+	answer, err := s.store.GetUserAnswer(ctx, answerID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return answer, db.ExamAttempt{}, ErrAnswerNotFound
+	}
+	if err != nil {
+		return answer, db.ExamAttempt{}, err
+	}
+	attempt, err := s.OwnedAttempt(ctx, userID, answer.AttemptID)
+	if errors.Is(err, ErrAttemptNotFound) {
+		// Answers of other users' attempts are not revealed to exist
+		return answer, attempt, ErrAnswerNotFound
+	}
+	return answer, attempt, err
+}
+
+func (s *service) Answer(ctx context.Context, userID, answerID int32) (db.UserAnswer, error) {
+	key := answerKey(answerID)
+	var answer db.UserAnswer
+	if s.cached(ctx, key, &answer) {
+		// The cached answer still has to belong to the user
+		if _, err := s.OwnedAttempt(ctx, userID, answer.AttemptID); err != nil {
+			if errors.Is(err, ErrAttemptNotFound) {
+				return db.UserAnswer{}, ErrAnswerNotFound
+			}
+			return db.UserAnswer{}, err
+		}
+		return answer, nil
+	}
+	answer, _, err := s.ownedAnswer(ctx, userID, answerID)
+	if err != nil {
+		return answer, err
+	}
+	s.cache(ctx, key, answer, answerTTL)
+	return answer, nil
+}
+
+func (s *service) UpdateAnswer(ctx context.Context, userID, answerID int32, selectedKey, selectedAnswer string) (db.UserAnswer, error) {
+	answer, attempt, err := s.ownedAnswer(ctx, userID, answerID)
+	if err != nil {
+		return answer, err
+	}
+	if err := s.checkNotRevealed(ctx, attempt, answer); err != nil {
+		return answer, err
+	}
+	option, correct, err := s.checkAnswer(ctx, answer.QuestionID, selectedKey, selectedAnswer)
+	if err != nil {
+		return answer, err
+	}
+	updated, err := s.store.UpdateUserAnswer(ctx, db.UpdateUserAnswerParams{
+		UserAnswerID:   answerID,
+		SelectedAnswer: option.Text,
+		IsCorrect:      correct,
+		SelectedKey:    option.Key,
+	})
+	if err != nil {
+		return updated, err
+	}
+	s.forget(ctx, userID, answerKey(answerID), answersKey(answer.AttemptID), scoreKey(answer.AttemptID))
+	return updated, nil
+}
+
+func (s *service) DeleteAnswer(ctx context.Context, userID, answerID int32, privileged bool) error {
+	var answer db.UserAnswer
+	var err error
+	if privileged {
+		answer, err = s.store.GetUserAnswer(ctx, answerID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrAnswerNotFound
+		}
+	} else {
+		var attempt db.ExamAttempt
+		answer, attempt, err = s.ownedAnswer(ctx, userID, answerID)
+		if err == nil && attempt.Status != db.ExamStatusEnumInProgress {
+			return ErrAttemptClosed
+		}
+		if err == nil {
+			// Deleting would let the question be answered again
+			err = s.checkNotRevealed(ctx, attempt, answer)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := s.store.DeleteUserAnswer(ctx, answerID); err != nil {
+		return err
+	}
+	s.forget(ctx, userID, answerKey(answerID), answersKey(answer.AttemptID), scoreKey(answer.AttemptID))
+	return nil
+}
+
+// checkNotRevealed returns ErrAnswerRevealed when the transcript of the
+// answered question was revealed in the part practice
+func (s *service) checkNotRevealed(ctx context.Context, attempt db.ExamAttempt, answer db.UserAnswer) error {
+	if !attempt.PartID.Valid {
+		return nil
+	}
+	revealed, err := s.store.IsTranscriptRevealed(ctx, db.IsTranscriptRevealedParams{
+		AttemptID:  answer.AttemptID,
+		QuestionID: answer.QuestionID,
+	})
+	if err != nil {
+		return err
+	}
+	if revealed {
+		return ErrAnswerRevealed
+	}
+	return nil
+}
+
+func (s *service) AttemptAnswers(ctx context.Context, attemptID int32) ([]db.ListUserAnswersByAttemptWithQuestionsRow, error) {
+	key := answersKey(attemptID)
+	var answers []db.ListUserAnswersByAttemptWithQuestionsRow
+	if s.cached(ctx, key, &answers) {
+		return answers, nil
+	}
+	answers, err := s.store.ListUserAnswersByAttemptWithQuestions(ctx, attemptID)
+	if err != nil {
+		return nil, err
+	}
+	s.cache(ctx, key, answers, answersTTL)
+	return answers, nil
+}
+
+func (s *service) Score(ctx context.Context, userID, attemptID int32) (Score, error) {
+	attempt, err := s.OwnedAttempt(ctx, userID, attemptID)
+	if err != nil {
+		return Score{}, err
+	}
+	key := scoreKey(attemptID)
+	var score Score
+	if s.cached(ctx, key, &score) {
+		return score, nil
+	}
+
+	row, err := s.store.GetAttemptScore(ctx, db.GetAttemptScoreParams{
+		MaxScore:  MaxScore(attempt),
+		AttemptID: attemptID,
+	})
+	if err != nil {
+		return score, err
+	}
+	calculated, err := strconv.ParseFloat(row.CalculatedScore, 64)
+	if err != nil {
+		return score, fmt.Errorf("invalid calculated score %q: %w", row.CalculatedScore, err)
+	}
+	score = Score{
+		TotalQuestions:  int32(row.TotalQuestions),
+		CorrectAnswers:  int32(row.CorrectAnswers),
+		CalculatedScore: calculated,
+		MaxScore:        MaxScore(attempt),
+	}
+	s.cache(ctx, key, score, scoreTTL)
+	return score, nil
+}
+
+func (s *service) RegradeQuestion(ctx context.Context, questionID int32) ([]db.RegradeUserAnswersRow, error) {
+	question, err := s.store.GetQuestion(ctx, questionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrQuestionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	regraded, err := s.store.RegradeUserAnswers(ctx, db.RegradeUserAnswersParams{
+		QuestionID: questionID,
+		AnswerKey:  choices.AnswerOf(question),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if s.options.Cache != nil {
+		for _, answer := range regraded {
+			if err := s.options.Cache.Delete(ctx, answerKey(answer.UserAnswerID)); err != nil {
+				logger.Warn("Failed to clear cached answer %d: %v", answer.UserAnswerID, err)
+			}
+		}
+	}
+	return regraded, nil
+}
+
+func (s *service) RescoreAttempt(ctx context.Context, attemptID int32) (Rescore, error) {
+	attempt, err := s.Attempt(ctx, attemptID)
+	if err != nil {
+		return Rescore{}, err
+	}
+	rescore := Rescore{
+		AttemptID: attempt.AttemptID,
+		UserID:    attempt.UserID,
+		ExamID:    attempt.ExamID,
+		OldScore:  attempt.Score.String,
+		NewScore:  attempt.Score.String,
+	}
+
+	if attempt.Status == db.ExamStatusEnumCompleted && attempt.Score.Valid {
+		row, err := s.store.GetAttemptScore(ctx, db.GetAttemptScoreParams{
+			MaxScore:  MaxScore(attempt),
+			AttemptID: attemptID,
+		})
+		if err != nil {
+			return rescore, err
+		}
+		updated, err := s.store.RescoreExamAttempt(ctx, db.RescoreExamAttemptParams{
+			AttemptID: attemptID,
+			Score:     sql.NullString{String: row.CalculatedScore, Valid: true},
+		})
+		if err != nil {
+			return rescore, err
+		}
+		rescore.NewScore = updated.Score.String
+		if !attempt.PartID.Valid {
+			s.forgetLeaderboard(ctx, attempt.ExamID)
+		}
+		if rescore.Changed() {
+			s.completed(updated)
+		}
+	}
+
+	s.forget(ctx, attempt.UserID, answersKey(attemptID), scoreKey(attemptID))
+	return rescore, nil
+}
+
+// forgetLeaderboard makes every cached page of an exam's leaderboard stale.
+// The version outlives the pages cached under the previous one.
+func (s *service) forgetLeaderboard(ctx context.Context, examID int32) {
+	s.cache(ctx, leaderboardVersionKey(examID), s.now().UnixNano(), leaderboardTTL)
+}
@@ -0,0 +1,467 @@
+package examservice
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toeic-app/internal/choices"
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+// fakeStore keeps attempts, answers and questions in memory
+type fakeStore struct {
+	db.Querier
+	attempts  map[int32]db.ExamAttempt
+	answers   map[int32]db.UserAnswer
+	questions map[int32]db.Question
+	nextID    int32
+}
+
+// keyedQuestion is a question with the options A to D and answerKey as the
+// correct one
+func keyedQuestion(id int32, answerKey string) db.Question {
+	options := choices.Rekey(nil, []string{"on Monday", "on Tuesday", "on Wednesday", "on Friday"})
+	answer, _ := choices.Find(options, answerKey)
+	return db.Question{
+		QuestionID:      id,
+		PossibleAnswers: choices.Texts(options),
+		TrueAnswer:      answer.Text,
+		Options:         choices.Encode(options),
+		AnswerKey:       answerKey,
+	}
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{
+		attempts: map[int32]db.ExamAttempt{
+			1: {AttemptID: 1, UserID: 7, ExamID: 3, Status: db.ExamStatusEnumInProgress},
+			2: {AttemptID: 2, UserID: 8, ExamID: 3, Status: db.ExamStatusEnumCompleted},
+		},
+		answers:   map[int32]db.UserAnswer{},
+		questions: map[int32]db.Question{10: keyedQuestion(10, "B"), 11: keyedQuestion(11, "C")},
+		nextID:    100,
+	}
+}
+
+func (s *fakeStore) GetExamAttempt(ctx context.Context, attemptID int32) (db.ExamAttempt, error) {
+	attempt, ok := s.attempts[attemptID]
+	if !ok {
+		return attempt, sql.ErrNoRows
+	}
+	return attempt, nil
+}
+
+func (s *fakeStore) GetExamAttemptByUser(ctx context.Context, arg db.GetExamAttemptByUserParams) (db.ExamAttempt, error) {
+	attempt, ok := s.attempts[arg.AttemptID]
+	if !ok || attempt.UserID != arg.UserID {
+		return db.ExamAttempt{}, sql.ErrNoRows
+	}
+	return attempt, nil
+}
+
+func (s *fakeStore) DeleteExamAttempt(ctx context.Context, attemptID int32) error {
+	delete(s.attempts, attemptID)
+	return nil
+}
+
+func (s *fakeStore) GetQuestion(ctx context.Context, questionID int32) (db.Question, error) {
+	question, ok := s.questions[questionID]
+	if !ok {
+		return question, sql.ErrNoRows
+	}
+	return question, nil
+}
+
+func (s *fakeStore) GetUserAnswer(ctx context.Context, answerID int32) (db.UserAnswer, error) {
+	answer, ok := s.answers[answerID]
+	if !ok {
+		return answer, sql.ErrNoRows
+	}
+	return answer, nil
+}
+
+func (s *fakeStore) GetUserAnswerByAttemptAndQuestion(ctx context.Context, arg db.GetUserAnswerByAttemptAndQuestionParams) (db.UserAnswer, error) {
+	for _, answer := range s.answers {
+		if answer.AttemptID == arg.AttemptID && answer.QuestionID == arg.QuestionID {
+			return answer, nil
+		}
+	}
+	return db.UserAnswer{}, sql.ErrNoRows
+}
+
+func (s *fakeStore) CreateUserAnswer(ctx context.Context, arg db.CreateUserAnswerParams) (db.UserAnswer, error) {
+	s.nextID++
+	answer := db.UserAnswer{
+		UserAnswerID:   s.nextID,
+		AttemptID:      arg.AttemptID,
+		QuestionID:     arg.QuestionID,
+		SelectedAnswer: arg.SelectedAnswer,
+		IsCorrect:      arg.IsCorrect,
+		SelectedKey:    arg.SelectedKey,
+	}
+	s.answers[answer.UserAnswerID] = answer
+	return answer, nil
+}
+
+func (s *fakeStore) DeleteUserAnswer(ctx context.Context, answerID int32) error {
+	delete(s.answers, answerID)
+	return nil
+}
+
+func (s *fakeStore) GetAttemptScore(ctx context.Context, arg db.GetAttemptScoreParams) (db.GetAttemptScoreRow, error) {
+	row := db.GetAttemptScoreRow{CalculatedScore: "0"}
+	for _, answer := range s.answers {
+		if answer.AttemptID == arg.AttemptID {
+			row.TotalQuestions++
+			if answer.IsCorrect {
+				row.CorrectAnswers++
+			}
+		}
+	}
+	if row.TotalQuestions > 0 {
+		row.CalculatedScore = strconv.Itoa(int(row.CorrectAnswers * int64(arg.MaxScore) / row.TotalQuestions))
+	}
+	return row, nil
+}
+
+func (s *fakeStore) RegradeUserAnswers(ctx context.Context, arg db.RegradeUserAnswersParams) ([]db.RegradeUserAnswersRow, error) {
+	var rows []db.RegradeUserAnswersRow
+	for id, answer := range s.answers {
+		if isCorrect := answer.SelectedKey == arg.AnswerKey; answer.QuestionID == arg.QuestionID && answer.IsCorrect != isCorrect {
+			answer.IsCorrect = isCorrect
+			s.answers[id] = answer
+			rows = append(rows, db.RegradeUserAnswersRow{UserAnswerID: id, AttemptID: answer.AttemptID, IsCorrect: isCorrect})
+		}
+	}
+	return rows, nil
+}
+
+func (s *fakeStore) RescoreExamAttempt(ctx context.Context, arg db.RescoreExamAttemptParams) (db.ExamAttempt, error) {
+	attempt, ok := s.attempts[arg.AttemptID]
+	if !ok || attempt.Status != db.ExamStatusEnumCompleted {
+		return db.ExamAttempt{}, sql.ErrNoRows
+	}
+	attempt.Score = arg.Score
+	s.attempts[arg.AttemptID] = attempt
+	return attempt, nil
+}
+
+// practiceStore has the parts of exam 3: questions 10 and 11 are in parts
+// 5 and 6, and part 7 has none
+type practiceStore struct {
+	*fakeStore
+	revealed map[[2]int32]bool // Attempt and question of revealed transcripts
+}
+
+func (s *practiceStore) GetExam(ctx context.Context, examID int32) (db.Exam, error) {
+	return db.Exam{ExamID: examID, TimeLimitMinutes: 120}, nil
+}
+
+func (s *practiceStore) GetPart(ctx context.Context, partID int32) (db.Part, error) {
+	if partID < 5 || partID > 7 {
+		return db.Part{}, sql.ErrNoRows
+	}
+	return db.Part{PartID: partID, ExamID: 3}, nil
+}
+
+func (s *practiceStore) CountPartQuestions(ctx context.Context, arg db.CountPartQuestionsParams) (db.CountPartQuestionsRow, error) {
+	row := db.CountPartQuestionsRow{ExamQuestions: 200}
+	if arg.PartID != 7 {
+		row.PartQuestions = 40
+	}
+	return row, nil
+}
+
+func (s *practiceStore) GetQuestionPartID(ctx context.Context, questionID int32) (int32, error) {
+	if _, ok := s.questions[questionID]; !ok {
+		return 0, sql.ErrNoRows
+	}
+	return questionID - 5, nil
+}
+
+func (s *practiceStore) GetActiveExamAttempt(ctx context.Context, arg db.GetActiveExamAttemptParams) (db.ExamAttempt, error) {
+	for _, attempt := range s.attempts {
+		if attempt.UserID == arg.UserID && attempt.ExamID == arg.ExamID && attempt.PartID == arg.PartID &&
+			attempt.Status == db.ExamStatusEnumInProgress {
+			return attempt, nil
+		}
+	}
+	return db.ExamAttempt{}, sql.ErrNoRows
+}
+
+func (s *practiceStore) CreateExamAttempt(ctx context.Context, arg db.CreateExamAttemptParams) (db.ExamAttempt, error) {
+	s.nextID++
+	attempt := db.ExamAttempt{
+		AttemptID:        s.nextID,
+		UserID:           arg.UserID,
+		ExamID:           arg.ExamID,
+		StartTime:        arg.StartTime,
+		Status:           arg.Status,
+		PartID:           arg.PartID,
+		TimeLimitMinutes: arg.TimeLimitMinutes,
+	}
+	s.attempts[attempt.AttemptID] = attempt
+	return attempt, nil
+}
+
+func (s *practiceStore) CompleteExamAttempt(ctx context.Context, arg db.CompleteExamAttemptParams) (db.ExamAttempt, error) {
+	attempt := s.attempts[arg.AttemptID]
+	attempt.Status = db.ExamStatusEnumCompleted
+	attempt.Score = arg.Score
+	s.attempts[arg.AttemptID] = attempt
+	return attempt, nil
+}
+
+func (s *practiceStore) UpdateUserAnswer(ctx context.Context, arg db.UpdateUserAnswerParams) (db.UserAnswer, error) {
+	answer := s.answers[arg.UserAnswerID]
+	answer.SelectedAnswer = arg.SelectedAnswer
+	answer.SelectedKey = arg.SelectedKey
+	answer.IsCorrect = arg.IsCorrect
+	s.answers[arg.UserAnswerID] = answer
+	return answer, nil
+}
+
+func (s *practiceStore) IsTranscriptRevealed(ctx context.Context, arg db.IsTranscriptRevealedParams) (bool, error) {
+	return s.revealed[[2]int32{arg.AttemptID, arg.QuestionID}], nil
+}
+
+// mapCache is a Cache kept in memory
+type mapCache map[string][]byte
+
+func (c mapCache) Get(ctx context.Context, key string, result interface{}) error {
+	data, ok := c[key]
+	if !ok {
+		return errors.New("cache miss")
+	}
+	return json.Unmarshal(data, result)
+}
+
+func (c mapCache) Set(ctx context.Context, key string, data interface{}, ttl time.Duration) error {
+	encoded, err := json.Marshal(data)
+	c[key] = encoded
+	return err
+}
+
+func (c mapCache) Delete(ctx context.Context, key string) error {
+	delete(c, key)
+	return nil
+}
+
+func TestSubmitAnswersRecordsFailures(t *testing.T) {
+	store := newFakeStore()
+	service := NewService(store, Options{})
+
+	result, err := service.SubmitAnswers(context.Background(), 7, 1, []Submission{
+		{QuestionID: 10, SelectedKey: "B"},
+		{QuestionID: 11, SelectedKey: "E"},
+		{QuestionID: 11, SelectedAnswer: "on Monday"},
+		{QuestionID: 10, SelectedKey: "C"},
+		{QuestionID: 99, SelectedKey: "A"},
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Answers, 2)
+	assert.Equal(t, int32(1), result.Correct)
+	// Answers given by text from older clients are stored by key too
+	assert.Equal(t, "A", result.Answers[1].SelectedKey)
+	assert.Equal(t, "on Monday", result.Answers[1].SelectedAnswer)
+	assert.Equal(t, []FailedSubmission{
+		{QuestionID: 11, SelectedKey: "E", Error: FailureInvalidOption},
+		{QuestionID: 10, SelectedKey: "C", Error: FailureAnswerExists},
+		{QuestionID: 99, SelectedKey: "A", Error: FailureNoQuestion},
+	}, result.Failed)
+
+	// Answers cannot be submitted to the attempts of other users
+	_, err = service.SubmitAnswers(context.Background(), 7, 2, []Submission{{QuestionID: 10, SelectedKey: "B"}})
+	assert.ErrorIs(t, err, ErrAttemptNotFound)
+}
+
+func TestAnswerChecksOwnershipBeforeCache(t *testing.T) {
+	store := newFakeStore()
+	cache := mapCache{}
+	service := NewService(store, Options{Cache: cache})
+	ctx := context.Background()
+
+	answer, err := service.SubmitAnswer(ctx, 7, Submission{AttemptID: 1, QuestionID: 10, SelectedKey: "B"})
+	require.NoError(t, err)
+	_, err = service.Answer(ctx, 7, answer.UserAnswerID)
+	require.NoError(t, err)
+	require.Contains(t, cache, answerKey(answer.UserAnswerID))
+
+	_, err = service.Answer(ctx, 8, answer.UserAnswerID)
+	assert.ErrorIs(t, err, ErrAnswerNotFound)
+	_, err = service.Score(ctx, 8, 1)
+	assert.ErrorIs(t, err, ErrAttemptNotFound)
+}
+
+func TestScoreIsForgottenWhenAnswersChange(t *testing.T) {
+	store := newFakeStore()
+	cache := mapCache{}
+	changed := make(chan int32, 4)
+	service := NewService(store, Options{Cache: cache, UserChanged: func(userID int32) { changed <- userID }})
+	ctx := context.Background()
+
+	_, err := service.SubmitAnswer(ctx, 7, Submission{AttemptID: 1, QuestionID: 10, SelectedKey: "B"})
+	require.NoError(t, err)
+	score, err := service.Score(ctx, 7, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), score.TotalQuestions)
+
+	answer, err := service.SubmitAnswer(ctx, 7, Submission{AttemptID: 1, QuestionID: 11, SelectedKey: "C"})
+	require.NoError(t, err)
+	score, err = service.Score(ctx, 7, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), score.CorrectAnswers)
+
+	require.NoError(t, service.DeleteAnswer(ctx, 7, answer.UserAnswerID, false))
+	score, err = service.Score(ctx, 7, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), score.TotalQuestions)
+	assert.Equal(t, int32(7), <-changed)
+}
+
+func TestDeleteAttemptKeepsClosedAttempts(t *testing.T) {
+	store := newFakeStore()
+	service := NewService(store, Options{})
+	ctx := context.Background()
+
+	assert.ErrorIs(t, service.DeleteAttempt(ctx, 8, 2, false), ErrAttemptClosed)
+	assert.ErrorIs(t, service.DeleteAttempt(ctx, 7, 2, false), ErrAttemptNotFound)
+	require.NoError(t, service.DeleteAttempt(ctx, 1, 2, true))
+	assert.NotContains(t, store.attempts, int32(2))
+}
+
+func TestRegradeRescoresCompletedAttempts(t *testing.T) {
+	store := newFakeStore()
+	cache := mapCache{}
+	service := NewService(store, Options{Cache: cache})
+	ctx := context.Background()
+
+	// Attempt 2 was completed with answers graded against the old key
+	store.answers[50] = db.UserAnswer{UserAnswerID: 50, AttemptID: 2, QuestionID: 10, SelectedKey: "B", IsCorrect: true}
+	store.answers[51] = db.UserAnswer{UserAnswerID: 51, AttemptID: 2, QuestionID: 11, SelectedKey: "C", IsCorrect: true}
+	store.answers[52] = db.UserAnswer{UserAnswerID: 52, AttemptID: 1, QuestionID: 10, SelectedKey: "A"}
+	attempt := store.attempts[2]
+	attempt.Score = sql.NullString{String: "990", Valid: true}
+	store.attempts[2] = attempt
+	cache[answerKey(50)] = []byte("{}")
+	cache[scoreKey(2)] = []byte("{}")
+	cache[leaderboardKey(3, 0, 10, 0)] = []byte("[]")
+
+	store.questions[10] = keyedQuestion(10, "A")
+	regraded, err := service.RegradeQuestion(ctx, 10)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []db.RegradeUserAnswersRow{
+		{UserAnswerID: 50, AttemptID: 2, IsCorrect: false},
+		{UserAnswerID: 52, AttemptID: 1, IsCorrect: true},
+	}, regraded)
+	assert.NotContains(t, cache, answerKey(50))
+
+	rescore, err := service.RescoreAttempt(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, Rescore{AttemptID: 2, UserID: 8, ExamID: 3, OldScore: "990", NewScore: "495"}, rescore)
+	assert.True(t, rescore.Changed())
+	assert.NotContains(t, cache, scoreKey(2))
+	// Leaderboard pages cached before the rescore are no longer read
+	assert.Contains(t, cache, leaderboardVersionKey(3))
+
+	// Attempts in progress keep their empty score until they are completed
+	rescore, err = service.RescoreAttempt(ctx, 1)
+	require.NoError(t, err)
+	assert.False(t, rescore.Changed())
+
+	_, err = service.RegradeQuestion(ctx, 99)
+	assert.ErrorIs(t, err, ErrQuestionNotFound)
+}
+
+func TestStartPartPractice(t *testing.T) {
+	store := &practiceStore{fakeStore: newFakeStore(), revealed: map[[2]int32]bool{}}
+	service := NewService(store, Options{})
+	ctx := context.Background()
+
+	// User 7 taking the full test of exam 3 in attempt 1 may still practice
+	// one of its parts
+	practice, err := service.StartPartPractice(ctx, 7, 5, 0)
+	require.NoError(t, err)
+	assert.Equal(t, sql.NullInt32{Int32: 5, Valid: true}, practice.PartID)
+	assert.Equal(t, sql.NullInt32{Int32: 24, Valid: true}, practice.TimeLimitMinutes)
+	assert.Equal(t, int32(PracticeMaxScore), MaxScore(practice))
+
+	active, err := service.StartPartPractice(ctx, 7, 5, 10)
+	assert.ErrorIs(t, err, ErrActiveAttempt)
+	assert.Equal(t, practice.AttemptID, active.AttemptID)
+	other, err := service.StartPartPractice(ctx, 7, 6, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int32(10), other.TimeLimitMinutes.Int32)
+
+	_, err = service.StartPartPractice(ctx, 7, 7, 0)
+	assert.ErrorIs(t, err, ErrPartEmpty)
+	_, err = service.StartPartPractice(ctx, 7, 8, 0)
+	assert.ErrorIs(t, err, ErrPartNotFound)
+
+	// Only questions of the part are answered
+	result, err := service.SubmitAnswers(ctx, 7, practice.AttemptID, []Submission{
+		{QuestionID: 10, SelectedKey: "B"},
+		{QuestionID: 11, SelectedKey: "C"},
+		{QuestionID: 99, SelectedKey: "A"},
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Answers, 1)
+	assert.Equal(t, []FailedSubmission{
+		{QuestionID: 11, SelectedKey: "C", Error: FailureNotInPart},
+		{QuestionID: 99, SelectedKey: "A", Error: FailureNoQuestion},
+	}, result.Failed)
+
+	// Practice is scored from its answers, whatever score is given
+	completed, err := service.CompleteAttempt(ctx, 7, practice.AttemptID, "990")
+	require.NoError(t, err)
+	assert.Equal(t, "100", completed.Score.String)
+	score, err := service.Score(ctx, 7, practice.AttemptID)
+	require.NoError(t, err)
+	assert.Equal(t, int32(PracticeMaxScore), score.MaxScore)
+}
+
+func TestAnswerIsFinalOnceTranscriptRevealed(t *testing.T) {
+	store := &practiceStore{fakeStore: newFakeStore(), revealed: map[[2]int32]bool{}}
+	service := NewService(store, Options{})
+	ctx := context.Background()
+
+	practice, err := service.StartPartPractice(ctx, 7, 5, 0)
+	require.NoError(t, err)
+	answer, err := service.SubmitAnswer(ctx, 7, Submission{AttemptID: practice.AttemptID, QuestionID: 10, SelectedKey: "A"})
+	require.NoError(t, err)
+
+	// The answer can be corrected until the transcript is revealed
+	updated, err := service.UpdateAnswer(ctx, 7, answer.UserAnswerID, "C", "")
+	require.NoError(t, err)
+	assert.Equal(t, "C", updated.SelectedKey)
+
+	store.revealed[[2]int32{practice.AttemptID, 10}] = true
+	_, err = service.UpdateAnswer(ctx, 7, answer.UserAnswerID, "B", "")
+	assert.ErrorIs(t, err, ErrAnswerRevealed)
+	assert.Equal(t, "C", store.answers[answer.UserAnswerID].SelectedKey)
+	// Nor can it be deleted and answered again
+	assert.ErrorIs(t, service.DeleteAnswer(ctx, 7, answer.UserAnswerID, false), ErrAnswerRevealed)
+	assert.Contains(t, store.answers, answer.UserAnswerID)
+}
+
+func TestPracticeTimeLimit(t *testing.T) {
+	assert.Equal(t, int32(24), practiceTimeLimit(120, 40, 200))
+	assert.Equal(t, int32(4), practiceTimeLimit(120, 6, 200))
+	assert.Equal(t, int32(1), practiceTimeLimit(10, 1, 200))
+	assert.Equal(t, int32(120), practiceTimeLimit(120, 200, 200))
+	assert.Equal(t, int32(120), practiceTimeLimit(120, 5, 0))
+}
+
+func TestScoreValue(t *testing.T) {
+	assert.Nil(t, scoreValue(nil))
+	assert.Equal(t, 850.5, *scoreValue([]byte("850.5")))
+	assert.Equal(t, 700.0, *scoreValue(int64(700)))
+	assert.Nil(t, scoreValue("not a number"))
+}
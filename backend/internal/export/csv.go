@@ -0,0 +1,56 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+// flushEvery is how many rows are buffered before they are sent on
+const flushEvery = 500
+
+type csvWriter struct {
+	w    *csv.Writer
+	rows int
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+func (c *csvWriter) Write(row []string) error {
+	cells := make([]string, len(row))
+	for i, value := range row {
+		cells[i] = escapeFormula(value)
+	}
+	if err := c.w.Write(cells); err != nil {
+		return err
+	}
+	c.rows++
+	if c.rows%flushEvery == 0 {
+		c.w.Flush()
+		return c.w.Error()
+	}
+	return nil
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// escapeFormula stops spreadsheet applications from evaluating user supplied
+// text such as usernames as a formula when the CSV file is opened
+func escapeFormula(value string) string {
+	if value == "" {
+		return value
+	}
+	switch value[0] {
+	case '=', '+', '-', '@', '\t', '\r':
+		if _, err := strconv.ParseFloat(value, 64); err == nil {
+			return value
+		}
+		return "'" + value
+	}
+	return value
+}
@@ -0,0 +1,77 @@
+// Package export streams tabular admin data as CSV or XLSX. Rows are written
+// to the destination as they are produced, so large result sets never have to
+// be held in memory.
+package export
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Format is a file format rows can be exported as
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatXLSX Format = "xlsx"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported export format, use csv or xlsx")
+
+// ParseFormat reads a format query value. An empty value means no export.
+func ParseFormat(value string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimSpace(value))) {
+	case "":
+		return "", nil
+	case FormatCSV:
+		return FormatCSV, nil
+	case FormatXLSX:
+		return FormatXLSX, nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnsupportedFormat, value)
+}
+
+// ContentType returns the MIME type of the format
+func (f Format) ContentType() string {
+	if f == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// Filename returns name with the format's extension
+func (f Format) Filename(name string) string {
+	return name + "." + string(f)
+}
+
+// SetHeaders marks a response as a download of name in format. The file name
+// carries the export time so repeated downloads do not overwrite each other.
+func SetHeaders(h http.Header, format Format, name string) {
+	filename := format.Filename(fmt.Sprintf("%s-%s", name, time.Now().UTC().Format("20060102-150405")))
+	h.Set("Content-Type", format.ContentType())
+	h.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	h.Set("Cache-Control", "no-store")
+}
+
+// Writer writes rows of a table. The first row written is the header.
+// Close must be called to complete the file.
+type Writer interface {
+	Write(row []string) error
+	Close() error
+}
+
+// NewWriter creates a writer for format that streams to w. sheet names the
+// worksheet of XLSX files and is ignored for CSV.
+func NewWriter(format Format, w io.Writer, sheet string) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w), nil
+	case FormatXLSX:
+		return newXLSXWriter(w, sheet)
+	}
+	return nil, ErrUnsupportedFormat
+}
@@ -0,0 +1,84 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat("")
+	require.NoError(t, err)
+	assert.Equal(t, Format(""), format)
+
+	format, err = ParseFormat(" XLSX ")
+	require.NoError(t, err)
+	assert.Equal(t, FormatXLSX, format)
+
+	_, err = ParseFormat("pdf")
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+}
+
+func TestCSVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatCSV, &buf, "")
+	require.NoError(t, err)
+
+	require.NoError(t, w.Write([]string{"id", "username", "balance"}))
+	require.NoError(t, w.Write([]string{"1", "=HYPERLINK(\"x\")", "-12.5"}))
+	require.NoError(t, w.Write([]string{"2", "a,b", "@sum"}))
+	require.NoError(t, w.Close())
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"id", "username", "balance"},
+		{"1", "'=HYPERLINK(\"x\")", "-12.5"},
+		{"2", "a,b", "'@sum"},
+	}, records)
+}
+
+func TestXLSXWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatXLSX, &buf, "Users: [all]")
+	require.NoError(t, err)
+
+	require.NoError(t, w.Write([]string{"id", "name", "code"}))
+	require.NoError(t, w.Write([]string{"42", "Tom & <Jerry>", "007"}))
+	require.NoError(t, w.Close())
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	files := make(map[string]string)
+	for _, f := range archive.File {
+		r, err := f.Open()
+		require.NoError(t, err)
+		body, err := io.ReadAll(r)
+		require.NoError(t, err)
+		files[f.Name] = string(body)
+	}
+
+	require.Contains(t, files, "[Content_Types].xml")
+	require.Contains(t, files, "_rels/.rels")
+	assert.Contains(t, files["xl/workbook.xml"], `name="Users_ _all_"`)
+
+	sheet := files["xl/worksheets/sheet1.xml"]
+	assert.Contains(t, sheet, `<c r="A1" t="inlineStr"><is><t xml:space="preserve">id</t></is></c>`)
+	assert.Contains(t, sheet, `<c r="A2"><v>42</v></c>`)
+	assert.Contains(t, sheet, `Tom &amp; &lt;Jerry&gt;`)
+	assert.Contains(t, sheet, `<c r="C2" t="inlineStr"><is><t xml:space="preserve">007</t></is></c>`)
+}
+
+func TestColumnName(t *testing.T) {
+	assert.Equal(t, "A", columnName(0))
+	assert.Equal(t, "Z", columnName(25))
+	assert.Equal(t, "AA", columnName(26))
+	assert.Equal(t, "AZ", columnName(51))
+	assert.Equal(t, "BA", columnName(52))
+}
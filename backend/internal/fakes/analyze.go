@@ -0,0 +1,110 @@
+package fakes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// AnalyzeWord is a word of an analyze service reply
+type AnalyzeWord struct {
+	Word  string `json:"word"`
+	Level string `json:"level"`
+	Count int    `json:"count"`
+}
+
+// Analyze serves the /analyze and /health endpoints of the analyze service.
+// Every word of a text is reported at Level; SetHealthy(false) makes health
+// checks fail.
+type Analyze struct {
+	server *httptest.Server
+
+	mu       sync.Mutex
+	level    string
+	healthy  bool
+	requests []string
+}
+
+// NewAnalyze starts a fake analyze service that is closed when the test ends
+func NewAnalyze(t testing.TB) *Analyze {
+	f := &Analyze{level: "B1", healthy: true}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/analyze", f.analyze)
+	mux.HandleFunc("/health", f.health)
+	f.server = httptest.NewServer(mux)
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+// URL is the base URL, for ANALYZE_SERVICE_URL
+func (f *Analyze) URL() string {
+	return f.server.URL
+}
+
+// SetLevel sets the CEFR level reported for every word
+func (f *Analyze) SetLevel(level string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.level = level
+}
+
+// SetHealthy sets the health the service reports
+func (f *Analyze) SetHealthy(healthy bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.healthy = healthy
+}
+
+// Texts returns the texts analyzed so far
+func (f *Analyze) Texts() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.requests...)
+}
+
+func (f *Analyze) analyze(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Text string `json:"text"`
+	}
+	if r.Method != http.MethodPost || json.NewDecoder(r.Body).Decode(&req) != nil || req.Text == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"detail": "text is required"})
+		return
+	}
+
+	f.mu.Lock()
+	f.requests = append(f.requests, req.Text)
+	level := f.level
+	f.mu.Unlock()
+
+	counts := make(map[string]int)
+	var words []AnalyzeWord
+	for _, field := range strings.Fields(strings.ToLower(req.Text)) {
+		word := strings.Trim(field, ".,;:!?\"'()")
+		if word == "" {
+			continue
+		}
+		if counts[word] == 0 {
+			words = append(words, AnalyzeWord{Word: word, Level: level})
+		}
+		counts[word]++
+	}
+	for i := range words {
+		words[i].Count = counts[words[i].Word]
+	}
+	writeJSON(w, http.StatusOK, words)
+}
+
+func (f *Analyze) health(w http.ResponseWriter, _ *http.Request) {
+	f.mu.Lock()
+	healthy := f.healthy
+	f.mu.Unlock()
+
+	if !healthy {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "unhealthy"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"status": "healthy", "word_levels_loaded": true, "word_count": 1000})
+}
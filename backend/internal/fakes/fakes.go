@@ -0,0 +1,16 @@
+// Package fakes provides in-process stand-ins for the external services the
+// backend calls: the OpenAI chat and speech APIs, Cloudinary, Redis and the
+// analyze service. They let handler tests run without network access or
+// credentials.
+package fakes
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
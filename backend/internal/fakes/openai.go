@@ -0,0 +1,168 @@
+package fakes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// DefaultWritingAssessment is a chat reply the writing scorer parses
+const DefaultWritingAssessment = `{
+  "score": 150,
+  "band": "Level 7",
+  "feedback": {
+    "grammar": "Few errors",
+    "vocabulary": "Varied",
+    "organization": "Clear",
+    "development": "Supported",
+    "task_response": "Complete",
+    "language_use": "Appropriate",
+    "overall": "Good response"
+  },
+  "suggestions": ["Use more linking words"],
+  "confidence": 0.8
+}`
+
+// FakeAudio is the MP3 body returned by the speech endpoint
+var FakeAudio = []byte("ID3fake-mp3")
+
+// ChatMessage is a message of a chat completion request
+type ChatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// ChatRequest is a chat completion request received by the fake
+type ChatRequest struct {
+	Model     string        `json:"model"`
+	Messages  []ChatMessage `json:"messages"`
+	APIKey    string        `json:"-"`
+	MaxTokens int           `json:"max_tokens"`
+}
+
+// SpeechRequest is a speech request received by the fake
+type SpeechRequest struct {
+	Model  string `json:"model"`
+	Input  string `json:"input"`
+	Voice  string `json:"voice"`
+	APIKey string `json:"-"`
+}
+
+// OpenAI serves /v1/chat/completions and /v1/audio/speech. Chat requests are
+// answered with Reply, or fail with FailStatus when it is set.
+type OpenAI struct {
+	server *httptest.Server
+
+	mu         sync.Mutex
+	reply      string
+	failStatus int
+	chats      []ChatRequest
+	speech     []SpeechRequest
+}
+
+// NewOpenAI starts a fake OpenAI API that is closed when the test ends
+func NewOpenAI(t testing.TB) *OpenAI {
+	f := &OpenAI{reply: DefaultWritingAssessment}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/chat/completions", f.chat)
+	mux.HandleFunc("/v1/audio/speech", f.synthesize)
+	f.server = httptest.NewServer(mux)
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+// ChatURL is the chat completions endpoint, for OPENAI_API_URL
+func (f *OpenAI) ChatURL() string {
+	return f.server.URL + "/v1/chat/completions"
+}
+
+// SpeechURL is the speech endpoint, for TTS_API_URL
+func (f *OpenAI) SpeechURL() string {
+	return f.server.URL + "/v1/audio/speech"
+}
+
+// Reply sets the content of the next chat completions
+func (f *OpenAI) Reply(content string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reply = content
+}
+
+// Fail makes every request fail with status; 0 restores normal replies
+func (f *OpenAI) Fail(status int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failStatus = status
+}
+
+// ChatRequests returns the chat completion requests received so far
+func (f *OpenAI) ChatRequests() []ChatRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]ChatRequest(nil), f.chats...)
+}
+
+// SpeechRequests returns the speech requests received so far
+func (f *OpenAI) SpeechRequests() []SpeechRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]SpeechRequest(nil), f.speech...)
+}
+
+func (f *OpenAI) chat(w http.ResponseWriter, r *http.Request) {
+	var req ChatRequest
+	if r.Method != http.MethodPost || json.NewDecoder(r.Body).Decode(&req) != nil {
+		writeJSON(w, http.StatusBadRequest, openAIError("invalid request"))
+		return
+	}
+	req.APIKey = bearer(r)
+
+	f.mu.Lock()
+	f.chats = append(f.chats, req)
+	reply, failStatus := f.reply, f.failStatus
+	f.mu.Unlock()
+
+	if failStatus != 0 {
+		writeJSON(w, failStatus, openAIError("fake failure"))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"id":      "chatcmpl-fake",
+		"object":  "chat.completion",
+		"model":   req.Model,
+		"choices": []map[string]interface{}{{"index": 0, "message": ChatMessage{Role: "assistant", Content: reply}, "finish_reason": "stop"}},
+		"usage":   map[string]int{"prompt_tokens": 10, "completion_tokens": 20, "total_tokens": 30},
+	})
+}
+
+func (f *OpenAI) synthesize(w http.ResponseWriter, r *http.Request) {
+	var req SpeechRequest
+	if r.Method != http.MethodPost || json.NewDecoder(r.Body).Decode(&req) != nil {
+		writeJSON(w, http.StatusBadRequest, openAIError("invalid request"))
+		return
+	}
+	req.APIKey = bearer(r)
+
+	f.mu.Lock()
+	f.speech = append(f.speech, req)
+	failStatus := f.failStatus
+	f.mu.Unlock()
+
+	if failStatus != 0 {
+		writeJSON(w, failStatus, openAIError("fake failure"))
+		return
+	}
+	w.Header().Set("Content-Type", "audio/mpeg")
+	w.Write(FakeAudio)
+}
+
+func bearer(r *http.Request) string {
+	return strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+}
+
+func openAIError(message string) map[string]interface{} {
+	return map[string]interface{}{"error": map[string]string{"message": message, "type": "fake_error"}}
+}
@@ -0,0 +1,22 @@
+package fakes
+
+import (
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// NewRedis starts an in-memory Redis server that is closed when the test
+// ends. Use its Addr() as REDIS_ADDR; FastForward expires keys.
+func NewRedis(t testing.TB) *miniredis.Miniredis {
+	return miniredis.RunT(t)
+}
+
+// NewRedisClient starts an in-memory Redis server and returns a client for it
+func NewRedisClient(t testing.TB) (*redis.Client, *miniredis.Miniredis) {
+	server := NewRedis(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return client, server
+}
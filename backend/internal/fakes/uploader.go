@@ -0,0 +1,143 @@
+package fakes
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Upload is a file stored by the fake uploader
+type Upload struct {
+	// Kind is image, audio, private_audio or private_image
+	Kind string
+	Name string
+	Data []byte
+}
+
+// Uploader keeps uploads in memory in place of Cloudinary. Setting Err makes
+// every upload and deletion fail.
+type Uploader struct {
+	mu      sync.Mutex
+	uploads []Upload
+	Err     error
+}
+
+// NewUploader creates an empty fake uploader
+func NewUploader() *Uploader {
+	return &Uploader{}
+}
+
+// URL is the delivery URL of an upload
+func (u *Uploader) URL(kind, name string) string {
+	return fmt.Sprintf("https://media.fake/%s/%s", kind, name)
+}
+
+// Uploads returns the files stored so far
+func (u *Uploader) Uploads() []Upload {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return append([]Upload(nil), u.uploads...)
+}
+
+func (u *Uploader) store(kind, name string, file interface{}) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.Err != nil {
+		return u.Err
+	}
+
+	var data []byte
+	switch f := file.(type) {
+	case []byte:
+		data = f
+	case string:
+		data = []byte(f)
+	case io.Reader:
+		var err error
+		if data, err = io.ReadAll(f); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported upload source %T", file)
+	}
+	u.uploads = append(u.uploads, Upload{Kind: kind, Name: name, Data: data})
+	return nil
+}
+
+func (u *Uploader) UploadImage(_ context.Context, file interface{}, filename string) (string, error) {
+	if err := u.store("image", filename, file); err != nil {
+		return "", err
+	}
+	return u.URL("image", filename), nil
+}
+
+func (u *Uploader) UploadAudio(_ context.Context, file interface{}, filename string) (string, error) {
+	if err := u.store("audio", filename, file); err != nil {
+		return "", err
+	}
+	return u.URL("audio", filename), nil
+}
+
+// UploadPrivateAudio keeps an existing upload with the same public ID, like Cloudinary
+func (u *Uploader) UploadPrivateAudio(_ context.Context, file interface{}, publicID string) error {
+	for _, upload := range u.Uploads() {
+		if upload.Kind == "private_audio" && upload.Name == publicID {
+			return nil
+		}
+	}
+	return u.store("private_audio", publicID, file)
+}
+
+func (u *Uploader) SignedAudioURL(publicID string) (string, error) {
+	return u.URL("private_audio", publicID) + "?signature=fake", nil
+}
+
+// PlaybackURL adds the expiry to the URL, as Unix seconds
+func (u *Uploader) PlaybackURL(url string, expiresAt time.Time) (string, error) {
+	return fmt.Sprintf("%s?expires=%d&signature=fake", url, expiresAt.Unix()), nil
+}
+
+// DeleteAudio removes an audio upload by its URL. Unknown URLs are ignored,
+// like URLs of other hosts in production.
+func (u *Uploader) DeleteAudio(_ context.Context, url string) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.Err != nil {
+		return u.Err
+	}
+	for i, upload := range u.uploads {
+		if upload.Kind == "audio" && u.URL(upload.Kind, upload.Name) == url {
+			u.uploads = append(u.uploads[:i], u.uploads[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (u *Uploader) UploadPrivateImage(_ context.Context, file interface{}, publicID string) error {
+	return u.store("private_image", publicID, file)
+}
+
+// SignedImageURL adds the expiry to the URL, as Unix seconds
+func (u *Uploader) SignedImageURL(publicID string, expiresAt time.Time) (string, error) {
+	return fmt.Sprintf("%s?expires=%d&signature=fake", u.URL("private_image", publicID), expiresAt.Unix()), nil
+}
+
+// DeletePrivateImage removes a private image. Unknown public IDs are
+// ignored, like assets that are already gone in production.
+func (u *Uploader) DeletePrivateImage(_ context.Context, publicID string) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.Err != nil {
+		return u.Err
+	}
+	for i, upload := range u.uploads {
+		if upload.Kind == "private_image" && upload.Name == publicID {
+			u.uploads = append(u.uploads[:i], u.uploads[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,279 @@
+package featureflags
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"time"
+
+	"github.com/toeic-app/internal/cache"
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/logger"
+)
+
+// Well-known feature flags
+const (
+	FlagAIScoringV2  = "ai_scoring_v2"
+	FlagAdaptiveExam = "adaptive_exam"
+)
+
+// cacheTTL keeps flags fresh enough that a kill switch takes effect within seconds
+const cacheTTL = 30 * time.Second
+
+// ErrFlagNotFound is returned when a flag key does not exist
+var ErrFlagNotFound = errors.New("feature flag not found")
+
+// Flag represents a feature flag and its targeting rules
+type Flag struct {
+	ID                int32     `json:"id"`
+	Key               string    `json:"key"`
+	Description       string    `json:"description"`
+	Enabled           bool      `json:"enabled"`
+	RolloutPercentage int32     `json:"rollout_percentage"`
+	AllowedRoles      []string  `json:"allowed_roles"`
+	AllowedUserIDs    []int32   `json:"allowed_user_ids"`
+	AllowedOrgs       []string  `json:"allowed_orgs"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// FlagInput holds the editable fields of a flag
+type FlagInput struct {
+	Description       string
+	Enabled           bool
+	RolloutPercentage int32
+	AllowedRoles      []string
+	AllowedUserIDs    []int32
+	AllowedOrgs       []string
+}
+
+// EvalContext describes the user a flag is evaluated for
+type EvalContext struct {
+	UserID int32
+	Roles  []string
+	Org    string
+}
+
+// Service manages feature flags stored in the database with a cache in front
+type Service struct {
+	store db.Querier
+	cache cache.Cache
+}
+
+// NewService creates a new feature flag service. The cache is optional.
+func NewService(store db.Querier, flagCache cache.Cache) *Service {
+	return &Service{
+		store: store,
+		cache: flagCache,
+	}
+}
+
+// IsEnabled evaluates a flag for the given user. Unknown flags and lookup
+// errors evaluate to false so a broken flag never enables a feature.
+func (s *Service) IsEnabled(ctx context.Context, key string, evalCtx EvalContext) bool {
+	flag, err := s.GetFlag(ctx, key)
+	if err != nil {
+		if !errors.Is(err, ErrFlagNotFound) {
+			logger.Warn("Failed to evaluate feature flag %s: %v", key, err)
+		}
+		return false
+	}
+	return flag.Evaluate(evalCtx)
+}
+
+// Evaluate applies the targeting rules of the flag. A disabled flag is always
+// off; otherwise explicit user, role and org targets are checked before the
+// percentage rollout.
+func (f *Flag) Evaluate(evalCtx EvalContext) bool {
+	if !f.Enabled {
+		return false
+	}
+
+	if evalCtx.UserID > 0 && slices.Contains(f.AllowedUserIDs, evalCtx.UserID) {
+		return true
+	}
+
+	for _, role := range evalCtx.Roles {
+		if slices.Contains(f.AllowedRoles, role) {
+			return true
+		}
+	}
+
+	if evalCtx.Org != "" && slices.Contains(f.AllowedOrgs, evalCtx.Org) {
+		return true
+	}
+
+	if f.RolloutPercentage >= 100 {
+		return true
+	}
+	if f.RolloutPercentage <= 0 || evalCtx.UserID <= 0 {
+		return false
+	}
+
+	return bucket(f.Key, evalCtx.UserID) < uint32(f.RolloutPercentage)
+}
+
+// bucket maps a user to a stable bucket in [0, 100) for a flag, so a user keeps
+// the same result while the rollout percentage only grows
+func bucket(key string, userID int32) uint32 {
+	h := fnv.New32a()
+	fmt.Fprintf(h, "%s:%d", key, userID)
+	return h.Sum32() % 100
+}
+
+// GetFlag returns a flag by key, using the cache when available
+func (s *Service) GetFlag(ctx context.Context, key string) (*Flag, error) {
+	if s.cache != nil {
+		if data, err := s.cache.Get(ctx, cacheKey(key)); err == nil {
+			var flag Flag
+			if err := json.Unmarshal(data, &flag); err == nil {
+				return &flag, nil
+			}
+		}
+	}
+
+	record, err := s.store.GetFeatureFlagByKey(ctx, key)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrFlagNotFound
+		}
+		return nil, fmt.Errorf("failed to get feature flag: %w", err)
+	}
+
+	flag := toFlag(record)
+	s.cacheFlag(ctx, flag)
+	return flag, nil
+}
+
+// ListFlags returns all flags
+func (s *Service) ListFlags(ctx context.Context) ([]Flag, error) {
+	records, err := s.store.ListFeatureFlags(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feature flags: %w", err)
+	}
+
+	flags := make([]Flag, len(records))
+	for i, record := range records {
+		flags[i] = *toFlag(record)
+	}
+	return flags, nil
+}
+
+// CreateFlag creates a new flag
+func (s *Service) CreateFlag(ctx context.Context, key string, input FlagInput) (*Flag, error) {
+	record, err := s.store.CreateFeatureFlag(ctx, db.CreateFeatureFlagParams{
+		Key:               key,
+		Description:       sql.NullString{String: input.Description, Valid: input.Description != ""},
+		Enabled:           input.Enabled,
+		RolloutPercentage: input.RolloutPercentage,
+		AllowedRoles:      nonNilStrings(input.AllowedRoles),
+		AllowedUserIds:    nonNilInts(input.AllowedUserIDs),
+		AllowedOrgs:       nonNilStrings(input.AllowedOrgs),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create feature flag: %w", err)
+	}
+
+	logger.Info("Feature flag created: %s (enabled: %t, rollout: %d%%)", key, input.Enabled, input.RolloutPercentage)
+	return toFlag(record), nil
+}
+
+// UpdateFlag replaces the rules of an existing flag and invalidates its cache entry
+func (s *Service) UpdateFlag(ctx context.Context, key string, input FlagInput) (*Flag, error) {
+	record, err := s.store.UpdateFeatureFlag(ctx, db.UpdateFeatureFlagParams{
+		Key:               key,
+		Description:       sql.NullString{String: input.Description, Valid: input.Description != ""},
+		Enabled:           input.Enabled,
+		RolloutPercentage: input.RolloutPercentage,
+		AllowedRoles:      nonNilStrings(input.AllowedRoles),
+		AllowedUserIds:    nonNilInts(input.AllowedUserIDs),
+		AllowedOrgs:       nonNilStrings(input.AllowedOrgs),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrFlagNotFound
+		}
+		return nil, fmt.Errorf("failed to update feature flag: %w", err)
+	}
+
+	s.invalidate(ctx, key)
+	logger.Info("Feature flag updated: %s (enabled: %t, rollout: %d%%)", key, input.Enabled, input.RolloutPercentage)
+	return toFlag(record), nil
+}
+
+// DeleteFlag removes a flag
+func (s *Service) DeleteFlag(ctx context.Context, key string) error {
+	if _, err := s.store.GetFeatureFlagByKey(ctx, key); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrFlagNotFound
+		}
+		return fmt.Errorf("failed to get feature flag: %w", err)
+	}
+
+	if err := s.store.DeleteFeatureFlag(ctx, key); err != nil {
+		return fmt.Errorf("failed to delete feature flag: %w", err)
+	}
+
+	s.invalidate(ctx, key)
+	logger.Info("Feature flag deleted: %s", key)
+	return nil
+}
+
+func (s *Service) cacheFlag(ctx context.Context, flag *Flag) {
+	if s.cache == nil {
+		return
+	}
+	data, err := json.Marshal(flag)
+	if err != nil {
+		return
+	}
+	if err := s.cache.Set(ctx, cacheKey(flag.Key), data, cacheTTL); err != nil {
+		logger.Debug("Failed to cache feature flag %s: %v", flag.Key, err)
+	}
+}
+
+func (s *Service) invalidate(ctx context.Context, key string) {
+	if s.cache == nil {
+		return
+	}
+	if err := s.cache.Delete(ctx, cacheKey(key)); err != nil {
+		logger.Warn("Failed to invalidate cached feature flag %s: %v", key, err)
+	}
+}
+
+func cacheKey(key string) string {
+	return "feature_flag:" + key
+}
+
+func toFlag(record db.FeatureFlag) *Flag {
+	return &Flag{
+		ID:                record.ID,
+		Key:               record.Key,
+		Description:       record.Description.String,
+		Enabled:           record.Enabled,
+		RolloutPercentage: record.RolloutPercentage,
+		AllowedRoles:      nonNilStrings(record.AllowedRoles),
+		AllowedUserIDs:    nonNilInts(record.AllowedUserIds),
+		AllowedOrgs:       nonNilStrings(record.AllowedOrgs),
+		CreatedAt:         record.CreatedAt,
+		UpdatedAt:         record.UpdatedAt,
+	}
+}
+
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+func nonNilInts(values []int32) []int32 {
+	if values == nil {
+		return []int32{}
+	}
+	return values
+}
@@ -0,0 +1,58 @@
+package featureflags
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlagEvaluate(t *testing.T) {
+	flag := &Flag{
+		Key:            FlagAIScoringV2,
+		Enabled:        true,
+		AllowedRoles:   []string{"teacher"},
+		AllowedUserIDs: []int32{42},
+		AllowedOrgs:    []string{"acme"},
+	}
+
+	// Explicit targets
+	assert.True(t, flag.Evaluate(EvalContext{UserID: 42}))
+	assert.True(t, flag.Evaluate(EvalContext{UserID: 7, Roles: []string{"student", "teacher"}}))
+	assert.True(t, flag.Evaluate(EvalContext{UserID: 7, Org: "acme"}))
+	assert.False(t, flag.Evaluate(EvalContext{UserID: 7, Roles: []string{"student"}}))
+
+	// Kill switch overrides every target
+	flag.Enabled = false
+	assert.False(t, flag.Evaluate(EvalContext{UserID: 42}))
+
+	// Full rollout
+	flag.Enabled = true
+	flag.RolloutPercentage = 100
+	assert.True(t, flag.Evaluate(EvalContext{UserID: 7}))
+}
+
+func TestFlagPercentageRollout(t *testing.T) {
+	flag := &Flag{Key: FlagAdaptiveExam, Enabled: true, RolloutPercentage: 25}
+
+	enabled := 0
+	for userID := int32(1); userID <= 1000; userID++ {
+		result := flag.Evaluate(EvalContext{UserID: userID})
+		// Evaluation is stable for a user
+		assert.Equal(t, result, flag.Evaluate(EvalContext{UserID: userID}))
+		if result {
+			enabled++
+		}
+	}
+	assert.InDelta(t, 250, enabled, 60)
+
+	// Users in the rollout stay in it when the percentage grows
+	wider := &Flag{Key: FlagAdaptiveExam, Enabled: true, RolloutPercentage: 50}
+	for userID := int32(1); userID <= 1000; userID++ {
+		if flag.Evaluate(EvalContext{UserID: userID}) {
+			assert.True(t, wider.Evaluate(EvalContext{UserID: userID}))
+		}
+	}
+
+	// Anonymous users are never part of a partial rollout
+	assert.False(t, flag.Evaluate(EvalContext{}))
+}
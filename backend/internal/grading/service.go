@@ -0,0 +1,314 @@
+// Package grading lets teachers grade the recorded speaking turns of learners
+// in their organization against a rubric. A teacher claims a turn before
+// grading it so two teachers never grade the same recording; claims expire
+// so abandoned turns return to the queue.
+package grading
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sqlc-dev/pqtype"
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/logger"
+)
+
+// EventSpeakingGraded is sent over WebSocket to a learner whose turn was graded
+const EventSpeakingGraded = "speaking.graded"
+
+var (
+	// ErrTurnNotFound is returned for turns that do not exist, have no
+	// recording or belong to learners outside the grader's organizations
+	ErrTurnNotFound = errors.New("speaking turn not found")
+	// ErrAlreadyClaimed is returned when another grader holds the turn or it was graded
+	ErrAlreadyClaimed = errors.New("speaking turn is already claimed or graded")
+	// ErrNotClaimed is returned when the grader does not hold an unexpired claim on the turn
+	ErrNotClaimed = errors.New("speaking turn is not claimed by you")
+	// ErrInvalidScores is returned when scores do not match the rubric
+	ErrInvalidScores = errors.New("scores do not match the rubric")
+)
+
+// Criterion is a rubric criterion scored from 0 to MaxScore
+type Criterion struct {
+	Key         string `json:"key"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	MaxScore    int    `json:"max_score"`
+}
+
+// Rubric is the speaking rubric, modeled on the TOEIC speaking scoring guide
+var Rubric = []Criterion{
+	{Key: "pronunciation", Name: "Pronunciation", Description: "Sounds are clear and intelligible", MaxScore: 3},
+	{Key: "intonation_stress", Name: "Intonation and stress", Description: "Rhythm, stress and intonation support meaning", MaxScore: 3},
+	{Key: "grammar", Name: "Grammar", Description: "Sentences are accurate and varied", MaxScore: 3},
+	{Key: "vocabulary", Name: "Vocabulary", Description: "Words are precise and appropriate", MaxScore: 3},
+	{Key: "cohesion", Name: "Cohesion", Description: "Ideas are connected and easy to follow", MaxScore: 3},
+	{Key: "relevance", Name: "Relevance", Description: "The response addresses the task completely", MaxScore: 3},
+}
+
+// MaxTotalScore is the highest total score of the rubric
+func MaxTotalScore() int {
+	total := 0
+	for _, criterion := range Rubric {
+		total += criterion.MaxScore
+	}
+	return total
+}
+
+// Notifier delivers real-time events to connected users
+type Notifier interface {
+	SendToUser(userID string, messageType string, data interface{}) error
+}
+
+// Options configure grading
+type Options struct {
+	// ClaimTTL is how long a claim keeps a turn away from other graders
+	ClaimTTL time.Duration
+}
+
+// QueueItem is a recorded turn waiting to be graded
+type QueueItem struct {
+	TurnID          int32     `json:"turn_id"`
+	SessionID       int32     `json:"session_id"`
+	StudentID       int32     `json:"student_id"`
+	StudentUsername string    `json:"student_username"`
+	SessionTopic    string    `json:"session_topic"`
+	Text            string    `json:"text"`
+	AudioURL        string    `json:"audio_url"`
+	AIScore         *float64  `json:"ai_score,omitempty"`
+	SpokenAt        time.Time `json:"spoken_at"`
+	// ClaimedUntil is set when the grader holds a claim on the turn
+	ClaimedUntil *time.Time `json:"claimed_until,omitempty"`
+}
+
+// Claim reserves a turn for a grader
+type Claim struct {
+	TurnID    int32     `json:"turn_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Grade is the rubric grade of a turn
+type Grade struct {
+	TurnID         int32          `json:"turn_id"`
+	SessionID      int32          `json:"session_id,omitempty"`
+	SessionTopic   string         `json:"session_topic,omitempty"`
+	Text           string         `json:"text,omitempty"`
+	Scores         map[string]int `json:"scores"`
+	TotalScore     int            `json:"total_score"`
+	MaxScore       int            `json:"max_score"`
+	Comment        string         `json:"comment,omitempty"`
+	GraderUsername string         `json:"grader_username,omitempty"`
+	GradedAt       time.Time      `json:"graded_at"`
+}
+
+// Service manages the grading queue
+type Service struct {
+	store    db.Querier
+	notifier Notifier
+	options  Options
+	now      func() time.Time
+}
+
+// NewService creates a grading service. notifier may be nil.
+func NewService(store db.Querier, notifier Notifier, options Options) *Service {
+	return &Service{store: store, notifier: notifier, options: options, now: time.Now}
+}
+
+// Queue lists turns the grader can grade, oldest first, with the total
+// count. organizationID 0 includes every organization of the grader.
+func (s *Service) Queue(ctx context.Context, graderID, organizationID, limit, offset int32) ([]QueueItem, int64, error) {
+	now := s.now()
+	organization := sql.NullInt32{Int32: organizationID, Valid: organizationID != 0}
+	rows, err := s.store.ListSpeakingGradingQueue(ctx, db.ListSpeakingGradingQueueParams{
+		GraderID:       graderID,
+		OrganizationID: organization,
+		Now:            now,
+		Limit:          limit,
+		Offset:         offset,
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list grading queue: %w", err)
+	}
+	total, err := s.store.CountSpeakingGradingQueue(ctx, db.CountSpeakingGradingQueueParams{
+		GraderID:       graderID,
+		OrganizationID: organization,
+		Now:            now,
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count grading queue: %w", err)
+	}
+
+	items := make([]QueueItem, 0, len(rows))
+	for _, row := range rows {
+		item := QueueItem{
+			TurnID:          row.TurnID,
+			SessionID:       row.SessionID,
+			StudentID:       row.StudentID,
+			StudentUsername: row.StudentUsername,
+			SessionTopic:    row.SessionTopic,
+			Text:            row.TextSpoken.String,
+			AudioURL:        row.AudioUrl,
+			SpokenAt:        row.SpokenAt,
+		}
+		if score, err := strconv.ParseFloat(row.AiScore.String, 64); row.AiScore.Valid && err == nil {
+			item.AIScore = &score
+		}
+		// Expired claims of other graders are listed too; only unexpired ones are ours
+		if row.ClaimExpiresAt.Valid && row.ClaimExpiresAt.Time.After(now) {
+			item.ClaimedUntil = &row.ClaimExpiresAt.Time
+		}
+		items = append(items, item)
+	}
+	return items, total, nil
+}
+
+// Claim reserves a turn for the grader. Claiming a turn again extends the claim.
+func (s *Service) Claim(ctx context.Context, graderID, turnID int32) (Claim, error) {
+	turn, err := s.store.GetGradableSpeakingTurn(ctx, db.GetGradableSpeakingTurnParams{TurnID: turnID, GraderID: graderID})
+	if errors.Is(err, sql.ErrNoRows) {
+		return Claim{}, ErrTurnNotFound
+	}
+	if err != nil {
+		return Claim{}, fmt.Errorf("failed to get speaking turn: %w", err)
+	}
+
+	now := s.now()
+	grade, err := s.store.ClaimSpeakingTurn(ctx, db.ClaimSpeakingTurnParams{
+		TurnID:         turn.TurnID,
+		StudentID:      turn.StudentID,
+		GraderID:       sql.NullInt32{Int32: graderID, Valid: true},
+		ClaimedAt:      now,
+		ClaimExpiresAt: now.Add(s.options.ClaimTTL),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return Claim{}, ErrAlreadyClaimed
+	}
+	if err != nil {
+		return Claim{}, fmt.Errorf("failed to claim speaking turn: %w", err)
+	}
+	return Claim{TurnID: grade.TurnID, ExpiresAt: grade.ClaimExpiresAt}, nil
+}
+
+// Release returns a claimed turn to the queue
+func (s *Service) Release(ctx context.Context, graderID, turnID int32) error {
+	released, err := s.store.ReleaseSpeakingTurn(ctx, db.ReleaseSpeakingTurnParams{
+		TurnID:   turnID,
+		GraderID: sql.NullInt32{Int32: graderID, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to release speaking turn: %w", err)
+	}
+	if released == 0 {
+		return ErrNotClaimed
+	}
+	return nil
+}
+
+// Grade scores a turn the grader has claimed and notifies the learner
+func (s *Service) Grade(ctx context.Context, graderID, turnID int32, scores map[string]int, comment string) (Grade, error) {
+	total, err := validateScores(scores)
+	if err != nil {
+		return Grade{}, err
+	}
+	data, err := json.Marshal(scores)
+	if err != nil {
+		return Grade{}, fmt.Errorf("failed to encode scores: %w", err)
+	}
+
+	comment = strings.TrimSpace(comment)
+	row, err := s.store.GradeSpeakingTurn(ctx, db.GradeSpeakingTurnParams{
+		Scores:     pqtype.NullRawMessage{RawMessage: data, Valid: true},
+		TotalScore: sql.NullInt32{Int32: int32(total), Valid: true},
+		Comment:    sql.NullString{String: comment, Valid: comment != ""},
+		GradedAt:   s.now(),
+		TurnID:     turnID,
+		GraderID:   sql.NullInt32{Int32: graderID, Valid: true},
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return Grade{}, ErrNotClaimed
+	}
+	if err != nil {
+		return Grade{}, fmt.Errorf("failed to grade speaking turn: %w", err)
+	}
+
+	grade := Grade{
+		TurnID:     row.TurnID,
+		Scores:     scores,
+		TotalScore: total,
+		MaxScore:   MaxTotalScore(),
+		Comment:    row.Comment.String,
+		GradedAt:   row.GradedAt.Time,
+	}
+	s.notify(row.StudentID, EventSpeakingGraded, grade)
+	return grade, nil
+}
+
+// Grades lists the graded turns of a learner, newest first, with the total count
+func (s *Service) Grades(ctx context.Context, studentID, limit, offset int32) ([]Grade, int64, error) {
+	rows, err := s.store.ListStudentSpeakingGrades(ctx, db.ListStudentSpeakingGradesParams{StudentID: studentID, Limit: limit, Offset: offset})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list speaking grades: %w", err)
+	}
+	total, err := s.store.CountStudentSpeakingGrades(ctx, studentID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count speaking grades: %w", err)
+	}
+
+	grades := make([]Grade, 0, len(rows))
+	for _, row := range rows {
+		scores := map[string]int{}
+		if row.Scores.Valid {
+			if err := json.Unmarshal(row.Scores.RawMessage, &scores); err != nil {
+				return nil, 0, fmt.Errorf("failed to decode scores of turn %d: %w", row.TurnID, err)
+			}
+		}
+		grades = append(grades, Grade{
+			TurnID:         row.TurnID,
+			SessionID:      row.SessionID,
+			SessionTopic:   row.SessionTopic,
+			Text:           row.TextSpoken.String,
+			Scores:         scores,
+			TotalScore:     int(row.TotalScore),
+			MaxScore:       MaxTotalScore(),
+			Comment:        row.Comment.String,
+			GraderUsername: row.GraderUsername,
+			GradedAt:       row.GradedAt,
+		})
+	}
+	return grades, total, nil
+}
+
+// validateScores checks that every rubric criterion, and nothing else, is
+// scored within range and returns the total
+func validateScores(scores map[string]int) (int, error) {
+	total := 0
+	for _, criterion := range Rubric {
+		score, ok := scores[criterion.Key]
+		if !ok {
+			return 0, fmt.Errorf("%w: %s is missing", ErrInvalidScores, criterion.Key)
+		}
+		if score < 0 || score > criterion.MaxScore {
+			return 0, fmt.Errorf("%w: %s must be between 0 and %d", ErrInvalidScores, criterion.Key, criterion.MaxScore)
+		}
+		total += score
+	}
+	if len(scores) != len(Rubric) {
+		return 0, fmt.Errorf("%w: unknown criteria", ErrInvalidScores)
+	}
+	return total, nil
+}
+
+func (s *Service) notify(userID int32, event string, data interface{}) {
+	if s.notifier == nil {
+		return
+	}
+	if err := s.notifier.SendToUser(strconv.Itoa(int(userID)), event, data); err != nil {
+		logger.Debug("Failed to send %s to user %d: %v", event, userID, err)
+	}
+}
@@ -0,0 +1,152 @@
+package grading
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+// fakeStore keeps grades in memory; turn 1 by learner 7 is gradable
+type fakeStore struct {
+	db.Querier
+	grades map[int32]db.SpeakingGrade
+}
+
+func (s *fakeStore) GetGradableSpeakingTurn(_ context.Context, arg db.GetGradableSpeakingTurnParams) (db.GetGradableSpeakingTurnRow, error) {
+	if arg.TurnID != 1 {
+		return db.GetGradableSpeakingTurnRow{}, sql.ErrNoRows
+	}
+	return db.GetGradableSpeakingTurnRow{TurnID: 1, StudentID: 7}, nil
+}
+
+func (s *fakeStore) ClaimSpeakingTurn(_ context.Context, arg db.ClaimSpeakingTurnParams) (db.SpeakingGrade, error) {
+	grade, exists := s.grades[arg.TurnID]
+	if exists && (grade.Status != "claimed" || (grade.GraderID != arg.GraderID && grade.ClaimExpiresAt.After(arg.ClaimedAt))) {
+		return db.SpeakingGrade{}, sql.ErrNoRows
+	}
+	grade = db.SpeakingGrade{
+		TurnID:         arg.TurnID,
+		StudentID:      arg.StudentID,
+		GraderID:       arg.GraderID,
+		Status:         "claimed",
+		ClaimedAt:      arg.ClaimedAt,
+		ClaimExpiresAt: arg.ClaimExpiresAt,
+	}
+	s.grades[arg.TurnID] = grade
+	return grade, nil
+}
+
+func (s *fakeStore) ReleaseSpeakingTurn(_ context.Context, arg db.ReleaseSpeakingTurnParams) (int64, error) {
+	grade, exists := s.grades[arg.TurnID]
+	if !exists || grade.GraderID != arg.GraderID || grade.Status != "claimed" {
+		return 0, nil
+	}
+	delete(s.grades, arg.TurnID)
+	return 1, nil
+}
+
+func (s *fakeStore) GradeSpeakingTurn(_ context.Context, arg db.GradeSpeakingTurnParams) (db.SpeakingGrade, error) {
+	grade, exists := s.grades[arg.TurnID]
+	if !exists || grade.GraderID != arg.GraderID || grade.Status != "claimed" || !grade.ClaimExpiresAt.After(arg.GradedAt) {
+		return db.SpeakingGrade{}, sql.ErrNoRows
+	}
+	grade.Status = "graded"
+	grade.Scores = arg.Scores
+	grade.TotalScore = arg.TotalScore
+	grade.Comment = arg.Comment
+	grade.GradedAt = sql.NullTime{Time: arg.GradedAt, Valid: true}
+	s.grades[arg.TurnID] = grade
+	return grade, nil
+}
+
+// recorder collects notifications
+type recorder struct {
+	users  []string
+	events []string
+}
+
+func (r *recorder) SendToUser(userID string, messageType string, data interface{}) error {
+	r.users = append(r.users, userID)
+	r.events = append(r.events, messageType)
+	return nil
+}
+
+func fullScores() map[string]int {
+	scores := map[string]int{}
+	for _, criterion := range Rubric {
+		scores[criterion.Key] = 2
+	}
+	return scores
+}
+
+func TestValidateScores(t *testing.T) {
+	total, err := validateScores(fullScores())
+	require.NoError(t, err)
+	assert.Equal(t, 12, total)
+	assert.Equal(t, 18, MaxTotalScore())
+
+	scores := fullScores()
+	delete(scores, "grammar")
+	_, err = validateScores(scores)
+	assert.ErrorIs(t, err, ErrInvalidScores)
+
+	scores = fullScores()
+	scores["grammar"] = 4
+	_, err = validateScores(scores)
+	assert.ErrorIs(t, err, ErrInvalidScores)
+
+	scores = fullScores()
+	scores["fluency"] = 1
+	_, err = validateScores(scores)
+	assert.ErrorIs(t, err, ErrInvalidScores)
+}
+
+func TestClaimAndGrade(t *testing.T) {
+	store := &fakeStore{grades: map[int32]db.SpeakingGrade{}}
+	notifier := &recorder{}
+	service := NewService(store, notifier, Options{ClaimTTL: 15 * time.Minute})
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	_, err := service.Claim(ctx, 3, 2)
+	assert.ErrorIs(t, err, ErrTurnNotFound)
+
+	claim, err := service.Claim(ctx, 3, 1)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(15*time.Minute), claim.ExpiresAt)
+
+	// Another grader has to wait for the claim to be released or expire
+	_, err = service.Claim(ctx, 4, 1)
+	assert.ErrorIs(t, err, ErrAlreadyClaimed)
+	_, err = service.Grade(ctx, 4, 1, fullScores(), "")
+	assert.ErrorIs(t, err, ErrNotClaimed)
+	assert.ErrorIs(t, service.Release(ctx, 4, 1), ErrNotClaimed)
+
+	require.NoError(t, service.Release(ctx, 3, 1))
+	_, err = service.Claim(ctx, 4, 1)
+	require.NoError(t, err)
+
+	now = now.Add(20 * time.Minute)
+	_, err = service.Grade(ctx, 4, 1, fullScores(), "")
+	assert.ErrorIs(t, err, ErrNotClaimed)
+	_, err = service.Claim(ctx, 3, 1)
+	require.NoError(t, err)
+
+	grade, err := service.Grade(ctx, 3, 1, fullScores(), "  Work on linking words.  ")
+	require.NoError(t, err)
+	assert.Equal(t, 12, grade.TotalScore)
+	assert.Equal(t, 18, grade.MaxScore)
+	assert.Equal(t, "Work on linking words.", grade.Comment)
+	assert.Equal(t, []string{"7"}, notifier.users)
+	assert.Equal(t, []string{EventSpeakingGraded}, notifier.events)
+
+	// Graded turns cannot be claimed again
+	_, err = service.Claim(ctx, 4, 1)
+	assert.ErrorIs(t, err, ErrAlreadyClaimed)
+}
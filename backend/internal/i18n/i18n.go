@@ -142,6 +142,19 @@ func (i *I18n) GetMessage(lang SupportedLanguage, key string, args ...interface{
 	return key
 }
 
+// Lookup returns the message for a key without formatting it, falling back
+// to the fallback language, and whether a message was found
+func (i *I18n) Lookup(lang SupportedLanguage, key string) (string, bool) {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+
+	if message, exists := i.messages[lang][key]; exists {
+		return message, true
+	}
+	message, exists := i.messages[i.fallbackLang][key]
+	return message, exists
+}
+
 // GetLanguageFromContext extracts language from Gin context
 func GetLanguageFromContext(c *gin.Context) SupportedLanguage {
 	if lang, exists := c.Get(LanguageContextKey); exists {
@@ -7,17 +7,33 @@ import (
 	"github.com/toeic-app/internal/logger"
 )
 
+// Sources of the language of a request, from the highest precedence to the
+// lowest. The source is reported in the X-Language-Source header.
+const (
+	SourceQuery          = "query"           // ?lang= parameter, meant for debugging
+	SourceHeader         = "header"          // X-Language header
+	SourceAcceptLanguage = "accept-language" // Accept-Language header
+	SourceProfile        = "profile"         // Preferred language of the authenticated user
+	SourceDefault        = "default"
+)
+
+const (
+	// LanguageSourceHeader is the response header telling where the language came from
+	LanguageSourceHeader = "X-Language-Source"
+	// languageSourceContextKey is the context key of the language source
+	languageSourceContextKey = "language_source"
+)
+
 // LanguageMiddleware returns a middleware that detects and sets the language preference
 func LanguageMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		lang := detectLanguage(c)
-		SetLanguageInContext(c, lang)
-		// Add language to response headers for client awareness
-		c.Header("Content-Language", string(lang))
+		lang, source := detectLanguage(c)
+		setLanguage(c, lang, source)
 
 		fields := logger.Fields{
 			"component": "i18n_middleware",
 			"language":  string(lang),
+			"source":    source,
 			"method":    c.Request.Method,
 			"path":      c.Request.URL.Path,
 		}
@@ -27,37 +43,75 @@ func LanguageMiddleware() gin.HandlerFunc {
 	}
 }
 
-// detectLanguage detects the preferred language from various sources
-func detectLanguage(c *gin.Context) SupportedLanguage {
+// setLanguage sets the language of a request and reports it, with its
+// source, in the response headers
+func setLanguage(c *gin.Context, lang SupportedLanguage, source string) {
+	SetLanguageInContext(c, lang)
+	c.Set(languageSourceContextKey, source)
+	// Add language to response headers for client awareness
+	c.Header("Content-Language", string(lang))
+	c.Header(LanguageSourceHeader, source)
+}
+
+// GetLanguageSource returns where the language of a request came from
+func GetLanguageSource(c *gin.Context) string {
+	if source, ok := c.Get(languageSourceContextKey); ok {
+		if s, ok := source.(string); ok {
+			return s
+		}
+	}
+	return SourceDefault
+}
+
+// ApplyPreferredLanguage uses a user's preferred language for a request
+// that did not ask for a language. Explicit choices of the request win, and
+// unsupported languages are ignored. It reports whether the language was
+// applied.
+func ApplyPreferredLanguage(c *gin.Context, preferred string) bool {
+	if preferred == "" || GetLanguageSource(c) != SourceDefault {
+		return false
+	}
+	lang := parseLanguageParam(preferred)
+	if !GetI18n().IsSupported(lang) {
+		return false
+	}
+	setLanguage(c, lang, SourceProfile)
+	return true
+}
+
+// detectLanguage detects the preferred language from various sources and
+// returns it with its source
+func detectLanguage(c *gin.Context) (SupportedLanguage, string) {
 	// Priority order:
 	// 1. Query parameter 'lang'
-	// 2. Accept-Language header
-	// 3. X-Language header (custom header)
-	// 4. Default language
+	// 2. X-Language header (custom header)
+	// 3. Accept-Language header
+	// 4. Default language, replaced by the user's preferred language once
+	//    the request is authenticated (see ApplyPreferredLanguage)
 
 	// Check query parameter first
 	if langParam := c.Query("lang"); langParam != "" {
 		if lang := parseLanguageParam(langParam); GetI18n().IsSupported(lang) {
-			return lang
+			return lang, SourceQuery
 		}
 	}
 
 	// Check custom X-Language header
 	if langHeader := c.GetHeader("X-Language"); langHeader != "" {
 		if lang := parseLanguageParam(langHeader); GetI18n().IsSupported(lang) {
-			return lang
+			return lang, SourceHeader
 		}
 	}
 
 	// Check Accept-Language header
 	if acceptLang := c.GetHeader(LanguageHeaderKey); acceptLang != "" {
 		if lang := ParseLanguageFromHeader(acceptLang); GetI18n().IsSupported(lang) {
-			return lang
+			return lang, SourceAcceptLanguage
 		}
 	}
 
 	// Fallback to default language
-	return DefaultLanguage
+	return DefaultLanguage, SourceDefault
 }
 
 // parseLanguageParam parses language parameter and returns appropriate SupportedLanguage
@@ -76,7 +130,8 @@ func parseLanguageParam(param string) SupportedLanguage {
 
 // GetLanguageFromRequest extracts language from request without middleware
 func GetLanguageFromRequest(c *gin.Context) SupportedLanguage {
-	return detectLanguage(c)
+	lang, _ := detectLanguage(c)
+	return lang
 }
 
 // WithLanguage wraps a handler to force a specific language
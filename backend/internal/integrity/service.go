@@ -0,0 +1,237 @@
+// Package integrity stores the focus losses, tab switches and pastes the
+// client reports while an exam attempt is in progress and scores how likely
+// the attempt was taken without outside help.
+package integrity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+// Event types reported by the client
+const (
+	// EventFocusLoss is the exam window losing focus, e.g. to another app
+	EventFocusLoss = "focus_loss"
+	// EventTabSwitch is the exam tab being hidden by another browser tab
+	EventTabSwitch = "tab_switch"
+	// EventPaste is text pasted into an answer
+	EventPaste = "paste"
+)
+
+// Event types recorded by the server when listening audio is played
+const (
+	// EventAudioPlay is a question's audio being played
+	EventAudioPlay = "audio_play"
+	// EventAudioReplayDenied is a play refused because the question's play
+	// limit was reached or its playback token expired
+	EventAudioReplayDenied = "audio_replay_denied"
+)
+
+// MaxBatch is the number of events a client may report at once
+const MaxBatch = 100
+
+// maxListedEvents is the number of events returned with a report
+const maxListedEvents = 1000
+
+// Integrity levels, from the score
+const (
+	LevelClean      = "clean"
+	LevelReview     = "review"
+	LevelSuspicious = "suspicious"
+)
+
+// Points taken off the score of 100
+const (
+	focusLossPenalty = 3
+	tabSwitchPenalty = 5
+	pastePenalty     = 10
+	// replayDeniedPenalty is taken off for every refused replay; plays
+	// within the limit cost nothing
+	replayDeniedPenalty = 5
+	// awayPenalty is taken off for every full minute out of focus
+	awayPenalty = 2
+)
+
+var ErrUnknownEventType = errors.New("unknown integrity event type")
+
+// EventTypes lists the valid event types, including those only the
+// server records
+func EventTypes() []string {
+	return []string{EventFocusLoss, EventTabSwitch, EventPaste, EventAudioPlay, EventAudioReplayDenied}
+}
+
+func validType(eventType string) bool {
+	for _, known := range EventTypes() {
+		if eventType == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Event is one reported event
+type Event struct {
+	Type       string    `json:"type"`
+	OccurredAt time.Time `json:"occurred_at"`
+	// DurationMs is how long the exam was out of focus, for focus losses
+	// and tab switches
+	DurationMs int32 `json:"duration_ms,omitempty"`
+	// PasteLength is the number of characters pasted
+	PasteLength int32 `json:"paste_length,omitempty"`
+}
+
+// Summary is the integrity score of an attempt with the counts it is
+// computed from
+type Summary struct {
+	// Score is 100 for an attempt without events and falls to 0
+	Score       int    `json:"score"`
+	Level       string `json:"level"`
+	FocusLosses int64  `json:"focus_losses"`
+	TabSwitches int64  `json:"tab_switches"`
+	Pastes      int64  `json:"pastes"`
+	TimeAwayMs  int64  `json:"time_away_ms"`
+	PastedChars int64  `json:"pasted_chars"`
+	AudioPlays  int64  `json:"audio_plays"`
+	// DeniedReplays are plays refused by the listening play limit
+	DeniedReplays int64 `json:"denied_replays"`
+}
+
+// Report is the summary of an attempt with its events, oldest first
+type Report struct {
+	AttemptID int32   `json:"attempt_id"`
+	UserID    int32   `json:"user_id"`
+	Summary   Summary `json:"summary"`
+	Events    []Event `json:"events"`
+}
+
+// score computes the integrity score and level from the counts
+func (s *Summary) score() {
+	penalty := s.FocusLosses*focusLossPenalty +
+		s.TabSwitches*tabSwitchPenalty +
+		s.Pastes*pastePenalty +
+		s.DeniedReplays*replayDeniedPenalty +
+		s.TimeAwayMs/int64(time.Minute/time.Millisecond)*awayPenalty
+	s.Score = 100 - int(min(penalty, 100))
+
+	switch {
+	case s.Score >= 90:
+		s.Level = LevelClean
+	case s.Score >= 60:
+		s.Level = LevelReview
+	default:
+		s.Level = LevelSuspicious
+	}
+}
+
+// Service records integrity events and scores attempts
+type Service struct {
+	store db.Querier
+	now   func() time.Time
+}
+
+// NewService creates an integrity service
+func NewService(store db.Querier) *Service {
+	return &Service{store: store, now: time.Now}
+}
+
+// Record stores events reported during attempt. Times are moved into the
+// attempt, between its start and now, since client clocks cannot be
+// trusted; events without a time are stored as happening now.
+func (s *Service) Record(ctx context.Context, attempt db.ExamAttempt, events []Event) error {
+	now := s.now()
+	arg := db.CreateIntegrityEventsParams{AttemptID: attempt.AttemptID}
+	for _, event := range events {
+		if !validType(event.Type) {
+			return fmt.Errorf("%w: %q", ErrUnknownEventType, event.Type)
+		}
+		occurredAt := event.OccurredAt
+		if occurredAt.IsZero() || occurredAt.After(now) {
+			occurredAt = now
+		}
+		if occurredAt.Before(attempt.StartTime) {
+			occurredAt = attempt.StartTime
+		}
+
+		// Durations only apply to leaving the exam and lengths to pastes
+		var duration, length int32
+		switch event.Type {
+		case EventPaste:
+			length = max(event.PasteLength, 0)
+		case EventFocusLoss, EventTabSwitch:
+			duration = max(event.DurationMs, 0)
+		}
+
+		arg.EventTypes = append(arg.EventTypes, event.Type)
+		arg.OccurredAts = append(arg.OccurredAts, occurredAt.UTC().Format(time.RFC3339Nano))
+		arg.DurationsMs = append(arg.DurationsMs, duration)
+		arg.PasteLengths = append(arg.PasteLengths, length)
+	}
+	if len(arg.EventTypes) == 0 {
+		return nil
+	}
+	if err := s.store.CreateIntegrityEvents(ctx, arg); err != nil {
+		return fmt.Errorf("failed to record integrity events: %w", err)
+	}
+	return nil
+}
+
+// Summarize scores an attempt from its events
+func (s *Service) Summarize(ctx context.Context, attemptID int32) (*Summary, error) {
+	rows, err := s.store.SummarizeIntegrityEvents(ctx, attemptID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize integrity events: %w", err)
+	}
+	summary := &Summary{}
+	for _, row := range rows {
+		switch row.EventType {
+		case EventFocusLoss:
+			summary.FocusLosses = row.Events
+		case EventTabSwitch:
+			summary.TabSwitches = row.Events
+		case EventPaste:
+			summary.Pastes = row.Events
+		case EventAudioPlay:
+			summary.AudioPlays = row.Events
+		case EventAudioReplayDenied:
+			summary.DeniedReplays = row.Events
+		}
+		summary.TimeAwayMs += row.DurationMs
+		summary.PastedChars += row.PasteLength
+	}
+	summary.score()
+	return summary, nil
+}
+
+// Report returns the summary of an attempt with its events
+func (s *Service) Report(ctx context.Context, attempt db.ExamAttempt) (*Report, error) {
+	summary, err := s.Summarize(ctx, attempt.AttemptID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.store.ListIntegrityEvents(ctx, db.ListIntegrityEventsParams{
+		AttemptID: attempt.AttemptID,
+		Limit:     maxListedEvents,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list integrity events: %w", err)
+	}
+	report := &Report{
+		AttemptID: attempt.AttemptID,
+		UserID:    attempt.UserID,
+		Summary:   *summary,
+		Events:    make([]Event, 0, len(rows)),
+	}
+	for _, row := range rows {
+		report.Events = append(report.Events, Event{
+			Type:        row.EventType,
+			OccurredAt:  row.OccurredAt,
+			DurationMs:  row.DurationMs,
+			PasteLength: row.PasteLength,
+		})
+	}
+	return report, nil
+}
@@ -0,0 +1,138 @@
+package integrity
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+// fakeStore keeps the events of one attempt in memory
+type fakeStore struct {
+	db.Querier
+	events []db.ExamAttemptIntegrityEvent
+}
+
+func (f *fakeStore) CreateIntegrityEvents(_ context.Context, arg db.CreateIntegrityEventsParams) error {
+	for i := range arg.EventTypes {
+		occurredAt, err := time.Parse(time.RFC3339Nano, arg.OccurredAts[i])
+		if err != nil {
+			return err
+		}
+		f.events = append(f.events, db.ExamAttemptIntegrityEvent{
+			ID:          int64(len(f.events) + 1),
+			AttemptID:   arg.AttemptID,
+			EventType:   arg.EventTypes[i],
+			OccurredAt:  occurredAt,
+			DurationMs:  arg.DurationsMs[i],
+			PasteLength: arg.PasteLengths[i],
+		})
+	}
+	return nil
+}
+
+func (f *fakeStore) SummarizeIntegrityEvents(_ context.Context, attemptID int32) ([]db.SummarizeIntegrityEventsRow, error) {
+	byType := make(map[string]*db.SummarizeIntegrityEventsRow)
+	var rows []db.SummarizeIntegrityEventsRow
+	for _, event := range f.events {
+		row, ok := byType[event.EventType]
+		if !ok {
+			row = &db.SummarizeIntegrityEventsRow{EventType: event.EventType}
+			byType[event.EventType] = row
+		}
+		row.Events++
+		row.DurationMs += int64(event.DurationMs)
+		row.PasteLength += int64(event.PasteLength)
+	}
+	for _, row := range byType {
+		rows = append(rows, *row)
+	}
+	return rows, nil
+}
+
+func (f *fakeStore) ListIntegrityEvents(_ context.Context, arg db.ListIntegrityEventsParams) ([]db.ExamAttemptIntegrityEvent, error) {
+	return f.events, nil
+}
+
+func TestRecordMovesEventsIntoAttempt(t *testing.T) {
+	now := time.Date(2026, 5, 1, 10, 30, 0, 0, time.UTC)
+	attempt := db.ExamAttempt{AttemptID: 4, UserID: 7, StartTime: now.Add(-30 * time.Minute)}
+	store := &fakeStore{}
+	service := NewService(store)
+	service.now = func() time.Time { return now }
+
+	err := service.Record(context.Background(), attempt, []Event{
+		{Type: EventFocusLoss, OccurredAt: now.Add(-time.Hour), DurationMs: 4000, PasteLength: 9},
+		{Type: EventPaste, OccurredAt: now.Add(time.Hour), DurationMs: 100, PasteLength: 42},
+		{Type: EventTabSwitch},
+	})
+	require.NoError(t, err)
+	require.Len(t, store.events, 3)
+
+	assert.Equal(t, attempt.StartTime, store.events[0].OccurredAt)
+	assert.Equal(t, int32(4000), store.events[0].DurationMs)
+	assert.Zero(t, store.events[0].PasteLength)
+	assert.Equal(t, now, store.events[1].OccurredAt)
+	assert.Zero(t, store.events[1].DurationMs)
+	assert.Equal(t, int32(42), store.events[1].PasteLength)
+	assert.Equal(t, now, store.events[2].OccurredAt)
+}
+
+func TestRecordRejectsUnknownType(t *testing.T) {
+	store := &fakeStore{}
+	err := NewService(store).Record(context.Background(), db.ExamAttempt{AttemptID: 1},
+		[]Event{{Type: EventPaste}, {Type: "screenshot"}})
+	assert.ErrorIs(t, err, ErrUnknownEventType)
+	assert.Empty(t, store.events)
+}
+
+func TestSummarizeScoresAttempt(t *testing.T) {
+	store := &fakeStore{}
+	service := NewService(store)
+
+	summary, err := service.Summarize(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, 100, summary.Score)
+	assert.Equal(t, LevelClean, summary.Level)
+
+	require.NoError(t, service.Record(context.Background(), db.ExamAttempt{AttemptID: 1}, []Event{
+		{Type: EventTabSwitch, DurationMs: 90000},
+		{Type: EventFocusLoss, DurationMs: 5000},
+		{Type: EventPaste, PasteLength: 120},
+	}))
+	summary, err = service.Summarize(context.Background(), 1)
+	require.NoError(t, err)
+	// 5 + 3 + 10 for the events and 2 for the full minute away
+	assert.Equal(t, 80, summary.Score)
+	assert.Equal(t, LevelReview, summary.Level)
+	assert.Equal(t, int64(95000), summary.TimeAwayMs)
+	assert.Equal(t, int64(120), summary.PastedChars)
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, service.Record(context.Background(), db.ExamAttempt{AttemptID: 1}, []Event{{Type: EventPaste}}))
+	}
+	summary, err = service.Summarize(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Zero(t, summary.Score)
+	assert.Equal(t, LevelSuspicious, summary.Level)
+}
+
+func TestSummarizePenalizesOnlyDeniedReplays(t *testing.T) {
+	store := &fakeStore{}
+	service := NewService(store)
+
+	require.NoError(t, service.Record(context.Background(), db.ExamAttempt{AttemptID: 1}, []Event{
+		{Type: EventAudioPlay, DurationMs: 3000},
+		{Type: EventAudioPlay},
+		{Type: EventAudioReplayDenied},
+	}))
+	summary, err := service.Summarize(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, 95, summary.Score)
+	assert.Equal(t, int64(2), summary.AudioPlays)
+	assert.Equal(t, int64(1), summary.DeniedReplays)
+	assert.Zero(t, summary.TimeAwayMs)
+}
@@ -0,0 +1,182 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+// KindAccountData exports everything the app stores about the user who
+// starts it, for data portability requests
+const KindAccountData = "account_data"
+
+// accountDataPageSize is how many exam attempts are read at a time
+const accountDataPageSize = 500
+
+type accountData struct {
+	ExportedAt       time.Time         `json:"exported_at"`
+	User             accountUser       `json:"user"`
+	Profile          *accountProfile   `json:"profile"`
+	Writings         []accountWriting  `json:"writings"`
+	SpeakingSessions []accountSpeaking `json:"speaking_sessions"`
+	ExamAttempts     []accountAttempt  `json:"exam_attempts"`
+}
+
+type accountUser struct {
+	ID        int32     `json:"id"`
+	Username  string    `json:"username"`
+	Email     string    `json:"email,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type accountProfile struct {
+	FullName       string     `json:"full_name,omitempty"`
+	Bio            string     `json:"bio,omitempty"`
+	AvatarURL      string     `json:"avatar_url,omitempty"`
+	TargetScore    *int32     `json:"target_score,omitempty"`
+	ExamDate       *time.Time `json:"exam_date,omitempty"`
+	NativeLanguage string     `json:"native_language,omitempty"`
+}
+
+type accountWriting struct {
+	ID             int32     `json:"id"`
+	PromptID       *int32    `json:"prompt_id,omitempty"`
+	SubmissionText string    `json:"submission_text"`
+	AIScore        string    `json:"ai_score,omitempty"`
+	SubmittedAt    time.Time `json:"submitted_at"`
+}
+
+type accountSpeaking struct {
+	ID        int32      `json:"id"`
+	Topic     string     `json:"topic,omitempty"`
+	StartTime time.Time  `json:"start_time"`
+	EndTime   *time.Time `json:"end_time,omitempty"`
+}
+
+type accountAttempt struct {
+	AttemptID int32      `json:"attempt_id"`
+	ExamID    int32      `json:"exam_id"`
+	StartTime time.Time  `json:"start_time"`
+	EndTime   *time.Time `json:"end_time,omitempty"`
+	Score     string     `json:"score,omitempty"`
+	Status    string     `json:"status"`
+}
+
+// AccountData exports the account, profile, writings, speaking sessions and
+// exam attempts of the job's owner as a JSON document
+func AccountData(store db.Querier) Handler {
+	return Handler{
+		Extension:   "json",
+		ContentType: "application/json",
+		Run: func(ctx context.Context, job *Job, out io.Writer, progress ProgressFunc) error {
+			data := accountData{ExportedAt: time.Now().UTC()}
+
+			progress(0, "Exporting account")
+			user, err := store.GetUser(ctx, job.UserID)
+			if err != nil {
+				return fmt.Errorf("failed to get user: %w", err)
+			}
+			data.User = accountUser{ID: user.ID, Username: user.Username, Email: user.Email.String, CreatedAt: user.CreatedAt}
+
+			profile, err := store.GetUserProfileByUserID(ctx, sql.NullInt32{Int32: job.UserID, Valid: true})
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("failed to get profile: %w", err)
+			}
+			if err == nil {
+				data.Profile = &accountProfile{
+					FullName:       profile.FullName.String,
+					Bio:            profile.Bio.String,
+					AvatarURL:      profile.AvatarUrl.String,
+					TargetScore:    nullInt32(profile.TargetScore),
+					ExamDate:       nullTime(profile.ExamDate),
+					NativeLanguage: profile.NativeLanguage.String,
+				}
+			}
+
+			progress(20, "Exporting writings")
+			writings, err := store.ListUserWritingsByUserID(ctx, job.UserID)
+			if err != nil {
+				return fmt.Errorf("failed to list writings: %w", err)
+			}
+			data.Writings = make([]accountWriting, 0, len(writings))
+			for _, writing := range writings {
+				data.Writings = append(data.Writings, accountWriting{
+					ID:             writing.ID,
+					PromptID:       nullInt32(writing.PromptID),
+					SubmissionText: writing.SubmissionText,
+					AIScore:        writing.AiScore.String,
+					SubmittedAt:    writing.SubmittedAt,
+				})
+			}
+
+			progress(40, "Exporting speaking sessions")
+			sessions, err := store.ListSpeakingSessionsByUserID(ctx, job.UserID)
+			if err != nil {
+				return fmt.Errorf("failed to list speaking sessions: %w", err)
+			}
+			data.SpeakingSessions = make([]accountSpeaking, 0, len(sessions))
+			for _, session := range sessions {
+				data.SpeakingSessions = append(data.SpeakingSessions, accountSpeaking{
+					ID:        session.ID,
+					Topic:     session.SessionTopic.String,
+					StartTime: session.StartTime,
+					EndTime:   nullTime(session.EndTime),
+				})
+			}
+
+			progress(60, "Exporting exam attempts")
+			data.ExamAttempts = []accountAttempt{}
+			for offset := int32(0); ; offset += accountDataPageSize {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				attempts, err := store.ListExamAttemptsByUser(ctx, db.ListExamAttemptsByUserParams{
+					UserID: job.UserID,
+					Limit:  accountDataPageSize,
+					Offset: offset,
+				})
+				if err != nil {
+					return fmt.Errorf("failed to list exam attempts: %w", err)
+				}
+				for _, attempt := range attempts {
+					data.ExamAttempts = append(data.ExamAttempts, accountAttempt{
+						AttemptID: attempt.AttemptID,
+						ExamID:    attempt.ExamID,
+						StartTime: attempt.StartTime,
+						EndTime:   nullTime(attempt.EndTime),
+						Score:     attempt.Score.String,
+						Status:    string(attempt.Status),
+					})
+				}
+				if len(attempts) < accountDataPageSize {
+					break
+				}
+			}
+
+			progress(90, "Writing file")
+			encoder := json.NewEncoder(out)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(data)
+		},
+	}
+}
+
+func nullInt32(value sql.NullInt32) *int32 {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Int32
+}
+
+func nullTime(value sql.NullTime) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Time
+}
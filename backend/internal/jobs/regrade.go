@@ -0,0 +1,119 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+
+	"github.com/toeic-app/internal/examservice"
+	"github.com/toeic-app/internal/logger"
+)
+
+// KindRegradeAnswers checks the past answers to questions again after their
+// correct answer was fixed, rescores the completed attempts they belong to
+// and tells the users whose attempts changed
+const KindRegradeAnswers = "regrade_answers"
+
+// EventAnswersRegraded is pushed to a user whose attempt was re-graded
+const EventAnswersRegraded = "exam.answers_regraded"
+
+// RegradeParams are the parameters of a regrade_answers job
+type RegradeParams struct {
+	QuestionIDs []int32 `json:"question_ids"`
+}
+
+// RegradedAttempt is an attempt with answers that were re-graded
+type RegradedAttempt struct {
+	examservice.Rescore
+	QuestionIDs []int32 `json:"question_ids"`
+	Answers     int     `json:"regraded_answers"`
+}
+
+type regradeReport struct {
+	QuestionIDs      []int32           `json:"question_ids"`
+	MissingQuestions []int32           `json:"missing_questions,omitempty"`
+	RegradedAnswers  int               `json:"regraded_answers"`
+	Attempts         []RegradedAttempt `json:"attempts"`
+}
+
+// RegradeAnswers re-grades the answers to the questions in the job's
+// params and writes a JSON report of the attempts it changed. Users are
+// notified of their attempts through notifier.
+func RegradeAnswers(exams examservice.Service, notifier Notifier) Handler {
+	return Handler{
+		Permission:  "exams.grade",
+		Extension:   "json",
+		ContentType: "application/json",
+		Run: func(ctx context.Context, job *Job, out io.Writer, progress ProgressFunc) error {
+			var params RegradeParams
+			if err := json.Unmarshal(job.Params, &params); err != nil || len(params.QuestionIDs) == 0 {
+				return fmt.Errorf("%w: question_ids required", ErrInvalidParams)
+			}
+			report := regradeReport{QuestionIDs: params.QuestionIDs, Attempts: []RegradedAttempt{}}
+
+			attempts := make(map[int32]*RegradedAttempt)
+			for i, questionID := range params.QuestionIDs {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				progress(i*50/len(params.QuestionIDs), fmt.Sprintf("Re-grading answers to question %d", questionID))
+				regraded, err := exams.RegradeQuestion(ctx, questionID)
+				if errors.Is(err, examservice.ErrQuestionNotFound) {
+					report.MissingQuestions = append(report.MissingQuestions, questionID)
+					continue
+				}
+				if err != nil {
+					return fmt.Errorf("failed to re-grade answers to question %d: %w", questionID, err)
+				}
+
+				report.RegradedAnswers += len(regraded)
+				for _, answer := range regraded {
+					attempt, ok := attempts[answer.AttemptID]
+					if !ok {
+						attempt = &RegradedAttempt{}
+						attempts[answer.AttemptID] = attempt
+					}
+					attempt.Answers++
+					if !slices.Contains(attempt.QuestionIDs, questionID) {
+						attempt.QuestionIDs = append(attempt.QuestionIDs, questionID)
+					}
+				}
+			}
+
+			attemptIDs := make([]int32, 0, len(attempts))
+			for attemptID := range attempts {
+				attemptIDs = append(attemptIDs, attemptID)
+			}
+			slices.Sort(attemptIDs)
+			for i, attemptID := range attemptIDs {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				progress(50+i*45/len(attemptIDs), fmt.Sprintf("Rescoring %d attempts", len(attemptIDs)))
+				rescore, err := exams.RescoreAttempt(ctx, attemptID)
+				if errors.Is(err, examservice.ErrAttemptNotFound) {
+					continue // Deleted since
+				}
+				if err != nil {
+					return fmt.Errorf("failed to rescore attempt %d: %w", attemptID, err)
+				}
+
+				attempt := attempts[attemptID]
+				attempt.Rescore = rescore
+				report.Attempts = append(report.Attempts, *attempt)
+				if err := notifier.SendToUser(strconv.Itoa(int(rescore.UserID)), EventAnswersRegraded, attempt); err != nil {
+					logger.Debug("Failed to notify user %d of re-graded attempt %d: %v", rescore.UserID, attemptID, err)
+				}
+			}
+
+			progress(95, "Writing report")
+			encoder := json.NewEncoder(out)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(report)
+		},
+	}
+}
@@ -0,0 +1,87 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/examservice"
+)
+
+// fakeExams re-grades from fixed results
+type fakeExams struct {
+	examservice.Service
+	regraded map[int32][]db.RegradeUserAnswersRow
+	rescores map[int32]examservice.Rescore
+	rescored []int32
+}
+
+func (e *fakeExams) RegradeQuestion(ctx context.Context, questionID int32) ([]db.RegradeUserAnswersRow, error) {
+	rows, ok := e.regraded[questionID]
+	if !ok {
+		return nil, examservice.ErrQuestionNotFound
+	}
+	return rows, nil
+}
+
+func (e *fakeExams) RescoreAttempt(ctx context.Context, attemptID int32) (examservice.Rescore, error) {
+	e.rescored = append(e.rescored, attemptID)
+	rescore, ok := e.rescores[attemptID]
+	if !ok {
+		return rescore, examservice.ErrAttemptNotFound
+	}
+	return rescore, nil
+}
+
+// userEvents records the events pushed to users
+type userEvents struct {
+	users  []string
+	events []RegradedAttempt
+}
+
+func (n *userEvents) SendToUser(userID string, messageType string, data interface{}) error {
+	n.users = append(n.users, userID+" "+messageType)
+	n.events = append(n.events, *data.(*RegradedAttempt))
+	return nil
+}
+
+func TestRegradeAnswers(t *testing.T) {
+	exams := &fakeExams{
+		regraded: map[int32][]db.RegradeUserAnswersRow{
+			10: {{UserAnswerID: 1, AttemptID: 5}, {UserAnswerID: 2, AttemptID: 4, IsCorrect: true}},
+			11: {{UserAnswerID: 3, AttemptID: 5, IsCorrect: true}, {UserAnswerID: 4, AttemptID: 6}},
+			12: {},
+		},
+		rescores: map[int32]examservice.Rescore{
+			4: {AttemptID: 4, UserID: 8, ExamID: 3, OldScore: "500", NewScore: "600"},
+			5: {AttemptID: 5, UserID: 7, ExamID: 3, OldScore: "700", NewScore: "700"},
+		},
+	}
+	notifier := &userEvents{}
+	handler := RegradeAnswers(exams, notifier)
+	assert.Equal(t, "exams.grade", handler.Permission)
+
+	var out bytes.Buffer
+	job := &Job{Params: json.RawMessage(`{"question_ids":[10,11,12,99]}`)}
+	require.NoError(t, handler.Run(context.Background(), job, &out, func(int, string) {}))
+
+	// Attempts are rescored once each, in order; attempt 6 was deleted
+	assert.Equal(t, []int32{4, 5, 6}, exams.rescored)
+	assert.Equal(t, []string{"8 exam.answers_regraded", "7 exam.answers_regraded"}, notifier.users)
+	assert.Equal(t, []int32{10, 11}, notifier.events[1].QuestionIDs)
+	assert.Equal(t, 2, notifier.events[1].Answers)
+
+	var report regradeReport
+	require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+	assert.Equal(t, 4, report.RegradedAnswers)
+	assert.Equal(t, []int32{99}, report.MissingQuestions)
+	require.Len(t, report.Attempts, 2)
+	assert.Equal(t, "600", report.Attempts[0].NewScore)
+
+	err := handler.Run(context.Background(), &Job{Params: json.RawMessage(`{}`)}, &out, func(int, string) {})
+	assert.ErrorIs(t, err, ErrInvalidParams)
+}
@@ -0,0 +1,486 @@
+// Package jobs runs long exports and reports in the background. A job is
+// stored with its status and progress, so clients can poll it or follow the
+// job.progress events pushed over WebSocket, and can be cancelled while it
+// is queued or running. The file a job produces is kept on disk until it
+// expires.
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/logger"
+)
+
+// Job statuses
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusCanceled  = "canceled"
+)
+
+// EventJobProgress is pushed to the owner of a job whenever its status or
+// progress changes
+const EventJobProgress = "job.progress"
+
+var (
+	ErrUnknownKind   = errors.New("unknown job kind")
+	ErrJobNotFound   = errors.New("job not found")
+	ErrJobFinished   = errors.New("job already finished")
+	ErrNoResult      = errors.New("job has no result to download")
+	ErrInvalidParams = errors.New("invalid job parameters")
+)
+
+// Notifier delivers real-time events to connected users
+type Notifier interface {
+	SendToUser(userID string, messageType string, data interface{}) error
+}
+
+// ProgressFunc reports how far a job got, from 0 to 100, with a short
+// description of the current step
+type ProgressFunc func(percent int, message string)
+
+// Handler runs one kind of job
+type Handler struct {
+	// Permission a user needs to start the job; empty lets every user
+	// start it
+	Permission string
+	// Extension and ContentType of the file the job produces
+	Extension   string
+	ContentType string
+	// Run writes the result of the job to out. It must return when ctx is
+	// cancelled.
+	Run func(ctx context.Context, job *Job, out io.Writer, progress ProgressFunc) error
+}
+
+// Job is a background job as seen by its owner
+type Job struct {
+	ID         int32           `json:"id"`
+	Kind       string          `json:"kind"`
+	UserID     int32           `json:"-"`
+	Status     string          `json:"status"`
+	Progress   int32           `json:"progress"`
+	Message    string          `json:"message,omitempty"`
+	Params     json.RawMessage `json:"params" swaggertype:"object"`
+	Error      string          `json:"error,omitempty"`
+	ResultName string          `json:"result_name,omitempty"`
+	ResultSize int64           `json:"result_size,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+	StartedAt  *time.Time      `json:"started_at,omitempty"`
+	FinishedAt *time.Time      `json:"finished_at,omitempty"`
+	ExpiresAt  *time.Time      `json:"expires_at,omitempty"`
+}
+
+// Result is the file a finished job produced
+type Result struct {
+	Path        string
+	Name        string
+	ContentType string
+}
+
+// Options configure the job runner
+type Options struct {
+	// Dir holds the files jobs produce
+	Dir string
+	// Workers is how many jobs run at the same time
+	Workers int
+	// ResultTTL is how long results can be downloaded
+	ResultTTL time.Duration
+	// Runner names this instance. Jobs run on the instance that created
+	// them, so on start only the jobs of this runner are failed.
+	Runner string
+}
+
+// Service creates, runs and tracks jobs
+type Service struct {
+	store    db.Querier
+	notifier Notifier
+	options  Options
+	now      func() time.Time
+
+	mu       sync.Mutex
+	handlers map[string]Handler
+	running  map[int32]context.CancelFunc
+	pending  int
+	slots    chan struct{}
+	wg       sync.WaitGroup
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewService creates a job service. Register the job kinds, then call
+// Start.
+func NewService(store db.Querier, notifier Notifier, options Options) *Service {
+	if options.Workers < 1 {
+		options.Workers = 1
+	}
+	return &Service{
+		store:    store,
+		notifier: notifier,
+		options:  options,
+		now:      time.Now,
+		handlers: make(map[string]Handler),
+		running:  make(map[int32]context.CancelFunc),
+		slots:    make(chan struct{}, options.Workers),
+	}
+}
+
+// Register adds a kind of job
+func (s *Service) Register(kind string, handler Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[kind] = handler
+}
+
+// Handler returns the handler of a kind of job
+func (s *Service) Handler(kind string) (Handler, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	handler, ok := s.handlers[kind]
+	return handler, ok
+}
+
+// Start fails the jobs a previous process of this runner left unfinished
+// and deletes expired results every cleanupInterval
+func (s *Service) Start(cleanupInterval time.Duration) error {
+	interrupted, err := s.store.FailInterruptedJobs(context.Background(), db.FailInterruptedJobsParams{
+		FinishedAt: s.now(),
+		Runner:     s.options.Runner,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to fail interrupted jobs: %w", err)
+	}
+	if interrupted > 0 {
+		logger.Warn("Marked %d jobs interrupted by the last shutdown as failed", interrupted)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop != nil {
+		return nil
+	}
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+
+	go func(stop, done chan struct{}) {
+		defer close(done)
+		ticker := time.NewTicker(cleanupInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := s.DeleteExpired(context.Background()); err != nil {
+					logger.Warn("Failed to delete expired job results: %v", err)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}(s.stop, s.done)
+	return nil
+}
+
+// Stop cancels the running jobs and waits for them to return
+func (s *Service) Stop() {
+	s.mu.Lock()
+	stop, done := s.stop, s.done
+	s.stop, s.done = nil, nil
+	for _, cancel := range s.running {
+		cancel()
+	}
+	s.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+	s.wg.Wait()
+}
+
+// Pending returns how many jobs of this instance are queued or running
+func (s *Service) Pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pending
+}
+
+// Create queues a job for a user and starts it as soon as a worker is free
+func (s *Service) Create(ctx context.Context, userID int32, kind string, params json.RawMessage) (*Job, error) {
+	handler, ok := s.Handler(kind)
+	if !ok {
+		return nil, ErrUnknownKind
+	}
+	if len(params) == 0 {
+		params = json.RawMessage("{}")
+	}
+	if !json.Valid(params) {
+		return nil, ErrInvalidParams
+	}
+
+	row, err := s.store.CreateJob(ctx, db.CreateJobParams{
+		Kind:      kind,
+		UserID:    userID,
+		Runner:    s.options.Runner,
+		Params:    params,
+		CreatedAt: s.now(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create job: %w", err)
+	}
+
+	s.mu.Lock()
+	s.pending++
+	s.mu.Unlock()
+	s.wg.Add(1)
+	go s.run(row.ID, handler)
+	return toJob(row), nil
+}
+
+// Get returns a job of the user
+func (s *Service) Get(ctx context.Context, userID, id int32) (*Job, error) {
+	row, err := s.get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return toJob(row), nil
+}
+
+// List returns the jobs of a user, newest first, with the total count
+func (s *Service) List(ctx context.Context, userID, limit, offset int32) ([]*Job, int64, error) {
+	rows, err := s.store.ListUserJobs(ctx, db.ListUserJobsParams{UserID: userID, Limit: limit, Offset: offset})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	total, err := s.store.CountUserJobs(ctx, userID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count jobs: %w", err)
+	}
+	jobs := make([]*Job, 0, len(rows))
+	for _, row := range rows {
+		jobs = append(jobs, toJob(row))
+	}
+	return jobs, total, nil
+}
+
+// Cancel stops a queued or running job of the user
+func (s *Service) Cancel(ctx context.Context, userID, id int32) (*Job, error) {
+	row, err := s.store.CancelJob(ctx, db.CancelJobParams{FinishedAt: s.now(), ID: id, UserID: userID})
+	if errors.Is(err, sql.ErrNoRows) {
+		if _, err := s.get(ctx, userID, id); err != nil {
+			return nil, err
+		}
+		return nil, ErrJobFinished
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel job: %w", err)
+	}
+
+	s.mu.Lock()
+	if cancel, ok := s.running[id]; ok {
+		cancel()
+	}
+	s.mu.Unlock()
+
+	job := toJob(row)
+	s.notify(job)
+	return job, nil
+}
+
+// Result returns the file a succeeded job of the user produced
+func (s *Service) Result(ctx context.Context, userID, id int32) (*Result, error) {
+	row, err := s.get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if row.Status != StatusSucceeded || !row.ResultPath.Valid || (row.ExpiresAt.Valid && !row.ExpiresAt.Time.After(s.now())) {
+		return nil, ErrNoResult
+	}
+	return &Result{Path: row.ResultPath.String, Name: row.ResultName.String, ContentType: row.ResultType.String}, nil
+}
+
+// DeleteExpired removes expired jobs and their files
+func (s *Service) DeleteExpired(ctx context.Context) (int, error) {
+	paths, err := s.store.DeleteExpiredJobs(ctx, s.now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired jobs: %w", err)
+	}
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			logger.Warn("Failed to remove expired job result %s: %v", path, err)
+		}
+	}
+	return len(paths), nil
+}
+
+// run waits for a free worker and runs a job
+func (s *Service) run(id int32, handler Handler) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		s.pending--
+		s.mu.Unlock()
+	}()
+	s.slots <- struct{}{}
+	defer func() { <-s.slots }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	row, err := s.store.StartJob(ctx, db.StartJobParams{StartedAt: s.now(), ID: id})
+	if errors.Is(err, sql.ErrNoRows) {
+		return // Cancelled while queued
+	}
+	if err != nil {
+		logger.Error("Failed to start job %d: %v", id, err)
+		return
+	}
+	s.mu.Lock()
+	s.running[id] = cancel
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.running, id)
+		s.mu.Unlock()
+	}()
+
+	job := toJob(row)
+	s.notify(job)
+
+	name := fmt.Sprintf("%s-%d.%s", job.Kind, job.ID, handler.Extension)
+	path := filepath.Join(s.options.Dir, name)
+	size, runErr := s.execute(ctx, job, handler, path)
+
+	finish := db.FinishJobParams{Status: StatusSucceeded, FinishedAt: s.now(), ID: id}
+	if runErr != nil {
+		os.Remove(path)
+		finish.Status = StatusFailed
+		finish.Error = sql.NullString{String: runErr.Error(), Valid: true}
+		logger.Warn("Job %d (%s) failed: %v", id, job.Kind, runErr)
+	} else {
+		finish.ResultPath = sql.NullString{String: path, Valid: true}
+		finish.ResultName = sql.NullString{String: name, Valid: true}
+		finish.ResultType = sql.NullString{String: handler.ContentType, Valid: true}
+		finish.ResultSize = sql.NullInt64{Int64: size, Valid: true}
+		finish.ExpiresAt = sql.NullTime{Time: finish.FinishedAt.Add(s.options.ResultTTL), Valid: true}
+	}
+
+	finished, err := s.store.FinishJob(context.Background(), finish)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Cancelled while running; the result is discarded
+		os.Remove(path)
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to record the outcome of job %d: %v", id, err)
+		return
+	}
+	s.notify(toJob(finished))
+}
+
+// execute runs the handler into the result file and returns its size
+func (s *Service) execute(ctx context.Context, job *Job, handler Handler, path string) (size int64, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return 0, fmt.Errorf("failed to create job directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o640)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create result file: %w", err)
+	}
+	defer file.Close()
+
+	last := int32(-1)
+	progress := func(percent int, message string) {
+		current := int32(min(max(percent, 0), 99))
+		if current == last && message == job.Message {
+			return
+		}
+		last = current
+		job.Progress, job.Message = current, message
+		if err := s.store.UpdateJobProgress(ctx, db.UpdateJobProgressParams{Progress: current, Message: message, ID: job.ID}); err != nil {
+			logger.Debug("Failed to record progress of job %d: %v", job.ID, err)
+		}
+		s.notify(job)
+	}
+
+	if err := handler.Run(ctx, job, file, progress); err != nil {
+		return 0, err
+	}
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	if err := file.Close(); err != nil {
+		return 0, fmt.Errorf("failed to write result file: %w", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read result file: %w", err)
+	}
+	return info.Size(), nil
+}
+
+func (s *Service) get(ctx context.Context, userID, id int32) (db.Job, error) {
+	row, err := s.store.GetJob(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && row.UserID != userID) {
+		return db.Job{}, ErrJobNotFound
+	}
+	if err != nil {
+		return db.Job{}, fmt.Errorf("failed to get job: %w", err)
+	}
+	return row, nil
+}
+
+func (s *Service) notify(job *Job) {
+	if s.notifier == nil {
+		return
+	}
+	if err := s.notifier.SendToUser(strconv.Itoa(int(job.UserID)), EventJobProgress, job); err != nil {
+		logger.Debug("Failed to send %s to user %d: %v", EventJobProgress, job.UserID, err)
+	}
+}
+
+func toJob(row db.Job) *Job {
+	job := &Job{
+		ID:         row.ID,
+		Kind:       row.Kind,
+		UserID:     row.UserID,
+		Status:     row.Status,
+		Progress:   row.Progress,
+		Message:    row.Message,
+		Params:     row.Params,
+		Error:      row.Error.String,
+		ResultName: row.ResultName.String,
+		ResultSize: row.ResultSize.Int64,
+		CreatedAt:  row.CreatedAt,
+	}
+	if row.StartedAt.Valid {
+		job.StartedAt = &row.StartedAt.Time
+	}
+	if row.FinishedAt.Valid {
+		job.FinishedAt = &row.FinishedAt.Time
+	}
+	if row.ExpiresAt.Valid {
+		job.ExpiresAt = &row.ExpiresAt.Time
+	}
+	return job
+}
@@ -0,0 +1,210 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+// fakeStore keeps jobs in memory
+type fakeStore struct {
+	db.Querier
+	mu   sync.Mutex
+	jobs map[int32]db.Job
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{jobs: make(map[int32]db.Job)}
+}
+
+func (s *fakeStore) CreateJob(_ context.Context, arg db.CreateJobParams) (db.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job := db.Job{ID: int32(len(s.jobs) + 1), Kind: arg.Kind, UserID: arg.UserID, Status: StatusQueued, Params: arg.Params, CreatedAt: arg.CreatedAt}
+	s.jobs[job.ID] = job
+	return job, nil
+}
+
+func (s *fakeStore) GetJob(_ context.Context, id int32) (db.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return db.Job{}, sql.ErrNoRows
+	}
+	return job, nil
+}
+
+func (s *fakeStore) StartJob(_ context.Context, arg db.StartJobParams) (db.Job, error) {
+	return s.transition(arg.ID, []string{StatusQueued}, func(job *db.Job) {
+		job.Status = StatusRunning
+		job.StartedAt = sql.NullTime{Time: arg.StartedAt, Valid: true}
+	})
+}
+
+func (s *fakeStore) UpdateJobProgress(_ context.Context, arg db.UpdateJobProgressParams) error {
+	_, err := s.transition(arg.ID, []string{StatusRunning}, func(job *db.Job) {
+		job.Progress, job.Message = arg.Progress, arg.Message
+	})
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	return err
+}
+
+func (s *fakeStore) FinishJob(_ context.Context, arg db.FinishJobParams) (db.Job, error) {
+	return s.transition(arg.ID, []string{StatusRunning}, func(job *db.Job) {
+		job.Status = arg.Status
+		if arg.Status == StatusSucceeded {
+			job.Progress = 100
+		}
+		job.Error, job.ResultPath, job.ResultName, job.ResultType = arg.Error, arg.ResultPath, arg.ResultName, arg.ResultType
+		job.ResultSize, job.ExpiresAt = arg.ResultSize, arg.ExpiresAt
+		job.FinishedAt = sql.NullTime{Time: arg.FinishedAt, Valid: true}
+	})
+}
+
+func (s *fakeStore) CancelJob(_ context.Context, arg db.CancelJobParams) (db.Job, error) {
+	s.mu.Lock()
+	job, ok := s.jobs[arg.ID]
+	s.mu.Unlock()
+	if !ok || job.UserID != arg.UserID {
+		return db.Job{}, sql.ErrNoRows
+	}
+	return s.transition(arg.ID, []string{StatusQueued, StatusRunning}, func(job *db.Job) {
+		job.Status = StatusCanceled
+		job.FinishedAt = sql.NullTime{Time: arg.FinishedAt, Valid: true}
+	})
+}
+
+func (s *fakeStore) transition(id int32, from []string, change func(*db.Job)) (db.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return db.Job{}, sql.ErrNoRows
+	}
+	for _, status := range from {
+		if job.Status == status {
+			change(&job)
+			s.jobs[id] = job
+			return job, nil
+		}
+	}
+	return db.Job{}, sql.ErrNoRows
+}
+
+// fakeNotifier records the statuses pushed to users
+type fakeNotifier struct {
+	mu       sync.Mutex
+	statuses []string
+}
+
+func (n *fakeNotifier) SendToUser(_ string, _ string, data interface{}) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.statuses = append(n.statuses, data.(*Job).Status)
+	return nil
+}
+
+func newTestService(t *testing.T, store *fakeStore, notifier Notifier) *Service {
+	return NewService(store, notifier, Options{Dir: t.TempDir(), Workers: 2, ResultTTL: time.Hour})
+}
+
+func waitForStatus(t *testing.T, service *Service, id int32, status string) *Job {
+	var job *Job
+	require.Eventually(t, func() bool {
+		var err error
+		job, err = service.Get(context.Background(), 1, id)
+		return err == nil && job.Status == status
+	}, 2*time.Second, 10*time.Millisecond)
+	return job
+}
+
+func TestRunJob(t *testing.T) {
+	store := newFakeStore()
+	notifier := &fakeNotifier{}
+	service := newTestService(t, store, notifier)
+	service.Register("report", Handler{
+		Extension:   "csv",
+		ContentType: "text/csv",
+		Run: func(_ context.Context, job *Job, out io.Writer, progress ProgressFunc) error {
+			progress(50, "Halfway")
+			_, err := io.WriteString(out, "a,b\n")
+			return err
+		},
+	})
+	ctx := context.Background()
+
+	_, err := service.Create(ctx, 1, "unknown", nil)
+	assert.ErrorIs(t, err, ErrUnknownKind)
+	_, err = service.Create(ctx, 1, "report", json.RawMessage("{"))
+	assert.ErrorIs(t, err, ErrInvalidParams)
+
+	job, err := service.Create(ctx, 1, "report", nil)
+	require.NoError(t, err)
+	assert.Equal(t, StatusQueued, job.Status)
+	assert.JSONEq(t, "{}", string(job.Params))
+
+	job = waitForStatus(t, service, job.ID, StatusSucceeded)
+	service.Stop()
+	assert.Equal(t, int32(100), job.Progress)
+	assert.Equal(t, int64(4), job.ResultSize)
+	assert.Equal(t, "report-1.csv", job.ResultName)
+	assert.Equal(t, []string{StatusRunning, StatusRunning, StatusSucceeded}, notifier.statuses)
+
+	result, err := service.Result(ctx, 1, job.ID)
+	require.NoError(t, err)
+	content, err := os.ReadFile(result.Path)
+	require.NoError(t, err)
+	assert.Equal(t, "a,b\n", string(content))
+
+	_, err = service.Result(ctx, 2, job.ID)
+	assert.ErrorIs(t, err, ErrJobNotFound)
+	_, err = service.Cancel(ctx, 1, job.ID)
+	assert.ErrorIs(t, err, ErrJobFinished)
+}
+
+func TestCancelRunningJob(t *testing.T) {
+	store := newFakeStore()
+	service := newTestService(t, store, nil)
+	started := make(chan struct{})
+	service.Register("slow", Handler{
+		Extension: "json",
+		Run: func(ctx context.Context, _ *Job, _ io.Writer, _ ProgressFunc) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	})
+	ctx := context.Background()
+
+	job, err := service.Create(ctx, 1, "slow", nil)
+	require.NoError(t, err)
+	<-started
+	assert.Equal(t, 1, service.Pending())
+
+	canceled, err := service.Cancel(ctx, 1, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusCanceled, canceled.Status)
+	service.Stop()
+	assert.Equal(t, 0, service.Pending())
+
+	job, err = service.Get(ctx, 1, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusCanceled, job.Status)
+	_, err = service.Result(ctx, 1, job.ID)
+	assert.ErrorIs(t, err, ErrNoResult)
+	entries, err := os.ReadDir(service.options.Dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
@@ -0,0 +1,200 @@
+package leader
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// LocalBackend is used when leader election is disabled: the single
+// instance is always the leader
+type LocalBackend struct {
+	instanceID string
+}
+
+// NewLocalBackend creates a backend that always grants leadership
+func NewLocalBackend(instanceID string) *LocalBackend {
+	return &LocalBackend{instanceID: instanceID}
+}
+
+func (b *LocalBackend) TryAcquire(ctx context.Context) (bool, error) { return true, nil }
+func (b *LocalBackend) Release(ctx context.Context) error            { return nil }
+func (b *LocalBackend) Name() string                                 { return "local" }
+func (b *LocalBackend) CurrentLeader(ctx context.Context) (string, error) {
+	return b.instanceID, nil
+}
+
+// PostgresBackend uses a session-level advisory lock. The lock lives as long
+// as the dedicated connection, so a crashed leader releases it automatically.
+type PostgresBackend struct {
+	db         *sql.DB
+	lockID     int64
+	instanceID string
+
+	mu   sync.Mutex
+	conn *sql.Conn
+}
+
+// NewPostgresBackend creates an advisory-lock backend
+func NewPostgresBackend(db *sql.DB, lockID int64, instanceID string) *PostgresBackend {
+	return &PostgresBackend{
+		db:         db,
+		lockID:     lockID,
+		instanceID: instanceID,
+	}
+}
+
+func (b *PostgresBackend) Name() string { return "postgres" }
+
+func (b *PostgresBackend) TryAcquire(ctx context.Context) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// Already holding the lock: make sure the session is still alive
+	if b.conn != nil {
+		if err := b.conn.PingContext(ctx); err != nil {
+			b.conn.Close()
+			b.conn = nil
+			return false, fmt.Errorf("leader connection lost: %w", err)
+		}
+		return true, nil
+	}
+
+	conn, err := b.db.Conn(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to get database connection: %w", err)
+	}
+
+	// Tag the session so other instances can see who holds the lock
+	if _, err := conn.ExecContext(ctx, "SELECT set_config('application_name', $1, false)", b.applicationName()); err != nil {
+		conn.Close()
+		return false, fmt.Errorf("failed to set application name: %w", err)
+	}
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", b.lockID).Scan(&acquired); err != nil {
+		conn.Close()
+		return false, fmt.Errorf("failed to acquire advisory lock: %w", err)
+	}
+	if !acquired {
+		conn.Close()
+		return false, nil
+	}
+
+	b.conn = conn
+	return true, nil
+}
+
+func (b *PostgresBackend) Release(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.conn == nil {
+		return nil
+	}
+	_, err := b.conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", b.lockID)
+	b.conn.Close()
+	b.conn = nil
+	return err
+}
+
+func (b *PostgresBackend) CurrentLeader(ctx context.Context) (string, error) {
+	// Advisory locks on a bigint key store the low 32 bits in objid and the high bits in classid
+	const query = `SELECT a.application_name FROM pg_locks l
+JOIN pg_stat_activity a ON a.pid = l.pid
+WHERE l.locktype = 'advisory' AND l.granted
+AND l.classid = ($1::bigint >> 32)::oid AND l.objid = ($1::bigint & 4294967295)::oid
+LIMIT 1`
+
+	var applicationName string
+	err := b.db.QueryRowContext(ctx, query, b.lockID).Scan(&applicationName)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to look up leader: %w", err)
+	}
+	return trimApplicationName(applicationName), nil
+}
+
+const applicationNamePrefix = "toeic-leader:"
+
+func (b *PostgresBackend) applicationName() string {
+	return applicationNamePrefix + b.instanceID
+}
+
+func trimApplicationName(name string) string {
+	if len(name) > len(applicationNamePrefix) && name[:len(applicationNamePrefix)] == applicationNamePrefix {
+		return name[len(applicationNamePrefix):]
+	}
+	return name
+}
+
+// RedisBackend uses a lease key holding the leader's instance ID. The leader
+// renews the lease; if it stops renewing, the key expires and another instance
+// takes over.
+type RedisBackend struct {
+	client     *redis.Client
+	key        string
+	instanceID string
+	ttl        time.Duration
+}
+
+// NewRedisBackend creates a lease backend
+func NewRedisBackend(client *redis.Client, key, instanceID string, ttl time.Duration) *RedisBackend {
+	return &RedisBackend{
+		client:     client,
+		key:        key,
+		instanceID: instanceID,
+		ttl:        ttl,
+	}
+}
+
+// acquireScript renews the lease if we own it, otherwise tries to take it
+var acquireScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+	return 1
+end
+if redis.call('SET', KEYS[1], ARGV[1], 'NX', 'PX', ARGV[2]) then
+	return 1
+end
+return 0
+`)
+
+// releaseScript deletes the lease only if we own it
+var releaseScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+func (b *RedisBackend) Name() string { return "redis" }
+
+func (b *RedisBackend) TryAcquire(ctx context.Context) (bool, error) {
+	result, err := acquireScript.Run(ctx, b.client, []string{b.key}, b.instanceID, b.ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire leader lease: %w", err)
+	}
+	return result == 1, nil
+}
+
+func (b *RedisBackend) Release(ctx context.Context) error {
+	return releaseScript.Run(ctx, b.client, []string{b.key}, b.instanceID).Err()
+}
+
+func (b *RedisBackend) CurrentLeader(ctx context.Context) (string, error) {
+	leaderID, err := b.client.Get(ctx, b.key).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to look up leader: %w", err)
+	}
+	return leaderID, nil
+}
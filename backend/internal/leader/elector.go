@@ -0,0 +1,243 @@
+package leader
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/toeic-app/internal/logger"
+)
+
+// Backend acquires and renews the leadership lock
+type Backend interface {
+	// TryAcquire acquires the lock, or renews it when already held.
+	// It returns true while this instance holds the lock.
+	TryAcquire(ctx context.Context) (bool, error)
+
+	// Release gives up the lock if held
+	Release(ctx context.Context) error
+
+	// CurrentLeader returns the instance ID of the current leader, if known
+	CurrentLeader(ctx context.Context) (string, error)
+
+	// Name returns the backend name for status reporting
+	Name() string
+}
+
+// Status describes the leadership state of this instance
+type Status struct {
+	InstanceID  string     `json:"instance_id"`
+	Backend     string     `json:"backend"`
+	IsLeader    bool       `json:"is_leader"`
+	LeaderID    string     `json:"leader_id"`
+	LeaderSince *time.Time `json:"leader_since,omitempty"`
+	LastCheck   time.Time  `json:"last_check"`
+	LastError   string     `json:"last_error,omitempty"`
+}
+
+// Elector runs leader election so scheduled work only runs on one instance
+type Elector struct {
+	backend    Backend
+	instanceID string
+	interval   time.Duration
+
+	mu        sync.RWMutex
+	isLeader  bool
+	since     time.Time
+	leaderCtx context.Context
+	endLead   context.CancelFunc
+	lastCheck time.Time
+	lastError string
+	onElected []func()
+	onRevoked []func()
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewElector creates an elector that checks the lock at the given interval
+func NewElector(backend Backend, instanceID string, interval time.Duration) *Elector {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	leaderCtx, endLead := context.WithCancel(context.Background())
+	endLead()
+	return &Elector{
+		backend:    backend,
+		instanceID: instanceID,
+		interval:   interval,
+		leaderCtx:  leaderCtx,
+		endLead:    endLead,
+	}
+}
+
+// DefaultInstanceID returns hostname-pid, unique enough across replicas
+func DefaultInstanceID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
+
+// OnElected registers a callback run when this instance becomes leader
+func (e *Elector) OnElected(fn func()) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.onElected = append(e.onElected, fn)
+}
+
+// OnRevoked registers a callback run when this instance loses leadership
+func (e *Elector) OnRevoked(fn func()) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.onRevoked = append(e.onRevoked, fn)
+}
+
+// Start runs the election loop until Stop is called or ctx is cancelled
+func (e *Elector) Start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	e.cancel = cancel
+	e.done = make(chan struct{})
+
+	// First check runs synchronously so IsLeader is accurate right after Start
+	e.check(ctx)
+
+	go func() {
+		defer close(e.done)
+
+		ticker := time.NewTicker(e.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				e.check(ctx)
+			}
+		}
+	}()
+
+	logger.Info("Leader election started (backend: %s, instance: %s)", e.backend.Name(), e.instanceID)
+}
+
+// Stop ends the election loop and releases the lock so another instance can take over
+func (e *Elector) Stop() {
+	if e.cancel == nil {
+		return
+	}
+	e.cancel()
+	<-e.done
+
+	e.setLeader(false)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := e.backend.Release(ctx); err != nil {
+		logger.Warn("Failed to release leader lock: %v", err)
+	}
+	logger.Info("Leader election stopped")
+}
+
+// IsLeader reports whether this instance is currently the leader
+func (e *Elector) IsLeader() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.isLeader
+}
+
+// Context returns a context that is cancelled when the current leadership
+// ends, for leader-only work started by OnElected callbacks. It is already
+// cancelled when this instance is not the leader.
+func (e *Elector) Context() context.Context {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.leaderCtx
+}
+
+// Status returns the leadership state, including the current leader's ID
+func (e *Elector) Status(ctx context.Context) Status {
+	e.mu.RLock()
+	status := Status{
+		InstanceID: e.instanceID,
+		Backend:    e.backend.Name(),
+		IsLeader:   e.isLeader,
+		LastCheck:  e.lastCheck,
+		LastError:  e.lastError,
+	}
+	if e.isLeader {
+		since := e.since
+		status.LeaderSince = &since
+	}
+	e.mu.RUnlock()
+
+	if status.IsLeader {
+		status.LeaderID = e.instanceID
+		return status
+	}
+
+	leaderID, err := e.backend.CurrentLeader(ctx)
+	if err != nil {
+		status.LastError = err.Error()
+	}
+	status.LeaderID = leaderID
+	return status
+}
+
+// check acquires or renews the lock and fires callbacks on transitions
+func (e *Elector) check(ctx context.Context) {
+	checkCtx, cancel := context.WithTimeout(ctx, e.interval)
+	defer cancel()
+
+	acquired, err := e.backend.TryAcquire(checkCtx)
+
+	e.mu.Lock()
+	e.lastCheck = time.Now()
+	if err != nil {
+		e.lastError = err.Error()
+	} else {
+		e.lastError = ""
+	}
+	e.mu.Unlock()
+
+	if err != nil {
+		logger.Warn("Leader election check failed: %v", err)
+		// Step down: without a healthy lock another instance may take over
+		acquired = false
+	}
+
+	e.setLeader(acquired)
+}
+
+// setLeader records the new state and runs callbacks when it changes
+func (e *Elector) setLeader(leader bool) {
+	e.mu.Lock()
+	if e.isLeader == leader {
+		e.mu.Unlock()
+		return
+	}
+	e.isLeader = leader
+	var callbacks []func()
+	if leader {
+		e.since = time.Now()
+		e.leaderCtx, e.endLead = context.WithCancel(context.Background())
+		callbacks = append(callbacks, e.onElected...)
+	} else {
+		e.endLead()
+		callbacks = append(callbacks, e.onRevoked...)
+	}
+	e.mu.Unlock()
+
+	if leader {
+		logger.Info("Instance %s elected as leader", e.instanceID)
+	} else {
+		logger.Warn("Instance %s is no longer the leader", e.instanceID)
+	}
+
+	for _, fn := range callbacks {
+		fn()
+	}
+}
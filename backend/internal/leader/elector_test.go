@@ -0,0 +1,160 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryLease is a lease the memoryBackends of a test compete for, standing
+// in for Redis or Postgres. Its clock is moved by the test.
+type memoryLease struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	now     time.Time
+	holder  string
+	expires time.Time
+}
+
+func newMemoryLease(ttl time.Duration) *memoryLease {
+	return &memoryLease{ttl: ttl, now: time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)}
+}
+
+// advance moves the clock of the lease
+func (l *memoryLease) advance(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.now = l.now.Add(d)
+}
+
+// memoryBackend acquires a memoryLease for one instance
+type memoryBackend struct {
+	lease      *memoryLease
+	instanceID string
+}
+
+func (b *memoryBackend) Name() string { return "memory" }
+
+func (b *memoryBackend) TryAcquire(ctx context.Context) (bool, error) {
+	l := b.lease
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.holder != b.instanceID && l.holder != "" && l.now.Before(l.expires) {
+		return false, nil
+	}
+	l.holder = b.instanceID
+	l.expires = l.now.Add(l.ttl)
+	return true, nil
+}
+
+func (b *memoryBackend) Release(ctx context.Context) error {
+	l := b.lease
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.holder == b.instanceID {
+		l.holder = ""
+	}
+	return nil
+}
+
+func (b *memoryBackend) CurrentLeader(ctx context.Context) (string, error) {
+	l := b.lease
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.now.Before(l.expires) {
+		return "", nil
+	}
+	return l.holder, nil
+}
+
+// failingBackend fails its checks while err is set
+type failingBackend struct {
+	Backend
+	err error
+}
+
+func (b *failingBackend) TryAcquire(ctx context.Context) (bool, error) {
+	if b.err != nil {
+		return false, b.err
+	}
+	return b.Backend.TryAcquire(ctx)
+}
+
+func TestElectorAcquiresLease(t *testing.T) {
+	ctx := context.Background()
+	lease := newMemoryLease(15 * time.Second)
+	a := NewElector(&memoryBackend{lease: lease, instanceID: "a"}, "a", time.Hour)
+	b := NewElector(&memoryBackend{lease: lease, instanceID: "b"}, "b", time.Hour)
+	elected := 0
+	a.OnElected(func() { elected++ })
+
+	a.check(ctx)
+	b.check(ctx)
+	assert.True(t, a.IsLeader())
+	assert.False(t, b.IsLeader())
+	assert.Equal(t, 1, elected)
+	assert.Equal(t, "a", b.Status(ctx).LeaderID)
+	assert.NoError(t, a.Context().Err())
+	assert.Error(t, b.Context().Err())
+
+	// Renewing within the TTL keeps the leadership
+	lease.advance(10 * time.Second)
+	a.check(ctx)
+	lease.advance(10 * time.Second)
+	b.check(ctx)
+	assert.True(t, a.IsLeader())
+	assert.False(t, b.IsLeader())
+	assert.Equal(t, 1, elected)
+
+	// Stopping releases the lease for the next instance
+	a.Start(ctx)
+	a.Stop()
+	assert.False(t, a.IsLeader())
+	b.check(ctx)
+	assert.True(t, b.IsLeader())
+}
+
+func TestElectorCancelsLeaderWhenRenewalFails(t *testing.T) {
+	ctx := context.Background()
+	lease := newMemoryLease(15 * time.Second)
+	a := NewElector(&memoryBackend{lease: lease, instanceID: "a"}, "a", time.Hour)
+	b := NewElector(&memoryBackend{lease: lease, instanceID: "b"}, "b", time.Hour)
+	var leading context.Context
+	a.OnElected(func() { leading = a.Context() })
+	revoked := 0
+	a.OnRevoked(func() { revoked++ })
+
+	a.check(ctx)
+	require.NotNil(t, leading)
+	assert.NoError(t, leading.Err())
+
+	// a stopped renewing, so b takes over once the lease expires
+	lease.advance(20 * time.Second)
+	b.check(ctx)
+	assert.True(t, b.IsLeader())
+	a.check(ctx)
+	assert.False(t, a.IsLeader())
+	assert.ErrorIs(t, leading.Err(), context.Canceled)
+	assert.Equal(t, 1, revoked)
+
+	// A failing backend steps down as well
+	backend := &failingBackend{Backend: &memoryBackend{lease: lease, instanceID: "b"}}
+	c := NewElector(backend, "b", time.Hour)
+	c.check(ctx)
+	require.True(t, c.IsLeader())
+	leading = c.Context()
+	backend.err = errors.New("connection refused")
+	c.check(ctx)
+	assert.False(t, c.IsLeader())
+	assert.ErrorIs(t, leading.Err(), context.Canceled)
+	assert.Equal(t, "connection refused", c.Status(ctx).LastError)
+}
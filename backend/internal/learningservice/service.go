@@ -0,0 +1,484 @@
+// Package learningservice holds the rules for vocabulary learning
+// sessions: which words a session practices, how attempts are graded and
+// how a completed session is summarized. The HTTP handlers build the
+// questions shown to the client and translate errors.
+package learningservice
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sqlc-dev/pqtype"
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/vocabstats"
+	"github.com/toeic-app/internal/wordsense"
+)
+
+var (
+	ErrStudySetNotFound = errors.New("study set not found")
+	ErrStudySetDenied   = errors.New("access denied to study set")
+	ErrNoWords          = errors.New("no words available for learning session")
+	ErrSessionNotFound  = errors.New("learning session not found")
+	ErrWordNotFound     = errors.New("word not found")
+	ErrSenseMismatch    = errors.New("sense does not belong to the word")
+)
+
+// reviewMasteryLevel is the mastery level below which a word is reviewed
+const reviewMasteryLevel = 8
+
+// Senses lists the meanings of a word. *wordsense.Service satisfies it.
+type Senses interface {
+	List(ctx context.Context, wordID int32) ([]wordsense.Sense, error)
+}
+
+// Service manages the learning sessions of users. All methods act on the
+// sessions of the given user only.
+type Service interface {
+	// SessionWords picks up to limit words for a new session: those of a
+	// study set the user owns or that is public, or else the user's words
+	// most in need of review
+	SessionWords(ctx context.Context, userID int32, studySetID *int32, limit int32) ([]db.Word, error)
+	StartSession(ctx context.Context, session NewSession) (db.LearningSession, error)
+	Session(ctx context.Context, userID, sessionID int32) (db.LearningSession, error)
+	// SubmitAttempt grades and stores an answer given in a session
+	SubmitAttempt(ctx context.Context, userID, sessionID int32, attempt Attempt) (GradedAttempt, error)
+	// CompleteSession closes a session with the statistics of its
+	// attempts, or with those the client reported, and summarizes how each
+	// word went
+	CompleteSession(ctx context.Context, userID, sessionID int32, reported *Stats) (Completion, error)
+}
+
+// NewSession is a session to start. Data holds the generated questions and
+// the session settings shown to the client.
+type NewSession struct {
+	UserID      int32
+	StudySetID  *int32
+	SessionType string
+	Words       int32
+	// WordIDs are the words practiced; their mastery levels are kept with
+	// the session to report how the session changed them
+	WordIDs []int32
+	Data    map[string]interface{}
+}
+
+// Attempt is an answer given for a word
+type Attempt struct {
+	WordID int32
+	// SenseID is the sense a quiz question asked for
+	SenseID          *int32
+	AttemptType      string
+	UserAnswer       string
+	ResponseTimeMs   *int32
+	DifficultyRating *int32
+}
+
+// GradedAttempt is a stored attempt with the word it was for
+type GradedAttempt struct {
+	Attempt db.LearningAttempt
+	Word    db.Word
+}
+
+// Stats summarizes the attempts of a session
+type Stats struct {
+	TotalAttempts      int32   `json:"total_attempts"`
+	CorrectAttempts    int32   `json:"correct_attempts"`
+	AvgResponseTime    float64 `json:"avg_response_time"`
+	AvgDifficulty      float64 `json:"avg_difficulty"`
+	AccuracyPercentage float64 `json:"accuracy_percentage"`
+}
+
+// WordOutcome is how a word went in a session
+type WordOutcome struct {
+	WordID            int32   `json:"word_id"`
+	Word              string  `json:"word"`
+	Attempts          int32   `json:"attempts"`
+	CorrectAttempts   int32   `json:"correct_attempts"`
+	LastCorrect       bool    `json:"last_correct"`
+	AvgResponseTimeMs float64 `json:"avg_response_time_ms,omitempty"`
+	MasteryBefore     int32   `json:"mastery_before"`
+	MasteryAfter      int32   `json:"mastery_after"`
+	MasteryChange     int32   `json:"mastery_change"`
+	// Review is set for words answered wrong in the session
+	Review bool `json:"review"`
+}
+
+// Completion is a completed session with the statistics of its attempts
+type Completion struct {
+	Session db.LearningSession
+	Stats   Stats
+	// Words are the words attempted, in the order they were first attempted
+	Words []WordOutcome
+	// ReviewWordIDs are the words answered wrong, most often missed first
+	ReviewWordIDs []int32
+}
+
+// sessionMastery is the part of the session data holding the mastery levels
+// of its words when it started
+type sessionMastery struct {
+	MasteryBefore map[int32]int32 `json:"mastery_before"`
+}
+
+type service struct {
+	store  db.Querier
+	senses Senses
+	now    func() time.Time
+}
+
+// NewService creates a learning session service
+func NewService(store db.Querier, senses Senses) Service {
+	return &service{store: store, senses: senses, now: time.Now}
+}
+
+func (s *service) SessionWords(ctx context.Context, userID int32, studySetID *int32, limit int32) ([]db.Word, error) {
+	var words []db.Word
+	if studySetID != nil {
+		studySet, err := s.store.GetStudySet(ctx, *studySetID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrStudySetNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		if studySet.UserID != userID && (!studySet.IsPublic.Valid || !studySet.IsPublic.Bool) {
+			return nil, ErrStudySetDenied
+		}
+
+		studySetWords, err := s.store.GetStudySetWords(ctx, *studySetID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get study set words: %w", err)
+		}
+		for _, studySetWord := range studySetWords {
+			words = append(words, studySetWord.Word)
+		}
+	} else {
+		reviewWords, err := s.store.GetWordsNeedingReview(ctx, db.GetWordsNeedingReviewParams{
+			UserID:       userID,
+			MasteryLevel: sql.NullInt32{Int32: reviewMasteryLevel, Valid: true},
+			Limit:        limit,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get words for review: %w", err)
+		}
+		for _, review := range reviewWords {
+			words = append(words, review.Word)
+		}
+	}
+
+	if len(words) == 0 {
+		return nil, ErrNoWords
+	}
+	if len(words) > int(limit) {
+		words = words[:limit]
+	}
+	return words, nil
+}
+
+func (s *service) StartSession(ctx context.Context, session NewSession) (db.LearningSession, error) {
+	if len(session.WordIDs) > 0 {
+		levels, err := s.masteryLevels(ctx, session.UserID, session.WordIDs)
+		if err != nil {
+			return db.LearningSession{}, err
+		}
+		if session.Data == nil {
+			session.Data = map[string]interface{}{}
+		}
+		session.Data["mastery_before"] = levels
+	}
+	data, err := json.Marshal(session.Data)
+	if err != nil {
+		return db.LearningSession{}, fmt.Errorf("failed to encode session data: %w", err)
+	}
+	var studySetID sql.NullInt32
+	if session.StudySetID != nil {
+		studySetID = sql.NullInt32{Int32: *session.StudySetID, Valid: true}
+	}
+	return s.store.CreateLearningSession(ctx, db.CreateLearningSessionParams{
+		UserID:         session.UserID,
+		StudySetID:     studySetID,
+		SessionType:    session.SessionType,
+		TotalQuestions: sql.NullInt32{Int32: session.Words, Valid: true},
+		SessionData:    pqtype.NullRawMessage{RawMessage: data, Valid: true},
+	})
+}
+
+func (s *service) Session(ctx context.Context, userID, sessionID int32) (db.LearningSession, error) {
+	session, err := s.store.GetLearningSession(ctx, db.GetLearningSessionParams{
+		ID:     sessionID,
+		UserID: userID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return session, ErrSessionNotFound
+	}
+	return session, err
+}
+
+// compareTypedAnswer accepts a typed answer matching the correct answer or
+// the word itself, ignoring case and surrounding spaces
+func compareTypedAnswer(userAnswer, correctAnswer, word string) bool {
+	userLower := strings.ToLower(strings.TrimSpace(userAnswer))
+	correctLower := strings.ToLower(strings.TrimSpace(correctAnswer))
+	wordLower := strings.ToLower(strings.TrimSpace(word))
+	return userLower == correctLower || userLower == wordLower
+}
+
+// grade returns the expected answer for an attempt and whether the
+// attempt gave it
+func grade(word db.Word, senses []wordsense.Sense, attempt Attempt) (string, bool, error) {
+	// Quiz questions ask for one sense; other attempts are checked against
+	// the short meaning
+	correctAnswer := word.ShortMean
+	if attempt.SenseID != nil {
+		found := false
+		for _, sense := range senses {
+			if sense.ID == *attempt.SenseID {
+				correctAnswer, found = sense.Meaning, true
+				break
+			}
+		}
+		if !found {
+			return "", false, ErrSenseMismatch
+		}
+	}
+
+	switch attempt.AttemptType {
+	case "flashcard":
+		// Users self-report correctness in flashcard mode
+		return correctAnswer, true, nil
+	case "multiple_choice", "quiz", "match":
+		return correctAnswer, attempt.UserAnswer == correctAnswer, nil
+	case "type":
+		// Typed answers are compared loosely and may give any sense of the word
+		return correctAnswer, compareTypedAnswer(attempt.UserAnswer, correctAnswer, word.Word) ||
+			wordsense.Matches(senses, attempt.UserAnswer), nil
+	}
+	return correctAnswer, false, nil
+}
+
+func nullInt32(value *int32) sql.NullInt32 {
+	if value == nil {
+		return sql.NullInt32{}
+	}
+	return sql.NullInt32{Int32: *value, Valid: true}
+}
+
+func (s *service) SubmitAttempt(ctx context.Context, userID, sessionID int32, attempt Attempt) (GradedAttempt, error) {
+	if _, err := s.Session(ctx, userID, sessionID); err != nil {
+		return GradedAttempt{}, err
+	}
+	word, err := s.store.GetWord(ctx, attempt.WordID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return GradedAttempt{}, ErrWordNotFound
+		}
+		return GradedAttempt{}, err
+	}
+	senses, err := s.senses.List(ctx, word.ID)
+	if err != nil {
+		return GradedAttempt{}, fmt.Errorf("failed to get word senses: %w", err)
+	}
+	correctAnswer, correct, err := grade(word, senses, attempt)
+	if err != nil {
+		return GradedAttempt{}, err
+	}
+
+	stored, err := s.store.CreateLearningAttempt(ctx, db.CreateLearningAttemptParams{
+		SessionID:        sessionID,
+		WordID:           attempt.WordID,
+		AttemptType:      attempt.AttemptType,
+		UserAnswer:       sql.NullString{String: attempt.UserAnswer, Valid: attempt.UserAnswer != ""},
+		CorrectAnswer:    correctAnswer,
+		IsCorrect:        correct,
+		ResponseTimeMs:   nullInt32(attempt.ResponseTimeMs),
+		DifficultyRating: nullInt32(attempt.DifficultyRating),
+	})
+	if err != nil {
+		return GradedAttempt{}, err
+	}
+	return GradedAttempt{Attempt: stored, Word: word}, nil
+}
+
+func (s *service) CompleteSession(ctx context.Context, userID, sessionID int32, reported *Stats) (Completion, error) {
+	started, err := s.Session(ctx, userID, sessionID)
+	if err != nil {
+		return Completion{}, err
+	}
+	attempts, err := s.store.ListSessionAttempts(ctx, sessionID)
+	if err != nil {
+		return Completion{}, fmt.Errorf("failed to list session attempts: %w", err)
+	}
+	before, err := s.masteryBefore(ctx, started, attempts)
+	if err != nil {
+		return Completion{}, err
+	}
+	words, review := outcomes(attempts, before)
+
+	row, err := s.store.GetSessionStats(ctx, sessionID)
+	if err != nil {
+		return Completion{}, fmt.Errorf("failed to get session statistics: %w", err)
+	}
+	stats := Stats{
+		TotalAttempts:   int32(row.TotalAttempts),
+		CorrectAttempts: int32(row.CorrectAttempts),
+		AvgResponseTime: row.AvgResponseTime,
+		AvgDifficulty:   row.AvgDifficulty,
+	}
+	if row.TotalAttempts > 0 {
+		stats.AccuracyPercentage = float64(row.CorrectAttempts) / float64(row.TotalAttempts) * 100
+	}
+
+	// The client's statistics, if reported, are kept with the session
+	// instead of the calculated ones
+	finalStats := stats
+	if reported != nil {
+		finalStats = *reported
+	}
+	data, err := json.Marshal(map[string]interface{}{"final_stats": finalStats})
+	if err != nil {
+		return Completion{}, fmt.Errorf("failed to encode session data: %w", err)
+	}
+
+	session, err := s.store.UpdateLearningSession(ctx, db.UpdateLearningSessionParams{
+		ID:             sessionID,
+		UserID:         userID,
+		CompletedAt:    sql.NullTime{Time: s.now(), Valid: true},
+		TotalQuestions: sql.NullInt32{Int32: stats.TotalAttempts, Valid: true},
+		CorrectAnswers: sql.NullInt32{Int32: stats.CorrectAttempts, Valid: true},
+		SessionData:    pqtype.NullRawMessage{RawMessage: data, Valid: true},
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return Completion{}, ErrSessionNotFound
+	}
+	if err != nil {
+		return Completion{}, err
+	}
+	return Completion{Session: session, Stats: stats, Words: words, ReviewWordIDs: review}, nil
+}
+
+// masteryLevels returns the mastery levels of words, vocabstats.MinMastery
+// for words not practiced before
+func (s *service) masteryLevels(ctx context.Context, userID int32, wordIDs []int32) (map[int32]int32, error) {
+	rows, err := s.store.ListVocabularyMastery(ctx, db.ListVocabularyMasteryParams{UserID: userID, WordIds: wordIDs})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mastery levels: %w", err)
+	}
+	levels := make(map[int32]int32, len(wordIDs))
+	for _, wordID := range wordIDs {
+		levels[wordID] = vocabstats.MinMastery
+	}
+	for _, row := range rows {
+		if row.MasteryLevel.Valid {
+			levels[row.WordID] = row.MasteryLevel.Int32
+		}
+	}
+	return levels, nil
+}
+
+// masteryBefore returns the mastery levels of the attempted words when the
+// session started. Words missing from the session data, such as those of
+// sessions started before levels were kept, get their current level, which
+// may already include attempts of the session.
+func (s *service) masteryBefore(ctx context.Context, session db.LearningSession, attempts []db.ListSessionAttemptsRow) (map[int32]int32, error) {
+	var stored sessionMastery
+	if session.SessionData.Valid {
+		// Session data without levels leaves the map empty
+		_ = json.Unmarshal(session.SessionData.RawMessage, &stored)
+	}
+	levels := stored.MasteryBefore
+	if levels == nil {
+		levels = map[int32]int32{}
+	}
+
+	var missing []int32
+	for _, row := range attempts {
+		wordID := row.LearningAttempt.WordID
+		if _, ok := levels[wordID]; !ok && !slices.Contains(missing, wordID) {
+			missing = append(missing, wordID)
+		}
+	}
+	if len(missing) == 0 {
+		return levels, nil
+	}
+	current, err := s.masteryLevels(ctx, session.UserID, missing)
+	if err != nil {
+		return nil, err
+	}
+	for wordID, level := range current {
+		levels[wordID] = level
+	}
+	return levels, nil
+}
+
+// outcomes summarizes the attempts of a session per word. Mastery levels
+// after the session follow the rules of vocabstats.Mastery, which the
+// statistics of the words are updated with.
+func outcomes(attempts []db.ListSessionAttemptsRow, before map[int32]int32) ([]WordOutcome, []int32) {
+	words := []WordOutcome{}
+	index := make(map[int32]int)
+	practiced := make(map[int32][]vocabstats.Attempt)
+	responseTimes := make(map[int32][]int32)
+	for _, row := range attempts {
+		attempt := row.LearningAttempt
+		i, ok := index[attempt.WordID]
+		if !ok {
+			i = len(words)
+			index[attempt.WordID] = i
+			words = append(words, WordOutcome{WordID: attempt.WordID, Word: row.Word.Word})
+		}
+		word := &words[i]
+		word.Attempts++
+		if attempt.IsCorrect {
+			word.CorrectAttempts++
+		} else {
+			word.Review = true
+		}
+		word.LastCorrect = attempt.IsCorrect
+
+		practice := vocabstats.Attempt{Correct: attempt.IsCorrect}
+		if attempt.ResponseTimeMs.Valid {
+			practice.ResponseTimeMs = &attempt.ResponseTimeMs.Int32
+			responseTimes[attempt.WordID] = append(responseTimes[attempt.WordID], attempt.ResponseTimeMs.Int32)
+		}
+		if attempt.DifficultyRating.Valid {
+			practice.DifficultyRating = &attempt.DifficultyRating.Int32
+		}
+		practiced[attempt.WordID] = append(practiced[attempt.WordID], practice)
+	}
+
+	var review []WordOutcome
+	for i := range words {
+		word := &words[i]
+		level, ok := before[word.WordID]
+		if !ok {
+			level = vocabstats.MinMastery
+		}
+		word.MasteryBefore = level
+		word.MasteryAfter = vocabstats.Mastery(level, practiced[word.WordID])
+		word.MasteryChange = word.MasteryAfter - word.MasteryBefore
+		if times := responseTimes[word.WordID]; len(times) > 0 {
+			var total int64
+			for _, ms := range times {
+				total += int64(ms)
+			}
+			word.AvgResponseTimeMs = float64(total) / float64(len(times))
+		}
+		if word.Review {
+			review = append(review, *word)
+		}
+	}
+
+	sort.SliceStable(review, func(i, j int) bool {
+		return review[i].Attempts-review[i].CorrectAttempts > review[j].Attempts-review[j].CorrectAttempts
+	})
+	reviewIDs := make([]int32, len(review))
+	for i, word := range review {
+		reviewIDs[i] = word.WordID
+	}
+	return words, reviewIDs
+}
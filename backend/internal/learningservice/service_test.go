@@ -0,0 +1,197 @@
+package learningservice
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+
+	"github.com/sqlc-dev/pqtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/wordsense"
+)
+
+// fakeStore keeps one session of user 7, one word and two study sets
+type fakeStore struct {
+	db.Querier
+	stats    db.GetSessionStatsRow
+	data     json.RawMessage
+	attempts []db.ListSessionAttemptsRow
+	mastery  []db.ListVocabularyMasteryRow
+	updated  *db.UpdateLearningSessionParams
+	created  *db.CreateLearningSessionParams
+}
+
+func (s *fakeStore) GetStudySet(ctx context.Context, id int32) (db.StudySet, error) {
+	switch id {
+	case 1:
+		return db.StudySet{ID: 1, UserID: 8}, nil
+	case 2:
+		return db.StudySet{ID: 2, UserID: 8, IsPublic: sql.NullBool{Bool: true, Valid: true}}, nil
+	}
+	return db.StudySet{}, sql.ErrNoRows
+}
+
+func (s *fakeStore) GetStudySetWords(ctx context.Context, studySetID int32) ([]db.GetStudySetWordsRow, error) {
+	return []db.GetStudySetWordsRow{{Word: db.Word{ID: 5}}, {Word: db.Word{ID: 6}}}, nil
+}
+
+func (s *fakeStore) GetLearningSession(ctx context.Context, arg db.GetLearningSessionParams) (db.LearningSession, error) {
+	if arg.ID != 1 || arg.UserID != 7 {
+		return db.LearningSession{}, sql.ErrNoRows
+	}
+	return db.LearningSession{ID: 1, UserID: 7, SessionData: pqtype.NullRawMessage{RawMessage: s.data, Valid: s.data != nil}}, nil
+}
+
+func (s *fakeStore) CreateLearningSession(ctx context.Context, arg db.CreateLearningSessionParams) (db.LearningSession, error) {
+	s.created = &arg
+	return db.LearningSession{ID: 1, UserID: arg.UserID}, nil
+}
+
+func (s *fakeStore) ListSessionAttempts(ctx context.Context, sessionID int32) ([]db.ListSessionAttemptsRow, error) {
+	return s.attempts, nil
+}
+
+func (s *fakeStore) ListVocabularyMastery(ctx context.Context, arg db.ListVocabularyMasteryParams) ([]db.ListVocabularyMasteryRow, error) {
+	var rows []db.ListVocabularyMasteryRow
+	for _, row := range s.mastery {
+		for _, wordID := range arg.WordIds {
+			if row.WordID == wordID {
+				rows = append(rows, row)
+			}
+		}
+	}
+	return rows, nil
+}
+
+func (s *fakeStore) GetWord(ctx context.Context, id int32) (db.Word, error) {
+	if id != 5 {
+		return db.Word{}, sql.ErrNoRows
+	}
+	return db.Word{ID: 5, Word: "book", ShortMean: "a written work"}, nil
+}
+
+func (s *fakeStore) CreateLearningAttempt(ctx context.Context, arg db.CreateLearningAttemptParams) (db.LearningAttempt, error) {
+	return db.LearningAttempt{SessionID: arg.SessionID, WordID: arg.WordID, CorrectAnswer: arg.CorrectAnswer, IsCorrect: arg.IsCorrect}, nil
+}
+
+func (s *fakeStore) GetSessionStats(ctx context.Context, sessionID int32) (db.GetSessionStatsRow, error) {
+	return s.stats, nil
+}
+
+func (s *fakeStore) UpdateLearningSession(ctx context.Context, arg db.UpdateLearningSessionParams) (db.LearningSession, error) {
+	s.updated = &arg
+	return db.LearningSession{ID: arg.ID, UserID: arg.UserID}, nil
+}
+
+type fakeSenses []wordsense.Sense
+
+func (s fakeSenses) List(ctx context.Context, wordID int32) ([]wordsense.Sense, error) {
+	return s, nil
+}
+
+func TestSessionWordsChecksStudySetAccess(t *testing.T) {
+	service := NewService(&fakeStore{}, fakeSenses{})
+	ctx := context.Background()
+
+	private, public, missing := int32(1), int32(2), int32(3)
+	_, err := service.SessionWords(ctx, 7, &private, 10)
+	assert.ErrorIs(t, err, ErrStudySetDenied)
+	_, err = service.SessionWords(ctx, 7, &missing, 10)
+	assert.ErrorIs(t, err, ErrStudySetNotFound)
+
+	words, err := service.SessionWords(ctx, 7, &public, 1)
+	require.NoError(t, err)
+	assert.Len(t, words, 1)
+}
+
+func TestSubmitAttemptGradesSenses(t *testing.T) {
+	senses := fakeSenses{{ID: 20, WordID: 5, Meaning: "a written work"}, {ID: 21, WordID: 5, Meaning: "to reserve"}}
+	service := NewService(&fakeStore{}, senses)
+	ctx := context.Background()
+
+	sense := int32(21)
+	graded, err := service.SubmitAttempt(ctx, 7, 1, Attempt{WordID: 5, SenseID: &sense, AttemptType: "quiz", UserAnswer: "to reserve"})
+	require.NoError(t, err)
+	assert.True(t, graded.Attempt.IsCorrect)
+	assert.Equal(t, "to reserve", graded.Attempt.CorrectAnswer)
+
+	graded, err = service.SubmitAttempt(ctx, 7, 1, Attempt{WordID: 5, AttemptType: "type", UserAnswer: " To Reserve "})
+	require.NoError(t, err)
+	assert.True(t, graded.Attempt.IsCorrect)
+
+	other := int32(99)
+	_, err = service.SubmitAttempt(ctx, 7, 1, Attempt{WordID: 5, SenseID: &other, AttemptType: "quiz"})
+	assert.ErrorIs(t, err, ErrSenseMismatch)
+	_, err = service.SubmitAttempt(ctx, 8, 1, Attempt{WordID: 5, AttemptType: "quiz"})
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+	_, err = service.SubmitAttempt(ctx, 7, 1, Attempt{WordID: 6, AttemptType: "quiz"})
+	assert.ErrorIs(t, err, ErrWordNotFound)
+}
+
+func TestCompleteSessionWithoutAttempts(t *testing.T) {
+	store := &fakeStore{}
+	service := NewService(store, fakeSenses{})
+
+	_, err := service.CompleteSession(context.Background(), 8, 1, nil)
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+	assert.Nil(t, store.updated)
+
+	completion, err := service.CompleteSession(context.Background(), 7, 1, nil)
+	require.NoError(t, err)
+	assert.Equal(t, Stats{}, completion.Stats)
+	require.NotNil(t, store.updated)
+	assert.True(t, store.updated.CompletedAt.Valid)
+	assert.JSONEq(t, `{"final_stats":{"total_attempts":0,"correct_attempts":0,"avg_response_time":0,"avg_difficulty":0,"accuracy_percentage":0}}`,
+		string(store.updated.SessionData.RawMessage))
+}
+
+func TestCompleteSessionSummarizesWords(t *testing.T) {
+	ms := func(v int32) sql.NullInt32 { return sql.NullInt32{Int32: v, Valid: true} }
+	attempt := func(wordID int32, correct bool, responseTime int32) db.ListSessionAttemptsRow {
+		return db.ListSessionAttemptsRow{
+			LearningAttempt: db.LearningAttempt{WordID: wordID, IsCorrect: correct, ResponseTimeMs: ms(responseTime)},
+			Word:            db.Word{ID: wordID, Word: map[int32]string{5: "book", 6: "desk", 9: "lamp"}[wordID]},
+		}
+	}
+	store := &fakeStore{
+		mastery: []db.ListVocabularyMasteryRow{{WordID: 5, MasteryLevel: ms(4)}, {WordID: 6, MasteryLevel: ms(3)}, {WordID: 9, MasteryLevel: ms(6)}},
+	}
+	service := NewService(store, fakeSenses{})
+	ctx := context.Background()
+
+	// Levels are kept when the session starts, before its attempts move them
+	_, err := service.StartSession(ctx, NewSession{UserID: 7, SessionType: "quiz", Words: 2, WordIDs: []int32{5, 6}, Data: map[string]interface{}{}})
+	require.NoError(t, err)
+	require.NotNil(t, store.created)
+	store.data = store.created.SessionData.RawMessage
+	assert.JSONEq(t, `{"mastery_before":{"5":4,"6":3}}`, string(store.data))
+
+	store.mastery = []db.ListVocabularyMasteryRow{{WordID: 5, MasteryLevel: ms(2)}, {WordID: 6, MasteryLevel: ms(7)}, {WordID: 9, MasteryLevel: ms(6)}}
+	store.attempts = []db.ListSessionAttemptsRow{
+		attempt(6, false, 3000),
+		attempt(5, true, 1000),
+		attempt(6, false, 4000),
+		attempt(9, false, 2000),
+		attempt(6, true, 2000),
+	}
+	completion, err := service.CompleteSession(ctx, 7, 1, nil)
+	require.NoError(t, err)
+
+	require.Len(t, completion.Words, 3)
+	assert.Equal(t, WordOutcome{
+		WordID: 6, Word: "desk", Attempts: 3, CorrectAttempts: 1, LastCorrect: true, AvgResponseTimeMs: 3000,
+		MasteryBefore: 3, MasteryAfter: 2, MasteryChange: -1, Review: true,
+	}, completion.Words[0])
+	assert.Equal(t, WordOutcome{
+		WordID: 5, Word: "book", Attempts: 1, CorrectAttempts: 1, LastCorrect: true, AvgResponseTimeMs: 1000,
+		MasteryBefore: 4, MasteryAfter: 5, MasteryChange: 1,
+	}, completion.Words[1])
+	// Word 9 was not part of the session when it started and gets its current level
+	assert.Equal(t, int32(6), completion.Words[2].MasteryBefore)
+	assert.Equal(t, int32(4), completion.Words[2].MasteryAfter)
+
+	assert.Equal(t, []int32{6, 9}, completion.ReviewWordIDs)
+}
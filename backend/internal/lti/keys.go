@@ -0,0 +1,128 @@
+package lti
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+// JWK is an RSA public key in JSON Web Key form
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// JWKS is a JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// ToolKey is the RSA key the tool signs service requests with. Platforms fetch
+// its public half from the tool JWKS endpoint.
+type ToolKey struct {
+	private *rsa.PrivateKey
+	kid     string
+}
+
+// LoadToolKey reads a PEM encoded PKCS#1 or PKCS#8 RSA private key
+func LoadToolKey(path string) (*ToolKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read LTI private key: %w", err)
+	}
+	return ParseToolKey(data)
+}
+
+// ParseToolKey parses a PEM encoded PKCS#1 or PKCS#8 RSA private key
+func ParseToolKey(data []byte) (*ToolKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("LTI private key is not PEM encoded")
+	}
+
+	var private *rsa.PrivateKey
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse LTI private key: %w", err)
+		}
+		private = key
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse LTI private key: %w", err)
+		}
+		rsaKey, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, errors.New("LTI private key is not an RSA key")
+		}
+		private = rsaKey
+	default:
+		return nil, fmt.Errorf("unsupported LTI private key type %q", block.Type)
+	}
+
+	return NewToolKey(private), nil
+}
+
+// NewToolKey wraps an RSA private key. The key ID is its RFC 7638 thumbprint.
+func NewToolKey(private *rsa.PrivateKey) *ToolKey {
+	jwk := publicJWK(&private.PublicKey)
+	thumbprint := sha256.Sum256([]byte(`{"e":"` + jwk.E + `","kty":"RSA","n":"` + jwk.N + `"}`))
+	return &ToolKey{
+		private: private,
+		kid:     base64.RawURLEncoding.EncodeToString(thumbprint[:]),
+	}
+}
+
+// KeyID returns the key ID sent in the header of signed tokens
+func (k *ToolKey) KeyID() string {
+	return k.kid
+}
+
+// JWKS returns the public key set of the tool
+func (k *ToolKey) JWKS() JWKS {
+	jwk := publicJWK(&k.private.PublicKey)
+	jwk.Kid = k.kid
+	jwk.Use = "sig"
+	jwk.Alg = "RS256"
+	return JWKS{Keys: []JWK{jwk}}
+}
+
+func publicJWK(key *rsa.PublicKey) JWK {
+	return JWK{
+		Kty: "RSA",
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+// PublicKey decodes an RSA JWK
+func (j JWK) PublicKey() (*rsa.PublicKey, error) {
+	if j.Kty != "RSA" {
+		return nil, fmt.Errorf("unsupported key type %q", j.Kty)
+	}
+	n, err := base64.RawURLEncoding.DecodeString(j.N)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key modulus: %w", err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(j.E)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key exponent: %w", err)
+	}
+	exponent := new(big.Int).SetBytes(e)
+	if !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+		return nil, errors.New("invalid key exponent")
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+}
@@ -0,0 +1,47 @@
+package lti
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseToolKeyAcceptsPKCS1AndPKCS8(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	for _, block := range []*pem.Block{
+		{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)},
+		{Type: "PRIVATE KEY", Bytes: pkcs8},
+	} {
+		toolKey, err := ParseToolKey(pem.EncodeToMemory(block))
+		require.NoError(t, err, block.Type)
+		assert.Equal(t, NewToolKey(key).KeyID(), toolKey.KeyID())
+	}
+
+	_, err = ParseToolKey([]byte("not a key"))
+	assert.Error(t, err)
+}
+
+func TestJWKSRoundTrip(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	toolKey := NewToolKey(key)
+
+	jwks := toolKey.JWKS()
+	require.Len(t, jwks.Keys, 1)
+	assert.Equal(t, toolKey.KeyID(), jwks.Keys[0].Kid)
+	assert.Equal(t, "RS256", jwks.Keys[0].Alg)
+
+	public, err := jwks.Keys[0].PublicKey()
+	require.NoError(t, err)
+	assert.True(t, public.Equal(&key.PublicKey))
+}
@@ -0,0 +1,204 @@
+package lti
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+// LTI 1.3 message values
+const (
+	MessageTypeResourceLink = "LtiResourceLinkRequest"
+	LTIVersion              = "1.3.0"
+
+	// ScopeScore allows posting scores to line items
+	ScopeScore = "https://purl.imsglobal.org/spec/lti-ags/scope/score"
+	// ScopeMemberships allows reading course rosters
+	ScopeMemberships = "https://purl.imsglobal.org/spec/lti-nrps/scope/contextmembership.readonly"
+
+	roleInstructor = "http://purl.imsglobal.org/vocab/lis/v2/membership#Instructor"
+)
+
+// clockSkew is tolerated between platform and tool clocks
+const clockSkew = time.Minute
+
+// audience is a string or a list of strings
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+// ResourceLinkClaim identifies the link placement the user launched from
+type ResourceLinkClaim struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+// ContextClaim identifies the course the user launched from
+type ContextClaim struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+	Title string `json:"title"`
+}
+
+// AGSClaim holds the assignment and grade service endpoints of a launch
+type AGSClaim struct {
+	Scope     []string `json:"scope"`
+	LineItems string   `json:"lineitems"`
+	LineItem  string   `json:"lineitem"`
+}
+
+// NRPSClaim holds the names and role provisioning service endpoint of a launch
+type NRPSClaim struct {
+	ContextMembershipsURL string `json:"context_memberships_url"`
+}
+
+// Claims are the claims of an LTI 1.3 launch ID token
+type Claims struct {
+	Issuer          string   `json:"iss"`
+	Subject         string   `json:"sub"`
+	Audience        audience `json:"aud"`
+	AuthorizedParty string   `json:"azp"`
+	ExpiresAt       int64    `json:"exp"`
+	IssuedAt        int64    `json:"iat"`
+	Nonce           string   `json:"nonce"`
+	Name            string   `json:"name"`
+	GivenName       string   `json:"given_name"`
+	FamilyName      string   `json:"family_name"`
+	Email           string   `json:"email"`
+
+	MessageType   string            `json:"https://purl.imsglobal.org/spec/lti/claim/message_type"`
+	Version       string            `json:"https://purl.imsglobal.org/spec/lti/claim/version"`
+	DeploymentID  string            `json:"https://purl.imsglobal.org/spec/lti/claim/deployment_id"`
+	TargetLinkURI string            `json:"https://purl.imsglobal.org/spec/lti/claim/target_link_uri"`
+	ResourceLink  ResourceLinkClaim `json:"https://purl.imsglobal.org/spec/lti/claim/resource_link"`
+	Context       ContextClaim      `json:"https://purl.imsglobal.org/spec/lti/claim/context"`
+	Roles         []string          `json:"https://purl.imsglobal.org/spec/lti/claim/roles"`
+	Custom        map[string]any    `json:"https://purl.imsglobal.org/spec/lti/claim/custom"`
+	AGS           *AGSClaim         `json:"https://purl.imsglobal.org/spec/lti-ags/claim/endpoint"`
+	NRPS          *NRPSClaim        `json:"https://purl.imsglobal.org/spec/lti-nrps/claim/namesroleservice"`
+}
+
+// DisplayName returns the best available name of the user
+func (c *Claims) DisplayName() string {
+	if name := strings.TrimSpace(c.Name); name != "" {
+		return name
+	}
+	return strings.TrimSpace(c.GivenName + " " + c.FamilyName)
+}
+
+// ExamID returns the exam bound to the resource link through the exam_id
+// custom parameter, or 0 when there is none
+func (c *Claims) ExamID() int32 {
+	value, ok := c.Custom["exam_id"]
+	if !ok {
+		return 0
+	}
+	var raw string
+	switch v := value.(type) {
+	case string:
+		raw = v
+	case float64:
+		raw = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return 0
+	}
+	id, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 32)
+	if err != nil || id <= 0 {
+		return 0
+	}
+	return int32(id)
+}
+
+// IsInstructor reports whether the user launched as a course instructor
+func (c *Claims) IsInstructor() bool {
+	for _, role := range c.Roles {
+		if role == roleInstructor {
+			return true
+		}
+	}
+	return false
+}
+
+// hasScope reports whether the grade service claim grants a scope
+func (a *AGSClaim) hasScope(scope string) bool {
+	for _, s := range a.Scope {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyIDToken checks the signature and standard claims of a launch ID token
+// issued by the platform
+func (s *Service) verifyIDToken(ctx context.Context, platform db.LtiPlatform, idToken string) (*Claims, error) {
+	parser := &jwt.Parser{ValidMethods: []string{"RS256"}, SkipClaimsValidation: true}
+	mapClaims := jwt.MapClaims{}
+	_, err := parser.ParseWithClaims(idToken, mapClaims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return s.keys.Key(ctx, platform.JwksUrl, kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidLaunch, err)
+	}
+
+	encoded, err := json.Marshal(mapClaims)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode claims: %w", err)
+	}
+	var claims Claims
+	if err := json.Unmarshal(encoded, &claims); err != nil {
+		return nil, fmt.Errorf("%w: malformed claims: %v", ErrInvalidLaunch, err)
+	}
+
+	now := s.now()
+	switch {
+	case claims.Issuer != platform.Issuer:
+		return nil, fmt.Errorf("%w: unexpected issuer", ErrInvalidLaunch)
+	case !claims.hasAudience(platform.ClientID):
+		return nil, fmt.Errorf("%w: token is not for this tool", ErrInvalidLaunch)
+	case claims.ExpiresAt == 0 || now.After(time.Unix(claims.ExpiresAt, 0).Add(clockSkew)):
+		return nil, fmt.Errorf("%w: token expired", ErrInvalidLaunch)
+	case claims.IssuedAt != 0 && time.Unix(claims.IssuedAt, 0).After(now.Add(clockSkew)):
+		return nil, fmt.Errorf("%w: token issued in the future", ErrInvalidLaunch)
+	case claims.Subject == "":
+		return nil, fmt.Errorf("%w: anonymous launches are not supported", ErrInvalidLaunch)
+	}
+	return &claims, nil
+}
+
+// hasAudience checks the audience, and the authorized party when the token
+// has several audiences
+func (c *Claims) hasAudience(clientID string) bool {
+	found := false
+	for _, aud := range c.Audience {
+		if aud == clientID {
+			found = true
+		}
+	}
+	if !found {
+		return false
+	}
+	if len(c.Audience) > 1 || c.AuthorizedParty != "" {
+		return c.AuthorizedParty == clientID
+	}
+	return true
+}
@@ -0,0 +1,402 @@
+package lti
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/logger"
+	"github.com/toeic-app/internal/util"
+)
+
+var (
+	ErrUnknownPlatform  = errors.New("unknown LTI platform")
+	ErrInvalidState     = errors.New("invalid or expired LTI login state")
+	ErrInvalidLaunch    = errors.New("invalid LTI launch")
+	ErrPlatformNotFound = errors.New("LTI platform not found")
+	ErrContextNotFound  = errors.New("LTI context not found")
+	ErrNoRosterService  = errors.New("the platform did not offer a roster service for this course")
+)
+
+// stateTTL is how long a login may take between initiation and launch
+const stateTTL = 10 * time.Minute
+
+// maxUsernameLength matches the limit of registration
+const maxUsernameLength = 50
+
+// LoginRequest is a third-party initiated login from a platform
+type LoginRequest struct {
+	Issuer        string
+	LoginHint     string
+	TargetLinkURI string
+	MessageHint   string
+	ClientID      string
+	DeploymentID  string
+}
+
+// LaunchResult is a verified launch with the local user it signed in
+type LaunchResult struct {
+	UserID       int32  `json:"user_id"`
+	PlatformID   int32  `json:"platform_id"`
+	ContextID    int32  `json:"context_id,omitempty"` // Local ID of the course, 0 when launched outside a course
+	ExamID       int32  `json:"exam_id,omitempty"`
+	IsInstructor bool   `json:"is_instructor"`
+	Target       string `json:"target_link_uri,omitempty"`
+}
+
+// Service handles LTI 1.3 launches, grade passback and roster sync
+type Service struct {
+	store      db.Querier
+	key        *ToolKey
+	keys       *keyFetcher
+	httpClient *http.Client
+	now        func() time.Time
+
+	mu     sync.Mutex
+	tokens map[string]serviceToken
+}
+
+// NewService creates a new LTI service signing service requests with key
+func NewService(store db.Querier, key *ToolKey) *Service {
+	httpClient := &http.Client{Timeout: 15 * time.Second}
+	return &Service{
+		store:      store,
+		key:        key,
+		keys:       newKeyFetcher(httpClient),
+		httpClient: httpClient,
+		now:        time.Now,
+		tokens:     make(map[string]serviceToken),
+	}
+}
+
+// JWKS returns the public keys platforms verify tool requests with
+func (s *Service) JWKS() JWKS {
+	return s.key.JWKS()
+}
+
+// RegisterPlatform registers an LMS deployment
+func (s *Service) RegisterPlatform(ctx context.Context, arg db.CreateLTIPlatformParams) (db.LtiPlatform, error) {
+	platform, err := s.store.CreateLTIPlatform(ctx, arg)
+	if err != nil {
+		return db.LtiPlatform{}, fmt.Errorf("failed to create platform: %w", err)
+	}
+	return platform, nil
+}
+
+// Platforms lists registered platforms
+func (s *Service) Platforms(ctx context.Context) ([]db.LtiPlatform, error) {
+	platforms, err := s.store.ListLTIPlatforms(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list platforms: %w", err)
+	}
+	return platforms, nil
+}
+
+// DeletePlatform removes a platform with its courses and account links
+func (s *Service) DeletePlatform(ctx context.Context, id int32) error {
+	rows, err := s.store.DeleteLTIPlatform(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete platform: %w", err)
+	}
+	if rows == 0 {
+		return ErrPlatformNotFound
+	}
+	return nil
+}
+
+// Login starts an OIDC login and returns the platform authorization URL the
+// browser is redirected to. redirectURI is the launch endpoint of the tool.
+func (s *Service) Login(ctx context.Context, req LoginRequest, redirectURI string) (string, error) {
+	if req.Issuer == "" || req.LoginHint == "" {
+		return "", fmt.Errorf("%w: iss and login_hint are required", ErrInvalidLaunch)
+	}
+
+	platform, err := s.findPlatform(ctx, req.Issuer, req.ClientID, req.DeploymentID)
+	if err != nil {
+		return "", err
+	}
+
+	// Logins abandoned at the platform leave their state behind
+	if _, err := s.store.DeleteExpiredLTILaunchStates(ctx); err != nil {
+		logger.Debug("Failed to delete expired LTI login states: %v", err)
+	}
+
+	state, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	if err := s.store.CreateLTILaunchState(ctx, db.CreateLTILaunchStateParams{
+		State:      state,
+		Nonce:      nonce,
+		PlatformID: platform.ID,
+		ExpiresAt:  s.now().Add(stateTTL),
+	}); err != nil {
+		return "", fmt.Errorf("failed to save login state: %w", err)
+	}
+
+	authURL, err := url.Parse(platform.AuthLoginUrl)
+	if err != nil {
+		return "", fmt.Errorf("invalid platform login URL: %w", err)
+	}
+	query := authURL.Query()
+	query.Set("scope", "openid")
+	query.Set("response_type", "id_token")
+	query.Set("response_mode", "form_post")
+	query.Set("prompt", "none")
+	query.Set("client_id", platform.ClientID)
+	query.Set("redirect_uri", redirectURI)
+	query.Set("login_hint", req.LoginHint)
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	if req.MessageHint != "" {
+		query.Set("lti_message_hint", req.MessageHint)
+	}
+	authURL.RawQuery = query.Encode()
+
+	return authURL.String(), nil
+}
+
+// findPlatform selects the registration a login is for. Client and deployment
+// IDs are optional in the login request; they narrow the choice when present.
+func (s *Service) findPlatform(ctx context.Context, issuer, clientID, deploymentID string) (db.LtiPlatform, error) {
+	platforms, err := s.store.ListLTIPlatformsByIssuer(ctx, issuer)
+	if err != nil {
+		return db.LtiPlatform{}, fmt.Errorf("failed to list platforms: %w", err)
+	}
+
+	var match *db.LtiPlatform
+	for i := range platforms {
+		p := &platforms[i]
+		if (clientID != "" && p.ClientID != clientID) || (deploymentID != "" && p.DeploymentID != deploymentID) {
+			continue
+		}
+		// Deployments of one client share its keys, so any of them will do;
+		// several clients of one issuer are ambiguous without a client ID
+		if match != nil && match.ClientID != p.ClientID {
+			return db.LtiPlatform{}, fmt.Errorf("%w: client_id is required for issuer %s", ErrUnknownPlatform, issuer)
+		}
+		if match == nil {
+			match = p
+		}
+	}
+	if match == nil {
+		return db.LtiPlatform{}, ErrUnknownPlatform
+	}
+	return *match, nil
+}
+
+// Launch verifies a launch ID token against its login state, signs the user in,
+// provisioning an account on first launch, and records the course, roster
+// membership and resource link of the launch
+func (s *Service) Launch(ctx context.Context, idToken, state string) (*LaunchResult, error) {
+	loginState, err := s.store.ConsumeLTILaunchState(ctx, state)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidState
+		}
+		return nil, fmt.Errorf("failed to get login state: %w", err)
+	}
+
+	platform, err := s.store.GetLTIPlatform(ctx, loginState.PlatformID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidState
+		}
+		return nil, fmt.Errorf("failed to get platform: %w", err)
+	}
+
+	claims, err := s.verifyIDToken(ctx, platform, idToken)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case claims.Nonce != loginState.Nonce:
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidLaunch)
+	case claims.MessageType != MessageTypeResourceLink:
+		return nil, fmt.Errorf("%w: unsupported message type %q", ErrInvalidLaunch, claims.MessageType)
+	case claims.Version != LTIVersion:
+		return nil, fmt.Errorf("%w: unsupported LTI version %q", ErrInvalidLaunch, claims.Version)
+	case claims.DeploymentID == "":
+		return nil, fmt.Errorf("%w: missing deployment ID", ErrInvalidLaunch)
+	}
+
+	// The deployment must be registered, not just the client
+	deployment, err := s.findPlatform(ctx, platform.Issuer, platform.ClientID, claims.DeploymentID)
+	if err != nil {
+		if errors.Is(err, ErrUnknownPlatform) {
+			return nil, fmt.Errorf("%w: unknown deployment %q", ErrInvalidLaunch, claims.DeploymentID)
+		}
+		return nil, err
+	}
+
+	userID, err := s.provisionUser(ctx, deployment.ID, claims.Subject, claims.DisplayName(), claims.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &LaunchResult{
+		UserID:       userID,
+		PlatformID:   deployment.ID,
+		ExamID:       claims.ExamID(),
+		IsInstructor: claims.IsInstructor(),
+		Target:       claims.TargetLinkURI,
+	}
+	if claims.Context.ID == "" {
+		return result, nil
+	}
+
+	courseID, err := s.recordCourse(ctx, deployment.ID, userID, claims)
+	if err != nil {
+		return nil, err
+	}
+	result.ContextID = courseID
+	return result, nil
+}
+
+// recordCourse stores the course, the membership of the user and the resource
+// link of a launch, returning the local course ID
+func (s *Service) recordCourse(ctx context.Context, platformID, userID int32, claims *Claims) (int32, error) {
+	var membershipsURL, lineItemsURL, lineItemURL sql.NullString
+	if claims.NRPS != nil && claims.NRPS.ContextMembershipsURL != "" {
+		membershipsURL = sql.NullString{String: claims.NRPS.ContextMembershipsURL, Valid: true}
+	}
+	if claims.AGS != nil && claims.AGS.hasScope(ScopeScore) {
+		lineItemsURL = sql.NullString{String: claims.AGS.LineItems, Valid: claims.AGS.LineItems != ""}
+		lineItemURL = sql.NullString{String: claims.AGS.LineItem, Valid: claims.AGS.LineItem != ""}
+	}
+
+	title := claims.Context.Title
+	if title == "" {
+		title = claims.Context.Label
+	}
+	course, err := s.store.UpsertLTIContext(ctx, db.UpsertLTIContextParams{
+		PlatformID:     platformID,
+		ContextID:      claims.Context.ID,
+		Title:          title,
+		MembershipsUrl: membershipsURL,
+		LineitemsUrl:   lineItemsURL,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to save course: %w", err)
+	}
+
+	if err := s.store.UpsertLTIContextMember(ctx, db.UpsertLTIContextMemberParams{
+		ContextID: course.ID,
+		UserID:    userID,
+		Roles:     strings.Join(claims.Roles, ","),
+		Status:    "active",
+	}); err != nil {
+		return 0, fmt.Errorf("failed to save course membership: %w", err)
+	}
+
+	if claims.ResourceLink.ID != "" {
+		examID := sql.NullInt32{Int32: claims.ExamID(), Valid: claims.ExamID() != 0}
+		if _, err := s.store.UpsertLTIResourceLink(ctx, db.UpsertLTIResourceLinkParams{
+			ContextID:      course.ID,
+			ResourceLinkID: claims.ResourceLink.ID,
+			Title:          claims.ResourceLink.Title,
+			ExamID:         examID,
+			LineitemUrl:    lineItemURL,
+		}); err != nil {
+			return 0, fmt.Errorf("failed to save resource link: %w", err)
+		}
+	}
+
+	return course.ID, nil
+}
+
+// provisionUser returns the account linked to a platform user, creating it on
+// first sight. Existing accounts are never linked by email, as the platform
+// is not trusted to vouch for addresses registered directly with the app.
+func (s *Service) provisionUser(ctx context.Context, platformID int32, subject, name, email string) (int32, error) {
+	userID, err := s.store.GetLTIUser(ctx, db.GetLTIUserParams{PlatformID: platformID, Subject: subject})
+	if err == nil {
+		return userID, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("failed to get linked user: %w", err)
+	}
+
+	username := name
+	if username == "" {
+		username = "LMS user"
+	}
+	if utf8.RuneCountInString(username) > maxUsernameLength {
+		username = string([]rune(username)[:maxUsernameLength])
+	}
+
+	if email == "" || !util.IsValidEmail(email) {
+		email = placeholderEmail(platformID, subject)
+	} else if _, err := s.store.GetUserByEmail(ctx, sql.NullString{String: email, Valid: true}); err == nil {
+		email = placeholderEmail(platformID, subject)
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("failed to check email: %w", err)
+	}
+
+	// LMS users sign in through their platform, so the password is unusable
+	secret, err := randomToken()
+	if err != nil {
+		return 0, err
+	}
+	passwordHash, err := util.HashPassword(secret)
+	if err != nil {
+		return 0, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	user, err := s.store.CreateUser(ctx, db.CreateUserParams{
+		Username:     username,
+		Email:        sql.NullString{String: email, Valid: true},
+		PasswordHash: passwordHash,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	rows, err := s.store.CreateLTIUser(ctx, db.CreateLTIUserParams{PlatformID: platformID, Subject: subject, UserID: user.ID})
+	if err != nil {
+		return 0, fmt.Errorf("failed to link user: %w", err)
+	}
+	if rows == 0 {
+		// A concurrent launch linked the subject first
+		if err := s.store.DeleteUser(ctx, user.ID); err != nil {
+			return 0, fmt.Errorf("failed to delete duplicate user: %w", err)
+		}
+		userID, err := s.store.GetLTIUser(ctx, db.GetLTIUserParams{PlatformID: platformID, Subject: subject})
+		if err != nil {
+			return 0, fmt.Errorf("failed to get linked user: %w", err)
+		}
+		return userID, nil
+	}
+
+	return user.ID, nil
+}
+
+// placeholderEmail is a stable undeliverable address for platform users
+// without a usable email
+func placeholderEmail(platformID int32, subject string) string {
+	sum := sha256.Sum256([]byte(subject))
+	return fmt.Sprintf("lti-%d-%s@lti.invalid", platformID, hex.EncodeToString(sum[:8]))
+}
+
+func randomToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate random token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,504 @@
+package lti
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+type linkKey struct {
+	platformID int32
+	subject    string
+}
+
+// ltiStore keeps LTI records in memory; other Querier methods are not used
+type ltiStore struct {
+	db.Querier
+	mu          sync.Mutex
+	platforms   []db.LtiPlatform
+	states      map[string]db.LtiLaunchState
+	users       map[int32]db.User
+	links       map[linkKey]int32
+	contexts    []db.LtiContext
+	members     map[[2]int32]db.LtiContextMember
+	resources   []db.LtiResourceLink
+	submissions map[[2]int32]db.UpsertLTIScoreSubmissionParams
+	nextUserID  int32
+}
+
+func newLTIStore(platforms ...db.LtiPlatform) *ltiStore {
+	return &ltiStore{
+		platforms:   platforms,
+		states:      make(map[string]db.LtiLaunchState),
+		users:       make(map[int32]db.User),
+		links:       make(map[linkKey]int32),
+		members:     make(map[[2]int32]db.LtiContextMember),
+		submissions: make(map[[2]int32]db.UpsertLTIScoreSubmissionParams),
+	}
+}
+
+func (s *ltiStore) ListLTIPlatformsByIssuer(ctx context.Context, issuer string) ([]db.LtiPlatform, error) {
+	var out []db.LtiPlatform
+	for _, p := range s.platforms {
+		if p.Issuer == issuer {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+func (s *ltiStore) GetLTIPlatform(ctx context.Context, id int32) (db.LtiPlatform, error) {
+	for _, p := range s.platforms {
+		if p.ID == id {
+			return p, nil
+		}
+	}
+	return db.LtiPlatform{}, sql.ErrNoRows
+}
+
+func (s *ltiStore) DeleteExpiredLTILaunchStates(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func (s *ltiStore) CreateLTILaunchState(ctx context.Context, arg db.CreateLTILaunchStateParams) error {
+	s.states[arg.State] = db.LtiLaunchState{State: arg.State, Nonce: arg.Nonce, PlatformID: arg.PlatformID, ExpiresAt: arg.ExpiresAt}
+	return nil
+}
+
+func (s *ltiStore) ConsumeLTILaunchState(ctx context.Context, state string) (db.LtiLaunchState, error) {
+	st, ok := s.states[state]
+	if !ok {
+		return db.LtiLaunchState{}, sql.ErrNoRows
+	}
+	delete(s.states, state)
+	return st, nil
+}
+
+func (s *ltiStore) GetLTIUser(ctx context.Context, arg db.GetLTIUserParams) (int32, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	userID, ok := s.links[linkKey{arg.PlatformID, arg.Subject}]
+	if !ok {
+		return 0, sql.ErrNoRows
+	}
+	return userID, nil
+}
+
+func (s *ltiStore) CreateLTIUser(ctx context.Context, arg db.CreateLTIUserParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := linkKey{arg.PlatformID, arg.Subject}
+	if _, ok := s.links[key]; ok {
+		return 0, nil
+	}
+	s.links[key] = arg.UserID
+	return 1, nil
+}
+
+func (s *ltiStore) GetUserByEmail(ctx context.Context, email sql.NullString) (db.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, u := range s.users {
+		if u.Email == email {
+			return u, nil
+		}
+	}
+	return db.User{}, sql.ErrNoRows
+}
+
+func (s *ltiStore) CreateUser(ctx context.Context, arg db.CreateUserParams) (db.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextUserID++
+	user := db.User{ID: s.nextUserID, Username: arg.Username, Email: arg.Email, PasswordHash: arg.PasswordHash}
+	s.users[user.ID] = user
+	return user, nil
+}
+
+func (s *ltiStore) DeleteUser(ctx context.Context, id int32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.users, id)
+	return nil
+}
+
+func (s *ltiStore) UpsertLTIContext(ctx context.Context, arg db.UpsertLTIContextParams) (db.LtiContext, error) {
+	for i, c := range s.contexts {
+		if c.PlatformID == arg.PlatformID && c.ContextID == arg.ContextID {
+			s.contexts[i].Title = arg.Title
+			if arg.MembershipsUrl.Valid {
+				s.contexts[i].MembershipsUrl = arg.MembershipsUrl
+			}
+			return s.contexts[i], nil
+		}
+	}
+	c := db.LtiContext{ID: int32(len(s.contexts) + 1), PlatformID: arg.PlatformID, ContextID: arg.ContextID, Title: arg.Title, MembershipsUrl: arg.MembershipsUrl, LineitemsUrl: arg.LineitemsUrl}
+	s.contexts = append(s.contexts, c)
+	return c, nil
+}
+
+func (s *ltiStore) GetLTIContext(ctx context.Context, id int32) (db.LtiContext, error) {
+	for _, c := range s.contexts {
+		if c.ID == id {
+			return c, nil
+		}
+	}
+	return db.LtiContext{}, sql.ErrNoRows
+}
+
+func (s *ltiStore) UpsertLTIContextMember(ctx context.Context, arg db.UpsertLTIContextMemberParams) error {
+	s.members[[2]int32{arg.ContextID, arg.UserID}] = db.LtiContextMember{ContextID: arg.ContextID, UserID: arg.UserID, Roles: arg.Roles, Status: arg.Status}
+	return nil
+}
+
+func (s *ltiStore) DeactivateLTIContextMembers(ctx context.Context, arg db.DeactivateLTIContextMembersParams) (int64, error) {
+	active := make(map[int32]bool)
+	for _, id := range arg.ActiveUserIds {
+		active[id] = true
+	}
+	var n int64
+	for key, m := range s.members {
+		if m.ContextID == arg.ContextID && m.Status == "active" && !active[m.UserID] {
+			m.Status = "inactive"
+			s.members[key] = m
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (s *ltiStore) MarkLTIContextRosterSynced(ctx context.Context, id int32) error {
+	return nil
+}
+
+func (s *ltiStore) UpsertLTIResourceLink(ctx context.Context, arg db.UpsertLTIResourceLinkParams) (db.LtiResourceLink, error) {
+	link := db.LtiResourceLink{ID: int32(len(s.resources) + 1), ContextID: arg.ContextID, ResourceLinkID: arg.ResourceLinkID, ExamID: arg.ExamID, LineitemUrl: arg.LineitemUrl}
+	s.resources = append(s.resources, link)
+	return link, nil
+}
+
+func (s *ltiStore) ListLTIGradeTargets(ctx context.Context, arg db.ListLTIGradeTargetsParams) ([]db.ListLTIGradeTargetsRow, error) {
+	var out []db.ListLTIGradeTargetsRow
+	for _, rl := range s.resources {
+		m, ok := s.members[[2]int32{rl.ContextID, arg.UserID}]
+		if !ok || m.Status != "active" || rl.ExamID.Int32 != arg.ExamID || !rl.LineitemUrl.Valid {
+			continue
+		}
+		course, _ := s.GetLTIContext(ctx, rl.ContextID)
+		for key, userID := range s.links {
+			if userID == arg.UserID && key.platformID == course.PlatformID {
+				out = append(out, db.ListLTIGradeTargetsRow{ResourceLinkID: rl.ID, LineitemUrl: rl.LineitemUrl, Subject: key.subject, PlatformID: course.PlatformID})
+			}
+		}
+	}
+	return out, nil
+}
+
+func (s *ltiStore) UpsertLTIScoreSubmission(ctx context.Context, arg db.UpsertLTIScoreSubmissionParams) error {
+	s.submissions[[2]int32{arg.AttemptID, arg.ResourceLinkID}] = arg
+	return nil
+}
+
+// fakePlatform is an LMS serving its keys, a token endpoint, a line item and a roster
+type fakePlatform struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	mu     sync.Mutex
+	scores []score
+	roster [][]member
+	tokens int
+}
+
+func newFakePlatform(t *testing.T) *fakePlatform {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	p := &fakePlatform{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		jwk := publicJWK(&key.PublicKey)
+		jwk.Kid = "platform-key"
+		json.NewEncoder(w).Encode(JWKS{Keys: []JWK{jwk}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		if r.Form.Get("grant_type") != "client_credentials" || r.Form.Get("client_assertion") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		p.mu.Lock()
+		p.tokens++
+		p.mu.Unlock()
+		json.NewEncoder(w).Encode(map[string]any{"access_token": "service-token", "expires_in": 3600})
+	})
+	mux.HandleFunc("/lineitems/1/scores", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer service-token" || r.Header.Get("Content-Type") != mediaTypeScore {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var sc score
+		json.NewDecoder(r.Body).Decode(&sc)
+		p.mu.Lock()
+		p.scores = append(p.scores, sc)
+		p.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("/memberships", func(w http.ResponseWriter, r *http.Request) {
+		page := 0
+		fmt.Sscan(r.URL.Query().Get("page"), &page)
+		if page+1 < len(p.roster) {
+			w.Header().Set("Link", fmt.Sprintf(`<%s/memberships?page=%d>; rel="next"`, p.server.URL, page+1))
+		}
+		json.NewEncoder(w).Encode(map[string]any{"members": p.roster[page]})
+	})
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+func (p *fakePlatform) registration() db.LtiPlatform {
+	return db.LtiPlatform{
+		ID:           1,
+		Name:         "Moodle",
+		Issuer:       "https://lms.example.com",
+		ClientID:     "tool-client",
+		DeploymentID: "dep-1",
+		AuthLoginUrl: p.server.URL + "/auth",
+		AuthTokenUrl: p.server.URL + "/token",
+		JwksUrl:      p.server.URL + "/jwks",
+	}
+}
+
+func (p *fakePlatform) idToken(t *testing.T, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "platform-key"
+	signed, err := token.SignedString(p.key)
+	require.NoError(t, err)
+	return signed
+}
+
+func (p *fakePlatform) launchClaims(nonce, subject string) jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":   "https://lms.example.com",
+		"sub":   subject,
+		"aud":   []string{"tool-client"},
+		"azp":   "tool-client",
+		"exp":   now.Add(5 * time.Minute).Unix(),
+		"iat":   now.Unix(),
+		"nonce": nonce,
+		"name":  "Lan Nguyen",
+		"email": subject + "@school.example.com",
+		"https://purl.imsglobal.org/spec/lti/claim/message_type":  MessageTypeResourceLink,
+		"https://purl.imsglobal.org/spec/lti/claim/version":       LTIVersion,
+		"https://purl.imsglobal.org/spec/lti/claim/deployment_id": "dep-1",
+		"https://purl.imsglobal.org/spec/lti/claim/resource_link": map[string]any{"id": "link-1", "title": "Mock test 1"},
+		"https://purl.imsglobal.org/spec/lti/claim/context":       map[string]any{"id": "course-1", "title": "TOEIC Prep"},
+		"https://purl.imsglobal.org/spec/lti/claim/roles":         []string{"http://purl.imsglobal.org/vocab/lis/v2/membership#Learner"},
+		"https://purl.imsglobal.org/spec/lti/claim/custom":        map[string]any{"exam_id": "42"},
+		"https://purl.imsglobal.org/spec/lti-ags/claim/endpoint": map[string]any{
+			"scope":     []string{ScopeScore},
+			"lineitems": p.server.URL + "/lineitems",
+			"lineitem":  p.server.URL + "/lineitems/1",
+		},
+		"https://purl.imsglobal.org/spec/lti-nrps/claim/namesroleservice": map[string]any{
+			"context_memberships_url": p.server.URL + "/memberships",
+		},
+	}
+}
+
+func newTestService(t *testing.T, store db.Querier) *Service {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return NewService(store, NewToolKey(key))
+}
+
+// login runs the OIDC login and returns the state and nonce sent to the platform
+func login(t *testing.T, service *Service) (string, string) {
+	redirect, err := service.Login(context.Background(), LoginRequest{
+		Issuer:    "https://lms.example.com",
+		LoginHint: "hint",
+		ClientID:  "tool-client",
+	}, "https://tool.example.com/api/v1/lti/launch")
+	require.NoError(t, err)
+
+	u, err := url.Parse(redirect)
+	require.NoError(t, err)
+	assert.Equal(t, "tool-client", u.Query().Get("client_id"))
+	assert.Equal(t, "form_post", u.Query().Get("response_mode"))
+	assert.Equal(t, "https://tool.example.com/api/v1/lti/launch", u.Query().Get("redirect_uri"))
+	return u.Query().Get("state"), u.Query().Get("nonce")
+}
+
+func TestLaunchProvisionsUserAndCourse(t *testing.T) {
+	platform := newFakePlatform(t)
+	store := newLTIStore(platform.registration())
+	service := newTestService(t, store)
+	ctx := context.Background()
+
+	state, nonce := login(t, service)
+	result, err := service.Launch(ctx, platform.idToken(t, platform.launchClaims(nonce, "student-1")), state)
+	require.NoError(t, err)
+
+	assert.Equal(t, int32(42), result.ExamID)
+	assert.False(t, result.IsInstructor)
+	assert.Equal(t, "Lan Nguyen", store.users[result.UserID].Username)
+	assert.Equal(t, "student-1@school.example.com", store.users[result.UserID].Email.String)
+	require.Len(t, store.contexts, 1)
+	assert.Equal(t, result.ContextID, store.contexts[0].ID)
+	assert.Equal(t, "active", store.members[[2]int32{result.ContextID, result.UserID}].Status)
+	require.Len(t, store.resources, 1)
+	assert.Equal(t, int32(42), store.resources[0].ExamID.Int32)
+
+	// A second launch signs the same account in
+	state, nonce = login(t, service)
+	again, err := service.Launch(ctx, platform.idToken(t, platform.launchClaims(nonce, "student-1")), state)
+	require.NoError(t, err)
+	assert.Equal(t, result.UserID, again.UserID)
+	assert.Len(t, store.users, 1)
+}
+
+func TestLaunchDoesNotLinkExistingEmail(t *testing.T) {
+	platform := newFakePlatform(t)
+	store := newLTIStore(platform.registration())
+	service := newTestService(t, store)
+	ctx := context.Background()
+
+	existing, err := store.CreateUser(ctx, db.CreateUserParams{Username: "owner", Email: sql.NullString{String: "student-1@school.example.com", Valid: true}})
+	require.NoError(t, err)
+
+	state, nonce := login(t, service)
+	result, err := service.Launch(ctx, platform.idToken(t, platform.launchClaims(nonce, "student-1")), state)
+	require.NoError(t, err)
+	assert.NotEqual(t, existing.ID, result.UserID)
+	assert.Contains(t, store.users[result.UserID].Email.String, "@lti.invalid")
+}
+
+func TestLaunchRejectsInvalidTokens(t *testing.T) {
+	platform := newFakePlatform(t)
+	store := newLTIStore(platform.registration())
+	service := newTestService(t, store)
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		modify func(claims jwt.MapClaims)
+	}{
+		{"wrong nonce", func(c jwt.MapClaims) { c["nonce"] = "other" }},
+		{"wrong audience", func(c jwt.MapClaims) { c["aud"] = "other-client"; delete(c, "azp") }},
+		{"expired", func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() }},
+		{"unknown deployment", func(c jwt.MapClaims) { c["https://purl.imsglobal.org/spec/lti/claim/deployment_id"] = "dep-2" }},
+		{"wrong version", func(c jwt.MapClaims) { c["https://purl.imsglobal.org/spec/lti/claim/version"] = "1.1" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state, nonce := login(t, service)
+			claims := platform.launchClaims(nonce, "student-1")
+			tt.modify(claims)
+			_, err := service.Launch(ctx, platform.idToken(t, claims), state)
+			assert.ErrorIs(t, err, ErrInvalidLaunch)
+		})
+	}
+
+	// A token signed by another key is rejected
+	state, nonce := login(t, service)
+	forger, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	forged := jwt.NewWithClaims(jwt.SigningMethodRS256, platform.launchClaims(nonce, "student-1"))
+	forged.Header["kid"] = "platform-key"
+	signed, err := forged.SignedString(forger)
+	require.NoError(t, err)
+	_, err = service.Launch(ctx, signed, state)
+	assert.ErrorIs(t, err, ErrInvalidLaunch)
+
+	// States are single use
+	state, nonce = login(t, service)
+	token := platform.idToken(t, platform.launchClaims(nonce, "student-1"))
+	_, err = service.Launch(ctx, token, state)
+	require.NoError(t, err)
+	_, err = service.Launch(ctx, token, state)
+	assert.ErrorIs(t, err, ErrInvalidState)
+}
+
+func TestSubmitAttemptScorePostsToLineItem(t *testing.T) {
+	platform := newFakePlatform(t)
+	store := newLTIStore(platform.registration())
+	service := newTestService(t, store)
+	ctx := context.Background()
+
+	state, nonce := login(t, service)
+	result, err := service.Launch(ctx, platform.idToken(t, platform.launchClaims(nonce, "student-1")), state)
+	require.NoError(t, err)
+
+	attempt := db.ExamAttempt{AttemptID: 9, UserID: result.UserID, ExamID: 42, Status: db.ExamStatusEnumCompleted, Score: sql.NullString{String: "785", Valid: true}}
+	require.NoError(t, service.SubmitAttemptScore(ctx, attempt))
+	require.NoError(t, service.SubmitAttemptScore(ctx, attempt))
+
+	require.Len(t, platform.scores, 2)
+	assert.Equal(t, "student-1", platform.scores[0].UserID)
+	assert.Equal(t, 785.0, platform.scores[0].ScoreGiven)
+	assert.Equal(t, 990.0, platform.scores[0].ScoreMaximum)
+	assert.Equal(t, "FullyGraded", platform.scores[0].GradingProgress)
+	assert.Equal(t, 1, platform.tokens, "access token is reused")
+	assert.Equal(t, "sent", store.submissions[[2]int32{9, store.resources[0].ID}].Status)
+
+	// Attempts at other exams are not passed back
+	other := attempt
+	other.ExamID = 7
+	require.NoError(t, service.SubmitAttemptScore(ctx, other))
+	assert.Len(t, platform.scores, 2)
+
+	// Neither is part practice
+	practice := attempt
+	practice.PartID = sql.NullInt32{Int32: 5, Valid: true}
+	require.NoError(t, service.SubmitAttemptScore(ctx, practice))
+	assert.Len(t, platform.scores, 2)
+}
+
+func TestSyncRosterFollowsPagesAndDeactivates(t *testing.T) {
+	platform := newFakePlatform(t)
+	store := newLTIStore(platform.registration())
+	service := newTestService(t, store)
+	ctx := context.Background()
+
+	state, nonce := login(t, service)
+	launched, err := service.Launch(ctx, platform.idToken(t, platform.launchClaims(nonce, "student-1")), state)
+	require.NoError(t, err)
+
+	// student-1 has left the course; two others are listed over two pages
+	platform.roster = [][]member{
+		{{Status: "Active", UserID: "student-2", Name: "Minh Tran", Roles: []string{"Learner"}}},
+		{{Status: "Active", UserID: "teacher-1", GivenName: "Hoa", FamilyName: "Le", Roles: []string{roleInstructor}}, {Status: "Inactive", UserID: "student-3"}},
+	}
+
+	result, err := service.SyncRoster(ctx, launched.ContextID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Members)
+	assert.Equal(t, 2, result.Active)
+	assert.Equal(t, int64(1), result.Deactivated)
+	assert.Equal(t, "inactive", store.members[[2]int32{launched.ContextID, launched.UserID}].Status)
+	assert.Len(t, store.users, 4)
+
+	_, err = service.SyncRoster(ctx, 99)
+	assert.ErrorIs(t, err, ErrContextNotFound)
+}
+
+func TestScoresURLKeepsQuery(t *testing.T) {
+	u, err := scoresURL("https://lms.example.com/api/lineitems/5/?type=ags")
+	require.NoError(t, err)
+	assert.Equal(t, "https://lms.example.com/api/lineitems/5/scores?type=ags", u)
+}
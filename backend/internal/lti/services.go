@@ -0,0 +1,399 @@
+package lti
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/logger"
+)
+
+// Media types of the LTI Advantage services
+const (
+	mediaTypeScore       = "application/vnd.ims.lis.v1.score+json"
+	mediaTypeMemberships = "application/vnd.ims.lti-nrps.v2.membershipcontainer+json"
+)
+
+// maxTOEICScore is the score maximum reported to gradebooks
+const maxTOEICScore = 990
+
+// maxRosterPages bounds the pages followed in one roster sync
+const maxRosterPages = 100
+
+// maxServiceResponseBytes bounds the responses read from platform services
+const maxServiceResponseBytes = 10 << 20
+
+var nextLinkPattern = regexp.MustCompile(`<([^>]+)>\s*;\s*rel="?next"?`)
+
+// serviceToken is a cached platform access token
+type serviceToken struct {
+	value     string
+	expiresAt time.Time
+}
+
+// accessToken returns a platform access token for a service scope, requested
+// with a client assertion signed by the tool key
+func (s *Service) accessToken(ctx context.Context, platform db.LtiPlatform, scope string) (string, error) {
+	cacheKey := strconv.Itoa(int(platform.ID)) + " " + scope
+
+	s.mu.Lock()
+	cached, ok := s.tokens[cacheKey]
+	s.mu.Unlock()
+	if ok && s.now().Before(cached.expiresAt) {
+		return cached.value, nil
+	}
+
+	jti, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	now := s.now()
+	assertion := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss": platform.ClientID,
+		"sub": platform.ClientID,
+		"aud": platform.AuthTokenUrl,
+		"iat": now.Unix(),
+		"exp": now.Add(5 * time.Minute).Unix(),
+		"jti": jti,
+	})
+	assertion.Header["kid"] = s.key.KeyID()
+	signed, err := assertion.SignedString(s.key.private)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign client assertion: %w", err)
+	}
+
+	form := url.Values{
+		"grant_type":            {"client_credentials"},
+		"client_assertion_type": {"urn:ietf:params:oauth:client-assertion-type:jwt-bearer"},
+		"client_assertion":      {signed},
+		"scope":                 {scope},
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", platform.AuthTokenUrl, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to request access token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("token endpoint returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxServiceResponseBytes)).Decode(&token); err != nil {
+		return "", fmt.Errorf("failed to decode access token: %w", err)
+	}
+	if token.AccessToken == "" {
+		return "", errors.New("token endpoint returned no access token")
+	}
+
+	// Renew a minute early so a token does not expire in flight
+	lifetime := time.Duration(token.ExpiresIn)*time.Second - time.Minute
+	if lifetime > 0 {
+		s.mu.Lock()
+		s.tokens[cacheKey] = serviceToken{value: token.AccessToken, expiresAt: now.Add(lifetime)}
+		s.mu.Unlock()
+	}
+
+	return token.AccessToken, nil
+}
+
+// score is an Assignment and Grade Services score
+type score struct {
+	UserID           string  `json:"userId"`
+	ScoreGiven       float64 `json:"scoreGiven"`
+	ScoreMaximum     float64 `json:"scoreMaximum"`
+	ActivityProgress string  `json:"activityProgress"`
+	GradingProgress  string  `json:"gradingProgress"`
+	Timestamp        string  `json:"timestamp"`
+}
+
+// SubmitAttemptScore passes the score of a completed exam attempt back to the
+// gradebook line items of every course resource link bound to the exam that
+// the user is an active member of. Each outcome is recorded. Part practice
+// is not graded.
+func (s *Service) SubmitAttemptScore(ctx context.Context, attempt db.ExamAttempt) error {
+	if attempt.Status != db.ExamStatusEnumCompleted || !attempt.Score.Valid || attempt.PartID.Valid {
+		return nil
+	}
+	given, err := strconv.ParseFloat(attempt.Score.String, 64)
+	if err != nil {
+		return fmt.Errorf("invalid attempt score %q: %w", attempt.Score.String, err)
+	}
+
+	targets, err := s.store.ListLTIGradeTargets(ctx, db.ListLTIGradeTargetsParams{UserID: attempt.UserID, ExamID: attempt.ExamID})
+	if err != nil {
+		return fmt.Errorf("failed to list grade targets: %w", err)
+	}
+
+	var errs []error
+	for _, target := range targets {
+		err := s.postScore(ctx, target, score{
+			UserID:           target.Subject,
+			ScoreGiven:       given,
+			ScoreMaximum:     maxTOEICScore,
+			ActivityProgress: "Completed",
+			GradingProgress:  "FullyGraded",
+			Timestamp:        s.now().UTC().Format("2006-01-02T15:04:05.000Z07:00"),
+		})
+
+		record := db.UpsertLTIScoreSubmissionParams{
+			AttemptID:      attempt.AttemptID,
+			ResourceLinkID: target.ResourceLinkID,
+			Status:         "sent",
+		}
+		if err != nil {
+			record.Status = "failed"
+			record.Error = sql.NullString{String: err.Error(), Valid: true}
+			errs = append(errs, err)
+		}
+		if err := s.store.UpsertLTIScoreSubmission(ctx, record); err != nil {
+			logger.Warn("Failed to record LTI score submission of attempt %d: %v", attempt.AttemptID, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s *Service) postScore(ctx context.Context, target db.ListLTIGradeTargetsRow, sc score) error {
+	platform, err := s.store.GetLTIPlatform(ctx, target.PlatformID)
+	if err != nil {
+		return fmt.Errorf("failed to get platform: %w", err)
+	}
+	token, err := s.accessToken(ctx, platform, ScopeScore)
+	if err != nil {
+		return err
+	}
+
+	scoresURL, err := scoresURL(target.LineitemUrl.String)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(sc)
+	if err != nil {
+		return fmt.Errorf("failed to marshal score: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", scoresURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", mediaTypeScore)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post score: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("score endpoint returned status %d: %s", resp.StatusCode, string(detail))
+	}
+	return nil
+}
+
+// scoresURL appends /scores to the path of a line item URL, keeping its query
+func scoresURL(lineItem string) (string, error) {
+	u, err := url.Parse(lineItem)
+	if err != nil {
+		return "", fmt.Errorf("invalid line item URL: %w", err)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/scores"
+	return u.String(), nil
+}
+
+// member is a Names and Role Provisioning Services roster entry
+type member struct {
+	Status     string   `json:"status"`
+	UserID     string   `json:"user_id"`
+	Name       string   `json:"name"`
+	GivenName  string   `json:"given_name"`
+	FamilyName string   `json:"family_name"`
+	Email      string   `json:"email"`
+	Roles      []string `json:"roles"`
+}
+
+// RosterSyncResult summarizes a roster sync
+type RosterSyncResult struct {
+	ContextID   int32 `json:"context_id"`
+	Members     int   `json:"members"`
+	Active      int   `json:"active"`
+	Deactivated int64 `json:"deactivated"`
+}
+
+// SyncRoster reads the roster of a course from the platform, provisioning
+// accounts for new members and deactivating members no longer listed
+func (s *Service) SyncRoster(ctx context.Context, contextID int32) (*RosterSyncResult, error) {
+	course, err := s.store.GetLTIContext(ctx, contextID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrContextNotFound
+		}
+		return nil, fmt.Errorf("failed to get course: %w", err)
+	}
+	if !course.MembershipsUrl.Valid {
+		return nil, ErrNoRosterService
+	}
+
+	platform, err := s.store.GetLTIPlatform(ctx, course.PlatformID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get platform: %w", err)
+	}
+	token, err := s.accessToken(ctx, platform, ScopeMemberships)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RosterSyncResult{ContextID: contextID}
+	active := []int32{}
+	next := course.MembershipsUrl.String
+	for page := 0; next != "" && page < maxRosterPages; page++ {
+		var members []member
+		members, next, err = s.fetchMembers(ctx, next, token)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, m := range members {
+			if m.UserID == "" {
+				continue
+			}
+			name := strings.TrimSpace(m.Name)
+			if name == "" {
+				name = strings.TrimSpace(m.GivenName + " " + m.FamilyName)
+			}
+			userID, err := s.provisionUser(ctx, platform.ID, m.UserID, name, m.Email)
+			if err != nil {
+				return nil, err
+			}
+
+			status := "active"
+			if m.Status != "" && !strings.EqualFold(m.Status, "Active") {
+				status = "inactive"
+			} else {
+				active = append(active, userID)
+			}
+			if err := s.store.UpsertLTIContextMember(ctx, db.UpsertLTIContextMemberParams{
+				ContextID: contextID,
+				UserID:    userID,
+				Roles:     strings.Join(m.Roles, ","),
+				Status:    status,
+			}); err != nil {
+				return nil, fmt.Errorf("failed to save course membership: %w", err)
+			}
+			result.Members++
+		}
+	}
+	if next != "" {
+		return nil, fmt.Errorf("roster has more than %d pages", maxRosterPages)
+	}
+	result.Active = len(active)
+
+	result.Deactivated, err = s.store.DeactivateLTIContextMembers(ctx, db.DeactivateLTIContextMembersParams{
+		ContextID:     contextID,
+		ActiveUserIds: active,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to deactivate former members: %w", err)
+	}
+	if err := s.store.MarkLTIContextRosterSynced(ctx, contextID); err != nil {
+		return nil, fmt.Errorf("failed to mark roster synced: %w", err)
+	}
+
+	logger.Info("Synced LTI roster of course %d: %d members, %d deactivated", contextID, result.Members, result.Deactivated)
+	return result, nil
+}
+
+// fetchMembers reads one roster page and returns the URL of the next page
+func (s *Service) fetchMembers(ctx context.Context, pageURL, token string) ([]member, string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", mediaTypeMemberships)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch roster: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, "", fmt.Errorf("roster endpoint returned status %d: %s", resp.StatusCode, string(detail))
+	}
+
+	var container struct {
+		Members []member `json:"members"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxServiceResponseBytes)).Decode(&container); err != nil {
+		return nil, "", fmt.Errorf("failed to decode roster: %w", err)
+	}
+
+	next := ""
+	for _, link := range resp.Header.Values("Link") {
+		if match := nextLinkPattern.FindStringSubmatch(link); match != nil {
+			next = match[1]
+			break
+		}
+	}
+	return container.Members, next, nil
+}
+
+// Courses lists the courses the app was launched from
+func (s *Service) Courses(ctx context.Context, limit, offset int32) ([]db.LtiContext, error) {
+	courses, err := s.store.ListLTIContexts(ctx, db.ListLTIContextsParams{Limit: limit, Offset: offset})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list courses: %w", err)
+	}
+	return courses, nil
+}
+
+// Roster lists the members of a course
+func (s *Service) Roster(ctx context.Context, contextID int32) ([]db.ListLTIContextMembersRow, error) {
+	if _, err := s.store.GetLTIContext(ctx, contextID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrContextNotFound
+		}
+		return nil, fmt.Errorf("failed to get course: %w", err)
+	}
+	members, err := s.store.ListLTIContextMembers(ctx, contextID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list course members: %w", err)
+	}
+	return members, nil
+}
+
+// ScoreSubmissions lists recent grade passbacks with a status
+func (s *Service) ScoreSubmissions(ctx context.Context, status string, limit int32) ([]db.LtiScoreSubmission, error) {
+	submissions, err := s.store.ListLTIScoreSubmissions(ctx, db.ListLTIScoreSubmissionsParams{Status: status, Limit: limit})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list score submissions: %w", err)
+	}
+	return submissions, nil
+}
@@ -1,17 +1,15 @@
 package middleware
 
 import (
-	"strconv"
+	"fmt"
 	"sync"
 	"time"
 
-	"fmt"
-
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
 	"github.com/toeic-app/internal/config"
 	"github.com/toeic-app/internal/logger"
 	"github.com/toeic-app/internal/token"
-	"golang.org/x/time/rate"
 )
 
 // Constants for authenticated and unauthenticated users
@@ -35,22 +33,30 @@ type ThrottleConfig struct {
 	MaxQuota int
 }
 
-// userLimiter represents rate limiters for a specific user
-type userLimiter struct {
-	rateLimiter    *rate.Limiter // short-term rate limiter
-	quotaLimiter   *rate.Limiter // long-term quota limiter
-	lastSeen       time.Time
-	requestCount   int
-	quotaResetTime time.Time
+// windows returns the sliding windows enforcing the config: the short-term
+// window allows Burst requests in Burst/Rate seconds, which averages to Rate
+// requests per second, and the quota window allows MaxQuota requests in
+// QuotaPeriod.
+func (tc ThrottleConfig) windows() []RateWindow {
+	shortTerm := time.Second
+	if tc.Rate > 0 {
+		shortTerm = time.Duration(float64(tc.Burst) / tc.Rate * float64(time.Second))
+	}
+	return []RateWindow{
+		{Limit: tc.Burst, Window: shortTerm},
+		{Limit: tc.MaxQuota, Window: tc.QuotaPeriod},
+	}
 }
 
 // AdvancedRateLimit implements sophisticated rate limiting with different
-// strategies for authenticated and unauthenticated users
+// strategies for authenticated and unauthenticated users. Request logs are
+// kept in Redis when RATE_LIMIT_BACKEND is redis, so limits hold across
+// instances, and in memory otherwise or while Redis is down.
 type AdvancedRateLimit struct {
-	ipLimiters      map[string]*userLimiter // IP-based limiters for anonymous users
-	authLimiters    map[int64]*userLimiter  // User ID based limiters for authenticated
-	anonConfig      ThrottleConfig          // Config for anonymous users
-	authConfig      ThrottleConfig          // Config for authenticated users
+	store           RateLimitStore
+	memory          *MemoryRateLimitStore // Local logs, also the fallback of Redis
+	anonConfig      ThrottleConfig        // Config for anonymous users
+	authConfig      ThrottleConfig        // Config for authenticated users
 	mu              sync.RWMutex
 	cleanupInterval time.Duration
 	expirationTime  time.Duration
@@ -81,9 +87,24 @@ func NewAdvancedRateLimit(cfg config.Config, tokenMaker token.Maker) *AdvancedRa
 	cleanupInterval := 5 * time.Minute
 	expirationTime := 1 * time.Hour
 
+	memory := NewMemoryRateLimitStore()
+	var store RateLimitStore = memory
+	if cfg.RateLimitBackend == "redis" {
+		client := redis.NewClient(&redis.Options{
+			Addr:         cfg.RedisAddr,
+			Password:     cfg.RedisPassword,
+			DB:           cfg.RedisDB,
+			DialTimeout:  time.Second,
+			ReadTimeout:  redisRateLimitTimeout,
+			WriteTimeout: redisRateLimitTimeout,
+		})
+		store = NewRedisRateLimitStore(client, cfg.RateLimitKeyPrefix, memory)
+		logger.Info("Rate limit counters stored in Redis at %s", cfg.RedisAddr)
+	}
+
 	arl := &AdvancedRateLimit{
-		ipLimiters:      make(map[string]*userLimiter),
-		authLimiters:    make(map[int64]*userLimiter),
+		store:           store,
+		memory:          memory,
 		anonConfig:      anonConfig,
 		authConfig:      authConfig,
 		cleanupInterval: cleanupInterval,
@@ -99,36 +120,25 @@ func NewAdvancedRateLimit(cfg config.Config, tokenMaker token.Maker) *AdvancedRa
 	return arl
 }
 
-// Stop terminates the cleanup goroutine
+// Stop terminates the cleanup goroutine and closes the Redis connection
 func (arl *AdvancedRateLimit) Stop() {
 	arl.cleanupTicker.Stop()
 	arl.stopCleanup <- struct{}{}
+	if redisStore, ok := arl.store.(*RedisRateLimitStore); ok {
+		if err := redisStore.Close(); err != nil {
+			logger.Warn("Failed to close rate limit Redis client: %v", err)
+		}
+	}
 }
 
-// cleanup periodically removes old entries from the limiters maps
+// cleanup periodically removes the in-memory logs of inactive clients
 func (arl *AdvancedRateLimit) cleanup() {
 	for {
 		select {
 		case <-arl.cleanupTicker.C:
-			now := time.Now()
-
-			// Clean up IP-based limiters
-			arl.mu.Lock()
-			for ip, limiter := range arl.ipLimiters {
-				if now.Sub(limiter.lastSeen) > arl.expirationTime {
-					delete(arl.ipLimiters, ip)
-					logger.Debug("Removed IP limiter for %s due to inactivity", ip)
-				}
-			}
-
-			// Clean up auth limiters
-			for userID, limiter := range arl.authLimiters {
-				if now.Sub(limiter.lastSeen) > arl.expirationTime {
-					delete(arl.authLimiters, userID)
-					logger.Debug("Removed auth limiter for user %d due to inactivity", userID)
-				}
+			if removed := arl.memory.Prune(arl.expirationTime); removed > 0 {
+				logger.Debug("Removed %d rate limit logs due to inactivity", removed)
 			}
-			arl.mu.Unlock()
 
 		case <-arl.stopCleanup:
 			logger.Debug("Advanced rate limiter cleanup stopped")
@@ -137,151 +147,102 @@ func (arl *AdvancedRateLimit) cleanup() {
 	}
 }
 
-// getOrCreateLimiter gets or creates limiters for a specific key and type
-func (arl *AdvancedRateLimit) getOrCreateLimiter(_ string, userType string) *userLimiter {
-	var config ThrottleConfig
-	if userType == userTypeAuth {
-		config = arl.authConfig
-	} else {
-		config = arl.anonConfig
-	}
-
-	// Create new limiter
-	rateLim := rate.NewLimiter(rate.Limit(config.Rate), config.Burst)
+// UpdateLimits applies new rate/burst values from a reloaded configuration.
+// Request logs are kept; the new limits apply to them from the next request.
+func (arl *AdvancedRateLimit) UpdateLimits(cfg config.Config) {
+	arl.mu.Lock()
+	defer arl.mu.Unlock()
 
-	// Initialize with a fresh quota
-	quotaRate := rate.Limit(float64(config.MaxQuota) / config.QuotaPeriod.Seconds())
-	quotaLim := rate.NewLimiter(quotaRate, config.MaxQuota)
+	arl.anonConfig.Rate = float64(cfg.RateLimitRequests)
+	arl.anonConfig.Burst = cfg.RateLimitBurst
+	arl.authConfig.Rate = float64(cfg.RateLimitRequests) * 2
+	arl.authConfig.Burst = cfg.RateLimitBurst * 2
 
-	now := time.Now()
-	return &userLimiter{
-		rateLimiter:    rateLim,
-		quotaLimiter:   quotaLim,
-		lastSeen:       now,
-		requestCount:   0,
-		quotaResetTime: now.Add(config.QuotaPeriod),
-	}
+	logger.Info("Rate limits updated: %d req/s, burst %d", cfg.RateLimitRequests, cfg.RateLimitBurst)
 }
 
 // Middleware returns a Gin middleware that implements advanced rate limiting
 func (arl *AdvancedRateLimit) Middleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Try to get user ID from the authorization payload
-		var userID int64 = 0
-		var isAuthenticated bool
-
-		// Extract auth payload if present
+		// Authenticated users are limited by user ID, others by IP
+		key := "ip:" + c.ClientIP()
+		isAuthenticated := false
 		if payload, exists := c.Get("authorization_payload"); exists {
-			if auth, ok := payload.(*token.Payload); ok {
-				// Convert from int32 to int64 to match our type
-				userID = int64(auth.ID)
+			if auth, ok := payload.(*token.Payload); ok && auth.ID > 0 {
+				key = fmt.Sprintf("user:%d", auth.ID)
 				isAuthenticated = true
 			}
 		}
 
-		var limiter *userLimiter
-		var exists bool
-		now := time.Now()
-
-		if isAuthenticated && userID > 0 {
-			// Handle authenticated user
-			arl.mu.RLock()
-			limiter, exists = arl.authLimiters[userID]
-			arl.mu.RUnlock()
-
-			if !exists {
-				limiter = arl.getOrCreateLimiter(fmt.Sprint(userID), userTypeAuth)
-				arl.mu.Lock()
-				arl.authLimiters[userID] = limiter
-				arl.mu.Unlock()
-			} else {
-				// Reset quota if period expired
-				if now.After(limiter.quotaResetTime) {
-					quotaRate := rate.Limit(float64(arl.authConfig.MaxQuota) / arl.authConfig.QuotaPeriod.Seconds())
-					limiter.quotaLimiter = rate.NewLimiter(quotaRate, arl.authConfig.MaxQuota)
-					limiter.quotaResetTime = now.Add(arl.authConfig.QuotaPeriod)
-					limiter.requestCount = 0
-				}
-			}
-		} else {
-			// Handle unauthenticated user by IP
-			clientIP := c.ClientIP()
-
-			arl.mu.RLock()
-			limiter, exists := arl.ipLimiters[clientIP]
-			arl.mu.RUnlock()
-
-			if !exists {
-				limiter = arl.getOrCreateLimiter(clientIP, userTypeAnonymous)
-				arl.mu.Lock()
-				arl.ipLimiters[clientIP] = limiter
-				arl.mu.Unlock()
-			}
-			if limiter != nil {
-				// Reset quota if period expired
-				if now.After(limiter.quotaResetTime) {
-					quotaRate := rate.Limit(float64(arl.anonConfig.MaxQuota) / arl.anonConfig.QuotaPeriod.Seconds())
-					limiter.quotaLimiter = rate.NewLimiter(quotaRate, arl.anonConfig.MaxQuota)
-					limiter.quotaResetTime = now.Add(arl.anonConfig.QuotaPeriod)
-					limiter.requestCount = 0
-				}
-			}
-		}
-
-		// Verify limiter is not nil before proceeding
-		if limiter == nil {
-			// This shouldn't happen with our current code logic, but handle it gracefully
-			logger.Error("Rate limiter unexpectedly nil - creating a default limiter")
-			// Create a default limiter for this case to avoid nil dereference
-			limiter = arl.getOrCreateLimiter("default", userTypeAnonymous)
+		arl.mu.RLock()
+		config := arl.anonConfig
+		if isAuthenticated {
+			config = arl.authConfig
 		}
+		arl.mu.RUnlock()
 
-		// Now update last seen with nil check in place
-		arl.mu.Lock()
-		limiter.lastSeen = now
-		limiter.requestCount++
-		arl.mu.Unlock()
-
-		// Get the quota limit based on user type
-		var quota int
-		if isAuthenticated {
-			quota = arl.authConfig.MaxQuota
-		} else {
-			quota = arl.anonConfig.MaxQuota
+		result, err := arl.store.Allow(c.Request.Context(), key, config.windows())
+		if err != nil {
+			// Never fail requests because limits could not be checked
+			logger.Error("Failed to check rate limit for %s: %v", key, err)
+			c.Next()
+			return
 		}
 
-		// Check short-term rate limit
-		if !limiter.rateLimiter.Allow() {
-			// Short-term rate limit exceeded - use standardized response
+		window := result.Tightest()
+		setRateLimitPolicy(c, config.windows())
+		if !result.Allowed {
 			SendRateLimitExceededResponse(
 				c,
-				quota,
-				quota-limiter.requestCount,
-				limiter.quotaResetTime,
-				true, // short term limit
+				window.Limit,
+				max(window.Remaining, 0),
+				time.Now().Add(window.Reset),
+				result.Exceeded == 0, // short term limit
 			)
 			return
 		}
 
-		// Check long-term quota
-		if !limiter.quotaLimiter.Allow() {
-			// Long-term quota exceeded - use standardized response
+		setRateLimitHeaders(c, window.Limit, window.Remaining, window.Reset)
+
+		// Continue processing the request
+		c.Next()
+	}
+}
+
+// Bucket returns a middleware limiting a group of endpoints with windows of
+// its own, such as the AI endpoints whose requests cost far more than
+// others. Requests of the group count against the bucket in addition to the
+// general limits. Users are limited by user ID, others by IP.
+func (arl *AdvancedRateLimit) Bucket(name string, windows []RateWindow) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := name + ":ip:" + c.ClientIP()
+		if payload, exists := c.Get("authorization_payload"); exists {
+			if auth, ok := payload.(*token.Payload); ok && auth.ID > 0 {
+				key = fmt.Sprintf("%s:user:%d", name, auth.ID)
+			}
+		}
+
+		result, err := arl.store.Allow(c.Request.Context(), key, windows)
+		if err != nil {
+			logger.Error("Failed to check %s rate limit for %s: %v", name, key, err)
+			c.Next()
+			return
+		}
+
+		window := result.Tightest()
+		setRateLimitPolicy(c, windows)
+		if !result.Allowed {
 			SendRateLimitExceededResponse(
 				c,
-				quota,
-				quota-limiter.requestCount,
-				limiter.quotaResetTime,
-				false, // long term limit
+				window.Limit,
+				max(window.Remaining, 0),
+				time.Now().Add(window.Reset),
+				result.Exceeded == 0,
 			)
 			return
 		}
 
-		// Add standardized rate limit headers
-		c.Header("X-RateLimit-Limit", strconv.Itoa(quota))
-		c.Header("X-RateLimit-Remaining", strconv.Itoa(quota-limiter.requestCount))
-		c.Header("X-RateLimit-Reset", strconv.FormatInt(limiter.quotaResetTime.Unix(), 10))
-
-		// Continue processing the request
+		setRateLimitHeaders(c, window.Limit, window.Remaining, window.Reset)
 		c.Next()
 	}
 }
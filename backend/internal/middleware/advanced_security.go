@@ -56,6 +56,8 @@ type AdvancedSecurityConfig struct {
 type AdvancedSecurityMiddleware struct {
 	config     AdvancedSecurityConfig
 	tokenMaker token.Maker
+	signer     *RequestSigner // Optional HMAC request signing with replay protection
+	cors       *CORSPolicy    // Optional origins shared with CORS, including wildcard patterns
 }
 
 // NewAdvancedSecurityMiddleware creates a new advanced security middleware
@@ -87,9 +89,14 @@ func NewAdvancedSecurityMiddleware(cfg config.Config, tokenMaker token.Maker) *A
 		"/api/auth/login",
 		"/api/auth/register",
 		"/api/auth/refresh-token",
+		"/api/auth/oauth/", // Verified with Google and Apple signatures
 		"/swagger",
-		"/api/v1/grammars",    // Public grammar endpoints
-		"/api/v1/performance", // Public performance endpoints
+		"/api/v1/grammars",         // Public grammar endpoints
+		"/api/v1/performance",      // Public performance endpoints
+		"/api/v1/billing/webhooks", // Verified with provider signatures
+		"/api/v1/calendar/",        // Verified with signed feed tokens
+		"/api/v1/lti/",             // Verified with LMS platform signatures
+		"/api/v1/public/",          // Verified with third-party API keys
 	}
 
 	securityConfig := AdvancedSecurityConfig{
@@ -110,6 +117,17 @@ func NewAdvancedSecurityMiddleware(cfg config.Config, tokenMaker token.Maker) *A
 	}
 }
 
+// SetRequestSigner enables verification of signed requests
+func (asm *AdvancedSecurityMiddleware) SetRequestSigner(signer *RequestSigner) {
+	asm.signer = signer
+}
+
+// SetCORSPolicy validates origins and referers against a CORS policy, so
+// they follow its wildcard patterns and reloads
+func (asm *AdvancedSecurityMiddleware) SetCORSPolicy(policy *CORSPolicy) {
+	asm.cors = policy
+}
+
 // Middleware returns the advanced security middleware function
 func (asm *AdvancedSecurityMiddleware) Middleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -205,6 +223,13 @@ func (asm *AdvancedSecurityMiddleware) validateAdvancedSecurity(c *gin.Context)
 		return fmt.Errorf("WASM/worker context validation failed: %w", err)
 	}
 
+	// 8. Validate request signature and reject replayed nonces
+	if asm.signer != nil {
+		if err := asm.signer.Verify(c); err != nil {
+			return fmt.Errorf("request signature validation failed: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -410,6 +435,9 @@ func (asm *AdvancedSecurityMiddleware) shouldBypassSecurity(path string) bool {
 
 // isOriginAllowed checks if the origin is in the allowed list
 func (asm *AdvancedSecurityMiddleware) isOriginAllowed(origin string) bool {
+	if asm.cors != nil {
+		return asm.cors.Allowed(origin)
+	}
 	for _, allowedOrigin := range asm.config.AllowedOrigins {
 		if origin == allowedOrigin {
 			return true
@@ -420,6 +448,9 @@ func (asm *AdvancedSecurityMiddleware) isOriginAllowed(origin string) bool {
 
 // isRefererAllowed checks if the referer is from an allowed origin
 func (asm *AdvancedSecurityMiddleware) isRefererAllowed(referer string) bool {
+	if asm.cors != nil {
+		return asm.cors.Allowed(refererOrigin(referer))
+	}
 	for _, allowedOrigin := range asm.config.AllowedOrigins {
 		if strings.HasPrefix(referer, allowedOrigin) {
 			return true
@@ -428,6 +459,18 @@ func (asm *AdvancedSecurityMiddleware) isRefererAllowed(referer string) bool {
 	return false
 }
 
+// refererOrigin returns the scheme and host of a referer URL
+func refererOrigin(referer string) string {
+	scheme, rest, ok := strings.Cut(referer, "://")
+	if !ok {
+		return ""
+	}
+	if i := strings.IndexAny(rest, "/?#"); i >= 0 {
+		rest = rest[:i]
+	}
+	return scheme + "://" + rest
+}
+
 // verifyHMACSignature verifies an HMAC signature using user-specific secret key
 func (asm *AdvancedSecurityMiddleware) verifyHMACSignature(message, signature string) error {
 	// This is a fallback method that uses server secret key
@@ -1,66 +1,232 @@
 package middleware
 
 import (
-	"strings"
-
+	"errors"
+	"fmt"
+	"net/http"
 	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/toeic-app/internal/config"
+	"github.com/toeic-app/internal/logger"
 )
 
-// CORS middleware for handling Cross-Origin Resource Sharing
-func CORS(cfg config.Config) gin.HandlerFunc {
-	// Parse allowed origins from config
-	var allowedOrigins []string
-	if cfg.CORSAllowedOrigins != "" {
-		allowedOrigins = strings.Split(cfg.CORSAllowedOrigins, ",")
-		// Trim whitespace from each origin
-		for i, origin := range allowedOrigins {
-			allowedOrigins[i] = strings.TrimSpace(origin)
+// FlutterAppOrigin is the origin of the mobile app, which is always allowed
+const FlutterAppOrigin = "flutter-app://toeic-app"
+
+// Headers and methods allowed and exposed by CORS responses
+var (
+	CORSAllowedMethods = []string{"POST", "OPTIONS", "GET", "PUT", "DELETE", "PATCH"}
+	CORSAllowedHeaders = []string{"Content-Type", "Content-Length", "Accept-Encoding", "X-CSRF-Token", "Authorization", "accept", "origin", "Cache-Control", "X-Requested-With", "X-Security-Token", "X-Client-Signature", "X-Request-Timestamp", "X-Browser-Fingerprint", "X-WASM-Mode", "X-Worker-Context", "X-Origin-Validation", "X-Security-Level", "X-Encrypted-Payload", "X-Request-Nonce", "X-API-Key", "X-Access-Purpose", "If-None-Match"}
+	CORSExposedHeaders = []string{"Content-Length", "Access-Control-Allow-Origin", "Access-Control-Allow-Headers", "Content-Type", "X-Response-Nonce", "ETag", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset", "Retry-After", "X-Upload-Quota-Bytes-Limit", "X-Upload-Quota-Bytes-Remaining", "X-Upload-Quota-Files-Limit", "X-Upload-Quota-Files-Remaining", "X-Upload-Quota-Reset", "Content-Language", "X-Language-Source"}
+)
+
+// defaultCORSOrigins are allowed when no origin is configured
+var defaultCORSOrigins = []string{
+	"http://localhost:3000",
+	"http://localhost:8080",
+	"http://localhost:8000",
+	"http://192.168.31.37:8000",
+	"http://127.0.0.1:8000",
+	"https://localhost:3000",
+	"https://localhost:8080",
+	"https://localhost:8000",
+	"https://192.168.31.37:8000",
+	"https://127.0.0.1:8000",
+}
+
+var ErrInvalidOriginPattern = errors.New("invalid CORS origin")
+
+// OriginPattern is an allowed origin. A host starting with "*." matches
+// every subdomain of the domain, at any depth, but not the domain itself.
+// The scheme and port must match exactly.
+type OriginPattern struct {
+	Scheme   string
+	Host     string // Without the "*." of wildcard patterns
+	Port     string
+	Wildcard bool
+}
+
+// ParseOriginPattern parses an origin such as https://app.toeic.app or
+// https://*.toeic.app:8443
+func ParseOriginPattern(origin string) (OriginPattern, error) {
+	scheme, rest, ok := strings.Cut(strings.ToLower(strings.TrimSpace(origin)), "://")
+	if !ok || scheme == "" || rest == "" {
+		return OriginPattern{}, fmt.Errorf("%w %q: expected scheme://host[:port]", ErrInvalidOriginPattern, origin)
+	}
+	if strings.ContainsAny(rest, "/?#@") {
+		return OriginPattern{}, fmt.Errorf("%w %q: an origin has no path, query or credentials", ErrInvalidOriginPattern, origin)
+	}
+
+	pattern := OriginPattern{Scheme: scheme, Host: rest}
+	if i := strings.LastIndex(rest, ":"); i >= 0 && !strings.HasSuffix(rest, "]") {
+		pattern.Host, pattern.Port = rest[:i], rest[i+1:]
+		if port, err := strconv.Atoi(pattern.Port); err != nil || port <= 0 || port > 65535 {
+			return OriginPattern{}, fmt.Errorf("%w %q: invalid port", ErrInvalidOriginPattern, origin)
 		}
-	} else {
-		// Default allowed origins if not configured
-		allowedOrigins = []string{
-			"http://localhost:3000",
-			"http://localhost:8080",
-			"http://localhost:8000",
-			"http://192.168.31.37:8000",
-			"http://127.0.0.1:8000",
-			"https://localhost:3000",
-			"https://localhost:8080",
-			"https://localhost:8000",
-			"https://192.168.31.37:8000",
-			"https://127.0.0.1:8000",
-			"flutter-app://toeic-app", // Flutter mobile app origin
+	}
+	if strings.HasPrefix(pattern.Host, "*.") {
+		pattern.Wildcard = true
+		pattern.Host = pattern.Host[2:]
+	}
+	// A bare * would let every site send credentialed requests
+	if pattern.Host == "" || strings.Contains(pattern.Host, "*") {
+		return OriginPattern{}, fmt.Errorf("%w %q: wildcards are only allowed as the first label, e.g. https://*.example.com", ErrInvalidOriginPattern, origin)
+	}
+	return pattern, nil
+}
+
+// Match reports whether an origin sent by a browser matches the pattern
+func (p OriginPattern) Match(origin string) bool {
+	scheme, rest, ok := strings.Cut(strings.ToLower(origin), "://")
+	if !ok || scheme != p.Scheme {
+		return false
+	}
+	host, port := rest, ""
+	if i := strings.LastIndex(rest, ":"); i >= 0 && !strings.HasSuffix(rest, "]") {
+		host, port = rest[:i], rest[i+1:]
+	}
+	if port != p.Port {
+		return false
+	}
+	if !p.Wildcard {
+		return host == p.Host
+	}
+	subdomain, found := strings.CutSuffix(host, "."+p.Host)
+	return found && subdomain != "" && !strings.ContainsAny(subdomain, "/?#@")
+}
+
+// String returns the pattern as configured
+func (p OriginPattern) String() string {
+	host := p.Host
+	if p.Wildcard {
+		host = "*." + host
+	}
+	if p.Port != "" {
+		host += ":" + p.Port
+	}
+	return p.Scheme + "://" + host
+}
+
+// CORSRules are the rules applied by a CORSPolicy
+type CORSRules struct {
+	Environment string
+	Origins     []string // Exact origins
+	Patterns    []string // Wildcard subdomain patterns
+	MaxAge      time.Duration
+}
+
+// CORSPolicy answers CORS requests from the configured origins. The origins
+// can be replaced at runtime, e.g. after a configuration reload.
+type CORSPolicy struct {
+	mu       sync.RWMutex
+	rules    CORSRules
+	exact    map[string]bool
+	patterns []OriginPattern
+}
+
+// NewCORSPolicy creates a CORS policy using the configured origins of the
+// app environment
+func NewCORSPolicy(cfg config.Config) *CORSPolicy {
+	policy := &CORSPolicy{exact: map[string]bool{FlutterAppOrigin: true}}
+	policy.Update(cfg)
+	return policy
+}
+
+// Update applies the origins and preflight max age of cfg. Invalid origins
+// are reported and the current policy is kept.
+func (p *CORSPolicy) Update(cfg config.Config) {
+	origins := cfg.CORSOrigins()
+	if len(origins) == 0 {
+		origins = defaultCORSOrigins
+	}
+
+	rules := CORSRules{Environment: cfg.AppEnv, MaxAge: cfg.CORSMaxAge}
+	exact := make(map[string]bool)
+	var patterns []OriginPattern
+	for _, origin := range append(slices.Clone(origins), FlutterAppOrigin) {
+		pattern, err := ParseOriginPattern(origin)
+		if err != nil {
+			logger.Error("Invalid CORS origins, keeping current policy: %v", err)
+			return
+		}
+		if pattern.Wildcard {
+			patterns = append(patterns, pattern)
+			rules.Patterns = append(rules.Patterns, pattern.String())
+		} else if !exact[pattern.String()] {
+			exact[pattern.String()] = true
+			rules.Origins = append(rules.Origins, pattern.String())
 		}
 	}
 
-	// Always ensure Flutter app origin is included
-	if !slices.Contains(allowedOrigins, "flutter-app://toeic-app") {
-		allowedOrigins = append(allowedOrigins, "flutter-app://toeic-app")
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.rules = rules
+	p.exact = exact
+	p.patterns = patterns
+	logger.Info("CORS policy for %s: %d origins, %d wildcard patterns, preflight max age %s",
+		rules.Environment, len(rules.Origins), len(rules.Patterns), rules.MaxAge)
+}
+
+// Rules returns the rules currently applied
+func (p *CORSPolicy) Rules() CORSRules {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.rules
+}
+
+// Allowed reports whether requests from an origin are allowed
+func (p *CORSPolicy) Allowed(origin string) bool {
+	if origin == "" {
+		return false
 	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.exact[strings.ToLower(origin)] {
+		return true
+	}
+	for _, pattern := range p.patterns {
+		if pattern.Match(origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// Middleware returns the middleware handling Cross-Origin Resource Sharing
+func (p *CORSPolicy) Middleware() gin.HandlerFunc {
+	allowedHeaders := strings.Join(CORSAllowedHeaders, ", ")
+	allowedMethods := strings.Join(CORSAllowedMethods, ", ")
+	exposedHeaders := strings.Join(CORSExposedHeaders, ", ")
 
 	return func(c *gin.Context) {
 		origin := c.Request.Header.Get("Origin")
+		header := c.Writer.Header()
 
-		// Check if the origin is in the allowed list
-		isAllowed := slices.Contains(allowedOrigins, origin)
-
-		// Set the appropriate Access-Control-Allow-Origin header
-		if isAllowed {
-			c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
+		// The allowed origin depends on the request's, so caches must not
+		// share responses between origins
+		header.Add("Vary", "Origin")
+		if p.Allowed(origin) {
+			header.Set("Access-Control-Allow-Origin", origin)
 		} else if origin == "" {
 			// Allow requests without origin (like Postman, curl, etc.)
-			c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+			header.Set("Access-Control-Allow-Origin", "*")
 		}
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-Security-Token, X-Client-Signature, X-Request-Timestamp, X-Browser-Fingerprint, X-WASM-Mode, X-Worker-Context, X-Origin-Validation, X-Security-Level, X-Encrypted-Payload, X-Request-Nonce")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE, PATCH")
-		c.Writer.Header().Set("Access-Control-Expose-Headers", "Content-Length, Access-Control-Allow-Origin, Access-Control-Allow-Headers, Content-Type, X-Response-Nonce")
+		header.Set("Access-Control-Allow-Credentials", "true")
+		header.Set("Access-Control-Allow-Headers", allowedHeaders)
+		header.Set("Access-Control-Allow-Methods", allowedMethods)
+		header.Set("Access-Control-Expose-Headers", exposedHeaders)
 
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
+		if c.Request.Method == http.MethodOptions {
+			// Let browsers skip the preflight of further requests for a while
+			header.Set("Access-Control-Max-Age", strconv.Itoa(int(p.Rules().MaxAge.Seconds())))
+			header.Add("Vary", "Access-Control-Request-Method")
+			header.Add("Vary", "Access-Control-Request-Headers")
+			c.AbortWithStatus(http.StatusNoContent)
 			return
 		}
 
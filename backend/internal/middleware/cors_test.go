@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toeic-app/internal/config"
+)
+
+func TestOriginPatternMatch(t *testing.T) {
+	pattern, err := ParseOriginPattern("https://*.toeic.app")
+	require.NoError(t, err)
+	assert.True(t, pattern.Match("https://admin.toeic.app"))
+	assert.True(t, pattern.Match("https://eu.admin.toeic.app"))
+	assert.False(t, pattern.Match("https://toeic.app"))      // Not the domain itself
+	assert.False(t, pattern.Match("http://admin.toeic.app")) // Other scheme
+	assert.False(t, pattern.Match("https://admin.toeic.app:8443"))
+	assert.False(t, pattern.Match("https://eviltoeic.app"))
+	assert.False(t, pattern.Match("https://admin.toeic.app.evil.com"))
+
+	pattern, err = ParseOriginPattern("http://localhost:3000")
+	require.NoError(t, err)
+	assert.True(t, pattern.Match("http://localhost:3000"))
+	assert.False(t, pattern.Match("http://localhost:3001"))
+
+	for _, invalid := range []string{"*", "https://*", "toeic.app", "https://toeic.app/", "https://*.toeic.*", "https://a.*.toeic.app", "https://toeic.app:0"} {
+		_, err := ParseOriginPattern(invalid)
+		assert.ErrorIs(t, err, ErrInvalidOriginPattern, invalid)
+	}
+}
+
+func TestCORSPolicy(t *testing.T) {
+	policy := NewCORSPolicy(config.Config{
+		AppEnv:                       "production",
+		CORSAllowedOrigins:           "https://toeic.app",
+		CORSAllowedOriginsProduction: "https://*.toeic.app",
+		CORSMaxAge:                   10 * time.Minute,
+	})
+	assert.True(t, policy.Allowed("https://toeic.app"))
+	assert.True(t, policy.Allowed("https://admin.toeic.app"))
+	assert.True(t, policy.Allowed(FlutterAppOrigin))
+	assert.False(t, policy.Allowed("https://evil.com"))
+
+	router := gin.New()
+	router.Use(policy.Middleware())
+	router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	req := httptest.NewRequest(http.MethodOptions, "/", nil)
+	req.Header.Set("Origin", "https://admin.toeic.app")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	assert.Equal(t, "https://admin.toeic.app", recorder.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "600", recorder.Header().Get("Access-Control-Max-Age"))
+	assert.Contains(t, recorder.Header().Values("Vary"), "Origin")
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Origin", "https://evil.com")
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	assert.Empty(t, recorder.Header().Get("Access-Control-Allow-Origin"))
+
+	// A reload with an invalid origin keeps the current policy
+	policy.Update(config.Config{AppEnv: "production", CORSAllowedOrigins: "https://toeic.app,*"})
+	assert.True(t, policy.Allowed("https://admin.toeic.app"))
+
+	policy.Update(config.Config{AppEnv: "staging", CORSAllowedOrigins: "https://toeic.app", CORSAllowedOriginsStaging: "https://*.staging.toeic.app"})
+	assert.False(t, policy.Allowed("https://admin.toeic.app"))
+	assert.True(t, policy.Allowed("https://admin.staging.toeic.app"))
+	assert.Equal(t, []string{"https://*.staging.toeic.app"}, policy.Rules().Patterns)
+}
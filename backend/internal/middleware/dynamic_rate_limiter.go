@@ -51,6 +51,7 @@ func isAuthEndpoint(path string) bool {
 		"/api/auth/login",
 		"/api/auth/register",
 		"/api/auth/refresh-token",
+		"/api/auth/oauth/",
 	}
 
 	for _, authPath := range authPaths {
@@ -85,7 +85,10 @@ func handleErrors(c *gin.Context, ginErrors []*gin.Error, config ErrorHandlerCon
 		logError(appErr, c, config)
 	}
 
-	// Send response
+	// Send response, unless the handler already answered
+	if c.Writer.Written() {
+		return
+	}
 	if validationErr != nil {
 		sendValidationErrorResponse(c, validationErr)
 	} else {
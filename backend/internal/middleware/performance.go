@@ -63,7 +63,7 @@ func (pt *PerformanceTracker) TrackPerformance() gin.HandlerFunc {
 
 		// Add performance headers
 		c.Header("X-Response-Time", duration.String())
-		c.Header("X-Request-ID", c.GetString("request_id"))
+		c.Header("X-Request-ID", c.GetString(RequestIDKey))
 	}
 }
 
@@ -273,15 +273,24 @@ func acceptsGzip(acceptEncoding string) bool {
 			len(acceptEncoding) > 4 && acceptEncoding[:4] == "gzip")
 }
 
-// RequestIDMiddleware adds unique request ID for tracing
+// Context keys set by RequestIDMiddleware
+const (
+	RequestIDKey      = "request_id"
+	RequestStartedKey = "request_started_at"
+)
+
+// RequestIDMiddleware adds unique request ID for tracing and records when the
+// request started, for the meta block of API responses
 func RequestIDMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		c.Set(RequestStartedKey, time.Now())
+
 		requestID := c.GetHeader("X-Request-ID")
 		if requestID == "" {
 			requestID = generatePerfRequestID()
 		}
 
-		c.Set("request_id", requestID)
+		c.Set(RequestIDKey, requestID)
 		c.Header("X-Request-ID", requestID)
 
 		c.Next()
@@ -1,8 +1,10 @@
 package middleware
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -25,6 +27,29 @@ type RateLimitInfo struct {
 	RetryAfter     int   `json:"retry_after_seconds"`
 }
 
+// setRateLimitHeaders sets the RateLimit-* headers of the IETF draft, with
+// the reset as seconds from now, and the older X-RateLimit-* headers with the
+// reset as a Unix timestamp
+func setRateLimitHeaders(c *gin.Context, limit, remaining int, reset time.Duration) {
+	resetSeconds := int((reset + time.Second - 1) / time.Second)
+	c.Header("RateLimit-Limit", strconv.Itoa(limit))
+	c.Header("RateLimit-Remaining", strconv.Itoa(remaining))
+	c.Header("RateLimit-Reset", strconv.Itoa(resetSeconds))
+	c.Header("X-RateLimit-Limit", strconv.Itoa(limit))
+	c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
+	c.Header("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(reset).Unix(), 10))
+}
+
+// setRateLimitPolicy describes the windows a client is limited by, e.g.
+// "20;w=2, 600;w=3600"
+func setRateLimitPolicy(c *gin.Context, windows []RateWindow) {
+	policies := make([]string, 0, len(windows))
+	for _, window := range windows {
+		policies = append(policies, fmt.Sprintf("%d;w=%d", window.Limit, int((window.Window+time.Second-1)/time.Second)))
+	}
+	c.Header("RateLimit-Policy", strings.Join(policies, ", "))
+}
+
 // SendRateLimitExceededResponse sends a standardized rate limit exceeded response
 func SendRateLimitExceededResponse(c *gin.Context, quota int, remaining int, resetTime time.Time, shortTerm bool) {
 	// Get the client IP for logging
@@ -39,9 +64,7 @@ func SendRateLimitExceededResponse(c *gin.Context, quota int, remaining int, res
 	}
 
 	// Set rate limit headers
-	c.Header("X-RateLimit-Limit", strconv.Itoa(quota))
-	c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
-	c.Header("X-RateLimit-Reset", strconv.FormatInt(resetTime.Unix(), 10))
+	setRateLimitHeaders(c, quota, remaining, time.Duration(retryAfter)*time.Second)
 	c.Header("Retry-After", strconv.Itoa(retryAfter))
 
 	var errorType string
@@ -0,0 +1,266 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/toeic-app/internal/logger"
+)
+
+// RateWindow allows Limit requests in any period of length Window
+type RateWindow struct {
+	Limit  int
+	Window time.Duration
+}
+
+// RateWindowState is the state of a window after a request
+type RateWindowState struct {
+	RateWindow
+	// Remaining is the number of requests still allowed in the window
+	Remaining int
+	// Reset is the time until the oldest request in the window leaves it,
+	// freeing a slot
+	Reset time.Duration
+}
+
+// RateLimitResult is the outcome of a request checked against the windows
+// of a client
+type RateLimitResult struct {
+	Allowed bool
+	// Exceeded is the index of the first window that rejected the request,
+	// or -1 when it was allowed
+	Exceeded int
+	Windows  []RateWindowState
+}
+
+// Tightest returns the window with the fewest requests remaining
+func (r RateLimitResult) Tightest() RateWindowState {
+	if r.Exceeded >= 0 {
+		return r.Windows[r.Exceeded]
+	}
+	tightest := r.Windows[0]
+	for _, window := range r.Windows[1:] {
+		if window.Remaining < tightest.Remaining {
+			tightest = window
+		}
+	}
+	return tightest
+}
+
+// RateLimitStore keeps sliding-window request logs. A request is recorded
+// only when every window allows it.
+type RateLimitStore interface {
+	Allow(ctx context.Context, key string, windows []RateWindow) (RateLimitResult, error)
+}
+
+func longestWindow(windows []RateWindow) time.Duration {
+	var longest time.Duration
+	for _, window := range windows {
+		longest = max(longest, window.Window)
+	}
+	return longest
+}
+
+// MemoryRateLimitStore keeps request logs in this instance only
+type MemoryRateLimitStore struct {
+	mu   sync.Mutex
+	logs map[string][]time.Time
+	now  func() time.Time
+}
+
+// NewMemoryRateLimitStore creates an in-memory store
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{logs: make(map[string][]time.Time), now: time.Now}
+}
+
+func (s *MemoryRateLimitStore) Allow(_ context.Context, key string, windows []RateWindow) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	log := s.logs[key]
+	// Drop requests older than every window
+	expired := sort.Search(len(log), func(i int) bool { return log[i].After(now.Add(-longestWindow(windows))) })
+	log = log[expired:]
+
+	result := RateLimitResult{Allowed: true, Exceeded: -1, Windows: make([]RateWindowState, len(windows))}
+	for i, window := range windows {
+		first := sort.Search(len(log), func(j int) bool { return log[j].After(now.Add(-window.Window)) })
+		state := RateWindowState{RateWindow: window, Remaining: window.Limit - (len(log) - first), Reset: window.Window}
+		if first < len(log) {
+			state.Reset = log[first].Add(window.Window).Sub(now)
+		}
+		if state.Remaining <= 0 && result.Allowed {
+			result.Allowed = false
+			result.Exceeded = i
+		}
+		result.Windows[i] = state
+	}
+
+	if result.Allowed {
+		log = append(log, now)
+		for i := range result.Windows {
+			result.Windows[i].Remaining--
+		}
+	}
+	if len(log) == 0 {
+		delete(s.logs, key)
+	} else {
+		s.logs[key] = log
+	}
+	return result, nil
+}
+
+// Prune removes the logs of clients without requests in the last maxAge
+func (s *MemoryRateLimitStore) Prune(maxAge time.Duration) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := s.now().Add(-maxAge)
+	removed := 0
+	for key, log := range s.logs {
+		if len(log) == 0 || log[len(log)-1].Before(cutoff) {
+			delete(s.logs, key)
+			removed++
+		}
+	}
+	return removed
+}
+
+// slidingWindowScript checks a sorted set of request timestamps (in
+// microseconds, taken from the Redis clock so all instances agree) against
+// each window, and records the request when all of them allow it.
+//
+// ARGV: member, then limit and window (microseconds) for each window.
+// Returns the 1-based index of the exceeded window (0 when allowed), then
+// the count before this request and the reset time of each window.
+var slidingWindowScript = redis.NewScript(`
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+local windows = (#ARGV - 1) / 2
+local longest = 0
+for i = 1, windows do
+	longest = math.max(longest, tonumber(ARGV[2 * i + 1]))
+end
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - longest)
+
+local result = {0}
+for i = 1, windows do
+	local limit = tonumber(ARGV[2 * i])
+	local window = tonumber(ARGV[2 * i + 1])
+	local from = '(' .. (now - window)
+	local count = redis.call('ZCOUNT', KEYS[1], from, '+inf')
+	local reset = window
+	local oldest = redis.call('ZRANGEBYSCORE', KEYS[1], from, '+inf', 'WITHSCORES', 'LIMIT', 0, 1)
+	if #oldest > 0 then
+		reset = tonumber(oldest[2]) + window - now
+	end
+	if count >= limit and result[1] == 0 then
+		result[1] = i
+	end
+	table.insert(result, count)
+	table.insert(result, reset)
+end
+
+if result[1] == 0 then
+	redis.call('ZADD', KEYS[1], now, ARGV[1])
+	redis.call('PEXPIRE', KEYS[1], math.ceil(longest / 1000))
+end
+return result
+`)
+
+const (
+	// redisRateLimitTimeout bounds a Redis round trip so a slow Redis does
+	// not hold up requests
+	redisRateLimitTimeout = 200 * time.Millisecond
+	// redisRetryInterval is how long the in-memory store is used after Redis
+	// fails before Redis is tried again
+	redisRetryInterval = 10 * time.Second
+)
+
+// RedisRateLimitStore shares request logs between instances through Redis.
+// While Redis is unreachable it falls back to an in-memory store, so limits
+// are enforced per instance until Redis is back.
+type RedisRateLimitStore struct {
+	client    *redis.Client
+	prefix    string
+	fallback  *MemoryRateLimitStore
+	mu        sync.Mutex
+	downUntil time.Time
+}
+
+// NewRedisRateLimitStore creates a Redis store. Keys are prefixed with prefix.
+func NewRedisRateLimitStore(client *redis.Client, prefix string, fallback *MemoryRateLimitStore) *RedisRateLimitStore {
+	return &RedisRateLimitStore{client: client, prefix: prefix, fallback: fallback}
+}
+
+func (s *RedisRateLimitStore) Allow(ctx context.Context, key string, windows []RateWindow) (RateLimitResult, error) {
+	s.mu.Lock()
+	down := time.Now().Before(s.downUntil)
+	s.mu.Unlock()
+	if down {
+		return s.fallback.Allow(ctx, key, windows)
+	}
+
+	result, err := s.allow(ctx, key, windows)
+	if err != nil {
+		s.mu.Lock()
+		s.downUntil = time.Now().Add(redisRetryInterval)
+		s.mu.Unlock()
+		logger.Warn("Redis rate limiting unavailable, using in-memory limits for %v: %v", redisRetryInterval, err)
+		return s.fallback.Allow(ctx, key, windows)
+	}
+	return result, nil
+}
+
+func (s *RedisRateLimitStore) allow(ctx context.Context, key string, windows []RateWindow) (RateLimitResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, redisRateLimitTimeout)
+	defer cancel()
+
+	args := []interface{}{newRequestID()}
+	for _, window := range windows {
+		args = append(args, window.Limit, window.Window.Microseconds())
+	}
+	values, err := slidingWindowScript.Run(ctx, s.client, []string{s.prefix + key}, args...).Int64Slice()
+	if err != nil {
+		return RateLimitResult{}, fmt.Errorf("failed to check rate limit: %w", err)
+	}
+	if len(values) != 1+2*len(windows) {
+		return RateLimitResult{}, fmt.Errorf("unexpected rate limit script result: %v", values)
+	}
+
+	result := RateLimitResult{Allowed: values[0] == 0, Exceeded: int(values[0]) - 1, Windows: make([]RateWindowState, len(windows))}
+	for i, window := range windows {
+		remaining := window.Limit - int(values[1+2*i])
+		if result.Allowed {
+			remaining--
+		}
+		result.Windows[i] = RateWindowState{
+			RateWindow: window,
+			Remaining:  remaining,
+			Reset:      time.Duration(values[2+2*i]) * time.Microsecond,
+		}
+	}
+	return result, nil
+}
+
+// Close closes the Redis client
+func (s *RedisRateLimitStore) Close() error {
+	return s.client.Close()
+}
+
+// newRequestID returns a unique sorted set member for a request
+func newRequestID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(buf)
+}
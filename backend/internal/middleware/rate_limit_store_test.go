@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toeic-app/internal/config"
+	"github.com/toeic-app/internal/token"
+)
+
+func newTestMemoryStore(now *time.Time) *MemoryRateLimitStore {
+	store := NewMemoryRateLimitStore()
+	store.now = func() time.Time { return *now }
+	return store
+}
+
+func TestMemoryRateLimitStoreSlidingWindow(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	store := newTestMemoryStore(&now)
+	windows := []RateWindow{{Limit: 3, Window: 2 * time.Second}, {Limit: 5, Window: time.Minute}}
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		result, err := store.Allow(ctx, "ip:1.2.3.4", windows)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		now = now.Add(500 * time.Millisecond)
+	}
+
+	// Three requests in the last two seconds
+	result, err := store.Allow(ctx, "ip:1.2.3.4", windows)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 0, result.Exceeded)
+	assert.Equal(t, 0, result.Windows[0].Remaining)
+	assert.Equal(t, 500*time.Millisecond, result.Windows[0].Reset)
+
+	// Other clients have their own logs
+	result, _ = store.Allow(ctx, "ip:5.6.7.8", windows)
+	assert.True(t, result.Allowed)
+
+	// The first request slides out of the short window
+	now = now.Add(600 * time.Millisecond)
+	result, _ = store.Allow(ctx, "ip:1.2.3.4", windows)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 1, result.Windows[1].Remaining)
+	assert.Equal(t, 0, result.Tightest().Remaining)
+	assert.Equal(t, 3, result.Tightest().Limit)
+
+	now = now.Add(3 * time.Second)
+	result, _ = store.Allow(ctx, "ip:1.2.3.4", windows)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Windows[1].Remaining)
+
+	// The quota is spent; rejected requests are not counted
+	now = now.Add(3 * time.Second)
+	result, _ = store.Allow(ctx, "ip:1.2.3.4", windows)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 1, result.Exceeded)
+	assert.Equal(t, 3, result.Windows[0].Remaining)
+
+	// Logs of idle clients are pruned
+	now = now.Add(2 * time.Minute)
+	assert.Equal(t, 2, store.Prune(time.Minute))
+	result, _ = store.Allow(ctx, "ip:1.2.3.4", windows)
+	assert.Equal(t, 4, result.Windows[1].Remaining)
+}
+
+func TestRedisRateLimitStoreFallback(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", DialTimeout: 100 * time.Millisecond, MaxRetries: -1})
+	store := NewRedisRateLimitStore(client, "test:", NewMemoryRateLimitStore())
+	defer store.Close()
+
+	windows := []RateWindow{{Limit: 1, Window: time.Minute}}
+	result, err := store.Allow(context.Background(), "user:7", windows)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+
+	// Redis is skipped until the retry interval passes, and limits still hold
+	assert.True(t, time.Now().Before(store.downUntil))
+	result, err = store.Allow(context.Background(), "user:7", windows)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+}
+
+func TestAdvancedRateLimitHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	limiter := NewAdvancedRateLimit(config.Config{RateLimitRequests: 1, RateLimitBurst: 2, RateLimitBackend: "memory"}, nil)
+	defer limiter.Stop()
+
+	router := gin.New()
+	router.Use(limiter.Middleware())
+	router.GET("/", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	request := func() *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+		return recorder
+	}
+
+	recorder := request()
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	assert.Equal(t, "2", recorder.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", recorder.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "2", recorder.Header().Get("RateLimit-Reset"))
+	assert.Equal(t, "2;w=2, 600;w=3600", recorder.Header().Get("RateLimit-Policy"))
+
+	request()
+	recorder = request()
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	assert.Equal(t, "0", recorder.Header().Get("RateLimit-Remaining"))
+	assert.NotEmpty(t, recorder.Header().Get("Retry-After"))
+}
+
+func TestAdvancedRateLimitBucket(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	limiter := NewAdvancedRateLimit(config.Config{RateLimitRequests: 10, RateLimitBurst: 20, RateLimitBackend: "memory"}, nil)
+	defer limiter.Stop()
+
+	router := gin.New()
+	router.Use(limiter.Middleware())
+	router.GET("/ai", func(c *gin.Context) {
+		c.Set("authorization_payload", &token.Payload{ID: 7})
+	}, limiter.Bucket("ai", []RateWindow{{Limit: 2, Window: time.Minute}}), func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	router.GET("/other", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	request := func(path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		return recorder
+	}
+
+	assert.Equal(t, http.StatusNoContent, request("/ai").Code)
+	recorder := request("/ai")
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	assert.Equal(t, "2;w=60", recorder.Header().Get("RateLimit-Policy"))
+
+	recorder = request("/ai")
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	assert.NotEmpty(t, recorder.Header().Get("Retry-After"))
+
+	// Other endpoints only count against the general limits
+	assert.Equal(t, http.StatusNoContent, request("/other").Code)
+}
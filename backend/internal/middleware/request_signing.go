@@ -0,0 +1,235 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/toeic-app/internal/cache"
+	"github.com/toeic-app/internal/config"
+	"github.com/toeic-app/internal/logger"
+)
+
+// Request signing headers
+const (
+	HeaderRequestSignature = "X-Request-Signature" // HMAC of the canonical request
+	HeaderAppKeyID         = "X-App-Key-ID"        // Signing key of the app release, e.g. "android-2.4.0"
+)
+
+// Request signing modes
+const (
+	SigningModeOff      = "off"      // Signatures are ignored
+	SigningModeOptional = "optional" // Signatures are verified when present
+	SigningModeRequired = "required" // Every request must be signed
+)
+
+// NonceStore remembers nonces that were already used
+type NonceStore interface {
+	// Remember stores a nonce and reports whether it was seen before
+	Remember(ctx context.Context, nonce string, ttl time.Duration) (seen bool, err error)
+}
+
+// memoryNonceStore keeps nonces in process memory
+type memoryNonceStore struct {
+	mu          sync.Mutex
+	nonces      map[string]time.Time
+	lastCleanup time.Time
+}
+
+// NewMemoryNonceStore creates a nonce store for single instance deployments
+func NewMemoryNonceStore() NonceStore {
+	return &memoryNonceStore{
+		nonces:      make(map[string]time.Time),
+		lastCleanup: time.Now(),
+	}
+}
+
+func (s *memoryNonceStore) Remember(_ context.Context, nonce string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.lastCleanup) > time.Minute {
+		for key, expiresAt := range s.nonces {
+			if now.After(expiresAt) {
+				delete(s.nonces, key)
+			}
+		}
+		s.lastCleanup = now
+	}
+
+	if expiresAt, ok := s.nonces[nonce]; ok && now.Before(expiresAt) {
+		return true, nil
+	}
+	s.nonces[nonce] = now.Add(ttl)
+	return false, nil
+}
+
+// cacheNonceStore keeps nonces in the shared cache so replays are detected across instances
+type cacheNonceStore struct {
+	cache cache.Cache
+}
+
+// NewCacheNonceStore creates a nonce store backed by the application cache
+func NewCacheNonceStore(c cache.Cache) NonceStore {
+	return &cacheNonceStore{cache: c}
+}
+
+func (s *cacheNonceStore) Remember(ctx context.Context, nonce string, ttl time.Duration) (bool, error) {
+	stored, err := s.cache.SetNX(ctx, "request_nonce:"+nonce, []byte{1}, ttl)
+	if err != nil {
+		return false, err
+	}
+	return !stored, nil
+}
+
+// ParseSigningKeys parses "keyID:secret,keyID:secret" into a map
+func ParseSigningKeys(value string) (map[string]string, error) {
+	keys := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		keyID, secret, ok := strings.Cut(pair, ":")
+		if !ok || strings.TrimSpace(keyID) == "" || strings.TrimSpace(secret) == "" {
+			return nil, fmt.Errorf("signing key must be in KEY_ID:SECRET format")
+		}
+		keys[strings.TrimSpace(keyID)] = strings.TrimSpace(secret)
+	}
+	return keys, nil
+}
+
+// CanonicalRequest builds the string signed by clients:
+//
+//	METHOD\nPATH\nQUERY\nTIMESTAMP\nNONCE\nhex(sha256(body))
+func CanonicalRequest(method, path, rawQuery, timestamp, nonce string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	return strings.Join([]string{
+		strings.ToUpper(method),
+		path,
+		rawQuery,
+		timestamp,
+		nonce,
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n")
+}
+
+// SignRequest returns the hex encoded HMAC-SHA256 signature of a canonical request
+func SignRequest(secret, canonicalRequest string) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(canonicalRequest))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// RequestSigner verifies signed requests and rejects replayed nonces.
+// Each mobile app release ships with its own key, so a leaked key can be
+// revoked by removing it from REQUEST_SIGNING_KEYS without affecting other releases.
+type RequestSigner struct {
+	mu       sync.RWMutex
+	mode     string
+	keys     map[string]string
+	nonces   NonceStore
+	nonceTTL time.Duration
+}
+
+// NewRequestSigner creates a request signer using the signing configuration
+func NewRequestSigner(cfg config.Config, nonces NonceStore) *RequestSigner {
+	signer := &RequestSigner{
+		nonces:   nonces,
+		nonceTTL: MaxTimestampAge + time.Minute, // covers the accepted timestamp range including clock skew
+	}
+	signer.UpdateKeys(cfg)
+	return signer
+}
+
+// UpdateKeys applies the signing mode and keys, e.g. after a configuration reload
+func (rs *RequestSigner) UpdateKeys(cfg config.Config) {
+	keys, err := ParseSigningKeys(cfg.RequestSigningKeys)
+	if err != nil {
+		logger.Error("Invalid REQUEST_SIGNING_KEYS, keeping current keys: %v", err)
+		return
+	}
+
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	rs.mode = cfg.RequestSigningMode
+	rs.keys = keys
+	logger.Info("Request signing mode %q with %d release keys", rs.mode, len(keys))
+}
+
+// Mode returns the current signing mode
+func (rs *RequestSigner) Mode() string {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+	return rs.mode
+}
+
+// secret returns the key for a release key ID
+func (rs *RequestSigner) secret(keyID string) (string, bool) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+	secret, ok := rs.keys[keyID]
+	return secret, ok
+}
+
+// Verify checks the request signature and records its nonce.
+// The request timestamp must already have been validated.
+func (rs *RequestSigner) Verify(c *gin.Context) error {
+	mode := rs.Mode()
+	signature := c.GetHeader(HeaderRequestSignature)
+	if mode == SigningModeOff || (mode == SigningModeOptional && signature == "") {
+		return nil
+	}
+
+	if signature == "" {
+		return fmt.Errorf("missing request signature")
+	}
+
+	keyID := c.GetHeader(HeaderAppKeyID)
+	secret, ok := rs.secret(keyID)
+	if !ok {
+		return fmt.Errorf("unknown or revoked app key: %q", keyID)
+	}
+
+	nonce := c.GetHeader(HeaderNonce)
+	if len(nonce) < 16 || len(nonce) > 128 {
+		return fmt.Errorf("missing or invalid request nonce")
+	}
+
+	var body []byte
+	if c.Request.Body != nil {
+		var err error
+		body, err = io.ReadAll(c.Request.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read request body: %w", err)
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	canonical := CanonicalRequest(c.Request.Method, c.Request.URL.Path, c.Request.URL.RawQuery,
+		c.GetHeader(HeaderRequestTimestamp), nonce, body)
+	expected := SignRequest(secret, canonical)
+	if !hmac.Equal([]byte(strings.ToLower(signature)), []byte(expected)) {
+		return fmt.Errorf("request signature mismatch")
+	}
+
+	// Only remember nonces of correctly signed requests so attackers cannot burn them
+	seen, err := rs.nonces.Remember(c.Request.Context(), keyID+":"+nonce, rs.nonceTTL)
+	if err != nil {
+		return fmt.Errorf("failed to check request nonce: %w", err)
+	}
+	if seen {
+		return fmt.Errorf("request nonce already used")
+	}
+
+	return nil
+}
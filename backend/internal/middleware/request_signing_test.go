@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/toeic-app/internal/config"
+)
+
+func newSignedContext(method, target, body, keyID, secret, nonce string) *gin.Context {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(HeaderRequestTimestamp, timestamp)
+	req.Header.Set(HeaderNonce, nonce)
+	req.Header.Set(HeaderAppKeyID, keyID)
+	canonical := CanonicalRequest(method, req.URL.Path, req.URL.RawQuery, timestamp, nonce, []byte(body))
+	req.Header.Set(HeaderRequestSignature, SignRequest(secret, canonical))
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = req
+	return c
+}
+
+func TestRequestSignerVerify(t *testing.T) {
+	signer := NewRequestSigner(config.Config{
+		RequestSigningMode: SigningModeRequired,
+		RequestSigningKeys: "android-2.4.0:release-secret, ios-2.4.0:other-secret",
+	}, NewMemoryNonceStore())
+
+	body := `{"answer":"B"}`
+	c := newSignedContext(http.MethodPost, "/api/v1/answers?draft=1", body, "android-2.4.0", "release-secret", "nonce-0123456789abcdef")
+	assert.NoError(t, signer.Verify(c))
+
+	// The handler can still read the body
+	read := make([]byte, len(body))
+	_, _ = c.Request.Body.Read(read)
+	assert.Equal(t, body, string(read))
+
+	// Replaying the same nonce is rejected
+	c = newSignedContext(http.MethodPost, "/api/v1/answers?draft=1", body, "android-2.4.0", "release-secret", "nonce-0123456789abcdef")
+	assert.ErrorContains(t, signer.Verify(c), "nonce already used")
+
+	// A tampered body does not match the signature
+	c = newSignedContext(http.MethodPost, "/api/v1/answers", body, "android-2.4.0", "release-secret", "nonce-fedcba9876543210")
+	c.Request.Body = http.NoBody
+	assert.ErrorContains(t, signer.Verify(c), "mismatch")
+
+	// Revoked release keys are rejected
+	signer.UpdateKeys(config.Config{RequestSigningMode: SigningModeRequired, RequestSigningKeys: "ios-2.4.0:other-secret"})
+	c = newSignedContext(http.MethodGet, "/api/v1/words", "", "android-2.4.0", "release-secret", "nonce-aaaaaaaaaaaaaaaa")
+	assert.ErrorContains(t, signer.Verify(c), "revoked")
+}
+
+func TestRequestSignerOptionalMode(t *testing.T) {
+	signer := NewRequestSigner(config.Config{
+		RequestSigningMode: SigningModeOptional,
+		RequestSigningKeys: "android-2.4.0:release-secret",
+	}, NewMemoryNonceStore())
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/words", nil)
+	assert.NoError(t, signer.Verify(c))
+
+	c = newSignedContext(http.MethodGet, "/api/v1/words", "", "android-2.4.0", "wrong-secret", "nonce-0123456789abcdef")
+	assert.Error(t, signer.Verify(c))
+}
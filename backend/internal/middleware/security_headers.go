@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"crypto/tls"
+	"fmt"
 	"net/http"
 	"strings"
 
@@ -208,6 +209,42 @@ func RequestSizeLimit(maxBytes int64) gin.HandlerFunc {
 	}
 }
 
+// SizeLimitRule sets the body size limit for routes under a path prefix
+type SizeLimitRule struct {
+	PathPrefix string
+	MaxBytes   int64
+}
+
+// RouteSizeLimit limits request body size using the longest matching path
+// prefix rule, falling back to defaultMaxBytes. Requests announcing a larger
+// Content-Length are rejected with 413 before the body is read.
+func RouteSizeLimit(defaultMaxBytes int64, rules []SizeLimitRule) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		maxBytes := defaultMaxBytes
+		matched := 0
+		for _, rule := range rules {
+			if len(rule.PathPrefix) > matched && strings.HasPrefix(c.Request.URL.Path, rule.PathPrefix) {
+				maxBytes = rule.MaxBytes
+				matched = len(rule.PathPrefix)
+			}
+		}
+
+		if c.Request.ContentLength > maxBytes {
+			c.Header("Connection", "close")
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+				"status":  "error",
+				"message": "Request body too large",
+				"error":   fmt.Sprintf("maximum request body size is %d bytes", maxBytes),
+			})
+			c.Abort()
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		c.Next()
+	}
+}
+
 // SecureHeaders middleware for sensitive endpoints
 func SecureHeaders() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouteSizeLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RouteSizeLimit(10, []SizeLimitRule{
+		{PathPrefix: "/api/v1/upload", MaxBytes: 100},
+		{PathPrefix: "/api/v1/admin/backups/upload", MaxBytes: 1000},
+	}))
+	router.POST("/*path", func(c *gin.Context) {
+		if _, err := io.ReadAll(c.Request.Body); err != nil {
+			c.Status(http.StatusRequestEntityTooLarge)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	for _, tc := range []struct {
+		path string
+		size int
+		want int
+	}{
+		{"/api/v1/users", 10, http.StatusOK},
+		{"/api/v1/users", 11, http.StatusRequestEntityTooLarge},
+		{"/api/v1/upload-audio", 100, http.StatusOK},
+		{"/api/v1/upload", 101, http.StatusRequestEntityTooLarge},
+		{"/api/v1/admin/backups/upload", 1000, http.StatusOK},
+		{"/api/v1/admin/backups/upload", 1001, http.StatusRequestEntityTooLarge},
+		{"/api/v1/admin/backups", 11, http.StatusRequestEntityTooLarge},
+	} {
+		req := httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(strings.Repeat("x", tc.size)))
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		assert.Equal(t, tc.want, recorder.Code, "%s with %d bytes", tc.path, tc.size)
+		if tc.want == http.StatusRequestEntityTooLarge {
+			assert.Contains(t, recorder.Body.String(), "Request body too large")
+		}
+	}
+
+	// A body without Content-Length is cut off at the limit while it is read
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/upload", io.MultiReader(strings.NewReader(strings.Repeat("x", 101))))
+	req.ContentLength = -1
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+}
@@ -60,8 +60,20 @@ type AdvancedMetrics struct {
 	performanceHistory map[string][]float64
 }
 
-// NewAdvancedMetrics creates enhanced monitoring metrics
+var (
+	sharedAdvancedMetrics     *AdvancedMetrics
+	sharedAdvancedMetricsOnce sync.Once
+)
+
+// NewAdvancedMetrics returns the enhanced monitoring metrics of the process,
+// which are shared like the ones of NewMetrics
 func NewAdvancedMetrics() *AdvancedMetrics {
+	sharedAdvancedMetricsOnce.Do(func() { sharedAdvancedMetrics = newAdvancedMetrics() })
+	return sharedAdvancedMetrics
+}
+
+// newAdvancedMetrics registers enhanced monitoring metrics
+func newAdvancedMetrics() *AdvancedMetrics {
 	return &AdvancedMetrics{
 		// SLA tracking
 		SLAViolations: promauto.NewCounterVec(
@@ -4,11 +4,15 @@ import (
 	"context"
 	"database/sql"
 	"net/http"
+	"sort"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/toeic-app/internal/cache"
 	"github.com/toeic-app/internal/config"
+	"github.com/toeic-app/internal/export"
 	"github.com/toeic-app/internal/logger"
 )
 
@@ -145,6 +149,7 @@ type CapacityPrediction struct {
 
 // BusinessAnalyzer analyzes business metrics
 type BusinessAnalyzer struct {
+	mu       sync.RWMutex
 	metrics  map[string]BusinessMetric
 	insights []BusinessInsight
 	trends   map[string]BusinessTrend
@@ -498,9 +503,45 @@ func (ams *AdvancedMonitoringService) getBusinessMetricsHandler() gin.HandlerFun
 			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Business analytics not enabled"})
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{
-			"metrics": "implementation needed",
-		})
+		format, err := export.ParseFormat(c.Query("format"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		metrics := ams.businessAnalyzer.Metrics()
+		if format == "" {
+			c.JSON(http.StatusOK, gin.H{
+				"metrics": metrics,
+			})
+			return
+		}
+
+		export.SetHeaders(c.Writer.Header(), format, "business-metrics")
+		c.Status(http.StatusOK)
+		w, err := export.NewWriter(format, c.Writer, "business-metrics")
+		if err == nil {
+			err = w.Write([]string{"category", "name", "value", "trend", "timestamp"})
+		}
+		for _, metric := range metrics {
+			if err != nil {
+				break
+			}
+			err = w.Write([]string{
+				metric.Category,
+				metric.Name,
+				strconv.FormatFloat(metric.Value, 'f', -1, 64),
+				metric.Trend,
+				metric.Timestamp.UTC().Format(time.RFC3339),
+			})
+		}
+		if err == nil {
+			err = w.Close()
+		}
+		if err != nil {
+			logger.Error("Failed to export business metrics: %v", err)
+			c.Abort()
+		}
 	}
 }
 
@@ -650,6 +691,35 @@ func NewBusinessAnalyzer(config *AdvancedMonitoringConfig) *BusinessAnalyzer {
 	}
 }
 
+// RecordMetric stores the latest value of a business metric
+func (ba *BusinessAnalyzer) RecordMetric(metric BusinessMetric) {
+	if metric.Timestamp.IsZero() {
+		metric.Timestamp = time.Now()
+	}
+	ba.mu.Lock()
+	defer ba.mu.Unlock()
+	ba.metrics[metric.Category+"/"+metric.Name] = metric
+}
+
+// Metrics returns the latest value of every business metric, ordered by
+// category and name
+func (ba *BusinessAnalyzer) Metrics() []BusinessMetric {
+	ba.mu.RLock()
+	metrics := make([]BusinessMetric, 0, len(ba.metrics))
+	for _, metric := range ba.metrics {
+		metrics = append(metrics, metric)
+	}
+	ba.mu.RUnlock()
+
+	sort.Slice(metrics, func(i, j int) bool {
+		if metrics[i].Category != metrics[j].Category {
+			return metrics[i].Category < metrics[j].Category
+		}
+		return metrics[i].Name < metrics[j].Name
+	})
+	return metrics
+}
+
 func (ba *BusinessAnalyzer) Start(ctx context.Context) {
 	// Implementation for business analytics
 	logger.Info("Business Analyzer started")
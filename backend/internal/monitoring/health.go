@@ -5,6 +5,8 @@ import (
 	"database/sql"
 	"fmt"
 	"net/http"
+	"slices"
+	"sort"
 	"sync"
 	"time"
 
@@ -186,6 +188,78 @@ func (c *CacheHealthChecker) CheckHealth(ctx context.Context) *ComponentHealth {
 	return health
 }
 
+// MigrationHealthChecker checks that the database schema has been migrated to
+// the version the binary was built against
+type MigrationHealthChecker struct {
+	db       *sql.DB
+	name     string
+	expected uint
+	timeout  time.Duration
+}
+
+// NewMigrationHealthChecker creates a new migration health checker
+func NewMigrationHealthChecker(db *sql.DB, name string, expected uint, timeout time.Duration) *MigrationHealthChecker {
+	return &MigrationHealthChecker{
+		db:       db,
+		name:     name,
+		expected: expected,
+		timeout:  timeout,
+	}
+}
+
+func (m *MigrationHealthChecker) GetName() string {
+	return m.name
+}
+
+func (m *MigrationHealthChecker) CheckHealth(ctx context.Context) *ComponentHealth {
+	start := time.Now()
+
+	ctx, cancel := context.WithTimeout(ctx, m.timeout)
+	defer cancel()
+
+	health := &ComponentHealth{
+		LastChecked: start,
+		Details:     make(map[string]interface{}),
+	}
+	health.Details["expected_version"] = m.expected
+
+	// golang-migrate keeps a single row with the applied version
+	var version int64
+	var dirty bool
+	err := m.db.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	health.ResponseTime = time.Since(start)
+	switch {
+	case err == sql.ErrNoRows:
+		health.Status = HealthStatusDown
+		health.Message = "No migrations have been applied"
+		return health
+	case err != nil:
+		health.Status = HealthStatusDown
+		health.Message = fmt.Sprintf("Failed to read migration status: %v", err)
+		return health
+	}
+
+	health.Details["version"] = version
+	health.Details["dirty"] = dirty
+	switch {
+	case dirty:
+		health.Status = HealthStatusDown
+		health.Message = fmt.Sprintf("Migration %d did not complete and left the schema dirty", version)
+	case version < int64(m.expected):
+		health.Status = HealthStatusDown
+		health.Message = fmt.Sprintf("Schema is at version %d, this release needs %d", version, m.expected)
+	case version > int64(m.expected):
+		// A newer release has migrated the schema during a rollout. Migrations
+		// are additive, so this instance keeps serving until it is replaced.
+		health.Status = HealthStatusWarning
+		health.Message = fmt.Sprintf("Schema version %d is newer than this release's %d", version, m.expected)
+	default:
+		health.Status = HealthStatusUp
+		health.Message = "Schema is up to date"
+	}
+	return health
+}
+
 // ExternalServiceHealthChecker checks external service health
 type ExternalServiceHealthChecker struct {
 	name       string
@@ -257,6 +331,7 @@ func (e *ExternalServiceHealthChecker) CheckHealth(ctx context.Context) *Compone
 type HealthService struct {
 	checkers    map[string]HealthChecker
 	lastResults map[string]*ComponentHealth
+	lastUp      map[string]time.Time
 	startTime   time.Time
 	version     string
 	config      *HealthConfig
@@ -272,6 +347,18 @@ type HealthConfig struct {
 	MaxCacheAge       time.Duration
 	FailureThreshold  int
 	RecoveryThreshold int
+
+	// RequiredDependencies lists the checkers that must be up for the
+	// readiness probe to pass; the others only degrade the instance. When
+	// empty every checker is required.
+	RequiredDependencies []string
+	// ReadinessGracePeriod keeps the instance ready while a required
+	// dependency that was up recently is down, so a short blip does not take
+	// every instance out of rotation at once
+	ReadinessGracePeriod time.Duration
+	// ReadinessMaxAge is how long a check result is reused by the readiness
+	// probe, which runs more often than the background checks
+	ReadinessMaxAge time.Duration
 }
 
 // DefaultHealthConfig returns default health configuration
@@ -284,6 +371,9 @@ func DefaultHealthConfig() *HealthConfig {
 		MaxCacheAge:       1 * time.Minute,
 		FailureThreshold:  3,
 		RecoveryThreshold: 2,
+
+		ReadinessGracePeriod: 15 * time.Second,
+		ReadinessMaxAge:      2 * time.Second,
 	}
 }
 
@@ -296,6 +386,7 @@ func NewHealthService(version string, config *HealthConfig) *HealthService {
 	return &HealthService{
 		checkers:    make(map[string]HealthChecker),
 		lastResults: make(map[string]*ComponentHealth),
+		lastUp:      make(map[string]time.Time),
 		startTime:   time.Now(),
 		version:     version,
 		config:      config,
@@ -318,11 +409,21 @@ func (h *HealthService) UnregisterChecker(name string) {
 
 	delete(h.checkers, name)
 	delete(h.lastResults, name)
+	delete(h.lastUp, name)
 	logger.Info("Health checker unregistered: %s", name)
 }
 
 // CheckHealth performs health checks on all registered components
 func (h *HealthService) CheckHealth(ctx context.Context) *OverallHealth {
+	maxAge := time.Duration(0)
+	if h.config.CacheResults {
+		maxAge = h.config.MaxCacheAge
+	}
+	return h.checkHealth(ctx, maxAge)
+}
+
+// checkHealth checks every component, reusing results younger than maxAge
+func (h *HealthService) checkHealth(ctx context.Context, maxAge time.Duration) *OverallHealth {
 	overall := &OverallHealth{
 		Timestamp:  time.Now(),
 		Version:    h.version,
@@ -357,12 +458,12 @@ func (h *HealthService) CheckHealth(ctx context.Context) *OverallHealth {
 			defer wg.Done()
 
 			// Use cached result if available and fresh
-			if h.config.CacheResults {
+			if maxAge > 0 {
 				h.mu.RLock()
 				cached := h.lastResults[name]
 				h.mu.RUnlock()
 
-				if cached != nil && time.Since(cached.LastChecked) < h.config.MaxCacheAge {
+				if cached != nil && time.Since(cached.LastChecked) < maxAge {
 					results <- struct {
 						name   string
 						health *ComponentHealth
@@ -374,12 +475,13 @@ func (h *HealthService) CheckHealth(ctx context.Context) *OverallHealth {
 			// Perform health check
 			health := checker.CheckHealth(ctx)
 
-			// Cache result
-			if h.config.CacheResults {
-				h.mu.Lock()
-				h.lastResults[name] = health
-				h.mu.Unlock()
+			// Cache result and remember when the component was last usable
+			h.mu.Lock()
+			h.lastResults[name] = health
+			if health.Status != HealthStatusDown {
+				h.lastUp[name] = health.LastChecked
 			}
+			h.mu.Unlock()
 
 			results <- struct {
 				name   string
@@ -515,26 +617,90 @@ func (h *HealthService) GetLivenessHandler() gin.HandlerFunc {
 	}
 }
 
+// ReadinessDependency is the state of one component in a readiness probe
+type ReadinessDependency struct {
+	Status   HealthStatus `json:"status"`
+	Required bool         `json:"required"`
+	Message  string       `json:"message,omitempty"`
+	// InGracePeriod is set while a required component is down but was up
+	// within the grace period
+	InGracePeriod bool `json:"in_grace_period,omitempty"`
+}
+
+// ReadinessReport tells an orchestrator whether the instance should receive
+// traffic
+type ReadinessReport struct {
+	Ready        bool                           `json:"ready"`
+	Status       HealthStatus                   `json:"status"`
+	Timestamp    time.Time                      `json:"timestamp"`
+	Components   int                            `json:"components"`
+	Dependencies map[string]ReadinessDependency `json:"dependencies"`
+	Failing      []string                       `json:"failing,omitempty"`
+	Summary      map[string]interface{}         `json:"summary,omitempty"`
+}
+
+// CheckReadiness checks every component and decides readiness from the
+// required ones. A required component that has not been up since startup
+// keeps the instance out of rotation; one that goes down afterwards only does
+// so once the grace period has passed. Optional components never do.
+func (h *HealthService) CheckReadiness(ctx context.Context) *ReadinessReport {
+	health := h.checkHealth(ctx, h.config.ReadinessMaxAge)
+
+	report := &ReadinessReport{
+		Ready:        true,
+		Status:       health.Status,
+		Timestamp:    health.Timestamp,
+		Components:   len(health.Components),
+		Dependencies: make(map[string]ReadinessDependency, len(health.Components)),
+		Summary:      health.Summary,
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for name, component := range health.Components {
+		dependency := ReadinessDependency{
+			Status:   component.Status,
+			Required: h.isRequired(name),
+			Message:  component.Message,
+		}
+		if dependency.Required && component.Status == HealthStatusDown {
+			lastUp, seen := h.lastUp[name]
+			if seen && health.Timestamp.Sub(lastUp) < h.config.ReadinessGracePeriod {
+				dependency.InGracePeriod = true
+			} else {
+				report.Ready = false
+				report.Failing = append(report.Failing, name)
+			}
+		}
+		report.Dependencies[name] = dependency
+	}
+	sort.Strings(report.Failing)
+
+	return report
+}
+
+// isRequired reports whether a failing component makes the instance unready
+func (h *HealthService) isRequired(name string) bool {
+	if len(h.config.RequiredDependencies) == 0 {
+		return true
+	}
+	return slices.Contains(h.config.RequiredDependencies, name)
+}
+
 // GetReadinessHandler returns a readiness probe handler
 func (h *HealthService) GetReadinessHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx, cancel := context.WithTimeout(c.Request.Context(), h.config.DefaultTimeout)
 		defer cancel()
 
-		health := h.CheckHealth(ctx)
+		report := h.CheckReadiness(ctx)
 
-		// For readiness, we're more strict - any down component means not ready
 		statusCode := http.StatusOK
-		if health.Status == HealthStatusDown {
+		if !report.Ready {
 			statusCode = http.StatusServiceUnavailable
 		}
 
-		c.JSON(statusCode, gin.H{
-			"status":     health.Status,
-			"timestamp":  health.Timestamp,
-			"ready":      health.Status != HealthStatusDown,
-			"components": len(health.Components),
-			"summary":    health.Summary,
-		})
+		c.Header("Cache-Control", "no-cache, no-store, must-revalidate")
+		c.JSON(statusCode, report)
 	}
 }
@@ -0,0 +1,87 @@
+package monitoring
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubChecker struct {
+	name   string
+	status HealthStatus
+}
+
+func (s *stubChecker) GetName() string {
+	return s.name
+}
+
+func (s *stubChecker) CheckHealth(ctx context.Context) *ComponentHealth {
+	return &ComponentHealth{Status: s.status, LastChecked: time.Now()}
+}
+
+func newReadinessService(required ...string) (*HealthService, map[string]*stubChecker) {
+	config := DefaultHealthConfig()
+	config.RequiredDependencies = required
+	config.ReadinessGracePeriod = time.Minute
+	config.ReadinessMaxAge = 0
+
+	service := NewHealthService("test", config)
+	checkers := map[string]*stubChecker{}
+	for _, name := range []string{"database", "cache", "analyze"} {
+		checkers[name] = &stubChecker{name: name, status: HealthStatusUp}
+		service.RegisterChecker(checkers[name])
+	}
+	return service, checkers
+}
+
+func TestReadinessRequiredDependencyNeverUp(t *testing.T) {
+	service, checkers := newReadinessService("database", "cache")
+	checkers["cache"].status = HealthStatusDown
+
+	report := service.CheckReadiness(context.Background())
+	assert.False(t, report.Ready)
+	assert.Equal(t, []string{"cache"}, report.Failing)
+	assert.True(t, report.Dependencies["cache"].Required)
+}
+
+func TestReadinessOptionalDependencyDown(t *testing.T) {
+	service, checkers := newReadinessService("database", "cache")
+	checkers["analyze"].status = HealthStatusDown
+
+	report := service.CheckReadiness(context.Background())
+	assert.True(t, report.Ready)
+	assert.Empty(t, report.Failing)
+	assert.False(t, report.Dependencies["analyze"].Required)
+	assert.Equal(t, HealthStatusDown, report.Status)
+}
+
+func TestReadinessGracePeriod(t *testing.T) {
+	service, checkers := newReadinessService("database")
+	require.True(t, service.CheckReadiness(context.Background()).Ready)
+
+	// A blip right after being up keeps the instance in rotation
+	checkers["database"].status = HealthStatusDown
+	report := service.CheckReadiness(context.Background())
+	assert.True(t, report.Ready)
+	assert.True(t, report.Dependencies["database"].InGracePeriod)
+
+	// Once the grace period has passed the instance is taken out
+	service.mu.Lock()
+	service.lastUp["database"] = time.Now().Add(-2 * time.Minute)
+	service.mu.Unlock()
+	report = service.CheckReadiness(context.Background())
+	assert.False(t, report.Ready)
+	assert.Equal(t, []string{"database"}, report.Failing)
+}
+
+func TestReadinessEveryDependencyRequiredByDefault(t *testing.T) {
+	service, checkers := newReadinessService()
+	checkers["analyze"].status = HealthStatusDown
+
+	report := service.CheckReadiness(context.Background())
+	assert.False(t, report.Ready)
+	assert.Equal(t, []string{"analyze"}, report.Failing)
+}
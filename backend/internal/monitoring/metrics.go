@@ -51,11 +51,29 @@ type Metrics struct {
 	UserRegistrations *prometheus.CounterVec
 	AudioUploads      *prometheus.CounterVec
 
+	// WebSocket metrics
+	WebSocketConnections   *prometheus.GaugeVec
+	WebSocketSubscriptions *prometheus.GaugeVec
+	WebSocketMessages      *prometheus.CounterVec
+	WebSocketSendFailures  *prometheus.CounterVec
+
 	mu sync.RWMutex
 }
 
-// NewMetrics creates a new Metrics instance with all Prometheus metrics
+var (
+	sharedMetrics     *Metrics
+	sharedMetricsOnce sync.Once
+)
+
+// NewMetrics returns the Prometheus metrics of the process. Collectors can
+// only be registered once, so every monitor in a process shares them.
 func NewMetrics() *Metrics {
+	sharedMetricsOnce.Do(func() { sharedMetrics = newMetrics() })
+	return sharedMetrics
+}
+
+// newMetrics registers all Prometheus metrics
+func newMetrics() *Metrics {
 	return &Metrics{
 		// HTTP Request metrics
 		RequestsTotal: promauto.NewCounterVec(
@@ -215,6 +233,36 @@ func NewMetrics() *Metrics {
 			},
 			[]string{"status", "format"},
 		),
+
+		// WebSocket metrics
+		WebSocketConnections: promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "websocket_connections",
+				Help: "Number of open WebSocket connections",
+			},
+			[]string{},
+		),
+		WebSocketSubscriptions: promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "websocket_subscriptions",
+				Help: "Number of WebSocket connections subscribed to a topic",
+			},
+			[]string{"topic"},
+		),
+		WebSocketMessages: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "websocket_messages_total",
+				Help: "Total number of WebSocket messages sent and received",
+			},
+			[]string{"direction", "type"},
+		),
+		WebSocketSendFailures: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "websocket_send_failures_total",
+				Help: "Total number of WebSocket messages that could not be sent",
+			},
+			[]string{"reason"},
+		),
 	}
 }
 
@@ -7,6 +7,7 @@ import (
 
 	"github.com/toeic-app/internal/cache"
 	"github.com/toeic-app/internal/config"
+	"github.com/toeic-app/internal/db/migrations"
 	"github.com/toeic-app/internal/logger"
 )
 
@@ -80,12 +81,25 @@ func NewMonitoringService(db *sql.DB, cache cache.Cache, appConfig config.Config
 	}
 
 	if monitoringConfig.HealthEnabled {
-		healthService = NewHealthService("1.0.0", monitoringConfig.HealthConfig)
+		healthConfig := monitoringConfig.HealthConfig
+		if healthConfig == nil {
+			healthConfig = DefaultHealthConfig()
+		}
+		// Readiness gating comes from the application config when it was loaded
+		if dependencies := appConfig.RequiredDependencies(); len(dependencies) > 0 {
+			healthConfig.RequiredDependencies = dependencies
+			healthConfig.ReadinessGracePeriod = appConfig.ReadinessGracePeriod
+			healthConfig.ReadinessMaxAge = appConfig.ReadinessCacheMaxAge
+		}
+		healthService = NewHealthService("1.0.0", healthConfig)
 
 		// Register default health checkers
 		if db != nil {
 			dbChecker := NewDatabaseHealthChecker(db, "database", 5*time.Second)
 			healthService.RegisterChecker(dbChecker)
+
+			migrationChecker := NewMigrationHealthChecker(db, "migrations", migrations.LatestVersion(), 5*time.Second)
+			healthService.RegisterChecker(migrationChecker)
 		}
 
 		if cache != nil {
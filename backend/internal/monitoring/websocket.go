@@ -0,0 +1,65 @@
+package monitoring
+
+// WebSocketMetrics records the WebSocket connections and messages of this
+// instance. It implements websocket.Observer.
+type WebSocketMetrics struct {
+	monitor *Monitor
+}
+
+// WebSocketMetrics returns the observer to set on the WebSocket manager
+func (m *Monitor) WebSocketMetrics() *WebSocketMetrics {
+	return &WebSocketMetrics{monitor: m}
+}
+
+func (w *WebSocketMetrics) enabled() bool {
+	return w.monitor.config.Enabled
+}
+
+// ConnectionOpened counts a new connection
+func (w *WebSocketMetrics) ConnectionOpened() {
+	if w.enabled() {
+		w.monitor.metrics.WebSocketConnections.WithLabelValues().Inc()
+	}
+}
+
+// ConnectionClosed counts a closed connection
+func (w *WebSocketMetrics) ConnectionClosed() {
+	if w.enabled() {
+		w.monitor.metrics.WebSocketConnections.WithLabelValues().Dec()
+	}
+}
+
+// Subscribed counts a connection subscribing to topic
+func (w *WebSocketMetrics) Subscribed(topic string) {
+	if w.enabled() {
+		w.monitor.metrics.WebSocketSubscriptions.WithLabelValues(topic).Inc()
+	}
+}
+
+// Unsubscribed counts a connection leaving topic, including by closing
+func (w *WebSocketMetrics) Unsubscribed(topic string) {
+	if w.enabled() {
+		w.monitor.metrics.WebSocketSubscriptions.WithLabelValues(topic).Dec()
+	}
+}
+
+// MessageSent counts a message queued for a client
+func (w *WebSocketMetrics) MessageSent(messageType string) {
+	if w.enabled() {
+		w.monitor.metrics.WebSocketMessages.WithLabelValues("sent", messageType).Inc()
+	}
+}
+
+// MessageReceived counts a message from a client
+func (w *WebSocketMetrics) MessageReceived(messageType string) {
+	if w.enabled() {
+		w.monitor.metrics.WebSocketMessages.WithLabelValues("received", messageType).Inc()
+	}
+}
+
+// SendFailed counts a message that could not be sent
+func (w *WebSocketMetrics) SendFailed(reason string) {
+	if w.enabled() {
+		w.monitor.metrics.WebSocketSendFailures.WithLabelValues(reason).Inc()
+	}
+}
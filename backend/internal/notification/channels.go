@@ -0,0 +1,197 @@
+package notification
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/toeic-app/internal/config"
+)
+
+// channel delivers events to one destination
+type channel interface {
+	Name() string
+	Send(ctx context.Context, event Event) error
+}
+
+// slackChannel posts events to a Slack incoming webhook
+type slackChannel struct {
+	nm  *NotificationManager
+	url string
+}
+
+func (c slackChannel) Name() string { return "slack" }
+
+var severityColors = map[Severity]string{
+	SeverityInfo:     "good",
+	SeverityWarning:  "warning",
+	SeverityCritical: "danger",
+}
+
+func (c slackChannel) Send(ctx context.Context, event Event) error {
+	attachment := SlackAttachment{
+		Color:     severityColors[event.Severity],
+		Title:     "TOEIC Database Backup/Restore",
+		Text:      event.Message,
+		Timestamp: event.At.Unix(),
+	}
+
+	// Add metadata fields if available
+	if metadata := event.Backup; metadata != nil {
+		attachment.Fields = []SlackField{
+			{Title: "Filename", Value: metadata.Filename, Short: true},
+			{Title: "Size", Value: formatBytes(metadata.Size), Short: true},
+			{Title: "Database", Value: metadata.DatabaseName, Short: true},
+			{Title: "Type", Value: metadata.Type, Short: true},
+		}
+
+		if metadata.Compressed {
+			attachment.Fields = append(attachment.Fields, SlackField{
+				Title: "Compressed", Value: "Yes", Short: true,
+			})
+		}
+
+		if metadata.Encrypted {
+			attachment.Fields = append(attachment.Fields, SlackField{
+				Title: "Encrypted", Value: "Yes", Short: true,
+			})
+		}
+	}
+
+	// Add error details if present
+	if event.Error != "" {
+		attachment.Fields = append(attachment.Fields, SlackField{
+			Title: "Error", Value: event.Error, Short: false,
+		})
+	}
+
+	return c.nm.sendHTTPNotification(ctx, c.url, SlackMessage{
+		Username:    "Backup Bot",
+		IconEmoji:   ":floppy_disk:",
+		Attachments: []SlackAttachment{attachment},
+	})
+}
+
+// webhookChannel posts events as JSON to a generic webhook
+type webhookChannel struct {
+	nm  *NotificationManager
+	url string
+}
+
+func (c webhookChannel) Name() string { return "webhook" }
+
+func (c webhookChannel) Send(ctx context.Context, event Event) error {
+	payload := map[string]interface{}{
+		"event":     event.Type,
+		"severity":  event.Severity.String(),
+		"timestamp": event.At.Unix(),
+		"filename":  event.Filename,
+		"subject":   event.Subject,
+		"message":   event.Message,
+	}
+	if event.Backup != nil {
+		payload["data"] = event.Backup
+	}
+	if event.Duration > 0 {
+		payload["duration"] = event.Duration.String()
+	}
+	if len(event.Details) > 0 {
+		payload["details"] = event.Details
+	}
+	if event.Error != "" {
+		payload["error"] = event.Error
+	}
+	return c.nm.sendHTTPNotification(ctx, c.url, payload)
+}
+
+// mailSender sends an email; smtp.SendMail without a deadline could block
+// the backup that is being reported
+type mailSender func(ctx context.Context, addr string, auth smtp.Auth, from string, to []string, msg []byte) error
+
+// emailChannel emails events through an SMTP server
+type emailChannel struct {
+	config config.SMTPNotifyConfig
+	send   mailSender
+}
+
+func (c emailChannel) Name() string { return "email" }
+
+func (c emailChannel) Send(ctx context.Context, event Event) error {
+	var auth smtp.Auth
+	if c.config.Username != "" {
+		auth = smtp.PlainAuth("", c.config.Username, c.config.Password, c.config.Host)
+	}
+	addr := net.JoinHostPort(c.config.Host, strconv.Itoa(c.config.Port))
+	return c.send(ctx, addr, auth, c.config.From, c.config.To, c.message(event))
+}
+
+// message formats the event as a plain text email
+func (c emailChannel) message(event Event) []byte {
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", c.config.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(c.config.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", event.Subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", event.At.Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	msg.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(event.Message, "\n", "\r\n"))
+	msg.WriteString("\r\n")
+	return []byte(msg.String())
+}
+
+// sendMail sends an email like smtp.SendMail, within the deadline of ctx
+func sendMail(ctx context.Context, addr string, auth smtp.Auth, from string, to []string, msg []byte) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	host, _, _ := net.SplitHostPort(addr)
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+	if auth != nil {
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("SMTP authentication failed: %w", err)
+		}
+	}
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	for _, recipient := range to {
+		if err := client.Rcpt(recipient); err != nil {
+			return fmt.Errorf("recipient %s rejected: %w", recipient, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
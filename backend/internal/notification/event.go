@@ -0,0 +1,173 @@
+package notification
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/toeic-app/internal/logger"
+)
+
+// EventType identifies what happened to a backup
+type EventType string
+
+// Backup events
+const (
+	EventBackupSuccess     EventType = "backup.success"
+	EventBackupFailure     EventType = "backup.failure"
+	EventRestoreSuccess    EventType = "restore.success"
+	EventRestoreFailure    EventType = "restore.failure"
+	EventValidationFailure EventType = "validation.failure" // A backup or restore check found issues
+)
+
+// eventTypes lists every event, for loading templates
+var eventTypes = []EventType{
+	EventBackupSuccess,
+	EventBackupFailure,
+	EventRestoreSuccess,
+	EventRestoreFailure,
+	EventValidationFailure,
+}
+
+// succeeded reports whether the event reports a success, which is sent only
+// with BACKUP_NOTIFY_SUCCESS
+func (t EventType) succeeded() bool {
+	return t == EventBackupSuccess || t == EventRestoreSuccess
+}
+
+// Severity orders events so channels can ignore the less important ones
+type Severity int
+
+// Severities, least severe first
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityCritical
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	case SeverityCritical:
+		return "critical"
+	default:
+		return "info"
+	}
+}
+
+// ParseSeverity parses info, warning or critical; empty is info
+func ParseSeverity(s string) (Severity, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "info":
+		return SeverityInfo, nil
+	case "warning":
+		return SeverityWarning, nil
+	case "critical":
+		return SeverityCritical, nil
+	}
+	return SeverityInfo, fmt.Errorf("unknown severity %q", s)
+}
+
+// Event is a backup event sent to the notification channels. Templates are
+// executed with the event, so its fields are what a template can use.
+type Event struct {
+	Type     EventType
+	Severity Severity
+	Filename string
+	Backup   *BackupMetadata // Set for backup.success
+	Duration time.Duration
+	// Details are the issues of a validation, or the warnings of a restore
+	Details []string
+	Error   string
+	At      time.Time
+
+	// Subject and Message are rendered from the templates before sending
+	Subject string
+	Message string
+}
+
+// eventTitles head the subject of each event
+var eventTitles = map[EventType]string{
+	EventBackupSuccess:     "Backup succeeded",
+	EventBackupFailure:     "Backup failed",
+	EventRestoreSuccess:    "Restore succeeded",
+	EventRestoreFailure:    "Restore failed",
+	EventValidationFailure: "Backup validation failed",
+}
+
+// defaultTemplates are the messages of the events without a template in
+// BACKUP_NOTIFY_TEMPLATE_DIR
+var defaultTemplates = map[EventType]string{
+	EventBackupSuccess: `✅ Database backup completed successfully: {{.Filename}}` +
+		`{{with .Backup}} ({{bytes .Size}}){{end}}{{if .Duration}} in {{.Duration}}{{end}}`,
+	EventBackupFailure: `❌ Database backup failed{{with .Filename}}: {{.}}{{end}} - Error: {{.Error}}`,
+	EventRestoreSuccess: `✅ Database restore completed successfully from: {{.Filename}} (duration: {{.Duration}})` +
+		`{{range .Details}}
+- {{.}}{{end}}`,
+	EventRestoreFailure: `❌ Database restore failed from: {{.Filename}} - Error: {{.Error}}`,
+	EventValidationFailure: `⚠️ Backup {{.Filename}} failed validation:{{range .Details}}
+- {{.}}{{end}}`,
+}
+
+var templateFuncs = template.FuncMap{
+	"bytes": formatBytes,
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+}
+
+// loadTemplates parses the default templates, replacing those with a
+// <event>.tmpl file in dir. A template that does not parse is logged and
+// the default is kept.
+func loadTemplates(dir string) map[EventType]*template.Template {
+	templates := make(map[EventType]*template.Template, len(eventTypes))
+	for _, eventType := range eventTypes {
+		templates[eventType] = template.Must(template.New(string(eventType)).Funcs(templateFuncs).Parse(defaultTemplates[eventType]))
+		if dir == "" {
+			continue
+		}
+
+		path := filepath.Join(dir, string(eventType)+".tmpl")
+		text, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			logger.Error("Failed to read notification template %s: %v", path, err)
+			continue
+		}
+		custom, err := template.New(string(eventType)).Funcs(templateFuncs).Parse(string(text))
+		if err != nil {
+			logger.Error("Invalid notification template %s, using the default: %v", path, err)
+			continue
+		}
+		templates[eventType] = custom
+	}
+	return templates
+}
+
+// render sets the subject and message of the event
+func (e *Event) render(templates map[EventType]*template.Template) {
+	e.Subject = fmt.Sprintf("[%s] %s", strings.ToUpper(e.Severity.String()), eventTitles[e.Type])
+	if e.Filename != "" {
+		e.Subject += ": " + e.Filename
+	}
+
+	tmpl, ok := templates[e.Type]
+	if !ok {
+		e.Message = e.Subject
+		return
+	}
+	var message bytes.Buffer
+	if err := tmpl.Execute(&message, e); err != nil {
+		logger.Error("Failed to render %s notification: %v", e.Type, err)
+		e.Message = e.Subject
+		return
+	}
+	e.Message = strings.TrimSpace(message.String())
+}
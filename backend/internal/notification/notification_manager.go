@@ -2,18 +2,33 @@ package notification
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sync"
+	"text/template"
 	"time"
 
 	"github.com/toeic-app/internal/config"
 	"github.com/toeic-app/internal/logger"
 )
 
-// NotificationManager handles backup/restore notifications
+// notifyTimeout bounds how long the channels have to deliver an event
+const notifyTimeout = 10 * time.Second
+
+// NotificationManager handles backup/restore notifications. Each event is
+// sent to every configured channel whose minimum severity it reaches.
 type NotificationManager struct {
-	config config.BackupConfig
+	config    config.BackupConfig
+	routes    []route
+	templates map[EventType]*template.Template
+}
+
+// route is a channel with the least severe events it receives
+type route struct {
+	channel     channel
+	minSeverity Severity
 }
 
 // SlackMessage represents a Slack webhook message
@@ -55,175 +70,142 @@ type BackupMetadata struct {
 
 // NewNotificationManager creates a new notification manager
 func NewNotificationManager(config config.BackupConfig) *NotificationManager {
-	return &NotificationManager{
-		config: config,
+	nm := &NotificationManager{
+		config:    config,
+		templates: loadTemplates(config.NotifyTemplateDir),
 	}
-}
 
-// NotifyBackupSuccess sends a notification for successful backup
-func (nm *NotificationManager) NotifyBackupSuccess(metadata *BackupMetadata) {
-	if !nm.config.NotifyOnSuccess {
-		return
+	if config.SlackWebhookURL != "" {
+		nm.addRoute(slackChannel{nm: nm, url: config.SlackWebhookURL}, config.SlackMinSeverity)
 	}
-
-	message := fmt.Sprintf("✅ Database backup completed successfully: %s", metadata.Filename)
-
-	// Send to Slack if configured
-	if nm.config.SlackWebhookURL != "" {
-		nm.sendSlackNotification(message, "good", metadata, nil)
+	if config.WebhookURL != "" {
+		nm.addRoute(webhookChannel{nm: nm, url: config.WebhookURL}, config.WebhookMinSeverity)
 	}
-
-	// Send to webhook if configured
-	if nm.config.WebhookURL != "" {
-		nm.sendWebhookNotification("backup.success", metadata, nil)
+	if config.EmailNotifications && config.SMTPConfig != nil {
+		nm.addRoute(emailChannel{config: *config.SMTPConfig, send: sendMail}, config.EmailMinSeverity)
 	}
-
-	logger.Info("Backup success notification sent")
+	return nm
 }
 
-// NotifyBackupFailure sends a notification for failed backup
-func (nm *NotificationManager) NotifyBackupFailure(filename string, err error) {
-	if !nm.config.NotifyOnFailure {
-		return
-	}
-
-	message := fmt.Sprintf("❌ Database backup failed: %s - Error: %v", filename, err)
-
-	// Send to Slack if configured
-	if nm.config.SlackWebhookURL != "" {
-		nm.sendSlackNotification(message, "danger", nil, err)
-	}
-
-	// Send to webhook if configured
-	if nm.config.WebhookURL != "" {
-		nm.sendWebhookNotification("backup.failure", nil, err)
+func (nm *NotificationManager) addRoute(ch channel, minSeverity string) {
+	severity, err := ParseSeverity(minSeverity)
+	if err != nil {
+		logger.Warn("Invalid minimum severity for %s notifications, sending all: %v", ch.Name(), err)
 	}
-
-	logger.Info("Backup failure notification sent")
+	nm.routes = append(nm.routes, route{channel: ch, minSeverity: severity})
 }
 
-// NotifyRestoreSuccess sends a notification for successful restore
-func (nm *NotificationManager) NotifyRestoreSuccess(filename string, duration time.Duration) {
-	if !nm.config.NotifyOnSuccess {
+// Notify renders the event and sends it to the channels it is severe enough
+// for, in parallel, returning once every channel is done. Successes are
+// sent only with BACKUP_NOTIFY_SUCCESS, failures and validation issues
+// only with BACKUP_NOTIFY_FAILURE.
+func (nm *NotificationManager) Notify(event Event) {
+	if event.Type.succeeded() && !nm.config.NotifyOnSuccess {
 		return
 	}
-
-	message := fmt.Sprintf("✅ Database restore completed successfully from: %s (duration: %v)", filename, duration)
-
-	// Send to Slack if configured
-	if nm.config.SlackWebhookURL != "" {
-		nm.sendSlackNotification(message, "good", nil, nil)
-	}
-
-	// Send to webhook if configured
-	if nm.config.WebhookURL != "" {
-		nm.sendWebhookNotification("restore.success", map[string]interface{}{
-			"filename": filename,
-			"duration": duration.String(),
-		}, nil)
-	}
-
-	logger.Info("Restore success notification sent")
-}
-
-// NotifyRestoreFailure sends a notification for failed restore
-func (nm *NotificationManager) NotifyRestoreFailure(filename string, err error) {
-	if !nm.config.NotifyOnFailure {
+	if !event.Type.succeeded() && !nm.config.NotifyOnFailure {
 		return
 	}
-
-	message := fmt.Sprintf("❌ Database restore failed from: %s - Error: %v", filename, err)
-
-	// Send to Slack if configured
-	if nm.config.SlackWebhookURL != "" {
-		nm.sendSlackNotification(message, "danger", nil, err)
-	}
-
-	// Send to webhook if configured
-	if nm.config.WebhookURL != "" {
-		nm.sendWebhookNotification("restore.failure", map[string]interface{}{
-			"filename": filename,
-		}, err)
+	if event.At.IsZero() {
+		event.At = time.Now()
 	}
+	event.render(nm.templates)
 
-	logger.Info("Restore failure notification sent")
-}
-
-// sendSlackNotification sends a notification to Slack
-func (nm *NotificationManager) sendSlackNotification(message, color string, metadata *BackupMetadata, err error) {
-	attachment := SlackAttachment{
-		Color:     color,
-		Title:     "TOEIC Database Backup/Restore",
-		Text:      message,
-		Timestamp: time.Now().Unix(),
-	}
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
 
-	// Add metadata fields if available
-	if metadata != nil {
-		attachment.Fields = []SlackField{
-			{Title: "Filename", Value: metadata.Filename, Short: true},
-			{Title: "Size", Value: formatBytes(metadata.Size), Short: true},
-			{Title: "Database", Value: metadata.DatabaseName, Short: true},
-			{Title: "Type", Value: metadata.Type, Short: true},
+	var wg sync.WaitGroup
+	for _, r := range nm.routes {
+		if event.Severity < r.minSeverity {
+			continue
 		}
+		wg.Add(1)
+		go func(ch channel) {
+			defer wg.Done()
+			if err := ch.Send(ctx, event); err != nil {
+				logger.Error("Failed to send %s notification of %s: %v", ch.Name(), event.Type, err)
+				return
+			}
+			logger.Info("Sent %s notification of %s", ch.Name(), event.Type)
+		}(r.channel)
+	}
+	wg.Wait()
+}
 
-		if metadata.Compressed {
-			attachment.Fields = append(attachment.Fields, SlackField{
-				Title: "Compressed", Value: "Yes", Short: true,
-			})
-		}
-
-		if metadata.Encrypted {
-			attachment.Fields = append(attachment.Fields, SlackField{
-				Title: "Encrypted", Value: "Yes", Short: true,
-			})
-		}
-	}
-
-	// Add error details if present
-	if err != nil {
-		attachment.Fields = append(attachment.Fields, SlackField{
-			Title: "Error", Value: err.Error(), Short: false,
-		})
-	}
+// NotifyBackupSuccess sends a notification for successful backup
+func (nm *NotificationManager) NotifyBackupSuccess(metadata *BackupMetadata, duration time.Duration) {
+	nm.Notify(Event{
+		Type:     EventBackupSuccess,
+		Severity: SeverityInfo,
+		Filename: metadata.Filename,
+		Backup:   metadata,
+		Duration: duration,
+	})
+}
 
-	slackMsg := SlackMessage{
-		Username:    "Backup Bot",
-		IconEmoji:   ":floppy_disk:",
-		Attachments: []SlackAttachment{attachment},
-	}
+// NotifyBackupFailure sends a notification for failed backup
+func (nm *NotificationManager) NotifyBackupFailure(filename string, err error) {
+	nm.Notify(Event{
+		Type:     EventBackupFailure,
+		Severity: SeverityCritical,
+		Filename: filename,
+		Error:    errorText(err),
+	})
+}
 
-	// Send the message
-	if err := nm.sendHTTPNotification(nm.config.SlackWebhookURL, slackMsg); err != nil {
-		logger.Error("Failed to send Slack notification: %v", err)
-	}
+// NotifyRestoreSuccess sends a notification for successful restore. A
+// restore with warnings, such as a failed post-restore check, is reported
+// as a warning.
+func (nm *NotificationManager) NotifyRestoreSuccess(filename string, duration time.Duration, warnings []string) {
+	severity := SeverityInfo
+	if len(warnings) > 0 {
+		severity = SeverityWarning
+	}
+	nm.Notify(Event{
+		Type:     EventRestoreSuccess,
+		Severity: severity,
+		Filename: filename,
+		Duration: duration,
+		Details:  warnings,
+	})
 }
 
-// sendWebhookNotification sends a notification to a generic webhook
-func (nm *NotificationManager) sendWebhookNotification(event string, data interface{}, err error) {
-	payload := map[string]interface{}{
-		"event":     event,
-		"timestamp": time.Now().Unix(),
-		"data":      data,
-	}
+// NotifyRestoreFailure sends a notification for failed restore
+func (nm *NotificationManager) NotifyRestoreFailure(filename string, err error) {
+	nm.Notify(Event{
+		Type:     EventRestoreFailure,
+		Severity: SeverityCritical,
+		Filename: filename,
+		Error:    errorText(err),
+	})
+}
 
-	if err != nil {
-		payload["error"] = err.Error()
-	}
+// NotifyValidationIssues sends a notification for a backup that failed
+// validation or restore verification
+func (nm *NotificationManager) NotifyValidationIssues(filename string, issues []string) {
+	nm.Notify(Event{
+		Type:     EventValidationFailure,
+		Severity: SeverityWarning,
+		Filename: filename,
+		Details:  issues,
+	})
+}
 
-	if err := nm.sendHTTPNotification(nm.config.WebhookURL, payload); err != nil {
-		logger.Error("Failed to send webhook notification: %v", err)
+func errorText(err error) string {
+	if err == nil {
+		return ""
 	}
+	return err.Error()
 }
 
 // sendHTTPNotification sends an HTTP POST notification
-func (nm *NotificationManager) sendHTTPNotification(url string, payload interface{}) error {
+func (nm *NotificationManager) sendHTTPNotification(ctx context.Context, url string, payload interface{}) error {
 	jsonData, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("failed to marshal notification payload: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return fmt.Errorf("failed to create notification request: %w", err)
 	}
@@ -232,7 +214,7 @@ func (nm *NotificationManager) sendHTTPNotification(url string, payload interfac
 	req.Header.Set("User-Agent", "TOEIC-Backup-Manager/1.0")
 
 	client := &http.Client{
-		Timeout: 10 * time.Second,
+		Timeout: notifyTimeout,
 	}
 
 	resp, err := client.Do(req)
@@ -0,0 +1,162 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toeic-app/internal/config"
+)
+
+// recorder collects what the channels were sent
+type recorder struct {
+	mu     sync.Mutex
+	slack  []SlackMessage
+	hooks  []map[string]interface{}
+	emails []string
+}
+
+func (r *recorder) server(t *testing.T, handle func(body []byte)) string {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var body json.RawMessage
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+		r.mu.Lock()
+		handle(body)
+		r.mu.Unlock()
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func (r *recorder) sendMail(_ context.Context, _ string, _ smtp.Auth, _ string, _ []string, msg []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.emails = append(r.emails, string(msg))
+	return nil
+}
+
+func newTestManager(t *testing.T, cfg config.BackupConfig) (*NotificationManager, *recorder) {
+	rec := &recorder{}
+	cfg.SlackWebhookURL = rec.server(t, func(body []byte) {
+		var msg SlackMessage
+		require.NoError(t, json.Unmarshal(body, &msg))
+		rec.slack = append(rec.slack, msg)
+	})
+	cfg.WebhookURL = rec.server(t, func(body []byte) {
+		var payload map[string]interface{}
+		require.NoError(t, json.Unmarshal(body, &payload))
+		rec.hooks = append(rec.hooks, payload)
+	})
+	cfg.EmailNotifications = true
+	cfg.SMTPConfig = &config.SMTPNotifyConfig{Host: "mail.test", Port: 587, From: "backups@toeic-app.com", To: []string{"ops@toeic-app.com"}}
+
+	nm := NewNotificationManager(cfg)
+	require.Len(t, nm.routes, 3)
+	email := nm.routes[2].channel.(emailChannel)
+	email.send = rec.sendMail
+	nm.routes[2].channel = email
+	return nm, rec
+}
+
+func TestNotifyFiltersChannelsBySeverity(t *testing.T) {
+	nm, rec := newTestManager(t, config.BackupConfig{
+		NotifyOnSuccess:    true,
+		NotifyOnFailure:    true,
+		SlackMinSeverity:   "info",
+		WebhookMinSeverity: "critical",
+		EmailMinSeverity:   "warning",
+	})
+
+	nm.NotifyBackupSuccess(&BackupMetadata{Filename: "daily_backup.sql.gz", Size: 2048, Compressed: true}, time.Minute)
+	assert.Len(t, rec.slack, 1)
+	assert.Empty(t, rec.hooks)
+	assert.Empty(t, rec.emails)
+	attachment := rec.slack[0].Attachments[0]
+	assert.Equal(t, "good", attachment.Color)
+	assert.Equal(t, "✅ Database backup completed successfully: daily_backup.sql.gz (2.0 KB) in 1m0s", attachment.Text)
+
+	nm.NotifyValidationIssues("daily_backup.sql.gz", []string{"Checksum mismatch - file may be corrupted"})
+	assert.Len(t, rec.slack, 2)
+	assert.Empty(t, rec.hooks)
+	require.Len(t, rec.emails, 1)
+	assert.Contains(t, rec.emails[0], "Subject: [WARNING] Backup validation failed: daily_backup.sql.gz\r\n")
+	assert.Contains(t, rec.emails[0], "\r\n- Checksum mismatch - file may be corrupted\r\n")
+
+	nm.NotifyRestoreFailure("daily_backup.sql.gz", errors.New("psql exited with status 3"))
+	assert.Len(t, rec.slack, 3)
+	assert.Len(t, rec.emails, 2)
+	require.Len(t, rec.hooks, 1)
+	assert.Equal(t, "restore.failure", rec.hooks[0]["event"])
+	assert.Equal(t, "critical", rec.hooks[0]["severity"])
+	assert.Equal(t, "psql exited with status 3", rec.hooks[0]["error"])
+	assert.Equal(t, "danger", rec.slack[2].Attachments[0].Color)
+}
+
+func TestNotifyHonorsSuccessAndFailureSwitches(t *testing.T) {
+	nm, rec := newTestManager(t, config.BackupConfig{NotifyOnFailure: true})
+
+	nm.NotifyRestoreSuccess("daily_backup.sql", time.Second, nil)
+	assert.Empty(t, rec.slack)
+
+	nm.NotifyBackupFailure("", errors.New("disk full"))
+	assert.Len(t, rec.slack, 1)
+	assert.Len(t, rec.hooks, 1)
+	// Without a minimum severity a channel gets every event
+	assert.Len(t, rec.emails, 1)
+
+	nm.config.NotifyOnFailure = false
+	nm.NotifyBackupFailure("", errors.New("disk full"))
+	assert.Len(t, rec.slack, 1)
+}
+
+func TestRestoreWithWarningsIsAWarning(t *testing.T) {
+	nm, rec := newTestManager(t, config.BackupConfig{NotifyOnSuccess: true, EmailMinSeverity: "warning"})
+
+	nm.NotifyRestoreSuccess("daily_backup.sql", time.Second, nil)
+	assert.Empty(t, rec.emails)
+
+	nm.NotifyRestoreSuccess("daily_backup.sql", time.Second, []string{"Post-restore validation failed"})
+	require.Len(t, rec.emails, 1)
+	assert.Equal(t, "warning", rec.slack[1].Attachments[0].Color)
+	assert.Contains(t, rec.emails[0], "(duration: 1s)\r\n- Post-restore validation failed")
+}
+
+func TestTemplatesFromDirectory(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "backup.failure.tmpl"),
+		[]byte("{{upper .Severity.String}}: backup {{.Filename}} broke ({{.Error}})\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "restore.failure.tmpl"), []byte("{{.Filename"), 0600))
+	templates := loadTemplates(dir)
+
+	event := Event{Type: EventBackupFailure, Severity: SeverityCritical, Filename: "daily_backup.sql", Error: "disk full"}
+	event.render(templates)
+	assert.Equal(t, "CRITICAL: backup daily_backup.sql broke (disk full)", event.Message)
+	assert.Equal(t, "[CRITICAL] Backup failed: daily_backup.sql", event.Subject)
+
+	// A template that does not parse keeps the default
+	event = Event{Type: EventRestoreFailure, Severity: SeverityCritical, Filename: "daily_backup.sql", Error: "disk full"}
+	event.render(templates)
+	assert.Equal(t, "❌ Database restore failed from: daily_backup.sql - Error: disk full", event.Message)
+}
+
+func TestParseSeverity(t *testing.T) {
+	for text, want := range map[string]Severity{"": SeverityInfo, "info": SeverityInfo, "Warning": SeverityWarning, "critical": SeverityCritical} {
+		got, err := ParseSeverity(text)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+		assert.True(t, strings.EqualFold(got.String(), text) || text == "")
+	}
+	_, err := ParseSeverity("urgent")
+	assert.Error(t, err)
+}
@@ -0,0 +1,106 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/toeic-app/internal/lti"
+)
+
+// keySetTTL is how long fetched provider keys are trusted before refetching
+const keySetTTL = time.Hour
+
+// maxJWKSBytes bounds the key set read from a provider
+const maxJWKSBytes = 1 << 20
+
+// keySet is a fetched provider key set
+type keySet struct {
+	keys      map[string]*rsa.PublicKey
+	fetchedAt time.Time
+}
+
+// keyFetcher caches the public keys of providers by JWKS URL
+type keyFetcher struct {
+	httpClient *http.Client
+
+	mu   sync.Mutex
+	sets map[string]*keySet
+}
+
+func newKeyFetcher(httpClient *http.Client) *keyFetcher {
+	return &keyFetcher{
+		httpClient: httpClient,
+		sets:       make(map[string]*keySet),
+	}
+}
+
+// Key returns the provider key with the ID, refetching the key set when it is
+// stale or does not contain the key, as providers rotate keys
+func (f *keyFetcher) Key(ctx context.Context, jwksURL, kid string) (*rsa.PublicKey, error) {
+	f.mu.Lock()
+	set := f.sets[jwksURL]
+	f.mu.Unlock()
+
+	if set != nil && time.Since(set.fetchedAt) < keySetTTL {
+		if key, ok := set.keys[kid]; ok {
+			return key, nil
+		}
+	}
+
+	set, err := f.fetch(ctx, jwksURL)
+	if err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	f.sets[jwksURL] = set
+	f.mu.Unlock()
+
+	key, ok := set.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("provider key %q not found", kid)
+	}
+	return key, nil
+}
+
+func (f *keyFetcher) fetch(ctx context.Context, jwksURL string) (*keySet, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", jwksURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch provider keys: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("provider keys returned status %d", resp.StatusCode)
+	}
+
+	// Provider key sets have the same shape as the LTI ones
+	var jwks lti.JWKS
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxJWKSBytes)).Decode(&jwks); err != nil {
+		return nil, fmt.Errorf("failed to decode provider keys: %w", err)
+	}
+
+	set := &keySet{keys: make(map[string]*rsa.PublicKey), fetchedAt: time.Now()}
+	for _, jwk := range jwks.Keys {
+		if jwk.Kty != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+		key, err := jwk.PublicKey()
+		if err != nil {
+			continue
+		}
+		set.keys[jwk.Kid] = key
+	}
+	return set, nil
+}
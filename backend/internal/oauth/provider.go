@@ -0,0 +1,114 @@
+// Package oauth signs users in with their Google or Apple account. Clients
+// obtain an ID token, or an authorization code exchanged for one, from the
+// provider; the token is verified against the provider keys and the account
+// it names is linked to a local user. Accounts are linked on sign-up or by
+// the signed-in user, never by email, so a provider account cannot take over
+// an account registered with a password.
+package oauth
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// Providers users sign in with
+const (
+	ProviderGoogle = "google"
+	ProviderApple  = "apple"
+)
+
+// appleClientSecretTTL is how long a generated Apple client secret is valid.
+// A secret is generated for each code exchange, so it only needs to outlive
+// the request.
+const appleClientSecretTTL = 5 * time.Minute
+
+// Provider is an OpenID Connect provider users sign in with
+type Provider struct {
+	Name     string
+	Issuers  []string // Accepted iss claims
+	JWKSURL  string   // Keys ID tokens are signed with
+	TokenURL string   // Endpoint authorization codes are exchanged at
+	// ClientIDs are the client IDs of the app's web and mobile clients; ID
+	// tokens must be issued to one of them. Codes are exchanged with the first.
+	ClientIDs []string
+	// ClientSecret returns the secret codes are exchanged with; nil when
+	// codes cannot be exchanged
+	ClientSecret func(now time.Time) (string, error)
+}
+
+// Google returns the Google provider. An empty secret disables exchanging
+// authorization codes.
+func Google(clientIDs []string, clientSecret string) Provider {
+	provider := Provider{
+		Name:      ProviderGoogle,
+		Issuers:   []string{"https://accounts.google.com", "accounts.google.com"},
+		JWKSURL:   "https://www.googleapis.com/oauth2/v3/certs",
+		TokenURL:  "https://oauth2.googleapis.com/token",
+		ClientIDs: clientIDs,
+	}
+	if clientSecret != "" {
+		provider.ClientSecret = func(time.Time) (string, error) { return clientSecret, nil }
+	}
+	return provider
+}
+
+// Apple returns the Apple provider. Apple client secrets are JWTs signed with
+// a key of the developer team; a nil key disables exchanging authorization
+// codes.
+func Apple(clientIDs []string, teamID, keyID string, key *ecdsa.PrivateKey) Provider {
+	provider := Provider{
+		Name:      ProviderApple,
+		Issuers:   []string{"https://appleid.apple.com"},
+		JWKSURL:   "https://appleid.apple.com/auth/keys",
+		TokenURL:  "https://appleid.apple.com/auth/token",
+		ClientIDs: clientIDs,
+	}
+	if key != nil && len(clientIDs) > 0 {
+		clientID := clientIDs[0]
+		provider.ClientSecret = func(now time.Time) (string, error) {
+			token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.StandardClaims{
+				Issuer:    teamID,
+				Subject:   clientID,
+				Audience:  "https://appleid.apple.com",
+				IssuedAt:  now.Unix(),
+				ExpiresAt: now.Add(appleClientSecretTTL).Unix(),
+			})
+			token.Header["kid"] = keyID
+			secret, err := token.SignedString(key)
+			if err != nil {
+				return "", fmt.Errorf("failed to sign Apple client secret: %w", err)
+			}
+			return secret, nil
+		}
+	}
+	return provider
+}
+
+// LoadAppleKey reads the PKCS #8 private key downloaded from the Apple
+// developer account, a .p8 file
+func LoadAppleKey(path string) (*ecdsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Apple key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("Apple key is not PEM encoded")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Apple key: %w", err)
+	}
+	key, ok := parsed.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("Apple key is not an ECDSA key")
+	}
+	return key, nil
+}
@@ -0,0 +1,480 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dgrijalva/jwt-go"
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/logger"
+	"github.com/toeic-app/internal/util"
+)
+
+var (
+	ErrUnknownProvider = errors.New("sign-in provider is unknown or disabled")
+	ErrNoCredentials   = errors.New("an ID token or an authorization code is required")
+	ErrCodeUnsupported = errors.New("authorization codes are not accepted for this provider")
+	ErrInvalidToken    = errors.New("invalid provider token")
+	ErrEmailRegistered = errors.New("an account is already registered with this email; sign in with your password and link the provider from your account")
+	ErrLinkedToOther   = errors.New("the provider account is linked to another user")
+	ErrProviderLinked  = errors.New("another account of this provider is already linked")
+	ErrNotLinked       = errors.New("the provider account is not linked to a user")
+)
+
+const (
+	// clockSkew is tolerated between provider and app clocks
+	clockSkew = time.Minute
+	// maxUsernameLength matches the limit of registration
+	maxUsernameLength = 50
+	// maxTokenResponseBytes bounds the token response read from a provider
+	maxTokenResponseBytes = 1 << 20
+)
+
+// providerTitles name providers in the usernames of users who shared no name
+var providerTitles = map[string]string{
+	ProviderGoogle: "Google",
+	ProviderApple:  "Apple",
+}
+
+// Credentials prove a provider account: an ID token the client obtained, or
+// an authorization code exchanged for one
+type Credentials struct {
+	IDToken     string
+	Code        string
+	RedirectURI string // Redirect URI the code was issued for
+	Nonce       string // Nonce the client sent the provider; checked when set
+}
+
+// Identity is the provider account a verified ID token names
+type Identity struct {
+	Provider      string `json:"provider" example:"google"`
+	Subject       string `json:"-"`
+	Email         string `json:"email,omitempty" example:"learner@gmail.com"` // Empty when the user shared none
+	EmailVerified bool   `json:"email_verified"`
+	Name          string `json:"-"`
+}
+
+// SignIn is the local user a provider account signed in
+type SignIn struct {
+	UserID  int32
+	Created bool // The account was created by this sign-in
+}
+
+// Service verifies provider tokens and links provider accounts to users
+type Service struct {
+	store      db.Querier
+	providers  map[string]Provider
+	keys       *keyFetcher
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+// NewService creates an OAuth service for the providers. Providers without
+// client IDs are disabled.
+func NewService(store db.Querier, providers ...Provider) *Service {
+	httpClient := &http.Client{Timeout: 15 * time.Second}
+	s := &Service{
+		store:      store,
+		providers:  make(map[string]Provider),
+		keys:       newKeyFetcher(httpClient),
+		httpClient: httpClient,
+		now:        time.Now,
+	}
+	for _, provider := range providers {
+		if len(provider.ClientIDs) > 0 {
+			s.providers[provider.Name] = provider
+		}
+	}
+	return s
+}
+
+// Providers lists the enabled providers
+func (s *Service) Providers() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Verify returns the provider account credentials prove, exchanging an
+// authorization code for an ID token when no ID token is given
+func (s *Service) Verify(ctx context.Context, name string, credentials Credentials) (*Identity, error) {
+	provider, ok := s.providers[name]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	idToken := credentials.IDToken
+	if idToken == "" {
+		if credentials.Code == "" {
+			return nil, ErrNoCredentials
+		}
+		var err error
+		if idToken, err = s.exchange(ctx, provider, credentials); err != nil {
+			return nil, err
+		}
+	}
+	return s.verifyIDToken(ctx, provider, idToken, credentials.Nonce)
+}
+
+// LinkedUser returns the user linked to the provider account of credentials
+// without writing anything, or ErrNotLinked
+func (s *Service) LinkedUser(ctx context.Context, name string, credentials Credentials) (int32, error) {
+	identity, err := s.Verify(ctx, name, credentials)
+	if err != nil {
+		return 0, err
+	}
+	userID, err := s.store.GetUserIdentity(ctx, db.GetUserIdentityParams{Provider: identity.Provider, Subject: identity.Subject})
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrNotLinked
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get linked user: %w", err)
+	}
+	return userID, nil
+}
+
+// SignIn returns the user linked to the provider account of credentials,
+// creating one on first sign-in. displayName names the new user when the
+// token has no name, as Apple shares it with the client only.
+//
+// A new user gets the email of the account only when the provider verified
+// it. When that email is already registered, ErrEmailRegistered is returned
+// rather than linking: the owner signs in with their password and links the
+// provider, so a provider account never takes over an existing account.
+func (s *Service) SignIn(ctx context.Context, name string, credentials Credentials, displayName string) (SignIn, error) {
+	identity, err := s.Verify(ctx, name, credentials)
+	if err != nil {
+		return SignIn{}, err
+	}
+
+	key := db.GetUserIdentityParams{Provider: identity.Provider, Subject: identity.Subject}
+	userID, err := s.store.GetUserIdentity(ctx, key)
+	if err == nil {
+		if err := s.store.TouchUserIdentity(ctx, db.TouchUserIdentityParams(key)); err != nil {
+			logger.Warn("Failed to record %s sign-in of user %d: %v", identity.Provider, userID, err)
+		}
+		return SignIn{UserID: userID}, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return SignIn{}, fmt.Errorf("failed to get linked user: %w", err)
+	}
+
+	email := ""
+	if identity.EmailVerified && util.IsValidEmail(identity.Email) {
+		email = identity.Email
+		if _, err := s.store.GetUserByEmail(ctx, sql.NullString{String: email, Valid: true}); err == nil {
+			return SignIn{}, ErrEmailRegistered
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return SignIn{}, fmt.Errorf("failed to check email: %w", err)
+		}
+	} else {
+		// An unverified address may belong to someone else
+		email = placeholderEmail(identity)
+	}
+
+	// Provider users sign in through their provider, so the password is unusable
+	secret, err := randomToken()
+	if err != nil {
+		return SignIn{}, err
+	}
+	passwordHash, err := util.HashPassword(secret)
+	if err != nil {
+		return SignIn{}, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	user, err := s.store.CreateUser(ctx, db.CreateUserParams{
+		Username:     username(identity, displayName),
+		Email:        sql.NullString{String: email, Valid: true},
+		PasswordHash: passwordHash,
+	})
+	if err != nil {
+		return SignIn{}, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	rows, err := s.store.CreateUserIdentity(ctx, db.CreateUserIdentityParams{
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		UserID:   user.ID,
+	})
+	if err != nil {
+		return SignIn{}, fmt.Errorf("failed to link user: %w", err)
+	}
+	if rows == 0 {
+		// A concurrent sign-in linked the account first
+		if err := s.store.DeleteUser(ctx, user.ID); err != nil {
+			return SignIn{}, fmt.Errorf("failed to delete duplicate user: %w", err)
+		}
+		userID, err := s.store.GetUserIdentity(ctx, key)
+		if err != nil {
+			return SignIn{}, fmt.Errorf("failed to get linked user: %w", err)
+		}
+		return SignIn{UserID: userID}, nil
+	}
+
+	return SignIn{UserID: user.ID, Created: true}, nil
+}
+
+// Link links the provider account of credentials to a signed-in user, who
+// may link one account of each provider. Linking an account already linked
+// to the user succeeds.
+func (s *Service) Link(ctx context.Context, name string, userID int32, credentials Credentials) (*Identity, error) {
+	identity, err := s.Verify(ctx, name, credentials)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkLink(ctx, identity, userID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.store.CreateUserIdentity(ctx, db.CreateUserIdentityParams{
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		UserID:   userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to link account: %w", err)
+	}
+	if rows == 0 {
+		// A concurrent request linked the account or the provider first
+		if err := s.checkLink(ctx, identity, userID); err != nil {
+			return nil, err
+		}
+	}
+	return identity, nil
+}
+
+// checkLink returns an error when the provider account is linked to another
+// user or the user linked another account of the provider
+func (s *Service) checkLink(ctx context.Context, identity *Identity, userID int32) error {
+	linked, err := s.store.GetUserIdentity(ctx, db.GetUserIdentityParams{Provider: identity.Provider, Subject: identity.Subject})
+	switch {
+	case err == nil && linked == userID:
+		return nil
+	case err == nil:
+		return ErrLinkedToOther
+	case !errors.Is(err, sql.ErrNoRows):
+		return fmt.Errorf("failed to get linked user: %w", err)
+	}
+
+	_, err = s.store.GetUserIdentityByUser(ctx, db.GetUserIdentityByUserParams{UserID: userID, Provider: identity.Provider})
+	if err == nil {
+		return ErrProviderLinked
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to get linked account: %w", err)
+	}
+	return nil
+}
+
+// exchange exchanges an authorization code for an ID token at the token
+// endpoint of the provider
+func (s *Service) exchange(ctx context.Context, provider Provider, credentials Credentials) (string, error) {
+	if provider.ClientSecret == nil {
+		return "", ErrCodeUnsupported
+	}
+	secret, err := provider.ClientSecret(s.now())
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {credentials.Code},
+		"client_id":     {provider.ClientIDs[0]},
+		"client_secret": {secret},
+	}
+	if credentials.RedirectURI != "" {
+		form.Set("redirect_uri", credentials.RedirectURI)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", provider.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		IDToken string `json:"id_token"`
+		Error   string `json:"error"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxTokenResponseBytes)).Decode(&body); err != nil && resp.StatusCode == http.StatusOK {
+		return "", fmt.Errorf("failed to decode token response: %w", err)
+	}
+	switch {
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return "", fmt.Errorf("token endpoint returned status %d", resp.StatusCode)
+	case resp.StatusCode != http.StatusOK:
+		// The code is invalid, expired, already used or for another client
+		return "", fmt.Errorf("%w: authorization code rejected: %s", ErrInvalidToken, body.Error)
+	case body.IDToken == "":
+		return "", fmt.Errorf("%w: token response has no ID token", ErrInvalidToken)
+	}
+	return body.IDToken, nil
+}
+
+// verifyIDToken checks the signature and standard claims of an ID token
+// issued by the provider
+func (s *Service) verifyIDToken(ctx context.Context, provider Provider, idToken, nonce string) (*Identity, error) {
+	parser := &jwt.Parser{ValidMethods: []string{"RS256"}, SkipClaimsValidation: true}
+	mapClaims := jwt.MapClaims{}
+	_, err := parser.ParseWithClaims(idToken, mapClaims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return s.keys.Key(ctx, provider.JWKSURL, kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	encoded, err := json.Marshal(mapClaims)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode claims: %w", err)
+	}
+	var claims idTokenClaims
+	if err := json.Unmarshal(encoded, &claims); err != nil {
+		return nil, fmt.Errorf("%w: malformed claims: %v", ErrInvalidToken, err)
+	}
+
+	now := s.now()
+	switch {
+	case !slices.Contains(provider.Issuers, claims.Issuer):
+		return nil, fmt.Errorf("%w: unexpected issuer", ErrInvalidToken)
+	case !claims.hasAudience(provider.ClientIDs):
+		return nil, fmt.Errorf("%w: token is not for this app", ErrInvalidToken)
+	case claims.ExpiresAt == 0 || now.After(time.Unix(claims.ExpiresAt, 0).Add(clockSkew)):
+		return nil, fmt.Errorf("%w: token expired", ErrInvalidToken)
+	case claims.IssuedAt != 0 && time.Unix(claims.IssuedAt, 0).After(now.Add(clockSkew)):
+		return nil, fmt.Errorf("%w: token issued in the future", ErrInvalidToken)
+	case claims.Subject == "":
+		return nil, fmt.Errorf("%w: token names no account", ErrInvalidToken)
+	case nonce != "" && claims.Nonce != nonce:
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidToken)
+	}
+
+	return &Identity{
+		Provider:      provider.Name,
+		Subject:       claims.Subject,
+		Email:         strings.TrimSpace(claims.Email),
+		EmailVerified: bool(claims.EmailVerified),
+		Name:          strings.TrimSpace(claims.Name),
+	}, nil
+}
+
+// idTokenClaims are the claims of a provider ID token
+type idTokenClaims struct {
+	Issuer          string   `json:"iss"`
+	Subject         string   `json:"sub"`
+	Audience        audience `json:"aud"`
+	AuthorizedParty string   `json:"azp"`
+	ExpiresAt       int64    `json:"exp"`
+	IssuedAt        int64    `json:"iat"`
+	Nonce           string   `json:"nonce"`
+	Email           string   `json:"email"`
+	EmailVerified   flag     `json:"email_verified"`
+	Name            string   `json:"name"`
+}
+
+// hasAudience checks the audience is one of the client IDs, and the
+// authorized party when the token has several audiences
+func (c *idTokenClaims) hasAudience(clientIDs []string) bool {
+	found := false
+	for _, aud := range c.Audience {
+		if slices.Contains(clientIDs, aud) {
+			found = true
+		}
+	}
+	if !found {
+		return false
+	}
+	if len(c.Audience) > 1 {
+		return slices.Contains(clientIDs, c.AuthorizedParty)
+	}
+	return true
+}
+
+// audience is a string or a list of strings
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+// flag is a boolean claim, which Apple sends as a string
+type flag bool
+
+func (f *flag) UnmarshalJSON(data []byte) error {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	switch v := value.(type) {
+	case bool:
+		*f = flag(v)
+	case string:
+		*f = flag(strings.EqualFold(v, "true"))
+	default:
+		*f = false
+	}
+	return nil
+}
+
+// username names a new user after the account, the name the client shared,
+// or the email, in that order
+func username(identity *Identity, displayName string) string {
+	local, _, _ := strings.Cut(identity.Email, "@")
+	for _, candidate := range []string{identity.Name, displayName, local} {
+		if candidate = strings.TrimSpace(candidate); candidate == "" {
+			continue
+		}
+		if utf8.RuneCountInString(candidate) > maxUsernameLength {
+			candidate = string([]rune(candidate)[:maxUsernameLength])
+		}
+		return candidate
+	}
+	return providerTitles[identity.Provider] + " user"
+}
+
+// placeholderEmail is a stable undeliverable address for provider users
+// without a verified email
+func placeholderEmail(identity *Identity) string {
+	sum := sha256.Sum256([]byte(identity.Subject))
+	return fmt.Sprintf("%s-%s@oauth.invalid", identity.Provider, hex.EncodeToString(sum[:8]))
+}
+
+func randomToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate random token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,365 @@
+package oauth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/lti"
+)
+
+type identityKey struct {
+	provider string
+	subject  string
+}
+
+// oauthStore keeps users and linked accounts in memory; other Querier
+// methods are not used
+type oauthStore struct {
+	db.Querier
+	mu         sync.Mutex
+	users      map[int32]db.User
+	identities map[identityKey]int32
+	nextUserID int32
+}
+
+func newOAuthStore() *oauthStore {
+	return &oauthStore{users: make(map[int32]db.User), identities: make(map[identityKey]int32)}
+}
+
+func (s *oauthStore) GetUserIdentity(ctx context.Context, arg db.GetUserIdentityParams) (int32, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	userID, ok := s.identities[identityKey{arg.Provider, arg.Subject}]
+	if !ok {
+		return 0, sql.ErrNoRows
+	}
+	return userID, nil
+}
+
+func (s *oauthStore) GetUserIdentityByUser(ctx context.Context, arg db.GetUserIdentityByUserParams) (db.UserIdentity, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, userID := range s.identities {
+		if userID == arg.UserID && key.provider == arg.Provider {
+			return db.UserIdentity{Provider: key.provider, Subject: key.subject, UserID: userID}, nil
+		}
+	}
+	return db.UserIdentity{}, sql.ErrNoRows
+}
+
+func (s *oauthStore) CreateUserIdentity(ctx context.Context, arg db.CreateUserIdentityParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := identityKey{arg.Provider, arg.Subject}
+	if _, ok := s.identities[key]; ok {
+		return 0, nil
+	}
+	for other, userID := range s.identities {
+		if userID == arg.UserID && other.provider == arg.Provider {
+			return 0, nil
+		}
+	}
+	s.identities[key] = arg.UserID
+	return 1, nil
+}
+
+func (s *oauthStore) TouchUserIdentity(ctx context.Context, arg db.TouchUserIdentityParams) error {
+	return nil
+}
+
+func (s *oauthStore) GetUserByEmail(ctx context.Context, email sql.NullString) (db.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, u := range s.users {
+		if u.Email == email {
+			return u, nil
+		}
+	}
+	return db.User{}, sql.ErrNoRows
+}
+
+func (s *oauthStore) CreateUser(ctx context.Context, arg db.CreateUserParams) (db.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextUserID++
+	user := db.User{ID: s.nextUserID, Username: arg.Username, Email: arg.Email, PasswordHash: arg.PasswordHash}
+	s.users[user.ID] = user
+	return user, nil
+}
+
+func (s *oauthStore) DeleteUser(ctx context.Context, id int32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.users, id)
+	return nil
+}
+
+// fakeProvider is an identity provider serving its keys and a token endpoint
+type fakeProvider struct {
+	server *httptest.Server
+	key    *lti.ToolKey
+	signer *rsa.PrivateKey
+	// codes are the authorization codes the token endpoint exchanges
+	codes map[string]string
+}
+
+func newFakeProvider(t *testing.T) *fakeProvider {
+	signer, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	p := &fakeProvider{key: lti.NewToolKey(signer), signer: signer, codes: make(map[string]string)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(p.key.JWKS())
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		idToken, ok := p.codes[r.Form.Get("code")]
+		if r.Form.Get("grant_type") != "authorization_code" || r.Form.Get("client_secret") != "secret" || !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"access_token": "access", "id_token": idToken})
+	})
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+func (p *fakeProvider) provider(name string) Provider {
+	return Provider{
+		Name:         name,
+		Issuers:      []string{"https://accounts.example.com"},
+		JWKSURL:      p.server.URL + "/keys",
+		TokenURL:     p.server.URL + "/token",
+		ClientIDs:    []string{"web-client", "ios-client"},
+		ClientSecret: func(time.Time) (string, error) { return "secret", nil },
+	}
+}
+
+func (p *fakeProvider) idToken(t *testing.T, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = p.key.KeyID()
+	signed, err := token.SignedString(p.signer)
+	require.NoError(t, err)
+	return signed
+}
+
+func (p *fakeProvider) claims(subject, email string, verified any) jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":            "https://accounts.example.com",
+		"sub":            subject,
+		"aud":            "ios-client",
+		"exp":            now.Add(time.Hour).Unix(),
+		"iat":            now.Unix(),
+		"email":          email,
+		"email_verified": verified,
+	}
+}
+
+func newTestService(t *testing.T) (*Service, *oauthStore, *fakeProvider) {
+	provider := newFakeProvider(t)
+	store := newOAuthStore()
+	return NewService(store, provider.provider(ProviderGoogle), provider.provider(ProviderApple)), store, provider
+}
+
+func TestSignInCreatesAndReusesUser(t *testing.T) {
+	service, store, provider := newTestService(t)
+	ctx := context.Background()
+	claims := provider.claims("google-1", "learner@gmail.com", true)
+	claims["name"] = "Linh Tran"
+
+	first, err := service.SignIn(ctx, ProviderGoogle, Credentials{IDToken: provider.idToken(t, claims)}, "")
+	require.NoError(t, err)
+	assert.True(t, first.Created)
+	user := store.users[first.UserID]
+	assert.Equal(t, "Linh Tran", user.Username)
+	assert.Equal(t, "learner@gmail.com", user.Email.String)
+
+	again, err := service.SignIn(ctx, ProviderGoogle, Credentials{IDToken: provider.idToken(t, claims)}, "")
+	require.NoError(t, err)
+	assert.False(t, again.Created)
+	assert.Equal(t, first.UserID, again.UserID)
+	assert.Len(t, store.users, 1)
+}
+
+func TestSignInNeverLinksByEmail(t *testing.T) {
+	service, store, provider := newTestService(t)
+	ctx := context.Background()
+	existing, err := store.CreateUser(ctx, db.CreateUserParams{Username: "owner", Email: sql.NullString{String: "owner@gmail.com", Valid: true}})
+	require.NoError(t, err)
+
+	// A verified address of an existing account is left to its owner to link
+	idToken := provider.idToken(t, provider.claims("google-1", "owner@gmail.com", true))
+	_, err = service.SignIn(ctx, ProviderGoogle, Credentials{IDToken: idToken}, "")
+	assert.ErrorIs(t, err, ErrEmailRegistered)
+	assert.Empty(t, store.identities)
+
+	// An unverified address is not trusted at all
+	idToken = provider.idToken(t, provider.claims("google-2", "owner@gmail.com", false))
+	result, err := service.SignIn(ctx, ProviderGoogle, Credentials{IDToken: idToken}, "")
+	require.NoError(t, err)
+	assert.NotEqual(t, existing.ID, result.UserID)
+	assert.Contains(t, store.users[result.UserID].Email.String, "@oauth.invalid")
+
+	// Once linked by the owner, the account signs in as the owner
+	idToken = provider.idToken(t, provider.claims("google-1", "owner@gmail.com", true))
+	_, err = service.Link(ctx, ProviderGoogle, existing.ID, Credentials{IDToken: idToken})
+	require.NoError(t, err)
+	result, err = service.SignIn(ctx, ProviderGoogle, Credentials{IDToken: idToken}, "")
+	require.NoError(t, err)
+	assert.Equal(t, existing.ID, result.UserID)
+}
+
+func TestSignInUsesDisplayNameAndStringFlags(t *testing.T) {
+	service, store, provider := newTestService(t)
+
+	// Apple sends email_verified as a string and no name
+	idToken := provider.idToken(t, provider.claims("apple-1", "abc@privaterelay.appleid.com", "true"))
+	result, err := service.SignIn(context.Background(), ProviderApple, Credentials{IDToken: idToken}, "Minh")
+	require.NoError(t, err)
+	user := store.users[result.UserID]
+	assert.Equal(t, "Minh", user.Username)
+	assert.Equal(t, "abc@privaterelay.appleid.com", user.Email.String)
+}
+
+func TestLinkSafeguards(t *testing.T) {
+	service, store, provider := newTestService(t)
+	ctx := context.Background()
+	first, _ := store.CreateUser(ctx, db.CreateUserParams{Username: "first"})
+	second, _ := store.CreateUser(ctx, db.CreateUserParams{Username: "second"})
+	account := Credentials{IDToken: provider.idToken(t, provider.claims("google-1", "", false))}
+
+	identity, err := service.Link(ctx, ProviderGoogle, first.ID, account)
+	require.NoError(t, err)
+	assert.Equal(t, ProviderGoogle, identity.Provider)
+
+	_, err = service.Link(ctx, ProviderGoogle, first.ID, account)
+	assert.NoError(t, err, "linking again succeeds")
+
+	_, err = service.Link(ctx, ProviderGoogle, second.ID, account)
+	assert.ErrorIs(t, err, ErrLinkedToOther)
+
+	other := Credentials{IDToken: provider.idToken(t, provider.claims("google-2", "", false))}
+	_, err = service.Link(ctx, ProviderGoogle, first.ID, other)
+	assert.ErrorIs(t, err, ErrProviderLinked)
+}
+
+func TestVerifyRejectsInvalidTokens(t *testing.T) {
+	service, _, provider := newTestService(t)
+	ctx := context.Background()
+
+	tests := map[string]func(jwt.MapClaims){
+		"other audience": func(c jwt.MapClaims) { c["aud"] = "someone-else" },
+		"other issuer":   func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" },
+		"expired":        func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() },
+		"no subject":     func(c jwt.MapClaims) { delete(c, "sub") },
+		"unauthorized party": func(c jwt.MapClaims) {
+			c["aud"] = []string{"web-client", "someone-else"}
+			c["azp"] = "someone-else"
+		},
+	}
+	for name, change := range tests {
+		t.Run(name, func(t *testing.T) {
+			claims := provider.claims("google-1", "", false)
+			change(claims)
+			_, err := service.Verify(ctx, ProviderGoogle, Credentials{IDToken: provider.idToken(t, claims)})
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		})
+	}
+
+	// Signed by another key
+	forger, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, provider.claims("google-1", "", false))
+	token.Header["kid"] = provider.key.KeyID()
+	forged, err := token.SignedString(forger)
+	require.NoError(t, err)
+	_, err = service.Verify(ctx, ProviderGoogle, Credentials{IDToken: forged})
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	claims := provider.claims("google-1", "", false)
+	claims["nonce"] = "n-1"
+	_, err = service.Verify(ctx, ProviderGoogle, Credentials{IDToken: provider.idToken(t, claims), Nonce: "n-2"})
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	_, err = service.Verify(ctx, ProviderGoogle, Credentials{IDToken: provider.idToken(t, claims), Nonce: "n-1"})
+	assert.NoError(t, err)
+
+	_, err = service.Verify(ctx, "github", Credentials{IDToken: provider.idToken(t, claims)})
+	assert.ErrorIs(t, err, ErrUnknownProvider)
+	_, err = service.Verify(ctx, ProviderGoogle, Credentials{})
+	assert.ErrorIs(t, err, ErrNoCredentials)
+}
+
+func TestVerifyExchangesCodes(t *testing.T) {
+	service, _, provider := newTestService(t)
+	ctx := context.Background()
+	provider.codes["code-1"] = provider.idToken(t, provider.claims("google-1", "", false))
+
+	identity, err := service.Verify(ctx, ProviderGoogle, Credentials{Code: "code-1", RedirectURI: "https://app.example.com/callback"})
+	require.NoError(t, err)
+	assert.Equal(t, "google-1", identity.Subject)
+
+	_, err = service.Verify(ctx, ProviderGoogle, Credentials{Code: "unknown"})
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	withoutSecret := provider.provider(ProviderGoogle)
+	withoutSecret.ClientSecret = nil
+	service = NewService(newOAuthStore(), withoutSecret)
+	_, err = service.Verify(ctx, ProviderGoogle, Credentials{Code: "code-1"})
+	assert.ErrorIs(t, err, ErrCodeUnsupported)
+}
+
+func TestAppleClientSecret(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	provider := Apple([]string{"com.example.web", "com.example.app"}, "TEAM123", "KEY123", key)
+	require.NotNil(t, provider.ClientSecret)
+
+	now := time.Now()
+	secret, err := provider.ClientSecret(now)
+	require.NoError(t, err)
+	claims := jwt.MapClaims{}
+	token, err := jwt.ParseWithClaims(secret, claims, func(*jwt.Token) (interface{}, error) { return &key.PublicKey, nil })
+	require.NoError(t, err)
+	assert.Equal(t, "KEY123", token.Header["kid"])
+	assert.Equal(t, "ES256", token.Header["alg"])
+	assert.Equal(t, "TEAM123", claims["iss"])
+	assert.Equal(t, "com.example.web", claims["sub"])
+	assert.Equal(t, "https://appleid.apple.com", claims["aud"])
+
+	assert.Nil(t, Apple([]string{"com.example.app"}, "TEAM123", "KEY123", nil).ClientSecret)
+	assert.Nil(t, Google([]string{"web-client"}, "").ClientSecret)
+}
+
+func TestLinkedUserWritesNothing(t *testing.T) {
+	service, store, provider := newTestService(t)
+	ctx := context.Background()
+	account := Credentials{IDToken: provider.idToken(t, provider.claims("google-1", "learner@gmail.com", true))}
+
+	_, err := service.LinkedUser(ctx, ProviderGoogle, account)
+	assert.ErrorIs(t, err, ErrNotLinked)
+	assert.Empty(t, store.users)
+
+	result, err := service.SignIn(ctx, ProviderGoogle, account, "")
+	require.NoError(t, err)
+	userID, err := service.LinkedUser(ctx, ProviderGoogle, account)
+	require.NoError(t, err)
+	assert.Equal(t, result.UserID, userID)
+}
@@ -0,0 +1,262 @@
+package pii
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/logger"
+)
+
+// Migration modes
+const (
+	ModeBackfill = "backfill" // Encrypt plaintext values
+	ModeRotate   = "rotate"   // Re-wrap values onto the active key and refresh blind indexes
+	ModeDecrypt  = "decrypt"  // Restore plaintext values, e.g. before rolling back the migration
+)
+
+// MigrateOptions configures a migration run
+type MigrateOptions struct {
+	Mode      string
+	DryRun    bool
+	BatchSize int
+}
+
+// MigrateReport summarizes a migration run for one table
+type MigrateReport struct {
+	Table   string
+	Scanned int
+	Changed int
+	Failed  int
+}
+
+// KeyStatus counts the stored values of a table per key
+type KeyStatus struct {
+	Table     string
+	Plaintext int
+	ByKey     map[string]int
+}
+
+// columnState is the plaintext and encrypted value of a PII column
+type columnState struct {
+	plain     sql.NullString
+	encrypted sql.NullString
+}
+
+// migrateValue returns the new state of a column for the given mode, and whether it changed.
+// The plaintext of the value is returned as well so blind indexes can be computed.
+func (p *Protector) migrateValue(mode string, current columnState) (columnState, string, bool, error) {
+	plaintext := current.plain.String
+	if current.encrypted.Valid {
+		var err error
+		plaintext, err = p.Decrypt(current.encrypted.String)
+		if err != nil {
+			return current, "", false, err
+		}
+	}
+
+	switch mode {
+	case ModeDecrypt:
+		if !current.encrypted.Valid {
+			return current, plaintext, false, nil
+		}
+		return columnState{plain: sql.NullString{String: plaintext, Valid: true}}, plaintext, true, nil
+
+	case ModeBackfill:
+		if current.encrypted.Valid || !current.plain.Valid {
+			return current, plaintext, false, nil
+		}
+		encrypted, err := p.Encrypt(plaintext)
+		if err != nil {
+			return current, "", false, err
+		}
+		return columnState{encrypted: sql.NullString{String: encrypted, Valid: true}}, plaintext, true, nil
+
+	case ModeRotate:
+		if !current.encrypted.Valid {
+			return current, plaintext, false, nil
+		}
+		rewrapped, err := p.Rewrap(current.encrypted.String)
+		if err != nil {
+			return current, "", false, err
+		}
+		next := columnState{encrypted: sql.NullString{String: rewrapped, Valid: true}}
+		return next, plaintext, rewrapped != current.encrypted.String, nil
+	}
+
+	return current, "", false, fmt.Errorf("unsupported migration mode: %s", mode)
+}
+
+// Migrate encrypts, rotates or decrypts the PII columns of all users and profiles
+func (p *Protector) Migrate(ctx context.Context, querier db.Querier, opts MigrateOptions) ([]MigrateReport, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 500
+	}
+
+	reports := make([]MigrateReport, 0, 2)
+	usersReport, err := p.migrateUsers(ctx, querier, opts)
+	reports = append(reports, usersReport)
+	if err != nil {
+		return reports, err
+	}
+
+	profilesReport, err := p.migrateProfiles(ctx, querier, opts)
+	reports = append(reports, profilesReport)
+	return reports, err
+}
+
+func (p *Protector) migrateUsers(ctx context.Context, querier db.Querier, opts MigrateOptions) (MigrateReport, error) {
+	report := MigrateReport{Table: "users"}
+
+	for offset := int32(0); ; offset += int32(opts.BatchSize) {
+		users, err := querier.ListUsers(ctx, db.ListUsersParams{Limit: int32(opts.BatchSize), Offset: offset})
+		if err != nil {
+			return report, fmt.Errorf("failed to list users: %w", err)
+		}
+
+		for _, user := range users {
+			report.Scanned++
+			next, plaintext, changed, err := p.migrateValue(opts.Mode, columnState{plain: user.Email, encrypted: user.EmailEncrypted})
+			if err != nil {
+				logger.Warn("Failed to %s email of user %d: %v", opts.Mode, user.ID, err)
+				report.Failed++
+				continue
+			}
+
+			bidx := sql.NullString{}
+			if next.encrypted.Valid {
+				bidx = sql.NullString{String: p.BlindIndex(plaintext), Valid: true}
+			}
+			// Rotation also refreshes blind indexes after PII_BLIND_INDEX_KEY changes
+			if !changed && bidx == user.EmailBidx {
+				continue
+			}
+
+			report.Changed++
+			if opts.DryRun {
+				continue
+			}
+			err = querier.UpdateUserEmailEncryption(ctx, db.UpdateUserEmailEncryptionParams{
+				ID:             user.ID,
+				Email:          next.plain,
+				EmailEncrypted: next.encrypted,
+				EmailBidx:      bidx,
+			})
+			if err != nil {
+				logger.Warn("Failed to update email of user %d: %v", user.ID, err)
+				report.Changed--
+				report.Failed++
+			}
+		}
+
+		if len(users) < opts.BatchSize {
+			return report, nil
+		}
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+	}
+}
+
+func (p *Protector) migrateProfiles(ctx context.Context, querier db.Querier, opts MigrateOptions) (MigrateReport, error) {
+	report := MigrateReport{Table: "user_profiles"}
+
+	var lastID int32
+	for {
+		profiles, err := querier.ListUserProfilesAfter(ctx, db.ListUserProfilesAfterParams{ID: lastID, Limit: int32(opts.BatchSize)})
+		if err != nil {
+			return report, fmt.Errorf("failed to list user profiles: %w", err)
+		}
+
+		for _, profile := range profiles {
+			lastID = profile.ID
+			report.Scanned++
+			next, _, changed, err := p.migrateValue(opts.Mode, columnState{plain: profile.FullName, encrypted: profile.FullNameEncrypted})
+			if err != nil {
+				logger.Warn("Failed to %s full name of profile %d: %v", opts.Mode, profile.ID, err)
+				report.Failed++
+				continue
+			}
+			if !changed {
+				continue
+			}
+
+			report.Changed++
+			if opts.DryRun {
+				continue
+			}
+			err = querier.UpdateUserProfileFullNameEncryption(ctx, db.UpdateUserProfileFullNameEncryptionParams{
+				ID:                profile.ID,
+				FullName:          next.plain,
+				FullNameEncrypted: next.encrypted,
+			})
+			if err != nil {
+				logger.Warn("Failed to update full name of profile %d: %v", profile.ID, err)
+				report.Changed--
+				report.Failed++
+			}
+		}
+
+		if len(profiles) < opts.BatchSize {
+			return report, nil
+		}
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+	}
+}
+
+// Status counts plaintext and encrypted values per key for users and profiles.
+// It only reads key IDs, so it works without the keys themselves.
+func Status(ctx context.Context, querier db.Querier, batchSize int) ([]KeyStatus, error) {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+
+	users := KeyStatus{Table: "users", ByKey: make(map[string]int)}
+	for offset := int32(0); ; offset += int32(batchSize) {
+		batch, err := querier.ListUsers(ctx, db.ListUsersParams{Limit: int32(batchSize), Offset: offset})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list users: %w", err)
+		}
+		for _, user := range batch {
+			countValue(&users, user.Email, user.EmailEncrypted)
+		}
+		if len(batch) < batchSize {
+			break
+		}
+	}
+
+	profiles := KeyStatus{Table: "user_profiles", ByKey: make(map[string]int)}
+	var lastID int32
+	for {
+		batch, err := querier.ListUserProfilesAfter(ctx, db.ListUserProfilesAfterParams{ID: lastID, Limit: int32(batchSize)})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list user profiles: %w", err)
+		}
+		for _, profile := range batch {
+			lastID = profile.ID
+			countValue(&profiles, profile.FullName, profile.FullNameEncrypted)
+		}
+		if len(batch) < batchSize {
+			break
+		}
+	}
+
+	return []KeyStatus{users, profiles}, nil
+}
+
+func countValue(status *KeyStatus, plain, encrypted sql.NullString) {
+	if !encrypted.Valid {
+		if plain.Valid {
+			status.Plaintext++
+		}
+		return
+	}
+	keyID, err := KeyID(encrypted.String)
+	if err != nil {
+		keyID = "malformed"
+	}
+	status.ByKey[keyID]++
+}
@@ -0,0 +1,262 @@
+package pii
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/toeic-app/internal/config"
+)
+
+// envelopePrefix marks values encrypted by the Protector (format version 1)
+const envelopePrefix = "enc:v1:"
+
+// minBlindIndexKeyLength is the minimum length of PII_BLIND_INDEX_KEY
+const minBlindIndexKeyLength = 32
+
+// ErrUnknownKey is returned when a value was wrapped with a key that is no longer configured
+var ErrUnknownKey = errors.New("unknown PII encryption key")
+
+// ErrMalformedValue is returned when a stored value is not a valid envelope
+var ErrMalformedValue = errors.New("malformed encrypted PII value")
+
+// Protector encrypts PII with envelope encryption.
+// Every value gets its own random data key (DEK), which is wrapped with a
+// key encryption key (KEK) from PII_ENCRYPTION_KEYS. Rotating the KEK only
+// re-wraps the small data keys, the encrypted values themselves are unchanged.
+//
+// Stored format: enc:v1:<kek id>:<base64 wrapped DEK>:<base64 nonce|ciphertext>
+type Protector struct {
+	keys        map[string][]byte
+	activeKeyID string
+	indexKey    []byte
+}
+
+// ParseKeys parses "keyID:hexKey,keyID:hexKey" into 32-byte AES-256 keys
+func ParseKeys(value string) (map[string][]byte, error) {
+	keys := make(map[string][]byte)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		keyID, hexKey, ok := strings.Cut(pair, ":")
+		keyID = strings.TrimSpace(keyID)
+		if !ok || keyID == "" {
+			return nil, fmt.Errorf("PII key must be in KEY_ID:HEX_KEY format")
+		}
+		key, err := hex.DecodeString(strings.TrimSpace(hexKey))
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("PII key %q must be 64 hex characters (32 bytes)", keyID)
+		}
+		keys[keyID] = key
+	}
+	return keys, nil
+}
+
+// NewProtector creates a protector from the PII configuration.
+// It returns nil when no keys are configured.
+func NewProtector(cfg config.Config) (*Protector, error) {
+	if cfg.PIIEncryptionKeys == "" {
+		if cfg.PIIEncryptionEnabled {
+			return nil, fmt.Errorf("PII_ENCRYPTION_KEYS is required when PII encryption is enabled")
+		}
+		return nil, nil
+	}
+
+	keys, err := ParseKeys(cfg.PIIEncryptionKeys)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := keys[cfg.PIIActiveKeyID]; !ok {
+		return nil, fmt.Errorf("PII_ACTIVE_KEY_ID %q is not in PII_ENCRYPTION_KEYS", cfg.PIIActiveKeyID)
+	}
+	if len(cfg.PIIBlindIndexKey) < minBlindIndexKeyLength {
+		return nil, fmt.Errorf("PII_BLIND_INDEX_KEY must be at least %d characters", minBlindIndexKeyLength)
+	}
+
+	return &Protector{
+		keys:        keys,
+		activeKeyID: cfg.PIIActiveKeyID,
+		indexKey:    []byte(cfg.PIIBlindIndexKey),
+	}, nil
+}
+
+// ActiveKeyID returns the ID of the key used for new values
+func (p *Protector) ActiveKeyID() string {
+	return p.activeKeyID
+}
+
+// IsEncrypted reports whether a stored value is an envelope
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, envelopePrefix)
+}
+
+// seal encrypts plaintext with AES-256-GCM and returns nonce|ciphertext
+func seal(key, plaintext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// open decrypts nonce|ciphertext produced by seal
+func open(key, sealed []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, ErrMalformedValue
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
+// envelope is a parsed stored value
+type envelope struct {
+	keyID      string
+	wrappedDEK []byte
+	payload    []byte
+}
+
+func parseEnvelope(value string) (envelope, error) {
+	if !IsEncrypted(value) {
+		return envelope{}, ErrMalformedValue
+	}
+	parts := strings.Split(strings.TrimPrefix(value, envelopePrefix), ":")
+	if len(parts) != 3 {
+		return envelope{}, ErrMalformedValue
+	}
+	wrappedDEK, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return envelope{}, ErrMalformedValue
+	}
+	payload, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return envelope{}, ErrMalformedValue
+	}
+	return envelope{keyID: parts[0], wrappedDEK: wrappedDEK, payload: payload}, nil
+}
+
+func (e envelope) String() string {
+	return envelopePrefix + e.keyID + ":" +
+		base64.StdEncoding.EncodeToString(e.wrappedDEK) + ":" +
+		base64.StdEncoding.EncodeToString(e.payload)
+}
+
+// Encrypt encrypts a value with a new data key wrapped by the active key
+func (p *Protector) Encrypt(plaintext string) (string, error) {
+	dek := make([]byte, 32)
+	if _, err := rand.Read(dek); err != nil {
+		return "", fmt.Errorf("failed to generate data key: %w", err)
+	}
+
+	payload, err := seal(dek, []byte(plaintext))
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt value: %w", err)
+	}
+	wrappedDEK, err := seal(p.keys[p.activeKeyID], dek)
+	if err != nil {
+		return "", fmt.Errorf("failed to wrap data key: %w", err)
+	}
+
+	return envelope{keyID: p.activeKeyID, wrappedDEK: wrappedDEK, payload: payload}.String(), nil
+}
+
+// unwrap returns the data key of an envelope
+func (p *Protector) unwrap(e envelope) ([]byte, error) {
+	kek, ok := p.keys[e.keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, e.keyID)
+	}
+	dek, err := open(kek, e.wrappedDEK)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+	return dek, nil
+}
+
+// Decrypt decrypts a value produced by Encrypt
+func (p *Protector) Decrypt(value string) (string, error) {
+	e, err := parseEnvelope(value)
+	if err != nil {
+		return "", err
+	}
+	dek, err := p.unwrap(e)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := open(dek, e.payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt value: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// KeyID returns the ID of the key that wrapped a stored value
+func KeyID(value string) (string, error) {
+	e, err := parseEnvelope(value)
+	if err != nil {
+		return "", err
+	}
+	return e.keyID, nil
+}
+
+// Rewrap re-wraps the data key of a value with the active key.
+// The encrypted payload is kept as is.
+func (p *Protector) Rewrap(value string) (string, error) {
+	e, err := parseEnvelope(value)
+	if err != nil {
+		return "", err
+	}
+	if e.keyID == p.activeKeyID {
+		return value, nil
+	}
+
+	dek, err := p.unwrap(e)
+	if err != nil {
+		return "", err
+	}
+	wrappedDEK, err := seal(p.keys[p.activeKeyID], dek)
+	if err != nil {
+		return "", fmt.Errorf("failed to wrap data key: %w", err)
+	}
+
+	e.keyID = p.activeKeyID
+	e.wrappedDEK = wrappedDEK
+	return e.String(), nil
+}
+
+// NormalizeEmail returns the form of an email address used for blind indexes
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// BlindIndex returns a keyed hash of a normalized email address.
+// It allows exact-match lookups without storing the plaintext.
+func (p *Protector) BlindIndex(email string) string {
+	h := hmac.New(sha256.New, p.indexKey)
+	h.Write([]byte(NormalizeEmail(email)))
+	return hex.EncodeToString(h.Sum(nil))
+}
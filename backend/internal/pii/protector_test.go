@@ -0,0 +1,101 @@
+package pii
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toeic-app/internal/config"
+)
+
+const (
+	testKey1 = "0000000000000000000000000000000000000000000000000000000000000001"
+	testKey2 = "0000000000000000000000000000000000000000000000000000000000000002"
+)
+
+func newTestProtector(t *testing.T, activeKeyID string) *Protector {
+	protector, err := NewProtector(config.Config{
+		PIIEncryptionEnabled: true,
+		PIIEncryptionKeys:    "k1:" + testKey1 + ",k2:" + testKey2,
+		PIIActiveKeyID:       activeKeyID,
+		PIIBlindIndexKey:     strings.Repeat("x", minBlindIndexKeyLength),
+	})
+	require.NoError(t, err)
+	return protector
+}
+
+func TestProtectorEncryptDecrypt(t *testing.T) {
+	p := newTestProtector(t, "k1")
+
+	encrypted, err := p.Encrypt("user@example.com")
+	require.NoError(t, err)
+	assert.True(t, IsEncrypted(encrypted))
+	assert.NotContains(t, encrypted, "user@example.com")
+
+	again, err := p.Encrypt("user@example.com")
+	require.NoError(t, err)
+	assert.NotEqual(t, encrypted, again) // fresh data key and nonce per value
+
+	plaintext, err := p.Decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "user@example.com", plaintext)
+}
+
+func TestProtectorRewrap(t *testing.T) {
+	old := newTestProtector(t, "k1")
+	encrypted, err := old.Encrypt("Jane Doe")
+	require.NoError(t, err)
+
+	rotated := newTestProtector(t, "k2")
+	rewrapped, err := rotated.Rewrap(encrypted)
+	require.NoError(t, err)
+
+	keyID, err := KeyID(rewrapped)
+	require.NoError(t, err)
+	assert.Equal(t, "k2", keyID)
+
+	plaintext, err := rotated.Decrypt(rewrapped)
+	require.NoError(t, err)
+	assert.Equal(t, "Jane Doe", plaintext)
+
+	// The old key can be removed once everything is re-wrapped
+	withoutOld, err := NewProtector(config.Config{
+		PIIEncryptionKeys: "k2:" + testKey2,
+		PIIActiveKeyID:    "k2",
+		PIIBlindIndexKey:  strings.Repeat("x", minBlindIndexKeyLength),
+	})
+	require.NoError(t, err)
+	_, err = withoutOld.Decrypt(encrypted)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+	plaintext, err = withoutOld.Decrypt(rewrapped)
+	require.NoError(t, err)
+	assert.Equal(t, "Jane Doe", plaintext)
+}
+
+func TestProtectorBlindIndex(t *testing.T) {
+	p := newTestProtector(t, "k1")
+
+	assert.Equal(t, p.BlindIndex("user@example.com"), p.BlindIndex(" User@Example.COM "))
+	assert.NotEqual(t, p.BlindIndex("user@example.com"), p.BlindIndex("other@example.com"))
+	assert.Len(t, p.BlindIndex("user@example.com"), 64)
+}
+
+func TestNewProtectorValidation(t *testing.T) {
+	protector, err := NewProtector(config.Config{})
+	assert.NoError(t, err)
+	assert.Nil(t, protector)
+
+	_, err = NewProtector(config.Config{PIIEncryptionEnabled: true})
+	assert.Error(t, err)
+
+	_, err = NewProtector(config.Config{
+		PIIEncryptionKeys: "k1:" + testKey1,
+		PIIActiveKeyID:    "missing",
+		PIIBlindIndexKey:  strings.Repeat("x", minBlindIndexKeyLength),
+	})
+	assert.Error(t, err)
+
+	_, err = ParseKeys("k1:abcd")
+	assert.Error(t, err)
+}
@@ -0,0 +1,223 @@
+package pii
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/toeic-app/internal/config"
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/logger"
+)
+
+// Store wraps a db.Querier and transparently encrypts and decrypts user PII.
+// Handlers keep working with plaintext db.User values.
+type Store struct {
+	db.Querier
+	protector     *Protector
+	encryptWrites bool
+}
+
+// NewStore creates an encrypting store. When encryptWrites is false, stored
+// values are still decrypted on read but new values are written in plaintext,
+// which lets encryption be switched off without losing access to data.
+func NewStore(querier db.Querier, protector *Protector, encryptWrites bool) *Store {
+	return &Store{
+		Querier:       querier,
+		protector:     protector,
+		encryptWrites: encryptWrites,
+	}
+}
+
+// WrapStore wraps querier with PII encryption when keys are configured
+func WrapStore(cfg config.Config, querier db.Querier) (db.Querier, error) {
+	protector, err := NewProtector(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize PII encryption: %w", err)
+	}
+	if protector == nil {
+		return querier, nil
+	}
+
+	if cfg.PIIEncryptionEnabled {
+		logger.Info("PII encryption enabled (active key: %s)", protector.ActiveKeyID())
+	} else {
+		logger.Info("PII encryption disabled for writes, encrypted values are still readable")
+	}
+	return NewStore(querier, protector, cfg.PIIEncryptionEnabled), nil
+}
+
+// protectEmail returns the plaintext, encrypted and blind index column values for an email
+func (s *Store) protectEmail(email sql.NullString) (plain, encrypted, bidx sql.NullString, err error) {
+	if !s.encryptWrites || !email.Valid {
+		return email, sql.NullString{}, sql.NullString{}, nil
+	}
+
+	value, err := s.protector.Encrypt(email.String)
+	if err != nil {
+		return sql.NullString{}, sql.NullString{}, sql.NullString{}, err
+	}
+	encrypted = sql.NullString{String: value, Valid: true}
+	bidx = sql.NullString{String: s.protector.BlindIndex(email.String), Valid: true}
+	return sql.NullString{}, encrypted, bidx, nil
+}
+
+// revealUser replaces the encrypted email of a user with its plaintext
+func (s *Store) revealUser(user *db.User) error {
+	if !user.EmailEncrypted.Valid {
+		return nil
+	}
+	email, err := s.protector.Decrypt(user.EmailEncrypted.String)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt email of user %d: %w", user.ID, err)
+	}
+	user.Email = sql.NullString{String: email, Valid: true}
+	return nil
+}
+
+func (s *Store) revealUsers(users []db.User) ([]db.User, error) {
+	for i := range users {
+		if err := s.revealUser(&users[i]); err != nil {
+			return nil, err
+		}
+	}
+	return users, nil
+}
+
+func (s *Store) CreateUser(ctx context.Context, arg db.CreateUserParams) (db.User, error) {
+	var err error
+	arg.Email, arg.EmailEncrypted, arg.EmailBidx, err = s.protectEmail(arg.Email)
+	if err != nil {
+		return db.User{}, err
+	}
+
+	user, err := s.Querier.CreateUser(ctx, arg)
+	if err != nil {
+		return user, err
+	}
+	return user, s.revealUser(&user)
+}
+
+func (s *Store) UpdateUser(ctx context.Context, arg db.UpdateUserParams) (db.User, error) {
+	var err error
+	arg.Email, arg.EmailEncrypted, arg.EmailBidx, err = s.protectEmail(arg.Email)
+	if err != nil {
+		return db.User{}, err
+	}
+
+	user, err := s.Querier.UpdateUser(ctx, arg)
+	if err != nil {
+		return user, err
+	}
+	return user, s.revealUser(&user)
+}
+
+func (s *Store) GetUser(ctx context.Context, id int32) (db.User, error) {
+	user, err := s.Querier.GetUser(ctx, id)
+	if err != nil {
+		return user, err
+	}
+	return user, s.revealUser(&user)
+}
+
+// GetUserByEmail looks users up by blind index, falling back to the
+// plaintext column for rows that were not backfilled yet
+func (s *Store) GetUserByEmail(ctx context.Context, email sql.NullString) (db.User, error) {
+	user, err := s.Querier.GetUserByEmailBidx(ctx, sql.NullString{String: s.protector.BlindIndex(email.String), Valid: true})
+	if errors.Is(err, sql.ErrNoRows) {
+		user, err = s.Querier.GetUserByEmail(ctx, email)
+	}
+	if err != nil {
+		return user, err
+	}
+	return user, s.revealUser(&user)
+}
+
+func (s *Store) GetUserByEmailBidx(ctx context.Context, emailBidx sql.NullString) (db.User, error) {
+	user, err := s.Querier.GetUserByEmailBidx(ctx, emailBidx)
+	if err != nil {
+		return user, err
+	}
+	return user, s.revealUser(&user)
+}
+
+func (s *Store) ListUsers(ctx context.Context, arg db.ListUsersParams) ([]db.User, error) {
+	users, err := s.Querier.ListUsers(ctx, arg)
+	if err != nil {
+		return nil, err
+	}
+	return s.revealUsers(users)
+}
+
+func (s *Store) ListUsersAfter(ctx context.Context, arg db.ListUsersAfterParams) ([]db.User, error) {
+	users, err := s.Querier.ListUsersAfter(ctx, arg)
+	if err != nil {
+		return nil, err
+	}
+	return s.revealUsers(users)
+}
+
+func (s *Store) ListUsersWithRole(ctx context.Context, roleID int32) ([]db.User, error) {
+	users, err := s.Querier.ListUsersWithRole(ctx, roleID)
+	if err != nil {
+		return nil, err
+	}
+	return s.revealUsers(users)
+}
+
+// protectFullName returns the plaintext and encrypted column values for a full name
+func (s *Store) protectFullName(fullName sql.NullString) (plain, encrypted sql.NullString, err error) {
+	if !s.encryptWrites || !fullName.Valid {
+		return fullName, sql.NullString{}, nil
+	}
+
+	value, err := s.protector.Encrypt(fullName.String)
+	if err != nil {
+		return sql.NullString{}, sql.NullString{}, err
+	}
+	return sql.NullString{}, sql.NullString{String: value, Valid: true}, nil
+}
+
+// revealProfile replaces the encrypted full name of a profile with its plaintext
+func (s *Store) revealProfile(profile *db.UserProfile) error {
+	if !profile.FullNameEncrypted.Valid {
+		return nil
+	}
+	fullName, err := s.protector.Decrypt(profile.FullNameEncrypted.String)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt full name of profile %d: %w", profile.ID, err)
+	}
+	profile.FullName = sql.NullString{String: fullName, Valid: true}
+	return nil
+}
+
+func (s *Store) GetUserProfileByUserID(ctx context.Context, userID sql.NullInt32) (db.UserProfile, error) {
+	profile, err := s.Querier.GetUserProfileByUserID(ctx, userID)
+	if err != nil {
+		return profile, err
+	}
+	return profile, s.revealProfile(&profile)
+}
+
+func (s *Store) UpsertUserProfile(ctx context.Context, arg db.UpsertUserProfileParams) (db.UserProfile, error) {
+	var err error
+	arg.FullName, arg.FullNameEncrypted, err = s.protectFullName(arg.FullName)
+	if err != nil {
+		return db.UserProfile{}, err
+	}
+
+	profile, err := s.Querier.UpsertUserProfile(ctx, arg)
+	if err != nil {
+		return profile, err
+	}
+	return profile, s.revealProfile(&profile)
+}
+
+func (s *Store) SetUserPreferredLanguage(ctx context.Context, arg db.SetUserPreferredLanguageParams) (db.UserProfile, error) {
+	profile, err := s.Querier.SetUserPreferredLanguage(ctx, arg)
+	if err != nil {
+		return profile, err
+	}
+	return profile, s.revealProfile(&profile)
+}
@@ -0,0 +1,210 @@
+package placement
+
+import (
+	"errors"
+	"math"
+)
+
+// Skills measured by the placement test, in the order they are tested
+const (
+	SkillVocabulary = "vocabulary"
+	SkillListening  = "listening"
+	SkillReading    = "reading"
+)
+
+// Item kinds
+const (
+	KindWord     = "word"
+	KindQuestion = "question"
+)
+
+// Level scale shared with word levels and question difficulty
+const (
+	MinLevel      = 1
+	MaxLevel      = 6
+	StartLevel    = 3
+	ItemsPerSkill = 5
+)
+
+var (
+	// ErrNoPendingItem is returned when an answer arrives for a test without an open item
+	ErrNoPendingItem = errors.New("placement test has no pending item")
+	// ErrTestFinished is returned when a finished test is answered
+	ErrTestFinished = errors.New("placement test is already finished")
+)
+
+// sectionScores maps a level to a TOEIC section score (5-495)
+var sectionScores = map[int]int{1: 60, 2: 150, 3: 250, 4: 340, 5: 420, 6: 475}
+
+// Response is an answered item
+type Response struct {
+	ItemID  int32 `json:"item_id"`
+	Level   int   `json:"level"`
+	Correct bool  `json:"correct"`
+}
+
+// SkillState is the staircase of one skill: a correct answer raises the
+// level of the next item, a wrong answer lowers it
+type SkillState struct {
+	Skill     string     `json:"skill"`
+	Level     int        `json:"level"`
+	Responses []Response `json:"responses"`
+	Skipped   bool       `json:"skipped,omitempty"` // No items available for the skill
+}
+
+// PendingItem is the item the learner is currently answering
+type PendingItem struct {
+	Skill    string   `json:"skill"`
+	Kind     string   `json:"kind"`
+	ItemID   int32    `json:"item_id"`
+	Level    int      `json:"level"`
+	Answer   string   `json:"answer"`
+	Prompt   string   `json:"prompt"`
+	Options  []string `json:"options"`
+	MediaURL string   `json:"media_url,omitempty"`
+	ImageURL string   `json:"image_url,omitempty"`
+}
+
+// State is the persisted progress of a placement test
+type State struct {
+	Skills  []SkillState `json:"skills"`
+	Pending *PendingItem `json:"pending,omitempty"`
+}
+
+// Result is the outcome of a completed placement test
+type Result struct {
+	Levels         map[string]int `json:"levels"`
+	ListeningScore int            `json:"listening_score"`
+	ReadingScore   int            `json:"reading_score"`
+	EstimatedScore int            `json:"estimated_score"`
+	CorrectAnswers int            `json:"correct_answers"`
+	TotalAnswers   int            `json:"total_answers"`
+}
+
+// NewState creates the state of a new test
+func NewState() *State {
+	state := &State{}
+	for _, skill := range []string{SkillVocabulary, SkillListening, SkillReading} {
+		state.Skills = append(state.Skills, SkillState{Skill: skill, Level: StartLevel, Responses: []Response{}})
+	}
+	return state
+}
+
+// Current returns the skill being tested, or nil when every skill is done
+func (s *State) Current() *SkillState {
+	for i := range s.Skills {
+		skill := &s.Skills[i]
+		if !skill.Skipped && len(skill.Responses) < ItemsPerSkill {
+			return skill
+		}
+	}
+	return nil
+}
+
+// Finished reports whether every skill has been tested or skipped
+func (s *State) Finished() bool {
+	return s.Current() == nil
+}
+
+// Answered returns the IDs of the items answered for a skill, so they are not asked twice
+func (s *State) Answered(skill string) []int32 {
+	ids := []int32{}
+	for _, state := range s.Skills {
+		if state.Skill != skill {
+			continue
+		}
+		for _, response := range state.Responses {
+			ids = append(ids, response.ItemID)
+		}
+	}
+	return ids
+}
+
+// Record scores the answer to the pending item and moves the staircase
+func (s *State) Record(answer string) (bool, error) {
+	if s.Pending == nil {
+		return false, ErrNoPendingItem
+	}
+	current := s.Current()
+	if current == nil {
+		return false, ErrTestFinished
+	}
+
+	pending := s.Pending
+	correct := answer == pending.Answer
+	current.Responses = append(current.Responses, Response{ItemID: pending.ItemID, Level: pending.Level, Correct: correct})
+	if correct {
+		current.Level = clampLevel(current.Level + 1)
+	} else {
+		current.Level = clampLevel(current.Level - 1)
+	}
+	s.Pending = nil
+	return correct, nil
+}
+
+// Estimate returns the estimated level of a skill: the mean level of the
+// answered items after the first, adjusted by the final step, so a single
+// lucky or unlucky first answer does not dominate the result
+func (s *SkillState) Estimate() int {
+	if len(s.Responses) == 0 {
+		return 0
+	}
+	responses := s.Responses
+	if len(responses) > 1 {
+		responses = responses[1:]
+	}
+	total := 0
+	for _, response := range responses {
+		total += response.Level
+	}
+	mean := float64(total) / float64(len(responses))
+	if s.Responses[len(s.Responses)-1].Correct {
+		mean += 0.5
+	} else {
+		mean -= 0.5
+	}
+	return clampLevel(int(math.Round(mean)))
+}
+
+// Result computes the outcome of the test. Sections that could not be
+// tested fall back to the vocabulary level.
+func (s *State) Result() *Result {
+	result := &Result{Levels: map[string]int{}}
+	for i := range s.Skills {
+		skill := &s.Skills[i]
+		for _, response := range skill.Responses {
+			result.TotalAnswers++
+			if response.Correct {
+				result.CorrectAnswers++
+			}
+		}
+		if level := skill.Estimate(); level > 0 {
+			result.Levels[skill.Skill] = level
+		}
+	}
+
+	fallback := result.Levels[SkillVocabulary]
+	if fallback == 0 {
+		fallback = MinLevel
+	}
+	sectionLevel := func(skill string) int {
+		if level, ok := result.Levels[skill]; ok {
+			return level
+		}
+		return fallback
+	}
+	result.ListeningScore = sectionScores[sectionLevel(SkillListening)]
+	result.ReadingScore = sectionScores[sectionLevel(SkillReading)]
+	result.EstimatedScore = result.ListeningScore + result.ReadingScore
+	return result
+}
+
+func clampLevel(level int) int {
+	if level < MinLevel {
+		return MinLevel
+	}
+	if level > MaxLevel {
+		return MaxLevel
+	}
+	return level
+}
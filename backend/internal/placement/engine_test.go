@@ -0,0 +1,70 @@
+package placement
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// answerAll answers every item of the current skill with the given outcome
+func answerAll(t *testing.T, state *State, correct bool) {
+	current := state.Current()
+	require.NotNil(t, current)
+	skill := current.Skill
+	for state.Current() != nil && state.Current().Skill == skill {
+		state.Pending = &PendingItem{Skill: skill, ItemID: int32(len(current.Responses) + 1), Level: current.Level, Answer: "a"}
+		answer := "b"
+		if correct {
+			answer = "a"
+		}
+		got, err := state.Record(answer)
+		require.NoError(t, err)
+		assert.Equal(t, correct, got)
+	}
+}
+
+func TestStaircaseFollowsAnswers(t *testing.T) {
+	state := NewState()
+
+	answerAll(t, state, true)
+	assert.Equal(t, MaxLevel, state.Skills[0].Level)
+	assert.Equal(t, []int{3, 4, 5, 6, 6}, levelsOf(state.Skills[0]))
+	assert.Equal(t, MaxLevel, state.Skills[0].Estimate())
+
+	answerAll(t, state, false)
+	assert.Equal(t, MinLevel, state.Skills[1].Level)
+	assert.Equal(t, MinLevel, state.Skills[1].Estimate())
+
+	assert.Equal(t, SkillReading, state.Current().Skill)
+	assert.Equal(t, []int32{1, 2, 3, 4, 5}, state.Answered(SkillVocabulary))
+}
+
+func TestRecordWithoutPendingItem(t *testing.T) {
+	state := NewState()
+	_, err := state.Record("a")
+	assert.ErrorIs(t, err, ErrNoPendingItem)
+}
+
+func TestResultFallsBackToVocabularyForSkippedSections(t *testing.T) {
+	state := NewState()
+	answerAll(t, state, true)
+	state.Skills[1].Skipped = true
+	state.Skills[2].Skipped = true
+	require.True(t, state.Finished())
+
+	result := state.Result()
+	assert.Equal(t, map[string]int{SkillVocabulary: MaxLevel}, result.Levels)
+	assert.Equal(t, sectionScores[MaxLevel], result.ListeningScore)
+	assert.Equal(t, sectionScores[MaxLevel]*2, result.EstimatedScore)
+	assert.Equal(t, 5, result.CorrectAnswers)
+	assert.Equal(t, 5, result.TotalAnswers)
+}
+
+func levelsOf(skill SkillState) []int {
+	levels := []int{}
+	for _, response := range skill.Responses {
+		levels = append(levels, response.Level)
+	}
+	return levels
+}
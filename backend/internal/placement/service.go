@@ -0,0 +1,375 @@
+package placement
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/sqlc-dev/pqtype"
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/logger"
+)
+
+// Test statuses
+const (
+	StatusInProgress = "in_progress"
+	StatusCompleted  = "completed"
+)
+
+// SeedWords is the number of words at the placement level added to the review queue
+const SeedWords = 20
+
+// wordOptions is the number of meanings offered for a vocabulary item
+const wordOptions = 4
+
+var (
+	// ErrTestNotFound is returned for unknown tests and tests of other users
+	ErrTestNotFound = errors.New("placement test not found")
+	// ErrNoItems is returned when neither words nor questions are available
+	ErrNoItems = errors.New("no placement items are available")
+)
+
+// Item is an item shown to the learner, without its answer
+type Item struct {
+	Skill    string   `json:"skill"`
+	Kind     string   `json:"kind"`
+	Prompt   string   `json:"prompt"`
+	Options  []string `json:"options"`
+	MediaURL string   `json:"media_url,omitempty"`
+	ImageURL string   `json:"image_url,omitempty"`
+}
+
+// Test is the view of a placement test returned to clients
+type Test struct {
+	ID          int32      `json:"id"`
+	Status      string     `json:"status"`
+	Answered    int        `json:"answered"`
+	MaxItems    int        `json:"max_items"`
+	Item        *Item      `json:"item,omitempty"`
+	Result      *Result    `json:"result,omitempty"`
+	StartedAt   time.Time  `json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// AnswerOutcome is the result of answering an item
+type AnswerOutcome struct {
+	Correct bool  `json:"correct"`
+	Test    *Test `json:"test"`
+}
+
+// Service runs adaptive placement tests
+type Service struct {
+	store db.Querier
+}
+
+// NewService creates a new placement test service
+func NewService(store db.Querier) *Service {
+	return &Service{store: store}
+}
+
+// Start resumes the test in progress of a user or starts a new one
+func (s *Service) Start(ctx context.Context, userID int32) (*Test, error) {
+	active, err := s.store.GetActivePlacementTest(ctx, userID)
+	if err == nil {
+		return s.view(active)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get active placement test: %w", err)
+	}
+
+	state := NewState()
+	if err := s.nextItem(ctx, state); err != nil {
+		return nil, err
+	}
+	if state.Finished() {
+		return nil, ErrNoItems
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode placement state: %w", err)
+	}
+	test, err := s.store.CreatePlacementTest(ctx, db.CreatePlacementTestParams{UserID: userID, State: data})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create placement test: %w", err)
+	}
+
+	logger.Info("User %d started placement test %d", userID, test.ID)
+	return s.view(test)
+}
+
+// Get returns a placement test of a user
+func (s *Service) Get(ctx context.Context, userID, testID int32) (*Test, error) {
+	test, err := s.load(ctx, userID, testID)
+	if err != nil {
+		return nil, err
+	}
+	return s.view(test)
+}
+
+// Answer records the answer to the pending item and serves the next one.
+// Answering the last item completes the test, records the result on the
+// profile and seeds the vocabulary review queue at the placement level.
+func (s *Service) Answer(ctx context.Context, userID, testID int32, answer string) (*AnswerOutcome, error) {
+	test, err := s.load(ctx, userID, testID)
+	if err != nil {
+		return nil, err
+	}
+	if test.Status != StatusInProgress {
+		return nil, ErrTestFinished
+	}
+
+	var state State
+	if err := json.Unmarshal(test.State, &state); err != nil {
+		return nil, fmt.Errorf("failed to decode placement state: %w", err)
+	}
+	correct, err := state.Record(answer)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.nextItem(ctx, &state); err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode placement state: %w", err)
+	}
+	if !state.Finished() {
+		if err := s.store.UpdatePlacementTestState(ctx, db.UpdatePlacementTestStateParams{ID: test.ID, State: data}); err != nil {
+			return nil, fmt.Errorf("failed to save placement state: %w", err)
+		}
+		test.State = data
+	} else if test, err = s.complete(ctx, test, &state, data); err != nil {
+		return nil, err
+	}
+
+	view, err := s.view(test)
+	if err != nil {
+		return nil, err
+	}
+	return &AnswerOutcome{Correct: correct, Test: view}, nil
+}
+
+// complete stores the result of a finished test
+func (s *Service) complete(ctx context.Context, test db.PlacementTest, state *State, data json.RawMessage) (db.PlacementTest, error) {
+	result := state.Result()
+	resultData, err := json.Marshal(result)
+	if err != nil {
+		return test, fmt.Errorf("failed to encode placement result: %w", err)
+	}
+
+	test, err = s.store.CompletePlacementTest(ctx, db.CompletePlacementTestParams{
+		ID:     test.ID,
+		State:  data,
+		Result: pqtype.NullRawMessage{RawMessage: resultData, Valid: true},
+	})
+	if err != nil {
+		return test, fmt.Errorf("failed to complete placement test: %w", err)
+	}
+
+	levels, err := json.Marshal(result.Levels)
+	if err != nil {
+		return test, fmt.Errorf("failed to encode placement levels: %w", err)
+	}
+	err = s.store.UpdateUserProfilePlacement(ctx, db.UpdateUserProfilePlacementParams{
+		UserID:          sql.NullInt32{Int32: test.UserID, Valid: true},
+		PlacementScore:  sql.NullInt32{Int32: int32(result.EstimatedScore), Valid: true},
+		PlacementLevels: pqtype.NullRawMessage{RawMessage: levels, Valid: true},
+	})
+	if err != nil {
+		return test, fmt.Errorf("failed to record placement result on profile: %w", err)
+	}
+
+	// The review queue is a convenience, the result is already stored
+	if level, ok := result.Levels[SkillVocabulary]; ok {
+		seeded, err := s.store.SeedUserWordProgress(ctx, db.SeedUserWordProgressParams{
+			UserID:   test.UserID,
+			Level:    int32(level),
+			MaxWords: SeedWords,
+		})
+		if err != nil {
+			logger.Warn("Failed to seed vocabulary for user %d after placement: %v", test.UserID, err)
+		} else {
+			logger.Debug("Seeded %d level %d words for user %d", seeded, level, test.UserID)
+		}
+	}
+
+	logger.InfoWithFields(logger.Fields{
+		"user_id":         test.UserID,
+		"test_id":         test.ID,
+		"estimated_score": result.EstimatedScore,
+		"levels":          result.Levels,
+	}, "Placement test completed")
+	return test, nil
+}
+
+// load returns a test owned by the user
+func (s *Service) load(ctx context.Context, userID, testID int32) (db.PlacementTest, error) {
+	test, err := s.store.GetPlacementTest(ctx, testID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && test.UserID != userID) {
+		return db.PlacementTest{}, ErrTestNotFound
+	}
+	if err != nil {
+		return db.PlacementTest{}, fmt.Errorf("failed to get placement test: %w", err)
+	}
+	return test, nil
+}
+
+// nextItem selects the pending item for the current skill, skipping skills without items
+func (s *Service) nextItem(ctx context.Context, state *State) error {
+	for {
+		current := state.Current()
+		if current == nil {
+			return nil
+		}
+
+		var item *PendingItem
+		var err error
+		if current.Skill == SkillVocabulary {
+			item, err = s.wordItem(ctx, current.Level, state.Answered(current.Skill))
+		} else {
+			item, err = s.questionItem(ctx, current.Skill, current.Level, state.Answered(current.Skill))
+		}
+		if err != nil {
+			return err
+		}
+		if item != nil {
+			state.Pending = item
+			return nil
+		}
+		current.Skipped = true
+	}
+}
+
+// wordItem asks for the meaning of a word at the level closest to the requested one
+func (s *Service) wordItem(ctx context.Context, level int, answered []int32) (*PendingItem, error) {
+	for _, candidate := range nearbyLevels(level) {
+		words, err := s.store.ListPlacementWords(ctx, db.ListPlacementWordsParams{
+			Level:      int32(candidate),
+			ExcludeIds: answered,
+			MaxWords:   wordOptions,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list placement words: %w", err)
+		}
+
+		options := distinctMeanings(words)
+		if len(options) < 2 || words[0].ShortMean == "" {
+			continue
+		}
+		target := words[0]
+		rand.Shuffle(len(options), func(i, j int) { options[i], options[j] = options[j], options[i] })
+		return &PendingItem{
+			Skill:   SkillVocabulary,
+			Kind:    KindWord,
+			ItemID:  target.ID,
+			Level:   candidate,
+			Answer:  target.ShortMean,
+			Prompt:  target.Word,
+			Options: options,
+		}, nil
+	}
+	return nil, nil
+}
+
+// questionItem picks the question closest to the level. Listening questions are the ones with audio.
+func (s *Service) questionItem(ctx context.Context, skill string, level int, answered []int32) (*PendingItem, error) {
+	question, err := s.store.GetPlacementQuestion(ctx, db.GetPlacementQuestionParams{
+		Listening:  skill == SkillListening,
+		ExcludeIds: answered,
+		Level:      int32(level),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get placement question: %w", err)
+	}
+
+	item := &PendingItem{
+		Skill:    skill,
+		Kind:     KindQuestion,
+		ItemID:   question.QuestionID,
+		Level:    level,
+		Answer:   question.TrueAnswer,
+		Prompt:   question.Title,
+		Options:  question.PossibleAnswers,
+		MediaURL: question.MediaUrl.String,
+		ImageURL: question.ImageUrl.String,
+	}
+	if question.Difficulty.Valid {
+		item.Level = int(question.Difficulty.Int16)
+	}
+	return item, nil
+}
+
+// view converts a stored test to its client view
+func (s *Service) view(test db.PlacementTest) (*Test, error) {
+	var state State
+	if err := json.Unmarshal(test.State, &state); err != nil {
+		return nil, fmt.Errorf("failed to decode placement state: %w", err)
+	}
+
+	view := &Test{
+		ID:        test.ID,
+		Status:    test.Status,
+		MaxItems:  len(state.Skills) * ItemsPerSkill,
+		StartedAt: test.StartedAt,
+	}
+	for _, skill := range state.Skills {
+		view.Answered += len(skill.Responses)
+	}
+	if test.Status == StatusInProgress && state.Pending != nil {
+		view.Item = &Item{
+			Skill:    state.Pending.Skill,
+			Kind:     state.Pending.Kind,
+			Prompt:   state.Pending.Prompt,
+			Options:  state.Pending.Options,
+			MediaURL: state.Pending.MediaURL,
+			ImageURL: state.Pending.ImageURL,
+		}
+	}
+	if test.Result.Valid {
+		view.Result = &Result{}
+		if err := json.Unmarshal(test.Result.RawMessage, view.Result); err != nil {
+			return nil, fmt.Errorf("failed to decode placement result: %w", err)
+		}
+	}
+	if test.CompletedAt.Valid {
+		view.CompletedAt = &test.CompletedAt.Time
+	}
+	return view, nil
+}
+
+// nearbyLevels lists levels ordered by distance from level
+func nearbyLevels(level int) []int {
+	levels := []int{level}
+	for delta := 1; delta <= MaxLevel; delta++ {
+		if level+delta <= MaxLevel {
+			levels = append(levels, level+delta)
+		}
+		if level-delta >= MinLevel {
+			levels = append(levels, level-delta)
+		}
+	}
+	return levels
+}
+
+// distinctMeanings returns the short meanings of words without duplicates
+func distinctMeanings(words []db.Word) []string {
+	seen := map[string]bool{}
+	options := []string{}
+	for _, word := range words {
+		if word.ShortMean == "" || seen[word.ShortMean] {
+			continue
+		}
+		seen[word.ShortMean] = true
+		options = append(options, word.ShortMean)
+	}
+	return options
+}
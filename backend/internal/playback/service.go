@@ -0,0 +1,202 @@
+// Package playback limits how often the listening audio of an exam can be
+// played. Clients get a token per question of an attempt and redeem it for
+// a short-lived signed URL; every redemption uses up one play.
+package playback
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/integrity"
+	"github.com/toeic-app/internal/logger"
+)
+
+var (
+	ErrQuestionNotFound = errors.New("question is not part of the exam")
+	ErrNoAudio          = errors.New("question has no audio")
+	ErrInvalidToken     = errors.New("invalid playback token")
+	ErrTokenExpired     = errors.New("playback token has expired")
+	ErrPlayLimitReached = errors.New("play limit reached")
+)
+
+// Signer turns the delivery URL of question audio into a URL that stops
+// working at expiresAt
+type Signer interface {
+	PlaybackURL(url string, expiresAt time.Time) (string, error)
+}
+
+// Recorder stores plays and refused replays for the integrity score
+type Recorder interface {
+	Record(ctx context.Context, attempt db.ExamAttempt, events []integrity.Event) error
+}
+
+// Options are the playback limits
+type Options struct {
+	// MaxPlays is how often each question's audio can be played per attempt
+	MaxPlays int32
+	// TokenTTL is how long a playback token can be redeemed
+	TokenTTL time.Duration
+	// URLTTL is how long a signed URL can be fetched
+	URLTTL time.Duration
+}
+
+// Grant is a playback token for the audio of one question
+type Grant struct {
+	Token      string    `json:"token"`
+	QuestionID int32     `json:"question_id"`
+	MaxPlays   int32     `json:"max_plays"`
+	PlaysUsed  int32     `json:"plays_used"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// Playback is a signed URL for one play of a question's audio
+type Playback struct {
+	URL        string    `json:"url"`
+	QuestionID int32     `json:"question_id"`
+	PlaysLeft  int32     `json:"plays_left"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// Service issues and redeems playback tokens
+type Service struct {
+	store    db.Querier
+	signer   Signer
+	recorder Recorder
+	options  Options
+	now      func() time.Time
+}
+
+// NewService creates a playback service
+func NewService(store db.Querier, signer Signer, recorder Recorder, options Options) *Service {
+	return &Service{store: store, signer: signer, recorder: recorder, options: options, now: time.Now}
+}
+
+// Issue returns a new token for the audio of a question in the attempt's
+// exam. Issuing again replaces the previous token; plays already used are
+// kept.
+func (s *Service) Issue(ctx context.Context, attempt db.ExamAttempt, questionID int32) (*Grant, error) {
+	if _, err := s.audioURL(ctx, attempt, questionID); err != nil {
+		return nil, err
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate playback token: %w", err)
+	}
+	row, err := s.store.IssuePlaybackToken(ctx, db.IssuePlaybackTokenParams{
+		AttemptID:  attempt.AttemptID,
+		QuestionID: questionID,
+		TokenHash:  hashToken(token),
+		MaxPlays:   s.options.MaxPlays,
+		ExpiresAt:  s.now().Add(s.options.TokenTTL),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue playback token: %w", err)
+	}
+
+	return &Grant{
+		Token:      token,
+		QuestionID: row.QuestionID,
+		MaxPlays:   row.MaxPlays,
+		PlaysUsed:  row.PlaysUsed,
+		ExpiresAt:  row.ExpiresAt,
+	}, nil
+}
+
+// Play uses up one play of a token issued for the attempt and returns a
+// signed URL of the audio. Plays and refused replays are recorded as
+// integrity events.
+func (s *Service) Play(ctx context.Context, attempt db.ExamAttempt, token string) (*Playback, error) {
+	row, err := s.store.GetPlaybackToken(ctx, hashToken(token))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidToken
+		}
+		return nil, fmt.Errorf("failed to get playback token: %w", err)
+	}
+	if row.AttemptID != attempt.AttemptID {
+		return nil, ErrInvalidToken
+	}
+
+	mediaURL, err := s.audioURL(ctx, attempt, row.QuestionID)
+	if err != nil {
+		return nil, err
+	}
+	// The URL is signed before the play is used up so a signing failure
+	// does not cost a play
+	now := s.now()
+	expiresAt := now.Add(s.options.URLTTL)
+	url, err := s.signer.PlaybackURL(mediaURL, expiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign audio URL: %w", err)
+	}
+
+	consumed, err := s.store.ConsumePlaybackToken(ctx, db.ConsumePlaybackTokenParams{Now: now, ID: row.ID})
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("failed to use playback token: %w", err)
+		}
+		s.record(ctx, attempt, integrity.EventAudioReplayDenied, now)
+		if !now.Before(row.ExpiresAt) {
+			return nil, ErrTokenExpired
+		}
+		return nil, ErrPlayLimitReached
+	}
+	s.record(ctx, attempt, integrity.EventAudioPlay, now)
+
+	return &Playback{
+		URL:        url,
+		QuestionID: consumed.QuestionID,
+		PlaysLeft:  consumed.MaxPlays - consumed.PlaysUsed,
+		ExpiresAt:  expiresAt,
+	}, nil
+}
+
+// audioURL returns the delivery URL of a question's audio, checking the
+// question belongs to the attempt's exam
+func (s *Service) audioURL(ctx context.Context, attempt db.ExamAttempt, questionID int32) (string, error) {
+	row, err := s.store.GetExamQuestionAudio(ctx, db.GetExamQuestionAudioParams{
+		QuestionID: questionID,
+		ExamID:     attempt.ExamID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrQuestionNotFound
+		}
+		return "", fmt.Errorf("failed to get question audio: %w", err)
+	}
+	if !row.MediaUrl.Valid || row.MediaUrl.String == "" {
+		return "", ErrNoAudio
+	}
+	return row.MediaUrl.String, nil
+}
+
+// record stores a playback event. A play is not refused because it could
+// not be recorded.
+func (s *Service) record(ctx context.Context, attempt db.ExamAttempt, eventType string, at time.Time) {
+	err := s.recorder.Record(ctx, attempt, []integrity.Event{{Type: eventType, OccurredAt: at}})
+	if err != nil {
+		logger.Warn("Failed to record %s of attempt %d: %v", eventType, attempt.AttemptID, err)
+	}
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// hashToken is how tokens are stored, so a leaked table cannot be replayed
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
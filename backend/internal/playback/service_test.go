@@ -0,0 +1,165 @@
+package playback
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/integrity"
+)
+
+// fakeStore keeps tokens in memory. Question 10 of exam 1 has audio and
+// question 11 has none.
+type fakeStore struct {
+	db.Querier
+	tokens []db.AudioPlaybackToken
+}
+
+func (f *fakeStore) GetExamQuestionAudio(_ context.Context, arg db.GetExamQuestionAudioParams) (db.GetExamQuestionAudioRow, error) {
+	if arg.ExamID != 1 {
+		return db.GetExamQuestionAudioRow{}, sql.ErrNoRows
+	}
+	switch arg.QuestionID {
+	case 10:
+		return db.GetExamQuestionAudioRow{QuestionID: 10, MediaUrl: sql.NullString{String: "https://media.test/q10.mp3", Valid: true}}, nil
+	case 11:
+		return db.GetExamQuestionAudioRow{QuestionID: 11}, nil
+	}
+	return db.GetExamQuestionAudioRow{}, sql.ErrNoRows
+}
+
+func (f *fakeStore) IssuePlaybackToken(_ context.Context, arg db.IssuePlaybackTokenParams) (db.AudioPlaybackToken, error) {
+	for i, token := range f.tokens {
+		if token.AttemptID == arg.AttemptID && token.QuestionID == arg.QuestionID {
+			f.tokens[i].TokenHash = arg.TokenHash
+			f.tokens[i].ExpiresAt = arg.ExpiresAt
+			return f.tokens[i], nil
+		}
+	}
+	token := db.AudioPlaybackToken{
+		ID:         int32(len(f.tokens) + 1),
+		AttemptID:  arg.AttemptID,
+		QuestionID: arg.QuestionID,
+		TokenHash:  arg.TokenHash,
+		MaxPlays:   arg.MaxPlays,
+		ExpiresAt:  arg.ExpiresAt,
+	}
+	f.tokens = append(f.tokens, token)
+	return token, nil
+}
+
+func (f *fakeStore) GetPlaybackToken(_ context.Context, tokenHash string) (db.AudioPlaybackToken, error) {
+	for _, token := range f.tokens {
+		if token.TokenHash == tokenHash {
+			return token, nil
+		}
+	}
+	return db.AudioPlaybackToken{}, sql.ErrNoRows
+}
+
+func (f *fakeStore) ConsumePlaybackToken(_ context.Context, arg db.ConsumePlaybackTokenParams) (db.AudioPlaybackToken, error) {
+	for i, token := range f.tokens {
+		if token.ID == arg.ID && token.PlaysUsed < token.MaxPlays && token.ExpiresAt.After(arg.Now) {
+			f.tokens[i].PlaysUsed++
+			return f.tokens[i], nil
+		}
+	}
+	return db.AudioPlaybackToken{}, sql.ErrNoRows
+}
+
+type fakeSigner struct{ err error }
+
+func (f fakeSigner) PlaybackURL(url string, expiresAt time.Time) (string, error) {
+	return fmt.Sprintf("%s?exp=%d", url, expiresAt.Unix()), f.err
+}
+
+type fakeRecorder struct{ events []string }
+
+func (f *fakeRecorder) Record(_ context.Context, _ db.ExamAttempt, events []integrity.Event) error {
+	for _, event := range events {
+		f.events = append(f.events, event.Type)
+	}
+	return nil
+}
+
+func newTestService(signer Signer) (*Service, *fakeStore, *fakeRecorder, *time.Time) {
+	store := &fakeStore{}
+	recorder := &fakeRecorder{}
+	service := NewService(store, signer, recorder, Options{MaxPlays: 1, TokenTTL: 30 * time.Minute, URLTTL: 2 * time.Minute})
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	return service, store, recorder, &now
+}
+
+var attempt = db.ExamAttempt{AttemptID: 3, UserID: 5, ExamID: 1}
+
+func TestPlayAllowsOnePlay(t *testing.T) {
+	service, _, recorder, now := newTestService(fakeSigner{})
+
+	grant, err := service.Issue(context.Background(), attempt, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), grant.MaxPlays)
+	assert.Equal(t, now.Add(30*time.Minute), grant.ExpiresAt)
+
+	playback, err := service.Play(context.Background(), attempt, grant.Token)
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("https://media.test/q10.mp3?exp=%d", now.Add(2*time.Minute).Unix()), playback.URL)
+	assert.Zero(t, playback.PlaysLeft)
+
+	_, err = service.Play(context.Background(), attempt, grant.Token)
+	assert.ErrorIs(t, err, ErrPlayLimitReached)
+
+	// A new token does not give the question another play
+	grant, err = service.Issue(context.Background(), attempt, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), grant.PlaysUsed)
+	_, err = service.Play(context.Background(), attempt, grant.Token)
+	assert.ErrorIs(t, err, ErrPlayLimitReached)
+
+	assert.Equal(t, []string{integrity.EventAudioPlay, integrity.EventAudioReplayDenied, integrity.EventAudioReplayDenied}, recorder.events)
+}
+
+func TestPlayRejectsExpiredAndForeignTokens(t *testing.T) {
+	service, _, recorder, now := newTestService(fakeSigner{})
+
+	grant, err := service.Issue(context.Background(), attempt, 10)
+	require.NoError(t, err)
+
+	other := attempt
+	other.AttemptID = 4
+	_, err = service.Play(context.Background(), other, grant.Token)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	_, err = service.Play(context.Background(), attempt, "unknown")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	*now = now.Add(time.Hour)
+	_, err = service.Play(context.Background(), attempt, grant.Token)
+	assert.ErrorIs(t, err, ErrTokenExpired)
+	assert.Equal(t, []string{integrity.EventAudioReplayDenied}, recorder.events)
+}
+
+func TestIssueRequiresQuestionAudio(t *testing.T) {
+	service, _, _, _ := newTestService(fakeSigner{})
+
+	_, err := service.Issue(context.Background(), attempt, 11)
+	assert.ErrorIs(t, err, ErrNoAudio)
+	_, err = service.Issue(context.Background(), attempt, 12)
+	assert.ErrorIs(t, err, ErrQuestionNotFound)
+}
+
+func TestSigningFailureKeepsThePlay(t *testing.T) {
+	service, store, recorder, _ := newTestService(fakeSigner{err: errors.New("storage down")})
+
+	grant, err := service.Issue(context.Background(), attempt, 10)
+	require.NoError(t, err)
+	_, err = service.Play(context.Background(), attempt, grant.Token)
+	require.Error(t, err)
+	assert.Zero(t, store.tokens[0].PlaysUsed)
+	assert.Empty(t, recorder.events)
+}
@@ -0,0 +1,321 @@
+// Package portfolio assembles a learner's best writing submissions and
+// speaking sessions with the weekly progression of their scores. Learners
+// can share their portfolio with employers or teachers through public links
+// that expire; only a hash of a link's token is stored.
+package portfolio
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+var (
+	ErrLinkNotFound    = errors.New("share link not found")
+	ErrInvalidDuration = errors.New("share link duration is out of range")
+	ErrTooManyLinks    = errors.New("too many active share links")
+)
+
+// Options are the size of a portfolio and the limits of share links
+type Options struct {
+	// MaxItems is how many writings and speaking sessions are shown
+	MaxItems int32
+	// HistoryWeeks is how far back score progression goes
+	HistoryWeeks int
+	// DefaultShareDays is how long a share link works unless asked otherwise
+	DefaultShareDays int
+	// MaxShareDays is the longest a share link can work
+	MaxShareDays int
+	// MaxActiveLinks is how many unexpired links a learner can have
+	MaxActiveLinks int64
+}
+
+// Writing is an evaluated writing submission
+type Writing struct {
+	ID          int32      `json:"id"`
+	PromptID    *int32     `json:"prompt_id,omitempty"`
+	Prompt      string     `json:"prompt"`
+	Topic       string     `json:"topic,omitempty"`
+	Text        string     `json:"text"`
+	Score       float64    `json:"score"`
+	SubmittedAt time.Time  `json:"submitted_at"`
+	EvaluatedAt *time.Time `json:"evaluated_at,omitempty"`
+}
+
+// SpeakingSession summarizes the scored turns of a speaking session
+type SpeakingSession struct {
+	ID           int32      `json:"id"`
+	Topic        string     `json:"topic,omitempty"`
+	StartTime    time.Time  `json:"start_time"`
+	EndTime      *time.Time `json:"end_time,omitempty"`
+	Turns        int32      `json:"turns"`
+	AverageScore float64    `json:"average_score"`
+	BestScore    float64    `json:"best_score"`
+}
+
+// Point is one week of scores. Weeks without scores are left out.
+type Point struct {
+	Week         time.Time `json:"week"`
+	Count        int32     `json:"count"`
+	AverageScore float64   `json:"average_score"`
+	BestScore    float64   `json:"best_score"`
+}
+
+// Progression is the chart data of a learner's scores
+type Progression struct {
+	Writing  []Point `json:"writing"`
+	Speaking []Point `json:"speaking"`
+}
+
+// Portfolio is a learner's best work
+type Portfolio struct {
+	Username     string            `json:"username"`
+	GeneratedAt  time.Time         `json:"generated_at"`
+	Writings     []Writing         `json:"writings"`
+	Speaking     []SpeakingSession `json:"speaking"`
+	Progression  Progression       `json:"progression"`
+	HistoryWeeks int               `json:"history_weeks"`
+}
+
+// ShareLink is a public link to a portfolio. Token is only set when the
+// link is created.
+type ShareLink struct {
+	ID           int32      `json:"id"`
+	Token        string     `json:"token,omitempty"`
+	Label        string     `json:"label,omitempty"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+	Active       bool       `json:"active"`
+	ViewCount    int32      `json:"view_count"`
+	LastViewedAt *time.Time `json:"last_viewed_at,omitempty"`
+}
+
+// Service builds and shares portfolios
+type Service struct {
+	store   db.Querier
+	options Options
+	now     func() time.Time
+}
+
+// NewService creates a portfolio service
+func NewService(store db.Querier, options Options) *Service {
+	return &Service{store: store, options: options, now: time.Now}
+}
+
+// Build assembles the portfolio of a user
+func (s *Service) Build(ctx context.Context, userID int32) (*Portfolio, error) {
+	user, err := s.store.GetUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	now := s.now()
+	portfolio := &Portfolio{
+		Username:     user.Username,
+		GeneratedAt:  now,
+		Writings:     []Writing{},
+		Speaking:     []SpeakingSession{},
+		Progression:  Progression{Writing: []Point{}, Speaking: []Point{}},
+		HistoryWeeks: s.options.HistoryWeeks,
+	}
+
+	writings, err := s.store.ListBestUserWritings(ctx, db.ListBestUserWritingsParams{UserID: userID, Limit: s.options.MaxItems})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list writings: %w", err)
+	}
+	for _, row := range writings {
+		score, err := strconv.ParseFloat(row.AiScore.String, 64)
+		if err != nil {
+			continue
+		}
+		writing := Writing{
+			ID:          row.ID,
+			Prompt:      row.PromptText,
+			Topic:       row.Topic,
+			Text:        row.SubmissionText,
+			Score:       score,
+			SubmittedAt: row.SubmittedAt,
+		}
+		if row.PromptID.Valid {
+			writing.PromptID = &row.PromptID.Int32
+		}
+		if row.EvaluatedAt.Valid {
+			writing.EvaluatedAt = &row.EvaluatedAt.Time
+		}
+		portfolio.Writings = append(portfolio.Writings, writing)
+	}
+
+	sessions, err := s.store.ListBestSpeakingSessions(ctx, db.ListBestSpeakingSessionsParams{UserID: userID, Limit: s.options.MaxItems})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list speaking sessions: %w", err)
+	}
+	for _, row := range sessions {
+		session := SpeakingSession{
+			ID:           row.ID,
+			Topic:        row.SessionTopic,
+			StartTime:    row.StartTime,
+			Turns:        row.Turns,
+			AverageScore: round(row.AverageScore),
+			BestScore:    round(row.BestScore),
+		}
+		if row.EndTime.Valid {
+			session.EndTime = &row.EndTime.Time
+		}
+		portfolio.Speaking = append(portfolio.Speaking, session)
+	}
+
+	since := now.AddDate(0, 0, -7*s.options.HistoryWeeks)
+	writingWeeks, err := s.store.GetWritingScoreProgression(ctx, db.GetWritingScoreProgressionParams{UserID: userID, Since: since})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get writing progression: %w", err)
+	}
+	for _, row := range writingWeeks {
+		portfolio.Progression.Writing = append(portfolio.Progression.Writing, Point{
+			Week:         row.Week,
+			Count:        row.Submissions,
+			AverageScore: round(row.AverageScore),
+			BestScore:    round(row.BestScore),
+		})
+	}
+	speakingWeeks, err := s.store.GetSpeakingScoreProgression(ctx, db.GetSpeakingScoreProgressionParams{UserID: userID, Since: since})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get speaking progression: %w", err)
+	}
+	for _, row := range speakingWeeks {
+		portfolio.Progression.Speaking = append(portfolio.Progression.Speaking, Point{
+			Week:         row.Week,
+			Count:        row.Submissions,
+			AverageScore: round(row.AverageScore),
+			BestScore:    round(row.BestScore),
+		})
+	}
+	return portfolio, nil
+}
+
+// Share creates a public link to a user's portfolio that works for a
+// number of days. Zero days uses the default.
+func (s *Service) Share(ctx context.Context, userID int32, label string, days int) (*ShareLink, error) {
+	if days == 0 {
+		days = s.options.DefaultShareDays
+	}
+	if days < 1 || days > s.options.MaxShareDays {
+		return nil, ErrInvalidDuration
+	}
+	now := s.now()
+	active, err := s.store.CountActivePortfolioShareLinks(ctx, db.CountActivePortfolioShareLinksParams{UserID: userID, Now: now})
+	if err != nil {
+		return nil, fmt.Errorf("failed to count share links: %w", err)
+	}
+	if active >= s.options.MaxActiveLinks {
+		return nil, ErrTooManyLinks
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+	row, err := s.store.CreatePortfolioShareLink(ctx, db.CreatePortfolioShareLinkParams{
+		UserID:    userID,
+		TokenHash: hashToken(token),
+		Label:     label,
+		ExpiresAt: now.AddDate(0, 0, days),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create share link: %w", err)
+	}
+	link := s.toShareLink(row)
+	link.Token = token
+	return &link, nil
+}
+
+// Links lists a user's share links, newest first
+func (s *Service) Links(ctx context.Context, userID int32) ([]ShareLink, error) {
+	rows, err := s.store.ListPortfolioShareLinks(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list share links: %w", err)
+	}
+	links := make([]ShareLink, 0, len(rows))
+	for _, row := range rows {
+		links = append(links, s.toShareLink(row))
+	}
+	return links, nil
+}
+
+// Revoke stops a user's share link from working
+func (s *Service) Revoke(ctx context.Context, userID, id int32) (*ShareLink, error) {
+	row, err := s.store.RevokePortfolioShareLink(ctx, db.RevokePortfolioShareLinkParams{
+		RevokedAt: s.now(),
+		ID:        id,
+		UserID:    userID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrLinkNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke share link: %w", err)
+	}
+	link := s.toShareLink(row)
+	return &link, nil
+}
+
+// View returns the portfolio a share link points to and counts the view.
+// Unknown, expired and revoked links are all not found.
+func (s *Service) View(ctx context.Context, token string) (*Portfolio, error) {
+	row, err := s.store.ViewPortfolioShareLink(ctx, db.ViewPortfolioShareLinkParams{
+		ViewedAt:  s.now(),
+		TokenHash: hashToken(token),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrLinkNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open share link: %w", err)
+	}
+	return s.Build(ctx, row.UserID)
+}
+
+func (s *Service) toShareLink(row db.PortfolioShareLink) ShareLink {
+	link := ShareLink{
+		ID:        row.ID,
+		Label:     row.Label,
+		ExpiresAt: row.ExpiresAt,
+		CreatedAt: row.CreatedAt,
+		Active:    !row.RevokedAt.Valid && row.ExpiresAt.After(s.now()),
+		ViewCount: row.ViewCount,
+	}
+	if row.RevokedAt.Valid {
+		link.RevokedAt = &row.RevokedAt.Time
+	}
+	if row.LastViewedAt.Valid {
+		link.LastViewedAt = &row.LastViewedAt.Time
+	}
+	return link
+}
+
+// round keeps two decimals of an averaged score
+func round(score float64) float64 {
+	return math.Round(score*100) / 100
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// hashToken is how tokens are stored, so a leaked table cannot be replayed
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,188 @@
+package portfolio
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+// fakeStore keeps share links in memory and returns fixed scores
+type fakeStore struct {
+	db.Querier
+	links    []db.PortfolioShareLink
+	writings []db.ListBestUserWritingsRow
+	sessions []db.ListBestSpeakingSessionsRow
+	weeks    []db.GetWritingScoreProgressionRow
+}
+
+func (s *fakeStore) GetUser(_ context.Context, id int32) (db.User, error) {
+	return db.User{ID: id, Username: "learner", Email: sql.NullString{String: "learner@example.com", Valid: true}}, nil
+}
+
+func (s *fakeStore) ListBestUserWritings(_ context.Context, arg db.ListBestUserWritingsParams) ([]db.ListBestUserWritingsRow, error) {
+	return s.writings, nil
+}
+
+func (s *fakeStore) ListBestSpeakingSessions(_ context.Context, arg db.ListBestSpeakingSessionsParams) ([]db.ListBestSpeakingSessionsRow, error) {
+	return s.sessions, nil
+}
+
+func (s *fakeStore) GetWritingScoreProgression(_ context.Context, arg db.GetWritingScoreProgressionParams) ([]db.GetWritingScoreProgressionRow, error) {
+	return s.weeks, nil
+}
+
+func (s *fakeStore) GetSpeakingScoreProgression(_ context.Context, arg db.GetSpeakingScoreProgressionParams) ([]db.GetSpeakingScoreProgressionRow, error) {
+	return nil, nil
+}
+
+func (s *fakeStore) CountActivePortfolioShareLinks(_ context.Context, arg db.CountActivePortfolioShareLinksParams) (int64, error) {
+	var count int64
+	for _, link := range s.links {
+		if link.UserID == arg.UserID && !link.RevokedAt.Valid && link.ExpiresAt.After(arg.Now) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (s *fakeStore) CreatePortfolioShareLink(_ context.Context, arg db.CreatePortfolioShareLinkParams) (db.PortfolioShareLink, error) {
+	link := db.PortfolioShareLink{
+		ID:        int32(len(s.links) + 1),
+		UserID:    arg.UserID,
+		TokenHash: arg.TokenHash,
+		Label:     arg.Label,
+		ExpiresAt: arg.ExpiresAt,
+	}
+	s.links = append(s.links, link)
+	return link, nil
+}
+
+func (s *fakeStore) ListPortfolioShareLinks(_ context.Context, userID int32) ([]db.PortfolioShareLink, error) {
+	var links []db.PortfolioShareLink
+	for i := len(s.links) - 1; i >= 0; i-- {
+		if s.links[i].UserID == userID {
+			links = append(links, s.links[i])
+		}
+	}
+	return links, nil
+}
+
+func (s *fakeStore) RevokePortfolioShareLink(_ context.Context, arg db.RevokePortfolioShareLinkParams) (db.PortfolioShareLink, error) {
+	for i := range s.links {
+		if s.links[i].ID == arg.ID && s.links[i].UserID == arg.UserID {
+			s.links[i].RevokedAt = sql.NullTime{Time: arg.RevokedAt, Valid: true}
+			return s.links[i], nil
+		}
+	}
+	return db.PortfolioShareLink{}, sql.ErrNoRows
+}
+
+func (s *fakeStore) ViewPortfolioShareLink(_ context.Context, arg db.ViewPortfolioShareLinkParams) (db.PortfolioShareLink, error) {
+	for i := range s.links {
+		link := &s.links[i]
+		if link.TokenHash == arg.TokenHash && !link.RevokedAt.Valid && link.ExpiresAt.After(arg.ViewedAt) {
+			link.ViewCount++
+			link.LastViewedAt = sql.NullTime{Time: arg.ViewedAt, Valid: true}
+			return *link, nil
+		}
+	}
+	return db.PortfolioShareLink{}, sql.ErrNoRows
+}
+
+func newService(store *fakeStore) (*Service, *time.Time) {
+	service := NewService(store, Options{
+		MaxItems:         5,
+		HistoryWeeks:     26,
+		DefaultShareDays: 7,
+		MaxShareDays:     30,
+		MaxActiveLinks:   2,
+	})
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	return service, &now
+}
+
+func TestBuildPortfolio(t *testing.T) {
+	week := time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)
+	store := &fakeStore{
+		writings: []db.ListBestUserWritingsRow{{
+			ID:             4,
+			PromptID:       sql.NullInt32{Int32: 2, Valid: true},
+			SubmissionText: "Dear hiring manager",
+			AiScore:        sql.NullString{String: "172.50", Valid: true},
+			PromptText:     "Write a cover letter",
+		}},
+		sessions: []db.ListBestSpeakingSessionsRow{{ID: 9, SessionTopic: "Travel", Turns: 3, AverageScore: 6.666666, BestScore: 8}},
+		weeks:    []db.GetWritingScoreProgressionRow{{Week: week, Submissions: 2, AverageScore: 150.255, BestScore: 172.5}},
+	}
+	service, _ := newService(store)
+
+	portfolio, err := service.Build(context.Background(), 7)
+	require.NoError(t, err)
+	assert.Equal(t, "learner", portfolio.Username)
+	require.Len(t, portfolio.Writings, 1)
+	assert.Equal(t, 172.5, portfolio.Writings[0].Score)
+	require.NotNil(t, portfolio.Writings[0].PromptID)
+	assert.Equal(t, int32(2), *portfolio.Writings[0].PromptID)
+	require.Len(t, portfolio.Speaking, 1)
+	assert.Equal(t, 6.67, portfolio.Speaking[0].AverageScore)
+	assert.Equal(t, []Point{{Week: week, Count: 2, AverageScore: 150.26, BestScore: 172.5}}, portfolio.Progression.Writing)
+	assert.NotNil(t, portfolio.Progression.Speaking)
+}
+
+func TestShareLinks(t *testing.T) {
+	store := &fakeStore{}
+	service, now := newService(store)
+	ctx := context.Background()
+
+	_, err := service.Share(ctx, 7, "", 31)
+	assert.ErrorIs(t, err, ErrInvalidDuration)
+
+	link, err := service.Share(ctx, 7, "Acme recruiter", 0)
+	require.NoError(t, err)
+	assert.Len(t, link.Token, 64)
+	assert.NotEqual(t, link.Token, store.links[0].TokenHash)
+	assert.Equal(t, now.AddDate(0, 0, 7), link.ExpiresAt)
+	assert.True(t, link.Active)
+
+	portfolio, err := service.View(ctx, link.Token)
+	require.NoError(t, err)
+	assert.Equal(t, "learner", portfolio.Username)
+	_, err = service.View(ctx, "unknown")
+	assert.ErrorIs(t, err, ErrLinkNotFound)
+
+	second, err := service.Share(ctx, 7, "", 30)
+	require.NoError(t, err)
+	_, err = service.Share(ctx, 7, "", 1)
+	assert.ErrorIs(t, err, ErrTooManyLinks)
+
+	// Revoked links stop working and free a slot
+	_, err = service.Revoke(ctx, 8, second.ID)
+	assert.ErrorIs(t, err, ErrLinkNotFound)
+	revoked, err := service.Revoke(ctx, 7, second.ID)
+	require.NoError(t, err)
+	assert.False(t, revoked.Active)
+	_, err = service.View(ctx, second.Token)
+	assert.ErrorIs(t, err, ErrLinkNotFound)
+	_, err = service.Share(ctx, 7, "", 14)
+	require.NoError(t, err)
+
+	// Expired links stop working
+	*now = now.AddDate(0, 0, 8)
+	_, err = service.View(ctx, link.Token)
+	assert.ErrorIs(t, err, ErrLinkNotFound)
+
+	links, err := service.Links(ctx, 7)
+	require.NoError(t, err)
+	require.Len(t, links, 3)
+	assert.Equal(t, link.ID, links[2].ID)
+	assert.Equal(t, int32(1), links[2].ViewCount)
+	assert.False(t, links[2].Active)
+	assert.Empty(t, links[2].Token)
+	assert.True(t, links[0].Active)
+}
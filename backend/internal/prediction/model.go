@@ -0,0 +1,228 @@
+// Package prediction estimates the full test TOEIC score of a learner from
+// the questions they answered, including single part practice. The ability
+// of the learner in each section is fitted with a Rasch model using the
+// difficulty of the answered questions, so harder questions count for more,
+// and mapped to the 5-495 section scale with a 95% confidence interval that
+// narrows as more questions are answered.
+package prediction
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Sections of the test
+const (
+	SectionListening = "listening"
+	SectionReading   = "reading"
+)
+
+// Confidence is the coverage of the predicted intervals
+const Confidence = 0.95
+
+const (
+	// z is the normal quantile of a Confidence interval
+	z = 1.96
+	// defaultDifficulty is assumed for questions with neither an authored
+	// nor a calibrated difficulty: the middle of the 1-6 scale
+	defaultDifficulty = 3.5
+	// logitsPerLevel is how much less likely, in logits, a correct answer
+	// is for each difficulty level
+	logitsPerLevel = 0.8
+	// maxSteps bounds the Newton iterations fitting an ability
+	maxSteps = 50
+	// Section scores are reported in steps of 5, like TOEIC scores
+	minSectionScore = 5
+	maxSectionScore = 495
+	scoreStep       = 5
+)
+
+// Answer is the latest answer of a learner to a question
+type Answer struct {
+	PartTitle  string
+	Listening  bool
+	Difficulty float64 // On the 1-6 scale, zero when unknown
+	Correct    bool
+}
+
+// Section is the predicted score of a section
+type Section struct {
+	Score    int     `json:"score" example:"350"`
+	Low      int     `json:"low" example:"300"`    // Lower bound of the confidence interval
+	High     int     `json:"high" example:"400"`   // Upper bound of the confidence interval
+	Answers  int     `json:"answers" example:"60"` // Answered questions of the section
+	Accuracy float64 `json:"accuracy" example:"0.72"`
+	Ability  float64 `json:"ability" example:"0.85"` // Fitted ability, in logits
+}
+
+// Part is how well a learner answers the questions of parts with the same
+// title, across exams
+type Part struct {
+	Title             string  `json:"title" example:"Part 5"`
+	Section           string  `json:"section" example:"reading"`
+	Answers           int     `json:"answers" example:"30"`
+	Correct           int     `json:"correct" example:"21"`
+	Accuracy          float64 `json:"accuracy" example:"0.7"`
+	AverageDifficulty float64 `json:"average_difficulty" example:"3.4"` // On the 1-6 scale
+}
+
+// Prediction is the predicted full test score of a learner
+type Prediction struct {
+	Score      int       `json:"score" example:"650"`
+	Low        int       `json:"low" example:"590"`  // Lower bound of the confidence interval
+	High       int       `json:"high" example:"710"` // Upper bound of the confidence interval
+	Confidence float64   `json:"confidence" example:"0.95"`
+	Listening  Section   `json:"listening"`
+	Reading    Section   `json:"reading"`
+	Parts      []Part    `json:"parts"`
+	Answers    int       `json:"answers" example:"120"`
+	ComputedAt time.Time `json:"computed_at"`
+}
+
+// Estimate predicts the full test score from answers. A section without
+// answers is predicted at the middle of its scale with a wide interval.
+func Estimate(answers []Answer) Prediction {
+	var listening, reading []Answer
+	for _, answer := range answers {
+		if answer.Listening {
+			listening = append(listening, answer)
+		} else {
+			reading = append(reading, answer)
+		}
+	}
+	prediction := Prediction{Confidence: Confidence, Parts: parts(answers), Answers: len(answers)}
+	var listeningScore, readingScore, listeningVariance, readingVariance float64
+	prediction.Listening, listeningScore, listeningVariance = section(listening)
+	prediction.Reading, readingScore, readingVariance = section(reading)
+
+	// The sections are fitted apart, so their variances add up
+	total := listeningScore + readingScore
+	margin := z * math.Sqrt(listeningVariance+readingVariance)
+	prediction.Score = prediction.Listening.Score + prediction.Reading.Score
+	prediction.Low = min(prediction.Score, roundScore(total-margin, 2*minSectionScore, 2*maxSectionScore))
+	prediction.High = max(prediction.Score, roundScore(total+margin, 2*minSectionScore, 2*maxSectionScore))
+	return prediction
+}
+
+// section predicts the score of a section and returns it unrounded with
+// its variance
+func section(answers []Answer) (Section, float64, float64) {
+	theta, se := ability(answers)
+	score := sectionScore(theta)
+	result := Section{
+		Score:   roundScore(score, minSectionScore, maxSectionScore),
+		Low:     roundScore(sectionScore(theta-z*se), minSectionScore, maxSectionScore),
+		High:    roundScore(sectionScore(theta+z*se), minSectionScore, maxSectionScore),
+		Answers: len(answers),
+		Ability: math.Round(theta*100) / 100,
+	}
+	correct := 0
+	for _, answer := range answers {
+		if answer.Correct {
+			correct++
+		}
+	}
+	if len(answers) > 0 {
+		result.Accuracy = ratio(correct, len(answers))
+	}
+	// Delta method: the slope of the score at theta times the error of theta
+	p := logistic(theta)
+	sd := float64(maxSectionScore-minSectionScore) * p * (1 - p) * se
+	return result, score, sd * sd
+}
+
+// ability fits the ability of a learner to answers with a Rasch model and
+// returns it with its standard error. A standard normal prior keeps the
+// ability finite when every answer is right or wrong.
+func ability(answers []Answer) (theta, se float64) {
+	for range maxSteps {
+		gradient, information := -theta, 1.0
+		for _, answer := range answers {
+			p := logistic(theta - itemDifficulty(answer.Difficulty))
+			if answer.Correct {
+				gradient += 1 - p
+			} else {
+				gradient -= p
+			}
+			information += p * (1 - p)
+		}
+		step := gradient / information
+		theta += step
+		if math.Abs(step) < 1e-6 {
+			break
+		}
+	}
+	information := 1.0
+	for _, answer := range answers {
+		p := logistic(theta - itemDifficulty(answer.Difficulty))
+		information += p * (1 - p)
+	}
+	return theta, 1 / math.Sqrt(information)
+}
+
+// itemDifficulty maps a 1-6 difficulty to logits, the middle of the scale
+// being an average question
+func itemDifficulty(difficulty float64) float64 {
+	if difficulty <= 0 {
+		difficulty = defaultDifficulty
+	}
+	return (difficulty - defaultDifficulty) * logitsPerLevel
+}
+
+// sectionScore maps an ability to the section scale by the share of
+// average questions a learner of that ability answers correctly
+func sectionScore(theta float64) float64 {
+	return minSectionScore + float64(maxSectionScore-minSectionScore)*logistic(theta)
+}
+
+// parts groups answers by part title, in title order
+func parts(answers []Answer) []Part {
+	byTitle := make(map[string]*Part)
+	difficulties := make(map[string]float64)
+	for _, answer := range answers {
+		key := strings.ToLower(strings.TrimSpace(answer.PartTitle))
+		part, ok := byTitle[key]
+		if !ok {
+			part = &Part{Title: strings.TrimSpace(answer.PartTitle), Section: SectionReading}
+			if answer.Listening {
+				part.Section = SectionListening
+			}
+			byTitle[key] = part
+		}
+		part.Answers++
+		if answer.Correct {
+			part.Correct++
+		}
+		difficulty := answer.Difficulty
+		if difficulty <= 0 {
+			difficulty = defaultDifficulty
+		}
+		difficulties[key] += difficulty
+	}
+
+	result := make([]Part, 0, len(byTitle))
+	for key, part := range byTitle {
+		part.Accuracy = ratio(part.Correct, part.Answers)
+		part.AverageDifficulty = math.Round(difficulties[key]/float64(part.Answers)*10) / 10
+		result = append(result, *part)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Title < result[j].Title })
+	return result
+}
+
+// roundScore rounds a score to a step, within lowest and highest
+func roundScore(score float64, lowest, highest int) int {
+	rounded := int(math.Round(score/scoreStep)) * scoreStep
+	return max(lowest, min(highest, rounded))
+}
+
+// ratio returns part/total rounded to two decimals
+func ratio(part, total int) float64 {
+	return math.Round(float64(part)/float64(total)*100) / 100
+}
+
+func logistic(x float64) float64 {
+	return 1 / (1 + math.Exp(-x))
+}
@@ -0,0 +1,78 @@
+package prediction
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// answers returns n answers to questions of difficulty, the first correct
+// of them right
+func answers(n, correct int, title string, listening bool, difficulty float64) []Answer {
+	result := make([]Answer, n)
+	for i := range result {
+		result[i] = Answer{PartTitle: title, Listening: listening, Difficulty: difficulty, Correct: i < correct}
+	}
+	return result
+}
+
+func TestEstimateWithoutAnswers(t *testing.T) {
+	prediction := Estimate(nil)
+
+	assert.Equal(t, 500, prediction.Score)
+	assert.Equal(t, 250, prediction.Listening.Score)
+	assert.Equal(t, 250, prediction.Reading.Score)
+	assert.Less(t, prediction.Low, 400)
+	assert.Greater(t, prediction.High, 600)
+	assert.Equal(t, Confidence, prediction.Confidence)
+	assert.Empty(t, prediction.Parts)
+}
+
+func TestEstimate(t *testing.T) {
+	var all []Answer
+	all = append(all, answers(40, 36, "Part 2", true, 3)...)
+	all = append(all, answers(40, 20, "Part 5", false, 4)...)
+	prediction := Estimate(all)
+
+	assert.Equal(t, 80, prediction.Answers)
+	assert.Equal(t, prediction.Listening.Score+prediction.Reading.Score, prediction.Score)
+	assert.Greater(t, prediction.Listening.Score, prediction.Reading.Score)
+	assert.Equal(t, 0.9, prediction.Listening.Accuracy)
+	for _, section := range []Section{prediction.Listening, prediction.Reading} {
+		assert.LessOrEqual(t, section.Low, section.Score)
+		assert.GreaterOrEqual(t, section.High, section.Score)
+		assert.Zero(t, section.Score%5)
+	}
+	assert.LessOrEqual(t, prediction.Low, prediction.Score)
+	assert.GreaterOrEqual(t, prediction.High, prediction.Score)
+
+	require.Len(t, prediction.Parts, 2)
+	assert.Equal(t, Part{Title: "Part 2", Section: SectionListening, Answers: 40, Correct: 36, Accuracy: 0.9, AverageDifficulty: 3}, prediction.Parts[0])
+	assert.Equal(t, SectionReading, prediction.Parts[1].Section)
+}
+
+func TestEstimateWeighsDifficulty(t *testing.T) {
+	easy := Estimate(answers(30, 20, "Part 5", false, 2))
+	hard := Estimate(answers(30, 20, "Part 5", false, 5))
+
+	assert.Greater(t, hard.Reading.Score, easy.Reading.Score)
+}
+
+func TestEstimateNarrowsWithAnswers(t *testing.T) {
+	few := Estimate(answers(10, 7, "Part 7", false, 3.5))
+	many := Estimate(answers(200, 140, "Part 7", false, 3.5))
+
+	assert.Less(t, many.Reading.High-many.Reading.Low, few.Reading.High-few.Reading.Low)
+	assert.Less(t, many.High-many.Low, few.High-few.Low)
+}
+
+func TestEstimateStaysOnScale(t *testing.T) {
+	perfect := Estimate(append(answers(300, 300, "Part 1", true, 6), answers(300, 300, "Part 7", false, 6)...))
+	assert.LessOrEqual(t, perfect.High, 990)
+	assert.LessOrEqual(t, perfect.Listening.High, 495)
+
+	wrong := Estimate(append(answers(300, 0, "Part 1", true, 1), answers(300, 0, "Part 7", false, 1)...))
+	assert.GreaterOrEqual(t, wrong.Low, 10)
+	assert.GreaterOrEqual(t, wrong.Reading.Low, 5)
+}
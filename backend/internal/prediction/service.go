@@ -0,0 +1,254 @@
+package prediction
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/logger"
+)
+
+var (
+	ErrNotEnoughAnswers = errors.New("not enough answered questions to predict a score")
+	ErrRunInProgress    = errors.New("score predictions are already being recalculated")
+)
+
+const (
+	// userBatchSize is how many learners are listed per query when every
+	// prediction is recalculated
+	userBatchSize = 500
+	// completedTimeout bounds recalculating after a completed attempt
+	completedTimeout = 30 * time.Second
+)
+
+// Options configure predictions
+type Options struct {
+	// MinAnswers is how many questions a learner must have answered before
+	// their score is predicted
+	MinAnswers int
+	// MaxAnswers is how many of the latest answers a prediction is based on
+	MaxAnswers int32
+}
+
+// Stats describes a run recalculating every prediction
+type Stats struct {
+	Learners  int           `json:"learners"`
+	Predicted int           `json:"predicted"`
+	Failed    int           `json:"failed"`
+	Duration  time.Duration `json:"duration" swaggertype:"integer"`
+}
+
+// Service predicts and stores the scores of learners
+type Service struct {
+	store         db.Querier
+	options       Options
+	now           func() time.Time
+	recalculating atomic.Bool
+
+	mutex     sync.Mutex
+	isRunning bool
+	stopChan  chan struct{}
+	wg        sync.WaitGroup
+}
+
+// NewService creates a prediction service
+func NewService(store db.Querier, options Options) *Service {
+	return &Service{store: store, options: options, now: time.Now}
+}
+
+// Get returns the stored prediction of a learner, predicting it first when
+// none is stored
+func (s *Service) Get(ctx context.Context, userID int32) (*Prediction, error) {
+	row, err := s.store.GetScorePrediction(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return s.Recalculate(ctx, userID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get predicted score: %w", err)
+	}
+	var prediction Prediction
+	if err := json.Unmarshal(row.Prediction, &prediction); err != nil {
+		return nil, fmt.Errorf("failed to decode predicted score: %w", err)
+	}
+	return &prediction, nil
+}
+
+// Recalculate predicts the score of a learner from their latest answers
+// and stores it. A learner with too few answers gets ErrNotEnoughAnswers
+// and loses any stored prediction.
+func (s *Service) Recalculate(ctx context.Context, userID int32) (*Prediction, error) {
+	rows, err := s.store.ListPredictionAnswers(ctx, db.ListPredictionAnswersParams{UserID: userID, Limit: s.options.MaxAnswers})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list answers: %w", err)
+	}
+	if len(rows) == 0 || len(rows) < s.options.MinAnswers {
+		if _, err := s.store.DeleteScorePrediction(ctx, userID); err != nil {
+			return nil, fmt.Errorf("failed to remove predicted score: %w", err)
+		}
+		return nil, ErrNotEnoughAnswers
+	}
+
+	answers := make([]Answer, len(rows))
+	for i, row := range rows {
+		answers[i] = Answer{
+			PartTitle:  row.PartTitle,
+			Listening:  row.Listening,
+			Difficulty: float64(row.Difficulty.Int16),
+			Correct:    row.IsCorrect,
+		}
+	}
+	prediction := Estimate(answers)
+	// Postgres keeps microseconds
+	prediction.ComputedAt = s.now().UTC().Truncate(time.Microsecond)
+
+	data, err := json.Marshal(prediction)
+	if err != nil {
+		return nil, err
+	}
+	err = s.store.UpsertScorePrediction(ctx, db.UpsertScorePredictionParams{
+		UserID:     userID,
+		Score:      int16(prediction.Score),
+		ScoreLow:   int16(prediction.Low),
+		ScoreHigh:  int16(prediction.High),
+		Answers:    int32(prediction.Answers),
+		Prediction: data,
+		ComputedAt: prediction.ComputedAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store predicted score: %w", err)
+	}
+	return &prediction, nil
+}
+
+// Completed recalculates the prediction of the learner of a completed
+// attempt. Subscribe it to completed attempts; it runs in the background.
+func (s *Service) Completed(attempt db.ExamAttempt) {
+	ctx, cancel := context.WithTimeout(context.Background(), completedTimeout)
+	defer cancel()
+	if _, err := s.Recalculate(ctx, attempt.UserID); err != nil && !errors.Is(err, ErrNotEnoughAnswers) {
+		logger.Warn("Failed to predict score of user %d after attempt %d: %v", attempt.UserID, attempt.AttemptID, err)
+	}
+}
+
+// RecalculateAll recalculates the prediction of every learner with a
+// completed attempt, so predictions follow recalibrated question difficulty
+func (s *Service) RecalculateAll(ctx context.Context) (Stats, error) {
+	if !s.recalculating.CompareAndSwap(false, true) {
+		return Stats{}, ErrRunInProgress
+	}
+	defer s.recalculating.Store(false)
+
+	started := s.now()
+	var stats Stats
+	var after int32
+	for {
+		userIDs, err := s.store.ListScorePredictionUsers(ctx, db.ListScorePredictionUsersParams{AfterUserID: after, Limit: userBatchSize})
+		if err != nil {
+			return stats, fmt.Errorf("failed to list learners: %w", err)
+		}
+		for _, userID := range userIDs {
+			stats.Learners++
+			_, err := s.Recalculate(ctx, userID)
+			switch {
+			case err == nil:
+				stats.Predicted++
+			case errors.Is(err, ErrNotEnoughAnswers):
+			case ctx.Err() != nil:
+				return stats, ctx.Err()
+			default:
+				stats.Failed++
+				logger.Warn("Failed to predict score of user %d: %v", userID, err)
+			}
+		}
+		if len(userIDs) < userBatchSize {
+			break
+		}
+		after = userIDs[len(userIDs)-1]
+	}
+
+	stats.Duration = s.now().Sub(started)
+	logger.Info("Scores predicted: %d of %d learners, %d failed in %v", stats.Predicted, stats.Learners, stats.Failed, stats.Duration)
+	return stats, nil
+}
+
+// Start recalculates every prediction periodically until Stop is called.
+// Predictions older than the interval are recalculated right away.
+func (s *Service) Start(interval time.Duration) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.isRunning {
+		return fmt.Errorf("score predictor is already running")
+	}
+	s.isRunning = true
+	s.stopChan = make(chan struct{})
+	s.wg.Add(1)
+	go s.run(interval)
+
+	logger.Info("Score predictor started with interval: %v", interval)
+	return nil
+}
+
+// Stop stops the periodic recalculation
+func (s *Service) Stop() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.isRunning {
+		return fmt.Errorf("score predictor is not running")
+	}
+	close(s.stopChan)
+	s.wg.Wait()
+	s.isRunning = false
+
+	logger.Info("Score predictor stopped")
+	return nil
+}
+
+// IsRunning returns whether the predictor is running
+func (s *Service) IsRunning() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.isRunning
+}
+
+func (s *Service) run(interval time.Duration) {
+	defer s.wg.Done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-s.stopChan:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	if oldest, err := s.store.GetOldestScorePrediction(ctx); err == nil && (!oldest.Valid || s.now().Sub(oldest.Time) >= interval) {
+		s.recalculateAll(ctx)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.recalculateAll(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *Service) recalculateAll(ctx context.Context) {
+	if _, err := s.RecalculateAll(ctx); err != nil && !errors.Is(err, ErrRunInProgress) && ctx.Err() == nil {
+		logger.Error("Failed to predict scores: %v", err)
+	}
+}
@@ -0,0 +1,158 @@
+package prediction
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+// fakeStore keeps answers and predictions in memory
+type fakeStore struct {
+	db.Querier
+	answers     map[int32][]db.ListPredictionAnswersRow
+	predictions map[int32]db.ScorePrediction
+	limit       int32
+}
+
+func (s *fakeStore) ListPredictionAnswers(_ context.Context, arg db.ListPredictionAnswersParams) ([]db.ListPredictionAnswersRow, error) {
+	s.limit = arg.Limit
+	rows := s.answers[arg.UserID]
+	return rows[:min(len(rows), int(arg.Limit))], nil
+}
+
+func (s *fakeStore) UpsertScorePrediction(_ context.Context, arg db.UpsertScorePredictionParams) error {
+	s.predictions[arg.UserID] = db.ScorePrediction{
+		UserID:     arg.UserID,
+		Score:      arg.Score,
+		ScoreLow:   arg.ScoreLow,
+		ScoreHigh:  arg.ScoreHigh,
+		Answers:    arg.Answers,
+		Prediction: arg.Prediction,
+		ComputedAt: arg.ComputedAt,
+	}
+	return nil
+}
+
+func (s *fakeStore) GetScorePrediction(_ context.Context, userID int32) (db.ScorePrediction, error) {
+	prediction, ok := s.predictions[userID]
+	if !ok {
+		return db.ScorePrediction{}, sql.ErrNoRows
+	}
+	return prediction, nil
+}
+
+func (s *fakeStore) DeleteScorePrediction(_ context.Context, userID int32) (int64, error) {
+	if _, ok := s.predictions[userID]; !ok {
+		return 0, nil
+	}
+	delete(s.predictions, userID)
+	return 1, nil
+}
+
+func (s *fakeStore) ListScorePredictionUsers(_ context.Context, arg db.ListScorePredictionUsersParams) ([]int32, error) {
+	seen := make(map[int32]bool)
+	for userID := range s.answers {
+		seen[userID] = true
+	}
+	for userID := range s.predictions {
+		seen[userID] = true
+	}
+	var userIDs []int32
+	for userID := range seen {
+		if userID > arg.AfterUserID {
+			userIDs = append(userIDs, userID)
+		}
+	}
+	sort.Slice(userIDs, func(i, j int) bool { return userIDs[i] < userIDs[j] })
+	return userIDs[:min(len(userIDs), int(arg.Limit))], nil
+}
+
+// answerRows returns n answers to Part 5 questions, the first correct of
+// them right
+func answerRows(n, correct int) []db.ListPredictionAnswersRow {
+	rows := make([]db.ListPredictionAnswersRow, n)
+	for i := range rows {
+		rows[i] = db.ListPredictionAnswersRow{
+			QuestionID: int32(i + 1),
+			IsCorrect:  i < correct,
+			PartTitle:  "Part 5",
+			Difficulty: sql.NullInt16{Int16: 3, Valid: i%2 == 0},
+		}
+	}
+	return rows
+}
+
+func newTestService(store *fakeStore) *Service {
+	service := NewService(store, Options{MinAnswers: 10, MaxAnswers: 100})
+	now := time.Date(2026, 10, 16, 2, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	return service
+}
+
+func TestGet(t *testing.T) {
+	store := &fakeStore{
+		answers:     map[int32][]db.ListPredictionAnswersRow{1: answerRows(20, 15), 2: answerRows(5, 5)},
+		predictions: map[int32]db.ScorePrediction{},
+	}
+	service := newTestService(store)
+
+	// Predicted and stored on first read
+	prediction, err := service.Get(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, 20, prediction.Answers)
+	assert.Equal(t, int32(100), store.limit)
+	require.Contains(t, store.predictions, int32(1))
+	assert.Equal(t, int16(prediction.Score), store.predictions[1].Score)
+	assert.Equal(t, time.Date(2026, 10, 16, 2, 0, 0, 0, time.UTC), store.predictions[1].ComputedAt)
+
+	// Read back from the store afterwards
+	store.answers[1] = nil
+	stored, err := service.Get(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, prediction.Score, stored.Score)
+	assert.Equal(t, prediction.Parts, stored.Parts)
+
+	_, err = service.Get(context.Background(), 2)
+	assert.ErrorIs(t, err, ErrNotEnoughAnswers)
+	assert.NotContains(t, store.predictions, int32(2))
+}
+
+func TestCompleted(t *testing.T) {
+	store := &fakeStore{
+		answers:     map[int32][]db.ListPredictionAnswersRow{1: answerRows(20, 10)},
+		predictions: map[int32]db.ScorePrediction{},
+	}
+	service := newTestService(store)
+
+	service.Completed(db.ExamAttempt{AttemptID: 7, UserID: 1})
+	require.Contains(t, store.predictions, int32(1))
+	before := store.predictions[1].Score
+
+	store.answers[1] = answerRows(40, 38)
+	service.Completed(db.ExamAttempt{AttemptID: 8, UserID: 1})
+	assert.Greater(t, store.predictions[1].Score, before)
+}
+
+func TestRecalculateAll(t *testing.T) {
+	store := &fakeStore{
+		answers: map[int32][]db.ListPredictionAnswersRow{1: answerRows(20, 15), 3: answerRows(30, 10)},
+		// Learner 2 has too few answers left for their stored prediction
+		predictions: map[int32]db.ScorePrediction{2: {UserID: 2, Score: 500}},
+	}
+	service := newTestService(store)
+
+	stats, err := service.RecalculateAll(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, stats.Learners)
+	assert.Equal(t, 2, stats.Predicted)
+	assert.Zero(t, stats.Failed)
+	assert.Contains(t, store.predictions, int32(1))
+	assert.Contains(t, store.predictions, int32(3))
+	assert.NotContains(t, store.predictions, int32(2))
+}
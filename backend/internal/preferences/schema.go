@@ -0,0 +1,232 @@
+package preferences
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// timePattern matches HH:MM in 24-hour format
+const timePattern = `^([01][0-9]|2[0-3]):[0-5][0-9]$`
+
+// SchemaJSON is the JSON schema of the known preference keys. It is served to
+// clients and every update is validated against it.
+const SchemaJSON = `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "User preferences",
+  "type": "object",
+  "additionalProperties": false,
+  "properties": {
+    "theme": {"type": "string", "enum": ["light", "dark", "system"]},
+    "quiet_hours": {
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "enabled": {"type": "boolean"},
+        "start": {"type": "string", "pattern": "` + timePattern + `"},
+        "end": {"type": "string", "pattern": "` + timePattern + `"},
+        "timezone": {"type": "string", "maxLength": 64}
+      }
+    },
+    "notifications": {
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "channels": {
+          "type": "object",
+          "additionalProperties": false,
+          "properties": {
+            "in_app": {"type": "boolean"},
+            "email": {"type": "boolean"},
+            "push": {"type": "boolean"}
+          }
+        },
+        "social": {"type": "boolean"},
+        "achievements": {"type": "boolean"},
+        "grades": {"type": "boolean"}
+      }
+    },
+    "session": {
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "word_limit": {"type": "integer", "minimum": 1, "maximum": 50},
+        "time_limit": {"type": "integer", "minimum": 0, "maximum": 3600},
+        "show_hints": {"type": "boolean"},
+        "shuffle_answers": {"type": "boolean"}
+      }
+    }
+  }
+}`
+
+// Schema is the subset of JSON schema used to describe preferences
+type Schema struct {
+	Type                 string             `json:"type"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+// ValidationError lists the values of a document that do not match the schema
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid preferences: " + strings.Join(e.Problems, "; ")
+}
+
+// Is makes validation errors match ErrInvalidPreferences
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrInvalidPreferences
+}
+
+// schema is the parsed SchemaJSON
+var schema = mustParseSchema(SchemaJSON)
+
+func mustParseSchema(data string) *Schema {
+	var s Schema
+	if err := json.Unmarshal([]byte(data), &s); err != nil {
+		panic(fmt.Sprintf("invalid preferences schema: %v", err))
+	}
+	s.compile()
+	return &s
+}
+
+func (s *Schema) compile() {
+	if s.Pattern != "" {
+		s.pattern = regexp.MustCompile(s.Pattern)
+	}
+	for _, property := range s.Properties {
+		property.compile()
+	}
+}
+
+// ValidatePatch checks a merge patch against the schema. null values are
+// allowed anywhere and reset the key to its default.
+func ValidatePatch(patch map[string]interface{}) error {
+	var problems []string
+	schema.validate("", patch, &problems)
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+func (s *Schema) validate(path string, value interface{}, problems *[]string) {
+	if value == nil {
+		return
+	}
+	fail := func(format string, args ...interface{}) {
+		name := path
+		if name == "" {
+			name = "preferences"
+		}
+		*problems = append(*problems, name+": "+fmt.Sprintf(format, args...))
+	}
+
+	switch s.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			fail("must be an object")
+			return
+		}
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			property, known := s.Properties[key]
+			if !known {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					fail("unknown key %q", key)
+				}
+				continue
+			}
+			property.validate(joinPath(path, key), object[key], problems)
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			fail("must be a string")
+			return
+		}
+		if len(s.Enum) > 0 && !contains(s.Enum, str) {
+			fail("must be one of %s", strings.Join(s.Enum, ", "))
+		}
+		if s.pattern != nil && !s.pattern.MatchString(str) {
+			fail("must match %s", s.Pattern)
+		}
+		if s.MaxLength != nil && len(str) > *s.MaxLength {
+			fail("must be at most %d characters", *s.MaxLength)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			fail("must be a boolean")
+		}
+	case "integer":
+		number, ok := value.(float64)
+		if !ok || number != math.Trunc(number) {
+			fail("must be an integer")
+			return
+		}
+		if s.Minimum != nil && number < *s.Minimum {
+			fail("must be at least %v", *s.Minimum)
+		}
+		if s.Maximum != nil && number > *s.Maximum {
+			fail("must be at most %v", *s.Maximum)
+		}
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
+
+// mergePatch applies an RFC 7396 JSON merge patch to target
+func mergePatch(target, patch map[string]interface{}) map[string]interface{} {
+	if target == nil {
+		target = map[string]interface{}{}
+	}
+	for key, value := range patch {
+		if value == nil {
+			delete(target, key)
+			continue
+		}
+		if nested, ok := value.(map[string]interface{}); ok {
+			existing, _ := target[key].(map[string]interface{})
+			merged := mergePatch(existing, nested)
+			if len(merged) == 0 {
+				delete(target, key)
+			} else {
+				target[key] = merged
+			}
+			continue
+		}
+		target[key] = value
+	}
+	return target
+}
@@ -0,0 +1,245 @@
+package preferences
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/logger"
+)
+
+// Notification categories users can turn off
+const (
+	CategorySocial       = "social"
+	CategoryAchievements = "achievements"
+	CategoryGrades       = "grades"
+)
+
+// ErrInvalidPreferences is returned for updates that do not match the schema
+var ErrInvalidPreferences = errors.New("invalid preferences")
+
+// QuietHours is a daily period without real-time notifications
+type QuietHours struct {
+	Enabled  bool   `json:"enabled"`
+	Start    string `json:"start"` // HH:MM
+	End      string `json:"end"`   // HH:MM, before Start for periods spanning midnight
+	Timezone string `json:"timezone"`
+}
+
+// Channels are the delivery channels a user accepts
+type Channels struct {
+	InApp bool `json:"in_app"`
+	Email bool `json:"email"`
+	Push  bool `json:"push"`
+}
+
+// Notifications selects which notifications are delivered and how
+type Notifications struct {
+	Channels     Channels `json:"channels"`
+	Social       bool     `json:"social"`
+	Achievements bool     `json:"achievements"`
+	Grades       bool     `json:"grades"`
+}
+
+// Session holds the defaults of new learning sessions
+type Session struct {
+	WordLimit      int32 `json:"word_limit"`
+	TimeLimit      int32 `json:"time_limit"` // Seconds, 0 for no limit
+	ShowHints      bool  `json:"show_hints"`
+	ShuffleAnswers bool  `json:"shuffle_answers"`
+}
+
+// Preferences are the effective preferences of a user
+type Preferences struct {
+	Theme         string        `json:"theme"`
+	QuietHours    QuietHours    `json:"quiet_hours"`
+	Notifications Notifications `json:"notifications"`
+	Session       Session       `json:"session"`
+}
+
+// Defaults returns the preferences of users who changed nothing
+func Defaults() Preferences {
+	return Preferences{
+		Theme:      "system",
+		QuietHours: QuietHours{Start: "22:00", End: "07:00", Timezone: "UTC"},
+		Notifications: Notifications{
+			Channels:     Channels{InApp: true, Email: true, Push: true},
+			Social:       true,
+			Achievements: true,
+			Grades:       true,
+		},
+		Session: Session{WordLimit: 10, ShowHints: true, ShuffleAnswers: true},
+	}
+}
+
+// Resolve applies stored preferences over the defaults
+func Resolve(stored json.RawMessage) (Preferences, error) {
+	prefs := Defaults()
+	if len(stored) == 0 {
+		return prefs, nil
+	}
+	if err := json.Unmarshal(stored, &prefs); err != nil {
+		return Defaults(), fmt.Errorf("failed to decode preferences: %w", err)
+	}
+	return prefs, nil
+}
+
+// InQuietHours reports whether t falls within the quiet hours
+func (p Preferences) InQuietHours(t time.Time) bool {
+	quiet := p.QuietHours
+	if !quiet.Enabled {
+		return false
+	}
+	start, errStart := minuteOfDay(quiet.Start)
+	end, errEnd := minuteOfDay(quiet.End)
+	if errStart != nil || errEnd != nil || start == end {
+		return false
+	}
+
+	location, err := time.LoadLocation(quiet.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+	local := t.In(location)
+	now := local.Hour()*60 + local.Minute()
+	if start < end {
+		return now >= start && now < end
+	}
+	return now >= start || now < end
+}
+
+// Allows reports whether a real-time in-app notification of a category may be sent at t
+func (p Preferences) Allows(category string, t time.Time) bool {
+	if !p.Notifications.Channels.InApp {
+		return false
+	}
+	switch category {
+	case CategorySocial:
+		if !p.Notifications.Social {
+			return false
+		}
+	case CategoryAchievements:
+		if !p.Notifications.Achievements {
+			return false
+		}
+	case CategoryGrades:
+		if !p.Notifications.Grades {
+			return false
+		}
+	}
+	return !p.InQuietHours(t)
+}
+
+func minuteOfDay(value string) (int, error) {
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
+
+// Service stores user preferences
+type Service struct {
+	store db.Querier
+}
+
+// NewService creates a new preferences service
+func NewService(store db.Querier) *Service {
+	return &Service{store: store}
+}
+
+// Get returns the effective preferences of a user
+func (s *Service) Get(ctx context.Context, userID int32) (Preferences, error) {
+	stored, err := s.stored(ctx, userID)
+	if err != nil {
+		return Defaults(), err
+	}
+	return Resolve(stored)
+}
+
+// Update applies a JSON merge patch to the preferences of a user. Keys set
+// to null return to their default.
+func (s *Service) Update(ctx context.Context, userID int32, patch map[string]interface{}) (Preferences, error) {
+	if err := ValidatePatch(patch); err != nil {
+		return Preferences{}, err
+	}
+
+	stored, err := s.stored(ctx, userID)
+	if err != nil {
+		return Preferences{}, err
+	}
+	current := map[string]interface{}{}
+	if len(stored) > 0 {
+		if err := json.Unmarshal(stored, &current); err != nil {
+			return Preferences{}, fmt.Errorf("failed to decode preferences: %w", err)
+		}
+	}
+
+	data, err := json.Marshal(mergePatch(current, patch))
+	if err != nil {
+		return Preferences{}, fmt.Errorf("failed to encode preferences: %w", err)
+	}
+	prefs, err := Resolve(data)
+	if err != nil {
+		return Preferences{}, err
+	}
+	if _, err := time.LoadLocation(prefs.QuietHours.Timezone); err != nil {
+		return Preferences{}, &ValidationError{Problems: []string{"quiet_hours.timezone: unknown time zone " + strconv.Quote(prefs.QuietHours.Timezone)}}
+	}
+
+	if _, err := s.store.UpsertUserPreferences(ctx, db.UpsertUserPreferencesParams{UserID: userID, Preferences: data}); err != nil {
+		return Preferences{}, fmt.Errorf("failed to save preferences: %w", err)
+	}
+	return prefs, nil
+}
+
+func (s *Service) stored(ctx context.Context, userID int32) (json.RawMessage, error) {
+	row, err := s.store.GetUserPreferences(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get preferences: %w", err)
+	}
+	return row.Preferences, nil
+}
+
+// Notifier delivers real-time events to connected users
+type Notifier interface {
+	SendToUser(userID string, messageType string, data interface{}) error
+}
+
+// gatedNotifier drops notifications the recipient does not want right now
+type gatedNotifier struct {
+	service  *Service
+	next     Notifier
+	category string
+}
+
+// Gate wraps a notifier so events of a category honor the channel, category
+// and quiet hours preferences of each recipient
+func (s *Service) Gate(next Notifier, category string) Notifier {
+	return &gatedNotifier{service: s, next: next, category: category}
+}
+
+func (g *gatedNotifier) SendToUser(userID string, messageType string, data interface{}) error {
+	id, err := strconv.ParseInt(userID, 10, 32)
+	if err != nil {
+		return g.next.SendToUser(userID, messageType, data)
+	}
+	prefs, err := g.service.Get(context.Background(), int32(id))
+	if err != nil {
+		// Deliver with default preferences rather than lose the event
+		logger.Debug("Using default preferences for user %d: %v", id, err)
+	}
+	if !prefs.Allows(g.category, time.Now()) {
+		logger.Debug("Suppressed %s for user %d by preferences", messageType, id)
+		return nil
+	}
+	return g.next.SendToUser(userID, messageType, data)
+}
@@ -0,0 +1,86 @@
+package preferences
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decode(t *testing.T, data string) map[string]interface{} {
+	var patch map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(data), &patch))
+	return patch
+}
+
+func TestValidatePatch(t *testing.T) {
+	assert.NoError(t, ValidatePatch(decode(t, `{"theme": "dark", "session": {"word_limit": 20}}`)))
+	assert.NoError(t, ValidatePatch(decode(t, `{"quiet_hours": null, "notifications": {"channels": {"email": false}}}`)))
+
+	err := ValidatePatch(decode(t, `{"theme": "blue", "colour": 1, "session": {"word_limit": 2.5}, "quiet_hours": {"start": "25:00"}}`))
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrInvalidPreferences))
+
+	var validation *ValidationError
+	require.True(t, errors.As(err, &validation))
+	assert.Len(t, validation.Problems, 4)
+	assert.Contains(t, err.Error(), `unknown key "colour"`)
+	assert.Contains(t, err.Error(), "session.word_limit: must be an integer")
+}
+
+func TestMergePatch(t *testing.T) {
+	current := decode(t, `{"theme": "dark", "session": {"word_limit": 20, "show_hints": false}}`)
+	merged := mergePatch(current, decode(t, `{"theme": null, "session": {"show_hints": null}, "quiet_hours": {"enabled": true}}`))
+
+	assert.NotContains(t, merged, "theme")
+	assert.Equal(t, map[string]interface{}{"word_limit": float64(20)}, merged["session"])
+	assert.Equal(t, map[string]interface{}{"enabled": true}, merged["quiet_hours"])
+}
+
+func TestResolveKeepsDefaults(t *testing.T) {
+	prefs, err := Resolve(json.RawMessage(`{"session": {"word_limit": 25}}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, int32(25), prefs.Session.WordLimit)
+	assert.True(t, prefs.Session.ShowHints)
+	assert.Equal(t, "system", prefs.Theme)
+	assert.True(t, prefs.Notifications.Channels.InApp)
+}
+
+func TestInQuietHours(t *testing.T) {
+	prefs := Defaults()
+	at := func(clock string) time.Time {
+		parsed, _ := time.Parse("15:04", clock)
+		return time.Date(2026, 3, 10, parsed.Hour(), parsed.Minute(), 0, 0, time.UTC)
+	}
+	assert.False(t, prefs.InQuietHours(at("23:00")), "disabled by default")
+
+	prefs.QuietHours.Enabled = true
+	assert.True(t, prefs.InQuietHours(at("23:00")))
+	assert.True(t, prefs.InQuietHours(at("06:59")))
+	assert.False(t, prefs.InQuietHours(at("07:00")))
+	assert.False(t, prefs.InQuietHours(at("12:00")))
+
+	prefs.QuietHours.Start, prefs.QuietHours.End = "12:00", "14:00"
+	assert.True(t, prefs.InQuietHours(at("13:30")))
+	assert.False(t, prefs.InQuietHours(at("14:00")))
+}
+
+func TestAllows(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	prefs := Defaults()
+	assert.True(t, prefs.Allows(CategorySocial, now))
+
+	prefs.Notifications.Social = false
+	assert.False(t, prefs.Allows(CategorySocial, now))
+	assert.True(t, prefs.Allows(CategoryAchievements, now))
+
+	prefs.Notifications.Grades = false
+	assert.False(t, prefs.Allows(CategoryGrades, now))
+
+	prefs.Notifications.Channels.InApp = false
+	assert.False(t, prefs.Allows(CategoryAchievements, now))
+}
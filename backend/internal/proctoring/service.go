@@ -0,0 +1,380 @@
+// Package proctoring stores the webcam snapshots clients capture during
+// exam attempts taken in test mode. Snapshots are only accepted after the
+// learner consented to the current proctoring policy, are kept privately
+// in the media storage and are shown to reviewers through short-lived
+// signed URLs. The retention engine deletes them after a fixed period.
+package proctoring
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"time"
+	"unicode/utf8"
+
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/logger"
+)
+
+const (
+	maxIPLength        = 64
+	maxUserAgentLength = 255
+)
+
+var (
+	ErrConsentRequired   = errors.New("consent to the current proctoring policy is required")
+	ErrPolicyMismatch    = errors.New("consent must be given to the current proctoring policy")
+	ErrUnsupportedImage  = errors.New("snapshots must be JPEG or PNG images")
+	ErrPhotoTooLarge     = errors.New("snapshot is too large")
+	ErrTooFrequent       = errors.New("snapshots are captured too often")
+	ErrPhotoLimitReached = errors.New("snapshot limit of the attempt reached")
+	ErrPhotoNotFound     = errors.New("snapshot not found")
+)
+
+// Storage keeps snapshots out of public reach
+type Storage interface {
+	UploadPrivateImage(ctx context.Context, file interface{}, publicID string) error
+	SignedImageURL(publicID string, expiresAt time.Time) (string, error)
+	DeletePrivateImage(ctx context.Context, publicID string) error
+}
+
+// Options are the capture limits and the policy learners consent to
+type Options struct {
+	// Folder is the storage folder snapshots are kept in
+	Folder string
+	// PolicyVersion identifies the proctoring notice learners accept
+	PolicyVersion string
+	// CaptureInterval is how often clients capture a snapshot. Snapshots
+	// received less than half an interval apart are refused.
+	CaptureInterval time.Duration
+	// MaxPhotoBytes is the size limit of a snapshot
+	MaxPhotoBytes int64
+	// MaxPhotosPerAttempt is how many snapshots an attempt can have
+	MaxPhotosPerAttempt int64
+	// URLTTL is how long a signed snapshot URL can be fetched
+	URLTTL time.Duration
+	// RetentionDays is how long snapshots are kept; it is only reported
+	// to learners here and enforced by the retention engine
+	RetentionDays int
+}
+
+// Consent is a learner's agreement to proctoring for one attempt
+type Consent struct {
+	AttemptID     int32     `json:"attempt_id"`
+	PolicyVersion string    `json:"policy_version"`
+	ConsentedAt   time.Time `json:"consented_at"`
+}
+
+// Status tells clients whether and how to capture snapshots for an attempt
+type Status struct {
+	PolicyVersion string   `json:"policy_version"`
+	Consent       *Consent `json:"consent,omitempty"`
+	// ConsentRequired is true until the learner consented to the current
+	// policy version
+	ConsentRequired        bool  `json:"consent_required"`
+	CaptureIntervalSeconds int64 `json:"capture_interval_seconds"`
+	MaxPhotoBytes          int64 `json:"max_photo_bytes"`
+	MaxPhotos              int64 `json:"max_photos"`
+	PhotosTaken            int64 `json:"photos_taken"`
+	RetentionDays          int   `json:"retention_days"`
+}
+
+// Snapshot is an image captured by the client
+type Snapshot struct {
+	Data []byte
+	// CapturedAt defaults to the time the snapshot is received
+	CapturedAt time.Time
+}
+
+// Photo is a stored snapshot. URL is only set for reviewers.
+type Photo struct {
+	ID         int64      `json:"id"`
+	AttemptID  *int32     `json:"attempt_id,omitempty"`
+	UserID     int32      `json:"user_id"`
+	SizeBytes  int32      `json:"size_bytes"`
+	CapturedAt time.Time  `json:"captured_at"`
+	ReceivedAt time.Time  `json:"received_at"`
+	Flagged    bool       `json:"flagged"`
+	ReviewNote *string    `json:"review_note,omitempty"`
+	ReviewedBy *int32     `json:"reviewed_by,omitempty"`
+	ReviewedAt *time.Time `json:"reviewed_at,omitempty"`
+	URL        string     `json:"url,omitempty"`
+	// URLExpiresAt is when URL stops working
+	URLExpiresAt *time.Time `json:"url_expires_at,omitempty"`
+}
+
+// AttemptSummary is an attempt with snapshots awaiting or after review
+type AttemptSummary struct {
+	AttemptID      int32     `json:"attempt_id"`
+	UserID         int32     `json:"user_id"`
+	ExamID         int32     `json:"exam_id"`
+	Status         string    `json:"status"`
+	StartTime      time.Time `json:"start_time"`
+	Photos         int32     `json:"photos"`
+	FlaggedPhotos  int32     `json:"flagged_photos"`
+	LastCapturedAt time.Time `json:"last_captured_at"`
+}
+
+// Review is a reviewer's verdict on a snapshot
+type Review struct {
+	Flagged bool
+	// Note is cleared when nil
+	Note *string
+}
+
+// Service records consents and stores and reviews snapshots
+type Service struct {
+	store   db.Querier
+	storage Storage
+	options Options
+	now     func() time.Time
+}
+
+// NewService creates a proctoring service
+func NewService(store db.Querier, storage Storage, options Options) *Service {
+	return &Service{store: store, storage: storage, options: options, now: time.Now}
+}
+
+// Status returns the capture settings of an attempt and the learner's
+// consent
+func (s *Service) Status(ctx context.Context, attempt db.ExamAttempt) (*Status, error) {
+	status := &Status{
+		PolicyVersion:          s.options.PolicyVersion,
+		ConsentRequired:        true,
+		CaptureIntervalSeconds: int64(s.options.CaptureInterval / time.Second),
+		MaxPhotoBytes:          s.options.MaxPhotoBytes,
+		MaxPhotos:              s.options.MaxPhotosPerAttempt,
+		RetentionDays:          s.options.RetentionDays,
+	}
+	consent, err := s.store.GetProctoringConsent(ctx, attempt.AttemptID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get proctoring consent: %w", err)
+	}
+	if err == nil {
+		status.Consent = consentFromRow(consent)
+		status.ConsentRequired = consent.PolicyVersion != s.options.PolicyVersion
+	}
+	status.PhotosTaken, err = s.store.CountProctoringPhotos(ctx, attemptRef(attempt))
+	if err != nil {
+		return nil, fmt.Errorf("failed to count proctoring photos: %w", err)
+	}
+	return status, nil
+}
+
+// Consent records that the learner agreed to the current policy for the
+// attempt, from the given client
+func (s *Service) Consent(ctx context.Context, attempt db.ExamAttempt, policyVersion, ipAddress, userAgent string) (*Consent, error) {
+	if policyVersion != s.options.PolicyVersion {
+		return nil, ErrPolicyMismatch
+	}
+	row, err := s.store.RecordProctoringConsent(ctx, db.RecordProctoringConsentParams{
+		AttemptID:     attempt.AttemptID,
+		UserID:        attempt.UserID,
+		PolicyVersion: policyVersion,
+		IpAddress:     truncate(ipAddress, maxIPLength),
+		UserAgent:     truncate(userAgent, maxUserAgentLength),
+		ConsentedAt:   s.now(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to record proctoring consent: %w", err)
+	}
+	return consentFromRow(row), nil
+}
+
+// Upload stores a snapshot of an attempt the learner consented to
+// proctoring for
+func (s *Service) Upload(ctx context.Context, attempt db.ExamAttempt, snapshot Snapshot) (*Photo, error) {
+	if int64(len(snapshot.Data)) > s.options.MaxPhotoBytes {
+		return nil, ErrPhotoTooLarge
+	}
+	contentType := http.DetectContentType(snapshot.Data)
+	if contentType != "image/jpeg" && contentType != "image/png" {
+		return nil, ErrUnsupportedImage
+	}
+
+	consent, err := s.store.GetProctoringConsent(ctx, attempt.AttemptID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && consent.PolicyVersion != s.options.PolicyVersion) {
+		return nil, ErrConsentRequired
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get proctoring consent: %w", err)
+	}
+
+	now := s.now()
+	taken, err := s.store.CountProctoringPhotos(ctx, attemptRef(attempt))
+	if err != nil {
+		return nil, fmt.Errorf("failed to count proctoring photos: %w", err)
+	}
+	if taken >= s.options.MaxPhotosPerAttempt {
+		return nil, ErrPhotoLimitReached
+	}
+	latest, err := s.store.GetLatestProctoringPhoto(ctx, attemptRef(attempt))
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get latest proctoring photo: %w", err)
+	}
+	if err == nil && now.Sub(latest.ReceivedAt) < s.options.CaptureInterval/2 {
+		return nil, ErrTooFrequent
+	}
+
+	// Capture times outside the attempt are moved to its start or to now
+	capturedAt := snapshot.CapturedAt
+	if capturedAt.IsZero() || capturedAt.After(now) {
+		capturedAt = now
+	}
+	if capturedAt.Before(attempt.StartTime) {
+		capturedAt = attempt.StartTime
+	}
+
+	name, err := randomName()
+	if err != nil {
+		return nil, fmt.Errorf("failed to name proctoring photo: %w", err)
+	}
+	publicID := path.Join(s.options.Folder, fmt.Sprintf("attempt-%d", attempt.AttemptID), name)
+	if err := s.storage.UploadPrivateImage(ctx, bytes.NewReader(snapshot.Data), publicID); err != nil {
+		return nil, fmt.Errorf("failed to store proctoring photo: %w", err)
+	}
+	row, err := s.store.CreateProctoringPhoto(ctx, db.CreateProctoringPhotoParams{
+		AttemptID:  attemptRef(attempt),
+		UserID:     attempt.UserID,
+		PublicID:   publicID,
+		SizeBytes:  int32(len(snapshot.Data)),
+		CapturedAt: capturedAt,
+		ReceivedAt: now,
+	})
+	if err != nil {
+		// An image without a row would never be purged
+		if deleteErr := s.storage.DeletePrivateImage(ctx, publicID); deleteErr != nil {
+			logger.Warn("Failed to delete unrecorded proctoring photo %s: %v", publicID, deleteErr)
+		}
+		return nil, fmt.Errorf("failed to save proctoring photo: %w", err)
+	}
+	return photoFromRow(row), nil
+}
+
+// Attempts lists attempts with snapshots, most recently captured first,
+// and how many there are in total
+func (s *Service) Attempts(ctx context.Context, flaggedOnly bool, limit, offset int32) ([]AttemptSummary, int64, error) {
+	rows, err := s.store.ListProctoredAttempts(ctx, db.ListProctoredAttemptsParams{
+		FlaggedOnly: flaggedOnly,
+		Limit:       limit,
+		Offset:      offset,
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list proctored attempts: %w", err)
+	}
+	total, err := s.store.CountProctoredAttempts(ctx, flaggedOnly)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count proctored attempts: %w", err)
+	}
+	attempts := make([]AttemptSummary, 0, len(rows))
+	for _, row := range rows {
+		attempts = append(attempts, AttemptSummary{
+			AttemptID:      row.AttemptID,
+			UserID:         row.UserID,
+			ExamID:         row.ExamID,
+			Status:         string(row.Status),
+			StartTime:      row.StartTime,
+			Photos:         row.Photos,
+			FlaggedPhotos:  row.FlaggedPhotos,
+			LastCapturedAt: row.LastCapturedAt,
+		})
+	}
+	return attempts, total, nil
+}
+
+// Photos returns the snapshots of an attempt in capture order, with signed
+// URLs that expire after URLTTL
+func (s *Service) Photos(ctx context.Context, attemptID int32) ([]Photo, error) {
+	rows, err := s.store.ListProctoringPhotos(ctx, sql.NullInt32{Int32: attemptID, Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list proctoring photos: %w", err)
+	}
+	expiresAt := s.now().Add(s.options.URLTTL)
+	photos := make([]Photo, 0, len(rows))
+	for _, row := range rows {
+		photo := photoFromRow(row)
+		photo.URL, err = s.storage.SignedImageURL(row.PublicID, expiresAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign proctoring photo URL: %w", err)
+		}
+		photo.URLExpiresAt = &expiresAt
+		photos = append(photos, *photo)
+	}
+	return photos, nil
+}
+
+// Review flags or clears a snapshot and records who reviewed it
+func (s *Service) Review(ctx context.Context, photoID int64, reviewerID int32, review Review) (*Photo, error) {
+	note := sql.NullString{}
+	if review.Note != nil {
+		note = sql.NullString{String: *review.Note, Valid: true}
+	}
+	row, err := s.store.ReviewProctoringPhoto(ctx, db.ReviewProctoringPhotoParams{
+		Flagged:    review.Flagged,
+		ReviewNote: note,
+		ReviewedBy: sql.NullInt32{Int32: reviewerID, Valid: true},
+		ReviewedAt: s.now(),
+		ID:         photoID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrPhotoNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to review proctoring photo: %w", err)
+	}
+	return photoFromRow(row), nil
+}
+
+func attemptRef(attempt db.ExamAttempt) sql.NullInt32 {
+	return sql.NullInt32{Int32: attempt.AttemptID, Valid: true}
+}
+
+func consentFromRow(row db.ExamProctoringConsent) *Consent {
+	return &Consent{AttemptID: row.AttemptID, PolicyVersion: row.PolicyVersion, ConsentedAt: row.ConsentedAt}
+}
+
+func photoFromRow(row db.ExamProctoringPhoto) *Photo {
+	photo := &Photo{
+		ID:         row.ID,
+		UserID:     row.UserID,
+		SizeBytes:  row.SizeBytes,
+		CapturedAt: row.CapturedAt,
+		ReceivedAt: row.ReceivedAt,
+		Flagged:    row.Flagged,
+	}
+	if row.AttemptID.Valid {
+		photo.AttemptID = &row.AttemptID.Int32
+	}
+	if row.ReviewNote.Valid {
+		photo.ReviewNote = &row.ReviewNote.String
+	}
+	if row.ReviewedBy.Valid {
+		photo.ReviewedBy = &row.ReviewedBy.Int32
+	}
+	if row.ReviewedAt.Valid {
+		photo.ReviewedAt = &row.ReviewedAt.Time
+	}
+	return photo
+}
+
+// randomName returns an unguessable storage name
+func randomName() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func truncate(value string, limit int) string {
+	if utf8.RuneCountInString(value) <= limit {
+		return value
+	}
+	return string([]rune(value)[:limit])
+}
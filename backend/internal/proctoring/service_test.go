@@ -0,0 +1,230 @@
+package proctoring
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/fakes"
+)
+
+// jpeg is the smallest data detected as a JPEG image
+var jpeg = []byte("\xff\xd8\xff\xe0 snapshot")
+
+// fakeStore keeps consents and photos in memory
+type fakeStore struct {
+	db.Querier
+	consents  map[int32]db.ExamProctoringConsent
+	photos    []db.ExamProctoringPhoto
+	createErr error
+}
+
+func newStore() *fakeStore {
+	return &fakeStore{consents: map[int32]db.ExamProctoringConsent{}}
+}
+
+func (s *fakeStore) RecordProctoringConsent(_ context.Context, arg db.RecordProctoringConsentParams) (db.ExamProctoringConsent, error) {
+	consent := db.ExamProctoringConsent{
+		AttemptID:     arg.AttemptID,
+		UserID:        arg.UserID,
+		PolicyVersion: arg.PolicyVersion,
+		IpAddress:     arg.IpAddress,
+		UserAgent:     arg.UserAgent,
+		ConsentedAt:   arg.ConsentedAt,
+	}
+	s.consents[arg.AttemptID] = consent
+	return consent, nil
+}
+
+func (s *fakeStore) GetProctoringConsent(_ context.Context, attemptID int32) (db.ExamProctoringConsent, error) {
+	consent, ok := s.consents[attemptID]
+	if !ok {
+		return consent, sql.ErrNoRows
+	}
+	return consent, nil
+}
+
+func (s *fakeStore) CountProctoringPhotos(_ context.Context, attemptID sql.NullInt32) (int64, error) {
+	var count int64
+	for _, photo := range s.photos {
+		if photo.AttemptID == attemptID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (s *fakeStore) GetLatestProctoringPhoto(_ context.Context, attemptID sql.NullInt32) (db.ExamProctoringPhoto, error) {
+	for i := len(s.photos) - 1; i >= 0; i-- {
+		if s.photos[i].AttemptID == attemptID {
+			return s.photos[i], nil
+		}
+	}
+	return db.ExamProctoringPhoto{}, sql.ErrNoRows
+}
+
+func (s *fakeStore) CreateProctoringPhoto(_ context.Context, arg db.CreateProctoringPhotoParams) (db.ExamProctoringPhoto, error) {
+	if s.createErr != nil {
+		return db.ExamProctoringPhoto{}, s.createErr
+	}
+	photo := db.ExamProctoringPhoto{
+		ID:         int64(len(s.photos) + 1),
+		AttemptID:  arg.AttemptID,
+		UserID:     arg.UserID,
+		PublicID:   arg.PublicID,
+		SizeBytes:  arg.SizeBytes,
+		CapturedAt: arg.CapturedAt,
+		ReceivedAt: arg.ReceivedAt,
+	}
+	s.photos = append(s.photos, photo)
+	return photo, nil
+}
+
+func (s *fakeStore) ListProctoringPhotos(_ context.Context, attemptID sql.NullInt32) ([]db.ExamProctoringPhoto, error) {
+	var photos []db.ExamProctoringPhoto
+	for _, photo := range s.photos {
+		if photo.AttemptID == attemptID {
+			photos = append(photos, photo)
+		}
+	}
+	return photos, nil
+}
+
+func (s *fakeStore) ReviewProctoringPhoto(_ context.Context, arg db.ReviewProctoringPhotoParams) (db.ExamProctoringPhoto, error) {
+	for i := range s.photos {
+		if s.photos[i].ID == arg.ID {
+			s.photos[i].Flagged = arg.Flagged
+			s.photos[i].ReviewNote = arg.ReviewNote
+			s.photos[i].ReviewedBy = arg.ReviewedBy
+			s.photos[i].ReviewedAt = sql.NullTime{Time: arg.ReviewedAt, Valid: true}
+			return s.photos[i], nil
+		}
+	}
+	return db.ExamProctoringPhoto{}, sql.ErrNoRows
+}
+
+func newService(store *fakeStore, storage *fakes.Uploader) (*Service, *time.Time) {
+	service := NewService(store, storage, Options{
+		Folder:              "proctoring",
+		PolicyVersion:       "v2",
+		CaptureInterval:     time.Minute,
+		MaxPhotoBytes:       64,
+		MaxPhotosPerAttempt: 2,
+		URLTTL:              5 * time.Minute,
+		RetentionDays:       30,
+	})
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	return service, &now
+}
+
+var attempt = db.ExamAttempt{AttemptID: 3, UserID: 7, StartTime: time.Date(2026, 10, 16, 8, 30, 0, 0, time.UTC)}
+
+func TestUploadRequiresCurrentConsent(t *testing.T) {
+	store, storage := newStore(), fakes.NewUploader()
+	service, _ := newService(store, storage)
+	ctx := context.Background()
+
+	_, err := service.Upload(ctx, attempt, Snapshot{Data: jpeg})
+	assert.ErrorIs(t, err, ErrConsentRequired)
+
+	_, err = service.Consent(ctx, attempt, "v1", "10.0.0.1", "browser")
+	assert.ErrorIs(t, err, ErrPolicyMismatch)
+
+	// A consent to an earlier policy does not count
+	store.consents[attempt.AttemptID] = db.ExamProctoringConsent{AttemptID: attempt.AttemptID, PolicyVersion: "v1"}
+	status, err := service.Status(ctx, attempt)
+	require.NoError(t, err)
+	assert.True(t, status.ConsentRequired)
+	_, err = service.Upload(ctx, attempt, Snapshot{Data: jpeg})
+	assert.ErrorIs(t, err, ErrConsentRequired)
+
+	consent, err := service.Consent(ctx, attempt, "v2", "10.0.0.1", "browser")
+	require.NoError(t, err)
+	assert.Equal(t, "v2", consent.PolicyVersion)
+	status, err = service.Status(ctx, attempt)
+	require.NoError(t, err)
+	assert.False(t, status.ConsentRequired)
+	assert.Equal(t, int64(60), status.CaptureIntervalSeconds)
+	assert.Empty(t, storage.Uploads())
+}
+
+func TestUploadEnforcesLimits(t *testing.T) {
+	store, storage := newStore(), fakes.NewUploader()
+	service, now := newService(store, storage)
+	ctx := context.Background()
+	_, err := service.Consent(ctx, attempt, "v2", "", "")
+	require.NoError(t, err)
+
+	_, err = service.Upload(ctx, attempt, Snapshot{Data: []byte("GIF89a not allowed")})
+	assert.ErrorIs(t, err, ErrUnsupportedImage)
+	_, err = service.Upload(ctx, attempt, Snapshot{Data: append(jpeg, make([]byte, 64)...)})
+	assert.ErrorIs(t, err, ErrPhotoTooLarge)
+
+	// Capture times before the attempt are moved to its start
+	photo, err := service.Upload(ctx, attempt, Snapshot{Data: jpeg, CapturedAt: attempt.StartTime.Add(-time.Hour)})
+	require.NoError(t, err)
+	assert.Equal(t, attempt.StartTime, photo.CapturedAt)
+	require.Len(t, storage.Uploads(), 1)
+	assert.Equal(t, "private_image", storage.Uploads()[0].Kind)
+	assert.Regexp(t, `^proctoring/attempt-3/[0-9a-f]{32}$`, storage.Uploads()[0].Name)
+
+	*now = now.Add(20 * time.Second)
+	_, err = service.Upload(ctx, attempt, Snapshot{Data: jpeg})
+	assert.ErrorIs(t, err, ErrTooFrequent)
+
+	*now = now.Add(20 * time.Second)
+	_, err = service.Upload(ctx, attempt, Snapshot{Data: jpeg})
+	require.NoError(t, err)
+
+	*now = now.Add(time.Minute)
+	_, err = service.Upload(ctx, attempt, Snapshot{Data: jpeg})
+	assert.ErrorIs(t, err, ErrPhotoLimitReached)
+}
+
+func TestUploadDeletesImageWhenNotRecorded(t *testing.T) {
+	store, storage := newStore(), fakes.NewUploader()
+	service, _ := newService(store, storage)
+	ctx := context.Background()
+	_, err := service.Consent(ctx, attempt, "v2", "", "")
+	require.NoError(t, err)
+
+	store.createErr = errors.New("connection reset")
+	_, err = service.Upload(ctx, attempt, Snapshot{Data: jpeg})
+	assert.Error(t, err)
+	assert.Empty(t, storage.Uploads())
+}
+
+func TestPhotosAreSignedAndReviewed(t *testing.T) {
+	store, storage := newStore(), fakes.NewUploader()
+	service, now := newService(store, storage)
+	ctx := context.Background()
+	_, err := service.Consent(ctx, attempt, "v2", "", "")
+	require.NoError(t, err)
+	uploaded, err := service.Upload(ctx, attempt, Snapshot{Data: jpeg})
+	require.NoError(t, err)
+	assert.Empty(t, uploaded.URL)
+
+	photos, err := service.Photos(ctx, attempt.AttemptID)
+	require.NoError(t, err)
+	require.Len(t, photos, 1)
+	assert.Contains(t, photos[0].URL, "signature=fake")
+	require.NotNil(t, photos[0].URLExpiresAt)
+	assert.Equal(t, now.Add(5*time.Minute), *photos[0].URLExpiresAt)
+
+	note := "Second person in view"
+	reviewed, err := service.Review(ctx, uploaded.ID, 1, Review{Flagged: true, Note: &note})
+	require.NoError(t, err)
+	assert.True(t, reviewed.Flagged)
+	assert.Equal(t, &note, reviewed.ReviewNote)
+	require.NotNil(t, reviewed.ReviewedBy)
+	assert.Equal(t, int32(1), *reviewed.ReviewedBy)
+
+	_, err = service.Review(ctx, 99, 1, Review{})
+	assert.ErrorIs(t, err, ErrPhotoNotFound)
+}
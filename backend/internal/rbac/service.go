@@ -241,6 +241,39 @@ func (s *Service) AssignRole(ctx context.Context, userID, roleID, assignedBy int
 	return nil
 }
 
+// ExtendRole grants a role for a limited time. An unexpired grant is extended
+// from its current expiry; a permanent assignment is left unchanged. It returns
+// the new expiry, nil when the assignment is permanent.
+func (s *Service) ExtendRole(ctx context.Context, userID int32, roleName string, duration time.Duration) (*time.Time, error) {
+	role, err := s.store.GetRoleByName(ctx, roleName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get role %s: %w", roleName, err)
+	}
+	assignments, err := s.store.GetUserRoleAssignments(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user role assignments: %w", err)
+	}
+
+	expiresAt := time.Now()
+	for _, assignment := range assignments {
+		if assignment.RoleID != role.ID {
+			continue
+		}
+		if !assignment.ExpiresAt.Valid {
+			return nil, nil
+		}
+		if assignment.ExpiresAt.Time.After(expiresAt) {
+			expiresAt = assignment.ExpiresAt.Time
+		}
+	}
+	expiresAt = expiresAt.Add(duration)
+
+	if err := s.AssignRole(ctx, userID, role.ID, 0, &expiresAt); err != nil {
+		return nil, err
+	}
+	return &expiresAt, nil
+}
+
 // RemoveRole removes a role from a user
 func (s *Service) RemoveRole(ctx context.Context, userID, roleID int32) error {
 	err := s.store.RemoveRoleFromUser(ctx, db.RemoveRoleFromUserParams{
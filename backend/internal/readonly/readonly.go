@@ -0,0 +1,237 @@
+// Package readonly switches the API to read-only while the database is
+// restored or migrated. Writes are refused and reads keep being served. The
+// state is kept in a file or in Redis, so the backup-admin tool and every
+// API instance see the same switch.
+package readonly
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/toeic-app/internal/config"
+	"github.com/toeic-app/internal/logger"
+)
+
+// redisKey holds the state when it is kept in Redis
+const redisKey = "toeic:read_only"
+
+// ErrStatic is returned when switching off read-only mode forced by
+// READ_ONLY_MODE
+var ErrStatic = errors.New("read-only mode is forced by READ_ONLY_MODE")
+
+// State tells whether the API is read-only and why
+type State struct {
+	Enabled bool       `json:"enabled"`
+	Reason  string     `json:"reason,omitempty"`
+	SetBy   string     `json:"set_by,omitempty"`
+	Since   *time.Time `json:"since,omitempty"`
+	// Static is set while READ_ONLY_MODE forces read-only mode, which
+	// cannot be switched off at runtime
+	Static bool `json:"static"`
+}
+
+// Store keeps the state shared by the instances
+type Store interface {
+	Load(ctx context.Context) (State, error)
+	Save(ctx context.Context, state State) error
+}
+
+// NewStore creates the store configured by READ_ONLY_BACKEND
+func NewStore(cfg config.Config) Store {
+	if cfg.ReadOnlyBackend == "redis" {
+		return NewRedisStore(redis.NewClient(&redis.Options{
+			Addr:     cfg.RedisAddr,
+			Password: cfg.RedisPassword,
+			DB:       cfg.RedisDB,
+		}))
+	}
+	return NewFileStore(cfg.ReadOnlyStateFile)
+}
+
+// FileStore keeps the state in a file, for instances sharing a disk
+type FileStore struct {
+	path string
+}
+
+// NewFileStore creates a store for the file at path
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+func (s *FileStore) Load(_ context.Context) (State, error) {
+	var state State
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return state, err
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("invalid read-only state file: %w", err)
+	}
+	return state, nil
+}
+
+// Save writes the state, or removes the file when read-only mode is off
+func (s *FileStore) Save(_ context.Context, state State) error {
+	if !state.Enabled {
+		if err := os.Remove(s.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// RedisStore keeps the state in Redis, for instances on several hosts
+type RedisStore struct {
+	client *redis.Client
+}
+
+// NewRedisStore creates a store on client
+func NewRedisStore(client *redis.Client) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+func (s *RedisStore) Load(ctx context.Context) (State, error) {
+	var state State
+	data, err := s.client.Get(ctx, redisKey).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return state, nil
+	}
+	if err != nil {
+		return state, err
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("invalid read-only state: %w", err)
+	}
+	return state, nil
+}
+
+func (s *RedisStore) Save(ctx context.Context, state State) error {
+	if !state.Enabled {
+		return s.client.Del(ctx, redisKey).Err()
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, redisKey, data, 0).Err()
+}
+
+// Mode answers whether the API is read-only. The shared state is read at
+// most once per refresh interval.
+type Mode struct {
+	store   Store
+	static  func() bool
+	refresh time.Duration
+	now     func() time.Time
+
+	mu        sync.Mutex
+	cached    State
+	fetchedAt time.Time
+}
+
+// NewMode creates a mode on store. static reports whether READ_ONLY_MODE
+// forces read-only mode; it is checked on every call so configuration
+// reloads apply at once.
+func NewMode(store Store, static func() bool, refresh time.Duration) *Mode {
+	if static == nil {
+		static = func() bool { return false }
+	}
+	return &Mode{store: store, static: static, refresh: refresh, now: time.Now}
+}
+
+// Status returns the current state. When the store cannot be read the last
+// known state is kept.
+func (m *Mode) Status(ctx context.Context) State {
+	if m.static() {
+		return State{Enabled: true, Static: true, Reason: "READ_ONLY_MODE is set"}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.fetchedAt.IsZero() && m.now().Sub(m.fetchedAt) < m.refresh {
+		return m.cached
+	}
+	state, err := m.store.Load(ctx)
+	if err != nil {
+		logger.Warn("Failed to read read-only state, keeping the last known one: %v", err)
+		return m.cached
+	}
+	m.cached, m.fetchedAt = state, m.now()
+	return state
+}
+
+// Enable makes the API read-only
+func (m *Mode) Enable(ctx context.Context, reason, setBy string) (State, error) {
+	since := m.now()
+	state := State{Enabled: true, Reason: reason, SetBy: setBy, Since: &since}
+	if err := m.save(ctx, state); err != nil {
+		return State{}, err
+	}
+	return m.Status(ctx), nil
+}
+
+// Disable lets the API accept writes again
+func (m *Mode) Disable(ctx context.Context) (State, error) {
+	if m.static() {
+		return m.Status(ctx), ErrStatic
+	}
+	if err := m.save(ctx, State{}); err != nil {
+		return State{}, err
+	}
+	return m.Status(ctx), nil
+}
+
+// Acquire makes the API read-only for an operation such as a restore. The
+// returned function switches it back, unless it was already read-only
+// before, in which case it is left to whoever switched it on.
+func (m *Mode) Acquire(ctx context.Context, reason, setBy string) (func(), error) {
+	m.invalidate()
+	if m.Status(ctx).Enabled {
+		return func() {}, nil
+	}
+	if _, err := m.Enable(ctx, reason, setBy); err != nil {
+		return nil, err
+	}
+	return func() {
+		if _, err := m.Disable(context.Background()); err != nil {
+			logger.Error("Failed to switch off read-only mode after %s: %v", reason, err)
+		}
+	}, nil
+}
+
+func (m *Mode) save(ctx context.Context, state State) error {
+	if err := m.store.Save(ctx, state); err != nil {
+		return fmt.Errorf("failed to save read-only state: %w", err)
+	}
+	m.invalidate()
+	return nil
+}
+
+// invalidate makes the next Status read the store
+func (m *Mode) invalidate() {
+	m.mu.Lock()
+	m.fetchedAt = time.Time{}
+	m.mu.Unlock()
+}
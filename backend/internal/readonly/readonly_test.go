@@ -0,0 +1,83 @@
+package readonly
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toeic-app/internal/fakes"
+)
+
+func TestStoresShareTheSwitch(t *testing.T) {
+	ctx := context.Background()
+	server := fakes.NewRedis(t)
+	stores := map[string]Store{
+		"file":  NewFileStore(filepath.Join(t.TempDir(), "maintenance", "read_only.json")),
+		"redis": NewRedisStore(redis.NewClient(&redis.Options{Addr: server.Addr()})),
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			api := NewMode(store, nil, time.Minute)
+			admin := NewMode(store, nil, time.Minute)
+			assert.False(t, api.Status(ctx).Enabled)
+
+			_, err := admin.Enable(ctx, "restore", "backup-admin")
+			require.NoError(t, err)
+			// The API instance reads the store again once its copy expired
+			assert.False(t, api.Status(ctx).Enabled)
+			api.invalidate()
+			state := api.Status(ctx)
+			assert.True(t, state.Enabled)
+			assert.Equal(t, "restore", state.Reason)
+			assert.Equal(t, "backup-admin", state.SetBy)
+
+			state, err = admin.Disable(ctx)
+			require.NoError(t, err)
+			assert.False(t, state.Enabled)
+			api.invalidate()
+			assert.False(t, api.Status(ctx).Enabled)
+		})
+	}
+}
+
+func TestAcquireLeavesExistingReadOnlyModeOn(t *testing.T) {
+	ctx := context.Background()
+	mode := NewMode(NewFileStore(filepath.Join(t.TempDir(), "read_only.json")), nil, time.Minute)
+
+	release, err := mode.Acquire(ctx, "restore", "admin")
+	require.NoError(t, err)
+	assert.True(t, mode.Status(ctx).Enabled)
+	release()
+	assert.False(t, mode.Status(ctx).Enabled)
+
+	// Switched on for a migration before the restore started
+	_, err = mode.Enable(ctx, "migration", "admin")
+	require.NoError(t, err)
+	release, err = mode.Acquire(ctx, "restore", "admin")
+	require.NoError(t, err)
+	release()
+	state := mode.Status(ctx)
+	assert.True(t, state.Enabled)
+	assert.Equal(t, "migration", state.Reason)
+}
+
+func TestStaticModeCannotBeSwitchedOff(t *testing.T) {
+	ctx := context.Background()
+	static := true
+	mode := NewMode(NewFileStore(filepath.Join(t.TempDir(), "read_only.json")), func() bool { return static }, time.Minute)
+
+	state := mode.Status(ctx)
+	assert.True(t, state.Enabled)
+	assert.True(t, state.Static)
+	_, err := mode.Disable(ctx)
+	assert.ErrorIs(t, err, ErrStatic)
+
+	// A configuration reload unsetting READ_ONLY_MODE applies at once
+	static = false
+	assert.False(t, mode.Status(ctx).Enabled)
+}
@@ -0,0 +1,327 @@
+package referral
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/logger"
+)
+
+// Referral statuses
+const (
+	StatusPending   = "pending"
+	StatusConverted = "converted"
+	StatusRejected  = "rejected"
+)
+
+// Reasons a referral is rejected as a suspected self-referral
+const (
+	RejectSameEmail = "same_email" // Referred email is an alias of the referrer's
+	RejectSameIP    = "repeat_ip"  // Another account referred by the same user signed up from this IP recently
+)
+
+// RewardRole is the time-limited role granted to referrers
+const RewardRole = "premium"
+
+const (
+	codeLength   = 8
+	codeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789" // No 0/O or 1/I
+	codeAttempts = 5
+	ipWindow     = 24 * time.Hour
+)
+
+// ErrInvalidCode is returned for unknown referral codes
+var ErrInvalidCode = errors.New("invalid referral code")
+
+// RoleGranter grants time-limited roles
+type RoleGranter interface {
+	ExtendRole(ctx context.Context, userID int32, roleName string, duration time.Duration) (*time.Time, error)
+}
+
+// Summary is the referral code of a user with the results of their referrals
+type Summary struct {
+	Code        string `json:"code"`
+	Signups     int64  `json:"signups"`
+	Conversions int64  `json:"conversions"`
+	RewardDays  int64  `json:"reward_days"`
+}
+
+// Referrer is a row of the top referrers in the report
+type Referrer struct {
+	UserID      int32  `json:"user_id"`
+	Username    string `json:"username"`
+	Signups     int64  `json:"signups"`
+	Conversions int64  `json:"conversions"`
+	Rejected    int64  `json:"rejected"`
+}
+
+// Report describes referral conversion since a date
+type Report struct {
+	Since          time.Time        `json:"since"`
+	Total          int64            `json:"total"`
+	Pending        int64            `json:"pending"`
+	Converted      int64            `json:"converted"`
+	Rejected       int64            `json:"rejected"`
+	ConversionRate float64          `json:"conversion_rate"` // Converted share of referrals not rejected
+	RewardDays     int64            `json:"reward_days"`
+	RejectReasons  map[string]int64 `json:"reject_reasons"`
+	TopReferrers   []Referrer       `json:"top_referrers"`
+}
+
+// Service tracks referral codes, attributions and rewards
+type Service struct {
+	store      db.Querier
+	roles      RoleGranter
+	rewardDays int32
+}
+
+// NewService creates a new referral service. Referrers receive rewardDays of
+// the premium role per converted referral; 0 disables rewards.
+func NewService(store db.Querier, roles RoleGranter, rewardDays int32) *Service {
+	return &Service{store: store, roles: roles, rewardDays: rewardDays}
+}
+
+// NormalizeCode returns a referral code as stored
+func NormalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// NormalizeEmail reduces an email to the mailbox it delivers to, so aliases
+// of one address compare equal: the part after + is dropped, and dots are
+// ignored for Gmail addresses
+func NormalizeEmail(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return email
+	}
+	local, domain := email[:at], email[at+1:]
+	if plus := strings.Index(local, "+"); plus >= 0 {
+		local = local[:plus]
+	}
+	if domain == "googlemail.com" {
+		domain = "gmail.com"
+	}
+	if domain == "gmail.com" {
+		local = strings.ReplaceAll(local, ".", "")
+	}
+	return local + "@" + domain
+}
+
+// Code returns the referral code of a user, creating it on first use
+func (s *Service) Code(ctx context.Context, userID int32) (string, error) {
+	existing, err := s.store.GetReferralCodeByUser(ctx, userID)
+	if err == nil {
+		return existing.Code, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("failed to get referral code: %w", err)
+	}
+
+	for attempt := 0; attempt < codeAttempts; attempt++ {
+		code, err := generateCode()
+		if err != nil {
+			return "", err
+		}
+		if _, err := s.store.CreateReferralCode(ctx, db.CreateReferralCodeParams{UserID: userID, Code: code}); err != nil {
+			return "", fmt.Errorf("failed to create referral code: %w", err)
+		}
+		// Nothing is inserted when the code is taken or another request created one first
+		created, err := s.store.GetReferralCodeByUser(ctx, userID)
+		if err == nil {
+			return created.Code, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return "", fmt.Errorf("failed to get referral code: %w", err)
+		}
+	}
+	return "", fmt.Errorf("failed to generate a unique referral code")
+}
+
+func generateCode() (string, error) {
+	var b strings.Builder
+	max := big.NewInt(int64(len(codeAlphabet)))
+	for i := 0; i < codeLength; i++ {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate referral code: %w", err)
+		}
+		b.WriteByte(codeAlphabet[n.Int64()])
+	}
+	return b.String(), nil
+}
+
+// Lookup returns the owner of a referral code
+func (s *Service) Lookup(ctx context.Context, code string) (int32, error) {
+	owner, err := s.store.GetReferralCode(ctx, NormalizeCode(code))
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrInvalidCode
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get referral code: %w", err)
+	}
+	return owner.UserID, nil
+}
+
+// Attribute records that a new user registered with the code of referrerID.
+// Suspected self-referrals are recorded as rejected and never rewarded.
+func (s *Service) Attribute(ctx context.Context, referrerID int32, code string, referred db.User, signupIP string) (db.Referral, error) {
+	reason, err := s.fraudCheck(ctx, referrerID, referred, signupIP)
+	if err != nil {
+		return db.Referral{}, err
+	}
+
+	status := StatusPending
+	if reason != "" {
+		status = StatusRejected
+		logger.WarnWithFields(logger.Fields{
+			"referrer_id": referrerID,
+			"referred_id": referred.ID,
+			"reason":      reason,
+		}, "Referral rejected as suspected self-referral")
+	}
+
+	referral, err := s.store.CreateReferral(ctx, db.CreateReferralParams{
+		ReferrerID:   referrerID,
+		ReferredID:   referred.ID,
+		Code:         NormalizeCode(code),
+		Status:       status,
+		RejectReason: sql.NullString{String: reason, Valid: reason != ""},
+		SignupIp:     sql.NullString{String: signupIP, Valid: signupIP != ""},
+	})
+	if err != nil {
+		return referral, fmt.Errorf("failed to record referral: %w", err)
+	}
+	return referral, nil
+}
+
+// fraudCheck returns the reason a referral looks like a self-referral, or ""
+func (s *Service) fraudCheck(ctx context.Context, referrerID int32, referred db.User, signupIP string) (string, error) {
+	referrer, err := s.store.GetUser(ctx, referrerID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get referrer: %w", err)
+	}
+	if referrer.Email.Valid && referred.Email.Valid &&
+		NormalizeEmail(referrer.Email.String) == NormalizeEmail(referred.Email.String) {
+		return RejectSameEmail, nil
+	}
+
+	if signupIP != "" {
+		count, err := s.store.CountReferralsFromIP(ctx, db.CountReferralsFromIPParams{
+			ReferrerID: referrerID,
+			SignupIp:   sql.NullString{String: signupIP, Valid: true},
+			CreatedAt:  time.Now().Add(-ipWindow),
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to count referrals from IP: %w", err)
+		}
+		if count > 0 {
+			return RejectSameIP, nil
+		}
+	}
+	return "", nil
+}
+
+// Convert marks the pending referral of a user as converted and rewards the
+// referrer. It does nothing for users who were not referred.
+func (s *Service) Convert(ctx context.Context, referredID int32) error {
+	referral, err := s.store.ConvertReferral(ctx, referredID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to convert referral: %w", err)
+	}
+	if s.rewardDays <= 0 || s.roles == nil {
+		return nil
+	}
+
+	expiresAt, err := s.roles.ExtendRole(ctx, referral.ReferrerID, RewardRole, time.Duration(s.rewardDays)*24*time.Hour)
+	if err != nil {
+		return fmt.Errorf("failed to reward referrer: %w", err)
+	}
+	if err := s.store.MarkReferralRewarded(ctx, db.MarkReferralRewardedParams{ID: referral.ID, RewardDays: s.rewardDays}); err != nil {
+		return fmt.Errorf("failed to mark referral rewarded: %w", err)
+	}
+
+	fields := logger.Fields{"referral_id": referral.ID, "referrer_id": referral.ReferrerID, "reward_days": s.rewardDays}
+	if expiresAt != nil {
+		fields["premium_until"] = expiresAt.Format(time.RFC3339)
+	}
+	logger.InfoWithFields(fields, "Referral converted and rewarded")
+	return nil
+}
+
+// Summary returns the referral code of a user and the results of their referrals
+func (s *Service) Summary(ctx context.Context, userID int32) (*Summary, error) {
+	code, err := s.Code(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := s.store.GetReferrerStats(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get referral stats: %w", err)
+	}
+	return &Summary{
+		Code:        code,
+		Signups:     stats.Signups,
+		Conversions: stats.Conversions,
+		RewardDays:  stats.RewardDays,
+	}, nil
+}
+
+// Report summarizes referrals created since a date
+func (s *Service) Report(ctx context.Context, since time.Time, topReferrers int32) (*Report, error) {
+	summary, err := s.store.GetReferralSummary(ctx, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get referral summary: %w", err)
+	}
+	reasons, err := s.store.ListReferralRejectReasons(ctx, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reject reasons: %w", err)
+	}
+	referrers, err := s.store.ListTopReferrers(ctx, db.ListTopReferrersParams{Since: since, MaxItems: topReferrers})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list top referrers: %w", err)
+	}
+
+	report := &Report{
+		Since:         since,
+		Total:         summary.Total,
+		Pending:       summary.Pending,
+		Converted:     summary.Converted,
+		Rejected:      summary.Rejected,
+		RewardDays:    summary.RewardDays,
+		RejectReasons: map[string]int64{},
+		TopReferrers:  make([]Referrer, 0, len(referrers)),
+	}
+	report.ConversionRate = ConversionRate(summary.Converted, summary.Total-summary.Rejected)
+	for _, reason := range reasons {
+		report.RejectReasons[reason.RejectReason.String] = reason.Count
+	}
+	for _, referrer := range referrers {
+		report.TopReferrers = append(report.TopReferrers, Referrer{
+			UserID:      referrer.ReferrerID,
+			Username:    referrer.Username,
+			Signups:     referrer.Signups,
+			Conversions: referrer.Conversions,
+			Rejected:    referrer.Rejected,
+		})
+	}
+	return report, nil
+}
+
+// ConversionRate returns converted/eligible rounded to 4 decimals, 0 without eligible referrals
+func ConversionRate(converted, eligible int64) float64 {
+	if eligible <= 0 {
+		return 0
+	}
+	return float64(converted*10000/eligible) / 10000
+}
@@ -0,0 +1,35 @@
+package referral
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeEmail(t *testing.T) {
+	assert.Equal(t, "johndoe@gmail.com", NormalizeEmail("John.Doe+toeic@GMAIL.com"))
+	assert.Equal(t, "johndoe@gmail.com", NormalizeEmail("johndoe@googlemail.com"))
+	assert.Equal(t, "john.doe@example.com", NormalizeEmail(" john.doe+2@example.com "))
+	assert.NotEqual(t, NormalizeEmail("john@example.com"), NormalizeEmail("jane@example.com"))
+}
+
+func TestNormalizeCode(t *testing.T) {
+	assert.Equal(t, "AB12CD34", NormalizeCode(" ab12cd34 "))
+}
+
+func TestGenerateCode(t *testing.T) {
+	code, err := generateCode()
+	require.NoError(t, err)
+	assert.Len(t, code, codeLength)
+	for _, c := range code {
+		assert.True(t, strings.ContainsRune(codeAlphabet, c), "unexpected character %q", c)
+	}
+}
+
+func TestConversionRate(t *testing.T) {
+	assert.Equal(t, 0.0, ConversionRate(0, 0))
+	assert.Equal(t, 0.25, ConversionRate(1, 4))
+	assert.Equal(t, 0.3333, ConversionRate(1, 3))
+}
@@ -0,0 +1,372 @@
+// Package related links words, grammar, example sentences and questions so
+// detail pages can suggest more content. Links are computed in bulk from tags
+// and co-occurrence and stored, so serving them is a single indexed read.
+package related
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Content types that can be linked
+const (
+	TypeWord     = "word"
+	TypeGrammar  = "grammar"
+	TypeExample  = "example"
+	TypeQuestion = "question"
+)
+
+// Reasons content is linked
+const (
+	// ReasonDefinition links a word to the examples of its dictionary entry
+	ReasonDefinition = "definition"
+	// ReasonCurated links grammar an author listed as related
+	ReasonCurated = "curated"
+	// ReasonTag links content sharing a tag
+	ReasonTag = "tag"
+	// ReasonCooccurrence links a word to text it appears in, and words
+	// appearing in the same sentences
+	ReasonCooccurrence = "co-occurrence"
+	// ReasonMention links grammar to questions whose explanation names it
+	ReasonMention = "mention"
+)
+
+const (
+	// maxDocumentShare drops words found in more than this share of texts,
+	// such as "the" and "have", which relate everything to everything
+	maxDocumentShare = 0.02
+	// minDocumentLimit keeps small corpora from treating every word as common
+	minDocumentLimit = 50
+	// maxTagSize skips tags too broad to say two words are related
+	maxTagSize = 500
+	// minMentionLength keeps grammar names like "to" from matching every explanation
+	minMentionLength = 5
+)
+
+// Word is a dictionary word with its tags and definition examples
+type Word struct {
+	ID       int32
+	Text     string
+	Tags     []string
+	Examples []int32
+}
+
+// Grammar is a published grammar with the text of its examples and formulas
+type Grammar struct {
+	ID      int32
+	Title   string
+	Tags    []string
+	Related []int32
+	Text    string
+}
+
+// Example is an example sentence
+type Example struct {
+	ID   int32
+	Text string
+}
+
+// Question is an exam question. Text is what learners read; Explanation is
+// searched for grammar names.
+type Question struct {
+	ID          int32
+	Text        string
+	Explanation string
+}
+
+// Corpus is the content links are computed from
+type Corpus struct {
+	Words     []Word
+	Grammars  []Grammar
+	Examples  []Example
+	Questions []Question
+}
+
+// Relation links a source to a target. Every relation is stored in both
+// directions.
+type Relation struct {
+	SourceType string
+	SourceID   int32
+	TargetType string
+	TargetID   int32
+	Score      float32
+	Reason     string
+}
+
+type node struct {
+	kind string
+	id   int32
+}
+
+type link struct {
+	score  float64
+	reason string
+	// best is the largest single contribution, whose reason the link keeps
+	best float64
+}
+
+type graph map[node]map[node]*link
+
+// add links a and b both ways, adding score to what they already share
+func (g graph) add(a, b node, score float64, reason string) {
+	if a == b || score <= 0 {
+		return
+	}
+	g.addOne(a, b, score, reason)
+	g.addOne(b, a, score, reason)
+}
+
+func (g graph) addOne(from, to node, score float64, reason string) {
+	targets := g[from]
+	if targets == nil {
+		targets = make(map[node]*link)
+		g[from] = targets
+	}
+	l := targets[to]
+	if l == nil {
+		l = &link{}
+		targets[to] = l
+	}
+	l.score += score
+	if score > l.best {
+		l.best, l.reason = score, reason
+	}
+}
+
+// Build computes the relations of a corpus, keeping the limit best targets
+// of each type for every source
+func Build(corpus Corpus, limit int) []Relation {
+	g := make(graph)
+
+	examples := make(map[int32]bool, len(corpus.Examples))
+	for _, example := range corpus.Examples {
+		examples[example.ID] = true
+	}
+	for _, word := range corpus.Words {
+		for _, id := range word.Examples {
+			if examples[id] {
+				g.add(node{TypeWord, word.ID}, node{TypeExample, id}, 1, ReasonDefinition)
+			}
+		}
+	}
+
+	linkCooccurrence(g, corpus)
+	linkWordTags(g, corpus.Words)
+	linkGrammar(g, corpus.Grammars)
+	linkMentions(g, corpus.Grammars, corpus.Questions)
+
+	return rank(g, limit)
+}
+
+// linkCooccurrence links words to the texts they appear in, weighting rare
+// words higher, and words to each other when they share sentences
+func linkCooccurrence(g graph, corpus Corpus) {
+	type document struct {
+		node   node
+		tokens []string
+		weight float64
+	}
+	var documents []document
+	for _, example := range corpus.Examples {
+		documents = append(documents, document{node{TypeExample, example.ID}, tokens(example.Text), 0.5})
+	}
+	for _, question := range corpus.Questions {
+		documents = append(documents, document{node{TypeQuestion, question.ID}, tokens(question.Text), 0.5})
+	}
+	for _, grammar := range corpus.Grammars {
+		documents = append(documents, document{node{TypeGrammar, grammar.ID}, tokens(grammar.Text), 0.3})
+	}
+	if len(documents) == 0 {
+		return
+	}
+
+	words := make(map[string]int32, len(corpus.Words))
+	for _, word := range corpus.Words {
+		words[strings.ToLower(strings.TrimSpace(word.Text))] = word.ID
+	}
+
+	frequency := make(map[string]int)
+	for _, doc := range documents {
+		for _, token := range doc.tokens {
+			frequency[token]++
+		}
+	}
+	total := float64(len(documents))
+	maxIDF := math.Log(total + 1)
+	maxFrequency := max(minDocumentLimit, int(total*maxDocumentShare))
+
+	for _, doc := range documents {
+		var found []node
+		var weights []float64
+		for _, token := range doc.tokens {
+			id, ok := words[token]
+			if !ok || frequency[token] > maxFrequency {
+				continue
+			}
+			weight := math.Log(total/float64(frequency[token])+1) / maxIDF
+			found = append(found, node{TypeWord, id})
+			weights = append(weights, weight)
+			g.add(node{TypeWord, id}, doc.node, doc.weight*weight, ReasonCooccurrence)
+		}
+		if doc.node.kind == TypeGrammar {
+			continue
+		}
+		for i := range found {
+			for j := i + 1; j < len(found); j++ {
+				g.add(found[i], found[j], 0.2*weights[i]*weights[j], ReasonCooccurrence)
+			}
+		}
+	}
+}
+
+// linkWordTags links words sharing a tag. Smaller tags say more.
+func linkWordTags(g graph, words []Word) {
+	byTag := make(map[string][]int32)
+	for _, word := range words {
+		for _, tag := range word.Tags {
+			byTag[tag] = append(byTag[tag], word.ID)
+		}
+	}
+	for _, ids := range byTag {
+		if len(ids) > maxTagSize {
+			continue
+		}
+		score := 0.3 / math.Log2(float64(len(ids))+1)
+		for i := range ids {
+			for j := i + 1; j < len(ids); j++ {
+				g.add(node{TypeWord, ids[i]}, node{TypeWord, ids[j]}, score, ReasonTag)
+			}
+		}
+	}
+}
+
+// linkGrammar links grammar listed as related and grammar sharing a tag
+func linkGrammar(g graph, grammars []Grammar) {
+	published := make(map[int32]bool, len(grammars))
+	byTag := make(map[string][]int32)
+	for _, grammar := range grammars {
+		published[grammar.ID] = true
+		for _, tag := range grammar.Tags {
+			byTag[tag] = append(byTag[tag], grammar.ID)
+		}
+	}
+	for _, grammar := range grammars {
+		for _, id := range grammar.Related {
+			if published[id] {
+				g.add(node{TypeGrammar, grammar.ID}, node{TypeGrammar, id}, 1, ReasonCurated)
+			}
+		}
+	}
+	for _, ids := range byTag {
+		for i := range ids {
+			for j := i + 1; j < len(ids); j++ {
+				g.add(node{TypeGrammar, ids[i]}, node{TypeGrammar, ids[j]}, 0.5, ReasonTag)
+			}
+		}
+	}
+}
+
+// linkMentions links grammar to questions whose explanation names it, such
+// as "Đại từ phản thân" or "present perfect"
+func linkMentions(g graph, grammars []Grammar, questions []Question) {
+	explanations := make([]string, len(questions))
+	for i, question := range questions {
+		explanations[i] = strings.ToLower(question.Explanation)
+	}
+	for _, grammar := range grammars {
+		for _, term := range grammarTerms(grammar.Title) {
+			for i, explanation := range explanations {
+				if containsTerm(explanation, term) {
+					g.add(node{TypeGrammar, grammar.ID}, node{TypeQuestion, questions[i].ID}, 0.8, ReasonMention)
+				}
+			}
+		}
+	}
+}
+
+// grammarTerms splits a title like "Subject (chủ ngữ)" into the names it
+// can be mentioned by
+func grammarTerms(title string) []string {
+	var terms []string
+	for _, part := range strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return r == '(' || r == ')' || r == ',' || r == ';'
+	}) {
+		part = strings.TrimSpace(part)
+		if utf8.RuneCountInString(part) >= minMentionLength {
+			terms = append(terms, part)
+		}
+	}
+	return terms
+}
+
+// containsTerm reports whether text contains term as whole words
+func containsTerm(text, term string) bool {
+	for offset := 0; ; {
+		i := strings.Index(text[offset:], term)
+		if i < 0 {
+			return false
+		}
+		start, end := offset+i, offset+i+len(term)
+		before, _ := utf8.DecodeLastRuneInString(text[:start])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if (start == 0 || !isWordRune(before)) && (end == len(text) || !isWordRune(after)) {
+			return true
+		}
+		offset = start + 1
+	}
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// tokens returns the distinct lowercase words of text
+func tokens(text string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, token := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '-'
+	}) {
+		token = strings.Trim(token, "-")
+		if len(token) < 2 || seen[token] {
+			continue
+		}
+		seen[token] = true
+		result = append(result, token)
+	}
+	return result
+}
+
+// rank keeps the best targets of each type for every source
+func rank(g graph, limit int) []Relation {
+	var relations []Relation
+	for source, targets := range g {
+		byType := make(map[string][]Relation)
+		for target, l := range targets {
+			byType[target.kind] = append(byType[target.kind], Relation{
+				SourceType: source.kind,
+				SourceID:   source.id,
+				TargetType: target.kind,
+				TargetID:   target.id,
+				Score:      float32(l.score),
+				Reason:     l.reason,
+			})
+		}
+		for _, candidates := range byType {
+			sort.Slice(candidates, func(i, j int) bool {
+				if candidates[i].Score != candidates[j].Score {
+					return candidates[i].Score > candidates[j].Score
+				}
+				return candidates[i].TargetID < candidates[j].TargetID
+			})
+			if len(candidates) > limit {
+				candidates = candidates[:limit]
+			}
+			relations = append(relations, candidates...)
+		}
+	}
+	return relations
+}
@@ -0,0 +1,87 @@
+package related
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func targets(relations []Relation, sourceType string, sourceID int32, targetType string) map[int32]string {
+	found := make(map[int32]string)
+	for _, r := range relations {
+		if r.SourceType == sourceType && r.SourceID == sourceID && r.TargetType == targetType {
+			found[r.TargetID] = r.Reason
+		}
+	}
+	return found
+}
+
+func TestBuildLinksWordsToTheirExamples(t *testing.T) {
+	corpus := Corpus{
+		Words: []Word{
+			{ID: 1, Text: "invoice", Examples: []int32{10, 99}},
+			{ID: 2, Text: "payment"},
+		},
+		Examples: []Example{
+			{ID: 10, Text: "send the invoice"},
+			{ID: 11, Text: "the invoice is due on payment"},
+		},
+		Questions: []Question{{ID: 5, Text: "Please pay the ____ by Friday\ninvoice\ninvoiced"}},
+	}
+
+	relations := Build(corpus, 10)
+
+	examples := targets(relations, TypeWord, 1, TypeExample)
+	assert.Equal(t, ReasonDefinition, examples[10])
+	assert.Equal(t, ReasonCooccurrence, examples[11])
+	assert.NotContains(t, examples, int32(99), "missing examples are not linked")
+
+	assert.Contains(t, targets(relations, TypeWord, 1, TypeQuestion), int32(5))
+	assert.Contains(t, targets(relations, TypeQuestion, 5, TypeWord), int32(1), "links go both ways")
+	assert.Contains(t, targets(relations, TypeWord, 1, TypeWord), int32(2), "words in one sentence are related")
+}
+
+func TestBuildIgnoresCommonWords(t *testing.T) {
+	corpus := Corpus{Words: []Word{{ID: 1, Text: "the"}}}
+	for i := int32(1); i <= 200; i++ {
+		corpus.Examples = append(corpus.Examples, Example{ID: i, Text: "the sentence"})
+	}
+
+	assert.Empty(t, targets(Build(corpus, 10), TypeWord, 1, TypeExample))
+}
+
+func TestBuildLinksGrammar(t *testing.T) {
+	corpus := Corpus{
+		Grammars: []Grammar{
+			{ID: 1, Title: "Đại từ phản thân (reflexive pronouns)", Tags: []string{"Đại từ"}, Related: []int32{3}},
+			{ID: 2, Title: "Đại từ sở hữu", Tags: []string{"Đại từ"}},
+			{ID: 3, Title: "Subject (chủ ngữ)"},
+		},
+		Questions: []Question{
+			{ID: 7, Explanation: "D. Chính cô ấy: Đại từ phản thân"},
+			{ID: 8, Explanation: "A. Cô ấy: Chủ ngữ"},
+			{ID: 9, Explanation: "Subjective opinions"},
+		},
+	}
+
+	relations := Build(corpus, 10)
+
+	grammars := targets(relations, TypeGrammar, 1, TypeGrammar)
+	assert.Equal(t, ReasonCurated, grammars[3])
+	assert.Equal(t, ReasonTag, grammars[2])
+
+	assert.Equal(t, map[int32]string{7: ReasonMention}, targets(relations, TypeGrammar, 1, TypeQuestion))
+	assert.Equal(t, map[int32]string{8: ReasonMention}, targets(relations, TypeGrammar, 3, TypeQuestion),
+		"names match whole words only")
+}
+
+func TestBuildKeepsBestTargets(t *testing.T) {
+	corpus := Corpus{Words: []Word{{ID: 1, Text: "invoice", Examples: []int32{1}}}}
+	for i := int32(1); i <= 5; i++ {
+		corpus.Examples = append(corpus.Examples, Example{ID: i, Text: "an invoice"})
+	}
+
+	examples := targets(Build(corpus, 2), TypeWord, 1, TypeExample)
+	assert.Len(t, examples, 2)
+	assert.Equal(t, ReasonDefinition, examples[1])
+}
@@ -0,0 +1,358 @@
+package related
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/logger"
+)
+
+var (
+	ErrUnknownType       = errors.New("unknown content type")
+	ErrRebuildInProgress = errors.New("related content is already being rebuilt")
+)
+
+const (
+	// targetsPerType is how many targets of each type a source keeps
+	targetsPerType = 8
+	// upsertBatchSize is how many relations are written per statement
+	upsertBatchSize = 1000
+)
+
+// Item is a piece of content related to another
+type Item struct {
+	Type   string `json:"type"`
+	ID     int32  `json:"id"`
+	Label  string `json:"label"`
+	Detail string `json:"detail,omitempty"`
+	// ContentID is the exam content a question belongs to
+	ContentID *int32  `json:"content_id,omitempty"`
+	Score     float32 `json:"score"`
+	Reason    string  `json:"reason"`
+}
+
+// Stats describes a rebuild
+type Stats struct {
+	Relations  int           `json:"relations"`
+	Removed    int64         `json:"removed"`
+	Duration   time.Duration `json:"duration" swaggertype:"integer"`
+	ComputedAt time.Time     `json:"computed_at"`
+}
+
+// Summary describes the stored relations
+type Summary struct {
+	Relations  int64      `json:"relations"`
+	ComputedAt *time.Time `json:"computed_at,omitempty"`
+}
+
+// Service computes and serves related content
+type Service struct {
+	store      db.Querier
+	now        func() time.Time
+	rebuilding atomic.Bool
+
+	mutex     sync.Mutex
+	isRunning bool
+	stopChan  chan struct{}
+	wg        sync.WaitGroup
+}
+
+// NewService creates a related content service
+func NewService(store db.Querier) *Service {
+	return &Service{store: store, now: time.Now}
+}
+
+// ValidType reports whether content of contentType can have related content
+func ValidType(contentType string) bool {
+	switch contentType {
+	case TypeWord, TypeGrammar, TypeExample, TypeQuestion:
+		return true
+	}
+	return false
+}
+
+// For returns the content related to a piece of content, grouped by type
+// and best first within each type
+func (s *Service) For(ctx context.Context, contentType string, id int32) ([]Item, error) {
+	if !ValidType(contentType) {
+		return nil, ErrUnknownType
+	}
+	rows, err := s.store.ListRelatedContent(ctx, db.ListRelatedContentParams{SourceType: contentType, SourceID: id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list related content: %w", err)
+	}
+
+	items := make([]Item, 0, len(rows))
+	for _, row := range rows {
+		item := Item{
+			Type:   row.TargetType,
+			ID:     row.TargetID,
+			Label:  row.Label,
+			Detail: row.Detail,
+			Score:  row.Score,
+			Reason: row.Reason,
+		}
+		if row.ParentID.Valid {
+			item.ContentID = &row.ParentID.Int32
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// Summary reports how many relations are stored and when they were computed
+func (s *Service) Summary(ctx context.Context) (Summary, error) {
+	row, err := s.store.GetContentRelationsSummary(ctx)
+	if err != nil {
+		return Summary{}, fmt.Errorf("failed to summarize related content: %w", err)
+	}
+	summary := Summary{Relations: row.Relations}
+	if row.ComputedAt.Valid {
+		summary.ComputedAt = &row.ComputedAt.Time
+	}
+	return summary, nil
+}
+
+// Rebuild recomputes every relation. Relations are replaced in place, so
+// related content keeps being served while a rebuild runs.
+func (s *Service) Rebuild(ctx context.Context) (Stats, error) {
+	if !s.rebuilding.CompareAndSwap(false, true) {
+		return Stats{}, ErrRebuildInProgress
+	}
+	defer s.rebuilding.Store(false)
+
+	started := s.now()
+	// Postgres keeps microseconds; the sweep compares against the stored value
+	computedAt := started.UTC().Truncate(time.Microsecond)
+
+	corpus, err := s.load(ctx)
+	if err != nil {
+		return Stats{}, err
+	}
+	relations := Build(corpus, targetsPerType)
+
+	for start := 0; start < len(relations); start += upsertBatchSize {
+		batch := relations[start:min(start+upsertBatchSize, len(relations))]
+		arg := db.UpsertContentRelationsParams{ComputedAt: computedAt}
+		for _, relation := range batch {
+			arg.SourceTypes = append(arg.SourceTypes, relation.SourceType)
+			arg.SourceIds = append(arg.SourceIds, relation.SourceID)
+			arg.TargetTypes = append(arg.TargetTypes, relation.TargetType)
+			arg.TargetIds = append(arg.TargetIds, relation.TargetID)
+			arg.Scores = append(arg.Scores, relation.Score)
+			arg.Reasons = append(arg.Reasons, relation.Reason)
+		}
+		if err := s.store.UpsertContentRelations(ctx, arg); err != nil {
+			return Stats{}, fmt.Errorf("failed to store related content: %w", err)
+		}
+	}
+
+	removed, err := s.store.DeleteStaleContentRelations(ctx, computedAt)
+	if err != nil {
+		return Stats{}, fmt.Errorf("failed to remove stale related content: %w", err)
+	}
+
+	stats := Stats{
+		Relations:  len(relations),
+		Removed:    removed,
+		Duration:   s.now().Sub(started),
+		ComputedAt: computedAt,
+	}
+	logger.Info("Related content rebuilt: %d relations, %d removed in %v", stats.Relations, stats.Removed, stats.Duration)
+	return stats, nil
+}
+
+// load reads the content relations are computed from
+func (s *Service) load(ctx context.Context) (Corpus, error) {
+	var corpus Corpus
+
+	tags := make(map[int32][]string)
+	tagRows, err := s.store.ListAllWordTags(ctx)
+	if err != nil {
+		return corpus, fmt.Errorf("failed to load word tags: %w", err)
+	}
+	for _, row := range tagRows {
+		tags[row.WordID] = append(tags[row.WordID], row.Tag)
+	}
+
+	words, err := s.store.ListWordsForRelations(ctx)
+	if err != nil {
+		return corpus, fmt.Errorf("failed to load words: %w", err)
+	}
+	for _, word := range words {
+		corpus.Words = append(corpus.Words, Word{
+			ID:       word.ID,
+			Text:     word.Word,
+			Tags:     tags[word.ID],
+			Examples: definitionExamples(word.Means.RawMessage),
+		})
+	}
+
+	grammars, err := s.store.ListPublishedGrammarsForRelations(ctx)
+	if err != nil {
+		return corpus, fmt.Errorf("failed to load grammars: %w", err)
+	}
+	for _, grammar := range grammars {
+		corpus.Grammars = append(corpus.Grammars, Grammar{
+			ID:      grammar.ID,
+			Title:   grammar.Title,
+			Tags:    grammar.Tag,
+			Related: grammar.Related,
+			Text:    grammarText(grammar.Contents),
+		})
+	}
+
+	examples, err := s.store.ListExamples(ctx)
+	if err != nil {
+		return corpus, fmt.Errorf("failed to load examples: %w", err)
+	}
+	for _, example := range examples {
+		corpus.Examples = append(corpus.Examples, Example{ID: example.ID, Text: example.Title})
+	}
+
+	questions, err := s.store.ListQuestionsForRelations(ctx)
+	if err != nil {
+		return corpus, fmt.Errorf("failed to load questions: %w", err)
+	}
+	for _, question := range questions {
+		text := []string{question.Title}
+		text = append(text, question.PossibleAnswers...)
+		if question.Keywords.Valid {
+			text = append(text, question.Keywords.String)
+		}
+		corpus.Questions = append(corpus.Questions, Question{
+			ID:          question.QuestionID,
+			Text:        strings.Join(text, "\n"),
+			Explanation: question.Explanation,
+		})
+	}
+
+	return corpus, nil
+}
+
+// definitionExamples returns the example IDs referenced by the meanings of a word
+func definitionExamples(means json.RawMessage) []int32 {
+	var kinds []struct {
+		Means []struct {
+			Examples []int32 `json:"examples"`
+		} `json:"means"`
+	}
+	if len(means) == 0 || json.Unmarshal(means, &kinds) != nil {
+		return nil
+	}
+	var ids []int32
+	for _, kind := range kinds {
+		for _, mean := range kind.Means {
+			ids = append(ids, mean.Examples...)
+		}
+	}
+	return ids
+}
+
+// grammarText returns the example sentences and formulas of grammar contents
+func grammarText(contents json.RawMessage) string {
+	var sections []struct {
+		Content []struct {
+			Examples []struct {
+				Example string `json:"e"`
+			} `json:"e"`
+			Formulas []string `json:"f"`
+		} `json:"content"`
+	}
+	if len(contents) == 0 || json.Unmarshal(contents, &sections) != nil {
+		return ""
+	}
+	var text []string
+	for _, section := range sections {
+		for _, element := range section.Content {
+			for _, example := range element.Examples {
+				text = append(text, example.Example)
+			}
+			text = append(text, element.Formulas...)
+		}
+	}
+	return strings.Join(text, "\n")
+}
+
+// Start rebuilds related content periodically until Stop is called. Stale
+// or missing relations are rebuilt right away.
+func (s *Service) Start(interval time.Duration) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.isRunning {
+		return fmt.Errorf("related content rebuilder is already running")
+	}
+	s.isRunning = true
+	s.stopChan = make(chan struct{})
+	s.wg.Add(1)
+	go s.run(interval)
+
+	logger.Info("Related content rebuilder started with interval: %v", interval)
+	return nil
+}
+
+// Stop stops the periodic rebuild
+func (s *Service) Stop() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.isRunning {
+		return fmt.Errorf("related content rebuilder is not running")
+	}
+	close(s.stopChan)
+	s.wg.Wait()
+	s.isRunning = false
+
+	logger.Info("Related content rebuilder stopped")
+	return nil
+}
+
+// IsRunning returns whether the rebuilder is running
+func (s *Service) IsRunning() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.isRunning
+}
+
+func (s *Service) run(interval time.Duration) {
+	defer s.wg.Done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-s.stopChan:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	if summary, err := s.Summary(ctx); err == nil && (summary.ComputedAt == nil || s.now().Sub(*summary.ComputedAt) >= interval) {
+		s.rebuild(ctx)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.rebuild(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *Service) rebuild(ctx context.Context) {
+	if _, err := s.Rebuild(ctx); err != nil && !errors.Is(err, ErrRebuildInProgress) && ctx.Err() == nil {
+		logger.Error("Failed to rebuild related content: %v", err)
+	}
+}
@@ -0,0 +1,583 @@
+// Package retention purges personal data once it is older than the
+// retention period of its category. Organizations can keep their users'
+// data for a different period than the platform default.
+package retention
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lib/pq"
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/logger"
+)
+
+// Categories of data with their own retention period
+const (
+	// CategorySpeakingAudio is the recorded audio of speaking turns
+	CategorySpeakingAudio = "speaking_audio"
+	// CategoryAIFeedback is the raw AI feedback stored with writings and
+	// speaking turns; scores are kept
+	CategoryAIFeedback = "ai_feedback"
+	// CategoryLogs is the daily application log files of each instance
+	CategoryLogs = "logs"
+	// CategoryProctoringPhotos is the webcam snapshots taken during proctored
+	// exam attempts
+	CategoryProctoringPhotos = "proctoring_photos"
+)
+
+// batchSize is the number of recordings or snapshots deleted at a time
+const batchSize = 500
+
+var (
+	ErrUnknownCategory     = errors.New("unknown retention category")
+	ErrNotOverridable      = errors.New("retention category cannot be overridden per organization")
+	ErrUnknownOrganization = errors.New("organization not found")
+	ErrInvalidRetention    = errors.New("retention days must not be negative")
+	ErrPurgeInProgress     = errors.New("a purge is already in progress")
+)
+
+// Categories lists the retention categories
+func Categories() []string {
+	return []string{CategorySpeakingAudio, CategoryAIFeedback, CategoryLogs, CategoryProctoringPhotos}
+}
+
+// overridable reports whether organizations can set their own retention
+// for category. Log files are not tied to users, and proctoring snapshots
+// are kept for a fixed period everyone consented to, so they cannot.
+func overridable(category string) bool {
+	return category == CategorySpeakingAudio || category == CategoryAIFeedback
+}
+
+// Policy holds the default retention period of each category in days; 0
+// keeps the data forever
+type Policy struct {
+	SpeakingAudioDays   int
+	AIFeedbackDays      int
+	LogDays             int
+	ProctoringPhotoDays int
+}
+
+func (p Policy) days(category string) int {
+	switch category {
+	case CategorySpeakingAudio:
+		return p.SpeakingAudioDays
+	case CategoryAIFeedback:
+		return p.AIFeedbackDays
+	case CategoryLogs:
+		return p.LogDays
+	case CategoryProctoringPhotos:
+		return p.ProctoringPhotoDays
+	}
+	return 0
+}
+
+// MediaDeleter removes uploaded recordings and snapshots from the media
+// storage
+type MediaDeleter interface {
+	DeleteAudio(ctx context.Context, url string) error
+	DeletePrivateImage(ctx context.Context, publicID string) error
+}
+
+// Options configures the retention engine
+type Options struct {
+	Policy Policy
+	// LogDir is the directory of the app-YYYY-MM-DD.log files
+	LogDir string
+	// DryRun makes scheduled runs only report what they would purge
+	DryRun bool
+	// IsLeader reports whether this instance purges the database
+	// categories; log files are purged by every instance
+	IsLeader func() bool
+}
+
+// CategoryPolicy is the default retention period of a category
+type CategoryPolicy struct {
+	Category      string `json:"category"`
+	RetentionDays int    `json:"retention_days"`
+	Overridable   bool   `json:"overridable"`
+}
+
+// Override is the retention period an organization chose for a category
+type Override struct {
+	OrganizationID   int32     `json:"organization_id"`
+	OrganizationName string    `json:"organization_name"`
+	Category         string    `json:"category"`
+	RetentionDays    int32     `json:"retention_days"`
+	UpdatedBy        *int32    `json:"updated_by,omitempty"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// Settings describes the retention policy in effect
+type Settings struct {
+	Categories []CategoryPolicy `json:"categories"`
+	Overrides  []Override       `json:"overrides"`
+	// ScheduledDryRun is true when scheduled runs only report
+	ScheduledDryRun bool `json:"scheduled_dry_run"`
+	// LastRun is the report of the latest scheduled or manual purge on
+	// this instance
+	LastRun *Report `json:"last_run,omitempty"`
+}
+
+// CategoryReport is what a run purged, or would purge, in one category
+type CategoryReport struct {
+	Category string `json:"category"`
+	// Items is the number of recordings, feedback entries, log files or
+	// snapshots
+	Items int64 `json:"items"`
+	// Bytes is the size of the purged log files
+	Bytes  int64  `json:"bytes,omitempty"`
+	Failed int64  `json:"failed,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Report is the outcome of a run
+type Report struct {
+	DryRun     bool             `json:"dry_run"`
+	StartedAt  time.Time        `json:"started_at"`
+	Duration   time.Duration    `json:"duration" swaggertype:"integer"`
+	Categories []CategoryReport `json:"categories"`
+}
+
+// Service purges expired data on a schedule and on demand
+type Service struct {
+	store   db.Querier
+	media   MediaDeleter
+	options Options
+	now     func() time.Time
+
+	purging    atomic.Bool
+	reportLock sync.Mutex
+	lastReport *Report
+
+	mutex     sync.Mutex
+	isRunning bool
+	stopChan  chan struct{}
+	wg        sync.WaitGroup
+}
+
+// NewService creates a retention engine. media may be nil when uploads
+// are disabled, in which case recordings and snapshots are not purged.
+func NewService(store db.Querier, media MediaDeleter, options Options) *Service {
+	return &Service{store: store, media: media, options: options, now: time.Now}
+}
+
+// Settings returns the default periods and the organization overrides
+func (s *Service) Settings(ctx context.Context) (*Settings, error) {
+	rows, err := s.store.ListRetentionOverrides(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list retention overrides: %w", err)
+	}
+	settings := &Settings{ScheduledDryRun: s.options.DryRun, Overrides: make([]Override, 0, len(rows))}
+	for _, category := range Categories() {
+		settings.Categories = append(settings.Categories, CategoryPolicy{
+			Category:      category,
+			RetentionDays: s.options.Policy.days(category),
+			Overridable:   overridable(category),
+		})
+	}
+	for _, row := range rows {
+		override := Override{
+			OrganizationID:   row.PlatformID,
+			OrganizationName: row.PlatformName,
+			Category:         row.Category,
+			RetentionDays:    row.RetentionDays,
+			UpdatedAt:        row.UpdatedAt,
+		}
+		if row.UpdatedBy.Valid {
+			override.UpdatedBy = &row.UpdatedBy.Int32
+		}
+		settings.Overrides = append(settings.Overrides, override)
+	}
+
+	s.reportLock.Lock()
+	settings.LastRun = s.lastReport
+	s.reportLock.Unlock()
+	return settings, nil
+}
+
+func checkCategory(category string) error {
+	for _, known := range Categories() {
+		if category == known {
+			if !overridable(category) {
+				return ErrNotOverridable
+			}
+			return nil
+		}
+	}
+	return ErrUnknownCategory
+}
+
+// SetOverride sets how long the data of an organization's users is kept
+// for category. Users of several organizations keep their data for the
+// longest of their organizations' periods.
+func (s *Service) SetOverride(ctx context.Context, organizationID int32, category string, days int32, actorID int32) (*Override, error) {
+	if err := checkCategory(category); err != nil {
+		return nil, err
+	}
+	if days < 0 {
+		return nil, ErrInvalidRetention
+	}
+	row, err := s.store.UpsertRetentionOverride(ctx, db.UpsertRetentionOverrideParams{
+		PlatformID:    organizationID,
+		Category:      category,
+		RetentionDays: days,
+		UpdatedBy:     sql.NullInt32{Int32: actorID, Valid: actorID != 0},
+	})
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+		return nil, ErrUnknownOrganization
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to save retention override: %w", err)
+	}
+	override := &Override{
+		OrganizationID: row.PlatformID,
+		Category:       row.Category,
+		RetentionDays:  row.RetentionDays,
+		UpdatedAt:      row.UpdatedAt,
+	}
+	if row.UpdatedBy.Valid {
+		override.UpdatedBy = &row.UpdatedBy.Int32
+	}
+	return override, nil
+}
+
+// RemoveOverride makes an organization use the default period again. It
+// returns false when the organization had no override for category.
+func (s *Service) RemoveOverride(ctx context.Context, organizationID int32, category string) (bool, error) {
+	if err := checkCategory(category); err != nil {
+		return false, err
+	}
+	removed, err := s.store.DeleteRetentionOverride(ctx, db.DeleteRetentionOverrideParams{
+		PlatformID: organizationID,
+		Category:   category,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to remove retention override: %w", err)
+	}
+	return removed > 0, nil
+}
+
+// Run purges expired data in every category, or with dryRun only counts
+// it. Only one run can be in progress at a time.
+func (s *Service) Run(ctx context.Context, dryRun bool) (*Report, error) {
+	report, err := s.run(ctx, dryRun, true)
+	if err == nil && !dryRun {
+		s.setLastReport(report)
+	}
+	return report, err
+}
+
+func (s *Service) setLastReport(report *Report) {
+	s.reportLock.Lock()
+	s.lastReport = report
+	s.reportLock.Unlock()
+}
+
+func (s *Service) run(ctx context.Context, dryRun, database bool) (*Report, error) {
+	if !s.purging.CompareAndSwap(false, true) {
+		return nil, ErrPurgeInProgress
+	}
+	defer s.purging.Store(false)
+
+	report := &Report{DryRun: dryRun, StartedAt: s.now()}
+	if database {
+		report.Categories = append(report.Categories,
+			s.purgeSpeakingAudio(ctx, dryRun, report.StartedAt),
+			s.purgeAIFeedback(ctx, dryRun, report.StartedAt),
+			s.purgeProctoringPhotos(ctx, dryRun, report.StartedAt))
+	}
+	report.Categories = append(report.Categories, s.purgeLogs(dryRun, report.StartedAt))
+	report.Duration = time.Since(report.StartedAt)
+	return report, nil
+}
+
+func (s *Service) purgeSpeakingAudio(ctx context.Context, dryRun bool, now time.Time) CategoryReport {
+	report := CategoryReport{Category: CategorySpeakingAudio}
+	defaultDays := int32(s.options.Policy.SpeakingAudioDays)
+
+	if dryRun || s.media == nil {
+		count, err := s.store.CountExpiredSpeakingAudio(ctx, db.CountExpiredSpeakingAudioParams{DefaultDays: defaultDays, Now: now})
+		if err != nil {
+			report.Error = err.Error()
+		}
+		if dryRun {
+			report.Items = count
+		} else {
+			// Without media storage nothing can be deleted
+			report.Failed = count
+		}
+		return report
+	}
+
+	var afterID int32
+	for {
+		rows, err := s.store.ListExpiredSpeakingAudio(ctx, db.ListExpiredSpeakingAudioParams{
+			AfterID:     afterID,
+			DefaultDays: defaultDays,
+			Now:         now,
+			MaxItems:    batchSize,
+		})
+		if err != nil {
+			report.Error = err.Error()
+			return report
+		}
+		if len(rows) == 0 {
+			return report
+		}
+
+		// Recordings are only unlinked once they are gone from storage, so
+		// failed deletions are retried on the next run
+		deleted := make([]int32, 0, len(rows))
+		for _, row := range rows {
+			if err := s.media.DeleteAudio(ctx, row.AudioRecordingPath); err != nil {
+				if ctx.Err() != nil {
+					report.Error = ctx.Err().Error()
+					return report
+				}
+				logger.Warn("Failed to delete speaking recording %d: %v", row.ID, err)
+				report.Failed++
+				continue
+			}
+			deleted = append(deleted, row.ID)
+		}
+		if len(deleted) > 0 {
+			cleared, err := s.store.ClearSpeakingAudio(ctx, deleted)
+			if err != nil {
+				report.Error = err.Error()
+				return report
+			}
+			report.Items += cleared
+		}
+		afterID = rows[len(rows)-1].ID
+	}
+}
+
+func (s *Service) purgeAIFeedback(ctx context.Context, dryRun bool, now time.Time) CategoryReport {
+	report := CategoryReport{Category: CategoryAIFeedback}
+	defaultDays := int32(s.options.Policy.AIFeedbackDays)
+
+	var writings, evaluations int64
+	var err error
+	if dryRun {
+		writings, err = s.store.CountExpiredWritingFeedback(ctx, db.CountExpiredWritingFeedbackParams{DefaultDays: defaultDays, Now: now})
+		if err == nil {
+			evaluations, err = s.store.CountExpiredSpeakingEvaluations(ctx, db.CountExpiredSpeakingEvaluationsParams{DefaultDays: defaultDays, Now: now})
+		}
+	} else {
+		writings, err = s.store.ClearExpiredWritingFeedback(ctx, db.ClearExpiredWritingFeedbackParams{DefaultDays: defaultDays, Now: now})
+		if err == nil {
+			evaluations, err = s.store.ClearExpiredSpeakingEvaluations(ctx, db.ClearExpiredSpeakingEvaluationsParams{DefaultDays: defaultDays, Now: now})
+		}
+	}
+	if err != nil {
+		report.Error = err.Error()
+	}
+	report.Items = writings + evaluations
+	return report
+}
+
+// purgeProctoringPhotos deletes snapshots received more than the retention
+// period ago, from storage first and then from the database
+func (s *Service) purgeProctoringPhotos(ctx context.Context, dryRun bool, now time.Time) CategoryReport {
+	report := CategoryReport{Category: CategoryProctoringPhotos}
+	if s.options.Policy.ProctoringPhotoDays <= 0 {
+		return report
+	}
+	cutoff := now.AddDate(0, 0, -s.options.Policy.ProctoringPhotoDays)
+
+	if dryRun || s.media == nil {
+		count, err := s.store.CountExpiredProctoringPhotos(ctx, cutoff)
+		if err != nil {
+			report.Error = err.Error()
+		}
+		if dryRun {
+			report.Items = count
+		} else {
+			report.Failed = count
+		}
+		return report
+	}
+
+	var afterID int64
+	for {
+		rows, err := s.store.ListExpiredProctoringPhotos(ctx, db.ListExpiredProctoringPhotosParams{
+			AfterID:  afterID,
+			Cutoff:   cutoff,
+			MaxItems: batchSize,
+		})
+		if err != nil {
+			report.Error = err.Error()
+			return report
+		}
+		if len(rows) == 0 {
+			return report
+		}
+
+		deleted := make([]int64, 0, len(rows))
+		for _, row := range rows {
+			if err := s.media.DeletePrivateImage(ctx, row.PublicID); err != nil {
+				if ctx.Err() != nil {
+					report.Error = ctx.Err().Error()
+					return report
+				}
+				logger.Warn("Failed to delete proctoring photo %d: %v", row.ID, err)
+				report.Failed++
+				continue
+			}
+			deleted = append(deleted, row.ID)
+		}
+		if len(deleted) > 0 {
+			removed, err := s.store.DeleteProctoringPhotos(ctx, deleted)
+			if err != nil {
+				report.Error = err.Error()
+				return report
+			}
+			report.Items += removed
+		}
+		afterID = rows[len(rows)-1].ID
+	}
+}
+
+// purgeLogs removes log files whose date, taken from their name, is older
+// than the retention period. The current day's file is never removed.
+func (s *Service) purgeLogs(dryRun bool, now time.Time) CategoryReport {
+	report := CategoryReport{Category: CategoryLogs}
+	if s.options.Policy.LogDays <= 0 || s.options.LogDir == "" {
+		return report
+	}
+	entries, err := os.ReadDir(s.options.LogDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return report
+	}
+	if err != nil {
+		report.Error = err.Error()
+		return report
+	}
+
+	today := now.Format("2006-01-02")
+	cutoff := now.AddDate(0, 0, -s.options.Policy.LogDays)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, "app-") || !strings.HasSuffix(name, ".log") {
+			continue
+		}
+		date := strings.TrimSuffix(strings.TrimPrefix(name, "app-"), ".log")
+		day, err := time.ParseInLocation("2006-01-02", date, now.Location())
+		if err != nil || date == today || !day.Before(cutoff) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			report.Failed++
+			continue
+		}
+		if !dryRun {
+			if err := os.Remove(filepath.Join(s.options.LogDir, name)); err != nil {
+				logger.Warn("Failed to remove log file %s: %v", name, err)
+				report.Failed++
+				continue
+			}
+		}
+		report.Items++
+		report.Bytes += info.Size()
+	}
+	return report
+}
+
+// Start runs the retention policy every interval until Stop is called.
+// The first run happens after one interval.
+func (s *Service) Start(interval time.Duration) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.isRunning {
+		return fmt.Errorf("retention engine is already running")
+	}
+	s.isRunning = true
+	s.stopChan = make(chan struct{})
+	s.wg.Add(1)
+	go s.schedule(interval)
+
+	logger.Info("Retention engine started with interval: %v (dry run: %v)", interval, s.options.DryRun)
+	return nil
+}
+
+// Stop stops the scheduled runs
+func (s *Service) Stop() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.isRunning {
+		return fmt.Errorf("retention engine is not running")
+	}
+	close(s.stopChan)
+	s.wg.Wait()
+	s.isRunning = false
+
+	logger.Info("Retention engine stopped")
+	return nil
+}
+
+// IsRunning returns whether scheduled runs are enabled
+func (s *Service) IsRunning() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.isRunning
+}
+
+func (s *Service) schedule(interval time.Duration) {
+	defer s.wg.Done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-s.stopChan:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.scheduledRun(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *Service) scheduledRun(ctx context.Context) {
+	leader := s.options.IsLeader == nil || s.options.IsLeader()
+	report, err := s.run(ctx, s.options.DryRun, leader)
+	if err != nil {
+		logger.Warn("Skipped scheduled retention run: %v", err)
+		return
+	}
+	s.setLastReport(report)
+	for _, category := range report.Categories {
+		if category.Error != "" && ctx.Err() == nil {
+			logger.Error("Retention run failed for %s: %s", category.Category, category.Error)
+		}
+		if category.Items > 0 {
+			verb := "Purged"
+			if report.DryRun {
+				verb = "Would purge"
+			}
+			logger.Info("%s %d expired %s items", verb, category.Items, category.Category)
+		}
+	}
+}
@@ -0,0 +1,298 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+// fakeStore keeps expired recordings and snapshots in memory; the feedback
+// queries return fixed counts
+type fakeStore struct {
+	db.Querier
+	audio     []db.ListExpiredSpeakingAudioRow
+	photos    []db.ListExpiredProctoringPhotosRow
+	feedback  int64
+	overrides map[int32]db.RetentionOverride
+}
+
+func (f *fakeStore) ListExpiredSpeakingAudio(_ context.Context, arg db.ListExpiredSpeakingAudioParams) ([]db.ListExpiredSpeakingAudioRow, error) {
+	var rows []db.ListExpiredSpeakingAudioRow
+	for _, row := range f.audio {
+		if row.ID > arg.AfterID && len(rows) < int(arg.MaxItems) {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+func (f *fakeStore) CountExpiredSpeakingAudio(context.Context, db.CountExpiredSpeakingAudioParams) (int64, error) {
+	return int64(len(f.audio)), nil
+}
+
+func (f *fakeStore) ClearSpeakingAudio(_ context.Context, ids []int32) (int64, error) {
+	kept := f.audio[:0]
+	for _, row := range f.audio {
+		cleared := false
+		for _, id := range ids {
+			cleared = cleared || row.ID == id
+		}
+		if !cleared {
+			kept = append(kept, row)
+		}
+	}
+	removed := int64(len(f.audio) - len(kept))
+	f.audio = kept
+	return removed, nil
+}
+
+func (f *fakeStore) ListExpiredProctoringPhotos(_ context.Context, arg db.ListExpiredProctoringPhotosParams) ([]db.ListExpiredProctoringPhotosRow, error) {
+	var rows []db.ListExpiredProctoringPhotosRow
+	for _, row := range f.photos {
+		if row.ID > arg.AfterID && len(rows) < int(arg.MaxItems) {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+func (f *fakeStore) CountExpiredProctoringPhotos(context.Context, time.Time) (int64, error) {
+	return int64(len(f.photos)), nil
+}
+
+func (f *fakeStore) DeleteProctoringPhotos(_ context.Context, ids []int64) (int64, error) {
+	kept := f.photos[:0]
+	for _, row := range f.photos {
+		removed := false
+		for _, id := range ids {
+			removed = removed || row.ID == id
+		}
+		if !removed {
+			kept = append(kept, row)
+		}
+	}
+	removed := int64(len(f.photos) - len(kept))
+	f.photos = kept
+	return removed, nil
+}
+
+func (f *fakeStore) CountExpiredWritingFeedback(context.Context, db.CountExpiredWritingFeedbackParams) (int64, error) {
+	return f.feedback, nil
+}
+
+func (f *fakeStore) CountExpiredSpeakingEvaluations(context.Context, db.CountExpiredSpeakingEvaluationsParams) (int64, error) {
+	return 0, nil
+}
+
+func (f *fakeStore) ClearExpiredWritingFeedback(context.Context, db.ClearExpiredWritingFeedbackParams) (int64, error) {
+	cleared := f.feedback
+	f.feedback = 0
+	return cleared, nil
+}
+
+func (f *fakeStore) ClearExpiredSpeakingEvaluations(context.Context, db.ClearExpiredSpeakingEvaluationsParams) (int64, error) {
+	return 0, nil
+}
+
+func (f *fakeStore) UpsertRetentionOverride(_ context.Context, arg db.UpsertRetentionOverrideParams) (db.RetentionOverride, error) {
+	if arg.PlatformID != 1 {
+		return db.RetentionOverride{}, &pq.Error{Code: "23503"}
+	}
+	override := db.RetentionOverride{
+		PlatformID:    arg.PlatformID,
+		Category:      arg.Category,
+		RetentionDays: arg.RetentionDays,
+		UpdatedBy:     arg.UpdatedBy,
+		UpdatedAt:     time.Now(),
+	}
+	if f.overrides == nil {
+		f.overrides = make(map[int32]db.RetentionOverride)
+	}
+	f.overrides[arg.PlatformID] = override
+	return override, nil
+}
+
+func (f *fakeStore) ListRetentionOverrides(context.Context) ([]db.ListRetentionOverridesRow, error) {
+	var rows []db.ListRetentionOverridesRow
+	for _, override := range f.overrides {
+		rows = append(rows, db.ListRetentionOverridesRow{
+			PlatformID:    override.PlatformID,
+			PlatformName:  "Campus",
+			Category:      override.Category,
+			RetentionDays: override.RetentionDays,
+			UpdatedBy:     override.UpdatedBy,
+			UpdatedAt:     override.UpdatedAt,
+		})
+	}
+	return rows, nil
+}
+
+// fakeMedia fails to delete the recordings in failing
+type fakeMedia struct {
+	deleted []string
+	failing map[string]bool
+}
+
+func (m *fakeMedia) DeleteAudio(_ context.Context, url string) error {
+	if m.failing[url] {
+		return errors.New("storage unavailable")
+	}
+	m.deleted = append(m.deleted, url)
+	return nil
+}
+
+func (m *fakeMedia) DeletePrivateImage(_ context.Context, publicID string) error {
+	return m.DeleteAudio(context.Background(), publicID)
+}
+
+func writeLog(t *testing.T, dir, name string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("entry\n"), 0644))
+}
+
+func categoryReport(t *testing.T, report *Report, category string) CategoryReport {
+	t.Helper()
+	for _, entry := range report.Categories {
+		if entry.Category == category {
+			return entry
+		}
+	}
+	t.Fatalf("report has no %s category", category)
+	return CategoryReport{}
+}
+
+func TestSetOverrideValidatesInput(t *testing.T) {
+	service := NewService(&fakeStore{}, nil, Options{})
+	ctx := context.Background()
+
+	_, err := service.SetOverride(ctx, 1, "chat_history", 30, 2)
+	assert.ErrorIs(t, err, ErrUnknownCategory)
+	_, err = service.SetOverride(ctx, 1, CategoryLogs, 30, 2)
+	assert.ErrorIs(t, err, ErrNotOverridable)
+	_, err = service.SetOverride(ctx, 1, CategorySpeakingAudio, -1, 2)
+	assert.ErrorIs(t, err, ErrInvalidRetention)
+	_, err = service.SetOverride(ctx, 9, CategorySpeakingAudio, 30, 2)
+	assert.ErrorIs(t, err, ErrUnknownOrganization)
+
+	override, err := service.SetOverride(ctx, 1, CategorySpeakingAudio, 0, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), override.RetentionDays)
+	require.NotNil(t, override.UpdatedBy)
+	assert.Equal(t, int32(2), *override.UpdatedBy)
+
+	settings, err := service.Settings(ctx)
+	require.NoError(t, err)
+	require.Len(t, settings.Overrides, 1)
+	assert.Equal(t, "Campus", settings.Overrides[0].OrganizationName)
+	assert.Len(t, settings.Categories, len(Categories()))
+}
+
+func TestDryRunPurgesNothing(t *testing.T) {
+	dir := t.TempDir()
+	writeLog(t, dir, "app-2020-01-01.log")
+	store := &fakeStore{
+		audio:    []db.ListExpiredSpeakingAudioRow{{ID: 1, AudioRecordingPath: "a"}},
+		photos:   []db.ListExpiredProctoringPhotosRow{{ID: 1, PublicID: "proctoring/attempt-1/p"}},
+		feedback: 4,
+	}
+	media := &fakeMedia{}
+	service := NewService(store, media, Options{Policy: Policy{90, 365, 30, 30}, LogDir: dir})
+
+	report, err := service.Run(context.Background(), true)
+	require.NoError(t, err)
+	assert.True(t, report.DryRun)
+	assert.Equal(t, int64(1), categoryReport(t, report, CategorySpeakingAudio).Items)
+	assert.Equal(t, int64(4), categoryReport(t, report, CategoryAIFeedback).Items)
+	assert.Equal(t, int64(1), categoryReport(t, report, CategoryLogs).Items)
+	assert.Equal(t, int64(1), categoryReport(t, report, CategoryProctoringPhotos).Items)
+
+	assert.Empty(t, media.deleted)
+	assert.Len(t, store.audio, 1)
+	assert.Len(t, store.photos, 1)
+	assert.Equal(t, int64(4), store.feedback)
+	assert.FileExists(t, filepath.Join(dir, "app-2020-01-01.log"))
+}
+
+func TestRunKeepsRecordingsThatFailedToDelete(t *testing.T) {
+	store := &fakeStore{}
+	for id := int32(1); id <= batchSize+2; id++ {
+		store.audio = append(store.audio, db.ListExpiredSpeakingAudioRow{ID: id, AudioRecordingPath: string(rune('a' + id%26))})
+	}
+	media := &fakeMedia{failing: map[string]bool{"b": true}}
+	service := NewService(store, media, Options{Policy: Policy{SpeakingAudioDays: 90}})
+
+	report, err := service.Run(context.Background(), false)
+	require.NoError(t, err)
+	audio := categoryReport(t, report, CategorySpeakingAudio)
+	assert.NotZero(t, audio.Failed)
+	assert.Equal(t, int64(len(store.audio)), audio.Failed)
+	assert.Equal(t, int64(batchSize+2)-audio.Failed, audio.Items)
+	for _, row := range store.audio {
+		assert.Equal(t, "b", row.AudioRecordingPath)
+	}
+
+	settings, err := service.Settings(context.Background())
+	require.NoError(t, err)
+	assert.Same(t, report, settings.LastRun)
+}
+
+func TestRunDeletesProctoringPhotos(t *testing.T) {
+	store := &fakeStore{photos: []db.ListExpiredProctoringPhotosRow{
+		{ID: 1, PublicID: "proctoring/attempt-1/a"},
+		{ID: 2, PublicID: "proctoring/attempt-1/b"},
+	}}
+	media := &fakeMedia{failing: map[string]bool{"proctoring/attempt-1/b": true}}
+	service := NewService(store, media, Options{Policy: Policy{ProctoringPhotoDays: 30}})
+
+	report, err := service.Run(context.Background(), false)
+	require.NoError(t, err)
+	photos := categoryReport(t, report, CategoryProctoringPhotos)
+	assert.Equal(t, int64(1), photos.Items)
+	assert.Equal(t, int64(1), photos.Failed)
+	assert.Equal(t, []string{"proctoring/attempt-1/a"}, media.deleted)
+	require.Len(t, store.photos, 1)
+	assert.Equal(t, int64(2), store.photos[0].ID)
+
+	_, err = service.SetOverride(context.Background(), 1, CategoryProctoringPhotos, 365, 2)
+	assert.ErrorIs(t, err, ErrNotOverridable)
+}
+
+func TestRunRemovesOldLogFiles(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 3, 31, 12, 0, 0, 0, time.Local)
+	writeLog(t, dir, "app-2026-01-15.log")
+	writeLog(t, dir, "app-2026-03-30.log")
+	writeLog(t, dir, "app-2026-03-31.log")
+	writeLog(t, dir, "other-2020-01-01.log")
+
+	service := NewService(&fakeStore{}, nil, Options{Policy: Policy{LogDays: 30}, LogDir: dir})
+	service.now = func() time.Time { return now }
+
+	report, err := service.run(context.Background(), false, false)
+	require.NoError(t, err)
+	require.Len(t, report.Categories, 1)
+	logs := report.Categories[0]
+	assert.Equal(t, int64(1), logs.Items)
+	assert.Equal(t, int64(len("entry\n")), logs.Bytes)
+
+	assert.NoFileExists(t, filepath.Join(dir, "app-2026-01-15.log"))
+	assert.FileExists(t, filepath.Join(dir, "app-2026-03-30.log"))
+	assert.FileExists(t, filepath.Join(dir, "app-2026-03-31.log"))
+	assert.FileExists(t, filepath.Join(dir, "other-2020-01-01.log"))
+}
+
+func TestRunRejectsConcurrentPurges(t *testing.T) {
+	service := NewService(&fakeStore{}, nil, Options{})
+	service.purging.Store(true)
+
+	_, err := service.Run(context.Background(), false)
+	assert.ErrorIs(t, err, ErrPurgeInProgress)
+}
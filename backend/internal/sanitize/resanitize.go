@@ -0,0 +1,209 @@
+package sanitize
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/toeic-app/internal/logger"
+)
+
+// Column describes a stored column and how it is sanitized
+type Column struct {
+	Name       string
+	Type       FieldType
+	JSONFields map[string]FieldType // Set for JSON columns, see Sanitizer.JSON
+}
+
+// Target is a table whose stored content can be re-sanitized
+type Target struct {
+	Table    string
+	IDColumn string
+	Columns  []Column
+}
+
+// GrammarContentFields maps the keys of grammars.contents to field types
+var GrammarContentFields = map[string]FieldType{
+	"sub_title": Plain,
+	"c":         RichText, // content text
+	"e":         RichText, // examples
+	"f":         Plain,    // formulas
+}
+
+// Targets lists the stored content written through sanitized endpoints.
+// Keep it in sync with the `sanitize` tags on the request structs.
+var Targets = []Target{
+	{
+		Table:    "grammars",
+		IDColumn: "id",
+		Columns: []Column{
+			{Name: "title", Type: Plain},
+			{Name: "contents", Type: RichText, JSONFields: GrammarContentFields},
+		},
+	},
+	{
+		Table:    "writing_prompts",
+		IDColumn: "id",
+		Columns: []Column{
+			{Name: "prompt_text", Type: Markdown},
+			{Name: "topic", Type: Plain},
+		},
+	},
+	{
+		Table:    "contents",
+		IDColumn: "content_id",
+		Columns: []Column{
+			{Name: "description", Type: RichText},
+		},
+	},
+	{
+		Table:    "questions",
+		IDColumn: "question_id",
+		Columns: []Column{
+			{Name: "title", Type: Plain},
+			{Name: "explanation", Type: Markdown},
+		},
+	},
+}
+
+// TableReport summarizes a re-sanitization run for one table
+type TableReport struct {
+	Table   string `json:"table"`
+	Scanned int    `json:"scanned"`
+	Changed int    `json:"changed"`
+	Failed  int    `json:"failed"`
+}
+
+// ResanitizeOptions controls a re-sanitization run
+type ResanitizeOptions struct {
+	DryRun    bool // Report changes without writing them
+	BatchSize int  // Rows read per query
+}
+
+// Resanitize applies the current policies to content already stored in the
+// database, e.g. after a policy was tightened. Rows are processed in batches
+// ordered by primary key and only rows whose content changes are updated.
+func (s *Sanitizer) Resanitize(ctx context.Context, conn *sql.DB, targets []Target, opts ResanitizeOptions) ([]TableReport, error) {
+	if !s.Enabled() {
+		return nil, fmt.Errorf("sanitization is disabled (SANITIZE_ENABLED=false)")
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 500
+	}
+
+	reports := make([]TableReport, 0, len(targets))
+	for _, target := range targets {
+		report, err := s.resanitizeTable(ctx, conn, target, opts)
+		reports = append(reports, report)
+		if err != nil {
+			return reports, fmt.Errorf("failed to re-sanitize %s: %w", target.Table, err)
+		}
+		logger.Info("Re-sanitized %s: scanned=%d changed=%d failed=%d dry_run=%v",
+			target.Table, report.Scanned, report.Changed, report.Failed, opts.DryRun)
+	}
+
+	return reports, nil
+}
+
+func (s *Sanitizer) resanitizeTable(ctx context.Context, conn *sql.DB, target Target, opts ResanitizeOptions) (TableReport, error) {
+	report := TableReport{Table: target.Table}
+
+	columnNames := make([]string, len(target.Columns))
+	assignments := make([]string, len(target.Columns))
+	for i, column := range target.Columns {
+		columnNames[i] = column.Name
+		assignments[i] = fmt.Sprintf("%s = $%d", column.Name, i+1)
+	}
+
+	selectQuery := fmt.Sprintf("SELECT %s, %s FROM %s WHERE %s > $1 ORDER BY %s LIMIT $2",
+		target.IDColumn, strings.Join(columnNames, ", "), target.Table, target.IDColumn, target.IDColumn)
+	updateQuery := fmt.Sprintf("UPDATE %s SET %s WHERE %s = $%d",
+		target.Table, strings.Join(assignments, ", "), target.IDColumn, len(target.Columns)+1)
+
+	var lastID int64
+	for {
+		rows, err := conn.QueryContext(ctx, selectQuery, lastID, opts.BatchSize)
+		if err != nil {
+			return report, err
+		}
+
+		type row struct {
+			id     int64
+			values []sql.NullString
+		}
+		var batch []row
+		for rows.Next() {
+			r := row{values: make([]sql.NullString, len(target.Columns))}
+			dest := []interface{}{&r.id}
+			for i := range r.values {
+				dest = append(dest, &r.values[i])
+			}
+			if err := rows.Scan(dest...); err != nil {
+				rows.Close()
+				return report, err
+			}
+			batch = append(batch, r)
+		}
+		if err := rows.Err(); err != nil {
+			rows.Close()
+			return report, err
+		}
+		rows.Close()
+
+		if len(batch) == 0 {
+			return report, nil
+		}
+
+		for _, r := range batch {
+			lastID = r.id
+			report.Scanned++
+
+			changed := false
+			args := make([]interface{}, 0, len(target.Columns)+1)
+			for i, column := range target.Columns {
+				value := r.values[i]
+				if value.Valid {
+					cleaned, columnChanged, err := s.sanitizeColumn(column, value.String)
+					if err != nil {
+						logger.Warn("Skipping %s %d: %v", target.Table, r.id, err)
+						report.Failed++
+						changed = false
+						break
+					}
+					if columnChanged {
+						value.String = cleaned
+						changed = true
+					}
+				}
+				args = append(args, value)
+			}
+			if !changed {
+				continue
+			}
+
+			report.Changed++
+			if opts.DryRun {
+				continue
+			}
+
+			args = append(args, r.id)
+			if _, err := conn.ExecContext(ctx, updateQuery, args...); err != nil {
+				logger.Warn("Failed to update %s %d: %v", target.Table, r.id, err)
+				report.Failed++
+				report.Changed--
+			}
+		}
+	}
+}
+
+func (s *Sanitizer) sanitizeColumn(column Column, value string) (string, bool, error) {
+	if column.JSONFields != nil {
+		cleaned, changed, err := s.JSON(json.RawMessage(value), column.JSONFields, column.Type)
+		return string(cleaned), changed, err
+	}
+
+	cleaned := s.String(column.Type, value)
+	return cleaned, cleaned != value, nil
+}
@@ -0,0 +1,206 @@
+package sanitize
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/toeic-app/internal/config"
+)
+
+// FieldType selects the sanitization policy applied to a field.
+// Request structs declare it with a `sanitize:"..."` struct tag.
+type FieldType string
+
+const (
+	Plain    FieldType = "plain"     // No markup allowed, all HTML is stripped
+	Markdown FieldType = "markdown"  // Markdown text with an optional subset of inline HTML
+	RichText FieldType = "rich_text" // HTML rendered by clients
+)
+
+// Policy names accepted by SANITIZE_RICH_TEXT_POLICY and SANITIZE_MARKDOWN_POLICY
+const (
+	PolicyStrict = "strict" // Strip every tag
+	PolicyBasic  = "basic"  // Text formatting, lists and links
+	PolicyUGC    = "ugc"    // bluemonday's user generated content policy (adds images, tables, ...)
+)
+
+// textEntities restores characters escaped by bluemonday that are harmless in
+// text and Markdown fields, so "don't" is not stored as "don&#39;t".
+// "&lt;" is deliberately kept escaped.
+var textEntities = strings.NewReplacer(
+	"&amp;", "&",
+	"&#39;", "'",
+	"&#34;", `"`,
+	"&quot;", `"`,
+	"&gt;", ">",
+)
+
+// Sanitizer cleans user supplied content according to its field type
+type Sanitizer struct {
+	enabled  bool
+	policies map[FieldType]*bluemonday.Policy
+}
+
+// New creates a sanitizer using the policies selected in the configuration
+func New(cfg config.Config) *Sanitizer {
+	return &Sanitizer{
+		enabled: cfg.SanitizeEnabled,
+		policies: map[FieldType]*bluemonday.Policy{
+			Plain:    newPolicy(PolicyStrict),
+			Markdown: newPolicy(cfg.SanitizeMarkdownPolicy),
+			RichText: newPolicy(cfg.SanitizeRichTextPolicy),
+		},
+	}
+}
+
+// newPolicy builds the bluemonday policy for a policy name
+func newPolicy(name string) *bluemonday.Policy {
+	switch name {
+	case PolicyUGC:
+		return bluemonday.UGCPolicy()
+	case PolicyBasic:
+		p := bluemonday.NewPolicy()
+		p.AllowElements("b", "strong", "i", "em", "u", "s", "sub", "sup", "br", "p",
+			"ul", "ol", "li", "code", "pre", "blockquote", "span")
+		p.AllowStandardURLs()
+		p.AllowAttrs("href").OnElements("a")
+		p.RequireNoFollowOnLinks(true)
+		p.AddTargetBlankToFullyQualifiedLinks(true)
+		return p
+	default:
+		return bluemonday.StrictPolicy()
+	}
+}
+
+// Enabled reports whether sanitization is active
+func (s *Sanitizer) Enabled() bool {
+	return s != nil && s.enabled
+}
+
+// String sanitizes a single value
+func (s *Sanitizer) String(fieldType FieldType, value string) string {
+	if !s.Enabled() || value == "" {
+		return value
+	}
+
+	policy, ok := s.policies[fieldType]
+	if !ok {
+		policy = s.policies[Plain]
+	}
+
+	cleaned := policy.Sanitize(value)
+	if fieldType != RichText {
+		cleaned = textEntities.Replace(cleaned)
+	}
+	return cleaned
+}
+
+// Struct sanitizes, in place, every string field of v tagged with `sanitize:"<type>"`.
+// Nested structs, pointers and slices are walked. v must be a pointer.
+// It reports whether any value was modified.
+func (s *Sanitizer) Struct(v interface{}) bool {
+	if !s.Enabled() {
+		return false
+	}
+	return s.walk(reflect.ValueOf(v), "")
+}
+
+func (s *Sanitizer) walk(v reflect.Value, fieldType FieldType) bool {
+	changed := false
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			changed = s.walk(v.Elem(), fieldType)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if s.walk(v.Field(i), FieldType(field.Tag.Get("sanitize"))) {
+				changed = true
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if s.walk(v.Index(i), fieldType) {
+				changed = true
+			}
+		}
+	case reflect.String:
+		if fieldType == "" || !v.CanSet() {
+			return false
+		}
+		cleaned := s.String(fieldType, v.String())
+		if cleaned != v.String() {
+			v.SetString(cleaned)
+			changed = true
+		}
+	}
+
+	return changed
+}
+
+// JSON sanitizes the string values of a JSON document. The field type of a
+// string is looked up by the name of the object key holding it (or holding the
+// array it belongs to); unknown keys use fallback.
+// It reports whether any value was modified.
+func (s *Sanitizer) JSON(raw json.RawMessage, fields map[string]FieldType, fallback FieldType) (json.RawMessage, bool, error) {
+	if !s.Enabled() || len(raw) == 0 {
+		return raw, false, nil
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return raw, false, fmt.Errorf("failed to parse JSON content: %w", err)
+	}
+
+	cleaned, changed := s.walkJSON(doc, fallback, fields, fallback)
+	if !changed {
+		return raw, false, nil
+	}
+
+	data, err := json.Marshal(cleaned)
+	if err != nil {
+		return raw, false, fmt.Errorf("failed to encode sanitized JSON content: %w", err)
+	}
+	return data, true, nil
+}
+
+func (s *Sanitizer) walkJSON(node interface{}, fieldType FieldType, fields map[string]FieldType, fallback FieldType) (interface{}, bool) {
+	changed := false
+
+	switch value := node.(type) {
+	case map[string]interface{}:
+		for key, child := range value {
+			childType, ok := fields[key]
+			if !ok {
+				childType = fallback
+			}
+			cleaned, childChanged := s.walkJSON(child, childType, fields, fallback)
+			if childChanged {
+				value[key] = cleaned
+				changed = true
+			}
+		}
+	case []interface{}:
+		for i, child := range value {
+			cleaned, childChanged := s.walkJSON(child, fieldType, fields, fallback)
+			if childChanged {
+				value[i] = cleaned
+				changed = true
+			}
+		}
+	case string:
+		cleaned := s.String(fieldType, value)
+		return cleaned, cleaned != value
+	}
+
+	return node, changed
+}
@@ -0,0 +1,72 @@
+package sanitize
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/toeic-app/internal/config"
+)
+
+func newTestSanitizer() *Sanitizer {
+	return New(config.Config{
+		SanitizeEnabled:        true,
+		SanitizeRichTextPolicy: PolicyUGC,
+		SanitizeMarkdownPolicy: PolicyBasic,
+	})
+}
+
+func TestStringPolicies(t *testing.T) {
+	s := newTestSanitizer()
+
+	assert.Equal(t, "Don't panic & relax", s.String(Plain, "<b>Don't</b> panic & relax<script>alert(1)</script>"))
+	assert.Equal(t, "> **quoted** <em>text</em>", s.String(Markdown, `> **quoted** <em onclick="x()">text</em><img src="a.png">`))
+	assert.Equal(t, `<p>Hello <img src="https://example.com/a.png"></p>`, s.String(RichText, `<p onclick="x()">Hello <img src="https://example.com/a.png"></p><script>bad()</script>`))
+}
+
+func TestStructFollowsTags(t *testing.T) {
+	type example struct {
+		Text *string `sanitize:"rich_text"`
+	}
+	type request struct {
+		Title    string   `sanitize:"plain"`
+		Raw      string   // untagged fields are left alone
+		Formulas []string `sanitize:"plain"`
+		Examples []example
+	}
+
+	text := `<i>ok</i><iframe src="x"></iframe>`
+	req := request{
+		Title:    "<h1>Title</h1>",
+		Raw:      "<h1>Raw</h1>",
+		Formulas: []string{"S + V <b>+</b> O"},
+		Examples: []example{{Text: &text}},
+	}
+
+	assert.True(t, newTestSanitizer().Struct(&req))
+	assert.Equal(t, "Title", req.Title)
+	assert.Equal(t, "<h1>Raw</h1>", req.Raw)
+	assert.Equal(t, []string{"S + V + O"}, req.Formulas)
+	assert.Equal(t, "<i>ok</i>", *req.Examples[0].Text)
+
+	disabled := New(config.Config{SanitizeEnabled: false})
+	req.Raw = "<b>x</b>"
+	req.Title = "<b>x</b>"
+	assert.False(t, disabled.Struct(&req))
+	assert.Equal(t, "<b>x</b>", req.Title)
+}
+
+func TestJSONUsesKeyFieldTypes(t *testing.T) {
+	raw := json.RawMessage(`[{"sub_title":"<b>Use</b>","content":[{"c":"<b>bold</b><script>x</script>","f":["<i>S</i> + V"]}]}]`)
+
+	cleaned, changed, err := newTestSanitizer().JSON(raw, GrammarContentFields, RichText)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.JSONEq(t, `[{"sub_title":"Use","content":[{"c":"<b>bold</b>","f":["S + V"]}]}]`, string(cleaned))
+
+	unchanged := json.RawMessage(`[{"sub_title":"Plain"}]`)
+	cleaned, changed, err = newTestSanitizer().JSON(unchanged, GrammarContentFields, RichText)
+	assert.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, string(unchanged), string(cleaned))
+}
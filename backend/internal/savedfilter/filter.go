@@ -0,0 +1,206 @@
+// Package savedfilter stores named filter sets, such as "business words
+// level 3+", that users apply to the words, exams and study set lists with
+// ?filter_id=.
+package savedfilter
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+// Resources a filter applies to
+const (
+	ResourceWords     = "words"
+	ResourceExams     = "exams"
+	ResourceStudySets = "study_sets"
+)
+
+const (
+	// MaxQueryLength bounds the text filter
+	MaxQueryLength = 100
+	// MaxTags bounds the tags of a word filter
+	MaxTags = 10
+)
+
+var (
+	ErrInvalidResource = errors.New("resource must be words, exams or study_sets")
+	ErrInvalidCriteria = errors.New("invalid filter")
+)
+
+// Criteria are the fields of a filter. Unset fields match everything; which
+// fields are allowed depends on the resource.
+type Criteria struct {
+	// Query matches a substring of the word or its short meaning, the exam
+	// title or the study set name
+	Query string `json:"query,omitempty" form:"query"`
+	// MinLevel, MaxLevel, Tags and List filter words
+	MinLevel *int32   `json:"min_level,omitempty" form:"min_level"`
+	MaxLevel *int32   `json:"max_level,omitempty" form:"max_level"`
+	Tags     []string `json:"tags,omitempty" form:"tags"`
+	List     string   `json:"list,omitempty" form:"list"`
+	// Premium and Unlocked filter exams
+	Premium  *bool `json:"premium,omitempty" form:"premium"`
+	Unlocked *bool `json:"unlocked,omitempty" form:"unlocked"`
+	// Public filters the study sets of a user
+	Public *bool `json:"public,omitempty" form:"public"`
+}
+
+// ValidResource reports whether filters can be saved for resource
+func ValidResource(resource string) bool {
+	switch resource {
+	case ResourceWords, ResourceExams, ResourceStudySets:
+		return true
+	}
+	return false
+}
+
+// IsZero reports whether the criteria match everything
+func (c Criteria) IsZero() bool {
+	return c.Query == "" && c.MinLevel == nil && c.MaxLevel == nil && len(c.Tags) == 0 && c.List == "" &&
+		c.Premium == nil && c.Unlocked == nil && c.Public == nil
+}
+
+// Merge returns c with the fields set in override replacing its own
+func (c Criteria) Merge(override Criteria) Criteria {
+	if override.Query != "" {
+		c.Query = override.Query
+	}
+	if override.MinLevel != nil {
+		c.MinLevel = override.MinLevel
+	}
+	if override.MaxLevel != nil {
+		c.MaxLevel = override.MaxLevel
+	}
+	if len(override.Tags) > 0 {
+		c.Tags = override.Tags
+	}
+	if override.List != "" {
+		c.List = override.List
+	}
+	if override.Premium != nil {
+		c.Premium = override.Premium
+	}
+	if override.Unlocked != nil {
+		c.Unlocked = override.Unlocked
+	}
+	if override.Public != nil {
+		c.Public = override.Public
+	}
+	return c
+}
+
+// Normalize trims and lowercases the criteria and checks they fit resource
+func (c *Criteria) Normalize(resource string) error {
+	if !ValidResource(resource) {
+		return ErrInvalidResource
+	}
+	c.Query = strings.Join(strings.Fields(c.Query), " ")
+	if utf8.RuneCountInString(c.Query) > MaxQueryLength {
+		return fmt.Errorf("%w: query is longer than %d characters", ErrInvalidCriteria, MaxQueryLength)
+	}
+
+	// Tags may be given as one comma separated value
+	var tags []string
+	for _, value := range c.Tags {
+		for _, tag := range strings.Split(value, ",") {
+			if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" && !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	c.Tags = tags
+	c.List = strings.ToUpper(strings.TrimSpace(c.List))
+
+	var unsupported []string
+	if resource != ResourceWords {
+		unsupported = appendSet(unsupported, "min_level", c.MinLevel != nil)
+		unsupported = appendSet(unsupported, "max_level", c.MaxLevel != nil)
+		unsupported = appendSet(unsupported, "tags", len(c.Tags) > 0)
+		unsupported = appendSet(unsupported, "list", c.List != "")
+	}
+	if resource != ResourceExams {
+		unsupported = appendSet(unsupported, "premium", c.Premium != nil)
+		unsupported = appendSet(unsupported, "unlocked", c.Unlocked != nil)
+	}
+	if resource != ResourceStudySets {
+		unsupported = appendSet(unsupported, "public", c.Public != nil)
+	}
+	if len(unsupported) > 0 {
+		return fmt.Errorf("%w: %s cannot filter %s", ErrInvalidCriteria, strings.Join(unsupported, ", "), resource)
+	}
+
+	switch {
+	case len(c.Tags) > MaxTags:
+		return fmt.Errorf("%w: more than %d tags", ErrInvalidCriteria, MaxTags)
+	case c.MinLevel != nil && *c.MinLevel < 1, c.MaxLevel != nil && *c.MaxLevel < 1:
+		return fmt.Errorf("%w: levels start at 1", ErrInvalidCriteria)
+	case c.MinLevel != nil && c.MaxLevel != nil && *c.MinLevel > *c.MaxLevel:
+		return fmt.Errorf("%w: min_level is above max_level", ErrInvalidCriteria)
+	}
+	return nil
+}
+
+func appendSet(names []string, name string, set bool) []string {
+	if set {
+		return append(names, name)
+	}
+	return names
+}
+
+// WordParams are the query parameters listing words matching c
+func (c Criteria) WordParams(limit, offset int32) db.ListWordsFilteredParams {
+	return db.ListWordsFilteredParams{
+		Query:    nullString(c.Query),
+		MinLevel: nullInt32(c.MinLevel),
+		MaxLevel: nullInt32(c.MaxLevel),
+		Tags:     append([]string{}, c.Tags...),
+		ListName: nullString(c.List),
+		Limit:    limit,
+		Offset:   offset,
+	}
+}
+
+// ExamParams are the query parameters listing exams matching c
+func (c Criteria) ExamParams() db.ListExamsFilteredParams {
+	return db.ListExamsFilteredParams{
+		Query:      nullString(c.Query),
+		IsPremium:  nullBool(c.Premium),
+		IsUnlocked: nullBool(c.Unlocked),
+	}
+}
+
+// StudySetParams are the query parameters listing the study sets of a user
+// matching c, or the public ones when userID is 0
+func (c Criteria) StudySetParams(userID, limit, offset int32) db.ListStudySetsFilteredParams {
+	return db.ListStudySetsFilteredParams{
+		UserID:   sql.NullInt32{Int32: userID, Valid: userID > 0},
+		Query:    nullString(c.Query),
+		IsPublic: nullBool(c.Public),
+		Limit:    limit,
+		Offset:   offset,
+	}
+}
+
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+func nullInt32(value *int32) sql.NullInt32 {
+	if value == nil {
+		return sql.NullInt32{}
+	}
+	return sql.NullInt32{Int32: *value, Valid: true}
+}
+
+func nullBool(value *bool) sql.NullBool {
+	if value == nil {
+		return sql.NullBool{}
+	}
+	return sql.NullBool{Bool: *value, Valid: true}
+}
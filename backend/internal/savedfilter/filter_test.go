@@ -0,0 +1,52 @@
+package savedfilter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ptr[T any](value T) *T {
+	return &value
+}
+
+func TestNormalize(t *testing.T) {
+	criteria := Criteria{Query: "  business   trip ", MinLevel: ptr[int32](3), Tags: []string{"Business, travel", "business"}, List: " ngsl "}
+	require.NoError(t, criteria.Normalize(ResourceWords))
+
+	assert.Equal(t, "business trip", criteria.Query)
+	assert.Equal(t, []string{"business", "travel"}, criteria.Tags)
+	assert.Equal(t, "NGSL", criteria.List)
+}
+
+func TestNormalizeRejects(t *testing.T) {
+	tests := map[string]struct {
+		resource string
+		criteria Criteria
+	}{
+		"unknown resource":     {"grammars", Criteria{}},
+		"word field on exams":  {ResourceExams, Criteria{MinLevel: ptr[int32](2)}},
+		"exam field on words":  {ResourceWords, Criteria{Premium: ptr(true)}},
+		"public on exams":      {ResourceExams, Criteria{Public: ptr(false)}},
+		"inverted level range": {ResourceWords, Criteria{MinLevel: ptr[int32](4), MaxLevel: ptr[int32](2)}},
+		"level below one":      {ResourceWords, Criteria{MaxLevel: ptr[int32](0)}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, tt.criteria.Normalize(tt.resource))
+		})
+	}
+}
+
+func TestMerge(t *testing.T) {
+	saved := Criteria{Query: "meeting", MinLevel: ptr[int32](3), Tags: []string{"business"}}
+	merged := saved.Merge(Criteria{MinLevel: ptr[int32](4)})
+
+	assert.Equal(t, "meeting", merged.Query)
+	assert.Equal(t, int32(4), *merged.MinLevel)
+	assert.Equal(t, []string{"business"}, merged.Tags)
+	assert.Equal(t, int32(3), *saved.MinLevel, "the saved criteria are not changed")
+	assert.True(t, Criteria{}.IsZero())
+	assert.False(t, merged.IsZero())
+}
@@ -0,0 +1,204 @@
+package savedfilter
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/lib/pq"
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+// MaxNameLength bounds filter names
+const MaxNameLength = 100
+
+var (
+	ErrNotFound      = errors.New("saved filter not found")
+	ErrDuplicateName = errors.New("a filter with this name already exists")
+	ErrInvalidName   = fmt.Errorf("filter name must be 1 to %d characters", MaxNameLength)
+	ErrWrongResource = errors.New("saved filter is for another resource")
+	ErrLimitReached  = errors.New("saved filter limit reached")
+)
+
+// Filter is a named filter set of a user
+type Filter struct {
+	ID        int32     `json:"id"`
+	Resource  string    `json:"resource"`
+	Name      string    `json:"name"`
+	Criteria  Criteria  `json:"criteria"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func newFilter(row db.SavedFilter) Filter {
+	filter := Filter{
+		ID:        row.ID,
+		Resource:  row.Resource,
+		Name:      row.Name,
+		CreatedAt: row.CreatedAt,
+		UpdatedAt: row.UpdatedAt,
+	}
+	// Criteria are validated before they are stored
+	_ = json.Unmarshal(row.Criteria, &filter.Criteria)
+	return filter
+}
+
+// Service stores saved filters
+type Service struct {
+	store db.Querier
+	// limit is the number of filters a user may save; 0 means no limit
+	limit int
+}
+
+// NewService creates a saved filter service allowing limit filters per user
+func NewService(store db.Querier, limit int) *Service {
+	return &Service{store: store, limit: limit}
+}
+
+// List returns the filters of a user, for one resource or for all when
+// resource is empty
+func (s *Service) List(ctx context.Context, userID int32, resource string) ([]Filter, error) {
+	if resource != "" && !ValidResource(resource) {
+		return nil, ErrInvalidResource
+	}
+	rows, err := s.store.ListSavedFilters(ctx, db.ListSavedFiltersParams{UserID: userID, Resource: resource})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list saved filters: %w", err)
+	}
+	filters := make([]Filter, 0, len(rows))
+	for _, row := range rows {
+		filters = append(filters, newFilter(row))
+	}
+	return filters, nil
+}
+
+// Get returns a filter of a user
+func (s *Service) Get(ctx context.Context, userID, id int32) (Filter, error) {
+	row, err := s.store.GetSavedFilter(ctx, db.GetSavedFilterParams{ID: id, UserID: userID})
+	if errors.Is(err, sql.ErrNoRows) {
+		return Filter{}, ErrNotFound
+	}
+	if err != nil {
+		return Filter{}, fmt.Errorf("failed to get saved filter: %w", err)
+	}
+	return newFilter(row), nil
+}
+
+// Create saves a filter for a user
+func (s *Service) Create(ctx context.Context, userID int32, resource, name string, criteria Criteria) (Filter, error) {
+	name, data, err := prepare(resource, name, criteria)
+	if err != nil {
+		return Filter{}, err
+	}
+	if s.limit > 0 {
+		count, err := s.store.CountSavedFilters(ctx, userID)
+		if err != nil {
+			return Filter{}, fmt.Errorf("failed to count saved filters: %w", err)
+		}
+		if count >= int64(s.limit) {
+			return Filter{}, fmt.Errorf("%w: at most %d filters can be saved", ErrLimitReached, s.limit)
+		}
+	}
+
+	row, err := s.store.CreateSavedFilter(ctx, db.CreateSavedFilterParams{
+		UserID:   userID,
+		Resource: resource,
+		Name:     name,
+		Criteria: data,
+	})
+	if isUniqueViolation(err) {
+		return Filter{}, ErrDuplicateName
+	}
+	if err != nil {
+		return Filter{}, fmt.Errorf("failed to create saved filter: %w", err)
+	}
+	return newFilter(row), nil
+}
+
+// Update renames a filter of a user and replaces its criteria
+func (s *Service) Update(ctx context.Context, userID, id int32, name string, criteria Criteria) (Filter, error) {
+	current, err := s.Get(ctx, userID, id)
+	if err != nil {
+		return Filter{}, err
+	}
+	name, data, err := prepare(current.Resource, name, criteria)
+	if err != nil {
+		return Filter{}, err
+	}
+
+	row, err := s.store.UpdateSavedFilter(ctx, db.UpdateSavedFilterParams{
+		ID:       id,
+		UserID:   userID,
+		Name:     name,
+		Criteria: data,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return Filter{}, ErrNotFound
+	}
+	if isUniqueViolation(err) {
+		return Filter{}, ErrDuplicateName
+	}
+	if err != nil {
+		return Filter{}, fmt.Errorf("failed to update saved filter: %w", err)
+	}
+	return newFilter(row), nil
+}
+
+// Delete removes a filter of a user
+func (s *Service) Delete(ctx context.Context, userID, id int32) error {
+	removed, err := s.store.DeleteSavedFilter(ctx, db.DeleteSavedFilterParams{ID: id, UserID: userID})
+	if err != nil {
+		return fmt.Errorf("failed to delete saved filter: %w", err)
+	}
+	if removed == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Resolve returns the criteria a list request filters resource with: the
+// saved filter filterID, if any, with the fields given in the request on
+// top of it
+func (s *Service) Resolve(ctx context.Context, userID, filterID int32, resource string, inline Criteria) (Criteria, error) {
+	criteria := inline
+	if filterID > 0 {
+		filter, err := s.Get(ctx, userID, filterID)
+		if err != nil {
+			return Criteria{}, err
+		}
+		if filter.Resource != resource {
+			return Criteria{}, fmt.Errorf("%w: filter %d is for %s", ErrWrongResource, filterID, filter.Resource)
+		}
+		criteria = filter.Criteria.Merge(inline)
+	}
+	if err := criteria.Normalize(resource); err != nil {
+		return Criteria{}, err
+	}
+	return criteria, nil
+}
+
+// prepare checks a filter and encodes its criteria
+func prepare(resource, name string, criteria Criteria) (string, json.RawMessage, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > MaxNameLength {
+		return "", nil, ErrInvalidName
+	}
+	if err := criteria.Normalize(resource); err != nil {
+		return "", nil, err
+	}
+	data, err := json.Marshal(criteria)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to encode filter: %w", err)
+	}
+	return name, data, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
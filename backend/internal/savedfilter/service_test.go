@@ -0,0 +1,98 @@
+package savedfilter
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+// filterStore keeps saved filters in memory; other Querier methods are not used
+type filterStore struct {
+	db.Querier
+	rows []db.SavedFilter
+}
+
+func (s *filterStore) CountSavedFilters(ctx context.Context, userID int32) (int64, error) {
+	var count int64
+	for _, row := range s.rows {
+		if row.UserID == userID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (s *filterStore) CreateSavedFilter(ctx context.Context, arg db.CreateSavedFilterParams) (db.SavedFilter, error) {
+	for _, row := range s.rows {
+		if row.UserID == arg.UserID && row.Resource == arg.Resource && row.Name == arg.Name {
+			return db.SavedFilter{}, &pq.Error{Code: "23505"}
+		}
+	}
+	row := db.SavedFilter{ID: int32(len(s.rows) + 1), UserID: arg.UserID, Resource: arg.Resource, Name: arg.Name,
+		Criteria: arg.Criteria, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	s.rows = append(s.rows, row)
+	return row, nil
+}
+
+func (s *filterStore) GetSavedFilter(ctx context.Context, arg db.GetSavedFilterParams) (db.SavedFilter, error) {
+	for _, row := range s.rows {
+		if row.ID == arg.ID && row.UserID == arg.UserID {
+			return row, nil
+		}
+	}
+	return db.SavedFilter{}, sql.ErrNoRows
+}
+
+func TestCreateEnforcesLimitAndNames(t *testing.T) {
+	service := NewService(&filterStore{}, 2)
+	ctx := context.Background()
+
+	filter, err := service.Create(ctx, 1, ResourceWords, " Business words level 3+ ", Criteria{MinLevel: ptr[int32](3), Tags: []string{"Business"}})
+	require.NoError(t, err)
+	assert.Equal(t, "Business words level 3+", filter.Name)
+	assert.Equal(t, []string{"business"}, filter.Criteria.Tags)
+
+	_, err = service.Create(ctx, 1, ResourceWords, "Business words level 3+", Criteria{})
+	assert.ErrorIs(t, err, ErrDuplicateName)
+
+	_, err = service.Create(ctx, 1, ResourceExams, "Free exams", Criteria{Premium: ptr(false)})
+	require.NoError(t, err)
+	_, err = service.Create(ctx, 1, ResourceExams, "Unlocked", Criteria{Unlocked: ptr(true)})
+	assert.ErrorIs(t, err, ErrLimitReached)
+
+	// The limit is per user
+	_, err = service.Create(ctx, 2, ResourceExams, "Unlocked", Criteria{Unlocked: ptr(true)})
+	assert.NoError(t, err)
+
+	_, err = service.Create(ctx, 2, ResourceWords, "", Criteria{})
+	assert.ErrorIs(t, err, ErrInvalidName)
+}
+
+func TestResolve(t *testing.T) {
+	service := NewService(&filterStore{}, 0)
+	ctx := context.Background()
+	filter, err := service.Create(ctx, 1, ResourceWords, "Business", Criteria{MinLevel: ptr[int32](3), Tags: []string{"business"}})
+	require.NoError(t, err)
+
+	criteria, err := service.Resolve(ctx, 1, filter.ID, ResourceWords, Criteria{Query: "meet"})
+	require.NoError(t, err)
+	assert.Equal(t, "meet", criteria.Query)
+	assert.Equal(t, int32(3), *criteria.MinLevel)
+
+	_, err = service.Resolve(ctx, 1, filter.ID, ResourceExams, Criteria{})
+	assert.ErrorIs(t, err, ErrWrongResource)
+
+	// Filters of other users are not found
+	_, err = service.Resolve(ctx, 2, filter.ID, ResourceWords, Criteria{})
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	criteria, err = service.Resolve(ctx, 2, 0, ResourceWords, Criteria{})
+	require.NoError(t, err)
+	assert.True(t, criteria.IsZero())
+}
@@ -0,0 +1,117 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/toeic-app/internal/backup"
+)
+
+// staleDatabaseBackup is how old the newest backup of an automatically
+// backed up database can get before it is reported: two missed daily runs
+const staleDatabaseBackup = 48 * time.Hour
+
+// DatabaseStatus is the backup status of a database backed up besides the
+// application database
+type DatabaseStatus struct {
+	Name           string                 `json:"name" example:"analytics"`
+	Database       string                 `json:"database" example:"toeic_analytics"`
+	AutoBackup     bool                   `json:"auto_backup"`
+	LastBackup     *time.Time             `json:"last_backup,omitempty"`
+	NextBackup     *time.Time             `json:"next_backup,omitempty"`
+	TotalBackups   int                    `json:"total_backups"`
+	TotalSize      int64                  `json:"total_size"`
+	OldestBackup   *time.Time             `json:"oldest_backup,omitempty"`
+	Retention      backup.RetentionPolicy `json:"retention"`
+	MaxBackupCount int                    `json:"max_backup_count"`
+	Health         string                 `json:"health" example:"healthy"` // healthy, warning or critical
+	Issues         []string               `json:"issues,omitempty"`
+}
+
+// DatabaseStatuses returns the backup status of each database backed up
+// besides the application database, in the configured order
+func (ebs *EnhancedBackupScheduler) DatabaseStatuses(ctx context.Context) []DatabaseStatus {
+	statuses := make([]DatabaseStatus, 0, len(ebs.config.Databases))
+	for _, database := range ebs.config.Databases {
+		status := DatabaseStatus{
+			Name:       database.Name,
+			Database:   database.DBName,
+			AutoBackup: database.AutoBackupEnabled,
+			Retention: backup.RetentionPolicy{
+				Daily:   database.RetentionDaily,
+				Weekly:  database.RetentionWeekly,
+				Monthly: database.RetentionMonthly,
+			},
+			MaxBackupCount: database.MaxBackupCount,
+			Health:         "healthy",
+		}
+		ebs.databaseSchedule(&status)
+
+		manager, err := ebs.managerFor(database.Name)
+		if err != nil {
+			status.report("critical", err.Error())
+			statuses = append(statuses, status)
+			continue
+		}
+		// Newest first
+		files, err := manager.ListBackups(ctx)
+		if err != nil {
+			status.report("critical", fmt.Sprintf("Failed to list backups: %v", err))
+			statuses = append(statuses, status)
+			continue
+		}
+		for _, file := range files {
+			status.TotalBackups++
+			status.TotalSize += file.Size
+		}
+		if len(files) > 0 {
+			last, oldest := files[0].ModTime, files[len(files)-1].ModTime
+			status.LastBackup, status.OldestBackup = &last, &oldest
+		}
+		if status.AutoBackup {
+			switch {
+			case status.LastBackup == nil:
+				status.report("warning", "No backup made yet")
+			case time.Since(*status.LastBackup) > staleDatabaseBackup:
+				status.report("warning", fmt.Sprintf("No backup since %s", status.LastBackup.Format(time.RFC3339)))
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// databaseSchedule fills in when the schedules of a database run next and
+// whether its last scheduled backup failed
+func (ebs *EnhancedBackupScheduler) databaseSchedule(status *DatabaseStatus) {
+	ebs.mutex.Lock()
+	defer ebs.mutex.Unlock()
+
+	if ebs.isRunning {
+		for _, schedule := range ebs.schedules {
+			if !schedule.Enabled || schedule.Database != status.Name {
+				continue
+			}
+			if next := ebs.calculateNextRun(schedule); status.NextBackup == nil || next.Before(*status.NextBackup) {
+				status.NextBackup = &next
+			}
+		}
+	}
+	for i := len(ebs.backupHistory) - 1; i >= 0; i-- {
+		if item := ebs.backupHistory[i]; item.Database == status.Name {
+			if !item.Success {
+				status.report("warning", fmt.Sprintf("Scheduled backup %s failed: %s", item.ScheduleName, item.Error))
+			}
+			break
+		}
+	}
+}
+
+// report records an issue, raising the health to severity
+func (s *DatabaseStatus) report(severity, issue string) {
+	if s.Health != "critical" {
+		s.Health = severity
+	}
+	s.Issues = append(s.Issues, issue)
+}
@@ -0,0 +1,82 @@
+package scheduler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toeic-app/internal/backup"
+	"github.com/toeic-app/internal/config"
+)
+
+func TestDatabaseStatuses(t *testing.T) {
+	dir := t.TempDir()
+	backupConfig := config.BackupConfig{
+		Enabled:   true,
+		BackupDir: dir,
+		Databases: []config.BackupDatabase{
+			{Name: "analytics", DBName: "toeic_analytics", AutoBackupEnabled: true, BackupTime: "04:30", RetentionDaily: 3, MaxBackupCount: 10},
+			{Name: "reporting", DBName: "reporting", AutoBackupEnabled: true, BackupTime: "05:00", RetentionDaily: 7, MaxBackupCount: 10},
+		},
+	}
+	ebs := NewEnhancedBackupScheduler(backupConfig, config.Config{DBName: "toeic"})
+
+	schedule := ebs.GetSchedules()["analytics_daily_full"]
+	require.NotNil(t, schedule)
+	assert.Equal(t, "analytics", schedule.Database)
+	assert.Equal(t, "04:30", schedule.Time)
+
+	// The analytics backups are kept apart from those of the application
+	// database, which does not list them
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "analytics"), 0755))
+	old := time.Now().Add(-72 * time.Hour)
+	for name, modTime := range map[string]time.Time{
+		"automatic_backup_20250101_043000.sql.gz": old,
+		"automatic_backup_20250102_043000.sql.gz": old.Add(time.Hour),
+	} {
+		path := filepath.Join(dir, "analytics", name)
+		require.NoError(t, os.WriteFile(path, []byte("backup"), 0644))
+		require.NoError(t, os.Chtimes(path, modTime, modTime))
+	}
+	primary, err := ebs.backupManager.ListBackups(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, primary)
+
+	ebs.addToHistory(BackupHistoryItem{ScheduleName: "reporting_daily_full", Database: "reporting", Error: "pg_dump failed"})
+
+	statuses := ebs.DatabaseStatuses(context.Background())
+	require.Len(t, statuses, 2)
+	analytics := statuses[0]
+	assert.Equal(t, "analytics", analytics.Name)
+	assert.Equal(t, "toeic_analytics", analytics.Database)
+	assert.Equal(t, 2, analytics.TotalBackups)
+	assert.Equal(t, int64(12), analytics.TotalSize)
+	assert.Equal(t, 3, analytics.Retention.Daily)
+	assert.Equal(t, "warning", analytics.Health)
+	require.Len(t, analytics.Issues, 1)
+	assert.Contains(t, analytics.Issues[0], "No backup since")
+
+	reporting := statuses[1]
+	assert.Equal(t, "warning", reporting.Health)
+	assert.Equal(t, []string{"Scheduled backup reporting_daily_full failed: pg_dump failed", "No backup made yet"}, reporting.Issues)
+}
+
+func TestAddScheduleOfDatabase(t *testing.T) {
+	backupConfig := config.BackupConfig{
+		BackupDir: t.TempDir(),
+		Databases: []config.BackupDatabase{{Name: "analytics", DBName: "toeic_analytics"}},
+	}
+	ebs := NewEnhancedBackupScheduler(backupConfig, config.Config{DBName: "toeic"})
+
+	require.NoError(t, ebs.AddSchedule("analytics_hourly", "1h", "Hourly analytics backup", "full", "analytics", nil))
+	assert.Equal(t, "analytics", ebs.GetSchedules()["analytics_hourly"].Database)
+	require.NoError(t, ebs.AddSchedule("primary_hourly", "1h", "Hourly backup", "full", config.PrimaryDatabase, nil))
+	assert.Empty(t, ebs.GetSchedules()["primary_hourly"].Database)
+
+	err := ebs.AddSchedule("billing_hourly", "1h", "Hourly billing backup", "full", "billing", nil)
+	assert.ErrorIs(t, err, backup.ErrUnknownDatabase)
+}
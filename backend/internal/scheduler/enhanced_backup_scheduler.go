@@ -16,6 +16,7 @@ type EnhancedBackupScheduler struct {
 	config         config.BackupConfig
 	dbConfig       config.Config
 	backupManager  *backup.BackupManager
+	databases      map[string]*backup.BackupManager // Other databases backed up, by name
 	schedules      map[string]*ScheduleConfig
 	stopChan       chan struct{}
 	wg             *sync.WaitGroup
@@ -38,14 +39,21 @@ type ScheduleConfig struct {
 	BackupType  string        `json:"backup_type"` // full, incremental, differential
 	Enabled     bool          `json:"enabled"`
 	Retention   time.Duration `json:"retention"` // How long to keep backups from this schedule
-	ticker      *time.Ticker
-	lastRun     time.Time
+	// IOLimit overrides the configured IO throttling for the backups of
+	// the schedule; nil keeps it
+	IOLimit *backup.IOLimit `json:"io_limit,omitempty"`
+	// Database is the database backed up, one of BackupConfig.Databases;
+	// empty is the application database
+	Database string `json:"database,omitempty"`
+	ticker   *time.Ticker
+	lastRun  time.Time
 }
 
 // BackupHistoryItem tracks backup execution history
 type BackupHistoryItem struct {
 	Timestamp    time.Time     `json:"timestamp"`
 	ScheduleName string        `json:"schedule_name"`
+	Database     string        `json:"database,omitempty"` // Empty is the application database
 	BackupType   string        `json:"backup_type"`
 	Filename     string        `json:"filename"`
 	Success      bool          `json:"success"`
@@ -62,6 +70,7 @@ func NewEnhancedBackupScheduler(backupConfig config.BackupConfig, dbConfig confi
 		config:        backupConfig,
 		dbConfig:      dbConfig,
 		backupManager: backup.NewBackupManager(backupConfig, dbConfig),
+		databases:     make(map[string]*backup.BackupManager),
 		schedules:     make(map[string]*ScheduleConfig),
 		stopChan:      make(chan struct{}),
 		wg:            &sync.WaitGroup{},
@@ -70,6 +79,15 @@ func NewEnhancedBackupScheduler(backupConfig config.BackupConfig, dbConfig confi
 		cancel:        cancel,
 	}
 
+	for _, database := range backupConfig.Databases {
+		manager, err := backup.NewDatabaseManager(backupConfig, dbConfig, database.Name)
+		if err != nil {
+			logger.Error("Failed to set up backups of the %s database: %v", database.Name, err)
+			continue
+		}
+		scheduler.databases[database.Name] = manager
+	}
+
 	// Initialize default schedules
 	scheduler.initializeDefaultSchedules()
 
@@ -87,11 +105,12 @@ func (ebs *EnhancedBackupScheduler) initializeDefaultSchedules() {
 		Days:        []string{}, // Every day
 		BackupType:  "full",
 		Enabled:     ebs.config.AutoBackupEnabled,
-		Retention:   ebs.config.GetRetentionDuration(),
+		Retention:   time.Duration(ebs.config.RetentionDaily) * 24 * time.Hour,
 	}
 	ebs.schedules["daily_full"] = dailySchedule
 
-	// Weekly backup on Sunday at 2 AM (with longer retention)
+	// Weekly backup on Sunday at 2 AM, kept as the weekly backups of the
+	// retention policy
 	weeklySchedule := &ScheduleConfig{
 		Name:        "weekly_archive",
 		Description: "Weekly archive backup",
@@ -100,7 +119,7 @@ func (ebs *EnhancedBackupScheduler) initializeDefaultSchedules() {
 		Days:        []string{"Sunday"},
 		BackupType:  "archive",
 		Enabled:     ebs.config.AutoBackupEnabled,
-		Retention:   90 * 24 * time.Hour, // 90 days retention for weekly backups
+		Retention:   time.Duration(ebs.config.RetentionWeekly) * 7 * 24 * time.Hour,
 	}
 	ebs.schedules["weekly_archive"] = weeklySchedule
 
@@ -113,9 +132,25 @@ func (ebs *EnhancedBackupScheduler) initializeDefaultSchedules() {
 		Days:        []string{}, // Will be handled specially for monthly
 		BackupType:  "archive",
 		Enabled:     ebs.config.AutoBackupEnabled,
-		Retention:   365 * 24 * time.Hour, // 1 year retention
+		Retention:   time.Duration(ebs.config.RetentionMonthly) * 30 * 24 * time.Hour, // Approximate
 	}
 	ebs.schedules["monthly_archive"] = monthlySchedule
+
+	// A daily full backup of each other database, at its own time
+	for _, database := range ebs.config.Databases {
+		name := database.Name + "_daily_full"
+		ebs.schedules[name] = &ScheduleConfig{
+			Name:        name,
+			Description: fmt.Sprintf("Daily full backup of the %s database", database.Name),
+			Interval:    24 * time.Hour,
+			Time:        database.BackupTime,
+			Days:        []string{},
+			BackupType:  "full",
+			Enabled:     database.AutoBackupEnabled,
+			Retention:   time.Duration(database.RetentionDaily) * 24 * time.Hour,
+			Database:    database.Name,
+		}
+	}
 }
 
 // Start begins the enhanced backup scheduler
@@ -127,11 +162,17 @@ func (ebs *EnhancedBackupScheduler) Start() error {
 		return fmt.Errorf("enhanced backup scheduler is already running")
 	}
 
-	if !ebs.config.Enabled || !ebs.config.AutoBackupEnabled {
+	if !ebs.config.Enabled || (!ebs.config.AutoBackupEnabled && !ebs.databaseAutoBackup()) {
 		logger.Info("Backup scheduler disabled in configuration")
 		return nil
 	}
 
+	// Recreate stop signals if the scheduler was stopped before (e.g. lost leadership)
+	if ebs.ctx.Err() != nil {
+		ebs.ctx, ebs.cancel = context.WithCancel(context.Background())
+		ebs.stopChan = make(chan struct{})
+	}
+
 	ebs.isRunning = true
 
 	// Start monitoring goroutines for each enabled schedule
@@ -154,6 +195,40 @@ func (ebs *EnhancedBackupScheduler) Start() error {
 	return nil
 }
 
+// SetCatalog makes the scheduled backups be recorded in catalog
+func (ebs *EnhancedBackupScheduler) SetCatalog(catalog backup.Catalog) {
+	ebs.backupManager.SetCatalog(catalog)
+}
+
+// SetAssetSigner makes the scheduled backups download private media assets
+// with URLs signed by signer
+func (ebs *EnhancedBackupScheduler) SetAssetSigner(signer backup.AssetSigner) {
+	ebs.backupManager.SetAssetSigner(signer)
+}
+
+// databaseAutoBackup tells whether another database is backed up
+// automatically
+func (ebs *EnhancedBackupScheduler) databaseAutoBackup() bool {
+	for _, database := range ebs.config.Databases {
+		if database.AutoBackupEnabled {
+			return true
+		}
+	}
+	return false
+}
+
+// managerFor returns the manager backing up the database of a schedule
+func (ebs *EnhancedBackupScheduler) managerFor(database string) (*backup.BackupManager, error) {
+	if database == "" {
+		return ebs.backupManager, nil
+	}
+	manager, ok := ebs.databases[database]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", backup.ErrUnknownDatabase, database)
+	}
+	return manager, nil
+}
+
 // IsRunning returns whether the scheduler is currently running
 func (ebs *EnhancedBackupScheduler) IsRunning() bool {
 	ebs.mutex.Lock()
@@ -173,14 +248,22 @@ func (ebs *EnhancedBackupScheduler) GetSchedules() map[string]*ScheduleConfig {
 	return schedules
 }
 
-// AddSchedule adds a new backup schedule
-func (ebs *EnhancedBackupScheduler) AddSchedule(id, schedule, description, backupType string) error {
+// AddSchedule adds a new backup schedule of database, one of the databases
+// configured to be backed up; empty is the application database. ioLimit
+// overrides the configured IO throttling for its backups; nil keeps it.
+func (ebs *EnhancedBackupScheduler) AddSchedule(id, schedule, description, backupType, database string, ioLimit *backup.IOLimit) error {
 	ebs.mutex.Lock()
 	defer ebs.mutex.Unlock()
 
 	if _, exists := ebs.schedules[id]; exists {
 		return fmt.Errorf("schedule with ID %s already exists", id)
 	}
+	if database == config.PrimaryDatabase {
+		database = ""
+	}
+	if _, err := ebs.managerFor(database); err != nil {
+		return err
+	}
 
 	// Parse interval from schedule string (assuming it's a duration like "24h")
 	interval, err := time.ParseDuration(schedule)
@@ -196,6 +279,8 @@ func (ebs *EnhancedBackupScheduler) AddSchedule(id, schedule, description, backu
 		BackupType:  backupType,
 		Enabled:     true,
 		Retention:   720 * time.Hour, // 30 days default
+		IOLimit:     ioLimit,
+		Database:    database,
 		lastRun:     time.Time{},
 	}
 
@@ -299,6 +384,7 @@ func (ebs *EnhancedBackupScheduler) executeScheduledBackup(scheduleName string,
 	historyItem := BackupHistoryItem{
 		Timestamp:    startTime,
 		ScheduleName: scheduleName,
+		Database:     schedule.Database,
 		BackupType:   schedule.BackupType,
 		Success:      false,
 	}
@@ -307,7 +393,15 @@ func (ebs *EnhancedBackupScheduler) executeScheduledBackup(scheduleName string,
 	description := fmt.Sprintf("Scheduled %s backup (%s)", schedule.BackupType, schedule.Description)
 
 	// Execute backup
-	result, err := ebs.backupManager.CreateBackup(ebs.ctx, description, schedule.BackupType)
+	ctx := ebs.ctx
+	if schedule.IOLimit != nil {
+		ctx = backup.WithIOLimit(ctx, *schedule.IOLimit)
+	}
+	manager, err := ebs.managerFor(schedule.Database)
+	var result *backup.BackupResult
+	if err == nil {
+		result, err = manager.CreateBackup(ctx, description, schedule.BackupType)
+	}
 
 	// Record results
 	historyItem.Duration = time.Since(startTime)
@@ -321,10 +415,12 @@ func (ebs *EnhancedBackupScheduler) executeScheduledBackup(scheduleName string,
 		historyItem.Size = result.Size
 		logger.Info("Scheduled backup completed: %s -> %s", scheduleName, result.Metadata.Filename)
 
-		// Update last backup time
-		ebs.mutex.Lock()
-		ebs.lastBackupTime = startTime
-		ebs.mutex.Unlock()
+		// Update last backup time of the application database
+		if schedule.Database == "" {
+			ebs.mutex.Lock()
+			ebs.lastBackupTime = startTime
+			ebs.mutex.Unlock()
+		}
 	}
 
 	// Add to history (keep last 100 items)
@@ -414,18 +510,27 @@ func (ebs *EnhancedBackupScheduler) runCleanup() {
 	}
 }
 
-// performCleanup performs cleanup of old backups based on retention policies
+// performCleanup removes the backups the grandfather-father-son retention
+// policy does not keep, of each database with its own policy
 func (ebs *EnhancedBackupScheduler) performCleanup() {
-	logger.Info("Performing scheduled backup cleanup")
+	logger.Info("Performing scheduled backup cleanup (keeping %s)", ebs.backupManager.RetentionPolicy())
 
-	// This would implement sophisticated cleanup logic based on:
-	// 1. Schedule-specific retention policies
-	// 2. Backup types (keep more archives than daily backups)
-	// 3. Available disk space
-	// 4. Backup validation status
+	plan, deleted, err := ebs.backupManager.EnforceRetention(ebs.ctx)
+	if err != nil {
+		logger.Error("Scheduled backup cleanup failed: %v", err)
+	} else {
+		logger.Info("Backup cleanup completed: kept %d backups, removed %d", len(plan.Keep), deleted)
+	}
 
-	// For now, just log that cleanup would run
-	logger.Info("Backup cleanup completed")
+	for name, manager := range ebs.databases {
+		plan, deleted, err := manager.EnforceRetention(ebs.ctx)
+		if err != nil {
+			logger.Error("Scheduled backup cleanup of the %s database failed: %v", name, err)
+			continue
+		}
+		logger.Info("Backup cleanup of the %s database completed: kept %d backups, removed %d (keeping %s)",
+			name, len(plan.Keep), deleted, manager.RetentionPolicy())
+	}
 }
 
 // runHealthMonitoring monitors backup system health
@@ -0,0 +1,133 @@
+package security
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// windowCounter counts events in a fixed time window
+type windowCounter struct {
+	count       int
+	windowStart time.Time
+}
+
+// BotDetector flags clients that should solve a CAPTCHA before authenticating.
+// A client is suspicious when an IP address sends too many auth requests
+// (velocity) or when an IP address or account has too many failed attempts.
+type BotDetector struct {
+	mu                sync.Mutex
+	requests          map[string]*windowCounter // by IP
+	failures          map[string]*windowCounter // by "ip:<addr>" and "account:<email>"
+	failureThreshold  int
+	velocityThreshold int
+	window            time.Duration
+	lastCleanup       time.Time
+}
+
+// NewBotDetector creates a detector. A threshold of 0 disables that check.
+func NewBotDetector(failureThreshold, velocityThreshold int, window time.Duration) *BotDetector {
+	return &BotDetector{
+		requests:          make(map[string]*windowCounter),
+		failures:          make(map[string]*windowCounter),
+		failureThreshold:  failureThreshold,
+		velocityThreshold: velocityThreshold,
+		window:            window,
+		lastCleanup:       time.Now(),
+	}
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
+
+func accountKey(account string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(account))
+}
+
+// increment adds one event to a counter, starting a new window if the current one expired
+func (d *BotDetector) increment(counters map[string]*windowCounter, key string, now time.Time) int {
+	counter, ok := counters[key]
+	if !ok || now.Sub(counter.windowStart) >= d.window {
+		counter = &windowCounter{windowStart: now}
+		counters[key] = counter
+	}
+	counter.count++
+	return counter.count
+}
+
+// current returns the count of a counter inside its window
+func (d *BotDetector) current(counters map[string]*windowCounter, key string, now time.Time) int {
+	counter, ok := counters[key]
+	if !ok || now.Sub(counter.windowStart) >= d.window {
+		return 0
+	}
+	return counter.count
+}
+
+// RecordRequest counts an auth request from ip
+func (d *BotDetector) RecordRequest(ip string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	d.increment(d.requests, ip, now)
+	d.cleanupLocked(now)
+}
+
+// RecordFailure counts a failed authentication attempt
+func (d *BotDetector) RecordFailure(ip, account string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	d.increment(d.failures, ipKey(ip), now)
+	if account != "" {
+		d.increment(d.failures, accountKey(account), now)
+	}
+}
+
+// RecordSuccess clears the failed attempts of an account after a successful login
+func (d *BotDetector) RecordSuccess(account string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.failures, accountKey(account))
+}
+
+// RequiresChallenge reports whether the next request from ip for account must
+// include a solved CAPTCHA, and why.
+func (d *BotDetector) RequiresChallenge(ip, account string) (bool, string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	if d.velocityThreshold > 0 && d.current(d.requests, ip, now) > d.velocityThreshold {
+		return true, "velocity"
+	}
+	if d.failureThreshold > 0 {
+		if d.current(d.failures, ipKey(ip), now) >= d.failureThreshold {
+			return true, "failed_attempts"
+		}
+		if account != "" && d.current(d.failures, accountKey(account), now) >= d.failureThreshold {
+			return true, "failed_attempts"
+		}
+	}
+	return false, ""
+}
+
+// cleanupLocked drops expired counters at most once per window
+func (d *BotDetector) cleanupLocked(now time.Time) {
+	if now.Sub(d.lastCleanup) < d.window {
+		return
+	}
+	d.lastCleanup = now
+
+	for _, counters := range []map[string]*windowCounter{d.requests, d.failures} {
+		for key, counter := range counters {
+			if now.Sub(counter.windowStart) >= d.window {
+				delete(counters, key)
+			}
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package security
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBotDetectorFailedAttempts(t *testing.T) {
+	d := NewBotDetector(3, 0, time.Minute)
+
+	for i := 0; i < 2; i++ {
+		d.RecordFailure("10.0.0.1", "user@example.com")
+	}
+	required, _ := d.RequiresChallenge("10.0.0.1", "user@example.com")
+	assert.False(t, required)
+
+	d.RecordFailure("10.0.0.1", "User@Example.com")
+	required, reason := d.RequiresChallenge("10.0.0.2", "user@example.com")
+	assert.True(t, required) // the account is flagged from any IP
+	assert.Equal(t, "failed_attempts", reason)
+
+	d.RecordSuccess("user@example.com")
+	required, _ = d.RequiresChallenge("10.0.0.2", "user@example.com")
+	assert.False(t, required)
+	required, _ = d.RequiresChallenge("10.0.0.1", "other@example.com")
+	assert.True(t, required) // the IP stays flagged until the window expires
+}
+
+func TestBotDetectorVelocity(t *testing.T) {
+	d := NewBotDetector(0, 2, 50*time.Millisecond)
+
+	d.RecordRequest("10.0.0.1")
+	d.RecordRequest("10.0.0.1")
+	required, _ := d.RequiresChallenge("10.0.0.1", "")
+	assert.False(t, required)
+
+	d.RecordRequest("10.0.0.1")
+	required, reason := d.RequiresChallenge("10.0.0.1", "")
+	assert.True(t, required)
+	assert.Equal(t, "velocity", reason)
+
+	time.Sleep(60 * time.Millisecond)
+	required, _ = d.RequiresChallenge("10.0.0.1", "")
+	assert.False(t, required)
+}
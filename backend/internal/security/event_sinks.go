@@ -0,0 +1,233 @@
+package security
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/toeic-app/internal/config"
+)
+
+// Security event sinks
+const (
+	EventSinkNone    = "none"
+	EventSinkSyslog  = "syslog"
+	EventSinkWebhook = "webhook"
+	EventSinkKafka   = "kafka"
+)
+
+// NewEventSink creates the sink selected by SECURITY_EVENT_SINK, or nil for "none"
+func NewEventSink(cfg config.Config) (EventSink, error) {
+	switch cfg.SecurityEventSink {
+	case "", EventSinkNone:
+		return nil, nil
+	case EventSinkSyslog:
+		return NewSyslogSink(cfg.SecurityEventSyslogNetwork, cfg.SecurityEventSyslogAddress)
+	case EventSinkWebhook:
+		return NewWebhookSink(cfg.SecurityEventWebhookURL, cfg.SecurityEventWebhookToken), nil
+	case EventSinkKafka:
+		return NewKafkaRESTSink(cfg.SecurityEventKafkaRESTURL, cfg.SecurityEventKafkaTopic), nil
+	}
+	return nil, fmt.Errorf("unsupported security event sink: %s", cfg.SecurityEventSink)
+}
+
+// syslogSeverity maps event severities to RFC 5424 severity levels
+var syslogSeverity = map[SecuritySeverity]int{
+	SeverityLow:      6, // informational
+	SeverityMedium:   4, // warning
+	SeverityHigh:     3, // error
+	SeverityCritical: 2, // critical
+}
+
+// syslogFacility is local0
+const syslogFacility = 16
+
+// SyslogSink writes RFC 5424 messages with a JSON payload over UDP or TCP
+type SyslogSink struct {
+	network  string
+	address  string
+	hostname string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewSyslogSink creates a syslog sink. The connection is opened lazily.
+func NewSyslogSink(network, address string) (*SyslogSink, error) {
+	if network != "udp" && network != "tcp" {
+		return nil, fmt.Errorf("unsupported syslog network: %s", network)
+	}
+	if address == "" {
+		return nil, fmt.Errorf("syslog address is required")
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "-"
+	}
+	return &SyslogSink{network: network, address: address, hostname: hostname}, nil
+}
+
+func (s *SyslogSink) Name() string {
+	return "syslog://" + s.address
+}
+
+// formatSyslog formats an event as an RFC 5424 message
+func (s *SyslogSink) formatSyslog(event SecurityEvent) ([]byte, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	severity, ok := syslogSeverity[event.Severity]
+	if !ok {
+		severity = 5 // notice
+	}
+	header := fmt.Sprintf("<%d>1 %s %s toeic-app - %s - ",
+		syslogFacility*8+severity, event.Timestamp.UTC().Format(time.RFC3339Nano), s.hostname, event.Type)
+	return append([]byte(header), append(payload, '\n')...), nil
+}
+
+func (s *SyslogSink) Send(ctx context.Context, events []SecurityEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, s.network, s.address)
+		if err != nil {
+			return fmt.Errorf("failed to connect to syslog: %w", err)
+		}
+		s.conn = conn
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		s.conn.SetWriteDeadline(deadline)
+	}
+
+	for _, event := range events {
+		message, err := s.formatSyslog(event)
+		if err != nil {
+			return fmt.Errorf("failed to encode security event: %w", err)
+		}
+		if _, err := s.conn.Write(message); err != nil {
+			// Reconnect on the next attempt
+			s.conn.Close()
+			s.conn = nil
+			return fmt.Errorf("failed to write to syslog: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *SyslogSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// postJSON posts a JSON body and fails on non-2xx responses
+func postJSON(ctx context.Context, client *http.Client, url, contentType, bearerToken string, body interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode security events: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	if bearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+bearerToken)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// WebhookSink posts batches of events as a JSON array
+type WebhookSink struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// NewWebhookSink creates a webhook sink. The token is sent as a bearer token when set.
+func NewWebhookSink(url, token string) *WebhookSink {
+	return &WebhookSink{url: url, token: token, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (s *WebhookSink) Name() string {
+	return "webhook"
+}
+
+func (s *WebhookSink) Send(ctx context.Context, events []SecurityEvent) error {
+	return postJSON(ctx, s.client, s.url, "application/json", s.token, events)
+}
+
+func (s *WebhookSink) Close() error {
+	return nil
+}
+
+// KafkaRESTSink produces events to a Kafka topic through a Kafka REST Proxy
+type KafkaRESTSink struct {
+	url    string
+	topic  string
+	client *http.Client
+}
+
+// NewKafkaRESTSink creates a sink for the REST Proxy at restURL
+func NewKafkaRESTSink(restURL, topic string) *KafkaRESTSink {
+	return &KafkaRESTSink{
+		url:    strings.TrimRight(restURL, "/") + "/topics/" + topic,
+		topic:  topic,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (s *KafkaRESTSink) Name() string {
+	return "kafka://" + s.topic
+}
+
+// kafkaRecord is a record of the REST Proxy v2 JSON format
+type kafkaRecord struct {
+	Key   string        `json:"key"`
+	Value SecurityEvent `json:"value"`
+}
+
+func (s *KafkaRESTSink) Send(ctx context.Context, events []SecurityEvent) error {
+	records := make([]kafkaRecord, 0, len(events))
+	for _, event := range events {
+		// Keyed by type so events of one type stay ordered within a partition
+		records = append(records, kafkaRecord{Key: string(event.Type), Value: event})
+	}
+	return postJSON(ctx, s.client, s.url, "application/vnd.kafka.json.v2+json", "",
+		map[string]interface{}{"records": records})
+}
+
+func (s *KafkaRESTSink) Close() error {
+	return nil
+}
@@ -0,0 +1,230 @@
+package security
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/toeic-app/internal/config"
+	"github.com/toeic-app/internal/logger"
+)
+
+// Security event types exported to the SIEM in addition to the monitor's types
+const (
+	EventTypePermissionDenied SecurityEventType = "permission_denied"
+	EventTypeAdminAction      SecurityEventType = "admin_action"
+)
+
+// EventSink delivers batches of security events to an external system
+type EventSink interface {
+	Name() string
+	Send(ctx context.Context, events []SecurityEvent) error
+	Close() error
+}
+
+// EventStreamStats counts events passing through the stream
+type EventStreamStats struct {
+	Sink     string `json:"sink"`
+	Emitted  int64  `json:"emitted"`
+	Sent     int64  `json:"sent"`
+	Dropped  int64  `json:"dropped"` // Buffer full, the sink cannot keep up
+	Failed   int64  `json:"failed"`  // Delivery failed after all retries
+	Buffered int    `json:"buffered"`
+}
+
+// EventStream buffers security events and ships them to a sink in batches.
+// Emit never blocks the request path: when the buffer is full because the
+// sink is slow or unreachable, new events are dropped and counted instead.
+// Every event is also written to the application log, so nothing is lost
+// from the local audit trail.
+type EventStream struct {
+	sink          EventSink
+	events        chan SecurityEvent
+	batchSize     int
+	flushInterval time.Duration
+	maxRetries    int
+
+	emitted      atomic.Int64
+	sent         atomic.Int64
+	dropped      atomic.Int64
+	failed       atomic.Int64
+	lastDropWarn atomic.Int64
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// NewEventStream creates a stream and starts its delivery worker
+func NewEventStream(sink EventSink, bufferSize, batchSize int, flushInterval time.Duration, maxRetries int) *EventStream {
+	if bufferSize <= 0 {
+		bufferSize = 1000
+	}
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	if flushInterval <= 0 {
+		flushInterval = 5 * time.Second
+	}
+
+	stream := &EventStream{
+		sink:          sink,
+		events:        make(chan SecurityEvent, bufferSize),
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		maxRetries:    maxRetries,
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	go stream.run()
+	return stream
+}
+
+// NewEventStreamFromConfig creates the stream for the configured sink.
+// It returns nil when SECURITY_EVENT_SINK is "none".
+func NewEventStreamFromConfig(cfg config.Config) (*EventStream, error) {
+	sink, err := NewEventSink(cfg)
+	if err != nil || sink == nil {
+		return nil, err
+	}
+
+	logger.Info("Security events are exported to %s", sink.Name())
+	return NewEventStream(sink, cfg.SecurityEventBufferSize, cfg.SecurityEventBatchSize,
+		cfg.SecurityEventFlushInterval, cfg.SecurityEventMaxRetries), nil
+}
+
+// Emit queues an event without blocking. It is safe to call on a nil stream.
+func (s *EventStream) Emit(event SecurityEvent) {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	if event.ID == "" {
+		event.ID = generateEventID()
+	}
+
+	logger.InfoWithFields(logger.Fields{
+		"component":   "security_event",
+		"event_id":    event.ID,
+		"event_type":  string(event.Type),
+		"severity":    string(event.Severity),
+		"user_id":     event.UserID,
+		"ip_address":  event.IPAddress,
+		"path":        event.Path,
+		"method":      event.Method,
+		"description": event.Description,
+	}, "Security event")
+
+	if s == nil {
+		return
+	}
+
+	s.emitted.Add(1)
+	select {
+	case s.events <- event:
+	default:
+		s.dropped.Add(1)
+		// Warn at most once a minute while the buffer stays full
+		now := time.Now().Unix()
+		last := s.lastDropWarn.Load()
+		if now-last >= 60 && s.lastDropWarn.CompareAndSwap(last, now) {
+			logger.Warn("Security event buffer full, dropping events (%d dropped so far)", s.dropped.Load())
+		}
+	}
+}
+
+// Stats returns delivery counters
+func (s *EventStream) Stats() EventStreamStats {
+	return EventStreamStats{
+		Sink:     s.sink.Name(),
+		Emitted:  s.emitted.Load(),
+		Sent:     s.sent.Load(),
+		Dropped:  s.dropped.Load(),
+		Failed:   s.failed.Load(),
+		Buffered: len(s.events),
+	}
+}
+
+// Close flushes buffered events and closes the sink
+func (s *EventStream) Close(ctx context.Context) error {
+	s.once.Do(func() { close(s.stop) })
+
+	select {
+	case <-s.done:
+	case <-ctx.Done():
+		logger.Warn("Timed out flushing security events, %d left in buffer", len(s.events))
+	}
+	return s.sink.Close()
+}
+
+// run collects events into batches and delivers them
+func (s *EventStream) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]SecurityEvent, 0, s.batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		s.deliver(batch)
+		batch = make([]SecurityEvent, 0, s.batchSize)
+	}
+
+	for {
+		select {
+		case event := <-s.events:
+			batch = append(batch, event)
+			if len(batch) >= s.batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-s.stop:
+			// Drain what is already buffered
+			for {
+				select {
+				case event := <-s.events:
+					batch = append(batch, event)
+					if len(batch) >= s.batchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// deliver sends a batch, retrying with exponential backoff
+func (s *EventStream) deliver(batch []SecurityEvent) {
+	backoff := time.Second
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		err := s.sink.Send(ctx, batch)
+		cancel()
+		if err == nil {
+			s.sent.Add(int64(len(batch)))
+			return
+		}
+
+		if attempt >= s.maxRetries {
+			s.failed.Add(int64(len(batch)))
+			logger.Error("Failed to export %d security events to %s: %v", len(batch), s.sink.Name(), err)
+			return
+		}
+
+		logger.Warn("Failed to export security events to %s (attempt %d), retrying in %v: %v",
+			s.sink.Name(), attempt+1, backoff, err)
+		select {
+		case <-time.After(backoff):
+		case <-s.stop:
+			// Shutting down, give it one last immediate try
+		}
+		backoff *= 2
+	}
+}
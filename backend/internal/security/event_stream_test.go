@@ -0,0 +1,93 @@
+package security
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSink records delivered batches and can block or fail on demand
+type fakeSink struct {
+	mu      sync.Mutex
+	batches [][]SecurityEvent
+	block   chan struct{}
+	fail    bool
+}
+
+func (s *fakeSink) Name() string { return "fake" }
+
+func (s *fakeSink) Send(_ context.Context, events []SecurityEvent) error {
+	if s.block != nil {
+		<-s.block
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fail {
+		return errors.New("sink unavailable")
+	}
+	s.batches = append(s.batches, append([]SecurityEvent(nil), events...))
+	return nil
+}
+
+func (s *fakeSink) Close() error { return nil }
+
+func TestEventStreamBatchesAndFlushesOnClose(t *testing.T) {
+	sink := &fakeSink{}
+	stream := NewEventStream(sink, 10, 2, time.Hour, 0)
+
+	for i := 0; i < 3; i++ {
+		stream.Emit(SecurityEvent{Type: EventTypeAuthFailure, Severity: SeverityMedium})
+	}
+	require.NoError(t, stream.Close(context.Background()))
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	require.Len(t, sink.batches, 2)
+	assert.Len(t, sink.batches[0], 2)
+	assert.Len(t, sink.batches[1], 1) // the partial batch is flushed on close
+	assert.NotEmpty(t, sink.batches[0][0].ID)
+
+	stats := stream.Stats()
+	assert.Equal(t, int64(3), stats.Emitted)
+	assert.Equal(t, int64(3), stats.Sent)
+	assert.Zero(t, stats.Dropped)
+}
+
+func TestEventStreamDropsWhenBufferFull(t *testing.T) {
+	sink := &fakeSink{block: make(chan struct{})}
+	stream := NewEventStream(sink, 2, 1, time.Hour, 0)
+
+	// The first event is taken by the worker, which then blocks in Send
+	stream.Emit(SecurityEvent{Type: EventTypeRateLimitExceeded})
+	assert.Eventually(t, func() bool { return len(stream.events) == 0 }, time.Second, time.Millisecond)
+
+	for i := 0; i < 5; i++ {
+		stream.Emit(SecurityEvent{Type: EventTypeRateLimitExceeded})
+	}
+	stats := stream.Stats()
+	assert.Equal(t, int64(3), stats.Dropped)
+	assert.Equal(t, 2, stats.Buffered)
+
+	close(sink.block)
+	require.NoError(t, stream.Close(context.Background()))
+	assert.Equal(t, int64(3), stream.Stats().Sent)
+}
+
+func TestEventStreamCountsFailedDeliveries(t *testing.T) {
+	sink := &fakeSink{fail: true}
+	stream := NewEventStream(sink, 10, 5, time.Hour, 0)
+
+	stream.Emit(SecurityEvent{Type: EventTypeAdminAction})
+	require.NoError(t, stream.Close(context.Background()))
+	assert.Equal(t, int64(1), stream.Stats().Failed)
+}
+
+func TestEmitOnNilStream(t *testing.T) {
+	var stream *EventStream
+	assert.NotPanics(t, func() { stream.Emit(SecurityEvent{Type: EventTypeAuthFailure}) })
+}
@@ -0,0 +1,40 @@
+package security
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RFC 6238 test secret "12345678901234567890"
+const rfcTOTPSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCodeMatchesRFC6238(t *testing.T) {
+	code, err := TOTPCode(rfcTOTPSecret, time.Unix(59, 0))
+	require.NoError(t, err)
+	assert.Equal(t, "287082", code)
+
+	code, err = TOTPCode(rfcTOTPSecret, time.Unix(1111111109, 0))
+	require.NoError(t, err)
+	assert.Equal(t, "081804", code)
+}
+
+func TestValidateTOTPAllowsOneStepOfDrift(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+	previous, err := TOTPCode(rfcTOTPSecret, now.Add(-TOTPPeriod))
+	require.NoError(t, err)
+
+	step, ok := ValidateTOTP(rfcTOTPSecret, previous, now)
+	assert.True(t, ok)
+	assert.Equal(t, TOTPStep(now)-1, step)
+
+	stale, err := TOTPCode(rfcTOTPSecret, now.Add(-3*TOTPPeriod))
+	require.NoError(t, err)
+	_, ok = ValidateTOTP(rfcTOTPSecret, stale, now)
+	assert.False(t, ok)
+
+	_, ok = ValidateTOTP(rfcTOTPSecret, "12345", now)
+	assert.False(t, ok)
+}
@@ -0,0 +1,444 @@
+package social
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/logger"
+)
+
+// Activity types
+const (
+	ActivityExamCompleted            = "exam_completed"
+	ActivityLearningSessionCompleted = "learning_session_completed"
+	ActivityPlacementCompleted       = "placement_completed"
+	ActivityStreakMilestone          = "streak_milestone"
+)
+
+// Activity visibility settings
+const (
+	VisibilityPublic    = "public"
+	VisibilityFollowers = "followers"
+	VisibilityPrivate   = "private"
+)
+
+// Real-time events sent over WebSocket
+const (
+	EventNewFollower    = "social.new_follower"
+	EventFriendActivity = "social.friend_activity"
+)
+
+// XP awarded per activity
+const (
+	XPExamCompleted      = 100
+	XPPlacementCompleted = 50
+	xpSessionBase        = 10
+	xpPerCorrectAnswer   = 2
+	xpSessionMax         = 50
+)
+
+// StreakMilestones are the streak lengths, in days, announced to followers
+var StreakMilestones = []int{7, 30, 100, 365}
+
+// scorePayloadKeys are hidden from other users unless the owner shares scores
+var scorePayloadKeys = []string{"score", "estimated_score"}
+
+const (
+	maxFanOut       = 1000 // Followers notified in real time per activity
+	maxStreakLookup = 400  // Activity days read to compute a streak
+)
+
+var (
+	// ErrSelfFollow is returned when a user tries to follow themselves
+	ErrSelfFollow = errors.New("users cannot follow themselves")
+	// ErrUserNotFound is returned when the followed user does not exist
+	ErrUserNotFound = errors.New("user not found")
+	// ErrActivityHidden is returned when a user's activity is not visible to the viewer
+	ErrActivityHidden = errors.New("activity of this user is not visible")
+)
+
+// Notifier delivers real-time events to connected users
+type Notifier interface {
+	SendToUser(userID string, messageType string, data interface{}) error
+}
+
+// Settings are the privacy settings of a user
+type Settings struct {
+	ActivityVisibility string `json:"activity_visibility"`
+	ShareScores        bool   `json:"share_scores"`
+}
+
+// DefaultSettings apply to users who never changed their settings
+var DefaultSettings = Settings{ActivityVisibility: VisibilityFollowers}
+
+// Activity is an activity as shown to a viewer
+type Activity struct {
+	ID        int64                  `json:"id"`
+	UserID    int32                  `json:"user_id"`
+	Username  string                 `json:"username,omitempty"`
+	Type      string                 `json:"type"`
+	Payload   map[string]interface{} `json:"payload"`
+	XP        int32                  `json:"xp"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+// XPEntry is the weekly XP of one user
+type XPEntry struct {
+	Rank     int    `json:"rank"`
+	UserID   int32  `json:"user_id"`
+	Username string `json:"username"`
+	XP       int64  `json:"xp"`
+	IsSelf   bool   `json:"is_self"`
+}
+
+// WeeklyXP compares the XP of a user with the users they follow
+type WeeklyXP struct {
+	WeekStart time.Time `json:"week_start"`
+	Streak    int       `json:"streak"`
+	Entries   []XPEntry `json:"entries"`
+}
+
+// StreakUpdate is the streak of a user after an activity
+type StreakUpdate struct {
+	Days int `json:"days"`
+	// Extended is set when the activity was the first of the day, which adds
+	// the day to the streak or starts a new one
+	Extended bool `json:"extended"`
+	// Milestone is set when the streak reached one of StreakMilestones,
+	// which awards BonusXP
+	Milestone bool  `json:"milestone"`
+	BonusXP   int32 `json:"bonus_xp"`
+}
+
+// Service manages follows, activities and the activity feed
+type Service struct {
+	store    db.Querier
+	notifier Notifier
+}
+
+// NewService creates a new social service. notifier may be nil.
+func NewService(store db.Querier, notifier Notifier) *Service {
+	return &Service{store: store, notifier: notifier}
+}
+
+// LearningSessionXP returns the XP of a completed learning session
+func LearningSessionXP(correctAnswers int32) int32 {
+	return int32(math.Min(float64(xpSessionBase+xpPerCorrectAnswer*correctAnswers), xpSessionMax))
+}
+
+// WeekStart returns the start of the ISO week (Monday 00:00 UTC) containing t
+func WeekStart(t time.Time) time.Time {
+	t = t.UTC()
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, time.UTC)
+}
+
+// CurrentStreak counts consecutive active days ending today, or yesterday when
+// the user was not active yet today. days must be distinct and newest first.
+func CurrentStreak(days []time.Time, now time.Time) int {
+	today := truncateDay(now)
+	if len(days) == 0 {
+		return 0
+	}
+	expected := today
+	if truncateDay(days[0]).Before(today) {
+		expected = today.AddDate(0, 0, -1)
+	}
+
+	streak := 0
+	for _, day := range days {
+		if !truncateDay(day).Equal(expected) {
+			break
+		}
+		streak++
+		expected = expected.AddDate(0, 0, -1)
+	}
+	return streak
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func isMilestone(streak int) bool {
+	for _, milestone := range StreakMilestones {
+		if streak == milestone {
+			return true
+		}
+	}
+	return false
+}
+
+// Follow makes follower follow followee. It reports whether a new follow was created.
+func (s *Service) Follow(ctx context.Context, followerID, followeeID int32) (bool, error) {
+	if followerID == followeeID {
+		return false, ErrSelfFollow
+	}
+	if _, err := s.store.GetUser(ctx, followeeID); errors.Is(err, sql.ErrNoRows) {
+		return false, ErrUserNotFound
+	} else if err != nil {
+		return false, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	created, err := s.store.FollowUser(ctx, db.FollowUserParams{FollowerID: followerID, FolloweeID: followeeID})
+	if err != nil {
+		return false, fmt.Errorf("failed to follow user: %w", err)
+	}
+	if created == 0 {
+		return false, nil
+	}
+
+	if follower, err := s.store.GetUser(ctx, followerID); err == nil {
+		s.notify(followeeID, EventNewFollower, map[string]interface{}{
+			"follower_id": followerID,
+			"username":    follower.Username,
+		})
+	}
+	return true, nil
+}
+
+// Unfollow removes a follow. It reports whether the follow existed.
+func (s *Service) Unfollow(ctx context.Context, followerID, followeeID int32) (bool, error) {
+	removed, err := s.store.UnfollowUser(ctx, db.UnfollowUserParams{FollowerID: followerID, FolloweeID: followeeID})
+	if err != nil {
+		return false, fmt.Errorf("failed to unfollow user: %w", err)
+	}
+	return removed > 0, nil
+}
+
+// Settings returns the privacy settings of a user
+func (s *Service) Settings(ctx context.Context, userID int32) (Settings, error) {
+	settings, err := s.store.GetSocialSettings(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return DefaultSettings, nil
+	}
+	if err != nil {
+		return Settings{}, fmt.Errorf("failed to get social settings: %w", err)
+	}
+	return Settings{ActivityVisibility: settings.ActivityVisibility, ShareScores: settings.ShareScores}, nil
+}
+
+// UpdateSettings stores the privacy settings of a user
+func (s *Service) UpdateSettings(ctx context.Context, userID int32, settings Settings) (Settings, error) {
+	stored, err := s.store.UpsertSocialSettings(ctx, db.UpsertSocialSettingsParams{
+		UserID:             userID,
+		ActivityVisibility: settings.ActivityVisibility,
+		ShareScores:        settings.ShareScores,
+	})
+	if err != nil {
+		return Settings{}, fmt.Errorf("failed to update social settings: %w", err)
+	}
+	return Settings{ActivityVisibility: stored.ActivityVisibility, ShareScores: stored.ShareScores}, nil
+}
+
+// Record stores an activity of a user, announces streak milestones and
+// notifies online followers. It returns the streak of the user afterwards.
+func (s *Service) Record(ctx context.Context, userID int32, activityType string, payload map[string]interface{}, xp int32) (StreakUpdate, error) {
+	now := time.Now()
+	days, err := s.store.ListUserActivityDays(ctx, db.ListUserActivityDaysParams{UserID: userID, Limit: maxStreakLookup})
+	if err != nil {
+		return StreakUpdate{}, fmt.Errorf("failed to list activity days: %w", err)
+	}
+	firstToday := len(days) == 0 || truncateDay(days[0]).Before(truncateDay(now))
+
+	activity, err := s.create(ctx, userID, activityType, payload, xp)
+	if err != nil {
+		return StreakUpdate{}, err
+	}
+	activities := []db.UserActivity{activity}
+
+	// Only the first activity of a day can extend the streak
+	update := StreakUpdate{Days: CurrentStreak(days, now)}
+	if firstToday {
+		update.Days = CurrentStreak(append([]time.Time{now}, days...), now)
+		update.Extended = true
+		if isMilestone(update.Days) {
+			milestone, err := s.create(ctx, userID, ActivityStreakMilestone, map[string]interface{}{"days": update.Days}, int32(update.Days))
+			if err != nil {
+				return update, err
+			}
+			activities = append(activities, milestone)
+			update.Milestone, update.BonusXP = true, int32(update.Days)
+		}
+	}
+
+	s.fanOut(ctx, userID, activities)
+	return update, nil
+}
+
+func (s *Service) create(ctx context.Context, userID int32, activityType string, payload map[string]interface{}, xp int32) (db.UserActivity, error) {
+	if payload == nil {
+		payload = map[string]interface{}{}
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return db.UserActivity{}, fmt.Errorf("failed to encode activity payload: %w", err)
+	}
+	activity, err := s.store.CreateUserActivity(ctx, db.CreateUserActivityParams{
+		UserID:  userID,
+		Type:    activityType,
+		Payload: data,
+		Xp:      xp,
+	})
+	if err != nil {
+		return activity, fmt.Errorf("failed to record activity: %w", err)
+	}
+	return activity, nil
+}
+
+// fanOut pushes new activities to connected followers, respecting the owner's privacy settings
+func (s *Service) fanOut(ctx context.Context, userID int32, activities []db.UserActivity) {
+	if s.notifier == nil {
+		return
+	}
+	settings, err := s.Settings(ctx, userID)
+	if err != nil || settings.ActivityVisibility == VisibilityPrivate {
+		return
+	}
+	followers, err := s.store.ListFollowerIDs(ctx, db.ListFollowerIDsParams{FolloweeID: userID, Limit: maxFanOut})
+	if err != nil {
+		logger.Warn("Failed to list followers of user %d: %v", userID, err)
+		return
+	}
+	username := ""
+	if user, err := s.store.GetUser(ctx, userID); err == nil {
+		username = user.Username
+	}
+
+	for _, activity := range activities {
+		view := toActivity(activity.ID, activity.UserID, username, activity.Type, activity.Payload, activity.Xp, activity.CreatedAt, settings.ShareScores)
+		for _, follower := range followers {
+			s.notify(follower, EventFriendActivity, view)
+		}
+	}
+}
+
+func (s *Service) notify(userID int32, event string, data interface{}) {
+	if s.notifier == nil {
+		return
+	}
+	if err := s.notifier.SendToUser(strconv.Itoa(int(userID)), event, data); err != nil {
+		logger.Debug("Failed to send %s to user %d: %v", event, userID, err)
+	}
+}
+
+// Feed returns the activities of the users followed by userID, newest first.
+// Pass beforeID 0 for the first page.
+func (s *Service) Feed(ctx context.Context, userID int32, beforeID int64, limit int32) ([]Activity, error) {
+	rows, err := s.store.ListFeedActivities(ctx, db.ListFeedActivitiesParams{
+		FollowerID: userID,
+		BeforeID:   cursor(beforeID),
+		MaxItems:   limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feed: %w", err)
+	}
+
+	feed := make([]Activity, 0, len(rows))
+	for _, row := range rows {
+		feed = append(feed, toActivity(row.ID, row.UserID, row.Username, row.Type, row.Payload, row.Xp, row.CreatedAt, row.ShareScores))
+	}
+	return feed, nil
+}
+
+// UserActivities returns the activities of userID as seen by viewerID
+func (s *Service) UserActivities(ctx context.Context, viewerID, userID int32, beforeID int64, limit int32) ([]Activity, error) {
+	settings, err := s.Settings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	self := viewerID == userID
+	if !self {
+		switch settings.ActivityVisibility {
+		case VisibilityPrivate:
+			return nil, ErrActivityHidden
+		case VisibilityFollowers:
+			following, err := s.store.IsFollowing(ctx, db.IsFollowingParams{FollowerID: viewerID, FolloweeID: userID})
+			if err != nil {
+				return nil, fmt.Errorf("failed to check follow: %w", err)
+			}
+			if !following {
+				return nil, ErrActivityHidden
+			}
+		}
+	}
+
+	rows, err := s.store.ListUserActivities(ctx, db.ListUserActivitiesParams{
+		UserID:   userID,
+		BeforeID: cursor(beforeID),
+		MaxItems: limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list activities: %w", err)
+	}
+
+	activities := make([]Activity, 0, len(rows))
+	for _, row := range rows {
+		activities = append(activities, toActivity(row.ID, row.UserID, "", row.Type, row.Payload, row.Xp, row.CreatedAt, self || settings.ShareScores))
+	}
+	return activities, nil
+}
+
+// WeeklyXP ranks the XP earned this week by a user and the users they follow
+func (s *Service) WeeklyXP(ctx context.Context, userID int32, now time.Time) (*WeeklyXP, error) {
+	weekStart := WeekStart(now)
+	rows, err := s.store.ListWeeklyXP(ctx, db.ListWeeklyXPParams{Since: weekStart, UserID: userID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list weekly XP: %w", err)
+	}
+	days, err := s.store.ListUserActivityDays(ctx, db.ListUserActivityDaysParams{UserID: userID, Limit: maxStreakLookup})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list activity days: %w", err)
+	}
+
+	result := &WeeklyXP{WeekStart: weekStart, Streak: CurrentStreak(days, now), Entries: make([]XPEntry, 0, len(rows))}
+	for i, row := range rows {
+		result.Entries = append(result.Entries, XPEntry{
+			Rank:     i + 1,
+			UserID:   row.UserID,
+			Username: row.Username,
+			XP:       row.Xp,
+			IsSelf:   row.UserID == userID,
+		})
+	}
+	return result, nil
+}
+
+// cursor turns a missing keyset cursor into one that matches every row
+func cursor(beforeID int64) int64 {
+	if beforeID <= 0 {
+		return math.MaxInt64
+	}
+	return beforeID
+}
+
+// toActivity builds the view of an activity, hiding scores unless shared
+func toActivity(id int64, userID int32, username, activityType string, data json.RawMessage, xp int32, createdAt time.Time, showScores bool) Activity {
+	payload := map[string]interface{}{}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &payload); err != nil {
+			logger.Warn("Invalid payload of activity %d: %v", id, err)
+		}
+	}
+	if !showScores {
+		for _, key := range scorePayloadKeys {
+			delete(payload, key)
+		}
+	}
+	return Activity{
+		ID:        id,
+		UserID:    userID,
+		Username:  username,
+		Type:      activityType,
+		Payload:   payload,
+		XP:        xp,
+		CreatedAt: createdAt,
+	}
+}
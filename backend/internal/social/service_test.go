@@ -0,0 +1,57 @@
+package social
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func day(s string) time.Time {
+	t, _ := time.Parse("2006-01-02", s)
+	return t
+}
+
+func TestCurrentStreak(t *testing.T) {
+	now := day("2026-03-10").Add(15 * time.Hour)
+
+	assert.Equal(t, 0, CurrentStreak(nil, now))
+	assert.Equal(t, 3, CurrentStreak([]time.Time{day("2026-03-10"), day("2026-03-09"), day("2026-03-08")}, now))
+	// Not active yet today: the streak of yesterday still counts
+	assert.Equal(t, 2, CurrentStreak([]time.Time{day("2026-03-09"), day("2026-03-08"), day("2026-03-06")}, now))
+	// Last activity two days ago: streak is broken
+	assert.Equal(t, 0, CurrentStreak([]time.Time{day("2026-03-08"), day("2026-03-07")}, now))
+}
+
+func TestIsMilestone(t *testing.T) {
+	assert.True(t, isMilestone(7))
+	assert.True(t, isMilestone(30))
+	assert.False(t, isMilestone(8))
+	assert.False(t, isMilestone(0))
+}
+
+func TestWeekStart(t *testing.T) {
+	// 2026-03-11 is a Wednesday
+	assert.Equal(t, day("2026-03-09"), WeekStart(day("2026-03-11").Add(20*time.Hour)))
+	assert.Equal(t, day("2026-03-09"), WeekStart(day("2026-03-09")))
+	// Sunday belongs to the week started on the previous Monday
+	assert.Equal(t, day("2026-03-09"), WeekStart(day("2026-03-15").Add(23*time.Hour)))
+}
+
+func TestLearningSessionXP(t *testing.T) {
+	assert.Equal(t, int32(10), LearningSessionXP(0))
+	assert.Equal(t, int32(30), LearningSessionXP(10))
+	assert.Equal(t, int32(50), LearningSessionXP(40))
+}
+
+func TestToActivityHidesScores(t *testing.T) {
+	payload := json.RawMessage(`{"exam_id": 3, "score": 785}`)
+
+	hidden := toActivity(1, 2, "alice", ActivityExamCompleted, payload, 100, time.Now(), false)
+	assert.NotContains(t, hidden.Payload, "score")
+	assert.Equal(t, float64(3), hidden.Payload["exam_id"])
+
+	shown := toActivity(1, 2, "alice", ActivityExamCompleted, payload, 100, time.Now(), true)
+	assert.Equal(t, float64(785), shown.Payload["score"])
+}
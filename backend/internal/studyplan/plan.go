@@ -0,0 +1,251 @@
+package studyplan
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+// Skills a daily study session is split into
+const (
+	SkillVocabulary = "vocabulary"
+	SkillGrammar    = "grammar"
+	SkillListening  = "listening"
+	SkillReading    = "reading"
+	SkillMockTest   = "mock_test"
+)
+
+// Plan intensity, derived from the score gain needed per week
+const (
+	IntensityMaintain  = "maintain"
+	IntensityLight     = "light"
+	IntensityModerate  = "moderate"
+	IntensityIntensive = "intensive"
+)
+
+// Defaults used when the profile does not set a goal
+const (
+	DefaultDailyMinutes = 30
+	DefaultTargetScore  = 600
+	defaultStudyWeeks   = 12
+	minutesPerWord      = 2
+	maxWordsPerDay      = 50
+)
+
+// Goals are the study goals stored on the user profile
+type Goals struct {
+	TargetScore    int
+	ExamDate       *time.Time
+	DailyMinutes   int
+	NativeLanguage string
+}
+
+// Progress summarizes what the learner has achieved so far
+type Progress struct {
+	CurrentScore  *int // Estimated TOEIC score, nil when the learner has no completed attempts
+	WordsStudied  int
+	MasteredWords int
+}
+
+// SkillAllocation is the daily time spent on a skill
+type SkillAllocation struct {
+	Skill   string `json:"skill"`
+	Minutes int    `json:"minutes"`
+}
+
+// Recommendation is a personalized study tip. Code is stable for clients to localize.
+type Recommendation struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Plan is the generated study plan of a learner
+type Plan struct {
+	TargetScore      int               `json:"target_score"`
+	CurrentScore     *int              `json:"current_score,omitempty"`
+	ScoreGap         int               `json:"score_gap"`
+	ExamDate         *time.Time        `json:"exam_date,omitempty"`
+	DaysUntilExam    int               `json:"days_until_exam"`
+	WeeklyScoreGain  int               `json:"weekly_score_gain"`
+	Intensity        string            `json:"intensity"`
+	DailyMinutes     int               `json:"daily_minutes"`
+	DailyAllocation  []SkillAllocation `json:"daily_allocation"`
+	VocabularyGoal   int               `json:"vocabulary_goal"`
+	WordsPerDay      int               `json:"words_per_day"`
+	MockTestsPerWeek int               `json:"mock_tests_per_week"`
+	Recommendations  []Recommendation  `json:"recommendations"`
+	GeneratedAt      time.Time         `json:"generated_at"`
+}
+
+// articleFreeLanguages have no articles, so their speakers often lose points on
+// Part 5 questions about a/an/the and countable nouns
+var articleFreeLanguages = map[string]bool{
+	"ja": true, "ko": true, "zh": true, "vi": true, "th": true, "ru": true, "id": true,
+}
+
+// vocabularyGoal returns the number of words usually needed for a target score
+func vocabularyGoal(targetScore int) int {
+	switch {
+	case targetScore >= 900:
+		return 7000
+	case targetScore >= 800:
+		return 5500
+	case targetScore >= 700:
+		return 4500
+	case targetScore >= 600:
+		return 3500
+	default:
+		return 2500
+	}
+}
+
+// skillWeights splits study time by level: beginners build vocabulary and
+// grammar first, advanced learners spend more time on timed practice
+func skillWeights(score int) map[string]int {
+	switch {
+	case score < 450:
+		return map[string]int{SkillVocabulary: 35, SkillGrammar: 30, SkillListening: 20, SkillReading: 15}
+	case score < 700:
+		return map[string]int{SkillVocabulary: 25, SkillGrammar: 20, SkillListening: 25, SkillReading: 20, SkillMockTest: 10}
+	default:
+		return map[string]int{SkillVocabulary: 15, SkillGrammar: 10, SkillListening: 25, SkillReading: 25, SkillMockTest: 25}
+	}
+}
+
+// allocate splits minutes by weight in a fixed skill order, giving rounding leftovers to vocabulary
+func allocate(minutes int, weights map[string]int) []SkillAllocation {
+	order := []string{SkillVocabulary, SkillGrammar, SkillListening, SkillReading, SkillMockTest}
+
+	allocations := make([]SkillAllocation, 0, len(order))
+	assigned := 0
+	for _, skill := range order {
+		weight, ok := weights[skill]
+		if !ok {
+			continue
+		}
+		share := minutes * weight / 100
+		assigned += share
+		allocations = append(allocations, SkillAllocation{Skill: skill, Minutes: share})
+	}
+	if len(allocations) > 0 {
+		allocations[0].Minutes += minutes - assigned
+	}
+	return allocations
+}
+
+// Generate builds a study plan from the learner's goals and progress
+func Generate(goals Goals, progress Progress, now time.Time) *Plan {
+	plan := &Plan{
+		TargetScore:  goals.TargetScore,
+		CurrentScore: progress.CurrentScore,
+		ExamDate:     goals.ExamDate,
+		DailyMinutes: goals.DailyMinutes,
+		GeneratedAt:  now,
+	}
+	if plan.TargetScore <= 0 {
+		plan.TargetScore = DefaultTargetScore
+	}
+	if plan.DailyMinutes <= 0 {
+		plan.DailyMinutes = DefaultDailyMinutes
+	}
+
+	level := 0
+	if progress.CurrentScore != nil {
+		level = *progress.CurrentScore
+		plan.ScoreGap = plan.TargetScore - level
+		if plan.ScoreGap < 0 {
+			plan.ScoreGap = 0
+		}
+	}
+
+	studyDays := defaultStudyWeeks * 7
+	examPassed := false
+	if goals.ExamDate != nil {
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		exam := time.Date(goals.ExamDate.Year(), goals.ExamDate.Month(), goals.ExamDate.Day(), 0, 0, 0, 0, time.UTC)
+		plan.DaysUntilExam = int(exam.Sub(today).Hours() / 24)
+		if plan.DaysUntilExam < 0 {
+			examPassed = true
+			plan.DaysUntilExam = 0
+		} else if plan.DaysUntilExam > 0 {
+			studyDays = plan.DaysUntilExam
+		}
+	}
+
+	weeks := math.Max(float64(studyDays)/7, 1)
+	plan.WeeklyScoreGain = int(math.Ceil(float64(plan.ScoreGap) / weeks))
+	switch {
+	case progress.CurrentScore != nil && plan.ScoreGap == 0:
+		plan.Intensity = IntensityMaintain
+	case plan.WeeklyScoreGain > 25:
+		plan.Intensity = IntensityIntensive
+	case plan.WeeklyScoreGain > 10:
+		plan.Intensity = IntensityModerate
+	default:
+		plan.Intensity = IntensityLight
+	}
+
+	plan.DailyAllocation = allocate(plan.DailyMinutes, skillWeights(level))
+
+	plan.VocabularyGoal = vocabularyGoal(plan.TargetScore)
+	if remaining := plan.VocabularyGoal - progress.MasteredWords; remaining > 0 {
+		plan.WordsPerDay = int(math.Ceil(float64(remaining) / float64(studyDays)))
+	}
+	vocabularyMinutes := 0
+	if len(plan.DailyAllocation) > 0 {
+		vocabularyMinutes = plan.DailyAllocation[0].Minutes
+	}
+	if limit := vocabularyMinutes / minutesPerWord; plan.WordsPerDay > limit {
+		plan.WordsPerDay = limit
+	}
+	if plan.WordsPerDay > maxWordsPerDay {
+		plan.WordsPerDay = maxWordsPerDay
+	}
+
+	switch plan.Intensity {
+	case IntensityIntensive:
+		plan.MockTestsPerWeek = 2
+	case IntensityModerate, IntensityMaintain:
+		plan.MockTestsPerWeek = 1
+	}
+
+	plan.Recommendations = recommend(goals, progress, plan, examPassed)
+	return plan
+}
+
+// recommend derives personalized tips from the goals, progress and plan
+func recommend(goals Goals, progress Progress, plan *Plan, examPassed bool) []Recommendation {
+	recommendations := []Recommendation{}
+	add := func(code, message string) {
+		recommendations = append(recommendations, Recommendation{Code: code, Message: message})
+	}
+
+	if progress.CurrentScore == nil {
+		add("take_placement_test", "Take a placement test or a full practice exam so the plan can start from your current level.")
+	}
+	if goals.TargetScore <= 0 {
+		add("set_target_score", "Set a target score to get a plan tailored to your goal.")
+	}
+	switch {
+	case goals.ExamDate == nil:
+		add("set_exam_date", "Set your planned exam date to pace the plan.")
+	case examPassed:
+		add("update_exam_date", "Your planned exam date has passed. Set a new date or update your target score.")
+	case plan.DaysUntilExam <= 14:
+		add("final_review", "Your exam is close. Focus on full timed practice tests and reviewing your mistakes.")
+	}
+	if plan.WeeklyScoreGain > 40 {
+		add("unrealistic_pace", "Your target needs a very fast improvement. Consider increasing daily study time or moving your exam date.")
+	}
+	if plan.DailyMinutes < 20 {
+		add("increase_study_time", "Studying at least 20 minutes a day makes steady progress much more likely.")
+	}
+	if progress.WordsStudied > 0 && progress.MasteredWords*4 < progress.WordsStudied {
+		add("review_vocabulary", "Many studied words are not mastered yet. Review due words before learning new ones.")
+	}
+	language := strings.ToLower(strings.SplitN(goals.NativeLanguage, "-", 2)[0])
+	if articleFreeLanguages[language] {
+		add("practice_articles", "Practice articles and countable nouns, a common source of mistakes in Part 5.")
+	}
+	return recommendations
+}
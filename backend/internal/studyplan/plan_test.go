@@ -0,0 +1,66 @@
+package studyplan
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func recommendationCodes(plan *Plan) []string {
+	codes := make([]string, 0, len(plan.Recommendations))
+	for _, recommendation := range plan.Recommendations {
+		codes = append(codes, recommendation.Code)
+	}
+	return codes
+}
+
+func TestGeneratePacesPlanToExamDate(t *testing.T) {
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	examDate := time.Date(2026, 12, 20, 0, 0, 0, 0, time.UTC)
+	score := 620
+
+	plan := Generate(Goals{TargetScore: 750, ExamDate: &examDate, DailyMinutes: 45, NativeLanguage: "vi-VN"},
+		Progress{CurrentScore: &score}, now)
+
+	assert.Equal(t, 130, plan.ScoreGap)
+	assert.Equal(t, 65, plan.DaysUntilExam)
+	assert.Equal(t, 14, plan.WeeklyScoreGain)
+	assert.Equal(t, IntensityModerate, plan.Intensity)
+	assert.Equal(t, 1, plan.MockTestsPerWeek)
+	assert.Equal(t, 4500, plan.VocabularyGoal)
+	assert.Contains(t, recommendationCodes(plan), "practice_articles")
+
+	total := 0
+	for _, allocation := range plan.DailyAllocation {
+		total += allocation.Minutes
+	}
+	assert.Equal(t, 45, total)
+	// Vocabulary is capped by the time allocated to it
+	assert.Equal(t, plan.DailyAllocation[0].Minutes/minutesPerWord, plan.WordsPerDay)
+}
+
+func TestGenerateWithoutGoalsUsesDefaults(t *testing.T) {
+	plan := Generate(Goals{}, Progress{}, time.Now())
+
+	assert.Equal(t, DefaultTargetScore, plan.TargetScore)
+	assert.Equal(t, DefaultDailyMinutes, plan.DailyMinutes)
+	assert.Nil(t, plan.CurrentScore)
+	assert.Equal(t, IntensityLight, plan.Intensity)
+	assert.Equal(t, SkillVocabulary, plan.DailyAllocation[0].Skill)
+	assert.Subset(t, recommendationCodes(plan), []string{"take_placement_test", "set_target_score", "set_exam_date"})
+}
+
+func TestGenerateFlagsReachedTargetAndPassedExam(t *testing.T) {
+	now := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	examDate := now.AddDate(0, 0, -3)
+	score := 820
+
+	plan := Generate(Goals{TargetScore: 800, ExamDate: &examDate, DailyMinutes: 60},
+		Progress{CurrentScore: &score, WordsStudied: 400, MasteredWords: 50}, now)
+
+	assert.Equal(t, 0, plan.ScoreGap)
+	assert.Equal(t, 0, plan.DaysUntilExam)
+	assert.Equal(t, IntensityMaintain, plan.Intensity)
+	assert.Equal(t, []string{"update_exam_date", "review_vocabulary"}, recommendationCodes(plan))
+}
@@ -0,0 +1,103 @@
+package studyplan
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/logger"
+)
+
+// recentAttempts is the number of completed exam attempts averaged into the current score
+const recentAttempts = 3
+
+// Service generates study plans from stored profiles and progress
+type Service struct {
+	store db.Querier
+}
+
+// NewService creates a new study plan service
+func NewService(store db.Querier) *Service {
+	return &Service{store: store}
+}
+
+// GoalsFromProfile reads the study goals of a profile
+func GoalsFromProfile(profile db.UserProfile) Goals {
+	goals := Goals{
+		TargetScore:    int(profile.TargetScore.Int32),
+		DailyMinutes:   int(profile.DailyMinutes.Int32),
+		NativeLanguage: profile.NativeLanguage.String,
+	}
+	if profile.ExamDate.Valid {
+		examDate := profile.ExamDate.Time
+		goals.ExamDate = &examDate
+	}
+	return goals
+}
+
+// CurrentScore estimates the TOEIC score of a user from the most recent completed exam attempts
+func (s *Service) CurrentScore(ctx context.Context, userID int32) (*int, error) {
+	attempts, err := s.store.ListExamAttemptsByUser(ctx, db.ListExamAttemptsByUserParams{UserID: userID, Limit: 20})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list exam attempts: %w", err)
+	}
+
+	total, count := 0.0, 0
+	for _, attempt := range attempts {
+		if attempt.Status != db.ExamStatusEnumCompleted || !attempt.Score.Valid {
+			continue
+		}
+		score, err := strconv.ParseFloat(attempt.Score.String, 64)
+		if err != nil {
+			continue
+		}
+		total += score
+		count++
+		if count == recentAttempts {
+			break
+		}
+	}
+	if count == 0 {
+		return nil, nil
+	}
+	score := int(math.Round(total / float64(count)))
+	return &score, nil
+}
+
+// Generate builds the study plan of a user. Users without a profile get a plan from default goals.
+func (s *Service) Generate(ctx context.Context, userID int32) (*Plan, error) {
+	var goals Goals
+	profile, err := s.store.GetUserProfileByUserID(ctx, sql.NullInt32{Int32: userID, Valid: true})
+	switch {
+	case err == nil:
+		goals = GoalsFromProfile(profile)
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, fmt.Errorf("failed to get user profile: %w", err)
+	}
+
+	var progress Progress
+	progress.CurrentScore, err = s.CurrentScore(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	// Until the first completed exam, start from the placement test result
+	if progress.CurrentScore == nil && profile.PlacementScore.Valid {
+		score := int(profile.PlacementScore.Int32)
+		progress.CurrentScore = &score
+	}
+
+	// Vocabulary progress only refines the plan, so a failure here does not block it
+	if learning, err := s.store.GetUserLearningProgress(ctx, userID); err == nil {
+		progress.WordsStudied = int(learning.TotalWordsStudied)
+		progress.MasteredWords = int(learning.MasteredWords)
+	} else {
+		logger.Debug("No vocabulary progress for study plan of user %d: %v", userID, err)
+	}
+
+	return Generate(goals, progress, time.Now()), nil
+}
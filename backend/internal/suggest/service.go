@@ -0,0 +1,169 @@
+// Package suggest completes what a learner is typing into the search box
+// with words and grammar titles. Prefix matches come first, then fuzzy
+// trigram matches that forgive typos.
+package suggest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/toeic-app/internal/cache"
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/logger"
+)
+
+const (
+	DefaultLimit = 5
+	MaxLimit     = 20
+	// MaxQueryLength bounds the text matched against the indexes
+	MaxQueryLength = 64
+	// minFuzzyLength skips trigram matching for queries too short to have
+	// meaningful trigrams
+	minFuzzyLength = 3
+	// slowThreshold is the latency above which suggestions are logged
+	slowThreshold = 50 * time.Millisecond
+)
+
+// Suggestion types and how they matched
+const (
+	TypeWord    = "word"
+	TypeGrammar = "grammar"
+
+	MatchExact  = "exact"
+	MatchPrefix = "prefix"
+	MatchFuzzy  = "fuzzy"
+)
+
+// Suggestion completes a query
+type Suggestion struct {
+	Type   string  `json:"type"`
+	ID     int32   `json:"id"`
+	Text   string  `json:"text"`
+	Detail string  `json:"detail,omitempty"`
+	Match  string  `json:"match"`
+	Score  float32 `json:"score"`
+}
+
+// Result holds the suggestions for a query, best first within each type
+type Result struct {
+	Query    string       `json:"query"`
+	Words    []Suggestion `json:"words"`
+	Grammars []Suggestion `json:"grammars"`
+	Cached   bool         `json:"cached"`
+}
+
+// Service looks suggestions up, with a cache in front of the database
+type Service struct {
+	store db.Querier
+	cache cache.Cache
+	ttl   time.Duration
+}
+
+// NewService creates a suggestion service. The cache is optional.
+func NewService(store db.Querier, resultCache cache.Cache, ttl time.Duration) *Service {
+	return &Service{store: store, cache: resultCache, ttl: ttl}
+}
+
+// Normalize lowercases a query, collapses its spaces and cuts it to
+// MaxQueryLength characters
+func Normalize(query string) string {
+	query = strings.ToLower(strings.Join(strings.Fields(query), " "))
+	if utf8.RuneCountInString(query) > MaxQueryLength {
+		query = string([]rune(query)[:MaxQueryLength])
+	}
+	return query
+}
+
+// likePrefix escapes the LIKE wildcards of a query and appends %
+func likePrefix(query string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(query) + "%"
+}
+
+func match(exact, prefix bool) string {
+	switch {
+	case exact:
+		return MatchExact
+	case prefix:
+		return MatchPrefix
+	}
+	return MatchFuzzy
+}
+
+// Suggest returns up to limit words and limit grammar titles completing
+// query. An empty query has no suggestions.
+func (s *Service) Suggest(ctx context.Context, query string, limit int) (Result, error) {
+	query = Normalize(query)
+	limit = max(1, min(limit, MaxLimit))
+	result := Result{Query: query, Words: []Suggestion{}, Grammars: []Suggestion{}}
+	if query == "" {
+		return result, nil
+	}
+
+	key := cacheKey(query, limit)
+	if s.cache != nil && s.ttl > 0 {
+		if data, err := s.cache.Get(ctx, key); err == nil {
+			var cached Result
+			if err := json.Unmarshal(data, &cached); err == nil {
+				cached.Cached = true
+				return cached, nil
+			}
+		}
+	}
+
+	started := time.Now()
+	arg := db.SuggestWordsParams{
+		Query:      query,
+		Prefix:     likePrefix(query),
+		Fuzzy:      utf8.RuneCountInString(query) >= minFuzzyLength,
+		MaxResults: int32(limit),
+	}
+	words, err := s.store.SuggestWords(ctx, arg)
+	if err != nil {
+		return result, fmt.Errorf("failed to suggest words: %w", err)
+	}
+	grammars, err := s.store.SuggestGrammars(ctx, db.SuggestGrammarsParams(arg))
+	if err != nil {
+		return result, fmt.Errorf("failed to suggest grammars: %w", err)
+	}
+	if elapsed := time.Since(started); elapsed > slowThreshold {
+		logger.Warn("Slow search suggestions for %q: %v", query, elapsed)
+	}
+
+	for _, word := range words {
+		result.Words = append(result.Words, Suggestion{
+			Type:   TypeWord,
+			ID:     word.ID,
+			Text:   word.Word,
+			Detail: word.ShortMean,
+			Match:  match(word.Exact, word.IsPrefix),
+			Score:  word.Score,
+		})
+	}
+	for _, grammar := range grammars {
+		result.Grammars = append(result.Grammars, Suggestion{
+			Type:   TypeGrammar,
+			ID:     grammar.ID,
+			Text:   grammar.Title,
+			Detail: grammar.GrammarKey,
+			Match:  match(grammar.Exact, grammar.IsPrefix),
+			Score:  grammar.Score,
+		})
+	}
+
+	if s.cache != nil && s.ttl > 0 {
+		if data, err := json.Marshal(result); err == nil {
+			if err := s.cache.Set(ctx, key, data, s.ttl); err != nil {
+				logger.Debug("Failed to cache suggestions for %q: %v", query, err)
+			}
+		}
+	}
+	return result, nil
+}
+
+func cacheKey(query string, limit int) string {
+	return fmt.Sprintf("suggest:%d:%s", limit, query)
+}
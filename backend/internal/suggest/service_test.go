@@ -0,0 +1,88 @@
+package suggest
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toeic-app/internal/cache"
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+// suggestStore matches words by prefix only and counts lookups; other
+// Querier methods are not used
+type suggestStore struct {
+	db.Querier
+	words   []string
+	lookups int
+	last    db.SuggestWordsParams
+}
+
+func (s *suggestStore) SuggestWords(ctx context.Context, arg db.SuggestWordsParams) ([]db.SuggestWordsRow, error) {
+	s.lookups++
+	s.last = arg
+	var rows []db.SuggestWordsRow
+	for i, word := range s.words {
+		if strings.HasPrefix(word, arg.Query) && len(rows) < int(arg.MaxResults) {
+			rows = append(rows, db.SuggestWordsRow{ID: int32(i + 1), Word: word, Exact: word == arg.Query, IsPrefix: true})
+		}
+	}
+	return rows, nil
+}
+
+func (s *suggestStore) SuggestGrammars(ctx context.Context, arg db.SuggestGrammarsParams) ([]db.SuggestGrammarsRow, error) {
+	return []db.SuggestGrammarsRow{{ID: 1, Title: "Invitation phrases", Score: 0.4}}, nil
+}
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, "make up", Normalize("  Make   UP "))
+	assert.Equal(t, MaxQueryLength, len([]rune(Normalize(strings.Repeat("ệ", 100)))))
+}
+
+func TestLikePrefixEscapesWildcards(t *testing.T) {
+	assert.Equal(t, `50\%\_off\\%`, likePrefix(`50%_off\`))
+}
+
+func TestSuggest(t *testing.T) {
+	store := &suggestStore{words: []string{"invest", "invoice", "in"}}
+	service := NewService(store, nil, 0)
+
+	result, err := service.Suggest(context.Background(), " IN ", 10)
+	require.NoError(t, err)
+	assert.Equal(t, "in", result.Query)
+	require.Len(t, result.Words, 3)
+	assert.Equal(t, MatchPrefix, result.Words[0].Match)
+	assert.Equal(t, MatchExact, result.Words[2].Match)
+	assert.False(t, store.last.Fuzzy, "two letters are too short for trigrams")
+
+	_, err = service.Suggest(context.Background(), "invo", 100)
+	require.NoError(t, err)
+	assert.True(t, store.last.Fuzzy)
+	assert.Equal(t, int32(MaxLimit), store.last.MaxResults)
+	assert.Equal(t, MatchFuzzy, result.Grammars[0].Match)
+
+	empty, err := service.Suggest(context.Background(), "   ", 5)
+	require.NoError(t, err)
+	assert.Empty(t, empty.Words)
+	assert.Equal(t, 2, store.lookups, "empty queries are not looked up")
+}
+
+func TestSuggestCachesResults(t *testing.T) {
+	store := &suggestStore{words: []string{"invoice"}}
+	memory := cache.NewMemoryCache(cache.CacheConfig{MaxEntries: 100, DefaultTTL: time.Minute, CleanupInterval: time.Minute})
+	defer memory.Close()
+	service := NewService(store, memory, time.Minute)
+
+	first, err := service.Suggest(context.Background(), "inv", 5)
+	require.NoError(t, err)
+	assert.False(t, first.Cached)
+
+	second, err := service.Suggest(context.Background(), "INV", 5)
+	require.NoError(t, err)
+	assert.True(t, second.Cached)
+	assert.Equal(t, first.Words, second.Words)
+	assert.Equal(t, 1, store.lookups)
+}
@@ -0,0 +1,169 @@
+// Package transcript stores the transcripts of listening questions and
+// decides when learners may read them. Exams offer transcripts only when
+// they are enabled for the exam. In part practice a transcript is revealed
+// once the question is answered, after which the answer cannot change; in
+// a full test, once the attempt is completed, so transcripts cannot help
+// with the answers.
+package transcript
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+var (
+	ErrQuestionNotFound = errors.New("question not found")
+	ErrNotListening     = errors.New("question has no audio to transcribe")
+	ErrEmpty            = errors.New("transcript is empty")
+	ErrNoTranscript     = errors.New("question has no transcript")
+	ErrDisabled         = errors.New("exam does not offer transcripts")
+	ErrNotAnswered      = errors.New("transcript is revealed once the question is answered")
+	ErrNotCompleted     = errors.New("transcripts are revealed once the attempt is completed")
+)
+
+// Modes transcripts are revealed in
+const (
+	ModePractice = "practice" // Part practice: once the question is answered
+	ModeTest     = "test"     // Full test: once the attempt is completed
+)
+
+// Transcript is the transcript of a question's audio
+type Transcript struct {
+	QuestionID int32     `json:"question_id" example:"12"`
+	Transcript string    `json:"transcript" example:"Woman: Has the shipment arrived yet? Man: It's due this afternoon."`
+	UpdatedAt  time.Time `json:"updated_at"`
+	// Mode is the mode of the attempt it was revealed in; empty for editors
+	Mode string `json:"mode,omitempty" example:"practice"`
+}
+
+// Service stores and reveals transcripts
+type Service struct {
+	store db.Querier
+}
+
+// NewService creates a transcript service
+func NewService(store db.Querier) *Service {
+	return &Service{store: store}
+}
+
+// Mode returns the mode an attempt reveals transcripts in
+func Mode(attempt db.ExamAttempt) string {
+	if attempt.PartID.Valid {
+		return ModePractice
+	}
+	return ModeTest
+}
+
+// Get returns the transcript of a question, for editors
+func (s *Service) Get(ctx context.Context, questionID int32) (*Transcript, error) {
+	row, err := s.store.GetQuestionTranscript(ctx, questionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNoTranscript
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transcript: %w", err)
+	}
+	return &Transcript{QuestionID: row.QuestionID, Transcript: row.Transcript, UpdatedAt: row.UpdatedAt}, nil
+}
+
+// Set stores the transcript of a listening question, replacing any it had
+func (s *Service) Set(ctx context.Context, questionID, editorID int32, text string) (*Transcript, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, ErrEmpty
+	}
+	question, err := s.store.GetQuestion(ctx, questionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrQuestionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get question: %w", err)
+	}
+	if !question.MediaUrl.Valid || strings.TrimSpace(question.MediaUrl.String) == "" {
+		return nil, ErrNotListening
+	}
+
+	row, err := s.store.UpsertQuestionTranscript(ctx, db.UpsertQuestionTranscriptParams{
+		QuestionID: questionID,
+		Transcript: text,
+		UpdatedBy:  sql.NullInt32{Int32: editorID, Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store transcript: %w", err)
+	}
+	return &Transcript{QuestionID: row.QuestionID, Transcript: row.Transcript, UpdatedAt: row.UpdatedAt}, nil
+}
+
+// Delete removes the transcript of a question
+func (s *Service) Delete(ctx context.Context, questionID int32) error {
+	removed, err := s.store.DeleteQuestionTranscript(ctx, questionID)
+	if err != nil {
+		return fmt.Errorf("failed to remove transcript: %w", err)
+	}
+	if removed == 0 {
+		return ErrNoTranscript
+	}
+	return nil
+}
+
+// Reveal returns the transcript of a question in an attempt of the
+// learner, once its mode allows: in part practice when the question is
+// answered or the attempt is completed, in a full test when the attempt
+// is completed. Revealing in an open practice records the reveal, which
+// keeps the answer from changing.
+func (s *Service) Reveal(ctx context.Context, attempt db.ExamAttempt, questionID int32) (*Transcript, error) {
+	row, err := s.store.GetAttemptTranscript(ctx, db.GetAttemptTranscriptParams{QuestionID: questionID, ExamID: attempt.ExamID})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrQuestionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transcript: %w", err)
+	}
+	if attempt.PartID.Valid && row.PartID != attempt.PartID.Int32 {
+		return nil, ErrQuestionNotFound
+	}
+	if !row.TranscriptsEnabled {
+		return nil, ErrDisabled
+	}
+	if !row.Transcript.Valid {
+		return nil, ErrNoTranscript
+	}
+
+	mode := Mode(attempt)
+	if attempt.Status != db.ExamStatusEnumCompleted {
+		if mode == ModeTest {
+			return nil, ErrNotCompleted
+		}
+		_, err := s.store.GetUserAnswerByAttemptAndQuestion(ctx, db.GetUserAnswerByAttemptAndQuestionParams{
+			AttemptID:  attempt.AttemptID,
+			QuestionID: questionID,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotAnswered
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to check answer: %w", err)
+		}
+		// The answer is final once its transcript was read
+		err = s.store.RecordTranscriptReveal(ctx, db.RecordTranscriptRevealParams{
+			AttemptID:  attempt.AttemptID,
+			QuestionID: questionID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to record reveal: %w", err)
+		}
+	}
+
+	return &Transcript{
+		QuestionID: row.QuestionID,
+		Transcript: row.Transcript.String,
+		UpdatedAt:  row.UpdatedAt.Time,
+		Mode:       mode,
+	}, nil
+}
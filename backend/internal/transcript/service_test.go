@@ -0,0 +1,165 @@
+package transcript
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+// fakeStore keeps transcripts in memory. Exam 1 offers transcripts and
+// exam 2 does not; question 10 of part 5 and question 11 of part 6 have
+// audio, question 12 has none.
+type fakeStore struct {
+	db.Querier
+	transcripts map[int32]db.QuestionTranscript
+	answered    map[[2]int32]bool // Attempt and question
+	revealed    map[[2]int32]bool
+}
+
+func (f *fakeStore) GetQuestion(_ context.Context, questionID int32) (db.Question, error) {
+	switch questionID {
+	case 10, 11:
+		return db.Question{QuestionID: questionID, MediaUrl: sql.NullString{String: "https://media.test/q.mp3", Valid: true}}, nil
+	case 12:
+		return db.Question{QuestionID: questionID}, nil
+	}
+	return db.Question{}, sql.ErrNoRows
+}
+
+func (f *fakeStore) UpsertQuestionTranscript(_ context.Context, arg db.UpsertQuestionTranscriptParams) (db.QuestionTranscript, error) {
+	row := db.QuestionTranscript{
+		QuestionID: arg.QuestionID,
+		Transcript: arg.Transcript,
+		UpdatedBy:  arg.UpdatedBy,
+		UpdatedAt:  time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC),
+	}
+	f.transcripts[arg.QuestionID] = row
+	return row, nil
+}
+
+func (f *fakeStore) GetQuestionTranscript(_ context.Context, questionID int32) (db.QuestionTranscript, error) {
+	row, ok := f.transcripts[questionID]
+	if !ok {
+		return db.QuestionTranscript{}, sql.ErrNoRows
+	}
+	return row, nil
+}
+
+func (f *fakeStore) DeleteQuestionTranscript(_ context.Context, questionID int32) (int64, error) {
+	if _, ok := f.transcripts[questionID]; !ok {
+		return 0, nil
+	}
+	delete(f.transcripts, questionID)
+	return 1, nil
+}
+
+func (f *fakeStore) GetAttemptTranscript(_ context.Context, arg db.GetAttemptTranscriptParams) (db.GetAttemptTranscriptRow, error) {
+	parts := map[int32]int32{10: 5, 11: 6, 12: 5}
+	partID, ok := parts[arg.QuestionID]
+	if !ok || (arg.ExamID != 1 && arg.ExamID != 2) {
+		return db.GetAttemptTranscriptRow{}, sql.ErrNoRows
+	}
+	row := db.GetAttemptTranscriptRow{QuestionID: arg.QuestionID, PartID: partID, TranscriptsEnabled: arg.ExamID == 1}
+	if transcript, ok := f.transcripts[arg.QuestionID]; ok {
+		row.Transcript = sql.NullString{String: transcript.Transcript, Valid: true}
+		row.UpdatedAt = sql.NullTime{Time: transcript.UpdatedAt, Valid: true}
+	}
+	return row, nil
+}
+
+func (f *fakeStore) GetUserAnswerByAttemptAndQuestion(_ context.Context, arg db.GetUserAnswerByAttemptAndQuestionParams) (db.UserAnswer, error) {
+	if !f.answered[[2]int32{arg.AttemptID, arg.QuestionID}] {
+		return db.UserAnswer{}, sql.ErrNoRows
+	}
+	return db.UserAnswer{AttemptID: arg.AttemptID, QuestionID: arg.QuestionID}, nil
+}
+
+func (f *fakeStore) RecordTranscriptReveal(_ context.Context, arg db.RecordTranscriptRevealParams) error {
+	f.revealed[[2]int32{arg.AttemptID, arg.QuestionID}] = true
+	return nil
+}
+
+func newTestService(t *testing.T) (*Service, *fakeStore) {
+	t.Helper()
+	store := &fakeStore{transcripts: map[int32]db.QuestionTranscript{}, answered: map[[2]int32]bool{}, revealed: map[[2]int32]bool{}}
+	service := NewService(store)
+	for _, questionID := range []int32{10, 11} {
+		_, err := service.Set(context.Background(), questionID, 1, " Woman: Has the shipment arrived? ")
+		require.NoError(t, err)
+	}
+	return service, store
+}
+
+func TestSet(t *testing.T) {
+	service, store := newTestService(t)
+
+	assert.Equal(t, "Woman: Has the shipment arrived?", store.transcripts[10].Transcript)
+	assert.Equal(t, sql.NullInt32{Int32: 1, Valid: true}, store.transcripts[10].UpdatedBy)
+
+	_, err := service.Set(context.Background(), 10, 1, "  ")
+	assert.ErrorIs(t, err, ErrEmpty)
+	_, err = service.Set(context.Background(), 12, 1, "No audio")
+	assert.ErrorIs(t, err, ErrNotListening)
+	_, err = service.Set(context.Background(), 99, 1, "Missing")
+	assert.ErrorIs(t, err, ErrQuestionNotFound)
+
+	require.NoError(t, service.Delete(context.Background(), 11))
+	assert.ErrorIs(t, service.Delete(context.Background(), 11), ErrNoTranscript)
+	_, err = service.Get(context.Background(), 11)
+	assert.ErrorIs(t, err, ErrNoTranscript)
+}
+
+func TestRevealInPractice(t *testing.T) {
+	service, store := newTestService(t)
+	practice := db.ExamAttempt{AttemptID: 3, ExamID: 1, PartID: sql.NullInt32{Int32: 5, Valid: true}, Status: db.ExamStatusEnumInProgress}
+
+	_, err := service.Reveal(context.Background(), practice, 10)
+	assert.ErrorIs(t, err, ErrNotAnswered)
+
+	store.answered[[2]int32{3, 10}] = true
+	transcript, err := service.Reveal(context.Background(), practice, 10)
+	require.NoError(t, err)
+	assert.Equal(t, "Woman: Has the shipment arrived?", transcript.Transcript)
+	assert.Equal(t, ModePractice, transcript.Mode)
+	// The answer cannot change from now on
+	assert.True(t, store.revealed[[2]int32{3, 10}])
+
+	// Questions of other parts are not in the practice
+	_, err = service.Reveal(context.Background(), practice, 11)
+	assert.ErrorIs(t, err, ErrQuestionNotFound)
+	_, err = service.Reveal(context.Background(), practice, 12)
+	assert.ErrorIs(t, err, ErrNoTranscript)
+}
+
+func TestRevealInTest(t *testing.T) {
+	service, store := newTestService(t)
+	test := db.ExamAttempt{AttemptID: 4, ExamID: 1, Status: db.ExamStatusEnumInProgress}
+	store.answered[[2]int32{4, 10}] = true
+
+	// Answering is not enough in a full test
+	_, err := service.Reveal(context.Background(), test, 10)
+	assert.ErrorIs(t, err, ErrNotCompleted)
+
+	test.Status = db.ExamStatusEnumAbandoned
+	_, err = service.Reveal(context.Background(), test, 11)
+	assert.ErrorIs(t, err, ErrNotCompleted)
+
+	test.Status = db.ExamStatusEnumCompleted
+	transcript, err := service.Reveal(context.Background(), test, 11)
+	require.NoError(t, err)
+	assert.Equal(t, ModeTest, transcript.Mode)
+	assert.Empty(t, store.revealed)
+
+	// The exam must offer transcripts
+	test.ExamID = 2
+	_, err = service.Reveal(context.Background(), test, 11)
+	assert.ErrorIs(t, err, ErrDisabled)
+	test.ExamID = 3
+	_, err = service.Reveal(context.Background(), test, 11)
+	assert.ErrorIs(t, err, ErrQuestionNotFound)
+}
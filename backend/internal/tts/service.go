@@ -0,0 +1,194 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/logger"
+)
+
+var (
+	ErrEmptyText    = errors.New("text is required")
+	ErrTextTooLong  = errors.New("text is too long")
+	ErrInvalidVoice = errors.New("unsupported voice")
+)
+
+// Storage keeps synthesized audio private and hands out signed URLs
+type Storage interface {
+	UploadPrivateAudio(ctx context.Context, file interface{}, publicID string) error
+	SignedAudioURL(publicID string) (string, error)
+}
+
+// Clip is synthesized speech available at a signed URL
+type Clip struct {
+	URL    string `json:"url"`
+	Voice  string `json:"voice"`
+	Hash   string `json:"hash"`
+	Cached bool   `json:"cached"`
+}
+
+// Service synthesizes short phrases once and serves them from media storage
+type Service struct {
+	store        db.Querier
+	synthesizer  Synthesizer
+	storage      Storage
+	defaultVoice string
+	maxLength    int
+
+	mu       sync.Mutex
+	inflight map[string]*inflightClip
+}
+
+// inflightClip lets concurrent requests for the same phrase share one synthesis
+type inflightClip struct {
+	done chan struct{}
+	clip *Clip
+	err  error
+}
+
+// NewService creates a new text-to-speech service. maxLength is in characters.
+func NewService(store db.Querier, synthesizer Synthesizer, storage Storage, defaultVoice string, maxLength int) *Service {
+	return &Service{
+		store:        store,
+		synthesizer:  synthesizer,
+		storage:      storage,
+		defaultVoice: defaultVoice,
+		maxLength:    maxLength,
+		inflight:     make(map[string]*inflightClip),
+	}
+}
+
+// Voices returns the supported voices
+func (s *Service) Voices() []string {
+	return s.synthesizer.Voices()
+}
+
+// Speak returns a signed URL of the text spoken in the voice, synthesizing and
+// storing it on first use. An empty voice selects the default.
+func (s *Service) Speak(ctx context.Context, text, voice string) (*Clip, error) {
+	text = normalizeText(text)
+	if text == "" {
+		return nil, ErrEmptyText
+	}
+	if utf8.RuneCountInString(text) > s.maxLength {
+		return nil, ErrTextTooLong
+	}
+	if voice == "" {
+		voice = s.defaultVoice
+	}
+	if !s.validVoice(voice) {
+		return nil, fmt.Errorf("%w %q, use one of: %s", ErrInvalidVoice, voice, strings.Join(s.synthesizer.Voices(), ", "))
+	}
+
+	hash := clipHash(s.synthesizer.Name(), voice, text)
+
+	clip, err := s.cachedClip(ctx, hash, voice)
+	if err != nil || clip != nil {
+		return clip, err
+	}
+
+	s.mu.Lock()
+	if call, ok := s.inflight[hash]; ok {
+		s.mu.Unlock()
+		select {
+		case <-call.done:
+			return call.clip, call.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	call := &inflightClip{done: make(chan struct{})}
+	s.inflight[hash] = call
+	s.mu.Unlock()
+
+	// Synthesis is detached from the request so waiting callers are not
+	// failed by the first caller going away
+	call.clip, call.err = s.synthesize(context.WithoutCancel(ctx), hash, voice, text)
+
+	s.mu.Lock()
+	delete(s.inflight, hash)
+	s.mu.Unlock()
+	close(call.done)
+
+	return call.clip, call.err
+}
+
+// cachedClip returns the stored clip for a hash, or nil when there is none
+func (s *Service) cachedClip(ctx context.Context, hash, voice string) (*Clip, error) {
+	record, err := s.store.GetTTSClip(ctx, hash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get clip: %w", err)
+	}
+
+	url, err := s.storage.SignedAudioURL(record.PublicID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign clip URL: %w", err)
+	}
+	if err := s.store.TouchTTSClip(ctx, hash); err != nil {
+		logger.Debug("Failed to record use of TTS clip %s: %v", hash, err)
+	}
+
+	return &Clip{URL: url, Voice: voice, Hash: hash, Cached: true}, nil
+}
+
+func (s *Service) synthesize(ctx context.Context, hash, voice, text string) (*Clip, error) {
+	audio, err := s.synthesizer.Synthesize(ctx, text, voice)
+	if err != nil {
+		return nil, fmt.Errorf("failed to synthesize speech: %w", err)
+	}
+
+	publicID := "tts/" + hash
+	if err := s.storage.UploadPrivateAudio(ctx, bytes.NewReader(audio), publicID); err != nil {
+		return nil, fmt.Errorf("failed to store speech: %w", err)
+	}
+
+	if err := s.store.CreateTTSClip(ctx, db.CreateTTSClipParams{
+		Hash:      hash,
+		Voice:     voice,
+		Text:      text,
+		PublicID:  publicID,
+		SizeBytes: int32(len(audio)),
+	}); err != nil {
+		return nil, fmt.Errorf("failed to record clip: %w", err)
+	}
+
+	url, err := s.storage.SignedAudioURL(publicID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign clip URL: %w", err)
+	}
+
+	logger.Debug("Synthesized TTS clip %s (%d bytes)", hash, len(audio))
+	return &Clip{URL: url, Voice: voice, Hash: hash}, nil
+}
+
+func (s *Service) validVoice(voice string) bool {
+	for _, v := range s.synthesizer.Voices() {
+		if v == voice {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeText collapses whitespace so trivially different requests share a clip
+func normalizeText(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// clipHash identifies a clip by provider, voice and normalized text
+func clipHash(provider, voice, text string) string {
+	sum := sha256.Sum256([]byte(provider + "\x00" + voice + "\x00" + text))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,135 @@
+package tts
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+// clipStore keeps clips in memory; other Querier methods are not used
+type clipStore struct {
+	db.Querier
+	mu    sync.Mutex
+	clips map[string]db.TtsClip
+}
+
+func (s *clipStore) GetTTSClip(ctx context.Context, hash string) (db.TtsClip, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clip, ok := s.clips[hash]
+	if !ok {
+		return db.TtsClip{}, sql.ErrNoRows
+	}
+	return clip, nil
+}
+
+func (s *clipStore) CreateTTSClip(ctx context.Context, arg db.CreateTTSClipParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clips[arg.Hash] = db.TtsClip{Hash: arg.Hash, Voice: arg.Voice, Text: arg.Text, PublicID: arg.PublicID}
+	return nil
+}
+
+func (s *clipStore) TouchTTSClip(ctx context.Context, hash string) error {
+	return nil
+}
+
+type fakeSynthesizer struct {
+	calls int32
+	delay time.Duration
+}
+
+func (f *fakeSynthesizer) Name() string     { return "fake" }
+func (f *fakeSynthesizer) Voices() []string { return []string{"alloy", "nova"} }
+
+func (f *fakeSynthesizer) Synthesize(ctx context.Context, text, voice string) ([]byte, error) {
+	atomic.AddInt32(&f.calls, 1)
+	time.Sleep(f.delay)
+	return []byte("mp3:" + voice + ":" + text), nil
+}
+
+type fakeStorage struct {
+	mu       sync.Mutex
+	uploaded []string
+}
+
+func (f *fakeStorage) UploadPrivateAudio(ctx context.Context, file interface{}, publicID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.uploaded = append(f.uploaded, publicID)
+	return nil
+}
+
+func (f *fakeStorage) SignedAudioURL(publicID string) (string, error) {
+	return "https://media.example.com/s--sig--/" + publicID, nil
+}
+
+func newTestService(delay time.Duration) (*Service, *fakeSynthesizer, *fakeStorage) {
+	synthesizer := &fakeSynthesizer{delay: delay}
+	storage := &fakeStorage{}
+	store := &clipStore{clips: make(map[string]db.TtsClip)}
+	return NewService(store, synthesizer, storage, "alloy", 20), synthesizer, storage
+}
+
+func TestSpeakCachesByText(t *testing.T) {
+	service, synthesizer, storage := newTestService(0)
+
+	first, err := service.Speak(context.Background(), "Good  morning", "")
+	require.NoError(t, err)
+	assert.False(t, first.Cached)
+	assert.Equal(t, "alloy", first.Voice)
+	assert.True(t, strings.HasSuffix(first.URL, "tts/"+first.Hash))
+
+	second, err := service.Speak(context.Background(), " Good morning ", "alloy")
+	require.NoError(t, err)
+	assert.True(t, second.Cached)
+	assert.Equal(t, first.URL, second.URL)
+
+	other, err := service.Speak(context.Background(), "Good morning", "nova")
+	require.NoError(t, err)
+	assert.NotEqual(t, first.Hash, other.Hash, "each voice has its own clip")
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&synthesizer.calls))
+	assert.Len(t, storage.uploaded, 2)
+}
+
+func TestSpeakSharesConcurrentSynthesis(t *testing.T) {
+	service, synthesizer, _ := newTestService(50 * time.Millisecond)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := service.Speak(context.Background(), "See you soon", "nova")
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&synthesizer.calls))
+}
+
+func TestSpeakValidation(t *testing.T) {
+	service, synthesizer, _ := newTestService(0)
+
+	_, err := service.Speak(context.Background(), "   ", "")
+	assert.True(t, errors.Is(err, ErrEmptyText))
+
+	_, err = service.Speak(context.Background(), strings.Repeat("a", 21), "")
+	assert.True(t, errors.Is(err, ErrTextTooLong))
+
+	_, err = service.Speak(context.Background(), "hello", "robot")
+	assert.True(t, errors.Is(err, ErrInvalidVoice))
+
+	assert.Zero(t, atomic.LoadInt32(&synthesizer.calls))
+}
@@ -0,0 +1,98 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// maxAudioBytes bounds the audio read from the provider for a short phrase
+const maxAudioBytes = 5 << 20
+
+// Synthesizer turns text into speech
+type Synthesizer interface {
+	// Name identifies the provider and model, so a change of either is a cache miss
+	Name() string
+	// Voices lists the voices the provider accepts
+	Voices() []string
+	// Synthesize returns MP3 audio of the text spoken in the given voice
+	Synthesize(ctx context.Context, text, voice string) ([]byte, error)
+}
+
+// OpenAISynthesizer uses the OpenAI speech endpoint
+type OpenAISynthesizer struct {
+	apiKey     string
+	apiURL     string
+	model      string
+	httpClient *http.Client
+}
+
+// NewOpenAISynthesizer creates a synthesizer for the given speech endpoint and model
+func NewOpenAISynthesizer(apiKey, apiURL, model string) *OpenAISynthesizer {
+	return &OpenAISynthesizer{
+		apiKey: apiKey,
+		apiURL: apiURL,
+		model:  model,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// Name implements Synthesizer
+func (s *OpenAISynthesizer) Name() string {
+	return "openai:" + s.model
+}
+
+// Voices implements Synthesizer
+func (s *OpenAISynthesizer) Voices() []string {
+	return []string{"alloy", "echo", "fable", "onyx", "nova", "shimmer"}
+}
+
+// Synthesize implements Synthesizer
+func (s *OpenAISynthesizer) Synthesize(ctx context.Context, text, voice string) ([]byte, error) {
+	requestBody, err := json.Marshal(map[string]string{
+		"model":           s.model,
+		"input":           text,
+		"voice":           voice,
+		"response_format": "mp3",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.apiURL, bytes.NewReader(requestBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+s.apiKey)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("speech API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	audio, err := io.ReadAll(io.LimitReader(resp.Body, maxAudioBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audio: %w", err)
+	}
+	if len(audio) > maxAudioBytes {
+		return nil, fmt.Errorf("speech API returned more than %d bytes", maxAudioBytes)
+	}
+	if len(audio) == 0 {
+		return nil, fmt.Errorf("speech API returned no audio")
+	}
+
+	return audio, nil
+}
@@ -0,0 +1,408 @@
+package upgrade
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"time"
+
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/logger"
+	"github.com/toeic-app/internal/websocket"
+)
+
+// Delivery statuses of a rollout on a device
+const (
+	DeliveryPending      = "pending"
+	DeliveryDelivered    = "delivered"
+	DeliveryAcknowledged = "acknowledged"
+)
+
+var (
+	ErrVersionNotFound  = errors.New("version not found")
+	ErrInvalidRules     = errors.New("invalid targeting rules")
+	ErrDeliveryNotFound = errors.New("upgrade delivery not found")
+)
+
+// Notifier delivers real-time messages to connected users. Messages to
+// users that are not connected are dropped, so IsConnected tells delivered
+// notifications from pending ones.
+type Notifier interface {
+	SendToUser(userID string, messageType string, data interface{}) error
+	IsConnected(userID string) bool
+}
+
+// Rules select the devices a rollout is sent to. Empty platforms, segments
+// or version bounds match every device. Devices already on the rolled out
+// version or newer are never targeted.
+type Rules struct {
+	// Platforms such as android, ios or web
+	Platforms []string `json:"platforms"`
+	// MinAppVersion and MaxAppVersion bound the installed version, inclusive
+	MinAppVersion string `json:"min_app_version,omitempty"`
+	MaxAppVersion string `json:"max_app_version,omitempty"`
+	// Segments are role names; the device's user must have one of them
+	Segments []string `json:"segments"`
+	// Percentage of the matching devices, from 0 to 100. A device keeps its
+	// place when the percentage of a version is raised in a later rollout.
+	Percentage int32 `json:"percentage"`
+}
+
+// Device is a registered device of a user
+type Device struct {
+	ID         int32     `json:"id"`
+	DeviceID   string    `json:"device_id"`
+	Platform   string    `json:"platform"`
+	AppVersion string    `json:"app_version"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
+
+// Rollout is an upgrade notification sent to the devices matching its rules
+type Rollout struct {
+	ID           int32     `json:"id"`
+	Version      string    `json:"version"`
+	Rules        Rules     `json:"rules"`
+	CreatedBy    *int32    `json:"created_by,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	Targeted     int64     `json:"targeted"`
+	Delivered    int64     `json:"delivered"`
+	Acknowledged int64     `json:"acknowledged"`
+}
+
+// Delivery is the state of a rollout on one device
+type Delivery struct {
+	DeviceID       string     `json:"device_id"`
+	UserID         int32      `json:"user_id"`
+	Username       string     `json:"username"`
+	Platform       string     `json:"platform"`
+	AppVersion     string     `json:"app_version"`
+	Status         string     `json:"status"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+}
+
+// RolloutOptions configure targeted rollouts
+type RolloutOptions struct {
+	// ActiveWithin skips devices that have not registered for this long
+	ActiveWithin time.Duration
+}
+
+// Rollouts sends upgrade notifications to registered devices selected by
+// targeting rules and tracks their delivery and acknowledgement. Devices
+// that are offline when a rollout is sent receive it when they next
+// register.
+type Rollouts struct {
+	store    db.Querier
+	versions *Service
+	notifier Notifier
+	options  RolloutOptions
+	now      func() time.Time
+}
+
+// NewRollouts creates a rollout service. versions resolves the versions
+// rolled out.
+func NewRollouts(store db.Querier, versions *Service, notifier Notifier, options RolloutOptions) *Rollouts {
+	return &Rollouts{
+		store:    store,
+		versions: versions,
+		notifier: notifier,
+		options:  options,
+		now:      time.Now,
+	}
+}
+
+// RegisterDevice records the platform and installed version of a device and
+// returns the rollouts it missed while offline
+func (r *Rollouts) RegisterDevice(ctx context.Context, userID int32, deviceID, platform, appVersion string) (*Device, []websocket.UpgradeNotification, error) {
+	if !isValidVersionFormat(appVersion) {
+		return nil, nil, fmt.Errorf("%w: app version must be X.Y.Z", ErrInvalidRules)
+	}
+	now := r.now()
+	row, err := r.store.UpsertUpgradeDevice(ctx, db.UpsertUpgradeDeviceParams{
+		UserID:     userID,
+		DeviceID:   deviceID,
+		Platform:   strings.ToLower(platform),
+		AppVersion: appVersion,
+		LastSeenAt: now,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to register device: %w", err)
+	}
+
+	pending, err := r.store.DeliverPendingUpgradeDeliveries(ctx, db.DeliverPendingUpgradeDeliveriesParams{
+		DeliveredAt: now,
+		DeviceID:    row.ID,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to deliver pending upgrades: %w", err)
+	}
+	notifications := make([]websocket.UpgradeNotification, 0, len(pending))
+	for _, delivery := range pending {
+		version, ok := r.versions.GetVersion(delivery.Version)
+		if !ok {
+			version = &AppVersion{Version: delivery.Version}
+		}
+		notifications = append(notifications, r.notification(version, delivery.RolloutID, row.DeviceID, row.Platform))
+	}
+
+	return &Device{
+		ID:         row.ID,
+		DeviceID:   row.DeviceID,
+		Platform:   row.Platform,
+		AppVersion: row.AppVersion,
+		LastSeenAt: row.LastSeenAt,
+	}, notifications, nil
+}
+
+// Send records a rollout of a version and notifies the connected devices
+// matching the rules. createdBy is the admin who sent it.
+func (r *Rollouts) Send(ctx context.Context, versionNumber string, rules Rules, createdBy int32) (*Rollout, error) {
+	version, ok := r.versions.GetVersion(versionNumber)
+	if !ok {
+		return nil, ErrVersionNotFound
+	}
+	rules, err := normalizeRules(rules)
+	if err != nil {
+		return nil, err
+	}
+
+	now := r.now()
+	candidates, err := r.store.ListUpgradeDeviceCandidates(ctx, db.ListUpgradeDeviceCandidatesParams{
+		SeenSince: now.Add(-r.options.ActiveWithin),
+		Platforms: rules.Platforms,
+		Segments:  rules.Segments,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list devices: %w", err)
+	}
+	targets := make([]db.ListUpgradeDeviceCandidatesRow, 0, len(candidates))
+	for _, device := range candidates {
+		if rules.matches(version.Version, device) {
+			targets = append(targets, device)
+		}
+	}
+
+	row, err := r.store.CreateUpgradeRollout(ctx, db.CreateUpgradeRolloutParams{
+		Version:       version.Version,
+		Platforms:     rules.Platforms,
+		MinAppVersion: rules.MinAppVersion,
+		MaxAppVersion: rules.MaxAppVersion,
+		Segments:      rules.Segments,
+		Percentage:    rules.Percentage,
+		CreatedBy:     sql.NullInt32{Int32: createdBy, Valid: createdBy != 0},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rollout: %w", err)
+	}
+	rollout := &Rollout{
+		ID:        row.ID,
+		Version:   row.Version,
+		Rules:     rules,
+		CreatedAt: row.CreatedAt,
+		Targeted:  int64(len(targets)),
+	}
+	if row.CreatedBy.Valid {
+		rollout.CreatedBy = &row.CreatedBy.Int32
+	}
+	if len(targets) == 0 {
+		return rollout, nil
+	}
+
+	deviceIDs := make([]int32, 0, len(targets))
+	for _, device := range targets {
+		deviceIDs = append(deviceIDs, device.ID)
+	}
+	if err := r.store.CreateUpgradeDeliveries(ctx, db.CreateUpgradeDeliveriesParams{RolloutID: row.ID, DeviceIds: deviceIDs}); err != nil {
+		return nil, fmt.Errorf("failed to create deliveries: %w", err)
+	}
+
+	// Offline devices stay pending; they fetch the rollout when they register
+	delivered := make([]int32, 0, len(targets))
+	for _, device := range targets {
+		if !r.notifier.IsConnected(device.Username) {
+			continue
+		}
+		notification := r.notification(version, row.ID, device.DeviceID, device.Platform)
+		if err := r.notifier.SendToUser(device.Username, "upgrade_notification", notification); err != nil {
+			logger.Debug("Upgrade rollout %d not delivered to device %d: %v", row.ID, device.ID, err)
+			continue
+		}
+		delivered = append(delivered, device.ID)
+	}
+	if len(delivered) > 0 {
+		marked, err := r.store.MarkUpgradeDeliveriesDelivered(ctx, db.MarkUpgradeDeliveriesDeliveredParams{
+			DeliveredAt: now,
+			RolloutID:   row.ID,
+			DeviceIds:   delivered,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to record deliveries: %w", err)
+		}
+		rollout.Delivered = marked
+	}
+
+	logger.Info("Rolled out version %s to %d of %d matching devices (%d delivered)",
+		version.Version, len(targets), len(candidates), rollout.Delivered)
+	return rollout, nil
+}
+
+// Acknowledge records that a device of the user showed a rollout
+func (r *Rollouts) Acknowledge(ctx context.Context, userID, rolloutID int32, deviceID string) error {
+	updated, err := r.store.AcknowledgeUpgradeDelivery(ctx, db.AcknowledgeUpgradeDeliveryParams{
+		AcknowledgedAt: r.now(),
+		RolloutID:      rolloutID,
+		UserID:         userID,
+		DeviceID:       deviceID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to acknowledge upgrade delivery: %w", err)
+	}
+	if updated == 0 {
+		return ErrDeliveryNotFound
+	}
+	return nil
+}
+
+// List returns rollouts, newest first, with their delivery counts and the
+// total count
+func (r *Rollouts) List(ctx context.Context, limit, offset int32) ([]Rollout, int64, error) {
+	rows, err := r.store.ListUpgradeRollouts(ctx, db.ListUpgradeRolloutsParams{Limit: limit, Offset: offset})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list rollouts: %w", err)
+	}
+	total, err := r.store.CountUpgradeRollouts(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count rollouts: %w", err)
+	}
+
+	rollouts := make([]Rollout, 0, len(rows))
+	for _, row := range rows {
+		rollout := Rollout{
+			ID:      row.ID,
+			Version: row.Version,
+			Rules: Rules{
+				Platforms:     row.Platforms,
+				MinAppVersion: row.MinAppVersion,
+				MaxAppVersion: row.MaxAppVersion,
+				Segments:      row.Segments,
+				Percentage:    row.Percentage,
+			},
+			CreatedAt:    row.CreatedAt,
+			Targeted:     row.Targeted,
+			Delivered:    row.Delivered,
+			Acknowledged: row.Acknowledged,
+		}
+		if row.CreatedBy.Valid {
+			rollout.CreatedBy = &row.CreatedBy.Int32
+		}
+		rollouts = append(rollouts, rollout)
+	}
+	return rollouts, total, nil
+}
+
+// Deliveries returns the per-device state of a rollout, optionally of one
+// status, with the total count
+func (r *Rollouts) Deliveries(ctx context.Context, rolloutID int32, status string, limit, offset int32) ([]Delivery, int64, error) {
+	filter := sql.NullString{String: status, Valid: status != ""}
+	rows, err := r.store.ListUpgradeDeliveries(ctx, db.ListUpgradeDeliveriesParams{
+		RolloutID: rolloutID,
+		Status:    filter,
+		Limit:     limit,
+		Offset:    offset,
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list upgrade deliveries: %w", err)
+	}
+	total, err := r.store.CountUpgradeDeliveries(ctx, db.CountUpgradeDeliveriesParams{RolloutID: rolloutID, Status: filter})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count upgrade deliveries: %w", err)
+	}
+
+	deliveries := make([]Delivery, 0, len(rows))
+	for _, row := range rows {
+		delivery := Delivery{
+			DeviceID:   row.DeviceID,
+			UserID:     row.UserID,
+			Username:   row.Username,
+			Platform:   row.Platform,
+			AppVersion: row.AppVersion,
+			Status:     row.Status,
+		}
+		if row.DeliveredAt.Valid {
+			delivery.DeliveredAt = &row.DeliveredAt.Time
+		}
+		if row.AcknowledgedAt.Valid {
+			delivery.AcknowledgedAt = &row.AcknowledgedAt.Time
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, total, nil
+}
+
+func (r *Rollouts) notification(version *AppVersion, rolloutID int32, deviceID, platform string) websocket.UpgradeNotification {
+	notification := websocket.UpgradeNotification{
+		Version:     version.Version,
+		Title:       version.Title,
+		Description: version.Description,
+		Required:    version.Required,
+		ReleaseDate: version.ReleaseDate,
+		Changes:     version.Changes,
+		RolloutID:   rolloutID,
+		DeviceID:    deviceID,
+	}
+	if downloadURL, exists := version.Downloads[platform]; exists {
+		notification.UpdateURL = downloadURL
+	}
+	return notification
+}
+
+// normalizeRules validates rules and lowercases platforms
+func normalizeRules(rules Rules) (Rules, error) {
+	if rules.Percentage < 0 || rules.Percentage > 100 {
+		return rules, fmt.Errorf("%w: percentage must be between 0 and 100", ErrInvalidRules)
+	}
+	for _, bound := range []string{rules.MinAppVersion, rules.MaxAppVersion} {
+		if bound != "" && !isValidVersionFormat(bound) {
+			return rules, fmt.Errorf("%w: app versions must be X.Y.Z", ErrInvalidRules)
+		}
+	}
+	if rules.MinAppVersion != "" && rules.MaxAppVersion != "" && isNewerVersion(rules.MinAppVersion, rules.MaxAppVersion) {
+		return rules, fmt.Errorf("%w: min_app_version is newer than max_app_version", ErrInvalidRules)
+	}
+	platforms := make([]string, 0, len(rules.Platforms))
+	for _, platform := range rules.Platforms {
+		platforms = append(platforms, strings.ToLower(platform))
+	}
+	rules.Platforms = platforms
+	if rules.Segments == nil {
+		rules.Segments = []string{}
+	}
+	return rules, nil
+}
+
+// matches applies the rules the database does not: the installed version
+// range and the percentage
+func (rules Rules) matches(version string, device db.ListUpgradeDeviceCandidatesRow) bool {
+	if !isNewerVersion(version, device.AppVersion) {
+		return false
+	}
+	if rules.MinAppVersion != "" && isNewerVersion(rules.MinAppVersion, device.AppVersion) {
+		return false
+	}
+	if rules.MaxAppVersion != "" && isNewerVersion(device.AppVersion, rules.MaxAppVersion) {
+		return false
+	}
+	return rolloutBucket(version, device.UserID, device.DeviceID) < rules.Percentage
+}
+
+// rolloutBucket places a device in one of 100 buckets. Hashing with the
+// version keeps the same devices first in line across rollouts of a version
+// while spreading different versions over different devices.
+func rolloutBucket(version string, userID int32, deviceID string) int32 {
+	hash := fnv.New32a()
+	fmt.Fprintf(hash, "%s:%d:%s", version, userID, deviceID)
+	return int32(hash.Sum32() % 100)
+}
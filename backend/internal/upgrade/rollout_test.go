@@ -0,0 +1,125 @@
+package upgrade
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+// fakeStore returns fixed devices and records deliveries
+type fakeStore struct {
+	db.Querier
+	devices    []db.ListUpgradeDeviceCandidatesRow
+	candidates db.ListUpgradeDeviceCandidatesParams
+	created    []int32
+	delivered  []int32
+}
+
+func (s *fakeStore) ListUpgradeDeviceCandidates(_ context.Context, arg db.ListUpgradeDeviceCandidatesParams) ([]db.ListUpgradeDeviceCandidatesRow, error) {
+	s.candidates = arg
+	return s.devices, nil
+}
+
+func (s *fakeStore) CreateUpgradeRollout(_ context.Context, arg db.CreateUpgradeRolloutParams) (db.UpgradeRollout, error) {
+	return db.UpgradeRollout{ID: 7, Version: arg.Version, Percentage: arg.Percentage, CreatedBy: arg.CreatedBy}, nil
+}
+
+func (s *fakeStore) CreateUpgradeDeliveries(_ context.Context, arg db.CreateUpgradeDeliveriesParams) error {
+	s.created = arg.DeviceIds
+	return nil
+}
+
+func (s *fakeStore) MarkUpgradeDeliveriesDelivered(_ context.Context, arg db.MarkUpgradeDeliveriesDeliveredParams) (int64, error) {
+	s.delivered = arg.DeviceIds
+	return int64(len(arg.DeviceIds)), nil
+}
+
+// fakeNotifier drops messages to users that are not connected
+type fakeNotifier struct {
+	connected map[string]bool
+	sent      []interface{}
+}
+
+func (n *fakeNotifier) SendToUser(userID string, _ string, data interface{}) error {
+	if n.connected[userID] {
+		n.sent = append(n.sent, data)
+	}
+	return nil
+}
+
+func (n *fakeNotifier) IsConnected(userID string) bool {
+	return n.connected[userID]
+}
+
+func newTestRollouts(t *testing.T, store *fakeStore, notifier *fakeNotifier) *Rollouts {
+	versions := NewService(nil)
+	require.NoError(t, versions.AddVersion(&AppVersion{Version: "1.2.0", Downloads: map[string]string{"android": "/a.apk"}}))
+	rollouts := NewRollouts(store, versions, notifier, RolloutOptions{ActiveWithin: 24 * time.Hour})
+	rollouts.now = func() time.Time { return time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC) }
+	return rollouts
+}
+
+func TestSendTargetsVersionRange(t *testing.T) {
+	store := &fakeStore{devices: []db.ListUpgradeDeviceCandidatesRow{
+		{ID: 1, UserID: 1, Username: "ana", DeviceID: "a1", Platform: "android", AppVersion: "1.0.0"},
+		{ID: 2, UserID: 1, Username: "ana", DeviceID: "a2", Platform: "android", AppVersion: "1.1.5"},
+		{ID: 3, UserID: 2, Username: "bao", DeviceID: "b1", Platform: "android", AppVersion: "1.1.0"},
+		{ID: 4, UserID: 3, Username: "chi", DeviceID: "c1", Platform: "android", AppVersion: "1.2.0"},
+	}}
+	notifier := &fakeNotifier{connected: map[string]bool{"ana": true}}
+	rollouts := newTestRollouts(t, store, notifier)
+
+	rollout, err := rollouts.Send(context.Background(), "1.2.0", Rules{
+		Platforms:     []string{"Android"},
+		MinAppVersion: "1.1.0",
+		Percentage:    100,
+	}, 9)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"android"}, store.candidates.Platforms)
+	assert.Equal(t, time.Date(2026, 4, 30, 0, 0, 0, 0, time.UTC), store.candidates.SeenSince)
+	assert.Equal(t, []int32{2, 3}, store.created)
+	assert.Equal(t, []int32{2}, store.delivered)
+	assert.Equal(t, int64(2), rollout.Targeted)
+	assert.Equal(t, int64(1), rollout.Delivered)
+	require.Len(t, notifier.sent, 1)
+	require.NotNil(t, rollout.CreatedBy)
+	assert.Equal(t, int32(9), *rollout.CreatedBy)
+}
+
+func TestSendRejectsInvalidRules(t *testing.T) {
+	rollouts := newTestRollouts(t, &fakeStore{}, &fakeNotifier{})
+	ctx := context.Background()
+
+	_, err := rollouts.Send(ctx, "1.2.0", Rules{Percentage: 101}, 1)
+	assert.ErrorIs(t, err, ErrInvalidRules)
+	_, err = rollouts.Send(ctx, "1.2.0", Rules{MinAppVersion: "1.1", Percentage: 50}, 1)
+	assert.ErrorIs(t, err, ErrInvalidRules)
+	_, err = rollouts.Send(ctx, "1.2.0", Rules{MinAppVersion: "1.1.0", MaxAppVersion: "1.0.0", Percentage: 50}, 1)
+	assert.ErrorIs(t, err, ErrInvalidRules)
+	_, err = rollouts.Send(ctx, "9.9.9", Rules{Percentage: 50}, 1)
+	assert.ErrorIs(t, err, ErrVersionNotFound)
+}
+
+func TestPercentageIsStable(t *testing.T) {
+	var half, most int
+	for i := 0; i < 1000; i++ {
+		device := db.ListUpgradeDeviceCandidatesRow{UserID: int32(i), DeviceID: fmt.Sprintf("d%d", i), AppVersion: "1.0.0"}
+		inHalf := Rules{Percentage: 50}.matches("1.2.0", device)
+		inMost := Rules{Percentage: 80}.matches("1.2.0", device)
+		if inHalf {
+			half++
+			assert.True(t, inMost, "raising the percentage must keep device %d", i)
+		}
+		if inMost {
+			most++
+		}
+	}
+	assert.InDelta(t, 500, half, 60)
+	assert.InDelta(t, 800, most, 60)
+	assert.False(t, Rules{Percentage: 0}.matches("1.2.0", db.ListUpgradeDeviceCandidatesRow{AppVersion: "1.0.0"}))
+}
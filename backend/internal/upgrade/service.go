@@ -274,6 +274,29 @@ func (s *Service) GetCurrentVersion() *AppVersion {
 	return s.currentVersion
 }
 
+// MinSupportedVersion returns the oldest version clients may keep running:
+// the newer of floor and the min version of the current release, or the
+// current release itself when it is required
+func (s *Service) MinSupportedVersion(floor string) string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	minVersion := s.currentVersion.MinVersion
+	if s.currentVersion.Required {
+		minVersion = s.currentVersion.Version
+	}
+	if floor != "" && (minVersion == "" || isNewerVersion(floor, minVersion)) {
+		minVersion = floor
+	}
+	return minVersion
+}
+
+// Supported reports whether a client running version may keep running it
+// with minVersion as the oldest supported version
+func Supported(version, minVersion string) bool {
+	return minVersion == "" || !isNewerVersion(minVersion, version)
+}
+
 // GetVersions returns all available versions
 func (s *Service) GetVersions() map[string]*AppVersion {
 	s.mutex.RLock()
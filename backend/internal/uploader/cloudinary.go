@@ -2,8 +2,13 @@ package uploader
 
 import (
 	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
 
 	"github.com/cloudinary/cloudinary-go/v2"
+	"github.com/cloudinary/cloudinary-go/v2/api"
 	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
 	"github.com/toeic-app/internal/config"
 )
@@ -44,3 +49,166 @@ func (cu *CloudinaryUploader) UploadAudio(ctx context.Context, file interface{},
 	}
 	return uploadResult.SecureURL, nil
 }
+
+// UploadPrivateAudio uploads audio that can only be delivered through signed URLs.
+// An existing asset with the same public ID is kept.
+func (cu *CloudinaryUploader) UploadPrivateAudio(ctx context.Context, file interface{}, publicID string) error {
+	overwrite := false
+	uploadParams := uploader.UploadParams{
+		PublicID:     publicID,
+		ResourceType: "video", // Cloudinary uses "video" for audio files as well
+		Type:         api.Authenticated,
+		Overwrite:    &overwrite,
+	}
+
+	_, err := cu.cld.Upload.Upload(ctx, file, uploadParams)
+	return err
+}
+
+// SignedAudioURL returns a signed delivery URL for audio uploaded with UploadPrivateAudio
+func (cu *CloudinaryUploader) SignedAudioURL(publicID string) (string, error) {
+	asset, err := cu.cld.Video(publicID)
+	if err != nil {
+		return "", err
+	}
+	asset.DeliveryType = api.Authenticated
+	asset.Config.URL.SignURL = true
+	asset.Config.URL.Secure = true
+	return asset.String()
+}
+
+// PlaybackURL returns a signed delivery URL of audio uploaded with
+// UploadAudio, given its delivery URL. When CLOUDINARY_URL sets a token key
+// the URL carries a token that expires at expiresAt; without one Cloudinary
+// does not enforce the expiry. URLs of other clouds or hosts are returned
+// unchanged.
+func (cu *CloudinaryUploader) PlaybackURL(url string, expiresAt time.Time) (string, error) {
+	publicID, ok := audioPublicID(cu.cld.Config.Cloud.CloudName, url)
+	if !ok {
+		return url, nil
+	}
+	asset, err := cu.cld.Video(publicID)
+	if err != nil {
+		return "", err
+	}
+	asset.Config.URL.SignURL = true
+	asset.Config.URL.Secure = true
+	if cu.cld.Config.AuthToken.Key != "" {
+		authToken := cu.cld.Config.AuthToken
+		authToken.Duration = 0
+		authToken.Expiration = expiresAt.Unix()
+		asset.AuthToken.Config = &authToken
+	}
+	return asset.String()
+}
+
+// DeleteAudio permanently deletes audio uploaded with UploadAudio, given its
+// delivery URL. URLs of other clouds or hosts have nothing to delete here.
+func (cu *CloudinaryUploader) DeleteAudio(ctx context.Context, url string) error {
+	publicID, ok := audioPublicID(cu.cld.Config.Cloud.CloudName, url)
+	if !ok {
+		return nil
+	}
+	invalidate := true
+	result, err := cu.cld.Upload.Destroy(ctx, uploader.DestroyParams{
+		PublicID:     publicID,
+		ResourceType: "video",
+		Invalidate:   &invalidate,
+	})
+	if err != nil {
+		return err
+	}
+	if result.Error.Message != "" {
+		return fmt.Errorf("failed to delete audio %s: %s", publicID, result.Error.Message)
+	}
+	// "not found" means the asset is already gone
+	if result.Result != "ok" && result.Result != "not found" {
+		return fmt.Errorf("failed to delete audio %s: %s", publicID, result.Result)
+	}
+	return nil
+}
+
+// UploadPrivateImage uploads an image that can only be delivered through
+// signed URLs
+func (cu *CloudinaryUploader) UploadPrivateImage(ctx context.Context, file interface{}, publicID string) error {
+	uploadParams := uploader.UploadParams{
+		PublicID: publicID,
+		Type:     api.Authenticated,
+	}
+
+	_, err := cu.cld.Upload.Upload(ctx, file, uploadParams)
+	return err
+}
+
+// SignedImageURL returns a signed delivery URL for an image uploaded with
+// UploadPrivateImage. When CLOUDINARY_URL sets a token key the URL carries
+// a token that expires at expiresAt; without one Cloudinary does not
+// enforce the expiry.
+func (cu *CloudinaryUploader) SignedImageURL(publicID string, expiresAt time.Time) (string, error) {
+	asset, err := cu.cld.Image(publicID)
+	if err != nil {
+		return "", err
+	}
+	asset.DeliveryType = api.Authenticated
+	asset.Config.URL.SignURL = true
+	asset.Config.URL.Secure = true
+	if cu.cld.Config.AuthToken.Key != "" {
+		authToken := cu.cld.Config.AuthToken
+		authToken.Duration = 0
+		authToken.Expiration = expiresAt.Unix()
+		asset.AuthToken.Config = &authToken
+	}
+	return asset.String()
+}
+
+// DeletePrivateImage permanently deletes an image uploaded with
+// UploadPrivateImage
+func (cu *CloudinaryUploader) DeletePrivateImage(ctx context.Context, publicID string) error {
+	invalidate := true
+	result, err := cu.cld.Upload.Destroy(ctx, uploader.DestroyParams{
+		PublicID:     publicID,
+		Type:         api.Authenticated,
+		ResourceType: "image",
+		Invalidate:   &invalidate,
+	})
+	if err != nil {
+		return err
+	}
+	if result.Error.Message != "" {
+		return fmt.Errorf("failed to delete image %s: %s", publicID, result.Error.Message)
+	}
+	// "not found" means the asset is already gone
+	if result.Result != "ok" && result.Result != "not found" {
+		return fmt.Errorf("failed to delete image %s: %s", publicID, result.Result)
+	}
+	return nil
+}
+
+// audioPublicID extracts the public ID from a delivery URL such as
+// https://res.cloudinary.com/<cloud>/video/upload/v1700000000/<public_id>.mp3
+func audioPublicID(cloudName, url string) (string, bool) {
+	prefix := "res.cloudinary.com/" + cloudName + "/video/upload/"
+	_, rest, found := strings.Cut(url, prefix)
+	if !found || cloudName == "" {
+		return "", false
+	}
+	rest, _, _ = strings.Cut(rest, "?")
+	if version, after, ok := strings.Cut(rest, "/"); ok && isVersion(version) {
+		rest = after
+	}
+	publicID := strings.TrimSuffix(rest, path.Ext(rest))
+	return publicID, publicID != ""
+}
+
+// isVersion reports whether segment is a version component like v1700000000
+func isVersion(segment string) bool {
+	if len(segment) < 2 || segment[0] != 'v' {
+		return false
+	}
+	for _, r := range segment[1:] {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
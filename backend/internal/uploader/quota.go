@@ -0,0 +1,247 @@
+package uploader
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/logger"
+)
+
+// ErrQuotaExceeded is returned when an upload would exceed the daily quota
+var ErrQuotaExceeded = errors.New("daily upload quota exceeded")
+
+// QuotaUsage describes a user's upload usage for the current day (UTC)
+type QuotaUsage struct {
+	Date           string    `json:"date"`
+	BytesUsed      int64     `json:"bytes_used"`
+	FilesCount     int64     `json:"files_count"`
+	BytesLimit     int64     `json:"bytes_limit"` // 0 means unlimited
+	FilesLimit     int64     `json:"files_limit"` // 0 means unlimited
+	BytesRemaining int64     `json:"bytes_remaining"`
+	FilesRemaining int64     `json:"files_remaining"`
+	ResetsAt       time.Time `json:"resets_at"`
+}
+
+// QuotaService tracks per-user daily upload usage in the database, where an
+// upload is counted atomically when it starts so that concurrent uploads
+// cannot overrun the quota together. With UseRedis, uploads in progress are
+// counted in Redis instead and the database records completed uploads; its
+// counters are used while Redis is unreachable.
+type QuotaService struct {
+	store    db.Querier
+	maxBytes int64
+	maxFiles int64
+	redis    *redisQuota
+}
+
+// Reservation is the quota held by an upload in progress. It is committed
+// once the upload is stored, or released when it fails.
+type Reservation struct {
+	UserID int32
+	Size   int64
+	// Usage is the usage of the day with the upload counted
+	Usage    QuotaUsage
+	day      time.Time
+	redis    bool
+	database bool
+}
+
+// NewQuotaService creates a quota service. A limit of 0 disables that limit.
+func NewQuotaService(store db.Querier, maxBytes, maxFiles int64) *QuotaService {
+	return &QuotaService{
+		store:    store,
+		maxBytes: maxBytes,
+		maxFiles: maxFiles,
+	}
+}
+
+// UseRedis counts uploads in Redis under keys prefixed with prefix
+func (q *QuotaService) UseRedis(client *redis.Client, prefix string) {
+	q.redis = &redisQuota{client: client, prefix: prefix}
+}
+
+// Close closes the Redis client
+func (q *QuotaService) Close() error {
+	if q == nil || q.redis == nil {
+		return nil
+	}
+	return q.redis.client.Close()
+}
+
+// Enabled reports whether any quota is enforced
+func (q *QuotaService) Enabled() bool {
+	return q != nil && (q.maxBytes > 0 || q.maxFiles > 0)
+}
+
+// today returns the current quota day
+func today() time.Time {
+	return time.Now().UTC().Truncate(24 * time.Hour)
+}
+
+// Usage returns the user's usage for today
+func (q *QuotaService) Usage(ctx context.Context, userID int32) (QuotaUsage, error) {
+	day := today()
+	if q.redis != nil && q.redis.available() {
+		bytesUsed, filesCount, found, err := q.redis.usage(ctx, userID, day)
+		if err == nil && found {
+			return q.newUsage(day, bytesUsed, filesCount), nil
+		}
+		if err != nil {
+			q.redis.failed(err)
+		}
+	}
+
+	seed, err := q.recordedUsage(ctx, userID, day)
+	if err != nil {
+		return QuotaUsage{}, err
+	}
+	return q.newUsage(day, seed.bytes, seed.files), nil
+}
+
+// recordedUsage returns the uploads of a user's day recorded in the database
+func (q *QuotaService) recordedUsage(ctx context.Context, userID int32, day time.Time) (*quotaSeed, error) {
+	usage, err := q.store.GetUserUploadUsage(ctx, db.GetUserUploadUsageParams{
+		UserID:    userID,
+		UsageDate: day,
+	})
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get upload usage: %w", err)
+	}
+	return &quotaSeed{bytes: usage.BytesUsed, files: int64(usage.FilesCount)}, nil
+}
+
+// Record adds a completed upload to today's usage
+func (q *QuotaService) Record(ctx context.Context, userID int32, size int64) (QuotaUsage, error) {
+	day := today()
+	usage, err := q.store.AddUserUploadUsage(ctx, db.AddUserUploadUsageParams{
+		UserID:    userID,
+		UsageDate: day,
+		BytesUsed: size,
+	})
+	if err != nil {
+		return QuotaUsage{}, fmt.Errorf("failed to record upload usage: %w", err)
+	}
+
+	return q.newUsage(day, usage.BytesUsed, int64(usage.FilesCount)), nil
+}
+
+// Reserve holds the quota for an upload of size bytes, returning
+// ErrQuotaExceeded with the current usage when it does not fit. Without
+// Redis the upload is counted in the database right away.
+func (q *QuotaService) Reserve(ctx context.Context, userID int32, size int64) (Reservation, error) {
+	day := today()
+	if q.redis != nil && q.redis.available() {
+		added, bytesUsed, filesCount, err := q.redis.reserve(ctx, userID, day, size, q.maxBytes, q.maxFiles, nil)
+		if errors.Is(err, errQuotaNotSeeded) {
+			// Redis has no counters for the day, so they start from the
+			// uploads recorded in the database
+			seed, seedErr := q.recordedUsage(ctx, userID, day)
+			if seedErr != nil {
+				return Reservation{UserID: userID, Size: size, day: day}, seedErr
+			}
+			added, bytesUsed, filesCount, err = q.redis.reserve(ctx, userID, day, size, q.maxBytes, q.maxFiles, seed)
+		}
+		if err == nil {
+			reservation := Reservation{UserID: userID, Size: size, Usage: q.newUsage(day, bytesUsed, filesCount), day: day, redis: added}
+			if !added {
+				return reservation, ErrQuotaExceeded
+			}
+			return reservation, nil
+		}
+		q.redis.failed(err)
+	}
+
+	return q.reserveInDatabase(ctx, userID, day, size)
+}
+
+// reserveInDatabase adds an upload to the database counters of the day in
+// one conditional upsert
+func (q *QuotaService) reserveInDatabase(ctx context.Context, userID int32, day time.Time, size int64) (Reservation, error) {
+	reservation := Reservation{UserID: userID, Size: size, day: day}
+	var usage db.UserUploadUsage
+	err := sql.ErrNoRows
+	// The upsert only checks the limits of a day that already has uploads
+	if q.maxBytes == 0 || size <= q.maxBytes {
+		usage, err = q.store.ReserveUserUploadUsage(ctx, db.ReserveUserUploadUsageParams{
+			UserID:    userID,
+			UsageDate: day,
+			BytesUsed: size,
+			MaxBytes:  q.maxBytes,
+			MaxFiles:  int32(q.maxFiles),
+		})
+	}
+	if err == sql.ErrNoRows {
+		seed, err := q.recordedUsage(ctx, userID, day)
+		if err != nil {
+			return reservation, err
+		}
+		reservation.Usage = q.newUsage(day, seed.bytes, seed.files)
+		return reservation, ErrQuotaExceeded
+	}
+	if err != nil {
+		return reservation, fmt.Errorf("failed to reserve upload quota: %w", err)
+	}
+
+	reservation.Usage = q.newUsage(day, usage.BytesUsed, int64(usage.FilesCount))
+	reservation.database = true
+	return reservation, nil
+}
+
+// Commit records the upload of a reservation once it is stored
+func (q *QuotaService) Commit(ctx context.Context, r Reservation) (QuotaUsage, error) {
+	if r.database {
+		// The database counted the upload when it started
+		return r.Usage, nil
+	}
+	usage, err := q.Record(ctx, r.UserID, r.Size)
+	if r.redis {
+		// Redis counted the upload when it started, and also counts the
+		// uploads of other instances still in progress
+		return r.Usage, err
+	}
+	return usage, err
+}
+
+// Release gives back the quota of an upload that failed
+func (q *QuotaService) Release(ctx context.Context, r Reservation) {
+	if r.database {
+		err := q.store.ReleaseUserUploadUsage(ctx, db.ReleaseUserUploadUsageParams{
+			UserID:    r.UserID,
+			UsageDate: r.day,
+			BytesUsed: r.Size,
+		})
+		if err != nil {
+			logger.Warn("Failed to release upload quota of user %d: %v", r.UserID, err)
+		}
+		return
+	}
+	if !r.redis {
+		return
+	}
+	if err := q.redis.release(ctx, r.UserID, r.day, r.Size); err != nil {
+		logger.Warn("Failed to release upload quota of user %d: %v", r.UserID, err)
+	}
+}
+
+func (q *QuotaService) newUsage(day time.Time, bytesUsed, filesCount int64) QuotaUsage {
+	usage := QuotaUsage{
+		Date:       day.Format("2006-01-02"),
+		BytesUsed:  bytesUsed,
+		FilesCount: filesCount,
+		BytesLimit: q.maxBytes,
+		FilesLimit: q.maxFiles,
+		ResetsAt:   day.Add(24 * time.Hour),
+	}
+	if q.maxBytes > 0 {
+		usage.BytesRemaining = max(q.maxBytes-bytesUsed, 0)
+	}
+	if q.maxFiles > 0 {
+		usage.FilesRemaining = max(q.maxFiles-filesCount, 0)
+	}
+	return usage
+}
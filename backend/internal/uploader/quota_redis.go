@@ -0,0 +1,158 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/toeic-app/internal/logger"
+)
+
+const (
+	// redisQuotaTimeout bounds a Redis round trip so a slow Redis does not
+	// hold up uploads
+	redisQuotaTimeout = 200 * time.Millisecond
+	// redisQuotaRetryInterval is how long the database counters are used
+	// after Redis fails before Redis is tried again
+	redisQuotaRetryInterval = 10 * time.Second
+)
+
+// reserveQuotaScript adds an upload to the hash of a user's day when both
+// limits allow it. The hash expires when the day ends. A missing hash, after
+// Redis lost it or while uploads were counted in the database, starts from
+// the seed; without a seed the script returns -1 so the caller can read one.
+//
+// ARGV: size, bytes limit, files limit (0 for unlimited), expiry (unix),
+// then optionally the seed bytes and files.
+// Returns 1 when the upload was added, else 0, then the bytes and files
+// used afterwards.
+var reserveQuotaScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	if not ARGV[5] then
+		return {-1, 0, 0}
+	end
+	redis.call('HSET', KEYS[1], 'bytes', ARGV[5], 'files', ARGV[6])
+	redis.call('EXPIREAT', KEYS[1], ARGV[4])
+end
+local bytes = tonumber(redis.call('HGET', KEYS[1], 'bytes') or '0')
+local files = tonumber(redis.call('HGET', KEYS[1], 'files') or '0')
+local size = tonumber(ARGV[1])
+local maxBytes = tonumber(ARGV[2])
+local maxFiles = tonumber(ARGV[3])
+if (maxBytes > 0 and bytes + size > maxBytes) or (maxFiles > 0 and files + 1 > maxFiles) then
+	return {0, bytes, files}
+end
+bytes = redis.call('HINCRBY', KEYS[1], 'bytes', size)
+files = redis.call('HINCRBY', KEYS[1], 'files', 1)
+redis.call('EXPIREAT', KEYS[1], ARGV[4])
+return {1, bytes, files}
+`)
+
+// releaseQuotaScript takes back an upload that failed. The hash of a day
+// that already ended is left alone.
+var releaseQuotaScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	redis.call('HINCRBY', KEYS[1], 'bytes', -tonumber(ARGV[1]))
+	redis.call('HINCRBY', KEYS[1], 'files', -1)
+end
+return 0
+`)
+
+// errQuotaNotSeeded is returned by reserve when the hash of the day is
+// missing and no seed was given
+var errQuotaNotSeeded = errors.New("upload quota hash is missing")
+
+// quotaSeed is the usage a missing hash of a day starts from
+type quotaSeed struct {
+	bytes int64
+	files int64
+}
+
+// redisQuota keeps the daily upload counters of users in Redis, shared by
+// all instances
+type redisQuota struct {
+	client    *redis.Client
+	prefix    string
+	mu        sync.Mutex
+	downUntil time.Time
+}
+
+func (r *redisQuota) key(userID int32, day time.Time) string {
+	return r.prefix + strconv.Itoa(int(userID)) + ":" + day.Format("2006-01-02")
+}
+
+// available reports whether Redis is used, or skipped after a failure
+func (r *redisQuota) available() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return time.Now().After(r.downUntil)
+}
+
+// failed skips Redis for redisQuotaRetryInterval
+func (r *redisQuota) failed(err error) {
+	r.mu.Lock()
+	r.downUntil = time.Now().Add(redisQuotaRetryInterval)
+	r.mu.Unlock()
+	logger.Warn("Redis upload quota unavailable, using database counters for %v: %v", redisQuotaRetryInterval, err)
+}
+
+// usage returns the bytes and files used by a user on a day, and whether
+// Redis has the hash of that day
+func (r *redisQuota) usage(ctx context.Context, userID int32, day time.Time) (int64, int64, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, redisQuotaTimeout)
+	defer cancel()
+
+	values, err := r.client.HMGet(ctx, r.key(userID, day), "bytes", "files").Result()
+	if err != nil {
+		return 0, 0, false, fmt.Errorf("failed to get upload quota: %w", err)
+	}
+	counts := make([]int64, len(values))
+	found := false
+	for i, value := range values {
+		if s, ok := value.(string); ok {
+			counts[i], _ = strconv.ParseInt(s, 10, 64)
+			found = true
+		}
+	}
+	return counts[0], counts[1], found, nil
+}
+
+// reserve adds an upload of size bytes to a user's day when the limits
+// allow it, returning whether it was added and the usage afterwards. It
+// returns errQuotaNotSeeded when the hash of the day is missing and seed is
+// nil.
+func (r *redisQuota) reserve(ctx context.Context, userID int32, day time.Time, size, maxBytes, maxFiles int64, seed *quotaSeed) (bool, int64, int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, redisQuotaTimeout)
+	defer cancel()
+
+	args := []interface{}{size, maxBytes, maxFiles, day.Add(24 * time.Hour).Unix()}
+	if seed != nil {
+		args = append(args, seed.bytes, seed.files)
+	}
+	values, err := reserveQuotaScript.Run(ctx, r.client, []string{r.key(userID, day)}, args...).Int64Slice()
+	if err != nil {
+		return false, 0, 0, fmt.Errorf("failed to reserve upload quota: %w", err)
+	}
+	if len(values) != 3 {
+		return false, 0, 0, fmt.Errorf("unexpected upload quota script result: %v", values)
+	}
+	if values[0] == -1 {
+		return false, 0, 0, errQuotaNotSeeded
+	}
+	return values[0] == 1, values[1], values[2], nil
+}
+
+// release takes back an upload added by reserve
+func (r *redisQuota) release(ctx context.Context, userID int32, day time.Time, size int64) error {
+	ctx, cancel := context.WithTimeout(ctx, redisQuotaTimeout)
+	defer cancel()
+
+	if err := releaseQuotaScript.Run(ctx, r.client, []string{r.key(userID, day)}, size).Err(); err != nil {
+		return fmt.Errorf("failed to release upload quota: %w", err)
+	}
+	return nil
+}
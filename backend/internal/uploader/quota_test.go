@@ -0,0 +1,194 @@
+package uploader
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/fakes"
+)
+
+// usageStore keeps the upload usage of the current day
+type usageStore struct {
+	db.Querier
+	mu    sync.Mutex
+	usage map[int32]db.UserUploadUsage
+}
+
+func (s *usageStore) GetUserUploadUsage(_ context.Context, arg db.GetUserUploadUsageParams) (db.UserUploadUsage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	usage, ok := s.usage[arg.UserID]
+	if !ok {
+		return db.UserUploadUsage{}, sql.ErrNoRows
+	}
+	return usage, nil
+}
+
+func (s *usageStore) AddUserUploadUsage(_ context.Context, arg db.AddUserUploadUsageParams) (db.UserUploadUsage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	usage := s.usage[arg.UserID]
+	usage.UserID, usage.UsageDate = arg.UserID, arg.UsageDate
+	usage.BytesUsed += arg.BytesUsed
+	usage.FilesCount++
+	s.usage[arg.UserID] = usage
+	return usage, nil
+}
+
+func (s *usageStore) ReserveUserUploadUsage(_ context.Context, arg db.ReserveUserUploadUsageParams) (db.UserUploadUsage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	usage, ok := s.usage[arg.UserID]
+	if ok && ((arg.MaxBytes > 0 && usage.BytesUsed+arg.BytesUsed > arg.MaxBytes) || (arg.MaxFiles > 0 && usage.FilesCount >= arg.MaxFiles)) {
+		return db.UserUploadUsage{}, sql.ErrNoRows
+	}
+	usage.UserID, usage.UsageDate = arg.UserID, arg.UsageDate
+	usage.BytesUsed += arg.BytesUsed
+	usage.FilesCount++
+	s.usage[arg.UserID] = usage
+	return usage, nil
+}
+
+func (s *usageStore) ReleaseUserUploadUsage(_ context.Context, arg db.ReleaseUserUploadUsageParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	usage, ok := s.usage[arg.UserID]
+	if ok {
+		usage.BytesUsed = max(usage.BytesUsed-arg.BytesUsed, 0)
+		usage.FilesCount = max(usage.FilesCount-1, 0)
+		s.usage[arg.UserID] = usage
+	}
+	return nil
+}
+
+func TestRedisQuotaIsSharedByInstances(t *testing.T) {
+	client, server := fakes.NewRedisClient(t)
+	store := &usageStore{usage: make(map[int32]db.UserUploadUsage)}
+	first := NewQuotaService(store, 100, 3)
+	first.UseRedis(client, "test:quota:")
+	second := NewQuotaService(store, 100, 3)
+	second.UseRedis(client, "test:quota:")
+	ctx := context.Background()
+
+	// An upload in progress on one instance counts on the other
+	uploading, err := first.Reserve(ctx, 7, 60)
+	require.NoError(t, err)
+	assert.Equal(t, int64(40), uploading.Usage.BytesRemaining)
+	rejected, err := second.Reserve(ctx, 7, 50)
+	assert.ErrorIs(t, err, ErrQuotaExceeded)
+	assert.Equal(t, int64(60), rejected.Usage.BytesUsed)
+	assert.Equal(t, today().Add(24*time.Hour), rejected.Usage.ResetsAt)
+
+	// A failed upload gives its quota back
+	first.Release(ctx, uploading)
+	uploading, err = second.Reserve(ctx, 7, 50)
+	require.NoError(t, err)
+	usage, err := second.Commit(ctx, uploading)
+	require.NoError(t, err)
+	assert.Equal(t, int64(50), usage.BytesUsed)
+	assert.Equal(t, int64(1), usage.FilesCount)
+	assert.Equal(t, int64(50), store.usage[7].BytesUsed)
+
+	usage, err = first.Usage(ctx, 7)
+	require.NoError(t, err)
+	assert.Equal(t, int64(50), usage.BytesUsed)
+	assert.Equal(t, int64(2), usage.FilesRemaining)
+
+	// Counters expire with the day
+	key := "test:quota:7:" + today().Format("2006-01-02")
+	assert.True(t, server.Exists(key))
+	assert.Greater(t, server.TTL(key), time.Duration(0))
+}
+
+func TestRedisQuotaStartsFromRecordedUsage(t *testing.T) {
+	client, server := fakes.NewRedisClient(t)
+	// Uploads committed while the database counters were used
+	store := &usageStore{usage: map[int32]db.UserUploadUsage{7: {UserID: 7, BytesUsed: 80, FilesCount: 1}}}
+	quota := NewQuotaService(store, 100, 3)
+	quota.UseRedis(client, "test:quota:")
+	ctx := context.Background()
+
+	usage, err := quota.Usage(ctx, 7)
+	require.NoError(t, err)
+	assert.Equal(t, int64(80), usage.BytesUsed)
+	_, err = quota.Reserve(ctx, 7, 30)
+	assert.ErrorIs(t, err, ErrQuotaExceeded)
+	reservation, err := quota.Reserve(ctx, 7, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(90), reservation.Usage.BytesUsed)
+	assert.Equal(t, int64(2), reservation.Usage.FilesCount)
+	_, err = quota.Commit(ctx, reservation)
+	require.NoError(t, err)
+
+	// Redis restarts and loses the counters of the day
+	server.FlushAll()
+	_, err = quota.Reserve(ctx, 7, 20)
+	assert.ErrorIs(t, err, ErrQuotaExceeded)
+	reservation, err = quota.Reserve(ctx, 7, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), reservation.Usage.BytesUsed)
+	assert.Equal(t, int64(3), reservation.Usage.FilesCount)
+	assert.Greater(t, server.TTL("test:quota:7:"+today().Format("2006-01-02")), time.Duration(0))
+}
+
+func TestQuotaFallsBackToDatabase(t *testing.T) {
+	client, server := fakes.NewRedisClient(t)
+	store := &usageStore{usage: map[int32]db.UserUploadUsage{7: {UserID: 7, BytesUsed: 90, FilesCount: 1}}}
+	quota := NewQuotaService(store, 100, 3)
+	quota.UseRedis(client, "test:quota:")
+	server.Close()
+	ctx := context.Background()
+
+	_, err := quota.Reserve(ctx, 7, 20)
+	assert.ErrorIs(t, err, ErrQuotaExceeded)
+	reservation, err := quota.Reserve(ctx, 7, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), reservation.Usage.BytesRemaining)
+	// The upload is counted in the database until it is released
+	_, err = quota.Reserve(ctx, 7, 5)
+	assert.ErrorIs(t, err, ErrQuotaExceeded)
+	quota.Release(ctx, reservation)
+	assert.Equal(t, int64(90), store.usage[7].BytesUsed)
+
+	reservation, err = quota.Reserve(ctx, 7, 10)
+	require.NoError(t, err)
+	usage, err := quota.Commit(ctx, reservation)
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), usage.BytesUsed)
+	assert.Equal(t, int64(2), usage.FilesCount)
+	assert.Equal(t, int64(100), store.usage[7].BytesUsed)
+}
+
+func TestDatabaseQuotaReservesAtomically(t *testing.T) {
+	store := &usageStore{usage: make(map[int32]db.UserUploadUsage)}
+	quota := NewQuotaService(store, 100, 0)
+	ctx := context.Background()
+
+	_, err := quota.Reserve(ctx, 7, 101)
+	assert.ErrorIs(t, err, ErrQuotaExceeded)
+
+	// Only the uploads that fit are let through, however they interleave
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	reserved := 0
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := quota.Reserve(ctx, 7, 30); err == nil {
+				mu.Lock()
+				reserved++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 3, reserved)
+	assert.Equal(t, int64(90), store.usage[7].BytesUsed)
+}
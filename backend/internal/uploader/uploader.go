@@ -0,0 +1,31 @@
+package uploader
+
+import (
+	"context"
+	"time"
+)
+
+// Uploader stores user media and returns delivery URLs. CloudinaryUploader
+// is the production implementation.
+type Uploader interface {
+	UploadImage(ctx context.Context, file interface{}, filename string) (string, error)
+	UploadAudio(ctx context.Context, file interface{}, filename string) (string, error)
+	// UploadPrivateAudio stores audio that is only delivered through signed URLs
+	UploadPrivateAudio(ctx context.Context, file interface{}, publicID string) error
+	SignedAudioURL(publicID string) (string, error)
+	// PlaybackURL signs the delivery URL of audio stored by UploadAudio so it
+	// stops working at expiresAt where the storage supports it
+	PlaybackURL(url string, expiresAt time.Time) (string, error)
+	// DeleteAudio deletes audio stored by UploadAudio, given its delivery URL
+	DeleteAudio(ctx context.Context, url string) error
+	// UploadPrivateImage stores an image that is only delivered through
+	// signed URLs
+	UploadPrivateImage(ctx context.Context, file interface{}, publicID string) error
+	// SignedImageURL signs the URL of an image stored by UploadPrivateImage
+	// so it stops working at expiresAt where the storage supports it
+	SignedImageURL(publicID string, expiresAt time.Time) (string, error)
+	// DeletePrivateImage deletes an image stored by UploadPrivateImage
+	DeletePrivateImage(ctx context.Context, publicID string) error
+}
+
+var _ Uploader = (*CloudinaryUploader)(nil)
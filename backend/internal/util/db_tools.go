@@ -0,0 +1,296 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"sync"
+
+	"github.com/toeic-app/internal/config"
+	"github.com/toeic-app/internal/logger"
+)
+
+// Names of the database tools
+const (
+	ToolPgDump    = "pg_dump"
+	ToolPsql      = "psql"
+	ToolPgRestore = "pg_restore"
+)
+
+// toolEnv is the environment the tools are run with that is passed on to
+// a container
+var toolEnv = []string{"PGPASSWORD", "PGCLIENTENCODING"}
+
+// DatabaseTools runs pg_dump, psql and pg_restore where they are
+// configured: installed locally, in a throwaway container or in a sidecar
+// container
+type DatabaseTools struct {
+	config config.DatabaseToolsConfig
+	// mounts are the absolute directories a docker mode container sees at
+	// the same path
+	mounts []string
+}
+
+// NewDatabaseTools creates the tools of cfg. dirs are the directories the
+// tools read and write files in, which are mounted into docker mode
+// containers.
+func NewDatabaseTools(cfg config.DatabaseToolsConfig, dirs ...string) *DatabaseTools {
+	if cfg.Mode == "" {
+		cfg.Mode = config.ToolsModeLocal
+	}
+	if cfg.DockerPath == "" {
+		cfg.DockerPath = "docker"
+	}
+	tools := &DatabaseTools{config: cfg}
+	for _, dir := range dirs {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			logger.Warn("Failed to resolve %s for the database tools: %v", dir, err)
+			continue
+		}
+		tools.mounts = append(tools.mounts, abs)
+	}
+	return tools
+}
+
+// Mode returns how the tools are run: local, docker or sidecar
+func (t *DatabaseTools) Mode() string {
+	return t.config.Mode
+}
+
+// Containerized reports whether the tools run in a container, which only
+// sees the files of the backup directory
+func (t *DatabaseTools) Containerized() bool {
+	return t.config.Mode == config.ToolsModeDocker || t.config.Mode == config.ToolsModeSidecar
+}
+
+// Path returns the path tool is run with: its configured path, else in
+// BinDir, else found in PATH or the usual install locations. In a
+// container it is run by name unless configured.
+func (t *DatabaseTools) Path(tool string) (string, error) {
+	var path string
+	switch tool {
+	case ToolPgDump:
+		path = t.config.PgDumpPath
+	case ToolPsql:
+		path = t.config.PsqlPath
+	case ToolPgRestore:
+		path = t.config.PgRestorePath
+	default:
+		return "", fmt.Errorf("unknown database tool %q", tool)
+	}
+	switch {
+	case path != "":
+		return path, nil
+	case t.Containerized():
+		return tool, nil
+	case t.config.BinDir != "":
+		return filepath.Join(t.config.BinDir, tool), nil
+	}
+
+	switch tool {
+	case ToolPgDump:
+		return GetPgDumpCommand()
+	case ToolPsql:
+		return GetPsqlCommand()
+	default:
+		return GetPgRestoreCommand()
+	}
+}
+
+// Command returns the command running tool with args. Its environment is
+// passed on to containers, so PGPASSWORD set on cmd.Env reaches the tool
+// without appearing in the arguments of docker.
+func (t *DatabaseTools) Command(ctx context.Context, tool string, args ...string) (*exec.Cmd, error) {
+	path, err := t.Path(tool)
+	if err != nil {
+		return nil, err
+	}
+	if !t.Containerized() {
+		return exec.CommandContext(ctx, path, args...), nil
+	}
+	return exec.CommandContext(ctx, t.config.DockerPath, append(t.containerArgs(path), args...)...), nil
+}
+
+// containerArgs returns the docker arguments running the tool at path
+func (t *DatabaseTools) containerArgs(path string) []string {
+	var args []string
+	if t.config.Mode == config.ToolsModeSidecar {
+		args = []string{"exec", "-i"}
+	} else {
+		args = []string{"run", "--rm", "-i"}
+		if t.config.Network != "" {
+			args = append(args, "--network", t.config.Network)
+		}
+		// Files written to the mounted directories belong to the server
+		if runtime.GOOS != "windows" {
+			args = append(args, "--user", fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid()))
+		}
+		for _, mount := range t.mounts {
+			args = append(args, "--volume", mount+":"+mount)
+		}
+		// Relative paths resolve as they do for the server
+		if wd, err := os.Getwd(); err == nil {
+			args = append(args, "--workdir", wd)
+		}
+	}
+	for _, name := range toolEnv {
+		args = append(args, "--env", name)
+	}
+	if t.config.Mode == config.ToolsModeSidecar {
+		args = append(args, t.config.Container)
+	} else {
+		args = append(args, t.config.Image)
+	}
+	return append(args, path)
+}
+
+// PostgresVersion is a PostgreSQL release, of the server or of a tool
+type PostgresVersion struct {
+	Major int
+	// Minor is part of the major version before PostgreSQL 10, e.g. 9.6
+	Minor int
+}
+
+// postgresVersionPattern matches the version of server_version and of the
+// --version output of the tools, e.g. "pg_dump (PostgreSQL) 16.4"
+var postgresVersionPattern = regexp.MustCompile(`(?:^|\(PostgreSQL\) )(\d+)(?:\.(\d+))?`)
+
+// ParsePostgresVersion reads a PostgreSQL version like 16.4, 17beta1 or
+// "16.4 (Debian 16.4-1.pgdg120+1)"
+func ParsePostgresVersion(version string) (PostgresVersion, error) {
+	match := postgresVersionPattern.FindStringSubmatch(version)
+	if match == nil {
+		return PostgresVersion{}, fmt.Errorf("unexpected PostgreSQL version %q", version)
+	}
+	var v PostgresVersion
+	v.Major, _ = strconv.Atoi(match[1])
+	if match[2] != "" {
+		v.Minor, _ = strconv.Atoi(match[2])
+	}
+	return v, nil
+}
+
+// Before reports whether v is an older major version than other
+func (v PostgresVersion) Before(other PostgresVersion) bool {
+	if v.Major != other.Major || v.Major >= 10 {
+		return v.Major < other.Major
+	}
+	return v.Minor < other.Minor
+}
+
+// String returns the major version, e.g. 16 or 9.6
+func (v PostgresVersion) String() string {
+	if v.Major < 10 {
+		return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+	}
+	return strconv.Itoa(v.Major)
+}
+
+// toolVersions caches the versions of the tools by how they are run
+var toolVersions sync.Map
+
+// Version returns the PostgreSQL version of tool, running it once
+func (t *DatabaseTools) Version(ctx context.Context, tool string) (PostgresVersion, error) {
+	path, err := t.Path(tool)
+	if err != nil {
+		return PostgresVersion{}, err
+	}
+	key := t.config.Mode + "|" + t.config.Image + "|" + t.config.Container + "|" + path
+	if version, ok := toolVersions.Load(key); ok {
+		return version.(PostgresVersion), nil
+	}
+
+	cmd, err := t.Command(ctx, tool, "--version")
+	if err != nil {
+		return PostgresVersion{}, err
+	}
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return PostgresVersion{}, fmt.Errorf("%s is not working: %w, output: %s", path, err, output)
+	}
+	version, err := ParsePostgresVersion(string(output))
+	if err != nil {
+		return PostgresVersion{}, fmt.Errorf("%s: %w", path, err)
+	}
+	toolVersions.Store(key, version)
+	return version, nil
+}
+
+// pathVariables are the variables setting the path of each tool
+var pathVariables = map[string]string{
+	ToolPgDump:    "BACKUP_PG_DUMP_PATH",
+	ToolPsql:      "BACKUP_PSQL_PATH",
+	ToolPgRestore: "BACKUP_PG_RESTORE_PATH",
+}
+
+// CheckToolVersion tells whether tool at version works with a server at
+// server. pg_dump refuses to dump newer servers, pg_restore may not read
+// archives pg_dump made of them, and older psql may not run the plain
+// dumps.
+func CheckToolVersion(tool string, version, server PostgresVersion) error {
+	if !version.Before(server) {
+		return nil
+	}
+	return fmt.Errorf("%s %s is older than the PostgreSQL %s server; set %s, BACKUP_TOOLS_BIN_DIR or BACKUP_TOOLS_IMAGE to version %s or later",
+		tool, version, server, pathVariables[tool], server)
+}
+
+// CheckToolVersionFor checks tool against the server_version of a server
+func (t *DatabaseTools) CheckToolVersionFor(ctx context.Context, tool, serverVersion string) error {
+	server, err := ParsePostgresVersion(serverVersion)
+	if err != nil {
+		return err
+	}
+	version, err := t.Version(ctx, tool)
+	if err != nil {
+		return err
+	}
+	return CheckToolVersion(tool, version, server)
+}
+
+// ToolStatus is what Status found out about a tool
+type ToolStatus struct {
+	Tool    string `json:"tool"`
+	Path    string `json:"path,omitempty"`
+	Version string `json:"version,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Status checks every tool, against serverVersion when set
+func (t *DatabaseTools) Status(ctx context.Context, serverVersion string) ([]ToolStatus, error) {
+	var server *PostgresVersion
+	if serverVersion != "" {
+		parsed, err := ParsePostgresVersion(serverVersion)
+		if err != nil {
+			return nil, err
+		}
+		server = &parsed
+	}
+
+	var statuses []ToolStatus
+	var errs []error
+	for _, tool := range []string{ToolPgDump, ToolPsql, ToolPgRestore} {
+		status := ToolStatus{Tool: tool}
+		status.Path, _ = t.Path(tool)
+		version, err := t.Version(ctx, tool)
+		if err == nil {
+			status.Version = version.String()
+			if server != nil {
+				err = CheckToolVersion(tool, version, *server)
+			}
+		}
+		if err != nil {
+			status.Error = err.Error()
+			errs = append(errs, err)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, errors.Join(errs...)
+}
@@ -0,0 +1,76 @@
+package util
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toeic-app/internal/config"
+)
+
+func TestParsePostgresVersion(t *testing.T) {
+	for input, want := range map[string]PostgresVersion{
+		"pg_dump (PostgreSQL) 16.4\n":                      {Major: 16, Minor: 4},
+		"psql (PostgreSQL) 17.2 (Debian 17.2-1.pgdg120+1)": {Major: 17, Minor: 2},
+		"16.4 (Debian 16.4-1.pgdg120+1)":                   {Major: 16, Minor: 4},
+		"18beta1":                                          {Major: 18},
+		"pg_restore (PostgreSQL) 9.6.24":                   {Major: 9, Minor: 6},
+	} {
+		got, err := ParsePostgresVersion(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, want, got, input)
+	}
+
+	_, err := ParsePostgresVersion("docker: Error response from daemon")
+	assert.Error(t, err)
+}
+
+func TestCheckToolVersion(t *testing.T) {
+	v := func(major, minor int) PostgresVersion { return PostgresVersion{Major: major, Minor: minor} }
+
+	assert.NoError(t, CheckToolVersion(ToolPgDump, v(16, 4), v(16, 1)))
+	assert.NoError(t, CheckToolVersion(ToolPgDump, v(17, 0), v(16, 9)))
+	assert.NoError(t, CheckToolVersion(ToolPsql, v(10, 0), v(9, 6)))
+
+	err := CheckToolVersion(ToolPgDump, v(15, 8), v(16, 4))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "pg_dump 15 is older than the PostgreSQL 16 server")
+	assert.Contains(t, err.Error(), "BACKUP_PG_DUMP_PATH")
+	assert.Error(t, CheckToolVersion(ToolPgRestore, v(9, 5), v(9, 6)))
+}
+
+func TestDatabaseToolsCommand(t *testing.T) {
+	dir := t.TempDir()
+
+	local := NewDatabaseTools(config.DatabaseToolsConfig{PsqlPath: "/opt/pg/bin/psql", BinDir: "/usr/lib/postgresql/16/bin"})
+	cmd, err := local.Command(context.Background(), ToolPsql, "--version")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/opt/pg/bin/psql", "--version"}, cmd.Args)
+	path, err := local.Path(ToolPgDump)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("/usr/lib/postgresql/16/bin", "pg_dump"), path)
+
+	docker := NewDatabaseTools(config.DatabaseToolsConfig{
+		Mode:    config.ToolsModeDocker,
+		Image:   "postgres:16-alpine",
+		Network: "host",
+	}, dir)
+	cmd, err = docker.Command(context.Background(), ToolPgDump, "--file="+filepath.Join(dir, "backup.sql"), "toeic")
+	require.NoError(t, err)
+	assert.Equal(t, "docker", cmd.Args[0])
+	assert.Equal(t, []string{"run", "--rm", "-i", "--network", "host"}, cmd.Args[1:6])
+	assert.Contains(t, cmd.Args, dir+":"+dir)
+	// The password is passed on from the environment, never as an argument
+	assert.Contains(t, cmd.Args, "PGPASSWORD")
+	assert.Equal(t, []string{"postgres:16-alpine", "pg_dump", "--file=" + filepath.Join(dir, "backup.sql"), "toeic"}, cmd.Args[len(cmd.Args)-4:])
+
+	sidecar := NewDatabaseTools(config.DatabaseToolsConfig{Mode: config.ToolsModeSidecar, Container: "pg-tools", PgRestorePath: "/usr/lib/postgresql/16/bin/pg_restore"})
+	cmd, err = sidecar.Command(context.Background(), ToolPgRestore, "--list")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"docker", "exec", "-i", "--env", "PGPASSWORD", "--env", "PGCLIENTENCODING", "pg-tools", "/usr/lib/postgresql/16/bin/pg_restore", "--list"}, cmd.Args)
+
+	_, err = sidecar.Command(context.Background(), "pg_dumpall")
+	assert.Error(t, err)
+}
@@ -124,53 +124,37 @@ func GetPsqlCommand() (string, error) {
 	return filepath.Join(binDir, "psql"), nil
 }
 
-// CheckPgDumpAvailable checks if pg_dump command is available
-func CheckPgDumpAvailable() error {
-	pgDumpCmd, err := GetPgDumpCommand()
-	if err != nil {
-		return fmt.Errorf("pg_dump command not found: %w", err)
-	}
-
-	cmd := exec.Command(pgDumpCmd, "--version")
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("pg_dump command not working: %w", err)
+// GetPgRestoreCommand returns the command to run pg_restore with the appropriate path
+func GetPgRestoreCommand() (string, error) {
+	// First check if pg_restore is directly in PATH
+	_, err := exec.LookPath("pg_restore")
+	if err == nil {
+		return "pg_restore", nil
 	}
 
-	logger.Info("pg_dump command available: %s", pgDumpCmd)
-	return nil
-}
-
-// CheckPsqlAvailable checks if psql command is available
-func CheckPsqlAvailable() error {
-	psqlCmd, err := GetPsqlCommand()
+	// If not in PATH, try to find the bin directory
+	binDir, err := FindPostgreSQLBinPath()
 	if err != nil {
-		return fmt.Errorf("psql command not found: %w", err)
-	}
-
-	cmd := exec.Command(psqlCmd, "--version")
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("psql command not working: %w", err)
+		return "", err
 	}
 
-	logger.Info("psql command available: %s", psqlCmd)
-	return nil
+	return filepath.Join(binDir, "pg_restore"), nil
 }
 
-// CheckDatabaseTools checks if both pg_dump and psql are available
-func CheckDatabaseTools() error {
-	// Check if pg_dump is available
-	if err := CheckPgDumpAvailable(); err != nil {
-		logger.Error("pg_dump not available: %v", err)
-		return err
+// CheckDatabaseTools checks that pg_dump, psql and pg_restore work, and
+// that they handle the server when serverVersion is set
+func CheckDatabaseTools(ctx context.Context, tools *DatabaseTools, serverVersion string) error {
+	statuses, err := tools.Status(ctx, serverVersion)
+	for _, status := range statuses {
+		if status.Error == "" {
+			logger.Info("%s %s available: %s (%s)", status.Tool, status.Version, status.Path, tools.Mode())
+		}
 	}
-
-	// Check if psql is available
-	if err := CheckPsqlAvailable(); err != nil {
-		logger.Error("psql not available: %v", err)
+	if err != nil {
 		return err
 	}
 
-	logger.Info("Database tools (pg_dump, psql) are available")
+	logger.Info("Database tools (pg_dump, psql, pg_restore) are available")
 	return nil
 }
 
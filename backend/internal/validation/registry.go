@@ -0,0 +1,219 @@
+// Package validation is the registry of the rules request DTOs are
+// validated with. Custom rules are used through binding tags like the
+// validator's own, e.g. `binding:"required,toeic_score"`, and validation
+// errors are turned into per-field messages in the language of the request.
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/toeic-app/internal/i18n"
+)
+
+// Rule is a custom validation tag with its messages. Messages may name the
+// field with {field} and the tag parameter with {param}.
+type Rule struct {
+	Tag      string
+	Func     validator.Func
+	Messages map[i18n.SupportedLanguage]string
+}
+
+// rules are the custom validation tags request DTOs can use
+var rules = []Rule{
+	{
+		Tag:  "valid_email",
+		Func: validEmail,
+		Messages: map[i18n.SupportedLanguage]string{
+			i18n.LanguageEnglish:    "{field} must be a valid email address",
+			i18n.LanguageVietnamese: "{field} phải là địa chỉ email hợp lệ",
+		},
+	},
+	{
+		Tag:  "strong_password",
+		Func: strongPassword,
+		Messages: map[i18n.SupportedLanguage]string{
+			i18n.LanguageEnglish:    "{field} must have at least 8 characters with upper and lower case letters, a digit and a special character",
+			i18n.LanguageVietnamese: "{field} phải có ít nhất 8 ký tự gồm chữ hoa, chữ thường, chữ số và ký tự đặc biệt",
+		},
+	},
+	{
+		Tag:  "toeic_score",
+		Func: toeicScore,
+		Messages: map[i18n.SupportedLanguage]string{
+			i18n.LanguageEnglish:    "{field} must be a TOEIC score from 0 to 990",
+			i18n.LanguageVietnamese: "{field} phải là điểm TOEIC từ 0 đến 990",
+		},
+	},
+	{
+		Tag:  "language_code",
+		Func: languageCode,
+		Messages: map[i18n.SupportedLanguage]string{
+			i18n.LanguageEnglish:    "{field} must be a language code such as \"vi\" or \"en-US\"",
+			i18n.LanguageVietnamese: "{field} phải là mã ngôn ngữ, ví dụ \"vi\" hoặc \"en-US\"",
+		},
+	},
+	{
+		Tag:  "difficulty_level",
+		Func: difficultyLevel,
+		Messages: map[i18n.SupportedLanguage]string{
+			i18n.LanguageEnglish:    "{field} must be a difficulty level from 1 to 6, or beginner, intermediate or advanced",
+			i18n.LanguageVietnamese: "{field} phải là mức độ khó từ 1 đến 6, hoặc beginner, intermediate hoặc advanced",
+		},
+	},
+	{
+		Tag:  "safe_filename",
+		Func: safeFilename,
+		Messages: map[i18n.SupportedLanguage]string{
+			i18n.LanguageEnglish:    "{field} must be a file name of letters, digits, dots, dashes and underscores",
+			i18n.LanguageVietnamese: "{field} phải là tên tệp chỉ gồm chữ cái, chữ số, dấu chấm, gạch ngang và gạch dưới",
+		},
+	},
+}
+
+// builtinMessages are the messages of the validator's own tags used by
+// request DTOs. Tags without a message fall back to "invalid".
+var builtinMessages = map[string]map[i18n.SupportedLanguage]string{
+	"required": {
+		i18n.LanguageEnglish:    "{field} is required",
+		i18n.LanguageVietnamese: "{field} là bắt buộc",
+	},
+	"min": {
+		i18n.LanguageEnglish:    "{field} must be at least {param}",
+		i18n.LanguageVietnamese: "{field} phải lớn hơn hoặc bằng {param}",
+	},
+	"max": {
+		i18n.LanguageEnglish:    "{field} must be at most {param}",
+		i18n.LanguageVietnamese: "{field} phải nhỏ hơn hoặc bằng {param}",
+	},
+	"min_length": {
+		i18n.LanguageEnglish:    "{field} must have at least {param} characters or items",
+		i18n.LanguageVietnamese: "{field} phải có ít nhất {param} ký tự hoặc phần tử",
+	},
+	"max_length": {
+		i18n.LanguageEnglish:    "{field} must have at most {param} characters or items",
+		i18n.LanguageVietnamese: "{field} chỉ được có tối đa {param} ký tự hoặc phần tử",
+	},
+	"oneof": {
+		i18n.LanguageEnglish:    "{field} must be one of: {param}",
+		i18n.LanguageVietnamese: "{field} phải là một trong các giá trị: {param}",
+	},
+	"email": {
+		i18n.LanguageEnglish:    "{field} must be a valid email address",
+		i18n.LanguageVietnamese: "{field} phải là địa chỉ email hợp lệ",
+	},
+	"url": {
+		i18n.LanguageEnglish:    "{field} must be a valid URL",
+		i18n.LanguageVietnamese: "{field} phải là URL hợp lệ",
+	},
+	"invalid": {
+		i18n.LanguageEnglish:    "{field} is invalid",
+		i18n.LanguageVietnamese: "{field} không hợp lệ",
+	},
+}
+
+// messageKey is the i18n key of a tag's message
+func messageKey(tag string) string {
+	return "validation_" + tag
+}
+
+func init() {
+	// The messages live in the i18n catalog so message files can override
+	// them
+	for _, rule := range rules {
+		for lang, message := range rule.Messages {
+			i18n.AddGlobalMessage(lang, messageKey(rule.Tag), message)
+		}
+	}
+	for tag, messages := range builtinMessages {
+		for lang, message := range messages {
+			i18n.AddGlobalMessage(lang, messageKey(tag), message)
+		}
+	}
+}
+
+// Rules returns the custom validation tags
+func Rules() []Rule {
+	return append([]Rule(nil), rules...)
+}
+
+// fieldName names a field as clients send it: by its json, form or uri
+// name
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form", "uri"} {
+		name := strings.Split(field.Tag.Get(tag), ",")[0]
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// Register adds the custom rules to a validator, and has it name fields in
+// errors as clients send them
+func Register(v *validator.Validate) error {
+	v.RegisterTagNameFunc(fieldName)
+	for _, rule := range rules {
+		if err := v.RegisterValidation(rule.Tag, rule.Func); err != nil {
+			return fmt.Errorf("failed to register %s validation: %w", rule.Tag, err)
+		}
+	}
+	return nil
+}
+
+// RegisterWithGin adds the custom rules to the validator gin binds
+// requests with
+func RegisterWithGin() error {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return errors.New("gin does not validate with go-playground/validator")
+	}
+	return Register(v)
+}
+
+// fieldPath names the field of an error by its path within the request,
+// e.g. "answers[0].question_id"
+func fieldPath(fieldErr validator.FieldError) string {
+	namespace := fieldErr.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return fieldErr.Field()
+}
+
+// message returns the message of a failed rule in a language
+func message(fieldErr validator.FieldError, lang i18n.SupportedLanguage) string {
+	tag := fieldErr.Tag()
+	if tag == "min" || tag == "max" {
+		switch fieldErr.Kind() {
+		case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+			tag += "_length"
+		}
+	}
+	text, ok := i18n.GetI18n().Lookup(lang, messageKey(tag))
+	if !ok {
+		text, _ = i18n.GetI18n().Lookup(lang, messageKey("invalid"))
+	}
+	return strings.NewReplacer("{field}", fieldErr.Field(), "{param}", fieldErr.Param()).Replace(text)
+}
+
+// FieldErrors returns a message for each field that failed validation, in
+// the given language. It returns nil when err is not a validation error.
+func FieldErrors(err error, lang i18n.SupportedLanguage) map[string]string {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return nil
+	}
+	fields := make(map[string]string, len(validationErrs))
+	for _, fieldErr := range validationErrs {
+		fields[fieldPath(fieldErr)] = message(fieldErr, lang)
+	}
+	return fields
+}
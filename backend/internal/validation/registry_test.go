@@ -0,0 +1,102 @@
+package validation
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toeic-app/internal/i18n"
+)
+
+func newValidator(t *testing.T) *validator.Validate {
+	v := validator.New()
+	require.NoError(t, Register(v))
+	return v
+}
+
+func TestCustomRules(t *testing.T) {
+	v := newValidator(t)
+	cases := []struct {
+		tag   string
+		value interface{}
+		valid bool
+	}{
+		{"toeic_score", 990, true},
+		{"toeic_score", "785.5", true},
+		{"toeic_score", 995, false},
+		{"toeic_score", "-5", false},
+		{"toeic_score", "high", false},
+		{"difficulty_level", int16(6), true},
+		{"difficulty_level", "intermediate", true},
+		{"difficulty_level", "3", true},
+		{"difficulty_level", 7, false},
+		{"difficulty_level", "2.5", false},
+		{"difficulty_level", "expert", false},
+		{"language_code", "vi", true},
+		{"language_code", "en-US", true},
+		{"language_code", "english", false},
+		{"language_code", "EN", false},
+		{"safe_filename", "backup_2026-10-16.sql", true},
+		{"safe_filename", "../etc/passwd", false},
+		{"safe_filename", ".env", false},
+		{"safe_filename", "a..sql", false},
+		{"safe_filename", "dir/backup.sql", false},
+		{"strong_password", "Secret#123", true},
+		{"strong_password", "secret123", false},
+		{"valid_email", "learner@example.com", true},
+		{"valid_email", "learner@", false},
+	}
+	for _, c := range cases {
+		err := v.Var(c.value, c.tag)
+		assert.Equal(t, c.valid, err == nil, "%s %v", c.tag, c.value)
+	}
+}
+
+func TestFieldErrorsAreLocalized(t *testing.T) {
+	v := newValidator(t)
+	type answer struct {
+		QuestionID int32 `json:"question_id" validate:"required"`
+	}
+	type submitRequest struct {
+		Score    *string  `json:"score" validate:"omitempty,toeic_score"`
+		Name     string   `json:"name" validate:"min=3"`
+		Level    int32    `form:"level" validate:"min=1"`
+		Email    string   `json:"email" validate:"email"`
+		Answers  []answer `json:"answers" validate:"dive"`
+		Internal string   `json:"-" validate:"uuid"`
+	}
+	request := submitRequest{
+		Score:    func() *string { s := "1000"; return &s }(),
+		Name:     "ab",
+		Email:    "not-an-email",
+		Answers:  []answer{{}},
+		Internal: "x",
+	}
+	err := v.Struct(request)
+	require.Error(t, err)
+
+	assert.Equal(t, map[string]string{
+		"score":                  "score must be a TOEIC score from 0 to 990",
+		"name":                   "name must have at least 3 characters or items",
+		"level":                  "level must be at least 1",
+		"email":                  "email must be a valid email address",
+		"answers[0].question_id": "question_id is required",
+		"Internal":               "Internal is invalid",
+	}, FieldErrors(err, i18n.LanguageEnglish))
+
+	vietnamese := FieldErrors(err, i18n.LanguageVietnamese)
+	assert.Equal(t, "score phải là điểm TOEIC từ 0 đến 990", vietnamese["score"])
+	assert.Equal(t, "level phải lớn hơn hoặc bằng 1", vietnamese["level"])
+
+	assert.Nil(t, FieldErrors(errors.New("unexpected EOF"), i18n.LanguageEnglish))
+}
+
+func TestRulesHaveMessagesInEveryLanguage(t *testing.T) {
+	for _, rule := range Rules() {
+		for _, lang := range i18n.GetI18n().GetAllLanguages() {
+			assert.NotEmpty(t, rule.Messages[lang], "%s has no %s message", rule.Tag, lang)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package validation
+
+import (
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/toeic-app/internal/util"
+)
+
+const (
+	// MaxTOEICScore is the highest total TOEIC Listening & Reading score
+	MaxTOEICScore = 990
+	// MinDifficulty and MaxDifficulty bound the difficulty scale shared by
+	// words and questions
+	MinDifficulty = 1
+	MaxDifficulty = 6
+	// maxFilenameLength is the longest file name most file systems accept
+	maxFilenameLength = 255
+)
+
+var (
+	// languageCodePattern matches an ISO 639-1 code with an optional region,
+	// such as "vi" or "en-US"
+	languageCodePattern = regexp.MustCompile(`^[a-z]{2}(-[A-Z]{2})?$`)
+	safeFilenamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+)
+
+// number reads a numeric field, or a string holding a number
+func number(field reflect.Value) (float64, bool) {
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(field.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(field.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return field.Float(), true
+	case reflect.String:
+		value, err := strconv.ParseFloat(strings.TrimSpace(field.String()), 64)
+		return value, err == nil
+	}
+	return 0, false
+}
+
+// toeicScore accepts a score from 0 to 990, given as a number or a numeric
+// string
+func toeicScore(fl validator.FieldLevel) bool {
+	score, ok := number(fl.Field())
+	return ok && score >= 0 && score <= MaxTOEICScore
+}
+
+// difficultyNames are the named difficulties text fields may use instead
+// of a level
+var difficultyNames = map[string]bool{"beginner": true, "intermediate": true, "advanced": true}
+
+// difficultyLevel accepts a whole difficulty from MinDifficulty to
+// MaxDifficulty, or for text fields one of difficultyNames
+func difficultyLevel(fl validator.FieldLevel) bool {
+	if fl.Field().Kind() == reflect.String && difficultyNames[strings.ToLower(fl.Field().String())] {
+		return true
+	}
+	level, ok := number(fl.Field())
+	return ok && level == float64(int64(level)) && level >= MinDifficulty && level <= MaxDifficulty
+}
+
+// languageCode accepts a lowercase ISO 639-1 code with an optional
+// uppercase region
+func languageCode(fl validator.FieldLevel) bool {
+	return fl.Field().Kind() == reflect.String && languageCodePattern.MatchString(fl.Field().String())
+}
+
+// safeFilename accepts a plain file name: letters, digits, dots, dashes and
+// underscores, not starting with a dot and without "..", so it cannot leave
+// the directory it is joined to
+func safeFilename(fl validator.FieldLevel) bool {
+	if fl.Field().Kind() != reflect.String {
+		return false
+	}
+	name := fl.Field().String()
+	return len(name) <= maxFilenameLength && safeFilenamePattern.MatchString(name) && !strings.Contains(name, "..")
+}
+
+func validEmail(fl validator.FieldLevel) bool {
+	return fl.Field().Kind() == reflect.String && util.IsValidEmail(fl.Field().String())
+}
+
+func strongPassword(fl validator.FieldLevel) bool {
+	return fl.Field().Kind() == reflect.String && util.IsStrongPassword(fl.Field().String())
+}
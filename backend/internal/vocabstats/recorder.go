@@ -0,0 +1,271 @@
+// Package vocabstats keeps the per-word statistics of learners: attempts,
+// correct answers, response time and mastery level. Learning attempts are
+// buffered and written in batches, one transaction per flush, so a session
+// writes the statistics of a word once per flush instead of on every answer.
+package vocabstats
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/logger"
+)
+
+// Mastery levels run from MinMastery to MaxMastery
+const (
+	MinMastery = 1
+	MaxMastery = 10
+)
+
+// MaxPending is how many words may wait for a flush before one is started
+// early
+const MaxPending = 500
+
+// fastResponse is the response time under which an easy correct answer
+// counts double
+const fastResponse = 5 * time.Second
+
+// Attempt is an answered learning attempt
+type Attempt struct {
+	UserID  int32
+	WordID  int32
+	Correct bool
+	// ResponseTimeMs is how long the learner took to answer, when reported
+	ResponseTimeMs *int32
+	// DifficultyRating is the learner's rating, from 1 (easy) to 5 (hard)
+	DifficultyRating *int32
+	At               time.Time
+}
+
+// masteryChange is how much an attempt moves the mastery level: a wrong
+// answer costs two levels, a correct one gains a level, or two when the
+// learner rated the word easy and answered quickly
+func (a Attempt) masteryChange() int32 {
+	if !a.Correct {
+		return -2
+	}
+	easy := a.DifficultyRating != nil && *a.DifficultyRating <= 2
+	fast := a.ResponseTimeMs == nil || time.Duration(*a.ResponseTimeMs)*time.Millisecond <= fastResponse
+	if easy && fast {
+		return 2
+	}
+	return 1
+}
+
+// Mastery applies attempts, oldest first, to a mastery level
+func Mastery(level int32, attempts []Attempt) int32 {
+	for _, attempt := range attempts {
+		level += attempt.masteryChange()
+		level = min(max(level, MinMastery), MaxMastery)
+	}
+	return level
+}
+
+type wordKey struct {
+	userID int32
+	wordID int32
+}
+
+// TxFunc runs fn in a transaction, committing when it returns nil and rolling
+// back otherwise
+type TxFunc func(ctx context.Context, fn func(db.Querier) error) error
+
+// Recorder buffers learning attempts and writes them to vocabulary_stats.
+// Statistics lag by at most one flush interval.
+type Recorder struct {
+	inTx TxFunc
+
+	mu      sync.Mutex
+	pending map[wordKey][]Attempt
+	// flushing is held while a batch is written, so batches of the same
+	// word are applied in order
+	flushing sync.Mutex
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewRecorder creates a recorder writing batches on conn
+func NewRecorder(conn *sql.DB) *Recorder {
+	return newRecorder(func(ctx context.Context, fn func(db.Querier) error) error {
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		if err := fn(db.New(tx)); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+		return tx.Commit()
+	})
+}
+
+func newRecorder(inTx TxFunc) *Recorder {
+	return &Recorder{inTx: inTx, pending: make(map[wordKey][]Attempt)}
+}
+
+// Record queues an attempt for the next flush. It does not block on the
+// database; a flush is started early once MaxPending words are waiting.
+func (r *Recorder) Record(attempt Attempt) {
+	if attempt.At.IsZero() {
+		attempt.At = time.Now()
+	}
+	k := wordKey{userID: attempt.UserID, wordID: attempt.WordID}
+
+	r.mu.Lock()
+	r.pending[k] = append(r.pending[k], attempt)
+	full := len(r.pending) >= MaxPending
+	r.mu.Unlock()
+
+	if full {
+		go func() {
+			if err := r.Flush(context.Background()); err != nil {
+				logger.Warn("Failed to flush vocabulary statistics: %v", err)
+			}
+		}()
+	}
+}
+
+// Start flushes attempts to the database every interval
+func (r *Recorder) Start(interval time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stop != nil {
+		return
+	}
+	r.stop = make(chan struct{})
+	r.done = make(chan struct{})
+
+	go func(stop, done chan struct{}) {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := r.Flush(context.Background()); err != nil {
+					logger.Warn("Failed to flush vocabulary statistics: %v", err)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}(r.stop, r.done)
+}
+
+// Stop stops the flush loop and writes the remaining attempts
+func (r *Recorder) Stop() {
+	r.mu.Lock()
+	stop, done := r.stop, r.done
+	r.stop, r.done = nil, nil
+	r.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := r.Flush(ctx); err != nil {
+		logger.Warn("Failed to flush vocabulary statistics on shutdown: %v", err)
+	}
+}
+
+// Flush writes the pending attempts in one transaction: each word's row is
+// locked, its counters are added to and its mastery level is moved by the
+// attempts in the order they were made. Words are written ordered by user
+// and word so that concurrent flushes of several instances cannot deadlock.
+// When the transaction fails the attempts are kept for the next flush.
+func (r *Recorder) Flush(ctx context.Context) error {
+	r.flushing.Lock()
+	defer r.flushing.Unlock()
+
+	r.mu.Lock()
+	batch := r.pending
+	r.pending = make(map[wordKey][]Attempt)
+	r.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	keys := make([]wordKey, 0, len(batch))
+	for k := range batch {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].userID != keys[j].userID {
+			return keys[i].userID < keys[j].userID
+		}
+		return keys[i].wordID < keys[j].wordID
+	})
+
+	err := r.inTx(ctx, func(store db.Querier) error {
+		for _, k := range keys {
+			if err := upsert(ctx, store, k, batch[k]); err != nil {
+				return fmt.Errorf("failed to update statistics of word %d for user %d: %w", k.wordID, k.userID, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		// Keep the attempts, ahead of those recorded since, for the next flush
+		r.mu.Lock()
+		for k, attempts := range batch {
+			r.pending[k] = append(attempts, r.pending[k]...)
+		}
+		r.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// upsert adds the attempts at a word to its statistics
+func upsert(ctx context.Context, store db.Querier, k wordKey, attempts []Attempt) error {
+	level := int32(MinMastery)
+	current, err := store.GetVocabularyStatsForUpdate(ctx, db.GetVocabularyStatsForUpdateParams{UserID: k.userID, WordID: k.wordID})
+	switch {
+	case err == nil:
+		if current.MasteryLevel.Valid {
+			level = current.MasteryLevel.Int32
+		}
+	case !errors.Is(err, sql.ErrNoRows):
+		return err
+	}
+
+	var correct int32
+	var responseTime int64
+	last := attempts[0].At
+	for _, attempt := range attempts {
+		if attempt.Correct {
+			correct++
+		}
+		if attempt.ResponseTimeMs != nil && *attempt.ResponseTimeMs > 0 {
+			responseTime += int64(*attempt.ResponseTimeMs)
+		}
+		if attempt.At.After(last) {
+			last = attempt.At
+		}
+	}
+	if current.LastAttemptAt.Valid && current.LastAttemptAt.Time.After(last) {
+		last = current.LastAttemptAt.Time
+	}
+
+	_, err = store.CreateOrUpdateVocabularyStats(ctx, db.CreateOrUpdateVocabularyStatsParams{
+		UserID:              k.userID,
+		WordID:              k.wordID,
+		TotalAttempts:       sql.NullInt32{Int32: int32(len(attempts)), Valid: true},
+		CorrectAttempts:     sql.NullInt32{Int32: correct, Valid: true},
+		TotalResponseTimeMs: sql.NullInt64{Int64: responseTime, Valid: true},
+		MasteryLevel:        sql.NullInt32{Int32: Mastery(level, attempts), Valid: true},
+		LastAttemptAt:       sql.NullTime{Time: last, Valid: true},
+	})
+	return err
+}
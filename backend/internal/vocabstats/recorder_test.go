@@ -0,0 +1,101 @@
+package vocabstats
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+// fakeStore holds the statistics row of word 1 for user 7
+type fakeStore struct {
+	db.Querier
+	locked  []int32
+	written []db.CreateOrUpdateVocabularyStatsParams
+	fail    bool
+}
+
+func (s *fakeStore) GetVocabularyStatsForUpdate(_ context.Context, arg db.GetVocabularyStatsForUpdateParams) (db.VocabularyStat, error) {
+	s.locked = append(s.locked, arg.WordID)
+	if arg.UserID != 7 || arg.WordID != 1 {
+		return db.VocabularyStat{}, sql.ErrNoRows
+	}
+	return db.VocabularyStat{
+		UserID:        7,
+		WordID:        1,
+		MasteryLevel:  sql.NullInt32{Int32: 9, Valid: true},
+		LastAttemptAt: sql.NullTime{Time: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+	}, nil
+}
+
+func (s *fakeStore) CreateOrUpdateVocabularyStats(_ context.Context, arg db.CreateOrUpdateVocabularyStatsParams) (db.VocabularyStat, error) {
+	if s.fail {
+		return db.VocabularyStat{}, errors.New("connection lost")
+	}
+	s.written = append(s.written, arg)
+	return db.VocabularyStat{}, nil
+}
+
+func TestMastery(t *testing.T) {
+	ms := func(v int32) *int32 { return &v }
+	easy, hard := ms(1), ms(5)
+
+	assert.Equal(t, int32(2), Mastery(1, []Attempt{{Correct: true}}))
+	assert.Equal(t, int32(3), Mastery(1, []Attempt{{Correct: true, DifficultyRating: easy, ResponseTimeMs: ms(1200)}}))
+	assert.Equal(t, int32(2), Mastery(1, []Attempt{{Correct: true, DifficultyRating: easy, ResponseTimeMs: ms(9000)}}))
+	assert.Equal(t, int32(2), Mastery(1, []Attempt{{Correct: true, DifficultyRating: hard}}))
+
+	// Levels stay between 1 and 10 after every attempt
+	assert.Equal(t, int32(1), Mastery(2, []Attempt{{Correct: false}}))
+	assert.Equal(t, int32(8), Mastery(9, []Attempt{{Correct: true}, {Correct: true}, {Correct: false}}))
+}
+
+func TestFlushBatchesAttemptsPerWord(t *testing.T) {
+	store := &fakeStore{}
+	recorder := newRecorder(func(_ context.Context, fn func(db.Querier) error) error { return fn(store) })
+	at := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	ms := func(v int32) *int32 { return &v }
+
+	recorder.Record(Attempt{UserID: 7, WordID: 3, Correct: false, At: at})
+	recorder.Record(Attempt{UserID: 7, WordID: 1, Correct: true, ResponseTimeMs: ms(1500), At: at})
+	recorder.Record(Attempt{UserID: 7, WordID: 1, Correct: true, ResponseTimeMs: ms(2500), At: at.Add(time.Minute)})
+	recorder.Record(Attempt{UserID: 7, WordID: 1, Correct: false, At: at.Add(2 * time.Minute)})
+
+	require.NoError(t, recorder.Flush(context.Background()))
+	assert.Equal(t, []int32{1, 3}, store.locked)
+	require.Len(t, store.written, 2)
+	assert.Equal(t, db.CreateOrUpdateVocabularyStatsParams{
+		UserID:              7,
+		WordID:              1,
+		TotalAttempts:       sql.NullInt32{Int32: 3, Valid: true},
+		CorrectAttempts:     sql.NullInt32{Int32: 2, Valid: true},
+		TotalResponseTimeMs: sql.NullInt64{Int64: 4000, Valid: true},
+		MasteryLevel:        sql.NullInt32{Int32: 8, Valid: true},
+		LastAttemptAt:       sql.NullTime{Time: at.Add(2 * time.Minute), Valid: true},
+	}, store.written[0])
+	assert.Equal(t, int32(MinMastery), store.written[1].MasteryLevel.Int32)
+
+	// Nothing is written twice
+	require.NoError(t, recorder.Flush(context.Background()))
+	assert.Len(t, store.written, 2)
+}
+
+func TestFlushKeepsAttemptsOnFailure(t *testing.T) {
+	store := &fakeStore{fail: true}
+	recorder := newRecorder(func(_ context.Context, fn func(db.Querier) error) error { return fn(store) })
+
+	recorder.Record(Attempt{UserID: 7, WordID: 2, Correct: true})
+	assert.Error(t, recorder.Flush(context.Background()))
+	recorder.Record(Attempt{UserID: 7, WordID: 2, Correct: true})
+
+	store.fail = false
+	require.NoError(t, recorder.Flush(context.Background()))
+	require.Len(t, store.written, 1)
+	assert.Equal(t, int32(2), store.written[0].TotalAttempts.Int32)
+	assert.Equal(t, int32(3), store.written[0].MasteryLevel.Int32)
+}
@@ -0,0 +1,295 @@
+package warehouse
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+const (
+	bigQueryEndpoint = "https://bigquery.googleapis.com/bigquery/v2"
+	bigQueryScope    = "https://www.googleapis.com/auth/bigquery"
+)
+
+// serviceAccount is the part of a Google service account key file used to
+// request access tokens
+type serviceAccount struct {
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// BigQuery writes events with the BigQuery REST API, authenticating as a
+// service account. Rows are streamed with the event ID as insert ID, which
+// BigQuery uses to drop rows written twice.
+type BigQuery struct {
+	endpoint string
+	project  string
+	dataset  string
+	account  serviceAccount
+	key      *rsa.PrivateKey
+	client   *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+// NewBigQuery creates a sink for a dataset, reading the service account key
+// from credentialsFile
+func NewBigQuery(project, dataset, credentialsFile string) (*BigQuery, error) {
+	if project == "" {
+		return nil, fmt.Errorf("BigQuery project is required")
+	}
+	if !validIdentifier(dataset) {
+		return nil, fmt.Errorf("invalid BigQuery dataset name %q", dataset)
+	}
+	data, err := os.ReadFile(credentialsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read BigQuery credentials: %w", err)
+	}
+	var account serviceAccount
+	if err := json.Unmarshal(data, &account); err != nil {
+		return nil, fmt.Errorf("failed to parse BigQuery credentials: %w", err)
+	}
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(account.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse BigQuery private key: %w", err)
+	}
+	if account.TokenURI == "" {
+		account.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+	return &BigQuery{
+		endpoint: bigQueryEndpoint,
+		project:  project,
+		dataset:  dataset,
+		account:  account,
+		key:      key,
+		client:   &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+func (b *BigQuery) Name() string {
+	return "bigquery"
+}
+
+func bigQueryType(columnType ColumnType) string {
+	switch columnType {
+	case TypeInt64:
+		return "INT64"
+	case TypeTimestamp:
+		return "TIMESTAMP"
+	case TypeJSON:
+		return "JSON"
+	default:
+		return "STRING"
+	}
+}
+
+type bigQueryField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Mode string `json:"mode,omitempty"`
+}
+
+type bigQueryTable struct {
+	TableReference map[string]string `json:"tableReference,omitempty"`
+	Schema         struct {
+		Fields []bigQueryField `json:"fields"`
+	} `json:"schema"`
+	TimePartitioning map[string]string `json:"timePartitioning,omitempty"`
+}
+
+func (b *BigQuery) tableURL(table string) string {
+	return fmt.Sprintf("%s/projects/%s/datasets/%s/tables/%s",
+		b.endpoint, url.PathEscape(b.project), b.dataset, table)
+}
+
+// EnsureSchema creates the table, partitioned by day of occurred_at, or
+// adds the columns it is missing
+func (b *BigQuery) EnsureSchema(ctx context.Context, table string, columns []Column) error {
+	if !validIdentifier(table) {
+		return fmt.Errorf("invalid table name %q", table)
+	}
+	var existing bigQueryTable
+	status, err := b.call(ctx, http.MethodGet, b.tableURL(table), nil, &existing)
+	if err != nil && status != http.StatusNotFound {
+		return err
+	}
+
+	if status == http.StatusNotFound {
+		created := bigQueryTable{
+			TableReference:   map[string]string{"projectId": b.project, "datasetId": b.dataset, "tableId": table},
+			TimePartitioning: map[string]string{"type": "DAY", "field": "occurred_at"},
+		}
+		for _, column := range columns {
+			created.Schema.Fields = append(created.Schema.Fields,
+				bigQueryField{Name: column.Name, Type: bigQueryType(column.Type), Mode: "NULLABLE"})
+		}
+		tablesURL := fmt.Sprintf("%s/projects/%s/datasets/%s/tables", b.endpoint, url.PathEscape(b.project), b.dataset)
+		_, err := b.call(ctx, http.MethodPost, tablesURL, created, nil)
+		return err
+	}
+
+	known := make(map[string]bool, len(existing.Schema.Fields))
+	for _, field := range existing.Schema.Fields {
+		known[field.Name] = true
+	}
+	fields := existing.Schema.Fields
+	for _, column := range columns {
+		if !known[column.Name] {
+			fields = append(fields, bigQueryField{Name: column.Name, Type: bigQueryType(column.Type), Mode: "NULLABLE"})
+		}
+	}
+	if len(fields) == len(existing.Schema.Fields) {
+		return nil
+	}
+	var patch bigQueryTable
+	patch.Schema.Fields = fields
+	_, err = b.call(ctx, http.MethodPatch, b.tableURL(table), patch, nil)
+	return err
+}
+
+// Write streams events into the table
+func (b *BigQuery) Write(ctx context.Context, table string, events []Event) error {
+	if !validIdentifier(table) {
+		return fmt.Errorf("invalid table name %q", table)
+	}
+	type insertRow struct {
+		InsertID string                 `json:"insertId"`
+		JSON     map[string]interface{} `json:"json"`
+	}
+	request := struct {
+		Rows []insertRow `json:"rows"`
+	}{}
+	for _, event := range events {
+		row, err := event.row()
+		if err != nil {
+			return err
+		}
+		request.Rows = append(request.Rows, insertRow{InsertID: strconv.FormatInt(event.EventID, 10), JSON: row})
+	}
+
+	var response struct {
+		InsertErrors []struct {
+			Index  int `json:"index"`
+			Errors []struct {
+				Reason  string `json:"reason"`
+				Message string `json:"message"`
+			} `json:"errors"`
+		} `json:"insertErrors"`
+	}
+	if _, err := b.call(ctx, http.MethodPost, b.tableURL(table)+"/insertAll", request, &response); err != nil {
+		return err
+	}
+	if len(response.InsertErrors) > 0 {
+		first := response.InsertErrors[0]
+		message := "unknown error"
+		if len(first.Errors) > 0 {
+			message = first.Errors[0].Reason + ": " + first.Errors[0].Message
+		}
+		return fmt.Errorf("BigQuery rejected %d rows, first at index %d: %s", len(response.InsertErrors), first.Index, message)
+	}
+	return nil
+}
+
+// call sends a JSON request and decodes the response into out. It returns
+// the HTTP status with an error for statuses other than 2xx.
+func (b *BigQuery) call(ctx context.Context, method, endpoint string, in, out interface{}) (int, error) {
+	token, err := b.token(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var body io.Reader = http.NoBody
+	if in != nil {
+		encoded, err := json.Marshal(in)
+		if err != nil {
+			return 0, err
+		}
+		body = bytes.NewReader(encoded)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("BigQuery request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return resp.StatusCode, fmt.Errorf("BigQuery returned %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return resp.StatusCode, fmt.Errorf("failed to decode BigQuery response: %w", err)
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+// token returns an access token, exchanging a signed assertion for a new
+// one shortly before the current one expires
+func (b *BigQuery) token(ctx context.Context) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.accessToken != "" && time.Now().Before(b.expiresAt.Add(-time.Minute)) {
+		return b.accessToken, nil
+	}
+
+	now := time.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   b.account.ClientEmail,
+		"scope": bigQueryScope,
+		"aud":   b.account.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(b.key)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign BigQuery token request: %w", err)
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "urn:ietf:params:oauth:grant-type:jwt-bearer")
+	form.Set("assertion", assertion)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.account.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("BigQuery token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("BigQuery token request returned %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("failed to decode BigQuery token: %w", err)
+	}
+	b.accessToken = token.AccessToken
+	b.expiresAt = now.Add(time.Duration(token.ExpiresIn) * time.Second)
+	return b.accessToken, nil
+}
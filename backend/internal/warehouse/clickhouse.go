@@ -0,0 +1,134 @@
+package warehouse
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ClickHouse writes events through the ClickHouse HTTP interface. The table
+// is a ReplacingMergeTree ordered by event_id, so events written twice are
+// merged into one.
+type ClickHouse struct {
+	endpoint string
+	database string
+	user     string
+	password string
+	client   *http.Client
+}
+
+// NewClickHouse creates a sink for the database at endpoint, e.g.
+// http://localhost:8123
+func NewClickHouse(endpoint, database, user, password string) (*ClickHouse, error) {
+	if _, err := url.ParseRequestURI(endpoint); err != nil {
+		return nil, fmt.Errorf("invalid ClickHouse URL: %w", err)
+	}
+	if !validIdentifier(database) {
+		return nil, fmt.Errorf("invalid ClickHouse database name %q", database)
+	}
+	return &ClickHouse{
+		endpoint: strings.TrimRight(endpoint, "/"),
+		database: database,
+		user:     user,
+		password: password,
+		client:   &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+func (c *ClickHouse) Name() string {
+	return "clickhouse"
+}
+
+func clickHouseType(columnType ColumnType) string {
+	switch columnType {
+	case TypeInt64:
+		return "Int64"
+	case TypeTimestamp:
+		return "DateTime64(3, 'UTC')"
+	default:
+		return "String"
+	}
+}
+
+// EnsureSchema creates the table and adds missing columns. Both statements
+// are no-ops when the table is up to date.
+func (c *ClickHouse) EnsureSchema(ctx context.Context, table string, columns []Column) error {
+	if !validIdentifier(table) {
+		return fmt.Errorf("invalid table name %q", table)
+	}
+	definitions := make([]string, len(columns))
+	for i, column := range columns {
+		definitions[i] = fmt.Sprintf("%s %s", column.Name, clickHouseType(column.Type))
+	}
+	create := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s.%s (%s) ENGINE = ReplacingMergeTree(exported_at) "+
+		"PARTITION BY toYYYYMM(occurred_at) ORDER BY event_id",
+		c.database, table, strings.Join(definitions, ", "))
+	if err := c.exec(ctx, create, nil); err != nil {
+		return err
+	}
+
+	for _, column := range columns {
+		alter := fmt.Sprintf("ALTER TABLE %s.%s ADD COLUMN IF NOT EXISTS %s %s",
+			c.database, table, column.Name, clickHouseType(column.Type))
+		if err := c.exec(ctx, alter, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Write inserts events as JSONEachRow
+func (c *ClickHouse) Write(ctx context.Context, table string, events []Event) error {
+	if !validIdentifier(table) {
+		return fmt.Errorf("invalid table name %q", table)
+	}
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, event := range events {
+		row, err := event.row()
+		if err != nil {
+			return err
+		}
+		if err := encoder.Encode(row); err != nil {
+			return err
+		}
+	}
+	insert := fmt.Sprintf("INSERT INTO %s.%s FORMAT JSONEachRow", c.database, table)
+	return c.exec(ctx, insert, &body)
+}
+
+// exec runs a statement. Data, if any, is sent as the request body.
+func (c *ClickHouse) exec(ctx context.Context, statement string, data io.Reader) error {
+	params := url.Values{}
+	params.Set("query", statement)
+	// RFC 3339 timestamps are only parsed in best effort mode
+	params.Set("date_time_input_format", "best_effort")
+	if data == nil {
+		data = http.NoBody
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint+"/?"+params.Encode(), data)
+	if err != nil {
+		return err
+	}
+	if c.user != "" {
+		req.Header.Set("X-ClickHouse-User", c.user)
+		req.Header.Set("X-ClickHouse-Key", c.password)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("ClickHouse request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("ClickHouse returned %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
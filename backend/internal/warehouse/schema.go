@@ -0,0 +1,98 @@
+package warehouse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// SchemaVersion is stored with every exported event. It is bumped whenever
+// Columns change.
+const SchemaVersion = 1
+
+// ColumnType is a warehouse-neutral column type; sinks map it to their own
+type ColumnType string
+
+const (
+	TypeInt64     ColumnType = "int64"
+	TypeString    ColumnType = "string"
+	TypeTimestamp ColumnType = "timestamp"
+	// TypeJSON holds an object; it is written as a JSON string
+	TypeJSON ColumnType = "json"
+)
+
+// Column is a column of the events table
+type Column struct {
+	Name string     `json:"name"`
+	Type ColumnType `json:"type"`
+}
+
+// Columns of the events table. Columns are only ever added, at the end, so
+// sinks can bring existing tables up to date by adding the missing ones.
+var Columns = []Column{
+	{Name: "event_id", Type: TypeInt64},
+	{Name: "event_type", Type: TypeString},
+	{Name: "user_key", Type: TypeString},
+	{Name: "xp", Type: TypeInt64},
+	{Name: "occurred_at", Type: TypeTimestamp},
+	{Name: "properties", Type: TypeJSON},
+	{Name: "schema_version", Type: TypeInt64},
+	{Name: "exported_at", Type: TypeTimestamp},
+}
+
+// Event is an exported activity
+type Event struct {
+	EventID   int64
+	EventType string
+	// UserKey is a keyed hash of the user ID, stable across exports
+	UserKey    string
+	XP         int32
+	OccurredAt time.Time
+	// Properties is the activity payload after PII scrubbing
+	Properties map[string]interface{}
+	ExportedAt time.Time
+}
+
+// row returns the column values of the event. Timestamps are UTC RFC 3339
+// and properties a JSON string, which every sink accepts.
+func (e Event) row() (map[string]interface{}, error) {
+	properties := e.Properties
+	if properties == nil {
+		properties = map[string]interface{}{}
+	}
+	encoded, err := json.Marshal(properties)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode properties of event %d: %w", e.EventID, err)
+	}
+	return map[string]interface{}{
+		"event_id":       e.EventID,
+		"event_type":     e.EventType,
+		"user_key":       e.UserKey,
+		"xp":             e.XP,
+		"occurred_at":    e.OccurredAt.UTC().Format(time.RFC3339Nano),
+		"properties":     string(encoded),
+		"schema_version": SchemaVersion,
+		"exported_at":    e.ExportedAt.UTC().Format(time.RFC3339Nano),
+	}, nil
+}
+
+// Sink is an analytics warehouse
+type Sink interface {
+	// Name identifies the warehouse, e.g. "clickhouse"
+	Name() string
+	// EnsureSchema creates the table, or adds the columns it is missing
+	EnsureSchema(ctx context.Context, table string, columns []Column) error
+	// Write inserts events. Writing an event again must not duplicate it,
+	// so exports and backfills can be retried.
+	Write(ctx context.Context, table string, events []Event) error
+}
+
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// validIdentifier reports whether name can be used as a database, dataset
+// or table name without quoting concerns
+func validIdentifier(name string) bool {
+	return identifierPattern.MatchString(name)
+}
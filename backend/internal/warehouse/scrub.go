@@ -0,0 +1,92 @@
+package warehouse
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Default scrubbing rules for activity payloads
+var (
+	DefaultDropFields = []string{"email", "phone", "name", "full_name", "username", "ip", "ip_address", "address"}
+	DefaultHashFields = []string{"user_id", "follower_id", "followee_id"}
+)
+
+// keyLength is the number of hex characters kept of a hashed value
+const keyLength = 32
+
+var emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+
+// Scrubber removes personal data from events before they leave the
+// database. Fields are matched by name, case-insensitively, at any depth:
+// drop fields are removed and hash fields replaced by a keyed hash, so they
+// can still be joined on. Email addresses inside other strings are masked.
+type Scrubber struct {
+	key  []byte
+	drop map[string]bool
+	hash map[string]bool
+}
+
+// NewScrubber creates a scrubber hashing with key
+func NewScrubber(key string, dropFields, hashFields []string) *Scrubber {
+	return &Scrubber{key: []byte(key), drop: fieldSet(dropFields), hash: fieldSet(hashFields)}
+}
+
+func fieldSet(fields []string) map[string]bool {
+	set := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		if field = strings.ToLower(strings.TrimSpace(field)); field != "" {
+			set[field] = true
+		}
+	}
+	return set
+}
+
+// UserKey is the pseudonymous ID of a user in the warehouse
+func (s *Scrubber) UserKey(userID int32) string {
+	return s.digest(strconv.Itoa(int(userID)))
+}
+
+func (s *Scrubber) digest(value string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))[:keyLength]
+}
+
+// Properties returns a scrubbed copy of an activity payload
+func (s *Scrubber) Properties(payload map[string]interface{}) map[string]interface{} {
+	scrubbed := make(map[string]interface{}, len(payload))
+	for field, value := range payload {
+		name := strings.ToLower(field)
+		switch {
+		case s.drop[name]:
+			continue
+		case s.hash[name] && value != nil:
+			scrubbed[field] = s.digest(fmt.Sprint(value))
+		default:
+			scrubbed[field] = s.value(value)
+		}
+	}
+	return scrubbed
+}
+
+func (s *Scrubber) value(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return s.Properties(v)
+	case []interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = s.value(item)
+		}
+		return items
+	case string:
+		return emailPattern.ReplaceAllString(v, "[email]")
+	default:
+		return v
+	}
+}
@@ -0,0 +1,345 @@
+// Package warehouse ships the product event stream, the user_activities
+// log, to an analytics warehouse. Events are scrubbed of personal data
+// before they are written; the table is created and extended from Columns.
+package warehouse
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/logger"
+)
+
+// cursorName is the position of the exporter in the activity log
+const cursorName = "warehouse_export"
+
+var (
+	ErrBackfillRunning = errors.New("a backfill is already running")
+	ErrInvalidRange    = errors.New("backfill range must end after it starts")
+)
+
+// Options configure the exporter
+type Options struct {
+	Table     string
+	BatchSize int32
+	Scrubber  *Scrubber
+}
+
+// BackfillStatus is the progress of a backfill
+type BackfillStatus struct {
+	From       time.Time  `json:"from"`
+	To         time.Time  `json:"to"`
+	Running    bool       `json:"running"`
+	Exported   int64      `json:"exported"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// Status is the state of the exporter on this instance
+type Status struct {
+	Warehouse     string `json:"warehouse"`
+	Table         string `json:"table"`
+	SchemaVersion int    `json:"schema_version"`
+	// Cursor is the ID of the last exported activity
+	Cursor       int64           `json:"cursor"`
+	Exported     int64           `json:"exported"`
+	LastExportAt *time.Time      `json:"last_export_at,omitempty"`
+	LastError    string          `json:"last_error,omitempty"`
+	Backfill     *BackfillStatus `json:"backfill,omitempty"`
+}
+
+// Service exports new activities periodically and backfills past ones on
+// request
+type Service struct {
+	store   db.Querier
+	sink    Sink
+	options Options
+	now     func() time.Time
+
+	// writeMu lets one batch be written at a time, so the schema is ensured
+	// once and backfills do not race the periodic export
+	writeMu     sync.Mutex
+	schemaReady bool
+
+	statusMu       sync.Mutex
+	exported       int64
+	lastExportAt   *time.Time
+	lastError      string
+	backfill       *BackfillStatus
+	cancelBackfill context.CancelFunc
+	backfillWg     sync.WaitGroup
+
+	mutex     sync.Mutex
+	isRunning bool
+	stopChan  chan struct{}
+	wg        sync.WaitGroup
+}
+
+// NewService creates an exporter writing to sink
+func NewService(store db.Querier, sink Sink, options Options) *Service {
+	return &Service{store: store, sink: sink, options: options, now: time.Now}
+}
+
+// Start exports new activities periodically until Stop is called
+func (s *Service) Start(interval time.Duration) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.isRunning {
+		return fmt.Errorf("warehouse exporter is already running")
+	}
+	s.isRunning = true
+	s.stopChan = make(chan struct{})
+	s.wg.Add(1)
+	go s.run(interval)
+
+	logger.Info("Warehouse exporter started with interval: %v (%s)", interval, s.sink.Name())
+	return nil
+}
+
+// Stop stops the periodic export
+func (s *Service) Stop() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.isRunning {
+		return fmt.Errorf("warehouse exporter is not running")
+	}
+	close(s.stopChan)
+	s.wg.Wait()
+	s.isRunning = false
+
+	logger.Info("Warehouse exporter stopped")
+	return nil
+}
+
+// IsRunning returns whether the periodic export is running
+func (s *Service) IsRunning() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.isRunning
+}
+
+// Close cancels a running backfill and waits for it to end
+func (s *Service) Close() {
+	s.statusMu.Lock()
+	cancel := s.cancelBackfill
+	s.statusMu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+	s.backfillWg.Wait()
+}
+
+func (s *Service) run(interval time.Duration) {
+	defer s.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			// Drain the backlog before waiting for the next tick
+			for {
+				exported, err := s.ExportPending(context.Background())
+				if err != nil {
+					logger.Error("Failed to export activities to %s: %v", s.sink.Name(), err)
+				}
+				if err != nil || exported < int(s.options.BatchSize) {
+					break
+				}
+			}
+		case <-s.stopChan:
+			return
+		}
+	}
+}
+
+// ExportPending writes one batch of activities recorded since the last
+// export and returns the number written
+func (s *Service) ExportPending(ctx context.Context) (int, error) {
+	exported, err := s.exportPending(ctx)
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+	if err != nil {
+		s.lastError = err.Error()
+		return 0, err
+	}
+	now := s.now()
+	s.exported += int64(exported)
+	s.lastExportAt = &now
+	s.lastError = ""
+	return exported, nil
+}
+
+func (s *Service) exportPending(ctx context.Context) (int, error) {
+	lastID, err := s.cursor(ctx)
+	if err != nil {
+		return 0, err
+	}
+	activities, err := s.store.ListActivitiesAfter(ctx, db.ListActivitiesAfterParams{AfterID: lastID, MaxItems: s.options.BatchSize})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list activities: %w", err)
+	}
+	if len(activities) == 0 {
+		return 0, nil
+	}
+	if err := s.write(ctx, activities); err != nil {
+		return 0, err
+	}
+
+	last := activities[len(activities)-1].ID
+	if err := s.store.UpsertEventCursor(ctx, db.UpsertEventCursorParams{Name: cursorName, LastID: last}); err != nil {
+		return 0, fmt.Errorf("failed to save event cursor: %w", err)
+	}
+	return len(activities), nil
+}
+
+func (s *Service) cursor(ctx context.Context) (int64, error) {
+	lastID, err := s.store.GetEventCursor(ctx, cursorName)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("failed to get event cursor: %w", err)
+	}
+	return lastID, nil
+}
+
+// write scrubs activities and writes them to the warehouse
+func (s *Service) write(ctx context.Context, activities []db.UserActivity) error {
+	now := s.now()
+	events := make([]Event, 0, len(activities))
+	for _, activity := range activities {
+		var payload map[string]interface{}
+		if len(activity.Payload) > 0 {
+			if err := json.Unmarshal(activity.Payload, &payload); err != nil {
+				// A payload that is not an object carries no properties
+				logger.Warn("Exporting activity %d without its payload: %v", activity.ID, err)
+				payload = nil
+			}
+		}
+		events = append(events, Event{
+			EventID:    activity.ID,
+			EventType:  activity.Type,
+			UserKey:    s.options.Scrubber.UserKey(activity.UserID),
+			XP:         activity.Xp,
+			OccurredAt: activity.CreatedAt,
+			Properties: s.options.Scrubber.Properties(payload),
+			ExportedAt: now,
+		})
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	if !s.schemaReady {
+		if err := s.sink.EnsureSchema(ctx, s.options.Table, Columns); err != nil {
+			return fmt.Errorf("failed to update %s schema: %w", s.sink.Name(), err)
+		}
+		s.schemaReady = true
+	}
+	if err := s.sink.Write(ctx, s.options.Table, events); err != nil {
+		return fmt.Errorf("failed to write events to %s: %w", s.sink.Name(), err)
+	}
+	return nil
+}
+
+// StartBackfill exports the activities recorded in [from, to) again, in the
+// background. The export cursor is not moved; the warehouse drops events it
+// already has.
+func (s *Service) StartBackfill(from, to time.Time) (*BackfillStatus, error) {
+	if !to.After(from) {
+		return nil, ErrInvalidRange
+	}
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+	if s.backfill != nil && s.backfill.Running {
+		return nil, ErrBackfillRunning
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.backfill = &BackfillStatus{From: from, To: to, Running: true, StartedAt: s.now()}
+	s.cancelBackfill = cancel
+	s.backfillWg.Add(1)
+	go func() {
+		defer s.backfillWg.Done()
+		defer cancel()
+		err := s.Backfill(ctx, from, to)
+
+		s.statusMu.Lock()
+		defer s.statusMu.Unlock()
+		finishedAt := s.now()
+		s.backfill.Running = false
+		s.backfill.FinishedAt = &finishedAt
+		s.cancelBackfill = nil
+		if err != nil {
+			s.backfill.Error = err.Error()
+			logger.Error("Warehouse backfill failed: %v", err)
+			return
+		}
+		logger.Info("Warehouse backfill exported %d activities", s.backfill.Exported)
+	}()
+
+	status := *s.backfill
+	return &status, nil
+}
+
+// Backfill exports the activities recorded in [from, to)
+func (s *Service) Backfill(ctx context.Context, from, to time.Time) error {
+	var afterID int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		activities, err := s.store.ListActivitiesBetween(ctx, db.ListActivitiesBetweenParams{
+			FromTime: from,
+			ToTime:   to,
+			AfterID:  afterID,
+			MaxItems: s.options.BatchSize,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list activities: %w", err)
+		}
+		if len(activities) == 0 {
+			return nil
+		}
+		if err := s.write(ctx, activities); err != nil {
+			return err
+		}
+		afterID = activities[len(activities)-1].ID
+
+		s.statusMu.Lock()
+		if s.backfill != nil {
+			s.backfill.Exported += int64(len(activities))
+		}
+		s.statusMu.Unlock()
+	}
+}
+
+// Status returns the state of the exporter on this instance
+func (s *Service) Status(ctx context.Context) (*Status, error) {
+	cursor, err := s.cursor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+	status := &Status{
+		Warehouse:     s.sink.Name(),
+		Table:         s.options.Table,
+		SchemaVersion: SchemaVersion,
+		Cursor:        cursor,
+		Exported:      s.exported,
+		LastExportAt:  s.lastExportAt,
+		LastError:     s.lastError,
+	}
+	if s.backfill != nil {
+		backfill := *s.backfill
+		status.Backfill = &backfill
+	}
+	return status, nil
+}
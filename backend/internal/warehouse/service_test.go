@@ -0,0 +1,167 @@
+package warehouse
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+// fakeStore serves a fixed activity log and one event cursor
+type fakeStore struct {
+	db.Querier
+	activities []db.UserActivity
+	cursor     *int64
+}
+
+func (f *fakeStore) GetEventCursor(_ context.Context, name string) (int64, error) {
+	if f.cursor == nil || name != cursorName {
+		return 0, sql.ErrNoRows
+	}
+	return *f.cursor, nil
+}
+
+func (f *fakeStore) UpsertEventCursor(_ context.Context, arg db.UpsertEventCursorParams) error {
+	f.cursor = &arg.LastID
+	return nil
+}
+
+func (f *fakeStore) ListActivitiesAfter(_ context.Context, arg db.ListActivitiesAfterParams) ([]db.UserActivity, error) {
+	var items []db.UserActivity
+	for _, activity := range f.activities {
+		if activity.ID > arg.AfterID && len(items) < int(arg.MaxItems) {
+			items = append(items, activity)
+		}
+	}
+	return items, nil
+}
+
+func (f *fakeStore) ListActivitiesBetween(_ context.Context, arg db.ListActivitiesBetweenParams) ([]db.UserActivity, error) {
+	var items []db.UserActivity
+	for _, activity := range f.activities {
+		if activity.ID > arg.AfterID && !activity.CreatedAt.Before(arg.FromTime) && activity.CreatedAt.Before(arg.ToTime) &&
+			len(items) < int(arg.MaxItems) {
+			items = append(items, activity)
+		}
+	}
+	return items, nil
+}
+
+// fakeSink keeps written events by ID, like a deduplicating warehouse
+type fakeSink struct {
+	schemas int
+	events  map[int64]Event
+	writes  int
+}
+
+func (f *fakeSink) Name() string { return "fake" }
+
+func (f *fakeSink) EnsureSchema(context.Context, string, []Column) error {
+	f.schemas++
+	return nil
+}
+
+func (f *fakeSink) Write(_ context.Context, _ string, events []Event) error {
+	f.writes++
+	for _, event := range events {
+		f.events[event.EventID] = event
+	}
+	return nil
+}
+
+var start = time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+func newTestService() (*Service, *fakeStore, *fakeSink) {
+	store := &fakeStore{}
+	for i := int64(1); i <= 5; i++ {
+		payload, _ := json.Marshal(map[string]interface{}{
+			"exam_id":  i,
+			"email":    "jane@example.com",
+			"comment":  "mail me at jane@example.com",
+			"followee": map[string]interface{}{"user_id": 9, "username": "bob"},
+		})
+		store.activities = append(store.activities, db.UserActivity{
+			ID:        i,
+			UserID:    7,
+			Type:      "exam_completed",
+			Payload:   payload,
+			Xp:        50,
+			CreatedAt: start.Add(time.Duration(i) * 24 * time.Hour),
+		})
+	}
+	sink := &fakeSink{events: map[int64]Event{}}
+	service := NewService(store, sink, Options{
+		Table:     "product_events",
+		BatchSize: 2,
+		Scrubber:  NewScrubber("secret", DefaultDropFields, DefaultHashFields),
+	})
+	return service, store, sink
+}
+
+func TestExportPendingAdvancesCursor(t *testing.T) {
+	service, store, sink := newTestService()
+	ctx := context.Background()
+
+	for _, want := range []int{2, 2, 1, 0} {
+		exported, err := service.ExportPending(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, want, exported)
+	}
+	assert.Equal(t, int64(5), *store.cursor)
+	assert.Len(t, sink.events, 5)
+	assert.Equal(t, 1, sink.schemas)
+
+	status, err := service.Status(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), status.Cursor)
+	assert.Equal(t, int64(5), status.Exported)
+	assert.Equal(t, "fake", status.Warehouse)
+}
+
+func TestExportScrubsPersonalData(t *testing.T) {
+	service, _, sink := newTestService()
+	_, err := service.ExportPending(context.Background())
+	require.NoError(t, err)
+
+	event := sink.events[1]
+	assert.Equal(t, "exam_completed", event.EventType)
+	assert.Len(t, event.UserKey, keyLength)
+	assert.Equal(t, service.options.Scrubber.UserKey(7), event.UserKey)
+
+	assert.NotContains(t, event.Properties, "email")
+	assert.Equal(t, "mail me at [email]", event.Properties["comment"])
+	assert.Equal(t, float64(1), event.Properties["exam_id"])
+	followee := event.Properties["followee"].(map[string]interface{})
+	assert.NotContains(t, followee, "username")
+	assert.Equal(t, service.options.Scrubber.UserKey(9), followee["user_id"])
+}
+
+func TestBackfillKeepsCursor(t *testing.T) {
+	service, store, sink := newTestService()
+	ctx := context.Background()
+	_, err := service.ExportPending(ctx)
+	require.NoError(t, err)
+
+	_, err = service.StartBackfill(start.Add(72*time.Hour), start)
+	assert.ErrorIs(t, err, ErrInvalidRange)
+
+	// Days 2 to 4 hold activities 2, 3 and 4
+	status, err := service.StartBackfill(start.Add(48*time.Hour), start.Add(5*24*time.Hour))
+	require.NoError(t, err)
+	assert.True(t, status.Running)
+
+	var current *Status
+	require.Eventually(t, func() bool {
+		current, err = service.Status(ctx)
+		return err == nil && !current.Backfill.Running
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, int64(3), current.Backfill.Exported)
+	assert.Empty(t, current.Backfill.Error)
+	assert.Equal(t, int64(2), *store.cursor)
+	assert.Len(t, sink.events, 4)
+}
@@ -0,0 +1,126 @@
+package warehouse
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testEvent = Event{
+	EventID:    42,
+	EventType:  "exam_completed",
+	UserKey:    "abc",
+	XP:         50,
+	OccurredAt: time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC),
+	Properties: map[string]interface{}{"exam_id": 3},
+	ExportedAt: time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC),
+}
+
+func TestClickHouseSchemaAndWrite(t *testing.T) {
+	var mu sync.Mutex
+	var queries, bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		queries = append(queries, r.URL.Query().Get("query"))
+		bodies = append(bodies, string(body))
+		mu.Unlock()
+		assert.Equal(t, "exporter", r.Header.Get("X-ClickHouse-User"))
+	}))
+	defer server.Close()
+
+	sink, err := NewClickHouse(server.URL, "analytics", "exporter", "secret")
+	require.NoError(t, err)
+	require.NoError(t, sink.EnsureSchema(context.Background(), "product_events", Columns))
+	require.NoError(t, sink.Write(context.Background(), "product_events", []Event{testEvent}))
+
+	require.Len(t, queries, len(Columns)+2)
+	assert.Contains(t, queries[0], "CREATE TABLE IF NOT EXISTS analytics.product_events (event_id Int64, ")
+	assert.Contains(t, queries[0], "ReplacingMergeTree(exported_at)")
+	assert.Equal(t, "ALTER TABLE analytics.product_events ADD COLUMN IF NOT EXISTS event_id Int64", queries[1])
+	assert.Equal(t, "INSERT INTO analytics.product_events FORMAT JSONEachRow", queries[len(queries)-1])
+
+	var row map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(bodies[len(bodies)-1]), &row))
+	assert.Equal(t, float64(42), row["event_id"])
+	assert.Equal(t, `{"exam_id":3}`, row["properties"])
+	assert.Equal(t, "2026-03-01T08:00:00Z", row["occurred_at"])
+
+	_, err = NewClickHouse(server.URL, "analytics; DROP", "", "")
+	assert.Error(t, err)
+	assert.Error(t, sink.Write(context.Background(), "events`", nil))
+}
+
+// writeCredentials writes a service account key file whose token endpoint
+// is tokenURL
+func writeCredentials(t *testing.T, tokenURL string) string {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	privateKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	data, err := json.Marshal(serviceAccount{ClientEmail: "exporter@test.iam", PrivateKey: string(privateKey), TokenURI: tokenURL})
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+func TestBigQueryAddsMissingColumns(t *testing.T) {
+	var tokens int
+	var patched []bigQueryField
+	var inserted map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			tokens++
+			require.NoError(t, r.ParseForm())
+			assert.NotEmpty(t, r.Form.Get("assertion"))
+			_, _ = w.Write([]byte(`{"access_token":"token-1","expires_in":3600}`))
+			return
+		}
+		assert.Equal(t, "Bearer token-1", r.Header.Get("Authorization"))
+		assert.True(t, strings.HasPrefix(r.URL.Path, "/projects/toeic/datasets/analytics/tables/product_events"), r.URL.Path)
+		switch r.Method {
+		case http.MethodGet:
+			// The table predates the exported_at column
+			_, _ = w.Write([]byte(`{"schema":{"fields":[{"name":"event_id","type":"INTEGER"},{"name":"event_type","type":"STRING"}]}}`))
+		case http.MethodPatch:
+			var table bigQueryTable
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&table))
+			patched = table.Schema.Fields
+			_, _ = w.Write([]byte(`{}`))
+		case http.MethodPost:
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&inserted))
+			_, _ = w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	sink, err := NewBigQuery("toeic", "analytics", writeCredentials(t, server.URL+"/token"))
+	require.NoError(t, err)
+	sink.endpoint = server.URL
+
+	require.NoError(t, sink.EnsureSchema(context.Background(), "product_events", Columns))
+	require.Len(t, patched, len(Columns))
+	assert.Equal(t, "INTEGER", patched[0].Type)
+	assert.Equal(t, bigQueryField{Name: "properties", Type: "JSON", Mode: "NULLABLE"}, patched[5])
+
+	require.NoError(t, sink.Write(context.Background(), "product_events", []Event{testEvent}))
+	rows := inserted["rows"].([]interface{})
+	require.Len(t, rows, 1)
+	assert.Equal(t, "42", rows[0].(map[string]interface{})["insertId"])
+	assert.Equal(t, 1, tokens)
+}
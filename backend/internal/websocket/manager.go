@@ -4,7 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"regexp"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -13,24 +16,97 @@ import (
 	"github.com/toeic-app/internal/token"
 )
 
+// Reasons a message could not be sent, reported to the observer
+const (
+	FailureBufferFull = "buffer_full" // The client did not read its queued messages
+	FailureWrite      = "write_error" // Writing to the socket failed
+)
+
+// maxTopicsPerClient bounds the topics a single connection subscribes to
+const maxTopicsPerClient = 16
+
+var topicPattern = regexp.MustCompile(`^[a-z0-9_.:-]{1,64}$`)
+
+// AdminTopicPrefix starts the topics only administrators may subscribe to
+const AdminTopicPrefix = "admin."
+
+// TopicAuthorizer reports whether a user may subscribe to a topic
+type TopicAuthorizer func(userID int32, topic string) bool
+
 // Manager handles WebSocket connections and message broadcasting
 type Manager struct {
 	clients    map[string]*Client // userID -> client
-	broadcast  chan []byte        // Broadcast channel
+	broadcast  chan outbound      // Broadcast channel
 	register   chan *Client       // Register requests from clients
 	unregister chan *Client       // Unregister requests from clients
 	upgrader   websocket.Upgrader
 	mutex      sync.RWMutex
+
+	observer         Observer
+	authorize        TopicAuthorizer
+	messagesSent     atomic.Int64
+	messagesReceived atomic.Int64
+	sendFailures     atomic.Int64
+}
+
+// Observer is told about connections, subscriptions and messages, e.g. to
+// export them as metrics
+type Observer interface {
+	ConnectionOpened()
+	ConnectionClosed()
+	Subscribed(topic string)
+	Unsubscribed(topic string)
+	MessageSent(messageType string)
+	MessageReceived(messageType string)
+	SendFailed(reason string)
 }
 
 // Client represents a websocket client connection
 type Client struct {
-	ID       string          // User ID
-	Socket   *websocket.Conn // WebSocket connection
-	Send     chan []byte     // Buffered channel of outbound messages
-	Manager  *Manager        // Reference to the manager
-	UserID   int32           // User ID for authentication
-	LastPing time.Time       // Last ping time for connection health
+	ID          string          // User ID
+	Socket      *websocket.Conn // WebSocket connection
+	Send        chan []byte     // Buffered channel of outbound messages
+	Manager     *Manager        // Reference to the manager
+	UserID      int32           // User ID for authentication
+	LastPing    time.Time       // Last ping time for connection health
+	ConnectedAt time.Time
+
+	mu           sync.Mutex
+	lastActivity time.Time       // Last message or pong in either direction
+	topics       map[string]bool // Topics the client subscribed to
+}
+
+// outbound is a message broadcast to every client
+type outbound struct {
+	messageType string
+	data        []byte
+}
+
+// ConnectionInfo describes an open connection for debugging
+type ConnectionInfo struct {
+	Username       string    `json:"username"`
+	UserID         int32     `json:"user_id"`
+	RemoteAddr     string    `json:"remote_addr"`
+	ConnectedAt    time.Time `json:"connected_at"`
+	LastActivity   time.Time `json:"last_activity"`
+	Topics         []string  `json:"topics"`
+	QueuedMessages int       `json:"queued_messages"` // Messages waiting to be written
+}
+
+// Stats are the connection and message counters of this instance since it
+// started
+type Stats struct {
+	Connections      int            `json:"connections"`
+	Subscriptions    map[string]int `json:"subscriptions"` // Connections subscribed per topic
+	MessagesSent     int64          `json:"messages_sent"`
+	MessagesReceived int64          `json:"messages_received"`
+	SendFailures     int64          `json:"send_failures"`
+}
+
+// clientMessage is a message sent by a client
+type clientMessage struct {
+	Type  string `json:"type"`
+	Topic string `json:"topic,omitempty"`
 }
 
 // Message represents a WebSocket message
@@ -50,13 +126,15 @@ type UpgradeNotification struct {
 	Required    bool      `json:"required"`
 	ReleaseDate time.Time `json:"release_date"`
 	Changes     []string  `json:"changes,omitempty"`
+	RolloutID   int32     `json:"rollout_id,omitempty"` // Set when sent by a targeted rollout
+	DeviceID    string    `json:"device_id,omitempty"`  // Device the rollout targeted
 }
 
 // NewManager creates a new WebSocket manager
 func NewManager() *Manager {
 	return &Manager{
 		clients:    make(map[string]*Client),
-		broadcast:  make(chan []byte),
+		broadcast:  make(chan outbound),
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
 		upgrader: websocket.Upgrader{
@@ -92,6 +170,18 @@ func (m *Manager) Start() {
 	go m.pingClients()
 }
 
+// SetObserver sets the observer told about connections and messages. It
+// must be called before connections are accepted.
+func (m *Manager) SetObserver(observer Observer) {
+	m.observer = observer
+}
+
+// SetTopicAuthorizer makes subscriptions subject to authorize; without one
+// every topic can be subscribed to
+func (m *Manager) SetTopicAuthorizer(authorize TopicAuthorizer) {
+	m.authorize = authorize
+}
+
 // HandleWebSocket handles WebSocket connection upgrade
 func (m *Manager) HandleWebSocket(c *gin.Context, tokenMaker token.Maker) {
 	// Get user from JWT token
@@ -122,13 +212,17 @@ func (m *Manager) HandleWebSocket(c *gin.Context, tokenMaker token.Maker) {
 	}
 
 	// Create new client
+	now := time.Now()
 	client := &Client{
-		ID:       payload.Username,
-		Socket:   conn,
-		Send:     make(chan []byte, 256),
-		Manager:  m,
-		UserID:   payload.ID,
-		LastPing: time.Now(),
+		ID:           payload.Username,
+		Socket:       conn,
+		Send:         make(chan []byte, 256),
+		Manager:      m,
+		UserID:       payload.ID,
+		LastPing:     now,
+		ConnectedAt:  now,
+		lastActivity: now,
+		topics:       make(map[string]bool),
 	}
 
 	// Register client
@@ -148,12 +242,14 @@ func (m *Manager) registerClient(client *Client) {
 
 	// Close existing connection if user is already connected
 	if existingClient, exists := m.clients[client.ID]; exists {
-		close(existingClient.Send)
-		existingClient.Socket.Close()
+		m.closeClient(existingClient)
 		logger.Info("Replaced existing WebSocket connection for user: %s", client.ID)
 	}
 
 	m.clients[client.ID] = client
+	if m.observer != nil {
+		m.observer.ConnectionOpened()
+	}
 	logger.Info("Registered WebSocket client: %s (Total clients: %d)", client.ID, len(m.clients))
 
 	// Send welcome message
@@ -166,39 +262,72 @@ func (m *Manager) registerClient(client *Client) {
 	if data, err := json.Marshal(welcome); err == nil {
 		select {
 		case client.Send <- data:
+			m.recordSent(welcome.Type)
 		default:
-			close(client.Send)
+			m.recordSendFailure(FailureBufferFull)
+			m.closeClient(client)
 			delete(m.clients, client.ID)
 		}
 	}
 }
 
+// closeClient closes the connection of a client and drops its
+// subscriptions. The caller holds the write lock and removes the client.
+func (m *Manager) closeClient(client *Client) {
+	close(client.Send)
+	client.Socket.Close()
+	if m.observer == nil {
+		return
+	}
+	client.mu.Lock()
+	for topic := range client.topics {
+		m.observer.Unsubscribed(topic)
+	}
+	client.mu.Unlock()
+	m.observer.ConnectionClosed()
+}
+
+func (m *Manager) recordSent(messageType string) {
+	m.messagesSent.Add(1)
+	if m.observer != nil {
+		m.observer.MessageSent(messageType)
+	}
+}
+
+func (m *Manager) recordSendFailure(reason string) {
+	m.sendFailures.Add(1)
+	if m.observer != nil {
+		m.observer.SendFailed(reason)
+	}
+}
+
 // unregisterClient unregisters a client
 func (m *Manager) unregisterClient(client *Client) {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
-	if _, exists := m.clients[client.ID]; exists {
+	// A replaced connection was closed when its successor registered
+	if current, exists := m.clients[client.ID]; exists && current == client {
 		delete(m.clients, client.ID)
-		close(client.Send)
-		client.Socket.Close()
+		m.closeClient(client)
 		logger.Info("Unregistered WebSocket client: %s (Total clients: %d)", client.ID, len(m.clients))
 	}
 }
 
 // broadcastMessage broadcasts a message to all connected clients
-func (m *Manager) broadcastMessage(message []byte) {
-	m.mutex.RLock()
-	defer m.mutex.RUnlock()
+func (m *Manager) broadcastMessage(message outbound) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
 
 	for clientID, client := range m.clients {
 		select {
-		case client.Send <- message:
+		case client.Send <- message.data:
+			m.recordSent(message.messageType)
 		default:
 			// Client's send channel is full, remove the client
+			m.recordSendFailure(FailureBufferFull)
 			delete(m.clients, clientID)
-			close(client.Send)
-			client.Socket.Close()
+			m.closeClient(client)
 			logger.Warn("Removed unresponsive WebSocket client: %s", clientID)
 		}
 	}
@@ -217,7 +346,7 @@ func (m *Manager) BroadcastUpgradeNotification(notification UpgradeNotification)
 		return err
 	}
 
-	m.broadcast <- data
+	m.broadcast <- outbound{messageType: message.Type, data: data}
 	logger.Info("Broadcasted upgrade notification: %s", notification.Version)
 	return nil
 }
@@ -246,14 +375,83 @@ func (m *Manager) SendToUser(userID string, messageType string, data interface{}
 
 	select {
 	case client.Send <- messageData:
+		m.recordSent(messageType)
 		return nil
 	default:
 		// Client's send channel is full, remove the client
+		m.recordSendFailure(FailureBufferFull)
 		m.unregister <- client
 		return nil
 	}
 }
 
+// sendToClient sends a message to client if it is still connected
+func (m *Manager) sendToClient(client *Client, messageType string, data interface{}) error {
+	messageData, err := json.Marshal(Message{
+		Type:      messageType,
+		Data:      data,
+		Timestamp: time.Now(),
+		UserID:    client.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	if m.clients[client.ID] != client {
+		return nil
+	}
+	select {
+	case client.Send <- messageData:
+		m.recordSent(messageType)
+	default:
+		m.recordSendFailure(FailureBufferFull)
+	}
+	return nil
+}
+
+// PublishToTopic sends a message to every client subscribed to topic
+func (m *Manager) PublishToTopic(topic string, messageType string, data interface{}) error {
+	messageData, err := json.Marshal(Message{
+		Type:      messageType,
+		Data:      data,
+		Timestamp: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	m.mutex.RLock()
+	var full []*Client
+	for _, client := range m.clients {
+		if !client.subscribed(topic) {
+			continue
+		}
+		select {
+		case client.Send <- messageData:
+			m.recordSent(messageType)
+		default:
+			m.recordSendFailure(FailureBufferFull)
+			full = append(full, client)
+		}
+	}
+	m.mutex.RUnlock()
+
+	for _, client := range full {
+		m.unregister <- client
+	}
+	return nil
+}
+
+// IsConnected reports whether a user has an open connection
+func (m *Manager) IsConnected(userID string) bool {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	_, exists := m.clients[userID]
+	return exists
+}
+
 // GetConnectedUsers returns the number of connected users
 func (m *Manager) GetConnectedUsers() int {
 	m.mutex.RLock()
@@ -273,6 +471,63 @@ func (m *Manager) GetConnectedUserIDs() []string {
 	return userIDs
 }
 
+// Connections returns the open connections of this instance, oldest first
+func (m *Manager) Connections() []ConnectionInfo {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	connections := make([]ConnectionInfo, 0, len(m.clients))
+	for _, client := range m.clients {
+		client.mu.Lock()
+		info := ConnectionInfo{
+			Username:       client.ID,
+			UserID:         client.UserID,
+			RemoteAddr:     client.Socket.RemoteAddr().String(),
+			ConnectedAt:    client.ConnectedAt,
+			LastActivity:   client.lastActivity,
+			Topics:         sortedTopics(client.topics),
+			QueuedMessages: len(client.Send),
+		}
+		client.mu.Unlock()
+		connections = append(connections, info)
+	}
+	sort.Slice(connections, func(i, j int) bool {
+		return connections[i].ConnectedAt.Before(connections[j].ConnectedAt)
+	})
+	return connections
+}
+
+// Stats returns the connection and message counters of this instance
+func (m *Manager) Stats() Stats {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	stats := Stats{
+		Connections:      len(m.clients),
+		Subscriptions:    make(map[string]int),
+		MessagesSent:     m.messagesSent.Load(),
+		MessagesReceived: m.messagesReceived.Load(),
+		SendFailures:     m.sendFailures.Load(),
+	}
+	for _, client := range m.clients {
+		client.mu.Lock()
+		for topic := range client.topics {
+			stats.Subscriptions[topic]++
+		}
+		client.mu.Unlock()
+	}
+	return stats
+}
+
+func sortedTopics(topics map[string]bool) []string {
+	sorted := make([]string, 0, len(topics))
+	for topic := range topics {
+		sorted = append(sorted, topic)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
 // pingClients sends ping messages to maintain connections
 func (m *Manager) pingClients() {
 	ticker := time.NewTicker(30 * time.Second)
@@ -293,6 +548,7 @@ func (m *Manager) pingClients() {
 				// Send ping
 				if err := client.Socket.WriteControl(websocket.PingMessage, []byte{}, time.Now().Add(10*time.Second)); err != nil {
 					logger.Warn("Failed to ping client %s: %v", client.ID, err)
+					m.recordSendFailure(FailureWrite)
 					m.unregister <- client
 				}
 			}
@@ -311,6 +567,7 @@ func (c *Client) readMessages() {
 	c.Socket.SetReadDeadline(time.Now().Add(60 * time.Second))
 	c.Socket.SetPongHandler(func(string) error {
 		c.LastPing = time.Now()
+		c.touch()
 		c.Socket.SetReadDeadline(time.Now().Add(60 * time.Second))
 		return nil
 	})
@@ -325,7 +582,104 @@ func (c *Client) readMessages() {
 		}
 
 		logger.Debug("Received message from client %s: %s", c.ID, string(message))
-		// Handle incoming messages if needed (e.g., subscription preferences)
+		c.touch()
+		c.handleMessage(message)
+	}
+}
+
+// handleMessage handles a message sent by the client: subscribing to and
+// unsubscribing from topics
+func (c *Client) handleMessage(raw []byte) {
+	var message clientMessage
+	if err := json.Unmarshal(raw, &message); err != nil {
+		c.Manager.recordReceived("invalid")
+		return
+	}
+
+	switch message.Type {
+	case "subscribe", "unsubscribe":
+		c.Manager.recordReceived(message.Type)
+	default:
+		// Client messages are not otherwise handled; the type is not
+		// recorded so clients cannot add metric labels
+		c.Manager.recordReceived("other")
+		return
+	}
+
+	if !topicPattern.MatchString(message.Topic) {
+		c.reply("error", gin.H{"message": "invalid topic"})
+		return
+	}
+	if message.Type == "subscribe" {
+		if c.Manager.authorize != nil && !c.Manager.authorize(c.UserID, message.Topic) {
+			c.reply("error", gin.H{"message": "topic not allowed"})
+			return
+		}
+		if !c.subscribe(message.Topic) {
+			c.reply("error", gin.H{"message": "too many topics"})
+			return
+		}
+		c.reply("subscribed", gin.H{"topic": message.Topic})
+		return
+	}
+	c.unsubscribe(message.Topic)
+	c.reply("unsubscribed", gin.H{"topic": message.Topic})
+}
+
+// subscribe adds a topic; it reports false when the client has too many
+func (c *Client) subscribe(topic string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.topics[topic] {
+		return true
+	}
+	if len(c.topics) >= maxTopicsPerClient {
+		return false
+	}
+	c.topics[topic] = true
+	if c.Manager.observer != nil {
+		c.Manager.observer.Subscribed(topic)
+	}
+	return true
+}
+
+func (c *Client) unsubscribe(topic string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.topics[topic] {
+		return
+	}
+	delete(c.topics, topic)
+	if c.Manager.observer != nil {
+		c.Manager.observer.Unsubscribed(topic)
+	}
+}
+
+func (c *Client) subscribed(topic string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.topics[topic]
+}
+
+// reply sends a message to the client through the manager, which drops it
+// when the client was replaced or removed meanwhile
+func (c *Client) reply(messageType string, data interface{}) {
+	if err := c.Manager.sendToClient(c, messageType, data); err != nil {
+		logger.Warn("Failed to reply to client %s: %v", c.ID, err)
+	}
+}
+
+// touch records activity on the connection
+func (c *Client) touch() {
+	c.mu.Lock()
+	c.lastActivity = time.Now()
+	c.mu.Unlock()
+}
+
+func (m *Manager) recordReceived(messageType string) {
+	m.messagesReceived.Add(1)
+	if m.observer != nil {
+		m.observer.MessageReceived(messageType)
 	}
 }
 
@@ -348,6 +702,7 @@ func (c *Client) writeMessages() {
 
 			w, err := c.Socket.NextWriter(websocket.TextMessage)
 			if err != nil {
+				c.Manager.recordSendFailure(FailureWrite)
 				return
 			}
 			w.Write(message)
@@ -360,8 +715,10 @@ func (c *Client) writeMessages() {
 			}
 
 			if err := w.Close(); err != nil {
+				c.Manager.recordSendFailure(FailureWrite)
 				return
 			}
+			c.touch()
 
 		case <-ticker.C:
 			c.Socket.SetWriteDeadline(time.Now().Add(10 * time.Second))
@@ -381,8 +738,7 @@ func (m *Manager) Shutdown(ctx context.Context) error {
 
 	// Close all client connections
 	for _, client := range m.clients {
-		close(client.Send)
-		client.Socket.Close()
+		m.closeClient(client)
 	}
 
 	// Clear clients map
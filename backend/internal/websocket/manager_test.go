@@ -0,0 +1,191 @@
+package websocket
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toeic-app/internal/token"
+)
+
+// countingObserver counts the events the manager reports
+type countingObserver struct {
+	mu            sync.Mutex
+	connections   int
+	subscriptions map[string]int
+	sent          map[string]int
+	received      map[string]int
+	failures      map[string]int
+}
+
+func newCountingObserver() *countingObserver {
+	return &countingObserver{subscriptions: map[string]int{}, sent: map[string]int{}, received: map[string]int{}, failures: map[string]int{}}
+}
+
+func (o *countingObserver) update(change func()) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	change()
+}
+
+func (o *countingObserver) ConnectionOpened()       { o.update(func() { o.connections++ }) }
+func (o *countingObserver) ConnectionClosed()       { o.update(func() { o.connections-- }) }
+func (o *countingObserver) Subscribed(topic string) { o.update(func() { o.subscriptions[topic]++ }) }
+func (o *countingObserver) Unsubscribed(topic string) {
+	o.update(func() { o.subscriptions[topic]-- })
+}
+func (o *countingObserver) MessageSent(messageType string) {
+	o.update(func() { o.sent[messageType]++ })
+}
+func (o *countingObserver) MessageReceived(messageType string) {
+	o.update(func() { o.received[messageType]++ })
+}
+func (o *countingObserver) SendFailed(reason string) { o.update(func() { o.failures[reason]++ }) }
+
+func (o *countingObserver) snapshot() (int, map[string]int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	subscriptions := map[string]int{}
+	for topic, count := range o.subscriptions {
+		subscriptions[topic] = count
+	}
+	return o.connections, subscriptions
+}
+
+func newTestManager(t *testing.T) (*Manager, *countingObserver, func(username string) *websocket.Conn) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	maker, err := token.NewJWTMaker("test-symmetric-key-0123456789abcdef")
+	require.NoError(t, err)
+
+	manager := NewManager()
+	observer := newCountingObserver()
+	manager.SetObserver(observer)
+	manager.Start()
+
+	router := gin.New()
+	router.GET("/ws", func(c *gin.Context) { manager.HandleWebSocket(c, maker) })
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	dial := func(username string) *websocket.Conn {
+		accessToken, err := maker.CreateToken(1, username, time.Hour)
+		require.NoError(t, err)
+		header := http.Header{"Authorization": {"Bearer " + accessToken}}
+		conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", header)
+		require.NoError(t, err)
+		t.Cleanup(func() { conn.Close() })
+		return conn
+	}
+	return manager, observer, dial
+}
+
+// readMessage returns the next message of the given type; queued messages
+// are written in one frame separated by newlines
+func readMessage(t *testing.T, conn *websocket.Conn, messageType string) Message {
+	t.Helper()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	for {
+		_, frame, err := conn.ReadMessage()
+		require.NoError(t, err)
+		for _, line := range bytes.Split(frame, []byte("\n")) {
+			var message Message
+			require.NoError(t, json.Unmarshal(line, &message))
+			if message.Type == messageType {
+				return message
+			}
+		}
+	}
+}
+
+func TestManagerTracksConnectionsAndSubscriptions(t *testing.T) {
+	manager, observer, dial := newTestManager(t)
+
+	conn := dial("user1")
+	readMessage(t, conn, "welcome")
+	require.NoError(t, conn.WriteJSON(clientMessage{Type: "subscribe", Topic: "leaderboard"}))
+	readMessage(t, conn, "subscribed")
+	require.NoError(t, conn.WriteJSON(clientMessage{Type: "subscribe", Topic: "Not A Topic"}))
+	readMessage(t, conn, "error")
+
+	stats := manager.Stats()
+	assert.Equal(t, 1, stats.Connections)
+	assert.Equal(t, map[string]int{"leaderboard": 1}, stats.Subscriptions)
+	assert.Equal(t, int64(2), stats.MessagesReceived)
+
+	connections := manager.Connections()
+	require.Len(t, connections, 1)
+	assert.Equal(t, "user1", connections[0].Username)
+	assert.Equal(t, []string{"leaderboard"}, connections[0].Topics)
+	assert.False(t, connections[0].LastActivity.Before(connections[0].ConnectedAt))
+
+	// Only subscribers get topic messages
+	other := dial("user2")
+	readMessage(t, other, "welcome")
+	require.NoError(t, manager.PublishToTopic("leaderboard", "leaderboard_updated", gin.H{"rank": 1}))
+	message := readMessage(t, conn, "leaderboard_updated")
+	assert.Equal(t, map[string]interface{}{"rank": float64(1)}, message.Data)
+
+	connected, subscriptions := observer.snapshot()
+	assert.Equal(t, 2, connected)
+	assert.Equal(t, map[string]int{"leaderboard": 1}, subscriptions)
+	observer.update(func() {
+		assert.Equal(t, 1, observer.sent["leaderboard_updated"])
+		assert.Equal(t, 2, observer.received["subscribe"])
+	})
+
+	// Closing the connection drops its subscriptions
+	conn.Close()
+	require.Eventually(t, func() bool { return manager.Stats().Connections == 1 }, 5*time.Second, 10*time.Millisecond)
+	connected, subscriptions = observer.snapshot()
+	assert.Equal(t, 1, connected)
+	assert.Equal(t, map[string]int{"leaderboard": 0}, subscriptions)
+}
+
+func TestManagerAuthorizesTopics(t *testing.T) {
+	manager, observer, dial := newTestManager(t)
+	manager.SetTopicAuthorizer(func(userID int32, topic string) bool {
+		return !strings.HasPrefix(topic, AdminTopicPrefix)
+	})
+
+	conn := dial("user1")
+	readMessage(t, conn, "welcome")
+	require.NoError(t, conn.WriteJSON(clientMessage{Type: "subscribe", Topic: "admin.restores"}))
+	message := readMessage(t, conn, "error")
+	assert.Equal(t, map[string]interface{}{"message": "topic not allowed"}, message.Data)
+	require.NoError(t, conn.WriteJSON(clientMessage{Type: "subscribe", Topic: "exam.1.leaderboard"}))
+	readMessage(t, conn, "subscribed")
+
+	_, subscriptions := observer.snapshot()
+	assert.Equal(t, map[string]int{"exam.1.leaderboard": 1}, subscriptions)
+}
+
+func TestManagerReplacesConnectionOfSameUser(t *testing.T) {
+	manager, observer, dial := newTestManager(t)
+
+	first := dial("user1")
+	readMessage(t, first, "welcome")
+	second := dial("user1")
+	readMessage(t, second, "welcome")
+
+	// The replaced connection closing must not remove its successor
+	require.Eventually(t, func() bool {
+		connected, _ := observer.snapshot()
+		return connected == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 1, manager.Stats().Connections)
+	assert.True(t, manager.IsConnected("user1"))
+
+	require.NoError(t, manager.SendToUser("user1", "ping_test", nil))
+	readMessage(t, second, "ping_test")
+}
@@ -0,0 +1,155 @@
+package wordlink
+
+import (
+	"strings"
+	"unicode"
+)
+
+// maxPhraseWords is the longest dictionary phrase that is matched
+const maxPhraseWords = 4
+
+// minWordLength keeps letters and abbreviations like "a" or "am" from
+// being linked
+const minWordLength = 3
+
+// stopWords are dictionary words too common to be worth a link on their own
+var stopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "are": true, "but": true, "not": true,
+	"you": true, "all": true, "any": true, "can": true, "her": true, "was": true,
+	"one": true, "our": true, "out": true, "has": true, "his": true, "how": true,
+	"its": true, "who": true, "did": true, "yes": true, "she": true, "him": true,
+	"they": true, "this": true, "that": true, "with": true, "from": true, "have": true,
+	"will": true, "what": true, "when": true, "which": true, "there": true, "their": true,
+	"would": true, "about": true, "been": true, "were": true, "into": true, "than": true,
+	"then": true, "them": true, "these": true, "those": true, "some": true, "does": true,
+}
+
+// token is a word of a text with its position in characters
+type token struct {
+	text       string
+	start, end int
+}
+
+// tokenize splits text into lowercase words. Apostrophes and hyphens
+// inside a word belong to it.
+func tokenize(text string) []token {
+	runes := []rune(text)
+	var tokens []token
+	for i := 0; i < len(runes); {
+		if !unicode.IsLetter(runes[i]) {
+			i++
+			continue
+		}
+		start := i
+		for i < len(runes) && (unicode.IsLetter(runes[i]) ||
+			(isJoiner(runes[i]) && i+1 < len(runes) && unicode.IsLetter(runes[i+1]))) {
+			i++
+		}
+		tokens = append(tokens, token{text: strings.ToLower(string(runes[start:i])), start: start, end: i})
+	}
+	return tokens
+}
+
+func isJoiner(r rune) bool {
+	return r == '-' || r == '\'' || r == '’'
+}
+
+// normalize turns a dictionary entry into the form tokens are matched in
+func normalize(word string) string {
+	tokens := tokenize(word)
+	parts := make([]string, len(tokens))
+	for i, token := range tokens {
+		parts[i] = token.text
+	}
+	return strings.Join(parts, " ")
+}
+
+// stems lists base forms an inflected word may come from, e.g. "invoices"
+// from "invoice" or "shipped" from "ship"
+func stems(word string) []string {
+	var candidates []string
+	add := func(suffix, replacement string) {
+		if strings.HasSuffix(word, suffix) && len(word)-len(suffix) >= minWordLength {
+			candidates = append(candidates, word[:len(word)-len(suffix)]+replacement)
+		}
+	}
+	add("ies", "y")
+	add("es", "")
+	add("s", "")
+	add("ied", "y")
+	add("ed", "")
+	add("ed", "e")
+	add("ing", "")
+	add("ing", "e")
+	// Doubled consonants, as in "shipped" or "planning"
+	for _, suffix := range []string{"ed", "ing"} {
+		base := strings.TrimSuffix(word, suffix)
+		if base != word && len(base) > minWordLength && base[len(base)-1] == base[len(base)-2] {
+			candidates = append(candidates, base[:len(base)-1])
+		}
+	}
+	return candidates
+}
+
+// index finds dictionary words in texts
+type index struct {
+	words map[string]Entry
+}
+
+func newIndex(entries []Entry) *index {
+	idx := &index{words: make(map[string]Entry, len(entries))}
+	for _, entry := range entries {
+		key := normalize(entry.Word)
+		if key == "" {
+			continue
+		}
+		// Keep the first entry of words listed twice
+		if _, ok := idx.words[key]; !ok {
+			idx.words[key] = entry
+		}
+	}
+	return idx
+}
+
+// match links the longest dictionary phrases found in text, left to right
+// and without overlaps
+func (idx *index) match(text string) []Link {
+	tokens := tokenize(text)
+	var links []Link
+	for i := 0; i < len(tokens); {
+		n, entry, ok := idx.longest(tokens[i:])
+		if !ok {
+			i++
+			continue
+		}
+		links = append(links, Link{Start: tokens[i].start, End: tokens[i+n-1].end, WordID: entry.WordID})
+		i += n
+	}
+	return links
+}
+
+func (idx *index) longest(tokens []token) (int, Entry, bool) {
+	for n := min(maxPhraseWords, len(tokens)); n > 1; n-- {
+		parts := make([]string, n)
+		for i := range parts {
+			parts[i] = tokens[i].text
+		}
+		if entry, ok := idx.words[strings.Join(parts, " ")]; ok {
+			return n, entry, true
+		}
+	}
+
+	word := tokens[0].text
+	if len([]rune(word)) < minWordLength || stopWords[word] {
+		return 0, Entry{}, false
+	}
+	if entry, ok := idx.words[word]; ok {
+		return 1, entry, true
+	}
+	for _, stem := range stems(word) {
+		if entry, ok := idx.words[stem]; ok && !stopWords[stem] {
+			return 1, entry, true
+		}
+	}
+	return 0, Entry{}, false
+}
@@ -0,0 +1,144 @@
+// Package wordlink finds dictionary words in question texts and
+// explanations so clients can link them to their entries and save them to
+// a study set.
+package wordlink
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+// Entry is a dictionary word that can be linked
+type Entry struct {
+	WordID    int32  `json:"word_id"`
+	Word      string `json:"word"`
+	Pronounce string `json:"pronounce,omitempty"`
+	ShortMean string `json:"short_mean,omitempty"`
+	Level     int32  `json:"level,omitempty"`
+}
+
+// Link is a dictionary word found in a text. Start and End count
+// characters, not bytes; End is exclusive.
+type Link struct {
+	// Field names the text the word was found in, e.g. "explanation"
+	Field  string `json:"field"`
+	Start  int    `json:"start"`
+	End    int    `json:"end"`
+	WordID int32  `json:"word_id"`
+}
+
+// Service matches texts against the words table. The dictionary is loaded
+// once and kept for the cache TTL.
+type Service struct {
+	store db.Querier
+	ttl   time.Duration
+	now   func() time.Time
+
+	mutex    sync.Mutex
+	index    *index
+	entries  map[int32]Entry
+	loadedAt time.Time
+}
+
+// NewService creates a word linker that reloads the dictionary after ttl
+func NewService(store db.Querier, ttl time.Duration) *Service {
+	return &Service{store: store, ttl: ttl, now: time.Now}
+}
+
+// load returns the cached dictionary, reloading it when it is older than
+// the TTL. Concurrent callers wait for a single reload.
+func (s *Service) load(ctx context.Context) (*index, map[int32]Entry, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.index != nil && s.now().Sub(s.loadedAt) < s.ttl {
+		return s.index, s.entries, nil
+	}
+	rows, err := s.store.ListWordsForLinking(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load words: %w", err)
+	}
+	list := make([]Entry, 0, len(rows))
+	entries := make(map[int32]Entry, len(rows))
+	for _, row := range rows {
+		entry := Entry{
+			WordID:    row.ID,
+			Word:      row.Word,
+			Pronounce: row.Pronounce,
+			ShortMean: row.ShortMean,
+			Level:     row.Level,
+		}
+		list = append(list, entry)
+		entries[row.ID] = entry
+	}
+	s.index, s.entries, s.loadedAt = newIndex(list), entries, s.now()
+	return s.index, s.entries, nil
+}
+
+// Invalidate makes the next match reload the dictionary
+func (s *Service) Invalidate() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.index = nil
+}
+
+// Links finds dictionary words in text, preferring the longest phrase
+func (s *Service) Links(ctx context.Context, field, text string) ([]Link, error) {
+	idx, _, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	links := idx.match(text)
+	for i := range links {
+		links[i].Field = field
+	}
+	return links, nil
+}
+
+// Entries returns the dictionary entries of the linked words, in the order
+// they were first linked
+func (s *Service) Entries(ctx context.Context, links []Link) ([]Entry, error) {
+	_, entries, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[int32]bool)
+	var result []Entry
+	for _, link := range links {
+		entry, ok := entries[link.WordID]
+		if !ok || seen[link.WordID] {
+			continue
+		}
+		seen[link.WordID] = true
+		result = append(result, entry)
+	}
+	return result, nil
+}
+
+// SavedIn returns, for each word, the IDs of the user's study sets that
+// already contain it
+func (s *Service) SavedIn(ctx context.Context, userID int32, wordIDs []int32) (map[int32][]int32, error) {
+	saved := make(map[int32][]int32)
+	if len(wordIDs) == 0 {
+		return saved, nil
+	}
+	rows, err := s.store.ListStudySetWordsForUser(ctx, db.ListStudySetWordsForUserParams{
+		UserID:  userID,
+		WordIds: wordIDs,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list study set words: %w", err)
+	}
+	for _, row := range rows {
+		saved[row.WordID] = append(saved[row.WordID], row.StudySetID)
+	}
+	for _, ids := range saved {
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	}
+	return saved, nil
+}
@@ -0,0 +1,98 @@
+package wordlink
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+// fakeStore serves a fixed dictionary and counts how often it is loaded
+type fakeStore struct {
+	db.Querier
+	words []db.ListWordsForLinkingRow
+	loads int
+}
+
+func (f *fakeStore) ListWordsForLinking(context.Context) ([]db.ListWordsForLinkingRow, error) {
+	f.loads++
+	return f.words, nil
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{words: []db.ListWordsForLinkingRow{
+		{ID: 1, Word: "invoice", ShortMean: "hóa đơn"},
+		{ID: 2, Word: "ship"},
+		{ID: 3, Word: "take over"},
+		{ID: 4, Word: "take"},
+		{ID: 5, Word: "the"},
+		{ID: 6, Word: "supply"},
+		{ID: 7, Word: "over"},
+	}}
+}
+
+// linked returns the text of each link
+func linked(text string, links []Link) []string {
+	runes := []rune(text)
+	var words []string
+	for _, link := range links {
+		words = append(words, string(runes[link.Start:link.End]))
+	}
+	return words
+}
+
+func TestLinksPreferLongestPhrase(t *testing.T) {
+	service := NewService(newFakeStore(), time.Hour)
+	text := "Ms. Lê will take over the invoices; we shipped supplies."
+
+	links, err := service.Links(context.Background(), "explanation", text)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"take over", "invoices", "shipped", "supplies"}, linked(text, links))
+	assert.Equal(t, int32(3), links[0].WordID)
+	assert.Equal(t, int32(1), links[1].WordID)
+	assert.Equal(t, int32(2), links[2].WordID)
+	assert.Equal(t, int32(6), links[3].WordID)
+	assert.Equal(t, "explanation", links[0].Field)
+
+	entries, err := service.Entries(context.Background(), append(links, links[1]))
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+	assert.Equal(t, "hóa đơn", entries[1].ShortMean)
+}
+
+func TestLinksCountCharacters(t *testing.T) {
+	service := NewService(newFakeStore(), time.Hour)
+	text := "Hóa đơn: invoice"
+
+	links, err := service.Links(context.Background(), "question", text)
+	require.NoError(t, err)
+	require.Len(t, links, 1)
+	assert.Equal(t, 9, links[0].Start)
+	assert.Equal(t, 16, links[0].End)
+}
+
+func TestDictionaryIsCached(t *testing.T) {
+	store := newFakeStore()
+	service := NewService(store, time.Minute)
+	now := time.Now()
+	service.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		_, err := service.Links(context.Background(), "question", "invoice")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, store.loads)
+
+	now = now.Add(2 * time.Minute)
+	_, err := service.Links(context.Background(), "question", "invoice")
+	require.NoError(t, err)
+	assert.Equal(t, 2, store.loads)
+
+	service.Invalidate()
+	_, err = service.Links(context.Background(), "question", "invoice")
+	require.NoError(t, err)
+	assert.Equal(t, 3, store.loads)
+}
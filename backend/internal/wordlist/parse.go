@@ -0,0 +1,218 @@
+package wordlist
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var (
+	ErrMissingWordColumn = errors.New("list needs a word, lemma or headword column")
+	ErrInvalidFile       = errors.New("list is not a readable CSV file")
+	ErrTooManyEntries    = fmt.Errorf("list has more than %d entries", MaxEntries)
+)
+
+// MaxEntries bounds the size of one import
+const MaxEntries = 20000
+
+// Entry is one row of a vocabulary list
+type Entry struct {
+	Line      int      `json:"line"`
+	Word      string   `json:"word"`
+	Rank      int32    `json:"rank,omitempty"`
+	Frequency float64  `json:"frequency,omitempty"`
+	Level     int32    `json:"level,omitempty"`
+	CEFR      string   `json:"cefr,omitempty"`
+	Meaning   string   `json:"meaning,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+}
+
+// RowError is a row that could not be read
+type RowError struct {
+	Line  int    `json:"line"`
+	Word  string `json:"word,omitempty"`
+	Error string `json:"error"`
+}
+
+// columnAliases maps header names used by published lists to entry fields.
+// NGSL and TSL releases use "Lemma", "SFI Rank", "SFI" and "U".
+var columnAliases = map[string]string{
+	"word":       "word",
+	"lemma":      "word",
+	"headword":   "word",
+	"rank":       "rank",
+	"sfi rank":   "rank",
+	"frequency":  "frequency",
+	"freq":       "frequency",
+	"sfi":        "frequency",
+	"u":          "frequency",
+	"level":      "level",
+	"cefr":       "cefr",
+	"meaning":    "meaning",
+	"short_mean": "meaning",
+	"tags":       "tags",
+}
+
+// Parse reads a CSV vocabulary list with a header row. Rows that cannot be
+// read are reported instead of failing the whole list.
+func Parse(r io.Reader) ([]Entry, []RowError, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil, ErrMissingWordColumn
+		}
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if field, ok := columnAliases[name]; ok {
+			if _, seen := columns[field]; !seen {
+				columns[field] = i
+			}
+		}
+	}
+	if _, ok := columns["word"]; !ok {
+		return nil, nil, ErrMissingWordColumn
+	}
+
+	var entries []Entry
+	var rowErrors []RowError
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				rowErrors = append(rowErrors, RowError{Line: parseErr.StartLine, Error: parseErr.Err.Error()})
+				continue
+			}
+			return nil, nil, fmt.Errorf("failed to read list: %w", err)
+		}
+		// Line numbers in reports match the file, which may contain blank lines
+		line, _ := reader.FieldPos(0)
+		if len(entries) >= MaxEntries {
+			return nil, nil, ErrTooManyEntries
+		}
+
+		field := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		entry, err := parseEntry(line, field)
+		if err != nil {
+			rowErrors = append(rowErrors, RowError{Line: line, Word: field("word"), Error: err.Error()})
+			continue
+		}
+		if entry.Word == "" {
+			// Rows without a word separate bands in some published lists
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rowErrors, nil
+}
+
+func parseEntry(line int, field func(string) string) (Entry, error) {
+	entry := Entry{
+		Line:    line,
+		Word:    NormalizeWord(field("word")),
+		CEFR:    strings.ToUpper(field("cefr")),
+		Meaning: field("meaning"),
+		Tags:    ParseTags(field("tags")),
+	}
+	if entry.Word == "" {
+		return entry, nil
+	}
+	if !validWord(entry.Word) {
+		return entry, fmt.Errorf("invalid word %q", entry.Word)
+	}
+
+	if value := field("rank"); value != "" {
+		rank, err := strconv.ParseInt(value, 10, 32)
+		if err != nil || rank < 1 {
+			return entry, fmt.Errorf("invalid rank %q", value)
+		}
+		entry.Rank = int32(rank)
+	}
+	if value := field("frequency"); value != "" {
+		frequency, err := strconv.ParseFloat(value, 64)
+		if err != nil || frequency < 0 {
+			return entry, fmt.Errorf("invalid frequency %q", value)
+		}
+		entry.Frequency = frequency
+	}
+	if value := field("level"); value != "" {
+		level, err := strconv.ParseInt(value, 10, 32)
+		if err != nil || level < 1 {
+			return entry, fmt.Errorf("invalid level %q", value)
+		}
+		entry.Level = int32(level)
+	}
+	if entry.CEFR != "" && !validCEFR(entry.CEFR) {
+		return entry, fmt.Errorf("invalid CEFR level %q", entry.CEFR)
+	}
+	return entry, nil
+}
+
+// NormalizeWord lowercases a word and collapses inner whitespace; words are
+// matched against the dictionary in this form
+func NormalizeWord(word string) string {
+	return strings.Join(strings.Fields(strings.ToLower(word)), " ")
+}
+
+// ParseTags splits a ";" or "|" separated tag list into normalized tags
+func ParseTags(value string) []string {
+	var tags []string
+	for _, tag := range strings.FieldsFunc(value, func(r rune) bool { return r == ';' || r == '|' }) {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" && len(tag) <= 50 {
+			tags = appendUnique(tags, tag)
+		}
+	}
+	return tags
+}
+
+func validWord(word string) bool {
+	if len(word) > 100 {
+		return false
+	}
+	for _, r := range word {
+		if !unicode.IsLetter(r) && r != '-' && r != '\'' && r != ' ' && r != '.' {
+			return false
+		}
+	}
+	return true
+}
+
+func validCEFR(level string) bool {
+	switch level {
+	case "A1", "A2", "B1", "B2", "C1", "C2":
+		return true
+	}
+	return false
+}
+
+func appendUnique(values []string, value string) []string {
+	if slices.Contains(values, value) {
+		return values
+	}
+	return append(values, value)
+}
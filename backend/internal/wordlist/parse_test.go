@@ -0,0 +1,64 @@
+package wordlist
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNGSLColumns(t *testing.T) {
+	list := "\ufeffLemma,SFI Rank,SFI,U\nThe,1,77.4,60000\n\nBe,2,76.1,42000\nInvoice,2731,48.2,21.5\n"
+
+	entries, rowErrors, err := Parse(strings.NewReader(list))
+	require.NoError(t, err)
+	assert.Empty(t, rowErrors)
+	require.Len(t, entries, 3)
+	assert.Equal(t, Entry{Line: 2, Word: "the", Rank: 1, Frequency: 77.4}, entries[0])
+	assert.Equal(t, Entry{Line: 5, Word: "invoice", Rank: 2731, Frequency: 48.2}, entries[2])
+}
+
+func TestParseOptionalColumns(t *testing.T) {
+	list := "word,level,cefr,meaning,tags\n  Check   In ,2,b1,register at a hotel,Travel; hotel|travel\n"
+
+	entries, rowErrors, err := Parse(strings.NewReader(list))
+	require.NoError(t, err)
+	assert.Empty(t, rowErrors)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "check in", entries[0].Word)
+	assert.Equal(t, int32(2), entries[0].Level)
+	assert.Equal(t, "B1", entries[0].CEFR)
+	assert.Equal(t, "register at a hotel", entries[0].Meaning)
+	assert.Equal(t, []string{"travel", "hotel"}, entries[0].Tags)
+}
+
+func TestParseReportsInvalidRows(t *testing.T) {
+	list := "word,rank,cefr\nbudget,x,\nrev3nue,4,\nprofit,5,D1\nagenda,6,A2\n"
+
+	entries, rowErrors, err := Parse(strings.NewReader(list))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "agenda", entries[0].Word)
+	require.Len(t, rowErrors, 3)
+	assert.Equal(t, RowError{Line: 2, Word: "budget", Error: `invalid rank "x"`}, rowErrors[0])
+	assert.Equal(t, 3, rowErrors[1].Line)
+	assert.Equal(t, 4, rowErrors[2].Line)
+}
+
+func TestParseRequiresWordColumn(t *testing.T) {
+	_, _, err := Parse(strings.NewReader("rank,frequency\n1,2\n"))
+	assert.ErrorIs(t, err, ErrMissingWordColumn)
+
+	_, _, err = Parse(strings.NewReader(""))
+	assert.ErrorIs(t, err, ErrMissingWordColumn)
+}
+
+func TestTaggerMatchesWordAndMeaning(t *testing.T) {
+	tagger := DefaultTagger()
+
+	assert.Equal(t, []string{TagBusiness}, tagger.Tags("Invoice", ""))
+	assert.Equal(t, []string{TagTravel}, tagger.Tags("board", "to get on a flight"))
+	assert.Equal(t, []string{TagBusiness, TagTravel}, tagger.Tags("expense", "cost of a business trip"))
+	assert.Empty(t, tagger.Tags("happy", "feeling joy"))
+}
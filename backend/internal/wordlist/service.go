@@ -0,0 +1,511 @@
+package wordlist
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/sqlc-dev/pqtype"
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/logger"
+	"github.com/toeic-app/internal/wordsense"
+)
+
+var (
+	ErrImportNotFound   = errors.New("word import not found")
+	ErrImportFinished   = errors.New("word import was already committed or discarded")
+	ErrInvalidListName  = errors.New("list name must be 1-50 letters, digits, '-' or '_'")
+	ErrEmptyList        = errors.New("list has no words")
+	ErrInvalidTag       = errors.New("tags must be at most 50 characters")
+	errDuplicateWordRow = errors.New("word already exists")
+)
+
+// Planned changes of an import item
+const (
+	ActionCreate    = "create"    // The word is added to the dictionary
+	ActionLink      = "link"      // An existing word joins the list, changes rank or gains tags
+	ActionUnchanged = "unchanged" // The word is already in the list with the same rank and tags
+	ActionDuplicate = "duplicate" // The word appears earlier in the same list
+)
+
+// Import statuses
+const (
+	StatusPending   = "pending"
+	StatusCommitted = "committed"
+	StatusDiscarded = "discarded"
+)
+
+// Sources of word tags
+const (
+	TagSourceImport = "import"
+	TagSourceAuto   = "auto"
+)
+
+var listNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,50}$`)
+
+// Item is a list entry with the change committing the import makes
+type Item struct {
+	Entry
+	Action       string   `json:"action"`
+	WordID       int32    `json:"word_id,omitempty"`
+	PreviousRank int32    `json:"previous_rank,omitempty"`
+	AddTags      []string `json:"add_tags,omitempty"`
+	AutoTags     []string `json:"auto_tags,omitempty"`
+	DuplicateOf  int      `json:"duplicate_of,omitempty"`
+}
+
+// Summary counts the planned changes of an import
+type Summary struct {
+	Total     int            `json:"total"`
+	Create    int            `json:"create"`
+	Link      int            `json:"link"`
+	Unchanged int            `json:"unchanged"`
+	Duplicate int            `json:"duplicate"`
+	Invalid   int            `json:"invalid"`
+	Tags      map[string]int `json:"tags"`
+}
+
+// Result is the outcome of committing an import
+type Result struct {
+	Created int        `json:"created"`
+	Linked  int        `json:"linked"`
+	Tagged  int        `json:"tagged"`
+	Failed  int        `json:"failed"`
+	Errors  []RowError `json:"errors,omitempty"`
+}
+
+// Import is a previewed, committed or discarded list import
+type Import struct {
+	ID         int32      `json:"id"`
+	ListName   string     `json:"list_name"`
+	Filename   string     `json:"filename,omitempty"`
+	Status     string     `json:"status"`
+	Summary    Summary    `json:"summary"`
+	Items      []Item     `json:"items,omitempty"`
+	Invalid    []RowError `json:"invalid,omitempty"`
+	Result     *Result    `json:"result,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// PreviewRequest describes a list to import
+type PreviewRequest struct {
+	ListName  string
+	Filename  string
+	Tags      []string // Applied to every entry
+	CreatedBy int32
+}
+
+// storedItems is the items column of an import
+type storedItems struct {
+	Items   []Item     `json:"items"`
+	Invalid []RowError `json:"invalid,omitempty"`
+}
+
+// Service imports standard vocabulary lists into the dictionary
+type Service struct {
+	store  db.Querier
+	tagger *Tagger
+	senses *wordsense.Service
+}
+
+// NewService creates a new word list import service
+func NewService(store db.Querier, tagger *Tagger) *Service {
+	return &Service{store: store, tagger: tagger, senses: wordsense.NewService(store)}
+}
+
+// Preview parses a list and stores the changes committing it would make
+// without changing any word
+func (s *Service) Preview(ctx context.Context, req PreviewRequest, r io.Reader) (*Import, error) {
+	if !listNamePattern.MatchString(req.ListName) {
+		return nil, ErrInvalidListName
+	}
+	listName := strings.ToUpper(req.ListName)
+	for _, tag := range req.Tags {
+		if len(tag) > 50 {
+			return nil, ErrInvalidTag
+		}
+	}
+
+	entries, invalid, err := Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, ErrEmptyList
+	}
+
+	items, err := s.plan(ctx, listName, entries, ParseTags(strings.Join(req.Tags, ";")))
+	if err != nil {
+		return nil, err
+	}
+	summary := summarize(items, invalid)
+
+	summaryJSON, err := json.Marshal(summary)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode import summary: %w", err)
+	}
+	itemsJSON, err := json.Marshal(storedItems{Items: items, Invalid: invalid})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode import items: %w", err)
+	}
+
+	record, err := s.store.CreateWordImport(ctx, db.CreateWordImportParams{
+		ListName:  listName,
+		Filename:  req.Filename,
+		Summary:   summaryJSON,
+		Items:     itemsJSON,
+		CreatedBy: sql.NullInt32{Int32: req.CreatedBy, Valid: req.CreatedBy != 0},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save word import: %w", err)
+	}
+
+	logger.Info("Word list import %d previewed: list=%s create=%d link=%d unchanged=%d duplicate=%d invalid=%d",
+		record.ID, listName, summary.Create, summary.Link, summary.Unchanged, summary.Duplicate, summary.Invalid)
+	return newImport(record, true)
+}
+
+// plan matches entries against existing words, list entries and tags
+func (s *Service) plan(ctx context.Context, listName string, entries []Entry, tags []string) ([]Item, error) {
+	firstLine := make(map[string]int)
+	words := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if _, ok := firstLine[entry.Word]; !ok {
+			firstLine[entry.Word] = entry.Line
+			words = append(words, entry.Word)
+		}
+	}
+
+	existing, err := s.store.ListWordsByLowerText(ctx, words)
+	if err != nil {
+		return nil, fmt.Errorf("failed to match existing words: %w", err)
+	}
+	wordIDs := make(map[string]int32, len(existing))
+	ids := make([]int32, 0, len(existing))
+	for _, word := range existing {
+		normalized := NormalizeWord(word.Word)
+		// Several spellings may differ only in case; prefer the lowest ID
+		if id, ok := wordIDs[normalized]; !ok || word.ID < id {
+			wordIDs[normalized] = word.ID
+		}
+		ids = append(ids, word.ID)
+	}
+
+	ranks := make(map[int32]sql.NullInt32)
+	wordTags := make(map[int32]map[string]bool)
+	if len(ids) > 0 {
+		listed, err := s.store.ListWordListEntriesForWords(ctx, db.ListWordListEntriesForWordsParams{ListName: listName, WordIds: ids})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get list entries: %w", err)
+		}
+		for _, entry := range listed {
+			ranks[entry.WordID] = entry.Rank
+		}
+
+		tagged, err := s.store.ListWordTagsForWords(ctx, ids)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get word tags: %w", err)
+		}
+		for _, tag := range tagged {
+			if wordTags[tag.WordID] == nil {
+				wordTags[tag.WordID] = make(map[string]bool)
+			}
+			wordTags[tag.WordID][tag.Tag] = true
+		}
+	}
+
+	items := make([]Item, 0, len(entries))
+	for _, entry := range entries {
+		item := Item{Entry: entry}
+		if line := firstLine[entry.Word]; line != entry.Line {
+			item.Action = ActionDuplicate
+			item.DuplicateOf = line
+			items = append(items, item)
+			continue
+		}
+
+		wordID, exists := wordIDs[entry.Word]
+		current := wordTags[wordID]
+		for _, tag := range append(append([]string{}, tags...), entry.Tags...) {
+			if !current[tag] {
+				item.AddTags = appendUnique(item.AddTags, tag)
+			}
+		}
+		for _, tag := range s.tagger.Tags(entry.Word, entry.Meaning) {
+			if !current[tag] && !slices.Contains(item.AddTags, tag) {
+				item.AutoTags = appendUnique(item.AutoTags, tag)
+			}
+		}
+
+		switch {
+		case !exists:
+			item.Action = ActionCreate
+		default:
+			item.WordID = wordID
+			rank, listed := ranks[wordID]
+			if listed && rank.Valid {
+				item.PreviousRank = rank.Int32
+			}
+			if listed && rank.Int32 == entry.Rank && len(item.AddTags) == 0 && len(item.AutoTags) == 0 {
+				item.Action = ActionUnchanged
+			} else {
+				item.Action = ActionLink
+			}
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// Commit applies the changes of a previewed import
+func (s *Service) Commit(ctx context.Context, id int32) (*Import, error) {
+	record, stored, err := s.pending(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	result := Result{}
+	for _, item := range stored.Items {
+		if item.Action != ActionCreate && item.Action != ActionLink {
+			continue
+		}
+		if err := s.apply(ctx, record.ListName, &item, &result); err != nil {
+			result.Failed++
+			result.Errors = append(result.Errors, RowError{Line: item.Line, Word: item.Word, Error: err.Error()})
+		}
+	}
+
+	finished, err := s.finish(ctx, id, StatusCommitted, &result)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("Word list import %d committed: list=%s created=%d linked=%d tagged=%d failed=%d",
+		id, record.ListName, result.Created, result.Linked, result.Tagged, result.Failed)
+	return finished, nil
+}
+
+func (s *Service) apply(ctx context.Context, listName string, item *Item, result *Result) error {
+	wordID := item.WordID
+	if item.Action == ActionCreate {
+		created, err := s.createWord(ctx, item.Entry)
+		switch {
+		case err == nil:
+			wordID = created
+			result.Created++
+		case errors.Is(err, errDuplicateWordRow):
+			// Added since the preview; link the existing word instead
+			matches, err := s.store.ListWordsByLowerText(ctx, []string{item.Word})
+			if err != nil || len(matches) == 0 {
+				return fmt.Errorf("failed to find word created since the preview: %w", err)
+			}
+			wordID = matches[0].ID
+			result.Linked++
+		default:
+			return err
+		}
+	} else {
+		result.Linked++
+	}
+
+	err := s.store.UpsertWordListEntry(ctx, db.UpsertWordListEntryParams{
+		ListName:  listName,
+		WordID:    wordID,
+		Rank:      sql.NullInt32{Int32: item.Rank, Valid: item.Rank > 0},
+		Frequency: sql.NullFloat64{Float64: item.Frequency, Valid: item.Frequency > 0},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add word to list: %w", err)
+	}
+
+	for _, tags := range []struct {
+		source string
+		values []string
+	}{{TagSourceImport, item.AddTags}, {TagSourceAuto, item.AutoTags}} {
+		for _, tag := range tags.values {
+			if err := s.store.AddWordTag(ctx, db.AddWordTagParams{WordID: wordID, Tag: tag, Source: tags.source}); err != nil {
+				return fmt.Errorf("failed to tag word: %w", err)
+			}
+			result.Tagged++
+		}
+	}
+	return nil
+}
+
+// createWord adds a list word to the dictionary. Pronunciation and meanings
+// left empty are filled in by the dictionary backfill.
+func (s *Service) createWord(ctx context.Context, entry Entry) (int32, error) {
+	word, err := s.store.CreateWord(ctx, db.CreateWordParams{
+		Word:          entry.Word,
+		Level:         levelOf(entry),
+		DescriptLevel: entry.CEFR,
+		ShortMean:     entry.Meaning,
+		Means:         pqtype.NullRawMessage{},
+		Snym:          pqtype.NullRawMessage{},
+		Freq:          float32(entry.Frequency),
+		Conjugation:   pqtype.NullRawMessage{},
+	})
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return 0, errDuplicateWordRow
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to create word: %w", err)
+	}
+	if _, err := s.senses.Sync(ctx, word); err != nil {
+		logger.Warn("Failed to create senses of word %d: %v", word.ID, err)
+	}
+	return word.ID, nil
+}
+
+// Discard drops a previewed import without applying it
+func (s *Service) Discard(ctx context.Context, id int32) error {
+	if _, _, err := s.pending(ctx, id); err != nil {
+		return err
+	}
+	_, err := s.finish(ctx, id, StatusDiscarded, nil)
+	return err
+}
+
+// Get returns an import with its items
+func (s *Service) Get(ctx context.Context, id int32) (*Import, error) {
+	record, err := s.store.GetWordImport(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrImportNotFound
+		}
+		return nil, fmt.Errorf("failed to get word import: %w", err)
+	}
+	return newImport(record, true)
+}
+
+// List returns recent imports without their items
+func (s *Service) List(ctx context.Context, limit, offset int32) ([]Import, error) {
+	records, err := s.store.ListWordImports(ctx, db.ListWordImportsParams{Limit: limit, Offset: offset})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list word imports: %w", err)
+	}
+
+	imports := make([]Import, 0, len(records))
+	for _, record := range records {
+		imp, err := newImport(record, false)
+		if err != nil {
+			return nil, err
+		}
+		imports = append(imports, *imp)
+	}
+	return imports, nil
+}
+
+func (s *Service) pending(ctx context.Context, id int32) (db.WordImport, storedItems, error) {
+	var stored storedItems
+	record, err := s.store.GetWordImport(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return record, stored, ErrImportNotFound
+		}
+		return record, stored, fmt.Errorf("failed to get word import: %w", err)
+	}
+	if record.Status != StatusPending {
+		return record, stored, ErrImportFinished
+	}
+	if err := json.Unmarshal(record.Items, &stored); err != nil {
+		return record, stored, fmt.Errorf("failed to decode import items: %w", err)
+	}
+	return record, stored, nil
+}
+
+func (s *Service) finish(ctx context.Context, id int32, status string, result *Result) (*Import, error) {
+	var resultJSON pqtype.NullRawMessage
+	if result != nil {
+		encoded, err := json.Marshal(result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode import result: %w", err)
+		}
+		resultJSON = pqtype.NullRawMessage{RawMessage: encoded, Valid: true}
+	}
+
+	record, err := s.store.FinishWordImport(ctx, db.FinishWordImportParams{ID: id, Status: status, Result: resultJSON})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// Finished concurrently
+			return nil, ErrImportFinished
+		}
+		return nil, fmt.Errorf("failed to finish word import: %w", err)
+	}
+	return newImport(record, true)
+}
+
+func summarize(items []Item, invalid []RowError) Summary {
+	summary := Summary{Total: len(items) + len(invalid), Invalid: len(invalid), Tags: make(map[string]int)}
+	for _, item := range items {
+		switch item.Action {
+		case ActionCreate:
+			summary.Create++
+		case ActionLink:
+			summary.Link++
+		case ActionUnchanged:
+			summary.Unchanged++
+		case ActionDuplicate:
+			summary.Duplicate++
+		}
+		for _, tag := range item.AddTags {
+			summary.Tags[tag]++
+		}
+		for _, tag := range item.AutoTags {
+			summary.Tags[tag]++
+		}
+	}
+	return summary
+}
+
+func newImport(record db.WordImport, withItems bool) (*Import, error) {
+	imp := &Import{
+		ID:        record.ID,
+		ListName:  record.ListName,
+		Filename:  record.Filename,
+		Status:    record.Status,
+		CreatedAt: record.CreatedAt,
+	}
+	if record.FinishedAt.Valid {
+		imp.FinishedAt = &record.FinishedAt.Time
+	}
+	if err := json.Unmarshal(record.Summary, &imp.Summary); err != nil {
+		return nil, fmt.Errorf("failed to decode import summary: %w", err)
+	}
+	if record.Result.Valid {
+		imp.Result = &Result{}
+		if err := json.Unmarshal(record.Result.RawMessage, imp.Result); err != nil {
+			return nil, fmt.Errorf("failed to decode import result: %w", err)
+		}
+	}
+	if withItems {
+		var stored storedItems
+		if err := json.Unmarshal(record.Items, &stored); err != nil {
+			return nil, fmt.Errorf("failed to decode import items: %w", err)
+		}
+		imp.Items = stored.Items
+		imp.Invalid = stored.Invalid
+	}
+	return imp, nil
+}
+
+// levelOf returns the level of a new word: the level column of the list, or
+// the 1000-word band of its rank
+func levelOf(entry Entry) int32 {
+	if entry.Level > 0 {
+		return entry.Level
+	}
+	if entry.Rank > 0 {
+		return (entry.Rank-1)/1000 + 1
+	}
+	return 1
+}
@@ -0,0 +1,267 @@
+package wordlist
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+type listKey struct {
+	list   string
+	wordID int32
+}
+
+// wordStore keeps words, list entries, tags and imports in memory; other
+// Querier methods are not used
+type wordStore struct {
+	db.Querier
+	words   []db.Word
+	entries map[listKey]db.UpsertWordListEntryParams
+	tags    map[int32]map[string]string
+	imports []db.WordImport
+	// createdElsewhere is added by another admin between preview and commit
+	createdElsewhere string
+}
+
+func newWordStore(words ...string) *wordStore {
+	store := &wordStore{entries: make(map[listKey]db.UpsertWordListEntryParams), tags: make(map[int32]map[string]string)}
+	for _, word := range words {
+		store.words = append(store.words, db.Word{ID: int32(len(store.words) + 1), Word: word, Level: 3})
+	}
+	return store
+}
+
+func (s *wordStore) ListWordsByLowerText(ctx context.Context, words []string) ([]db.ListWordsByLowerTextRow, error) {
+	var rows []db.ListWordsByLowerTextRow
+	for _, word := range s.words {
+		for _, w := range words {
+			if strings.ToLower(word.Word) == w {
+				rows = append(rows, db.ListWordsByLowerTextRow{ID: word.ID, Word: word.Word})
+			}
+		}
+	}
+	return rows, nil
+}
+
+func (s *wordStore) CreateWord(ctx context.Context, arg db.CreateWordParams) (db.Word, error) {
+	if s.createdElsewhere == arg.Word {
+		s.words = append(s.words, db.Word{ID: int32(len(s.words) + 1), Word: arg.Word})
+		s.createdElsewhere = ""
+	}
+	for _, word := range s.words {
+		if word.Word == arg.Word {
+			return db.Word{}, &pq.Error{Code: "23505"}
+		}
+	}
+	word := db.Word{ID: int32(len(s.words) + 1), Word: arg.Word, Level: arg.Level, DescriptLevel: arg.DescriptLevel, ShortMean: arg.ShortMean, Freq: arg.Freq}
+	s.words = append(s.words, word)
+	return word, nil
+}
+
+func (s *wordStore) DeleteWordSenses(ctx context.Context, wordID int32) error {
+	return nil
+}
+
+func (s *wordStore) CreateWordSense(ctx context.Context, arg db.CreateWordSenseParams) (db.WordSense, error) {
+	return db.WordSense{WordID: arg.WordID, Meaning: arg.Meaning}, nil
+}
+
+func (s *wordStore) UpsertWordListEntry(ctx context.Context, arg db.UpsertWordListEntryParams) error {
+	s.entries[listKey{arg.ListName, arg.WordID}] = arg
+	return nil
+}
+
+func (s *wordStore) ListWordListEntriesForWords(ctx context.Context, arg db.ListWordListEntriesForWordsParams) ([]db.ListWordListEntriesForWordsRow, error) {
+	var rows []db.ListWordListEntriesForWordsRow
+	for _, id := range arg.WordIds {
+		if entry, ok := s.entries[listKey{arg.ListName, id}]; ok {
+			rows = append(rows, db.ListWordListEntriesForWordsRow{WordID: id, Rank: entry.Rank})
+		}
+	}
+	return rows, nil
+}
+
+func (s *wordStore) AddWordTag(ctx context.Context, arg db.AddWordTagParams) error {
+	if s.tags[arg.WordID] == nil {
+		s.tags[arg.WordID] = make(map[string]string)
+	}
+	if _, ok := s.tags[arg.WordID][arg.Tag]; !ok {
+		s.tags[arg.WordID][arg.Tag] = arg.Source
+	}
+	return nil
+}
+
+func (s *wordStore) ListWordTagsForWords(ctx context.Context, wordIds []int32) ([]db.ListWordTagsForWordsRow, error) {
+	var rows []db.ListWordTagsForWordsRow
+	for _, id := range wordIds {
+		for tag := range s.tags[id] {
+			rows = append(rows, db.ListWordTagsForWordsRow{WordID: id, Tag: tag})
+		}
+	}
+	return rows, nil
+}
+
+func (s *wordStore) CreateWordImport(ctx context.Context, arg db.CreateWordImportParams) (db.WordImport, error) {
+	record := db.WordImport{
+		ID:        int32(len(s.imports) + 1),
+		ListName:  arg.ListName,
+		Filename:  arg.Filename,
+		Status:    StatusPending,
+		Summary:   arg.Summary,
+		Items:     arg.Items,
+		CreatedBy: arg.CreatedBy,
+		CreatedAt: time.Now(),
+	}
+	s.imports = append(s.imports, record)
+	return record, nil
+}
+
+func (s *wordStore) GetWordImport(ctx context.Context, id int32) (db.WordImport, error) {
+	if id < 1 || int(id) > len(s.imports) {
+		return db.WordImport{}, sql.ErrNoRows
+	}
+	return s.imports[id-1], nil
+}
+
+func (s *wordStore) FinishWordImport(ctx context.Context, arg db.FinishWordImportParams) (db.WordImport, error) {
+	record := &s.imports[arg.ID-1]
+	if record.Status != StatusPending {
+		return db.WordImport{}, sql.ErrNoRows
+	}
+	record.Status = arg.Status
+	record.Result = arg.Result
+	record.FinishedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	return *record, nil
+}
+
+func itemFor(t *testing.T, imp *Import, word string) Item {
+	t.Helper()
+	for _, item := range imp.Items {
+		if item.Word == word {
+			return item
+		}
+	}
+	t.Fatalf("no item for %q", word)
+	return Item{}
+}
+
+func TestPreviewPlansChanges(t *testing.T) {
+	store := newWordStore("Budget", "agenda", "profit")
+	store.entries[listKey{"TSL", 2}] = db.UpsertWordListEntryParams{ListName: "TSL", WordID: 2, Rank: sql.NullInt32{Int32: 7, Valid: true}}
+	store.tags[2] = map[string]string{TagBusiness: TagSourceAuto}
+	store.entries[listKey{"TSL", 3}] = db.UpsertWordListEntryParams{ListName: "TSL", WordID: 3, Rank: sql.NullInt32{Int32: 9, Valid: true}}
+	store.tags[3] = map[string]string{TagBusiness: TagSourceAuto}
+	service := NewService(store, DefaultTagger())
+
+	list := "word,rank\nbudget,5\nagenda,7\nprofit,8\nitinerary,12\nBUDGET,13\nbad#word,14\n"
+	imp, err := service.Preview(context.Background(), PreviewRequest{ListName: "tsl", Filename: "tsl.csv", CreatedBy: 1}, strings.NewReader(list))
+	require.NoError(t, err)
+
+	assert.Equal(t, "TSL", imp.ListName)
+	assert.Equal(t, StatusPending, imp.Status)
+	assert.Equal(t, Summary{Total: 6, Create: 1, Link: 2, Unchanged: 1, Duplicate: 1, Invalid: 1, Tags: map[string]int{TagBusiness: 1, TagTravel: 1}}, imp.Summary)
+
+	budget := itemFor(t, imp, "budget")
+	assert.Equal(t, ActionLink, budget.Action)
+	assert.Equal(t, int32(1), budget.WordID)
+	assert.Equal(t, []string{TagBusiness}, budget.AutoTags)
+
+	assert.Equal(t, ActionUnchanged, itemFor(t, imp, "agenda").Action)
+
+	profit := itemFor(t, imp, "profit")
+	assert.Equal(t, ActionLink, profit.Action)
+	assert.Equal(t, int32(9), profit.PreviousRank)
+	assert.Empty(t, profit.AutoTags)
+
+	itinerary := itemFor(t, imp, "itinerary")
+	assert.Equal(t, ActionCreate, itinerary.Action)
+	assert.Equal(t, []string{TagTravel}, itinerary.AutoTags)
+
+	assert.Equal(t, ActionDuplicate, imp.Items[4].Action)
+	assert.Equal(t, 2, imp.Items[4].DuplicateOf)
+	require.Len(t, imp.Invalid, 1)
+	assert.Equal(t, 7, imp.Invalid[0].Line)
+
+	// Nothing is written before the import is committed
+	assert.Len(t, store.words, 3)
+	assert.Len(t, store.entries, 2)
+}
+
+func TestCommitAppliesPreview(t *testing.T) {
+	store := newWordStore("budget")
+	service := NewService(store, DefaultTagger())
+	ctx := context.Background()
+
+	list := "lemma,sfi rank,sfi,cefr,meaning\nbudget,5,60.5,,\nluggage,2100,40,A2,bags for travel\n"
+	imp, err := service.Preview(ctx, PreviewRequest{ListName: "NGSL", Tags: []string{"core"}}, strings.NewReader(list))
+	require.NoError(t, err)
+
+	committed, err := service.Commit(ctx, imp.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusCommitted, committed.Status)
+	require.NotNil(t, committed.Result)
+	assert.Equal(t, Result{Created: 1, Linked: 1, Tagged: 4}, *committed.Result)
+
+	require.Len(t, store.words, 2)
+	luggage := store.words[1]
+	assert.Equal(t, "luggage", luggage.Word)
+	assert.Equal(t, int32(3), luggage.Level)
+	assert.Equal(t, "A2", luggage.DescriptLevel)
+	assert.Equal(t, "bags for travel", luggage.ShortMean)
+	assert.Equal(t, float32(40), luggage.Freq)
+
+	// Existing words keep their own level and frequency
+	assert.Equal(t, int32(3), store.words[0].Level)
+
+	assert.Equal(t, sql.NullInt32{Int32: 2100, Valid: true}, store.entries[listKey{"NGSL", luggage.ID}].Rank)
+	assert.Equal(t, map[string]string{"core": TagSourceImport, TagTravel: TagSourceAuto}, store.tags[luggage.ID])
+	assert.Equal(t, map[string]string{"core": TagSourceImport, TagBusiness: TagSourceAuto}, store.tags[1])
+
+	_, err = service.Commit(ctx, imp.ID)
+	assert.ErrorIs(t, err, ErrImportFinished)
+}
+
+func TestCommitLinksWordsCreatedSincePreview(t *testing.T) {
+	store := newWordStore()
+	service := NewService(store, DefaultTagger())
+	ctx := context.Background()
+
+	imp, err := service.Preview(ctx, PreviewRequest{ListName: "NGSL"}, strings.NewReader("word\nticket\n"))
+	require.NoError(t, err)
+	assert.Equal(t, ActionCreate, imp.Items[0].Action)
+
+	store.createdElsewhere = "ticket"
+	committed, err := service.Commit(ctx, imp.ID)
+	require.NoError(t, err)
+	assert.Equal(t, Result{Linked: 1, Tagged: 1}, *committed.Result)
+	assert.Len(t, store.words, 1)
+	assert.Contains(t, store.entries, listKey{"NGSL", 1})
+}
+
+func TestDiscardAndValidation(t *testing.T) {
+	store := newWordStore()
+	service := NewService(store, DefaultTagger())
+	ctx := context.Background()
+
+	_, err := service.Preview(ctx, PreviewRequest{ListName: "bad name"}, strings.NewReader("word\nticket\n"))
+	assert.ErrorIs(t, err, ErrInvalidListName)
+	_, err = service.Preview(ctx, PreviewRequest{ListName: "NGSL"}, strings.NewReader("word\n"))
+	assert.ErrorIs(t, err, ErrEmptyList)
+
+	imp, err := service.Preview(ctx, PreviewRequest{ListName: "NGSL"}, strings.NewReader("word\nticket\n"))
+	require.NoError(t, err)
+	require.NoError(t, service.Discard(ctx, imp.ID))
+	_, err = service.Commit(ctx, imp.ID)
+	assert.ErrorIs(t, err, ErrImportFinished)
+	assert.Empty(t, store.words)
+
+	_, err = service.Get(ctx, 99)
+	assert.ErrorIs(t, err, ErrImportNotFound)
+}
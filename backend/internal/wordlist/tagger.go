@@ -0,0 +1,82 @@
+package wordlist
+
+import (
+	"sort"
+	"strings"
+)
+
+// Topic tags assigned automatically
+const (
+	TagBusiness = "business"
+	TagTravel   = "travel"
+)
+
+// Tagger assigns topic tags to words from keyword lexicons
+type Tagger struct {
+	lexicons map[string]map[string]bool
+	tags     []string
+}
+
+// NewTagger creates a tagger from lexicons of words per tag
+func NewTagger(lexicons map[string][]string) *Tagger {
+	tagger := &Tagger{lexicons: make(map[string]map[string]bool)}
+	for tag, words := range lexicons {
+		set := make(map[string]bool, len(words))
+		for _, word := range words {
+			set[NormalizeWord(word)] = true
+		}
+		tagger.lexicons[tag] = set
+		tagger.tags = append(tagger.tags, tag)
+	}
+	sort.Strings(tagger.tags)
+	return tagger
+}
+
+// DefaultTagger tags the business and travel vocabulary that TOEIC
+// listening and reading parts draw on
+func DefaultTagger() *Tagger {
+	return NewTagger(map[string][]string{
+		TagBusiness: {
+			"account", "accountant", "accounting", "agenda", "agreement", "applicant", "appointment", "asset",
+			"audit", "budget", "candidate", "client", "colleague", "commerce", "commercial", "commission",
+			"company", "competitor", "conference", "consultant", "contract", "corporate", "corporation",
+			"customer", "deadline", "department", "director", "dividend", "employee", "employer", "employment",
+			"enterprise", "executive", "expense", "finance", "financial", "firm", "fiscal", "headquarters",
+			"hire", "income", "industry", "inventory", "invest", "investment", "investor", "invoice", "lease",
+			"loan", "management", "manager", "market", "marketing", "meeting", "merger", "negotiate",
+			"negotiation", "office", "payroll", "personnel", "profit", "promotion", "proposal", "purchase",
+			"quarterly", "recruit", "recruitment", "refund", "retail", "revenue", "salary", "sales", "shareholder",
+			"shipment", "stock", "supervisor", "supplier", "tax", "transaction", "warehouse", "wholesale",
+		},
+		TagTravel: {
+			"accommodation", "airline", "airport", "arrival", "baggage", "boarding", "booking", "border",
+			"cabin", "check-in", "coach", "cruise", "currency", "customs", "delay", "departure", "destination",
+			"excursion", "fare", "ferry", "flight", "gate", "guide", "hotel", "itinerary", "journey", "landing",
+			"layover", "lobby", "lodging", "luggage", "passenger", "passport", "platform", "reception",
+			"reservation", "resort", "route", "sightseeing", "souvenir", "station", "suite", "terminal", "ticket",
+			"timetable", "tour", "tourism", "tourist", "transit", "travel", "traveler", "trip", "vacation",
+			"visa", "voyage",
+		},
+	})
+}
+
+// Tags returns the tags whose lexicon contains word, or a word of its meaning
+func (t *Tagger) Tags(word, meaning string) []string {
+	candidates := []string{NormalizeWord(word)}
+	for _, token := range strings.FieldsFunc(strings.ToLower(meaning), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z') && r != '-'
+	}) {
+		candidates = append(candidates, token)
+	}
+
+	var tags []string
+	for _, tag := range t.tags {
+		for _, candidate := range candidates {
+			if t.lexicons[tag][candidate] {
+				tags = append(tags, tag)
+				break
+			}
+		}
+	}
+	return tags
+}
@@ -0,0 +1,151 @@
+package wordsense
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+const (
+	// quizOptions is how many options a question has, the answer included
+	quizOptions = 4
+	// pickFrom limits quizzed senses to the first ones of a word, which are
+	// the common meanings
+	pickFrom = 3
+	// maxDistractorsPerPart caps the wrong answers loaded per part of speech
+	maxDistractorsPerPart = 60
+)
+
+// Question asks for the meaning of one sense of a word
+type Question struct {
+	Sense   Sense
+	Options []string
+}
+
+// Quiz builds a multiple choice question for each word. Each question asks
+// for one sense, and its wrong options are meanings of other words with the
+// same part of speech, so the part of speech does not give the answer away.
+func (s *Service) Quiz(ctx context.Context, words []db.Word, rng *rand.Rand) ([]Question, error) {
+	ids := make([]int32, 0, len(words))
+	for _, word := range words {
+		ids = append(ids, word.ID)
+	}
+	senses, err := s.ListForWords(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := make([]Sense, 0, len(words))
+	parts := make(map[string]bool)
+	for _, word := range words {
+		if len(senses[word.ID]) == 0 {
+			// Words added since the last sync fall back to their short meaning
+			senses[word.ID] = []Sense{{WordID: word.ID, Meaning: word.ShortMean}}
+		}
+		target := Pick(senses[word.ID], rng)
+		targets = append(targets, target)
+		parts[target.PartOfSpeech] = true
+	}
+
+	var pool []Sense
+	for _, list := range senses {
+		for _, sense := range list {
+			if sense.Register == "" {
+				pool = append(pool, sense)
+			}
+		}
+	}
+	partList := make([]string, 0, len(parts))
+	for part := range parts {
+		partList = append(partList, part)
+	}
+	distractors, err := s.store.ListDistractorSenses(ctx, db.ListDistractorSensesParams{
+		PartsOfSpeech:  partList,
+		ExcludeWordIds: ids,
+		PerPart:        int32(min(maxDistractorsPerPart, len(words)*(quizOptions-1))),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load quiz distractors: %w", err)
+	}
+	pool = append(pool, newSenses(distractors)...)
+
+	questions := make([]Question, 0, len(targets))
+	for _, target := range targets {
+		questions = append(questions, Question{
+			Sense:   target,
+			Options: Options(target, senses[target.WordID], pool, rng),
+		})
+	}
+	return questions, nil
+}
+
+// Pick chooses the sense of a word to quiz, preferring its first neutral
+// senses
+func Pick(senses []Sense, rng *rand.Rand) Sense {
+	var candidates []Sense
+	for _, sense := range senses {
+		if sense.Register == "" {
+			candidates = append(candidates, sense)
+			if len(candidates) == pickFrom {
+				break
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return senses[0]
+	}
+	return candidates[rng.Intn(len(candidates))]
+}
+
+// Options returns the shuffled options of a question on target. Wrong
+// options come from pool, same part of speech first; meanings of the word
+// itself are never offered as wrong. Fewer options are returned when the
+// pool runs out.
+func Options(target Sense, own []Sense, pool []Sense, rng *rand.Rand) []string {
+	used := map[string]bool{normalizeMeaning(target.Meaning): true}
+	for _, sense := range own {
+		used[normalizeMeaning(sense.Meaning)] = true
+	}
+
+	shuffled := append([]Sense(nil), pool...)
+	rng.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+
+	options := []string{target.Meaning}
+	for _, samePart := range []bool{true, false} {
+		for _, sense := range shuffled {
+			if len(options) == quizOptions {
+				break
+			}
+			key := normalizeMeaning(sense.Meaning)
+			if sense.WordID == target.WordID || used[key] || (sense.PartOfSpeech == target.PartOfSpeech) != samePart {
+				continue
+			}
+			used[key] = true
+			options = append(options, sense.Meaning)
+		}
+	}
+
+	rng.Shuffle(len(options), func(i, j int) { options[i], options[j] = options[j], options[i] })
+	return options
+}
+
+// Matches reports whether a typed answer is one of the meanings of a word
+func Matches(senses []Sense, answer string) bool {
+	answer = normalizeMeaning(answer)
+	if answer == "" {
+		return false
+	}
+	for _, sense := range senses {
+		if normalizeMeaning(sense.Meaning) == answer {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeMeaning(meaning string) string {
+	return strings.ToLower(strings.Join(strings.Fields(meaning), " "))
+}
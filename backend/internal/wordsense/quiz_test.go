@@ -0,0 +1,103 @@
+package wordsense
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+// senseStore serves senses from memory; other Querier methods are not used
+type senseStore struct {
+	db.Querier
+	senses      []db.WordSense
+	distractors []db.WordSense
+}
+
+func (s *senseStore) ListWordSensesForWords(ctx context.Context, wordIds []int32) ([]db.WordSense, error) {
+	var rows []db.WordSense
+	for _, row := range s.senses {
+		for _, id := range wordIds {
+			if row.WordID == id {
+				rows = append(rows, row)
+			}
+		}
+	}
+	return rows, nil
+}
+
+func (s *senseStore) ListDistractorSenses(ctx context.Context, arg db.ListDistractorSensesParams) ([]db.WordSense, error) {
+	var rows []db.WordSense
+	for _, row := range s.distractors {
+		for _, part := range arg.PartsOfSpeech {
+			if row.PartOfSpeech == part {
+				rows = append(rows, row)
+			}
+		}
+	}
+	return rows, nil
+}
+
+func TestOptionsPreferSamePartOfSpeech(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	target := Sense{WordID: 1, PartOfSpeech: Verb, Meaning: "đặt trước"}
+	own := []Sense{target, {WordID: 1, PartOfSpeech: Noun, Meaning: "sách"}}
+	pool := []Sense{
+		{WordID: 2, PartOfSpeech: Verb, Meaning: "gửi"},
+		{WordID: 3, PartOfSpeech: Verb, Meaning: "Gửi"},
+		{WordID: 4, PartOfSpeech: Verb, Meaning: "sách"},
+		{WordID: 5, PartOfSpeech: Noun, Meaning: "hoá đơn"},
+		{WordID: 6, PartOfSpeech: Verb, Meaning: "trả tiền"},
+		{WordID: 7, PartOfSpeech: Verb, Meaning: "huỷ"},
+	}
+
+	options := Options(target, own, pool, rng)
+	assert.Len(t, options, 4)
+	assert.Subset(t, options, []string{"đặt trước", "trả tiền", "huỷ"}, "meanings differing only in case are offered once")
+	assert.NotContains(t, options, "sách", "other meanings of the word are not wrong answers")
+	assert.NotContains(t, options, "hoá đơn", "other parts of speech only fill in")
+}
+
+func TestOptionsStopWhenPoolRunsOut(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	target := Sense{WordID: 1, PartOfSpeech: Noun, Meaning: "hoá đơn"}
+
+	options := Options(target, []Sense{target}, []Sense{{WordID: 2, PartOfSpeech: Verb, Meaning: "gửi"}, target}, rng)
+	assert.ElementsMatch(t, []string{"hoá đơn", "gửi"}, options)
+}
+
+func TestQuizAsksOneSensePerWord(t *testing.T) {
+	store := &senseStore{
+		senses: []db.WordSense{
+			{ID: 1, WordID: 1, Position: 1, PartOfSpeech: Noun, Meaning: "(từ cổ,nghĩa cổ) sổ sách", Register: Archaic},
+			{ID: 2, WordID: 1, Position: 2, PartOfSpeech: Verb, Meaning: "đặt trước"},
+		},
+		distractors: []db.WordSense{
+			{ID: 10, WordID: 10, PartOfSpeech: Verb, Meaning: "gửi"},
+			{ID: 11, WordID: 11, PartOfSpeech: Verb, Meaning: "huỷ"},
+			{ID: 12, WordID: 12, PartOfSpeech: Verb, Meaning: "trả tiền"},
+		},
+	}
+	words := []db.Word{{ID: 1, Word: "book"}, {ID: 2, Word: "new", ShortMean: "mới"}}
+
+	questions, err := NewService(store).Quiz(context.Background(), words, rand.New(rand.NewSource(1)))
+	require.NoError(t, err)
+	require.Len(t, questions, 2)
+
+	assert.Equal(t, int32(2), questions[0].Sense.ID, "archaic senses are not quizzed")
+	assert.ElementsMatch(t, []string{"đặt trước", "gửi", "huỷ", "trả tiền"}, questions[0].Options)
+
+	assert.Equal(t, "mới", questions[1].Sense.Meaning, "words without senses use their short meaning")
+	assert.Contains(t, questions[1].Options, "mới")
+	assert.Len(t, questions[1].Options, 4, "other parts of speech fill in")
+}
+
+func TestMatches(t *testing.T) {
+	senses := []Sense{{Meaning: "hoá đơn"}, {Meaning: "lập  hoá đơn"}}
+	assert.True(t, Matches(senses, " Lập hoá đơn "))
+	assert.False(t, Matches(senses, "hoá"))
+	assert.False(t, Matches(senses, ""))
+}
@@ -0,0 +1,223 @@
+// Package wordsense splits words into senses: one meaning each, with its part
+// of speech and register. The means JSON of a word is kept as the legacy
+// view and is rebuilt whenever senses change.
+package wordsense
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Parts of speech
+const (
+	Noun         = "noun"
+	Verb         = "verb"
+	Adjective    = "adjective"
+	Adverb       = "adverb"
+	Preposition  = "preposition"
+	Conjunction  = "conjunction"
+	Pronoun      = "pronoun"
+	Interjection = "interjection"
+	Determiner   = "determiner"
+	Affix        = "affix"
+	Abbreviation = "abbreviation"
+	Phrase       = "phrase"
+)
+
+// Registers. Senses without one are neutral.
+const (
+	Informal   = "informal"
+	Slang      = "slang"
+	Archaic    = "archaic"
+	Rare       = "rare"
+	American   = "american"
+	Figurative = "figurative"
+	Literary   = "literary"
+	Business   = "business"
+	Legal      = "legal"
+	Technical  = "technical"
+)
+
+// kindLabels are the dictionary labels written to the means JSON
+var kindLabels = map[string]string{
+	Noun:         "danh từ",
+	Verb:         "động từ",
+	Adjective:    "tính từ",
+	Adverb:       "phó từ",
+	Preposition:  "giới từ",
+	Conjunction:  "liên từ",
+	Pronoun:      "đại từ",
+	Interjection: "thán từ",
+	Determiner:   "từ xác định",
+	Affix:        "tiền tố",
+	Abbreviation: "viết tắt",
+	Phrase:       "cụm từ",
+}
+
+// kindPrefixes map dictionary labels to parts of speech. Labels such as
+// "ngoại động từ" are matched by what they contain.
+var kindPrefixes = []struct {
+	label string
+	pos   string
+}{
+	{"danh từ", Noun},
+	{"động từ", Verb},
+	{"tính từ", Adjective},
+	{"phó từ", Adverb},
+	{"giới từ", Preposition},
+	{"liên từ", Conjunction},
+	{"đại từ", Pronoun},
+	{"thán từ", Interjection},
+	{"từ xác định", Determiner},
+	{"định ngữ", Determiner},
+	{"tiền tố", Affix},
+	{"hậu tố", Affix},
+	{"viết tắt", Abbreviation},
+	{"cụm từ", Phrase},
+}
+
+// kindAbbreviations are the English labels used by imported entries
+var kindAbbreviations = map[string]string{
+	"n":      Noun,
+	"v":      Verb,
+	"adj":    Adjective,
+	"adv":    Adverb,
+	"prep":   Preposition,
+	"conj":   Conjunction,
+	"pron":   Pronoun,
+	"interj": Interjection,
+}
+
+// registerMarkers are the notes dictionary meanings start with
+var registerMarkers = []struct {
+	marker   string
+	register string
+}{
+	{"(thông tục)", Informal},
+	{"(từ lóng)", Slang},
+	{"(từ cổ", Archaic},
+	{"(từ hiếm", Rare},
+	{"(từ Mỹ", American},
+	{"(nghĩa bóng)", Figurative},
+	{"(thơ ca)", Literary},
+	{"(văn học)", Literary},
+	{"(thương nghiệp)", Business},
+	{"(thương mại)", Business},
+	{"(kinh tế)", Business},
+	{"(pháp lý)", Legal},
+	{"(kỹ thuật)", Technical},
+	{"(tin học)", Technical},
+}
+
+// ValidPartOfSpeech reports whether pos is a known part of speech or empty
+func ValidPartOfSpeech(pos string) bool {
+	_, ok := kindLabels[pos]
+	return ok || pos == ""
+}
+
+// ValidRegister reports whether register is a known register or empty
+func ValidRegister(register string) bool {
+	if register == "" {
+		return true
+	}
+	for _, marker := range registerMarkers {
+		if marker.register == register {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizePartOfSpeech maps a dictionary kind such as "ngoại động từ" or
+// "adj" to a part of speech. Unknown kinds map to "".
+func NormalizePartOfSpeech(kind string) string {
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	if _, ok := kindLabels[kind]; ok {
+		return kind
+	}
+	if pos, ok := kindAbbreviations[kind]; ok {
+		return pos
+	}
+	for _, prefix := range kindPrefixes {
+		if strings.HasPrefix(kind, prefix.label) || (prefix.pos == Verb && strings.Contains(kind, prefix.label)) {
+			return prefix.pos
+		}
+	}
+	return ""
+}
+
+// DetectRegister returns the register a meaning is marked with, such as
+// "(thông tục) tuyệt" being informal
+func DetectRegister(meaning string) string {
+	meaning = strings.TrimSpace(meaning)
+	for _, marker := range registerMarkers {
+		if strings.HasPrefix(meaning, marker.marker) {
+			return marker.register
+		}
+	}
+	return ""
+}
+
+// Draft is a sense before it is stored
+type Draft struct {
+	PartOfSpeech string  `json:"part_of_speech"`
+	Meaning      string  `json:"meaning"`
+	Register     string  `json:"register"`
+	Examples     []int32 `json:"examples"`
+}
+
+type meansKind struct {
+	Kind  string      `json:"kind,omitempty"`
+	Means []meansItem `json:"means"`
+}
+
+type meansItem struct {
+	Mean     string  `json:"mean"`
+	Examples []int32 `json:"examples,omitempty"`
+}
+
+// FromMeans splits the means JSON of a word into senses, in order. A word
+// without structured meanings gets its short meaning as its only sense.
+func FromMeans(means json.RawMessage, shortMean string) []Draft {
+	var kinds []meansKind
+	if len(means) > 0 {
+		// Malformed means are treated like missing ones
+		_ = json.Unmarshal(means, &kinds)
+	}
+
+	var drafts []Draft
+	for _, kind := range kinds {
+		pos := NormalizePartOfSpeech(kind.Kind)
+		for _, item := range kind.Means {
+			meaning := strings.TrimSpace(item.Mean)
+			if meaning == "" {
+				continue
+			}
+			drafts = append(drafts, Draft{
+				PartOfSpeech: pos,
+				Meaning:      meaning,
+				Register:     DetectRegister(meaning),
+				Examples:     item.Examples,
+			})
+		}
+	}
+	if len(drafts) == 0 && strings.TrimSpace(shortMean) != "" {
+		drafts = append(drafts, Draft{Meaning: strings.TrimSpace(shortMean)})
+	}
+	return drafts
+}
+
+// ToMeans builds the means JSON of a word from its senses, grouping
+// consecutive senses with the same part of speech
+func ToMeans(senses []Sense) json.RawMessage {
+	kinds := []meansKind{}
+	for i, sense := range senses {
+		if i == 0 || sense.PartOfSpeech != senses[i-1].PartOfSpeech {
+			kinds = append(kinds, meansKind{Kind: kindLabels[sense.PartOfSpeech]})
+		}
+		last := &kinds[len(kinds)-1]
+		last.Means = append(last.Means, meansItem{Mean: sense.Meaning, Examples: sense.Examples})
+	}
+	data, _ := json.Marshal(kinds)
+	return data
+}
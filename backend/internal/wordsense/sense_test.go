@@ -0,0 +1,69 @@
+package wordsense
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizePartOfSpeech(t *testing.T) {
+	for kind, pos := range map[string]string{
+		"danh từ":       Noun,
+		"ngoại động từ": Verb,
+		"nội động từ":   Verb,
+		"Tính từ":       Adjective,
+		"adj":           Adjective,
+		"verb":          Verb,
+		"định ngữ":      Determiner,
+		"hậu tố":        Affix,
+		"":              "",
+		"something":     "",
+	} {
+		assert.Equal(t, pos, NormalizePartOfSpeech(kind), kind)
+	}
+}
+
+func TestDetectRegister(t *testing.T) {
+	assert.Equal(t, Informal, DetectRegister("(thông tục) tuyệt, cừ"))
+	assert.Equal(t, Archaic, DetectRegister("(từ cổ,nghĩa cổ) cái móc"))
+	assert.Equal(t, American, DetectRegister(" (từ Mỹ,nghĩa Mỹ) hoá đơn"))
+	assert.Equal(t, "", DetectRegister("hoá đơn (thông tục)"), "only leading notes mark a register")
+}
+
+func TestFromMeans(t *testing.T) {
+	means := json.RawMessage(`[
+		{"kind":"danh từ","means":[{"mean":"hoá đơn","examples":[4,5]},{"mean":"  "}]},
+		{"kind":"ngoại động từ","means":[{"mean":"(thương nghiệp) lập hoá đơn"}]}
+	]`)
+
+	drafts := FromMeans(means, "hoá đơn (n)")
+	require.Len(t, drafts, 2)
+	assert.Equal(t, Draft{PartOfSpeech: Noun, Meaning: "hoá đơn", Examples: []int32{4, 5}}, drafts[0])
+	assert.Equal(t, Draft{PartOfSpeech: Verb, Meaning: "(thương nghiệp) lập hoá đơn", Register: Business}, drafts[1])
+
+	assert.Equal(t, []Draft{{Meaning: "mới (Adj)"}}, FromMeans(nil, "mới (Adj)"))
+	assert.Equal(t, []Draft{{Meaning: "mới"}}, FromMeans(json.RawMessage(`{"broken"`), "mới"))
+	assert.Empty(t, FromMeans(nil, ""))
+}
+
+func TestToMeansGroupsByPartOfSpeech(t *testing.T) {
+	senses := []Sense{
+		{PartOfSpeech: Noun, Meaning: "hoá đơn", Examples: []int32{4}},
+		{PartOfSpeech: Noun, Meaning: "danh đơn hàng gửi"},
+		{PartOfSpeech: Verb, Meaning: "lập hoá đơn"},
+	}
+
+	assert.JSONEq(t, `[
+		{"kind":"danh từ","means":[{"mean":"hoá đơn","examples":[4]},{"mean":"danh đơn hàng gửi"}]},
+		{"kind":"động từ","means":[{"mean":"lập hoá đơn"}]}
+	]`, string(ToMeans(senses)))
+	assert.JSONEq(t, `[]`, string(ToMeans(nil)))
+
+	// Round trip keeps meanings, parts of speech and examples
+	drafts := FromMeans(ToMeans(senses), "")
+	require.Len(t, drafts, 3)
+	assert.Equal(t, Verb, drafts[2].PartOfSpeech)
+	assert.Equal(t, []int32{4}, drafts[0].Examples)
+}
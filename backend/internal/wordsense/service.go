@@ -0,0 +1,210 @@
+package wordsense
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sqlc-dev/pqtype"
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+var (
+	ErrWordNotFound        = errors.New("word not found")
+	ErrSenseNotFound       = errors.New("sense not found")
+	ErrEmptyMeaning        = errors.New("meaning is required")
+	ErrInvalidPartOfSpeech = errors.New("unknown part of speech")
+	ErrInvalidRegister     = errors.New("unknown register")
+)
+
+// Sense is one meaning of a word
+type Sense struct {
+	ID           int32     `json:"id"`
+	WordID       int32     `json:"word_id"`
+	Position     int32     `json:"position"`
+	PartOfSpeech string    `json:"part_of_speech,omitempty"`
+	Meaning      string    `json:"meaning"`
+	Register     string    `json:"register,omitempty"`
+	Examples     []int32   `json:"examples,omitempty"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func newSense(row db.WordSense) Sense {
+	return Sense{
+		ID:           row.ID,
+		WordID:       row.WordID,
+		Position:     row.Position,
+		PartOfSpeech: row.PartOfSpeech,
+		Meaning:      row.Meaning,
+		Register:     row.Register,
+		Examples:     row.Examples,
+		UpdatedAt:    row.UpdatedAt,
+	}
+}
+
+func newSenses(rows []db.WordSense) []Sense {
+	senses := make([]Sense, 0, len(rows))
+	for _, row := range rows {
+		senses = append(senses, newSense(row))
+	}
+	return senses
+}
+
+// Service stores the senses of words
+type Service struct {
+	store db.Querier
+}
+
+// NewService creates a word sense service
+func NewService(store db.Querier) *Service {
+	return &Service{store: store}
+}
+
+// List returns the senses of a word in order
+func (s *Service) List(ctx context.Context, wordID int32) ([]Sense, error) {
+	rows, err := s.store.ListWordSenses(ctx, wordID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list word senses: %w", err)
+	}
+	return newSenses(rows), nil
+}
+
+// ListForWords returns the senses of several words, keyed by word
+func (s *Service) ListForWords(ctx context.Context, wordIDs []int32) (map[int32][]Sense, error) {
+	rows, err := s.store.ListWordSensesForWords(ctx, wordIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list word senses: %w", err)
+	}
+	senses := make(map[int32][]Sense, len(wordIDs))
+	for _, row := range rows {
+		senses[row.WordID] = append(senses[row.WordID], newSense(row))
+	}
+	return senses, nil
+}
+
+// Sync replaces the senses of a word with the ones in its means JSON. It is
+// called after the word itself is written.
+func (s *Service) Sync(ctx context.Context, word db.Word) ([]Sense, error) {
+	if err := s.store.DeleteWordSenses(ctx, word.ID); err != nil {
+		return nil, fmt.Errorf("failed to clear word senses: %w", err)
+	}
+	var senses []Sense
+	for _, draft := range FromMeans(word.Means.RawMessage, word.ShortMean) {
+		row, err := s.store.CreateWordSense(ctx, createParams(word.ID, draft))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create word sense: %w", err)
+		}
+		senses = append(senses, newSense(row))
+	}
+	return senses, nil
+}
+
+// Add appends a sense to a word
+func (s *Service) Add(ctx context.Context, wordID int32, draft Draft) (Sense, error) {
+	if err := normalize(&draft); err != nil {
+		return Sense{}, err
+	}
+	if _, err := s.store.GetWord(ctx, wordID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Sense{}, ErrWordNotFound
+		}
+		return Sense{}, fmt.Errorf("failed to get word: %w", err)
+	}
+	row, err := s.store.CreateWordSense(ctx, createParams(wordID, draft))
+	if err != nil {
+		return Sense{}, fmt.Errorf("failed to create word sense: %w", err)
+	}
+	return newSense(row), s.writeMeans(ctx, wordID)
+}
+
+// Update replaces a sense of a word
+func (s *Service) Update(ctx context.Context, wordID, senseID int32, draft Draft) (Sense, error) {
+	if err := normalize(&draft); err != nil {
+		return Sense{}, err
+	}
+	row, err := s.store.UpdateWordSense(ctx, db.UpdateWordSenseParams{
+		ID:           senseID,
+		WordID:       wordID,
+		PartOfSpeech: draft.PartOfSpeech,
+		Meaning:      draft.Meaning,
+		Register:     draft.Register,
+		Examples:     examples(draft.Examples),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Sense{}, ErrSenseNotFound
+		}
+		return Sense{}, fmt.Errorf("failed to update word sense: %w", err)
+	}
+	return newSense(row), s.writeMeans(ctx, wordID)
+}
+
+// Delete removes a sense of a word
+func (s *Service) Delete(ctx context.Context, wordID, senseID int32) error {
+	removed, err := s.store.DeleteWordSense(ctx, db.DeleteWordSenseParams{ID: senseID, WordID: wordID})
+	if err != nil {
+		return fmt.Errorf("failed to delete word sense: %w", err)
+	}
+	if removed == 0 {
+		return ErrSenseNotFound
+	}
+	return s.writeMeans(ctx, wordID)
+}
+
+// writeMeans rebuilds the means JSON of a word from its senses
+func (s *Service) writeMeans(ctx context.Context, wordID int32) error {
+	senses, err := s.List(ctx, wordID)
+	if err != nil {
+		return err
+	}
+	err = s.store.UpdateWordMeans(ctx, db.UpdateWordMeansParams{
+		ID:    wordID,
+		Means: pqtype.NullRawMessage{RawMessage: ToMeans(senses), Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update word meanings: %w", err)
+	}
+	return nil
+}
+
+// normalize trims a draft, detects its register when none is given and
+// checks the part of speech and register are known
+func normalize(draft *Draft) error {
+	draft.Meaning = strings.TrimSpace(draft.Meaning)
+	draft.PartOfSpeech = strings.ToLower(strings.TrimSpace(draft.PartOfSpeech))
+	draft.Register = strings.ToLower(strings.TrimSpace(draft.Register))
+	if draft.Meaning == "" {
+		return ErrEmptyMeaning
+	}
+	if !ValidPartOfSpeech(draft.PartOfSpeech) {
+		return ErrInvalidPartOfSpeech
+	}
+	if draft.Register == "" {
+		draft.Register = DetectRegister(draft.Meaning)
+	}
+	if !ValidRegister(draft.Register) {
+		return ErrInvalidRegister
+	}
+	return nil
+}
+
+func createParams(wordID int32, draft Draft) db.CreateWordSenseParams {
+	return db.CreateWordSenseParams{
+		WordID:       wordID,
+		PartOfSpeech: draft.PartOfSpeech,
+		Meaning:      draft.Meaning,
+		Register:     draft.Register,
+		Examples:     examples(draft.Examples),
+	}
+}
+
+// examples keeps a nil list from being stored as NULL
+func examples(ids []int32) []int32 {
+	if ids == nil {
+		return []int32{}
+	}
+	return ids
+}
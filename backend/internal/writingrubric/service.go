@@ -0,0 +1,249 @@
+// Package writingrubric manages the rubrics writing is scored on by AI.
+// A rubric belongs to a writing task, which is a writing topic such as
+// business emails, or to a single prompt. A prompt is scored on its own
+// rubric, else on the rubric of its topic or of the nearest ancestor topic
+// that has one, else on ai.DefaultRubric.
+package writingrubric
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/toeic-app/internal/ai"
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+// MaxCriteria is the most criteria a rubric has
+const MaxCriteria = 12
+
+var (
+	ErrRubricNotFound  = errors.New("writing rubric not found")
+	ErrInvalidTarget   = errors.New("a rubric belongs to either a writing topic or a prompt")
+	ErrTargetNotFound  = errors.New("writing topic or prompt not found")
+	ErrRubricExists    = errors.New("the writing topic or prompt already has a rubric")
+	ErrInvalidCriteria = errors.New("invalid rubric criteria")
+)
+
+var keyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,31}$`)
+
+// Rubric is the rubric of a writing topic or prompt
+type Rubric struct {
+	ID           int32                `json:"id"`
+	TopicID      *int32               `json:"topic_id,omitempty"`
+	PromptID     *int32               `json:"prompt_id,omitempty"`
+	Name         string               `json:"name"`
+	Instructions string               `json:"instructions"`
+	Criteria     []ai.RubricCriterion `json:"criteria"`
+	UpdatedBy    *int32               `json:"updated_by,omitempty"`
+	CreatedAt    time.Time            `json:"created_at"`
+	UpdatedAt    time.Time            `json:"updated_at"`
+}
+
+// Input creates or changes a rubric. The topic or prompt of a rubric is
+// only set when it is created.
+type Input struct {
+	TopicID      *int32
+	PromptID     *int32
+	Name         string
+	Instructions string
+	Criteria     []ai.RubricCriterion
+}
+
+// Service manages writing rubrics
+type Service struct {
+	store db.Querier
+}
+
+// NewService creates a writing rubric service
+func NewService(store db.Querier) *Service {
+	return &Service{store: store}
+}
+
+// List returns the rubrics of topics, then those of prompts
+func (s *Service) List(ctx context.Context) ([]Rubric, error) {
+	rows, err := s.store.ListWritingRubrics(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list writing rubrics: %w", err)
+	}
+	rubrics := make([]Rubric, 0, len(rows))
+	for _, row := range rows {
+		rubric, err := toRubric(row)
+		if err != nil {
+			return nil, err
+		}
+		rubrics = append(rubrics, rubric)
+	}
+	return rubrics, nil
+}
+
+// Get returns a rubric
+func (s *Service) Get(ctx context.Context, id int32) (Rubric, error) {
+	row, err := s.store.GetWritingRubric(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Rubric{}, ErrRubricNotFound
+	}
+	if err != nil {
+		return Rubric{}, fmt.Errorf("failed to get writing rubric: %w", err)
+	}
+	return toRubric(row)
+}
+
+// Create adds the rubric of a topic or prompt
+func (s *Service) Create(ctx context.Context, input Input, userID int32) (Rubric, error) {
+	if (input.TopicID == nil) == (input.PromptID == nil) {
+		return Rubric{}, ErrInvalidTarget
+	}
+	criteria, err := marshalCriteria(input.Criteria)
+	if err != nil {
+		return Rubric{}, err
+	}
+
+	row, err := s.store.CreateWritingRubric(ctx, db.CreateWritingRubricParams{
+		TopicID:      nullInt32(input.TopicID),
+		PromptID:     nullInt32(input.PromptID),
+		Name:         input.Name,
+		Instructions: input.Instructions,
+		Criteria:     criteria,
+		UpdatedBy:    sql.NullInt32{Int32: userID, Valid: true},
+	})
+	if isViolation(err, "23505") {
+		return Rubric{}, ErrRubricExists
+	}
+	if isViolation(err, "23503") {
+		return Rubric{}, ErrTargetNotFound
+	}
+	if err != nil {
+		return Rubric{}, fmt.Errorf("failed to create writing rubric: %w", err)
+	}
+	return toRubric(row)
+}
+
+// Update changes the name, instructions and criteria of a rubric
+func (s *Service) Update(ctx context.Context, id int32, input Input, userID int32) (Rubric, error) {
+	criteria, err := marshalCriteria(input.Criteria)
+	if err != nil {
+		return Rubric{}, err
+	}
+
+	row, err := s.store.UpdateWritingRubric(ctx, db.UpdateWritingRubricParams{
+		ID:           id,
+		Name:         input.Name,
+		Instructions: input.Instructions,
+		Criteria:     criteria,
+		UpdatedBy:    sql.NullInt32{Int32: userID, Valid: true},
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return Rubric{}, ErrRubricNotFound
+	}
+	if err != nil {
+		return Rubric{}, fmt.Errorf("failed to update writing rubric: %w", err)
+	}
+	return toRubric(row)
+}
+
+// Delete removes a rubric; its prompts are scored on the rubric of their
+// topic again
+func (s *Service) Delete(ctx context.Context, id int32) error {
+	deleted, err := s.store.DeleteWritingRubric(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete writing rubric: %w", err)
+	}
+	if deleted == 0 {
+		return ErrRubricNotFound
+	}
+	return nil
+}
+
+// ForPrompt returns the rubric a prompt is scored on
+func (s *Service) ForPrompt(ctx context.Context, promptID int32) (ai.Rubric, error) {
+	row, err := s.store.GetWritingRubricForPrompt(ctx, promptID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ai.DefaultRubric(), nil
+	}
+	if err != nil {
+		return ai.Rubric{}, fmt.Errorf("failed to get rubric of prompt: %w", err)
+	}
+	rubric, err := toRubric(row)
+	if err != nil {
+		return ai.Rubric{}, err
+	}
+	return ai.Rubric{Name: rubric.Name, Instructions: rubric.Instructions, Criteria: rubric.Criteria}, nil
+}
+
+// ValidateCriteria checks that criteria have unique keys, a name and
+// weights adding up to 100
+func ValidateCriteria(criteria []ai.RubricCriterion) error {
+	if len(criteria) == 0 || len(criteria) > MaxCriteria {
+		return fmt.Errorf("%w: a rubric has 1 to %d criteria", ErrInvalidCriteria, MaxCriteria)
+	}
+	keys := make(map[string]bool, len(criteria))
+	total := 0
+	for _, criterion := range criteria {
+		if !keyPattern.MatchString(criterion.Key) {
+			return fmt.Errorf("%w: key %q must be lowercase letters, digits and underscores starting with a letter, at most 32 characters", ErrInvalidCriteria, criterion.Key)
+		}
+		if keys[criterion.Key] {
+			return fmt.Errorf("%w: key %q is used twice", ErrInvalidCriteria, criterion.Key)
+		}
+		keys[criterion.Key] = true
+		if criterion.Name == "" {
+			return fmt.Errorf("%w: criterion %q has no name", ErrInvalidCriteria, criterion.Key)
+		}
+		if criterion.Weight < 1 || criterion.Weight > 100 {
+			return fmt.Errorf("%w: weight of %q must be between 1 and 100", ErrInvalidCriteria, criterion.Key)
+		}
+		total += criterion.Weight
+	}
+	if total != 100 {
+		return fmt.Errorf("%w: weights add up to %d instead of 100", ErrInvalidCriteria, total)
+	}
+	return nil
+}
+
+func marshalCriteria(criteria []ai.RubricCriterion) (json.RawMessage, error) {
+	if err := ValidateCriteria(criteria); err != nil {
+		return nil, err
+	}
+	return json.Marshal(criteria)
+}
+
+func toRubric(row db.WritingRubric) (Rubric, error) {
+	rubric := Rubric{
+		ID:           row.ID,
+		Name:         row.Name,
+		Instructions: row.Instructions,
+		CreatedAt:    row.CreatedAt,
+		UpdatedAt:    row.UpdatedAt,
+	}
+	if err := json.Unmarshal(row.Criteria, &rubric.Criteria); err != nil {
+		return Rubric{}, fmt.Errorf("failed to parse criteria of writing rubric %d: %w", row.ID, err)
+	}
+	if row.TopicID.Valid {
+		rubric.TopicID = &row.TopicID.Int32
+	}
+	if row.PromptID.Valid {
+		rubric.PromptID = &row.PromptID.Int32
+	}
+	if row.UpdatedBy.Valid {
+		rubric.UpdatedBy = &row.UpdatedBy.Int32
+	}
+	return rubric, nil
+}
+
+func nullInt32(value *int32) sql.NullInt32 {
+	if value == nil {
+		return sql.NullInt32{}
+	}
+	return sql.NullInt32{Int32: *value, Valid: true}
+}
+
+func isViolation(err error, code pq.ErrorCode) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == code
+}
@@ -0,0 +1,119 @@
+package writingrubric
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toeic-app/internal/ai"
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+// fakeStore holds the rubric of topic 1, which prompt 5 is filed under
+type fakeStore struct {
+	db.Querier
+	created *db.CreateWritingRubricParams
+}
+
+func (s *fakeStore) GetWritingRubricForPrompt(_ context.Context, promptID int32) (db.WritingRubric, error) {
+	if promptID != 5 {
+		return db.WritingRubric{}, sql.ErrNoRows
+	}
+	return db.WritingRubric{
+		ID:           1,
+		TopicID:      sql.NullInt32{Int32: 1, Valid: true},
+		Name:         "Business email",
+		Instructions: "Answer every question",
+		Criteria:     json.RawMessage(`[{"key": "task_response", "name": "Task completion", "weight": 100}]`),
+	}, nil
+}
+
+func (s *fakeStore) CreateWritingRubric(_ context.Context, arg db.CreateWritingRubricParams) (db.WritingRubric, error) {
+	if arg.TopicID.Int32 == 1 {
+		return db.WritingRubric{}, &pq.Error{Code: "23505"}
+	}
+	if arg.TopicID.Int32 == 9 {
+		return db.WritingRubric{}, &pq.Error{Code: "23503"}
+	}
+	s.created = &arg
+	return db.WritingRubric{ID: 2, TopicID: arg.TopicID, Name: arg.Name, Criteria: arg.Criteria, UpdatedBy: arg.UpdatedBy}, nil
+}
+
+func (s *fakeStore) DeleteWritingRubric(_ context.Context, id int32) (int64, error) {
+	if id != 1 {
+		return 0, nil
+	}
+	return 1, nil
+}
+
+func TestValidateCriteria(t *testing.T) {
+	criterion := func(key string, weight int) ai.RubricCriterion {
+		return ai.RubricCriterion{Key: key, Name: key, Weight: weight}
+	}
+
+	assert.NoError(t, ValidateCriteria([]ai.RubricCriterion{criterion("grammar", 40), criterion("task_response", 60)}))
+	assert.NoError(t, ValidateCriteria(ai.DefaultRubric().Criteria))
+
+	for name, criteria := range map[string][]ai.RubricCriterion{
+		"none":          nil,
+		"under 100":     {criterion("grammar", 40), criterion("tone", 50)},
+		"over 100":      {criterion("grammar", 60), criterion("tone", 50)},
+		"duplicate key": {criterion("grammar", 50), criterion("grammar", 50)},
+		"invalid key":   {criterion("Task Response", 100)},
+		"zero weight":   {criterion("grammar", 100), criterion("tone", 0)},
+		"no name":       {{Key: "grammar", Weight: 100}},
+	} {
+		assert.ErrorIs(t, ValidateCriteria(criteria), ErrInvalidCriteria, name)
+	}
+}
+
+func TestForPromptFallsBackToDefault(t *testing.T) {
+	service := NewService(&fakeStore{})
+	ctx := context.Background()
+
+	rubric, err := service.ForPrompt(ctx, 5)
+	require.NoError(t, err)
+	assert.Equal(t, ai.Rubric{
+		Name:         "Business email",
+		Instructions: "Answer every question",
+		Criteria:     []ai.RubricCriterion{{Key: "task_response", Name: "Task completion", Weight: 100}},
+	}, rubric)
+
+	rubric, err = service.ForPrompt(ctx, 6)
+	require.NoError(t, err)
+	assert.Equal(t, ai.DefaultRubric(), rubric)
+}
+
+func TestCreate(t *testing.T) {
+	store := &fakeStore{}
+	service := NewService(store)
+	ctx := context.Background()
+	topic := func(id int32) *int32 { return &id }
+	criteria := []ai.RubricCriterion{{Key: "grammar", Name: "Grammar", Weight: 100}}
+
+	_, err := service.Create(ctx, Input{Name: "Essay", Criteria: criteria}, 7)
+	assert.ErrorIs(t, err, ErrInvalidTarget)
+	_, err = service.Create(ctx, Input{TopicID: topic(3), PromptID: topic(4), Name: "Essay", Criteria: criteria}, 7)
+	assert.ErrorIs(t, err, ErrInvalidTarget)
+	_, err = service.Create(ctx, Input{TopicID: topic(3), Name: "Essay", Criteria: criteria[:0]}, 7)
+	assert.ErrorIs(t, err, ErrInvalidCriteria)
+	_, err = service.Create(ctx, Input{TopicID: topic(1), Name: "Essay", Criteria: criteria}, 7)
+	assert.ErrorIs(t, err, ErrRubricExists)
+	_, err = service.Create(ctx, Input{TopicID: topic(9), Name: "Essay", Criteria: criteria}, 7)
+	assert.ErrorIs(t, err, ErrTargetNotFound)
+	assert.Nil(t, store.created)
+
+	rubric, err := service.Create(ctx, Input{TopicID: topic(3), Name: "Essay", Criteria: criteria}, 7)
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), *rubric.TopicID)
+	assert.Nil(t, rubric.PromptID)
+	assert.Equal(t, criteria, rubric.Criteria)
+	assert.Equal(t, int32(7), *rubric.UpdatedBy)
+
+	assert.NoError(t, service.Delete(ctx, 1))
+	assert.ErrorIs(t, service.Delete(ctx, 2), ErrRubricNotFound)
+}
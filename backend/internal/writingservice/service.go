@@ -0,0 +1,468 @@
+// Package writingservice holds the rules for writing submissions and their
+// AI scoring: who may score a submission, when an earlier score is reused,
+// how scoring is counted against the daily quota and how scores are kept
+// with the submission.
+package writingservice
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/sqlc-dev/pqtype"
+	"github.com/toeic-app/internal/ai"
+	"github.com/toeic-app/internal/ailimit"
+	db "github.com/toeic-app/internal/db/sqlc"
+	"github.com/toeic-app/internal/logger"
+)
+
+var (
+	ErrSubmissionNotFound = errors.New("writing submission not found")
+	ErrNotOwner           = errors.New("only the author can score a submission")
+	ErrNoText             = errors.New("either a submission or a text must be given")
+	ErrScorerUnavailable  = errors.New("AI scoring service not available")
+	ErrScorerUnhealthy    = errors.New("AI scoring service is not healthy")
+)
+
+// cachedConfidence is reported for scores reused from a submission
+const cachedConfidence = 0.9
+
+// Scorer scores writing with AI. ai.Provider satisfies it.
+type Scorer interface {
+	ScoreWriting(ctx context.Context, req ai.AIScoreRequest) (*ai.AIScoreResponse, error)
+	IsHealthy() bool
+}
+
+// Quota counts AI scorings against a user's daily allowance.
+// *billing.Service satisfies it.
+type Quota interface {
+	// ConsumeAIScoring reports whether the scoring was counted, and so has
+	// to be released if it fails
+	ConsumeAIScoring(ctx context.Context, userID int32) (bool, error)
+	ReleaseAIScoring(ctx context.Context, userID int32) error
+}
+
+// Rubrics resolves the rubric a prompt is scored on.
+// *writingrubric.Service satisfies it.
+type Rubrics interface {
+	ForPrompt(ctx context.Context, promptID int32) (ai.Rubric, error)
+}
+
+// Service manages writing submissions
+type Service interface {
+	Create(ctx context.Context, submission NewSubmission) (db.UserWriting, error)
+	// Get returns a submission, or ErrSubmissionNotFound
+	Get(ctx context.Context, id int32) (db.UserWriting, error)
+	// Update changes the given fields of a submission
+	Update(ctx context.Context, id int32, changes Changes) (db.UserWriting, error)
+	Delete(ctx context.Context, id int32) error
+	ListByUser(ctx context.Context, userID int32) ([]db.UserWriting, error)
+	ListByPrompt(ctx context.Context, promptID int32) ([]db.UserWriting, error)
+	// Search finds the submissions of the teacher's learners whose text or
+	// AI feedback match a full-text search, best match first, with the
+	// total count
+	Search(ctx context.Context, query SearchQuery) ([]Match, int64, error)
+	// Score scores a submission of the user, which keeps the score, or a
+	// text. A submission scored before is not scored again.
+	Score(ctx context.Context, userID int32, request ScoreRequest) (Result, error)
+}
+
+// NewSubmission is a submission to store
+type NewSubmission struct {
+	UserID     int32
+	PromptID   *int32
+	Text       string
+	AIFeedback json.RawMessage
+	AIScore    *float64
+}
+
+// Changes are the fields of a submission to update; nil fields are kept
+type Changes struct {
+	Text        *string
+	AIFeedback  json.RawMessage
+	AIScore     *float64
+	EvaluatedAt *time.Time
+}
+
+// SearchQuery is a full-text search of the submissions of a teacher's
+// learners: those who share an organization with the teacher. Zero
+// filters match every submission.
+type SearchQuery struct {
+	TeacherID int32
+	// Text is searched in web search syntax: words, "quoted phrases", or
+	// and -excluded words
+	Text     string
+	ClassID  int32 // Only learners of this LTI course the teacher is in
+	UserID   int32
+	From     time.Time // Submitted at or after
+	To       time.Time // Submitted before
+	MinScore *float64
+	MaxScore *float64
+	Limit    int32
+	Offset   int32
+}
+
+// Match is a submission matching a search, with the matching passages of
+// its text and AI feedback marked **like this**
+type Match struct {
+	ID               int32     `json:"id"`
+	UserID           int32     `json:"user_id"`
+	Username         string    `json:"username"`
+	PromptID         *int32    `json:"prompt_id,omitempty"`
+	AIScore          *float64  `json:"ai_score,omitempty"`
+	SubmittedAt      time.Time `json:"submitted_at"`
+	Rank             float64   `json:"rank"`
+	TextHeadline     string    `json:"text_headline"`
+	FeedbackHeadline string    `json:"feedback_headline,omitempty"`
+}
+
+// ScoreRequest names a submission to score, or else a text and optionally
+// the prompt it answers
+type ScoreRequest struct {
+	SubmissionID *int32
+	Text         string
+	PromptID     *int32
+}
+
+// Result is an AI score of a text
+type Result struct {
+	Score    int
+	Band     string
+	Feedback map[string]interface{}
+	// Rubric names the rubric scored on and Criteria are the scores on its
+	// criteria
+	Rubric      string
+	Criteria    []ai.CriterionScore
+	Suggestions []string
+	Confidence  float64
+	ProcessedAt time.Time
+	Text        string
+	// Truncated is set when only the beginning of the text, Text, was
+	// scored because the text was over the writing limit
+	Truncated bool
+	// Cached is set when the score was kept from an earlier scoring
+	Cached bool
+}
+
+type service struct {
+	store   db.Querier
+	scorer  Scorer
+	quota   Quota
+	rubrics Rubrics
+	limits  ailimit.Limits
+	now     func() time.Time
+}
+
+// NewService creates a writing service. Scoring fails with
+// ErrScorerUnavailable when scorer is nil. Writing is scored on
+// ai.DefaultRubric when rubrics is nil. Texts over the writing limit of
+// limits are rejected with ailimit.ErrTooLong or truncated before scoring.
+func NewService(store db.Querier, scorer Scorer, quota Quota, rubrics Rubrics, limits ailimit.Limits) Service {
+	return &service{store: store, scorer: scorer, quota: quota, rubrics: rubrics, limits: limits, now: time.Now}
+}
+
+// formatScore stores a score with two decimals
+func formatScore(score float64) sql.NullString {
+	return sql.NullString{String: strconv.FormatFloat(score, 'f', 2, 64), Valid: true}
+}
+
+func (s *service) Create(ctx context.Context, submission NewSubmission) (db.UserWriting, error) {
+	arg := db.CreateUserWritingParams{
+		UserID:         submission.UserID,
+		SubmissionText: submission.Text,
+	}
+	if submission.PromptID != nil {
+		arg.PromptID = sql.NullInt32{Int32: *submission.PromptID, Valid: true}
+	}
+	if submission.AIFeedback != nil {
+		arg.AiFeedback = pqtype.NullRawMessage{RawMessage: submission.AIFeedback, Valid: true}
+	}
+	if submission.AIScore != nil {
+		arg.AiScore = formatScore(*submission.AIScore)
+	}
+	return s.store.CreateUserWriting(ctx, arg)
+}
+
+func (s *service) Get(ctx context.Context, id int32) (db.UserWriting, error) {
+	writing, err := s.store.GetUserWriting(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return writing, ErrSubmissionNotFound
+	}
+	return writing, err
+}
+
+func (s *service) Update(ctx context.Context, id int32, changes Changes) (db.UserWriting, error) {
+	existing, err := s.Get(ctx, id)
+	if err != nil {
+		return existing, err
+	}
+	arg := db.UpdateUserWritingParams{
+		ID:             id,
+		SubmissionText: existing.SubmissionText,
+		AiFeedback:     existing.AiFeedback,
+		AiScore:        existing.AiScore,
+		EvaluatedAt:    existing.EvaluatedAt,
+	}
+	if changes.Text != nil {
+		arg.SubmissionText = *changes.Text
+	}
+	if changes.AIFeedback != nil {
+		arg.AiFeedback = pqtype.NullRawMessage{RawMessage: changes.AIFeedback, Valid: true}
+	}
+	if changes.AIScore != nil {
+		arg.AiScore = formatScore(*changes.AIScore)
+	}
+	if changes.EvaluatedAt != nil {
+		arg.EvaluatedAt = sql.NullTime{Time: *changes.EvaluatedAt, Valid: true}
+	}
+	return s.store.UpdateUserWriting(ctx, arg)
+}
+
+func (s *service) Delete(ctx context.Context, id int32) error {
+	return s.store.DeleteUserWriting(ctx, id)
+}
+
+func (s *service) ListByUser(ctx context.Context, userID int32) ([]db.UserWriting, error) {
+	return s.store.ListUserWritingsByUserID(ctx, userID)
+}
+
+func (s *service) ListByPrompt(ctx context.Context, promptID int32) ([]db.UserWriting, error) {
+	return s.store.ListUserWritingsByPromptID(ctx, sql.NullInt32{Int32: promptID, Valid: true})
+}
+
+func (s *service) Search(ctx context.Context, query SearchQuery) ([]Match, int64, error) {
+	filters := db.CountUserWritingSearchParams{
+		Search:        query.Text,
+		TeacherID:     query.TeacherID,
+		ClassID:       sql.NullInt32{Int32: query.ClassID, Valid: query.ClassID != 0},
+		UserID:        sql.NullInt32{Int32: query.UserID, Valid: query.UserID != 0},
+		SubmittedFrom: sql.NullTime{Time: query.From, Valid: !query.From.IsZero()},
+		SubmittedTo:   sql.NullTime{Time: query.To, Valid: !query.To.IsZero()},
+	}
+	if query.MinScore != nil {
+		filters.MinScore = formatScore(*query.MinScore)
+	}
+	if query.MaxScore != nil {
+		filters.MaxScore = formatScore(*query.MaxScore)
+	}
+	rows, err := s.store.SearchUserWritings(ctx, db.SearchUserWritingsParams{
+		Search:        filters.Search,
+		TeacherID:     filters.TeacherID,
+		ClassID:       filters.ClassID,
+		UserID:        filters.UserID,
+		SubmittedFrom: filters.SubmittedFrom,
+		SubmittedTo:   filters.SubmittedTo,
+		MinScore:      filters.MinScore,
+		MaxScore:      filters.MaxScore,
+		Limit:         query.Limit,
+		Offset:        query.Offset,
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search writing submissions: %w", err)
+	}
+	total, err := s.store.CountUserWritingSearch(ctx, filters)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count writing submissions: %w", err)
+	}
+
+	matches := make([]Match, 0, len(rows))
+	for _, row := range rows {
+		match := Match{
+			ID:               row.ID,
+			UserID:           row.UserID,
+			Username:         row.Username,
+			SubmittedAt:      row.SubmittedAt,
+			Rank:             row.Rank,
+			TextHeadline:     row.TextHeadline,
+			FeedbackHeadline: row.FeedbackHeadline,
+		}
+		if row.PromptID.Valid {
+			match.PromptID = &row.PromptID.Int32
+		}
+		if score, err := strconv.ParseFloat(row.AiScore.String, 64); row.AiScore.Valid && err == nil {
+			match.AIScore = &score
+		}
+		matches = append(matches, match)
+	}
+	return matches, total, nil
+}
+
+// keptFeedback is the AI feedback kept with a submission: the feedback with
+// the rubric and its criteria scores added under these keys
+func keptFeedback(result Result) json.RawMessage {
+	kept := make(map[string]interface{}, len(result.Feedback)+2)
+	for key, value := range result.Feedback {
+		kept[key] = value
+	}
+	if result.Rubric != "" {
+		kept["rubric"] = result.Rubric
+	}
+	if len(result.Criteria) > 0 {
+		kept["criteria"] = result.Criteria
+	}
+	feedback, _ := json.Marshal(kept)
+	return feedback
+}
+
+// cachedResult returns the score kept with a submission, if it has one
+func cachedResult(submission db.UserWriting) (Result, bool) {
+	if !submission.AiScore.Valid || !submission.AiFeedback.Valid {
+		return Result{}, false
+	}
+	var feedback map[string]interface{}
+	var rubric struct {
+		Rubric   string              `json:"rubric"`
+		Criteria []ai.CriterionScore `json:"criteria"`
+	}
+	if err := json.Unmarshal(submission.AiFeedback.RawMessage, &feedback); err != nil {
+		logger.Warn("Failed to parse AI feedback of submission %d, scoring again: %v", submission.ID, err)
+		return Result{}, false
+	}
+	// Feedback kept before rubrics has neither key
+	if err := json.Unmarshal(submission.AiFeedback.RawMessage, &rubric); err != nil {
+		logger.Warn("Failed to parse rubric scores of submission %d: %v", submission.ID, err)
+	}
+	delete(feedback, "rubric")
+	delete(feedback, "criteria")
+	score, _ := strconv.ParseFloat(submission.AiScore.String, 64)
+	return Result{
+		Score: int(score),
+		// The band is not kept with the submission
+		Band:        string(ai.BandLevel1),
+		Feedback:    feedback,
+		Rubric:      rubric.Rubric,
+		Criteria:    rubric.Criteria,
+		Suggestions: []string{},
+		Confidence:  cachedConfidence,
+		ProcessedAt: submission.EvaluatedAt.Time,
+		Text:        submission.SubmissionText,
+		Cached:      true,
+	}, true
+}
+
+func (s *service) Score(ctx context.Context, userID int32, request ScoreRequest) (Result, error) {
+	text := request.Text
+	var submission *db.UserWriting
+	promptID := request.PromptID
+	if request.SubmissionID != nil {
+		writing, err := s.Get(ctx, *request.SubmissionID)
+		if err != nil {
+			return Result{}, err
+		}
+		if writing.UserID != userID {
+			return Result{}, ErrNotOwner
+		}
+		if result, ok := cachedResult(writing); ok {
+			logger.Info("Returning cached AI score for submission %d", writing.ID)
+			return result, nil
+		}
+		submission = &writing
+		text = writing.SubmissionText
+		promptID = nil
+		if writing.PromptID.Valid {
+			promptID = &writing.PromptID.Int32
+		}
+	} else if text == "" {
+		return Result{}, ErrNoText
+	}
+
+	// Oversized texts are checked before they count against the quota
+	checked, err := s.limits.Check(ailimit.KindWriting, text)
+	if err != nil {
+		return Result{}, err
+	}
+	text = checked.Text
+
+	if s.scorer == nil {
+		return Result{}, ErrScorerUnavailable
+	}
+	if !s.scorer.IsHealthy() {
+		return Result{}, ErrScorerUnhealthy
+	}
+
+	scoreRequest := ai.AIScoreRequest{Text: text, PromptID: promptID, UserID: userID}
+	if promptID != nil {
+		scoreRequest.Task, scoreRequest.Rubric = s.task(ctx, *promptID)
+	}
+
+	// Count the scoring against the daily quota unless the user has
+	// unlimited AI scoring
+	metered, err := s.quota.ConsumeAIScoring(ctx, userID)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to check AI scoring quota: %w", err)
+	}
+	scored, err := s.scorer.ScoreWriting(ctx, scoreRequest)
+	if err != nil {
+		if metered {
+			if releaseErr := s.quota.ReleaseAIScoring(ctx, userID); releaseErr != nil {
+				logger.Warn("Failed to release AI scoring use of user %d: %v", userID, releaseErr)
+			}
+		}
+		return Result{}, err
+	}
+
+	result := Result{
+		Score: scored.Score,
+		Band:  string(scored.Band),
+		Feedback: map[string]interface{}{
+			"grammar":       scored.Feedback.Grammar,
+			"vocabulary":    scored.Feedback.Vocabulary,
+			"organization":  scored.Feedback.Organization,
+			"development":   scored.Feedback.Development,
+			"task_response": scored.Feedback.TaskResponse,
+			"language_use":  scored.Feedback.LanguageUse,
+			"overall":       scored.Feedback.Overall,
+		},
+		Rubric:      scored.Rubric,
+		Criteria:    scored.Criteria,
+		Suggestions: scored.Suggestions,
+		Confidence:  scored.Confidence,
+		ProcessedAt: scored.ProcessedAt,
+		Text:        text,
+		Truncated:   checked.Truncated,
+	}
+
+	// Keep the score with the submission. The score is returned even if
+	// it cannot be kept.
+	if submission != nil {
+		_, err := s.store.UpdateUserWriting(ctx, db.UpdateUserWritingParams{
+			ID:             submission.ID,
+			SubmissionText: submission.SubmissionText,
+			AiFeedback:     pqtype.NullRawMessage{RawMessage: keptFeedback(result), Valid: true},
+			AiScore:        formatScore(float64(scored.Score)),
+			EvaluatedAt:    sql.NullTime{Time: s.now(), Valid: true},
+		})
+		if err != nil {
+			logger.Error("Failed to update writing submission %d with AI score: %v", submission.ID, err)
+		} else {
+			logger.Info("Updated writing submission %d with AI score %d", submission.ID, scored.Score)
+		}
+	}
+	return result, nil
+}
+
+// task returns the prompt text and the rubric a prompt is scored on.
+// Writing is scored without them, on the default rubric, when they cannot
+// be loaded.
+func (s *service) task(ctx context.Context, promptID int32) (string, *ai.Rubric) {
+	var task string
+	prompt, err := s.store.GetWritingPrompt(ctx, promptID)
+	if err != nil {
+		logger.Warn("Failed to get writing prompt %d for scoring: %v", promptID, err)
+	} else {
+		task = prompt.PromptText
+	}
+	if s.rubrics == nil {
+		return task, nil
+	}
+	rubric, err := s.rubrics.ForPrompt(ctx, promptID)
+	if err != nil {
+		logger.Warn("Failed to get rubric of writing prompt %d, scoring on the default rubric: %v", promptID, err)
+		return task, nil
+	}
+	return task, &rubric
+}
@@ -0,0 +1,242 @@
+package writingservice
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sqlc-dev/pqtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/toeic-app/internal/ai"
+	"github.com/toeic-app/internal/ailimit"
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+type fakeStore struct {
+	db.Querier
+	writings map[int32]db.UserWriting
+}
+
+func (s *fakeStore) GetUserWriting(ctx context.Context, id int32) (db.UserWriting, error) {
+	writing, ok := s.writings[id]
+	if !ok {
+		return writing, sql.ErrNoRows
+	}
+	return writing, nil
+}
+
+func (s *fakeStore) UpdateUserWriting(ctx context.Context, arg db.UpdateUserWritingParams) (db.UserWriting, error) {
+	writing := s.writings[arg.ID]
+	writing.SubmissionText = arg.SubmissionText
+	writing.AiFeedback = arg.AiFeedback
+	writing.AiScore = arg.AiScore
+	writing.EvaluatedAt = arg.EvaluatedAt
+	s.writings[arg.ID] = writing
+	return writing, nil
+}
+
+func (s *fakeStore) GetWritingPrompt(ctx context.Context, id int32) (db.WritingPrompt, error) {
+	return db.WritingPrompt{ID: id, PromptText: "Do you agree that remote work is better?"}, nil
+}
+
+type fakeScorer struct {
+	calls int
+	err   error
+	last  ai.AIScoreRequest
+}
+
+// ScoreWriting gives every criterion of the rubric a score of 150
+func (s *fakeScorer) ScoreWriting(ctx context.Context, req ai.AIScoreRequest) (*ai.AIScoreResponse, error) {
+	s.calls++
+	s.last = req
+	if s.err != nil {
+		return nil, s.err
+	}
+	response := &ai.AIScoreResponse{Score: 150, Band: ai.BandLevel7, Feedback: ai.ScoringCriteria{Overall: "Clear"}}
+	if req.Rubric != nil {
+		response.Rubric = req.Rubric.Name
+		for _, criterion := range req.Rubric.Criteria {
+			response.Criteria = append(response.Criteria, ai.CriterionScore{Key: criterion.Key, Name: criterion.Name, Weight: criterion.Weight, Score: 150})
+		}
+	}
+	return response, nil
+}
+
+func (s *fakeScorer) IsHealthy() bool { return true }
+
+type fakeQuota struct {
+	consumed, released int
+}
+
+func (q *fakeQuota) ConsumeAIScoring(ctx context.Context, userID int32) (bool, error) {
+	q.consumed++
+	return true, nil
+}
+
+func (q *fakeQuota) ReleaseAIScoring(ctx context.Context, userID int32) error {
+	q.released++
+	return nil
+}
+
+type fakeRubrics struct{}
+
+func (fakeRubrics) ForPrompt(ctx context.Context, promptID int32) (ai.Rubric, error) {
+	return ai.Rubric{Name: "Opinion essay", Criteria: []ai.RubricCriterion{{Key: "development", Name: "Development", Weight: 100}}}, nil
+}
+
+func newStore() *fakeStore {
+	return &fakeStore{writings: map[int32]db.UserWriting{
+		1: {ID: 1, UserID: 7, SubmissionText: "My essay"},
+	}}
+}
+
+func TestScoreKeepsScoreWithSubmission(t *testing.T) {
+	store, scorer, quota := newStore(), &fakeScorer{}, &fakeQuota{}
+	service := NewService(store, scorer, quota, nil, ailimit.Limits{})
+	ctx := context.Background()
+	id := int32(1)
+
+	_, err := service.Score(ctx, 8, ScoreRequest{SubmissionID: &id})
+	assert.ErrorIs(t, err, ErrNotOwner)
+
+	result, err := service.Score(ctx, 7, ScoreRequest{SubmissionID: &id})
+	require.NoError(t, err)
+	assert.False(t, result.Cached)
+	assert.Equal(t, "150.00", store.writings[1].AiScore.String)
+	assert.True(t, store.writings[1].EvaluatedAt.Valid)
+
+	// The kept score is returned without scoring or counting again
+	result, err = service.Score(ctx, 7, ScoreRequest{SubmissionID: &id})
+	require.NoError(t, err)
+	assert.True(t, result.Cached)
+	assert.Equal(t, 150, result.Score)
+	assert.Equal(t, "Clear", result.Feedback["overall"])
+	assert.Equal(t, 1, scorer.calls)
+	assert.Equal(t, 1, quota.consumed)
+}
+
+func TestScoreUsesRubricOfPrompt(t *testing.T) {
+	store, scorer, quota := newStore(), &fakeScorer{}, &fakeQuota{}
+	store.writings[2] = db.UserWriting{ID: 2, UserID: 7, SubmissionText: "I agree", PromptID: sql.NullInt32{Int32: 4, Valid: true}}
+	service := NewService(store, scorer, quota, fakeRubrics{}, ailimit.Limits{})
+	ctx := context.Background()
+	id := int32(2)
+
+	result, err := service.Score(ctx, 7, ScoreRequest{SubmissionID: &id})
+	require.NoError(t, err)
+	assert.Equal(t, "Do you agree that remote work is better?", scorer.last.Task)
+	require.NotNil(t, scorer.last.Rubric)
+	assert.Equal(t, "Opinion essay", scorer.last.Rubric.Name)
+	criteria := []ai.CriterionScore{{Key: "development", Name: "Development", Weight: 100, Score: 150}}
+	assert.Equal(t, criteria, result.Criteria)
+
+	// The rubric scores are kept with the submission, apart from the feedback
+	result, err = service.Score(ctx, 7, ScoreRequest{SubmissionID: &id})
+	require.NoError(t, err)
+	assert.True(t, result.Cached)
+	assert.Equal(t, "Opinion essay", result.Rubric)
+	assert.Equal(t, criteria, result.Criteria)
+	assert.NotContains(t, result.Feedback, "criteria")
+
+	// Texts without a prompt are scored on the default rubric
+	_, err = service.Score(ctx, 7, ScoreRequest{Text: "Some text"})
+	require.NoError(t, err)
+	assert.Nil(t, scorer.last.Rubric)
+	assert.Empty(t, scorer.last.Task)
+}
+
+func TestScoreReleasesQuotaOnFailure(t *testing.T) {
+	scorer, quota := &fakeScorer{err: errors.New("timeout")}, &fakeQuota{}
+	service := NewService(newStore(), scorer, quota, nil, ailimit.Limits{})
+
+	_, err := service.Score(context.Background(), 7, ScoreRequest{Text: "Some text"})
+	assert.Error(t, err)
+	assert.Equal(t, 1, quota.released)
+
+	_, err = service.Score(context.Background(), 7, ScoreRequest{})
+	assert.ErrorIs(t, err, ErrNoText)
+	_, err = NewService(newStore(), nil, quota, nil, ailimit.Limits{}).Score(context.Background(), 7, ScoreRequest{Text: "Some text"})
+	assert.ErrorIs(t, err, ErrScorerUnavailable)
+}
+
+func TestScoreEnforcesWritingLimit(t *testing.T) {
+	scorer, quota := &fakeScorer{}, &fakeQuota{}
+	limits := ailimit.Limits{Mode: ailimit.ModeReject, Kinds: map[string]ailimit.Limit{ailimit.KindWriting: {MaxWords: 4}}}
+	text := "Remote work saves time. It also saves money."
+
+	_, err := NewService(newStore(), scorer, quota, nil, limits).Score(context.Background(), 7, ScoreRequest{Text: text})
+	assert.ErrorIs(t, err, ailimit.ErrTooLong)
+	assert.Zero(t, quota.consumed)
+	assert.Zero(t, scorer.calls)
+
+	limits.Mode = ailimit.ModeTruncate
+	result, err := NewService(newStore(), scorer, quota, nil, limits).Score(context.Background(), 7, ScoreRequest{Text: text})
+	require.NoError(t, err)
+	assert.True(t, result.Truncated)
+	assert.Equal(t, "Remote work saves time.", result.Text)
+	assert.Equal(t, "Remote work saves time.", scorer.last.Text)
+}
+
+func TestCachedResultIgnoresBrokenFeedback(t *testing.T) {
+	_, ok := cachedResult(db.UserWriting{
+		AiScore:    sql.NullString{String: "120.00", Valid: true},
+		AiFeedback: pqtype.NullRawMessage{RawMessage: []byte("not json"), Valid: true},
+	})
+	assert.False(t, ok)
+}
+
+type searchStore struct {
+	db.Querier
+	searched db.SearchUserWritingsParams
+	counted  db.CountUserWritingSearchParams
+}
+
+func (s *searchStore) SearchUserWritings(ctx context.Context, arg db.SearchUserWritingsParams) ([]db.SearchUserWritingsRow, error) {
+	s.searched = arg
+	return []db.SearchUserWritingsRow{
+		{ID: 3, UserID: 9, Username: "learner", PromptID: sql.NullInt32{Int32: 4, Valid: true}, AiScore: sql.NullString{String: "120.50", Valid: true},
+			Rank: 0.6, TextHeadline: "she **go** to work"},
+		{ID: 2, UserID: 9, Username: "learner", TextHeadline: "they **goes** home"},
+	}, nil
+}
+
+func (s *searchStore) CountUserWritingSearch(ctx context.Context, arg db.CountUserWritingSearchParams) (int64, error) {
+	s.counted = arg
+	return 12, nil
+}
+
+func TestSearch(t *testing.T) {
+	store := &searchStore{}
+	service := NewService(store, nil, nil, nil, ailimit.Limits{})
+	from := time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC)
+	minScore := 100.0
+
+	matches, total, err := service.Search(context.Background(), SearchQuery{
+		TeacherID: 5, Text: "go OR goes", ClassID: 8, From: from, MinScore: &minScore, Limit: 20, Offset: 40,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(12), total)
+
+	assert.Equal(t, db.CountUserWritingSearchParams{
+		Search:        "go OR goes",
+		TeacherID:     5,
+		ClassID:       sql.NullInt32{Int32: 8, Valid: true},
+		SubmittedFrom: sql.NullTime{Time: from, Valid: true},
+		MinScore:      sql.NullString{String: "100.00", Valid: true},
+	}, store.counted)
+	assert.False(t, store.searched.UserID.Valid)
+	assert.False(t, store.searched.SubmittedTo.Valid)
+	assert.False(t, store.searched.MaxScore.Valid)
+	assert.Equal(t, int32(40), store.searched.Offset)
+
+	require.Len(t, matches, 2)
+	require.NotNil(t, matches[0].PromptID)
+	assert.Equal(t, int32(4), *matches[0].PromptID)
+	require.NotNil(t, matches[0].AIScore)
+	assert.Equal(t, 120.5, *matches[0].AIScore)
+	assert.Nil(t, matches[1].PromptID)
+	assert.Nil(t, matches[1].AIScore)
+}
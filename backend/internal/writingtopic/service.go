@@ -0,0 +1,318 @@
+// Package writingtopic manages the taxonomy of writing prompt topics, such
+// as business emails or opinion essays. Topics form a tree; a prompt is
+// filed under at most one topic and filtering by a topic includes the
+// prompts of its descendants.
+package writingtopic
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/lib/pq"
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+var (
+	ErrTopicNotFound = errors.New("writing topic not found")
+	ErrInvalidSlug   = errors.New("slug must be lowercase letters and digits separated by single dashes, at most 64 characters")
+	ErrSlugTaken     = errors.New("a writing topic with this slug already exists")
+	ErrInvalidParent = errors.New("a writing topic cannot be moved under itself or one of its subtopics")
+	ErrTopicInUse    = errors.New("writing topic still has subtopics or prompts")
+)
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// Counts are the prompts filed under a topic and its descendants
+type Counts struct {
+	Prompts   int64 `json:"prompts"`
+	Published int64 `json:"published"`
+}
+
+// Topic is a node of the taxonomy
+type Topic struct {
+	ID          int32    `json:"id"`
+	ParentID    *int32   `json:"parent_id,omitempty"`
+	Slug        string   `json:"slug"`
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Position    int32    `json:"position"`
+	Counts      *Counts  `json:"counts,omitempty"`
+	Children    []*Topic `json:"children"`
+}
+
+// Input creates or changes a topic. ParentID nil makes a top-level topic.
+type Input struct {
+	ParentID    *int32
+	Slug        string
+	Name        string
+	Description string
+	Position    int32
+}
+
+// Service manages writing topics
+type Service struct {
+	store db.Querier
+}
+
+// NewService creates a writing topic service
+func NewService(store db.Querier) *Service {
+	return &Service{store: store}
+}
+
+// Tree returns the top-level topics with their descendants, ordered by
+// position. withCounts adds the number of prompts of each topic, including
+// those filed under its descendants.
+func (s *Service) Tree(ctx context.Context, withCounts bool) ([]*Topic, error) {
+	rows, err := s.store.ListWritingTopics(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list writing topics: %w", err)
+	}
+
+	nodes := make(map[int32]*Topic, len(rows))
+	for _, row := range rows {
+		topic := &Topic{
+			ID:          row.ID,
+			Slug:        row.Slug,
+			Name:        row.Name,
+			Description: row.Description,
+			Position:    row.Position,
+			Children:    []*Topic{},
+		}
+		if row.ParentID.Valid {
+			topic.ParentID = &row.ParentID.Int32
+		}
+		if withCounts {
+			topic.Counts = &Counts{Prompts: row.Prompts, Published: row.PublishedPrompts}
+		}
+		nodes[row.ID] = topic
+	}
+
+	roots := []*Topic{}
+	for _, row := range rows {
+		topic := nodes[row.ID]
+		if parent, ok := nodes[row.ParentID.Int32]; row.ParentID.Valid && ok {
+			parent.Children = append(parent.Children, topic)
+		} else {
+			roots = append(roots, topic)
+		}
+	}
+	if withCounts {
+		for _, root := range roots {
+			rollUp(root)
+		}
+	}
+	return roots, nil
+}
+
+// rollUp adds the counts of the descendants of a topic to its own
+func rollUp(topic *Topic) Counts {
+	for _, child := range topic.Children {
+		counts := rollUp(child)
+		topic.Counts.Prompts += counts.Prompts
+		topic.Counts.Published += counts.Published
+	}
+	return *topic.Counts
+}
+
+// Get returns a topic without its children
+func (s *Service) Get(ctx context.Context, id int32) (*Topic, error) {
+	row, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return toTopic(row), nil
+}
+
+// Create adds a topic
+func (s *Service) Create(ctx context.Context, input Input) (*Topic, error) {
+	if !validSlug(input.Slug) {
+		return nil, ErrInvalidSlug
+	}
+	if input.ParentID != nil {
+		if _, err := s.get(ctx, *input.ParentID); err != nil {
+			return nil, err
+		}
+	}
+
+	row, err := s.store.CreateWritingTopic(ctx, db.CreateWritingTopicParams{
+		ParentID:    nullInt32(input.ParentID),
+		Slug:        input.Slug,
+		Name:        input.Name,
+		Description: input.Description,
+		Position:    input.Position,
+	})
+	if isViolation(err, "23505") {
+		return nil, ErrSlugTaken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create writing topic: %w", err)
+	}
+	return toTopic(row), nil
+}
+
+// Update changes a topic and may move it under another parent
+func (s *Service) Update(ctx context.Context, id int32, input Input) (*Topic, error) {
+	if !validSlug(input.Slug) {
+		return nil, ErrInvalidSlug
+	}
+	if _, err := s.get(ctx, id); err != nil {
+		return nil, err
+	}
+	if input.ParentID != nil {
+		if err := s.checkParent(ctx, id, *input.ParentID); err != nil {
+			return nil, err
+		}
+	}
+
+	row, err := s.store.UpdateWritingTopic(ctx, db.UpdateWritingTopicParams{
+		ID:          id,
+		ParentID:    nullInt32(input.ParentID),
+		Slug:        input.Slug,
+		Name:        input.Name,
+		Description: input.Description,
+		Position:    input.Position,
+	})
+	if isViolation(err, "23505") {
+		return nil, ErrSlugTaken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update writing topic: %w", err)
+	}
+	return toTopic(row), nil
+}
+
+// checkParent verifies that parentID exists and is neither the topic nor
+// one of its descendants
+func (s *Service) checkParent(ctx context.Context, id, parentID int32) error {
+	rows, err := s.store.ListWritingTopics(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list writing topics: %w", err)
+	}
+	parents := make(map[int32]sql.NullInt32, len(rows))
+	for _, row := range rows {
+		parents[row.ID] = row.ParentID
+	}
+	if _, ok := parents[parentID]; !ok {
+		return ErrTopicNotFound
+	}
+	// Walk up from the new parent; reaching the topic would close a cycle
+	for current, steps := parentID, 0; steps <= len(rows); steps++ {
+		if current == id {
+			return ErrInvalidParent
+		}
+		parent := parents[current]
+		if !parent.Valid {
+			return nil
+		}
+		current = parent.Int32
+	}
+	return ErrInvalidParent
+}
+
+// Delete removes a topic without subtopics or prompts
+func (s *Service) Delete(ctx context.Context, id int32) error {
+	deleted, err := s.store.DeleteWritingTopic(ctx, id)
+	if isViolation(err, "23503") {
+		return ErrTopicInUse
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete writing topic: %w", err)
+	}
+	if deleted == 0 {
+		return ErrTopicNotFound
+	}
+	return nil
+}
+
+// Prompts returns the published prompts filed under a topic or its
+// descendants, newest first
+func (s *Service) Prompts(ctx context.Context, topicID int32) ([]db.WritingPrompt, error) {
+	if _, err := s.get(ctx, topicID); err != nil {
+		return nil, err
+	}
+	prompts, err := s.store.ListWritingPromptsByTopic(ctx, topicID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list prompts of writing topic: %w", err)
+	}
+	return prompts, nil
+}
+
+// Assign files a prompt under a topic. topicID nil removes the prompt from
+// the taxonomy.
+func (s *Service) Assign(ctx context.Context, promptID int32, topicID *int32) error {
+	if topicID == nil {
+		if err := s.store.ClearWritingPromptTopic(ctx, promptID); err != nil {
+			return fmt.Errorf("failed to clear topic of prompt: %w", err)
+		}
+		return nil
+	}
+	if _, err := s.get(ctx, *topicID); err != nil {
+		return err
+	}
+	if err := s.store.SetWritingPromptTopic(ctx, db.SetWritingPromptTopicParams{PromptID: promptID, TopicID: *topicID}); err != nil {
+		return fmt.Errorf("failed to set topic of prompt: %w", err)
+	}
+	return nil
+}
+
+// TopicsOf maps prompts to the topic they are filed under. Prompts outside
+// the taxonomy are left out.
+func (s *Service) TopicsOf(ctx context.Context, promptIDs []int32) (map[int32]int32, error) {
+	topics := make(map[int32]int32)
+	if len(promptIDs) == 0 {
+		return topics, nil
+	}
+	rows, err := s.store.ListWritingPromptTopics(ctx, promptIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list topics of prompts: %w", err)
+	}
+	for _, row := range rows {
+		topics[row.PromptID] = row.TopicID
+	}
+	return topics, nil
+}
+
+func (s *Service) get(ctx context.Context, id int32) (db.WritingTopic, error) {
+	topic, err := s.store.GetWritingTopic(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return db.WritingTopic{}, ErrTopicNotFound
+	}
+	if err != nil {
+		return db.WritingTopic{}, fmt.Errorf("failed to get writing topic: %w", err)
+	}
+	return topic, nil
+}
+
+func toTopic(row db.WritingTopic) *Topic {
+	topic := &Topic{
+		ID:          row.ID,
+		Slug:        row.Slug,
+		Name:        row.Name,
+		Description: row.Description,
+		Position:    row.Position,
+		Children:    []*Topic{},
+	}
+	if row.ParentID.Valid {
+		topic.ParentID = &row.ParentID.Int32
+	}
+	return topic
+}
+
+func validSlug(slug string) bool {
+	return len(slug) <= 64 && slugPattern.MatchString(slug)
+}
+
+func nullInt32(value *int32) sql.NullInt32 {
+	if value == nil {
+		return sql.NullInt32{}
+	}
+	return sql.NullInt32{Int32: *value, Valid: true}
+}
+
+func isViolation(err error, code pq.ErrorCode) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == code
+}
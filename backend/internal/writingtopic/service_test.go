@@ -0,0 +1,84 @@
+package writingtopic
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	db "github.com/toeic-app/internal/db/sqlc"
+)
+
+// fakeStore holds a fixed taxonomy:
+//
+//	1 business-email (2 prompts, 1 published)
+//	  2 email-request (3 prompts, 3 published)
+//	    4 email-request-formal (1 prompt, 0 published)
+//	3 opinion-essay
+type fakeStore struct {
+	db.Querier
+	updated *db.UpdateWritingTopicParams
+}
+
+func (s *fakeStore) ListWritingTopics(context.Context) ([]db.ListWritingTopicsRow, error) {
+	return []db.ListWritingTopicsRow{
+		{ID: 1, Slug: "business-email", Position: 1, Prompts: 2, PublishedPrompts: 1},
+		{ID: 2, ParentID: sql.NullInt32{Int32: 1, Valid: true}, Slug: "email-request", Prompts: 3, PublishedPrompts: 3},
+		{ID: 3, Slug: "opinion-essay", Position: 2},
+		{ID: 4, ParentID: sql.NullInt32{Int32: 2, Valid: true}, Slug: "email-request-formal", Prompts: 1},
+	}, nil
+}
+
+func (s *fakeStore) GetWritingTopic(_ context.Context, id int32) (db.WritingTopic, error) {
+	if id < 1 || id > 4 {
+		return db.WritingTopic{}, sql.ErrNoRows
+	}
+	return db.WritingTopic{ID: id}, nil
+}
+
+func (s *fakeStore) UpdateWritingTopic(_ context.Context, arg db.UpdateWritingTopicParams) (db.WritingTopic, error) {
+	s.updated = &arg
+	return db.WritingTopic{ID: arg.ID, ParentID: arg.ParentID, Slug: arg.Slug}, nil
+}
+
+func TestTree(t *testing.T) {
+	service := NewService(&fakeStore{})
+
+	tree, err := service.Tree(context.Background(), true)
+	require.NoError(t, err)
+	require.Len(t, tree, 2)
+	email := tree[0]
+	assert.Equal(t, "business-email", email.Slug)
+	assert.Equal(t, Counts{Prompts: 6, Published: 4}, *email.Counts)
+	require.Len(t, email.Children, 1)
+	assert.Equal(t, Counts{Prompts: 4, Published: 3}, *email.Children[0].Counts)
+	assert.Equal(t, "email-request-formal", email.Children[0].Children[0].Slug)
+	assert.NotNil(t, tree[1].Children)
+
+	tree, err = service.Tree(context.Background(), false)
+	require.NoError(t, err)
+	assert.Nil(t, tree[0].Counts)
+}
+
+func TestUpdateRejectsCycles(t *testing.T) {
+	store := &fakeStore{}
+	service := NewService(store)
+	ctx := context.Background()
+	parent := func(id int32) *int32 { return &id }
+
+	_, err := service.Update(ctx, 1, Input{Slug: "business-email", ParentID: parent(4)})
+	assert.ErrorIs(t, err, ErrInvalidParent)
+	_, err = service.Update(ctx, 2, Input{Slug: "email-request", ParentID: parent(2)})
+	assert.ErrorIs(t, err, ErrInvalidParent)
+	_, err = service.Update(ctx, 2, Input{Slug: "email-request", ParentID: parent(9)})
+	assert.ErrorIs(t, err, ErrTopicNotFound)
+	_, err = service.Update(ctx, 2, Input{Slug: "Email Request"})
+	assert.ErrorIs(t, err, ErrInvalidSlug)
+	assert.Nil(t, store.updated)
+
+	topic, err := service.Update(ctx, 4, Input{Slug: "email-formal", ParentID: parent(3)})
+	require.NoError(t, err)
+	require.NotNil(t, topic.ParentID)
+	assert.Equal(t, int32(3), *topic.ParentID)
+}
@@ -14,10 +14,10 @@ import (
 	_ "github.com/lib/pq"
 	_ "github.com/toeic-app/docs"
 	"github.com/toeic-app/internal/api"
+	"github.com/toeic-app/internal/buildinfo"
 	"github.com/toeic-app/internal/config"
 	db "github.com/toeic-app/internal/db/sqlc"
 	"github.com/toeic-app/internal/logger"
-	"github.com/toeic-app/internal/middleware"
 	"github.com/toeic-app/internal/util"
 )
 
@@ -32,35 +32,26 @@ func main() {
 
 	logger.InfoWithFields(logger.Fields{
 		"component": "startup",
-		"version":   "1.0.0",
+		"version":   buildinfo.Version,
 		"env":       "development",
-	}, "Starting TOEIC application") // Load configuration
-	cfg := config.DefaultConfig()
+	}, "Starting TOEIC application")
+	// Load configuration: config file, environment and --set overrides
+	config.LoadEnv()
+	opts, err := config.ParseFlags(os.Args[1:])
+	if err != nil {
+		logger.Fatal("Invalid command line flags: %v", err)
+	}
+	cfg, err := config.Load(opts)
+	if err != nil {
+		logger.Fatal("Invalid configuration: %v", err)
+	}
 	logger.InfoWithFields(logger.Fields{
 		"component":   "config",
 		"db_driver":   cfg.DBDriver,
 		"server_addr": cfg.ServerAddress,
 	}, "Configuration loaded successfully")
-	// Register custom validators
-	middleware.RegisterValidators()
-	logger.DebugWithFields(logger.Fields{
-		"component": "middleware",
-		"feature":   "validators",
-	}, "Custom validators registered")
-	// Check if database tools (pg_dump, psql) are available
-	// This is optional - if tools are not available, the backup/restore features won't work
-	if err := util.CheckDatabaseTools(); err != nil {
-		logger.WarnWithFields(logger.Fields{
-			"component": "database",
-			"feature":   "backup_tools",
-			"error":     err.Error(),
-		}, "Database backup/restore tools not available")
-		logger.WarnWithFields(logger.Fields{
-			"component": "database",
-			"feature":   "backup_tools",
-			"impact":    "limited_functionality",
-		}, "Backup/restore functionality will be limited")
-	} // Open a connection to the database
+
+	// Open a connection to the database
 	logger.InfoWithFields(logger.Fields{
 		"component": "database",
 		"driver":    cfg.DBDriver,
@@ -83,6 +74,12 @@ func main() {
 
 	logger.Info("Successfully connected to database with enhanced connection pool!")
 
+	// Check if database tools (pg_dump, psql, pg_restore) are available and
+	// not older than the server, in the background as containerized tools
+	// may have to be pulled first. This is optional - if tools are not
+	// available, the backup/restore features won't work
+	go checkDatabaseTools(conn)
+
 	// Initialize queries with connection
 	queries := db.New(conn)
 	if err != nil {
@@ -98,6 +95,12 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Reload rate limits, cache TTLs and feature flags on SIGHUP
+	server.WatchConfigReload(ctx)
+
+	// Scheduled tasks only run on the elected leader when scaled out
+	server.StartLeaderElection(ctx)
+
 	// Setup automatic database backups
 	if err := server.StartAutomaticBackups(ctx); err != nil {
 		logger.Warn("Failed to setup automatic backups: %v", err)
@@ -105,11 +108,13 @@ func main() {
 	} else {
 		logger.Info("Automatic database backups configured successfully")
 
-		// Setup cleanup of old backups (keep backups for 30 days)
+		// Setup cleanup of old backups (grandfather-father-son retention)
 		go func() {
-			// Initial cleanup
-			if err := server.CleanupOldBackups(30 * 24 * time.Hour); err != nil {
-				logger.Warn("Failed to clean up old backups: %v", err)
+			// Initial cleanup (leader only)
+			if server.IsLeader() {
+				if err := server.EnforceBackupRetention(ctx); err != nil {
+					logger.Warn("Failed to clean up old backups: %v", err)
+				}
 			}
 
 			// Periodic cleanup (once a day)
@@ -119,7 +124,10 @@ func main() {
 			for {
 				select {
 				case <-ticker.C:
-					if err := server.CleanupOldBackups(30 * 24 * time.Hour); err != nil {
+					if !server.IsLeader() {
+						continue
+					}
+					if err := server.EnforceBackupRetention(ctx); err != nil {
 						logger.Warn("Failed to clean up old backups: %v", err)
 					}
 				case <-ctx.Done():
@@ -145,8 +153,9 @@ func main() {
 
 	logger.Info("Shutdown signal received...")
 
-	// Create a deadline to wait for current operations to complete
-	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+	// Create a deadline to wait for current operations to complete: the drain
+	// deadline for requests and queued jobs, plus time to clean up
+	ctx, cancel = context.WithTimeout(context.Background(), cfg.DrainTimeout+10*time.Second)
 	defer cancel()
 	// First shut down the server
 	if err := server.Shutdown(ctx); err != nil {
@@ -179,3 +188,30 @@ func main() {
 
 	logger.Info("Server exited gracefully")
 }
+
+// checkDatabaseTools warns when pg_dump, psql or pg_restore are missing or
+// older than the database server
+func checkDatabaseTools(conn *sql.DB) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	var serverVersion string
+	if err := conn.QueryRowContext(ctx, "SHOW server_version").Scan(&serverVersion); err != nil {
+		logger.Warn("Could not read the database server version: %v", err)
+	}
+	backupConfig := config.LoadBackupConfig()
+	tools := util.NewDatabaseTools(backupConfig.Tools, backupConfig.BackupDir)
+	if err := util.CheckDatabaseTools(ctx, tools, serverVersion); err != nil {
+		logger.WarnWithFields(logger.Fields{
+			"component": "database",
+			"feature":   "backup_tools",
+			"mode":      tools.Mode(),
+			"error":     err.Error(),
+		}, "Database backup/restore tools not available")
+		logger.WarnWithFields(logger.Fields{
+			"component": "database",
+			"feature":   "backup_tools",
+			"impact":    "limited_functionality",
+		}, "Backup/restore functionality will be limited")
+	}
+}
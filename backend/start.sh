@@ -114,6 +114,16 @@ run_migrations() {
     
     # Check if migrate tool exists
     if command -v migrate > /dev/null 2>&1; then
+        # Back up the database first; migrating without a backup needs MIGRATION_SKIP_BACKUP=true
+        if [ -x ./backup-admin ]; then
+            if ! ./backup-admin pre-migrate; then
+                echo -e "${RED}❌ Backup before migrating failed, not migrating${NC}"
+                exit 1
+            fi
+        elif [ "$MIGRATION_SKIP_BACKUP" != "true" ]; then
+            echo -e "${RED}❌ backup-admin not found, cannot back up before migrating (set MIGRATION_SKIP_BACKUP=true to migrate anyway)${NC}"
+            exit 1
+        fi
         migrate -path db/migrations -database "postgresql://$DB_USER:$DB_PASSWORD@$DB_HOST:${DB_PORT:-5432}/$DB_NAME?sslmode=disable" up
         echo -e "${GREEN}✅ Migrations completed${NC}"
     else